@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/logger"
+	"github.com/forward-mcp/internal/service"
+)
+
+// errUsage marks invalid arguments; the flag set has already printed the problem
+var errUsage = errors.New("invalid usage")
+
+// subcommand is a one-shot operation that runs instead of the MCP server and prints its
+// result to stdout, so tasks can be scripted without an MCP client
+type subcommand struct {
+	name    string
+	summary string
+	run     func(cfg *config.Config, args []string, stdout, stderr io.Writer) error
+}
+
+var subcommands = []subcommand{
+	{name: "run-query", summary: "Run an NQE query by ID or source and print the rows as JSON", run: runQueryCommand},
+	{name: "hydrate", summary: "Sync the local NQE query database with the query library", run: hydrateCommand},
+	{name: "export-topology", summary: "Print the devices and links of a network as JSON", run: exportTopologyCommand},
+	{name: "doctor", summary: "Check the setup and print remediation steps", run: doctorCommand},
+}
+
+// subcommandName maps the first argument to a subcommand, or "" to start the server
+func subcommandName(arg string) string {
+	switch arg {
+	case "--doctor", "-doctor":
+		return "doctor"
+	case "help", "-h", "-help", "--help":
+		return "help"
+	}
+	for _, command := range subcommands {
+		if command.name == arg {
+			return arg
+		}
+	}
+	return ""
+}
+
+// runSubcommand runs a subcommand and returns the process exit code
+func runSubcommand(cfg *config.Config, name string, args []string, stdout, stderr io.Writer) int {
+	if name == "help" {
+		printUsage(stdout)
+		return 0
+	}
+	for _, command := range subcommands {
+		if command.name != name {
+			continue
+		}
+		if err := command.run(cfg, args, stdout, stderr); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return 0
+			}
+			if errors.Is(err, errUsage) {
+				return 2
+			}
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(stderr, "Unknown command %q\n\n", name)
+	printUsage(stderr)
+	return 2
+}
+
+// printUsage lists the subcommands
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: forward-mcp [command] [flags]")
+	fmt.Fprintln(w, "\nWithout a command the MCP server starts. Commands:")
+	for _, command := range subcommands {
+		fmt.Fprintf(w, "  %-16s %s\n", command.name, command.summary)
+	}
+	fmt.Fprintln(w, "\nRun 'forward-mcp <command> -h' for the flags of a command.")
+}
+
+// newFlagSet creates the flag set of a subcommand, reporting errors instead of exiting
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
+}
+
+// parseFlags parses args and rejects stray positional arguments
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(flags.Output(), "Unexpected arguments: %v\n", flags.Args())
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// withService starts the service layer for a single operation and shuts it down afterwards
+func withService(cfg *config.Config, timeout time.Duration, run func(ctx context.Context, svc *service.ForwardMCPService) error) error {
+	if cfg.Forward.APIBaseURL == "" || cfg.Forward.APIKey == "" || cfg.Forward.APISecret == "" {
+		return fmt.Errorf("the Forward API base URL, key and secret are required (set FORWARD_API_BASE_URL, FORWARD_API_KEY and FORWARD_API_SECRET)")
+	}
+	log := logger.New()
+	svc := service.NewForwardMCPService(cfg, log)
+	defer func() {
+		if err := svc.Shutdown(30 * time.Second); err != nil {
+			log.Error("Error during service shutdown: %v", err)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return run(ctx, svc)
+}
+
+// writeJSON prints v as indented JSON
+func writeJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func runQueryCommand(cfg *config.Config, args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("run-query", stderr)
+	queryID := flags.String("id", "", "NQE query ID (e.g. FQ_ac651cb2901b067fe7dbfb511613ab44776d8029)")
+	query := flags.String("query", "", "NQE source to run instead of a query ID")
+	networkID := flags.String("network", "", "Network ID (default: the configured network)")
+	snapshotID := flags.String("snapshot", "", "Snapshot ID (default: the latest snapshot)")
+	params := flags.String("params", "", "Query parameters as a JSON object")
+	limit := flags.Int("limit", 0, "Rows per page (default: the configured query limit)")
+	offset := flags.Int("offset", 0, "First row to fetch")
+	all := flags.Bool("all", false, "Fetch every page")
+	store := flags.Bool("store", false, "Store the rows in the memory system and print the result ID to stderr")
+	timeout := flags.Duration("timeout", 10*time.Minute, "Maximum time for the query")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if (*queryID == "") == (*query == "") {
+		fmt.Fprintln(stderr, "Exactly one of -id and -query is required")
+		flags.Usage()
+		return errUsage
+	}
+	var parameters map[string]interface{}
+	if *params != "" {
+		if err := json.Unmarshal([]byte(*params), &parameters); err != nil {
+			return fmt.Errorf("invalid -params JSON: %w", err)
+		}
+	}
+
+	return withService(cfg, *timeout, func(ctx context.Context, svc *service.ForwardMCPService) error {
+		result, err := svc.RunQuery(ctx, service.QueryRequest{
+			QueryID:    *queryID,
+			Query:      *query,
+			NetworkID:  *networkID,
+			SnapshotID: *snapshotID,
+			Parameters: parameters,
+			Limit:      *limit,
+			Offset:     *offset,
+			AllResults: *all,
+			Store:      *store,
+		})
+		if err != nil {
+			return err
+		}
+		if result.EntityID != "" {
+			fmt.Fprintf(stderr, "Stored %d rows as %s\n", len(result.Items), result.EntityID)
+		}
+		if result.Items == nil {
+			result.Items = []map[string]interface{}{}
+		}
+		return writeJSON(stdout, result.Items)
+	})
+}
+
+func hydrateCommand(cfg *config.Config, args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("hydrate", stderr)
+	workers := flags.Int("workers", 0, "Parallel workers fetching query details (default: 4, max: 16)")
+	asJSON := flags.Bool("json", false, "Print the summary as JSON")
+	timeout := flags.Duration("timeout", 10*time.Minute, "Maximum time for the sync")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	return withService(cfg, *timeout, func(ctx context.Context, svc *service.ForwardMCPService) error {
+		delta, err := svc.SyncQueries(ctx, *workers)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			return writeJSON(stdout, delta)
+		}
+		_, err = fmt.Fprint(stdout, delta.String())
+		return err
+	})
+}
+
+func exportTopologyCommand(cfg *config.Config, args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("export-topology", stderr)
+	networkID := flags.String("network", "", "Network ID (default: the configured network)")
+	snapshotID := flags.String("snapshot", "", "Snapshot ID (default: the latest snapshot)")
+	timeout := flags.Duration("timeout", 5*time.Minute, "Maximum time for the export")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	return withService(cfg, *timeout, func(ctx context.Context, svc *service.ForwardMCPService) error {
+		topology, err := svc.ExportTopology(ctx, *networkID, *snapshotID)
+		if err != nil {
+			return err
+		}
+		return writeJSON(stdout, topology)
+	})
+}
+
+func doctorCommand(cfg *config.Config, args []string, stdout, stderr io.Writer) error {
+	flags := newFlagSet("doctor", stderr)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	report, healthy := service.RunDoctor(cfg)
+	fmt.Fprint(stdout, report)
+	if !healthy {
+		return fmt.Errorf("one or more checks failed")
+	}
+	return nil
+}
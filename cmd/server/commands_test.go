@@ -0,0 +1,46 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+)
+
+func TestSubcommandName(t *testing.T) {
+	cases := map[string]string{"run-query": "run-query", "--doctor": "doctor", "--help": "help", "serve": "", "-v": ""}
+	for arg, expected := range cases {
+		if name := subcommandName(arg); name != expected {
+			t.Errorf("subcommandName(%q) = %q, expected %q", arg, name, expected)
+		}
+	}
+}
+
+func TestRunSubcommandUsage(t *testing.T) {
+	cfg := &config.Config{}
+	cases := []struct {
+		name     string
+		args     []string
+		exitCode int
+		output   string
+	}{
+		{"help", nil, 0, "export-topology"},
+		{"run-query", nil, 2, "Exactly one of -id and -query is required"},
+		{"run-query", []string{"-id", "FQ_1", "-query", "foreach d in network.devices select {}"}, 2, "Exactly one of -id and -query"},
+		{"run-query", []string{"-id", "FQ_1", "extra"}, 2, "Unexpected arguments"},
+		{"hydrate", []string{"-bogus"}, 2, "flag provided but not defined"},
+		{"run-query", []string{"-id", "FQ_1"}, 1, "Error: the Forward API base URL, key and secret are required"},
+		{"run-query", []string{"-id", "FQ_1", "-params", "{"}, 1, "invalid -params JSON"},
+	}
+	for _, tc := range cases {
+		var stdout, stderr bytes.Buffer
+		code := runSubcommand(cfg, tc.name, tc.args, &stdout, &stderr)
+		if code != tc.exitCode {
+			t.Errorf("%s %v: expected exit code %d, got %d", tc.name, tc.args, tc.exitCode, code)
+		}
+		if output := stdout.String() + stderr.String(); !strings.Contains(output, tc.output) {
+			t.Errorf("%s %v: expected %q in output, got: %s", tc.name, tc.args, tc.output, output)
+		}
+	}
+}
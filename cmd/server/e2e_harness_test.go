@@ -0,0 +1,572 @@
+//go:build integration
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The end-to-end suite runs the server binary over stdio against one of three backends,
+// chosen with FORWARD_E2E_MODE:
+//   - replay (default): a local sandbox answering from testdata/sandbox.json
+//   - live: the Forward instance in FORWARD_API_BASE_URL, FORWARD_API_KEY and FORWARD_API_SECRET
+//   - record: like live, but every exchange is saved to testdata/sandbox.json for replay
+const (
+	e2eModeReplay = "replay"
+	e2eModeLive   = "live"
+	e2eModeRecord = "record"
+
+	sandboxFixture = "testdata/sandbox.json"
+	e2eCallTimeout = 2 * time.Minute
+)
+
+// serverBinary is the server built once for the whole suite
+var serverBinary string
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "forward-mcp-e2e-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create build directory: %v\n", err)
+		os.Exit(1)
+	}
+	serverBinary = filepath.Join(dir, "forward-mcp-server")
+	build := exec.Command("go", "build", "-o", serverBinary, ".")
+	build.Stdout, build.Stderr = os.Stderr, os.Stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to build the server: %v\n", err)
+		os.RemoveAll(dir)
+		os.Exit(1)
+	}
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func e2eMode() string {
+	switch mode := strings.ToLower(os.Getenv("FORWARD_E2E_MODE")); mode {
+	case "", e2eModeReplay:
+		return e2eModeReplay
+	default:
+		return mode
+	}
+}
+
+// sandboxInteraction is one recorded Forward API exchange. When replaying, query and body
+// match when every key they list equals the request's; null matches a missing key.
+type sandboxInteraction struct {
+	Method   string            `json:"method"`
+	Path     string            `json:"path"`
+	Query    map[string]string `json:"query,omitempty"`
+	Body     interface{}       `json:"body,omitempty"`
+	Status   int               `json:"status"`
+	Response json.RawMessage   `json:"response"`
+}
+
+type sandboxFixtureFile struct {
+	Description  string               `json:"description,omitempty"`
+	NetworkID    string               `json:"network_id"`
+	QueryID      string               `json:"query_id"`
+	Source       string               `json:"source"`
+	Destination  string               `json:"destination"`
+	Interactions []sandboxInteraction `json:"interactions"`
+}
+
+// sandbox serves the Forward API to the server under test
+type sandbox struct {
+	*httptest.Server
+	t        *testing.T
+	fixture  sandboxFixtureFile
+	upstream string // Live API proxied in record mode
+
+	mutex     sync.Mutex
+	recorded  []sandboxInteraction
+	unmatched []string
+}
+
+// newSandbox starts a replaying or recording sandbox; live mode needs none
+func newSandbox(t *testing.T, mode string) *sandbox {
+	t.Helper()
+	s := &sandbox{t: t}
+	raw, err := os.ReadFile(sandboxFixture)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", sandboxFixture, err)
+	}
+	if err := json.Unmarshal(raw, &s.fixture); err != nil {
+		t.Fatalf("Failed to parse %s: %v", sandboxFixture, err)
+	}
+	if mode == e2eModeRecord {
+		s.upstream = strings.TrimRight(requireEnv(t, "FORWARD_API_BASE_URL"), "/")
+		t.Cleanup(s.save)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	t.Cleanup(func() {
+		for _, request := range s.unmatched {
+			t.Logf("No recorded response for %s", request)
+		}
+	})
+	return s
+}
+
+func (s *sandbox) handle(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var decoded interface{}
+	if len(body) > 0 {
+		json.Unmarshal(body, &decoded)
+	}
+	query := make(map[string]string)
+	for key, values := range r.URL.Query() {
+		query[key] = values[0]
+	}
+
+	if s.upstream != "" {
+		s.proxy(w, r, body, query, decoded)
+		return
+	}
+	if interaction := s.match(r.Method, r.URL.Path, query, decoded); interaction != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(interaction.Status)
+		w.Write(interaction.Response)
+		return
+	}
+	s.mutex.Lock()
+	s.unmatched = append(s.unmatched, fmt.Sprintf("%s %s %s", r.Method, r.URL.RequestURI(), body))
+	s.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	w.Write([]byte(`{"message": "no recorded response"}`))
+}
+
+// match prefers an interaction recorded for exactly this request over one listing a subset
+func (s *sandbox) match(method, path string, query map[string]string, body interface{}) *sandboxInteraction {
+	var subset *sandboxInteraction
+	for i := range s.fixture.Interactions {
+		interaction := &s.fixture.Interactions[i]
+		if interaction.Method != method || interaction.Path != path || !queryMatches(interaction.Query, query) ||
+			!jsonSubset(interaction.Body, body) {
+			continue
+		}
+		if len(interaction.Query) == len(query) && reflect.DeepEqual(interaction.Body, body) {
+			return interaction
+		}
+		if subset == nil {
+			subset = interaction
+		}
+	}
+	return subset
+}
+
+func queryMatches(want, got map[string]string) bool {
+	for key, value := range want {
+		if got[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonSubset reports whether every key of want has the same value in got
+func jsonSubset(want, got interface{}) bool {
+	switch want := want.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		object, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range want {
+			if value == nil {
+				if object[key] != nil {
+					return false
+				}
+				continue
+			}
+			if !jsonSubset(value, object[key]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		array, ok := got.([]interface{})
+		if !ok || len(array) != len(want) {
+			return false
+		}
+		for i := range want {
+			if !jsonSubset(want[i], array[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(want, got)
+	}
+}
+
+// proxy forwards a request to the live API and records the exchange without its headers
+func (s *sandbox) proxy(w http.ResponseWriter, r *http.Request, body []byte, query map[string]string, decoded interface{}) {
+	request, err := http.NewRequest(r.Method, s.upstream+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	request.Header = r.Header.Clone()
+	client := &http.Client{Timeout: e2eCallTimeout, Transport: &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: os.Getenv("FORWARD_INSECURE_SKIP_VERIFY") == "true"},
+	}}
+	response, err := client.Do(request)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer response.Body.Close()
+	payload, _ := io.ReadAll(response.Body)
+
+	if json.Valid(payload) {
+		if len(query) == 0 {
+			query = nil
+		}
+		s.mutex.Lock()
+		s.recorded = append(s.recorded, sandboxInteraction{Method: r.Method, Path: r.URL.Path, Query: query, Body: decoded,
+			Status: response.StatusCode, Response: json.RawMessage(payload)})
+		s.mutex.Unlock()
+	}
+	w.Header().Set("Content-Type", response.Header.Get("Content-Type"))
+	w.WriteHeader(response.StatusCode)
+	w.Write(payload)
+}
+
+// save writes the recorded exchanges, keeping the fixture's flow parameters
+func (s *sandbox) save() {
+	if s.t.Failed() {
+		s.t.Logf("Not saving %s: the recording run failed", sandboxFixture)
+		return
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	fixture := s.fixture
+	fixture.Interactions = s.recorded
+	raw, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		s.t.Errorf("Failed to encode recording: %v", err)
+		return
+	}
+	if err := os.WriteFile(sandboxFixture, append(raw, '\n'), 0644); err != nil {
+		s.t.Errorf("Failed to save recording: %v", err)
+		return
+	}
+	s.t.Logf("Recorded %d exchanges to %s", len(s.recorded), sandboxFixture)
+}
+
+func requireEnv(t *testing.T, name string) string {
+	t.Helper()
+	value := os.Getenv(name)
+	if value == "" {
+		t.Skipf("%s must be set in %s mode", name, e2eMode())
+	}
+	return value
+}
+
+// e2eEnvironment describes the Forward instance and data the flows run against
+type e2eEnvironment struct {
+	NetworkID   string
+	QueryID     string
+	Source      string
+	Destination string
+	Replay      bool
+}
+
+// e2eEnvironmentFor returns the flow parameters: from the fixture when replaying, otherwise
+// from FORWARD_E2E_NETWORK_ID, FORWARD_E2E_QUERY_ID, FORWARD_E2E_SOURCE and
+// FORWARD_E2E_DESTINATION. A recording keeps them in the fixture for later replays.
+func e2eEnvironmentFor(t *testing.T, mode string, sandbox *sandbox) e2eEnvironment {
+	t.Helper()
+	if mode == e2eModeReplay {
+		fixture := sandbox.fixture
+		return e2eEnvironment{NetworkID: fixture.NetworkID, QueryID: fixture.QueryID, Source: fixture.Source,
+			Destination: fixture.Destination, Replay: true}
+	}
+	env := e2eEnvironment{
+		NetworkID:   requireEnv(t, "FORWARD_E2E_NETWORK_ID"),
+		QueryID:     requireEnv(t, "FORWARD_E2E_QUERY_ID"),
+		Source:      requireEnv(t, "FORWARD_E2E_SOURCE"),
+		Destination: requireEnv(t, "FORWARD_E2E_DESTINATION"),
+	}
+	if sandbox != nil {
+		sandbox.fixture.NetworkID, sandbox.fixture.QueryID = env.NetworkID, env.QueryID
+		sandbox.fixture.Source, sandbox.fixture.Destination = env.Source, env.Destination
+	}
+	return env
+}
+
+// mcpServer is a running server binary spoken to over stdio
+type mcpServer struct {
+	t        *testing.T
+	cmd      *exec.Cmd
+	stdin    io.WriteCloser
+	lines    chan []byte
+	stderr   *bytes.Buffer
+	nextID   int
+	Env      e2eEnvironment
+	Sandbox  *sandbox
+	mutex    sync.Mutex
+	finished chan struct{}
+}
+
+// startServer runs the server binary in an isolated home, data and lock directory, pointed at
+// the sandbox (replay and record) or the live instance, and completes the MCP handshake
+func startServer(t *testing.T) *mcpServer {
+	t.Helper()
+	mode := e2eMode()
+	home := t.TempDir()
+	env := []string{
+		"HOME=" + home,
+		"PATH=" + os.Getenv("PATH"),
+		"FORWARD_DATA_DIR=" + filepath.Join(home, "data"),
+		"FORWARD_LOCK_DIR=" + home,
+		"FORWARD_MCP_LOG_FILE=" + filepath.Join(home, "server.log"),
+		"FORWARD_EMBEDDING_PROVIDER=keyword",
+	}
+
+	server := &mcpServer{t: t, lines: make(chan []byte, 16), stderr: &bytes.Buffer{}, finished: make(chan struct{})}
+	switch mode {
+	case e2eModeReplay, e2eModeRecord:
+		server.Sandbox = newSandbox(t, mode)
+		apiKey, apiSecret := "sandbox-key", "sandbox-secret"
+		if mode == e2eModeRecord {
+			apiKey, apiSecret = requireEnv(t, "FORWARD_API_KEY"), requireEnv(t, "FORWARD_API_SECRET")
+		}
+		env = append(env, "FORWARD_API_BASE_URL="+server.Sandbox.URL, "FORWARD_API_KEY="+apiKey, "FORWARD_API_SECRET="+apiSecret)
+	case e2eModeLive:
+		for _, name := range []string{"FORWARD_API_BASE_URL", "FORWARD_API_KEY", "FORWARD_API_SECRET"} {
+			env = append(env, name+"="+requireEnv(t, name))
+		}
+		env = append(env, "FORWARD_INSECURE_SKIP_VERIFY="+os.Getenv("FORWARD_INSECURE_SKIP_VERIFY"))
+	default:
+		t.Fatalf("Unknown FORWARD_E2E_MODE %q; use replay, live or record", mode)
+	}
+	server.Env = e2eEnvironmentFor(t, mode, server.Sandbox)
+
+	server.cmd = exec.Command(serverBinary)
+	server.cmd.Dir = home // No .env or config.json of the developer
+	server.cmd.Env = env
+	server.cmd.Stderr = server.stderr
+	stdin, err := server.cmd.StdinPipe()
+	if err != nil {
+		t.Fatalf("Failed to open stdin: %v", err)
+	}
+	stdout, err := server.cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to open stdout: %v", err)
+	}
+	server.stdin = stdin
+	if err := server.cmd.Start(); err != nil {
+		t.Fatalf("Failed to start the server: %v", err)
+	}
+	go func() {
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 0, 1<<20), 64<<20)
+		for scanner.Scan() {
+			server.lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+		close(server.lines)
+	}()
+	go func() {
+		server.cmd.Wait()
+		close(server.finished)
+	}()
+	t.Cleanup(server.stop)
+
+	server.call(t, "initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "forward-mcp-e2e", "version": "1.0.0"},
+	})
+	server.notify("notifications/initialized")
+	return server
+}
+
+func (s *mcpServer) stop() {
+	s.stdin.Close()
+	s.cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-s.finished:
+	case <-time.After(10 * time.Second):
+		s.cmd.Process.Kill()
+		<-s.finished
+	}
+	if s.t.Failed() {
+		s.t.Logf("Server stderr:\n%s", s.stderr.String())
+	}
+}
+
+func (s *mcpServer) send(message map[string]interface{}) {
+	raw, err := json.Marshal(message)
+	if err != nil {
+		s.t.Fatalf("Failed to encode %v: %v", message, err)
+	}
+	if _, err := s.stdin.Write(append(raw, '\n')); err != nil {
+		s.t.Fatalf("Failed to write to the server: %v", err)
+	}
+}
+
+func (s *mcpServer) notify(method string) {
+	s.send(map[string]interface{}{"jsonrpc": "2.0", "method": method})
+}
+
+// rpcError is a JSON-RPC error response
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message) }
+
+// request sends a JSON-RPC request and waits for its response, skipping notifications
+func (s *mcpServer) request(method string, params interface{}) (json.RawMessage, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.nextID++
+	id := s.nextID
+	s.send(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+
+	timeout := time.After(e2eCallTimeout)
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				return nil, fmt.Errorf("server exited during %s", method)
+			}
+			var response struct {
+				ID     *int            `json:"id"`
+				Result json.RawMessage `json:"result"`
+				Error  *rpcError       `json:"error"`
+			}
+			if err := json.Unmarshal(line, &response); err != nil {
+				return nil, fmt.Errorf("invalid message from the server: %s", line)
+			}
+			if response.ID == nil || *response.ID != id {
+				continue
+			}
+			if response.Error != nil {
+				return nil, response.Error
+			}
+			return response.Result, nil
+		case <-timeout:
+			return nil, fmt.Errorf("no response to %s within %s", method, e2eCallTimeout)
+		}
+	}
+}
+
+func (s *mcpServer) call(t *testing.T, method string, params interface{}) json.RawMessage {
+	t.Helper()
+	result, err := s.request(method, params)
+	if err != nil {
+		t.Fatalf("%s failed: %v", method, err)
+	}
+	return result
+}
+
+// toolResult is the result of a tools/call request
+type toolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+// Text joins the text blocks of the result
+func (r toolResult) Text() string {
+	var texts []string
+	for _, content := range r.Content {
+		if content.Type == "text" {
+			texts = append(texts, content.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// jsonDocuments returns the JSON documents of the result: text blocks, lines, or the rest of a
+// block from a line where a document starts
+func (r toolResult) jsonDocuments() []json.RawMessage {
+	var documents []json.RawMessage
+	for _, content := range r.Content {
+		lines := strings.Split(content.Text, "\n")
+		for i, line := range lines {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "{") && !strings.HasPrefix(line, "[") {
+				continue
+			}
+			for _, candidate := range []string{line, strings.TrimSpace(strings.Join(lines[i:], "\n"))} {
+				if json.Valid([]byte(candidate)) {
+					documents = append(documents, json.RawMessage(candidate))
+					break
+				}
+			}
+		}
+	}
+	return documents
+}
+
+// JSONField decodes the value of key from the first JSON object of the result holding it
+func (r toolResult) JSONField(key string, v interface{}) error {
+	for _, document := range r.jsonDocuments() {
+		var object map[string]json.RawMessage
+		if json.Unmarshal(document, &object) == nil && object[key] != nil {
+			return json.Unmarshal(object[key], v)
+		}
+	}
+	return fmt.Errorf("no JSON object with %q in %q", key, r.Text())
+}
+
+// JSONArray decodes the first JSON array of the result
+func (r toolResult) JSONArray(v interface{}) error {
+	for _, document := range r.jsonDocuments() {
+		if strings.HasPrefix(string(document), "[") {
+			return json.Unmarshal(document, v)
+		}
+	}
+	return fmt.Errorf("no JSON array in %q", r.Text())
+}
+
+// callTool calls a tool and fails the test when the call or the tool fails
+func (s *mcpServer) callTool(t *testing.T, name string, arguments map[string]interface{}) toolResult {
+	t.Helper()
+	result, err := s.tryTool(name, arguments)
+	if err != nil {
+		t.Fatalf("%s failed: %v", name, err)
+	}
+	return result
+}
+
+// tryTool calls a tool, returning tool failures as errors
+func (s *mcpServer) tryTool(name string, arguments map[string]interface{}) (toolResult, error) {
+	raw, err := s.request("tools/call", map[string]interface{}{"name": name, "arguments": arguments})
+	if err != nil {
+		return toolResult{}, err
+	}
+	var result toolResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return toolResult{}, fmt.Errorf("invalid tool result %s: %w", raw, err)
+	}
+	if result.IsError {
+		return result, fmt.Errorf("tool error: %s", result.Text())
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+//go:build integration
+
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+var (
+	storedEntityPattern = regexp.MustCompile(`Stored in memory system as entity: (\S+)`)
+	totalItemsPattern   = regexp.MustCompile(`Total items: (\d+)`)
+)
+
+// TestEndToEnd runs the major tool flows against one server, in order, since later flows
+// analyze what earlier ones stored
+func TestEndToEnd(t *testing.T) {
+	server := startServer(t)
+	env := server.Env
+
+	t.Run("ListTools", func(t *testing.T) {
+		var tools struct {
+			Tools []struct {
+				Name        string          `json:"name"`
+				InputSchema json.RawMessage `json:"inputSchema"`
+			} `json:"tools"`
+		}
+		if err := json.Unmarshal(server.call(t, "tools/list", map[string]interface{}{}), &tools); err != nil {
+			t.Fatalf("Invalid tools/list result: %v", err)
+		}
+		names := make(map[string]bool)
+		for _, tool := range tools.Tools {
+			names[tool.Name] = true
+			if !json.Valid(tool.InputSchema) {
+				t.Errorf("Tool %s has an invalid input schema", tool.Name)
+			}
+		}
+		for _, name := range []string{"list_devices", "search_paths", "run_nqe_query_by_id", "analyze_nqe_result_sql"} {
+			if !names[name] {
+				t.Errorf("Expected %s among %d tools", name, len(tools.Tools))
+			}
+		}
+	})
+
+	t.Run("Inventory", func(t *testing.T) {
+		result := server.callTool(t, "list_devices", map[string]interface{}{"network_id": env.NetworkID, "limit": 10})
+		var pagination struct {
+			Total    int  `json:"total"`
+			Returned int  `json:"returned"`
+			HasMore  bool `json:"has_more"`
+		}
+		if err := result.JSONField("pagination", &pagination); err != nil {
+			t.Fatalf("Expected pagination metadata: %v", err)
+		}
+		if pagination.Returned == 0 || pagination.Returned > 10 || pagination.Total < pagination.Returned {
+			t.Errorf("Unexpected pagination %+v in: %s", pagination, result.Text())
+		}
+	})
+
+	t.Run("PathSearch", func(t *testing.T) {
+		result := server.callTool(t, "search_paths", map[string]interface{}{
+			"network_id": env.NetworkID, "src_ip": env.Source, "dst_ip": env.Destination, "max_results": 1,
+		})
+		var searches []struct {
+			TimedOut bool `json:"timedOut"`
+			Info     struct {
+				Paths []struct {
+					ForwardingOutcome string `json:"forwardingOutcome"`
+					Hops              []struct {
+						DeviceName string `json:"deviceName"`
+					} `json:"hops"`
+				} `json:"paths"`
+			} `json:"info"`
+		}
+		if err := result.JSONArray(&searches); err != nil {
+			t.Fatalf("Expected the path search results: %v", err)
+		}
+		if len(searches) != 1 || searches[0].TimedOut || len(searches[0].Info.Paths) == 0 {
+			t.Fatalf("Expected one completed search with paths, got %+v", searches)
+		}
+		for _, path := range searches[0].Info.Paths {
+			if path.ForwardingOutcome == "" || len(path.Hops) == 0 || path.Hops[0].DeviceName == "" {
+				t.Errorf("Expected an outcome and hops on every path, got %+v", path)
+			}
+		}
+	})
+
+	var entityID string
+	var rows int
+	t.Run("NQEAllResults", func(t *testing.T) {
+		result := server.callTool(t, "run_nqe_query_by_id", map[string]interface{}{
+			"network_id": env.NetworkID, "query_id": env.QueryID, "all_results": true,
+			"options": map[string]interface{}{"limit": 2}, "concurrency": 1,
+		})
+		text := result.Text()
+		match := storedEntityPattern.FindStringSubmatch(text)
+		if match == nil {
+			t.Fatalf("Expected the stored result entity in: %s", text)
+		}
+		entityID = match[1]
+		if count := totalItemsPattern.FindStringSubmatch(text); count != nil {
+			rows, _ = strconv.Atoi(count[1])
+		}
+		if rows < 3 {
+			t.Errorf("Expected the rows of several batches, got %d in: %s", rows, text)
+		}
+	})
+
+	t.Run("MemorySQL", func(t *testing.T) {
+		if entityID == "" {
+			t.Skip("No stored result to analyze")
+		}
+		result := server.callTool(t, "analyze_nqe_result_sql", map[string]interface{}{
+			"entity_id": entityID, "sql_query": "SELECT COUNT(*) AS row_count FROM nqe_result",
+		})
+		var counts []struct {
+			RowCount int `json:"row_count"`
+		}
+		if err := result.JSONArray(&counts); err != nil {
+			t.Fatalf("Expected SQL rows: %v", err)
+		}
+		if len(counts) != 1 || counts[0].RowCount != rows {
+			t.Errorf("Expected the stored result to hold the %d fetched rows, got %+v", rows, counts)
+		}
+
+		if _, err := server.tryTool("analyze_nqe_result_sql", map[string]interface{}{
+			"entity_id": entityID, "sql_query": "DROP TABLE nqe_result",
+		}); err == nil {
+			t.Error("Expected the SQL sandbox to reject writes")
+		}
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
@@ -11,6 +12,7 @@ import (
 	"github.com/forward-mcp/internal/logger"
 	"github.com/forward-mcp/internal/service"
 	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
 	"github.com/metoro-io/mcp-golang/transport/stdio"
 )
 
@@ -21,6 +23,13 @@ func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
+	// Subcommands (and --doctor) run a single operation and exit without starting the server
+	if len(os.Args) > 1 {
+		if name := subcommandName(os.Args[1]); name != "" {
+			os.Exit(runSubcommand(cfg, name, os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	// Create logger
 	logger.Info("Forward MCP Server starting...")
 
@@ -82,10 +91,27 @@ func main() {
 	logger.Debug("Creating Forward MCP service...")
 	forwardService := service.NewForwardMCPService(cfg, logger)
 
-	// Create MCP server with stdio transport for Claude Desktop compatibility
-	logger.Debug("Creating MCP server with stdio transport...")
-	transport := stdio.NewStdioServerTransport()
-	server := mcp.NewServer(transport)
+	// Create MCP server with stdio transport for Claude Desktop compatibility, or with the
+	// API key authenticated HTTP transport when running as a shared service
+	var serverTransport transport.Transport
+	if cfg.Server.Transport == "http" {
+		authenticator, err := service.NewAPIKeyAuthenticator(cfg.Server.APIKeys)
+		if err != nil {
+			logger.Fatalf("Invalid API key configuration: %v", err)
+		}
+		if authenticator.Len() == 0 {
+			logger.Fatalf("The HTTP transport requires at least one API key (set SERVER_API_KEYS_FILE)")
+		}
+		addr := fmt.Sprintf("%s:%d", cfg.Server.Host, cfg.Server.Port)
+		logger.Info("Serving MCP over HTTP at %s%s with %d API keys", addr, cfg.Server.HTTPPath, authenticator.Len())
+		serverTransport = service.NewHTTPTransport(addr, cfg.Server.HTTPPath, authenticator, logger)
+	} else {
+		logger.Debug("Creating MCP server with stdio transport...")
+		serverTransport = stdio.NewStdioServerTransport()
+	}
+	// Tool calls may pass preset: <name> to reuse a saved bundle of arguments and
+	// verbosity: verbose, normal or terse to control presentation text
+	server := mcp.NewServer(forwardService.WithArgumentPresets(serverTransport))
 
 	// Register all Forward Networks tools
 	logger.Debug("Registering Forward Networks tools...")
@@ -108,6 +134,11 @@ func main() {
 	}
 	logger.Debug("Contextual resources registered successfully!")
 
+	// Start validating new snapshots when the snapshot pipeline polls or has a webhook
+	if err := forwardService.StartSnapshotPipeline(); err != nil {
+		logger.Fatalf("Failed to start the snapshot pipeline: %v", err)
+	}
+
 	// Check if we're in a TTY (interactive mode) or pipe mode
 	if fileInfo, _ := os.Stdin.Stat(); (fileInfo.Mode() & os.ModeCharDevice) != 0 {
 		logger.Debug("Running in interactive mode (TTY detected)")
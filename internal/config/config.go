@@ -22,6 +22,26 @@ type Config struct {
 type ServerConfig struct {
 	Port int
 	Host string
+
+	// Transport serving MCP: "stdio" (default) or "http"
+	Transport string `json:"transport" env:"SERVER_TRANSPORT"`
+	HTTPPath  string `json:"httpPath" env:"SERVER_HTTP_PATH"`
+
+	// API keys accepted by the HTTP transport, with per-key scopes
+	APIKeys     []APIKeyConfig `json:"apiKeys"`
+	APIKeysFile string         `json:"apiKeysFile" env:"SERVER_API_KEYS_FILE"`
+}
+
+// APIKeyConfig is an API key accepted by the HTTP transport. Set either Key or KeySHA256
+// (hex SHA-256 of the key) so plaintext keys need not be kept in config files. Empty scope
+// lists allow everything; ReadOnly blocks tools that change state.
+type APIKeyConfig struct {
+	ID         string   `json:"id"` // Identity recorded in audit logs
+	Key        string   `json:"key,omitempty"`
+	KeySHA256  string   `json:"keySha256,omitempty"`
+	ReadOnly   bool     `json:"readOnly"`
+	Networks   []string `json:"networks,omitempty"`   // Network IDs the key may access
+	ToolGroups []string `json:"toolGroups,omitempty"` // Tool groups the key may call
 }
 
 // ForwardConfig holds Forward Networks API configuration
@@ -36,6 +56,11 @@ type ForwardConfig struct {
 	// Instance Configuration
 	InstanceID string `json:"instanceId" env:"FORWARD_INSTANCE_ID"`
 
+	// Network Access Configuration: network IDs the server exposes (empty allows all) and
+	// network IDs it hides; denied networks win over allowed ones
+	AllowedNetworks []string `json:"allowedNetworks" env:"FORWARD_ALLOWED_NETWORKS"`
+	DeniedNetworks  []string `json:"deniedNetworks" env:"FORWARD_DENIED_NETWORKS"`
+
 	// TLS Configuration
 	InsecureSkipVerify bool   `json:"insecureSkipVerify" env:"FORWARD_INSECURE_SKIP_VERIFY"`
 	CACertPath         string `json:"caCertPath" env:"FORWARD_CA_CERT_PATH"`
@@ -43,8 +68,209 @@ type ForwardConfig struct {
 	ClientKeyPath      string `json:"clientKeyPath" env:"FORWARD_CLIENT_KEY_PATH"`
 	Timeout            int    `json:"timeout" env:"FORWARD_TIMEOUT"`
 
+	// Compression Configuration
+	GzipResponses    bool `json:"gzipResponses" env:"FORWARD_GZIP_RESPONSES"`
+	CompressRequests bool `json:"compressRequests" env:"FORWARD_COMPRESS_REQUESTS"`
+
+	// Connection Pool Configuration
+	EnableHTTP2            bool `json:"enableHttp2" env:"FORWARD_HTTP2"`
+	MaxIdleConns           int  `json:"maxIdleConns" env:"FORWARD_MAX_IDLE_CONNS"`
+	MaxIdleConnsPerHost    int  `json:"maxIdleConnsPerHost" env:"FORWARD_MAX_IDLE_CONNS_PER_HOST"`
+	IdleConnTimeoutSeconds int  `json:"idleConnTimeoutSeconds" env:"FORWARD_IDLE_CONN_TIMEOUT_SECONDS"`
+
+	// API Debug Capture Configuration: "errors" or "full" logs sanitized request and response
+	// bodies to a rotating file; failed requests are always kept for get_last_api_errors
+	APIDebug             string `json:"apiDebug" env:"FORWARD_API_DEBUG"`
+	APIDebugFile         string `json:"apiDebugFile" env:"FORWARD_API_DEBUG_FILE"`
+	APIDebugMaxBodyBytes int    `json:"apiDebugMaxBodyBytes" env:"FORWARD_API_DEBUG_MAX_BODY_BYTES"`
+	APIDebugMaxFileMB    int    `json:"apiDebugMaxFileMB" env:"FORWARD_API_DEBUG_MAX_FILE_MB"`
+
+	// Display Configuration
+	DisplayTimezone string `json:"displayTimezone" env:"FORWARD_DISPLAY_TZ"`
+
 	// Semantic Cache Configuration
 	SemanticCache SemanticCacheConfig `json:"semanticCache"`
+
+	// Vendor Normalization Configuration
+	VendorMappings     []VendorMappingRule `json:"vendorMappings"`
+	VendorMappingsFile string              `json:"vendorMappingsFile" env:"FORWARD_VENDOR_MAPPINGS_FILE"`
+
+	// Stored Result Configuration: target serialized size of each stored result chunk
+	ChunkTargetBytes int `json:"chunkTargetBytes" env:"FORWARD_CHUNK_TARGET_BYTES"`
+
+	// SQL Sandbox Configuration for analyze_nqe_result_sql
+	SQLTimeoutSeconds int `json:"sqlTimeoutSeconds" env:"FORWARD_SQL_TIMEOUT_SECONDS"`
+	SQLMaxMemoryMB    int `json:"sqlMaxMemoryMB" env:"FORWARD_SQL_MAX_MEMORY_MB"`
+
+	// DuckDB CLI used by export_to_duckdb; looked up on PATH when empty
+	DuckDBPath string `json:"duckdbPath" env:"FORWARD_DUCKDB_PATH"`
+
+	// Output Redaction Configuration
+	Redaction RedactionConfig `json:"redaction"`
+
+	// Response Verbosity: verbose (default), normal or terse; a tool call's verbosity argument wins
+	Verbosity string `json:"verbosity" env:"FORWARD_VERBOSITY"`
+
+	// Report Rendering Configuration
+	Reports ReportConfig `json:"reports"`
+
+	// Probe Configuration for verify_with_probes on path searches
+	Probes ProbeConfig `json:"probes"`
+
+	// Result Summarization Configuration for summarize_result
+	Summarization SummarizationConfig `json:"summarization"`
+
+	// Federation Configuration: further Forward instances queried by the federated tools
+	Federation     []FederatedInstanceConfig `json:"federation"`
+	FederationFile string                    `json:"federationFile" env:"FORWARD_FEDERATION_FILE"`
+
+	// Feature Flags controlling which tools are registered
+	Features FeatureFlagConfig `json:"features"`
+
+	// Session Transcript Configuration: record tool calls to memory from the first call
+	// instead of waiting for start_session_transcript
+	SessionTranscript bool `json:"sessionTranscript" env:"FORWARD_SESSION_TRANSCRIPT"`
+
+	// Snapshot Pinning Configuration: keep calls that omit snapshot_id on the snapshot the
+	// first call of the analysis session used, without waiting for start_analysis_session
+	SnapshotPinning bool `json:"snapshotPinning" env:"FORWARD_SNAPSHOT_PINNING"`
+
+	// Snapshot Freshness Configuration for the stale snapshot warning on analysis tools
+	SnapshotFreshness SnapshotFreshnessConfig `json:"snapshotFreshness"`
+
+	// Snapshot Pipeline Configuration: run a playbook on every new snapshot of the watched
+	// networks, found by polling or announced through the webhook listener
+	SnapshotPipeline SnapshotPipelineConfig `json:"snapshotPipeline"`
+
+	// Interface Description Conventions for check_interface_hygiene: regex templates by
+	// site, then by interface role; the "*" site applies wherever a site sets no template
+	InterfaceConventions map[string]map[string]string `json:"interfaceConventions"`
+
+	// Memory Trash Configuration: hours a deleted entity stays restorable before it is
+	// permanently removed
+	TrashRetentionHours int `json:"trashRetentionHours" env:"FORWARD_TRASH_RETENTION_HOURS"`
+
+	// Prefetch Configuration: warm data a tool call is usually followed by, with at most
+	// PrefetchPerMinute background API calls
+	Prefetch          bool `json:"prefetch" env:"FORWARD_PREFETCH"`
+	PrefetchPerMinute int  `json:"prefetchPerMinute" env:"FORWARD_PREFETCH_PER_MINUTE"`
+
+	// NQE Fetch Concurrency: batches of an all_results query fetched in parallel, unless a
+	// call sets its own concurrency
+	NQEFetchConcurrency int `json:"nqeFetchConcurrency" env:"FORWARD_NQE_FETCH_CONCURRENCY"`
+}
+
+// FeatureFlagConfig controls tool registration. Experimental tools have flags named
+// "experimental/<tool>" and stay unregistered unless enabled; "experimental/*" enables all of
+// them. Disabled entries name tools or flags to leave out and win over enabled ones.
+type FeatureFlagConfig struct {
+	Enabled  []string `json:"enabled" env:"FORWARD_ENABLED_FEATURES"`
+	Disabled []string `json:"disabled" env:"FORWARD_DISABLED_FEATURES"`
+}
+
+// ReportConfig controls where rendered reports are saved, which templates override the
+// built-in ones and which headless converter produces PDFs
+type ReportConfig struct {
+	Directory    string `json:"directory" env:"FORWARD_REPORT_DIR"`            // Defaults to <data dir>/reports
+	TemplateDir  string `json:"templateDir" env:"FORWARD_REPORT_TEMPLATE_DIR"` // report.md.tmpl / report.html.tmpl overrides
+	PDFConverter string `json:"pdfConverter" env:"FORWARD_PDF_CONVERTER"`      // Command with {input} and {output} placeholders
+}
+
+// ProbeConfig controls where verify_with_probes sends real ICMP/TCP probes: through an
+// external probe API, or from a probe host reached over SSH ("local" probes from the server
+// itself). The API wins when both are set.
+type ProbeConfig struct {
+	Host           string `json:"host" env:"FORWARD_PROBE_HOST"`
+	URL            string `json:"url" env:"FORWARD_PROBE_URL"`
+	Count          int    `json:"count" env:"FORWARD_PROBE_COUNT"`                    // Probes per destination
+	TimeoutSeconds int    `json:"timeoutSeconds" env:"FORWARD_PROBE_TIMEOUT_SECONDS"` // Wait per probe
+}
+
+// SummarizationConfig controls the chat model summarize_result sends result samples to.
+// Summarization is off until a provider is set; the OpenAI provider reads OPENAI_API_KEY.
+type SummarizationConfig struct {
+	Provider          string `json:"provider" env:"FORWARD_SUMMARY_PROVIDER"`                     // "openai", or empty to disable
+	Model             string `json:"model" env:"FORWARD_SUMMARY_MODEL"`                           // Chat model name
+	URL               string `json:"url" env:"FORWARD_SUMMARY_URL"`                               // Chat completions endpoint
+	RequestsPerMinute int    `json:"requestsPerMinute" env:"FORWARD_SUMMARY_REQUESTS_PER_MINUTE"` // Requests allowed per minute
+	SampleRows        int    `json:"sampleRows" env:"FORWARD_SUMMARY_SAMPLE_ROWS"`                // Rows sent per summary
+}
+
+// SnapshotFreshnessConfig controls the warning added to analysis and inventory tool responses
+// when their snapshot is older than a threshold or a newer snapshot exists. A threshold of 0
+// turns the warning off.
+type SnapshotFreshnessConfig struct {
+	MaxAgeHours float64            `json:"maxAgeHours" env:"FORWARD_SNAPSHOT_MAX_AGE_HOURS"` // Age after which a snapshot is stale
+	Networks    map[string]float64 `json:"networks" env:"FORWARD_SNAPSHOT_MAX_AGE_NETWORKS"` // Thresholds by network ID, overriding MaxAgeHours
+}
+
+// SnapshotPipelineConfig controls the playbook run automatically when a network has a new
+// processed snapshot. New snapshots are found by polling every PollIntervalMinutes (0 turns
+// polling off) or announced by POSTs to the webhook listener at WebhookAddr.
+type SnapshotPipelineConfig struct {
+	Networks            []string         `json:"networks" env:"FORWARD_PIPELINE_NETWORKS"`                // Watched networks; the default network when empty
+	PollIntervalMinutes int              `json:"pollIntervalMinutes" env:"FORWARD_PIPELINE_POLL_MINUTES"` // Minutes between snapshot checks
+	WebhookAddr         string           `json:"webhookAddr" env:"FORWARD_PIPELINE_WEBHOOK_ADDR"`         // Listen address of the webhook, e.g. ":8090"
+	WebhookSecret       string           `json:"webhookSecret" env:"FORWARD_PIPELINE_WEBHOOK_SECRET"`     // Bearer token webhook calls must present
+	NotifyURL           string           `json:"notifyUrl" env:"FORWARD_PIPELINE_NOTIFY_URL"`             // Receives every run as a JSON POST
+	ReportFormat        string           `json:"reportFormat" env:"FORWARD_PIPELINE_REPORT_FORMAT"`       // Also save a rendered report of every run
+	Playbook            PipelinePlaybook `json:"playbook"`
+}
+
+// PipelinePlaybook lists the checks of a snapshot pipeline run
+type PipelinePlaybook struct {
+	Assertions []PipelineQuery `json:"assertions"` // Queries that may return at most MaxRows rows
+	KeyQueries []PipelineQuery `json:"keyQueries"` // Queries stored and diffed with the previous snapshot
+	KeyPaths   string          `json:"keyPaths"`   // Flows as src>dst[:port][=allow|deny] separated by semicolons
+}
+
+// PipelineQuery is an NQE query of a playbook, given by ID or by source
+type PipelineQuery struct {
+	Name       string   `json:"name"`
+	QueryID    string   `json:"queryId"`
+	Query      string   `json:"query"`
+	MaxRows    int      `json:"maxRows"`    // Assertions: violating rows allowed
+	KeyColumns []string `json:"keyColumns"` // Key queries: columns identifying a row across snapshots
+}
+
+// FederatedInstanceConfig is a further Forward instance the federated tools query. Each
+// instance has its own client; TLS and connection settings are shared with the primary one.
+type FederatedInstanceConfig struct {
+	Name               string `json:"name"`                         // Value of the instance column in merged results
+	APIBaseURL         string `json:"apiBaseUrl"`                   // e.g. https://fwd.emea.example.com
+	APIKey             string `json:"apiKey"`                       // Access key of the instance
+	APISecret          string `json:"apiSecret"`                    // Secret key of the instance
+	NetworkID          string `json:"networkId"`                    // Network queried on the instance
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"` // Overrides the primary TLS setting
+}
+
+// RedactionConfig controls masking of sensitive values in tool and prompt output
+type RedactionConfig struct {
+	Enabled bool `json:"enabled" env:"FORWARD_REDACTION"`
+
+	// Custom patterns applied after the built-in ones
+	Patterns []RedactionPattern `json:"patterns" env:"FORWARD_REDACTION_PATTERNS"`
+
+	// Values left unredacted, keyed by pattern name ("*" applies to every pattern)
+	Allowlist map[string][]string `json:"allowlist" env:"FORWARD_REDACTION_ALLOWLIST"`
+}
+
+// RedactionPattern is a named regular expression. When the expression has a capture group
+// only the first group is masked, so surrounding keywords stay readable.
+type RedactionPattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// VendorMappingRule maps vendor-specific device facts onto the canonical schema.
+// Match is a case-insensitive regular expression tested against the combined vendor,
+// platform, OS and model fields. Custom rules are evaluated before the built-in ones.
+type VendorMappingRule struct {
+	Match   string `json:"match"`
+	Vendor  string `json:"vendor"`
+	Family  string `json:"family"`
+	OSTrain string `json:"osTrain,omitempty"` // Overrides the train derived from the OS version
+	EOLKey  string `json:"eolKey,omitempty"`  // Overrides the default vendor:family:train key
 }
 
 // CacheEvictionPolicy defines the eviction strategy
@@ -67,6 +293,10 @@ type SemanticCacheConfig struct {
 	SimilarityThreshold float64 `json:"similarityThreshold" env:"FORWARD_SEMANTIC_CACHE_SIMILARITY_THRESHOLD"`
 	EmbeddingProvider   string  `json:"embeddingProvider" env:"FORWARD_EMBEDDING_PROVIDER"`
 
+	// Per-category similarity thresholds keyed by query category (e.g. "Security": 0.95).
+	// Categories without an entry use SimilarityThreshold.
+	CategoryThresholds map[string]float64 `json:"categoryThresholds" env:"FORWARD_SEMANTIC_CACHE_CATEGORY_THRESHOLDS"`
+
 	// Enhanced cache configuration for large API results
 	MaxMemoryMB      int                 `json:"maxMemoryMB" env:"FORWARD_SEMANTIC_CACHE_MAX_MEMORY_MB"`
 	EvictionPolicy   CacheEvictionPolicy `json:"evictionPolicy" env:"FORWARD_SEMANTIC_CACHE_EVICTION_POLICY"`
@@ -79,6 +309,12 @@ type SemanticCacheConfig struct {
 	// Eviction thresholds
 	MemoryEvictionThreshold float64 `json:"memoryEvictionThreshold" env:"FORWARD_SEMANTIC_CACHE_MEMORY_THRESHOLD"`
 	CleanupIntervalMinutes  int     `json:"cleanupIntervalMinutes" env:"FORWARD_SEMANTIC_CACHE_CLEANUP_INTERVAL"`
+
+	// Negative caching of recent query and network failures (0 disables)
+	NegativeTTLSeconds int `json:"negativeTTLSeconds" env:"FORWARD_NEGATIVE_CACHE_TTL_SECONDS"`
+
+	// Device inventory cache TTL for the latest snapshot in seconds (0 disables the cache)
+	DeviceCacheTTLSeconds int `json:"deviceCacheTTLSeconds" env:"FORWARD_DEVICE_CACHE_TTL_SECONDS"`
 }
 
 // MCPConfig holds MCP-specific configuration
@@ -94,27 +330,96 @@ func LoadConfig() *Config {
 
 	config := &Config{
 		Server: ServerConfig{
-			Port: getEnvAsInt("SERVER_PORT", 8080),
-			Host: getEnv("SERVER_HOST", "0.0.0.0"),
+			Port:        getEnvAsInt("SERVER_PORT", 8080),
+			Host:        getEnv("SERVER_HOST", "0.0.0.0"),
+			Transport:   getEnv("SERVER_TRANSPORT", "stdio"),
+			HTTPPath:    getEnv("SERVER_HTTP_PATH", "/mcp"),
+			APIKeysFile: getEnv("SERVER_API_KEYS_FILE", ""),
 		},
 		Forward: ForwardConfig{
-			APIKey:             getEnv("FORWARD_API_KEY", ""),
-			APISecret:          getEnv("FORWARD_API_SECRET", ""),
-			APIBaseURL:         getEnv("FORWARD_API_BASE_URL", ""),
-			Timeout:            getEnvAsInt("FORWARD_TIMEOUT", 600), // 10 minutes for enhanced API operations
-			InsecureSkipVerify: getEnvAsBool("FORWARD_INSECURE_SKIP_VERIFY", false),
-			CACertPath:         getEnv("FORWARD_CA_CERT_PATH", ""),
-			ClientCertPath:     getEnv("FORWARD_CLIENT_CERT_PATH", ""),
-			ClientKeyPath:      getEnv("FORWARD_CLIENT_KEY_PATH", ""),
-			DefaultNetworkID:   getEnv("FORWARD_DEFAULT_NETWORK_ID", ""),
-			DefaultSnapshotID:  getEnv("FORWARD_DEFAULT_SNAPSHOT_ID", ""),
-			DefaultQueryLimit:  getEnvAsInt("FORWARD_DEFAULT_QUERY_LIMIT", 10000),
+			APIKey:                 getEnv("FORWARD_API_KEY", ""),
+			APISecret:              getEnv("FORWARD_API_SECRET", ""),
+			APIBaseURL:             getEnv("FORWARD_API_BASE_URL", ""),
+			Timeout:                getEnvAsInt("FORWARD_TIMEOUT", 600), // 10 minutes for enhanced API operations
+			InsecureSkipVerify:     getEnvAsBool("FORWARD_INSECURE_SKIP_VERIFY", false),
+			GzipResponses:          getEnvAsBool("FORWARD_GZIP_RESPONSES", true),
+			CompressRequests:       getEnvAsBool("FORWARD_COMPRESS_REQUESTS", false),
+			EnableHTTP2:            getEnvAsBool("FORWARD_HTTP2", true),
+			MaxIdleConns:           getEnvAsInt("FORWARD_MAX_IDLE_CONNS", 100),
+			MaxIdleConnsPerHost:    getEnvAsInt("FORWARD_MAX_IDLE_CONNS_PER_HOST", 16),
+			IdleConnTimeoutSeconds: getEnvAsInt("FORWARD_IDLE_CONN_TIMEOUT_SECONDS", 90),
+			APIDebug:               getEnv("FORWARD_API_DEBUG", ""),
+			APIDebugFile:           getEnv("FORWARD_API_DEBUG_FILE", ""),
+			APIDebugMaxBodyBytes:   getEnvAsInt("FORWARD_API_DEBUG_MAX_BODY_BYTES", 16384),
+			APIDebugMaxFileMB:      getEnvAsInt("FORWARD_API_DEBUG_MAX_FILE_MB", 10),
+			CACertPath:             getEnv("FORWARD_CA_CERT_PATH", ""),
+			ClientCertPath:         getEnv("FORWARD_CLIENT_CERT_PATH", ""),
+			ClientKeyPath:          getEnv("FORWARD_CLIENT_KEY_PATH", ""),
+			DefaultNetworkID:       getEnv("FORWARD_DEFAULT_NETWORK_ID", ""),
+			DefaultSnapshotID:      getEnv("FORWARD_DEFAULT_SNAPSHOT_ID", ""),
+			DefaultQueryLimit:      getEnvAsInt("FORWARD_DEFAULT_QUERY_LIMIT", 10000),
+			AllowedNetworks:        getEnvAsList("FORWARD_ALLOWED_NETWORKS"),
+			DeniedNetworks:         getEnvAsList("FORWARD_DENIED_NETWORKS"),
+			VendorMappingsFile:     getEnv("FORWARD_VENDOR_MAPPINGS_FILE", ""),
+			FederationFile:         getEnv("FORWARD_FEDERATION_FILE", ""),
+			ChunkTargetBytes:       getEnvAsInt("FORWARD_CHUNK_TARGET_BYTES", 32768),
+			SQLTimeoutSeconds:      getEnvAsInt("FORWARD_SQL_TIMEOUT_SECONDS", 5),
+			SQLMaxMemoryMB:         getEnvAsInt("FORWARD_SQL_MAX_MEMORY_MB", 64),
+			DuckDBPath:             getEnv("FORWARD_DUCKDB_PATH", ""),
+			Verbosity:              getEnv("FORWARD_VERBOSITY", "verbose"),
+			Redaction: RedactionConfig{
+				Enabled:   getEnvAsBool("FORWARD_REDACTION", false),
+				Patterns:  getEnvAsRedactionPatterns("FORWARD_REDACTION_PATTERNS"),
+				Allowlist: getEnvAsListMap("FORWARD_REDACTION_ALLOWLIST"),
+			},
+			Reports: ReportConfig{
+				Directory:    getEnv("FORWARD_REPORT_DIR", ""),
+				TemplateDir:  getEnv("FORWARD_REPORT_TEMPLATE_DIR", ""),
+				PDFConverter: getEnv("FORWARD_PDF_CONVERTER", ""),
+			},
+			Probes: ProbeConfig{
+				Host:           getEnv("FORWARD_PROBE_HOST", ""),
+				URL:            getEnv("FORWARD_PROBE_URL", ""),
+				Count:          getEnvAsInt("FORWARD_PROBE_COUNT", 3),
+				TimeoutSeconds: getEnvAsInt("FORWARD_PROBE_TIMEOUT_SECONDS", 2),
+			},
+			Summarization: SummarizationConfig{
+				Provider:          getEnv("FORWARD_SUMMARY_PROVIDER", ""),
+				Model:             getEnv("FORWARD_SUMMARY_MODEL", "gpt-4o-mini"),
+				URL:               getEnv("FORWARD_SUMMARY_URL", "https://api.openai.com/v1/chat/completions"),
+				RequestsPerMinute: getEnvAsInt("FORWARD_SUMMARY_REQUESTS_PER_MINUTE", 6),
+				SampleRows:        getEnvAsInt("FORWARD_SUMMARY_SAMPLE_ROWS", 50),
+			},
+			Features: FeatureFlagConfig{
+				Enabled:  getEnvAsList("FORWARD_ENABLED_FEATURES"),
+				Disabled: getEnvAsList("FORWARD_DISABLED_FEATURES"),
+			},
+			SessionTranscript: getEnvAsBool("FORWARD_SESSION_TRANSCRIPT", false),
+			SnapshotPinning:   getEnvAsBool("FORWARD_SNAPSHOT_PINNING", false),
+			SnapshotFreshness: SnapshotFreshnessConfig{
+				MaxAgeHours: getEnvAsFloat("FORWARD_SNAPSHOT_MAX_AGE_HOURS", 24),
+				Networks:    getEnvAsFloatMap("FORWARD_SNAPSHOT_MAX_AGE_NETWORKS"),
+			},
+			SnapshotPipeline: SnapshotPipelineConfig{
+				Networks:            getEnvAsList("FORWARD_PIPELINE_NETWORKS"),
+				PollIntervalMinutes: getEnvAsInt("FORWARD_PIPELINE_POLL_MINUTES", 0),
+				WebhookAddr:         getEnv("FORWARD_PIPELINE_WEBHOOK_ADDR", ""),
+				WebhookSecret:       getEnv("FORWARD_PIPELINE_WEBHOOK_SECRET", ""),
+				NotifyURL:           getEnv("FORWARD_PIPELINE_NOTIFY_URL", ""),
+				ReportFormat:        getEnv("FORWARD_PIPELINE_REPORT_FORMAT", ""),
+			},
+			TrashRetentionHours: getEnvAsInt("FORWARD_TRASH_RETENTION_HOURS", 168),
+			Prefetch:            getEnvAsBool("FORWARD_PREFETCH", false),
+			PrefetchPerMinute:   getEnvAsInt("FORWARD_PREFETCH_PER_MINUTE", 20),
+			NQEFetchConcurrency: getEnvAsInt("FORWARD_NQE_FETCH_CONCURRENCY", 4),
+			DisplayTimezone:     getEnv("FORWARD_DISPLAY_TZ", "UTC"),
 			SemanticCache: SemanticCacheConfig{
 				Enabled:             getEnvAsBool("FORWARD_SEMANTIC_CACHE_ENABLED", true),
 				MaxEntries:          getEnvAsInt("FORWARD_SEMANTIC_CACHE_MAX_ENTRIES", 1000),
 				TTLHours:            getEnvAsInt("FORWARD_SEMANTIC_CACHE_TTL_HOURS", 24),
 				SimilarityThreshold: getEnvAsFloat("FORWARD_SEMANTIC_CACHE_SIMILARITY_THRESHOLD", 0.85),
 				EmbeddingProvider:   getEnv("FORWARD_EMBEDDING_PROVIDER", "openai"),
+				CategoryThresholds:  getEnvAsFloatMap("FORWARD_SEMANTIC_CACHE_CATEGORY_THRESHOLDS"),
 
 				// Enhanced cache configuration defaults
 				MaxMemoryMB:             getEnvAsInt("FORWARD_SEMANTIC_CACHE_MAX_MEMORY_MB", 512), // 512MB default
@@ -126,6 +431,8 @@ func LoadConfig() *Config {
 				MetricsEnabled:          getEnvAsBool("FORWARD_SEMANTIC_CACHE_METRICS_ENABLED", true),
 				MemoryEvictionThreshold: getEnvAsFloat("FORWARD_SEMANTIC_CACHE_MEMORY_THRESHOLD", 0.8), // 80%
 				CleanupIntervalMinutes:  getEnvAsInt("FORWARD_SEMANTIC_CACHE_CLEANUP_INTERVAL", 30),
+				NegativeTTLSeconds:      getEnvAsInt("FORWARD_NEGATIVE_CACHE_TTL_SECONDS", 60),
+				DeviceCacheTTLSeconds:   getEnvAsInt("FORWARD_DEVICE_CACHE_TTL_SECONDS", 300),
 			},
 		},
 		MCP: MCPConfig{
@@ -140,9 +447,84 @@ func LoadConfig() *Config {
 		debugLogger.Debug("Could not load JSON config file: %v", err)
 	}
 
+	// Extend vendor mappings from a dedicated file if configured
+	if config.Forward.VendorMappingsFile != "" {
+		rules, err := LoadVendorMappingsFile(config.Forward.VendorMappingsFile)
+		if err != nil {
+			debugLogger := logger.New()
+			debugLogger.Warn("Could not load vendor mappings file: %v", err)
+		} else {
+			config.Forward.VendorMappings = append(config.Forward.VendorMappings, rules...)
+		}
+	}
+
+	// Extend federated instances from a dedicated file if configured
+	if config.Forward.FederationFile != "" {
+		instances, err := LoadFederationFile(config.Forward.FederationFile)
+		if err != nil {
+			debugLogger := logger.New()
+			debugLogger.Warn("Could not load federation file: %v", err)
+		} else {
+			config.Forward.Federation = append(config.Forward.Federation, instances...)
+		}
+	}
+
+	// Extend API keys from a dedicated file if configured
+	if config.Server.APIKeysFile != "" {
+		keys, err := LoadAPIKeysFile(config.Server.APIKeysFile)
+		if err != nil {
+			debugLogger := logger.New()
+			debugLogger.Warn("Could not load API keys file: %v", err)
+		} else {
+			config.Server.APIKeys = append(config.Server.APIKeys, keys...)
+		}
+	}
+
 	return config
 }
 
+// LoadAPIKeysFile reads a JSON array of API keys
+func LoadAPIKeysFile(path string) ([]APIKeyConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys file %s: %w", path, err)
+	}
+
+	var keys []APIKeyConfig
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file %s: %w", path, err)
+	}
+	return keys, nil
+}
+
+// LoadFederationFile reads a JSON array of federated instances
+func LoadFederationFile(path string) ([]FederatedInstanceConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read federation file %s: %w", path, err)
+	}
+
+	var instances []FederatedInstanceConfig
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, fmt.Errorf("failed to parse federation file %s: %w", path, err)
+	}
+	return instances, nil
+}
+
+// LoadVendorMappingsFile reads a JSON array of vendor mapping rules
+func LoadVendorMappingsFile(path string) ([]VendorMappingRule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read vendor mappings file %s: %w", path, err)
+	}
+
+	var rules []VendorMappingRule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse vendor mappings file %s: %w", path, err)
+	}
+	return rules, nil
+}
+
 // loadEnvFile loads environment variables from .env file
 func loadEnvFile() {
 	if err := godotenv.Load(); err != nil {
@@ -174,6 +556,7 @@ func loadJSONConfig(config *Config) error {
 
 	// Parse JSON config
 	var jsonConfig struct {
+		Server  ServerConfig  `json:"server"`
 		Forward ForwardConfig `json:"forward"`
 	}
 	if err := json.Unmarshal(configFile, &jsonConfig); err != nil {
@@ -181,6 +564,18 @@ func loadJSONConfig(config *Config) error {
 	}
 
 	// Update config with JSON values if they are not empty
+	if jsonConfig.Server.Transport != "" && os.Getenv("SERVER_TRANSPORT") == "" {
+		config.Server.Transport = jsonConfig.Server.Transport
+	}
+	if jsonConfig.Server.HTTPPath != "" && os.Getenv("SERVER_HTTP_PATH") == "" {
+		config.Server.HTTPPath = jsonConfig.Server.HTTPPath
+	}
+	if len(jsonConfig.Server.APIKeys) > 0 {
+		config.Server.APIKeys = jsonConfig.Server.APIKeys
+	}
+	if jsonConfig.Server.APIKeysFile != "" && config.Server.APIKeysFile == "" {
+		config.Server.APIKeysFile = jsonConfig.Server.APIKeysFile
+	}
 	if jsonConfig.Forward.APIKey != "" {
 		config.Forward.APIKey = jsonConfig.Forward.APIKey
 	}
@@ -199,6 +594,172 @@ func loadJSONConfig(config *Config) error {
 	if jsonConfig.Forward.DefaultQueryLimit > 0 {
 		config.Forward.DefaultQueryLimit = jsonConfig.Forward.DefaultQueryLimit
 	}
+	if len(jsonConfig.Forward.AllowedNetworks) > 0 && os.Getenv("FORWARD_ALLOWED_NETWORKS") == "" {
+		config.Forward.AllowedNetworks = jsonConfig.Forward.AllowedNetworks
+	}
+	if len(jsonConfig.Forward.DeniedNetworks) > 0 {
+		// Denials from the config file and the environment both apply
+		config.Forward.DeniedNetworks = append(jsonConfig.Forward.DeniedNetworks, config.Forward.DeniedNetworks...)
+	}
+	if jsonConfig.Forward.APIDebug != "" && config.Forward.APIDebug == "" {
+		config.Forward.APIDebug = jsonConfig.Forward.APIDebug
+	}
+	if jsonConfig.Forward.APIDebugFile != "" && config.Forward.APIDebugFile == "" {
+		config.Forward.APIDebugFile = jsonConfig.Forward.APIDebugFile
+	}
+	if jsonConfig.Forward.APIDebugMaxBodyBytes > 0 && os.Getenv("FORWARD_API_DEBUG_MAX_BODY_BYTES") == "" {
+		config.Forward.APIDebugMaxBodyBytes = jsonConfig.Forward.APIDebugMaxBodyBytes
+	}
+	if jsonConfig.Forward.APIDebugMaxFileMB > 0 && os.Getenv("FORWARD_API_DEBUG_MAX_FILE_MB") == "" {
+		config.Forward.APIDebugMaxFileMB = jsonConfig.Forward.APIDebugMaxFileMB
+	}
+	if jsonConfig.Forward.DisplayTimezone != "" && os.Getenv("FORWARD_DISPLAY_TZ") == "" {
+		config.Forward.DisplayTimezone = jsonConfig.Forward.DisplayTimezone
+	}
+	if len(jsonConfig.Forward.VendorMappings) > 0 {
+		config.Forward.VendorMappings = jsonConfig.Forward.VendorMappings
+	}
+	if jsonConfig.Forward.VendorMappingsFile != "" && config.Forward.VendorMappingsFile == "" {
+		config.Forward.VendorMappingsFile = jsonConfig.Forward.VendorMappingsFile
+	}
+	if len(jsonConfig.Forward.Federation) > 0 {
+		config.Forward.Federation = jsonConfig.Forward.Federation
+	}
+	if jsonConfig.Forward.FederationFile != "" && config.Forward.FederationFile == "" {
+		config.Forward.FederationFile = jsonConfig.Forward.FederationFile
+	}
+	if jsonConfig.Forward.ChunkTargetBytes > 0 && os.Getenv("FORWARD_CHUNK_TARGET_BYTES") == "" {
+		config.Forward.ChunkTargetBytes = jsonConfig.Forward.ChunkTargetBytes
+	}
+	if jsonConfig.Forward.SQLTimeoutSeconds > 0 && os.Getenv("FORWARD_SQL_TIMEOUT_SECONDS") == "" {
+		config.Forward.SQLTimeoutSeconds = jsonConfig.Forward.SQLTimeoutSeconds
+	}
+	if jsonConfig.Forward.SQLMaxMemoryMB > 0 && os.Getenv("FORWARD_SQL_MAX_MEMORY_MB") == "" {
+		config.Forward.SQLMaxMemoryMB = jsonConfig.Forward.SQLMaxMemoryMB
+	}
+	if jsonConfig.Forward.DuckDBPath != "" && config.Forward.DuckDBPath == "" {
+		config.Forward.DuckDBPath = jsonConfig.Forward.DuckDBPath
+	}
+	if jsonConfig.Forward.Verbosity != "" && os.Getenv("FORWARD_VERBOSITY") == "" {
+		config.Forward.Verbosity = jsonConfig.Forward.Verbosity
+	}
+	if jsonConfig.Forward.Redaction.Enabled && os.Getenv("FORWARD_REDACTION") == "" {
+		config.Forward.Redaction.Enabled = true
+	}
+	if len(jsonConfig.Forward.Redaction.Patterns) > 0 {
+		// Environment patterns are appended after those from the config file
+		config.Forward.Redaction.Patterns = append(jsonConfig.Forward.Redaction.Patterns, config.Forward.Redaction.Patterns...)
+	}
+	if len(jsonConfig.Forward.Redaction.Allowlist) > 0 {
+		allowlist := jsonConfig.Forward.Redaction.Allowlist
+		for pattern, values := range config.Forward.Redaction.Allowlist {
+			allowlist[pattern] = append(allowlist[pattern], values...)
+		}
+		config.Forward.Redaction.Allowlist = allowlist
+	}
+	if jsonConfig.Forward.Reports.Directory != "" && config.Forward.Reports.Directory == "" {
+		config.Forward.Reports.Directory = jsonConfig.Forward.Reports.Directory
+	}
+	if jsonConfig.Forward.Reports.TemplateDir != "" && config.Forward.Reports.TemplateDir == "" {
+		config.Forward.Reports.TemplateDir = jsonConfig.Forward.Reports.TemplateDir
+	}
+	if jsonConfig.Forward.Reports.PDFConverter != "" && config.Forward.Reports.PDFConverter == "" {
+		config.Forward.Reports.PDFConverter = jsonConfig.Forward.Reports.PDFConverter
+	}
+	if jsonConfig.Forward.Probes.Host != "" && config.Forward.Probes.Host == "" {
+		config.Forward.Probes.Host = jsonConfig.Forward.Probes.Host
+	}
+	if jsonConfig.Forward.Probes.URL != "" && config.Forward.Probes.URL == "" {
+		config.Forward.Probes.URL = jsonConfig.Forward.Probes.URL
+	}
+	if jsonConfig.Forward.Probes.Count > 0 && os.Getenv("FORWARD_PROBE_COUNT") == "" {
+		config.Forward.Probes.Count = jsonConfig.Forward.Probes.Count
+	}
+	if jsonConfig.Forward.Probes.TimeoutSeconds > 0 && os.Getenv("FORWARD_PROBE_TIMEOUT_SECONDS") == "" {
+		config.Forward.Probes.TimeoutSeconds = jsonConfig.Forward.Probes.TimeoutSeconds
+	}
+	if jsonConfig.Forward.Summarization.Provider != "" && os.Getenv("FORWARD_SUMMARY_PROVIDER") == "" {
+		config.Forward.Summarization.Provider = jsonConfig.Forward.Summarization.Provider
+	}
+	if jsonConfig.Forward.Summarization.Model != "" && os.Getenv("FORWARD_SUMMARY_MODEL") == "" {
+		config.Forward.Summarization.Model = jsonConfig.Forward.Summarization.Model
+	}
+	if jsonConfig.Forward.Summarization.URL != "" && os.Getenv("FORWARD_SUMMARY_URL") == "" {
+		config.Forward.Summarization.URL = jsonConfig.Forward.Summarization.URL
+	}
+	if jsonConfig.Forward.Summarization.RequestsPerMinute > 0 && os.Getenv("FORWARD_SUMMARY_REQUESTS_PER_MINUTE") == "" {
+		config.Forward.Summarization.RequestsPerMinute = jsonConfig.Forward.Summarization.RequestsPerMinute
+	}
+	if jsonConfig.Forward.Summarization.SampleRows > 0 && os.Getenv("FORWARD_SUMMARY_SAMPLE_ROWS") == "" {
+		config.Forward.Summarization.SampleRows = jsonConfig.Forward.Summarization.SampleRows
+	}
+	if jsonConfig.Forward.SessionTranscript && os.Getenv("FORWARD_SESSION_TRANSCRIPT") == "" {
+		config.Forward.SessionTranscript = true
+	}
+	if jsonConfig.Forward.SnapshotPinning && os.Getenv("FORWARD_SNAPSHOT_PINNING") == "" {
+		config.Forward.SnapshotPinning = true
+	}
+	if jsonConfig.Forward.SnapshotFreshness.MaxAgeHours > 0 && os.Getenv("FORWARD_SNAPSHOT_MAX_AGE_HOURS") == "" {
+		config.Forward.SnapshotFreshness.MaxAgeHours = jsonConfig.Forward.SnapshotFreshness.MaxAgeHours
+	}
+	if len(jsonConfig.Forward.SnapshotFreshness.Networks) > 0 {
+		// Network thresholds from the environment win over those from the config file
+		networks := jsonConfig.Forward.SnapshotFreshness.Networks
+		for networkID, hours := range config.Forward.SnapshotFreshness.Networks {
+			networks[networkID] = hours
+		}
+		config.Forward.SnapshotFreshness.Networks = networks
+	}
+	pipeline := jsonConfig.Forward.SnapshotPipeline
+	if len(pipeline.Networks) > 0 && os.Getenv("FORWARD_PIPELINE_NETWORKS") == "" {
+		config.Forward.SnapshotPipeline.Networks = pipeline.Networks
+	}
+	if pipeline.PollIntervalMinutes > 0 && os.Getenv("FORWARD_PIPELINE_POLL_MINUTES") == "" {
+		config.Forward.SnapshotPipeline.PollIntervalMinutes = pipeline.PollIntervalMinutes
+	}
+	if pipeline.WebhookAddr != "" && os.Getenv("FORWARD_PIPELINE_WEBHOOK_ADDR") == "" {
+		config.Forward.SnapshotPipeline.WebhookAddr = pipeline.WebhookAddr
+	}
+	if pipeline.WebhookSecret != "" && os.Getenv("FORWARD_PIPELINE_WEBHOOK_SECRET") == "" {
+		config.Forward.SnapshotPipeline.WebhookSecret = pipeline.WebhookSecret
+	}
+	if pipeline.NotifyURL != "" && os.Getenv("FORWARD_PIPELINE_NOTIFY_URL") == "" {
+		config.Forward.SnapshotPipeline.NotifyURL = pipeline.NotifyURL
+	}
+	if pipeline.ReportFormat != "" && os.Getenv("FORWARD_PIPELINE_REPORT_FORMAT") == "" {
+		config.Forward.SnapshotPipeline.ReportFormat = pipeline.ReportFormat
+	}
+	config.Forward.SnapshotPipeline.Playbook = pipeline.Playbook
+	if len(jsonConfig.Forward.InterfaceConventions) > 0 {
+		config.Forward.InterfaceConventions = jsonConfig.Forward.InterfaceConventions
+	}
+	if jsonConfig.Forward.TrashRetentionHours > 0 && os.Getenv("FORWARD_TRASH_RETENTION_HOURS") == "" {
+		config.Forward.TrashRetentionHours = jsonConfig.Forward.TrashRetentionHours
+	}
+	if jsonConfig.Forward.Prefetch && os.Getenv("FORWARD_PREFETCH") == "" {
+		config.Forward.Prefetch = true
+	}
+	if jsonConfig.Forward.PrefetchPerMinute > 0 && os.Getenv("FORWARD_PREFETCH_PER_MINUTE") == "" {
+		config.Forward.PrefetchPerMinute = jsonConfig.Forward.PrefetchPerMinute
+	}
+	if jsonConfig.Forward.NQEFetchConcurrency > 0 && os.Getenv("FORWARD_NQE_FETCH_CONCURRENCY") == "" {
+		config.Forward.NQEFetchConcurrency = jsonConfig.Forward.NQEFetchConcurrency
+	}
+	// Feature flags from the config file and the environment both apply
+	if len(jsonConfig.Forward.Features.Enabled) > 0 {
+		config.Forward.Features.Enabled = append(jsonConfig.Forward.Features.Enabled, config.Forward.Features.Enabled...)
+	}
+	if len(jsonConfig.Forward.Features.Disabled) > 0 {
+		config.Forward.Features.Disabled = append(jsonConfig.Forward.Features.Disabled, config.Forward.Features.Disabled...)
+	}
+	if len(jsonConfig.Forward.SemanticCache.CategoryThresholds) > 0 {
+		// Environment entries take precedence over the config file
+		thresholds := jsonConfig.Forward.SemanticCache.CategoryThresholds
+		for category, threshold := range config.Forward.SemanticCache.CategoryThresholds {
+			thresholds[category] = threshold
+		}
+		config.Forward.SemanticCache.CategoryThresholds = thresholds
+	}
 
 	return nil
 }
@@ -239,3 +800,77 @@ func getEnvAsFloat(key string, defaultValue float64) float64 {
 	}
 	return defaultValue
 }
+
+// Helper function to get environment variable as a comma-separated list.
+// Empty items are ignored; returns nil when unset or empty.
+func getEnvAsList(key string) []string {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Helper function to get environment variable as a "key=float,key=float" map.
+// Malformed pairs are ignored; returns nil when unset or empty.
+func getEnvAsFloatMap(key string) map[string]float64 {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	result := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		name, raw, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		floatValue, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil || strings.TrimSpace(name) == "" {
+			continue
+		}
+		result[strings.TrimSpace(name)] = floatValue
+	}
+	return result
+}
+
+// Helper function to get environment variable as "name=regex;name=regex" redaction patterns.
+// Patterns are separated by semicolons because regular expressions often contain commas.
+func getEnvAsRedactionPatterns(key string) []RedactionPattern {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	var patterns []RedactionPattern
+	for _, entry := range strings.Split(value, ";") {
+		name, pattern, found := strings.Cut(entry, "=")
+		if !found || strings.TrimSpace(name) == "" || strings.TrimSpace(pattern) == "" {
+			continue
+		}
+		patterns = append(patterns, RedactionPattern{Name: strings.TrimSpace(name), Pattern: strings.TrimSpace(pattern)})
+	}
+	return patterns
+}
+
+// Helper function to get environment variable as a "key:value,key:value" map of lists.
+// Malformed pairs are ignored; returns nil when unset or empty.
+func getEnvAsListMap(key string) map[string][]string {
+	value, exists := os.LookupEnv(key)
+	if !exists || strings.TrimSpace(value) == "" {
+		return nil
+	}
+	result := make(map[string][]string)
+	for _, pair := range strings.Split(value, ",") {
+		name, item, found := strings.Cut(pair, ":")
+		if !found || strings.TrimSpace(name) == "" || strings.TrimSpace(item) == "" {
+			continue
+		}
+		result[strings.TrimSpace(name)] = append(result[strings.TrimSpace(name)], strings.TrimSpace(item))
+	}
+	return result
+}
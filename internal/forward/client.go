@@ -51,6 +51,7 @@ type ClientInterface interface {
 	GetNQEAllQueriesEnhanced() ([]NQEQueryDetail, error)
 	GetNQEAllQueriesEnhancedWithCache(existingCommitIDs map[string]string) ([]NQEQueryDetail, error)
 	GetNQEAllQueriesEnhancedWithCacheContext(ctx context.Context, existingCommitIDs map[string]string) ([]NQEQueryDetail, error)
+	GetNQEQuerySummariesContext(ctx context.Context, repository string) ([]NQEOrgQuerySummary, error)
 	GetNQEQueryByCommit(commitID string, path string, repository string) (*NQEQueryDetail, error)
 	GetNQEQueryByCommitWithContext(ctx context.Context, commitID string, path string, repository string) (*NQEQueryDetail, error)
 	DiffNQEQuery(before, after string, request *NQEDiffRequest) (*NQEDiffResult, error)
@@ -65,6 +66,10 @@ type ClientInterface interface {
 	GetLatestSnapshot(networkID string) (*Snapshot, error)
 	DeleteSnapshot(snapshotID string) error
 
+	// Verification check operations
+	GetChecks(snapshotID string, params *CheckQueryParams) ([]NetworkCheck, error)
+	GetCheck(snapshotID, checkID string) (*NetworkCheck, error)
+
 	// Location operations
 	GetLocations(networkID string) ([]Location, error)
 	CreateLocation(networkID string, location *LocationCreate) (*Location, error)
@@ -75,8 +80,11 @@ type ClientInterface interface {
 
 // Client represents the Forward platform client
 type Client struct {
-	httpClient *http.Client
-	config     *config.ForwardConfig
+	httpClient  *http.Client
+	config      *config.ForwardConfig
+	transport   *compressionTransport
+	connections *connectionTransport
+	debug       *debugTransport
 }
 
 // NewClient creates a new Forward platform client
@@ -105,17 +113,56 @@ func NewClient(config *config.ForwardConfig) ClientInterface {
 		}
 	}
 
-	// Create custom transport with TLS configuration
-	transport := &http.Transport{
-		TLSClientConfig: tlsConfig,
-	}
+	// Create a pooled keep-alive transport with TLS configuration, tracking connection reuse
+	connections := newConnectionTransport(newPooledTransport(config, tlsConfig))
+
+	// Wrap the transport with gzip negotiation and transfer size metrics
+	compression := newCompressionTransport(connections, config.GzipResponses, config.CompressRequests)
+
+	// Record failed requests, and log sanitized exchanges in a debug capture mode
+	debug := newDebugTransport(compression, apiDebugMode(config.APIDebug), openDebugLog(config), config.APIDebugMaxBodyBytes,
+		config.APIKey, config.APISecret)
 
 	return &Client{
 		httpClient: &http.Client{
 			Timeout:   time.Duration(config.Timeout) * time.Second,
-			Transport: transport,
+			Transport: debug,
 		},
-		config: config,
+		config:      config,
+		transport:   compression,
+		connections: connections,
+		debug:       debug,
+	}
+}
+
+// TransferStats returns request and response sizes on the wire and after decompression
+func (c *Client) TransferStats() TransferStats {
+	if c.transport == nil {
+		return TransferStats{}
+	}
+	return c.transport.Stats()
+}
+
+// ConnectionStats returns connection pool reuse and protocol counters for this client
+func (c *Client) ConnectionStats() ConnectionStats {
+	if c.connections == nil {
+		return ConnectionStats{}
+	}
+	return c.connections.Stats()
+}
+
+// RecentAPIErrors returns the most recent failed requests, newest first
+func (c *Client) RecentAPIErrors() []APIExchangeError {
+	if c.debug == nil {
+		return nil
+	}
+	return c.debug.RecentAPIErrors()
+}
+
+// SetDebugRedactor adds a redact function applied to everything captured from API exchanges
+func (c *Client) SetDebugRedactor(redact func(string) string) {
+	if c.debug != nil {
+		c.debug.setRedactor(redact)
 	}
 }
 
@@ -332,8 +379,17 @@ type DeviceQueryParams struct {
 type DeviceResponse struct {
 	Devices    []Device `json:"devices"`
 	TotalCount int      `json:"totalCount"`
+	Pages      int      `json:"pages,omitempty"`
+	Truncated  bool     `json:"truncated,omitempty"`
 }
 
+const (
+	// devicePageSize is the page size used when GetDevices iterates a full inventory
+	devicePageSize = 1000
+	// maxPaginatedDevices caps how many devices one unbounded GetDevices call collects
+	maxPaginatedDevices = 100000
+)
+
 type Device struct {
 	Name          string                 `json:"name"`
 	Type          string                 `json:"type,omitempty"`
@@ -376,6 +432,46 @@ type Snapshot struct {
 	DeviceCount int    `json:"deviceCount,omitempty"`
 }
 
+// NetworkCheck is a verification check configured in Forward with its result on a snapshot
+type NetworkCheck struct {
+	ID                      string           `json:"id"`
+	Name                    string           `json:"name,omitempty"`
+	Description             string           `json:"description,omitempty"`
+	Note                    string           `json:"note,omitempty"`
+	Definition              *CheckDefinition `json:"definition,omitempty"`
+	Enabled                 bool             `json:"enabled"`
+	Priority                string           `json:"priority,omitempty"` // NOT_SET, LOW, MEDIUM, HIGH
+	Tags                    []string         `json:"tags,omitempty"`
+	Status                  string           `json:"status,omitempty"` // PASS, FAIL, ERROR, TIMEOUT, PROCESSING
+	NumViolations           int              `json:"numViolations,omitempty"`
+	CreatorID               string           `json:"creatorId,omitempty"`
+	CreationDateMillis      int64            `json:"creationDateMillis,omitempty"`
+	EditDateMillis          int64            `json:"editDateMillis,omitempty"`
+	ExecutionDateMillis     int64            `json:"executionDateMillis,omitempty"`
+	ExecutionDurationMillis int64            `json:"executionDurationMillis,omitempty"`
+	Diagnosis               *CheckDiagnosis  `json:"diagnosis,omitempty"` // Only returned for a single check
+}
+
+// CheckDefinition describes what a check verifies; fields beyond the type depend on it
+type CheckDefinition struct {
+	CheckType           string `json:"checkType"` // e.g. Isolation, Reachability, Existential, NQE, Predefined
+	QueryID             string `json:"queryId,omitempty"`
+	PredefinedCheckType string `json:"predefinedCheckType,omitempty"`
+}
+
+// CheckDiagnosis explains why a check failed
+type CheckDiagnosis struct {
+	Summary string      `json:"summary,omitempty"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// CheckQueryParams filters the checks of a snapshot
+type CheckQueryParams struct {
+	Type     string // Check type, e.g. Isolation
+	Priority string
+	Status   string // PASS, FAIL, ERROR, TIMEOUT
+}
+
 // Response wrapper for snapshots API
 type SnapshotsResponse struct {
 	ID        string     `json:"id"`
@@ -456,7 +552,7 @@ func (c *Client) makeRequest(method, endpoint string, body interface{}) (*http.R
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		// Read the response body for error details
-		errorBody, readErr := io.ReadAll(resp.Body)
+		errorBody, readErr := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
 		resp.Body.Close()
 
 		errorMsg := fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
@@ -571,7 +667,7 @@ func (c *Client) makeRequestWithRetry(ctx context.Context, method, endpoint stri
 
 		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 			// Read the response body for error details
-			errorBody, readErr := io.ReadAll(resp.Body)
+			errorBody, readErr := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
 			resp.Body.Close()
 
 			errorMsg := fmt.Sprintf("unexpected status code: %d", resp.StatusCode)
@@ -1363,6 +1459,24 @@ func (c *Client) GetNQEAllQueriesEnhancedWithCacheContext(ctx context.Context, e
 	return result, nil
 }
 
+// GetNQEQuerySummariesContext lists the queries at the head commit of a repository ("org" or
+// "fwd") with their last commit IDs, without fetching query details
+func (c *Client) GetNQEQuerySummariesContext(ctx context.Context, repository string) ([]NQEOrgQuerySummary, error) {
+	endpoint := fmt.Sprintf("/api/nqe/repos/%s/commits/head/queries", repository)
+
+	resp, err := c.makeRequestWithRetry(ctx, "GET", endpoint, nil, 3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get NQE %s queries after retries: %w", repository, err)
+	}
+	defer resp.Body.Close()
+
+	var response NQEOrgQueriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode %s queries response: %w", repository, err)
+	}
+	return response.Queries, nil
+}
+
 func (c *Client) GetNQEQueryByCommit(commitID string, path string, repository string) (*NQEQueryDetail, error) {
 	return c.GetNQEQueryByCommitWithContext(context.Background(), commitID, path, repository)
 }
@@ -1403,28 +1517,85 @@ func (c *Client) DiffNQEQuery(before, after string, request *NQEDiffRequest) (*N
 
 // Device operations
 func (c *Client) GetDevices(networkID string, params *DeviceQueryParams) (*DeviceResponse, error) {
+	if params == nil {
+		params = &DeviceQueryParams{}
+	}
+
+	// An explicit limit requests a single page
+	if params.Limit > 0 {
+		devices, err := c.getDevicePage(networkID, params.SnapshotID, params.Offset, params.Limit)
+		if err != nil {
+			return nil, err
+		}
+		return &DeviceResponse{Devices: devices, TotalCount: len(devices), Pages: 1}, nil
+	}
+
+	// Without a limit, iterate pages until the inventory is exhausted so large networks are
+	// never silently truncated by the API's default page size
+	debugLogger := logger.New()
+	response := &DeviceResponse{}
+	offset := params.Offset
+	firstName := ""
+	for {
+		page, err := c.getDevicePage(networkID, params.SnapshotID, offset, devicePageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch devices at offset %d: %w", offset, err)
+		}
+		response.Pages++
+
+		// Servers that ignore offset return the first page again; stop rather than loop
+		if len(page) > 0 && response.Pages > 1 && page[0].Name == firstName {
+			debugLogger.Warn("GetDevices - network %s ignored offset %d, stopping pagination", networkID, offset)
+			break
+		}
+		if response.Pages == 1 && len(page) > 0 {
+			firstName = page[0].Name
+		}
+
+		if remaining := maxPaginatedDevices - len(response.Devices); len(page) > remaining {
+			response.Devices = append(response.Devices, page[:remaining]...)
+			response.Truncated = true
+			debugLogger.Warn("GetDevices - network %s exceeds %d devices, inventory truncated", networkID, maxPaginatedDevices)
+			break
+		}
+		response.Devices = append(response.Devices, page...)
+
+		// A short page is the last one; a page larger than requested means limit is unsupported
+		if len(page) != devicePageSize {
+			break
+		}
+		offset += len(page)
+		debugLogger.Debug("GetDevices - network %s: fetched %d devices over %d pages", networkID, len(response.Devices), response.Pages)
+	}
+
+	response.TotalCount = len(response.Devices)
+	return response, nil
+}
+
+// getDevicePage fetches one page of a network's device inventory
+func (c *Client) getDevicePage(networkID, snapshotID string, offset, limit int) ([]Device, error) {
 	endpoint := fmt.Sprintf("/api/networks/%s/devices", networkID)
 
 	// Build query parameters
 	query := ""
-	if params.SnapshotID != "" {
-		query += fmt.Sprintf("?snapshotId=%s", params.SnapshotID)
+	if snapshotID != "" {
+		query += fmt.Sprintf("?snapshotId=%s", snapshotID)
 	}
-	if params.Offset > 0 {
+	if offset > 0 {
 		if query == "" {
 			query += "?"
 		} else {
 			query += "&"
 		}
-		query += fmt.Sprintf("offset=%d", params.Offset)
+		query += fmt.Sprintf("offset=%d", offset)
 	}
-	if params.Limit > 0 {
+	if limit > 0 {
 		if query == "" {
 			query += "?"
 		} else {
 			query += "&"
 		}
-		query += fmt.Sprintf("limit=%d", params.Limit)
+		query += fmt.Sprintf("limit=%d", limit)
 	}
 
 	resp, err := c.makeRequest("GET", endpoint+query, nil)
@@ -1438,14 +1609,7 @@ func (c *Client) GetDevices(networkID string, params *DeviceQueryParams) (*Devic
 	if err := json.NewDecoder(resp.Body).Decode(&devices); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	// Wrap in our response structure for consistency
-	deviceResp := &DeviceResponse{
-		Devices:    devices,
-		TotalCount: len(devices),
-	}
-
-	return deviceResp, nil
+	return devices, nil
 }
 
 func (c *Client) GetDeviceLocations(networkID string) (map[string]string, error) {
@@ -1525,6 +1689,58 @@ func (c *Client) DeleteSnapshot(snapshotID string) error {
 	return nil
 }
 
+// GetChecks returns the verification checks of a snapshot with their results
+func (c *Client) GetChecks(snapshotID string, params *CheckQueryParams) ([]NetworkCheck, error) {
+	endpoint := fmt.Sprintf("/api/snapshots/%s/checks", url.PathEscape(snapshotID))
+
+	query := url.Values{}
+	if params != nil {
+		if params.Type != "" {
+			query.Set("type", params.Type)
+		}
+		if params.Priority != "" {
+			query.Set("priority", params.Priority)
+		}
+		if params.Status != "" {
+			query.Set("status", params.Status)
+		}
+	}
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	resp, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var checks []NetworkCheck
+	if err := json.NewDecoder(resp.Body).Decode(&checks); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return checks, nil
+}
+
+// GetCheck returns one verification check of a snapshot with the diagnosis of its result
+func (c *Client) GetCheck(snapshotID, checkID string) (*NetworkCheck, error) {
+	endpoint := fmt.Sprintf("/api/snapshots/%s/checks/%s", url.PathEscape(snapshotID), url.PathEscape(checkID))
+
+	resp, err := c.makeRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var check NetworkCheck
+	if err := json.NewDecoder(resp.Body).Decode(&check); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &check, nil
+}
+
 // Location operations
 func (c *Client) GetLocations(networkID string) ([]Location, error) {
 	endpoint := fmt.Sprintf("/api/networks/%s/locations", networkID)
@@ -1573,7 +1789,7 @@ func (c *Client) CreateLocationsBulk(networkID string, locations []LocationBulkP
 
 	// Expecting 204 No Content; treat any 2xx as success.
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
 		return fmt.Errorf("bulk patch locations failed: status=%d body=%s", resp.StatusCode, string(body))
 	}
 	return nil
@@ -1,10 +1,13 @@
 package forward
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 
 	"github.com/forward-mcp/internal/config"
@@ -157,3 +160,123 @@ func TestClient_GetAvailableModels(t *testing.T) {
 		})
 	}
 }
+
+// newDeviceInventoryServer serves total devices, honoring offset and limit unless ignoreOffset is set
+func newDeviceInventoryServer(t *testing.T, total int, ignoreOffset bool, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		assert.Equal(t, "/api/networks/net-1/devices", r.URL.Path)
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		if ignoreOffset {
+			offset = 0
+		}
+		if limit == 0 {
+			limit = total
+		}
+		devices := []Device{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			devices = append(devices, Device{Name: fmt.Sprintf("device-%d", i)})
+		}
+		json.NewEncoder(w).Encode(devices)
+	}))
+}
+
+func TestClient_GetDevicesPagination(t *testing.T) {
+	t.Run("iterates every page", func(t *testing.T) {
+		requests := 0
+		server := newDeviceInventoryServer(t, 2500, false, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.ForwardConfig{APIBaseURL: server.URL, Timeout: 10})
+		response, err := client.GetDevices("net-1", &DeviceQueryParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, 2500, response.TotalCount)
+		assert.Equal(t, 3, response.Pages)
+		assert.False(t, response.Truncated)
+		assert.Equal(t, "device-2499", response.Devices[2499].Name)
+		assert.Equal(t, 3, requests)
+	})
+
+	t.Run("explicit limit fetches one page", func(t *testing.T) {
+		requests := 0
+		server := newDeviceInventoryServer(t, 2500, false, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.ForwardConfig{APIBaseURL: server.URL, Timeout: 10})
+		response, err := client.GetDevices("net-1", &DeviceQueryParams{Offset: 10, Limit: 5})
+		assert.NoError(t, err)
+		assert.Len(t, response.Devices, 5)
+		assert.Equal(t, "device-10", response.Devices[0].Name)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("stops when offset is ignored", func(t *testing.T) {
+		requests := 0
+		server := newDeviceInventoryServer(t, 2500, true, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.ForwardConfig{APIBaseURL: server.URL, Timeout: 10})
+		response, err := client.GetDevices("net-1", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, devicePageSize, response.TotalCount)
+		assert.Equal(t, 2, requests)
+	})
+
+	t.Run("caps very large inventories", func(t *testing.T) {
+		requests := 0
+		server := newDeviceInventoryServer(t, maxPaginatedDevices+1500, false, &requests)
+		defer server.Close()
+
+		client := NewClient(&config.ForwardConfig{APIBaseURL: server.URL, Timeout: 30})
+		response, err := client.GetDevices("net-1", &DeviceQueryParams{})
+		assert.NoError(t, err)
+		assert.Equal(t, maxPaginatedDevices, response.TotalCount)
+		assert.True(t, response.Truncated)
+	})
+}
+
+func TestClient_GetNQEQuerySummariesContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/nqe/repos/fwd/commits/head/queries", r.URL.Path)
+		json.NewEncoder(w).Encode(NQEOrgQueriesResponse{Queries: []NQEOrgQuerySummary{
+			{Path: "/Forward/L3/Routes", QueryID: "FQ_1", LastCommitId: "abc"},
+		}})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ForwardConfig{APIBaseURL: server.URL, Timeout: 10})
+	summaries, err := client.GetNQEQuerySummariesContext(context.Background(), "fwd")
+	assert.NoError(t, err)
+	assert.Equal(t, []NQEOrgQuerySummary{{Path: "/Forward/L3/Routes", QueryID: "FQ_1", LastCommitId: "abc"}}, summaries)
+}
+
+func TestClient_GetChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/snapshots/snap-1/checks":
+			assert.Equal(t, "FAIL", r.URL.Query().Get("status"))
+			assert.Equal(t, "HIGH", r.URL.Query().Get("priority"))
+			w.Write([]byte(`[{"id": "C-1", "name": "PCI isolation", "definition": {"checkType": "Isolation"}, "enabled": true, "priority": "HIGH", "status": "FAIL", "numViolations": 3}]`))
+		case "/api/snapshots/snap-1/checks/C-1":
+			w.Write([]byte(`{"id": "C-1", "status": "FAIL", "diagnosis": {"summary": "3 paths reach the PCI zone"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.ForwardConfig{APIBaseURL: server.URL, Timeout: 10})
+	checks, err := client.GetChecks("snap-1", &CheckQueryParams{Status: "FAIL", Priority: "HIGH"})
+	assert.NoError(t, err)
+	assert.Len(t, checks, 1)
+	assert.Equal(t, "Isolation", checks[0].Definition.CheckType)
+	assert.Equal(t, 3, checks[0].NumViolations)
+
+	check, err := client.GetCheck("snap-1", "C-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "3 paths reach the PCI zone", check.Diagnosis.Summary)
+
+	_, err = client.GetCheck("snap-1", "C-404")
+	assert.Error(t, err)
+}
@@ -0,0 +1,184 @@
+package forward
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+)
+
+const (
+	defaultMaxIdleConns        = 100
+	defaultMaxIdleConnsPerHost = 16
+	defaultIdleConnTimeout     = 90 * time.Second
+	defaultKeepAlive           = 30 * time.Second
+	// maxDrainBytes bounds how much of an unread response body is discarded on close so the
+	// connection can go back to the idle pool
+	maxDrainBytes = 256 * 1024
+)
+
+// ConnectionStats reports how requests to the Forward API were served by the connection pool
+type ConnectionStats struct {
+	Requests            int64         `json:"requests"`
+	NewConnections      int64         `json:"new_connections"`
+	ReusedConnections   int64         `json:"reused_connections"`
+	TLSHandshakes       int64         `json:"tls_handshakes"`
+	ConnectTime         time.Duration `json:"connect_time"`
+	HTTP2Responses      int64         `json:"http2_responses"`
+	HTTP1Responses      int64         `json:"http1_responses"`
+	MaxIdleConns        int           `json:"max_idle_conns"`
+	MaxIdleConnsPerHost int           `json:"max_idle_conns_per_host"`
+	IdleConnTimeout     time.Duration `json:"idle_conn_timeout"`
+	HTTP2Enabled        bool          `json:"http2_enabled"`
+}
+
+// ReuseRatePercent is the share of requests served over an already open connection
+func (s ConnectionStats) ReuseRatePercent() float64 {
+	total := s.NewConnections + s.ReusedConnections
+	if total == 0 {
+		return 0
+	}
+	return float64(s.ReusedConnections) / float64(total) * 100
+}
+
+// AverageConnectTime is the mean time spent dialing new connections
+func (s ConnectionStats) AverageConnectTime() time.Duration {
+	if s.NewConnections == 0 {
+		return 0
+	}
+	return s.ConnectTime / time.Duration(s.NewConnections)
+}
+
+// ConnectionStatsReporter is implemented by clients that track connection pool behavior
+type ConnectionStatsReporter interface {
+	ConnectionStats() ConnectionStats
+}
+
+// newPooledTransport builds the base transport with keep-alive, HTTP/2 and idle pool
+// settings from the configuration; zero values fall back to the defaults
+func newPooledTransport(cfg *config.ForwardConfig, tlsConfig *tls.Config) *http.Transport {
+	maxIdle := cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = defaultMaxIdleConns
+	}
+	maxIdlePerHost := cfg.MaxIdleConnsPerHost
+	if maxIdlePerHost <= 0 {
+		maxIdlePerHost = defaultMaxIdleConnsPerHost
+	}
+	idleTimeout := time.Duration(cfg.IdleConnTimeoutSeconds) * time.Second
+	if idleTimeout <= 0 {
+		idleTimeout = defaultIdleConnTimeout
+	}
+
+	transport := &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: defaultKeepAlive,
+		}).DialContext,
+		ForceAttemptHTTP2:     cfg.EnableHTTP2,
+		MaxIdleConns:          maxIdle,
+		MaxIdleConnsPerHost:   maxIdlePerHost,
+		IdleConnTimeout:       idleTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		// compressionTransport negotiates gzip itself so it can measure wire sizes
+		DisableCompression: true,
+	}
+	if !cfg.EnableHTTP2 {
+		// A non-nil empty map disables the automatic HTTP/2 upgrade
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return transport
+}
+
+// connectionTransport records connection reuse and protocol for every request using
+// httptrace, so bulk workloads can confirm they are riding persistent connections
+type connectionTransport struct {
+	base *http.Transport
+
+	requests          atomic.Int64
+	newConnections    atomic.Int64
+	reusedConnections atomic.Int64
+	tlsHandshakes     atomic.Int64
+	connectNanos      atomic.Int64
+	http2Responses    atomic.Int64
+	http1Responses    atomic.Int64
+}
+
+func newConnectionTransport(base *http.Transport) *connectionTransport {
+	return &connectionTransport{base: base}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *connectionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var connectStart atomic.Int64
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.reusedConnections.Add(1)
+			} else {
+				t.newConnections.Add(1)
+			}
+		},
+		ConnectStart: func(string, string) {
+			connectStart.Store(time.Now().UnixNano())
+		},
+		ConnectDone: func(string, string, error) {
+			if start := connectStart.Load(); start != 0 {
+				t.connectNanos.Add(time.Now().UnixNano() - start)
+			}
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.tlsHandshakes.Add(1)
+		},
+	}
+
+	t.requests.Add(1)
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(req.Context(), trace)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.ProtoMajor == 2 {
+		t.http2Responses.Add(1)
+	} else {
+		t.http1Responses.Add(1)
+	}
+	resp.Body = &drainingReadCloser{ReadCloser: resp.Body}
+	return resp, nil
+}
+
+// drainingReadCloser discards the unread tail of a response body before closing it. JSON
+// decoders stop at the end of the value, and an HTTP/1.1 connection is only reused once its
+// body has been read to EOF.
+type drainingReadCloser struct {
+	io.ReadCloser
+}
+
+func (d *drainingReadCloser) Close() error {
+	io.CopyN(io.Discard, d.ReadCloser, maxDrainBytes)
+	return d.ReadCloser.Close()
+}
+
+// Stats returns a snapshot of the connection counters and pool settings
+func (t *connectionTransport) Stats() ConnectionStats {
+	return ConnectionStats{
+		Requests:            t.requests.Load(),
+		NewConnections:      t.newConnections.Load(),
+		ReusedConnections:   t.reusedConnections.Load(),
+		TLSHandshakes:       t.tlsHandshakes.Load(),
+		ConnectTime:         time.Duration(t.connectNanos.Load()),
+		HTTP2Responses:      t.http2Responses.Load(),
+		HTTP1Responses:      t.http1Responses.Load(),
+		MaxIdleConns:        t.base.MaxIdleConns,
+		MaxIdleConnsPerHost: t.base.MaxIdleConnsPerHost,
+		IdleConnTimeout:     t.base.IdleConnTimeout,
+		HTTP2Enabled:        t.base.ForceAttemptHTTP2,
+	}
+}
@@ -0,0 +1,97 @@
+package forward
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionTransport_ReusesConnections(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		// Trailing padding is left unread by the JSON decoder and must be drained for reuse
+		w.Write([]byte(`[{"id":"1","name":"net"}]` + strings.Repeat(" ", 8192)))
+	}))
+	defer server.Close()
+
+	client := newCompressionTestClient(server.URL, true, false)
+	for i := 0; i < 5; i++ {
+		networks, err := client.GetNetworks()
+		assert.NoError(t, err)
+		assert.Len(t, networks, 1)
+	}
+
+	stats := client.ConnectionStats()
+	assert.Equal(t, int64(5), stats.Requests)
+	assert.Equal(t, int64(1), stats.NewConnections)
+	assert.Equal(t, int64(4), stats.ReusedConnections)
+	assert.Equal(t, int64(5), stats.HTTP1Responses)
+	assert.Equal(t, 80.0, stats.ReuseRatePercent())
+}
+
+func TestConnectionTransport_HTTP2(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[]`))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	client := NewClient(&config.ForwardConfig{
+		APIBaseURL:         server.URL,
+		Timeout:            10,
+		InsecureSkipVerify: true,
+		EnableHTTP2:        true,
+	}).(*Client)
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetNetworks()
+		assert.NoError(t, err)
+	}
+
+	stats := client.ConnectionStats()
+	assert.True(t, stats.HTTP2Enabled)
+	assert.Equal(t, int64(3), stats.HTTP2Responses)
+	assert.Equal(t, int64(1), stats.NewConnections)
+	assert.Equal(t, int64(1), stats.TLSHandshakes)
+}
+
+func TestNewPooledTransport(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		transport := newPooledTransport(&config.ForwardConfig{EnableHTTP2: true}, nil)
+		assert.Equal(t, defaultMaxIdleConns, transport.MaxIdleConns)
+		assert.Equal(t, defaultMaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, defaultIdleConnTimeout, transport.IdleConnTimeout)
+		assert.True(t, transport.ForceAttemptHTTP2)
+		assert.Nil(t, transport.TLSNextProto)
+		assert.True(t, transport.DisableCompression)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		transport := newPooledTransport(&config.ForwardConfig{
+			MaxIdleConns:           20,
+			MaxIdleConnsPerHost:    8,
+			IdleConnTimeoutSeconds: 15,
+		}, nil)
+		assert.Equal(t, 20, transport.MaxIdleConns)
+		assert.Equal(t, 8, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, 15*time.Second, transport.IdleConnTimeout)
+		assert.False(t, transport.ForceAttemptHTTP2)
+		assert.NotNil(t, transport.TLSNextProto)
+	})
+}
+
+func TestConnectionStats_Averages(t *testing.T) {
+	assert.Equal(t, 0.0, ConnectionStats{}.ReuseRatePercent())
+	assert.Equal(t, time.Duration(0), ConnectionStats{}.AverageConnectTime())
+
+	stats := ConnectionStats{NewConnections: 2, ReusedConnections: 6, ConnectTime: 10 * time.Millisecond}
+	assert.Equal(t, 75.0, stats.ReuseRatePercent())
+	assert.Equal(t, 5*time.Millisecond, stats.AverageConnectTime())
+}
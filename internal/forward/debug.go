@@ -0,0 +1,438 @@
+package forward
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// API debug capture modes
+const (
+	APIDebugOff    = ""
+	APIDebugErrors = "errors" // Log failed exchanges only
+	APIDebugFull   = "full"   // Log every exchange
+)
+
+const (
+	defaultDebugBodyBytes = 16 << 10 // Body bytes kept per captured request or response
+	defaultDebugFileBytes = 10 << 20 // Debug log size before it is rotated
+	debugLogBackups       = 3        // Rotated debug logs kept next to the current one
+	MaxRecentAPIErrors    = 50       // Failed exchanges kept for RecentAPIErrors
+	apiErrorSnippetBytes  = 1 << 10  // Body bytes kept per recorded failure
+	maxErrorBodyBytes     = 1 << 20  // Bytes read from a failed response; the rest is dropped
+	redactedValue         = "[REDACTED]"
+)
+
+// sensitiveHeaders never appear in captured exchanges
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true, "Proxy-Authorization": true, "Cookie": true, "Set-Cookie": true, "X-Api-Key": true,
+}
+
+// sensitiveKeyPattern matches JSON keys whose values are masked
+var sensitiveKeyPattern = regexp.MustCompile(`(?i)pass(word|phrase)?|secret|token|api[-_]?key|authorization|credential|private[-_]?key|community`)
+
+// sensitiveTextPatterns mask secrets in text and in JSON cut short by the size cap; the first
+// group is kept. Device config secrets (SNMP communities, passwords, AAA keys and password
+// hashes) are always masked, since NQE results and configs pass through the API.
+var sensitiveTextPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)("[^"]*(?:pass(?:word|phrase)?|secret|token|api[-_]?key|credential|private[-_]?key|community)[^"]*"\s*:\s*")[^"]*`),
+	regexp.MustCompile(`(?i)(\b(?:basic|bearer)\s+)[A-Za-z0-9+/=._-]{12,}`),
+	regexp.MustCompile(`(?i)((?:password|secret|token|api[-_]?key)["']?\s*[:=]\s*["']?)[^\s"'&,}]+`),
+	regexp.MustCompile(`(?i)((?:\b|\\[nrt])snmp-server\s+community\s+)[^\s"'\\,]+`),
+	regexp.MustCompile(`(?i)((?:\b|\\[nrt])(?:password|passwd|secret|pre-shared-key|key-string|authentication-key)\s+(?:[0-9]\s+)?)[^\s"'\\,]+`),
+	regexp.MustCompile(`(?i)((?:\b|\\[nrt])(?:tacacs-server|radius-server|tacacs|radius)\b[^\n\\"]*?\bkey\s+(?:[0-9]\s+)?)[^\s"'\\,]+`),
+	regexp.MustCompile(`()\$(?:1|5|6|8|9|y|2[aby])\$[./A-Za-z0-9$]{8,}`),
+}
+
+// APIExchangeError is a failed request to the Forward API
+type APIExchangeError struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Status       int       `json:"status,omitempty"` // 0 when no response was received
+	Duration     string    `json:"duration"`
+	Error        string    `json:"error,omitempty"`
+	RequestBody  string    `json:"request_body,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"`
+}
+
+// APIErrorReporter is implemented by clients that keep their recent failed requests
+type APIErrorReporter interface {
+	RecentAPIErrors() []APIExchangeError
+}
+
+// DebugRedactorSetter is implemented by clients that accept an extra redact function for
+// captured API exchanges
+type DebugRedactorSetter interface {
+	SetDebugRedactor(redact func(string) string)
+}
+
+// apiDebugMode normalizes the configured debug capture mode; unknown values disable logging
+func apiDebugMode(mode string) string {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case APIDebugErrors, APIDebugFull:
+		return mode
+	default:
+		return APIDebugOff
+	}
+}
+
+// openDebugLog opens the debug log of a capture mode. Without a file, or when it cannot be
+// opened, exchanges are not logged but failures are still recorded.
+func openDebugLog(config *config.ForwardConfig) *rotatingFile {
+	if apiDebugMode(config.APIDebug) == APIDebugOff || config.APIDebugFile == "" {
+		return nil
+	}
+	log, err := newRotatingFile(config.APIDebugFile, int64(config.APIDebugMaxFileMB)<<20, debugLogBackups)
+	if err != nil {
+		logger.New().Warn("Forward API debug log disabled: %v", err)
+		return nil
+	}
+	return log
+}
+
+// debugExchange is one line of the debug log
+type debugExchange struct {
+	Time            time.Time         `json:"time"`
+	Method          string            `json:"method"`
+	URL             string            `json:"url"`
+	Status          int               `json:"status,omitempty"`
+	DurationMS      int64             `json:"duration_ms"`
+	Error           string            `json:"error,omitempty"`
+	RequestHeaders  map[string]string `json:"request_headers,omitempty"`
+	RequestBody     string            `json:"request_body,omitempty"`
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+	ResponseBody    string            `json:"response_body,omitempty"`
+	Truncated       bool              `json:"truncated,omitempty"`
+}
+
+// debugTransport records failed exchanges with the Forward API and, in a debug capture
+// mode, logs sanitized requests and responses to a rotating file. It wraps the compression
+// transport so bodies are seen decompressed. Secrets are masked before anything is kept:
+// credential headers, sensitive JSON keys, device config secrets, the configured API key and
+// secret, and the optional redact function.
+type debugTransport struct {
+	base     http.RoundTripper
+	mode     string
+	maxBody  int
+	log      *rotatingFile
+	secrets  []string
+	mu       sync.Mutex
+	recent   []APIExchangeError
+	redactMu sync.RWMutex
+	redact   func(string) string
+}
+
+func newDebugTransport(base http.RoundTripper, mode string, log *rotatingFile, maxBody int, secrets ...string) *debugTransport {
+	if maxBody <= 0 {
+		maxBody = defaultDebugBodyBytes
+	}
+	transport := &debugTransport{base: base, mode: mode, maxBody: maxBody, log: log}
+	for _, secret := range secrets {
+		if len(secret) >= 4 {
+			transport.secrets = append(transport.secrets, secret)
+		}
+	}
+	return transport
+}
+
+// setRedactor adds a redact function applied after the built-in masking
+func (t *debugTransport) setRedactor(redact func(string) string) {
+	t.redactMu.Lock()
+	defer t.redactMu.Unlock()
+	t.redact = redact
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		requestBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(requestBody))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(requestBody)), nil }
+	}
+
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	exchange := &debugExchange{
+		Time:           start,
+		Method:         req.Method,
+		URL:            t.sanitize(req.URL.String()),
+		RequestHeaders: t.sanitizeHeaders(req.Header),
+		RequestBody:    t.sanitizeBody(requestBody, t.maxBody),
+	}
+	if err != nil {
+		exchange.DurationMS = time.Since(start).Milliseconds()
+		exchange.Error = t.sanitize(err.Error())
+		t.recordFailure(req, exchange, requestBody, nil)
+		t.write(exchange)
+		return nil, err
+	}
+	exchange.Status = resp.StatusCode
+	exchange.ResponseHeaders = t.sanitizeHeaders(resp.Header)
+
+	// Failed responses are small; read them now so the failure carries the API's message
+	if resp.StatusCode >= http.StatusBadRequest {
+		body, readErr := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		exchange.DurationMS = time.Since(start).Milliseconds()
+		exchange.ResponseBody = t.sanitizeBody(body, t.maxBody)
+		exchange.Truncated = len(body) > t.maxBody || len(requestBody) > t.maxBody
+		if readErr != nil {
+			exchange.Error = t.sanitize(readErr.Error())
+		}
+		t.recordFailure(req, exchange, requestBody, body)
+		t.write(exchange)
+		return resp, nil
+	}
+
+	// Successful bodies may be large; keep the first bytes as they are read and log on close
+	if t.mode == APIDebugFull {
+		resp.Body = &capturingReadCloser{ReadCloser: resp.Body, limit: t.maxBody, done: func(captured []byte, total int) {
+			exchange.DurationMS = time.Since(start).Milliseconds()
+			exchange.ResponseBody = t.sanitizeCaptured(captured, total, t.maxBody)
+			exchange.Truncated = total > t.maxBody || len(requestBody) > t.maxBody
+			t.write(exchange)
+		}}
+	}
+	return resp, nil
+}
+
+// recordFailure keeps a failed exchange for RecentAPIErrors
+func (t *debugTransport) recordFailure(req *http.Request, exchange *debugExchange, requestBody, responseBody []byte) {
+	failure := APIExchangeError{
+		Time:         exchange.Time,
+		Method:       exchange.Method,
+		Path:         t.sanitize(req.URL.RequestURI()),
+		Status:       exchange.Status,
+		Duration:     (time.Duration(exchange.DurationMS) * time.Millisecond).String(),
+		Error:        exchange.Error,
+		RequestBody:  t.sanitizeBody(requestBody, apiErrorSnippetBytes),
+		ResponseBody: t.sanitizeBody(responseBody, apiErrorSnippetBytes),
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recent = append(t.recent, failure)
+	if len(t.recent) > MaxRecentAPIErrors {
+		t.recent = t.recent[len(t.recent)-MaxRecentAPIErrors:]
+	}
+}
+
+// RecentAPIErrors returns the kept failed exchanges, most recent first
+func (t *debugTransport) RecentAPIErrors() []APIExchangeError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	failures := make([]APIExchangeError, len(t.recent))
+	for i, failure := range t.recent {
+		failures[len(t.recent)-1-i] = failure
+	}
+	return failures
+}
+
+// write logs an exchange in the debug capture modes
+func (t *debugTransport) write(exchange *debugExchange) {
+	if t.log == nil || t.mode == APIDebugOff {
+		return
+	}
+	if t.mode == APIDebugErrors && exchange.Error == "" && exchange.Status < http.StatusBadRequest {
+		return
+	}
+	line, err := json.Marshal(exchange)
+	if err != nil {
+		return
+	}
+	t.log.Write(append(line, '\n'))
+}
+
+// sanitizeHeaders copies headers with credentials masked
+func (t *debugTransport) sanitizeHeaders(header http.Header) map[string]string {
+	if len(header) == 0 {
+		return nil
+	}
+	sanitized := make(map[string]string, len(header))
+	for name, values := range header {
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			sanitized[name] = redactedValue
+			continue
+		}
+		sanitized[name] = t.sanitize(strings.Join(values, ", "))
+	}
+	return sanitized
+}
+
+// sanitizeBody masks secrets in a body and cuts it to limit bytes. JSON bodies have the
+// values of sensitive keys masked; other bodies are masked by pattern.
+func (t *debugTransport) sanitizeBody(body []byte, limit int) string {
+	return t.sanitizeCaptured(body, len(body), limit)
+}
+
+// sanitizeCaptured is sanitizeBody for the first bytes of a body of total bytes
+func (t *debugTransport) sanitizeCaptured(body []byte, total, limit int) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var text string
+	var decoded interface{}
+	if json.Unmarshal(body, &decoded) == nil {
+		encoded, _ := json.Marshal(maskSensitiveKeys(decoded))
+		text = string(encoded)
+	} else {
+		text = string(body)
+	}
+	text = t.sanitize(text)
+	if len(text) > limit || total > len(body) {
+		text = text[:min(len(text), limit)] + fmt.Sprintf("... [%d bytes]", total)
+	}
+	return text
+}
+
+// sanitize masks credentials in free text
+func (t *debugTransport) sanitize(text string) string {
+	for _, secret := range t.secrets {
+		text = strings.ReplaceAll(text, secret, redactedValue)
+	}
+	for _, pattern := range sensitiveTextPatterns {
+		text = pattern.ReplaceAllString(text, "${1}"+redactedValue)
+	}
+	t.redactMu.RLock()
+	redact := t.redact
+	t.redactMu.RUnlock()
+	if redact != nil {
+		text = redact(text)
+	}
+	return text
+}
+
+// maskSensitiveKeys replaces the values of sensitive keys in decoded JSON
+func maskSensitiveKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if sensitiveKeyPattern.MatchString(key) {
+				v[key] = redactedValue
+			} else {
+				v[key] = maskSensitiveKeys(item)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = maskSensitiveKeys(item)
+		}
+	}
+	return value
+}
+
+// capturingReadCloser keeps the first limit bytes read and reports them once, at EOF or close
+type capturingReadCloser struct {
+	io.ReadCloser
+	limit    int
+	captured []byte
+	total    int
+	once     sync.Once
+	done     func(captured []byte, total int)
+}
+
+func (c *capturingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	if room := c.limit - len(c.captured); room > 0 {
+		c.captured = append(c.captured, p[:min(n, room)]...)
+	}
+	c.total += n
+	if err == io.EOF {
+		c.finish()
+	}
+	return n, err
+}
+
+func (c *capturingReadCloser) Close() error {
+	c.finish()
+	return c.ReadCloser.Close()
+}
+
+func (c *capturingReadCloser) finish() {
+	c.once.Do(func() { c.done(c.captured, c.total) })
+}
+
+// rotatingFile appends to a log file, moving it to .1 (and older logs up to .N) once it
+// would grow past maxBytes
+type rotatingFile struct {
+	path     string
+	maxBytes int64
+	backups  int
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+}
+
+func newRotatingFile(path string, maxBytes int64, backups int) (*rotatingFile, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultDebugFileBytes
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create debug log directory: %w", err)
+	}
+	r := &rotatingFile{path: path, maxBytes: maxBytes, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to open debug log: %w", err)
+	}
+	r.file, r.size = file, info.Size()
+	return nil
+}
+
+// Write appends p, rotating first when the file would exceed its size cap
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) rotate() error {
+	r.file.Close()
+	for i := r.backups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if r.backups > 0 {
+		os.Rename(r.path, r.path+".1")
+	} else {
+		os.Remove(r.path)
+	}
+	return r.open()
+}
@@ -0,0 +1,174 @@
+package forward
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDebugTestClient(serverURL, mode, logFile string) *Client {
+	return NewClient(&config.ForwardConfig{
+		APIKey:               "test-api-key",
+		APISecret:            "test-api-secret",
+		APIBaseURL:           serverURL,
+		Timeout:              10,
+		APIDebug:             mode,
+		APIDebugFile:         logFile,
+		APIDebugMaxBodyBytes: 64,
+	}).(*Client)
+}
+
+func readDebugLog(t *testing.T, path string) []debugExchange {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var exchanges []debugExchange
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var exchange debugExchange
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &exchange))
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges
+}
+
+func TestDebugTransport_RecordsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"bad credentials","token":"abc123secret"}`))
+	}))
+	defer server.Close()
+
+	client := newDebugTestClient(server.URL, "", "")
+	_, err := client.GetNetworks()
+	assert.Error(t, err)
+
+	failures := client.RecentAPIErrors()
+	require.Len(t, failures, 1)
+	assert.Equal(t, "GET", failures[0].Method)
+	assert.Equal(t, "/api/networks", failures[0].Path)
+	assert.Equal(t, http.StatusUnauthorized, failures[0].Status)
+	assert.Contains(t, failures[0].ResponseBody, "bad credentials")
+	assert.NotContains(t, failures[0].ResponseBody, "abc123secret")
+
+	// Transport errors are recorded without a status
+	server.Close()
+	_, err = client.GetNetworks()
+	assert.Error(t, err)
+	failures = client.RecentAPIErrors()
+	require.Len(t, failures, 2)
+	assert.Zero(t, failures[0].Status)
+	assert.NotEmpty(t, failures[0].Error)
+}
+
+func TestDebugTransport_MasksConfigSecretsWithoutRedactor(t *testing.T) {
+	config := `{"message":"bad query","config":"snmp-server community s3cr3t-comm RO\n enable secret 5 $1$abcd$0123456789abcdef\n tacacs-server host 10.0.0.1 key 7 tacKey99\n username admin password 0 hunter2"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(config))
+		w.Write([]byte(strings.Repeat(" ", maxErrorBodyBytes)))
+	}))
+	defer server.Close()
+
+	client := newDebugTestClient(server.URL, "", "")
+	_, err := client.GetNetworks()
+	require.Error(t, err)
+	assert.Less(t, len(err.Error()), maxErrorBodyBytes+200, "error bodies are read up to the cap")
+
+	failures := client.RecentAPIErrors()
+	require.Len(t, failures, 1)
+	for _, secret := range []string{"s3cr3t-comm", "$1$abcd$0123456789abcdef", "tacKey99", "hunter2"} {
+		assert.NotContains(t, failures[0].ResponseBody, secret)
+	}
+	assert.Contains(t, failures[0].ResponseBody, "bad query")
+}
+
+func TestDebugTransport_FullCaptureRedacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Contains(t, string(body), "hunter2", "the request body must reach the server unchanged")
+		w.Header().Set("Set-Cookie", "session=abcdef")
+		w.Write([]byte(`{"items":[{"name":"leaf-1","snmpCommunity":"public123"}],"padding":"` + strings.Repeat("x", 200) + `"}`))
+	}))
+	defer server.Close()
+
+	logFile := filepath.Join(t.TempDir(), "api-debug.log")
+	client := newDebugTestClient(server.URL, "full", logFile)
+	client.SetDebugRedactor(func(text string) string { return strings.ReplaceAll(text, "leaf-1", "[MASKED]") })
+
+	req, err := http.NewRequest("POST", server.URL+"/api/nqe?apiKey=test-api-key", strings.NewReader(`{"query":"q","password":"hunter2"}`))
+	require.NoError(t, err)
+	req.SetBasicAuth("test-api-key", "test-api-secret")
+	resp, err := client.httpClient.Do(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(body), "public123", "callers must see the response unchanged")
+
+	exchanges := readDebugLog(t, logFile)
+	require.Len(t, exchanges, 1)
+	exchange := exchanges[0]
+	assert.Equal(t, http.StatusOK, exchange.Status)
+	assert.Equal(t, redactedValue, exchange.RequestHeaders["Authorization"])
+	assert.Equal(t, redactedValue, exchange.ResponseHeaders["Set-Cookie"])
+	assert.NotContains(t, exchange.URL, "test-api-key")
+	assert.Contains(t, exchange.RequestBody, `"password":"[REDACTED]"`)
+	assert.Contains(t, exchange.ResponseBody, "[MASKED]")
+	assert.NotContains(t, exchange.ResponseBody, "public123")
+	assert.True(t, exchange.Truncated)
+	assert.LessOrEqual(t, len(exchange.ResponseBody), 64+len("... [999 bytes]"))
+	assert.Empty(t, client.RecentAPIErrors())
+}
+
+func TestDebugTransport_ErrorsModeSkipsSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/networks" {
+			w.Write([]byte(`[]`))
+			return
+		}
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	logFile := filepath.Join(t.TempDir(), "api-debug.log")
+	client := newDebugTestClient(server.URL, "ERRORS", logFile)
+	_, err := client.GetNetworks()
+	assert.NoError(t, err)
+	_, err = client.GetSnapshots("missing")
+	assert.Error(t, err)
+
+	exchanges := readDebugLog(t, logFile)
+	require.Len(t, exchanges, 1)
+	assert.Equal(t, http.StatusNotFound, exchanges[0].Status)
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "debug.log")
+	file, err := newRotatingFile(path, 100, 2)
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := file.Write([]byte(fmt.Sprintf("%s\n", strings.Repeat(fmt.Sprint(i), 39))))
+		require.NoError(t, err)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(100))
+	}
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+	current, _ := os.ReadFile(path)
+	assert.True(t, strings.HasPrefix(string(current), strings.Repeat("8", 39)))
+}
@@ -0,0 +1,186 @@
+package forward
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// minCompressedRequestBytes is the smallest request body worth compressing
+const minCompressedRequestBytes = 1024
+
+// TransferStats reports request and response sizes on the wire and after decompression
+type TransferStats struct {
+	Requests            int64 `json:"requests"`
+	CompressedRequests  int64 `json:"compressed_requests"`
+	RequestBytes        int64 `json:"request_bytes"`
+	RequestWireBytes    int64 `json:"request_wire_bytes"`
+	CompressedResponses int64 `json:"compressed_responses"`
+	ResponseBytes       int64 `json:"response_bytes"`
+	ResponseWireBytes   int64 `json:"response_wire_bytes"`
+	RequestCompression  bool  `json:"request_compression"`
+}
+
+// ResponseSavingsPercent is the share of response bytes saved by compression
+func (s TransferStats) ResponseSavingsPercent() float64 {
+	if s.ResponseBytes == 0 {
+		return 0
+	}
+	return float64(s.ResponseBytes-s.ResponseWireBytes) / float64(s.ResponseBytes) * 100
+}
+
+// TransferStatsReporter is implemented by clients that track transfer sizes
+type TransferStatsReporter interface {
+	TransferStats() TransferStats
+}
+
+// compressionTransport negotiates gzip with the Forward API and counts transfer sizes.
+// Responses are requested with Accept-Encoding: gzip and decompressed here rather than by
+// net/http so that wire sizes can be measured. Request bodies are gzipped when enabled; if
+// the server rejects them with 415 the request is resent uncompressed and request
+// compression is switched off for the rest of the session.
+type compressionTransport struct {
+	base             http.RoundTripper
+	gzipResponses    bool
+	compressRequests atomic.Bool
+
+	requests            atomic.Int64
+	compressedRequests  atomic.Int64
+	requestBytes        atomic.Int64
+	requestWireBytes    atomic.Int64
+	compressedResponses atomic.Int64
+	responseBytes       atomic.Int64
+	responseWireBytes   atomic.Int64
+}
+
+func newCompressionTransport(base http.RoundTripper, gzipResponses, compressRequests bool) *compressionTransport {
+	transport := &compressionTransport{base: base, gzipResponses: gzipResponses}
+	transport.compressRequests.Store(compressRequests)
+	return transport
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *compressionTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if t.compressRequests.Load() && len(body) >= minCompressedRequestBytes && req.Header.Get("Content-Encoding") == "" {
+		if compressed, ok := gzipBytes(body); ok {
+			resp, err := t.send(req, body, compressed)
+			if err != nil || resp.StatusCode != http.StatusUnsupportedMediaType {
+				return resp, err
+			}
+			resp.Body.Close()
+			t.compressRequests.Store(false)
+		}
+	}
+	return t.send(req, body, nil)
+}
+
+// send issues one attempt, using the compressed body when given
+func (t *compressionTransport) send(original *http.Request, body, compressed []byte) (*http.Response, error) {
+	req := original.Clone(original.Context())
+	payload := body
+	if compressed != nil {
+		payload = compressed
+		req.Header.Set("Content-Encoding", "gzip")
+		t.compressedRequests.Add(1)
+	}
+	if len(payload) > 0 {
+		req.Body = io.NopCloser(bytes.NewReader(payload))
+		req.ContentLength = int64(len(payload))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(payload)), nil }
+	} else if original.Body != nil {
+		req.Body = http.NoBody
+		req.ContentLength = 0
+	}
+	if t.gzipResponses && req.Header.Get("Accept-Encoding") == "" {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+
+	t.requests.Add(1)
+	t.requestBytes.Add(int64(len(body)))
+	t.requestWireBytes.Add(int64(len(payload)))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	wire := &countingReadCloser{ReadCloser: resp.Body, count: &t.responseWireBytes}
+	if strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		decoded, err := gzip.NewReader(wire)
+		if err != nil {
+			wire.Close()
+			return nil, err
+		}
+		t.compressedResponses.Add(1)
+		resp.Body = &countingReadCloser{ReadCloser: &gzipReadCloser{Reader: decoded, wire: wire}, count: &t.responseBytes}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	} else {
+		resp.Body = &countingReadCloser{ReadCloser: wire, count: &t.responseBytes}
+	}
+	return resp, nil
+}
+
+// Stats returns a snapshot of the transfer counters
+func (t *compressionTransport) Stats() TransferStats {
+	return TransferStats{
+		Requests:            t.requests.Load(),
+		CompressedRequests:  t.compressedRequests.Load(),
+		RequestBytes:        t.requestBytes.Load(),
+		RequestWireBytes:    t.requestWireBytes.Load(),
+		CompressedResponses: t.compressedResponses.Load(),
+		ResponseBytes:       t.responseBytes.Load(),
+		ResponseWireBytes:   t.responseWireBytes.Load(),
+		RequestCompression:  t.compressRequests.Load(),
+	}
+}
+
+func gzipBytes(data []byte) ([]byte, bool) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		return nil, false
+	}
+	if err := writer.Close(); err != nil {
+		return nil, false
+	}
+	return buffer.Bytes(), true
+}
+
+// countingReadCloser adds the number of bytes read to count
+type countingReadCloser struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (c *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.count.Add(int64(n))
+	return n, err
+}
+
+// gzipReadCloser closes both the gzip reader and the underlying response body
+type gzipReadCloser struct {
+	*gzip.Reader
+	wire io.Closer
+}
+
+func (g *gzipReadCloser) Close() error {
+	g.Reader.Close()
+	return g.wire.Close()
+}
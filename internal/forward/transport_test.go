@@ -0,0 +1,155 @@
+package forward
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCompressionTestClient(serverURL string, gzipResponses, compressRequests bool) *Client {
+	return NewClient(&config.ForwardConfig{
+		APIKey:           "test-api-key",
+		APISecret:        "test-api-secret",
+		APIBaseURL:       serverURL,
+		Timeout:          10,
+		GzipResponses:    gzipResponses,
+		CompressRequests: compressRequests,
+	}).(*Client)
+}
+
+func TestCompressionTransport_GzipResponses(t *testing.T) {
+	networks := make([]Network, 200)
+	for i := range networks {
+		networks[i] = Network{ID: "network-id", Name: "a network with a fairly repetitive name"}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Accept-Encoding"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		json.NewEncoder(writer).Encode(networks)
+		writer.Close()
+	}))
+	defer server.Close()
+
+	client := newCompressionTestClient(server.URL, true, false)
+	result, err := client.GetNetworks()
+	assert.NoError(t, err)
+	assert.Len(t, result, len(networks))
+
+	stats := client.TransferStats()
+	assert.Equal(t, int64(1), stats.Requests)
+	assert.Equal(t, int64(1), stats.CompressedResponses)
+	assert.Greater(t, stats.ResponseBytes, stats.ResponseWireBytes)
+	assert.Greater(t, stats.ResponseSavingsPercent(), 50.0)
+}
+
+func TestCompressionTransport_GzipResponsesDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotContains(t, r.Header.Get("Accept-Encoding"), "gzip")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":"1","name":"net"}]`))
+	}))
+	defer server.Close()
+
+	client := newCompressionTestClient(server.URL, false, false)
+
+	result, err := client.GetNetworks()
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+
+	stats := client.TransferStats()
+	assert.Equal(t, int64(0), stats.CompressedResponses)
+	assert.Equal(t, stats.ResponseBytes, stats.ResponseWireBytes)
+}
+
+func TestCompressionTransport_CompressRequests(t *testing.T) {
+	locations := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		locations[strings.Repeat("d", i+1)] = "site-1"
+	}
+
+	var received map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		reader, err := gzip.NewReader(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.NewDecoder(reader).Decode(&received))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newCompressionTestClient(server.URL, true, true)
+	assert.NoError(t, client.UpdateDeviceLocations("network-1", locations))
+	assert.Equal(t, locations, received)
+
+	stats := client.TransferStats()
+	assert.Equal(t, int64(1), stats.CompressedRequests)
+	assert.Greater(t, stats.RequestBytes, stats.RequestWireBytes)
+	assert.True(t, stats.RequestCompression)
+}
+
+func TestCompressionTransport_SmallRequestsStayUncompressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get("Content-Encoding"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := newCompressionTestClient(server.URL, true, true)
+	assert.NoError(t, client.UpdateDeviceLocations("network-1", map[string]string{"router-1": "site-1"}))
+	assert.Equal(t, int64(0), client.TransferStats().CompressedRequests)
+}
+
+func TestCompressionTransport_FallsBackOnUnsupportedMediaType(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		assert.True(t, json.Valid(body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	locations := map[string]string{"router-1": strings.Repeat("site", 512)}
+	client := newCompressionTestClient(server.URL, true, true)
+
+	assert.NoError(t, client.UpdateDeviceLocations("network-1", locations))
+	assert.Equal(t, 2, attempts)
+	assert.False(t, client.TransferStats().RequestCompression)
+
+	// Later requests go out uncompressed without another rejected attempt
+	assert.NoError(t, client.UpdateDeviceLocations("network-1", locations))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestGzipBytes(t *testing.T) {
+	data := bytes.Repeat([]byte("forward"), 500)
+	compressed, ok := gzipBytes(data)
+	assert.True(t, ok)
+	assert.Less(t, len(compressed), len(data))
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	assert.NoError(t, err)
+	decoded, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, data, decoded)
+}
+
+func TestTransferStats_ResponseSavingsPercent(t *testing.T) {
+	assert.Equal(t, 0.0, TransferStats{}.ResponseSavingsPercent())
+	assert.Equal(t, 75.0, TransferStats{ResponseBytes: 400, ResponseWireBytes: 100}.ResponseSavingsPercent())
+}
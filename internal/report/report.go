@@ -0,0 +1,256 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Output formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+	FormatPDF      = "pdf"
+)
+
+// Template file names; a template directory may override either one
+const (
+	markdownTemplate = "report.md.tmpl"
+	htmlTemplate     = "report.html.tmpl"
+)
+
+// pdfConversionTimeout bounds a PDF conversion, so a hung headless browser cannot block the
+// tool call that requested the report
+const pdfConversionTimeout = 2 * time.Minute
+
+// ErrNoPDFConverter is returned when PDF output is requested without a headless converter
+var ErrNoPDFConverter = errors.New("PDF output requires a headless converter: install wkhtmltopdf or Chrome/Chromium, or set FORWARD_PDF_CONVERTER")
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// Report is a format-independent document rendered by the templates
+type Report struct {
+	Title       string
+	Subtitle    string
+	GeneratedAt time.Time
+	Facts       []Fact
+	Sections    []Section
+}
+
+// Fact is a labeled headline value shown below the title
+type Fact struct {
+	Label string
+	Value string
+}
+
+// Section is a titled block with any of a paragraph, a bullet list, a table and a code block
+type Section struct {
+	Title string
+	Text  string
+	Items []string
+	Table *Table
+	Code  string
+	// Language tags the code block, e.g. mermaid for diagrams
+	Language string
+	// Status styles the section in HTML: ok, warn or fail
+	Status string
+}
+
+// Table is a simple grid of preformatted cells
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Renderer renders reports with the built-in templates or overrides from a directory
+type Renderer struct {
+	templateDir  string
+	pdfConverter string
+	pdfTimeout   time.Duration
+}
+
+// NewRenderer creates a renderer. templateDir may be empty to use only the built-in
+// templates; pdfConverter may be empty to detect wkhtmltopdf or Chrome on PATH.
+func NewRenderer(templateDir, pdfConverter string) *Renderer {
+	return &Renderer{templateDir: templateDir, pdfConverter: pdfConverter, pdfTimeout: pdfConversionTimeout}
+}
+
+// NormalizeFormat maps user input such as "md" or "HTML" to a supported format
+func NormalizeFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", "md", FormatMarkdown:
+		return FormatMarkdown, nil
+	case "htm", FormatHTML:
+		return FormatHTML, nil
+	case FormatPDF:
+		return FormatPDF, nil
+	}
+	return "", fmt.Errorf("unsupported report format %q (use markdown, html or pdf)", format)
+}
+
+// Extension returns the file extension for a format
+func Extension(format string) string {
+	switch format {
+	case FormatHTML:
+		return ".html"
+	case FormatPDF:
+		return ".pdf"
+	}
+	return ".md"
+}
+
+// Render renders the report in the given format
+func (r *Renderer) Render(doc *Report, format string) ([]byte, error) {
+	format, err := NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatHTML:
+		return r.renderHTML(doc)
+	case FormatPDF:
+		html, err := r.renderHTML(doc)
+		if err != nil {
+			return nil, err
+		}
+		return r.convertPDF(html)
+	}
+	return r.renderMarkdown(doc)
+}
+
+// templateSource returns the override from the template directory when present
+func (r *Renderer) templateSource(name string) (string, error) {
+	if r.templateDir != "" {
+		data, err := os.ReadFile(filepath.Join(r.templateDir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read report template %s: %w", name, err)
+		}
+	}
+	data, err := builtinTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("missing built-in report template %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+var templateFuncs = map[string]interface{}{
+	"cell":      markdownCell,
+	"timestamp": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+	"join":      strings.Join,
+}
+
+func (r *Renderer) renderMarkdown(doc *Report) ([]byte, error) {
+	source, err := r.templateSource(markdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(markdownTemplate).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report template %s: %w", markdownTemplate, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, doc); err != nil {
+		return nil, fmt.Errorf("failed to render markdown report: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+func (r *Renderer) renderHTML(doc *Report) ([]byte, error) {
+	source, err := r.templateSource(htmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := htmltemplate.New(htmlTemplate).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report template %s: %w", htmlTemplate, err)
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, doc); err != nil {
+		return nil, fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// markdownCell escapes pipes and newlines so a value stays within its table cell
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.ReplaceAll(value, "\n", " ")
+}
+
+// converterCommand returns the PDF converter command line with {input} and {output}
+// placeholders, detecting a converter on PATH when none is configured
+func (r *Renderer) converterCommand() ([]string, error) {
+	if r.pdfConverter != "" {
+		return strings.Fields(r.pdfConverter), nil
+	}
+	if path, err := exec.LookPath("wkhtmltopdf"); err == nil {
+		return []string{path, "--quiet", "{input}", "{output}"}, nil
+	}
+	for _, browser := range []string{"chromium", "chromium-browser", "google-chrome", "chrome"} {
+		if path, err := exec.LookPath(browser); err == nil {
+			return []string{path, "--headless", "--disable-gpu", "--no-pdf-header-footer", "--print-to-pdf={output}", "{input}"}, nil
+		}
+	}
+	return nil, ErrNoPDFConverter
+}
+
+var placeholderPattern = regexp.MustCompile(`\{(input|output)\}`)
+
+// convertPDF writes the HTML to a temporary directory and runs the converter on it, killing
+// the converter if it runs past the renderer's timeout
+func (r *Renderer) convertPDF(html []byte) ([]byte, error) {
+	command, err := r.converterCommand()
+	if err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp("", "forward-report-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "report.html")
+	output := filepath.Join(dir, "report.pdf")
+	if err := os.WriteFile(input, html, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write HTML for PDF conversion: %w", err)
+	}
+	args := make([]string, len(command))
+	for i, arg := range command {
+		args[i] = placeholderPattern.ReplaceAllStringFunc(arg, func(placeholder string) string {
+			if placeholder == "{input}" {
+				return input
+			}
+			return output
+		})
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.pdfTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	// Browsers start helper processes that may keep the output pipe open after a kill
+	cmd.WaitDelay = time.Second
+	if combined, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("PDF converter %s did not finish within %s", filepath.Base(args[0]), r.pdfTimeout)
+		}
+		return nil, fmt.Errorf("PDF converter %s failed: %w: %s", filepath.Base(args[0]), err, strings.TrimSpace(string(combined)))
+	}
+	pdf, err := os.ReadFile(output)
+	if err != nil {
+		return nil, fmt.Errorf("PDF converter produced no output: %w", err)
+	}
+	return pdf, nil
+}
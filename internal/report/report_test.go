@@ -0,0 +1,104 @@
+package report
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReport() *Report {
+	return &Report{
+		Title:       "Config Diff",
+		Subtitle:    "Network 162112",
+		GeneratedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Facts:       []Fact{{Label: "Devices changed", Value: "2"}},
+		Sections: []Section{
+			{Title: "Devices", Status: "warn", Table: &Table{Columns: []string{"Device", "Change"}, Rows: [][]string{{"core-1", "a|b"}, {"<edge>", "+3"}}}},
+			{Title: "Actions", Items: []string{"Review core-1"}, Code: "+ ntp server 10.0.0.1"},
+			{Title: "Diagram", Code: "graph LR", Language: "mermaid"},
+		},
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	out, err := NewRenderer("", "").Render(testReport(), "md")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := string(out)
+	for _, expected := range []string{"# Config Diff\n", "- **Devices changed:** 2", "_Generated 2026-03-01T12:00:00Z_",
+		"| Device | Change |\n|---|---|\n| core-1 | a\\|b |\n", "- Review core-1", "```\n+ ntp server 10.0.0.1\n```", "```mermaid\ngraph LR\n```"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in markdown, got:\n%s", expected, text)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	out, err := NewRenderer("", "").Render(testReport(), "HTML")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := string(out)
+	if !strings.Contains(text, `<section class="warn">`) || !strings.Contains(text, "<td>&lt;edge&gt;</td>") ||
+		!strings.Contains(text, `<pre class="language-mermaid">graph LR</pre>`) {
+		t.Errorf("Expected styled, escaped HTML, got:\n%s", text)
+	}
+	if _, err := NewRenderer("", "").Render(testReport(), "docx"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+}
+
+func TestTemplateOverride(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, markdownTemplate), []byte("custom: {{.Title}} ({{len .Sections}} sections)"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	renderer := NewRenderer(dir, "")
+	out, err := renderer.Render(testReport(), FormatMarkdown)
+	if err != nil || string(out) != "custom: Config Diff (3 sections)" {
+		t.Errorf("Expected the override template, got %q (%v)", out, err)
+	}
+	// Templates missing from the directory fall back to the built-in ones
+	if out, err := renderer.Render(testReport(), FormatHTML); err != nil || !strings.Contains(string(out), "<h1>Config Diff</h1>") {
+		t.Errorf("Expected the built-in HTML template, got %v", err)
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	// cp stands in for a headless browser: the "PDF" is the HTML it was given
+	out, err := NewRenderer("", "cp {input} {output}").Render(testReport(), FormatPDF)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(string(out), "<!DOCTYPE html>") {
+		t.Errorf("Expected the converter output, got %q", out)
+	}
+
+	if _, err := NewRenderer("", "false {input} {output}").Render(testReport(), FormatPDF); err == nil {
+		t.Error("Expected an error from a failing converter")
+	}
+
+	// A converter that hangs is killed at the deadline
+	script := filepath.Join(t.TempDir(), "hang.sh")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexec sleep 30\n"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	hanging := NewRenderer("", script+" {input} {output}")
+	hanging.pdfTimeout = 100 * time.Millisecond
+	started := time.Now()
+	if _, err := hanging.Render(testReport(), FormatPDF); err == nil || !strings.Contains(err.Error(), "did not finish within") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		t.Errorf("Expected the converter killed promptly, took %s", elapsed)
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := NewRenderer("", "").Render(testReport(), FormatPDF); !errors.Is(err, ErrNoPDFConverter) {
+		t.Errorf("Expected ErrNoPDFConverter without a converter, got %v", err)
+	}
+}
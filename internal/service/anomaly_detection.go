@@ -0,0 +1,446 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	// defaultAnomalyBaselineSize is how many earlier results of the same query form the baseline
+	defaultAnomalyBaselineSize = 5
+	// defaultAnomalyZThreshold is the z-score a numeric metric must reach once the baseline has variance
+	defaultAnomalyZThreshold = 3.0
+	// defaultAnomalyChangeThreshold is the relative change from the EWMA baseline that counts as a jump
+	defaultAnomalyChangeThreshold = 0.3
+	// anomalyEWMAAlpha weights recent baseline results more heavily than older ones
+	anomalyEWMAAlpha = 0.5
+	// maxCategoricalValues skips columns with more distinct values, which are identifiers rather than categories
+	maxCategoricalValues = 1000
+	// maxAnomalyValues caps the added/removed values listed per categorical anomaly
+	maxAnomalyValues = 50
+)
+
+// ResultAnomaly is an unusual change between the newest result of a query and its baseline
+type ResultAnomaly struct {
+	Metric   string   `json:"metric"` // row_count, sum(column) or values(column)
+	Kind     string   `json:"kind"`   // numeric or categorical
+	Current  float64  `json:"current,omitempty"`
+	Baseline float64  `json:"baseline,omitempty"` // EWMA of the baseline results
+	ZScore   float64  `json:"z_score,omitempty"`
+	Change   float64  `json:"change,omitempty"` // Relative change from the baseline
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Message  string   `json:"message"`
+}
+
+// AnomalyOptions tunes anomaly detection thresholds; zero values use the defaults
+type AnomalyOptions struct {
+	BaselineSize    int
+	ZThreshold      float64
+	ChangeThreshold float64
+}
+
+func (o AnomalyOptions) withDefaults() AnomalyOptions {
+	if o.BaselineSize <= 0 {
+		o.BaselineSize = defaultAnomalyBaselineSize
+	}
+	if o.ZThreshold <= 0 {
+		o.ZThreshold = defaultAnomalyZThreshold
+	}
+	if o.ChangeThreshold <= 0 {
+		o.ChangeThreshold = defaultAnomalyChangeThreshold
+	}
+	return o
+}
+
+// resultProfile holds the metrics of one stored result that anomaly detection compares
+type resultProfile struct {
+	EntityID    string
+	Numeric     map[string]float64
+	Categorical map[string]map[string]bool
+}
+
+// profileResultRows computes the row count, the sum of every numeric column and the distinct
+// values of every categorical column
+func profileResultRows(rows []map[string]interface{}) *resultProfile {
+	profile := &resultProfile{
+		Numeric:     map[string]float64{"row_count": float64(len(rows))},
+		Categorical: make(map[string]map[string]bool),
+	}
+
+	sums := make(map[string]float64)
+	nonNumeric := make(map[string]bool)
+	for _, row := range rows {
+		for column, value := range row {
+			switch v := value.(type) {
+			case nil:
+			case float64:
+				sums[column] += v
+			case int:
+				sums[column] += float64(v)
+			case int64:
+				sums[column] += float64(v)
+			case string, bool:
+				nonNumeric[column] = true
+				values := profile.Categorical[column]
+				if values == nil {
+					values = make(map[string]bool)
+					profile.Categorical[column] = values
+				}
+				values[fmt.Sprintf("%v", v)] = true
+			default:
+				nonNumeric[column] = true
+			}
+		}
+	}
+
+	for column, sum := range sums {
+		if !nonNumeric[column] {
+			profile.Numeric["sum("+column+")"] = sum
+		}
+	}
+	for column, values := range profile.Categorical {
+		if _, numeric := sums[column]; numeric || len(values) > maxCategoricalValues {
+			delete(profile.Categorical, column)
+		}
+	}
+	return profile
+}
+
+// detectResultAnomalies compares the current profile with a baseline ordered oldest to newest.
+// A numeric metric is flagged when it moved at least ChangeThreshold from the EWMA baseline and,
+// once three or more baseline results show variance, its z-score also reaches ZThreshold.
+// A categorical column is flagged when values appear that no baseline result had, or values
+// present in every baseline result disappear.
+func detectResultAnomalies(current *resultProfile, baseline []*resultProfile, opts AnomalyOptions) []ResultAnomaly {
+	opts = opts.withDefaults()
+	var anomalies []ResultAnomaly
+
+	metrics := make([]string, 0, len(current.Numeric))
+	for metric := range current.Numeric {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	for _, metric := range metrics {
+		var history []float64
+		for _, profile := range baseline {
+			if value, ok := profile.Numeric[metric]; ok {
+				history = append(history, value)
+			}
+		}
+		if len(history) == 0 {
+			continue
+		}
+		if anomaly, ok := numericAnomaly(metric, current.Numeric[metric], history, opts); ok {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+
+	columns := make([]string, 0, len(current.Categorical))
+	for column := range current.Categorical {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		if anomaly, ok := categoricalAnomaly(column, current.Categorical[column], baseline); ok {
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	return anomalies
+}
+
+func numericAnomaly(metric string, value float64, history []float64, opts AnomalyOptions) (ResultAnomaly, bool) {
+	ewma := history[0]
+	mean := 0.0
+	for i, sample := range history {
+		if i > 0 {
+			ewma = anomalyEWMAAlpha*sample + (1-anomalyEWMAAlpha)*ewma
+		}
+		mean += sample
+	}
+	mean /= float64(len(history))
+	variance := 0.0
+	for _, sample := range history {
+		variance += (sample - mean) * (sample - mean)
+	}
+	stddev := math.Sqrt(variance / float64(len(history)))
+
+	var change float64
+	switch {
+	case ewma != 0:
+		change = (value - ewma) / math.Abs(ewma)
+	case value != 0:
+		change = math.Copysign(1, value)
+	}
+	if math.Abs(change) < opts.ChangeThreshold {
+		return ResultAnomaly{}, false
+	}
+
+	anomaly := ResultAnomaly{
+		Metric: metric, Kind: "numeric", Current: value, Baseline: ewma,
+		Change: math.Round(change*1000) / 1000,
+	}
+	if len(history) >= 3 && stddev > 0 {
+		anomaly.ZScore = math.Round((value-mean)/stddev*100) / 100
+		if math.Abs(anomaly.ZScore) < opts.ZThreshold {
+			return ResultAnomaly{}, false
+		}
+	}
+
+	direction := "rose"
+	if change < 0 {
+		direction = "dropped"
+	}
+	anomaly.Message = fmt.Sprintf("%s %s %.1f%% (baseline %s → %s over %d earlier results",
+		metric, direction, math.Abs(change)*100, formatMetricValue(ewma), formatMetricValue(value), len(history))
+	if anomaly.ZScore != 0 {
+		anomaly.Message += fmt.Sprintf(", z=%.1f", anomaly.ZScore)
+	}
+	anomaly.Message += ")"
+	return anomaly, true
+}
+
+func categoricalAnomaly(column string, values map[string]bool, baseline []*resultProfile) (ResultAnomaly, bool) {
+	seen := make(map[string]bool)
+	var everywhere map[string]bool
+	samples := 0
+	for _, profile := range baseline {
+		previous, ok := profile.Categorical[column]
+		if !ok {
+			continue
+		}
+		samples++
+		for value := range previous {
+			seen[value] = true
+		}
+		if everywhere == nil {
+			everywhere = make(map[string]bool, len(previous))
+			for value := range previous {
+				everywhere[value] = true
+			}
+			continue
+		}
+		for value := range everywhere {
+			if !previous[value] {
+				delete(everywhere, value)
+			}
+		}
+	}
+	if samples == 0 {
+		return ResultAnomaly{}, false
+	}
+
+	var added, removed []string
+	for value := range values {
+		if !seen[value] {
+			added = append(added, value)
+		}
+	}
+	for value := range everywhere {
+		if !values[value] {
+			removed = append(removed, value)
+		}
+	}
+	if len(added) == 0 && len(removed) == 0 {
+		return ResultAnomaly{}, false
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, fmt.Sprintf("%d new (%s)", len(added), strings.Join(limitStrings(added, 5), ", ")))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d missing (%s)", len(removed), strings.Join(limitStrings(removed, 5), ", ")))
+	}
+	return ResultAnomaly{
+		Metric:  "values(" + column + ")",
+		Kind:    "categorical",
+		Added:   limitStrings(added, maxAnomalyValues),
+		Removed: limitStrings(removed, maxAnomalyValues),
+		Message: fmt.Sprintf("%s values changed: %s", column, strings.Join(parts, "; ")),
+	}, true
+}
+
+func limitStrings(values []string, limit int) []string {
+	if len(values) > limit {
+		return values[:limit]
+	}
+	return values
+}
+
+func formatMetricValue(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// resultHistory returns the latest stored result of a query on a network for each snapshot,
+// newest first
+func (s *ForwardMCPService) resultHistory(queryID, networkID string) ([]*Entity, error) {
+	entities, err := s.memorySystem.SearchEntities(fmt.Sprintf("%s-%s-", queryID, networkID), "nqe_result", 200)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search stored results: %w", err)
+	}
+
+	var history []*Entity
+	for _, entity := range entities {
+		if entity.Metadata["query_id"] == queryID && entity.Metadata["network_id"] == networkID && !isSupersededVersion(entity) {
+			history = append(history, entity)
+		}
+	}
+	// Entity IDs embed their creation time in nanoseconds, which breaks ties within a second
+	sort.SliceStable(history, func(i, j int) bool {
+		if !history[i].UpdatedAt.Equal(history[j].UpdatedAt) {
+			return history[i].UpdatedAt.After(history[j].UpdatedAt)
+		}
+		return history[i].ID > history[j].ID
+	})
+	return history, nil
+}
+
+// annotateResultAnomalies compares a stored result with earlier results of the same query and
+// network and records any anomalies as an "anomaly" observation on the result entity
+func (s *ForwardMCPService) annotateResultAnomalies(entityID string, opts AnomalyOptions) ([]ResultAnomaly, []*Entity, error) {
+	opts = opts.withDefaults()
+
+	entity, rows, err := s.loadStoredResultRows(entityID)
+	if err != nil {
+		return nil, nil, err
+	}
+	queryID, _ := entity.Metadata["query_id"].(string)
+	networkID, _ := entity.Metadata["network_id"].(string)
+	if queryID == "" || networkID == "" {
+		return nil, nil, fmt.Errorf("entity %s is not a stored NQE result", entityID)
+	}
+
+	history, err := s.resultHistory(queryID, networkID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var baselineEntities []*Entity
+	for _, previous := range history {
+		if previous.ID == entity.ID || previous.Name == entity.Name {
+			continue
+		}
+		baselineEntities = append(baselineEntities, previous)
+		if len(baselineEntities) == opts.BaselineSize {
+			break
+		}
+	}
+	if len(baselineEntities) == 0 {
+		return nil, nil, nil
+	}
+
+	// Profiles run oldest to newest so the EWMA favours the most recent results
+	baseline := make([]*resultProfile, 0, len(baselineEntities))
+	for i := len(baselineEntities) - 1; i >= 0; i-- {
+		_, previousRows, err := s.loadStoredResultRows(baselineEntities[i].ID)
+		if err != nil {
+			s.logger.Debug("Skipping baseline result %s: %v", baselineEntities[i].ID, err)
+			continue
+		}
+		profile := profileResultRows(previousRows)
+		profile.EntityID = baselineEntities[i].ID
+		baseline = append(baseline, profile)
+	}
+	if len(baseline) == 0 {
+		return nil, baselineEntities, nil
+	}
+
+	current := profileResultRows(rows)
+	current.EntityID = entity.ID
+	anomalies := detectResultAnomalies(current, baseline, opts)
+	if len(anomalies) > 0 {
+		baselineIDs := make([]string, len(baseline))
+		for i, profile := range baseline {
+			baselineIDs[i] = profile.EntityID
+		}
+		s.replaceAnomalyObservation(entity.ID, MarshalCompactJSONString(anomalies),
+			map[string]interface{}{"baseline_entities": baselineIDs, "anomaly_count": len(anomalies)})
+	}
+	return anomalies, baselineEntities, nil
+}
+
+// replaceAnomalyObservation keeps one anomaly observation per result: a re-run with the same
+// findings leaves it alone, and different findings replace the earlier annotation
+func (s *ForwardMCPService) replaceAnomalyObservation(entityID, content string, metadata map[string]interface{}) {
+	existing, err := s.memorySystem.GetObservations(entityID, "anomaly")
+	if err != nil {
+		s.logger.Warn("Failed to load anomaly annotations on %s: %v", entityID, err)
+		return
+	}
+	for _, observation := range existing {
+		if observation.Content == content {
+			return
+		}
+	}
+	if _, err := s.memorySystem.AddObservation(entityID, content, "anomaly", metadata); err != nil {
+		s.logger.Warn("Failed to annotate anomalies on %s: %v", entityID, err)
+		return
+	}
+	for _, observation := range existing {
+		if err := s.memorySystem.DeleteObservation(observation.ID); err != nil {
+			s.logger.Warn("Failed to remove earlier anomaly annotation %s: %v", observation.ID, err)
+		}
+	}
+}
+
+// detectResultAnomaliesTool checks the newest stored result of a query against its trailing baseline
+func (s *ForwardMCPService) detectResultAnomaliesTool(args DetectResultAnomaliesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("detect_result_anomalies", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+
+	entityID := args.EntityID
+	if entityID == "" {
+		if args.QueryID == "" {
+			return nil, fmt.Errorf("entity_id or query_id is required")
+		}
+		networkID := s.getNetworkID(args.NetworkID)
+		if networkID == "" {
+			return nil, newCodedError(CodeNetworkIDRequired)
+		}
+		history, err := s.resultHistory(args.QueryID, networkID)
+		if err != nil {
+			return nil, err
+		}
+		if len(history) == 0 {
+			return nil, fmt.Errorf("no stored results for query %s on network %s; run it with run_nqe_query_by_id first", args.QueryID, networkID)
+		}
+		entityID = history[0].ID
+	}
+
+	opts := AnomalyOptions{BaselineSize: args.BaselineSize, ZThreshold: args.ZThreshold, ChangeThreshold: args.ChangeThreshold}
+	anomalies, baseline, err := s.annotateResultAnomalies(entityID, opts)
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(formatResultAnomalies(entityID, anomalies, len(baseline)))), nil
+}
+
+// formatResultAnomalies renders detected anomalies as Markdown
+func formatResultAnomalies(entityID string, anomalies []ResultAnomaly, baselineSize int) string {
+	if baselineSize == 0 {
+		return fmt.Sprintf("No earlier results to compare with %s. Anomaly detection needs the same query stored for at least one other snapshot.", entityID)
+	}
+	if len(anomalies) == 0 {
+		return fmt.Sprintf("No anomalies in %s compared with the previous %d results.", entityID, baselineSize)
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("⚠️ %d anomalies in %s compared with the previous %d results:\n\n", len(anomalies), entityID, baselineSize))
+	for _, anomaly := range anomalies {
+		report.WriteString(fmt.Sprintf("- %s\n", anomaly.Message))
+	}
+	report.WriteString("\nAnomalies are attached to the result entity as an observation of type anomaly.\n")
+	return report.String()
+}
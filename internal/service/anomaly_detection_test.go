@@ -0,0 +1,107 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func routeRows(count int, vendors ...string) []map[string]interface{} {
+	rows := make([]map[string]interface{}, count)
+	for i := range rows {
+		rows[i] = map[string]interface{}{
+			"device": fmt.Sprintf("router-%d", i),
+			"vendor": vendors[i%len(vendors)],
+			"routes": float64(100),
+		}
+	}
+	return rows
+}
+
+func TestDetectResultAnomalies(t *testing.T) {
+	baseline := []*resultProfile{
+		profileResultRows(routeRows(10, "cisco", "arista")),
+		profileResultRows(routeRows(10, "cisco", "arista")),
+		profileResultRows(routeRows(10, "cisco", "arista")),
+	}
+	if _, ok := baseline[0].Categorical["device"]; !ok {
+		t.Fatal("Expected device to be profiled as categorical")
+	}
+	if baseline[0].Numeric["sum(routes)"] != 1000 {
+		t.Errorf("Expected sum(routes) 1000, got %v", baseline[0].Numeric)
+	}
+
+	if anomalies := detectResultAnomalies(profileResultRows(routeRows(10, "cisco", "arista")), baseline, AnomalyOptions{}); len(anomalies) != 0 {
+		t.Errorf("Expected no anomalies for an identical result, got %+v", anomalies)
+	}
+
+	anomalies := detectResultAnomalies(profileResultRows(routeRows(7, "cisco", "juniper")), baseline, AnomalyOptions{})
+	messages := make([]string, len(anomalies))
+	for i, anomaly := range anomalies {
+		messages[i] = anomaly.Message
+	}
+	joined := strings.Join(messages, "\n")
+	for _, want := range []string{"row_count dropped 30.0% (baseline 10 → 7", "sum(routes) dropped 30.0%", "vendor values changed: 1 new (juniper); 1 missing (arista)", "device values changed: 3 missing"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("Expected %q in anomalies, got:\n%s", want, joined)
+		}
+	}
+
+	// A noisy baseline needs a high z-score as well as a large relative change
+	noisy := []*resultProfile{
+		{Numeric: map[string]float64{"row_count": 50}},
+		{Numeric: map[string]float64{"row_count": 100}},
+		{Numeric: map[string]float64{"row_count": 50}},
+		{Numeric: map[string]float64{"row_count": 100}},
+	}
+	if anomalies := detectResultAnomalies(&resultProfile{Numeric: map[string]float64{"row_count": 40}}, noisy, AnomalyOptions{}); len(anomalies) != 0 {
+		t.Errorf("Expected noisy baseline to suppress the anomaly, got %+v", anomalies)
+	}
+	if anomalies := detectResultAnomalies(&resultProfile{Numeric: map[string]float64{"row_count": 400}}, noisy, AnomalyOptions{}); len(anomalies) != 1 || anomalies[0].ZScore == 0 {
+		t.Errorf("Expected a z-scored anomaly, got %+v", anomalies)
+	}
+}
+
+func TestDetectResultAnomaliesTool(t *testing.T) {
+	service := createTestService()
+	queryID := "FQ_anomaly_test"
+	for i, rows := range [][]map[string]interface{}{routeRows(10, "cisco"), routeRows(10, "cisco"), routeRows(5, "cisco")} {
+		if _, err := service.memorySystem.StoreNQEResultWithChunking(queryID, "162112", fmt.Sprintf("snap-anomaly-%d", i),
+			&forward.NQERunResult{Items: rows}, 200); err != nil {
+			t.Fatalf("Failed to store result %d: %v", i, err)
+		}
+	}
+
+	response, err := service.detectResultAnomaliesTool(DetectResultAnomaliesArgs{QueryID: queryID, NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "compared with the previous 2 results") || !strings.Contains(text, "row_count dropped 50.0%") {
+		t.Errorf("Expected row count anomaly, got:\n%s", text)
+	}
+
+	newest, err := service.memorySystem.GetEntity(queryID + "-162112-snap-anomaly-2")
+	if err != nil {
+		t.Fatalf("Failed to load newest result: %v", err)
+	}
+	if observations, _ := service.memorySystem.GetObservations(newest.ID, "anomaly"); len(observations) != 1 {
+		t.Errorf("Expected one anomaly annotation on the result entity, got %d", len(observations))
+	}
+
+	// Re-running keeps a single annotation, whether the findings repeat or change
+	for _, baselineSize := range []int{0, 1} {
+		if _, err := service.detectResultAnomaliesTool(DetectResultAnomaliesArgs{QueryID: queryID, NetworkID: "162112", BaselineSize: baselineSize}); err != nil {
+			t.Fatalf("Expected no error on re-run, got: %v", err)
+		}
+		if observations, _ := service.memorySystem.GetObservations(newest.ID, "anomaly"); len(observations) != 1 {
+			t.Errorf("Expected one anomaly annotation after re-run with baseline %d, got %d", baselineSize, len(observations))
+		}
+	}
+
+	if _, err := service.detectResultAnomaliesTool(DetectResultAnomaliesArgs{QueryID: "FQ_never_stored", NetworkID: "162112"}); err == nil {
+		t.Error("Expected an error when no results are stored")
+	}
+}
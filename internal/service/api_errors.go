@@ -0,0 +1,199 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	defaultAPIErrorDetails = 10 // Recent failures listed in detail
+	maxAPIErrorDetails     = 50 // Upper bound on limit
+)
+
+// APIErrorGroup counts the failures of one endpoint and status
+type APIErrorGroup struct {
+	Instance  string    `json:"instance"`
+	Method    string    `json:"method"`
+	Endpoint  string    `json:"endpoint"`
+	Status    int       `json:"status"`
+	Count     int       `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+	LastError string    `json:"last_error"`
+}
+
+// APIErrorRecord is a recorded failure and the instance it came from
+type APIErrorRecord struct {
+	Instance string `json:"instance"`
+	forward.APIExchangeError
+}
+
+// APIErrorSummary is the report of get_last_api_errors
+type APIErrorSummary struct {
+	Tracked []string         `json:"tracked_instances"`
+	Total   int              `json:"total"`
+	Groups  []APIErrorGroup  `json:"groups"`
+	Recent  []APIErrorRecord `json:"recent"`
+}
+
+// summarizeAPIErrors groups failures by instance, method, endpoint (without its query string)
+// and status, most frequent first, and keeps the limit most recent failures
+func summarizeAPIErrors(records []APIErrorRecord, limit int) APIErrorSummary {
+	summary := APIErrorSummary{Total: len(records), Groups: []APIErrorGroup{}}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.After(records[j].Time) })
+
+	index := make(map[string]int)
+	for _, record := range records {
+		endpoint, _, _ := strings.Cut(record.Path, "?")
+		key := fmt.Sprintf("%s %s %s %d", record.Instance, record.Method, endpoint, record.Status)
+		i, ok := index[key]
+		if !ok {
+			// Records are newest first, so the first of a group is its latest
+			summary.Groups = append(summary.Groups, APIErrorGroup{Instance: record.Instance, Method: record.Method,
+				Endpoint: endpoint, Status: record.Status, LastSeen: record.Time, LastError: apiErrorMessage(record.APIExchangeError)})
+			i = len(summary.Groups) - 1
+			index[key] = i
+		}
+		summary.Groups[i].Count++
+	}
+	sort.SliceStable(summary.Groups, func(i, j int) bool { return summary.Groups[i].Count > summary.Groups[j].Count })
+
+	if len(records) > limit {
+		records = records[:limit]
+	}
+	summary.Recent = records
+	return summary
+}
+
+// apiErrorMessage is the transport error of a failure or the start of the API's response
+func apiErrorMessage(failure forward.APIExchangeError) string {
+	message := failure.Error
+	if message == "" {
+		message = failure.ResponseBody
+	}
+	if message == "" {
+		return "(empty response)"
+	}
+	return truncateString(strings.Join(strings.Fields(message), " "), 160)
+}
+
+// apiErrorStatus renders a status, or that no response was received
+func apiErrorStatus(status int) string {
+	if status == 0 {
+		return "no response"
+	}
+	return fmt.Sprintf("%d", status)
+}
+
+// apiErrorNetworkPattern finds the network a request path names
+var apiErrorNetworkPattern = regexp.MustCompile(`(?:/networks/|[?&]networkId=)([^/?&]+)`)
+
+// apiErrorVisible reports whether a failure may be listed: failures naming a restricted
+// network are hidden, and keys limited to specific networks only see failures naming one of
+// them
+func (s *ForwardMCPService) apiErrorVisible(identity *APIKeyIdentity, failure forward.APIExchangeError) bool {
+	networkID := ""
+	if match := apiErrorNetworkPattern.FindStringSubmatch(failure.Path); match != nil {
+		networkID = match[1]
+	}
+	if networkID == "" {
+		return identity == nil || len(identity.Networks) == 0
+	}
+	return s.networkPolicy.Permits(networkID) && identity.permitsNetwork(networkID)
+}
+
+// getLastAPIErrors summarizes the failed Forward API requests recorded by this instance's
+// client and the federated ones
+func (s *ForwardMCPService) getLastAPIErrors(args GetLastAPIErrorsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_last_api_errors", args, nil)
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultAPIErrorDetails
+	}
+	if limit > maxAPIErrorDetails {
+		limit = maxAPIErrorDetails
+	}
+	format := strings.ToLower(strings.TrimSpace(args.Format))
+	if format != "" && format != "markdown" && format != "json" {
+		return nil, fmt.Errorf("unsupported format '%s' (use markdown or json)", args.Format)
+	}
+
+	var tracked []string
+	var records []APIErrorRecord
+	instances := append([]federatedInstance{{name: localInstanceName, client: s.forwardClient}}, s.federation.configured()...)
+	for _, instance := range instances {
+		reporter, ok := instance.client.(forward.APIErrorReporter)
+		if !ok {
+			continue
+		}
+		tracked = append(tracked, instance.name)
+		for _, failure := range reporter.RecentAPIErrors() {
+			if s.apiErrorVisible(args.Caller, failure) {
+				records = append(records, APIErrorRecord{Instance: instance.name, APIExchangeError: failure})
+			}
+		}
+	}
+	if len(tracked) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("The Forward API client does not record failed requests.")), nil
+	}
+
+	summary := summarizeAPIErrors(records, limit)
+	summary.Tracked = tracked
+	if format == "json" {
+		return s.streamResponse("get_last_api_errors", MarshalCompactJSONString(summary)), nil
+	}
+	return s.streamResponse("get_last_api_errors", s.formatAPIErrorSummary(summary)), nil
+}
+
+// formatAPIErrorSummary renders the failures as a markdown table of endpoints followed by the
+// most recent failures with their request and response snippets
+func (s *ForwardMCPService) formatAPIErrorSummary(summary APIErrorSummary) string {
+	var out strings.Builder
+	out.WriteString("## Recent Forward API Errors\n\n")
+	if summary.Total == 0 {
+		out.WriteString(fmt.Sprintf("No failed requests recorded since startup (instances: %s).\n", strings.Join(summary.Tracked, ", ")))
+		return out.String()
+	}
+	out.WriteString(fmt.Sprintf("%d failed requests in %d endpoint/status groups (the last %d per instance are kept).\n\n",
+		summary.Total, len(summary.Groups), forward.MaxRecentAPIErrors))
+
+	multiInstance := len(summary.Tracked) > 1
+	if multiInstance {
+		out.WriteString("| Instance | Endpoint | Status | Count | Last Seen | Last Error |\n|---|---|---|---|---|---|\n")
+	} else {
+		out.WriteString("| Endpoint | Status | Count | Last Seen | Last Error |\n|---|---|---|---|---|\n")
+	}
+	for _, group := range summary.Groups {
+		if multiInstance {
+			out.WriteString(fmt.Sprintf("| %s ", group.Instance))
+		}
+		out.WriteString(fmt.Sprintf("| %s %s | %s | %d | %s | %s |\n", group.Method, group.Endpoint, apiErrorStatus(group.Status),
+			group.Count, s.timeFormatter.FormatWithAge(group.LastSeen), strings.ReplaceAll(group.LastError, "|", "\\|")))
+	}
+
+	out.WriteString(fmt.Sprintf("\n### Most Recent (%d)\n", len(summary.Recent)))
+	for _, record := range summary.Recent {
+		out.WriteString(fmt.Sprintf("\n**%s %s** → %s in %s at %s", record.Method, record.Path, apiErrorStatus(record.Status),
+			record.Duration, s.timeFormatter.Format(record.Time)))
+		if multiInstance {
+			out.WriteString(fmt.Sprintf(" (%s)", record.Instance))
+		}
+		out.WriteString("\n")
+		if record.Error != "" {
+			out.WriteString(fmt.Sprintf("- Error: %s\n", record.Error))
+		}
+		if record.RequestBody != "" {
+			out.WriteString(fmt.Sprintf("- Request: `%s`\n", record.RequestBody))
+		}
+		if record.ResponseBody != "" {
+			out.WriteString(fmt.Sprintf("- Response: `%s`\n", record.ResponseBody))
+		}
+	}
+	return out.String()
+}
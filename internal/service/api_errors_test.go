@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// errorRecordingClient is a mock client that reports recorded API failures
+type errorRecordingClient struct {
+	*MockForwardClient
+	failures []forward.APIExchangeError
+}
+
+func (c *errorRecordingClient) RecentAPIErrors() []forward.APIExchangeError {
+	return c.failures
+}
+
+func TestGetLastAPIErrors(t *testing.T) {
+	service := createTestService()
+
+	// The mock client keeps no failures
+	response, err := service.getLastAPIErrors(GetLastAPIErrorsArgs{})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "does not record failed requests") {
+		t.Fatalf("Expected the client to be reported as not recording, got %v", err)
+	}
+
+	now := time.Now()
+	service.forwardClient = &errorRecordingClient{MockForwardClient: NewMockForwardClient()}
+	response, err = service.getLastAPIErrors(GetLastAPIErrorsArgs{})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "No failed requests recorded since startup (instances: local)") {
+		t.Fatalf("Expected no failures, got %v", err)
+	}
+
+	service.forwardClient = &errorRecordingClient{MockForwardClient: NewMockForwardClient(), failures: []forward.APIExchangeError{
+		{Time: now, Method: "POST", Path: "/api/nqe?networkId=1", Status: 400, Duration: "12ms", RequestBody: `{"query":"bad"}`, ResponseBody: `{"message":"Parse error at line 1"}`},
+		{Time: now.Add(-time.Minute), Method: "POST", Path: "/api/nqe?networkId=2", Status: 400, ResponseBody: `{"message":"older"}`},
+		{Time: now.Add(-2 * time.Minute), Method: "GET", Path: "/api/networks", Error: "dial tcp: connection refused"},
+	}}
+	service.federation = &Federation{}
+	service.federation.add("lab", "", &errorRecordingClient{MockForwardClient: NewMockForwardClient(), failures: []forward.APIExchangeError{
+		{Time: now.Add(-time.Hour), Method: "GET", Path: "/api/networks", Status: 503},
+	}})
+
+	response, err = service.getLastAPIErrors(GetLastAPIErrorsArgs{Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{
+		"4 failed requests in 3 endpoint/status groups",
+		"| local | POST /api/nqe | 400 | 2 |",
+		"Parse error at line 1",
+		"| local | GET /api/networks | no response | 1 |",
+		"| lab | GET /api/networks | 503 | 1 |",
+		"### Most Recent (2)",
+		"- Request: `{\"query\":\"bad\"}`",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "connection refused\n") {
+		t.Errorf("Expected only the two most recent failures in detail: %s", text)
+	}
+
+	summary := summarizeAPIErrors([]APIErrorRecord{{Instance: "local", APIExchangeError: forward.APIExchangeError{Method: "GET", Path: "/a", Status: 500}}}, 5)
+	if len(summary.Groups) != 1 || summary.Groups[0].LastError != "(empty response)" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	response, err = service.getLastAPIErrors(GetLastAPIErrorsArgs{Format: "json"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, `"tracked_instances":["local","lab"]`) {
+		t.Errorf("Expected JSON output, got %v", err)
+	}
+	if _, err := service.getLastAPIErrors(GetLastAPIErrorsArgs{Format: "xml"}); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
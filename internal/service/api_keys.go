@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/forward-mcp/internal/config"
+)
+
+// toolGroups assigns each tool and prompt to the group API key scopes refer to. Tools and
+// prompts missing from the table can only be called by keys without a tool group restriction.
+var toolGroups = map[string]string{
+	"list_networks": "networks", "create_network": "networks", "delete_network": "networks",
+	"update_network": "networks", "list_snapshots": "networks", "get_latest_snapshot": "networks",
+	"delete_snapshot": "networks", "get_default_settings": "networks", "set_default_network": "networks",
+	"start_analysis_session": "networks", "end_analysis_session": "networks", "run_snapshot_pipeline": "networks", "get_pipeline_runs": "networks",
+	"list_platform_checks": "networks", "get_check_results": "networks",
+
+	"search_paths": "paths", "search_paths_bulk": "paths", "analyze_network_prefixes": "paths",
+	"troubleshoot_connectivity": "paths", "which_devices_in_prefix": "paths", "analyze_summarization": "paths", "suggest_site_pairs": "paths",
+	"annotate_prefix": "paths", "import_prefix_annotations": "paths", "prefix_documentation_coverage": "paths",
+	"sweep_violations": "paths", "detect_connectivity_drift": "paths", "build_service_map": "paths", "export_service_map": "paths",
+
+	"run_nqe_query_by_id": "nqe", "estimate_query_cost": "nqe", "run_federated_query": "nqe",
+	"get_federated_inventory": "devices", "list_nqe_queries": "nqe", "search_nqe_queries": "nqe",
+	"get_nqe_query_source": "nqe", "suggest_parameter_values": "nqe", "check_query_compatibility": "nqe", "suggest_similar_queries": "nqe",
+	"initialize_query_index": "nqe", "hydrate_database": "nqe", "refresh_query_index": "nqe", "purge_deprecated_queries": "nqe",
+	"get_database_status": "nqe", "query_catalog_sql": "nqe", "get_query_analytics": "nqe", "set_query_category": "nqe",
+	"remove_query_category": "nqe", "list_query_taxonomy": "nqe", "list_schema_changes": "nqe",
+
+	"get_device_basic_info": "devices", "get_device_hardware": "devices", "get_hardware_support": "devices",
+	"get_os_support": "devices", "forecast_eol_exposure": "devices", "generate_network_docs": "devices", "reconcile_inventory": "devices",
+	"list_devices": "devices", "get_device_locations": "devices", "refresh_device_cache": "devices",
+	"list_device_aliases": "devices", "add_device_alias": "devices", "detect_device_renames": "devices",
+	"classify_devices": "devices", "set_device_tag_rule": "devices", "remove_device_tag_rule": "devices",
+	"apply_device_tags": "devices", "list_device_tags": "devices", "get_vlan_inventory": "devices", "check_vlan_consistency": "devices",
+	"check_interface_hygiene": "devices", "check_fhrp": "devices", "get_support_matrix": "devices",
+	"plan_device_onboarding": "devices", "check_device_onboarding": "devices",
+
+	"search_configs": "configs", "get_config_section": "configs", "get_config_diff": "configs",
+
+	"list_locations": "locations", "create_location": "locations", "update_location": "locations",
+	"delete_location": "locations", "create_locations_bulk": "locations", "update_device_locations": "locations",
+	"assign_onboarded_locations": "locations",
+
+	"create_entity": "memory", "create_relation": "memory", "add_observation": "memory",
+	"search_entities": "memory", "get_entity": "memory", "get_entity_versions": "memory",
+	"get_relations": "memory", "get_observations": "memory", "delete_entity": "memory",
+	"delete_relation": "memory", "delete_observation": "memory", "get_memory_stats": "memory",
+	"list_instance_ids": "memory", "start_session_transcript": "memory", "stop_session_transcript": "memory",
+	"get_session_transcript": "memory", "restore_entity": "memory", "list_trash": "memory",
+	"pin_entity": "memory", "unpin_entity": "memory", "find_duplicate_entities": "memory", "merge_entities": "memory",
+	"bulk_create_entities": "memory", "bulk_create_relations": "memory",
+	"create_workspace": "memory", "close_workspace": "memory", "list_workspace_contents": "memory",
+	"export_workspace": "memory", "cleanup_workspace": "memory", "save_preset": "memory", "list_presets": "memory",
+	"delete_preset": "memory",
+
+	"get_nqe_result_chunks": "results", "get_nqe_result_summary": "results", "analyze_nqe_result_sql": "results", "export_to_duckdb": "results",
+	"detect_result_anomalies": "results", "diff_stored_results": "results", "continue_response": "results",
+	"collect_timeseries": "results", "query_timeseries": "results", "generate_chart_spec": "results",
+	"share_result": "results", "get_shared_result": "results", "summarize_result": "results",
+
+	"get_cache_stats": "cache", "clear_cache": "cache", "list_cache_entries": "cache",
+	"inspect_cache_entry": "cache", "evict_cache_entry": "cache", "build_bloom_filter": "cache",
+	"search_bloom_filter": "cache", "get_bloom_filter_stats": "cache",
+
+	"nqe_discovery": "nqe", "network_discovery": "networks", "large_nqe_results_workflow": "results",
+	"path_search_workflow": "paths", "network_prefix_discovery_workflow": "paths", "security_posture_workflow": "nqe",
+	"site_turnup_workflow": "devices", "onboard_devices_workflow": "devices", "bulk_location_setup": "locations",
+
+	"get_redaction_stats": "diagnostics", "client_diagnostics": "diagnostics",
+	"run_diagnostics": "diagnostics", "get_storage_stats": "diagnostics", "cleanup_storage": "diagnostics",
+	"backup_state": "diagnostics", "restore_state": "diagnostics", "list_feature_flags": "diagnostics",
+	"lookup_error": "diagnostics", "classify_intent": "diagnostics", "get_last_api_errors": "diagnostics",
+}
+
+// writeTools change state in Forward, the memory system or the server, or write files, so
+// read-only keys cannot call them and identical calls are never coalesced. Storing fetched or
+// analyzed results for follow-up calls does not count.
+var writeTools = map[string]bool{
+	"create_network": true, "delete_network": true, "update_network": true, "delete_snapshot": true,
+	"set_default_network": true, "create_location": true, "update_location": true, "delete_location": true,
+	"create_locations_bulk": true, "update_device_locations": true, "assign_onboarded_locations": true, "plan_device_onboarding": true, "add_device_alias": true,
+	"refresh_device_cache": true, "initialize_query_index": true, "hydrate_database": true,
+	"refresh_query_index": true, "create_entity": true, "create_relation": true, "add_observation": true,
+	"delete_entity": true, "delete_relation": true, "delete_observation": true, "clear_cache": true,
+	"evict_cache_entry": true, "build_bloom_filter": true, "collect_timeseries": true, "run_snapshot_pipeline": true,
+	"cleanup_storage": true, "backup_state": true, "export_to_duckdb": true, "restore_state": true, "purge_deprecated_queries": true,
+	"set_query_category": true, "remove_query_category": true, "share_result": true,
+	"start_session_transcript": true, "stop_session_transcript": true, "set_device_tag_rule": true,
+	"remove_device_tag_rule": true, "apply_device_tags": true,
+	"annotate_prefix": true, "import_prefix_annotations": true,
+	"restore_entity": true, "build_service_map": true, "pin_entity": true, "unpin_entity": true, "merge_entities": true,
+	"bulk_create_entities": true, "bulk_create_relations": true,
+	"create_workspace": true, "close_workspace": true, "cleanup_workspace": true,
+	"summarize_result": true, "save_preset": true, "delete_preset": true,
+	"export_workspace": true, "export_service_map": true, "generate_network_docs": true, "detect_device_renames": true,
+	"start_analysis_session": true, "end_analysis_session": true,
+	"security_posture_workflow": true, "site_turnup_workflow": true, "onboard_devices_workflow": true,
+}
+
+// promptSessionPrefixes are the prefixes workflow prompts add to their session_id to key
+// their workflow state
+var promptSessionPrefixes = map[string]string{
+	"nqe_discovery": "session_", "large_nqe_results_workflow": "session_", "network_prefix_discovery_workflow": "session_",
+	"path_search_workflow": "path_session_", "security_posture_workflow": "posture_session_",
+	"site_turnup_workflow": "turnup_session_", "onboard_devices_workflow": "onboard_session_",
+}
+
+// APIKeyIdentity is an authenticated API key and its scopes
+type APIKeyIdentity struct {
+	ID         string
+	ReadOnly   bool
+	Networks   map[string]bool // Empty allows every network
+	ToolGroups map[string]bool // Empty allows every tool group
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKeyIdentity returns a context carrying the identity of the calling API key
+func WithAPIKeyIdentity(ctx context.Context, identity *APIKeyIdentity) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, identity)
+}
+
+// apiKeyIdentityFromContext returns the calling API key, nil for unauthenticated transports
+func apiKeyIdentityFromContext(ctx context.Context) *APIKeyIdentity {
+	identity, _ := ctx.Value(apiKeyContextKey{}).(*APIKeyIdentity)
+	return identity
+}
+
+// APIKeyAuthenticator resolves API keys presented to the HTTP transport
+type APIKeyAuthenticator struct {
+	keys map[string]*APIKeyIdentity // Keyed by hex SHA-256 of the key
+}
+
+// NewAPIKeyAuthenticator builds an authenticator from configured keys. Keys without an ID
+// or key material are rejected so audit logs always name the caller.
+func NewAPIKeyAuthenticator(keys []config.APIKeyConfig) (*APIKeyAuthenticator, error) {
+	authenticator := &APIKeyAuthenticator{keys: make(map[string]*APIKeyIdentity)}
+	for i, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("API key %d has no id", i)
+		}
+		hash := strings.ToLower(strings.TrimSpace(key.KeySHA256))
+		if key.Key != "" {
+			sum := sha256.Sum256([]byte(key.Key))
+			hash = hex.EncodeToString(sum[:])
+		}
+		if len(hash) != sha256.Size*2 {
+			return nil, fmt.Errorf("API key %s needs a key or a hex SHA-256 keySha256", key.ID)
+		}
+		if _, exists := authenticator.keys[hash]; exists {
+			return nil, fmt.Errorf("API key %s duplicates another key", key.ID)
+		}
+		identity := &APIKeyIdentity{
+			ID:         key.ID,
+			ReadOnly:   key.ReadOnly,
+			Networks:   make(map[string]bool),
+			ToolGroups: make(map[string]bool),
+		}
+		for _, network := range key.Networks {
+			identity.Networks[network] = true
+		}
+		for _, group := range key.ToolGroups {
+			identity.ToolGroups[strings.ToLower(group)] = true
+		}
+		authenticator.keys[hash] = identity
+	}
+	return authenticator, nil
+}
+
+// Len returns the number of configured keys
+func (a *APIKeyAuthenticator) Len() int {
+	return len(a.keys)
+}
+
+// Authenticate returns the identity of a presented key, or nil when it is unknown
+func (a *APIKeyAuthenticator) Authenticate(key string) *APIKeyIdentity {
+	if key == "" {
+		return nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	presented := hex.EncodeToString(sum[:])
+	var match *APIKeyIdentity
+	for hash, identity := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(presented)) == 1 {
+			match = identity
+		}
+	}
+	return match
+}
+
+// authorizeTool reports why an API key may not call a tool, or nil when the call is within
+// its scopes. Network scopes apply to tools that target a network.
+func (identity *APIKeyIdentity) authorizeTool(toolName, networkID string, targetsNetwork bool) error {
+	if identity.ReadOnly && writeTools[toolName] {
+		return fmt.Errorf("API key %s is read-only and cannot call %s", identity.ID, toolName)
+	}
+	if len(identity.ToolGroups) > 0 && !identity.ToolGroups[toolGroups[toolName]] {
+		return fmt.Errorf("API key %s is not allowed to call %s", identity.ID, toolName)
+	}
+	if targetsNetwork && len(identity.Networks) > 0 && !identity.Networks[networkID] {
+		if networkID == "" {
+			return fmt.Errorf("API key %s is limited to specific networks; %s needs a network_id", identity.ID, toolName)
+		}
+		return fmt.Errorf("API key %s is not allowed to access network %s", identity.ID, networkID)
+	}
+	return nil
+}
+
+// permitsNetwork reports whether the key may read data of a network. A nil key (an
+// unauthenticated transport), a key without network scopes and data without a network pass.
+func (identity *APIKeyIdentity) permitsNetwork(networkID string) bool {
+	return identity == nil || len(identity.Networks) == 0 || networkID == "" || identity.Networks[networkID]
+}
+
+// permitsEntity reports whether the key may read a memory entity, by the network_id it records
+func (identity *APIKeyIdentity) permitsEntity(entity *Entity) bool {
+	return entity == nil || identity.permitsNetwork(resultValueString(entity.Metadata["network_id"]))
+}
+
+// callerEntities drops the entities of networks outside the calling key's scopes
+func callerEntities(identity *APIKeyIdentity, entities []*Entity) []*Entity {
+	permitted := entities[:0]
+	for _, entity := range entities {
+		if identity.permitsEntity(entity) {
+			permitted = append(permitted, entity)
+		}
+	}
+	return permitted
+}
+
+// callerID names the caller in state kept per caller: the API key ID, or "" for the
+// unauthenticated stdio and HTTP transports
+func (identity *APIKeyIdentity) callerID() string {
+	if identity == nil {
+		return ""
+	}
+	return identity.ID
+}
+
+// withCaller returns tool arguments with their Caller field, when they have one, set to the
+// calling key. The field is tagged json:"-", so clients can neither see nor set it.
+func withCaller(args reflect.Value, identity *APIKeyIdentity) reflect.Value {
+	if args.Kind() != reflect.Struct {
+		return args
+	}
+	field := args.FieldByName("Caller")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(identity) {
+		return args
+	}
+	scoped := reflect.New(args.Type()).Elem()
+	scoped.Set(args)
+	scoped.FieldByName("Caller").Set(reflect.ValueOf(identity))
+	return scoped
+}
+
+// toolCallerID returns the ID of the key in the Caller field of tool arguments, "" without one
+func toolCallerID(args interface{}) string {
+	value := reflect.ValueOf(args)
+	if value.Kind() != reflect.Struct {
+		return ""
+	}
+	field := value.FieldByName("Caller")
+	if !field.IsValid() || !field.CanInterface() {
+		return ""
+	}
+	identity, _ := field.Interface().(*APIKeyIdentity)
+	return identity.callerID()
+}
+
+// checkEntityScope refuses calls on a stored entity of a network outside the key's scopes,
+// reporting it like a missing entity. Tools name the entity in an entity_id or identifier
+// argument.
+func (s *ForwardMCPService) checkEntityScope(identity *APIKeyIdentity, args reflect.Value) error {
+	if identity == nil || len(identity.Networks) == 0 || s.memorySystem == nil || args.Kind() != reflect.Struct {
+		return nil
+	}
+	for _, name := range []string{"EntityID", "Identifier"} {
+		field := args.FieldByName(name)
+		if !field.IsValid() || field.Kind() != reflect.String || field.String() == "" {
+			continue
+		}
+		if entity, err := s.memorySystem.GetEntity(field.String()); err == nil && !identity.permitsEntity(entity) {
+			return fmt.Errorf("entity not found: %s", field.String())
+		}
+	}
+	return nil
+}
+
+// toolNetworkID returns the network a tool call targets: its network_id argument, or the
+// default network the tool falls back to when the argument is empty. The second result is
+// false for tools without a network_id argument.
+func (s *ForwardMCPService) toolNetworkID(args interface{}) (string, bool) {
+	value := reflect.ValueOf(args)
+	if value.Kind() != reflect.Struct {
+		return "", false
+	}
+	field := value.FieldByName("NetworkID")
+	if !field.IsValid() || field.Kind() != reflect.String {
+		return "", false
+	}
+	return s.networkIDOrDefault(field.String()), true
+}
+
+// authorizeToolHandler returns a context-aware handler that enforces the network access
+// policy, then checks the calling API key's scopes and records the call in the audit log
+// before running handler. Calls without an API key (the stdio transport) skip the scope
+// checks. A handler that takes the context itself receives it.
+func (s *ForwardMCPService) authorizeToolHandler(toolName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if handlerType.Kind() != reflect.Func || handlerType.NumOut() != 2 {
+		return handler
+	}
+	takesContext := handlerType.NumIn() == 2 && handlerType.In(0) == contextType
+	if handlerType.NumIn() != 1 && !takesContext {
+		return handler
+	}
+	wrappedType := reflect.FuncOf([]reflect.Type{contextType, handlerType.In(handlerType.NumIn() - 1)},
+		[]reflect.Type{handlerType.Out(0), handlerType.Out(1)}, false)
+	deny := func(err error) []reflect.Value {
+		err = describeToolError(err)
+		return []reflect.Value{reflect.Zero(handlerType.Out(0)), reflect.ValueOf(&err).Elem()}
+	}
+	call := func(ctx, args reflect.Value) []reflect.Value {
+		if takesContext {
+			return value.Call([]reflect.Value{ctx, args})
+		}
+		return value.Call([]reflect.Value{args})
+	}
+
+	return reflect.MakeFunc(wrappedType, func(args []reflect.Value) []reflect.Value {
+		networkID, targetsNetwork := s.toolNetworkID(args[1].Interface())
+		if targetsNetwork {
+			if err := s.checkNetworkAccess(networkID); err != nil {
+				return deny(err)
+			}
+		}
+
+		ctx, _ := args[0].Interface().(context.Context)
+		identity := apiKeyIdentityFromContext(ctx)
+		if identity == nil {
+			return call(args[0], args[1])
+		}
+		if err := identity.authorizeTool(toolName, networkID, targetsNetwork); err != nil {
+			s.logger.Warn("Audit: API key %s denied %s (network: %s): %v", identity.ID, toolName, networkID, err)
+			return deny(withErrorCode(CodeAPIKeyDenied, err))
+		}
+		if err := s.checkEntityScope(identity, args[1]); err != nil {
+			s.logger.Warn("Audit: API key %s denied %s: %v", identity.ID, toolName, err)
+			return deny(err)
+		}
+		s.logger.Info("Audit: API key %s called %s (network: %s)", identity.ID, toolName, networkID)
+		results := call(args[0], withCaller(args[1], identity))
+		if err, _ := results[1].Interface().(error); err != nil {
+			s.logger.Info("Audit: %s for API key %s failed: %v", toolName, identity.ID, err)
+		}
+		return results
+	}).Interface()
+}
+
+// promptCallerSeparator ends the API key ID the transport puts in front of a prompt's session_id
+const promptCallerSeparator = "\x1f"
+
+// scopePromptSession puts the calling API key in front of the session_id of a prompt call, so
+// workflow sessions are kept per key and the prompt's handler knows its caller. Prompt
+// handlers cannot take a context, so the session carries the caller like preset_owner does
+// for tools.
+func (s *ForwardMCPService) scopePromptSession(ctx context.Context, params json.RawMessage) json.RawMessage {
+	identity := apiKeyIdentityFromContext(ctx)
+	if identity == nil {
+		return params
+	}
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(params, &call); err != nil {
+		return params // Left for the server to reject
+	}
+	arguments := make(map[string]interface{})
+	if raw := call["arguments"]; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &arguments); err != nil || arguments == nil {
+			return params
+		}
+	}
+	session, _ := arguments["session_id"].(string)
+	arguments["session_id"] = identity.ID + promptCallerSeparator + session
+	raw, err := json.Marshal(arguments)
+	if err != nil {
+		return params
+	}
+	call["arguments"] = raw
+	scoped, err := json.Marshal(call)
+	if err != nil {
+		return params
+	}
+	s.promptCallers.Store(identity.ID, identity)
+	return scoped
+}
+
+// promptCaller returns the API key that made a prompt call, nil for unauthenticated transports
+func (s *ForwardMCPService) promptCaller(sessionID string) *APIKeyIdentity {
+	id, _, found := strings.Cut(sessionID, promptCallerSeparator)
+	if !found {
+		return nil
+	}
+	identity, _ := s.promptCallers.Load(id)
+	caller, _ := identity.(*APIKeyIdentity)
+	return caller
+}
+
+// authorizePromptHandler returns a prompt handler held to the same checks as tools. A prompt
+// targets the network named by network_id in its answer or selected earlier in its session;
+// a network the call selects is checked once it is known, and the selection is undone when
+// it is denied.
+func (s *ForwardMCPService) authorizePromptHandler(promptName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if handlerType.Kind() != reflect.Func || handlerType.NumIn() != 1 || handlerType.NumOut() != 2 || handlerType.In(0).Kind() != reflect.Struct {
+		return handler
+	}
+	deny := func(err error) []reflect.Value {
+		err = describeToolError(err)
+		return []reflect.Value{reflect.Zero(handlerType.Out(0)), reflect.ValueOf(&err).Elem()}
+	}
+
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		var identity *APIKeyIdentity
+		if session := args[0].FieldByName("SessionID"); session.IsValid() && session.Kind() == reflect.String {
+			identity = s.promptCaller(session.String())
+		}
+		authorize := func(networkID string) error {
+			if err := s.checkNetworkAccess(networkID); err != nil {
+				return err
+			}
+			if identity == nil {
+				return nil
+			}
+			if err := identity.authorizeTool(promptName, networkID, networkID != ""); err != nil {
+				s.logger.Warn("Audit: API key %s denied prompt %s (network: %s): %v", identity.ID, promptName, networkID, err)
+				return withErrorCode(CodeAPIKeyDenied, err)
+			}
+			return nil
+		}
+
+		state, stateKey := s.promptState(promptName, args[0])
+		previous := ""
+		if state != nil {
+			previous = state.NetworkID
+		}
+		networkID := previous
+		if answer := args[0].FieldByName("Answer"); answer.IsValid() && answer.Kind() == reflect.String {
+			if selected, ok := parseWorkflowKeyValues(answer.String())["network_id"]; ok {
+				networkID = s.networkIDOrDefault(selected)
+			}
+		}
+		if err := authorize(networkID); err != nil {
+			return deny(err)
+		}
+		if identity != nil {
+			s.logger.Info("Audit: API key %s called prompt %s (network: %s)", identity.ID, promptName, networkID)
+		}
+
+		results := value.Call(args)
+		if state, _ := s.promptState(promptName, args[0]); state != nil && state.NetworkID != previous {
+			if err := authorize(state.NetworkID); err != nil {
+				state.NetworkID = previous
+				s.workflowManager.SetState(stateKey, state)
+				return deny(err)
+			}
+		}
+		return results
+	}).Interface()
+}
+
+// promptState returns the workflow state of a prompt call and its key, or nil for prompts
+// without workflow state
+func (s *ForwardMCPService) promptState(promptName string, args reflect.Value) (*WorkflowState, string) {
+	prefix, ok := promptSessionPrefixes[promptName]
+	session := args.FieldByName("SessionID")
+	if !ok || s.workflowManager == nil || !session.IsValid() || session.Kind() != reflect.String {
+		return nil, ""
+	}
+	key := prefix + session.String()
+	return s.workflowManager.GetState(key), key
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport/stdio"
+)
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	sum := sha256.Sum256([]byte("hashed-secret"))
+	authenticator, err := NewAPIKeyAuthenticator([]config.APIKeyConfig{
+		{ID: "admin", Key: "plain-secret"},
+		{ID: "noc", KeySHA256: hex.EncodeToString(sum[:]), ReadOnly: true, Networks: []string{"net-1"}, ToolGroups: []string{"Paths"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build authenticator: %v", err)
+	}
+	if identity := authenticator.Authenticate("plain-secret"); identity == nil || identity.ID != "admin" {
+		t.Errorf("Expected the plaintext key to authenticate as admin, got %+v", identity)
+	}
+	noc := authenticator.Authenticate("hashed-secret")
+	if noc == nil || noc.ID != "noc" || !noc.ToolGroups["paths"] {
+		t.Fatalf("Expected the hashed key to authenticate as noc, got %+v", noc)
+	}
+	if authenticator.Authenticate("wrong") != nil || authenticator.Authenticate("") != nil {
+		t.Error("Expected unknown keys to be rejected")
+	}
+
+	for _, test := range []struct {
+		tool, network  string
+		targetsNetwork bool
+		allowed        bool
+	}{
+		{"search_paths", "net-1", true, true},
+		{"search_paths", "net-2", true, false},
+		{"search_paths", "", true, false},
+		{"list_devices", "net-1", true, false},
+		{"suggest_site_pairs", "net-1", true, true},
+	} {
+		if err := noc.authorizeTool(test.tool, test.network, test.targetsNetwork); (err == nil) != test.allowed {
+			t.Errorf("%s on %q: expected allowed=%t, got %v", test.tool, test.network, test.allowed, err)
+		}
+	}
+	readOnly := &APIKeyIdentity{ID: "ro", ReadOnly: true}
+	if readOnly.authorizeTool("delete_entity", "", false) == nil || readOnly.authorizeTool("get_entity", "", false) != nil {
+		t.Error("Expected read-only keys to be limited to non-mutating tools")
+	}
+
+	if _, err := NewAPIKeyAuthenticator([]config.APIKeyConfig{{Key: "x"}}); err == nil {
+		t.Error("Expected a key without an id to be rejected")
+	}
+	if _, err := NewAPIKeyAuthenticator([]config.APIKeyConfig{{ID: "bad", KeySHA256: "abc"}}); err == nil {
+		t.Error("Expected a malformed key hash to be rejected")
+	}
+}
+
+func TestHTTPTransportEnforcesAPIKeyScopes(t *testing.T) {
+	service := createTestService()
+	authenticator, err := NewAPIKeyAuthenticator([]config.APIKeyConfig{
+		{ID: "scoped", Key: "scoped-key", Networks: []string{"net-1"}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to build authenticator: %v", err)
+	}
+	httpTransport := NewHTTPTransport("127.0.0.1:0", "/mcp", authenticator, logger.New())
+	mcpServer := mcp.NewServer(httpTransport)
+	server := &toolServer{Server: mcpServer, service: service}
+	if err := server.RegisterTool("list_devices", "test tool", func(args ListDevicesArgs) (*mcp.ToolResponse, error) {
+		return mcp.NewToolResponse(mcp.NewTextContent("devices of " + args.NetworkID)), nil
+	}); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+	go mcpServer.Serve()
+	defer httpTransport.Close()
+
+	call := func(key, network string) (int, string) {
+		body := `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"list_devices","arguments":{"network_id":"` + network + `"}}}`
+		for attempt := 0; ; attempt++ {
+			request := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+			request.Header.Set("Authorization", "Bearer "+key)
+			recorder := httptest.NewRecorder()
+			httpTransport.ServeHTTP(recorder, request)
+			if recorder.Code != http.StatusServiceUnavailable || attempt > 50 {
+				return recorder.Code, recorder.Body.String()
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	if code, _ := call("unknown", "net-1"); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for an unknown key, got %d", code)
+	}
+	code, body := call("scoped-key", "net-1")
+	if code != http.StatusOK || !contains(body, "devices of net-1") || !contains(body, `"id":7`) {
+		t.Errorf("Expected the in-scope call to succeed, got %d: %s", code, body)
+	}
+	if _, body := call("scoped-key", "net-2"); !contains(body, "not allowed to access network net-2") {
+		t.Errorf("Expected the out-of-scope call to be denied, got: %s", body)
+	}
+}
+
+func TestAPIKeyScopesApplyToPrompts(t *testing.T) {
+	service := createTestService()
+	// Stands in for a workflow whose step selects the network named in the answer, or the default
+	handler := func(args SiteTurnupWorkflowArgs) (*mcp.PromptResponse, error) {
+		key := "turnup_session_" + args.SessionID
+		state := service.workflowManager.GetState(key)
+		state.NetworkID = service.getNetworkID(parseWorkflowKeyValues(args.Answer)["network_id"])
+		service.workflowManager.SetState(key, state)
+		return mcp.NewPromptResponse("test", mcp.NewPromptMessage(mcp.NewTextContent("network "+state.NetworkID), mcp.RoleAssistant)), nil
+	}
+	wrapped := service.authorizePromptHandler("site_turnup_workflow", handler).(func(SiteTurnupWorkflowArgs) (*mcp.PromptResponse, error))
+	// The transport puts the calling key in front of the session, as it would for prompts/get
+	call := func(identity *APIKeyIdentity, session, answer string) (*mcp.PromptResponse, error) {
+		params := service.scopePromptSession(WithAPIKeyIdentity(context.Background(), identity),
+			json.RawMessage(`{"name":"site_turnup_workflow","arguments":{"session_id":"`+session+`","answer":"`+answer+`"}}`))
+		var request struct {
+			Arguments SiteTurnupWorkflowArgs `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &request); err != nil {
+			t.Fatalf("Expected valid scoped params, got %v", err)
+		}
+		return wrapped(request.Arguments)
+	}
+	scoped := &APIKeyIdentity{ID: "scoped", Networks: map[string]bool{"net-1": true}}
+
+	if _, err := call(scoped, "s1", "network_id=net-2"); err == nil || !contains(err.Error(), "not allowed to access network net-2") {
+		t.Errorf("Expected a network outside the key's scope to be denied, got %v", err)
+	}
+	response, err := call(scoped, "s1", "network_id=net-1, site=hq")
+	if err != nil || response.Messages[0].Content.TextContent.Text != "network net-1" {
+		t.Fatalf("Expected the in-scope network to be selected, got %+v, %v", response, err)
+	}
+	// Falling back to the default network is checked once the step has selected it
+	if _, err := call(scoped, "s1", "site=hq"); err == nil {
+		t.Error("Expected the default network outside the key's scope to be denied")
+	}
+	if state := service.workflowManager.GetState("turnup_session_scoped" + promptCallerSeparator + "s1"); state.NetworkID != "net-1" {
+		t.Errorf("Expected the denied selection undone, got network %q", state.NetworkID)
+	}
+	if state := service.workflowManager.GetState("turnup_session_s1"); state.NetworkID != "" {
+		t.Error("Expected the session kept under the calling key")
+	}
+
+	if _, err := call(&APIKeyIdentity{ID: "ro", ReadOnly: true}, "s2", ""); err == nil || !contains(err.Error(), "read-only") {
+		t.Errorf("Expected a read-only key to be denied a writing workflow, got %v", err)
+	}
+}
+
+func TestAPIKeyNetworkScopesApplyToMemoryReads(t *testing.T) {
+	service := createTestService()
+	entityType := uniqueTestName("scoped_result_")
+	permitted, _ := service.memorySystem.CreateEntity("result-net-1", entityType, map[string]interface{}{"network_id": "net-1"})
+	hidden, _ := service.memorySystem.CreateEntity("result-net-2", entityType, map[string]interface{}{"network_id": "net-2"})
+	scoped := WithAPIKeyIdentity(context.Background(), &APIKeyIdentity{ID: "scoped", Networks: map[string]bool{"net-1": true}})
+
+	getEntity := service.authorizeToolHandler("get_entity", service.getEntity).(func(context.Context, GetEntityArgs) (*mcp.ToolResponse, error))
+	if _, err := getEntity(scoped, GetEntityArgs{Identifier: hidden.ID}); err == nil || !contains(err.Error(), "entity not found") {
+		t.Errorf("Expected an entity of another network reported as missing, got %v", err)
+	}
+	if _, err := getEntity(scoped, GetEntityArgs{Identifier: permitted.ID}); err != nil {
+		t.Errorf("Expected an entity of the key's network to be readable, got %v", err)
+	}
+
+	search := service.authorizeToolHandler("search_entities", service.searchEntities).(func(context.Context, SearchEntitiesArgs) (*mcp.ToolResponse, error))
+	response, err := search(scoped, SearchEntitiesArgs{EntityType: entityType, Limit: 10})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !contains(text, "result-net-1") || contains(text, "result-net-2") {
+		t.Errorf("Expected only the key's network in the search, got: %s", text)
+	}
+
+	identity := apiKeyIdentityFromContext(scoped)
+	for path, visible := range map[string]bool{"/api/networks/net-1/snapshots": true, "/api/nqe?networkId=net-2": false, "/api/version": false} {
+		if service.apiErrorVisible(identity, forward.APIExchangeError{Path: path}) != visible {
+			t.Errorf("Expected the failure of %s visible=%t", path, visible)
+		}
+	}
+	if !service.apiErrorVisible(nil, forward.APIExchangeError{Path: "/api/version"}) {
+		t.Error("Expected unscoped callers to see failures without a network")
+	}
+}
+
+// readOnlyTools were reviewed as having no side effects beyond storing the results they fetch
+// or analyze. A new tool must be added here or to writeTools.
+var readOnlyTools = []string{
+	"analyze_network_prefixes", "analyze_nqe_result_sql", "analyze_summarization", "check_device_onboarding",
+	"check_fhrp", "check_interface_hygiene", "check_query_compatibility", "check_vlan_consistency",
+	"classify_devices", "classify_intent", "client_diagnostics", "continue_response",
+	"detect_connectivity_drift", "detect_result_anomalies", "diff_stored_results", "estimate_query_cost",
+	"find_duplicate_entities", "forecast_eol_exposure", "generate_chart_spec", "get_bloom_filter_stats",
+	"get_cache_stats", "get_check_results", "get_config_diff", "get_config_section",
+	"get_database_status", "get_default_settings", "get_device_basic_info", "get_device_hardware",
+	"get_device_locations", "get_entity", "get_entity_versions", "get_federated_inventory",
+	"get_hardware_support", "get_last_api_errors", "get_latest_snapshot", "get_memory_stats",
+	"get_nqe_query_source", "get_nqe_result_chunks", "get_nqe_result_summary", "get_observations",
+	"get_os_support", "get_pipeline_runs", "get_query_analytics", "get_redaction_stats",
+	"get_relations", "get_session_transcript", "get_shared_result", "get_storage_stats",
+	"get_support_matrix", "get_vlan_inventory", "inspect_cache_entry", "list_cache_entries",
+	"list_device_aliases", "list_device_tags", "list_devices", "list_feature_flags",
+	"list_instance_ids", "list_locations", "list_networks", "list_nqe_queries",
+	"list_platform_checks", "list_presets", "list_query_taxonomy", "list_schema_changes",
+	"list_snapshots", "list_trash", "list_workspace_contents", "lookup_error",
+	"prefix_documentation_coverage", "query_catalog_sql", "query_timeseries", "reconcile_inventory",
+	"run_diagnostics", "run_federated_query", "run_nqe_query_by_id", "search_bloom_filter",
+	"search_configs", "search_entities", "search_nqe_queries", "search_paths",
+	"search_paths_bulk", "suggest_parameter_values", "suggest_similar_queries", "suggest_site_pairs",
+	"sweep_violations", "troubleshoot_connectivity", "which_devices_in_prefix",
+}
+
+func TestRegisteredToolsClassifiedAsReadOrWrite(t *testing.T) {
+	service := createTestService()
+	service.features = NewFeatureFlags(config.FeatureFlagConfig{Enabled: []string{"experimental/*"}})
+	server := mcp.NewServer(stdio.NewStdioServerTransport())
+	if err := service.RegisterTools(server); err != nil {
+		t.Fatalf("Failed to register tools: %v", err)
+	}
+	if err := service.RegisterPrompts(server); err != nil {
+		t.Fatalf("Failed to register prompts: %v", err)
+	}
+	// Prompts are held to the same scopes, so each needs a tool group
+	for _, prompt := range []string{"nqe_discovery", "network_discovery", "large_nqe_results_workflow", "path_search_workflow",
+		"network_prefix_discovery_workflow", "security_posture_workflow", "site_turnup_workflow", "onboard_devices_workflow", "bulk_location_setup"} {
+		if !server.CheckPromptRegistered(prompt) || toolGroups[prompt] == "" {
+			t.Errorf("Expected prompt %s to be registered with a tool group", prompt)
+		}
+	}
+	readOnly := make(map[string]bool, len(readOnlyTools))
+	for _, tool := range readOnlyTools {
+		readOnly[tool] = true
+		if writeTools[tool] {
+			t.Errorf("Tool %s is listed as both read-only and write", tool)
+		}
+	}
+	for tool, registered := range service.features.registered {
+		if registered && !writeTools[tool] && !readOnly[tool] {
+			t.Errorf("Tool %s is not classified: add it to writeTools if it changes state or writes files, otherwise to readOnlyTools", tool)
+		}
+	}
+	for _, tool := range []string{"export_workspace", "export_service_map", "generate_network_docs", "detect_device_renames", "start_analysis_session", "end_analysis_session"} {
+		if !writeTools[tool] {
+			t.Errorf("Expected %s to be a write tool", tool)
+		}
+	}
+}
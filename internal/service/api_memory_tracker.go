@@ -12,6 +12,7 @@ import (
 // APIMemoryTracker integrates the memory system with API result tracking
 type APIMemoryTracker struct {
 	memorySystem *MemorySystem
+	linker       *AutoLinker // Links tracked devices, prefixes, locations and results
 	logger       *logger.Logger
 	instanceID   string
 }
@@ -20,6 +21,7 @@ type APIMemoryTracker struct {
 func NewAPIMemoryTracker(memorySystem *MemorySystem, logger *logger.Logger, instanceID string) *APIMemoryTracker {
 	return &APIMemoryTracker{
 		memorySystem: memorySystem,
+		linker:       NewAutoLinker(memorySystem, logger),
 		logger:       logger,
 		instanceID:   instanceID,
 	}
@@ -27,6 +29,16 @@ func NewAPIMemoryTracker(memorySystem *MemorySystem, logger *logger.Logger, inst
 
 // TrackNetworkQuery tracks when a query is executed on a network
 func (amt *APIMemoryTracker) TrackNetworkQuery(queryID, networkID, snapshotID string, result *forward.NQERunResult, executionTime time.Duration) error {
+	return amt.trackNetworkQuery(queryID, networkID, snapshotID, result, executionTime, 0)
+}
+
+// TrackNetworkQueryPage tracks a single-page query execution with its page limit, so a page
+// that came back full is known to be a lower bound of the result size
+func (amt *APIMemoryTracker) TrackNetworkQueryPage(queryID, networkID, snapshotID string, result *forward.NQERunResult, executionTime time.Duration, limit int) error {
+	return amt.trackNetworkQuery(queryID, networkID, snapshotID, result, executionTime, limit)
+}
+
+func (amt *APIMemoryTracker) trackNetworkQuery(queryID, networkID, snapshotID string, result *forward.NQERunResult, executionTime time.Duration, limit int) error {
 	if amt.memorySystem == nil {
 		return nil // Memory system not available
 	}
@@ -94,6 +106,7 @@ func (amt *APIMemoryTracker) TrackNetworkQuery(queryID, networkID, snapshotID st
 			amt.logger.Debug("Failed to create relation %s->%s (%s): %v", rel.fromID, rel.toID, rel.relationType, err)
 		}
 	}
+	links := amt.linker.LinkQueryResult(resultEntity, networkEntity, snapshotEntity, result)
 
 	// Add performance observation
 	perfMetadata := map[string]interface{}{
@@ -102,6 +115,10 @@ func (amt *APIMemoryTracker) TrackNetworkQuery(queryID, networkID, snapshotID st
 		"snapshot_id":       snapshotID,
 		"network_id":        networkID,
 		"timestamp":         time.Now().Unix(),
+		"mode":              queryRunPage,
+	}
+	if limit > 0 {
+		perfMetadata["limit"] = limit
 	}
 
 	_, err = amt.memorySystem.AddObservation(
@@ -115,12 +132,65 @@ func (amt *APIMemoryTracker) TrackNetworkQuery(queryID, networkID, snapshotID st
 		amt.logger.Debug("Failed to add performance observation: %v", err)
 	}
 
-	amt.logger.Debug("Tracked query execution: %s on network %s (results: %d, time: %dms)",
-		queryID, networkID, len(result.Items), executionTime.Milliseconds())
+	amt.logger.Debug("Tracked query execution: %s on network %s (results: %d, time: %dms, links: %d)",
+		queryID, networkID, len(result.Items), executionTime.Milliseconds(), links)
 
 	return nil
 }
 
+// TrackBatchFetch tracks a complete all_results fetch: its total row count, the batches it
+// took and its wall-clock time at the given concurrency
+func (amt *APIMemoryTracker) TrackBatchFetch(queryID, networkID, snapshotID string, rows, batches, concurrency int, executionTime time.Duration) error {
+	return amt.trackQueryRun(queryID, networkID,
+		fmt.Sprintf("Fetched all %d rows in %d batches in %dms", rows, batches, executionTime.Milliseconds()),
+		map[string]interface{}{
+			"execution_time_ms": executionTime.Milliseconds(),
+			"result_count":      rows,
+			"batches":           batches,
+			"concurrency":       concurrency,
+			"snapshot_id":       snapshotID,
+			"network_id":        networkID,
+			"timestamp":         time.Now().Unix(),
+			"mode":              queryRunAllResults,
+		})
+}
+
+// TrackOversizedResult tracks a query whose single-page result the API rejected as too large
+func (amt *APIMemoryTracker) TrackOversizedResult(queryID, networkID, snapshotID string) error {
+	return amt.trackQueryRun(queryID, networkID, "Result exceeded the maximum response length",
+		map[string]interface{}{
+			"snapshot_id": snapshotID,
+			"network_id":  networkID,
+			"timestamp":   time.Now().Unix(),
+			"mode":        queryRunOversized,
+		})
+}
+
+// trackQueryRun adds a performance observation to a query, linking it to the network it ran on
+func (amt *APIMemoryTracker) trackQueryRun(queryID, networkID, content string, metadata map[string]interface{}) error {
+	if amt.memorySystem == nil {
+		return nil
+	}
+	networkEntity, err := amt.ensureNetworkEntity(networkID)
+	if err != nil {
+		return err
+	}
+	queryEntity, err := amt.ensureQueryEntity(queryID)
+	if err != nil {
+		return err
+	}
+	if executionTime, ok := metadata["execution_time_ms"]; ok {
+		if _, err := amt.memorySystem.CreateRelation(queryEntity.ID, networkEntity.ID, "executed_on", map[string]interface{}{
+			"timestamp":      time.Now().Unix(),
+			"execution_time": executionTime,
+		}); err != nil {
+			amt.logger.Debug("Failed to create relation %s->%s (executed_on): %v", queryEntity.ID, networkEntity.ID, err)
+		}
+	}
+	_, err = amt.memorySystem.AddObservation(queryEntity.ID, content, "performance", metadata)
+	return err
+}
+
 // TrackDeviceDiscovery tracks when devices are discovered in a network
 func (amt *APIMemoryTracker) TrackDeviceDiscovery(networkID string, devices []forward.Device) error {
 	if amt.memorySystem == nil || len(devices) == 0 {
@@ -153,12 +223,16 @@ func (amt *APIMemoryTracker) TrackDeviceDiscovery(networkID string, devices []fo
 			deviceMetadata["management_ip"] = device.ManagementIPs[0]
 		}
 
-		deviceEntity, err := amt.memorySystem.CreateEntity(device.Name, "device", deviceMetadata)
-		if err != nil {
-			// Entity might already exist, try to get it
-			deviceEntity, err = amt.memorySystem.GetEntity(device.Name)
+		// Update a known device in place so its relations survive rediscovery
+		deviceEntity, err := amt.memorySystem.getEntityByNameAndType(device.Name, "device")
+		if err == nil {
+			if err := amt.memorySystem.updateEntityMetadata(deviceEntity.ID, deviceMetadata); err != nil {
+				amt.logger.Debug("Failed to update device entity %s: %v", device.Name, err)
+			}
+		} else {
+			deviceEntity, err = amt.memorySystem.CreateEntity(device.Name, "device", deviceMetadata)
 			if err != nil {
-				amt.logger.Debug("Failed to create/get device entity %s: %v", device.Name, err)
+				amt.logger.Debug("Failed to create device entity %s: %v", device.Name, err)
 				continue
 			}
 		}
@@ -170,6 +244,7 @@ func (amt *APIMemoryTracker) TrackDeviceDiscovery(networkID string, devices []fo
 		if err != nil {
 			amt.logger.Debug("Failed to create device-network relation: %v", err)
 		}
+		amt.linker.LinkDevice(deviceEntity, networkEntity, device)
 
 		deviceCount++
 	}
@@ -233,6 +308,7 @@ func (amt *APIMemoryTracker) TrackPathSearch(networkID, srcIP, dstIP string, res
 			amt.logger.Debug("Failed to create search-network relation: %v", err)
 		}
 	}
+	links := amt.linker.LinkPathSearch(searchEntity, networkID, srcIP, dstIP, result.Paths)
 
 	// Add observation about path search results
 	var outcome string
@@ -253,8 +329,8 @@ func (amt *APIMemoryTracker) TrackPathSearch(networkID, srcIP, dstIP string, res
 		amt.logger.Debug("Failed to add path search observation: %v", err)
 	}
 
-	amt.logger.Debug("Tracked path search: %s->%s on network %s (%d paths, %dms)",
-		srcIP, dstIP, networkID, len(result.Paths), result.SearchTimeMs)
+	amt.logger.Debug("Tracked path search: %s->%s on network %s (%d paths, %dms, links: %d)",
+		srcIP, dstIP, networkID, len(result.Paths), result.SearchTimeMs, links)
 
 	return nil
 }
@@ -394,8 +470,13 @@ func (amt *APIMemoryTracker) ensureSnapshotEntity(snapshotID, networkID string)
 }
 
 func (amt *APIMemoryTracker) createQueryResultEntity(queryID, networkID, snapshotID string, result *forward.NQERunResult, executionTime time.Duration) (*Entity, error) {
-	// Create unique result ID
-	resultID := fmt.Sprintf("result_%s_%s_%d", queryID, networkID, time.Now().Unix())
+	// One result entity per query, network and snapshot; re-runs with the same items reuse it
+	// and changed items become a new version
+	resultID := fmt.Sprintf("result_%s_%s_%s", queryID, networkID, snapshotID)
+	hash, err := contentHash(result.Items)
+	if err != nil {
+		return nil, err
+	}
 
 	// Calculate result size
 	resultBytes, _ := json.Marshal(result)
@@ -411,5 +492,6 @@ func (amt *APIMemoryTracker) createQueryResultEntity(queryID, networkID, snapsho
 		"timestamp":      time.Now().Unix(),
 	}
 
-	return amt.memorySystem.CreateEntity(resultID, "query_result", metadata)
+	entity, _, err := amt.memorySystem.storeEntityVersion(resultID, "query_result", metadata, hash)
+	return entity, err
 }
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// Relation types created by the auto-linker
+const (
+	relationLocatedAt   = "located_at"   // device → location
+	relationConnectedTo = "connected_to" // device → prefix
+	relationPrefixOf    = "prefix_of"    // prefix → network
+	relationTraverses   = "traverses"    // path search → device
+	relationFromPrefix  = "from_prefix"  // path search → prefix holding the source
+	relationToPrefix    = "to_prefix"    // path search → prefix holding the destination
+	relationResultOf    = "result_of"    // query result → network
+	relationAtSnapshot  = "at_snapshot"  // query result → snapshot
+	relationMentions    = "mentions"     // query result → device named in its rows
+)
+
+const (
+	maxMentionedDevices   = 200 // Cap on device links per query result
+	linkedDeviceNameLimit = 256 // Longer cell values are not device names
+)
+
+// deviceNameColumns are the NQE result columns whose values name devices
+var deviceNameColumns = map[string]bool{
+	"device":      true,
+	"devicename":  true,
+	"device_name": true,
+	"hostname":    true,
+}
+
+// AutoLinker derives relations between tracked entities from API results, so devices,
+// networks, locations, prefixes, path searches and query results form one graph
+type AutoLinker struct {
+	memorySystem *MemorySystem
+	logger       *logger.Logger
+}
+
+// NewAutoLinker creates an auto-linker writing to the memory system
+func NewAutoLinker(memorySystem *MemorySystem, logger *logger.Logger) *AutoLinker {
+	return &AutoLinker{memorySystem: memorySystem, logger: logger}
+}
+
+// LinkDevice links a device to its location and to the prefixes of its interfaces, and each
+// prefix to the network. It returns the number of relations created.
+func (al *AutoLinker) LinkDevice(deviceEntity, networkEntity *Entity, device forward.Device) int {
+	created := 0
+	if device.LocationID != "" {
+		location, err := al.ensureEntity(device.LocationID, "location", map[string]interface{}{
+			"location_id": device.LocationID,
+		})
+		if err == nil && al.link(deviceEntity.ID, location.ID, relationLocatedAt, nil) {
+			created++
+		}
+	}
+
+	for _, iface := range device.Interfaces {
+		_, subnet, err := net.ParseCIDR(strings.TrimSpace(iface.IPAddress))
+		if err != nil {
+			continue
+		}
+		prefix, err := al.ensureEntity(prefixEntityName(networkEntity.Name, subnet.String()), prefixEntityType, map[string]interface{}{
+			"prefix":     subnet.String(),
+			"network_id": networkEntity.Name,
+		})
+		if err != nil {
+			continue
+		}
+		if al.link(deviceEntity.ID, prefix.ID, relationConnectedTo, map[string]interface{}{"interface": iface.Name}) {
+			created++
+		}
+		if al.link(prefix.ID, networkEntity.ID, relationPrefixOf, nil) {
+			created++
+		}
+	}
+	return created
+}
+
+// LinkPathSearch links a path search to every device its paths traverse and to the known
+// prefixes of its network holding its source and destination. It returns the number of
+// relations created.
+func (al *AutoLinker) LinkPathSearch(searchEntity *Entity, networkID, srcIP, dstIP string, paths []forward.Path) int {
+	created := 0
+	linked := make(map[string]bool)
+	for pathIndex, path := range paths {
+		for hopIndex, hop := range path.Hops {
+			if hop.Device == "" || linked[hop.Device] {
+				continue
+			}
+			linked[hop.Device] = true
+			device, err := al.ensureEntity(hop.Device, "device", map[string]interface{}{
+				"network_id": networkID,
+			})
+			if err != nil {
+				continue
+			}
+			if al.link(searchEntity.ID, device.ID, relationTraverses, map[string]interface{}{
+				"path_index": pathIndex,
+				"hop_index":  hopIndex,
+				"outcome":    path.Outcome,
+			}) {
+				created++
+			}
+		}
+	}
+
+	for _, endpoint := range []struct{ ip, relation string }{{srcIP, relationFromPrefix}, {dstIP, relationToPrefix}} {
+		if prefix := al.longestPrefixEntity(networkID, endpoint.ip); prefix != nil {
+			if al.link(searchEntity.ID, prefix.ID, endpoint.relation, map[string]interface{}{"ip": endpoint.ip}) {
+				created++
+			}
+		}
+	}
+	return created
+}
+
+// LinkQueryResult links a query result to its network and snapshot and to the already
+// known devices named in its rows. It returns the number of relations created.
+func (al *AutoLinker) LinkQueryResult(resultEntity, networkEntity, snapshotEntity *Entity, result *forward.NQERunResult) int {
+	created := 0
+	if al.link(resultEntity.ID, networkEntity.ID, relationResultOf, nil) {
+		created++
+	}
+	if snapshotEntity != nil && al.link(resultEntity.ID, snapshotEntity.ID, relationAtSnapshot, nil) {
+		created++
+	}
+	if result == nil {
+		return created
+	}
+
+	seen := make(map[string]bool)
+	for _, row := range result.Items {
+		for column, value := range row {
+			name, ok := value.(string)
+			if !ok || name == "" || len(name) > linkedDeviceNameLimit || seen[name] || !deviceNameColumns[strings.ToLower(column)] {
+				continue
+			}
+			seen[name] = true
+			if len(seen) > maxMentionedDevices {
+				return created
+			}
+			device, err := al.memorySystem.getEntityByNameAndType(name, "device")
+			if err != nil {
+				continue
+			}
+			if al.link(resultEntity.ID, device.ID, relationMentions, map[string]interface{}{"column": column}) {
+				created++
+			}
+		}
+	}
+	return created
+}
+
+// ensureEntity returns the entity with name and type, creating it when missing
+func (al *AutoLinker) ensureEntity(name, entityType string, metadata map[string]interface{}) (*Entity, error) {
+	if entity, err := al.memorySystem.getEntityByNameAndType(name, entityType); err == nil {
+		return entity, nil
+	}
+	metadata["discovered_at"] = time.Now().Unix()
+	entity, err := al.memorySystem.CreateEntity(name, entityType, metadata)
+	if err != nil {
+		al.logger.Debug("Failed to create %s entity %s: %v", entityType, name, err)
+	}
+	return entity, err
+}
+
+// link creates a relation, reporting false when it already exists or cannot be created
+func (al *AutoLinker) link(fromID, toID, relationType string, properties map[string]interface{}) bool {
+	if al.memorySystem.hasRelation(fromID, toID, relationType) {
+		return false
+	}
+	if properties == nil {
+		properties = make(map[string]interface{})
+	}
+	properties["linked_at"] = time.Now().Unix()
+	properties["auto_linked"] = true
+	if _, err := al.memorySystem.CreateRelation(fromID, toID, relationType, properties); err != nil {
+		al.logger.Debug("Failed to auto-link %s->%s (%s): %v", fromID, toID, relationType, err)
+		return false
+	}
+	return true
+}
+
+// longestPrefixEntity returns the most specific prefix entity of the network containing ip
+func (al *AutoLinker) longestPrefixEntity(networkID, ip string) *Entity {
+	if net.ParseIP(strings.TrimSpace(ip)) == nil {
+		return nil
+	}
+	host, err := normalizePrefix(ip)
+	if err != nil {
+		return nil
+	}
+	for _, prefix := range containingPrefixes(host) {
+		if entity, err := al.memorySystem.getEntityByNameAndType(prefixEntityName(networkID, prefix), prefixEntityType); err == nil {
+			return entity
+		}
+	}
+	return nil
+}
+
+// prefixEntityName names a prefix entity; address space is often reused between networks,
+// so prefixes are scoped to their network
+func prefixEntityName(networkID, prefix string) string {
+	return networkID + ":" + prefix
+}
+
+// containingPrefixes returns subnet and every less specific prefix containing it, most
+// specific first, so the longest match can be found with exact name lookups
+func containingPrefixes(subnet *net.IPNet) []string {
+	address := subnet.IP
+	if v4 := address.To4(); v4 != nil {
+		address = v4
+	}
+	length, bits := subnet.Mask.Size()
+	prefixes := make([]string, 0, length+1)
+	for ; length >= 0; length-- {
+		mask := net.CIDRMask(length, bits)
+		prefixes = append(prefixes, (&net.IPNet{IP: address.Mask(mask), Mask: mask}).String())
+	}
+	return prefixes
+}
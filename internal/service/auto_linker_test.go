@@ -0,0 +1,108 @@
+package service
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+func TestAutoLinkerLinksTrackedData(t *testing.T) {
+	memorySystem := createTestMemorySystem(t)
+	defer memorySystem.Close()
+	tracker := NewAPIMemoryTracker(memorySystem, logger.New(), "test-instance")
+
+	devices := []forward.Device{
+		{Name: "edge-1", LocationID: "loc-nyc", Interfaces: []forward.DeviceInterface{
+			{Name: "vlan10", IPAddress: "10.10.1.1/24"},
+			{Name: "lo0", IPAddress: "10.255.0.1/32"},
+		}},
+		{Name: "core-1", LocationID: "loc-nyc"},
+	}
+	if err := tracker.TrackDeviceDiscovery("net-1", devices); err != nil {
+		t.Fatalf("Failed to track devices: %v", err)
+	}
+
+	edge, err := memorySystem.getEntityByNameAndType("edge-1", "device")
+	if err != nil {
+		t.Fatalf("Device entity not found: %v", err)
+	}
+	located, _ := memorySystem.GetRelations(edge.ID, relationLocatedAt)
+	connected, _ := memorySystem.GetRelations(edge.ID, relationConnectedTo)
+	if len(located) != 1 || len(connected) != 2 {
+		t.Fatalf("Expected 1 location and 2 prefix links, got %d and %d", len(located), len(connected))
+	}
+
+	// Tracking the same devices again creates no duplicate links
+	if err := tracker.TrackDeviceDiscovery("net-1", devices); err != nil {
+		t.Fatalf("Failed to track devices: %v", err)
+	}
+	if connected, _ := memorySystem.GetRelations(edge.ID, relationConnectedTo); len(connected) != 2 {
+		t.Errorf("Expected re-tracking to keep 2 prefix links, got %d", len(connected))
+	}
+
+	search := &forward.PathSearchResponse{Paths: []forward.Path{{
+		Outcome: "delivered",
+		Hops:    []forward.Hop{{Device: "edge-1"}, {Device: "core-1"}, {Device: "dc-fw-1"}},
+	}}}
+	if err := tracker.TrackPathSearch("net-1", "10.10.1.50", "172.16.0.10", search); err != nil {
+		t.Fatalf("Failed to track path search: %v", err)
+	}
+	searchEntity, err := memorySystem.getEntityByNameAndType("path_search_10.10.1.50_to_172.16.0.10", "path_search")
+	if err != nil {
+		t.Fatalf("Path search entity not found: %v", err)
+	}
+	traverses, _ := memorySystem.GetRelations(searchEntity.ID, relationTraverses)
+	fromPrefix, _ := memorySystem.GetRelations(searchEntity.ID, relationFromPrefix)
+	toPrefix, _ := memorySystem.GetRelations(searchEntity.ID, relationToPrefix)
+	if len(traverses) != 3 || len(fromPrefix) != 1 || len(toPrefix) != 0 {
+		t.Errorf("Expected 3 traversed devices and a source prefix, got %d, %d, %d", len(traverses), len(fromPrefix), len(toPrefix))
+	}
+	if prefix, err := memorySystem.getEntityByID(fromPrefix[0].ToID); err != nil || prefix.Name != prefixEntityName("net-1", "10.10.1.0/24") {
+		t.Errorf("Expected the source prefix of net-1, got %v (%v)", prefix, err)
+	}
+
+	// The same address in another network is not placed in net-1's prefix
+	if err := tracker.TrackPathSearch("net-2", "10.10.1.60", "172.16.0.10", search); err != nil {
+		t.Fatalf("Failed to track path search: %v", err)
+	}
+	otherSearch, err := memorySystem.getEntityByNameAndType("path_search_10.10.1.60_to_172.16.0.10", "path_search")
+	if err != nil {
+		t.Fatalf("Path search entity not found: %v", err)
+	}
+	if fromPrefix, _ := memorySystem.GetRelations(otherSearch.ID, relationFromPrefix); len(fromPrefix) != 0 {
+		t.Errorf("Expected no prefix link across networks, got %d", len(fromPrefix))
+	}
+
+	result := &forward.NQERunResult{Items: []map[string]interface{}{
+		{"deviceName": "edge-1", "status": "up"},
+		{"deviceName": "unknown-9", "status": "up"},
+	}}
+	if err := tracker.TrackNetworkQuery("FQ_links", "net-1", "snap-1", result, 10*time.Millisecond); err != nil {
+		t.Fatalf("Failed to track query: %v", err)
+	}
+	results, _ := memorySystem.SearchEntities("result_FQ_links", "query_result", 10)
+	if len(results) != 1 {
+		t.Fatalf("Expected one query result entity, got %d", len(results))
+	}
+	mentions, _ := memorySystem.GetRelations(results[0].ID, relationMentions)
+	resultOf, _ := memorySystem.GetRelations(results[0].ID, relationResultOf)
+	atSnapshot, _ := memorySystem.GetRelations(results[0].ID, relationAtSnapshot)
+	if len(mentions) != 1 || len(resultOf) != 1 || len(atSnapshot) != 1 {
+		t.Errorf("Expected result links to 1 device, the network and the snapshot, got %d, %d, %d", len(mentions), len(resultOf), len(atSnapshot))
+	}
+}
+
+func TestContainingPrefixes(t *testing.T) {
+	host, _ := normalizePrefix("10.10.1.7")
+	prefixes := containingPrefixes(host)
+	if len(prefixes) != 33 || prefixes[0] != "10.10.1.7/32" || prefixes[8] != "10.10.1.0/24" || prefixes[32] != "0.0.0.0/0" {
+		t.Errorf("Unexpected IPv4 prefixes: %v", prefixes)
+	}
+	_, subnet, _ := net.ParseCIDR("2001:db8::/32")
+	if prefixes := containingPrefixes(subnet); len(prefixes) != 33 || prefixes[0] != "2001:db8::/32" || prefixes[32] != "::/0" {
+		t.Errorf("Unexpected IPv6 prefixes: %v", prefixes)
+	}
+}
@@ -0,0 +1,748 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Backup archive layout: one top-level directory per component plus a manifest
+const (
+	backupManifestName    = "manifest.json"
+	backupFormatVersion   = 1
+	backupArchiveSuffix   = ".tar.gz"
+	backupEmbeddings      = "embeddings"
+	backupMemoryDBFile    = "memory.db"
+	backupNQEDBFile       = "nqe_queries.db"
+	backupEmbeddingsFile  = "nqe-embeddings.json"
+	backupDirectoryName   = "backups"
+	backupFilePermissions = 0600
+)
+
+// backupComponents lists the components a backup can hold, in archive order
+var backupComponents = []string{storageMemoryDB, storageNQEDB, storageBloomIndexes, backupEmbeddings, storageReports}
+
+// BackupManifest describes the contents of a state backup archive
+type BackupManifest struct {
+	FormatVersion int               `json:"format_version"`
+	InstanceID    string            `json:"instance_id"`
+	CreatedAt     time.Time         `json:"created_at"`
+	Components    []BackupComponent `json:"components"`
+}
+
+// BackupComponent is one component stored in a backup archive
+type BackupComponent struct {
+	Name  string `json:"name"`
+	Files int    `json:"files"`
+	Bytes int64  `json:"bytes"`
+}
+
+// backupFile is a file to archive under a component directory
+type backupFile struct {
+	component string
+	name      string // Slash-separated path within the component
+	path      string
+}
+
+// copySQLiteDatabase copies src into dst with the SQLite online backup API, which yields a
+// consistent snapshot while other connections keep writing
+func copySQLiteDatabase(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open destination connection: %w", err)
+	}
+	defer dstConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open source connection: %w", err)
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			dstSQLite, ok := dstDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected sqlite driver connection %T", dstDriverConn)
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected sqlite driver connection %T", srcDriverConn)
+			}
+			backup, err := dstSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start SQLite backup: %w", err)
+			}
+			for {
+				done, err := backup.Step(-1)
+				if err != nil {
+					backup.Finish()
+					return fmt.Errorf("SQLite backup failed: %w", err)
+				}
+				if done {
+					break
+				}
+			}
+			return backup.Finish()
+		})
+	})
+}
+
+// snapshotDatabase writes a consistent copy of a live database to path
+func snapshotDatabase(ctx context.Context, db *sql.DB, path string) error {
+	dst, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return fmt.Errorf("failed to create database copy: %w", err)
+	}
+	defer dst.Close()
+	return copySQLiteDatabase(ctx, dst, db)
+}
+
+// backupDirectory returns where archives are written and looked up by name
+func backupDirectory() (string, error) {
+	dataDir, err := getWritableDataDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, backupDirectoryName), nil
+}
+
+// backupPath resolves a path inside the backup directory: a path relative to it, or an absolute
+// path within it. Paths that leave the directory are refused, so tool callers cannot read or
+// write archives elsewhere on the host.
+func backupPath(name string) (string, error) {
+	dir, err := backupDirectory()
+	if err != nil {
+		return "", err
+	}
+	relative := filepath.Clean(name)
+	if filepath.IsAbs(relative) {
+		if relative, err = filepath.Rel(dir, relative); err != nil {
+			return "", fmt.Errorf("%s is not in the backup directory %s", name, dir)
+		}
+	}
+	if !filepath.IsLocal(relative) && relative != "." {
+		return "", fmt.Errorf("%s is not in the backup directory %s", name, dir)
+	}
+	return filepath.Join(dir, relative), nil
+}
+
+// selectBackupComponents validates a component filter; empty selects every component
+func selectBackupComponents(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return backupComponents, nil
+	}
+	var selected []string
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(backupComponents, name) {
+			return nil, fmt.Errorf("unknown backup component %q (use %s)", name, strings.Join(backupComponents, ", "))
+		}
+		if !slices.Contains(selected, name) {
+			selected = append(selected, name)
+		}
+	}
+	return selected, nil
+}
+
+// directoryFiles lists the regular files under dir for a component
+func directoryFiles(component, dir string) []backupFile {
+	var files []backupFile
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return nil
+		}
+		files = append(files, backupFile{component: component, name: filepath.ToSlash(rel), path: path})
+		return nil
+	})
+	return files
+}
+
+// collectBackupFiles snapshots the databases into stagingDir and lists every file to archive
+func (s *ForwardMCPService) collectBackupFiles(ctx context.Context, components []string, stagingDir string) ([]backupFile, error) {
+	var files []backupFile
+	for _, component := range components {
+		switch component {
+		case storageMemoryDB:
+			if s.memorySystem == nil {
+				continue
+			}
+			path := filepath.Join(stagingDir, backupMemoryDBFile)
+			if err := snapshotDatabase(ctx, s.memorySystem.db, path); err != nil {
+				return nil, fmt.Errorf("failed to back up the memory database: %w", err)
+			}
+			files = append(files, backupFile{component, backupMemoryDBFile, path})
+		case storageNQEDB:
+			if s.database == nil {
+				continue
+			}
+			path := filepath.Join(stagingDir, backupNQEDBFile)
+			if err := snapshotDatabase(ctx, s.database.db, path); err != nil {
+				return nil, fmt.Errorf("failed to back up the NQE database: %w", err)
+			}
+			files = append(files, backupFile{component, backupNQEDBFile, path})
+		case storageBloomIndexes:
+			if s.bloomIndexManager != nil {
+				files = append(files, directoryFiles(component, s.bloomIndexManager.baseDir)...)
+			}
+		case backupEmbeddings:
+			if s.queryIndex == nil {
+				continue
+			}
+			if _, err := os.Stat(s.queryIndex.embeddingsCachePath); err == nil {
+				files = append(files, backupFile{component, backupEmbeddingsFile, s.queryIndex.embeddingsCachePath})
+			}
+		case storageReports:
+			if s.reports != nil {
+				files = append(files, directoryFiles(component, s.reports.dir)...)
+			}
+		}
+	}
+	return files, nil
+}
+
+// writeBackupArchive writes the manifest followed by the files as a gzipped tar archive
+func writeBackupArchive(path string, manifest BackupManifest, files []backupFile) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, backupFilePermissions)
+	if err != nil {
+		return fmt.Errorf("failed to create backup archive: %w", err)
+	}
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	err = func() error {
+		data, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode backup manifest: %w", err)
+		}
+		header := &tar.Header{Name: backupManifestName, Mode: backupFilePermissions, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := addFileToArchive(tw, file.component+"/"+file.name, file.path); err != nil {
+				return err
+			}
+		}
+		if err := tw.Close(); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		return out.Close()
+	}()
+	if err != nil {
+		out.Close()
+		os.Remove(path)
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	return nil
+}
+
+func addFileToArchive(tw *tar.Writer, name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header := &tar.Header{Name: name, Mode: backupFilePermissions, Size: info.Size(), ModTime: info.ModTime()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, file)
+	return err
+}
+
+// backupState writes a timestamped archive of the databases, bloom indexes, embeddings and reports
+func (s *ForwardMCPService) backupState(args BackupStateArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("backup_state", args, nil)
+
+	components, err := selectBackupComponents(args.Components)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := backupPath(args.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory %s: %w", dir, err)
+	}
+	stagingDir, err := os.MkdirTemp(dir, ".staging-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	files, err := s.collectBackupFiles(context.Background(), components, stagingDir)
+	if err != nil {
+		return nil, err
+	}
+	manifest := BackupManifest{FormatVersion: backupFormatVersion, InstanceID: s.instanceID, CreatedAt: time.Now().UTC()}
+	for _, component := range components {
+		entry := BackupComponent{Name: component}
+		for _, file := range files {
+			if file.component == component {
+				entry.Files++
+				if info, err := os.Stat(file.path); err == nil {
+					entry.Bytes += info.Size()
+				}
+			}
+		}
+		manifest.Components = append(manifest.Components, entry)
+	}
+
+	name := fmt.Sprintf("forward-mcp-state-%s%s", manifest.CreatedAt.Format("20060102-150405"), backupArchiveSuffix)
+	path := filepath.Join(dir, name)
+	if err := writeBackupArchive(path, manifest, files); err != nil {
+		return nil, err
+	}
+	size := int64(0)
+	if info, err := os.Stat(path); err == nil {
+		size = info.Size()
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Backup saved to %s (%s)\n\n", path, formatBytes(size)))
+	result.WriteString("| Component | Files | Size |\n|---|---|---|\n")
+	for _, component := range manifest.Components {
+		result.WriteString(fmt.Sprintf("| %s | %d | %s |\n", component.Name, component.Files, formatBytes(component.Bytes)))
+	}
+	result.WriteString(fmt.Sprintf("\nRestore on another host with restore_state archive=%s confirm=true.\n", path))
+	return mcp.NewToolResponse(mcp.NewTextContent(result.String())), nil
+}
+
+// resolveBackupArchive accepts the name or path of an archive in the backup directory
+func resolveBackupArchive(archive string) (string, error) {
+	if archive == "" {
+		return "", fmt.Errorf("archive is required")
+	}
+	path, err := backupPath(archive)
+	if err != nil {
+		return "", err
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return "", fmt.Errorf("backup archive %s not found", archive)
+	}
+	return path, nil
+}
+
+// extractBackupArchive unpacks an archive into dir and returns its manifest
+func extractBackupArchive(archive, dir string) (*BackupManifest, error) {
+	file, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid backup archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		// Security: refuse entries that would land outside the extraction directory
+		if !filepath.IsLocal(header.Name) {
+			return nil, fmt.Errorf("invalid backup archive: unsafe path %q", header.Name)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		out, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, backupFilePermissions)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(out, tr)
+		out.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", header.Name, err)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, backupManifestName))
+	if err != nil {
+		return nil, fmt.Errorf("backup archive has no manifest")
+	}
+	var manifest BackupManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if manifest.FormatVersion > backupFormatVersion {
+		return nil, fmt.Errorf("backup format version %d is newer than this server supports (%d)", manifest.FormatVersion, backupFormatVersion)
+	}
+	return &manifest, nil
+}
+
+// restoreDatabase replaces the contents of a live database with a backed up copy
+func restoreDatabase(ctx context.Context, live *sql.DB, backupPath string) error {
+	src, err := sql.Open("sqlite3", "file:"+backupPath+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backed up database: %w", err)
+	}
+	defer src.Close()
+	return copySQLiteDatabase(ctx, live, src)
+}
+
+// validateBackupDatabase checks that a backed up database opens and passes an integrity check
+func validateBackupDatabase(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("missing %s", filepath.Base(path))
+	}
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var status string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&status); err != nil {
+		return fmt.Errorf("%s is not a valid database: %w", filepath.Base(path), err)
+	}
+	if status != "ok" {
+		return fmt.Errorf("%s failed its integrity check: %s", filepath.Base(path), status)
+	}
+	return nil
+}
+
+// moveFile renames src to dst, copying when they sit on different filesystems
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, backupFilePermissions)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
+
+// restoreSwap moves live state aside while restoreState replaces it, so a failed restore
+// can put everything back and a successful one can discard the old copies
+type restoreSwap struct {
+	undo    []func() error
+	discard []string
+}
+
+// replace puts src at dst; an existing dst is renamed next to itself until commit
+func (r *restoreSwap) replace(src, dst string) error {
+	aside := ""
+	if _, err := os.Lstat(dst); err == nil {
+		aside = fmt.Sprintf("%s.restore-old-%d", dst, time.Now().UnixNano())
+		if err := os.Rename(dst, aside); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	putBack := func() error {
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if aside == "" {
+			return nil
+		}
+		return os.Rename(aside, dst)
+	}
+
+	var err error
+	if info, statErr := os.Stat(src); statErr != nil {
+		err = statErr
+	} else if info.IsDir() {
+		if err = os.MkdirAll(dst, 0700); err == nil {
+			for _, file := range directoryFiles("", src) {
+				if err = moveFile(file.path, filepath.Join(dst, filepath.FromSlash(file.name))); err != nil {
+					break
+				}
+			}
+		}
+	} else {
+		err = moveFile(src, dst)
+	}
+	if err != nil {
+		putBack()
+		return err
+	}
+	r.undo = append(r.undo, putBack)
+	if aside != "" {
+		r.discard = append(r.discard, aside)
+	}
+	return nil
+}
+
+// onRollback registers an extra step to undo when a later component fails
+func (r *restoreSwap) onRollback(undo func() error) {
+	r.undo = append(r.undo, undo)
+}
+
+// rollback undoes every completed step in reverse order
+func (r *restoreSwap) rollback() error {
+	var failed []string
+	for i := len(r.undo) - 1; i >= 0; i-- {
+		if err := r.undo[i](); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	for _, aside := range r.discard {
+		if _, err := os.Lstat(aside); err == nil {
+			failed = append(failed, "previous state left at "+aside)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("rollback incomplete: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// commit removes the live state that was moved aside
+func (r *restoreSwap) commit() {
+	for _, aside := range r.discard {
+		os.RemoveAll(aside)
+	}
+}
+
+// restorePlan is a validated component ready to be applied
+type restorePlan struct {
+	component string
+	dir       string
+}
+
+// planRestore checks every selected component before any live state is touched
+func (s *ForwardMCPService) planRestore(components []string, stagingDir string) ([]restorePlan, []string, error) {
+	var plans []restorePlan
+	var skipped []string
+	for _, component := range components {
+		componentDir := filepath.Join(stagingDir, component)
+		if _, err := os.Stat(componentDir); err != nil {
+			skipped = append(skipped, component+" (not in archive)")
+			continue
+		}
+		var err error
+		switch component {
+		case storageMemoryDB:
+			if s.memorySystem == nil {
+				skipped = append(skipped, component+" (memory system unavailable)")
+				continue
+			}
+			err = validateBackupDatabase(filepath.Join(componentDir, backupMemoryDBFile))
+		case storageNQEDB:
+			if s.database == nil {
+				skipped = append(skipped, component+" (NQE database unavailable)")
+				continue
+			}
+			err = validateBackupDatabase(filepath.Join(componentDir, backupNQEDBFile))
+		case storageBloomIndexes:
+			if s.bloomIndexManager == nil {
+				skipped = append(skipped, component+" (bloom indexes unavailable)")
+				continue
+			}
+		case backupEmbeddings:
+			if s.queryIndex == nil {
+				skipped = append(skipped, component+" (query index unavailable)")
+				continue
+			}
+			var data []byte
+			if data, err = os.ReadFile(filepath.Join(componentDir, backupEmbeddingsFile)); err == nil && !json.Valid(data) {
+				err = fmt.Errorf("%s is not valid JSON", backupEmbeddingsFile)
+			}
+		case storageReports:
+			if s.reports == nil {
+				skipped = append(skipped, component+" (report storage unavailable)")
+				continue
+			}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("backup component %s is invalid, nothing was restored: %w", component, err)
+		}
+		plans = append(plans, restorePlan{component: component, dir: componentDir})
+	}
+	return plans, skipped, nil
+}
+
+// restoreLiveDatabase replaces a live database, keeping a copy of its contents for rollback
+func restoreLiveDatabase(ctx context.Context, swap *restoreSwap, live *sql.DB, backupPath, previousPath string) error {
+	if err := snapshotDatabase(ctx, live, previousPath); err != nil {
+		return fmt.Errorf("failed to save the current contents: %w", err)
+	}
+	if err := restoreDatabase(ctx, live, backupPath); err != nil {
+		if rollbackErr := restoreDatabase(ctx, live, previousPath); rollbackErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rollbackErr)
+		}
+		return err
+	}
+	swap.onRollback(func() error { return restoreDatabase(ctx, live, previousPath) })
+	return nil
+}
+
+// applyRestorePlan applies one validated component and describes what was restored
+func (s *ForwardMCPService) applyRestorePlan(ctx context.Context, swap *restoreSwap, plan restorePlan, stagingDir string) (string, error) {
+	switch plan.component {
+	case storageMemoryDB:
+		previous := filepath.Join(stagingDir, "previous-"+backupMemoryDBFile)
+		if err := restoreLiveDatabase(ctx, swap, s.memorySystem.db, filepath.Join(plan.dir, backupMemoryDBFile), previous); err != nil {
+			return "", fmt.Errorf("failed to restore the memory database: %w", err)
+		}
+		return "memory_db: knowledge graph, stored results and time series", nil
+	case storageNQEDB:
+		previous := filepath.Join(stagingDir, "previous-"+backupNQEDBFile)
+		if err := restoreLiveDatabase(ctx, swap, s.database.db, filepath.Join(plan.dir, backupNQEDBFile), previous); err != nil {
+			return "", fmt.Errorf("failed to restore the NQE database: %w", err)
+		}
+		return "nqe_db: query index database (run refresh_query_index to reload the search index)", nil
+	case storageBloomIndexes:
+		count := len(directoryFiles("", plan.dir))
+		s.bloomIndexManager.Close()
+		if err := swap.replace(plan.dir, s.bloomIndexManager.baseDir); err != nil {
+			return "", fmt.Errorf("failed to restore bloom indexes: %w", err)
+		}
+		return fmt.Sprintf("bloom_indexes: %d files", count), nil
+	case backupEmbeddings:
+		if err := swap.replace(filepath.Join(plan.dir, backupEmbeddingsFile), s.queryIndex.embeddingsCachePath); err != nil {
+			return "", fmt.Errorf("failed to restore embeddings: %w", err)
+		}
+		return "embeddings: " + s.queryIndex.embeddingsCachePath, nil
+	case storageReports:
+		// Reports merge into the directory; only same-named files are replaced
+		files := directoryFiles(plan.component, plan.dir)
+		for _, file := range files {
+			if err := swap.replace(file.path, filepath.Join(s.reports.dir, filepath.Base(file.name))); err != nil {
+				return "", fmt.Errorf("failed to restore report %s: %w", file.name, err)
+			}
+		}
+		return fmt.Sprintf("reports: %d files", len(files)), nil
+	}
+	return "", nil
+}
+
+// restoreState replaces server state with the contents of a backup archive
+func (s *ForwardMCPService) restoreState(args RestoreStateArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("restore_state", args, nil)
+
+	if !args.Confirm {
+		return nil, fmt.Errorf("restore_state replaces the current memory, history and indexes; set confirm=true to proceed")
+	}
+	components, err := selectBackupComponents(args.Components)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := resolveBackupArchive(args.Archive)
+	if err != nil {
+		return nil, err
+	}
+	// Stage under the data directory so restored files can be renamed into place
+	dataDir, err := getWritableDataDirectory()
+	if err != nil {
+		return nil, err
+	}
+	stagingDir, err := os.MkdirTemp(dataDir, ".restore-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+	manifest, err := extractBackupArchive(archive, stagingDir)
+	if err != nil {
+		return nil, err
+	}
+	plans, skipped, err := s.planRestore(components, stagingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	swap := &restoreSwap{}
+	var restored []string
+	for _, plan := range plans {
+		line, err := s.applyRestorePlan(ctx, swap, plan, stagingDir)
+		if err != nil {
+			if rollbackErr := swap.rollback(); rollbackErr != nil {
+				s.logger.Error("Failed to roll back restore_state: %v", rollbackErr)
+				return nil, fmt.Errorf("%w; %v", err, rollbackErr)
+			}
+			return nil, fmt.Errorf("%w; the previous state was kept", err)
+		}
+		restored = append(restored, line)
+	}
+	swap.commit()
+
+	// Reload what was replaced now that the restore is final
+	for _, plan := range plans {
+		switch plan.component {
+		case storageMemoryDB:
+			// SQL analysis databases were built from the replaced results
+			if s.analysisCache != nil {
+				s.analysisCache.mutex.Lock()
+				removeStaleEntries(s.analysisCache.dir, time.Time{}, false, nil)
+				s.analysisCache.mutex.Unlock()
+			}
+		case backupEmbeddings:
+			s.queryIndex.mutex.Lock()
+			err := s.queryIndex.loadEmbeddingsFromCache()
+			s.queryIndex.mutex.Unlock()
+			if err != nil {
+				s.logger.Warn("Failed to reload restored embeddings: %v", err)
+			}
+		case storageReports:
+			s.reports.reload()
+		}
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Restored state from %s (created %s)\n\n", archive, manifest.CreatedAt.Format(time.RFC3339)))
+	if manifest.InstanceID != "" && manifest.InstanceID != s.instanceID {
+		result.WriteString(fmt.Sprintf("⚠️ The backup was taken for instance %s but this server uses %s; restored data stays under the original instance ID (see list_instance_ids).\n\n", manifest.InstanceID, s.instanceID))
+	}
+	for _, line := range restored {
+		result.WriteString("- " + line + "\n")
+	}
+	if len(skipped) > 0 {
+		result.WriteString(fmt.Sprintf("\nSkipped: %s\n", strings.Join(skipped, ", ")))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(result.String())), nil
+}
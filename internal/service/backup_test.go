@@ -0,0 +1,250 @@
+package service
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/report"
+)
+
+// newBackupTestService returns a test service whose state lives in a temporary data directory
+func newBackupTestService(t *testing.T) *ForwardMCPService {
+	t.Helper()
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+
+	memorySystem, err := NewMemorySystem(service.logger, "backup-test")
+	if err != nil {
+		t.Fatalf("Failed to create memory system: %v", err)
+	}
+	t.Cleanup(func() { memorySystem.Close() })
+	database, err := NewNQEDatabase(service.logger, "backup-test")
+	if err != nil {
+		t.Fatalf("Failed to create NQE database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	service.memorySystem = memorySystem
+	service.database = database
+	service.analysisCache = newAnalysisDBCache(t.TempDir())
+	service.bloomIndexManager = NewBloomIndexManager(service.logger, t.TempDir())
+	service.reports = newTestReportStore(t)
+	service.queryIndex.embeddingsCachePath = filepath.Join(t.TempDir(), "nqe-embeddings.json")
+	return service
+}
+
+func TestBackupAndRestoreState(t *testing.T) {
+	service := newBackupTestService(t)
+	before, err := service.memorySystem.CreateEntity("core-1", "device", nil)
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	bloomFile := filepath.Join(service.bloomIndexManager.baseDir, "entity_a", "block-1")
+	writeStorageFile(t, bloomFile, 64, before.CreatedAt)
+	if err := os.WriteFile(service.queryIndex.embeddingsCachePath, []byte(`{"/L3/Routes": [0.5]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := service.reports.Save(&report.Report{Title: "Weekly"}, "weekly", "md")
+	if err != nil {
+		t.Fatalf("Failed to save report: %v", err)
+	}
+
+	response, err := service.backupState(BackupStateArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	archive := strings.TrimPrefix(strings.SplitN(text, " (", 2)[0], "Backup saved to ")
+	if !strings.HasSuffix(archive, backupArchiveSuffix) || !strings.Contains(text, "| memory_db | 1 |") || !strings.Contains(text, "| embeddings | 1 |") {
+		t.Fatalf("Unexpected backup summary: %s", text)
+	}
+
+	// Change every component after the backup
+	after, _ := service.memorySystem.CreateEntity("core-2", "device", nil)
+	if err := service.memorySystem.DeleteEntity(before.ID); err != nil {
+		t.Fatalf("Failed to delete entity: %v", err)
+	}
+	os.RemoveAll(service.bloomIndexManager.baseDir)
+	os.Remove(service.queryIndex.embeddingsCachePath)
+	os.Remove(saved.Path)
+
+	if _, err := service.restoreState(RestoreStateArgs{Archive: archive}); err == nil {
+		t.Error("Expected restore without confirm to be refused")
+	}
+	response, err = service.restoreState(RestoreStateArgs{Archive: filepath.Base(archive), Confirm: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "memory_db:") || !strings.Contains(text, "reports: 1 files") {
+		t.Errorf("Unexpected restore summary: %s", text)
+	}
+	if _, err := service.memorySystem.GetEntity(before.ID); err != nil {
+		t.Errorf("Expected the backed up entity to be restored: %v", err)
+	}
+	if _, err := service.memorySystem.GetEntity(after.ID); err == nil {
+		t.Error("Expected the entity created after the backup to be gone")
+	}
+	for _, path := range []string{bloomFile, service.queryIndex.embeddingsCachePath, saved.Path} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to be restored", path)
+		}
+	}
+
+	// Only the selected components are restored
+	service.memorySystem.CreateEntity("core-3", "device", nil)
+	response, err = service.restoreState(RestoreStateArgs{Archive: archive, Components: []string{storageReports}, Confirm: true})
+	if err != nil || strings.Contains(response.Content[0].TextContent.Text, "memory_db") {
+		t.Errorf("Expected only reports to be restored, got %v", err)
+	}
+	if _, err := service.memorySystem.GetEntity("core-3"); err != nil {
+		t.Error("Expected the memory database to be left alone")
+	}
+	if _, err := service.backupState(BackupStateArgs{Components: []string{"everything"}}); err == nil {
+		t.Error("Expected an error for an unknown component")
+	}
+}
+
+func TestBackupPathsStayInBackupDirectory(t *testing.T) {
+	service := newBackupTestService(t)
+	dir, _ := backupDirectory()
+
+	response, err := service.backupState(BackupStateArgs{Components: []string{storageReports}, OutputDir: "nightly"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, filepath.Join(dir, "nightly", "forward-mcp-state-")) {
+		t.Errorf("Expected the archive in a subdirectory of the backup directory: %s", text)
+	}
+	for _, outputDir := range []string{t.TempDir(), "../elsewhere"} {
+		if _, err := service.backupState(BackupStateArgs{OutputDir: outputDir}); err == nil || !strings.Contains(err.Error(), "not in the backup directory") {
+			t.Errorf("Expected output_dir %s refused, got %v", outputDir, err)
+		}
+	}
+
+	outside := filepath.Join(t.TempDir(), "outside.tar.gz")
+	writeTestArchive(t, outside, map[string]string{backupManifestName: `{"format_version": 1}`})
+	for _, archive := range []string{outside, "../" + filepath.Base(outside), "/etc/passwd"} {
+		if _, err := service.restoreState(RestoreStateArgs{Archive: archive, Confirm: true}); err == nil || !strings.Contains(err.Error(), "not in the backup directory") {
+			t.Errorf("Expected archive %s refused, got %v", archive, err)
+		}
+	}
+}
+
+func TestExtractBackupArchiveRejectsUnsafePaths(t *testing.T) {
+	archive := filepath.Join(t.TempDir(), "evil.tar.gz")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	content := []byte("pwned")
+	tw.WriteHeader(&tar.Header{Name: "../escape.txt", Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+	file.Close()
+
+	dir := t.TempDir()
+	if _, err := extractBackupArchive(archive, dir); err == nil || !strings.Contains(err.Error(), "unsafe path") {
+		t.Errorf("Expected an unsafe path error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "escape.txt")); err == nil {
+		t.Error("Expected nothing to be written outside the extraction directory")
+	}
+}
+
+// writeTestArchive writes a gzipped tar archive with the given entries
+func writeTestArchive(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	tw := tar.NewWriter(gz)
+	for name, content := range entries {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	file.Close()
+}
+
+func TestRestoreStateValidatesBeforeReplacing(t *testing.T) {
+	service := newBackupTestService(t)
+	bloomFile := filepath.Join(service.bloomIndexManager.baseDir, "entity_a", "block-1")
+	if err := os.MkdirAll(filepath.Dir(bloomFile), 0700); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(bloomFile, []byte("live"), 0600)
+
+	dir, _ := backupDirectory()
+	os.MkdirAll(dir, 0700)
+	archive := filepath.Join(dir, "corrupt.tar.gz")
+	writeTestArchive(t, archive, map[string]string{
+		backupManifestName:                         `{"format_version": 1}`,
+		storageBloomIndexes + "/entity_b/blk":      "restored",
+		storageMemoryDB + "/" + backupMemoryDBFile: "not a database",
+	})
+	_, err := service.restoreState(RestoreStateArgs{Archive: archive, Confirm: true})
+	if err == nil || !strings.Contains(err.Error(), "nothing was restored") {
+		t.Fatalf("Expected the corrupt component to be refused, got %v", err)
+	}
+	if data, err := os.ReadFile(bloomFile); err != nil || string(data) != "live" {
+		t.Errorf("Expected the live bloom indexes untouched, got %q, %v", data, err)
+	}
+	dataDir, _ := getWritableDataDirectory()
+	if leftovers, _ := filepath.Glob(filepath.Join(dataDir, ".restore-*")); len(leftovers) > 0 {
+		t.Errorf("Expected the staging directory removed, found %v", leftovers)
+	}
+}
+
+func TestRestoreSwapRollback(t *testing.T) {
+	dir := t.TempDir()
+	liveDir := filepath.Join(dir, "indexes")
+	os.MkdirAll(liveDir, 0700)
+	os.WriteFile(filepath.Join(liveDir, "old"), []byte("old"), 0600)
+	liveFile := filepath.Join(dir, "embeddings.json")
+	os.WriteFile(liveFile, []byte("old"), 0600)
+
+	staged := filepath.Join(dir, "staged")
+	os.MkdirAll(filepath.Join(staged, "indexes"), 0700)
+	os.WriteFile(filepath.Join(staged, "indexes", "new"), []byte("new"), 0600)
+	os.WriteFile(filepath.Join(staged, "embeddings.json"), []byte("new"), 0600)
+
+	swap := &restoreSwap{}
+	if err := swap.replace(filepath.Join(staged, "indexes"), liveDir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := swap.replace(filepath.Join(staged, "embeddings.json"), liveFile); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(liveDir, "new")); err != nil {
+		t.Errorf("Expected the staged directory in place: %v", err)
+	}
+	if err := swap.replace(filepath.Join(staged, "missing"), filepath.Join(dir, "reports")); err == nil {
+		t.Fatal("Expected an error for a missing source")
+	}
+
+	if err := swap.rollback(); err != nil {
+		t.Fatalf("Expected a clean rollback, got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(liveDir, "old")); string(data) != "old" {
+		t.Error("Expected the previous directory restored")
+	}
+	if _, err := os.Stat(filepath.Join(liveDir, "new")); err == nil {
+		t.Error("Expected the restored directory removed on rollback")
+	}
+	if data, _ := os.ReadFile(liveFile); string(data) != "old" {
+		t.Error("Expected the previous file restored")
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "*.restore-old-*")); len(leftovers) > 0 {
+		t.Errorf("Expected nothing left aside, found %v", leftovers)
+	}
+}
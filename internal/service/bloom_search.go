@@ -275,6 +275,13 @@ func (bim *BloomIndexManager) loadBlockData(entityID, blockID string) (*BlockDat
 	return blockData, nil
 }
 
+// RemoveEngine drops the engine of an entity whose index directory was deleted
+func (bim *BloomIndexManager) RemoveEngine(entityID string) {
+	bim.mutex.Lock()
+	defer bim.mutex.Unlock()
+	delete(bim.engines, entityID)
+}
+
 // Close closes all bloom search engines
 func (bim *BloomIndexManager) Close() error {
 	bim.mutex.Lock()
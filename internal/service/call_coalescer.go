@@ -0,0 +1,141 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// errCoalescedCallIncomplete is returned to waiters when the shared call panicked
+var errCoalescedCallIncomplete = errors.New("coalesced call did not complete")
+
+// coalescedCall is a tool call in flight whose result is shared with identical calls
+type coalescedCall struct {
+	done     chan struct{}
+	response interface{}
+	err      error
+}
+
+// CallCoalescer shares one execution among identical tool calls that are in flight at the
+// same time, so parallel duplicates from an agent cost a single API round trip. Results
+// are not kept once the call finishes; caching stays with the dedicated caches.
+type CallCoalescer struct {
+	mutex     sync.Mutex
+	calls     map[string]*coalescedCall
+	executed  int64
+	coalesced int64
+	byTool    map[string]int64 // Coalesced calls per tool
+}
+
+// NewCallCoalescer creates an empty call coalescer
+func NewCallCoalescer() *CallCoalescer {
+	return &CallCoalescer{
+		calls:  make(map[string]*coalescedCall),
+		byTool: make(map[string]int64),
+	}
+}
+
+// coalescingKey identifies a tool call by tool name and normalized arguments. Arguments are
+// compared by their JSON encoding, so field order and omitted defaults do not matter.
+func coalescingKey(toolName string, args interface{}) (string, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return "", err
+	}
+	// Calls of different API keys never share a result, since results can be scoped to the key
+	sum := sha256.Sum256(append([]byte(toolName+"\x00"+toolCallerID(args)+"\x00"), data...))
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Do runs fn unless an identical call is already in flight, in which case it waits for that
+// call and returns its result. shared reports whether the result came from another call.
+func (c *CallCoalescer) Do(toolName, key string, fn func() (interface{}, error)) (response interface{}, shared bool, err error) {
+	c.mutex.Lock()
+	if call, exists := c.calls[key]; exists {
+		c.coalesced++
+		c.byTool[toolName]++
+		c.mutex.Unlock()
+		<-call.done
+		return call.response, true, call.err
+	}
+	call := &coalescedCall{done: make(chan struct{}), err: errCoalescedCallIncomplete}
+	c.calls[key] = call
+	c.executed++
+	c.mutex.Unlock()
+
+	defer func() {
+		c.mutex.Lock()
+		delete(c.calls, key)
+		c.mutex.Unlock()
+		close(call.done)
+	}()
+	call.response, call.err = fn()
+	return call.response, false, call.err
+}
+
+// GetStats returns coalescing counts
+func (c *CallCoalescer) GetStats() map[string]interface{} {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	total := c.executed + c.coalesced
+	rate := 0.0
+	if total > 0 {
+		rate = float64(c.coalesced) / float64(total) * 100
+	}
+	tools := make([]string, 0, len(c.byTool))
+	for tool := range c.byTool {
+		tools = append(tools, tool)
+	}
+	sort.Slice(tools, func(i, j int) bool { return c.byTool[tools[i]] > c.byTool[tools[j]] })
+	byTool := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		byTool = append(byTool, map[string]interface{}{"tool": tool, "coalesced": c.byTool[tool]})
+	}
+
+	return map[string]interface{}{
+		"executed_calls":   c.executed,
+		"coalesced_calls":  c.coalesced,
+		"in_flight":        len(c.calls),
+		"coalesce_percent": rate,
+		"by_tool":          byTool,
+	}
+}
+
+// coalesceToolHandler returns a handler with the same signature that shares the result of
+// identical concurrent calls. Tools that change state always run.
+func (s *ForwardMCPService) coalesceToolHandler(toolName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if s.callCoalescer == nil || writeTools[toolName] || handlerType.Kind() != reflect.Func ||
+		handlerType.NumIn() != 1 || handlerType.NumOut() != 2 || handlerType.Out(0) != toolResponseType {
+		return handler
+	}
+
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		key, err := coalescingKey(toolName, args[0].Interface())
+		if err != nil {
+			return value.Call(args)
+		}
+		response, shared, err := s.callCoalescer.Do(toolName, key, func() (interface{}, error) {
+			results := value.Call(args)
+			err, _ := results[1].Interface().(error)
+			return results[0].Interface(), err
+		})
+		if shared {
+			s.logger.Debug("Coalesced duplicate %s call", toolName)
+		}
+		results := []reflect.Value{reflect.Zero(handlerType.Out(0)), reflect.Zero(handlerType.Out(1))}
+		if response != nil {
+			results[0] = reflect.ValueOf(response)
+		}
+		if err != nil {
+			results[1] = reflect.ValueOf(&err).Elem()
+		}
+		return results
+	}).Interface()
+}
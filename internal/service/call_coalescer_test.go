@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func TestCallCoalescerSharesConcurrentCalls(t *testing.T) {
+	service := createTestService()
+	service.callCoalescer = NewCallCoalescer()
+
+	var executions int32
+	release := make(chan struct{})
+	handler := service.coalesceToolHandler("list_devices", func(args ListDevicesArgs) (*mcp.ToolResponse, error) {
+		atomic.AddInt32(&executions, 1)
+		<-release
+		return mcp.NewToolResponse(mcp.NewTextContent("devices of " + args.NetworkID)), nil
+	}).(func(ListDevicesArgs) (*mcp.ToolResponse, error))
+
+	var wg sync.WaitGroup
+	responses := make([]*mcp.ToolResponse, 5)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = handler(ListDevicesArgs{NetworkID: "net-1", Limit: 10})
+		}(i)
+	}
+	// Wait until the duplicates are queued behind the first call
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if stats := service.callCoalescer.GetStats(); stats["coalesced_calls"].(int64) == 4 {
+			break
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	if executions != 1 {
+		t.Errorf("Expected one execution for 5 identical calls, got %d", executions)
+	}
+	for _, response := range responses {
+		if response == nil || response.Content[0].TextContent.Text != "devices of net-1" {
+			t.Fatalf("Expected every caller to receive the shared response, got %+v", response)
+		}
+	}
+
+	// Different arguments and later calls run on their own
+	handler(ListDevicesArgs{NetworkID: "net-2"})
+	handler(ListDevicesArgs{NetworkID: "net-1", Limit: 10})
+	if executions != 3 {
+		t.Errorf("Expected distinct and sequential calls to execute, got %d executions", executions)
+	}
+	stats := service.callCoalescer.GetStats()
+	if stats["executed_calls"].(int64) != 3 || stats["coalesced_calls"].(int64) != 4 || stats["in_flight"].(int) != 0 {
+		t.Errorf("Unexpected coalescing stats: %v", stats)
+	}
+}
+
+func TestCallCoalescerSkipsWriteTools(t *testing.T) {
+	service := createTestService()
+	service.callCoalescer = NewCallCoalescer()
+	handler := func(args DeleteEntityArgs) (*mcp.ToolResponse, error) { return nil, nil }
+	if _, ok := service.coalesceToolHandler("delete_entity", handler).(func(DeleteEntityArgs) (*mcp.ToolResponse, error)); !ok {
+		t.Fatal("Expected the handler signature to be preserved")
+	}
+	if service.callCoalescer.GetStats()["executed_calls"].(int64) != 0 {
+		t.Error("Expected no coalescing bookkeeping for write tools")
+	}
+}
@@ -0,0 +1,363 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Chart types and spec libraries supported by generate_chart_spec
+const (
+	chartBar  = "bar"
+	chartPie  = "pie"
+	chartLine = "line"
+
+	chartVegaLite = "vega-lite"
+	chartChartJS  = "chartjs"
+
+	// chartOtherLabel collects the categories beyond the category limit
+	chartOtherLabel = "(other)"
+)
+
+// chartSeries is one named value per label
+type chartSeries struct {
+	Name   string
+	Values []float64
+}
+
+// chartData is the aggregated, library-neutral input for a chart spec
+type chartData struct {
+	XField   string
+	Labels   []string
+	Series   []chartSeries
+	Temporal bool
+}
+
+// parseChartTime recognizes the timestamp and date formats found in results
+func parseChartTime(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// aggregateChartRows groups rows by the x column, summing each y column per group. Without
+// y columns the number of rows per group is charted.
+func aggregateChartRows(rows []map[string]interface{}, xColumn string, yColumns []string) (*chartData, error) {
+	data := &chartData{XField: xColumn}
+	names := yColumns
+	if len(names) == 0 {
+		names = []string{"count"}
+	}
+
+	index := make(map[string]int)
+	sums := make([][]float64, len(names))
+	numeric := make([]bool, len(names))
+	for _, row := range rows {
+		value, ok := row[xColumn]
+		if !ok {
+			continue
+		}
+		label := resultValueString(value)
+		if label == "" {
+			label = "(empty)"
+		}
+		i, seen := index[label]
+		if !seen {
+			i = len(data.Labels)
+			index[label] = i
+			data.Labels = append(data.Labels, label)
+			for s := range sums {
+				sums[s] = append(sums[s], 0)
+			}
+		}
+		if len(yColumns) == 0 {
+			sums[0][i]++
+			numeric[0] = true
+			continue
+		}
+		for s, column := range yColumns {
+			if number, ok := timeSeriesNumber(row[column]); ok {
+				sums[s][i] += number
+				numeric[s] = true
+			}
+		}
+	}
+	if len(data.Labels) == 0 {
+		return nil, fmt.Errorf("column %q not found in the result", xColumn)
+	}
+	for s, name := range names {
+		if !numeric[s] {
+			return nil, fmt.Errorf("column %q has no numeric values", name)
+		}
+		data.Series = append(data.Series, chartSeries{Name: name, Values: sums[s]})
+	}
+
+	data.Temporal = true
+	for _, label := range data.Labels {
+		if _, ok := parseChartTime(label); !ok {
+			data.Temporal = false
+			break
+		}
+	}
+	return data, nil
+}
+
+// timeSeriesChartData charts the selected metrics of a stored series over snapshot time
+func timeSeriesChartData(points []TimeSeriesPoint, metrics []string) *chartData {
+	data := &chartData{XField: "snapshot_time", Temporal: true}
+	for _, point := range points {
+		data.Labels = append(data.Labels, point.SnapshotTime.UTC().Format(time.RFC3339))
+	}
+	for _, metric := range metrics {
+		series := chartSeries{Name: metric, Values: make([]float64, len(points))}
+		for i, point := range points {
+			series.Values[i] = point.Metrics[metric]
+		}
+		data.Series = append(data.Series, series)
+	}
+	return data
+}
+
+// arrange orders the labels for the chart type: chronologically for time axes, otherwise
+// by the first series descending with categories beyond limit folded into "(other)"
+func (d *chartData) arrange(chartType string, limit int) {
+	order := make([]int, len(d.Labels))
+	for i := range order {
+		order[i] = i
+	}
+	if d.Temporal {
+		sort.SliceStable(order, func(a, b int) bool {
+			ta, _ := parseChartTime(d.Labels[order[a]])
+			tb, _ := parseChartTime(d.Labels[order[b]])
+			return ta.Before(tb)
+		})
+	} else {
+		sort.SliceStable(order, func(a, b int) bool {
+			return d.Series[0].Values[order[a]] > d.Series[0].Values[order[b]]
+		})
+	}
+
+	labels := make([]string, 0, len(order))
+	values := make([][]float64, len(d.Series))
+	for position, i := range order {
+		if chartType != chartLine && limit > 0 && position >= limit {
+			if position == limit {
+				labels = append(labels, chartOtherLabel)
+				for s := range d.Series {
+					values[s] = append(values[s], 0)
+				}
+			}
+			for s := range d.Series {
+				values[s][limit] += d.Series[s].Values[i]
+			}
+			continue
+		}
+		labels = append(labels, d.Labels[i])
+		for s := range d.Series {
+			values[s] = append(values[s], d.Series[s].Values[i])
+		}
+	}
+	d.Labels = labels
+	for s := range d.Series {
+		d.Series[s].Values = values[s]
+	}
+}
+
+// vegaLiteSpec renders chart data as a Vega-Lite v5 spec with inline long-format values
+func vegaLiteSpec(data *chartData, chartType, title string) map[string]interface{} {
+	var values []map[string]interface{}
+	for _, series := range data.Series {
+		for i, label := range data.Labels {
+			values = append(values, map[string]interface{}{data.XField: label, "series": series.Name, "value": series.Values[i]})
+		}
+	}
+
+	valueTitle := data.Series[0].Name
+	encoding := map[string]interface{}{}
+	var mark interface{}
+	switch chartType {
+	case chartPie:
+		mark = map[string]interface{}{"type": "arc", "tooltip": true}
+		encoding["theta"] = map[string]interface{}{"field": "value", "type": "quantitative", "title": valueTitle}
+		encoding["color"] = map[string]interface{}{"field": data.XField, "type": "nominal", "sort": nil}
+	case chartLine:
+		mark = map[string]interface{}{"type": "line", "point": true, "tooltip": true}
+		xType := "ordinal"
+		if data.Temporal {
+			xType = "temporal"
+		}
+		encoding["x"] = map[string]interface{}{"field": data.XField, "type": xType}
+		encoding["y"] = map[string]interface{}{"field": "value", "type": "quantitative", "title": valueTitle}
+	default:
+		mark = map[string]interface{}{"type": "bar", "tooltip": true}
+		encoding["x"] = map[string]interface{}{"field": data.XField, "type": "nominal", "sort": nil}
+		encoding["y"] = map[string]interface{}{"field": "value", "type": "quantitative", "title": valueTitle}
+	}
+	if len(data.Series) > 1 && chartType != chartPie {
+		encoding["color"] = map[string]interface{}{"field": "series", "type": "nominal"}
+		encoding["y"].(map[string]interface{})["title"] = "value"
+	}
+
+	return map[string]interface{}{
+		"$schema":  "https://vega.github.io/schema/vega-lite/v5.json",
+		"title":    title,
+		"data":     map[string]interface{}{"values": values},
+		"mark":     mark,
+		"encoding": encoding,
+	}
+}
+
+// chartJSSpec renders chart data as a Chart.js configuration object
+func chartJSSpec(data *chartData, chartType, title string) map[string]interface{} {
+	var datasets []map[string]interface{}
+	for _, series := range data.Series {
+		datasets = append(datasets, map[string]interface{}{"label": series.Name, "data": series.Values})
+	}
+	options := map[string]interface{}{
+		"plugins": map[string]interface{}{"title": map[string]interface{}{"display": title != "", "text": title}},
+	}
+	if chartType != chartPie {
+		options["scales"] = map[string]interface{}{
+			"x": map[string]interface{}{"title": map[string]interface{}{"display": true, "text": data.XField}},
+			"y": map[string]interface{}{"beginAtZero": true},
+		}
+	}
+	return map[string]interface{}{
+		"type":    chartType,
+		"data":    map[string]interface{}{"labels": data.Labels, "datasets": datasets},
+		"options": options,
+	}
+}
+
+// generateChartSpec builds a Vega-Lite or Chart.js spec from a stored result, the output
+// of a SQL query over it, or a stored time series
+func (s *ForwardMCPService) generateChartSpec(args GenerateChartSpecArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("generate_chart_spec", args, nil)
+
+	library := strings.ToLower(strings.TrimSpace(args.Library))
+	switch library {
+	case "", "vegalite", chartVegaLite:
+		library = chartVegaLite
+	case chartChartJS, "chart.js":
+		library = chartChartJS
+	default:
+		return nil, fmt.Errorf("unsupported library %q (use vega-lite or chartjs)", args.Library)
+	}
+	chartType := strings.ToLower(strings.TrimSpace(args.ChartType))
+	if chartType == "timeseries" || chartType == "time-series" {
+		chartType = chartLine
+	}
+	if chartType != "" && chartType != chartBar && chartType != chartPie && chartType != chartLine {
+		return nil, fmt.Errorf("unsupported chart_type %q (use bar, pie or line)", args.ChartType)
+	}
+
+	var data *chartData
+	var source string
+	switch {
+	case strings.TrimSpace(args.SeriesName) != "":
+		if s.timeSeries == nil {
+			return nil, newCodedError(CodeTimeSeriesUnavailable)
+		}
+		networkID := s.getNetworkID(args.NetworkID)
+		if networkID == "" {
+			return nil, newCodedError(CodeNetworkIDRequired)
+		}
+		name := strings.TrimSpace(args.SeriesName)
+		points, err := s.timeSeries.Points(networkID, name, time.Time{}, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		if len(points) == 0 {
+			return nil, fmt.Errorf("time series %s has no points on network %s (run collect_timeseries first)", name, networkID)
+		}
+		metrics := timeSeriesMetricNames(points, args.Metric)
+		if len(metrics) == 0 {
+			return nil, fmt.Errorf("no metric of series %s matches %q", name, args.Metric)
+		}
+		if len(metrics) > 10 {
+			metrics = metrics[:10]
+		}
+		data = timeSeriesChartData(points, metrics)
+		source = "time series " + name
+	case args.EntityID != "":
+		if s.memorySystem == nil {
+			return nil, newCodedError(CodeMemoryUnavailable)
+		}
+		if args.XColumn == "" {
+			return nil, fmt.Errorf("x_column is required when charting a stored result")
+		}
+		var rows []map[string]interface{}
+		if args.SQLQuery != "" {
+			if _, err := validateSandboxSQL(args.SQLQuery); err != nil {
+				return nil, err
+			}
+			var err error
+			if rows, _, _, err = s.queryStoredResult(args.EntityID, args.SQLQuery, 1000); err != nil {
+				return nil, err
+			}
+			source = "SQL output over " + args.EntityID
+		} else {
+			entity, allRows, err := s.loadStoredResultRows(args.EntityID)
+			if err != nil {
+				return nil, err
+			}
+			rows = allRows
+			source = "stored result " + entity.ID
+		}
+		var err error
+		if data, err = aggregateChartRows(rows, args.XColumn, args.YColumns); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("either entity_id or series_name is required")
+	}
+
+	if chartType == "" {
+		chartType = chartBar
+		if data.Temporal {
+			chartType = chartLine
+		}
+	}
+	// A pie shows the shares of a single measure
+	if chartType == chartPie {
+		data.Series = data.Series[:1]
+	}
+	limit := args.MaxCategories
+	if limit <= 0 {
+		limit = 20
+	}
+	data.arrange(chartType, limit)
+
+	title := args.Title
+	if title == "" {
+		title = fmt.Sprintf("%s by %s", strings.Join(seriesNames(data.Series), ", "), data.XField)
+	}
+	var spec map[string]interface{}
+	if library == chartChartJS {
+		spec = chartJSSpec(data, chartType, title)
+	} else {
+		spec = vegaLiteSpec(data, chartType, title)
+	}
+	specJSON, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode chart spec: %w", err)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("%s %s chart of %d %s from %s:\n%s",
+		library, chartType, len(data.Labels), data.XField, source, string(specJSON)))), nil
+}
+
+// seriesNames lists the series names of a chart for titles
+func seriesNames(series []chartSeries) []string {
+	names := make([]string, len(series))
+	for i, s := range series {
+		names[i] = s.Name
+	}
+	return names
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// chartSpecJSON decodes the spec that follows the summary line of a chart response
+func chartSpecJSON(t *testing.T, text string) map[string]interface{} {
+	t.Helper()
+	var spec map[string]interface{}
+	if err := json.Unmarshal([]byte(text[strings.Index(text, "\n")+1:]), &spec); err != nil {
+		t.Fatalf("Expected a JSON spec, got %v: %s", err, text)
+	}
+	return spec
+}
+
+func TestAggregateChartRows(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"vendor": "cisco", "ports": float64(48)},
+		{"vendor": "arista", "ports": "32"},
+		{"vendor": "cisco", "ports": float64(24)},
+		{"vendor": "juniper", "ports": nil},
+		{"vendor": "cisco"},
+	}
+	data, err := aggregateChartRows(rows, "vendor", nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data.arrange(chartBar, 2)
+	if !reflect.DeepEqual(data.Labels, []string{"cisco", "arista", chartOtherLabel}) || !reflect.DeepEqual(data.Series[0].Values, []float64{3, 1, 1}) {
+		t.Errorf("Unexpected counts: %v %v", data.Labels, data.Series[0].Values)
+	}
+
+	data, err = aggregateChartRows(rows, "vendor", []string{"ports"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !reflect.DeepEqual(data.Series[0].Values, []float64{72, 32, 0}) || data.Temporal {
+		t.Errorf("Unexpected sums: %+v", data)
+	}
+
+	if _, err := aggregateChartRows(rows, "site", nil); err == nil {
+		t.Error("Expected an error for a missing x column")
+	}
+	if _, err := aggregateChartRows(rows, "vendor", []string{"vendor"}); err == nil {
+		t.Error("Expected an error for a non-numeric y column")
+	}
+
+	dated := []map[string]interface{}{{"day": "2026-03-02"}, {"day": "2026-03-01"}, {"day": "2026-03-02"}}
+	data, _ = aggregateChartRows(dated, "day", nil)
+	data.arrange(chartLine, 20)
+	if !data.Temporal || !reflect.DeepEqual(data.Labels, []string{"2026-03-01", "2026-03-02"}) {
+		t.Errorf("Expected chronological dates, got %+v", data)
+	}
+}
+
+func TestGenerateChartSpec(t *testing.T) {
+	service := createTestService()
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_chart_test", "162112", "snap-chart", &forward.NQERunResult{
+		Items: []map[string]interface{}{
+			{"name": "core-1", "vendor": "cisco"},
+			{"name": "edge-1", "vendor": "cisco"},
+			{"name": "leaf-1", "vendor": "arista"},
+		},
+	}, 2)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+
+	response, err := service.generateChartSpec(GenerateChartSpecArgs{EntityID: entityID, XColumn: "vendor", ChartType: "pie"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	spec := chartSpecJSON(t, response.Content[0].TextContent.Text)
+	if spec["$schema"] != "https://vega.github.io/schema/vega-lite/v5.json" || spec["mark"].(map[string]interface{})["type"] != "arc" {
+		t.Errorf("Expected a Vega-Lite pie chart, got %v", spec)
+	}
+	values := spec["data"].(map[string]interface{})["values"].([]interface{})
+	if first := values[0].(map[string]interface{}); len(values) != 2 || first["vendor"] != "cisco" || first["value"] != float64(2) {
+		t.Errorf("Unexpected data values: %v", values)
+	}
+
+	// SQL output is charted as-is and rendered for Chart.js
+	response, err = service.generateChartSpec(GenerateChartSpecArgs{EntityID: entityID, Library: "chartjs",
+		SQLQuery: "SELECT vendor, COUNT(*) AS devices FROM nqe_result GROUP BY vendor", XColumn: "vendor", YColumns: []string{"devices"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	spec = chartSpecJSON(t, response.Content[0].TextContent.Text)
+	data := spec["data"].(map[string]interface{})
+	if spec["type"] != chartBar || !reflect.DeepEqual(data["labels"], []interface{}{"cisco", "arista"}) {
+		t.Errorf("Expected a Chart.js bar chart, got %v", spec)
+	}
+
+	if _, err := service.generateChartSpec(GenerateChartSpecArgs{EntityID: entityID}); err == nil {
+		t.Error("Expected an error without x_column")
+	}
+	if _, err := service.generateChartSpec(GenerateChartSpecArgs{EntityID: entityID, XColumn: "vendor", Library: "d3"}); err == nil {
+		t.Error("Expected an error for an unsupported library")
+	}
+}
+
+func TestGenerateChartSpecTimeSeries(t *testing.T) {
+	service := createTestService()
+	service.timeSeries = newTestTimeSeriesStore(t)
+	if err := service.timeSeries.Define(&TimeSeriesDefinition{NetworkID: "162112", Name: "devices", QueryID: "FQ_devices"}); err != nil {
+		t.Fatalf("Failed to define series: %v", err)
+	}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, count := range []float64{10, 12} {
+		point := TimeSeriesPoint{SnapshotID: fmt.Sprintf("s%d", i+1), SnapshotTime: base.AddDate(0, 0, i),
+			Metrics: map[string]float64{"row_count": count, "count(vendor=cisco)": count - 2}}
+		if err := service.timeSeries.AddPoint("162112", "devices", point); err != nil {
+			t.Fatalf("Failed to add point: %v", err)
+		}
+	}
+
+	response, err := service.generateChartSpec(GenerateChartSpecArgs{SeriesName: "devices", NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	spec := chartSpecJSON(t, response.Content[0].TextContent.Text)
+	encoding := spec["encoding"].(map[string]interface{})
+	if spec["mark"].(map[string]interface{})["type"] != chartLine || encoding["x"].(map[string]interface{})["type"] != "temporal" || encoding["color"] == nil {
+		t.Errorf("Expected a multi-series temporal line chart, got %v", spec)
+	}
+	if values := spec["data"].(map[string]interface{})["values"].([]interface{}); len(values) != 4 {
+		t.Errorf("Expected two points for two metrics, got %v", values)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// maxDiagnosticProbes bounds the probe requests one client_diagnostics call may issue
+const maxDiagnosticProbes = 10
+
+// clientDiagnostics reports how the Forward API client is configured and how its connection
+// pool is behaving, optionally issuing lightweight probe requests to measure latency and reuse
+func (s *ForwardMCPService) clientDiagnostics(args ClientDiagnosticsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("client_diagnostics", args, nil)
+
+	probes := args.ProbeRequests
+	if probes < 0 {
+		probes = 0
+	}
+	if probes > maxDiagnosticProbes {
+		probes = maxDiagnosticProbes
+	}
+
+	var report strings.Builder
+	report.WriteString("Forward API Client Diagnostics\n\n")
+
+	if s.config != nil {
+		cfg := s.config.Forward
+		host := cfg.APIBaseURL
+		if parsed, err := url.Parse(cfg.APIBaseURL); err == nil && parsed.Host != "" {
+			host = parsed.Host
+		}
+		report.WriteString("Configuration:\n")
+		report.WriteString(fmt.Sprintf("• API Host: %s\n", host))
+		report.WriteString(fmt.Sprintf("• Request Timeout: %ds\n", cfg.Timeout))
+		report.WriteString(fmt.Sprintf("• HTTP/2: %s\n", enabledLabel(cfg.EnableHTTP2)))
+		report.WriteString(fmt.Sprintf("• Gzip Responses: %s, Compressed Requests: %s\n",
+			enabledLabel(cfg.GzipResponses), enabledLabel(cfg.CompressRequests)))
+		report.WriteString(fmt.Sprintf("• TLS Verification: %s\n", enabledLabel(!cfg.InsecureSkipVerify)))
+		report.WriteString("\n")
+	}
+
+	connections, hasConnections := s.forwardClient.(forward.ConnectionStatsReporter)
+	var before forward.ConnectionStats
+	if hasConnections {
+		before = connections.ConnectionStats()
+	}
+
+	if probes > 0 {
+		report.WriteString(s.runClientProbes(probes))
+		if hasConnections {
+			after := connections.ConnectionStats()
+			report.WriteString(fmt.Sprintf("• Connections During Probe: %d new, %d reused\n",
+				after.NewConnections-before.NewConnections, after.ReusedConnections-before.ReusedConnections))
+		}
+		report.WriteString("\n")
+	}
+
+	if hasConnections {
+		stats := connections.ConnectionStats()
+		report.WriteString("Connection Pool:\n")
+		report.WriteString(fmt.Sprintf("• Max Idle Connections: %d (%d per host), idle timeout %s\n",
+			stats.MaxIdleConns, stats.MaxIdleConnsPerHost, stats.IdleConnTimeout))
+		report.WriteString(fmt.Sprintf("• Requests: %d\n", stats.Requests))
+		report.WriteString(fmt.Sprintf("• Connections: %d new, %d reused (%.1f%% reuse)\n",
+			stats.NewConnections, stats.ReusedConnections, stats.ReuseRatePercent()))
+		report.WriteString(fmt.Sprintf("• TLS Handshakes: %d, average connect time %s\n",
+			stats.TLSHandshakes, stats.AverageConnectTime().Round(time.Microsecond)))
+		report.WriteString(fmt.Sprintf("• Protocols: %d HTTP/2, %d HTTP/1.x responses\n", stats.HTTP2Responses, stats.HTTP1Responses))
+		if stats.Requests > 1 && stats.ReuseRatePercent() < 50 {
+			report.WriteString("• Note: most requests opened a new connection; check proxies that close keep-alive connections or raise FORWARD_MAX_IDLE_CONNS_PER_HOST for concurrent workloads\n")
+		}
+		report.WriteString("\n")
+	} else {
+		report.WriteString("Connection Pool: not available for this client\n\n")
+	}
+
+	if reporter, ok := s.forwardClient.(forward.TransferStatsReporter); ok {
+		transfer := reporter.TransferStats()
+		report.WriteString("Transfer:\n")
+		report.WriteString(fmt.Sprintf("• Responses: %s received as %s on the wire (%.1f%% saved)\n",
+			formatBytes(transfer.ResponseBytes), formatBytes(transfer.ResponseWireBytes), transfer.ResponseSavingsPercent()))
+		report.WriteString(fmt.Sprintf("• Request Bodies: %s sent as %s\n", formatBytes(transfer.RequestBytes), formatBytes(transfer.RequestWireBytes)))
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
+
+// runClientProbes issues lightweight list-networks requests and summarizes their latency
+func (s *ForwardMCPService) runClientProbes(count int) string {
+	var latencies []time.Duration
+	failures := 0
+	var lastErr error
+	for i := 0; i < count; i++ {
+		start := time.Now()
+		if _, err := s.forwardClient.GetNetworks(); err != nil {
+			failures++
+			lastErr = err
+			continue
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("Probe (%d list-networks requests):\n", count))
+	if len(latencies) > 0 {
+		minLatency, maxLatency, total := latencies[0], latencies[0], time.Duration(0)
+		for _, latency := range latencies {
+			total += latency
+			if latency < minLatency {
+				minLatency = latency
+			}
+			if latency > maxLatency {
+				maxLatency = latency
+			}
+		}
+		summary.WriteString(fmt.Sprintf("• Latency: first %s, min %s, avg %s, max %s\n",
+			latencies[0].Round(time.Microsecond), minLatency.Round(time.Microsecond),
+			(total / time.Duration(len(latencies))).Round(time.Microsecond), maxLatency.Round(time.Microsecond)))
+	}
+	if failures > 0 {
+		summary.WriteString(fmt.Sprintf("• Failures: %d (last error: %v)\n", failures, lastErr))
+	}
+	return summary.String()
+}
+
+func enabledLabel(enabled bool) string {
+	if enabled {
+		return "enabled"
+	}
+	return "disabled"
+}
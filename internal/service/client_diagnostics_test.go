@@ -0,0 +1,59 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestClientDiagnosticsWithoutConnectionStats(t *testing.T) {
+	service := createTestService()
+
+	response, err := service.clientDiagnostics(ClientDiagnosticsArgs{ProbeRequests: 3})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Probe (3 list-networks requests)") {
+		t.Errorf("Expected probe summary, got: %s", text)
+	}
+	if !strings.Contains(text, "Connection Pool: not available") {
+		t.Errorf("Expected mock client to report no connection stats, got: %s", text)
+	}
+}
+
+func TestClientDiagnosticsReportsConnectionReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":"1","name":"net"}]`))
+	}))
+	defer server.Close()
+
+	service := createTestService()
+	service.config.Forward.APIBaseURL = server.URL
+	service.config.Forward.EnableHTTP2 = true
+	service.forwardClient = forward.NewClient(&service.config.Forward)
+
+	response, err := service.clientDiagnostics(ClientDiagnosticsArgs{ProbeRequests: 50})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+
+	expected := []string{
+		"API Host: " + strings.TrimPrefix(server.URL, "http://"),
+		"HTTP/2: enabled",
+		"Probe (10 list-networks requests)",
+		"Connections During Probe: 1 new, 9 reused",
+		"(90.0% reuse)",
+		"10 HTTP/1.x responses",
+	}
+	for _, want := range expected {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected diagnostics to contain %q, got: %s", want, text)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// configDiffQueryID is the NQE library Config Diff query
+const configDiffQueryID = "FQ_51f090cbea069b4049eb283716ab3bbb3f578aea"
+
+const (
+	// defaultConfigDiffDevices caps the per-device summary table
+	defaultConfigDiffDevices = 100
+	// configDiffChunkLines is the number of diff lines per stored chunk and per drill-down page
+	configDiffChunkLines = 500
+)
+
+// Normalized field names carrying config diff data in NQE rows
+var (
+	configDiffDeviceKeys  = []string{"device", "devicename", "name", "hostname"}
+	configDiffTextKeys    = []string{"diff", "configdiff", "unifieddiff", "difftext", "changes", "lines"}
+	configDiffAddedKeys   = []string{"added", "addedlines", "linesadded", "additions"}
+	configDiffRemovedKeys = []string{"removed", "removedlines", "linesremoved", "deleted", "deletions"}
+	configDiffTypeKeys    = []string{"changetype", "change", "difftype", "status"}
+	configDiffLineKeys    = []string{"line", "content", "text", "configline"}
+)
+
+// DeviceConfigDiff is the configuration change of one device between two snapshots
+type DeviceConfigDiff struct {
+	Device  string   `json:"device"`
+	Added   int      `json:"lines_added"`
+	Removed int      `json:"lines_removed"`
+	Lines   []string `json:"lines,omitempty"`
+}
+
+// configDiffChunk is a stored slice of a device's diff lines
+type configDiffChunk struct {
+	Device      string   `json:"device"`
+	Added       int      `json:"lines_added"`
+	Removed     int      `json:"lines_removed"`
+	ChunkIndex  int      `json:"chunk_index"`
+	TotalChunks int      `json:"total_chunks"`
+	Lines       []string `json:"lines"`
+}
+
+// getConfigDiff summarizes configuration changes per device, or returns one device's diff
+// when a device is given. Full diffs are stored chunked in the memory system for drill-down.
+func (s *ForwardMCPService) getConfigDiff(args GetConfigDiffArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_config_diff", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	if err := validateReportFormat(args.ReportFormat); err != nil {
+		return nil, err
+	}
+	if args.Device != "" {
+		return s.getDeviceConfigDiff(networkID, args)
+	}
+
+	diffs, err := s.computeConfigDiffs(networkID, args)
+	if err != nil {
+		return nil, err
+	}
+	diffs = filterConfigDiffs(diffs, args.DeviceFilter)
+	entityID := s.storeConfigDiffs(networkID, args, diffs)
+	reportNote := s.saveReport(configDiffReport(networkID, args, diffs), fmt.Sprintf("config_diff_%s_%s_%s", networkID, args.BeforeSnapshot, args.AfterSnapshot), args.ReportFormat)
+
+	offset, limit := 0, defaultConfigDiffDevices
+	if args.Options != nil {
+		offset = min(max(args.Options.Offset, 0), len(diffs))
+		if args.Options.Limit > 0 {
+			limit = args.Options.Limit
+		}
+	}
+	page := newPagination(len(diffs), offset, limit, min(limit, len(diffs)-offset))
+	page.EntityID = entityID
+	return paginatedResponse(formatConfigDiffSummary(networkID, args, diffs, offset, limit, entityID)+reportNote, page), nil
+}
+
+// getDeviceConfigDiff returns a page of one device's diff, reusing a stored diff when available
+func (s *ForwardMCPService) getDeviceConfigDiff(networkID string, args GetConfigDiffArgs) (*mcp.ToolResponse, error) {
+	diff := s.loadStoredDeviceConfigDiff(networkID, args)
+	if diff == nil {
+		diffs, err := s.computeConfigDiffs(networkID, args)
+		if err != nil {
+			return nil, err
+		}
+		s.storeConfigDiffs(networkID, args, diffs)
+		for i := range diffs {
+			if strings.EqualFold(diffs[i].Device, args.Device) {
+				diff = &diffs[i]
+				break
+			}
+		}
+	}
+	if diff == nil {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+			"No configuration changes for device %s between snapshots %s and %s.", args.Device, args.BeforeSnapshot, args.AfterSnapshot))), nil
+	}
+
+	offset, limit := 0, configDiffChunkLines
+	if args.Options != nil {
+		offset = min(max(args.Options.Offset, 0), len(diff.Lines))
+		if args.Options.Limit > 0 {
+			limit = args.Options.Limit
+		}
+	}
+	end := offset + limit
+	if end > len(diff.Lines) {
+		end = len(diff.Lines)
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# Config Diff: %s (%s → %s)\n\n", diff.Device, args.BeforeSnapshot, args.AfterSnapshot))
+	report.WriteString(fmt.Sprintf("Lines added: %d | Lines removed: %d\n\n", diff.Added, diff.Removed))
+	report.WriteString("```diff\n")
+	for _, line := range diff.Lines[offset:end] {
+		report.WriteString(line + "\n")
+	}
+	report.WriteString("```\n")
+	if len(diff.Lines) > 0 {
+		report.WriteString(fmt.Sprintf("\nShowing lines %d-%d of %d.", offset+1, end, len(diff.Lines)))
+		if end < len(diff.Lines) {
+			report.WriteString(fmt.Sprintf(" Use options.offset=%d to see more.", end))
+		}
+		report.WriteString("\n")
+	}
+	return paginatedResponse(report.String(), newPagination(len(diff.Lines), offset, limit, end-offset)), nil
+}
+
+// computeConfigDiffs runs the Config Diff query across all pages and groups the rows by device
+func (s *ForwardMCPService) computeConfigDiffs(networkID string, args GetConfigDiffArgs) ([]DeviceConfigDiff, error) {
+	params := map[string]interface{}{}
+	for key, value := range args.Parameters {
+		params[key] = value
+	}
+	if args.AfterSnapshot != "" {
+		params["compareSnapshotId"] = args.AfterSnapshot
+	}
+
+	items, err := s.fetchAllNQEItems(networkID, args.BeforeSnapshot, configDiffQueryID, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config diff: %w", err)
+	}
+	return buildDeviceConfigDiffs(items), nil
+}
+
+// buildDeviceConfigDiffs groups diff rows by device. Rows may carry a unified diff, lists of
+// added and removed lines, or one changed line with a change type. Devices with the most
+// changes come first.
+func buildDeviceConfigDiffs(items []map[string]interface{}) []DeviceConfigDiff {
+	byDevice := make(map[string]*DeviceConfigDiff)
+	var order []string
+
+	for _, item := range items {
+		fields := make(map[string]interface{}, len(item))
+		for key, value := range item {
+			fields[normalizeFactKey(key)] = value
+		}
+
+		device := ""
+		for _, key := range configDiffDeviceKeys {
+			if name, ok := fields[key].(string); ok && strings.TrimSpace(name) != "" {
+				device = strings.TrimSpace(name)
+				break
+			}
+		}
+		if device == "" {
+			continue
+		}
+		diff, exists := byDevice[device]
+		if !exists {
+			diff = &DeviceConfigDiff{Device: device}
+			byDevice[device] = diff
+			order = append(order, device)
+		}
+		appendConfigDiffRow(diff, fields)
+	}
+
+	diffs := make([]DeviceConfigDiff, 0, len(order))
+	for _, device := range order {
+		if diff := byDevice[device]; diff.Added > 0 || diff.Removed > 0 {
+			diffs = append(diffs, *diff)
+		}
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].Added+diffs[i].Removed > diffs[j].Added+diffs[j].Removed
+	})
+	return diffs
+}
+
+// appendConfigDiffRow adds the changed lines of one row to a device diff
+func appendConfigDiffRow(diff *DeviceConfigDiff, fields map[string]interface{}) {
+	for _, key := range configDiffTextKeys {
+		for _, line := range configDiffValueLines(fields[key]) {
+			switch {
+			case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+				continue
+			case strings.HasPrefix(line, "+"):
+				diff.Added++
+			case strings.HasPrefix(line, "-"):
+				diff.Removed++
+			}
+			diff.Lines = append(diff.Lines, line)
+		}
+	}
+
+	addLines := func(keys []string, prefix string, count *int) {
+		for _, key := range keys {
+			if number, ok := fields[key].(float64); ok {
+				*count += int(number)
+				continue
+			}
+			for _, line := range configDiffValueLines(fields[key]) {
+				diff.Lines = append(diff.Lines, prefix+line)
+				*count++
+			}
+		}
+	}
+	addLines(configDiffAddedKeys, "+ ", &diff.Added)
+	addLines(configDiffRemovedKeys, "- ", &diff.Removed)
+
+	changeType := ""
+	for _, key := range configDiffTypeKeys {
+		if value, ok := fields[key].(string); ok && value != "" {
+			changeType = strings.ToLower(value)
+			break
+		}
+	}
+	if changeType == "" {
+		return
+	}
+	for _, key := range configDiffLineKeys {
+		line, ok := fields[key].(string)
+		if !ok || line == "" {
+			continue
+		}
+		switch {
+		case strings.Contains(changeType, "add") || strings.Contains(changeType, "insert") || strings.Contains(changeType, "new"):
+			diff.Lines = append(diff.Lines, "+ "+line)
+			diff.Added++
+		case strings.Contains(changeType, "remov") || strings.Contains(changeType, "delet"):
+			diff.Lines = append(diff.Lines, "- "+line)
+			diff.Removed++
+		}
+		break
+	}
+}
+
+// configDiffValueLines splits a string or list value into non-empty lines
+func configDiffValueLines(value interface{}) []string {
+	var lines []string
+	switch v := value.(type) {
+	case string:
+		for _, line := range strings.Split(v, "\n") {
+			if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+	case []interface{}:
+		for _, element := range v {
+			if line, ok := element.(string); ok && strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+	case []string:
+		for _, line := range v {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return lines
+}
+
+// filterConfigDiffs keeps devices whose name contains the filter (case-insensitive)
+func filterConfigDiffs(diffs []DeviceConfigDiff, filter string) []DeviceConfigDiff {
+	if filter == "" {
+		return diffs
+	}
+	filter = strings.ToLower(filter)
+	var filtered []DeviceConfigDiff
+	for _, diff := range diffs {
+		if strings.Contains(strings.ToLower(diff.Device), filter) {
+			filtered = append(filtered, diff)
+		}
+	}
+	return filtered
+}
+
+// configDiffEntityName identifies the stored diff for a network and snapshot pair
+func configDiffEntityName(networkID, beforeSnapshot, afterSnapshot string) string {
+	return fmt.Sprintf("config_diff_%s_%s_%s", networkID, beforeSnapshot, afterSnapshot)
+}
+
+// storeConfigDiffs stores each device's diff as chunks of lines plus a summary observation.
+// It returns the entity ID, or an empty string without a memory system.
+func (s *ForwardMCPService) storeConfigDiffs(networkID string, args GetConfigDiffArgs, diffs []DeviceConfigDiff) string {
+	if s.memorySystem == nil {
+		return ""
+	}
+
+	added, removed := 0, 0
+	for _, diff := range diffs {
+		added += diff.Added
+		removed += diff.Removed
+	}
+	entity, err := s.memorySystem.CreateEntity(
+		configDiffEntityName(networkID, args.BeforeSnapshot, args.AfterSnapshot),
+		"config_diff",
+		map[string]interface{}{
+			"network_id":      networkID,
+			"before_snapshot": args.BeforeSnapshot,
+			"after_snapshot":  args.AfterSnapshot,
+			"devices_changed": len(diffs),
+			"lines_added":     added,
+			"lines_removed":   removed,
+		},
+	)
+	if err != nil {
+		s.logger.Warn("Failed to store config diff: %v", err)
+		return ""
+	}
+
+	summaries := make([]DeviceConfigDiff, 0, len(diffs))
+	for _, diff := range diffs {
+		summaries = append(summaries, DeviceConfigDiff{Device: diff.Device, Added: diff.Added, Removed: diff.Removed})
+
+		totalChunks := (len(diff.Lines) + configDiffChunkLines - 1) / configDiffChunkLines
+		for i := 0; i < totalChunks; i++ {
+			end := (i + 1) * configDiffChunkLines
+			if end > len(diff.Lines) {
+				end = len(diff.Lines)
+			}
+			chunk := configDiffChunk{
+				Device: diff.Device, Added: diff.Added, Removed: diff.Removed,
+				ChunkIndex: i, TotalChunks: totalChunks, Lines: diff.Lines[i*configDiffChunkLines : end],
+			}
+			if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(chunk), "config_diff_chunk",
+				map[string]interface{}{"device": diff.Device, "chunk_index": i, "total_chunks": totalChunks}); err != nil {
+				s.logger.Debug("Failed to store config diff chunk for %s: %v", diff.Device, err)
+			}
+		}
+	}
+	if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(summaries), "config_diff_summary", nil); err != nil {
+		s.logger.Debug("Failed to store config diff summary: %v", err)
+	}
+	return entity.ID
+}
+
+// loadStoredDeviceConfigDiff reassembles a device's diff from stored chunks, or returns nil
+func (s *ForwardMCPService) loadStoredDeviceConfigDiff(networkID string, args GetConfigDiffArgs) *DeviceConfigDiff {
+	if s.memorySystem == nil {
+		return nil
+	}
+	entity, err := s.memorySystem.GetEntity(configDiffEntityName(networkID, args.BeforeSnapshot, args.AfterSnapshot))
+	if err != nil {
+		return nil
+	}
+	observations, err := s.memorySystem.GetObservations(entity.ID, "config_diff_chunk")
+	if err != nil {
+		return nil
+	}
+
+	var chunks []configDiffChunk
+	for _, observation := range observations {
+		var chunk configDiffChunk
+		if err := json.Unmarshal([]byte(observation.Content), &chunk); err != nil || !strings.EqualFold(chunk.Device, args.Device) {
+			continue
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) == 0 || len(chunks) != chunks[0].TotalChunks {
+		return nil
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].ChunkIndex < chunks[j].ChunkIndex })
+
+	diff := &DeviceConfigDiff{Device: chunks[0].Device, Added: chunks[0].Added, Removed: chunks[0].Removed}
+	for _, chunk := range chunks {
+		diff.Lines = append(diff.Lines, chunk.Lines...)
+	}
+	return diff
+}
+
+// formatConfigDiffSummary renders the per-device summary table
+func formatConfigDiffSummary(networkID string, args GetConfigDiffArgs, diffs []DeviceConfigDiff, offset, limit int, entityID string) string {
+	added, removed := 0, 0
+	for _, diff := range diffs {
+		added += diff.Added
+		removed += diff.Removed
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# Config Diff Summary (%s → %s)\n\n", args.BeforeSnapshot, args.AfterSnapshot))
+	report.WriteString(fmt.Sprintf("Network: %s | Devices changed: %d | Lines added: %d | Lines removed: %d\n", networkID, len(diffs), added, removed))
+	if len(diffs) == 0 {
+		report.WriteString("\nNo configuration changes found.\n")
+		return report.String()
+	}
+
+	if offset > len(diffs) {
+		offset = len(diffs)
+	}
+	end := offset + limit
+	if end > len(diffs) {
+		end = len(diffs)
+	}
+	report.WriteString("\n| Device | Lines Added | Lines Removed |\n|---|---|---|\n")
+	for _, diff := range diffs[offset:end] {
+		report.WriteString(fmt.Sprintf("| %s | +%d | -%d |\n", diff.Device, diff.Added, diff.Removed))
+	}
+	if end < len(diffs) {
+		report.WriteString(fmt.Sprintf("\nShowing devices %d-%d of %d. Use options.offset=%d to see more.\n", offset+1, end, len(diffs), end))
+	}
+
+	report.WriteString("\nUse get_config_diff with device=<name> to view a device's full diff.\n")
+	if entityID != "" {
+		report.WriteString(fmt.Sprintf("Full diff stored in memory (entity: %s).\n", entityID))
+	}
+	return report.String()
+}
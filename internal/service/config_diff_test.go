@@ -0,0 +1,79 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestBuildDeviceConfigDiffs(t *testing.T) {
+	items := []map[string]interface{}{
+		// Unified diff text
+		{"device": "core-1", "diff": "--- before\n+++ after\n interface Gi0/1\n- description old\n+ description new\n+ shutdown"},
+		// Added/removed lists
+		{"Device Name": "edge-1", "added": []interface{}{"ip route 0.0.0.0 0.0.0.0 10.0.0.1"}, "removed": []interface{}{}},
+		// One changed line per row
+		{"deviceName": "edge-1", "changeType": "DELETED", "line": "logging host 10.1.1.1"},
+		{"device": "leaf-1", "changeType": "UNCHANGED", "line": "hostname leaf-1"},
+	}
+
+	diffs := buildDeviceConfigDiffs(items)
+	if len(diffs) != 2 {
+		t.Fatalf("Expected 2 changed devices, got %+v", diffs)
+	}
+	if diffs[0].Device != "core-1" || diffs[0].Added != 2 || diffs[0].Removed != 1 || len(diffs[0].Lines) != 4 {
+		t.Errorf("Unexpected core-1 diff: %+v", diffs[0])
+	}
+	if diffs[1].Device != "edge-1" || diffs[1].Added != 1 || diffs[1].Removed != 1 {
+		t.Errorf("Unexpected edge-1 diff: %+v", diffs[1])
+	}
+	if filtered := filterConfigDiffs(diffs, "EDGE"); len(filtered) != 1 || filtered[0].Device != "edge-1" {
+		t.Errorf("Expected filter to keep edge-1, got %+v", filtered)
+	}
+}
+
+func TestGetConfigDiffSummaryAndDrillDown(t *testing.T) {
+	service := createTestService()
+	var lines []string
+	for i := 0; i < 30; i++ {
+		lines = append(lines, "+ vlan 1"+strings.Repeat("0", i%3))
+	}
+	service.forwardClient.(*MockForwardClient).nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"device": "core-1", "diff": strings.Join(lines, "\n")},
+		{"device": "edge-1", "diff": "- ntp server 10.0.0.1"},
+	}}
+	args := GetConfigDiffArgs{NetworkID: "162112", BeforeSnapshot: "snap-1", AfterSnapshot: "snap-2"}
+
+	response, err := service.getConfigDiff(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Devices changed: 2 | Lines added: 30 | Lines removed: 1") || !strings.Contains(text, "| core-1 | +30 | -0 |") {
+		t.Errorf("Unexpected summary: %s", text)
+	}
+	if page := responsePagination(t, response); page.Total != 2 || page.Returned != 2 || page.HasMore {
+		t.Errorf("Unexpected summary pagination: %+v", page)
+	}
+
+	args.Device = "CORE-1"
+	args.Options = &NQEQueryOptions{Limit: 10, Offset: 10}
+	response, err = service.getConfigDiff(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Showing lines 11-20 of 30. Use options.offset=20") {
+		t.Errorf("Unexpected drill-down: %s", text)
+	}
+	if page := responsePagination(t, response); page.Total != 30 || page.Returned != 10 || !page.HasMore || *page.NextOffset != 20 {
+		t.Errorf("Unexpected drill-down pagination: %+v", page)
+	}
+
+	args.Device = "missing-1"
+	response, _ = service.getConfigDiff(args)
+	if !strings.Contains(response.Content[0].TextContent.Text, "No configuration changes for device missing-1") {
+		t.Errorf("Expected no-change message, got: %s", response.Content[0].TextContent.Text)
+	}
+}
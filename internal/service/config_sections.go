@@ -0,0 +1,488 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Configuration section types supported by get_config_section
+const (
+	configSectionInterfaces   = "interfaces"
+	configSectionBGP          = "bgp"
+	configSectionOSPF         = "ospf"
+	configSectionACL          = "acl"
+	configSectionStaticRoutes = "static_routes"
+	configSectionVLANs        = "vlans"
+)
+
+// Configuration grammars
+const (
+	configGrammarIOS   = "ios"   // Indentation based (Cisco IOS/NX-OS/IOS-XR, Arista EOS)
+	configGrammarJunos = "junos" // Brace or "set" based (Juniper Junos)
+)
+
+var configSectionTypes = []string{
+	configSectionInterfaces,
+	configSectionBGP,
+	configSectionOSPF,
+	configSectionACL,
+	configSectionStaticRoutes,
+	configSectionVLANs,
+}
+
+// ConfigSection is a single block of device configuration
+type ConfigSection struct {
+	Type  string   `json:"type"`
+	Name  string   `json:"name"`
+	Lines []string `json:"lines"`
+}
+
+// iosSectionPatterns map top-level IOS-style statements to a section type. The first
+// capture group, when present, names the section instance.
+var iosSectionPatterns = []struct {
+	sectionType string
+	pattern     *regexp.Regexp
+}{
+	{configSectionInterfaces, regexp.MustCompile(`^interface\s+(\S+)`)},
+	{configSectionBGP, regexp.MustCompile(`^router\s+bgp\s+(\S+)`)},
+	{configSectionOSPF, regexp.MustCompile(`^(?:router\s+ospf(?:v3)?|ipv6\s+router\s+ospf)\s+(\S+)`)},
+	{configSectionACL, regexp.MustCompile(`^(?:ip|ipv6|mac)\s+access-list\s+(?:(?:standard|extended|resequence)\s+)?(\S+)`)},
+	{configSectionACL, regexp.MustCompile(`^access-list\s+(\S+)`)},
+	{configSectionStaticRoutes, regexp.MustCompile(`^(?:ip|ipv6)\s+route\s+(?:vrf\s+(\S+))?`)},
+	{configSectionVLANs, regexp.MustCompile(`^vlan\s+(\S+)`)},
+}
+
+// junosSectionPaths map Junos hierarchy paths to a section type. A "*" matches any
+// single element; the element after the path names the section instance.
+var junosSectionPaths = []struct {
+	sectionType string
+	path        []string
+}{
+	{configSectionInterfaces, []string{"interfaces"}},
+	{configSectionBGP, []string{"protocols", "bgp", "group"}},
+	{configSectionOSPF, []string{"protocols", "ospf", "area"}},
+	{configSectionOSPF, []string{"protocols", "ospf3", "area"}},
+	{configSectionACL, []string{"firewall", "family", "*", "filter"}},
+	{configSectionACL, []string{"firewall", "filter"}},
+	{configSectionStaticRoutes, []string{"routing-options", "static", "route"}},
+	{configSectionVLANs, []string{"vlans"}},
+}
+
+// getConfigSection returns only the requested section of a device configuration so
+// agents do not have to pull a full config to look at a single interface or ACL
+func (s *ForwardMCPService) getConfigSection(args GetConfigSectionArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_config_section", args, nil)
+
+	sectionType := normalizeConfigSectionType(args.Section)
+	if sectionType == "" {
+		return nil, fmt.Errorf("unsupported section '%s' (supported: %s)", args.Section, strings.Join(configSectionTypes, ", "))
+	}
+	if args.Device == "" {
+		return nil, fmt.Errorf("device is required")
+	}
+
+	configText := args.ConfigText
+	source := "provided configuration"
+	if configText == "" {
+		networkID := s.getNetworkID(args.NetworkID)
+		if networkID == "" {
+			return nil, newCodedError(CodeNetworkIDRequired)
+		}
+		var err error
+		configText, err = s.fetchDeviceConfig(networkID, s.getSnapshotID(args.SnapshotID), args.Device)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch configuration for %s: %w", args.Device, err)
+		}
+		source = "network " + networkID
+	}
+	if strings.TrimSpace(configText) == "" {
+		return nil, fmt.Errorf("no configuration found for device '%s'", args.Device)
+	}
+
+	grammar := detectConfigGrammar(args.Vendor, configText)
+	sections := parseConfigSections(configText, grammar)
+
+	var matches []ConfigSection
+	for _, section := range sections {
+		if section.Type != sectionType {
+			continue
+		}
+		if args.Name != "" && !strings.EqualFold(section.Name, args.Name) {
+			continue
+		}
+		matches = append(matches, section)
+	}
+
+	if len(matches) == 0 {
+		available := make(map[string]int)
+		for _, section := range sections {
+			available[section.Type]++
+		}
+		var summary []string
+		for _, t := range configSectionTypes {
+			if available[t] > 0 {
+				summary = append(summary, fmt.Sprintf("%s (%d)", t, available[t]))
+			}
+		}
+		message := fmt.Sprintf("No %s section", sectionType)
+		if args.Name != "" {
+			message += fmt.Sprintf(" named '%s'", args.Name)
+		}
+		message += fmt.Sprintf(" found in the configuration of %s (%s grammar).", args.Device, grammar)
+		if names := configSectionNames(sections, sectionType); args.Name != "" && len(names) > 0 {
+			message += fmt.Sprintf("\n\nAvailable %s: %s", sectionType, strings.Join(names, ", "))
+		}
+		if len(summary) > 0 {
+			message += "\n\nAvailable sections: " + strings.Join(summary, ", ")
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(message)), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("## %s configuration for %s\n\n", sectionType, args.Device))
+	result.WriteString(fmt.Sprintf("Source: %s | Grammar: %s | Sections: %d\n", source, grammar, len(matches)))
+	for _, section := range matches {
+		if section.Name != "" {
+			result.WriteString(fmt.Sprintf("\n### %s\n", section.Name))
+		}
+		result.WriteString("```\n")
+		result.WriteString(strings.Join(section.Lines, "\n"))
+		result.WriteString("\n```\n")
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(result.String())), nil
+}
+
+// NQE cannot recurse over config children, so the query nests a fixed number of levels and
+// is rerun deeper while any line at the last level still has children
+const (
+	configTreeDepth    = 4
+	maxConfigTreeDepth = 64
+)
+
+// configTreeQuery selects a device's config lines with their children down to depth levels.
+// Lines at the last level report whether they have children of their own.
+func configTreeQuery(device string, depth int) string {
+	var query strings.Builder
+	query.WriteString(fmt.Sprintf("foreach d in network.devices\nwhere d.name == %s\nforeach l0 in d.files.config\nselect {\n", strconv.Quote(device)))
+	for level := 0; level < depth; level++ {
+		indent := strings.Repeat("  ", level+1)
+		query.WriteString(fmt.Sprintf("%stext: l%d.text,\n", indent, level))
+		query.WriteString(fmt.Sprintf("%schildren: (foreach l%d in l%d.children select {\n", indent, level+1, level))
+	}
+	indent := strings.Repeat("  ", depth+1)
+	query.WriteString(fmt.Sprintf("%stext: l%d.text,\n%struncated: length(l%d.children) > 0\n", indent, depth, indent, depth))
+	for level := depth; level > 0; level-- {
+		query.WriteString(strings.Repeat("  ", level) + "})\n")
+	}
+	query.WriteString("}")
+	return query.String()
+}
+
+// configTreeTruncated reports whether any line of an NQE config tree has unreturned children
+func configTreeTruncated(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if truncated, _ := v["truncated"].(bool); truncated {
+			return true
+		}
+		return configTreeTruncated(v["children"])
+	case []interface{}:
+		for _, child := range v {
+			if configTreeTruncated(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fetchDeviceConfig retrieves a device's configuration through NQE and renders it back
+// into indented text, however deeply its stanzas nest
+func (s *ForwardMCPService) fetchDeviceConfig(networkID, snapshotID, device string) (string, error) {
+	for depth := configTreeDepth; ; depth *= 2 {
+		result, err := s.forwardClient.RunNQEQueryByString(&forward.NQEQueryParams{
+			NetworkID:  networkID,
+			SnapshotID: snapshotID,
+			Query:      configTreeQuery(device, depth),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		truncated := false
+		for _, item := range result.Items {
+			truncated = truncated || configTreeTruncated(map[string]interface{}(item))
+		}
+		if truncated && depth < maxConfigTreeDepth {
+			continue
+		}
+		if truncated {
+			return "", fmt.Errorf("configuration nests deeper than %d levels", maxConfigTreeDepth)
+		}
+
+		items := make([]interface{}, len(result.Items))
+		for i, item := range result.Items {
+			items[i] = map[string]interface{}(item)
+		}
+		return strings.Join(appendConfigLines(nil, items, 0), "\n"), nil
+	}
+}
+
+// configLineText returns the text of an NQE config line
+func configLineText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case map[string]interface{}:
+		text, _ := v["text"].(string)
+		return text
+	}
+	return ""
+}
+
+// appendConfigLines renders sibling NQE config lines (strings or {text, children} records).
+// A block opened with "{" is closed when the tree leaves out its closing brace, both as its
+// last child and as the next sibling, so brace grammars parse the rendered text.
+func appendConfigLines(lines []string, values []interface{}, depth int) []string {
+	indent := strings.Repeat(" ", depth)
+	isClose := func(value interface{}) bool { return strings.HasPrefix(strings.TrimSpace(configLineText(value)), "}") }
+	for i, value := range values {
+		text := configLineText(value)
+		if text != "" {
+			lines = append(lines, indent+text)
+		}
+		var children []interface{}
+		if record, ok := value.(map[string]interface{}); ok {
+			children, _ = record["children"].([]interface{})
+		}
+		lines = appendConfigLines(lines, children, depth+1)
+		if strings.HasSuffix(strings.TrimSpace(text), "{") &&
+			(len(children) == 0 || !isClose(children[len(children)-1])) && (i+1 == len(values) || !isClose(values[i+1])) {
+			lines = append(lines, indent+"}")
+		}
+	}
+	return lines
+}
+
+// normalizeConfigSectionType maps user supplied section names and common aliases to a section type
+func normalizeConfigSectionType(section string) string {
+	switch strings.ToLower(strings.TrimSpace(section)) {
+	case "interface", "interfaces", "intf":
+		return configSectionInterfaces
+	case "bgp", "router bgp":
+		return configSectionBGP
+	case "ospf", "ospfv3", "ospf3", "router ospf":
+		return configSectionOSPF
+	case "acl", "acls", "access-list", "access-lists", "filter", "filters", "firewall":
+		return configSectionACL
+	case "static", "static_routes", "static-routes", "routes":
+		return configSectionStaticRoutes
+	case "vlan", "vlans":
+		return configSectionVLANs
+	}
+	return ""
+}
+
+// detectConfigGrammar picks a grammar from the vendor hint, falling back to the config content
+func detectConfigGrammar(vendor, configText string) string {
+	switch v := strings.ToLower(vendor); {
+	case strings.Contains(v, "juniper"), strings.Contains(v, "junos"):
+		return configGrammarJunos
+	case v != "":
+		return configGrammarIOS
+	}
+
+	for _, line := range strings.Split(configText, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "!") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "set ") || strings.HasSuffix(trimmed, "{") {
+			return configGrammarJunos
+		}
+		break
+	}
+	return configGrammarIOS
+}
+
+// parseConfigSections splits a configuration into typed sections for the given grammar
+func parseConfigSections(configText, grammar string) []ConfigSection {
+	lines := strings.Split(strings.ReplaceAll(configText, "\r\n", "\n"), "\n")
+	if grammar == configGrammarJunos {
+		for _, line := range lines {
+			if strings.HasPrefix(strings.TrimSpace(line), "set ") {
+				return parseJunosSetSections(lines)
+			}
+		}
+		return parseJunosBraceSections(lines)
+	}
+	return parseIOSSections(lines)
+}
+
+// parseIOSSections groups each top-level statement with its indented children.
+// Single-line statements sharing a section (numbered ACLs, static routes) are merged.
+func parseIOSSections(lines []string) []ConfigSection {
+	var sections []ConfigSection
+	index := make(map[string]int)
+	current := -1
+
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || strings.TrimSpace(line) == "!" {
+			continue
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if current >= 0 {
+				sections[current].Lines = append(sections[current].Lines, line)
+			}
+			continue
+		}
+
+		current = -1
+		for _, candidate := range iosSectionPatterns {
+			match := candidate.pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			name := ""
+			if len(match) > 1 {
+				name = match[1]
+			}
+			key := candidate.sectionType + "\x00" + name
+			if existing, ok := index[key]; ok {
+				current = existing
+				sections[current].Lines = append(sections[current].Lines, line)
+			} else {
+				sections = append(sections, ConfigSection{Type: candidate.sectionType, Name: name, Lines: []string{line}})
+				current = len(sections) - 1
+				index[key] = current
+			}
+			break
+		}
+	}
+	return sections
+}
+
+// parseJunosBraceSections walks a curly-brace Junos configuration tracking the
+// hierarchy path, and captures the block under each matching section path
+func parseJunosBraceSections(lines []string) []ConfigSection {
+	var sections []ConfigSection
+	var stack [][]string // Elements pushed by each open brace
+	current := -1
+	captureDepth := 0
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "/*") {
+			continue
+		}
+
+		if current >= 0 {
+			sections[current].Lines = append(sections[current].Lines, line)
+		}
+
+		switch {
+		case strings.HasSuffix(trimmed, "{"):
+			fields := strings.Fields(strings.TrimSuffix(trimmed, "{"))
+			if current < 0 {
+				if sectionType, name, ok := matchJunosSectionPath(flattenJunosPath(stack), fields); ok {
+					sections = append(sections, ConfigSection{Type: sectionType, Name: name, Lines: []string{line}})
+					current = len(sections) - 1
+					captureDepth = len(stack)
+				}
+			}
+			stack = append(stack, fields)
+		case strings.HasPrefix(trimmed, "}"):
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if current >= 0 && len(stack) <= captureDepth {
+				current = -1
+			}
+		case current < 0:
+			// Single-line leaf statements such as "route 0.0.0.0/0 next-hop 10.0.0.1;"
+			fields := strings.Fields(strings.TrimSuffix(trimmed, ";"))
+			if sectionType, name, ok := matchJunosSectionPath(flattenJunosPath(stack), fields); ok {
+				sections = append(sections, ConfigSection{Type: sectionType, Name: name, Lines: []string{line}})
+			}
+		}
+	}
+	return sections
+}
+
+// flattenJunosPath joins the elements of each open brace into a single hierarchy path
+func flattenJunosPath(stack [][]string) []string {
+	var path []string
+	for _, fields := range stack {
+		path = append(path, fields...)
+	}
+	return path
+}
+
+// parseJunosSetSections groups "set" statements by their section path and instance name
+func parseJunosSetSections(lines []string) []ConfigSection {
+	var sections []ConfigSection
+	index := make(map[string]int)
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "set ") {
+			continue
+		}
+		sectionType, name, ok := matchJunosSectionPath(nil, strings.Fields(strings.TrimPrefix(trimmed, "set ")))
+		if !ok {
+			continue
+		}
+		key := sectionType + "\x00" + name
+		if existing, exists := index[key]; exists {
+			sections[existing].Lines = append(sections[existing].Lines, trimmed)
+		} else {
+			sections = append(sections, ConfigSection{Type: sectionType, Name: name, Lines: []string{trimmed}})
+			index[key] = len(sections) - 1
+		}
+	}
+	return sections
+}
+
+// matchJunosSectionPath reports whether a statement with the given fields, found at
+// path, starts a section. The section instance name is the element following the
+// section path, which must be introduced by this statement rather than its parents.
+func matchJunosSectionPath(path []string, fields []string) (string, string, bool) {
+	full := append(append([]string{}, path...), fields...)
+
+	for _, candidate := range junosSectionPaths {
+		depth := len(candidate.path)
+		if len(full) <= depth || len(path) > depth {
+			continue
+		}
+		matched := true
+		for i, want := range candidate.path {
+			if want != "*" && want != full[i] {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return candidate.sectionType, full[depth], true
+		}
+	}
+	return "", "", false
+}
+
+// configSectionNames lists section instance names of a type, used in summaries
+func configSectionNames(sections []ConfigSection, sectionType string) []string {
+	var names []string
+	for _, section := range sections {
+		if section.Type == sectionType && section.Name != "" {
+			names = append(names, section.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const testIOSConfig = `hostname edge-1
+!
+interface Ethernet1
+ description uplink
+ ip address 10.0.0.1/31
+!
+interface Ethernet2
+ shutdown
+!
+router bgp 65001
+ neighbor 10.0.0.0 remote-as 65000
+ address-family ipv4
+  network 10.1.0.0/16
+!
+ip access-list extended MGMT
+ permit tcp any any eq 22
+access-list 10 permit 10.0.0.0 0.255.255.255
+access-list 10 deny any
+ip route 0.0.0.0 0.0.0.0 10.0.0.0
+ip route 192.168.0.0 255.255.0.0 10.0.0.2
+`
+
+const testJunosConfig = `interfaces {
+    ge-0/0/0 {
+        unit 0 {
+            family inet {
+                address 10.0.0.1/31;
+            }
+        }
+    }
+    lo0 {
+        unit 0;
+    }
+}
+protocols {
+    bgp {
+        group EXTERNAL {
+            neighbor 10.0.0.0;
+        }
+    }
+}
+firewall {
+    family inet {
+        filter PROTECT-RE {
+            term ssh {
+                then accept;
+            }
+        }
+    }
+}
+routing-options {
+    static {
+        route 0.0.0.0/0 next-hop 10.0.0.0;
+    }
+}
+`
+
+func TestParseIOSSections(t *testing.T) {
+	sections := parseConfigSections(testIOSConfig, detectConfigGrammar("", testIOSConfig))
+
+	byKey := make(map[string]ConfigSection)
+	for _, section := range sections {
+		byKey[section.Type+":"+section.Name] = section
+	}
+
+	if got := byKey["interfaces:Ethernet1"].Lines; len(got) != 3 {
+		t.Errorf("Expected Ethernet1 block with 3 lines, got %v", got)
+	}
+	if got := byKey["bgp:65001"].Lines; len(got) != 4 {
+		t.Errorf("Expected nested BGP block with 4 lines, got %v", got)
+	}
+	if got := byKey["acl:10"].Lines; len(got) != 2 {
+		t.Errorf("Expected numbered ACL lines to merge, got %v", got)
+	}
+	if _, ok := byKey["acl:MGMT"]; !ok {
+		t.Error("Expected named ACL MGMT")
+	}
+	if got := byKey["static_routes:"].Lines; len(got) != 2 {
+		t.Errorf("Expected both static routes in one section, got %v", got)
+	}
+}
+
+func TestParseJunosSections(t *testing.T) {
+	if grammar := detectConfigGrammar("", testJunosConfig); grammar != configGrammarJunos {
+		t.Fatalf("Expected junos grammar, got %s", grammar)
+	}
+
+	sections := parseConfigSections(testJunosConfig, configGrammarJunos)
+	byKey := make(map[string]ConfigSection)
+	for _, section := range sections {
+		byKey[section.Type+":"+section.Name] = section
+	}
+
+	for _, key := range []string{"interfaces:ge-0/0/0", "interfaces:lo0", "bgp:EXTERNAL", "acl:PROTECT-RE", "static_routes:0.0.0.0/0"} {
+		if _, ok := byKey[key]; !ok {
+			t.Errorf("Expected section %s, got %v", key, byKey)
+		}
+	}
+	if got := byKey["interfaces:ge-0/0/0"].Lines; len(got) != 7 {
+		t.Errorf("Expected ge-0/0/0 block with 7 lines, got %d: %v", len(got), got)
+	}
+
+	setConfig := "set interfaces ge-0/0/0 unit 0 family inet address 10.0.0.1/31\nset interfaces ge-0/0/0 description uplink\nset firewall filter MGMT term a then accept\n"
+	sections = parseConfigSections(setConfig, detectConfigGrammar("", setConfig))
+	if len(sections) != 2 || len(sections[0].Lines) != 2 || sections[1].Name != "MGMT" {
+		t.Errorf("Unexpected set-style sections: %+v", sections)
+	}
+}
+
+func TestGetConfigSection(t *testing.T) {
+	service := createTestService()
+	mockClient := service.forwardClient.(*MockForwardClient)
+	mockClient.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"text": "interface Ethernet1", "children": []interface{}{
+			map[string]interface{}{"text": "ip address 10.0.0.1/31", "children": []interface{}{}},
+		}},
+		{"text": "router bgp 65001", "children": []interface{}{}},
+	}}
+
+	response, err := service.getConfigSection(GetConfigSectionArgs{NetworkID: "162112", Device: "edge-1", Section: "interface", Name: "ethernet1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, " ip address 10.0.0.1/31") || contains(text, "router bgp") {
+		t.Errorf("Expected only the Ethernet1 block, got: %s", text)
+	}
+
+	response, err = service.getConfigSection(GetConfigSectionArgs{Device: "edge-1", Section: "acl", ConfigText: testIOSConfig})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "### MGMT") {
+		t.Errorf("Expected ACL sections from provided config, got: %s", response.Content[0].TextContent.Text)
+	}
+
+	if _, err := service.getConfigSection(GetConfigSectionArgs{Device: "edge-1", Section: "qos", ConfigText: testIOSConfig}); err == nil {
+		t.Error("Expected unsupported section to be rejected")
+	}
+}
+
+// configTreeClient serves a config tree trimmed to the depth of the NQE query, the way
+// Forward returns only the levels a query nests
+type configTreeClient struct {
+	*MockForwardClient
+	tree    []interface{}
+	queries int
+}
+
+func (c *configTreeClient) RunNQEQueryByString(params *forward.NQEQueryParams) (*forward.NQERunResult, error) {
+	c.queries++
+	depth := strings.Count(params.Query, "children: (foreach")
+	var trim func(value interface{}, level int) map[string]interface{}
+	trim = func(value interface{}, level int) map[string]interface{} {
+		line := value.(map[string]interface{})
+		children, _ := line["children"].([]interface{})
+		if level == depth {
+			return map[string]interface{}{"text": line["text"], "truncated": len(children) > 0}
+		}
+		trimmed := []interface{}{}
+		for _, child := range children {
+			trimmed = append(trimmed, trim(child, level+1))
+		}
+		return map[string]interface{}{"text": line["text"], "children": trimmed}
+	}
+	result := &forward.NQERunResult{}
+	for _, line := range c.tree {
+		result.Items = append(result.Items, trim(line, 0))
+	}
+	return result, nil
+}
+
+// configLine builds a config tree line; Forward leaves out closing braces
+func configLine(text string, children ...interface{}) interface{} {
+	return map[string]interface{}{"text": text, "children": children}
+}
+
+func TestFetchDeviceConfigDeepStanzas(t *testing.T) {
+	service := createTestService()
+	client := &configTreeClient{MockForwardClient: NewMockForwardClient(), tree: []interface{}{
+		configLine("firewall {",
+			configLine("family inet {",
+				configLine("filter PROTECT-RE {",
+					configLine("term ssh {",
+						configLine("from {",
+							configLine("protocol tcp;"),
+							configLine("destination-port ssh;")),
+						configLine("then accept;")),
+					configLine("term deny {",
+						configLine("then discard;"))))),
+		configLine("system {", configLine("host-name edge-1;")),
+	}}
+	service.forwardClient = client
+
+	configText, err := service.fetchDeviceConfig("162112", "", "edge-1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.queries != 2 {
+		t.Errorf("Expected the query rerun once deeper, got %d queries", client.queries)
+	}
+	if !strings.Contains(configText, "     protocol tcp;") {
+		t.Errorf("Expected lines five levels deep, got:\n%s", configText)
+	}
+
+	sections := parseConfigSections(configText, configGrammarJunos)
+	if len(sections) != 1 || sections[0].Name != "PROTECT-RE" {
+		t.Fatalf("Expected the PROTECT-RE filter, got %+v", sections)
+	}
+	text := strings.Join(sections[0].Lines, "\n")
+	for _, expected := range []string{"protocol tcp;", "destination-port ssh;", "then accept;", "term deny {", "then discard;"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in the filter, got:\n%s", expected, text)
+		}
+	}
+	if strings.Contains(text, "host-name") {
+		t.Errorf("Expected the filter to end at its closing brace, got:\n%s", text)
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// defaultConfirmationTTL is how long a confirmation token stays valid
+const defaultConfirmationTTL = 5 * time.Minute
+
+// DeleteImpact describes what a destructive action would remove
+type DeleteImpact struct {
+	Summary    string   // One line naming the target
+	Details    []string // What else goes with it
+	Rows       int      // Records removed; the token is only valid while this is unchanged
+	Reversible bool     // Whether the deletion can be undone afterwards
+}
+
+// pendingConfirmation is an issued token waiting for the client to resend the action
+type pendingConfirmation struct {
+	action    string
+	target    string
+	rows      int
+	expiresAt time.Time
+}
+
+// ConfirmationStore issues single-use tokens for destructive actions. A token is bound to
+// the action, its target and the number of records it would remove, so a client cannot
+// confirm one deletion and have a different or larger one executed.
+type ConfirmationStore struct {
+	pending map[string]*pendingConfirmation
+	mutex   sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+}
+
+// NewConfirmationStore creates a store whose tokens expire after ttl
+func NewConfirmationStore(ttl time.Duration) *ConfirmationStore {
+	if ttl <= 0 {
+		ttl = defaultConfirmationTTL
+	}
+	return &ConfirmationStore{pending: make(map[string]*pendingConfirmation), ttl: ttl, now: time.Now}
+}
+
+// Issue returns a new token for the action on target
+func (c *ConfirmationStore) Issue(action, target string, rows int) string {
+	random := make([]byte, 8)
+	rand.Read(random)
+	token := "confirm_" + hex.EncodeToString(random)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.removeExpired()
+	c.pending[token] = &pendingConfirmation{action: action, target: target, rows: rows, expiresAt: c.now().Add(c.ttl)}
+	return token
+}
+
+// Consume validates and releases a token. A token presented for another action or target,
+// or after the impact changed, is released as well so it cannot be retried.
+func (c *ConfirmationStore) Consume(token, action, target string, rows int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.removeExpired()
+
+	entry, ok := c.pending[token]
+	if !ok {
+		return fmt.Errorf("confirmation token %s not found or expired; call %s again without a token", token, action)
+	}
+	delete(c.pending, token)
+	if entry.action != action || entry.target != target {
+		return fmt.Errorf("confirmation token %s was issued for %s %s, not %s %s", token, entry.action, entry.target, action, target)
+	}
+	if entry.rows != rows {
+		return fmt.Errorf("the impact of %s %s changed from %d to %d records since the token was issued; call %s again without a token to review it", action, target, entry.rows, rows, action)
+	}
+	return nil
+}
+
+// removeExpired drops unused tokens; callers must hold the mutex
+func (c *ConfirmationStore) removeExpired() {
+	now := c.now()
+	for token, entry := range c.pending {
+		if now.After(entry.expiresAt) {
+			delete(c.pending, token)
+		}
+	}
+}
+
+// confirmDestructive implements the two-step protocol for destructive tools. Without a token
+// it returns a response describing the impact with a token to resend; with a valid token it
+// returns nil and the caller proceeds. Without a store, actions run immediately.
+func (s *ForwardMCPService) confirmDestructive(action, target, token string, impact DeleteImpact) (*mcp.ToolResponse, error) {
+	if s.confirmations == nil {
+		return nil, nil
+	}
+	if token != "" {
+		return nil, s.confirmations.Consume(token, action, target, impact.Rows)
+	}
+
+	token = s.confirmations.Issue(action, target, impact.Rows)
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("⚠️ Confirmation required: %s\n\n%s\n", action, impact.Summary))
+	for _, detail := range impact.Details {
+		text.WriteString("- " + detail + "\n")
+	}
+	text.WriteString(fmt.Sprintf("\nRecords removed: %d\n", impact.Rows))
+	if impact.Reversible {
+		text.WriteString(fmt.Sprintf("Undo: restore_entity within %s of the deletion.\n", formatRetention(s.trashRetention())))
+	} else {
+		text.WriteString("Undo: not possible.\n")
+	}
+	text.WriteString(fmt.Sprintf("\nNothing has been deleted. To proceed, call %s again with the same arguments and confirmation_token=%q (valid for %s).",
+		action, token, s.confirmations.ttl))
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// locationDeleteImpact names a location and counts the devices assigned to it
+func (s *ForwardMCPService) locationDeleteImpact(networkID, locationID string) (DeleteImpact, error) {
+	locations, err := s.forwardClient.GetLocations(networkID)
+	if err != nil {
+		return DeleteImpact{}, fmt.Errorf("failed to get locations: %w", err)
+	}
+	name := ""
+	for _, location := range locations {
+		if location.ID == locationID {
+			name = location.Name
+		}
+	}
+	if name == "" {
+		return DeleteImpact{}, fmt.Errorf("location %s not found in network %s", locationID, networkID)
+	}
+
+	impact := DeleteImpact{Summary: fmt.Sprintf("Location '%s' (%s) will be permanently deleted from network %s.", name, locationID, networkID), Rows: 1}
+	assignments, err := s.forwardClient.GetDeviceLocations(networkID)
+	if err != nil {
+		s.logger.Debug("Failed to count devices at location %s: %v", locationID, err)
+		return impact, nil
+	}
+	devices := 0
+	for _, assigned := range assignments {
+		if assigned == locationID {
+			devices++
+		}
+	}
+	if devices > 0 {
+		impact.Details = append(impact.Details, fmt.Sprintf("%d devices assigned to it lose their location", devices))
+		impact.Rows += devices
+	}
+	return impact, nil
+}
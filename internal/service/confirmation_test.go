@@ -0,0 +1,166 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var confirmationTokenPattern = regexp.MustCompile(`confirm_[0-9a-f]{16}`)
+
+// confirmationToken extracts the token from a first-step response
+func confirmationToken(t *testing.T, text string) string {
+	t.Helper()
+	token := confirmationTokenPattern.FindString(text)
+	if token == "" {
+		t.Fatalf("Expected a confirmation token, got %s", text)
+	}
+	return token
+}
+
+func TestConfirmationStore(t *testing.T) {
+	store := NewConfirmationStore(time.Minute)
+
+	token := store.Issue("delete_entity", "entity-1", 3)
+	if err := store.Consume(token, "delete_entity", "entity-1", 3); err != nil {
+		t.Fatalf("Expected the token to be accepted, got %v", err)
+	}
+	if err := store.Consume(token, "delete_entity", "entity-1", 3); err == nil {
+		t.Error("Expected a token to be single-use")
+	}
+
+	token = store.Issue("delete_entity", "entity-1", 3)
+	if err := store.Consume(token, "delete_entity", "entity-2", 3); err == nil || !strings.Contains(err.Error(), "was issued for") {
+		t.Errorf("Expected a target mismatch, got %v", err)
+	}
+
+	token = store.Issue("delete_location", "net/loc", 2)
+	if err := store.Consume(token, "delete_location", "net/loc", 5); err == nil || !strings.Contains(err.Error(), "changed from 2 to 5") {
+		t.Errorf("Expected a changed impact to be rejected, got %v", err)
+	}
+
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	token = store.Issue("delete_snapshot", "snap-1", 1)
+	now = now.Add(2 * time.Minute)
+	if err := store.Consume(token, "delete_snapshot", "snap-1", 1); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("Expected an expired token, got %v", err)
+	}
+}
+
+func TestDeleteEntityConfirmationAndRestore(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.confirmations = NewConfirmationStore(0)
+	memory := service.memorySystem
+
+	router, err := memory.CreateEntity("router-1", "device", map[string]interface{}{"role": "core"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	site, _ := memory.CreateEntity("site-a", "site", nil)
+	if _, err := memory.CreateRelation(router.ID, site.ID, "located_at", nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := memory.AddObservation(router.ID, "Upgraded to 17.9", "note", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first call only reports the impact
+	response, err := service.deleteEntity(DeleteEntityArgs{EntityID: router.ID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Records removed: 3") || !strings.Contains(text, "Nothing has been deleted") {
+		t.Errorf("Expected the impact summary, got %s", text)
+	}
+	if _, err := memory.GetEntity(router.ID); err != nil {
+		t.Fatalf("Expected the entity to survive the first call, got %v", err)
+	}
+
+	if _, err := service.deleteEntity(DeleteEntityArgs{EntityID: router.ID, ConfirmationToken: "confirm_0000000000000000"}); err == nil {
+		t.Error("Expected an unknown token to be rejected")
+	}
+	token := confirmationToken(t, text)
+	if _, err := service.deleteEntity(DeleteEntityArgs{EntityID: site.ID, ConfirmationToken: token}); err == nil {
+		t.Error("Expected a token for another entity to be rejected")
+	}
+
+	response, _ = service.deleteEntity(DeleteEntityArgs{EntityID: router.ID})
+	response, err = service.deleteEntity(DeleteEntityArgs{EntityID: router.ID, ConfirmationToken: confirmationToken(t, response.Content[0].TextContent.Text)})
+	if err != nil {
+		t.Fatalf("Expected the confirmed delete to succeed, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "1 relations and 1 observations") || !strings.Contains(text, "restore_entity") {
+		t.Errorf("Unexpected delete response: %s", text)
+	}
+	if _, err := memory.GetEntity(router.ID); err == nil {
+		t.Fatal("Expected the entity to be deleted")
+	}
+
+	response, err = service.restoreEntity(RestoreEntityArgs{EntityID: router.ID})
+	if err != nil {
+		t.Fatalf("Expected the entity to be restored, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "restored with 1 relations") {
+		t.Errorf("Unexpected restore response: %s", text)
+	}
+	restored, err := memory.GetEntity(router.ID)
+	if err != nil || restored.Metadata["role"] != "core" {
+		t.Fatalf("Expected the original entity back, got %+v %v", restored, err)
+	}
+	if relations, _ := memory.GetRelations(router.ID, ""); len(relations) != 1 {
+		t.Errorf("Expected the relation back, got %d", len(relations))
+	}
+	if observations, _ := memory.GetObservations(router.ID, ""); len(observations) != 1 {
+		t.Errorf("Expected the observation back, got %d", len(observations))
+	}
+	if _, err := service.restoreEntity(RestoreEntityArgs{EntityID: router.ID}); err == nil {
+		t.Error("Expected a second restore to fail")
+	}
+}
+
+func TestDeleteLocationAndSnapshotConfirmation(t *testing.T) {
+	service := createTestService()
+	service.confirmations = NewConfirmationStore(0)
+	mock := service.forwardClient.(*MockForwardClient)
+
+	response, err := service.deleteLocation(DeleteLocationArgs{NetworkID: "162112", LocationID: "location-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Data Center 1") || !strings.Contains(text, "1 devices assigned") || !strings.Contains(text, "Undo: not possible") {
+		t.Errorf("Expected the location impact, got %s", text)
+	}
+	if len(mock.locations) != 2 {
+		t.Fatal("Expected the location to survive the first call")
+	}
+
+	// Another device moving to the location invalidates the token
+	mock.deviceLocations["switch-1"] = "location-1"
+	if _, err := service.deleteLocation(DeleteLocationArgs{NetworkID: "162112", LocationID: "location-1", ConfirmationToken: confirmationToken(t, text)}); err == nil {
+		t.Error("Expected a stale token to be rejected")
+	}
+	response, _ = service.deleteLocation(DeleteLocationArgs{NetworkID: "162112", LocationID: "location-1"})
+	if _, err := service.deleteLocation(DeleteLocationArgs{NetworkID: "162112", LocationID: "location-1", ConfirmationToken: confirmationToken(t, response.Content[0].TextContent.Text)}); err != nil {
+		t.Fatalf("Expected the confirmed delete to succeed, got %v", err)
+	}
+	if len(mock.locations) != 1 {
+		t.Error("Expected the location to be deleted")
+	}
+	if _, err := service.deleteLocation(DeleteLocationArgs{NetworkID: "162112", LocationID: "missing"}); err == nil {
+		t.Error("Expected an unknown location to be rejected")
+	}
+
+	response, err = service.deleteSnapshot(DeleteSnapshotArgs{SnapshotID: "snapshot-1"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "Snapshot snapshot-1 will be permanently deleted") {
+		t.Fatalf("Expected the snapshot impact, got %v", err)
+	}
+	response, err = service.deleteSnapshot(DeleteSnapshotArgs{SnapshotID: "snapshot-1", ConfirmationToken: confirmationToken(t, response.Content[0].TextContent.Text)})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "deleted successfully") {
+		t.Errorf("Expected the confirmed delete to succeed, got %v", err)
+	}
+}
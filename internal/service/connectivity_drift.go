@@ -0,0 +1,366 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	connectivityBaselineEntityType = "connectivity_baseline"
+	defaultBaselineName            = "default"
+	defaultBaselinePrefixes        = 12 // Representative prefixes per aggregation level
+	maxBaselinePairs               = 500
+	maxDriftRowsShown              = 50
+)
+
+// Connectivity statuses besides the violation types of classifyViolation
+const (
+	connectivityConnected  = "CONNECTED"
+	connectivityNoPath     = "NO_PATH"
+	connectivityTimedOut   = "TIMED_OUT"
+	connectivityUnmeasured = "UNMEASURED"
+)
+
+// ConnectivityPair is one cell of the connectivity matrix: a path search between the
+// representative hosts of two prefixes and its outcome
+type ConnectivityPair struct {
+	SrcPrefix string `json:"src_prefix"`
+	DstPrefix string `json:"dst_prefix"`
+	From      string `json:"from"`
+	SrcIP     string `json:"src_ip"`
+	DstIP     string `json:"dst_ip"`
+	Status    string `json:"status"`
+}
+
+// key identifies the pair independently of its status
+func (p ConnectivityPair) key() string {
+	return p.From + "|" + p.SrcIP + "|" + p.DstIP
+}
+
+// ConnectivityBaseline is a stored connectivity matrix that later runs are compared against
+type ConnectivityBaseline struct {
+	Name       string             `json:"name"`
+	NetworkID  string             `json:"network_id"`
+	SnapshotID string             `json:"snapshot_id"`
+	CreatedAt  time.Time          `json:"created_at"`
+	Pairs      []ConnectivityPair `json:"pairs"`
+}
+
+// ConnectivityDrift is a pair whose status changed since the baseline
+type ConnectivityDrift struct {
+	ConnectivityPair
+	BaselineStatus string `json:"baseline_status"`
+	Regression     bool   `json:"regression"`
+}
+
+// ConnectivityDriftReport compares a re-run of the matrix with its baseline
+type ConnectivityDriftReport struct {
+	Baseline     string              `json:"baseline"`
+	NetworkID    string              `json:"network_id"`
+	SnapshotID   string              `json:"snapshot_id"`
+	BaselineAt   time.Time           `json:"baseline_at"`
+	Checked      int                 `json:"checked"`
+	Total        int                 `json:"total"`
+	Unchanged    int                 `json:"unchanged"`
+	Inconclusive int                 `json:"inconclusive"`
+	Drift        []ConnectivityDrift `json:"drift"`
+	Failed       []string            `json:"failed_batches,omitempty"`
+}
+
+// connectivityBaselinePairs builds the matrix of a baseline: every ordered pair of the
+// representative hosts of the busiest prefixes at each level, within one address family
+func connectivityBaselinePairs(entries []PrefixIndexEntry, prefixLevels []string) []ConnectivityPair {
+	var pairs []ConnectivityPair
+	seen := make(map[string]bool)
+	for _, level := range prefixLevels {
+		length, ok := parsePrefixLevel(level)
+		if !ok {
+			continue
+		}
+		endpoints := sweepEndpoints(entries, length, defaultBaselinePrefixes)
+		for _, src := range endpoints {
+			for _, dst := range endpoints {
+				if src.Prefix == dst.Prefix || src.Family != dst.Family || len(pairs) >= maxBaselinePairs {
+					continue
+				}
+				pair := ConnectivityPair{SrcPrefix: src.Prefix, DstPrefix: dst.Prefix, From: src.Device, SrcIP: src.IP, DstIP: dst.IP}
+				if seen[pair.key()] {
+					continue
+				}
+				seen[pair.key()] = true
+				pairs = append(pairs, pair)
+			}
+		}
+	}
+	return pairs
+}
+
+// connectivityStatus classifies the outcome of one path search: CONNECTED when any path is
+// delivered and permitted, otherwise the violation of the first path
+func connectivityStatus(response forward.PathSearchBulkResponse) string {
+	if len(response.Info.Paths) == 0 {
+		if response.TimedOut {
+			return connectivityTimedOut
+		}
+		return connectivityNoPath
+	}
+	for _, path := range response.Info.Paths {
+		if classifyViolation(path) == "" {
+			return connectivityConnected
+		}
+	}
+	return strings.ToUpper(classifyViolation(response.Info.Paths[0]))
+}
+
+// isConclusiveStatus reports whether a status says something about connectivity; timeouts
+// and failed searches are neither drift nor agreement
+func isConclusiveStatus(status string) bool {
+	return status != connectivityTimedOut && status != connectivityUnmeasured && status != ""
+}
+
+// measureConnectivity runs the path search of every pair in batches and sets its status.
+// Pairs of failed batches are left UNMEASURED and the failures returned.
+func (s *ForwardMCPService) measureConnectivity(networkID, snapshotID string, pairs []ConnectivityPair) []string {
+	apiSnapshotID := ""
+	if snapshotID != "latest" {
+		apiSnapshotID = snapshotID
+	}
+	var failed []string
+	for start := 0; start < len(pairs); start += sweepBatchSize {
+		batch := pairs[start:min(start+sweepBatchSize, len(pairs))]
+		queries := make([]forward.PathSearchParams, len(batch))
+		for i, pair := range batch {
+			batch[i].Status = connectivityUnmeasured
+			queries[i] = forward.PathSearchParams{From: pair.From, SrcIP: pair.SrcIP, DstIP: pair.DstIP}
+		}
+		responses, err := s.forwardClient.SearchPathsBulk(networkID, &forward.PathSearchBulkRequest{
+			Queries:    queries,
+			Intent:     "PREFER_DELIVERED",
+			MaxResults: 3,
+		}, apiSnapshotID)
+		if err != nil {
+			s.logger.Warn("Connectivity batch at %d failed: %v", start, err)
+			failed = append(failed, fmt.Sprintf("searches %d-%d: %s", start+1, start+len(batch), truncateString(err.Error(), 160)))
+			continue
+		}
+		for i, response := range responses {
+			if i < len(batch) {
+				batch[i].Status = connectivityStatus(response)
+			}
+		}
+	}
+	return failed
+}
+
+// baselineEntityName is the memory entity name of a network's named baseline
+func baselineEntityName(networkID, name string) string {
+	return fmt.Sprintf("connectivity_baseline_%s_%s", networkID, name)
+}
+
+// storeConnectivityBaseline saves a baseline in memory, replacing one of the same name
+func (s *ForwardMCPService) storeConnectivityBaseline(baseline *ConnectivityBaseline) error {
+	entityName := baselineEntityName(baseline.NetworkID, baseline.Name)
+	// The matrix lives in metadata rather than an observation, so redaction cannot alter the
+	// addresses the drift check searches again
+	_, err := s.memorySystem.ReplaceEntity(entityName, connectivityBaselineEntityType, map[string]interface{}{
+		"name":        baseline.Name,
+		"network_id":  baseline.NetworkID,
+		"snapshot_id": baseline.SnapshotID,
+		"created_at":  baseline.CreatedAt.Format(time.RFC3339),
+		"pairs":       len(baseline.Pairs),
+		"matrix":      MarshalCompactJSONString(baseline.Pairs),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store baseline %s: %w", baseline.Name, err)
+	}
+	return nil
+}
+
+// loadConnectivityBaseline reads a network's named baseline
+func (s *ForwardMCPService) loadConnectivityBaseline(networkID, name string) (*ConnectivityBaseline, error) {
+	entity, err := s.memorySystem.getEntityByNameAndType(baselineEntityName(networkID, name), connectivityBaselineEntityType)
+	if err != nil {
+		return nil, fmt.Errorf("no connectivity baseline %q for network %s; run analyze_network_prefixes with save_baseline=true first", name, networkID)
+	}
+	baseline := &ConnectivityBaseline{
+		Name:       name,
+		NetworkID:  networkID,
+		SnapshotID: resultValueString(entity.Metadata["snapshot_id"]),
+	}
+	if created, err := time.Parse(time.RFC3339, resultValueString(entity.Metadata["created_at"])); err == nil {
+		baseline.CreatedAt = created
+	}
+	if err := json.Unmarshal([]byte(resultValueString(entity.Metadata["matrix"])), &baseline.Pairs); err != nil {
+		return nil, fmt.Errorf("baseline %q is corrupt: %w", name, err)
+	}
+	return baseline, nil
+}
+
+// saveConnectivityBaseline measures the connectivity matrix of a network and stores it as a
+// baseline, returning a summary for the analyze_network_prefixes report
+func (s *ForwardMCPService) saveConnectivityBaseline(networkID, snapshotID, name string, prefixLevels []string) (string, error) {
+	if s.memorySystem == nil {
+		return "", newCodedError(CodeMemoryUnavailable)
+	}
+	entries, err := s.prefixIndexEntries(networkID, snapshotID, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to index network %s: %w", networkID, err)
+	}
+	pairs := connectivityBaselinePairs(entries, prefixLevels)
+	if len(pairs) == 0 {
+		return "", fmt.Errorf("network %s has no prefix pairs at levels %s to baseline", networkID, strings.Join(prefixLevels, ", "))
+	}
+	failed := s.measureConnectivity(networkID, snapshotID, pairs)
+	if len(failed) > 0 {
+		return "", fmt.Errorf("baseline not saved, path searches failed: %s", failed[0])
+	}
+	baseline := &ConnectivityBaseline{
+		Name:       name,
+		NetworkID:  networkID,
+		SnapshotID: prefixIndexSnapshotKey(snapshotID),
+		CreatedAt:  time.Now().UTC(),
+		Pairs:      pairs,
+	}
+	if err := s.storeConnectivityBaseline(baseline); err != nil {
+		return "", err
+	}
+
+	counts := make(map[string]int)
+	for _, pair := range pairs {
+		counts[pair.Status]++
+	}
+	statuses := make([]string, 0, len(counts))
+	for status, count := range counts {
+		statuses = append(statuses, fmt.Sprintf("%s %d", status, count))
+	}
+	sort.Strings(statuses)
+	return fmt.Sprintf("\n## 📌 Connectivity Baseline %q\n\nMeasured %d prefix pairs (%s). Run detect_connectivity_drift after a change to compare.\n",
+		name, len(pairs), strings.Join(statuses, ", ")), nil
+}
+
+// compareConnectivity reports the pairs whose conclusive status differs from the baseline
+func compareConnectivity(baseline *ConnectivityBaseline, current []ConnectivityPair) *ConnectivityDriftReport {
+	report := &ConnectivityDriftReport{
+		Baseline:   baseline.Name,
+		NetworkID:  baseline.NetworkID,
+		BaselineAt: baseline.CreatedAt,
+		Checked:    len(current),
+		Total:      len(baseline.Pairs),
+	}
+	previous := make(map[string]string, len(baseline.Pairs))
+	for _, pair := range baseline.Pairs {
+		previous[pair.key()] = pair.Status
+	}
+	for _, pair := range current {
+		before := previous[pair.key()]
+		switch {
+		case !isConclusiveStatus(pair.Status) || !isConclusiveStatus(before):
+			report.Inconclusive++
+		case pair.Status == before:
+			report.Unchanged++
+		default:
+			report.Drift = append(report.Drift, ConnectivityDrift{
+				ConnectivityPair: pair,
+				BaselineStatus:   before,
+				Regression:       before == connectivityConnected,
+			})
+		}
+	}
+	// Regressions first, since they are what post-change validation is looking for
+	sort.SliceStable(report.Drift, func(i, j int) bool {
+		return report.Drift[i].Regression && !report.Drift[j].Regression
+	})
+	return report
+}
+
+// detectConnectivityDrift re-runs a baseline's matrix, or a sample of it, and reports the
+// pairs whose status changed
+func (s *ForwardMCPService) detectConnectivityDrift(args DetectConnectivityDriftArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("detect_connectivity_drift", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	baseline, err := s.loadConnectivityBaseline(networkID, firstNonEmpty(args.Baseline, defaultBaselineName))
+	if err != nil {
+		return nil, err
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	current := make([]ConnectivityPair, len(baseline.Pairs))
+	copy(current, baseline.Pairs)
+	if args.Sample > 0 && args.Sample < len(current) {
+		rand.Shuffle(len(current), func(i, j int) { current[i], current[j] = current[j], current[i] })
+		current = current[:args.Sample]
+	}
+	failed := s.measureConnectivity(networkID, snapshotID, current)
+	if len(failed) > 0 && !slices.ContainsFunc(current, func(pair ConnectivityPair) bool { return pair.Status != connectivityUnmeasured }) {
+		return nil, fmt.Errorf("connectivity drift check failed: %s", failed[0])
+	}
+
+	report := compareConnectivity(baseline, current)
+	report.SnapshotID = prefixIndexSnapshotKey(snapshotID)
+	report.Failed = failed
+
+	if strings.EqualFold(args.Format, "json") {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode drift report: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(s.formatConnectivityDrift(report))), nil
+}
+
+// formatConnectivityDrift renders a drift report as markdown
+func (s *ForwardMCPService) formatConnectivityDrift(report *ConnectivityDriftReport) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Connectivity Drift: network %s (snapshot %s)\n\n", report.NetworkID, report.SnapshotID))
+	text.WriteString(fmt.Sprintf("Baseline %q from %s. Checked %d of %d pairs: %d unchanged, %d changed",
+		report.Baseline, s.timeFormatter.FormatWithAge(report.BaselineAt), report.Checked, report.Total, report.Unchanged, len(report.Drift)))
+	if report.Inconclusive > 0 {
+		text.WriteString(fmt.Sprintf(", %d inconclusive (timed out or not measured)", report.Inconclusive))
+	}
+	text.WriteString(".\n\n")
+	for _, failure := range report.Failed {
+		text.WriteString(fmt.Sprintf("⚠️ Failed batch %s\n", failure))
+	}
+
+	if len(report.Drift) == 0 {
+		text.WriteString("✅ No connectivity changed since the baseline.\n")
+		return text.String()
+	}
+
+	regressions := 0
+	for _, drift := range report.Drift {
+		if drift.Regression {
+			regressions++
+		}
+	}
+	if regressions > 0 {
+		text.WriteString(fmt.Sprintf("❌ %d pairs lost connectivity.\n\n", regressions))
+	}
+	text.WriteString("| Source | Destination | Baseline | Now |\n|---|---|---|---|\n")
+	for i, drift := range report.Drift {
+		if i == maxDriftRowsShown {
+			text.WriteString(fmt.Sprintf("\n… %d more changes (use format=json for all)\n", len(report.Drift)-i))
+			break
+		}
+		text.WriteString(fmt.Sprintf("| %s %s (%s) | %s (%s) | %s | %s |\n",
+			drift.From, drift.SrcIP, drift.SrcPrefix, drift.DstIP, drift.DstPrefix, drift.BaselineStatus, drift.Status))
+	}
+	text.WriteString("\nUse troubleshoot_connectivity on a changed pair for the full route trace.\n")
+	return text.String()
+}
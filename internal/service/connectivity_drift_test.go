@@ -0,0 +1,122 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// driftClient delivers every search except those to a blackholed destination
+type driftClient struct {
+	*MockForwardClient
+	blackholed map[string]bool
+	searches   int
+}
+
+func (c *driftClient) SearchPathsBulk(networkID string, request *forward.PathSearchBulkRequest, snapshotID string) ([]forward.PathSearchBulkResponse, error) {
+	responses := make([]forward.PathSearchBulkResponse, len(request.Queries))
+	for i, query := range request.Queries {
+		c.searches++
+		outcome := "DELIVERED"
+		if c.blackholed[query.DstIP] {
+			outcome = "BLACKHOLE"
+		}
+		responses[i] = forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{
+			{ForwardingOutcome: outcome, SecurityOutcome: "PERMITTED", Hops: []forward.BulkHop{{DeviceName: query.From}}},
+		}}}
+	}
+	return responses, nil
+}
+
+func TestConnectivityStatus(t *testing.T) {
+	tests := map[string]forward.PathSearchBulkResponse{
+		connectivityConnected: {Info: forward.PathSearchInfo{Paths: []forward.BulkPath{
+			{ForwardingOutcome: "BLACKHOLE"}, {ForwardingOutcome: "DELIVERED", SecurityOutcome: "PERMITTED"}}}},
+		"ACL_DROP":           {Info: forward.PathSearchInfo{Paths: []forward.BulkPath{{ForwardingOutcome: "DELIVERED", SecurityOutcome: "DENIED"}}}},
+		connectivityNoPath:   {},
+		connectivityTimedOut: {TimedOut: true},
+	}
+	for expected, response := range tests {
+		if status := connectivityStatus(response); status != expected {
+			t.Errorf("Expected %s, got %s", expected, status)
+		}
+	}
+}
+
+func TestConnectivityBaselineAndDrift(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	client := &driftClient{MockForwardClient: service.forwardClient.(*MockForwardClient), blackholed: make(map[string]bool)}
+	client.devices = prefixIndexTestDevices()
+	service.forwardClient = client
+	service.deviceCache = nil
+
+	if _, err := service.detectConnectivityDrift(DetectConnectivityDriftArgs{NetworkID: "162112"}); err == nil || !strings.Contains(err.Error(), "save_baseline") {
+		t.Errorf("Expected a hint to save a baseline first, got %v", err)
+	}
+
+	readOnly := NetworkPrefixAnalysisArgs{NetworkID: "162112", SaveBaseline: true, Caller: &APIKeyIdentity{ID: "ro", ReadOnly: true}}
+	if _, err := service.analyzeNetworkPrefixes(readOnly); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expected a read-only key refused a baseline, got %v", err)
+	}
+
+	// Four /24 prefixes form twelve ordered pairs
+	summary, err := service.saveConnectivityBaseline("162112", "", defaultBaselineName, []string{"/24"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(summary, "Measured 12 prefix pairs (CONNECTED 12)") {
+		t.Errorf("Unexpected baseline summary: %s", summary)
+	}
+	baseline, err := service.loadConnectivityBaseline("162112", defaultBaselineName)
+	if err != nil || len(baseline.Pairs) != 12 {
+		t.Fatalf("Expected the stored matrix, got %v %v", baseline, err)
+	}
+
+	response, err := service.detectConnectivityDrift(DetectConnectivityDriftArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "12 unchanged, 0 changed") {
+		t.Errorf("Expected no drift, got %s", text)
+	}
+
+	// Blackholing branch-1 breaks the three pairs towards it
+	client.blackholed["172.16.0.1"] = true
+	response, err = service.detectConnectivityDrift(DetectConnectivityDriftArgs{NetworkID: "162112", Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report ConnectivityDriftReport
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	if report.Unchanged != 9 || len(report.Drift) != 3 {
+		t.Fatalf("Expected three changed pairs, got %+v", report)
+	}
+	for _, drift := range report.Drift {
+		if !drift.Regression || drift.DstIP != "172.16.0.1" || drift.BaselineStatus != connectivityConnected || drift.Status != "BLACKHOLE" {
+			t.Errorf("Unexpected drift: %+v", drift)
+		}
+	}
+
+	client.searches = 0
+	response, err = service.detectConnectivityDrift(DetectConnectivityDriftArgs{NetworkID: "162112", Sample: 5})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; client.searches != 5 || !strings.Contains(text, "Checked 5 of 12 pairs") {
+		t.Errorf("Expected a sample of 5 searches, got %d: %s", client.searches, text)
+	}
+
+	// Saving again under the same name replaces the baseline
+	if _, err := service.saveConnectivityBaseline("162112", "", defaultBaselineName, []string{"/24"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	response, _ = service.detectConnectivityDrift(DetectConnectivityDriftArgs{NetworkID: "162112"})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "12 unchanged, 0 changed") {
+		t.Errorf("Expected the new baseline to include the blackhole, got %s", text)
+	}
+}
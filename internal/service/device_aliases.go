@@ -0,0 +1,549 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// maxAliasChain bounds how many renames Resolve follows
+const maxAliasChain = 16
+
+// Alias sources
+const (
+	aliasSourceSnapshot = "snapshot_diff"
+	aliasSourceManual   = "manual"
+)
+
+// DeviceAlias maps a former device name to its current name
+type DeviceAlias struct {
+	NetworkID    string    `json:"network_id"`
+	OldName      string    `json:"old_name"`
+	CurrentName  string    `json:"current_name"`
+	Source       string    `json:"source"`
+	MatchedOn    string    `json:"matched_on,omitempty"`
+	FromSnapshot string    `json:"from_snapshot,omitempty"`
+	ToSnapshot   string    `json:"to_snapshot,omitempty"`
+	DetectedAt   time.Time `json:"detected_at"`
+}
+
+// DeviceRename is a rename detected between two inventories
+type DeviceRename struct {
+	OldName   string `json:"old_name"`
+	NewName   string `json:"new_name"`
+	MatchedOn string `json:"matched_on"`
+}
+
+// deviceIdentity holds the attributes that survive a rename
+type deviceIdentity struct {
+	SerialNumber  string   `json:"serial,omitempty"`
+	ManagementIPs []string `json:"management_ips,omitempty"`
+	InterfaceIPs  []string `json:"interface_ips,omitempty"`
+}
+
+// DeviceAliasStore persists old → current device name mappings per network, together with
+// the identities of the last inventory seen so renames can be detected between snapshots
+type DeviceAliasStore struct {
+	db         *sql.DB
+	instanceID string
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewDeviceAliasStore creates the alias tables in db
+func NewDeviceAliasStore(db *sql.DB, instanceID string, logger *logger.Logger) (*DeviceAliasStore, error) {
+	store := &DeviceAliasStore{db: db, instanceID: instanceID, logger: logger, now: time.Now}
+	schema := `
+	CREATE TABLE IF NOT EXISTS device_aliases (
+		instance_id TEXT NOT NULL,
+		network_id TEXT NOT NULL,
+		old_name TEXT NOT NULL,
+		current_name TEXT NOT NULL,
+		source TEXT NOT NULL,
+		matched_on TEXT,
+		from_snapshot TEXT,
+		to_snapshot TEXT,
+		detected_at INTEGER NOT NULL,
+		PRIMARY KEY(instance_id, network_id, old_name)
+	);
+
+	CREATE TABLE IF NOT EXISTS device_identities (
+		instance_id TEXT NOT NULL,
+		network_id TEXT NOT NULL,
+		device TEXT NOT NULL,
+		snapshot_id TEXT NOT NULL,
+		identity TEXT NOT NULL,
+		PRIMARY KEY(instance_id, network_id, device)
+	);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create device alias schema: %w", err)
+	}
+	return store, nil
+}
+
+// identityOf extracts the rename-stable attributes of a device
+func identityOf(device forward.Device) deviceIdentity {
+	identity := deviceIdentity{SerialNumber: device.SerialNumber, ManagementIPs: device.ManagementIPs}
+	for _, iface := range device.Interfaces {
+		if ip := parseInterfaceAddress(iface.IPAddress); ip != nil && !ip.IsLinkLocalUnicast() {
+			identity.InterfaceIPs = append(identity.InterfaceIPs, ip.String())
+		}
+	}
+	return identity
+}
+
+// detectDeviceRenames pairs devices that disappeared from previous with devices that appeared
+// in current. Matches are made on serial number, then management IP, then a majority of
+// shared interface addresses; each device is matched at most once.
+func detectDeviceRenames(previous, current map[string]deviceIdentity) []DeviceRename {
+	var removed, added []string
+	for name := range previous {
+		if _, ok := current[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	for name := range current {
+		if _, ok := previous[name]; !ok {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+
+	matchers := []struct {
+		name  string
+		match func(a, b deviceIdentity) bool
+	}{
+		{"serial_number", func(a, b deviceIdentity) bool {
+			return a.SerialNumber != "" && strings.EqualFold(a.SerialNumber, b.SerialNumber)
+		}},
+		{"management_ip", func(a, b deviceIdentity) bool { return sharedCount(a.ManagementIPs, b.ManagementIPs) > 0 }},
+		{"interface_ips", func(a, b deviceIdentity) bool {
+			shared := sharedCount(a.InterfaceIPs, b.InterfaceIPs)
+			smaller := len(a.InterfaceIPs)
+			if len(b.InterfaceIPs) < smaller {
+				smaller = len(b.InterfaceIPs)
+			}
+			return shared > 0 && shared*2 > smaller
+		}},
+	}
+
+	used := make(map[string]bool)
+	matched := make(map[string]bool)
+	var renames []DeviceRename
+	for _, matcher := range matchers {
+		for _, oldName := range removed {
+			if matched[oldName] {
+				continue
+			}
+			for _, newName := range added {
+				if used[newName] || !matcher.match(previous[oldName], current[newName]) {
+					continue
+				}
+				renames = append(renames, DeviceRename{OldName: oldName, NewName: newName, MatchedOn: matcher.name})
+				matched[oldName] = true
+				used[newName] = true
+				break
+			}
+		}
+	}
+	sort.Slice(renames, func(i, j int) bool { return renames[i].OldName < renames[j].OldName })
+	return renames
+}
+
+func sharedCount(a, b []string) int {
+	set := make(map[string]bool, len(a))
+	for _, value := range a {
+		set[value] = true
+	}
+	shared := 0
+	for _, value := range b {
+		if set[value] {
+			shared++
+			delete(set, value)
+		}
+	}
+	return shared
+}
+
+func identitiesOf(devices []forward.Device) map[string]deviceIdentity {
+	identities := make(map[string]deviceIdentity, len(devices))
+	for _, device := range devices {
+		identities[device.Name] = identityOf(device)
+	}
+	return identities
+}
+
+// Add records that oldName is now currentName. Aliases pointing at oldName are moved to
+// currentName so chains stay one hop, and an alias for currentName itself is dropped since
+// that name is in use again.
+func (d *DeviceAliasStore) Add(networkID string, alias DeviceAlias) error {
+	if alias.OldName == "" || alias.CurrentName == "" || alias.OldName == alias.CurrentName {
+		return fmt.Errorf("old and current device names must be different and non-empty")
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin alias transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE device_aliases SET current_name = ? WHERE instance_id = ? AND network_id = ? AND current_name = ?`,
+		alias.CurrentName, d.instanceID, networkID, alias.OldName); err != nil {
+		return fmt.Errorf("failed to update alias chain: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM device_aliases WHERE instance_id = ? AND network_id = ? AND (old_name = ? OR old_name = current_name)`,
+		d.instanceID, networkID, alias.CurrentName); err != nil {
+		return fmt.Errorf("failed to clear reused alias: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO device_aliases (instance_id, network_id, old_name, current_name, source, matched_on, from_snapshot, to_snapshot, detected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, d.instanceID, networkID, alias.OldName, alias.CurrentName, alias.Source, alias.MatchedOn,
+		alias.FromSnapshot, alias.ToSnapshot, d.now().Unix()); err != nil {
+		return fmt.Errorf("failed to record alias: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Remove deletes the alias for a former name
+func (d *DeviceAliasStore) Remove(networkID, oldName string) (bool, error) {
+	result, err := d.db.Exec(`DELETE FROM device_aliases WHERE instance_id = ? AND network_id = ? AND old_name = ?`,
+		d.instanceID, networkID, oldName)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove alias: %w", err)
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// Resolve follows aliases from name to the device's current name
+func (d *DeviceAliasStore) Resolve(networkID, name string) (string, bool) {
+	current := name
+	for i := 0; i < maxAliasChain; i++ {
+		var next string
+		err := d.db.QueryRow(`SELECT current_name FROM device_aliases WHERE instance_id = ? AND network_id = ? AND old_name = ?`,
+			d.instanceID, networkID, current).Scan(&next)
+		if err != nil || next == "" || next == name {
+			break
+		}
+		current = next
+	}
+	return current, current != name
+}
+
+// List returns the aliases of a network, optionally only those involving device
+func (d *DeviceAliasStore) List(networkID, device string) ([]DeviceAlias, error) {
+	query := `SELECT network_id, old_name, current_name, source, COALESCE(matched_on, ''), COALESCE(from_snapshot, ''), COALESCE(to_snapshot, ''), detected_at
+		FROM device_aliases WHERE instance_id = ? AND network_id = ?`
+	args := []interface{}{d.instanceID, networkID}
+	if device != "" {
+		query += " AND (old_name = ? OR current_name = ?)"
+		args = append(args, device, device)
+	}
+	query += " ORDER BY current_name, old_name"
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list aliases: %w", err)
+	}
+	defer rows.Close()
+
+	var aliases []DeviceAlias
+	for rows.Next() {
+		var alias DeviceAlias
+		var detectedAt int64
+		if err := rows.Scan(&alias.NetworkID, &alias.OldName, &alias.CurrentName, &alias.Source, &alias.MatchedOn,
+			&alias.FromSnapshot, &alias.ToSnapshot, &detectedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan alias: %w", err)
+		}
+		alias.DetectedAt = time.Unix(detectedAt, 0)
+		aliases = append(aliases, alias)
+	}
+	return aliases, rows.Err()
+}
+
+// Observe compares an inventory with the last one seen for the network, records any renames
+// and remembers the new inventory for the next comparison
+func (d *DeviceAliasStore) Observe(networkID, snapshotID string, devices []forward.Device) ([]DeviceRename, error) {
+	previous, previousSnapshot, err := d.loadIdentities(networkID)
+	if err != nil {
+		return nil, err
+	}
+	current := identitiesOf(devices)
+
+	var renames []DeviceRename
+	if len(previous) > 0 {
+		renames = detectDeviceRenames(previous, current)
+		for _, rename := range renames {
+			if err := d.Add(networkID, DeviceAlias{
+				OldName:      rename.OldName,
+				CurrentName:  rename.NewName,
+				Source:       aliasSourceSnapshot,
+				MatchedOn:    rename.MatchedOn,
+				FromSnapshot: previousSnapshot,
+				ToSnapshot:   snapshotID,
+			}); err != nil {
+				return renames, err
+			}
+			d.logger.Info("Detected device rename in network %s: %s -> %s (matched on %s)", networkID, rename.OldName, rename.NewName, rename.MatchedOn)
+		}
+		if sameDeviceNames(previous, current) {
+			return renames, nil
+		}
+	}
+	return renames, d.saveIdentities(networkID, snapshotID, current)
+}
+
+func sameDeviceNames(a, b map[string]deviceIdentity) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name := range a {
+		if _, ok := b[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *DeviceAliasStore) loadIdentities(networkID string) (map[string]deviceIdentity, string, error) {
+	rows, err := d.db.Query(`SELECT device, snapshot_id, identity FROM device_identities WHERE instance_id = ? AND network_id = ?`,
+		d.instanceID, networkID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to load device identities: %w", err)
+	}
+	defer rows.Close()
+
+	identities := make(map[string]deviceIdentity)
+	snapshotID := ""
+	for rows.Next() {
+		var device, data string
+		if err := rows.Scan(&device, &snapshotID, &data); err != nil {
+			return nil, "", fmt.Errorf("failed to scan device identity: %w", err)
+		}
+		var identity deviceIdentity
+		if err := json.Unmarshal([]byte(data), &identity); err != nil {
+			continue
+		}
+		identities[device] = identity
+	}
+	return identities, snapshotID, rows.Err()
+}
+
+func (d *DeviceAliasStore) saveIdentities(networkID, snapshotID string, identities map[string]deviceIdentity) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin identity transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM device_identities WHERE instance_id = ? AND network_id = ?`, d.instanceID, networkID); err != nil {
+		return fmt.Errorf("failed to clear device identities: %w", err)
+	}
+	stmt, err := tx.Prepare(`INSERT INTO device_identities (instance_id, network_id, device, snapshot_id, identity) VALUES (?, ?, ?, ?, ?)`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare identity insert: %w", err)
+	}
+	defer stmt.Close()
+	for device, identity := range identities {
+		data, err := json.Marshal(identity)
+		if err != nil {
+			return fmt.Errorf("failed to marshal device identity: %w", err)
+		}
+		if _, err := stmt.Exec(d.instanceID, networkID, device, prefixIndexSnapshotKey(snapshotID), string(data)); err != nil {
+			return fmt.Errorf("failed to save device identity: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// observeDeviceRenames records renames whenever a fresh latest-snapshot inventory is loaded.
+// Older pinned snapshots are skipped so that browsing history never records reverse renames.
+func (s *ForwardMCPService) observeDeviceRenames(networkID, snapshotID string, devices []forward.Device) {
+	if s.deviceAliases == nil || len(devices) == 0 || (snapshotID != "" && snapshotID != "latest") {
+		return
+	}
+	if _, err := s.deviceAliases.Observe(networkID, snapshotID, devices); err != nil {
+		s.logger.Warn("Failed to track device renames for network %s: %v", networkID, err)
+	}
+}
+
+// canonicalDeviceName maps a possibly outdated device name to the name used in the current
+// inventory, leaving names that exist or have no alias unchanged
+func (s *ForwardMCPService) canonicalDeviceName(networkID, name string, devices []forward.Device) string {
+	if s.deviceAliases == nil {
+		return name
+	}
+	for _, device := range devices {
+		if device.Name == name {
+			return name
+		}
+	}
+	if current, renamed := s.deviceAliases.Resolve(networkID, name); renamed {
+		s.logger.Info("Resolved former device name %s to %s", name, current)
+		return current
+	}
+	return name
+}
+
+// listDeviceAliases lists known device renames
+func (s *ForwardMCPService) listDeviceAliases(args ListDeviceAliasesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_device_aliases", args, nil)
+
+	if s.deviceAliases == nil {
+		return nil, fmt.Errorf("device alias tracking requires the memory system")
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	aliases, err := s.deviceAliases.List(networkID, args.Device)
+	if err != nil {
+		return nil, err
+	}
+	if len(aliases) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("No device aliases recorded for network %s.", networkID))), nil
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("%d device aliases in network %s:\n\n", len(aliases), networkID))
+	out.WriteString("| Former Name | Current Name | Source | Matched On | Detected |\n|---|---|---|---|---|\n")
+	for _, alias := range aliases {
+		out.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", alias.OldName, alias.CurrentName, alias.Source,
+			firstNonEmpty(alias.MatchedOn, "-"), s.timeFormatter.Format(alias.DetectedAt)))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(out.String())), nil
+}
+
+// addDeviceAlias records a rename by hand, or removes an alias when current_name is empty
+func (s *ForwardMCPService) addDeviceAlias(args AddDeviceAliasArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("add_device_alias", args, nil)
+
+	if s.deviceAliases == nil {
+		return nil, fmt.Errorf("device alias tracking requires the memory system")
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	oldName := strings.TrimSpace(args.OldName)
+	if oldName == "" {
+		return nil, fmt.Errorf("old_name is required")
+	}
+
+	currentName := strings.TrimSpace(args.CurrentName)
+	if currentName == "" {
+		removed, err := s.deviceAliases.Remove(networkID, oldName)
+		if err != nil {
+			return nil, err
+		}
+		if !removed {
+			return nil, fmt.Errorf("no alias recorded for %s in network %s", oldName, networkID)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Removed alias for %s.", oldName))), nil
+	}
+
+	if err := s.deviceAliases.Add(networkID, DeviceAlias{OldName: oldName, CurrentName: currentName, Source: aliasSourceManual}); err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Recorded alias: %s is now %s in network %s.", oldName, currentName, networkID))), nil
+}
+
+// detectDeviceRenamesTool compares the inventories of two snapshots and records renames
+func (s *ForwardMCPService) detectDeviceRenamesTool(args DetectDeviceRenamesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("detect_device_renames", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	fromSnapshot, toSnapshot := args.FromSnapshotID, args.ToSnapshotID
+	if fromSnapshot == "" {
+		snapshots, err := s.forwardClient.GetSnapshots(networkID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list snapshots: %w", err)
+		}
+		var processed []forward.Snapshot
+		for _, snapshot := range snapshots {
+			if !snapshot.IsDraft && (snapshot.State == "" || strings.EqualFold(snapshot.State, "PROCESSED")) {
+				processed = append(processed, snapshot)
+			}
+		}
+		sort.Slice(processed, func(i, j int) bool { return processed[i].CreationDateMillis > processed[j].CreationDateMillis })
+
+		// The source is the newest snapshot older than the target (the latest by default)
+		target := 0
+		if toSnapshot != "" {
+			target = -1
+			for i, snapshot := range processed {
+				if snapshot.ID == toSnapshot {
+					target = i
+					break
+				}
+			}
+			if target < 0 {
+				return nil, fmt.Errorf("snapshot %s not found in network %s", toSnapshot, networkID)
+			}
+		}
+		if target+1 >= len(processed) {
+			return nil, fmt.Errorf("network %s has no processed snapshot before %s to compare with", networkID, prefixIndexSnapshotKey(toSnapshot))
+		}
+		fromSnapshot = processed[target+1].ID
+		if toSnapshot == "" {
+			toSnapshot = processed[0].ID
+		}
+	}
+
+	previous, err := s.getNetworkDevices(networkID, fromSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices for snapshot %s: %w", fromSnapshot, err)
+	}
+	current, err := s.getNetworkDevices(networkID, toSnapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices for snapshot %s: %w", prefixIndexSnapshotKey(toSnapshot), err)
+	}
+
+	renames := detectDeviceRenames(identitiesOf(previous), identitiesOf(current))
+	recorded := 0
+	if !args.DryRun && s.deviceAliases != nil {
+		for _, rename := range renames {
+			if err := s.deviceAliases.Add(networkID, DeviceAlias{
+				OldName:      rename.OldName,
+				CurrentName:  rename.NewName,
+				Source:       aliasSourceSnapshot,
+				MatchedOn:    rename.MatchedOn,
+				FromSnapshot: fromSnapshot,
+				ToSnapshot:   prefixIndexSnapshotKey(toSnapshot),
+			}); err != nil {
+				return nil, err
+			}
+			recorded++
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Compared snapshot %s (%d devices) with %s (%d devices): %d renames detected",
+		fromSnapshot, len(previous), prefixIndexSnapshotKey(toSnapshot), len(current), len(renames)))
+	if recorded > 0 {
+		out.WriteString(fmt.Sprintf(", %d aliases recorded", recorded))
+	}
+	out.WriteString(".\n")
+	if len(renames) > 0 {
+		out.WriteString("\n| Former Name | New Name | Matched On |\n|---|---|---|\n")
+		for _, rename := range renames {
+			out.WriteString(fmt.Sprintf("| %s | %s | %s |\n", rename.OldName, rename.NewName, rename.MatchedOn))
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(out.String())), nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+func newTestDeviceAliasStore(t *testing.T) *DeviceAliasStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewDeviceAliasStore(db, "test", logger.New())
+	if err != nil {
+		t.Fatalf("Failed to create alias store: %v", err)
+	}
+	return store
+}
+
+// snapshotDeviceClient serves a different inventory per snapshot
+type snapshotDeviceClient struct {
+	*MockForwardClient
+	inventories map[string][]forward.Device
+}
+
+func (c *snapshotDeviceClient) GetDevices(networkID string, params *forward.DeviceQueryParams) (*forward.DeviceResponse, error) {
+	devices := c.inventories[params.SnapshotID]
+	return &forward.DeviceResponse{Devices: devices, TotalCount: len(devices)}, nil
+}
+
+func TestDetectDeviceRenames(t *testing.T) {
+	previous := identitiesOf([]forward.Device{
+		{Name: "nyc-core-1", SerialNumber: "SN100"},
+		{Name: "nyc-edge-1", ManagementIPs: []string{"10.0.0.2"}},
+		{Name: "lab-sw-1", Interfaces: []forward.DeviceInterface{
+			{IPAddress: "10.9.0.1/24"}, {IPAddress: "10.9.1.1/24"}, {IPAddress: "10.9.2.1/24"},
+		}},
+		{Name: "retired-1", SerialNumber: "SN999"},
+		{Name: "unchanged", SerialNumber: "SN200"},
+	})
+	current := identitiesOf([]forward.Device{
+		{Name: "us-nyc-core-01", SerialNumber: "sn100"},
+		{Name: "us-nyc-edge-01", ManagementIPs: []string{"10.0.0.2"}},
+		{Name: "lab-switch-01", Interfaces: []forward.DeviceInterface{
+			{IPAddress: "10.9.0.1/24"}, {IPAddress: "10.9.1.1/24"}, {IPAddress: "10.9.3.1/24"},
+		}},
+		{Name: "brand-new", SerialNumber: "SN300"},
+		{Name: "unchanged", SerialNumber: "SN200"},
+	})
+
+	renames := detectDeviceRenames(previous, current)
+	expected := map[string]DeviceRename{
+		"lab-sw-1":   {OldName: "lab-sw-1", NewName: "lab-switch-01", MatchedOn: "interface_ips"},
+		"nyc-core-1": {OldName: "nyc-core-1", NewName: "us-nyc-core-01", MatchedOn: "serial_number"},
+		"nyc-edge-1": {OldName: "nyc-edge-1", NewName: "us-nyc-edge-01", MatchedOn: "management_ip"},
+	}
+	if len(renames) != len(expected) {
+		t.Fatalf("Expected %d renames, got %+v", len(expected), renames)
+	}
+	for _, rename := range renames {
+		if expected[rename.OldName] != rename {
+			t.Errorf("Unexpected rename %+v", rename)
+		}
+	}
+}
+
+func TestDeviceAliasStoreChains(t *testing.T) {
+	store := newTestDeviceAliasStore(t)
+
+	if err := store.Add("net-1", DeviceAlias{OldName: "a", CurrentName: "b", Source: aliasSourceManual}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if err := store.Add("net-1", DeviceAlias{OldName: "b", CurrentName: "c", Source: aliasSourceManual}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if current, renamed := store.Resolve("net-1", "a"); !renamed || current != "c" {
+		t.Errorf("Expected a to resolve to c, got %s (%v)", current, renamed)
+	}
+	if _, renamed := store.Resolve("net-2", "a"); renamed {
+		t.Error("Expected aliases to be scoped to their network")
+	}
+
+	// Chains are collapsed so every alias points at the current name
+	aliases, err := store.List("net-1", "")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	for _, alias := range aliases {
+		if alias.CurrentName != "c" {
+			t.Errorf("Expected collapsed alias to point at c, got %+v", alias)
+		}
+	}
+
+	// Renaming back to a former name drops the alias for that name
+	if err := store.Add("net-1", DeviceAlias{OldName: "c", CurrentName: "a", Source: aliasSourceManual}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if current, _ := store.Resolve("net-1", "a"); current != "a" {
+		t.Errorf("Expected a to be current again, got %s", current)
+	}
+	if current, _ := store.Resolve("net-1", "b"); current != "a" {
+		t.Errorf("Expected b to resolve to a, got %s", current)
+	}
+
+	if removed, err := store.Remove("net-1", "b"); err != nil || !removed {
+		t.Errorf("Expected alias for b to be removed, got %v, %v", removed, err)
+	}
+	if err := store.Add("net-1", DeviceAlias{OldName: "x", CurrentName: "x"}); err == nil {
+		t.Error("Expected an error for a self alias")
+	}
+}
+
+func TestDeviceAliasObserveAndResolve(t *testing.T) {
+	service := createTestService()
+	service.deviceAliases = newTestDeviceAliasStore(t)
+	service.deviceCache = nil
+	mock := service.forwardClient.(*MockForwardClient)
+	client := &snapshotDeviceClient{MockForwardClient: mock, inventories: map[string][]forward.Device{
+		"": {{Name: "core-1", SerialNumber: "SN1", ManagementIPs: []string{"10.0.0.1"}}},
+	}}
+	service.forwardClient = client
+
+	if _, err := service.getNetworkDevices("162112", ""); err != nil {
+		t.Fatalf("Failed to load devices: %v", err)
+	}
+
+	// The device is renamed in the next snapshot
+	client.inventories[""] = []forward.Device{{Name: "dc1-core-01", SerialNumber: "SN1", ManagementIPs: []string{"10.0.0.1"}}}
+	if _, err := service.getNetworkDevices("162112", ""); err != nil {
+		t.Fatalf("Failed to load devices: %v", err)
+	}
+
+	ip, err := service.resolveDeviceToIP("162112", "core-1")
+	if err != nil || ip != "10.0.0.1" {
+		t.Errorf("Expected former name to resolve to 10.0.0.1, got %q, %v", ip, err)
+	}
+
+	response, err := service.listDeviceAliases(ListDeviceAliasesArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "| core-1 | dc1-core-01 | snapshot_diff | serial_number |") {
+		t.Errorf("Unexpected alias listing: %s", text)
+	}
+
+	// Pinned historical snapshots never record reverse renames
+	client.inventories["snap-old"] = []forward.Device{{Name: "core-1", SerialNumber: "SN1"}}
+	if _, err := service.getNetworkDevices("162112", "snap-old"); err != nil {
+		t.Fatalf("Failed to load devices: %v", err)
+	}
+	if current, _ := service.deviceAliases.Resolve("162112", "dc1-core-01"); current != "dc1-core-01" {
+		t.Errorf("Expected no reverse alias, got %s", current)
+	}
+}
+
+func TestDetectDeviceRenamesTool(t *testing.T) {
+	service := createTestService()
+	service.deviceAliases = newTestDeviceAliasStore(t)
+	service.deviceCache = nil
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.snapshots = []forward.Snapshot{
+		{ID: "snap-1", State: "PROCESSED", CreationDateMillis: 1000},
+		{ID: "snap-3", State: "PROCESSED", CreationDateMillis: 3000},
+		{ID: "snap-2", State: "PROCESSED", CreationDateMillis: 2000},
+	}
+	service.forwardClient = &snapshotDeviceClient{MockForwardClient: mock, inventories: map[string][]forward.Device{
+		"snap-2": {{Name: "fw-1", SerialNumber: "FW1"}},
+		"snap-3": {{Name: "dc1-fw-01", SerialNumber: "FW1"}},
+	}}
+
+	response, err := service.detectDeviceRenamesTool(DetectDeviceRenamesArgs{NetworkID: "162112", DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Compared snapshot snap-2 (1 devices) with snap-3 (1 devices): 1 renames detected.") {
+		t.Errorf("Unexpected report: %s", text)
+	}
+	if _, renamed := service.deviceAliases.Resolve("162112", "fw-1"); renamed {
+		t.Error("Expected dry run not to record aliases")
+	}
+
+	if _, err := service.detectDeviceRenamesTool(DetectDeviceRenamesArgs{NetworkID: "162112"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if current, _ := service.deviceAliases.Resolve("162112", "fw-1"); current != "dc1-fw-01" {
+		t.Errorf("Expected fw-1 to resolve to dc1-fw-01, got %s", current)
+	}
+
+	if _, err := service.detectDeviceRenamesTool(DetectDeviceRenamesArgs{NetworkID: "162112", ToSnapshotID: "snap-1"}); err == nil {
+		t.Error("Expected an error when no snapshot precedes the target")
+	}
+}
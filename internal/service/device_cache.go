@@ -0,0 +1,243 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// defaultDeviceCacheLatestTTL applies to inventories of the latest snapshot, which changes
+// whenever the network is re-processed
+const defaultDeviceCacheLatestTTL = 5 * time.Minute
+
+// DeviceCacheEntry holds the device inventory of one network snapshot
+type DeviceCacheEntry struct {
+	NetworkID  string
+	SnapshotID string
+	Devices    []forward.Device
+	FetchedAt  time.Time
+	ExpiresAt  time.Time
+	HitCount   int64
+}
+
+// DeviceCache is a read-through cache of network device inventories keyed by network and
+// snapshot. Inventories of a pinned snapshot never change, so they live for pinnedTTL;
+// the latest snapshot's inventory expires after latestTTL.
+type DeviceCache struct {
+	entries   map[string]*DeviceCacheEntry
+	mutex     sync.Mutex
+	pinnedTTL time.Duration
+	latestTTL time.Duration
+	hits      int64
+	misses    int64
+	refreshes int64
+	now       func() time.Time
+}
+
+// NewDeviceCache creates a new device inventory cache
+func NewDeviceCache(pinnedTTL, latestTTL time.Duration) *DeviceCache {
+	if pinnedTTL <= 0 {
+		pinnedTTL = 24 * time.Hour
+	}
+	if latestTTL <= 0 {
+		latestTTL = defaultDeviceCacheLatestTTL
+	}
+	return &DeviceCache{
+		entries:   make(map[string]*DeviceCacheEntry),
+		pinnedTTL: pinnedTTL,
+		latestTTL: latestTTL,
+		now:       time.Now,
+	}
+}
+
+func deviceCacheKey(networkID, snapshotID string) string {
+	if snapshotID == "" {
+		snapshotID = "latest"
+	}
+	return networkID + "|" + snapshotID
+}
+
+// Get returns the cached inventory for a network snapshot, recording a hit or miss
+func (dc *DeviceCache) Get(networkID, snapshotID string) ([]forward.Device, bool) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	key := deviceCacheKey(networkID, snapshotID)
+	entry, exists := dc.entries[key]
+	if exists && dc.now().After(entry.ExpiresAt) {
+		delete(dc.entries, key)
+		exists = false
+	}
+	if !exists {
+		dc.misses++
+		return nil, false
+	}
+
+	dc.hits++
+	entry.HitCount++
+	return entry.Devices, true
+}
+
+// Contains reports whether a fresh inventory is cached, without counting a hit or miss
+func (dc *DeviceCache) Contains(networkID, snapshotID string) bool {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	entry, exists := dc.entries[deviceCacheKey(networkID, snapshotID)]
+	return exists && !dc.now().After(entry.ExpiresAt)
+}
+
+// Put stores the inventory of a network snapshot
+func (dc *DeviceCache) Put(networkID, snapshotID string, devices []forward.Device) {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	ttl := dc.pinnedTTL
+	if snapshotID == "" || snapshotID == "latest" {
+		ttl = dc.latestTTL
+	}
+	now := dc.now()
+	dc.entries[deviceCacheKey(networkID, snapshotID)] = &DeviceCacheEntry{
+		NetworkID:  networkID,
+		SnapshotID: snapshotID,
+		Devices:    devices,
+		FetchedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+}
+
+// Invalidate removes every cached snapshot of a network, or all networks when networkID is
+// empty, and returns how many inventories were removed
+func (dc *DeviceCache) Invalidate(networkID string) int {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	removed := 0
+	for key, entry := range dc.entries {
+		if networkID == "" || entry.NetworkID == networkID {
+			delete(dc.entries, key)
+			removed++
+		}
+	}
+	dc.refreshes++
+	return removed
+}
+
+// ClearExpired removes expired inventories and returns how many were removed
+func (dc *DeviceCache) ClearExpired() int {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	removed := 0
+	now := dc.now()
+	for key, entry := range dc.entries {
+		if now.After(entry.ExpiresAt) {
+			delete(dc.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// GetStats returns device cache statistics
+func (dc *DeviceCache) GetStats() map[string]interface{} {
+	dc.mutex.Lock()
+	defer dc.mutex.Unlock()
+
+	total := dc.hits + dc.misses
+	hitRate := float64(0)
+	if total > 0 {
+		hitRate = float64(dc.hits) / float64(total) * 100
+	}
+	devices := 0
+	for _, entry := range dc.entries {
+		devices += len(entry.Devices)
+	}
+
+	return map[string]interface{}{
+		"cached_inventories": len(dc.entries),
+		"cached_devices":     devices,
+		"cache_hits":         dc.hits,
+		"cache_misses":       dc.misses,
+		"refreshes":          dc.refreshes,
+		"hit_rate_percent":   fmt.Sprintf("%.2f", hitRate),
+		"pinned_ttl_hours":   dc.pinnedTTL.Hours(),
+		"latest_ttl_minutes": dc.latestTTL.Minutes(),
+	}
+}
+
+// getNetworkDevices returns the full device inventory of a network snapshot, served from the
+// device cache while it is fresh
+func (s *ForwardMCPService) getNetworkDevices(networkID, snapshotID string) ([]forward.Device, error) {
+	if s.deviceCache != nil {
+		if devices, found := s.deviceCache.Get(networkID, snapshotID); found {
+			return devices, nil
+		}
+	}
+
+	response, err := s.forwardClient.GetDevices(networkID, &forward.DeviceQueryParams{SnapshotID: snapshotID})
+	if err != nil {
+		return nil, err
+	}
+	var devices []forward.Device
+	if response != nil {
+		devices = response.Devices
+		if response.Truncated {
+			s.logger.Warn("Device inventory of network %s was truncated at %d devices", networkID, len(devices))
+		}
+	}
+	if s.deviceCache != nil {
+		s.deviceCache.Put(networkID, snapshotID, devices)
+	}
+	s.observeDeviceRenames(networkID, snapshotID, devices)
+	return devices, nil
+}
+
+// refreshDeviceCache drops cached inventories and optionally reloads one network
+func (s *ForwardMCPService) refreshDeviceCache(args RefreshDeviceCacheArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("refresh_device_cache", args, nil)
+
+	if s.deviceCache == nil {
+		return nil, fmt.Errorf("device cache is disabled (FORWARD_DEVICE_CACHE_TTL_SECONDS=0)")
+	}
+
+	if args.AllNetworks {
+		removed := s.deviceCache.Invalidate("")
+		s.invalidatePrefixIndex("")
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Cleared %d cached device inventories across all networks.", removed))), nil
+	}
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	removed := s.deviceCache.Invalidate(networkID)
+	s.invalidatePrefixIndex(networkID)
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload devices for network %s: %w", networkID, err)
+	}
+
+	snapshotLabel := snapshotID
+	if snapshotLabel == "" {
+		snapshotLabel = "latest"
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"Refreshed device cache for network %s (snapshot %s): cleared %d cached inventories, loaded %d devices.",
+		networkID, snapshotLabel, removed, len(devices)))), nil
+}
+
+// invalidatePrefixIndex drops persisted prefix indexes derived from refreshed inventories
+func (s *ForwardMCPService) invalidatePrefixIndex(networkID string) {
+	if s.prefixIndex == nil {
+		return
+	}
+	if err := s.prefixIndex.Invalidate(networkID); err != nil {
+		s.logger.Warn("Failed to invalidate prefix index: %v", err)
+	}
+}
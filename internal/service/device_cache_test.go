@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// countingDeviceClient counts inventory fetches that reach the API
+type countingDeviceClient struct {
+	*MockForwardClient
+	deviceCalls int
+}
+
+func (c *countingDeviceClient) GetDevices(networkID string, params *forward.DeviceQueryParams) (*forward.DeviceResponse, error) {
+	c.deviceCalls++
+	return c.MockForwardClient.GetDevices(networkID, params)
+}
+
+func TestDeviceCacheExpiry(t *testing.T) {
+	cache := NewDeviceCache(time.Hour, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	devices := []forward.Device{{Name: "core-1"}}
+	cache.Put("net-1", "", devices)
+	cache.Put("net-1", "snap-1", devices)
+	cache.Put("net-2", "", devices)
+
+	if _, found := cache.Get("net-1", "latest"); !found {
+		t.Error("Expected latest inventory to be cached")
+	}
+	now = now.Add(2 * time.Minute)
+	if _, found := cache.Get("net-1", ""); found {
+		t.Error("Expected latest inventory to expire after the latest TTL")
+	}
+	if _, found := cache.Get("net-1", "snap-1"); !found {
+		t.Error("Expected pinned snapshot inventory to outlive the latest TTL")
+	}
+
+	if removed := cache.Invalidate("net-1"); removed != 1 {
+		t.Errorf("Expected 1 remaining net-1 inventory to be removed, got %d", removed)
+	}
+	stats := cache.GetStats()
+	if stats["cache_hits"] != int64(2) || stats["cache_misses"] != int64(1) || stats["cached_inventories"] != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestDeviceCacheReadThrough(t *testing.T) {
+	service := createTestService()
+	client := &countingDeviceClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	client.devices = []forward.Device{
+		{Name: "core-1", ManagementIPs: []string{"10.0.0.1"}},
+		{Name: "edge-1", ManagementIPs: []string{"10.0.0.2"}},
+		{Name: "tgw-1", Type: "AWS_TRANSIT_GATEWAY"},
+	}
+	service.forwardClient = client
+	service.deviceCache = NewDeviceCache(time.Hour, time.Minute)
+
+	for _, device := range []string{"core-1", "edge-1"} {
+		if _, err := service.resolveDeviceToIP("162112", device); err != nil {
+			t.Fatalf("Failed to resolve %s: %v", device, err)
+		}
+	}
+	classes, err := service.classifyNetworkDevices("162112", "")
+	if err != nil {
+		t.Fatalf("Failed to classify devices: %v", err)
+	}
+	if classes["tgw-1"].Class != deviceClassCloud || classes["core-1"].Class == deviceClassCloud {
+		t.Errorf("Expected only tgw-1 to be detected as a cloud device, got %v", classes)
+	}
+	if client.deviceCalls != 1 {
+		t.Errorf("Expected one inventory fetch for the network, got %d", client.deviceCalls)
+	}
+
+	response, err := service.refreshDeviceCache(RefreshDeviceCacheArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "loaded 3 devices") {
+		t.Errorf("Unexpected refresh response: %s", text)
+	}
+	if client.deviceCalls != 2 {
+		t.Errorf("Expected refresh to reload the inventory, got %d fetches", client.deviceCalls)
+	}
+
+	stats, err := service.getCacheStats(GetCacheStatsArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text := stats.Content[0].TextContent.Text; !strings.Contains(text, "Device Cache:") || !strings.Contains(text, "Refreshes: 1") {
+		t.Errorf("Expected device cache stats, got:\n%s", text)
+	}
+}
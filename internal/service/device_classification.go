@@ -0,0 +1,219 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Device classes reported by classifyDevice
+const (
+	deviceClassPhysical = "physical"
+	deviceClassCloud    = "cloud"
+	deviceClassVirtual  = "virtual"
+	deviceClassUnknown  = "unknown"
+)
+
+// Classification sources, from most to least reliable
+const (
+	classificationSourceMetadata = "metadata"
+	classificationSourceName     = "name"
+)
+
+// DeviceClassification describes whether a device is physical, a cloud resource or a
+// virtual appliance, and what the decision was based on
+type DeviceClassification struct {
+	Device   string `json:"device"`
+	Class    string `json:"class"`
+	Provider string `json:"provider,omitempty"`
+	Source   string `json:"source"`
+	Reason   string `json:"reason"`
+}
+
+// Movable reports whether the device can be assigned to a physical location. Only cloud
+// resources cannot; virtual appliances run on hosts that sit in a site.
+func (c DeviceClassification) Movable() bool {
+	return c.Class != deviceClassCloud
+}
+
+// cloudProviderMarkers map substrings of the device type or vendor to a cloud provider
+var cloudProviderMarkers = []struct {
+	marker   string
+	provider string
+}{
+	{"AWS", "aws"},
+	{"AMAZON", "aws"},
+	{"AZURE", "azure"},
+	{"GCP", "gcp"},
+	{"GOOGLE", "gcp"},
+	{"OCI", "oci"},
+	{"ORACLE_CLOUD", "oci"},
+}
+
+// virtualTypeMarkers identify virtualized device types
+var virtualTypeMarkers = []string{"VIRTUAL", "HYPERVISOR", "VSWITCH", "VM", "CONTAINER"}
+
+// virtualPlatformMarkers identify virtual appliance platforms and models
+var virtualPlatformMarkers = []string{
+	"csr1000v", "csr1kv", "c8000v", "cat8000v", "xrv", "nxosv", "asav", "ftdv",
+	"vmx", "vsrx", "vqfx", "vm-series", "vm_series", "veos", "ceos", "vmanage", "vedge",
+	"fortigate-vm", "fortigate_vm", "nsx", "1000v", "virtual",
+}
+
+// classifyDevice classifies a device from its inventory Type, Vendor, Platform and Model.
+// Devices without any of these fields fall back to the name-based isCloudDevice heuristic.
+func (s *ForwardMCPService) classifyDevice(device forward.Device) DeviceClassification {
+	classification := DeviceClassification{Device: device.Name, Source: classificationSourceMetadata}
+
+	deviceType := strings.ToUpper(device.Type)
+	vendor := strings.ToUpper(device.Vendor)
+	platform := strings.ToLower(device.Platform + " " + device.Model)
+
+	if deviceType == "" && vendor == "" && strings.TrimSpace(platform) == "" {
+		classification.Source = classificationSourceName
+		if s.isCloudDevice(device.Name) {
+			classification.Class = deviceClassCloud
+			classification.Reason = "device name matches cloud/virtual naming patterns"
+		} else {
+			classification.Class = deviceClassUnknown
+			classification.Reason = "no inventory metadata and name does not look like a cloud device"
+		}
+		return classification
+	}
+
+	for _, candidate := range cloudProviderMarkers {
+		if strings.Contains(deviceType, candidate.marker) || strings.Contains(vendor, candidate.marker) {
+			classification.Class = deviceClassCloud
+			classification.Provider = candidate.provider
+			classification.Reason = fmt.Sprintf("type %q / vendor %q identify a %s resource", device.Type, device.Vendor, candidate.provider)
+			return classification
+		}
+	}
+	if strings.Contains(deviceType, "CLOUD") || strings.Contains(deviceType, "VPC") || strings.Contains(deviceType, "VNET") {
+		classification.Class = deviceClassCloud
+		classification.Reason = fmt.Sprintf("type %q is a cloud resource", device.Type)
+		return classification
+	}
+
+	for _, marker := range virtualTypeMarkers {
+		if containsToken(deviceType, marker) {
+			classification.Class = deviceClassVirtual
+			classification.Reason = fmt.Sprintf("type %q is virtualized", device.Type)
+			return classification
+		}
+	}
+	for _, marker := range virtualPlatformMarkers {
+		if strings.Contains(platform, marker) {
+			classification.Class = deviceClassVirtual
+			classification.Reason = fmt.Sprintf("platform/model %q is a virtual appliance", strings.TrimSpace(device.Platform+" "+device.Model))
+			return classification
+		}
+	}
+
+	classification.Class = deviceClassPhysical
+	classification.Reason = "inventory metadata describes a physical device"
+	return classification
+}
+
+// containsToken reports whether token appears as a whole word of an underscore-separated
+// type name, so "VM" matches "VM_HOST" but not "VMX_ROUTER"
+func containsToken(value, token string) bool {
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == '_' || r == '-' || r == ' ' }) {
+		if part == token {
+			return true
+		}
+	}
+	return false
+}
+
+// classifyNetworkDevices classifies every device in a network's inventory by name
+func (s *ForwardMCPService) classifyNetworkDevices(networkID, snapshotID string) (map[string]DeviceClassification, error) {
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, err
+	}
+	classifications := make(map[string]DeviceClassification, len(devices))
+	for _, device := range devices {
+		classifications[device.Name] = s.classifyDevice(device)
+	}
+	return classifications, nil
+}
+
+// classifyDeviceNames classifies named devices, using inventory metadata when available and
+// falling back to name heuristics for devices missing from the inventory
+func (s *ForwardMCPService) classifyDeviceNames(networkID string, names []string) []DeviceClassification {
+	inventory, err := s.classifyNetworkDevices(networkID, "")
+	if err != nil {
+		s.logger.Debug("Could not load inventory for device classification: %v", err)
+	}
+
+	classifications := make([]DeviceClassification, 0, len(names))
+	for _, name := range names {
+		if classification, found := inventory[name]; found {
+			classifications = append(classifications, classification)
+			continue
+		}
+		// A former name classifies like the renamed device but keeps the requested name
+		if s.deviceAliases != nil {
+			if current, renamed := s.deviceAliases.Resolve(networkID, name); renamed {
+				if classification, found := inventory[current]; found {
+					classification.Device = name
+					classifications = append(classifications, classification)
+					continue
+				}
+			}
+		}
+		classifications = append(classifications, s.classifyDevice(forward.Device{Name: name}))
+	}
+	return classifications
+}
+
+// classifyDevices reports the physical/cloud/virtual classification of network devices
+func (s *ForwardMCPService) classifyDevices(args ClassifyDevicesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("classify_devices", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	var classifications []DeviceClassification
+	if len(args.Devices) > 0 {
+		classifications = s.classifyDeviceNames(networkID, args.Devices)
+	} else {
+		inventory, err := s.classifyNetworkDevices(networkID, snapshotID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load devices for network %s: %w", networkID, err)
+		}
+		for _, classification := range inventory {
+			classifications = append(classifications, classification)
+		}
+	}
+
+	classFilter := strings.ToLower(strings.TrimSpace(args.Class))
+	counts := make(map[string]int)
+	var filtered []DeviceClassification
+	for _, classification := range classifications {
+		counts[classification.Class]++
+		if classFilter == "" || classification.Class == classFilter {
+			filtered = append(filtered, classification)
+		}
+	}
+	sort.Slice(filtered, func(i, j int) bool { return filtered[i].Device < filtered[j].Device })
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Classified %d devices in network %s: %d physical, %d cloud, %d virtual, %d unknown\n",
+		len(classifications), networkID, counts[deviceClassPhysical], counts[deviceClassCloud], counts[deviceClassVirtual], counts[deviceClassUnknown]))
+	if len(filtered) > 0 {
+		out.WriteString("\n| Device | Class | Provider | Source | Reason |\n|---|---|---|---|---|\n")
+		for _, classification := range filtered {
+			out.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", classification.Device, classification.Class,
+				classification.Provider, classification.Source, classification.Reason))
+		}
+	}
+	return s.streamResponse("classify_devices", out.String()), nil
+}
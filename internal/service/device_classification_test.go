@@ -0,0 +1,109 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestClassifyDevice(t *testing.T) {
+	service := createTestService()
+
+	tests := []struct {
+		device   forward.Device
+		class    string
+		provider string
+		source   string
+	}{
+		{forward.Device{Name: "tgw-1", Type: "AWS_TRANSIT_GATEWAY"}, deviceClassCloud, "aws", classificationSourceMetadata},
+		{forward.Device{Name: "vnet-gw", Type: "AZURE_VNET_GATEWAY"}, deviceClassCloud, "azure", classificationSourceMetadata},
+		{forward.Device{Name: "edge", Type: "ROUTER", Vendor: "GOOGLE"}, deviceClassCloud, "gcp", classificationSourceMetadata},
+		{forward.Device{Name: "csr-edge", Type: "ROUTER", Vendor: "CISCO", Platform: "csr1000v"}, deviceClassVirtual, "", classificationSourceMetadata},
+		{forward.Device{Name: "fw-1", Type: "FIREWALL", Vendor: "PALO_ALTO_NETWORKS", Model: "PA-VM-SERIES"}, deviceClassVirtual, "", classificationSourceMetadata},
+		{forward.Device{Name: "host-1", Type: "HYPERVISOR"}, deviceClassVirtual, "", classificationSourceMetadata},
+		// Physical infrastructure whose names merely contain cloud keywords
+		{forward.Device{Name: "fel-wps1-cloud2s02", Type: "SWITCH", Vendor: "ARISTA", Platform: "eos"}, deviceClassPhysical, "", classificationSourceMetadata},
+		{forward.Device{Name: "aws-core-1", Type: "ROUTER", Vendor: "CISCO", Platform: "ios_xe"}, deviceClassPhysical, "", classificationSourceMetadata},
+		{forward.Device{Name: "vmx-lab", Type: "ROUTER", Vendor: "JUNIPER", Platform: "junos"}, deviceClassPhysical, "", classificationSourceMetadata},
+		// No metadata falls back to name heuristics
+		{forward.Device{Name: "azure-vm-01"}, deviceClassCloud, "", classificationSourceName},
+		{forward.Device{Name: "core-router-01"}, deviceClassUnknown, "", classificationSourceName},
+	}
+
+	for _, tt := range tests {
+		got := service.classifyDevice(tt.device)
+		if got.Class != tt.class || got.Provider != tt.provider || got.Source != tt.source {
+			t.Errorf("classifyDevice(%s) = %+v; want class %s, provider %q, source %s",
+				tt.device.Name, got, tt.class, tt.provider, tt.source)
+		}
+		if got.Reason == "" {
+			t.Errorf("classifyDevice(%s) returned no reason", tt.device.Name)
+		}
+		// Only cloud resources cannot be placed in a location
+		if got.Movable() != (tt.class != deviceClassCloud) {
+			t.Errorf("classifyDevice(%s).Movable() = %v for class %s", tt.device.Name, got.Movable(), got.Class)
+		}
+	}
+}
+
+func TestClassifyDevicesTool(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	client.devices = []forward.Device{
+		{Name: "core-1", Type: "ROUTER", Vendor: "CISCO", Platform: "ios_xe"},
+		{Name: "tgw-1", Type: "AWS_TRANSIT_GATEWAY"},
+		{Name: "csr-1", Type: "ROUTER", Vendor: "CISCO", Platform: "csr1000v"},
+	}
+
+	response, err := service.classifyDevices(ClassifyDevicesArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Classified 3 devices in network 162112: 1 physical, 1 cloud, 1 virtual, 0 unknown") {
+		t.Errorf("Unexpected summary: %s", text)
+	}
+
+	response, err = service.classifyDevices(ClassifyDevicesArgs{NetworkID: "162112", Class: "cloud"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !strings.Contains(text, "| tgw-1 | cloud | aws |") || strings.Contains(text, "| core-1 |") {
+		t.Errorf("Expected only cloud devices to be listed, got: %s", text)
+	}
+
+	// Named devices missing from the inventory are classified by name
+	response, err = service.classifyDevices(ClassifyDevicesArgs{NetworkID: "162112", Devices: []string{"core-1", "gcp-compute-01"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "| gcp-compute-01 | cloud |  | name |") {
+		t.Errorf("Expected name-based classification for unknown device, got: %s", text)
+	}
+}
+
+func TestUpdateDeviceLocationsUsesClassification(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	client.devices = []forward.Device{
+		{Name: "fel-wps1-cloud2s02", Type: "SWITCH", Vendor: "ARISTA", Platform: "eos"},
+		{Name: "edge-1", Type: "ROUTER", Vendor: "CISCO", Platform: "csr1000v"},
+	}
+
+	response, err := service.updateDeviceLocations(UpdateDeviceLocationsArgs{
+		NetworkID: "162112",
+		Locations: map[string]string{"fel-wps1-cloud2s02": "dc1", "edge-1": "dc1", "aws-ec2-01": "dc1"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// The virtual router is placed with the switch; only the cloud instance is excluded
+	if len(client.deviceLocations) != 2 || client.deviceLocations["fel-wps1-cloud2s02"] != "dc1" || client.deviceLocations["edge-1"] != "dc1" {
+		t.Errorf("Expected the physical switch and the virtual router to be moved, got %v", client.deviceLocations)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "1 cloud devices were excluded") {
+		t.Errorf("Unexpected response: %s", text)
+	}
+}
@@ -0,0 +1,500 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	deviceTagType      = "device_tag"
+	deviceEntityType   = "device"
+	relationTaggedAs   = "tagged_as" // device → tag
+	maxTagSampleShown  = 10
+	maxTagRulesPerList = 500
+	maxTagPathSources  = 50 // Devices one from_tag may expand to in a bulk path search
+)
+
+// tagRuleFields read the inventory attribute a condition compares
+var tagRuleFields = map[string]func(device forward.Device) []string{
+	"name":          func(d forward.Device) []string { return []string{d.Name} },
+	"hostname":      func(d forward.Device) []string { return []string{d.Hostname} },
+	"platform":      func(d forward.Device) []string { return []string{d.Platform} },
+	"vendor":        func(d forward.Device) []string { return []string{d.Vendor} },
+	"model":         func(d forward.Device) []string { return []string{d.Model} },
+	"type":          func(d forward.Device) []string { return []string{d.Type} },
+	"os_version":    func(d forward.Device) []string { return []string{d.OSVersion, d.Version} },
+	"location":      func(d forward.Device) []string { return []string{d.LocationID} },
+	"management_ip": func(d forward.Device) []string { return d.ManagementIPs },
+}
+
+// tagCondition is one comparison of a tag rule, e.g. platform contains 'nxos'
+type tagCondition struct {
+	field   string
+	op      string
+	value   string
+	negate  bool
+	pattern *regexp.Regexp // For matches
+	subnet  *net.IPNet     // For in
+}
+
+// TagRule assigns a tag to devices matching its condition: clauses joined by OR, each a list
+// of conditions joined by AND
+type TagRule struct {
+	Tag       string
+	Condition string
+	clauses   [][]tagCondition
+}
+
+// tokenizeTagCondition splits a condition into words and quoted values
+func tokenizeTagCondition(condition string) ([]string, error) {
+	var tokens []string
+	runes := []rune(condition)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote in %q", condition)
+			}
+			// Quoted values keep a marker so they are never read as keywords
+			tokens = append(tokens, "\x00"+string(runes[i+1:end]))
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '\'' && runes[end] != '"' {
+				end++
+			}
+			tokens = append(tokens, string(runes[i:end]))
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// ParseTagRule parses a condition such as "platform contains 'nxos' AND name matches 'core'".
+// Fields are name, hostname, platform, vendor, model, type, os_version, location and
+// management_ip; operators are contains, equals, starts_with, matches (regular expression) and
+// in (CIDR, for management_ip). NOT negates a comparison; AND binds tighter than OR.
+func ParseTagRule(tag, condition string) (*TagRule, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return nil, fmt.Errorf("tag is required")
+	}
+	tokens, err := tokenizeTagCondition(condition)
+	if err != nil {
+		return nil, err
+	}
+	rule := &TagRule{Tag: tag, Condition: strings.TrimSpace(condition)}
+	var clause []tagCondition
+	for i := 0; i < len(tokens); {
+		var current tagCondition
+		if strings.EqualFold(tokens[i], "not") {
+			current.negate = true
+			i++
+		}
+		if i+2 >= len(tokens) {
+			return nil, fmt.Errorf("incomplete comparison at %q; expected <field> <operator> '<value>'", strings.Join(tokens[i:], " "))
+		}
+		current.field = strings.ToLower(tokens[i])
+		current.op = strings.ToLower(tokens[i+1])
+		current.value = strings.TrimPrefix(tokens[i+2], "\x00")
+		i += 3
+		if _, ok := tagRuleFields[current.field]; !ok {
+			return nil, fmt.Errorf("unknown field %q (use name, hostname, platform, vendor, model, type, os_version, location or management_ip)", current.field)
+		}
+		switch current.op {
+		case "contains", "starts_with":
+			current.value = strings.ToLower(current.value)
+		case "equals", "=", "==", "is":
+			current.op = "equals"
+		case "matches":
+			if current.pattern, err = regexp.Compile("(?i)" + current.value); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", current.value, err)
+			}
+		case "in":
+			if _, current.subnet, err = net.ParseCIDR(current.value); err != nil {
+				return nil, fmt.Errorf("in expects a CIDR such as 10.1.0.0/16, got %q", current.value)
+			}
+		default:
+			return nil, fmt.Errorf("unknown operator %q (use contains, equals, starts_with, matches or in)", current.op)
+		}
+		clause = append(clause, current)
+
+		if i == len(tokens) {
+			break
+		}
+		switch strings.ToLower(tokens[i]) {
+		case "and":
+		case "or":
+			rule.clauses = append(rule.clauses, clause)
+			clause = nil
+		default:
+			return nil, fmt.Errorf("expected AND or OR before %q", strings.TrimPrefix(tokens[i], "\x00"))
+		}
+		i++
+		if i == len(tokens) {
+			return nil, fmt.Errorf("condition ends with %s", strings.ToUpper(tokens[i-1]))
+		}
+	}
+	if len(clause) == 0 {
+		return nil, fmt.Errorf("condition is required, e.g. platform contains 'nxos' AND name matches 'core'")
+	}
+	rule.clauses = append(rule.clauses, clause)
+	return rule, nil
+}
+
+// matches reports whether one of the field's values satisfies the comparison
+func (c tagCondition) matches(device forward.Device) bool {
+	matched := false
+	for _, value := range tagRuleFields[c.field](device) {
+		if value == "" {
+			continue
+		}
+		switch c.op {
+		case "contains":
+			matched = strings.Contains(strings.ToLower(value), c.value)
+		case "starts_with":
+			matched = strings.HasPrefix(strings.ToLower(value), c.value)
+		case "equals":
+			matched = strings.EqualFold(value, c.value)
+		case "matches":
+			matched = c.pattern.MatchString(value)
+		case "in":
+			ip := net.ParseIP(strings.Split(value, "/")[0])
+			matched = ip != nil && c.subnet.Contains(ip)
+		}
+		if matched {
+			break
+		}
+	}
+	return matched != c.negate
+}
+
+// Matches reports whether a device gets the rule's tag
+func (r *TagRule) Matches(device forward.Device) bool {
+	for _, clause := range r.clauses {
+		all := true
+		for _, condition := range clause {
+			if !condition.matches(device) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return true
+		}
+	}
+	return false
+}
+
+// loadTagRules returns the stored rules, or only the rule of tag, sorted by tag
+func (s *ForwardMCPService) loadTagRules(tag string) ([]*TagRule, error) {
+	entities, err := s.memorySystem.SearchEntities("", deviceTagType, maxTagRulesPerList)
+	if err != nil {
+		return nil, err
+	}
+	var rules []*TagRule
+	for _, entity := range entities {
+		if tag != "" && entity.Name != tag {
+			continue
+		}
+		condition := resultValueString(entity.Metadata["condition"])
+		if condition == "" {
+			continue
+		}
+		rule, err := ParseTagRule(entity.Name, condition)
+		if err != nil {
+			s.logger.Warn("Skipping invalid tag rule for %s: %v", entity.Name, err)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Tag < rules[j].Tag })
+	return rules, nil
+}
+
+// taggedDevices returns the names of devices of a network stored with tag
+func (s *ForwardMCPService) taggedDevices(networkID, tag string) ([]string, error) {
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	tagEntity, err := s.memorySystem.getEntityByNameAndType(tag, deviceTagType)
+	if err != nil {
+		return nil, fmt.Errorf("unknown tag %s; define it with set_device_tag_rule", tag)
+	}
+	relations, err := s.memorySystem.GetRelations(tagEntity.ID, relationTaggedAs)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, relation := range relations {
+		if relation.ToID != tagEntity.ID || resultValueString(relation.Properties["network_id"]) != networkID {
+			continue
+		}
+		if device, err := s.memorySystem.getEntityByID(relation.FromID); err == nil {
+			names = append(names, device.Name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no devices of network %s are tagged %s; run apply_device_tags first", networkID, tag)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// expandDeviceTags appends the devices tagged with any of tags to devices, without duplicates
+func (s *ForwardMCPService) expandDeviceTags(networkID string, devices, tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(devices))
+	for _, device := range devices {
+		seen[device] = true
+	}
+	for _, tag := range tags {
+		names, err := s.taggedDevices(networkID, tag)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				devices = append(devices, name)
+			}
+		}
+	}
+	return devices, nil
+}
+
+// expandPathSourceTags replaces each query with a from_tag by one query per tagged device
+func (s *ForwardMCPService) expandPathSourceTags(networkID string, queries []PathSearchQueryArgs) ([]PathSearchQueryArgs, error) {
+	expanded := make([]PathSearchQueryArgs, 0, len(queries))
+	for i, query := range queries {
+		if query.FromTag == "" {
+			expanded = append(expanded, query)
+			continue
+		}
+		if query.From != "" {
+			return nil, fmt.Errorf("query %d: use either 'from' or 'from_tag', not both", i+1)
+		}
+		names, err := s.taggedDevices(networkID, query.FromTag)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i+1, err)
+		}
+		if len(names) > maxTagPathSources {
+			return nil, fmt.Errorf("query %d: tag %s has %d devices; at most %d can be path sources", i+1, query.FromTag, len(names), maxTagPathSources)
+		}
+		for _, name := range names {
+			source := query
+			source.From, source.FromTag = name, ""
+			expanded = append(expanded, source)
+		}
+	}
+	return expanded, nil
+}
+
+// DeviceTagChange summarizes how applying one rule changed the stored tags
+type DeviceTagChange struct {
+	Tag     string
+	Matched []string
+	Added   int
+	Removed int
+}
+
+// applyTagRules evaluates rules over a network's inventory and stores the tags as device →
+// tag relations, removing tags from devices that no longer match
+func (s *ForwardMCPService) applyTagRules(networkID string, devices []forward.Device, rules []*TagRule, dryRun bool) ([]DeviceTagChange, error) {
+	var changes []DeviceTagChange
+	for _, rule := range rules {
+		change := DeviceTagChange{Tag: rule.Tag}
+		matched := make(map[string]bool)
+		for _, device := range devices {
+			if device.Name != "" && rule.Matches(device) {
+				matched[device.Name] = true
+				change.Matched = append(change.Matched, device.Name)
+			}
+		}
+		sort.Strings(change.Matched)
+
+		tagEntity, err := s.memorySystem.getEntityByNameAndType(rule.Tag, deviceTagType)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s has no stored rule: %w", rule.Tag, err)
+		}
+		relations, err := s.memorySystem.GetRelations(tagEntity.ID, relationTaggedAs)
+		if err != nil {
+			return nil, err
+		}
+		tagged := make(map[string]bool)
+		for _, relation := range relations {
+			if relation.ToID != tagEntity.ID || resultValueString(relation.Properties["network_id"]) != networkID {
+				continue
+			}
+			device, err := s.memorySystem.getEntityByID(relation.FromID)
+			if err == nil && matched[device.Name] {
+				tagged[device.Name] = true
+				continue
+			}
+			change.Removed++
+			if !dryRun {
+				if err := s.memorySystem.DeleteRelation(relation.ID); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		for _, name := range change.Matched {
+			if tagged[name] {
+				continue
+			}
+			change.Added++
+			if dryRun {
+				continue
+			}
+			device, err := s.memorySystem.getEntityByNameAndType(name, deviceEntityType)
+			if err != nil {
+				if device, err = s.memorySystem.CreateEntity(name, deviceEntityType, map[string]interface{}{"network_id": networkID}); err != nil {
+					return nil, err
+				}
+			}
+			if _, err := s.memorySystem.CreateRelation(device.ID, tagEntity.ID, relationTaggedAs, map[string]interface{}{
+				"network_id": networkID,
+				"condition":  rule.Condition,
+				"tagged_at":  time.Now().Unix(),
+			}); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// setDeviceTagRule creates or replaces the rule of a tag
+func (s *ForwardMCPService) setDeviceTagRule(args SetDeviceTagRuleArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("set_device_tag_rule", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	rule, err := ParseTagRule(args.Tag, args.Condition)
+	if err != nil {
+		return nil, err
+	}
+	metadata := map[string]interface{}{"condition": rule.Condition, "updated_at": time.Now().UTC().Format(time.RFC3339)}
+	if existing, err := s.memorySystem.getEntityByNameAndType(rule.Tag, deviceTagType); err == nil {
+		if err := s.memorySystem.updateEntityMetadata(existing.ID, metadata); err != nil {
+			return nil, err
+		}
+	} else if _, err := s.memorySystem.CreateEntity(rule.Tag, deviceTagType, metadata); err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"Tag rule saved: %s → %s\n\nRun apply_device_tags to tag the devices of a network; the tag can then be used with list_devices tag, analyze_network_prefixes from_tags/to_tags and search_paths_bulk from_tag.",
+		rule.Condition, rule.Tag))), nil
+}
+
+// removeDeviceTagRule deletes a tag with its rule and device links
+func (s *ForwardMCPService) removeDeviceTagRule(args RemoveDeviceTagRuleArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("remove_device_tag_rule", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	tagEntity, err := s.memorySystem.getEntityByNameAndType(strings.TrimSpace(args.Tag), deviceTagType)
+	if err != nil {
+		return nil, fmt.Errorf("unknown tag %s", args.Tag)
+	}
+	if err := s.memorySystem.DeleteEntity(tagEntity.ID); err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Removed tag %s and its device links.", tagEntity.Name))), nil
+}
+
+// applyDeviceTags evaluates tag rules over a network's inventory and stores the tags
+func (s *ForwardMCPService) applyDeviceTags(args ApplyDeviceTagsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("apply_device_tags", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	rules, err := s.loadTagRules(args.Tag)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		if args.Tag != "" {
+			return nil, fmt.Errorf("no rule for tag %s; define it with set_device_tag_rule", args.Tag)
+		}
+		return nil, fmt.Errorf("no tag rules defined; define one with set_device_tag_rule")
+	}
+	devices, err := s.getNetworkDevices(networkID, s.getSnapshotID(args.SnapshotID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	changes, err := s.applyTagRules(networkID, devices, rules, args.DryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	title := "Device Tags"
+	if args.DryRun {
+		title += " (dry run, nothing stored)"
+	}
+	text.WriteString(fmt.Sprintf("## %s: network %s\n\n%d devices evaluated against %d rules.\n\n", title, networkID, len(devices), len(rules)))
+	text.WriteString("| Tag | Rule | Devices | Added | Removed | Examples |\n|-----|------|---------|-------|---------|----------|\n")
+	for i, change := range changes {
+		examples := strings.Join(change.Matched[:min(len(change.Matched), maxTagSampleShown)], ", ")
+		if len(change.Matched) > maxTagSampleShown {
+			examples += ", …"
+		}
+		text.WriteString(fmt.Sprintf("| %s | `%s` | %d | %d | %d | %s |\n", change.Tag, rules[i].Condition, len(change.Matched), change.Added, change.Removed, examples))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// listDeviceTags lists the tag rules and, for a network, the devices in each tag
+func (s *ForwardMCPService) listDeviceTags(args ListDeviceTagsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_device_tags", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	rules, err := s.loadTagRules("")
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No device tag rules defined. Define one with set_device_tag_rule, e.g. tag=datacenter-core condition=\"platform contains 'nxos' AND name matches 'core'\".")), nil
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+
+	var text strings.Builder
+	text.WriteString("## Device Tags\n\n")
+	for _, rule := range rules {
+		text.WriteString(fmt.Sprintf("### %s\n`%s`\n", rule.Tag, rule.Condition))
+		if networkID != "" {
+			if names, err := s.taggedDevices(networkID, rule.Tag); err == nil {
+				text.WriteString(fmt.Sprintf("%d devices in network %s: %s\n", len(names), networkID, strings.Join(names, ", ")))
+			} else {
+				text.WriteString(fmt.Sprintf("No devices of network %s tagged yet.\n", networkID))
+			}
+		}
+		text.WriteString("\n")
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+var taggedTestDevices = []forward.Device{
+	{Name: "dc1-core-1", Platform: "nxos", Vendor: "CISCO", ManagementIPs: []string{"10.1.0.1"}},
+	{Name: "dc1-core-2", Platform: "NX-OS nxos", Vendor: "CISCO", ManagementIPs: []string{"10.1.0.2"}},
+	{Name: "dc1-leaf-1", Platform: "nxos", Vendor: "CISCO", ManagementIPs: []string{"10.1.1.1"}},
+	{Name: "branch-core-1", Platform: "ios_xe", Vendor: "CISCO", ManagementIPs: []string{"10.9.0.1"}},
+	{Name: "edge-fw-1", Platform: "panos", Vendor: "PALO_ALTO_NETWORKS"},
+}
+
+func TestParseTagRule(t *testing.T) {
+	tests := []struct {
+		condition string
+		want      []string
+	}{
+		{"platform contains 'nxos' AND name matches 'core'", []string{"dc1-core-1", "dc1-core-2"}},
+		{"vendor equals cisco AND NOT platform contains nxos", []string{"branch-core-1"}},
+		{"management_ip in 10.1.0.0/16 or vendor = \"palo_alto_networks\"", []string{"dc1-core-1", "dc1-core-2", "dc1-leaf-1", "edge-fw-1"}},
+		{"name starts_with 'DC1-' and name matches '-(leaf|spine)-'", []string{"dc1-leaf-1"}},
+	}
+	for _, test := range tests {
+		rule, err := ParseTagRule("test", test.condition)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %v", test.condition, err)
+		}
+		var matched []string
+		for _, device := range taggedTestDevices {
+			if rule.Matches(device) {
+				matched = append(matched, device.Name)
+			}
+		}
+		if !reflect.DeepEqual(matched, test.want) {
+			t.Errorf("%q matched %v, expected %v", test.condition, matched, test.want)
+		}
+	}
+
+	for _, condition := range []string{
+		"", "platform contains", "serial contains 'x'", "name like 'x'", "name matches '('",
+		"management_ip in 10.1.0.0", "name contains x AND", "name contains x name contains y", "name contains 'x",
+	} {
+		if _, err := ParseTagRule("test", condition); err == nil {
+			t.Errorf("Expected %q to be rejected", condition)
+		}
+	}
+	if _, err := ParseTagRule(" ", "name contains x"); err == nil {
+		t.Error("Expected an empty tag to be rejected")
+	}
+}
+
+func TestApplyDeviceTagsAndFilters(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	client.devices = taggedTestDevices
+
+	if _, err := service.setDeviceTagRule(SetDeviceTagRuleArgs{Tag: "datacenter-core", Condition: "platform contains 'nxos' AND name matches 'core'"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.listDevices(ListDevicesArgs{NetworkID: "162112", Tag: "datacenter-core"}); err == nil || !strings.Contains(err.Error(), "apply_device_tags") {
+		t.Errorf("Expected a hint to apply tags first, got %v", err)
+	}
+
+	response, err := service.applyDeviceTags(ApplyDeviceTagsArgs{NetworkID: "162112", DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "| datacenter-core |") || !strings.Contains(text, "| 2 | 2 | 0 |") {
+		t.Errorf("Unexpected dry run: %s", text)
+	}
+	if _, err := service.taggedDevices("162112", "datacenter-core"); err == nil {
+		t.Error("Expected a dry run to store nothing")
+	}
+
+	if _, err := service.applyDeviceTags(ApplyDeviceTagsArgs{NetworkID: "162112"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	names, err := service.taggedDevices("162112", "datacenter-core")
+	if err != nil || !reflect.DeepEqual(names, []string{"dc1-core-1", "dc1-core-2"}) {
+		t.Errorf("Expected the core switches tagged, got %v %v", names, err)
+	}
+
+	response, err = service.listDevices(ListDevicesArgs{NetworkID: "162112", Tag: "datacenter-core", Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Found 1 devices (total: 2)") || !strings.Contains(text, "dc1-core-2") {
+		t.Errorf("Expected the second tagged device, got %s", text)
+	}
+
+	queries, err := service.expandPathSourceTags("162112", []PathSearchQueryArgs{{FromTag: "datacenter-core", DstIP: "10.9.0.1"}, {SrcIP: "10.1.0.1", DstIP: "10.9.0.1"}})
+	if err != nil || len(queries) != 3 || queries[0].From != "dc1-core-1" || queries[1].From != "dc1-core-2" || queries[2].From != "" {
+		t.Errorf("Expected one query per tagged source, got %+v %v", queries, err)
+	}
+	if _, err := service.expandPathSourceTags("162112", []PathSearchQueryArgs{{From: "x", FromTag: "datacenter-core", DstIP: "10.9.0.1"}}); err == nil {
+		t.Error("Expected an error for from with from_tag")
+	}
+	devices, err := service.expandDeviceTags("162112", []string{"dc1-core-2", "edge-fw-1"}, []string{"datacenter-core"})
+	if err != nil || !reflect.DeepEqual(devices, []string{"dc1-core-2", "edge-fw-1", "dc1-core-1"}) {
+		t.Errorf("Expected tagged devices added without duplicates, got %v %v", devices, err)
+	}
+
+	// Narrowing the rule untags devices that no longer match
+	service.setDeviceTagRule(SetDeviceTagRuleArgs{Tag: "datacenter-core", Condition: "name equals dc1-core-1"})
+	if _, err := service.applyDeviceTags(ApplyDeviceTagsArgs{NetworkID: "162112", Tag: "datacenter-core"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if names, _ := service.taggedDevices("162112", "datacenter-core"); !reflect.DeepEqual(names, []string{"dc1-core-1"}) {
+		t.Errorf("Expected only dc1-core-1 tagged, got %v", names)
+	}
+	if names, err := service.taggedDevices("other-network", "datacenter-core"); err == nil {
+		t.Errorf("Expected tags to be per network, got %v", names)
+	}
+
+	if _, err := service.removeDeviceTagRule(RemoveDeviceTagRuleArgs{Tag: "datacenter-core"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.applyDeviceTags(ApplyDeviceTagsArgs{NetworkID: "162112"}); err == nil {
+		t.Error("Expected an error without rules")
+	}
+}
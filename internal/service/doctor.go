@@ -0,0 +1,349 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Diagnostic check outcomes
+const (
+	diagnosticOK   = "ok"
+	diagnosticWarn = "warn"
+	diagnosticFail = "fail"
+)
+
+// DiagnosticCheck is the outcome of one self-test with the steps that fix a problem
+type DiagnosticCheck struct {
+	Name        string `json:"name"`
+	Status      string `json:"status"`
+	Detail      string `json:"detail"`
+	Remediation string `json:"remediation,omitempty"`
+}
+
+// configDoctor checks the configuration and environment the server depends on
+type configDoctor struct {
+	cfg       *config.Config
+	client    forward.ClientInterface
+	embedding EmbeddingService // nil skips the embedding probe
+	dataDir   func() (string, error)
+}
+
+// run executes every check in order. Checks that need the API are skipped when the
+// configuration check fails.
+func (d *configDoctor) run() []DiagnosticCheck {
+	checks := []DiagnosticCheck{d.checkConfiguration()}
+	if checks[0].Status != diagnosticFail {
+		credentials := d.checkCredentials()
+		checks = append(checks, credentials)
+		if credentials.Status != diagnosticFail {
+			checks = append(checks, d.checkAPICompatibility(), d.checkDefaultNetwork())
+		}
+	}
+	checks = append(checks, d.checkTLSFiles(), d.checkSpecFile(), d.checkDataDirectory(), d.checkWritablePaths(), d.checkEmbeddingProvider())
+	return checks
+}
+
+func (d *configDoctor) checkConfiguration() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "Configuration", Status: diagnosticOK}
+	cfg := d.cfg.Forward
+	var problems []string
+	if cfg.APIBaseURL == "" {
+		problems = append(problems, "FORWARD_API_BASE_URL is not set")
+	} else if parsed, err := url.Parse(cfg.APIBaseURL); err != nil || parsed.Host == "" || (parsed.Scheme != "https" && parsed.Scheme != "http") {
+		problems = append(problems, fmt.Sprintf("FORWARD_API_BASE_URL %q is not an http(s) URL", cfg.APIBaseURL))
+	} else if strings.HasSuffix(strings.TrimRight(parsed.Path, "/"), "/api") {
+		problems = append(problems, "FORWARD_API_BASE_URL ends with /api, but API paths already include it")
+	}
+	if cfg.APIKey == "" || cfg.APISecret == "" {
+		problems = append(problems, "FORWARD_API_KEY or FORWARD_API_SECRET is not set")
+	}
+	if len(problems) > 0 {
+		check.Status = diagnosticFail
+		check.Detail = strings.Join(problems, "; ")
+		check.Remediation = "Set FORWARD_API_BASE_URL to the instance root (e.g. https://fwd.app) and FORWARD_API_KEY/FORWARD_API_SECRET to an API token pair, in the environment, .env or config.json"
+		return check
+	}
+	check.Detail = fmt.Sprintf("API %s, timeout %ds", cfg.APIBaseURL, cfg.Timeout)
+	if strings.HasPrefix(cfg.APIBaseURL, "http://") {
+		check.Status = diagnosticWarn
+		check.Detail += " (plain HTTP sends credentials unencrypted)"
+		check.Remediation = "Use an https:// base URL"
+	}
+	return check
+}
+
+func (d *configDoctor) checkCredentials() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "Credentials"}
+	start := time.Now()
+	networks, err := d.client.GetNetworks()
+	if err != nil {
+		check.Status = diagnosticFail
+		check.Detail = truncateString(err.Error(), 200)
+		switch {
+		case strings.Contains(err.Error(), "status code: 401"):
+			check.Remediation = "The API token was rejected; create a new token in Forward (Settings > Account > API tokens) and update FORWARD_API_KEY/FORWARD_API_SECRET"
+		case strings.Contains(err.Error(), "status code: 403"):
+			check.Remediation = "The API token lacks permission to list networks; grant the account network access in Forward"
+		case strings.Contains(err.Error(), "status code: 404"):
+			check.Remediation = "The base URL does not serve the Forward API; check FORWARD_API_BASE_URL"
+		case strings.Contains(err.Error(), "x509") || strings.Contains(err.Error(), "certificate"):
+			check.Remediation = "TLS verification failed; set FORWARD_CA_CERT_PATH to the instance's CA certificate"
+		default:
+			check.Remediation = "The API is unreachable; check FORWARD_API_BASE_URL, DNS, proxies and firewalls, or raise FORWARD_TIMEOUT"
+		}
+		return check
+	}
+	check.Status = diagnosticOK
+	check.Detail = fmt.Sprintf("authenticated; %d networks visible (%s)", len(networks), time.Since(start).Round(time.Millisecond))
+	return check
+}
+
+// checkAPICompatibility probes the NQE library endpoint query discovery depends on, which
+// older Forward releases do not serve
+func (d *configDoctor) checkAPICompatibility() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "API Compatibility"}
+	queries, err := d.client.GetNQEOrgQueries()
+	if err != nil {
+		check.Status = diagnosticWarn
+		check.Detail = "NQE library endpoint unavailable: " + truncateString(err.Error(), 160)
+		check.Remediation = "Query discovery falls back to the bundled spec file; upgrade Forward or grant the token NQE library access for org queries"
+		return check
+	}
+	check.Status = diagnosticOK
+	check.Detail = fmt.Sprintf("NQE library endpoint available (%d org queries)", len(queries))
+	return check
+}
+
+func (d *configDoctor) checkDefaultNetwork() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "Default Network", Status: diagnosticWarn}
+	networkID := d.cfg.Forward.DefaultNetworkID
+	if networkID == "" {
+		check.Detail = "not set; tools need a network_id argument"
+		check.Remediation = "Set FORWARD_DEFAULT_NETWORK_ID (list_networks shows the IDs)"
+		return check
+	}
+	snapshot, err := d.client.GetLatestSnapshot(networkID)
+	if err != nil {
+		check.Status = diagnosticFail
+		check.Detail = fmt.Sprintf("network %s has no processed snapshot or is not accessible: %s", networkID, truncateString(err.Error(), 160))
+		check.Remediation = "Check FORWARD_DEFAULT_NETWORK_ID against list_networks and that the network has a processed snapshot"
+		return check
+	}
+	check.Status = diagnosticOK
+	check.Detail = fmt.Sprintf("network %s, latest snapshot %s", networkID, snapshot.ID)
+	return check
+}
+
+func (d *configDoctor) checkTLSFiles() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "TLS Files", Status: diagnosticOK, Detail: "system trust store"}
+	cfg := d.cfg.Forward
+	var missing []string
+	for _, path := range []string{cfg.CACertPath, cfg.ClientCertPath, cfg.ClientKeyPath} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.ReadFile(path); err != nil {
+			missing = append(missing, path)
+		}
+	}
+	if len(missing) > 0 {
+		check.Status = diagnosticFail
+		check.Detail = "unreadable: " + strings.Join(missing, ", ")
+		check.Remediation = "Fix FORWARD_CA_CERT_PATH, FORWARD_CLIENT_CERT_PATH and FORWARD_CLIENT_KEY_PATH or the file permissions"
+		return check
+	}
+	if cfg.CACertPath != "" {
+		check.Detail = "custom CA " + cfg.CACertPath
+	}
+	if cfg.InsecureSkipVerify {
+		check.Status = diagnosticWarn
+		check.Detail = "certificate verification disabled"
+		check.Remediation = "Set FORWARD_INSECURE_SKIP_VERIFY=false and FORWARD_CA_CERT_PATH for self-signed instances"
+	}
+	return check
+}
+
+func (d *configDoctor) checkSpecFile() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "NQE Spec File"}
+	path, err := findSpecFile("NQELibrary.json")
+	if err != nil {
+		check.Status = diagnosticWarn
+		check.Detail = "spec/NQELibrary.json not found"
+		check.Remediation = "Run the server from the repository root or place spec/NQELibrary.json next to the binary; without it query search depends on hydrating the database"
+		return check
+	}
+	check.Status = diagnosticOK
+	check.Detail = path
+	return check
+}
+
+func (d *configDoctor) checkDataDirectory() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "Data Directory"}
+	dir, err := d.dataDir()
+	if err != nil {
+		check.Status = diagnosticFail
+		check.Detail = err.Error()
+		check.Remediation = "Make ~/.forward-mcp/data writable by the server user or set FORWARD_DATA_DIR to a writable directory"
+		return check
+	}
+	var found []string
+	for _, name := range []string{"nqe_queries.db", "memory.db"} {
+		path := filepath.Join(dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		file, err := os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			check.Status = diagnosticFail
+			check.Detail = fmt.Sprintf("%s is not writable: %v", path, err)
+			check.Remediation = fmt.Sprintf("Fix the ownership or permissions of %s (chmod 600)", path)
+			return check
+		}
+		file.Close()
+		found = append(found, fmt.Sprintf("%s (%s)", name, formatBytes(info.Size())))
+	}
+	check.Status = diagnosticOK
+	check.Detail = dir
+	if len(found) == 0 {
+		check.Status = diagnosticWarn
+		check.Detail += "; no databases yet"
+		check.Remediation = "Start the server once or run hydrate_database to build the query database"
+	} else {
+		check.Detail += "; " + strings.Join(found, ", ")
+	}
+	if !strings.HasPrefix(dir, string(filepath.Separator)) || strings.HasPrefix(dir, os.TempDir()) {
+		check.Status = diagnosticWarn
+		check.Remediation = "Databases are in a relative or temporary directory and may be lost; make ~/.forward-mcp/data writable or set FORWARD_DATA_DIR"
+	}
+	return check
+}
+
+// checkWritablePaths verifies the lock, log, disk cache and report locations accept writes
+func (d *configDoctor) checkWritablePaths() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "Writable Paths", Status: diagnosticOK}
+	lockDir := os.Getenv("FORWARD_LOCK_DIR")
+	if lockDir == "" {
+		lockDir = "/tmp"
+	}
+	dirs := map[string]string{"lock directory (FORWARD_LOCK_DIR)": lockDir}
+	if logPath := os.Getenv("FORWARD_MCP_LOG_FILE"); logPath != "" {
+		dirs["log directory (FORWARD_MCP_LOG_FILE)"] = filepath.Dir(logPath)
+	}
+	if d.cfg.Forward.SemanticCache.PersistToDisk {
+		dirs["disk cache (FORWARD_SEMANTIC_CACHE_DISK_PATH)"] = d.cfg.Forward.SemanticCache.DiskCachePath
+	}
+	if d.cfg.Forward.Reports.Directory != "" {
+		dirs["report directory (FORWARD_REPORT_DIR)"] = d.cfg.Forward.Reports.Directory
+	}
+
+	var failures, checked []string
+	for label, dir := range dirs {
+		if err := probeWritableDir(dir); err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %v", label, dir, err))
+			continue
+		}
+		checked = append(checked, dir)
+	}
+	if len(failures) > 0 {
+		check.Status = diagnosticFail
+		check.Detail = strings.Join(failures, "; ")
+		check.Remediation = "Point the listed settings at directories the server user can write"
+		return check
+	}
+	check.Detail = strings.Join(checked, ", ")
+	return check
+}
+
+func (d *configDoctor) checkEmbeddingProvider() DiagnosticCheck {
+	check := DiagnosticCheck{Name: "Embedding Provider", Status: diagnosticOK}
+	provider := d.cfg.Forward.SemanticCache.EmbeddingProvider
+	if provider != "openai" {
+		check.Detail = fmt.Sprintf("%s (local, no network access needed)", provider)
+		return check
+	}
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		check.Status = diagnosticWarn
+		check.Detail = "openai selected but OPENAI_API_KEY is not set; using keyword embeddings"
+		check.Remediation = "Set OPENAI_API_KEY or FORWARD_EMBEDDING_PROVIDER=keyword"
+		return check
+	}
+	if d.embedding == nil {
+		check.Detail = "openai (not probed)"
+		return check
+	}
+	start := time.Now()
+	if _, err := d.embedding.GenerateEmbedding("forward-mcp diagnostics"); err != nil {
+		check.Status = diagnosticFail
+		check.Detail = "openai unreachable: " + truncateString(err.Error(), 160)
+		check.Remediation = "Check OPENAI_API_KEY and outbound access to api.openai.com, or set FORWARD_EMBEDDING_PROVIDER=keyword"
+		return check
+	}
+	check.Detail = fmt.Sprintf("openai reachable (%s)", time.Since(start).Round(time.Millisecond))
+	return check
+}
+
+// probeWritableDir creates dir if needed and writes and removes a probe file in it
+func probeWritableDir(dir string) error {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+	return os.Remove(file.Name())
+}
+
+// formatDiagnostics renders checks with remediation steps for failures and warnings, and
+// reports whether no check failed
+func formatDiagnostics(checks []DiagnosticCheck) (string, bool) {
+	var report strings.Builder
+	report.WriteString("Forward MCP Diagnostics\n\n")
+	counts := map[string]int{}
+	for _, check := range checks {
+		counts[check.Status]++
+		marker := map[string]string{diagnosticOK: "✅", diagnosticWarn: "⚠️", diagnosticFail: "❌"}[check.Status]
+		report.WriteString(fmt.Sprintf("%s %s: %s\n", marker, check.Name, check.Detail))
+		if check.Remediation != "" {
+			report.WriteString(fmt.Sprintf("   → %s\n", check.Remediation))
+		}
+	}
+	report.WriteString(fmt.Sprintf("\n%d passed, %d warnings, %d failed\n", counts[diagnosticOK], counts[diagnosticWarn], counts[diagnosticFail]))
+	return report.String(), counts[diagnosticFail] == 0
+}
+
+// RunDoctor checks the configuration and environment without starting the server and
+// returns the report and whether every check passed or only warned
+func RunDoctor(cfg *config.Config) (string, bool) {
+	doctor := &configDoctor{
+		cfg:     cfg,
+		client:  forward.NewClient(&cfg.Forward),
+		dataDir: getWritableDataDirectory,
+	}
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" && cfg.Forward.SemanticCache.EmbeddingProvider == "openai" {
+		doctor.embedding = NewOpenAIEmbeddingService(apiKey)
+	}
+	return formatDiagnostics(doctor.run())
+}
+
+// runDiagnostics runs the configuration doctor against the running server's client
+func (s *ForwardMCPService) runDiagnostics(args RunDiagnosticsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("run_diagnostics", args, nil)
+
+	doctor := &configDoctor{cfg: s.config, client: s.forwardClient, dataDir: getWritableDataDirectory}
+	if args.ProbeEmbeddings && s.semanticCache != nil {
+		doctor.embedding = s.semanticCache.embeddingService
+	}
+	report, _ := formatDiagnostics(doctor.run())
+	return mcp.NewToolResponse(mcp.NewTextContent(report)), nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+)
+
+func diagnosticByName(checks []DiagnosticCheck, name string) *DiagnosticCheck {
+	for i := range checks {
+		if checks[i].Name == name {
+			return &checks[i]
+		}
+	}
+	return nil
+}
+
+func TestConfigDoctor(t *testing.T) {
+	dataDir := t.TempDir()
+	t.Setenv("FORWARD_LOCK_DIR", t.TempDir())
+	cfg := &config.Config{Forward: config.ForwardConfig{
+		APIKey:           "key",
+		APISecret:        "secret",
+		APIBaseURL:       "https://fwd.example.com",
+		DefaultNetworkID: "162112",
+		SemanticCache:    config.SemanticCacheConfig{EmbeddingProvider: "keyword"},
+	}}
+	client := NewMockForwardClient()
+	doctor := &configDoctor{cfg: cfg, client: client, dataDir: func() (string, error) { return dataDir, nil }}
+
+	checks := doctor.run()
+	for _, name := range []string{"Configuration", "Credentials", "API Compatibility", "Default Network", "Writable Paths", "Embedding Provider"} {
+		check := diagnosticByName(checks, name)
+		if check == nil {
+			t.Fatalf("Expected a %s check", name)
+		}
+		if check.Status != diagnosticOK {
+			t.Errorf("Expected %s to pass, got %s: %s", name, check.Status, check.Detail)
+		}
+	}
+	if _, healthy := formatDiagnostics(checks); !healthy {
+		t.Error("Expected a healthy report")
+	}
+
+	// Rejected credentials fail with remediation steps
+	client.SetError(true, "unexpected status code: 401, response: unauthorized")
+	checks = doctor.run()
+	credentials := diagnosticByName(checks, "Credentials")
+	if credentials == nil || credentials.Status != diagnosticFail || !contains(credentials.Remediation, "token") {
+		t.Errorf("Expected rejected credentials to fail with remediation, got %+v", credentials)
+	}
+	if diagnosticByName(checks, "API Compatibility") != nil {
+		t.Error("Expected API checks to be skipped after a credential failure")
+	}
+	report, healthy := formatDiagnostics(checks)
+	if healthy || !contains(report, "FORWARD_API_KEY") {
+		t.Errorf("Expected an unhealthy report with remediation, got: %s", report)
+	}
+	client.SetError(false, "")
+
+	// A base URL ending in /api is a configuration failure
+	cfg.Forward.APIBaseURL = "https://fwd.example.com/api"
+	if check := doctor.checkConfiguration(); check.Status != diagnosticFail {
+		t.Errorf("Expected a base URL ending in /api to fail, got %+v", check)
+	}
+	cfg.Forward.APIBaseURL = "https://fwd.example.com"
+
+	// Missing TLS files and an unreachable data directory fail
+	cfg.Forward.CACertPath = dataDir + "/missing-ca.pem"
+	if check := doctor.checkTLSFiles(); check.Status != diagnosticFail {
+		t.Errorf("Expected a missing CA file to fail, got %+v", check)
+	}
+	doctor.dataDir = func() (string, error) { return "", errors.New("no writable data directory") }
+	if check := doctor.checkDataDirectory(); check.Status != diagnosticFail {
+		t.Errorf("Expected an unavailable data directory to fail, got %+v", check)
+	}
+}
+
+func TestRunDiagnosticsTool(t *testing.T) {
+	service := createTestService()
+	response, err := service.runDiagnostics(RunDiagnosticsArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"Forward MCP Diagnostics", "Credentials", "NQE Spec File", "passed"} {
+		if !contains(text, expected) {
+			t.Errorf("Expected report to contain %q, got: %s", expected, text)
+		}
+	}
+}
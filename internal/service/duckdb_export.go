@@ -0,0 +1,269 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	duckdbExportDirectoryName = "exports"
+	duckdbMetadataTable       = "_export_entities"
+	maxDuckDBExportEntities   = 50
+)
+
+// errNoDuckDB is returned when neither FORWARD_DUCKDB_PATH nor PATH provides the duckdb CLI
+var errNoDuckDB = errors.New("the duckdb CLI was not found; install it from https://duckdb.org or set FORWARD_DUCKDB_PATH")
+
+// duckdbColumn is a typed column of an exported table
+type duckdbColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// duckdbTable is one stored result written to the export database
+type duckdbTable struct {
+	Name     string         `json:"table"`
+	EntityID string         `json:"entity_id"`
+	Entity   string         `json:"entity"`
+	Rows     int            `json:"rows"`
+	Columns  []duckdbColumn `json:"columns"`
+	Indexes  []string       `json:"indexes,omitempty"`
+	dataPath string
+	metadata map[string]interface{}
+}
+
+// duckdbColumns types the columns of result rows: whole numbers become BIGINT, other numbers
+// DOUBLE, arrays and objects JSON, and columns mixing types VARCHAR
+func duckdbColumns(rows []map[string]interface{}) []duckdbColumn {
+	schema := resultSchema(rows)
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]duckdbColumn, 0, len(names))
+	for _, name := range names {
+		columnType := "VARCHAR"
+		switch schema[name] {
+		case "boolean":
+			columnType = "BOOLEAN"
+		case "number":
+			columnType = "BIGINT"
+			for _, row := range rows {
+				if value, ok := row[name].(float64); ok && (value != math.Trunc(value) || math.Abs(value) > 1<<53) {
+					columnType = "DOUBLE"
+					break
+				}
+			}
+		case "array", "object", "array|object":
+			columnType = "JSON"
+		}
+		columns = append(columns, duckdbColumn{Name: name, Type: columnType})
+	}
+	return columns
+}
+
+// defaultDuckDBIndexColumns picks the columns rows are usually looked up by: device and name
+// columns and identifiers
+func defaultDuckDBIndexColumns(columns []duckdbColumn) []string {
+	var indexes []string
+	for _, column := range columns {
+		if column.Type == "JSON" {
+			continue
+		}
+		lower := strings.ToLower(column.Name)
+		if lower == "name" || lower == "device" || lower == "devicename" || lower == "hostname" ||
+			lower == "id" || strings.HasSuffix(lower, "_id") || (len(column.Name) > 2 && strings.HasSuffix(column.Name, "Id")) {
+			indexes = append(indexes, column.Name)
+		}
+	}
+	return indexes
+}
+
+// duckdbTableName turns an entity name into a unique lowercase table name
+func duckdbTableName(entityName string, used map[string]bool) string {
+	base := strings.Trim(strings.ToLower(unsafeFileNameChars.ReplaceAllString(entityName, "_")), "_")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "result_" + base
+	}
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+func duckdbIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func duckdbString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// duckdbScript builds the SQL that loads each table from its newline-delimited JSON file with
+// explicit column types, indexes it and records where every table came from
+func duckdbScript(tables []duckdbTable, exportedAt time.Time) string {
+	var script strings.Builder
+	script.WriteString("BEGIN TRANSACTION;\n")
+	for _, table := range tables {
+		columns := make([]string, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			columns = append(columns, fmt.Sprintf("%s: %s", duckdbString(column.Name), duckdbString(column.Type)))
+		}
+		fmt.Fprintf(&script, "CREATE OR REPLACE TABLE %s AS SELECT * FROM read_json(%s, format = 'newline_delimited', columns = {%s});\n",
+			duckdbIdentifier(table.Name), duckdbString(table.dataPath), strings.Join(columns, ", "))
+		for _, column := range table.Indexes {
+			fmt.Fprintf(&script, "CREATE INDEX %s ON %s (%s);\n",
+				duckdbIdentifier("idx_"+table.Name+"_"+strings.ToLower(unsafeFileNameChars.ReplaceAllString(column, "_"))),
+				duckdbIdentifier(table.Name), duckdbIdentifier(column))
+		}
+	}
+	fmt.Fprintf(&script, "CREATE OR REPLACE TABLE %s (table_name VARCHAR PRIMARY KEY, entity_id VARCHAR, entity_name VARCHAR, query_id VARCHAR, network_id VARCHAR, snapshot_id VARCHAR, row_count BIGINT, exported_at TIMESTAMP);\n",
+		duckdbMetadataTable)
+	for _, table := range tables {
+		fmt.Fprintf(&script, "INSERT INTO %s VALUES (%s, %s, %s, %s, %s, %s, %d, %s);\n", duckdbMetadataTable,
+			duckdbString(table.Name), duckdbString(table.EntityID), duckdbString(table.Entity),
+			duckdbString(resultValueString(table.metadata["query_id"])), duckdbString(resultValueString(table.metadata["network_id"])),
+			duckdbString(resultValueString(table.metadata["snapshot_id"])), table.Rows, duckdbString(exportedAt.UTC().Format("2006-01-02 15:04:05")))
+	}
+	script.WriteString("COMMIT;\n")
+	return script.String()
+}
+
+// duckdbCommand returns the duckdb CLI, preferring the configured path
+func (s *ForwardMCPService) duckdbCommand() (string, error) {
+	if s.config != nil && s.config.Forward.DuckDBPath != "" {
+		return s.config.Forward.DuckDBPath, nil
+	}
+	if path, err := exec.LookPath("duckdb"); err == nil {
+		return path, nil
+	}
+	return "", errNoDuckDB
+}
+
+// exportToDuckDB writes stored results into a DuckDB database file for offline analytics
+func (s *ForwardMCPService) exportToDuckDB(args ExportToDuckDBArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("export_to_duckdb", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if len(args.Entities) == 0 {
+		return nil, fmt.Errorf("at least one stored result entity is required")
+	}
+	if len(args.Entities) > maxDuckDBExportEntities {
+		return nil, fmt.Errorf("at most %d entities can be exported at once", maxDuckDBExportEntities)
+	}
+	duckdb, err := s.duckdbCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	dataDir, err := getWritableDataDirectory()
+	if err != nil {
+		return nil, err
+	}
+	exportDir := filepath.Join(dataDir, duckdbExportDirectoryName)
+	if err := os.MkdirAll(exportDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create export directory: %w", err)
+	}
+	name := unsafeFileNameChars.ReplaceAllString(strings.TrimSuffix(strings.TrimSpace(args.Name), ".duckdb"), "_")
+	if name == "" {
+		name = "export-" + time.Now().UTC().Format("20060102-150405")
+	}
+	dbPath := filepath.Join(exportDir, name+".duckdb")
+	if _, err := os.Stat(dbPath); err == nil {
+		if !args.Overwrite {
+			return nil, fmt.Errorf("%s already exists; pass overwrite=true to replace it", dbPath)
+		}
+		os.Remove(dbPath)
+		os.Remove(dbPath + ".wal")
+	}
+
+	workDir, err := os.MkdirTemp("", "forward-duckdb-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(workDir)
+
+	used := map[string]bool{duckdbMetadataTable: true}
+	var tables []duckdbTable
+	for _, identifier := range args.Entities {
+		entity, rows, err := s.loadStoredResultRows(identifier)
+		if err != nil {
+			return nil, err
+		}
+		table := duckdbTable{
+			Name:     duckdbTableName(entity.Name, used),
+			EntityID: entity.ID,
+			Entity:   entity.Name,
+			Rows:     len(rows),
+			Columns:  duckdbColumns(rows),
+			metadata: entity.Metadata,
+		}
+		table.Indexes = defaultDuckDBIndexColumns(table.Columns)
+		if len(args.IndexColumns) > 0 {
+			table.Indexes = nil
+			for _, column := range args.IndexColumns {
+				if resultRowsHaveColumn(rows, column) {
+					table.Indexes = append(table.Indexes, column)
+				}
+			}
+		}
+
+		table.dataPath = filepath.Join(workDir, table.Name+".ndjson")
+		file, err := os.Create(table.dataPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write rows of %s: %w", identifier, err)
+		}
+		encoder := json.NewEncoder(file)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to write rows of %s: %w", identifier, err)
+			}
+		}
+		if err := file.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write rows of %s: %w", identifier, err)
+		}
+		tables = append(tables, table)
+	}
+
+	command := exec.Command(duckdb, dbPath)
+	command.Stdin = strings.NewReader(duckdbScript(tables, time.Now()))
+	if combined, err := command.CombinedOutput(); err != nil {
+		os.Remove(dbPath)
+		return nil, fmt.Errorf("duckdb failed: %w: %s", err, strings.TrimSpace(string(combined)))
+	}
+	info, err := os.Stat(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("duckdb produced no database: %w", err)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "# DuckDB export: %s (%s)\n\n", dbPath, formatBytes(info.Size()))
+	text.WriteString("| Table | Entity | Rows | Columns | Indexed |\n|---|---|---|---|---|\n")
+	for _, table := range tables {
+		columns := make([]string, 0, len(table.Columns))
+		for _, column := range table.Columns {
+			columns = append(columns, column.Name+" "+column.Type)
+		}
+		fmt.Fprintf(&text, "| %s | %s | %d | %s | %s |\n", table.Name, table.Entity, table.Rows, strings.Join(columns, ", "), strings.Join(table.Indexes, ", "))
+	}
+	fmt.Fprintf(&text, "\n%s lists the source entity, query, network and snapshot of every table.\n\n", duckdbMetadataTable)
+	fmt.Fprintf(&text, "Open it in a notebook with:\n```python\nimport duckdb\ncon = duckdb.connect(%q, read_only=True)\ncon.sql(\"SELECT * FROM %s\").df()\n```\n", dbPath, tables[0].Name)
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestDuckDBColumns(t *testing.T) {
+	columns := duckdbColumns([]map[string]interface{}{
+		{"device": "core-1", "mtu": float64(1500), "load": float64(1), "up": true, "vlans": []interface{}{"10"}, "mixed": "a"},
+		{"device": "edge-1", "mtu": float64(9000), "load": 0.5, "up": false, "vlans": nil, "mixed": float64(2)},
+	})
+	types := make(map[string]string)
+	for _, column := range columns {
+		types[column.Name] = column.Type
+	}
+	expected := map[string]string{"device": "VARCHAR", "mtu": "BIGINT", "load": "DOUBLE", "up": "BOOLEAN", "vlans": "JSON", "mixed": "VARCHAR"}
+	for name, columnType := range expected {
+		if types[name] != columnType {
+			t.Errorf("Expected %s to be %s, got %s", name, columnType, types[name])
+		}
+	}
+	if indexes := defaultDuckDBIndexColumns(columns); len(indexes) != 1 || indexes[0] != "device" {
+		t.Errorf("Expected device to be indexed, got %v", indexes)
+	}
+}
+
+func TestExportToDuckDB(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	// Stands in for the duckdb CLI: keeps the SQL script it receives as the database file
+	fake := filepath.Join(t.TempDir(), "duckdb")
+	if err := os.WriteFile(fake, []byte("#!/bin/sh\ncat > \"$1\"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake duckdb: %v", err)
+	}
+	service.config.Forward.DuckDBPath = fake
+
+	queryID := uniqueTestName("FQ_duckdb_")
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking(queryID, "162112", "snap-1", &forward.NQERunResult{
+		Items: []map[string]interface{}{
+			{"deviceName": "core-1", "mtu": float64(1500), "note": "it's up"},
+			{"deviceName": "edge-1", "mtu": float64(9000), "note": "ok"},
+		},
+	}, 0)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	entity, err := service.memorySystem.GetEntity(entityID)
+	if err != nil {
+		t.Fatalf("Failed to load result entity: %v", err)
+	}
+
+	response, err := service.exportToDuckDB(ExportToDuckDBArgs{Entities: []string{entity.ID, entity.Name}, Name: "lab"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	table := duckdbTableName(entity.Name, map[string]bool{})
+	for _, expected := range []string{"lab.duckdb", "| " + table + " | " + entity.Name + " | 2 | deviceName VARCHAR, mtu BIGINT, note VARCHAR | deviceName |", "| " + table + "_2 |", "duckdb.connect("} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	dataDir, _ := getWritableDataDirectory()
+	script, err := os.ReadFile(filepath.Join(dataDir, duckdbExportDirectoryName, "lab.duckdb"))
+	if err != nil {
+		t.Fatalf("Expected the database file, got %v", err)
+	}
+	for _, expected := range []string{
+		`CREATE OR REPLACE TABLE "` + table + `" AS SELECT * FROM read_json(`,
+		`columns = {'deviceName': 'VARCHAR', 'mtu': 'BIGINT', 'note': 'VARCHAR'}`,
+		`CREATE INDEX "idx_` + table + `_devicename" ON "` + table + `" ("deviceName");`,
+		`INSERT INTO _export_entities VALUES ('` + table + `', '` + entity.ID + `', '` + entity.Name + `', '` + queryID + `', '162112', 'snap-1', 2,`,
+	} {
+		if !strings.Contains(string(script), expected) {
+			t.Errorf("Expected %q in script: %s", expected, script)
+		}
+	}
+
+	if _, err := service.exportToDuckDB(ExportToDuckDBArgs{Entities: []string{entity.ID}, Name: "lab"}); err == nil || !strings.Contains(err.Error(), "overwrite") {
+		t.Errorf("Expected an existing export to need overwrite, got %v", err)
+	}
+	if _, err := service.exportToDuckDB(ExportToDuckDBArgs{Entities: []string{entity.ID}, Name: "lab", Overwrite: true, IndexColumns: []string{"mtu", "missing"}}); err != nil {
+		t.Fatalf("Expected overwrite to succeed, got %v", err)
+	}
+	script, _ = os.ReadFile(filepath.Join(dataDir, duckdbExportDirectoryName, "lab.duckdb"))
+	if !strings.Contains(string(script), `("mtu");`) || strings.Contains(string(script), "missing") || strings.Contains(string(script), `("deviceName")`) {
+		t.Errorf("Expected only the requested index columns that exist: %s", script)
+	}
+
+	service.config.Forward.DuckDBPath = ""
+	t.Setenv("PATH", t.TempDir())
+	if _, err := service.exportToDuckDB(ExportToDuckDBArgs{Entities: []string{entity.ID}}); err != errNoDuckDB {
+		t.Errorf("Expected the missing CLI to be reported, got %v", err)
+	}
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// EOL exposure buckets, ordered from most to least urgent
+const (
+	eolBucketOverdue  = "overdue"
+	eolBucket6Months  = "0-6 months"
+	eolBucket12Months = "6-12 months"
+	eolBucket24Months = "12-24 months"
+	eolBucketLater    = "24+ months"
+	eolBucketUnknown  = "unknown"
+)
+
+var eolBuckets = []string{eolBucketOverdue, eolBucket6Months, eolBucket12Months, eolBucket24Months, eolBucketLater, eolBucketUnknown}
+
+// EOL record components
+const (
+	eolComponentHardware = "hardware"
+	eolComponentOS       = "os"
+)
+
+// Query IDs backing get_hardware_support and get_os_support
+const (
+	hardwareSupportQueryID = "FQ_f0984b777b940b4376ed3ec4317ad47437426e7c"
+	osSupportQueryID       = "FQ_fc33d9fd70ba19a18455b0e4d26ca8420003d9cc"
+)
+
+// Normalized field names carrying support dates, in order of preference. End of
+// support is the planning deadline; end of life and sale are fallbacks.
+var eolDateKeys = []string{
+	"lastdateofsupport", "lastsupportdate", "endofsupport", "endofsupportdate", "supportenddate",
+	"endofswmaintenance", "endofvulnerabilitysupport", "endoflife", "endoflifedate", "eol", "eoldate",
+	"endofsale", "endofsaledate",
+}
+
+var (
+	eolDeviceKeys   = []string{"device", "devicename", "name", "hostname"}
+	eolLocationKeys = []string{"location", "locationname", "site", "sitename"}
+)
+
+var eolDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"02-Jan-2006",
+}
+
+// EOLExposureRecord is a single device component with a support deadline
+type EOLExposureRecord struct {
+	Device    string `json:"device"`
+	Location  string `json:"location"`
+	Vendor    string `json:"vendor"`
+	Family    string `json:"family,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Component string `json:"component"`
+	EOLDate   string `json:"eol_date,omitempty"`
+	Bucket    string `json:"bucket"`
+	Quarter   string `json:"quarter,omitempty"`
+}
+
+// EOLQuarterEstimate is the planned work for one calendar quarter
+type EOLQuarterEstimate struct {
+	Quarter      string `json:"quarter"`
+	Replacements int    `json:"replacements"` // Devices whose hardware reaches end of support
+	Upgrades     int    `json:"upgrades"`     // Devices whose OS train reaches end of support
+}
+
+// EOLForecast groups EOL exposure by time bucket, location and vendor
+type EOLForecast struct {
+	NetworkID   string                    `json:"network_id"`
+	SnapshotID  string                    `json:"snapshot_id,omitempty"`
+	GeneratedAt time.Time                 `json:"generated_at"`
+	Devices     int                       `json:"devices"`
+	Buckets     map[string]int            `json:"buckets"`
+	ByLocation  map[string]map[string]int `json:"by_location"`
+	ByVendor    map[string]map[string]int `json:"by_vendor"`
+	Quarters    []EOLQuarterEstimate      `json:"quarters"`
+	Records     []EOLExposureRecord       `json:"records"`
+}
+
+// forecastEOLExposure builds a planning-grade EOL report from hardware and OS support data
+func (s *ForwardMCPService) forecastEOLExposure(args ForecastEOLExposureArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("forecast_eol_exposure", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	scope := strings.ToLower(args.Scope)
+	if scope == "" {
+		scope = "all"
+	}
+	if scope != "all" && scope != eolComponentHardware && scope != eolComponentOS {
+		return nil, fmt.Errorf("invalid scope '%s' (expected all, hardware or os)", args.Scope)
+	}
+
+	// Device locations are optional; rows without a location column fall back to them
+	locations, err := s.forwardClient.GetDeviceLocations(networkID)
+	if err != nil {
+		s.logger.Debug("Failed to get device locations for EOL forecast: %v", err)
+	}
+
+	now := time.Now()
+	var records []EOLExposureRecord
+	sources := []struct{ component, queryID string }{
+		{eolComponentHardware, hardwareSupportQueryID},
+		{eolComponentOS, osSupportQueryID},
+	}
+	for _, source := range sources {
+		if scope != "all" && scope != source.component {
+			continue
+		}
+		items, err := s.fetchAllNQEItems(networkID, snapshotID, source.queryID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s support data: %w", source.component, err)
+		}
+		records = append(records, s.eolRecordsFromItems(items, source.component, locations, now)...)
+	}
+
+	forecast := buildEOLForecast(records, now)
+	forecast.NetworkID = networkID
+	forecast.SnapshotID = snapshotID
+
+	entityID := ""
+	if s.memorySystem != nil {
+		if entityID, err = s.storeEOLForecast(forecast); err != nil {
+			s.logger.Warn("Failed to store EOL forecast: %v", err)
+		}
+	}
+
+	switch strings.ToLower(args.Format) {
+	case "csv":
+		csvText, err := eolForecastCSV(forecast.Records)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render EOL forecast CSV: %w", err)
+		}
+		return s.streamResponse("forecast_eol_exposure", csvText), nil
+	case "json":
+		return s.streamResponse("forecast_eol_exposure", MarshalCompactJSONString(forecast)), nil
+	case "", "markdown":
+		report := formatEOLForecast(forecast)
+		if entityID != "" {
+			report += fmt.Sprintf("\nReport stored in memory (entity: %s). Retrieve the CSV export with get_observations (type: csv) or rerun with format: csv.\n", entityID)
+		}
+		return s.streamResponse("forecast_eol_exposure", report), nil
+	}
+	return nil, fmt.Errorf("invalid format '%s' (expected markdown, json or csv)", args.Format)
+}
+
+// eolRecordsFromItems converts support query rows into EOL records for one component
+func (s *ForwardMCPService) eolRecordsFromItems(items []map[string]interface{}, component string, locations map[string]string, now time.Time) []EOLExposureRecord {
+	records := make([]EOLExposureRecord, 0, len(items))
+	for _, item := range items {
+		fields := make(map[string]string, len(item))
+		rawDates := make(map[string]interface{})
+		for key, value := range item {
+			normalized := normalizeFactKey(key)
+			rawDates[normalized] = value
+			if str, ok := value.(string); ok {
+				fields[normalized] = str
+			}
+		}
+
+		record := EOLExposureRecord{
+			Device:    firstFactValue(fields, eolDeviceKeys),
+			Location:  firstFactValue(fields, eolLocationKeys),
+			Model:     firstFactValue(fields, modelFactKeys),
+			Version:   firstFactValue(fields, versionFactKeys),
+			Component: component,
+			Bucket:    eolBucketUnknown,
+		}
+		if record.Device == "" {
+			continue
+		}
+		if record.Location == "" {
+			record.Location = locations[record.Device]
+		}
+		if record.Location == "" {
+			record.Location = "unassigned"
+		}
+		if facts, ok := s.vendorNormalizer.NormalizeItem(item); ok {
+			record.Vendor = facts.Vendor
+			record.Family = facts.Family
+		}
+		if record.Vendor == "" {
+			record.Vendor = "unknown"
+		}
+
+		for _, key := range eolDateKeys {
+			if date, ok := parseEOLDate(rawDates[key]); ok {
+				record.EOLDate = date.Format("2006-01-02")
+				record.Bucket = eolBucketFor(date, now)
+				record.Quarter = calendarQuarter(date)
+				break
+			}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// buildEOLForecast aggregates records into buckets, groupings and quarterly estimates
+func buildEOLForecast(records []EOLExposureRecord, now time.Time) *EOLForecast {
+	forecast := &EOLForecast{
+		GeneratedAt: now,
+		Buckets:     make(map[string]int),
+		ByLocation:  make(map[string]map[string]int),
+		ByVendor:    make(map[string]map[string]int),
+		Records:     records,
+	}
+
+	// A device counts once per component, at its earliest deadline
+	type deviceComponent struct{ device, component string }
+	earliest := make(map[deviceComponent]EOLExposureRecord)
+	devices := make(map[string]bool)
+	for _, record := range records {
+		devices[record.Device] = true
+		key := deviceComponent{record.Device, record.Component}
+		current, exists := earliest[key]
+		if !exists || (record.EOLDate != "" && (current.EOLDate == "" || record.EOLDate < current.EOLDate)) {
+			earliest[key] = record
+		}
+	}
+	forecast.Devices = len(devices)
+
+	horizon := calendarQuarter(now.AddDate(0, 24, 0))
+	quarters := make(map[string]*EOLQuarterEstimate)
+	for _, record := range earliest {
+		forecast.Buckets[record.Bucket]++
+		incrementEOLGroup(forecast.ByLocation, record.Location, record.Bucket)
+		incrementEOLGroup(forecast.ByVendor, record.Vendor, record.Bucket)
+
+		if record.Quarter == "" || record.Bucket == eolBucketOverdue || record.Quarter > horizon {
+			continue
+		}
+		estimate, exists := quarters[record.Quarter]
+		if !exists {
+			estimate = &EOLQuarterEstimate{Quarter: record.Quarter}
+			quarters[record.Quarter] = estimate
+		}
+		if record.Component == eolComponentHardware {
+			estimate.Replacements++
+		} else {
+			estimate.Upgrades++
+		}
+	}
+
+	for _, estimate := range quarters {
+		forecast.Quarters = append(forecast.Quarters, *estimate)
+	}
+	sort.Slice(forecast.Quarters, func(i, j int) bool { return forecast.Quarters[i].Quarter < forecast.Quarters[j].Quarter })
+	sort.SliceStable(forecast.Records, func(i, j int) bool {
+		a, b := forecast.Records[i], forecast.Records[j]
+		if (a.EOLDate == "") != (b.EOLDate == "") {
+			return a.EOLDate != ""
+		}
+		if a.EOLDate != b.EOLDate {
+			return a.EOLDate < b.EOLDate
+		}
+		return a.Device < b.Device
+	})
+	return forecast
+}
+
+func incrementEOLGroup(groups map[string]map[string]int, group, bucket string) {
+	if groups[group] == nil {
+		groups[group] = make(map[string]int)
+	}
+	groups[group][bucket]++
+}
+
+// parseEOLDate parses support dates as returned by NQE (strings or epoch milliseconds)
+func parseEOLDate(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case string:
+		v = strings.TrimSpace(v)
+		for _, layout := range eolDateLayouts {
+			if date, err := time.Parse(layout, v); err == nil {
+				return date, true
+			}
+		}
+	case float64:
+		if v > 1e11 {
+			return time.UnixMilli(int64(v)).UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// eolBucketFor places a deadline into a planning bucket relative to now
+func eolBucketFor(date, now time.Time) string {
+	switch {
+	case date.Before(now):
+		return eolBucketOverdue
+	case date.Before(now.AddDate(0, 6, 0)):
+		return eolBucket6Months
+	case date.Before(now.AddDate(0, 12, 0)):
+		return eolBucket12Months
+	case date.Before(now.AddDate(0, 24, 0)):
+		return eolBucket24Months
+	}
+	return eolBucketLater
+}
+
+// calendarQuarter formats a date as YYYY-Qn
+func calendarQuarter(date time.Time) string {
+	return fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1)
+}
+
+// formatEOLForecast renders the forecast as a markdown planning report
+func formatEOLForecast(forecast *EOLForecast) string {
+	var report strings.Builder
+	report.WriteString("# EOL Exposure Forecast\n\n")
+	report.WriteString(fmt.Sprintf("Network: %s | Devices: %d | Records: %d | Generated: %s\n\n",
+		forecast.NetworkID, forecast.Devices, len(forecast.Records), forecast.GeneratedAt.Format("2006-01-02")))
+
+	report.WriteString("## Exposure by Time Bucket\n\n")
+	for _, bucket := range eolBuckets {
+		report.WriteString(fmt.Sprintf("- %s: %d\n", bucket, forecast.Buckets[bucket]))
+	}
+
+	writeGroup := func(title string, groups map[string]map[string]int) {
+		report.WriteString(fmt.Sprintf("\n## Exposure by %s\n\n", title))
+		report.WriteString("| " + title + " | " + strings.Join(eolBuckets, " | ") + " |\n")
+		report.WriteString("|---" + strings.Repeat("|---", len(eolBuckets)) + "|\n")
+		names := make([]string, 0, len(groups))
+		for name := range groups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			row := []string{name}
+			for _, bucket := range eolBuckets {
+				row = append(row, fmt.Sprintf("%d", groups[name][bucket]))
+			}
+			report.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		}
+	}
+	writeGroup("Location", forecast.ByLocation)
+	writeGroup("Vendor", forecast.ByVendor)
+
+	report.WriteString("\n## Estimated Work per Quarter (next 24 months)\n\n")
+	if len(forecast.Quarters) == 0 {
+		report.WriteString("No hardware or OS deadlines fall within the next 24 months.\n")
+	} else {
+		report.WriteString("| Quarter | Replacements | OS Upgrades |\n|---|---|---|\n")
+		for _, quarter := range forecast.Quarters {
+			report.WriteString(fmt.Sprintf("| %s | %d | %d |\n", quarter.Quarter, quarter.Replacements, quarter.Upgrades))
+		}
+	}
+	if overdue := forecast.Buckets[eolBucketOverdue]; overdue > 0 {
+		report.WriteString(fmt.Sprintf("\n⚠️ %d device components are already past end of support and should be planned immediately.\n", overdue))
+	}
+	return report.String()
+}
+
+// eolForecastCSV renders EOL records as CSV for spreadsheet planning
+func eolForecastCSV(records []EOLExposureRecord) (string, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write([]string{"device", "location", "vendor", "family", "model", "version", "component", "eol_date", "bucket", "quarter"}); err != nil {
+		return "", err
+	}
+	for _, r := range records {
+		if err := writer.Write([]string{r.Device, r.Location, r.Vendor, r.Family, r.Model, r.Version, r.Component, r.EOLDate, r.Bucket, r.Quarter}); err != nil {
+			return "", err
+		}
+	}
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
+
+// storeEOLForecast stores the forecast as a report entity with summary and CSV observations
+func (s *ForwardMCPService) storeEOLForecast(forecast *EOLForecast) (string, error) {
+	entity, err := s.memorySystem.CreateEntity(
+		fmt.Sprintf("eol_forecast_%s_%d", forecast.NetworkID, forecast.GeneratedAt.Unix()),
+		"eol_forecast",
+		map[string]interface{}{
+			"network_id":   forecast.NetworkID,
+			"snapshot_id":  forecast.SnapshotID,
+			"generated_at": forecast.GeneratedAt.Format(time.RFC3339),
+			"devices":      forecast.Devices,
+			"buckets":      forecast.Buckets,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create EOL forecast entity: %w", err)
+	}
+
+	summary := *forecast
+	summary.Records = nil
+	if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(summary), "summary", nil); err != nil {
+		s.logger.Debug("Failed to add EOL forecast summary: %v", err)
+	}
+	if csvText, err := eolForecastCSV(forecast.Records); err == nil {
+		if _, err := s.memorySystem.AddObservation(entity.ID, csvText, "csv", map[string]interface{}{"rows": len(forecast.Records)}); err != nil {
+			s.logger.Debug("Failed to add EOL forecast CSV: %v", err)
+		}
+	}
+	return entity.ID, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestEOLBucketsAndQuarters(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		date     time.Time
+		expected string
+	}{
+		{now.AddDate(0, -1, 0), eolBucketOverdue},
+		{now.AddDate(0, 3, 0), eolBucket6Months},
+		{now.AddDate(0, 9, 0), eolBucket12Months},
+		{now.AddDate(0, 18, 0), eolBucket24Months},
+		{now.AddDate(3, 0, 0), eolBucketLater},
+	}
+	for _, tt := range tests {
+		if got := eolBucketFor(tt.date, now); got != tt.expected {
+			t.Errorf("eolBucketFor(%s) = %s; want %s", tt.date.Format("2006-01-02"), got, tt.expected)
+		}
+	}
+
+	if q := calendarQuarter(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)); q != "2026-Q4" {
+		t.Errorf("Expected 2026-Q4, got %s", q)
+	}
+
+	for _, value := range []interface{}{"2027-03-31", "Mar 31, 2027", "03/31/2027", float64(time.Date(2027, 3, 31, 0, 0, 0, 0, time.UTC).UnixMilli())} {
+		date, ok := parseEOLDate(value)
+		if !ok || date.Format("2006-01-02") != "2027-03-31" {
+			t.Errorf("parseEOLDate(%v) = %v, %v", value, date, ok)
+		}
+	}
+}
+
+func TestBuildEOLForecast(t *testing.T) {
+	now := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	records := []EOLExposureRecord{
+		// Two hardware components on one device count as a single replacement at the earliest date
+		{Device: "core-1", Location: "dc1", Vendor: "cisco", Component: eolComponentHardware, EOLDate: "2026-05-01", Bucket: eolBucket6Months, Quarter: "2026-Q2"},
+		{Device: "core-1", Location: "dc1", Vendor: "cisco", Component: eolComponentHardware, EOLDate: "2026-11-01", Bucket: eolBucket12Months, Quarter: "2026-Q4"},
+		{Device: "core-1", Location: "dc1", Vendor: "cisco", Component: eolComponentOS, EOLDate: "2026-06-01", Bucket: eolBucket6Months, Quarter: "2026-Q2"},
+		{Device: "edge-1", Location: "branch", Vendor: "juniper", Component: eolComponentHardware, EOLDate: "2025-12-01", Bucket: eolBucketOverdue, Quarter: "2025-Q4"},
+		{Device: "edge-2", Location: "branch", Vendor: "juniper", Component: eolComponentHardware, Bucket: eolBucketUnknown},
+	}
+
+	forecast := buildEOLForecast(records, now)
+	if forecast.Devices != 3 {
+		t.Errorf("Expected 3 devices, got %d", forecast.Devices)
+	}
+	if forecast.Buckets[eolBucket6Months] != 2 || forecast.Buckets[eolBucketOverdue] != 1 || forecast.Buckets[eolBucketUnknown] != 1 {
+		t.Errorf("Unexpected bucket counts: %v", forecast.Buckets)
+	}
+	if len(forecast.Quarters) != 1 || forecast.Quarters[0] != (EOLQuarterEstimate{Quarter: "2026-Q2", Replacements: 1, Upgrades: 1}) {
+		t.Errorf("Unexpected quarterly estimates: %+v", forecast.Quarters)
+	}
+	if forecast.ByLocation["branch"][eolBucketOverdue] != 1 || forecast.ByVendor["cisco"][eolBucket6Months] != 2 {
+		t.Errorf("Unexpected groupings: %v / %v", forecast.ByLocation, forecast.ByVendor)
+	}
+	if forecast.Records[len(forecast.Records)-1].Device != "edge-2" {
+		t.Error("Expected records without a date to sort last")
+	}
+}
+
+func TestForecastEOLExposure(t *testing.T) {
+	service := createTestService()
+	mockClient := service.forwardClient.(*MockForwardClient)
+	nextYear := time.Now().AddDate(1, 0, 0).Format("2006-01-02")
+	mockClient.deviceLocations = map[string]string{"router-1": "dc1"}
+	mockClient.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"device": "router-1", "vendor": "CISCO", "model": "ASR1001", "Last Date of Support": nextYear},
+		{"device": "switch-1", "vendor": "ARISTA", "location": "dc2", "endOfSupport": "not announced"},
+	}}
+
+	response, err := service.forecastEOLExposure(ForecastEOLExposureArgs{NetworkID: "162112", Scope: "hardware", Format: "csv"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(response.Content[0].TextContent.Text), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "device,location,vendor") {
+		t.Fatalf("Expected CSV header and 2 rows, got: %v", lines)
+	}
+	if !strings.HasPrefix(lines[1], "router-1,dc1,cisco,ios,ASR1001,,hardware,"+nextYear) {
+		t.Errorf("Unexpected first CSV row: %s", lines[1])
+	}
+	if !strings.Contains(lines[2], "switch-1,dc2,arista") || !strings.Contains(lines[2], eolBucketUnknown) {
+		t.Errorf("Unexpected second CSV row: %s", lines[2])
+	}
+
+	response, err = service.forecastEOLExposure(ForecastEOLExposureArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "Exposure by Location") {
+		t.Errorf("Expected markdown report, got: %s", response.Content[0].TextContent.Text)
+	}
+
+	if _, err := service.forecastEOLExposure(ForecastEOLExposureArgs{NetworkID: "162112", Scope: "licenses"}); err == nil {
+		t.Error("Expected invalid scope to be rejected")
+	}
+}
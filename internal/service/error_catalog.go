@@ -0,0 +1,311 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Error codes returned in tool errors. Agents match on them, so a code is never renumbered or
+// reused; retired codes stay in the catalog.
+const (
+	CodeNetworkIDRequired      = "FWD-NET-001"
+	CodeNetworkNotAvailable    = "FWD-NET-002"
+	CodeRecentFailure          = "FWD-NET-003"
+	CodeAPIUnreachable         = "FWD-API-001"
+	CodeAPIUnauthorized        = "FWD-API-002"
+	CodeAPINotFound            = "FWD-API-003"
+	CodeAPIRateLimited         = "FWD-API-004"
+	CodeNQEInvalidQuery        = "FWD-NQE-001"
+	CodeNQEResultTooLarge      = "FWD-NQE-002"
+	CodeNQEDatabaseUnavailable = "FWD-NQE-003"
+	CodeMemoryUnavailable      = "FWD-MEM-001"
+	CodeStoredResultNotFound   = "FWD-MEM-002"
+	CodeTimeSeriesUnavailable  = "FWD-MEM-003"
+	CodeAPIKeyDenied           = "FWD-AUTH-001"
+)
+
+// ErrorDefinition is a catalog entry: the message template used when the server raises the
+// error, a one-line hint appended to tool errors and the remediation steps shown by
+// lookup_error. Keeping the text here rather than at call sites is what allows translation.
+type ErrorDefinition struct {
+	Code        string
+	Title       string
+	Message     string // fmt template for newCodedError; empty for errors recognized by markers
+	Hint        string
+	Remediation []string
+	markers     []string // Lowercase substrings identifying uncoded errors, e.g. from the Forward API
+}
+
+// errorCatalog lists every error code in the order lookup_error shows them. Entries with
+// markers are matched in order, so more specific markers come first.
+var errorCatalog = []ErrorDefinition{
+	{
+		Code:    CodeNetworkIDRequired,
+		Title:   "Network ID required",
+		Message: "network_id is required (no default network configured)",
+		Hint:    "Pass network_id, or set a default with set_default_network.",
+		Remediation: []string{
+			"Call list_networks to find the network ID.",
+			"Pass network_id to the tool, or call set_default_network so later calls can omit it.",
+			"To set a default at startup, configure FORWARD_DEFAULT_NETWORK_ID.",
+		},
+	},
+	{
+		Code:    CodeNetworkNotAvailable,
+		Title:   "Network not available",
+		Message: "network %s is not available on this server",
+		Hint:    "The network is hidden by the network access policy; use list_networks to see the exposed networks.",
+		Remediation: []string{
+			"Call list_networks to see the networks this server exposes.",
+			"Ask the operator to add the network to FORWARD_ALLOWED_NETWORKS or remove it from FORWARD_DENIED_NETWORKS.",
+		},
+	},
+	{
+		Code:    CodeRecentFailure,
+		Title:   "Recent failure, not retried",
+		Message: "not retrying: %s",
+		Hint:    "The same call failed moments ago; wait for the retry window or fix the cause first.",
+		Remediation: []string{
+			"Read the recorded failure in the message to find the cause.",
+			"Wait until the retry window passes; the TTL is set by FORWARD_NEGATIVE_CACHE_TTL_SECONDS.",
+			"Call clear_cache to forget recorded failures once the cause is fixed.",
+		},
+	},
+	{
+		Code:  CodeAPIUnauthorized,
+		Title: "Forward API authentication failed",
+		Hint:  "Check FORWARD_API_KEY and FORWARD_API_SECRET and the account's network permissions.",
+		Remediation: []string{
+			"Verify FORWARD_API_KEY and FORWARD_API_SECRET are set and not expired.",
+			"Confirm the account can access the network in the Forward UI.",
+			"Run run_diagnostics to test the credentials.",
+		},
+		markers: []string{"status code: 401", "status code: 403", "unauthorized", "forbidden"},
+	},
+	{
+		Code:  CodeAPIRateLimited,
+		Title: "Forward API rate limited",
+		Hint:  "Too many requests; retry after a short pause or reduce parallel calls.",
+		Remediation: []string{
+			"Wait a few seconds and retry.",
+			"Prefer bulk tools such as search_paths_bulk and all_results paging over many single calls.",
+		},
+		markers: []string{"status code: 429", "too many requests"},
+	},
+	{
+		Code:  CodeAPIUnreachable,
+		Title: "Forward API unreachable",
+		Hint:  "The Forward platform could not be reached; check FORWARD_API_BASE_URL and connectivity.",
+		Remediation: []string{
+			"Check FORWARD_API_BASE_URL and that the host resolves and accepts connections.",
+			"Check proxies, firewalls and TLS settings (FORWARD_CA_CERT_PATH, FORWARD_INSECURE_SKIP_VERIFY).",
+			"Run run_diagnostics or client_diagnostics with probes to measure connectivity.",
+		},
+		markers: []string{"connection refused", "no such host", "i/o timeout", "deadline exceeded",
+			"network is unreachable", "connection reset", "status code: 502", "status code: 503", "status code: 504"},
+	},
+	{
+		Code:  CodeNQEResultTooLarge,
+		Title: "NQE result too large",
+		Hint:  "Use a smaller limit with offset paging, or all_results to page automatically.",
+		Remediation: []string{
+			"Lower limit and page with offset.",
+			"Use all_results so the server pages and stores the rows for SQL analysis.",
+			"Select fewer columns in the query.",
+		},
+		markers: []string{"result exceeds maximum length"},
+	},
+	{
+		Code:  CodeNQEInvalidQuery,
+		Title: "Invalid NQE query",
+		Hint:  "The query failed to compile or run; check the query ID and parameters with get_nqe_query_source.",
+		Remediation: []string{
+			"Check the query ID with search_nqe_queries; IDs change when queries are re-committed.",
+			"Inspect the source and parameters with get_nqe_query_source.",
+			"Run check_query_compatibility to find data model changes that break the query.",
+		},
+		markers: []string{"nqe_compile_error", "nqe_runtime_error", "invalid module path", "query not found"},
+	},
+	{
+		Code:  CodeAPINotFound,
+		Title: "Forward resource not found",
+		Hint:  "The network, snapshot or query does not exist; list them to find valid IDs.",
+		Remediation: []string{
+			"Call list_networks, list_snapshots or search_nqe_queries to find valid IDs.",
+			"Snapshots may have been deleted; use get_latest_snapshot.",
+		},
+		markers: []string{"status code: 404"},
+	},
+	{
+		Code:    CodeNQEDatabaseUnavailable,
+		Title:   "NQE query database unavailable",
+		Message: "NQE database is not available",
+		Hint:    "The local NQE query database failed to open; run run_diagnostics.",
+		Remediation: []string{
+			"Run run_diagnostics to check the data directory and database files.",
+			"Check that FORWARD_DATA_DIR is writable and has free space.",
+			"Restart the server after fixing the data directory.",
+		},
+	},
+	{
+		Code:    CodeMemoryUnavailable,
+		Title:   "Memory system unavailable",
+		Message: "memory system is not available",
+		Hint:    "The memory database failed to open, so stored results and entities are unavailable; run run_diagnostics.",
+		Remediation: []string{
+			"Run run_diagnostics to check the data directory and memory database.",
+			"Check that FORWARD_DATA_DIR is writable and has free space.",
+			"Restart the server after fixing the data directory.",
+		},
+	},
+	{
+		Code:    CodeStoredResultNotFound,
+		Title:   "Stored result not found",
+		Message: "stored result %s not found: %w",
+		Hint:    "Use search_entities or get_nqe_result_summary to find the ID of a stored result.",
+		Remediation: []string{
+			"Call search_entities with the query ID or result name to find stored results.",
+			"Re-run the query with all_results to store the rows again if the result was deleted or cleaned up.",
+		},
+	},
+	{
+		Code:    CodeTimeSeriesUnavailable,
+		Title:   "Time-series storage unavailable",
+		Message: "time-series storage is not available (memory system disabled)",
+		Hint:    "Time series are kept in the memory database, which failed to open; run run_diagnostics.",
+		Remediation: []string{
+			"Run run_diagnostics to check the data directory and memory database.",
+			"Restart the server after fixing the data directory.",
+		},
+	},
+	{
+		Code:  CodeAPIKeyDenied,
+		Title: "API key not permitted",
+		Hint:  "The calling API key's scopes do not allow this call.",
+		Remediation: []string{
+			"Use a key whose toolGroups and networks cover the call, or one that is not read-only for write tools.",
+			"Ask the operator to extend the key's scopes in the API keys file (SERVER_API_KEYS_FILE).",
+		},
+	},
+}
+
+// lookupErrorDefinition returns the catalog entry for a code
+func lookupErrorDefinition(code string) (ErrorDefinition, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	for _, definition := range errorCatalog {
+		if definition.Code == code {
+			return definition, true
+		}
+	}
+	return ErrorDefinition{}, false
+}
+
+// CodedError is an error with a catalog code
+type CodedError struct {
+	Code string
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.Code, e.Err)
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// newCodedError formats the catalog message of code with args
+func newCodedError(code string, args ...interface{}) error {
+	definition, _ := lookupErrorDefinition(code)
+	return &CodedError{Code: code, Err: fmt.Errorf(definition.Message, args...)}
+}
+
+// withErrorCode tags an existing error with a code, keeping its message
+func withErrorCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// ErrorCode returns the catalog code of an error: the code it carries, or the code whose
+// markers match its message. Unrecognized errors return "".
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	message := strings.ToLower(err.Error())
+	for _, definition := range errorCatalog {
+		for _, marker := range definition.markers {
+			if strings.Contains(message, marker) {
+				return definition.Code
+			}
+		}
+	}
+	return ""
+}
+
+// toolError is a tool failure with its catalog code and hint appended to the message
+type toolError struct {
+	code string
+	hint string
+	err  error
+}
+
+func (e *toolError) Error() string {
+	message := e.err.Error()
+	if !strings.Contains(message, e.code) {
+		message = fmt.Sprintf("[%s] %s", e.code, message)
+	}
+	return fmt.Sprintf("%s\nHint: %s (lookup_error %s has remediation steps)", message, e.hint, e.code)
+}
+
+func (e *toolError) Unwrap() error {
+	return e.err
+}
+
+// describeToolError adds the code and hint of a recognized error for the client
+func describeToolError(err error) error {
+	code := ErrorCode(err)
+	if code == "" {
+		return err
+	}
+	var described *toolError
+	if errors.As(err, &described) {
+		return err
+	}
+	definition, _ := lookupErrorDefinition(code)
+	return &toolError{code: code, hint: definition.Hint, err: err}
+}
+
+// lookupError explains an error code with its remediation steps, or lists every code
+func (s *ForwardMCPService) lookupError(args LookupErrorArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("lookup_error", args, nil)
+
+	var text strings.Builder
+	if strings.TrimSpace(args.Code) == "" {
+		text.WriteString("## Error Codes\n\n| Code | Title | Hint |\n|------|-------|------|\n")
+		for _, definition := range errorCatalog {
+			text.WriteString(fmt.Sprintf("| %s | %s | %s |\n", definition.Code, definition.Title, definition.Hint))
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+	}
+
+	definition, ok := lookupErrorDefinition(args.Code)
+	if !ok {
+		return nil, fmt.Errorf("unknown error code %s; call lookup_error without a code to list every code", args.Code)
+	}
+	text.WriteString(fmt.Sprintf("## %s: %s\n\n", definition.Code, definition.Title))
+	text.WriteString(fmt.Sprintf("%s\n\n### Remediation\n", definition.Hint))
+	for i, step := range definition.Remediation {
+		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
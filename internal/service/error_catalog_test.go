@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func TestErrorCatalogEntries(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, definition := range errorCatalog {
+		if seen[definition.Code] {
+			t.Errorf("Duplicate error code %s", definition.Code)
+		}
+		seen[definition.Code] = true
+		if definition.Title == "" || definition.Hint == "" || len(definition.Remediation) == 0 {
+			t.Errorf("Expected %s to have a title, hint and remediation", definition.Code)
+		}
+		if definition.Message == "" && len(definition.markers) == 0 && definition.Code != CodeAPIKeyDenied {
+			t.Errorf("Expected %s to have a message or markers", definition.Code)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	coded := newCodedError(CodeNetworkNotAvailable, "162112")
+	if coded.Error() != "[FWD-NET-002] network 162112 is not available on this server" {
+		t.Errorf("Unexpected message: %s", coded.Error())
+	}
+	cases := []struct {
+		err      error
+		expected string
+	}{
+		{fmt.Errorf("failed to list devices: %w", coded), CodeNetworkNotAvailable},
+		{errors.New("unexpected status code: 401, response: denied"), CodeAPIUnauthorized},
+		{errors.New("retryable error: unexpected status code: 429"), CodeAPIRateLimited},
+		{errors.New("NQE_COMPILE_ERROR at line 3"), CodeNQEInvalidQuery},
+		{errors.New("invalid prefix: 10.0.0.0/33"), ""},
+		{nil, ""},
+	}
+	for _, tc := range cases {
+		if code := ErrorCode(tc.err); code != tc.expected {
+			t.Errorf("ErrorCode(%v) = %q, expected %q", tc.err, code, tc.expected)
+		}
+	}
+}
+
+func TestToolErrorsCarryHints(t *testing.T) {
+	service := createTestService()
+	handler := service.wrapToolHandler(func(args LookupErrorArgs) (*mcp.ToolResponse, error) {
+		return nil, fmt.Errorf("failed to run query: %w", errors.New("unexpected status code: 503"))
+	}).(func(LookupErrorArgs) (*mcp.ToolResponse, error))
+
+	_, err := handler(LookupErrorArgs{})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	message := err.Error()
+	if !strings.HasPrefix(message, "[FWD-API-001] failed to run query") || !strings.Contains(message, "Hint: The Forward platform could not be reached") {
+		t.Errorf("Expected the code and hint, got: %s", message)
+	}
+	if describeToolError(err).Error() != message {
+		t.Error("Expected describing an error twice to leave it unchanged")
+	}
+	plain := errors.New("invalid prefix")
+	if describeToolError(plain) != plain {
+		t.Error("Expected unrecognized errors to pass through")
+	}
+}
+
+func TestLookupError(t *testing.T) {
+	service := createTestService()
+	response, err := service.lookupError(LookupErrorArgs{Code: "fwd-net-001"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "## FWD-NET-001: Network ID required") || !strings.Contains(text, "1. Call list_networks") {
+		t.Errorf("Unexpected explanation: %s", text)
+	}
+
+	response, _ = service.lookupError(LookupErrorArgs{})
+	if text := response.Content[0].TextContent.Text; strings.Count(text, "| FWD-") != len(errorCatalog) {
+		t.Errorf("Expected every code to be listed: %s", text)
+	}
+	if _, err := service.lookupError(LookupErrorArgs{Code: "FWD-XYZ-999"}); err == nil {
+		t.Error("Expected an error for an unknown code")
+	}
+}
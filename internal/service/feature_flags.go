@@ -0,0 +1,142 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/forward-mcp/internal/config"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// experimentalFlagPrefix namespaces the feature flags of experimental tools
+const experimentalFlagPrefix = "experimental/"
+
+// experimentalTools are registered only when their "experimental/<tool>" flag is enabled. Tools
+// move out of this list once their output and arguments are stable.
+var experimentalTools = map[string]bool{
+	"detect_result_anomalies": true,
+}
+
+// FeatureFlags decides which tools are registered and records the outcome for list_feature_flags.
+// A nil FeatureFlags registers every stable tool and no experimental ones.
+type FeatureFlags struct {
+	enabled  map[string]bool
+	disabled map[string]bool
+
+	mu         sync.Mutex
+	registered map[string]bool // Tool name → registered
+}
+
+// NewFeatureFlags builds the flags from configuration
+func NewFeatureFlags(cfg config.FeatureFlagConfig) *FeatureFlags {
+	flags := &FeatureFlags{enabled: make(map[string]bool), disabled: make(map[string]bool), registered: make(map[string]bool)}
+	for _, name := range cfg.Enabled {
+		flags.enabled[strings.TrimSpace(name)] = true
+	}
+	for _, name := range cfg.Disabled {
+		flags.disabled[strings.TrimSpace(name)] = true
+	}
+	return flags
+}
+
+// flagName returns the feature flag controlling a tool
+func flagName(tool string) string {
+	if experimentalTools[tool] {
+		return experimentalFlagPrefix + tool
+	}
+	return tool
+}
+
+// ToolEnabled reports whether a tool should be registered
+func (f *FeatureFlags) ToolEnabled(tool string) bool {
+	flag := flagName(tool)
+	if f == nil {
+		return !experimentalTools[tool]
+	}
+	if f.disabled[tool] || f.disabled[flag] {
+		return false
+	}
+	if experimentalTools[tool] {
+		return f.enabled[flag] || f.enabled[experimentalFlagPrefix+"*"]
+	}
+	return true
+}
+
+// record notes whether a tool was registered
+func (f *FeatureFlags) record(tool string, registered bool) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.registered[tool] = registered
+}
+
+// FeatureFlagStatus is the state of one flag
+type FeatureFlagStatus struct {
+	Flag         string
+	Tool         string
+	Experimental bool
+	Enabled      bool
+}
+
+// Statuses returns the experimental flags and the flags of disabled tools, sorted by flag
+func (f *FeatureFlags) Statuses() []FeatureFlagStatus {
+	var statuses []FeatureFlagStatus
+	seen := make(map[string]bool)
+	for tool := range experimentalTools {
+		seen[tool] = true
+		statuses = append(statuses, FeatureFlagStatus{Flag: flagName(tool), Tool: tool, Experimental: true, Enabled: f.ToolEnabled(tool)})
+	}
+	if f != nil {
+		f.mu.Lock()
+		for tool, registered := range f.registered {
+			if !registered && !seen[tool] {
+				statuses = append(statuses, FeatureFlagStatus{Flag: tool, Tool: tool})
+			}
+		}
+		f.mu.Unlock()
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Flag < statuses[j].Flag })
+	return statuses
+}
+
+// RegisteredCount returns how many tools were registered
+func (f *FeatureFlags) RegisteredCount() int {
+	if f == nil {
+		return 0
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	count := 0
+	for _, registered := range f.registered {
+		if registered {
+			count++
+		}
+	}
+	return count
+}
+
+// listFeatureFlags reports which experimental tools are enabled and which tools are disabled
+func (s *ForwardMCPService) listFeatureFlags(args ListFeatureFlagsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_feature_flags", args, nil)
+
+	var text strings.Builder
+	text.WriteString("## Feature Flags\n\n")
+	text.WriteString(fmt.Sprintf("%d tools registered. Experimental tools are enabled with FORWARD_ENABLED_FEATURES=experimental/<tool> (or experimental/* for all); FORWARD_DISABLED_FEATURES removes tools.\n\n", s.features.RegisteredCount()))
+	text.WriteString("| Flag | Tool | Type | Status |\n|------|------|------|--------|\n")
+	for _, status := range s.features.Statuses() {
+		kind := "stable"
+		if status.Experimental {
+			kind = "experimental"
+		}
+		state := "disabled"
+		if status.Enabled {
+			state = "enabled"
+		}
+		text.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", status.Flag, status.Tool, kind, state))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport/stdio"
+)
+
+func TestFeatureFlagsToolEnabled(t *testing.T) {
+	var defaults *FeatureFlags
+	if !defaults.ToolEnabled("list_networks") || defaults.ToolEnabled("detect_result_anomalies") {
+		t.Error("Expected nil flags to register stable tools only")
+	}
+
+	flags := NewFeatureFlags(config.FeatureFlagConfig{Enabled: []string{"experimental/*"}, Disabled: []string{"clear_cache"}})
+	cases := map[string]bool{"list_networks": true, "clear_cache": false, "detect_result_anomalies": true}
+	for tool, expected := range cases {
+		if flags.ToolEnabled(tool) != expected {
+			t.Errorf("ToolEnabled(%s) = %v, expected %v", tool, !expected, expected)
+		}
+	}
+	// Disabling wins over enabling, by tool name or flag
+	flags = NewFeatureFlags(config.FeatureFlagConfig{Enabled: []string{"experimental/detect_result_anomalies"}, Disabled: []string{"experimental/detect_result_anomalies"}})
+	if flags.ToolEnabled("detect_result_anomalies") {
+		t.Error("Expected the disabled flag to win")
+	}
+}
+
+func TestFeatureFlagsRegistration(t *testing.T) {
+	service := createTestService()
+	service.features = NewFeatureFlags(config.FeatureFlagConfig{Disabled: []string{"clear_cache"}})
+	server := mcp.NewServer(stdio.NewStdioServerTransport())
+	if err := service.RegisterTools(server); err != nil {
+		t.Fatalf("Failed to register tools: %v", err)
+	}
+	if server.CheckToolRegistered("clear_cache") || server.CheckToolRegistered("detect_result_anomalies") {
+		t.Error("Expected disabled and experimental tools to be skipped")
+	}
+	if !server.CheckToolRegistered("list_feature_flags") {
+		t.Error("Expected list_feature_flags to be registered")
+	}
+
+	response, err := service.listFeatureFlags(ListFeatureFlagsArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"| clear_cache | clear_cache | stable | disabled |", "| experimental/detect_result_anomalies | detect_result_anomalies | experimental | disabled |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in output, got: %s", expected, text)
+		}
+	}
+}
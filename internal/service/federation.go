@@ -0,0 +1,406 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	// localInstanceName names the primary instance in federated results
+	localInstanceName = "local"
+	// federationInstanceColumn is added to every merged row
+	federationInstanceColumn = "instance"
+	// federationNetworkID is the network federated results are stored under
+	federationNetworkID = "federation"
+	// maxFederatedRowsPerInstance caps all_results fetches from each instance
+	maxFederatedRowsPerInstance = 100000
+	// federationPreviewRows are shown in markdown responses
+	federationPreviewRows = 5
+)
+
+// federatedInstance is one Forward instance queried by the federated tools
+type federatedInstance struct {
+	name      string
+	networkID string
+	client    forward.ClientInterface
+}
+
+// Federation runs the same query or inventory pull across several Forward instances.
+// The primary instance is always part of it under the name "local".
+type Federation struct {
+	instances []federatedInstance
+}
+
+// NewFederation creates a client for every configured instance. The instances share the
+// primary instance's TLS and connection settings. It returns nil when no instance is configured.
+func NewFederation(primary config.ForwardConfig, instances []config.FederatedInstanceConfig) (*Federation, error) {
+	if len(instances) == 0 {
+		return nil, nil
+	}
+	federation := &Federation{}
+	seen := map[string]bool{localInstanceName: true}
+	for _, instance := range instances {
+		name := strings.TrimSpace(instance.Name)
+		if name == "" {
+			return nil, fmt.Errorf("federated instance %s has no name", instance.APIBaseURL)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("federated instance name '%s' is used twice (or is reserved)", name)
+		}
+		seen[name] = true
+		if instance.APIBaseURL == "" || instance.APIKey == "" || instance.APISecret == "" {
+			return nil, fmt.Errorf("federated instance '%s' needs apiBaseUrl, apiKey and apiSecret", name)
+		}
+
+		cfg := primary
+		cfg.APIBaseURL = instance.APIBaseURL
+		cfg.APIKey = instance.APIKey
+		cfg.APISecret = instance.APISecret
+		cfg.DefaultNetworkID = instance.NetworkID
+		cfg.InsecureSkipVerify = cfg.InsecureSkipVerify || instance.InsecureSkipVerify
+		if cfg.APIDebugFile != "" {
+			// Each instance rotates its own debug log
+			ext := filepath.Ext(cfg.APIDebugFile)
+			cfg.APIDebugFile = strings.TrimSuffix(cfg.APIDebugFile, ext) + "-" + name + ext
+		}
+		federation.add(name, instance.NetworkID, forward.NewClient(&cfg))
+	}
+	return federation, nil
+}
+
+// add registers an instance with its client
+func (f *Federation) add(name, networkID string, client forward.ClientInterface) {
+	f.instances = append(f.instances, federatedInstance{name: name, networkID: networkID, client: client})
+}
+
+// members returns the local instance followed by the configured ones, optionally limited to
+// the named instances
+func (f *Federation) members(local federatedInstance, names []string) ([]federatedInstance, error) {
+	all := append([]federatedInstance{local}, f.instances...)
+	if len(names) == 0 {
+		return all, nil
+	}
+	byName := make(map[string]federatedInstance, len(all))
+	for _, instance := range all {
+		byName[instance.name] = instance
+	}
+	var selected []federatedInstance
+	for _, name := range names {
+		instance, ok := byName[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown federated instance '%s' (known: %s)", name, strings.Join(f.names(), ", "))
+		}
+		selected = append(selected, instance)
+	}
+	return selected, nil
+}
+
+// configured returns the further instances; nil-safe
+func (f *Federation) configured() []federatedInstance {
+	if f == nil {
+		return nil
+	}
+	return f.instances
+}
+
+// names lists the instance names, local first
+func (f *Federation) names() []string {
+	names := []string{localInstanceName}
+	for _, instance := range f.instances {
+		names = append(names, instance.name)
+	}
+	return names
+}
+
+// FederatedInstanceStatus is the outcome of a federated call on one instance
+type FederatedInstanceStatus struct {
+	Instance  string `json:"instance"`
+	NetworkID string `json:"network_id,omitempty"`
+	Rows      int    `json:"rows"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Duration  string `json:"duration"`
+	Error     string `json:"error,omitempty"`
+}
+
+// FederatedResult is the merged result of a federated call
+type FederatedResult struct {
+	Instances []FederatedInstanceStatus `json:"instances"`
+	Rows      []map[string]interface{}  `json:"rows"`
+	EntityID  string                    `json:"entity_id,omitempty"`
+}
+
+// failed counts the instances that returned an error
+func (r *FederatedResult) failed() int {
+	failed := 0
+	for _, status := range r.Instances {
+		if status.Error != "" {
+			failed++
+		}
+	}
+	return failed
+}
+
+// federate runs fetch on every instance concurrently and merges the rows in instance order,
+// tagging each with its instance. A failing instance is reported in its status and leaves
+// the others unaffected.
+func federate(instances []federatedInstance, fetch func(federatedInstance) ([]map[string]interface{}, bool, error)) *FederatedResult {
+	statuses := make([]FederatedInstanceStatus, len(instances))
+	rows := make([][]map[string]interface{}, len(instances))
+
+	var wg sync.WaitGroup
+	for i, instance := range instances {
+		wg.Add(1)
+		go func(i int, instance federatedInstance) {
+			defer wg.Done()
+			start := time.Now()
+			status := FederatedInstanceStatus{Instance: instance.name, NetworkID: instance.networkID}
+			var items []map[string]interface{}
+			var err error
+			if instance.networkID == "" {
+				err = fmt.Errorf("no network configured for this instance")
+			} else {
+				items, status.Truncated, err = fetch(instance)
+			}
+			status.Duration = time.Since(start).Round(time.Millisecond).String()
+			if err != nil {
+				status.Error = err.Error()
+			}
+			status.Rows = len(items)
+			statuses[i] = status
+			rows[i] = items
+		}(i, instance)
+	}
+	wg.Wait()
+
+	result := &FederatedResult{Instances: statuses, Rows: []map[string]interface{}{}}
+	for i, items := range rows {
+		for _, item := range items {
+			row := make(map[string]interface{}, len(item)+1)
+			for key, value := range item {
+				row[key] = value
+			}
+			if existing, ok := row[federationInstanceColumn]; ok {
+				row["row_"+federationInstanceColumn] = existing
+			}
+			row[federationInstanceColumn] = instances[i].name
+			result.Rows = append(result.Rows, row)
+		}
+	}
+	return result
+}
+
+// fetchFederatedNQE runs an NQE query on one instance, page by page up to the row limit, or
+// to maxFederatedRowsPerInstance when all is set. It reports whether rows were left out.
+func fetchFederatedNQE(client forward.ClientInterface, params forward.NQEQueryParams, limit int, all bool) ([]map[string]interface{}, bool, error) {
+	run := client.RunNQEQueryByID
+	if params.QueryID == "" {
+		run = client.RunNQEQueryByString
+	}
+	maxRows := limit
+	if all {
+		maxRows = maxFederatedRowsPerInstance
+	}
+
+	var items []map[string]interface{}
+	for offset := 0; offset < maxRows; offset += limit {
+		page := params
+		page.Options = &forward.NQEQueryOptions{Limit: limit, Offset: offset}
+		result, err := run(&page)
+		if err != nil {
+			return items, false, err
+		}
+		if result == nil {
+			break
+		}
+		items = append(items, result.Items...)
+		if len(result.Items) < limit {
+			return items, false, nil
+		}
+	}
+	// A full last page may have been followed by more rows
+	return items, len(items) >= maxRows, nil
+}
+
+// localFederatedInstance is the primary instance on the given network
+func (s *ForwardMCPService) localFederatedInstance(networkID string) federatedInstance {
+	return federatedInstance{name: localInstanceName, networkID: networkID, client: s.forwardClient}
+}
+
+// runFederatedQuery runs one NQE query on every federated instance and merges the rows
+func (s *ForwardMCPService) runFederatedQuery(args RunFederatedQueryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("run_federated_query", args, nil)
+
+	if s.federation == nil {
+		return nil, fmt.Errorf("no federated instances are configured (set federation or FORWARD_FEDERATION_FILE)")
+	}
+	if (args.QueryID == "") == (args.Query == "") {
+		return nil, fmt.Errorf("set exactly one of query_id and query")
+	}
+	format := strings.ToLower(args.Format)
+	if format != "" && format != "markdown" && format != "json" {
+		return nil, fmt.Errorf("invalid format '%s' (expected markdown or json)", args.Format)
+	}
+	instances, err := s.federation.members(s.localFederatedInstance(s.getNetworkID(args.NetworkID)), args.Instances)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := s.getQueryLimit(args.Limit)
+	if limit <= 0 {
+		limit = 1000
+	}
+	params := forward.NQEQueryParams{QueryID: args.QueryID, Query: args.Query, Parameters: args.Parameters}
+	result := federate(instances, func(instance federatedInstance) ([]map[string]interface{}, bool, error) {
+		page := params
+		page.NetworkID = instance.networkID
+		return fetchFederatedNQE(instance.client, page, limit, args.AllResults)
+	})
+	for _, status := range result.Instances {
+		if status.Error != "" {
+			s.logger.Warn("Federated query on instance %s failed: %s", status.Instance, status.Error)
+		}
+	}
+
+	// Keep the merged rows so they can be analyzed like any other stored result
+	name := firstNonEmpty(args.QueryID, "adhoc")
+	if s.memorySystem != nil && len(result.Rows) > 0 {
+		stored := &forward.NQERunResult{Items: result.Rows}
+		if id, err := s.memorySystem.StoreNQEResultWithChunking(name, federationNetworkID, "latest", stored, s.chunkTargetBytes()); err != nil {
+			s.logger.Warn("Failed to store federated result: %v", err)
+		} else {
+			result.EntityID = id
+		}
+	}
+
+	if format == "json" {
+		return s.streamResponse("run_federated_query", MarshalCompactJSONString(result)), nil
+	}
+	return s.streamResponse("run_federated_query", formatFederatedResult("Federated query "+name, result)), nil
+}
+
+// getFederatedInventory lists the devices of every federated instance
+func (s *ForwardMCPService) getFederatedInventory(args GetFederatedInventoryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_federated_inventory", args, nil)
+
+	if s.federation == nil {
+		return nil, fmt.Errorf("no federated instances are configured (set federation or FORWARD_FEDERATION_FILE)")
+	}
+	format := strings.ToLower(args.Format)
+	if format != "" && format != "markdown" && format != "json" {
+		return nil, fmt.Errorf("invalid format '%s' (expected markdown or json)", args.Format)
+	}
+	instances, err := s.federation.members(s.localFederatedInstance(s.getNetworkID(args.NetworkID)), args.Instances)
+	if err != nil {
+		return nil, err
+	}
+
+	result := federate(instances, func(instance federatedInstance) ([]map[string]interface{}, bool, error) {
+		var devices []forward.Device
+		var err error
+		if instance.name == localInstanceName {
+			// The primary instance's inventory is shared with the other device tools
+			devices, err = s.getNetworkDevices(instance.networkID, "")
+		} else {
+			var response *forward.DeviceResponse
+			response, err = instance.client.GetDevices(instance.networkID, nil)
+			if response != nil {
+				devices = response.Devices
+			}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		rows := make([]map[string]interface{}, 0, len(devices))
+		for _, device := range devices {
+			facts := s.vendorNormalizer.NormalizeDevice(device)
+			rows = append(rows, map[string]interface{}{
+				"name":          device.Name,
+				"vendor":        facts.Vendor,
+				"family":        facts.Family,
+				"platform":      device.Platform,
+				"model":         device.Model,
+				"os_version":    firstNonEmpty(device.OSVersion, device.Version),
+				"management_ip": strings.Join(device.ManagementIPs, ","),
+			})
+		}
+		return rows, false, nil
+	})
+
+	if format == "json" {
+		return s.streamResponse("get_federated_inventory", MarshalCompactJSONString(result)), nil
+	}
+
+	// Summarize the merged inventory per instance and platform family
+	families := make(map[string]map[string]int)
+	for _, row := range result.Rows {
+		instance := row[federationInstanceColumn].(string)
+		if families[instance] == nil {
+			families[instance] = make(map[string]int)
+		}
+		families[instance][firstNonEmpty(row["family"].(string), unrecognizedFamily)]++
+	}
+	text := formatFederatedResult("Federated inventory", result)
+	var out strings.Builder
+	out.WriteString(text)
+	if len(families) > 0 {
+		out.WriteString("\n### Platforms\n\n")
+		for _, status := range result.Instances {
+			counts := families[status.Instance]
+			if len(counts) == 0 {
+				continue
+			}
+			var parts []string
+			for family, count := range counts {
+				parts = append(parts, fmt.Sprintf("%s %d", family, count))
+			}
+			sort.Strings(parts)
+			out.WriteString(fmt.Sprintf("- **%s**: %s\n", status.Instance, strings.Join(parts, ", ")))
+		}
+	}
+	return s.streamResponse("get_federated_inventory", out.String()), nil
+}
+
+// formatFederatedResult renders the per-instance outcome, a preview of the merged rows and
+// where the full result is stored
+func formatFederatedResult(title string, result *FederatedResult) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("## %s\n\n", title))
+	out.WriteString(fmt.Sprintf("%d rows from %d of %d instances.\n\n", len(result.Rows), len(result.Instances)-result.failed(), len(result.Instances)))
+
+	out.WriteString("| Instance | Network | Rows | Time | Status |\n|---|---|---|---|---|\n")
+	for _, status := range result.Instances {
+		state := "ok"
+		if status.Error != "" {
+			state = "failed: " + status.Error
+		} else if status.Truncated {
+			state = "truncated"
+		}
+		out.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s |\n", status.Instance, status.NetworkID, status.Rows, status.Duration, state))
+	}
+
+	if len(result.Rows) > 0 {
+		preview := result.Rows
+		if len(preview) > federationPreviewRows {
+			preview = preview[:federationPreviewRows]
+		}
+		previewJSON, _ := json.MarshalIndent(preview, "", "  ")
+		out.WriteString(fmt.Sprintf("\nPreview (first %d rows):\n%s\n", len(preview), string(previewJSON)))
+	}
+	if result.EntityID != "" {
+		out.WriteString(fmt.Sprintf("\nStored in memory system as entity: %s\n", result.EntityID))
+		out.WriteString("Use analyze_nqe_result_sql to compare instances, e.g. GROUP BY instance.\n")
+	}
+	if result.failed() > 0 {
+		out.WriteString("\n⚠️ Results are incomplete: some instances failed. Their rows are missing from the merge.\n")
+	}
+	return out.String()
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+)
+
+func newFederationTestService(t *testing.T) (*ForwardMCPService, *MockForwardClient) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.deviceCache = nil
+
+	emea := NewMockForwardClient()
+	emea.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"device": "lon-1", "instance": "prod"},
+		{"device": "par-1", "instance": "prod"},
+	}}
+	emea.devices = []forward.Device{{Name: "lon-1", Vendor: "JUNIPER", Platform: "JUNOS"}}
+	broken := NewMockForwardClient()
+	broken.shouldError = true
+	broken.errorMessage = "401 unauthorized"
+
+	service.federation = &Federation{}
+	service.federation.add("emea", "300", emea)
+	service.federation.add("apac", "400", broken)
+	service.federation.add("lab", "", NewMockForwardClient())
+	return service, emea
+}
+
+func TestRunFederatedQuery(t *testing.T) {
+	service, _ := newFederationTestService(t)
+	service.forwardClient.(*MockForwardClient).nqeResult = pageResult(3)
+
+	response, err := service.runFederatedQuery(RunFederatedQueryArgs{NetworkID: "162112", QueryID: "FQ_devices", Limit: 100, Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var result FederatedResult
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Rows) != 5 || result.Rows[0]["instance"] != "local" || result.Rows[3]["instance"] != "emea" {
+		t.Fatalf("Expected the local rows followed by the emea rows, got %v", result.Rows)
+	}
+	if result.Rows[3]["row_instance"] != "prod" {
+		t.Errorf("Expected the row's own instance column kept, got %v", result.Rows[3])
+	}
+	statuses := result.Instances
+	if len(statuses) != 4 || statuses[1].Rows != 2 || !strings.Contains(statuses[2].Error, "401 unauthorized") || statuses[3].Error == "" {
+		t.Errorf("Expected the failing and unconfigured instances reported, got %+v", statuses)
+	}
+	if result.EntityID == "" {
+		t.Error("Expected the merged result stored")
+	}
+
+	// Markdown, limited to instances by name
+	response, err = service.runFederatedQuery(RunFederatedQueryArgs{NetworkID: "162112", Query: "foreach d in network.devices select {name: d.name}", Instances: []string{"emea", "apac"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"2 rows from 1 of 2 instances", "| emea | 300 | 2 |", "failed: 401 unauthorized", "Results are incomplete"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	if _, err := service.runFederatedQuery(RunFederatedQueryArgs{QueryID: "FQ_devices", Instances: []string{"us"}}); err == nil {
+		t.Error("Expected an unknown instance to be rejected")
+	}
+	if _, err := service.runFederatedQuery(RunFederatedQueryArgs{QueryID: "FQ_devices", Query: "x"}); err == nil {
+		t.Error("Expected query_id and query to be rejected together")
+	}
+	service.federation = nil
+	if _, err := service.runFederatedQuery(RunFederatedQueryArgs{QueryID: "FQ_devices"}); err == nil {
+		t.Error("Expected an error without federated instances")
+	}
+}
+
+func TestFetchFederatedNQEPages(t *testing.T) {
+	client := NewMockForwardClient()
+	client.nqeResult = pageResult(25)
+
+	items, truncated, err := fetchFederatedNQE(client, forward.NQEQueryParams{QueryID: "FQ_1"}, 10, false)
+	if err != nil || len(items) != 10 || !truncated {
+		t.Errorf("Expected one truncated page, got %d rows, truncated %v, %v", len(items), truncated, err)
+	}
+	items, truncated, err = fetchFederatedNQE(client, forward.NQEQueryParams{QueryID: "FQ_1"}, 10, true)
+	if err != nil || len(items) != 25 || truncated {
+		t.Errorf("Expected every row, got %d rows, truncated %v, %v", len(items), truncated, err)
+	}
+}
+
+func TestGetFederatedInventory(t *testing.T) {
+	service, _ := newFederationTestService(t)
+	service.forwardClient.(*MockForwardClient).devices = []forward.Device{
+		{Name: "core-1", Vendor: "CISCO", Platform: "NX_OS"},
+		{Name: "core-2", Vendor: "CISCO", Platform: "NX_OS"},
+	}
+
+	response, err := service.getFederatedInventory(GetFederatedInventoryArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"3 rows from 2 of 4 instances", "- **local**: nx_os 2", "- **emea**: junos 1", "no network configured"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+}
+
+func TestNewFederation(t *testing.T) {
+	primary := config.ForwardConfig{APIBaseURL: "https://fwd.example.com", Timeout: 10}
+	federation, err := NewFederation(primary, []config.FederatedInstanceConfig{
+		{Name: "emea", APIBaseURL: "https://fwd.emea.example.com", APIKey: "k", APISecret: "s", NetworkID: "300"},
+	})
+	if err != nil || len(federation.instances) != 1 || federation.instances[0].networkID != "300" {
+		t.Fatalf("Expected one instance, got %+v %v", federation, err)
+	}
+
+	if federation, err := NewFederation(primary, nil); federation != nil || err != nil {
+		t.Error("Expected federation off without instances")
+	}
+	for _, instances := range [][]config.FederatedInstanceConfig{
+		{{Name: "local", APIBaseURL: "https://a", APIKey: "k", APISecret: "s"}},
+		{{Name: "emea", APIBaseURL: "https://a"}},
+		{{APIBaseURL: "https://a", APIKey: "k", APISecret: "s"}},
+	} {
+		if _, err := NewFederation(primary, instances); err == nil {
+			t.Errorf("Expected %+v to be rejected", instances)
+		}
+	}
+}
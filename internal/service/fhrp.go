@@ -0,0 +1,374 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// fhrpMembersQuery returns one row per HSRP or VRRP group configured on an interface address,
+// with the group's virtual addresses, priority and current state
+const fhrpMembersQuery = `foreach device in network.devices
+foreach iface in device.interfaces
+foreach subiface in iface.subinterfaces
+foreach address in subiface.ipv4.addresses
+foreach group in address.vrrpGroups
+select {
+  device: device.name,
+  location: device.locationName,
+  interface: iface.name,
+  address: toString(address.ip) + "/" + toString(address.prefixLength),
+  protocol: toString(group.protocol),
+  group: group.virtualRouterId,
+  virtualAddresses: (foreach v in group.virtualAddresses select toString(v)),
+  priority: group.priority,
+  preempt: group.preempt,
+  state: toString(group.state)
+}`
+
+const (
+	maxFHRPReportRows      = 50
+	fhrpUnknownSite        = "unknown"
+	fhrpRoleActive         = "active"
+	fhrpRoleStandby        = "standby"
+	fhrpRoleOther          = "other"
+	fhrpIssueNoStandby     = "no_standby"
+	fhrpIssueNoActive      = "no_active"
+	fhrpIssueMultiActive   = "multiple_active"
+	fhrpIssueVIPMismatch   = "vip_mismatch"
+	fhrpIssuePriorityTie   = "priority_tie"
+	fhrpIssuePriorityOrder = "active_not_highest_priority"
+)
+
+var fhrpIssueTypes = []string{fhrpIssueMultiActive, fhrpIssueNoActive, fhrpIssueNoStandby, fhrpIssueVIPMismatch, fhrpIssuePriorityTie, fhrpIssuePriorityOrder}
+
+// FHRPMember is one device's participation in an HSRP or VRRP group
+type FHRPMember struct {
+	Device     string   `json:"device"`
+	Location   string   `json:"location"`
+	Interface  string   `json:"interface"`
+	Address    string   `json:"address,omitempty"`
+	Protocol   string   `json:"protocol"`
+	Group      int      `json:"group"`
+	VIPs       []string `json:"virtual_addresses"`
+	Priority   int      `json:"priority"`
+	Preempt    bool     `json:"preempt"`
+	State      string   `json:"state"`
+	Role       string   `json:"role"`
+	segmentKey string
+}
+
+// FHRPGroup is an HSRP or VRRP group on one segment with the issues found between its members
+type FHRPGroup struct {
+	Protocol string       `json:"protocol"`
+	Group    int          `json:"group"`
+	Segment  string       `json:"segment"`
+	Location string       `json:"location"`
+	VIPs     []string     `json:"virtual_addresses"`
+	Members  []FHRPMember `json:"members"`
+	Issues   []string     `json:"issues,omitempty"`
+	Detail   string       `json:"detail,omitempty"`
+}
+
+// FHRPSiteSummary counts groups and issues per site
+type FHRPSiteSummary struct {
+	Location  string `json:"location"`
+	Groups    int    `json:"groups"`
+	Healthy   int    `json:"healthy"`
+	Unhealthy int    `json:"unhealthy"`
+}
+
+// fhrpRole maps HSRP and VRRP states to the role a member plays in its group
+func fhrpRole(state string) string {
+	switch state = strings.ToLower(state); {
+	case strings.Contains(state, "active"), strings.Contains(state, "master"):
+		return fhrpRoleActive
+	case strings.Contains(state, "standby"), strings.Contains(state, "backup"):
+		return fhrpRoleStandby
+	default:
+		return fhrpRoleOther
+	}
+}
+
+// fhrpProtocol names the protocol of a group, defaulting to VRRP (the model's group type)
+func fhrpProtocol(value string) string {
+	value = strings.ToLower(value)
+	switch {
+	case strings.Contains(value, "hsrp"):
+		return "HSRP"
+	case strings.Contains(value, "glbp"):
+		return "GLBP"
+	default:
+		return "VRRP"
+	}
+}
+
+// parseFHRPMembers converts query rows. Members are grouped by protocol, group number and the
+// subnet of the interface address, since group numbers are reused on other segments.
+func parseFHRPMembers(items []map[string]interface{}) []FHRPMember {
+	members := make([]FHRPMember, 0, len(items))
+	for _, item := range items {
+		member := FHRPMember{
+			Device:    resultValueString(item["device"]),
+			Location:  firstNonEmpty(resultValueString(item["location"]), fhrpUnknownSite),
+			Interface: resultValueString(item["interface"]),
+			Address:   resultValueString(item["address"]),
+			Protocol:  fhrpProtocol(resultValueString(item["protocol"])),
+			State:     strings.ToUpper(resultValueString(item["state"])),
+			Preempt:   strings.EqualFold(resultValueString(item["preempt"]), "true"),
+		}
+		if member.Device == "" {
+			continue
+		}
+		member.Group, _ = strconv.Atoi(firstListValue(item["group"]))
+		member.Priority, _ = strconv.Atoi(resultValueString(item["priority"]))
+		member.Role = fhrpRole(member.State)
+		switch vips := item["virtualAddresses"].(type) {
+		case []interface{}:
+			for _, vip := range vips {
+				if value := resultValueString(vip); value != "" {
+					member.VIPs = append(member.VIPs, value)
+				}
+			}
+		case string:
+			for _, vip := range strings.FieldsFunc(vips, func(r rune) bool { return r == ',' || r == ' ' }) {
+				member.VIPs = append(member.VIPs, vip)
+			}
+		}
+		sort.Strings(member.VIPs)
+
+		segment := strings.Join(member.VIPs, ",")
+		if prefix, err := netip.ParsePrefix(member.Address); err == nil {
+			segment = prefix.Masked().String()
+		}
+		member.segmentKey = fmt.Sprintf("%s\x00%d\x00%s", member.Protocol, member.Group, segment)
+		members = append(members, member)
+	}
+	return members
+}
+
+// checkFHRPGroups groups the members and flags groups without exactly one active and at
+// least one standby member, with members disagreeing on the virtual addresses, and with
+// priorities that do not decide or contradict which member is active
+func checkFHRPGroups(members []FHRPMember) []FHRPGroup {
+	byKey := make(map[string]*FHRPGroup)
+	var keys []string
+	for _, member := range members {
+		group := byKey[member.segmentKey]
+		if group == nil {
+			segment := member.segmentKey[strings.LastIndex(member.segmentKey, "\x00")+1:]
+			group = &FHRPGroup{Protocol: member.Protocol, Group: member.Group, Segment: segment, Location: member.Location}
+			byKey[member.segmentKey] = group
+			keys = append(keys, member.segmentKey)
+		}
+		group.Members = append(group.Members, member)
+	}
+
+	groups := make([]FHRPGroup, 0, len(keys))
+	for _, key := range keys {
+		group := byKey[key]
+		sort.Slice(group.Members, func(i, j int) bool { return group.Members[i].Device < group.Members[j].Device })
+		var active, standby []FHRPMember
+		vips := make(map[string]bool)
+		vipSets := make(map[string]bool)
+		highest := -1
+		for _, member := range group.Members {
+			switch member.Role {
+			case fhrpRoleActive:
+				active = append(active, member)
+			case fhrpRoleStandby:
+				standby = append(standby, member)
+			}
+			for _, vip := range member.VIPs {
+				vips[vip] = true
+			}
+			vipSets[strings.Join(member.VIPs, ",")] = true
+			highest = max(highest, member.Priority)
+		}
+		for vip := range vips {
+			group.VIPs = append(group.VIPs, vip)
+		}
+		sort.Strings(group.VIPs)
+
+		var details []string
+		switch {
+		case len(active) > 1:
+			group.Issues = append(group.Issues, fhrpIssueMultiActive)
+			names := make([]string, len(active))
+			for i, member := range active {
+				names[i] = member.Device
+			}
+			details = append(details, "active on "+strings.Join(names, " and "))
+		case len(active) == 0:
+			group.Issues = append(group.Issues, fhrpIssueNoActive)
+			details = append(details, "no member is active")
+		}
+		if len(standby) == 0 && len(active) <= 1 {
+			group.Issues = append(group.Issues, fhrpIssueNoStandby)
+			if len(group.Members) == 1 {
+				details = append(details, "single member")
+			} else {
+				details = append(details, "no member is standby")
+			}
+		}
+		if len(vipSets) > 1 {
+			group.Issues = append(group.Issues, fhrpIssueVIPMismatch)
+			details = append(details, "members disagree on the virtual address")
+		}
+		if len(group.Members) > 1 {
+			tied := 0
+			for _, member := range group.Members {
+				if member.Priority == highest {
+					tied++
+				}
+			}
+			if tied > 1 {
+				group.Issues = append(group.Issues, fhrpIssuePriorityTie)
+				details = append(details, fmt.Sprintf("%d members share priority %d", tied, highest))
+			} else if len(active) == 1 && active[0].Priority < highest {
+				group.Issues = append(group.Issues, fhrpIssuePriorityOrder)
+				details = append(details, fmt.Sprintf("%s is active with priority %d below %d", active[0].Device, active[0].Priority, highest))
+			}
+		}
+		group.Detail = strings.Join(details, "; ")
+		groups = append(groups, *group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		if (len(groups[i].Issues) > 0) != (len(groups[j].Issues) > 0) {
+			return len(groups[i].Issues) > 0
+		}
+		if groups[i].Location != groups[j].Location {
+			return groups[i].Location < groups[j].Location
+		}
+		return groups[i].Group < groups[j].Group
+	})
+	return groups
+}
+
+// fhrpSiteSummaries counts healthy and unhealthy groups per site
+func fhrpSiteSummaries(groups []FHRPGroup) []FHRPSiteSummary {
+	sites := make(map[string]*FHRPSiteSummary)
+	for _, group := range groups {
+		site := sites[group.Location]
+		if site == nil {
+			site = &FHRPSiteSummary{Location: group.Location}
+			sites[group.Location] = site
+		}
+		site.Groups++
+		if len(group.Issues) > 0 {
+			site.Unhealthy++
+		} else {
+			site.Healthy++
+		}
+	}
+	summaries := make([]FHRPSiteSummary, 0, len(sites))
+	for _, site := range sites {
+		summaries = append(summaries, *site)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Location < summaries[j].Location })
+	return summaries
+}
+
+// checkFHRPTool reports the health of HSRP and VRRP groups with per-site rollups and the
+// gateway addresses of unhealthy groups
+func (s *ForwardMCPService) checkFHRPTool(args CheckFHRPArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("check_fhrp", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	items, err := s.fetchAllNQEQueryItems(networkID, snapshotID, fhrpMembersQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch first-hop redundancy groups: %w", err)
+	}
+	// Every member is needed to judge a group, so the site filter applies to the groups
+	groups := checkFHRPGroups(parseFHRPMembers(items))
+	if args.Location != "" {
+		filtered := groups[:0]
+		for _, group := range groups {
+			if strings.EqualFold(group.Location, args.Location) {
+				filtered = append(filtered, group)
+			}
+		}
+		groups = filtered
+	}
+	if len(groups) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("No HSRP or VRRP groups found in network %s.", networkID))), nil
+	}
+	sites := fhrpSiteSummaries(groups)
+	counts := make(map[string]int)
+	var affectedVIPs []string
+	unhealthy := 0
+	for _, group := range groups {
+		for _, issue := range group.Issues {
+			counts[issue]++
+		}
+		if len(group.Issues) > 0 {
+			unhealthy++
+			affectedVIPs = append(affectedVIPs, group.VIPs...)
+		}
+	}
+
+	if strings.EqualFold(args.Format, "json") {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"network_id":    networkID,
+			"sites":         sites,
+			"groups":        groups,
+			"affected_vips": affectedVIPs,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode first-hop redundancy report: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# First-Hop Redundancy: network %s\n\n", networkID))
+	if unhealthy == 0 {
+		report.WriteString(fmt.Sprintf("✅ All %d groups have one active and a standby member that agree on the virtual address and priorities.\n", len(groups)))
+	} else {
+		report.WriteString(fmt.Sprintf("❌ %d of %d groups unhealthy:", unhealthy, len(groups)))
+		for _, issueType := range fhrpIssueTypes {
+			if counts[issueType] > 0 {
+				report.WriteString(fmt.Sprintf(" %s ×%d", issueType, counts[issueType]))
+			}
+		}
+		report.WriteString("\n")
+	}
+
+	report.WriteString("\n## Sites\n\n| Site | Groups | Healthy | Unhealthy |\n|---|---|---|---|\n")
+	for _, site := range sites {
+		report.WriteString(fmt.Sprintf("| %s | %d | %d | %d |\n", site.Location, site.Groups, site.Healthy, site.Unhealthy))
+	}
+
+	if unhealthy > 0 {
+		report.WriteString(fmt.Sprintf("\n## Affected Gateway VIPs (%d)\n\n%s\n", len(affectedVIPs), firstNonEmpty(strings.Join(affectedVIPs, ", "), "(none configured)")))
+	}
+
+	report.WriteString("\n## Groups\n\n| Protocol | Group | Site | Segment | VIPs | Members | Issues |\n|---|---|---|---|---|---|---|\n")
+	for i, group := range groups {
+		if i == maxFHRPReportRows {
+			report.WriteString(fmt.Sprintf("\n… %d more groups (use format=json for all)\n", len(groups)-i))
+			break
+		}
+		members := make([]string, len(group.Members))
+		for j, member := range group.Members {
+			members[j] = fmt.Sprintf("%s %s (%s, %d)", member.Device, member.Interface, firstNonEmpty(member.State, "?"), member.Priority)
+		}
+		issues := "-"
+		if len(group.Issues) > 0 {
+			issues = strings.Join(group.Issues, ", ") + ": " + group.Detail
+		}
+		report.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %s | %s |\n", group.Protocol, group.Group, group.Location, group.Segment,
+			firstNonEmpty(strings.Join(group.VIPs, ", "), "-"), strings.Join(members, "; "), issues))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
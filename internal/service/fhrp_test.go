@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func fhrpTestService() *ForwardMCPService {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	member := func(device, location, address, protocol string, group float64, vip string, priority float64, state string) map[string]interface{} {
+		return map[string]interface{}{"device": device, "location": location, "interface": "Vlan" + strings.Split(address, ".")[2],
+			"address": address, "protocol": protocol, "group": group, "virtualAddresses": []interface{}{vip},
+			"priority": priority, "preempt": true, "state": state}
+	}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		// Healthy HSRP pair
+		member("core1", "hq", "10.0.10.2/24", "HSRP", 10, "10.0.10.1", 110, "ACTIVE"),
+		member("core2", "hq", "10.0.10.3/24", "HSRP", 10, "10.0.10.1", 100, "STANDBY"),
+		// Split brain with a priority tie; group 10 is reused on another segment
+		member("core1", "hq", "10.0.20.2/24", "HSRP", 10, "10.0.20.1", 100, "ACTIVE"),
+		member("core2", "hq", "10.0.20.3/24", "HSRP", 10, "10.0.20.1", 100, "ACTIVE"),
+		// VRRP members disagree on the VIP and the lower priority is master
+		member("dist1", "branch", "10.1.30.2/24", "VRRP", 30, "10.1.30.1", 90, "MASTER"),
+		member("dist2", "branch", "10.1.30.3/24", "VRRP", 30, "10.1.30.254", 120, "BACKUP"),
+		// A single member
+		member("dist1", "branch", "10.1.40.2/24", "", 40, "10.1.40.1", 100, "MASTER"),
+	}}
+	return service
+}
+
+func TestCheckFHRPGroups(t *testing.T) {
+	if fhrpRole("Active") != fhrpRoleActive || fhrpRole("MASTER") != fhrpRoleActive || fhrpRole("backup") != fhrpRoleStandby || fhrpRole("INIT") != fhrpRoleOther {
+		t.Error("Unexpected FHRP roles")
+	}
+
+	groups := checkFHRPGroups(parseFHRPMembers(fhrpTestService().forwardClient.(*MockForwardClient).nqeResult.Items))
+	issues := make(map[string][]string)
+	for _, group := range groups {
+		issues[group.Segment] = group.Issues
+	}
+	expected := map[string][]string{
+		"10.0.10.0/24": nil,
+		"10.0.20.0/24": {fhrpIssueMultiActive, fhrpIssuePriorityTie},
+		"10.1.30.0/24": {fhrpIssueVIPMismatch, fhrpIssuePriorityOrder},
+		"10.1.40.0/24": {fhrpIssueNoStandby},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("Expected %v, got %v", expected, issues)
+	}
+	if groups[len(groups)-1].Segment != "10.0.10.0/24" {
+		t.Errorf("Expected healthy groups last, got %+v", groups)
+	}
+}
+
+func TestCheckFHRPTool(t *testing.T) {
+	service := fhrpTestService()
+
+	response, err := service.checkFHRPTool(CheckFHRPArgs{NetworkID: "162112", Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report struct {
+		Sites        []FHRPSiteSummary `json:"sites"`
+		AffectedVIPs []string          `json:"affected_vips"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	if !reflect.DeepEqual(report.Sites, []FHRPSiteSummary{{"branch", 2, 0, 2}, {"hq", 2, 1, 1}}) {
+		t.Errorf("Unexpected site summaries: %+v", report.Sites)
+	}
+	if len(report.AffectedVIPs) != 4 {
+		t.Errorf("Expected the VIPs of the three unhealthy groups, got %v", report.AffectedVIPs)
+	}
+
+	response, err = service.checkFHRPTool(CheckFHRPArgs{NetworkID: "162112", Location: "HQ"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"❌ 1 of 2 groups unhealthy: multiple_active ×1 priority_tie ×1", "| hq | 2 | 1 | 1 |",
+		"## Affected Gateway VIPs (1)\n\n10.0.20.1", "active on core1 and core2; 2 members share priority 100",
+		"| HSRP | 10 | hq | 10.0.10.0/24 | 10.0.10.1 | core1 Vlan10 (ACTIVE, 110); core2 Vlan10 (STANDBY, 100) | - |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "branch") {
+		t.Errorf("Expected only hq groups: %s", text)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/forward-mcp/internal/logger"
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// maxHTTPRequestBytes bounds the JSON-RPC body accepted from a client
+const maxHTTPRequestBytes = 4 << 20
+
+// HTTPTransport serves MCP over stateless HTTP POST requests for shared deployments. Every
+// request must present a configured API key, whose identity travels in the request context
+// to the tool handlers that enforce its scopes.
+type HTTPTransport struct {
+	addr          string
+	path          string
+	authenticator *APIKeyAuthenticator
+	logger        *logger.Logger
+	server        *http.Server
+
+	mutex          sync.Mutex
+	messageHandler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	errorHandler   func(error)
+	closeHandler   func()
+	pending        map[transport.RequestId]chan *transport.BaseJsonRpcMessage
+	nextID         transport.RequestId
+}
+
+// NewHTTPTransport creates a transport listening on addr that serves MCP at path
+func NewHTTPTransport(addr, path string, authenticator *APIKeyAuthenticator, logger *logger.Logger) *HTTPTransport {
+	if path == "" {
+		path = "/mcp"
+	}
+	return &HTTPTransport{
+		addr:          addr,
+		path:          path,
+		authenticator: authenticator,
+		logger:        logger,
+		pending:       make(map[transport.RequestId]chan *transport.BaseJsonRpcMessage),
+	}
+}
+
+// Start implements transport.Transport, serving until Close is called
+func (t *HTTPTransport) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle(t.path, t)
+
+	t.mutex.Lock()
+	t.server = &http.Server{Addr: t.addr, Handler: mux}
+	server := t.server
+	t.mutex.Unlock()
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("HTTP transport failed: %w", err)
+	}
+	return nil
+}
+
+// Send implements transport.Transport, delivering a response to the request awaiting it.
+// Server-initiated messages have no HTTP request to ride on and are dropped.
+func (t *HTTPTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	var id transport.RequestId
+	switch {
+	case message.JsonRpcResponse != nil:
+		id = message.JsonRpcResponse.Id
+	case message.JsonRpcError != nil:
+		id = message.JsonRpcError.Id
+	default:
+		return nil
+	}
+
+	t.mutex.Lock()
+	responses, ok := t.pending[id]
+	delete(t.pending, id)
+	t.mutex.Unlock()
+	if !ok {
+		return fmt.Errorf("no pending HTTP request for response %d", id)
+	}
+	responses <- message
+	return nil
+}
+
+// Close implements transport.Transport
+func (t *HTTPTransport) Close() error {
+	t.mutex.Lock()
+	server, closeHandler := t.server, t.closeHandler
+	t.mutex.Unlock()
+
+	if server != nil {
+		if err := server.Close(); err != nil {
+			return err
+		}
+	}
+	if closeHandler != nil {
+		closeHandler()
+	}
+	return nil
+}
+
+// SetCloseHandler implements transport.Transport
+func (t *HTTPTransport) SetCloseHandler(handler func()) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.closeHandler = handler
+}
+
+// SetErrorHandler implements transport.Transport
+func (t *HTTPTransport) SetErrorHandler(handler func(error)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.errorHandler = handler
+}
+
+// SetMessageHandler implements transport.Transport
+func (t *HTTPTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.messageHandler = handler
+}
+
+// ServeHTTP authenticates a request and answers its JSON-RPC message
+func (t *HTTPTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+	identity := t.authenticator.Authenticate(presentedAPIKey(r))
+	if identity == nil {
+		t.logger.Warn("Audit: rejected HTTP request from %s with a missing or unknown API key", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="forward-mcp"`)
+		http.Error(w, "missing or invalid API key", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPRequestBytes))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	var envelope struct {
+		ID     *transport.RequestId `json:"id"`
+		Method string               `json:"method"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON-RPC message: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	t.mutex.Lock()
+	handler := t.messageHandler
+	t.mutex.Unlock()
+	if handler == nil {
+		http.Error(w, "server is not ready", http.StatusServiceUnavailable)
+		return
+	}
+	ctx := WithAPIKeyIdentity(r.Context(), identity)
+
+	// Notifications and client responses expect no reply
+	if envelope.Method == "" || envelope.ID == nil {
+		var notification transport.BaseJSONRPCNotification
+		if envelope.Method != "" && json.Unmarshal(body, &notification) == nil {
+			handler(ctx, transport.NewBaseMessageNotification(&notification))
+		}
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	var request transport.BaseJSONRPCRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		http.Error(w, fmt.Sprintf("invalid JSON-RPC request: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	// Requests from different clients may reuse IDs, so each gets a transport-unique ID
+	// that is swapped back into the response
+	responses := make(chan *transport.BaseJsonRpcMessage, 1)
+	t.mutex.Lock()
+	t.nextID++
+	request.Id = t.nextID
+	t.pending[request.Id] = responses
+	t.mutex.Unlock()
+
+	handler(ctx, transport.NewBaseMessageRequest(&request))
+
+	var response *transport.BaseJsonRpcMessage
+	select {
+	case response = <-responses:
+	case <-r.Context().Done():
+		t.mutex.Lock()
+		delete(t.pending, request.Id)
+		t.mutex.Unlock()
+		return
+	}
+	if response.JsonRpcResponse != nil {
+		response.JsonRpcResponse.Id = *envelope.ID
+	} else if response.JsonRpcError != nil {
+		response.JsonRpcError.Id = *envelope.ID
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		t.mutex.Lock()
+		errorHandler := t.errorHandler
+		t.mutex.Unlock()
+		if errorHandler != nil {
+			errorHandler(fmt.Errorf("failed to marshal response: %w", err))
+		}
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// presentedAPIKey returns the key from an "Authorization: Bearer" or "X-API-Key" header
+func presentedAPIKey(r *http.Request) string {
+	if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+		return key
+	}
+	scheme, token, found := strings.Cut(strings.TrimSpace(r.Header.Get("Authorization")), " ")
+	if found && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
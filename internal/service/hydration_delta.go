@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// maxDeltaListed caps the paths listed per change kind in a delta summary
+const maxDeltaListed = 20
+
+// HydrationDelta is the outcome of a delta hydration
+type HydrationDelta struct {
+	Added      []string      `json:"added"`      // Paths of new queries
+	Updated    []string      `json:"updated"`    // Paths of queries with a new commit or path
+	Deprecated []string      `json:"deprecated"` // Paths of queries missing upstream, now excluded from search
+	Restored   []string      `json:"restored"`   // Paths of deprecated queries listed again
+	Unchanged  int           `json:"unchanged"`
+	Failed     []string      `json:"failed,omitempty"`  // Paths whose details could not be fetched
+	Partial    bool          `json:"partial,omitempty"` // A repository could not be listed, so nothing was deprecated
+	Duration   time.Duration `json:"duration"`
+}
+
+// Changed reports whether the delta changed the database
+func (d *HydrationDelta) Changed() bool {
+	return len(d.Added)+len(d.Updated)+len(d.Deprecated)+len(d.Restored) > 0
+}
+
+// hydrateDelta lists both repositories and fetches only the queries whose last commit differs
+// from the stored one, then deprecates stored queries that no longer exist upstream
+func (db *NQEDatabase) hydrateDelta(ctx context.Context, client forward.ClientInterface, logger *logger.Logger, workers int) (*HydrationDelta, error) {
+	started := time.Now()
+	existing, err := db.LoadQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load stored queries: %w", err)
+	}
+	// Deprecated queries keep their commit, so one that comes back unchanged is not fetched again
+	deprecated, err := db.LoadDeprecatedQueries()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load deprecated queries: %w", err)
+	}
+	for _, query := range deprecated {
+		existing = append(existing, query.NQEQueryDetail)
+	}
+	summaries, complete, err := listQuerySummaries(ctx, client, logger)
+	if err != nil {
+		return nil, err
+	}
+	shards := buildHydrationShards(summaries)
+
+	storedByID := make(map[string]forward.NQEQueryDetail, len(existing))
+	existingCommitIDs := make(map[string]string, len(existing))
+	for _, query := range existing {
+		storedByID[query.QueryID] = query
+		if query.LastCommit.ID != "" {
+			existingCommitIDs[query.Path] = query.LastCommit.ID
+		}
+	}
+
+	delta := &HydrationDelta{Partial: !complete}
+	listed := make(map[string]bool)
+	changed := make(map[string]bool) // Paths that must be fetched
+	for _, shard := range shards {
+		for _, summary := range shard.queries {
+			listed[summary.QueryID] = true
+			stored, ok := storedByID[summary.QueryID]
+			switch {
+			case !ok:
+				delta.Added = append(delta.Added, summary.Path)
+			case stored.LastCommit.ID != summary.LastCommitId || stored.Path != summary.Path:
+				delta.Updated = append(delta.Updated, summary.Path)
+			default:
+				delta.Unchanged++
+				continue
+			}
+			changed[summary.Path] = true
+		}
+	}
+
+	// Fetch only the changed queries with the shard workers
+	var changedShards []hydrationShard
+	for _, shard := range shards {
+		var queries []forward.NQEOrgQuerySummary
+		for _, summary := range shard.queries {
+			if changed[summary.Path] {
+				queries = append(queries, summary)
+			}
+		}
+		if len(queries) > 0 {
+			shard.queries = queries
+			changedShards = append(changedShards, shard)
+		}
+	}
+	if len(changedShards) > 0 {
+		result, err := db.runHydrationShards(ctx, client, logger, changedShards, existingCommitIDs, workers, false)
+		if err != nil && (result == nil || result.Fetched == 0) {
+			return nil, err
+		}
+		fetched := make(map[string]bool)
+		for _, query := range result.Queries {
+			fetched[query.Path] = true
+		}
+		for path := range changed {
+			if !fetched[path] {
+				delta.Failed = append(delta.Failed, path)
+			}
+		}
+	}
+
+	// Deprecations are only known when both repositories were listed
+	delta.Deprecated, delta.Restored, err = db.syncDeprecations(listed, complete)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, paths := range [][]string{delta.Added, delta.Updated, delta.Failed} {
+		sort.Strings(paths)
+	}
+	if delta.Changed() {
+		db.notifyUpdateCallbacks()
+	}
+	if err := db.SetMetadata("last_sync", time.Now().Format(time.RFC3339)); err != nil {
+		logger.Warn("🔄 Failed to update sync time: %v", err)
+	}
+	delta.Duration = time.Since(started)
+	logger.Info("🔄 Delta hydration: %d added, %d updated, %d deprecated, %d restored, %d unchanged, %d failed in %s",
+		len(delta.Added), len(delta.Updated), len(delta.Deprecated), len(delta.Restored), delta.Unchanged, len(delta.Failed), delta.Duration.Round(time.Millisecond))
+	return delta, nil
+}
+
+// String renders the summary returned by the hydrate_database tool
+func (d *HydrationDelta) String() string {
+	return formatHydrationDelta(d)
+}
+
+// formatHydrationDelta renders a delta hydration summary
+func formatHydrationDelta(delta *HydrationDelta) string {
+	var result strings.Builder
+	result.WriteString("Delta Hydration Complete\n\n")
+	result.WriteString(fmt.Sprintf("Added: %d | Updated: %d | Deprecated: %d | Restored: %d | Unchanged: %d | Failed: %d | Took: %s\n",
+		len(delta.Added), len(delta.Updated), len(delta.Deprecated), len(delta.Restored), delta.Unchanged, len(delta.Failed), delta.Duration.Round(time.Millisecond)))
+	if delta.Partial {
+		result.WriteString("\n⚠️ One repository could not be listed, so no queries were deprecated.\n")
+	}
+	for _, section := range []struct {
+		title string
+		paths []string
+	}{{"Added", delta.Added}, {"Updated", delta.Updated}, {"Deprecated", delta.Deprecated}, {"Restored", delta.Restored}, {"Failed to fetch", delta.Failed}} {
+		if len(section.paths) == 0 {
+			continue
+		}
+		result.WriteString(fmt.Sprintf("\n%s:\n", section.title))
+		for _, path := range section.paths[:min(len(section.paths), maxDeltaListed)] {
+			result.WriteString(fmt.Sprintf("- %s\n", path))
+		}
+		if len(section.paths) > maxDeltaListed {
+			result.WriteString(fmt.Sprintf("- … %d more\n", len(section.paths)-maxDeltaListed))
+		}
+	}
+	if !delta.Changed() {
+		result.WriteString("\nThe database is up to date.\n")
+	}
+	return result.String()
+}
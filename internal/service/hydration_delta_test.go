@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// summaryClient serves fixed repository listings and records which queries are fetched
+type summaryClient struct {
+	*MockForwardClient
+	summaries map[string][]forward.NQEOrgQuerySummary
+	mutex     sync.Mutex
+	fetched   []string
+}
+
+func (c *summaryClient) GetNQEQuerySummariesContext(ctx context.Context, repository string) ([]forward.NQEOrgQuerySummary, error) {
+	list, ok := c.summaries[repository]
+	if !ok {
+		return nil, &MockError{"repository unavailable"}
+	}
+	return list, nil
+}
+
+func (c *summaryClient) GetNQEQueryByCommitWithContext(ctx context.Context, commitID string, path string, repository string) (*forward.NQEQueryDetail, error) {
+	c.mutex.Lock()
+	c.fetched = append(c.fetched, path)
+	c.mutex.Unlock()
+	return c.MockForwardClient.GetNQEQueryByCommitWithContext(ctx, commitID, path, repository)
+}
+
+func TestHydrateDelta(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	log := logger.New()
+	db, err := NewNQEDatabase(log, "delta-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	client := &summaryClient{MockForwardClient: NewMockForwardClient(), summaries: map[string][]forward.NQEOrgQuerySummary{
+		"org": {
+			{Path: "/L3/Routes", QueryID: "Q1", LastCommitId: "c1"},
+			{Path: "/L3/BGP", QueryID: "Q2", LastCommitId: "c1"},
+		},
+		"fwd": {{Path: "/Forward/Interfaces", QueryID: "Q3", LastCommitId: "c1"}},
+	}}
+	delta, err := db.hydrateDelta(context.Background(), client, log, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(delta.Added) != 3 || len(delta.Updated) != 0 || len(client.fetched) != 3 {
+		t.Errorf("Expected every query added on an empty database, got %+v", delta)
+	}
+
+	// Q1 gets a new commit, Q2 is deleted and Q4 is new; only Q1 and Q4 are fetched
+	client.summaries["org"] = []forward.NQEOrgQuerySummary{
+		{Path: "/L3/Routes", QueryID: "Q1", LastCommitId: "c2"},
+		{Path: "/L3/OSPF", QueryID: "Q4", LastCommitId: "c1"},
+	}
+	client.fetched = nil
+	delta, err = db.hydrateDelta(context.Background(), client, log, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if strings.Join(delta.Added, ",") != "/L3/OSPF" || strings.Join(delta.Updated, ",") != "/L3/Routes" ||
+		strings.Join(delta.Deprecated, ",") != "/L3/BGP" || delta.Unchanged != 1 || len(client.fetched) != 2 {
+		t.Errorf("Unexpected delta: %+v (fetched %v)", delta, client.fetched)
+	}
+	if query, err := db.GetQuery("Q1"); err != nil || query.LastCommit.ID != "c2" {
+		t.Errorf("Expected Q1 at its new commit, got %+v (%v)", query, err)
+	}
+	if count, _ := db.GetQueryCount(); count != 3 {
+		t.Errorf("Expected the deleted query to be deprecated, got %d active queries", count)
+	}
+
+	// Without a complete listing nothing is deprecated
+	delete(client.summaries, "fwd")
+	client.fetched = nil
+	delta, err = db.hydrateDelta(context.Background(), client, log, 2)
+	if err != nil || !delta.Partial || len(delta.Deprecated) != 0 || delta.Changed() || len(client.fetched) != 0 {
+		t.Errorf("Expected a partial no-op delta, got %+v (%v)", delta, err)
+	}
+	if text := formatHydrationDelta(delta); !strings.Contains(text, "no queries were deprecated") || !strings.Contains(text, "up to date") {
+		t.Errorf("Unexpected summary: %s", text)
+	}
+}
+
+func TestHydrateDatabaseDelta(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	db, err := NewNQEDatabase(service.logger, "delta-tool-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	service.database = db
+	service.forwardClient.(*MockForwardClient).nqeQueries = []forward.NQEQuery{{QueryID: "Q1", Path: "/L3/Routes", Repository: "org"}}
+
+	response, err := service.hydrateDatabase(HydrateDatabaseArgs{Delta: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Added: 1 | Updated: 0 | Deprecated: 0") || !strings.Contains(text, "- /L3/Routes") {
+		t.Errorf("Unexpected delta summary: %s", text)
+	}
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// Enhanced hydration is sharded by repository directory. A pool of workers fetches the query
+// details of each shard, saving them and a checkpoint as the shard finishes, so a run that is
+// interrupted resumes with the shards that did not complete instead of starting over.
+const (
+	defaultHydrationWorkers = 4
+	maxHydrationWorkers     = 16
+)
+
+// Hydration shard states
+const (
+	shardRunning  = "running"
+	shardComplete = "complete"
+	shardFailed   = "failed"
+)
+
+// hydrationShard is the queries of one repository directory
+type hydrationShard struct {
+	key         string // <repository>:<directory>
+	repository  string
+	directory   string
+	fingerprint string // Changes when a query in the shard is added, removed or committed
+	queries     []forward.NQEOrgQuerySummary
+}
+
+// HydrationCheckpoint is the stored progress of one hydration shard
+type HydrationCheckpoint struct {
+	Shard       string    `json:"shard"`
+	Repository  string    `json:"repository"`
+	Directory   string    `json:"directory"`
+	Fingerprint string    `json:"fingerprint"`
+	Total       int       `json:"total"`
+	Fetched     int       `json:"fetched"`
+	Unchanged   int       `json:"unchanged"`
+	Failed      int       `json:"failed"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// HydrationResult summarizes a sharded hydration run
+type HydrationResult struct {
+	Shards        int
+	Resumed       int // Shards skipped because their checkpoint is complete and current
+	Completed     int
+	Failed        int
+	Fetched       int
+	Unchanged     int
+	FailedQueries int
+	Queries       []forward.NQEQueryDetail // Details fetched by this run
+	Deprecated    []string                 // Paths of stored queries missing from the listing
+	Restored      []string                 // Paths of deprecated queries listed again
+}
+
+// buildHydrationShards groups query summaries by repository and directory. Org queries take
+// precedence, so fwd queries with the same ID are left out.
+func buildHydrationShards(summaries map[string][]forward.NQEOrgQuerySummary) []hydrationShard {
+	orgIDs := make(map[string]bool)
+	for _, summary := range summaries["org"] {
+		orgIDs[summary.QueryID] = true
+	}
+
+	byKey := make(map[string]*hydrationShard)
+	for _, repository := range []string{"org", "fwd"} {
+		for _, summary := range summaries[repository] {
+			if repository == "fwd" && orgIDs[summary.QueryID] {
+				continue
+			}
+			directory := path.Dir(summary.Path)
+			key := repository + ":" + directory
+			shard, ok := byKey[key]
+			if !ok {
+				shard = &hydrationShard{key: key, repository: repository, directory: directory}
+				byKey[key] = shard
+			}
+			shard.queries = append(shard.queries, summary)
+		}
+	}
+
+	shards := make([]hydrationShard, 0, len(byKey))
+	for _, shard := range byKey {
+		sort.Slice(shard.queries, func(i, j int) bool { return shard.queries[i].Path < shard.queries[j].Path })
+		hash := sha256.New()
+		for _, summary := range shard.queries {
+			fmt.Fprintf(hash, "%s@%s\n", summary.Path, summary.LastCommitId)
+		}
+		shard.fingerprint = hex.EncodeToString(hash.Sum(nil))[:16]
+		shards = append(shards, *shard)
+	}
+	sort.Slice(shards, func(i, j int) bool { return shards[i].key < shards[j].key })
+	return shards
+}
+
+// saveHydrationCheckpoint stores the progress of a shard
+func (db *NQEDatabase) saveHydrationCheckpoint(checkpoint HydrationCheckpoint) error {
+	_, err := db.db.Exec(`
+		INSERT OR REPLACE INTO hydration_checkpoints (
+			instance_id, shard, repository, directory, fingerprint, total, fetched, unchanged,
+			failed, status, error, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, db.instanceID, checkpoint.Shard, checkpoint.Repository, checkpoint.Directory, checkpoint.Fingerprint,
+		checkpoint.Total, checkpoint.Fetched, checkpoint.Unchanged, checkpoint.Failed, checkpoint.Status,
+		checkpoint.Error, checkpoint.UpdatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save hydration checkpoint: %w", err)
+	}
+	return nil
+}
+
+// LoadHydrationCheckpoints returns the shard checkpoints of this instance
+func (db *NQEDatabase) LoadHydrationCheckpoints() ([]HydrationCheckpoint, error) {
+	rows, err := db.db.Query(`
+		SELECT shard, repository, directory, fingerprint, total, fetched, unchanged, failed,
+			   status, COALESCE(error, ''), updated_at
+		FROM hydration_checkpoints
+		WHERE instance_id = ?
+		ORDER BY shard
+	`, db.instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hydration checkpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var checkpoints []HydrationCheckpoint
+	for rows.Next() {
+		var checkpoint HydrationCheckpoint
+		var updatedAt int64
+		if err := rows.Scan(&checkpoint.Shard, &checkpoint.Repository, &checkpoint.Directory, &checkpoint.Fingerprint,
+			&checkpoint.Total, &checkpoint.Fetched, &checkpoint.Unchanged, &checkpoint.Failed,
+			&checkpoint.Status, &checkpoint.Error, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan hydration checkpoint: %w", err)
+		}
+		checkpoint.UpdatedAt = time.Unix(updatedAt, 0)
+		checkpoints = append(checkpoints, checkpoint)
+	}
+	return checkpoints, rows.Err()
+}
+
+// ClearHydrationCheckpoints forgets shard progress so the next run fetches every shard
+func (db *NQEDatabase) ClearHydrationCheckpoints() error {
+	if _, err := db.db.Exec("DELETE FROM hydration_checkpoints WHERE instance_id = ?", db.instanceID); err != nil {
+		return fmt.Errorf("failed to clear hydration checkpoints: %w", err)
+	}
+	return nil
+}
+
+// listQuerySummaries lists the queries of both repositories. One repository may fail; complete
+// reports whether both were listed, which is required to tell that a query was deleted.
+func listQuerySummaries(ctx context.Context, client forward.ClientInterface, logger *logger.Logger) (summaries map[string][]forward.NQEOrgQuerySummary, complete bool, err error) {
+	summaries = make(map[string][]forward.NQEOrgQuerySummary)
+	var listErrors []string
+	for _, repository := range []string{"org", "fwd"} {
+		list, err := client.GetNQEQuerySummariesContext(ctx, repository)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, false, fmt.Errorf("query listing cancelled: %w", ctx.Err())
+			}
+			logger.Warn("⚠️  Failed to list %s queries: %v", repository, err)
+			listErrors = append(listErrors, err.Error())
+			continue
+		}
+		summaries[repository] = list
+	}
+	if len(summaries) == 0 {
+		return nil, false, fmt.Errorf("failed to list queries: %s", strings.Join(listErrors, "; "))
+	}
+	return summaries, len(listErrors) == 0, nil
+}
+
+// hydrateEnhancedSharded fetches query details shard by shard across workers, skipping queries
+// whose commit matches existingCommitIDs and shards completed by an earlier run. restart ignores
+// the checkpoints. Fetched details are already saved when it returns, even on error. After a
+// successful run, queries missing from the listing are deprecated.
+func (db *NQEDatabase) hydrateEnhancedSharded(ctx context.Context, client forward.ClientInterface, logger *logger.Logger, existingCommitIDs map[string]string, workers int, restart bool) (*HydrationResult, error) {
+	summaries, complete, err := listQuerySummaries(ctx, client, logger)
+	if err != nil {
+		return nil, err
+	}
+	result, err := db.runHydrationShards(ctx, client, logger, buildHydrationShards(summaries), existingCommitIDs, workers, restart)
+	if err != nil {
+		return result, err
+	}
+
+	listed := make(map[string]bool)
+	for _, list := range summaries {
+		for _, summary := range list {
+			listed[summary.QueryID] = true
+		}
+	}
+	if result.Deprecated, result.Restored, err = db.syncDeprecations(listed, complete); err != nil {
+		logger.Warn("🔄 Failed to update deprecated queries: %v", err)
+	} else if len(result.Deprecated) > 0 {
+		logger.Info("🔄 Deprecated %d queries no longer in the library", len(result.Deprecated))
+	}
+	return result, nil
+}
+
+// runHydrationShards fetches the shards across workers, checkpointing each one
+func (db *NQEDatabase) runHydrationShards(ctx context.Context, client forward.ClientInterface, logger *logger.Logger, shards []hydrationShard, existingCommitIDs map[string]string, workers int, restart bool) (*HydrationResult, error) {
+	// Checkpoints only describe saved queries, so they are meaningless for an empty database
+	checkpoints := make(map[string]HydrationCheckpoint)
+	if restart || len(existingCommitIDs) == 0 {
+		if err := db.ClearHydrationCheckpoints(); err != nil {
+			logger.Warn("🔄 %v", err)
+		}
+	} else if stored, err := db.LoadHydrationCheckpoints(); err != nil {
+		logger.Warn("🔄 %v", err)
+	} else {
+		for _, checkpoint := range stored {
+			checkpoints[checkpoint.Shard] = checkpoint
+		}
+	}
+
+	result := &HydrationResult{Shards: len(shards)}
+	var todo []hydrationShard
+	for _, shard := range shards {
+		if checkpoint, ok := checkpoints[shard.key]; ok && checkpoint.Status == shardComplete && checkpoint.Fingerprint == shard.fingerprint {
+			result.Resumed++
+			continue
+		}
+		todo = append(todo, shard)
+	}
+	workers = max(1, min(workers, maxHydrationWorkers))
+	logger.Info("🔄 Sharded hydration: %d shards, %d already complete, %d to fetch with %d workers",
+		len(shards), result.Resumed, len(todo), workers)
+
+	pending := make(chan hydrationShard)
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range pending {
+				checkpoint, details := db.hydrateShard(ctx, client, logger, shard, existingCommitIDs)
+
+				mutex.Lock()
+				result.Fetched += checkpoint.Fetched
+				result.Unchanged += checkpoint.Unchanged
+				result.FailedQueries += checkpoint.Failed
+				result.Queries = append(result.Queries, details...)
+				if checkpoint.Status == shardComplete {
+					result.Completed++
+				} else {
+					result.Failed++
+				}
+				done := result.Completed + result.Failed
+				mutex.Unlock()
+
+				logger.Info("🔄 Shard %d/%d %s: %s (%d fetched, %d unchanged, %d failed)",
+					done, len(todo), shard.key, checkpoint.Status, checkpoint.Fetched, checkpoint.Unchanged, checkpoint.Failed)
+			}
+		}()
+	}
+feed:
+	for _, shard := range todo {
+		select {
+		case pending <- shard:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(pending)
+	wg.Wait()
+
+	if ctx.Err() != nil || result.Failed > 0 {
+		cause := "shard errors"
+		if ctx.Err() != nil {
+			cause = ctx.Err().Error()
+		}
+		return result, fmt.Errorf("hydration stopped with %d of %d shards complete (%s); run it again to resume",
+			result.Resumed+result.Completed, result.Shards, cause)
+	}
+	logger.Info("✅ Sharded hydration complete: %d fetched, %d unchanged, %d failed, %d shards resumed",
+		result.Fetched, result.Unchanged, result.FailedQueries, result.Resumed)
+	return result, nil
+}
+
+// loadEnhancedSharded runs sharded hydration and returns the fetched queries. Partial progress
+// is kept for the next run to resume; the error is returned only when nothing was loaded, so
+// callers can fall back to the basic API.
+func (db *NQEDatabase) loadEnhancedSharded(ctx context.Context, client forward.ClientInterface, logger *logger.Logger, existingCommitIDs map[string]string, workers int, restart bool) ([]forward.NQEQueryDetail, error) {
+	result, err := db.hydrateEnhancedSharded(ctx, client, logger, existingCommitIDs, workers, restart)
+	if err != nil {
+		if result == nil || result.Fetched+result.Unchanged+result.Resumed == 0 {
+			return nil, err
+		}
+		logger.Warn("🔄 %v", err)
+	}
+	return result.Queries, nil
+}
+
+// hydrateShard fetches the changed queries of a shard and saves them with its checkpoint
+func (db *NQEDatabase) hydrateShard(ctx context.Context, client forward.ClientInterface, logger *logger.Logger, shard hydrationShard, existingCommitIDs map[string]string) (HydrationCheckpoint, []forward.NQEQueryDetail) {
+	checkpoint := HydrationCheckpoint{
+		Shard:       shard.key,
+		Repository:  shard.repository,
+		Directory:   shard.directory,
+		Fingerprint: shard.fingerprint,
+		Total:       len(shard.queries),
+		Status:      shardRunning,
+		UpdatedAt:   time.Now(),
+	}
+	if err := db.saveHydrationCheckpoint(checkpoint); err != nil {
+		logger.Warn("🔄 %v", err)
+	}
+
+	var details []forward.NQEQueryDetail
+	for _, summary := range shard.queries {
+		if ctx.Err() != nil {
+			checkpoint.Status = shardFailed
+			checkpoint.Error = ctx.Err().Error()
+			break
+		}
+		if commitID, ok := existingCommitIDs[summary.Path]; ok && commitID == summary.LastCommitId {
+			checkpoint.Unchanged++
+			continue
+		}
+		detail, err := client.GetNQEQueryByCommitWithContext(ctx, summary.LastCommitId, summary.Path, shard.repository)
+		if err != nil {
+			if ctx.Err() != nil {
+				checkpoint.Status = shardFailed
+				checkpoint.Error = ctx.Err().Error()
+				break
+			}
+			// Queries with path issues are skipped, as in unsharded hydration; keep the first example
+			checkpoint.Failed++
+			if checkpoint.Error == "" {
+				checkpoint.Error = fmt.Sprintf("%s: %v", summary.Path, err)
+			}
+			continue
+		}
+		detail.QueryID = summary.QueryID
+		detail.Path = summary.Path
+		detail.Repository = shard.repository
+		if detail.LastCommit.ID == "" {
+			detail.LastCommit.ID = summary.LastCommitId
+		}
+		details = append(details, *detail)
+	}
+
+	// Save what was fetched even when interrupted; the next run skips it as unchanged
+	checkpoint.Fetched = len(details)
+	if len(details) > 0 {
+		if err := db.storeQueries(details); err != nil {
+			checkpoint.Status = shardFailed
+			checkpoint.Error = err.Error()
+		}
+	}
+	// A shard with failed queries is not complete, so the next run fetches them again; the
+	// queries saved here are skipped as unchanged
+	if checkpoint.Status == shardRunning && checkpoint.Failed > 0 {
+		checkpoint.Status = shardFailed
+		checkpoint.Error = fmt.Sprintf("%d of %d queries failed, first %s", checkpoint.Failed, checkpoint.Total, checkpoint.Error)
+	}
+	if checkpoint.Status == shardRunning {
+		checkpoint.Status = shardComplete
+	}
+	checkpoint.UpdatedAt = time.Now()
+	if err := db.saveHydrationCheckpoint(checkpoint); err != nil {
+		logger.Warn("🔄 %v", err)
+	}
+	return checkpoint, details
+}
+
+// hydrationProgress summarizes the stored checkpoints for get_database_status
+func hydrationProgress(checkpoints []HydrationCheckpoint) map[string]interface{} {
+	counts := map[string]int{}
+	queries := map[string]int{}
+	var lastUpdate time.Time
+	var unfinished []HydrationCheckpoint
+	for _, checkpoint := range checkpoints {
+		counts[checkpoint.Status]++
+		queries["total"] += checkpoint.Total
+		queries["fetched"] += checkpoint.Fetched
+		queries["unchanged"] += checkpoint.Unchanged
+		queries["failed"] += checkpoint.Failed
+		if checkpoint.UpdatedAt.After(lastUpdate) {
+			lastUpdate = checkpoint.UpdatedAt
+		}
+		if checkpoint.Status != shardComplete && len(unfinished) < 20 {
+			unfinished = append(unfinished, checkpoint)
+		}
+	}
+	progress := map[string]interface{}{
+		"shards":          len(checkpoints),
+		"shards_complete": counts[shardComplete],
+		"shards_running":  counts[shardRunning],
+		"shards_failed":   counts[shardFailed],
+		"queries":         queries,
+		"last_update":     lastUpdate.Format(time.RFC3339),
+	}
+	if len(unfinished) > 0 {
+		progress["unfinished_shards"] = unfinished
+	}
+	return progress
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// flakyHydrationClient counts detail fetches and cancels the run after cancelAfter of them
+type flakyHydrationClient struct {
+	*MockForwardClient
+	calls       int32
+	cancelAfter int32
+	cancel      context.CancelFunc
+}
+
+func (c *flakyHydrationClient) GetNQEQueryByCommitWithContext(ctx context.Context, commitID string, path string, repository string) (*forward.NQEQueryDetail, error) {
+	if calls := atomic.AddInt32(&c.calls, 1); c.cancelAfter > 0 && calls > c.cancelAfter {
+		c.cancel()
+		return nil, ctx.Err()
+	}
+	return c.MockForwardClient.GetNQEQueryByCommitWithContext(ctx, commitID, path, repository)
+}
+
+func TestBuildHydrationShards(t *testing.T) {
+	summaries := map[string][]forward.NQEOrgQuerySummary{
+		"org": {
+			{Path: "/L3/Routes/Static", QueryID: "Q1", LastCommitId: "c1"},
+			{Path: "/L3/Routes/BGP", QueryID: "Q2", LastCommitId: "c1"},
+			{Path: "/Security/Telnet", QueryID: "Q3", LastCommitId: "c1"},
+		},
+		"fwd": {
+			{Path: "/Forward/L3/Routes/Static", QueryID: "Q1", LastCommitId: "c9"},
+			{Path: "/Forward/Interfaces/Errors", QueryID: "Q4", LastCommitId: "c1"},
+		},
+	}
+	shards := buildHydrationShards(summaries)
+	keys := make([]string, len(shards))
+	for i, shard := range shards {
+		keys[i] = shard.key
+	}
+	expected := []string{"fwd:/Forward/Interfaces", "org:/L3/Routes", "org:/Security"}
+	if len(keys) != len(expected) {
+		t.Fatalf("Expected shards %v, got %v", expected, keys)
+	}
+	for i := range expected {
+		if keys[i] != expected[i] {
+			t.Errorf("Expected shards %v, got %v", expected, keys)
+		}
+	}
+	if len(shards[1].queries) != 2 || shards[1].queries[0].Path != "/L3/Routes/BGP" {
+		t.Errorf("Expected the routes shard sorted by path, got %+v", shards[1].queries)
+	}
+
+	// A new commit changes the fingerprint of its shard only
+	summaries["org"][0].LastCommitId = "c2"
+	changed := buildHydrationShards(summaries)
+	if changed[1].fingerprint == shards[1].fingerprint || changed[2].fingerprint != shards[2].fingerprint {
+		t.Error("Expected only the changed shard's fingerprint to differ")
+	}
+}
+
+// failingPathClient fails the detail fetch of one query path
+type failingPathClient struct {
+	*MockForwardClient
+	failPath string
+}
+
+func (c *failingPathClient) GetNQEQueryByCommitWithContext(ctx context.Context, commitID string, path string, repository string) (*forward.NQEQueryDetail, error) {
+	if path == c.failPath {
+		return nil, fmt.Errorf("unexpected status code: 500")
+	}
+	return c.MockForwardClient.GetNQEQueryByCommitWithContext(ctx, commitID, path, repository)
+}
+
+func TestHydrateShardWithFailedQueriesIsRetried(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	log := logger.New()
+	db, err := NewNQEDatabase(log, "hydration-failed-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	mock := NewMockForwardClient()
+	mock.nqeQueries = []forward.NQEQuery{
+		{QueryID: "Q1", Path: "/A/one", Repository: "org"},
+		{QueryID: "Q2", Path: "/A/two", Repository: "org"},
+	}
+	result, err := db.hydrateEnhancedSharded(context.Background(), &failingPathClient{MockForwardClient: mock, failPath: "/A/two"}, log, nil, 1, false)
+	if err == nil || result.Completed != 0 || result.Failed != 1 || result.FailedQueries != 1 {
+		t.Fatalf("Expected the shard with a failed query to fail, got %+v, %v", result, err)
+	}
+	checkpoints, _ := db.LoadHydrationCheckpoints()
+	if len(checkpoints) != 1 || checkpoints[0].Status != shardFailed || !strings.Contains(checkpoints[0].Error, "1 of 2 queries failed, first /A/two") {
+		t.Fatalf("Expected a failed checkpoint naming the query, got %+v", checkpoints)
+	}
+
+	// The next run fetches the shard again rather than resuming past it
+	existingCommitIDs := map[string]string{"/A/one": "commit-Q1"}
+	result, err = db.hydrateEnhancedSharded(context.Background(), &failingPathClient{MockForwardClient: mock}, log, existingCommitIDs, 1, false)
+	if err != nil || result.Resumed != 0 || result.Completed != 1 || result.Fetched != 1 || result.Unchanged != 1 {
+		t.Errorf("Expected the failed query fetched on the next run, got %+v, %v", result, err)
+	}
+}
+
+func TestHydrateEnhancedShardedResumes(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	log := logger.New()
+	db, err := NewNQEDatabase(log, "hydration-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+
+	mock := NewMockForwardClient()
+	mock.nqeQueries = []forward.NQEQuery{
+		{QueryID: "Q1", Path: "/A/one", Repository: "org"},
+		{QueryID: "Q2", Path: "/A/two", Repository: "org"},
+		{QueryID: "Q3", Path: "/B/one", Repository: "org"},
+		{QueryID: "Q4", Path: "/B/two", Repository: "org"},
+		{QueryID: "Q5", Path: "/C/one", Repository: "fwd"},
+		{QueryID: "Q6", Path: "/C/two", Repository: "fwd"},
+	}
+
+	// The first run is interrupted partway through the second shard
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &flakyHydrationClient{MockForwardClient: mock, cancelAfter: 3, cancel: cancel}
+	result, err := db.hydrateEnhancedSharded(ctx, client, log, nil, 1, false)
+	if err == nil || result == nil {
+		t.Fatalf("Expected an interrupted run with partial results, got %+v, %v", result, err)
+	}
+	if result.Completed != 1 || result.Fetched != 3 {
+		t.Errorf("Expected one complete shard and three saved queries, got %+v", result)
+	}
+
+	saved, _ := db.LoadQueries()
+	existingCommitIDs := make(map[string]string)
+	for _, query := range saved {
+		existingCommitIDs[query.Path] = query.LastCommit.ID
+	}
+	if len(saved) != 3 || existingCommitIDs["/A/one"] != "commit-Q1" {
+		t.Fatalf("Expected the fetched queries to be saved with their commits, got %+v", saved)
+	}
+
+	// The second run skips the completed shard and the already saved query
+	client = &flakyHydrationClient{MockForwardClient: mock}
+	result, err = db.hydrateEnhancedSharded(context.Background(), client, log, existingCommitIDs, 4, false)
+	if err != nil {
+		t.Fatalf("Expected the resumed run to succeed, got %v", err)
+	}
+	if result.Resumed != 1 || result.Completed != 2 || result.Unchanged != 1 || client.calls != 3 {
+		t.Errorf("Expected to resume after the first shard, got %+v with %d fetches", result, client.calls)
+	}
+	if saved, _ := db.LoadQueries(); len(saved) != 6 {
+		t.Errorf("Expected every query saved, got %d", len(saved))
+	}
+
+	checkpoints, err := db.LoadHydrationCheckpoints()
+	if err != nil || len(checkpoints) != 3 {
+		t.Fatalf("Expected three checkpoints, got %+v (%v)", checkpoints, err)
+	}
+	progress := hydrationProgress(checkpoints)
+	if progress["shards_complete"] != 3 || progress["unfinished_shards"] != nil {
+		t.Errorf("Expected every shard complete, got %+v", progress)
+	}
+
+	// Restarting ignores the checkpoints
+	result, err = db.hydrateEnhancedSharded(context.Background(), client, log, existingCommitIDs, 2, true)
+	if err != nil || result.Resumed != 0 || result.Completed != 3 {
+		t.Errorf("Expected a restart to process every shard, got %+v (%v)", result, err)
+	}
+}
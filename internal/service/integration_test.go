@@ -57,8 +57,9 @@ func TestIntegrationListNetworks(t *testing.T) {
 		t.Fatal("Expected response, got nil")
 	}
 
-	if len(response.Content) != 1 {
-		t.Fatalf("Expected 1 content item, got: %d", len(response.Content))
+	// The listing is followed by its pagination metadata
+	if len(response.Content) != 2 {
+		t.Fatalf("Expected 2 content items, got: %d", len(response.Content))
 	}
 
 	content := response.Content[0].TextContent.Text
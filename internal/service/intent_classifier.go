@@ -0,0 +1,454 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Score weights of the intent classifier. Embedding similarity is normalized across intents,
+// so it only ranks them; keywords and extracted arguments decide how confident the match is.
+const (
+	intentSemanticWeight = 0.4
+	intentKeywordWeight  = 0.4
+	intentHighConfidence = 0.5
+	intentMinimumMargin  = 0.15
+	intentLowConfidence  = 0.25
+)
+
+var (
+	intentQuotedPattern  = regexp.MustCompile(`(?:^|\s)["'“‘]([^"'”’]+)["'”’]`)
+	intentPortPattern    = regexp.MustCompile(`(?i)\bport\s+(\d{1,5})\b`)
+	intentSubjectPattern = regexp.MustCompile(`(?i)\b(?:about|regarding)\s+([\w.:/-]+)`)
+	// intentServicePorts maps well-known service names to their protocol and port
+	intentServicePorts = map[string]struct {
+		proto int
+		port  string
+	}{
+		"ssh": {6, "22"}, "telnet": {6, "23"}, "http": {6, "80"}, "https": {6, "443"},
+		"dns": {17, "53"}, "ntp": {17, "123"}, "snmp": {17, "161"}, "syslog": {17, "514"},
+		"ldap": {6, "389"}, "rdp": {6, "3389"}, "smtp": {6, "25"}, "bgp": {6, "179"},
+	}
+)
+
+// intentFeatures are the parts of a request the classifier extracts before scoring
+type intentFeatures struct {
+	Text      string   // Lower-cased request with single spaces
+	Addresses []string // IP addresses in the request, canonicalized
+	Prefixes  []string // CIDR prefixes in the request, canonicalized
+	Quoted    string   // First quoted phrase
+	Subject   string   // Word following "about" or "regarding"
+	Proto     *int
+	Port      string
+}
+
+// intentDefinition is one labeled intent: the tool it routes to, example requests for the
+// embedding comparison, keywords, and a builder for the argument skeleton
+type intentDefinition struct {
+	Name      string
+	Label     string
+	Tool      string
+	Examples  []string
+	Keywords  []string
+	Network   bool // The tool takes a network_id
+	Boost     func(features intentFeatures) float64
+	Arguments func(features intentFeatures, request string) map[string]interface{}
+}
+
+// IntentMatch is the score of one intent for a request
+type IntentMatch struct {
+	Intent    string                 `json:"intent"`
+	Label     string                 `json:"label"`
+	Tool      string                 `json:"tool"`
+	Score     float64                `json:"score"`
+	Keywords  []string               `json:"matched_keywords,omitempty"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// IntentClassification is the routing decision for a request
+type IntentClassification struct {
+	Request      string        `json:"request"`
+	Best         IntentMatch   `json:"best"`
+	Confidence   string        `json:"confidence"` // high, medium or low
+	Alternatives []IntentMatch `json:"alternatives,omitempty"`
+}
+
+// IntentClassifier maps free-text requests to tools using a small labeled intent set
+type IntentClassifier struct {
+	embeddingService EmbeddingService
+	intents          []intentDefinition
+	examples         [][][]float64 // Example embeddings per intent, computed on first use
+	semantic         bool          // False when the examples could not be embedded
+	once             sync.Once
+}
+
+// NewIntentClassifier creates a classifier over the built-in intents
+func NewIntentClassifier(embeddingService EmbeddingService) *IntentClassifier {
+	return &IntentClassifier{embeddingService: embeddingService, intents: builtinIntents()}
+}
+
+// builtinIntents is the labeled intent set
+func builtinIntents() []intentDefinition {
+	return []intentDefinition{
+		{
+			Name: "path_search", Label: "Path search", Tool: "search_paths", Network: true,
+			Examples: []string{
+				"can 10.1.1.10 reach 10.2.2.20 on port 443",
+				"trace the path from the web server to the database",
+				"is traffic from the branch to the data center blocked by a firewall",
+				"why can't host a talk to host b over ssh",
+				"check connectivity between two subnets",
+			},
+			Keywords: []string{"reach", "reachable", "reachability", "path", "paths", "trace", "traceroute", "connectivity",
+				"traffic", "blocked", "talk to", "flow", "get to", "can't connect", "cannot connect", "dropped"},
+			Boost: func(features intentFeatures) float64 {
+				if len(features.Addresses)+len(features.Prefixes) >= 2 {
+					return 0.3
+				}
+				return 0
+			},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				arguments := map[string]interface{}{}
+				endpoints := append(append([]string{}, features.Addresses...), features.Prefixes...)
+				if len(endpoints) >= 2 {
+					arguments["src_ip"], arguments["dst_ip"] = endpoints[0], endpoints[1]
+				} else if len(endpoints) == 1 {
+					arguments["dst_ip"] = endpoints[0]
+					arguments["src_ip"] = "<source address>"
+				} else {
+					arguments["src_ip"], arguments["dst_ip"] = "<source address>", "<destination address>"
+				}
+				if features.Proto != nil {
+					arguments["ip_proto"] = *features.Proto
+				}
+				if features.Port != "" {
+					arguments["dst_port"] = features.Port
+				}
+				return arguments
+			},
+		},
+		{
+			Name: "nqe_query", Label: "NQE query", Tool: "search_nqe_queries",
+			Examples: []string{
+				"show devices with high cpu usage",
+				"find bgp neighbors that are down",
+				"list interfaces with errors",
+				"which devices run an os version with known cves",
+				"check ntp compliance across the network",
+			},
+			Keywords: []string{"bgp", "ospf", "eigrp", "isis", "neighbor", "neighbors", "cve", "cves", "vulnerable", "compliance",
+				"interface", "interfaces", "mtu", "cpu", "utilization", "errors", "nqe", "query", "stp", "lldp", "cdp", "mac", "arp"},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				return map[string]interface{}{"query": request}
+			},
+		},
+		{
+			Name: "config_search", Label: "Configuration search", Tool: "search_configs", Network: true,
+			Examples: []string{
+				"which devices have ip ssh version 2 configured",
+				"find snmp community public in the configs",
+				"search the running configuration for ntp server 10.0.0.1",
+				"grep configs for logging host",
+			},
+			Keywords: []string{"config", "configs", "configuration", "configured", "running-config", "running config",
+				"grep", "line", "lines", "command", "commands", "stanza"},
+			Boost: func(features intentFeatures) float64 {
+				if features.Quoted != "" {
+					return 0.2
+				}
+				return 0
+			},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				term := features.Quoted
+				if term == "" {
+					term = "<configuration text>"
+				}
+				return map[string]interface{}{"search_term": term}
+			},
+		},
+		{
+			Name: "memory_lookup", Label: "Memory lookup", Tool: "search_entities",
+			Examples: []string{
+				"what did we find last time about router-1",
+				"recall my notes on the data center migration",
+				"show the previous investigation of the outage",
+				"what do you remember about site a",
+			},
+			Keywords: []string{"remember", "recall", "notes", "note", "previous", "previously", "earlier", "last time",
+				"saved", "stored", "memory", "investigation", "we found", "did we"},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				return map[string]interface{}{"query": firstNonEmpty(features.Quoted, features.Subject, "<entity name or text>")}
+			},
+		},
+		{
+			Name: "prefix_lookup", Label: "Prefix lookup", Tool: "which_devices_in_prefix", Network: true,
+			Examples: []string{
+				"which devices are in 10.20.0.0/16",
+				"who owns subnet 192.168.10.0/24",
+				"where is 10.1.1.5 attached",
+			},
+			Keywords: []string{"subnet", "subnets", "prefix", "prefixes", "cidr", "owns", "owner", "attached", "which devices in", "where is"},
+			Boost: func(features intentFeatures) float64 {
+				if len(features.Addresses)+len(features.Prefixes) == 1 {
+					return 0.2
+				}
+				return 0
+			},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				prefix := "<prefix or address>"
+				if len(features.Prefixes) > 0 {
+					prefix = features.Prefixes[0]
+				} else if len(features.Addresses) > 0 {
+					prefix = features.Addresses[0]
+				}
+				return map[string]interface{}{"prefix": prefix}
+			},
+		},
+		{
+			Name: "config_diff", Label: "Configuration changes", Tool: "get_config_diff", Network: true,
+			Examples: []string{
+				"what changed between yesterday's and today's snapshots",
+				"show config changes since the last snapshot",
+				"compare the configuration before and after the change window",
+			},
+			Keywords: []string{"changed", "changes", "diff", "difference", "differences", "compare", "since", "before and after"},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				return map[string]interface{}{"before_snapshot": "<earlier snapshot id>", "after_snapshot": "<later snapshot id>"}
+			},
+		},
+		{
+			Name: "device_inventory", Label: "Device inventory", Tool: "list_devices", Network: true,
+			Examples: []string{
+				"list all devices",
+				"how many routers do we have",
+				"show me the device inventory",
+			},
+			Keywords: []string{"inventory", "how many", "all devices", "list devices", "device list", "count"},
+			Arguments: func(features intentFeatures, request string) map[string]interface{} {
+				return map[string]interface{}{}
+			},
+		},
+	}
+}
+
+// extractIntentFeatures finds addresses, quoted text, protocol and port in a request
+func extractIntentFeatures(request string) intentFeatures {
+	features := intentFeatures{Text: " " + strings.Join(strings.Fields(strings.ToLower(request)), " ") + " "}
+	if match := intentQuotedPattern.FindStringSubmatch(request); match != nil {
+		features.Quoted = strings.TrimSpace(match[1])
+	}
+	if match := intentSubjectPattern.FindStringSubmatch(request); match != nil {
+		features.Subject = strings.TrimRight(match[1], ".")
+	}
+	for _, word := range strings.Fields(request) {
+		word = strings.Trim(word, ",;?!()[]{}\"'")
+		word = strings.TrimSuffix(word, ".")
+		lower := strings.ToLower(word)
+		if number, ok := ipProtocolNumbers[lower]; ok && features.Proto == nil {
+			features.Proto = &number
+		}
+		if service, ok := intentServicePorts[lower]; ok && features.Port == "" {
+			proto := service.proto
+			features.Proto, features.Port = &proto, service.port
+		}
+		canonical, _, err := normalizeIPOrCIDR(word)
+		if err != nil || !strings.ContainsAny(word, ".:") {
+			continue
+		}
+		if strings.Contains(canonical, "/") {
+			features.Prefixes = append(features.Prefixes, canonical)
+		} else {
+			features.Addresses = append(features.Addresses, canonical)
+		}
+	}
+	if match := intentPortPattern.FindStringSubmatch(request); match != nil {
+		if port, err := strconv.Atoi(match[1]); err == nil && port <= 65535 {
+			features.Port = match[1]
+			if features.Proto == nil {
+				tcp := ipProtocolNumbers["tcp"]
+				features.Proto = &tcp
+			}
+		}
+	}
+	return features
+}
+
+// embedExamples computes the example embeddings once
+func (c *IntentClassifier) embedExamples() {
+	c.once.Do(func() {
+		if c.embeddingService == nil {
+			return
+		}
+		c.examples = make([][][]float64, len(c.intents))
+		for i, intent := range c.intents {
+			for _, example := range intent.Examples {
+				embedding, err := c.embeddingService.GenerateEmbedding(example)
+				if err != nil {
+					c.examples = nil
+					return
+				}
+				c.examples[i] = append(c.examples[i], embedding)
+			}
+		}
+		c.semantic = true
+	})
+}
+
+// semanticScores returns the best example similarity per intent, scaled to 0-1 across intents
+func (c *IntentClassifier) semanticScores(request string) []float64 {
+	scores := make([]float64, len(c.intents))
+	c.embedExamples()
+	if !c.semantic {
+		return scores
+	}
+	embedding, err := c.embeddingService.GenerateEmbedding(request)
+	if err != nil {
+		return scores
+	}
+	low, high := math.Inf(1), math.Inf(-1)
+	for i, examples := range c.examples {
+		for _, example := range examples {
+			scores[i] = math.Max(scores[i], embeddingSimilarity(embedding, example))
+		}
+		low, high = math.Min(low, scores[i]), math.Max(high, scores[i])
+	}
+	for i := range scores {
+		if high > low {
+			scores[i] = (scores[i] - low) / (high - low)
+		} else {
+			scores[i] = 0
+		}
+	}
+	return scores
+}
+
+// Classify scores every intent for the request, best first
+func (c *IntentClassifier) Classify(request string) (*IntentClassification, error) {
+	request = strings.TrimSpace(request)
+	if request == "" {
+		return nil, fmt.Errorf("request is empty")
+	}
+	features := extractIntentFeatures(request)
+	semantic := c.semanticScores(request)
+
+	matches := make([]IntentMatch, len(c.intents))
+	evidence := make(map[string]bool) // Intents supported by more than embedding similarity
+	for i, intent := range c.intents {
+		match := IntentMatch{Intent: intent.Name, Label: intent.Label, Tool: intent.Tool}
+		for _, keyword := range intent.Keywords {
+			if strings.Contains(features.Text, " "+keyword+" ") {
+				match.Keywords = append(match.Keywords, keyword)
+			}
+		}
+		score := intentSemanticWeight*semantic[i] + intentKeywordWeight*math.Min(1, float64(len(match.Keywords))/2)
+		boost := 0.0
+		if intent.Boost != nil {
+			boost = intent.Boost(features)
+		}
+		evidence[intent.Name] = len(match.Keywords) > 0 || boost > 0
+		match.Score = math.Round(math.Min(1, score+boost)*100) / 100
+		match.Arguments = intent.Arguments(features, request)
+		matches[i] = match
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+
+	classification := &IntentClassification{Request: request, Best: matches[0], Alternatives: matches[1:], Confidence: "low"}
+	margin := matches[0].Score - matches[1].Score
+	switch {
+	case !evidence[matches[0].Intent]:
+		// The closest examples alone are not enough to route a request
+	case matches[0].Score >= intentHighConfidence && margin >= intentMinimumMargin:
+		classification.Confidence = "high"
+	case matches[0].Score >= intentLowConfidence:
+		classification.Confidence = "medium"
+	}
+	return classification, nil
+}
+
+// embeddingSimilarity is the cosine similarity of two embeddings of the same length
+func embeddingSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// classifyIntent routes a natural-language request to a tool with an argument skeleton
+func (s *ForwardMCPService) classifyIntent(args ClassifyIntentArgs) (*mcp.ToolResponse, error) {
+	if s.intentClassifier == nil {
+		s.intentClassifier = NewIntentClassifier(NewKeywordEmbeddingService())
+	}
+	classification, err := s.intentClassifier.Classify(args.Request)
+	if err != nil {
+		return nil, err
+	}
+	// Tools scoped to a network get the default network, or a placeholder without one
+	networkID := s.getNetworkID("")
+	if networkID == "" {
+		networkID = "<network_id>"
+	}
+	scoped := make(map[string]bool)
+	for _, intent := range s.intentClassifier.intents {
+		scoped[intent.Name] = intent.Network
+	}
+	for _, match := range append([]IntentMatch{classification.Best}, classification.Alternatives...) {
+		if scoped[match.Intent] {
+			match.Arguments["network_id"] = networkID
+		}
+	}
+	limit := args.Alternatives
+	if limit <= 0 {
+		limit = 2
+	}
+	if len(classification.Alternatives) > limit {
+		classification.Alternatives = classification.Alternatives[:limit]
+	}
+
+	if args.Format == "json" {
+		data, err := json.MarshalIndent(classification, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal classification: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(formatIntentClassification(classification))), nil
+}
+
+// formatIntentClassification renders the routing decision as markdown
+func formatIntentClassification(classification *IntentClassification) string {
+	var text strings.Builder
+	best := classification.Best
+	text.WriteString(fmt.Sprintf("## Intent: %s (%s confidence, score %.2f)\n\n", best.Label, classification.Confidence, best.Score))
+	text.WriteString(fmt.Sprintf("Tool: `%s`\n", best.Tool))
+	if len(best.Keywords) > 0 {
+		text.WriteString(fmt.Sprintf("Matched: %s\n", strings.Join(best.Keywords, ", ")))
+	}
+	arguments, _ := json.MarshalIndent(best.Arguments, "", "  ")
+	text.WriteString(fmt.Sprintf("\nArguments (replace <placeholders> before calling):\n```json\n%s\n```\n", arguments))
+	if classification.Confidence == "low" {
+		text.WriteString("\nNo intent matched clearly; ask the user what they want to find out, or rephrase the request.\n")
+	}
+	if len(classification.Alternatives) > 0 {
+		text.WriteString("\nAlternatives:\n")
+		for _, match := range classification.Alternatives {
+			text.WriteString(fmt.Sprintf("- %s (`%s`), score %.2f\n", match.Label, match.Tool, match.Score))
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExtractIntentFeatures(t *testing.T) {
+	features := extractIntentFeatures("Can 10.1.1.10 reach 2001:DB8::1 and 10.2.2.0/24 on port 8443?")
+	if len(features.Addresses) != 2 || features.Addresses[1] != "2001:db8::1" || len(features.Prefixes) != 1 {
+		t.Errorf("Expected two addresses and a prefix, got %+v", features)
+	}
+	if features.Port != "8443" || features.Proto == nil || *features.Proto != 6 {
+		t.Errorf("Expected tcp/8443, got %+v", features)
+	}
+
+	features = extractIntentFeatures("what's configured as 'ntp server 10.0.0.1' over udp")
+	if features.Quoted != "ntp server 10.0.0.1" || *features.Proto != 17 {
+		t.Errorf("Expected the quoted phrase and udp, got %+v", features)
+	}
+	if features = extractIntentFeatures("which hosts use dns"); features.Port != "53" || *features.Proto != 17 {
+		t.Errorf("Expected dns to imply udp/53, got %+v", features)
+	}
+}
+
+func TestClassifyIntent(t *testing.T) {
+	classifier := NewIntentClassifier(NewKeywordEmbeddingService())
+	tests := map[string]string{
+		"can 10.1.1.10 reach 10.2.2.20 on https?":                 "search_paths",
+		"is traffic from the web tier to the db tier blocked":     "search_paths",
+		"which switches have 'spanning-tree portfast' configured": "search_configs",
+		"what did we find last time about core-1":                 "search_entities",
+		"which devices are in 10.20.0.0/16":                       "which_devices_in_prefix",
+		"show me BGP neighbors that are down":                     "search_nqe_queries",
+		"what changed since yesterday's snapshot":                 "get_config_diff",
+		"how many routers do we have":                             "list_devices",
+	}
+	for request, tool := range tests {
+		classification, err := classifier.Classify(request)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if classification.Best.Tool != tool || classification.Confidence != "high" {
+			t.Errorf("%q: expected %s with high confidence, got %s (%s, %.2f)", request, tool,
+				classification.Best.Tool, classification.Confidence, classification.Best.Score)
+		}
+	}
+
+	classification, _ := classifier.Classify("hello there")
+	if classification.Confidence != "low" {
+		t.Errorf("Expected low confidence without keywords, got %s", classification.Confidence)
+	}
+	if _, err := classifier.Classify("  "); err == nil {
+		t.Error("Expected an error for an empty request")
+	}
+}
+
+func TestClassifyIntentTool(t *testing.T) {
+	service := createTestService()
+	service.defaults.NetworkID = "162112"
+
+	response, err := service.classifyIntent(ClassifyIntentArgs{Request: "can 10.1.1.10 reach 10.2.2.20 on port 443", Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var classification IntentClassification
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &classification); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	arguments := classification.Best.Arguments
+	if classification.Best.Tool != "search_paths" || arguments["src_ip"] != "10.1.1.10" || arguments["dst_ip"] != "10.2.2.20" ||
+		arguments["dst_port"] != "443" || arguments["ip_proto"] != float64(6) || arguments["network_id"] != "162112" {
+		t.Errorf("Expected a filled search_paths skeleton, got %+v", classification.Best)
+	}
+	if len(classification.Alternatives) != 2 {
+		t.Errorf("Expected two alternatives by default, got %d", len(classification.Alternatives))
+	}
+
+	response, err = service.classifyIntent(ClassifyIntentArgs{Request: "recall my notes about 'dc migration'", Alternatives: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Intent: Memory lookup (high confidence") || !strings.Contains(text, `"query": "dc migration"`) ||
+		strings.Contains(text, "network_id") || strings.Count(text, "\n- ") != 1 {
+		t.Errorf("Unexpected classification: %s", text)
+	}
+}
@@ -0,0 +1,422 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// interfaceHygieneQuery returns one row per interface with its description, status, switched
+// mode and link peer; roles and conventions are evaluated locally
+const interfaceHygieneQuery = `foreach device in network.devices
+foreach iface in device.interfaces
+let vlan = iface.ethernet.switchedVlan
+let peer = (foreach link in iface.links select link)
+select {
+  device: device.name,
+  location: device.locationName,
+  interface: iface.name,
+  type: iface.interfaceType,
+  description: iface.description,
+  adminStatus: iface.adminStatus,
+  mode: if isPresent(vlan) then toString(vlan.vlanMode) else "",
+  accessVlan: if isPresent(vlan) then vlan.accessVlan else null,
+  peerDevice: (foreach l in peer select l.deviceName),
+  peerInterface: (foreach l in peer select l.ifaceName)
+}`
+
+// Interface roles conventions are keyed by
+const (
+	interfaceRoleUplink      = "uplink"
+	interfaceRoleTrunk       = "trunk"
+	interfaceRoleAccess      = "access"
+	interfaceRoleLoopback    = "loopback"
+	interfaceRoleManagement  = "management"
+	interfaceRoleSVI         = "svi"
+	interfaceRolePortChannel = "port_channel"
+	interfaceRoleRouted      = "routed"
+)
+
+const (
+	maxHygieneReportRows = 50
+	hygieneIssueMissing  = "undescribed"
+	hygieneIssueMisnamed = "misnamed"
+	hygieneIssueInvalid  = "invalid_convention"
+	interfaceAnySite     = "*"
+	hygieneUnknownSite   = "unknown"
+)
+
+var interfaceRoles = []string{interfaceRoleUplink, interfaceRoleTrunk, interfaceRoleAccess, interfaceRoleLoopback,
+	interfaceRoleManagement, interfaceRoleSVI, interfaceRolePortChannel, interfaceRoleRouted}
+
+// defaultInterfaceConventions apply when neither the configuration nor the call sets a
+// template for a role: an uplink's description names the device at the other end
+var defaultInterfaceConventions = map[string]map[string]string{
+	interfaceAnySite: {interfaceRoleUplink: "{peer_device}"},
+}
+
+// sampleHygieneInterface fills every placeholder when a template is validated; an empty
+// value expands to .* and can hide a pattern that only breaks once a value is filled in
+var sampleHygieneInterface = HygieneInterface{Device: "d", Location: "s", Interface: "i", PeerDevice: "p", PeerInterface: "p", AccessVLAN: 1}
+
+var (
+	loopbackNamePattern    = regexp.MustCompile(`(?i)^(?:lo|loopback)\d*(?:$|[./:])`)
+	managementNamePattern  = regexp.MustCompile(`(?i)^(?:mgmt|management|ma\d|fxp\d|me\d|em\d)`)
+	portChannelNamePattern = regexp.MustCompile(`(?i)^(?:port-channel|po\d|ae\d|bond|bundle-ether)`)
+	interfacePlaceholder   = regexp.MustCompile(`\{(device|site|interface|peer_device|peer_interface|vlan)\}`)
+)
+
+// HygieneInterface is one interface with the role its description is checked against
+type HygieneInterface struct {
+	Device        string `json:"device"`
+	Location      string `json:"location"`
+	Interface     string `json:"interface"`
+	Role          string `json:"role"`
+	Description   string `json:"description,omitempty"`
+	AdminDown     bool   `json:"admin_down,omitempty"`
+	AccessVLAN    int    `json:"access_vlan,omitempty"`
+	PeerDevice    string `json:"peer_device,omitempty"`
+	PeerInterface string `json:"peer_interface,omitempty"`
+}
+
+// HygieneFinding is an interface without a description or with one that breaks its convention
+type HygieneFinding struct {
+	Type        string `json:"type"`
+	Location    string `json:"location"`
+	Device      string `json:"device"`
+	Interface   string `json:"interface"`
+	Role        string `json:"role"`
+	Description string `json:"description,omitempty"`
+	Expected    string `json:"expected,omitempty"`
+}
+
+// HygieneDevice counts the checked interfaces and findings of one device
+type HygieneDevice struct {
+	Device      string `json:"device"`
+	Location    string `json:"location"`
+	Checked     int    `json:"checked"`
+	Undescribed int    `json:"undescribed"`
+	Misnamed    int    `json:"misnamed"`
+}
+
+// HygieneSiteSummary aggregates the device counts per site
+type HygieneSiteSummary struct {
+	Location    string `json:"location"`
+	Devices     int    `json:"devices"`
+	Checked     int    `json:"checked"`
+	Undescribed int    `json:"undescribed"`
+	Misnamed    int    `json:"misnamed"`
+}
+
+// interfaceRole classifies an interface by its name and type first, then by its link and
+// switched mode
+func interfaceRole(name, ifaceType, mode string, linked bool) string {
+	ifaceType = strings.ToLower(ifaceType)
+	switch {
+	case strings.Contains(ifaceType, "loopback") || loopbackNamePattern.MatchString(name):
+		return interfaceRoleLoopback
+	case strings.Contains(ifaceType, "management") || managementNamePattern.MatchString(name):
+		return interfaceRoleManagement
+	case sviNamePattern.MatchString(name):
+		return interfaceRoleSVI
+	case strings.Contains(ifaceType, "aggregate") || strings.Contains(ifaceType, "lag") || portChannelNamePattern.MatchString(name):
+		return interfaceRolePortChannel
+	case linked:
+		return interfaceRoleUplink
+	case strings.Contains(strings.ToLower(mode), vlanModeTrunk):
+		return interfaceRoleTrunk
+	case mode != "":
+		return interfaceRoleAccess
+	default:
+		return interfaceRoleRouted
+	}
+}
+
+// parseHygieneInterfaces converts query rows, sorted by device and interface
+func parseHygieneInterfaces(items []map[string]interface{}) []HygieneInterface {
+	interfaces := make([]HygieneInterface, 0, len(items))
+	for _, item := range items {
+		iface := HygieneInterface{
+			Device:        resultValueString(item["device"]),
+			Location:      firstNonEmpty(resultValueString(item["location"]), hygieneUnknownSite),
+			Interface:     resultValueString(item["interface"]),
+			Description:   strings.TrimSpace(resultValueString(item["description"])),
+			AdminDown:     strings.Contains(strings.ToLower(resultValueString(item["adminStatus"])), "down"),
+			AccessVLAN:    firstVLAN(item["accessVlan"]),
+			PeerDevice:    firstListValue(item["peerDevice"]),
+			PeerInterface: firstListValue(item["peerInterface"]),
+		}
+		if iface.Device == "" || iface.Interface == "" {
+			continue
+		}
+		iface.Role = interfaceRole(iface.Interface, resultValueString(item["type"]), resultValueString(item["mode"]), iface.PeerDevice != "")
+		interfaces = append(interfaces, iface)
+	}
+	sort.Slice(interfaces, func(i, j int) bool {
+		if interfaces[i].Device != interfaces[j].Device {
+			return interfaces[i].Device < interfaces[j].Device
+		}
+		return interfaces[i].Interface < interfaces[j].Interface
+	})
+	return interfaces
+}
+
+// interfaceConventions holds description templates by lower-case site, then role
+type interfaceConventions map[string]map[string]string
+
+// newInterfaceConventions layers the built-in templates, the configured ones and those of the
+// call, and rejects unknown roles and templates that are not valid expressions
+func newInterfaceConventions(configured map[string]map[string]string, overrides []InterfaceConventionArgs) (interfaceConventions, error) {
+	conventions := make(interfaceConventions)
+	set := func(site, role, pattern string) error {
+		site, role = strings.ToLower(strings.TrimSpace(site)), strings.ToLower(strings.TrimSpace(role))
+		if site == "" {
+			site = interfaceAnySite
+		}
+		known := false
+		for _, candidate := range interfaceRoles {
+			known = known || candidate == role
+		}
+		if !known {
+			return fmt.Errorf("unknown interface role %q; use one of %s", role, strings.Join(interfaceRoles, ", "))
+		}
+		for _, sample := range []HygieneInterface{{}, sampleHygieneInterface} {
+			if _, err := regexp.Compile(expandInterfaceTemplate(pattern, sample)); err != nil {
+				return fmt.Errorf("invalid convention for role %s at site %s: %w", role, site, err)
+			}
+		}
+		if conventions[site] == nil {
+			conventions[site] = make(map[string]string)
+		}
+		conventions[site][role] = pattern
+		return nil
+	}
+	for _, layer := range []map[string]map[string]string{defaultInterfaceConventions, configured} {
+		for site, roles := range layer {
+			for role, pattern := range roles {
+				if err := set(site, role, pattern); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	for _, override := range overrides {
+		if err := set(override.Site, override.Role, override.Pattern); err != nil {
+			return nil, err
+		}
+	}
+	return conventions, nil
+}
+
+// template returns the template for an interface's role at its site, or "" when there is none
+func (c interfaceConventions) template(iface HygieneInterface) string {
+	if pattern, ok := c[strings.ToLower(iface.Location)][iface.Role]; ok {
+		return pattern
+	}
+	return c[interfaceAnySite][iface.Role]
+}
+
+// expandInterfaceTemplate replaces the placeholders of a template with the interface's values,
+// quoted and matched regardless of case; an unknown value matches anything
+func expandInterfaceTemplate(pattern string, iface HygieneInterface) string {
+	return interfacePlaceholder.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		value := ""
+		switch placeholder {
+		case "{device}":
+			value = iface.Device
+		case "{site}":
+			value = iface.Location
+		case "{interface}":
+			value = iface.Interface
+		case "{peer_device}":
+			value = iface.PeerDevice
+		case "{peer_interface}":
+			value = iface.PeerInterface
+		case "{vlan}":
+			if iface.AccessVLAN > 0 {
+				value = strconv.Itoa(iface.AccessVLAN)
+			}
+		}
+		if value == "" {
+			return ".*"
+		}
+		return "(?i:" + regexp.QuoteMeta(value) + ")"
+	})
+}
+
+// checkInterfaceHygiene flags interfaces without a description and descriptions that do not
+// match the template of their role, and counts the checked interfaces per device
+func checkInterfaceHygiene(interfaces []HygieneInterface, conventions interfaceConventions, includeDown bool) ([]HygieneFinding, []HygieneDevice) {
+	compiled := make(map[string]*regexp.Regexp)
+	var findings []HygieneFinding
+	var devices []HygieneDevice
+	for _, iface := range interfaces {
+		if len(devices) == 0 || devices[len(devices)-1].Device != iface.Device {
+			devices = append(devices, HygieneDevice{Device: iface.Device, Location: iface.Location})
+		}
+		if iface.AdminDown && !includeDown {
+			continue
+		}
+		device := &devices[len(devices)-1]
+		device.Checked++
+
+		finding := HygieneFinding{Location: iface.Location, Device: iface.Device, Interface: iface.Interface, Role: iface.Role, Description: iface.Description}
+		template := conventions.template(iface)
+		if iface.Description == "" {
+			finding.Type = hygieneIssueMissing
+			finding.Expected = template
+			device.Undescribed++
+			findings = append(findings, finding)
+			continue
+		}
+		if template == "" {
+			continue
+		}
+		expanded := expandInterfaceTemplate(template, iface)
+		re, ok := compiled[expanded]
+		if !ok {
+			// A stored convention can still break once this interface's values are filled in
+			var err error
+			if re, err = regexp.Compile(expanded); err != nil {
+				finding.Type = hygieneIssueInvalid
+				finding.Expected = template
+				findings = append(findings, finding)
+				continue
+			}
+			compiled[expanded] = re
+		}
+		if !re.MatchString(iface.Description) {
+			finding.Type = hygieneIssueMisnamed
+			finding.Expected = template
+			device.Misnamed++
+			findings = append(findings, finding)
+		}
+	}
+	return findings, devices
+}
+
+// hygieneSiteSummaries aggregates the device counts per site
+func hygieneSiteSummaries(devices []HygieneDevice) []HygieneSiteSummary {
+	sites := make(map[string]*HygieneSiteSummary)
+	for _, device := range devices {
+		site := sites[device.Location]
+		if site == nil {
+			site = &HygieneSiteSummary{Location: device.Location}
+			sites[device.Location] = site
+		}
+		site.Devices++
+		site.Checked += device.Checked
+		site.Undescribed += device.Undescribed
+		site.Misnamed += device.Misnamed
+	}
+	summaries := make([]HygieneSiteSummary, 0, len(sites))
+	for _, site := range sites {
+		summaries = append(summaries, *site)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Location < summaries[j].Location })
+	return summaries
+}
+
+// checkInterfaceHygieneTool audits interface descriptions against the site naming conventions
+func (s *ForwardMCPService) checkInterfaceHygieneTool(args CheckInterfaceHygieneArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("check_interface_hygiene", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	var configured map[string]map[string]string
+	if s.config != nil {
+		configured = s.config.Forward.InterfaceConventions
+	}
+	conventions, err := newInterfaceConventions(configured, args.Conventions)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.fetchAllNQEQueryItems(networkID, snapshotID, interfaceHygieneQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch interface descriptions: %w", err)
+	}
+	interfaces := parseHygieneInterfaces(items)
+	if args.Location != "" || args.Device != "" {
+		filtered := interfaces[:0]
+		for _, iface := range interfaces {
+			if (args.Location == "" || strings.EqualFold(iface.Location, args.Location)) && (args.Device == "" || strings.EqualFold(iface.Device, args.Device)) {
+				filtered = append(filtered, iface)
+			}
+		}
+		interfaces = filtered
+	}
+	if len(interfaces) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("No interfaces found in network %s.", networkID))), nil
+	}
+	findings, devices := checkInterfaceHygiene(interfaces, conventions, args.IncludeDown)
+	sites := hygieneSiteSummaries(devices)
+
+	if strings.EqualFold(args.Format, "json") {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"network_id":  networkID,
+			"conventions": conventions,
+			"sites":       sites,
+			"devices":     devices,
+			"findings":    findings,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode interface hygiene report: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# Interface Description Hygiene: network %s\n\n", networkID))
+	checked, undescribed, misnamed := 0, 0, 0
+	for _, site := range sites {
+		checked, undescribed, misnamed = checked+site.Checked, undescribed+site.Undescribed, misnamed+site.Misnamed
+	}
+	if len(findings) == 0 {
+		report.WriteString(fmt.Sprintf("✅ All %d checked interfaces are described according to their conventions.\n", checked))
+	} else {
+		report.WriteString(fmt.Sprintf("❌ %d of %d checked interfaces: %s ×%d %s ×%d\n", len(findings), checked, hygieneIssueMissing, undescribed, hygieneIssueMisnamed, misnamed))
+	}
+	if !args.IncludeDown {
+		report.WriteString("ℹ️ Administratively down interfaces were skipped (include_down=true checks them).\n")
+	}
+
+	report.WriteString("\n## Sites\n\n| Site | Devices | Checked | Undescribed | Misnamed |\n|---|---|---|---|---|\n")
+	for _, site := range sites {
+		report.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |\n", site.Location, site.Devices, site.Checked, site.Undescribed, site.Misnamed))
+	}
+
+	// Devices with findings first, worst first
+	sort.SliceStable(devices, func(i, j int) bool {
+		return devices[i].Undescribed+devices[i].Misnamed > devices[j].Undescribed+devices[j].Misnamed
+	})
+	report.WriteString(fmt.Sprintf("\n## Devices (%d)\n\n| Device | Site | Checked | Undescribed | Misnamed |\n|---|---|---|---|---|\n", len(devices)))
+	for i, device := range devices {
+		if i == maxHygieneReportRows {
+			report.WriteString(fmt.Sprintf("\n… %d more devices (use format=json for all)\n", len(devices)-i))
+			break
+		}
+		report.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d |\n", device.Device, device.Location, device.Checked, device.Undescribed, device.Misnamed))
+	}
+
+	if len(findings) > 0 {
+		report.WriteString("\n## Findings\n\n| Type | Site | Device | Interface | Role | Description | Convention |\n|---|---|---|---|---|---|---|\n")
+		for i, finding := range findings {
+			if i == maxHygieneReportRows {
+				report.WriteString(fmt.Sprintf("\n… %d more findings (use format=json for all)\n", len(findings)-i))
+				break
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s |\n", finding.Type, finding.Location, finding.Device, finding.Interface,
+				finding.Role, firstNonEmpty(strings.ReplaceAll(finding.Description, "|", `\|`), "-"), firstNonEmpty(strings.ReplaceAll(finding.Expected, "|", `\|`), "-")))
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+)
+
+func hygieneTestService() *ForwardMCPService {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	iface := func(device, location, name, description, mode, peerDevice, peerIface string) map[string]interface{} {
+		row := map[string]interface{}{"device": device, "location": location, "interface": name, "description": description,
+			"adminStatus": "UP", "mode": mode, "type": "IF_ETHERNET"}
+		if peerDevice != "" {
+			row["peerDevice"] = []interface{}{peerDevice}
+			row["peerInterface"] = []interface{}{peerIface}
+		}
+		return row
+	}
+	down := iface("sw1", "hq", "Gi9", "", "ACCESS", "", "")
+	down["adminStatus"] = "DOWN"
+	access := iface("sw1", "hq", "Gi3", "USER-VLAN20", "ACCESS", "", "")
+	access["accessVlan"] = float64(10)
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		iface("sw1", "hq", "Gi1", "UPLINK: CORE1 Eth1", "", "core1", "Eth1"),
+		iface("sw1", "hq", "Gi2", "to old-core", "", "core1", "Eth2"),
+		access, down,
+		iface("sw1", "hq", "Loopback0", "", "", "", ""),
+		iface("core1", "dc", "Eth1", "sw1 Gi1", "", "sw1", "Gi1"),
+		iface("core1", "dc", "Vlan10", "SVI users", "", "", ""),
+		iface("edge1", "branch", "ge-0/0/0", "WAN circuit 1234", "", "", ""),
+	}}
+	return service
+}
+
+func TestInterfaceRole(t *testing.T) {
+	for _, test := range []struct {
+		name, ifaceType, mode string
+		linked                bool
+		expected              string
+	}{
+		{"Loopback0", "", "", false, interfaceRoleLoopback},
+		{"lo0.0", "", "", false, interfaceRoleLoopback},
+		{"mgmt0", "", "", false, interfaceRoleManagement},
+		{"fxp0", "", "", false, interfaceRoleManagement},
+		{"Vlan100", "", "", false, interfaceRoleSVI},
+		{"Port-channel10", "", "TRUNK", true, interfaceRolePortChannel},
+		{"ae1", "", "", false, interfaceRolePortChannel},
+		{"Gi1/0/1", "", "TRUNK", true, interfaceRoleUplink},
+		{"Gi1/0/2", "", "TRUNK", false, interfaceRoleTrunk},
+		{"Gi1/0/3", "", "ACCESS", false, interfaceRoleAccess},
+		{"Ethernet1", "IF_LOOPBACK", "", false, interfaceRoleLoopback},
+		{"Ethernet2", "IF_ETHERNET", "", false, interfaceRoleRouted},
+	} {
+		if role := interfaceRole(test.name, test.ifaceType, test.mode, test.linked); role != test.expected {
+			t.Errorf("interfaceRole(%q) = %s, expected %s", test.name, role, test.expected)
+		}
+	}
+}
+
+func TestInterfaceConventions(t *testing.T) {
+	conventions, err := newInterfaceConventions(map[string]map[string]string{"HQ": {"access": `^USER-VLAN{vlan}$`}},
+		[]InterfaceConventionArgs{{Role: "loopback", Pattern: "^RID"}, {Site: "hq", Role: "Uplink", Pattern: `^UPLINK: {peer_device} {peer_interface}$`}})
+	if err != nil {
+		t.Fatalf("Expected valid conventions, got %v", err)
+	}
+	uplink := HygieneInterface{Location: "hq", Role: interfaceRoleUplink, PeerDevice: "core1", PeerInterface: "Eth1"}
+	if template := conventions.template(uplink); template != `^UPLINK: {peer_device} {peer_interface}$` {
+		t.Errorf("Expected the site template, got %q", template)
+	}
+	if template := conventions.template(HygieneInterface{Location: "dc", Role: interfaceRoleUplink}); template != "{peer_device}" {
+		t.Errorf("Expected the built-in uplink template elsewhere, got %q", template)
+	}
+	if expanded := expandInterfaceTemplate(`^{peer_device}.{vlan}$`, HygieneInterface{PeerDevice: "core1.example.com"}); expanded != `^(?i:core1\.example\.com)..*$` {
+		t.Errorf("Unexpected expansion: %s", expanded)
+	}
+
+	if _, err := newInterfaceConventions(nil, []InterfaceConventionArgs{{Role: "wan", Pattern: "x"}}); err == nil || !strings.Contains(err.Error(), "unknown interface role") {
+		t.Errorf("Expected an unknown role to be rejected, got %v", err)
+	}
+	if _, err := newInterfaceConventions(map[string]map[string]string{"*": {"access": "(unclosed"}}, nil); err == nil || !strings.Contains(err.Error(), "invalid convention for role access at site *") {
+		t.Errorf("Expected an invalid expression to be rejected, got %v", err)
+	}
+	if _, err := newInterfaceConventions(nil, []InterfaceConventionArgs{{Role: "uplink", Pattern: `\{device}`}}); err == nil {
+		t.Error("Expected a template that only breaks once a value is filled in to be rejected")
+	}
+
+	// A convention that breaks on expansion is reported rather than crashing the check
+	broken := interfaceConventions{interfaceAnySite: {interfaceRoleUplink: `\{device}`}}
+	findings, _ := checkInterfaceHygiene([]HygieneInterface{{Device: "r1", Role: interfaceRoleUplink, Description: "to core"}}, broken, false)
+	if len(findings) != 1 || findings[0].Type != hygieneIssueInvalid {
+		t.Errorf("Expected an invalid convention finding, got %+v", findings)
+	}
+}
+
+func TestCheckInterfaceHygiene(t *testing.T) {
+	service := hygieneTestService()
+	service.config = &config.Config{}
+	service.config.Forward.InterfaceConventions = map[string]map[string]string{"hq": {"access": `^USER-VLAN{vlan}$`}}
+
+	response, err := service.checkInterfaceHygieneTool(CheckInterfaceHygieneArgs{NetworkID: "162112", Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report struct {
+		Sites    []HygieneSiteSummary `json:"sites"`
+		Devices  []HygieneDevice      `json:"devices"`
+		Findings []HygieneFinding     `json:"findings"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	found := make(map[string]string)
+	for _, finding := range report.Findings {
+		found[finding.Device+" "+finding.Interface] = finding.Type
+	}
+	expected := map[string]string{"sw1 Gi2": hygieneIssueMisnamed, "sw1 Gi3": hygieneIssueMisnamed, "sw1 Loopback0": hygieneIssueMissing}
+	if len(found) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, found)
+	}
+	for key, issue := range expected {
+		if found[key] != issue {
+			t.Errorf("Expected %s to be %s, got %q", key, issue, found[key])
+		}
+	}
+	if len(report.Sites) != 3 || report.Sites[2] != (HygieneSiteSummary{Location: "hq", Devices: 1, Checked: 4, Undescribed: 1, Misnamed: 2}) {
+		t.Errorf("Unexpected site summaries: %+v", report.Sites)
+	}
+
+	// Down interfaces are checked on request, and call conventions win for their site and role
+	response, err = service.checkInterfaceHygieneTool(CheckInterfaceHygieneArgs{NetworkID: "162112", Location: "HQ", IncludeDown: true,
+		Conventions: []InterfaceConventionArgs{{Site: "hq", Role: "access", Pattern: "^USER-"}}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"❌ 3 of 5 checked interfaces: undescribed ×2 misnamed ×1", "| hq | 1 | 5 | 2 | 1 |",
+		"| misnamed | hq | sw1 | Gi2 | uplink | to old-core | {peer_device} |", "| undescribed | hq | sw1 | Gi9 | access | - | ^USER- |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "core1 |") || strings.Contains(text, "skipped") {
+		t.Errorf("Expected only hq interfaces with down ports included: %s", text)
+	}
+
+	if _, err := service.checkInterfaceHygieneTool(CheckInterfaceHygieneArgs{NetworkID: "162112", Conventions: []InterfaceConventionArgs{{Role: "access", Pattern: "["}}}); err == nil {
+		t.Error("Expected an invalid convention to be rejected")
+	}
+}
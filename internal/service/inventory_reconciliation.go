@@ -0,0 +1,325 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// deviceHardwareQueryID backs get_device_hardware and provides per-component serials
+const deviceHardwareQueryID = "FQ_7ec4a8148b48a91271f342c512b2af1cdb276744"
+
+// maxReconciliationRows caps each section of the markdown report; the stored entity keeps everything
+const maxReconciliationRows = 50
+
+// Normalized column names accepted for each asset field
+var (
+	assetDeviceKeys = []string{"hostname", "device", "devicename", "name"}
+	assetSerialKeys = []string{"serial", "serialnumber", "serialno", "sn"}
+	assetModelKeys  = []string{"model", "pid", "partnumber", "productid", "modelnumber"}
+)
+
+// InventoryAsset is a single asset from either the expected list or the live inventory
+type InventoryAsset struct {
+	Device string `json:"device,omitempty"`
+	Serial string `json:"serial,omitempty"`
+	Model  string `json:"model,omitempty"`
+}
+
+// InventoryMismatch is an expected asset found live with differing attributes
+type InventoryMismatch struct {
+	Expected    InventoryAsset `json:"expected"`
+	Found       InventoryAsset `json:"found"`
+	Differences []string       `json:"differences"`
+}
+
+// InventoryReconciliation is the result of comparing an asset list with the live inventory
+type InventoryReconciliation struct {
+	NetworkID  string              `json:"network_id"`
+	SnapshotID string              `json:"snapshot_id,omitempty"`
+	Expected   int                 `json:"expected"`
+	Live       int                 `json:"live"`
+	Matched    int                 `json:"matched"`
+	Missing    []InventoryAsset    `json:"missing"`
+	Unexpected []InventoryAsset    `json:"unexpected"`
+	Mismatched []InventoryMismatch `json:"mismatched"`
+}
+
+// reconcileInventory compares an uploaded CSV asset list against the live hardware inventory
+func (s *ForwardMCPService) reconcileInventory(args ReconcileInventoryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("reconcile_inventory", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	expected, err := parseAssetCSV(args.AssetsCSV)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse asset CSV: %w", err)
+	}
+
+	items, err := s.fetchAllNQEItems(networkID, snapshotID, deviceHardwareQueryID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hardware inventory: %w", err)
+	}
+	live := liveAssetsFromItems(items)
+
+	result := compareInventory(expected, live)
+	result.NetworkID = networkID
+	result.SnapshotID = snapshotID
+
+	entityID := ""
+	if s.memorySystem != nil {
+		entity, err := s.memorySystem.CreateEntity(
+			fmt.Sprintf("inventory_reconciliation_%s_%d", networkID, time.Now().Unix()),
+			"inventory_reconciliation",
+			map[string]interface{}{
+				"network_id":  networkID,
+				"snapshot_id": snapshotID,
+				"expected":    result.Expected,
+				"matched":     result.Matched,
+				"missing":     len(result.Missing),
+				"unexpected":  len(result.Unexpected),
+				"mismatched":  len(result.Mismatched),
+			},
+		)
+		if err != nil {
+			s.logger.Warn("Failed to store inventory reconciliation: %v", err)
+		} else {
+			entityID = entity.ID
+			if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(result), "data", nil); err != nil {
+				s.logger.Debug("Failed to add inventory reconciliation data: %v", err)
+			}
+		}
+	}
+
+	if strings.EqualFold(args.Format, "json") {
+		return s.streamResponse("reconcile_inventory", MarshalCompactJSONString(result)), nil
+	}
+
+	report := formatInventoryReconciliation(result)
+	if entityID != "" {
+		report += fmt.Sprintf("\nFull results stored in memory (entity: %s).\n", entityID)
+	}
+	return s.streamResponse("reconcile_inventory", report), nil
+}
+
+// parseAssetCSV reads an asset list with a header row. Each row needs a serial or a hostname.
+func parseAssetCSV(data string) ([]InventoryAsset, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, fmt.Errorf("assets_csv is empty")
+	}
+
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeFactKey(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	column := func(keys []string) int {
+		for _, key := range keys {
+			if index, ok := columns[key]; ok {
+				return index
+			}
+		}
+		return -1
+	}
+	deviceCol, serialCol, modelCol := column(assetDeviceKeys), column(assetSerialKeys), column(assetModelKeys)
+	if deviceCol < 0 && serialCol < 0 {
+		return nil, fmt.Errorf("header must include a serial or hostname column (got: %s)", strings.Join(header, ", "))
+	}
+
+	value := func(record []string, index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var assets []InventoryAsset
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		asset := InventoryAsset{
+			Device: value(record, deviceCol),
+			Serial: value(record, serialCol),
+			Model:  value(record, modelCol),
+		}
+		if asset.Device == "" && asset.Serial == "" {
+			continue
+		}
+		assets = append(assets, asset)
+	}
+	if len(assets) == 0 {
+		return nil, fmt.Errorf("no assets found")
+	}
+	return assets, nil
+}
+
+// liveAssetsFromItems extracts assets from hardware inventory rows
+func liveAssetsFromItems(items []map[string]interface{}) []InventoryAsset {
+	assets := make([]InventoryAsset, 0, len(items))
+	for _, item := range items {
+		fields := make(map[string]string, len(item))
+		for key, value := range item {
+			if str, ok := value.(string); ok {
+				fields[normalizeFactKey(key)] = str
+			}
+		}
+		asset := InventoryAsset{
+			Device: firstFactValue(fields, assetDeviceKeys),
+			Serial: firstFactValue(fields, assetSerialKeys),
+			Model:  firstFactValue(fields, append(append([]string{}, assetModelKeys...), modelFactKeys...)),
+		}
+		if asset.Device != "" || asset.Serial != "" {
+			assets = append(assets, asset)
+		}
+	}
+	return assets
+}
+
+// compareInventory matches expected assets to live ones by serial, falling back to hostname.
+// Live devices are unexpected only when none of their assets or their name were expected,
+// so modules of a listed chassis are not reported.
+func compareInventory(expected, live []InventoryAsset) *InventoryReconciliation {
+	result := &InventoryReconciliation{
+		Expected:   len(expected),
+		Live:       len(live),
+		Missing:    []InventoryAsset{},
+		Unexpected: []InventoryAsset{},
+		Mismatched: []InventoryMismatch{},
+	}
+
+	bySerial := make(map[string]InventoryAsset)
+	byDevice := make(map[string][]InventoryAsset)
+	for _, asset := range live {
+		if asset.Serial != "" {
+			bySerial[normalizeAssetValue(asset.Serial)] = asset
+		}
+		if asset.Device != "" {
+			key := strings.ToLower(asset.Device)
+			byDevice[key] = append(byDevice[key], asset)
+		}
+	}
+
+	referencedDevices := make(map[string]bool)
+	for _, want := range expected {
+		if want.Device != "" {
+			referencedDevices[strings.ToLower(want.Device)] = true
+		}
+
+		if found, ok := bySerial[normalizeAssetValue(want.Serial)]; ok && want.Serial != "" {
+			referencedDevices[strings.ToLower(found.Device)] = true
+			if differences := assetDifferences(want, found); len(differences) > 0 {
+				result.Mismatched = append(result.Mismatched, InventoryMismatch{Expected: want, Found: found, Differences: differences})
+			} else {
+				result.Matched++
+			}
+			continue
+		}
+
+		if candidates := byDevice[strings.ToLower(want.Device)]; want.Device != "" && len(candidates) > 0 {
+			found := candidates[0]
+			differences := assetDifferences(want, found)
+			if want.Serial != "" {
+				differences = append(differences, fmt.Sprintf("serial: expected %s, found %s", want.Serial, firstNonEmpty(found.Serial, "none")))
+			}
+			if len(differences) > 0 {
+				result.Mismatched = append(result.Mismatched, InventoryMismatch{Expected: want, Found: found, Differences: differences})
+			} else {
+				result.Matched++
+			}
+			continue
+		}
+
+		result.Missing = append(result.Missing, want)
+	}
+
+	reported := make(map[string]bool)
+	for _, asset := range live {
+		key := strings.ToLower(asset.Device)
+		if referencedDevices[key] || reported[key] {
+			continue
+		}
+		reported[key] = true
+		result.Unexpected = append(result.Unexpected, asset)
+	}
+	sort.Slice(result.Unexpected, func(i, j int) bool { return result.Unexpected[i].Device < result.Unexpected[j].Device })
+	return result
+}
+
+// assetDifferences compares the model and hostname of a matched asset
+func assetDifferences(want, found InventoryAsset) []string {
+	var differences []string
+	if want.Model != "" && found.Model != "" && normalizeAssetValue(want.Model) != normalizeAssetValue(found.Model) {
+		differences = append(differences, fmt.Sprintf("model: expected %s, found %s", want.Model, found.Model))
+	}
+	if want.Device != "" && found.Device != "" && !strings.EqualFold(want.Device, found.Device) {
+		differences = append(differences, fmt.Sprintf("hostname: expected %s, found %s", want.Device, found.Device))
+	}
+	return differences
+}
+
+// normalizeAssetValue makes serials and models comparable across sources
+func normalizeAssetValue(value string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(value), ""))
+}
+
+// formatInventoryReconciliation renders the reconciliation as a markdown report
+func formatInventoryReconciliation(result *InventoryReconciliation) string {
+	var report strings.Builder
+	report.WriteString("# Inventory Reconciliation\n\n")
+	report.WriteString(fmt.Sprintf("Network: %s | Expected assets: %d | Live assets: %d\n\n", result.NetworkID, result.Expected, result.Live))
+	report.WriteString(fmt.Sprintf("- ✅ Matched: %d\n- ❌ Missing: %d\n- ❓ Unexpected devices: %d\n- ⚠️ Mismatched: %d\n",
+		result.Matched, len(result.Missing), len(result.Unexpected), len(result.Mismatched)))
+
+	writeAssets := func(title string, assets []InventoryAsset) {
+		if len(assets) == 0 {
+			return
+		}
+		report.WriteString(fmt.Sprintf("\n## %s\n\n| Device | Serial | Model |\n|---|---|---|\n", title))
+		for i, asset := range assets {
+			if i == maxReconciliationRows {
+				report.WriteString(fmt.Sprintf("\n... and %d more\n", len(assets)-maxReconciliationRows))
+				break
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s |\n", asset.Device, asset.Serial, asset.Model))
+		}
+	}
+	writeAssets("Missing (expected but not found)", result.Missing)
+	writeAssets("Unexpected (found but not in asset list)", result.Unexpected)
+
+	if len(result.Mismatched) > 0 {
+		report.WriteString("\n## Mismatched\n\n| Expected | Found | Differences |\n|---|---|---|\n")
+		for i, mismatch := range result.Mismatched {
+			if i == maxReconciliationRows {
+				report.WriteString(fmt.Sprintf("\n... and %d more\n", len(result.Mismatched)-maxReconciliationRows))
+				break
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s |\n",
+				firstNonEmpty(mismatch.Expected.Device, mismatch.Expected.Serial),
+				firstNonEmpty(mismatch.Found.Device, mismatch.Found.Serial),
+				strings.Join(mismatch.Differences, "; ")))
+		}
+	}
+	return report.String()
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestParseAssetCSV(t *testing.T) {
+	assets, err := parseAssetCSV("Hostname,Serial Number,PID\ncore-1, FOC123 ,N9K-C93180YC-EX\n,,\nedge-1,,ASR1001\n")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(assets) != 2 {
+		t.Fatalf("Expected 2 assets (blank rows skipped), got %d", len(assets))
+	}
+	if assets[0] != (InventoryAsset{Device: "core-1", Serial: "FOC123", Model: "N9K-C93180YC-EX"}) {
+		t.Errorf("Unexpected first asset: %+v", assets[0])
+	}
+
+	if _, err := parseAssetCSV("model,location\nASR1001,dc1\n"); err == nil {
+		t.Error("Expected CSV without serial or hostname columns to be rejected")
+	}
+}
+
+func TestCompareInventory(t *testing.T) {
+	expected := []InventoryAsset{
+		{Device: "core-1", Serial: "FOC123", Model: "N9K-C93180YC-EX"}, // matched
+		{Device: "core-2", Serial: "FOC456", Model: "N9K-C9336C"},      // model differs
+		{Device: "edge-1", Serial: "JN999"},                            // serial differs
+		{Device: "gone-1", Serial: "ZZZ000"},                           // missing
+	}
+	live := []InventoryAsset{
+		{Device: "core-1", Serial: "foc 123", Model: "N9K-C93180YC-EX"},
+		{Device: "core-1", Serial: "LC001", Model: "N9K-X9732C"}, // module of a listed chassis
+		{Device: "core-2", Serial: "FOC456", Model: "N9K-C93240YC"},
+		{Device: "edge-1", Serial: "JN111"},
+		{Device: "rogue-1", Serial: "XYZ789"},
+	}
+
+	result := compareInventory(expected, live)
+	if result.Matched != 1 {
+		t.Errorf("Expected 1 match, got %d", result.Matched)
+	}
+	if len(result.Missing) != 1 || result.Missing[0].Device != "gone-1" {
+		t.Errorf("Expected gone-1 missing, got %+v", result.Missing)
+	}
+	if len(result.Unexpected) != 1 || result.Unexpected[0].Device != "rogue-1" {
+		t.Errorf("Expected only rogue-1 unexpected, got %+v", result.Unexpected)
+	}
+	if len(result.Mismatched) != 2 {
+		t.Fatalf("Expected 2 mismatches, got %+v", result.Mismatched)
+	}
+	if !contains(result.Mismatched[1].Differences[0], "serial: expected JN999, found JN111") {
+		t.Errorf("Unexpected serial difference: %v", result.Mismatched[1].Differences)
+	}
+}
+
+func TestReconcileInventory(t *testing.T) {
+	service := createTestService()
+	service.forwardClient.(*MockForwardClient).nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"device": "router-1", "serialNumber": "SN1", "model": "ISR4451"},
+		{"device": "switch-1", "serialNumber": "SN2", "model": "C9300"},
+	}}
+
+	response, err := service.reconcileInventory(ReconcileInventoryArgs{
+		NetworkID: "162112",
+		AssetsCSV: "serial,model\nSN1,ISR4451\nSN3,C9300\n",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"Matched: 1", "Missing: 1", "Unexpected devices: 1", "| switch-1 | SN2 | C9300 |"} {
+		if !contains(text, expected) {
+			t.Errorf("Expected %q in report, got: %s", expected, text)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IP address families
+const (
+	ipFamilyV4 = 4
+	ipFamilyV6 = 6
+)
+
+// Default aggregation levels used for prefix discovery per address family
+var (
+	ipv4AggregationLevels = []int{8, 16, 24}
+	ipv6AggregationLevels = []int{32, 48, 64}
+)
+
+// ipFamily returns ipFamilyV4 or ipFamilyV6 for a parsed address.
+// IPv4-mapped IPv6 addresses (::ffff:a.b.c.d) are treated as IPv4.
+func ipFamily(ip net.IP) int {
+	if ip.To4() != nil {
+		return ipFamilyV4
+	}
+	return ipFamilyV6
+}
+
+// normalizeIPOrCIDR validates an IPv4/IPv6 address or prefix and returns its canonical
+// form and address family. Bracketed IPv6 literals ("[2001:db8::1]") are accepted;
+// zoned addresses ("fe80::1%eth0") are rejected since zones are host-local.
+func normalizeIPOrCIDR(value string) (string, int, error) {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if value == "" {
+		return "", 0, fmt.Errorf("empty address")
+	}
+	if strings.Contains(value, "%") {
+		return "", 0, fmt.Errorf("zoned IPv6 address '%s' is not supported", value)
+	}
+
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String(), ipFamily(ip), nil
+	}
+
+	if strings.Contains(value, "/") {
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return "", 0, err
+		}
+		return ipNet.String(), ipFamily(ipNet.IP), nil
+	}
+
+	return "", 0, fmt.Errorf("'%s' is not a valid IP address or CIDR", value)
+}
+
+// parseInterfaceAddress parses an interface address that may be a plain IP or IP/len
+func parseInterfaceAddress(value string) net.IP {
+	if ip, _, err := net.ParseCIDR(value); err == nil {
+		return ip
+	}
+	return net.ParseIP(value)
+}
+
+// aggregationLevelsFor returns the default aggregation levels for an address
+func aggregationLevelsFor(ip net.IP) []int {
+	if ipFamily(ip) == ipFamilyV4 {
+		return ipv4AggregationLevels
+	}
+	return ipv6AggregationLevels
+}
+
+// isSignificantAggregate reports whether a prefix length is a coarse, summary-level
+// aggregate worth reporting even when only one device falls inside it
+func isSignificantAggregate(family, length int) bool {
+	if family == ipFamilyV4 {
+		return length == 8 || length == 16
+	}
+	return length == 32 || length == 48
+}
+
+// parsePrefixLevel parses an aggregation level such as "/24" or "48"
+func parsePrefixLevel(level string) (int, bool) {
+	length, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(level), "/"))
+	if err != nil || length < 0 || length > 128 {
+		return 0, false
+	}
+	return length, true
+}
+
+// prefixLevelApplies reports whether an aggregation level is meaningful for a family.
+// Levels up to /32 apply to IPv4 and levels from /32 apply to IPv6, so mixed default
+// level lists such as ["/16", "/24", "/48", "/64"] do the right thing for both.
+func prefixLevelApplies(family, length int) bool {
+	if family == ipFamilyV4 {
+		return length <= 32
+	}
+	return length >= 32 && length <= 128
+}
+
+// maskPrefixToLevel re-aggregates a prefix to the given level, returning "" when the
+// level does not apply to the prefix family or would be more specific than the prefix
+func maskPrefixToLevel(prefix, level string) string {
+	length, ok := parsePrefixLevel(level)
+	if !ok {
+		return ""
+	}
+
+	var ip net.IP
+	currentLength := -1
+	if _, ipNet, err := net.ParseCIDR(prefix); err == nil {
+		ip = ipNet.IP
+		currentLength, _ = ipNet.Mask.Size()
+	} else if parsed := net.ParseIP(prefix); parsed != nil {
+		ip = parsed
+	} else {
+		return ""
+	}
+
+	family := ipFamily(ip)
+	if !prefixLevelApplies(family, length) {
+		return ""
+	}
+	if currentLength >= 0 && length > currentLength {
+		return ""
+	}
+
+	bits := 128
+	if family == ipFamilyV4 {
+		ip = ip.To4()
+		bits = 32
+	}
+	mask := net.CIDRMask(length, bits)
+	return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+}
+
+// prefixLevelOf returns the "/N" aggregation level of a prefix, or "/32" / "/128" for
+// plain IPv4 / IPv6 host addresses
+func prefixLevelOf(prefix string) string {
+	if _, ipNet, err := net.ParseCIDR(prefix); err == nil {
+		length, _ := ipNet.Mask.Size()
+		return fmt.Sprintf("/%d", length)
+	}
+	if ip := net.ParseIP(prefix); ip != nil && ipFamily(ip) == ipFamilyV6 {
+		return "/128"
+	}
+	return "/32"
+}
+
+// prefixFamily returns the address family of an IP or prefix, or 0 if unparseable
+func prefixFamily(prefix string) int {
+	if _, family, err := normalizeIPOrCIDR(prefix); err == nil {
+		return family
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestNormalizeIPOrCIDR(t *testing.T) {
+	tests := []struct {
+		input     string
+		expected  string
+		family    int
+		shouldErr bool
+	}{
+		{"10.0.0.1", "10.0.0.1", ipFamilyV4, false},
+		{"10.1.2.3/16", "10.1.0.0/16", ipFamilyV4, false},
+		{"2001:DB8::1", "2001:db8::1", ipFamilyV6, false},
+		{"[2001:db8::1]", "2001:db8::1", ipFamilyV6, false},
+		{"2001:db8:1:2::/48", "2001:db8:1::/48", ipFamilyV6, false},
+		{"::ffff:10.0.0.1", "10.0.0.1", ipFamilyV4, false},
+		{"fe80::1%eth0", "", 0, true},
+		{"router-1", "", 0, true},
+		{"10.0.0.0/33", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, family, err := normalizeIPOrCIDR(tt.input)
+			if tt.shouldErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.input)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error for %q: %v", tt.input, err)
+			}
+			if got != tt.expected || family != tt.family {
+				t.Errorf("normalizeIPOrCIDR(%q) = %q, IPv%d; want %q, IPv%d", tt.input, got, family, tt.expected, tt.family)
+			}
+		})
+	}
+}
+
+func TestMaskPrefixToLevel(t *testing.T) {
+	tests := []struct {
+		prefix, level, expected string
+	}{
+		{"10.1.2.0/24", "/16", "10.1.0.0/16"},
+		{"10.1.0.0/16", "/24", ""}, // more specific than the source prefix
+		{"10.1.2.0/24", "/48", ""}, // IPv6-only level
+		{"2001:db8:aa:bb::/64", "/48", "2001:db8:aa::/48"},
+		{"2001:db8:aa::/48", "/24", ""}, // IPv4-only level
+		{"2001:db8::1", "/64", "2001:db8::/64"},
+	}
+
+	for _, tt := range tests {
+		if got := maskPrefixToLevel(tt.prefix, tt.level); got != tt.expected {
+			t.Errorf("maskPrefixToLevel(%q, %q) = %q; want %q", tt.prefix, tt.level, got, tt.expected)
+		}
+	}
+
+	if level := prefixLevelOf("2001:db8::/48"); level != "/48" {
+		t.Errorf("Expected /48, got %s", level)
+	}
+	if level := prefixLevelOf("2001:db8::1"); level != "/128" {
+		t.Errorf("Expected /128 for an IPv6 host, got %s", level)
+	}
+}
+
+func TestDiscoverNetworkPrefixesMixedFamilies(t *testing.T) {
+	service := createTestService()
+	mockClient := service.forwardClient.(*MockForwardClient)
+	mockClient.devices = []forward.Device{
+		{Name: "core-1", LocationID: "dc1", Interfaces: []forward.DeviceInterface{
+			{Name: "eth0", IPAddress: "10.1.1.1/24"},
+			{Name: "eth1", IPAddress: "2001:db8:10:1::1/64"},
+			{Name: "eth2", IPAddress: "fe80::1/64"},
+		}},
+		{Name: "core-2", LocationID: "dc1", Interfaces: []forward.DeviceInterface{
+			{Name: "eth0", IPAddress: "10.1.1.2/24"},
+			{Name: "eth1", IPAddress: "2001:db8:10:1::2/64"},
+			{Name: "eth2", IPAddress: "fe80::1/64"},
+		}},
+	}
+
+	prefixes, err := service.discoverNetworkPrefixes("162112", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, info := range prefixes {
+		found[info.Prefix] = true
+	}
+	for _, expected := range []string{"10.1.1.0/24", "2001:db8:10::/48", "2001:db8:10:1::/64"} {
+		if !found[expected] {
+			t.Errorf("Expected prefix %s to be discovered, got %v", expected, found)
+		}
+	}
+	if found["fe80::/64"] {
+		t.Error("Expected link-local prefixes to be skipped")
+	}
+
+	// Connectivity matrices never pair IPv4 and IPv6 prefixes
+	queries := service.createConnectivityQueries(prefixes, []string{"/24", "/64"}, []string{"10.1", "2001:db8"}, nil)
+	for _, query := range queries {
+		if prefixFamily(query.DstIP) == 0 {
+			t.Errorf("Unexpected unparseable destination %q", query.DstIP)
+		}
+	}
+}
+
+func TestSearchPathsBulkIPv6Validation(t *testing.T) {
+	service := createTestService()
+
+	_, err := service.searchPathsBulk(SearchPathsBulkArgs{
+		NetworkID: "162112",
+		Queries:   []PathSearchQueryArgs{{SrcIP: "2001:db8::1", DstIP: "2001:db8:1::/48"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected IPv6 query to be accepted, got: %v", err)
+	}
+
+	_, err = service.searchPathsBulk(SearchPathsBulkArgs{
+		NetworkID: "162112",
+		Queries:   []PathSearchQueryArgs{{SrcIP: "10.0.0.1", DstIP: "2001:db8::1"}},
+	})
+	if err == nil || !contains(err.Error(), "same address family") {
+		t.Errorf("Expected mixed-family query to be rejected, got: %v", err)
+	}
+}
@@ -2,12 +2,13 @@ package service
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -21,14 +22,24 @@ import (
 
 // Arguments for get_nqe_result_chunks tool
 // Either entity_id or (query_id, network_id, snapshot_id) must be provided
-// Optionally, chunk_index can be used to fetch a single chunk
-// If chunk_index is omitted, all chunks are returned
+// Optionally, chunk_index can be used to fetch a single chunk, or start_row/end_row a row range
+// filters and columns narrow rows and columns server-side while the chunks are decoded
+// If none of these are given, all chunks are returned
 type GetNQEResultChunksArgs struct {
 	EntityID   string `json:"entity_id" jsonschema:"required,description=Entity ID containing the NQE results"`
 	QueryID    string `json:"query_id" jsonschema:"required,description=Query ID that was executed"`
 	NetworkID  string `json:"network_id" jsonschema:"required,description=Network ID where the query was run"`
 	SnapshotID string `json:"snapshot_id" jsonschema:"required,description=Snapshot ID used for the query"`
 	ChunkIndex *int   `json:"chunk_index,omitempty" jsonschema:"description=Specific chunk index to retrieve (omit for all chunks)"`
+	StartRow   *int   `json:"start_row,omitempty" jsonschema:"description=First row of a row range to retrieve (zero-based; e.g. 1000)"`
+	EndRow     *int   `json:"end_row,omitempty" jsonschema:"description=Last row of the range, inclusive (e.g. 1500; defaults to the last row)"`
+	// Server-side row filtering and column projection
+	Filters []ResultRowFilter `json:"filters,omitempty" jsonschema:"description=Only return rows matching every filter (column_name, value, match=equals|contains)"`
+	Columns []string          `json:"columns,omitempty" jsonschema:"description=Only return these columns of each row"`
+	Limit   int               `json:"limit,omitempty" jsonschema:"description=Maximum number of filtered or projected rows to return (default 1000)"`
+	// Rollup of repeated rows at render time
+	GroupBy       []string `json:"group_by,omitempty" jsonschema:"description=Return the selected rows grouped by these columns with a count and a few examples per group"`
+	GroupExamples int      `json:"group_examples,omitempty" jsonschema:"description=Example rows shown per group with group_by (default: 2, max: 20; -1 shows counts only)"`
 }
 
 // WorkflowState represents the current state of a user workflow
@@ -38,6 +49,7 @@ type WorkflowState struct {
 	SelectedQuery string                 `json:"selected_query"`
 	NetworkID     string                 `json:"network_id"`
 	SnapshotID    string                 `json:"snapshot_id"`
+	LastStep      string                 `json:"last_step,omitempty"` // Last step rendered to the client
 }
 
 // WorkflowManager manages user workflow states
@@ -85,9 +97,40 @@ type ForwardMCPService struct {
 	queryIndex        *NQEQueryIndex
 	database          *NQEDatabase
 	memorySystem      *MemorySystem       // Knowledge graph memory system
+	prefixIndex       *PrefixIndex        // Persisted interface/prefix index per network snapshot
+	deviceAliases     *DeviceAliasStore   // Former → current device names detected across snapshots
+	timeSeries        *TimeSeriesStore    // Query metrics collected across historical snapshots
 	apiTracker        *APIMemoryTracker   // API result tracking using memory system
 	bloomManager      *BloomSearchManager // Bloom filter for efficient large result filtering
 	bloomIndexManager *BloomIndexManager  // Persistent bloom index for large NQE results
+	pathCache         *PathSearchCache    // Exact-match cache for bulk path search results
+	deviceCache       *DeviceCache        // Per-network device inventories shared across tools
+	vendorNormalizer  *VendorNormalizer   // Canonical vendor/family/OS train mapping for device facts
+	negativeCache     *NegativeCache      // Recent query and network failures for fast retries
+	timeFormatter     *TimeFormatter      // ISO-8601 timestamps in the configured display timezone
+	redactor          *Redactor           // Masks sensitive values in tool and prompt output (nil when disabled)
+	verbosity         Verbosity           // Default presentation level of tool responses
+	continuations     *ContinuationStore  // Undelivered content blocks of large streamed responses
+	confirmations     *ConfirmationStore  // Pending confirmation tokens of destructive actions
+	analysisCache     *analysisDBCache    // On-disk SQL databases of stored results (nil uses in-memory databases)
+	reports           *ReportStore        // Rendered report files, also served as resources
+	features          *FeatureFlags       // Tool registration flags (nil registers stable tools only)
+	transcript        *SessionTranscript  // Opt-in recording of tool calls (nil without the memory system)
+	workspaces        *Workspaces         // Workspace new memory artifacts are linked to (nil without the memory system)
+	intentClassifier  *IntentClassifier   // Routes natural-language requests to tools
+	prefetcher        *Prefetcher         // Background warming of likely follow-up data (nil when disabled)
+	prober            Prober              // Real probes for verify_with_probes (nil when not configured)
+	summarizer        *ResultSummarizer   // Chat model for summarize_result (nil when not configured)
+	federation        *Federation         // Further Forward instances for the federated tools (nil when not configured)
+	snapshotPins      *SnapshotPins       // Snapshot each network is pinned to during an analysis session
+	snapshotFreshness *SnapshotFreshness  // Flags stale or superseded snapshots in analysis responses (nil when off)
+	pipeline          *SnapshotPipeline   // Playbook run on every new snapshot (nil without a playbook)
+	presets           *ArgumentPresets    // Session argument presets of each API key
+	promptCallers     sync.Map            // API key IDs of prompt sessions to their identities
+	// Networks the server exposes (nil allows all)
+	networkPolicy *NetworkAccessPolicy
+	// Shares results of identical tool calls in flight at the same time
+	callCoalescer *CallCoalescer
 	// Context cancellation for graceful shutdown
 	ctx        context.Context
 	cancelFunc context.CancelFunc
@@ -111,6 +154,18 @@ func NewForwardMCPService(cfg *config.Config, logger *logger.Logger) *ForwardMCP
 		logger.Info("Using configured instance ID '%s' for partitioning", instanceID)
 	}
 
+	// Keep the API debug log with the other server data unless a file is configured
+	if cfg.Forward.APIDebug != "" && cfg.Forward.APIDebugFile == "" {
+		if dataDir, err := getWritableDataDirectory(); err == nil {
+			cfg.Forward.APIDebugFile = filepath.Join(dataDir, "logs", "forward-api-debug.log")
+		} else {
+			logger.Warn("Forward API debug log disabled: %v", err)
+		}
+	}
+	if cfg.Forward.APIDebug != "" && cfg.Forward.APIDebugFile != "" {
+		logger.Info("Forward API debug capture (%s) logging to %s", cfg.Forward.APIDebug, cfg.Forward.APIDebugFile)
+	}
+
 	// Create Forward Networks client
 	forwardClient := forward.NewClient(&cfg.Forward)
 
@@ -127,6 +182,12 @@ func NewForwardMCPService(cfg *config.Config, logger *logger.Logger) *ForwardMCP
 		embeddingService = NewKeywordEmbeddingService()
 	}
 
+	// Create prefetcher for follow-up data of interactive sessions
+	var prefetcher *Prefetcher
+	if cfg.Forward.Prefetch {
+		prefetcher = NewPrefetcher(cfg.Forward.PrefetchPerMinute)
+	}
+
 	// Create semantic cache with instance partitioning
 	semanticCache := NewSemanticCache(embeddingService, logger, instanceID, &cfg.Forward.SemanticCache)
 
@@ -156,15 +217,137 @@ func NewForwardMCPService(cfg *config.Config, logger *logger.Logger) *ForwardMCP
 		logger.Info("API memory tracker initialized for tracking API results and relationships")
 	}
 
+	// Create persisted interface/prefix index alongside the memory system
+	var prefixIndex *PrefixIndex
+	if memorySystem != nil {
+		prefixIndex, err = NewPrefixIndex(memorySystem.db, instanceID, logger)
+		if err != nil {
+			logger.Error("Failed to create prefix index: %v", err)
+			prefixIndex = nil
+		}
+	}
+
+	// Create device alias store so renamed devices keep resolving by their former names
+	var deviceAliases *DeviceAliasStore
+	if memorySystem != nil {
+		deviceAliases, err = NewDeviceAliasStore(memorySystem.db, instanceID, logger)
+		if err != nil {
+			logger.Error("Failed to create device alias store: %v", err)
+			deviceAliases = nil
+		}
+	}
+
+	// Create time-series storage for query metrics across snapshots
+	var timeSeries *TimeSeriesStore
+	if memorySystem != nil {
+		timeSeries, err = NewTimeSeriesStore(memorySystem.db, instanceID, logger)
+		if err != nil {
+			logger.Error("Failed to create time-series store: %v", err)
+			timeSeries = nil
+		}
+	}
+
 	// Create bloom search manager for efficient large result filtering
 	bloomManager := NewBloomSearchManager(logger, instanceID)
 	logger.Info("Bloom search manager initialized for efficient large result filtering")
 
-	// Create persistent bloom index manager for large NQE results
-	bloomIndexDir := filepath.Join("data", "bloom_indexes", instanceID)
+	// Create persistent bloom index manager for large NQE results under the data directory
+	bloomIndexRoot := os.Getenv("FORWARD_BLOOM_INDEX_PATH")
+	if bloomIndexRoot == "" {
+		bloomIndexRoot = filepath.Join("data", "bloom_indexes")
+		if dataDir, err := getWritableDataDirectory(); err == nil {
+			bloomIndexRoot = filepath.Join(dataDir, "bloom_indexes")
+		}
+	}
+	bloomIndexDir := filepath.Join(bloomIndexRoot, instanceID)
 	bloomIndexManager := NewBloomIndexManager(logger, bloomIndexDir)
 	logger.Info("Persistent bloom index manager initialized for large NQE results")
 
+	// Create exact-match path search cache; pinned snapshots share the semantic cache TTL
+	pathCache := NewPathSearchCache(cfg.Forward.SemanticCache.MaxEntries,
+		time.Duration(cfg.Forward.SemanticCache.TTLHours)*time.Hour, defaultPathCacheLatestTTL)
+
+	// Create device inventory cache; pinned snapshots share the semantic cache TTL
+	var deviceCache *DeviceCache
+	if cfg.Forward.SemanticCache.DeviceCacheTTLSeconds > 0 {
+		deviceCache = NewDeviceCache(time.Duration(cfg.Forward.SemanticCache.TTLHours)*time.Hour,
+			time.Duration(cfg.Forward.SemanticCache.DeviceCacheTTLSeconds)*time.Second)
+	}
+
+	// Create vendor normalizer; configured mappings take precedence over the built-in table
+	vendorNormalizer := NewVendorNormalizer(cfg.Forward.VendorMappings, logger)
+
+	// Create negative cache for recently failing queries and networks
+	var negativeCache *NegativeCache
+	if cfg.Forward.SemanticCache.NegativeTTLSeconds > 0 {
+		negativeCache = NewNegativeCache(time.Duration(cfg.Forward.SemanticCache.NegativeTTLSeconds) * time.Second)
+	}
+
+	// Create time formatter for timestamps shown in tool responses
+	timeFormatter := NewTimeFormatter(cfg.Forward.DisplayTimezone, logger)
+
+	// Create redactor for sensitive values in tool and prompt output; stored data is kept as
+	// fetched, so turning redaction off later shows it unchanged
+	var redactor *Redactor
+	if cfg.Forward.Redaction.Enabled {
+		redactor = NewRedactor(cfg.Forward.Redaction, logger)
+		logger.Info("Output redaction enabled")
+	}
+
+	// Cache analysis databases next to the memory database so repeated SQL skips rebuilding
+	var analysisCache *analysisDBCache
+	if memorySystem != nil {
+		analysisCache = newAnalysisDBCache(filepath.Join(filepath.Dir(memorySystem.dbPath), "analysis"))
+	}
+
+	// Save rendered reports as files; they are served as resources once registered
+	reports, err := NewReportStore(cfg.Forward.Reports, logger)
+	if err != nil {
+		logger.Warn("Report rendering disabled: %v", err)
+		reports = nil
+	}
+
+	// Summarize stored results with a chat model when a provider is configured
+	summarizer, err := NewResultSummarizer(cfg.Forward.Summarization)
+	if err != nil {
+		logger.Warn("Result summarization disabled: %v", err)
+	} else if summarizer != nil {
+		logger.Info("Result summarization enabled (%s, %d requests per minute)", summarizer.model.Name(), summarizer.perMinute)
+	}
+
+	// Query further Forward instances alongside this one when federation is configured
+	federation, err := NewFederation(cfg.Forward, cfg.Forward.Federation)
+	if err != nil {
+		logger.Warn("Federation disabled: %v", err)
+	} else if federation != nil {
+		logger.Info("Federation enabled with %d further instances", len(federation.instances))
+	}
+
+	// Captured API exchanges are masked with the output redaction rules as well
+	if redactor != nil {
+		clients := []forward.ClientInterface{forwardClient}
+		for _, instance := range federation.configured() {
+			clients = append(clients, instance.client)
+		}
+		for _, client := range clients {
+			if setter, ok := client.(forward.DebugRedactorSetter); ok {
+				setter.SetDebugRedactor(redactor.Redact)
+			}
+		}
+	}
+
+	// Restrict the networks exposed to clients regardless of the API key's reach
+	networkPolicy := NewNetworkAccessPolicy(cfg.Forward.AllowedNetworks, cfg.Forward.DeniedNetworks)
+	if networkPolicy != nil {
+		logger.Info("Network access restricted (%d allowed, %d denied)", len(cfg.Forward.AllowedNetworks), len(cfg.Forward.DeniedNetworks))
+		if !networkPolicy.Permits(cfg.Forward.DefaultNetworkID) {
+			logger.Warn("Default network %s is restricted and will not be used", cfg.Forward.DefaultNetworkID)
+		}
+		if memorySystem != nil {
+			memorySystem.SetVisibility(networkPolicy.PermitsEntity)
+		}
+	}
+
 	// Create context for cancellation
 	ctx, cancelFunc := context.WithCancel(context.Background())
 
@@ -183,13 +366,45 @@ func NewForwardMCPService(cfg *config.Config, logger *logger.Logger) *ForwardMCP
 		queryIndex:        queryIndex,
 		database:          database,
 		memorySystem:      memorySystem,
+		prefixIndex:       prefixIndex,
+		deviceAliases:     deviceAliases,
+		timeSeries:        timeSeries,
 		apiTracker:        apiTracker,
 		bloomManager:      bloomManager,
 		bloomIndexManager: bloomIndexManager,
+		pathCache:         pathCache,
+		deviceCache:       deviceCache,
+		vendorNormalizer:  vendorNormalizer,
+		negativeCache:     negativeCache,
+		timeFormatter:     timeFormatter,
+		redactor:          redactor,
+		verbosity:         NewVerbosity(cfg.Forward.Verbosity, logger),
+		continuations:     NewContinuationStore(defaultStreamMaxBlocks, defaultContinuationTTL),
+		confirmations:     NewConfirmationStore(defaultConfirmationTTL),
+		analysisCache:     analysisCache,
+		reports:           reports,
+		networkPolicy:     networkPolicy,
+		callCoalescer:     NewCallCoalescer(),
+		features:          NewFeatureFlags(cfg.Forward.Features),
+		transcript:        NewSessionTranscript(memorySystem, timeFormatter, logger, cfg.Forward.SessionTranscript),
+		workspaces:        NewWorkspaces(memorySystem, logger),
+		intentClassifier:  NewIntentClassifier(embeddingService),
+		prefetcher:        prefetcher,
+		prober:            NewProber(cfg.Forward.Probes),
+		summarizer:        summarizer,
+		federation:        federation,
+		snapshotPins:      NewSnapshotPins(cfg.Forward.SnapshotPinning),
+		snapshotFreshness: NewSnapshotFreshness(cfg.Forward.SnapshotFreshness),
+		pipeline:          NewSnapshotPipeline(cfg.Forward.SnapshotPipeline),
+		presets:           NewArgumentPresets(),
 		ctx:               ctx,
 		cancelFunc:        cancelFunc,
 	}
 
+	// Entities deleted longer ago than the retention period leave the trash for good
+	service.purgeExpiredTrash()
+	service.applyPinsToCache()
+
 	// Set up database callback to automatically refresh query index when database is updated
 	if database != nil && queryIndex != nil {
 		database.AddUpdateCallback(func() {
@@ -315,8 +530,18 @@ func (s *ForwardMCPService) Shutdown(timeout time.Duration) error {
 	return nil
 }
 
-// Helper function to get network ID with fallback to default
+// Helper function to get network ID with fallback to default. Networks hidden by the
+// network access policy resolve to "" so they are never queried.
 func (s *ForwardMCPService) getNetworkID(networkID string) string {
+	networkID = s.networkIDOrDefault(networkID)
+	if s.checkNetworkAccess(networkID) != nil {
+		return ""
+	}
+	return networkID
+}
+
+// networkIDOrDefault returns networkID, or the default network when it is empty
+func (s *ForwardMCPService) networkIDOrDefault(networkID string) string {
 	if networkID != "" {
 		return networkID
 	}
@@ -369,7 +594,9 @@ func (s *ForwardMCPService) timeAndLogTool(toolName string, args interface{}, fn
 }
 
 // RegisterTools registers all Forward Networks tools with the MCP server
-func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
+func (s *ForwardMCPService) RegisterTools(mcpServer *mcp.Server) error {
+	server := &toolServer{Server: mcpServer, service: s}
+
 	// Network Management Tools
 	if err := server.RegisterTool("list_networks",
 		"List all networks in the Forward platform. Returns network IDs, names, and descriptions. Use this to discover available networks or find network IDs for other operations. Supports pagination (limit/offset) and memory storage for large datasets.",
@@ -397,13 +624,13 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 
 	// Path Search Tools
 	if err := server.RegisterTool("search_paths",
-		"🔍 **SINGLE PATH SEARCH**: Execute a single path search by tracing packets through the network.\n\nExecute path searches by tracing packets through the network. This tool is optimized for single path queries.\n\n**Source Specification Rules:**\n- **Option 1**: Use 'from' (device name) - API will use the device as source\n- **Option 2**: Use 'src_ip' (IP address/subnet) - API will resolve the IP to source locations\n- **Option 3**: Use both 'from' + 'src_ip' for precise packet header specification\n\n**Destination Specification:**\n- **REQUIRED**: 'dst_ip' must be a valid IP address or CIDR\n- **IMPORTANT**: Device names are NOT supported in dst_ip - use actual IP addresses\n\n**Best Practices:**\n- Use 'intent' parameter to control search behavior (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)\n- Set 'max_results' and 'max_candidates' to control response size and performance\n- Use 'max_seconds' for timeout control\n- 'snapshot_id' is optional - API uses latest processed snapshot if omitted\n\n**For multiple paths, use search_paths_bulk for better performance.**",
+		"🔍 **SINGLE PATH SEARCH**: Execute a single path search by tracing packets through the network.\n\nExecute path searches by tracing packets through the network. This tool is optimized for single path queries.\n\n**Source Specification Rules:**\n- **Option 1**: Use 'from' (device name) - API will use the device as source\n- **Option 2**: Use 'src_ip' (IP address/subnet) - API will resolve the IP to source locations\n- **Option 3**: Use both 'from' + 'src_ip' for precise packet header specification\n\n**Destination Specification:**\n- **REQUIRED**: 'dst_ip' must be a valid IPv4/IPv6 address or CIDR (src_ip must use the same family)\n- A device name in dst_ip resolves to that device's address; names not in the inventory are rejected\n\n**Best Practices:**\n- Use 'intent' parameter to control search behavior (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)\n- Set 'max_results' and 'max_candidates' to control response size and performance\n- Use 'max_seconds' for timeout control\n- 'snapshot_id' is optional - API uses latest processed snapshot if omitted\n\n**For multiple paths, use search_paths_bulk for better performance.**",
 		s.searchPathsEntry); err != nil {
 		return fmt.Errorf("failed to register search_paths tool: %w", err)
 	}
 
 	if err := server.RegisterTool("search_paths_bulk",
-		"🚀 **RECOMMENDED**: Use this tool for path searches (single or bulk) with better performance.\n\nExecute path searches by tracing packets through the network. Supports both single and bulk path searches.\n\n**Source Specification Rules:**\n- **Option 1**: Use 'from' (device name) - API will use the device as source\n- **Option 2**: Use 'src_ip' (IP address/subnet) - API will resolve the IP to source locations\n- **Option 3**: Use both 'from' + 'src_ip' for precise packet header specification\n\n**Destination Specification:**\n- **REQUIRED**: 'dst_ip' must be a valid IP address or CIDR\n- **IMPORTANT**: Device names are NOT supported in dst_ip - use actual IP addresses\n\n**Best Practices:**\n- Use 'intent' parameter to control search behavior (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)\n- Set 'max_results' and 'max_candidates' to control response size and performance\n- Use 'max_seconds' and 'max_overall_seconds' for timeout control\n- 'snapshot_id' is optional - API uses latest processed snapshot if omitted\n\n**Request Format:** Provide an array of path search queries, each with 'dst_ip' and either 'from' or 'src_ip'.",
+		"🚀 **RECOMMENDED**: Use this tool for path searches (single or bulk) with better performance.\n\nExecute path searches by tracing packets through the network. Supports both single and bulk path searches.\n\n**Source Specification Rules:**\n- **Option 1**: Use 'from' (device name) - API will use the device as source\n- **Option 2**: Use 'src_ip' (IP address/subnet) - API will resolve the IP to source locations\n- **Option 3**: Use both 'from' + 'src_ip' for precise packet header specification\n\n**Destination Specification:**\n- **REQUIRED**: 'dst_ip' must be a valid IPv4/IPv6 address or CIDR (src_ip must use the same family)\n- A device name in dst_ip resolves to that device's address; names not in the inventory are rejected\n\n**Best Practices:**\n- Use 'intent' parameter to control search behavior (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)\n- Set 'max_results' and 'max_candidates' to control response size and performance\n- Use 'max_seconds' and 'max_overall_seconds' for timeout control\n- 'snapshot_id' is optional - API uses latest processed snapshot if omitted\n\n**Request Format:** Provide an array of path search queries, each with 'dst_ip' and either 'from' or 'src_ip'. Flow lists from spreadsheets can be passed as 'csv' (or 'csv_file') with src,dst,proto,port columns; invalid rows are reported by row number and results are correlated back to their rows.",
 		s.searchPathsBulkEntry); err != nil {
 		return fmt.Errorf("failed to register search_paths_bulk tool: %w", err)
 	}
@@ -415,15 +642,63 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register analyze_network_prefixes tool: %w", err)
 	}
 
+	if err := server.RegisterTool("troubleshoot_connectivity",
+		"🩺 **Troubleshoot connectivity end-to-end** between a source and destination (device names or IPs). Resolves both endpoints, runs path search in both directions, checks ACL verdicts and the route trace, looks up the route matched at the failure point, and summarizes the likely failure point. Each intermediate result is stored as a memory entity for drill-down.",
+		s.troubleshootConnectivity); err != nil {
+		return fmt.Errorf("failed to register troubleshoot_connectivity tool: %w", err)
+	}
+
+	if err := server.RegisterTool("sweep_violations",
+		"Sweep the whole network for blackholes, routing loops and ACL drops: runs VIOLATIONS_ONLY path searches between representative hosts of the prefixes in the prefix index and summarizes the violations by type and device, with example flows to troubleshoot.",
+		s.sweepViolations); err != nil {
+		return fmt.Errorf("failed to register sweep_violations tool: %w", err)
+	}
+
+	if err := server.RegisterTool("detect_connectivity_drift",
+		"Re-run the connectivity matrix saved by analyze_network_prefixes (save_baseline=true) and report the prefix pairs whose status changed since the baseline, regressions first. Intended for post-change validation; use sample to re-run a random subset of the pairs.",
+		s.detectConnectivityDrift); err != nil {
+		return fmt.Errorf("failed to register detect_connectivity_drift tool: %w", err)
+	}
+
+	if err := server.RegisterTool("build_service_map",
+		"Build a service dependency map from application endpoints given as CSV (tier, ip, optional name, proto and port) or memory entities. Runs bulk path searches between the endpoints of different tiers (or along tier_order) and reports which tiers reach which, through which firewalls and load balancers. The map is stored in the knowledge graph as tier entities with depends_on and traverses relations; export it with export_service_map.",
+		s.buildServiceMap); err != nil {
+		return fmt.Errorf("failed to register build_service_map tool: %w", err)
+	}
+
+	if err := server.RegisterTool("export_service_map",
+		"Export a service map stored by build_service_map as GraphML (for yEd, Gephi or networkx) or JSON. Tiers and middleboxes are nodes; depends_on edges carry the flow counts and the middleboxes crossed.",
+		s.exportServiceMap); err != nil {
+		return fmt.Errorf("failed to register export_service_map tool: %w", err)
+	}
+
 	// NQE Tools
 	if err := server.RegisterTool("run_nqe_query_by_id",
-		"🚀 **RECOMMENDED**: Use this tool for standard network analysis and compliance checks.\n\nRun a Network Query Engine (NQE) query using a predefined query ID from the library. This is the preferred method for consistent, reliable network analysis.\n\n**Best Practices:**\n- Use 'all_results: true' to fetch complete datasets\n- Set appropriate 'limit' and 'offset' for pagination\n- Use 'parameters' for dynamic query customization\n- Check query descriptions with list_nqe_queries first\n\n**Performance Tips:**\n- Large results are automatically cached and chunked\n- Use semantic search to find relevant queries\n- Set reasonable limits to avoid timeouts",
+		"🚀 **RECOMMENDED**: Use this tool for standard network analysis and compliance checks.\n\nRun a Network Query Engine (NQE) query using a predefined query ID from the library. This is the preferred method for consistent, reliable network analysis.\n\n**Best Practices:**\n- Use 'all_results: true' to fetch complete datasets\n- Use 'sample: true' to inspect the columns and size of a large result first\n- Use 'group_by' to render repeated rows (e.g. one per interface) as counts with a few examples per group\n- Set appropriate 'limit' and 'offset' for pagination\n- Use 'parameters' for dynamic query customization\n- Check query descriptions with list_nqe_queries first\n\n**Performance Tips:**\n- Large results are automatically cached and chunked\n- Use semantic search to find relevant queries\n- Set reasonable limits to avoid timeouts",
 		s.runNQEQueryByID); err != nil {
 		return fmt.Errorf("failed to register run_nqe_query_by_id tool: %w", err)
 	}
 
+	if err := server.RegisterTool("estimate_query_cost",
+		"Predict the runtime and result size of an NQE library query on a network before running it, from earlier runs of the query (row counts, durations, page sizes) and its metadata. Recommends a direct run, all_results or sampling, with the call to make. Nothing is sent to the API unless the device count is needed for a query that never ran.",
+		s.estimateQueryCost); err != nil {
+		return fmt.Errorf("failed to register estimate_query_cost tool: %w", err)
+	}
+
+	if err := server.RegisterTool("run_federated_query",
+		"Run the same NQE query (by query_id or source) on this Forward instance and every configured federated instance concurrently, and merge the rows with an instance column. An instance that fails is reported with its error while the others still return rows. The merged result is stored for get_nqe_result_summary and analyze_nqe_result_sql.",
+		s.runFederatedQuery); err != nil {
+		return fmt.Errorf("failed to register run_federated_query tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_federated_inventory",
+		"List the devices of this Forward instance and every configured federated instance in one inventory with an instance column and normalized vendor and platform family. Failing instances are reported without hiding the others.",
+		s.getFederatedInventory); err != nil {
+		return fmt.Errorf("failed to register get_federated_inventory tool: %w", err)
+	}
+
 	if err := server.RegisterTool("list_nqe_queries",
-		"🔍 **DISCOVERY TOOL**: Find available NQE queries for your analysis needs.\n\nList available NQE queries from the Forward Networks query library. Use this to discover predefined queries for reports and analysis.\n\n**Usage Tips:**\n- Filter by directory (e.g., '/L3/Basic/', '/L3/Advanced/', '/L3/Security/')\n- Use search_nqe_queries for semantic search\n- Check query descriptions before running\n- Use query IDs with run_nqe_query_by_id",
+		"🔍 **DISCOVERY TOOL**: Find available NQE queries for your analysis needs.\n\nList available NQE queries from the Forward Networks query library. Use this to discover predefined queries for reports and analysis.\n\n**Usage Tips:**\n- Filter by directory (e.g., '/L3/Basic/', '/L3/Advanced/', '/L3/Security/')\n- Filter or group by category, including custom categories from set_query_category\n- Use search_nqe_queries for semantic search\n- Check query descriptions before running\n- Use query IDs with run_nqe_query_by_id",
 		s.listNQEQueries); err != nil {
 		return fmt.Errorf("failed to register list_nqe_queries tool: %w", err)
 	}
@@ -453,14 +728,104 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register get_os_support tool: %w", err)
 	}
 
+	if err := server.RegisterTool("forecast_eol_exposure",
+		"📅 **EOL BUDGET FORECAST**: Plan hardware refreshes and OS upgrades from support dates.\n\nCombines hardware and OS support data into a planning report that groups devices by end-of-support buckets (overdue, next 6/12/24 months), location and vendor, and estimates replacements and upgrades per quarter. The report is stored as a memory entity with a CSV export; use format: csv to get the CSV directly.",
+		s.forecastEOLExposure); err != nil {
+		return fmt.Errorf("failed to register forecast_eol_exposure tool: %w", err)
+	}
+
+	if err := server.RegisterTool("run_snapshot_pipeline",
+		"Run the configured snapshot validation playbook now: NQE assertions that must return no more than their allowed rows, key path checks and key queries stored and diffed with the previous snapshot. The same playbook runs automatically on new snapshots when polling or the webhook is configured.",
+		s.runSnapshotPipelineTool); err != nil {
+		return fmt.Errorf("failed to register run_snapshot_pipeline tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_pipeline_runs",
+		"List recent snapshot pipeline runs with their trigger, result, failed checks and changed rows; each run is also stored as a pipeline_run memory entity.",
+		s.getPipelineRuns); err != nil {
+		return fmt.Errorf("failed to register get_pipeline_runs tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_platform_checks",
+		"✅ **PLATFORM CHECKS**: List the verification checks configured in Forward Enterprise (isolation, reachability, existential, NQE and predefined checks) with their pass/fail status and violation count on a snapshot. Failing checks are listed first; filter by type, priority or status.",
+		s.listPlatformChecks); err != nil {
+		return fmt.Errorf("failed to register list_platform_checks tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_check_results",
+		"Read Forward's own verification results per snapshot. With check_id: the check's diagnosis and its status on recent snapshots. Without: status counts per snapshot, the failing checks and the checks that started or stopped failing since the previous snapshot (pass snapshots: 2 or more).",
+		s.getCheckResults); err != nil {
+		return fmt.Errorf("failed to register get_check_results tool: %w", err)
+	}
+
+	if err := server.RegisterTool("save_preset",
+		"Save a named bundle of tool arguments (network, snapshot, limits, intent...) so later calls can pass preset: <name> instead of repeating them. Arguments given with a call override the preset and tools ignore preset arguments they do not take. Session presets last until the server restarts; persistent presets are stored in the memory system. Each API key has its own presets.",
+		s.savePreset); err != nil {
+		return fmt.Errorf("failed to register save_preset tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_presets",
+		"List your saved argument presets with their scope and arguments.",
+		s.listPresets); err != nil {
+		return fmt.Errorf("failed to register list_presets tool: %w", err)
+	}
+
+	if err := server.RegisterTool("delete_preset",
+		"Delete a saved argument preset from the session, the persistent store or both.",
+		s.deletePreset); err != nil {
+		return fmt.Errorf("failed to register delete_preset tool: %w", err)
+	}
+
+	if err := server.RegisterTool("generate_network_docs",
+		"Generate an architecture document for a network for handover and audits: devices by site, per-site prefixes, verified key paths, an EOL summary and a Mermaid topology diagram from the topology export. Rendered as markdown, html or pdf and saved as a forward://reports/ resource; rerun it to refresh the document.",
+		s.generateNetworkDocs); err != nil {
+		return fmt.Errorf("failed to register generate_network_docs tool: %w", err)
+	}
+
+	if err := server.RegisterTool("reconcile_inventory",
+		"📋 **INVENTORY RECONCILIATION**: Compare an expected asset list against the live hardware inventory.\n\nAccepts a CSV of expected assets (serial, hostname, model columns) and reports assets that are missing from the network, devices that are not on the list, and assets whose model or hostname do not match. Serials are matched first, hostnames second. Full results are stored as a memory entity.",
+		s.reconcileInventory); err != nil {
+		return fmt.Errorf("failed to register reconcile_inventory tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_vlan_inventory",
+		"List VLANs per device and trunk: VLANs in local use (access, native and SVI VLANs), access port and trunk counts, and each trunk's peer, native VLAN and allowed VLANs, with a per-site summary.",
+		s.getVLANInventory); err != nil {
+		return fmt.Errorf("failed to register get_vlan_inventory tool: %w", err)
+	}
+
+	if err := server.RegisterTool("check_vlan_consistency",
+		"Check both ends of every trunk link for VLAN problems: VLANs used on one side that do not exist on the other, allowed-VLAN list mismatches, native VLAN mismatches and trunks facing access ports. Summarized per site.",
+		s.checkVLANConsistencyTool); err != nil {
+		return fmt.Errorf("failed to register check_vlan_consistency tool: %w", err)
+	}
+
+	if err := server.RegisterTool("check_fhrp",
+		"Check HSRP and VRRP gateway redundancy: groups with no standby, no active or several active members, members disagreeing on the virtual address, and priorities that tie or contradict the active member. Summarized per site with the gateway VIPs of unhealthy groups.",
+		s.checkFHRPTool); err != nil {
+		return fmt.Errorf("failed to register check_fhrp tool: %w", err)
+	}
+
+	if err := server.RegisterTool("check_interface_hygiene",
+		"Audit interface descriptions against the site naming conventions: regex templates per interface role (uplink, trunk, access, loopback, management, svi, port_channel, routed) from the configuration or the call. Reports undescribed and misnamed interfaces per device and site.",
+		s.checkInterfaceHygieneTool); err != nil {
+		return fmt.Errorf("failed to register check_interface_hygiene tool: %w", err)
+	}
+
 	if err := server.RegisterTool("search_configs",
 		"🔍 **CONFIGURATION SEARCH**: Search device configurations for specific patterns and settings.\n\nSearch device configurations for specific patterns, commands, or settings. Use this to find specific configurations across your network.\n\n**Pattern Examples:**\n```\ninterface\n  zone-member security\n  ip address {ip:string}\n```\n\n**Best Practices:**\n- Use hierarchical patterns with indentation\n- Extract variables with {name:type} syntax\n- Filter by device names for targeted searches\n- Use specific patterns for better results\n\n**Common Use Cases:**\n- Find specific interface configurations\n- Locate security policies\n- Identify routing configurations\n- Audit configuration compliance",
 		s.searchConfigs); err != nil {
 		return fmt.Errorf("failed to register search_configs tool: %w", err)
 	}
 
+	if err := server.RegisterTool("get_config_section",
+		"📄 **Get a single configuration section** for a device instead of its full config. Parses the configuration per vendor grammar (Cisco/Arista indentation, Juniper braces or set commands) and returns only the requested interfaces, bgp, ospf, acl, static_routes or vlans section, optionally narrowed to one named instance.",
+		s.getConfigSection); err != nil {
+		return fmt.Errorf("failed to register get_config_section tool: %w", err)
+	}
+
 	if err := server.RegisterTool("get_config_diff",
-		"Compare network configurations between snapshots to identify changes. Essential for change tracking and troubleshooting configuration drift.",
+		"Compare network configurations between snapshots to identify changes. Returns a per-device summary (devices changed, lines added/removed); pass device to drill into one device's diff. Essential for change tracking and troubleshooting configuration drift.",
 		s.getConfigDiff); err != nil {
 		return fmt.Errorf("failed to register get_config_diff tool: %w", err)
 	}
@@ -491,8 +856,20 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register get_latest_snapshot tool: %w", err)
 	}
 
+	if err := server.RegisterTool("start_analysis_session",
+		"Start an analysis session that pins each network to one snapshot: the first call that omits snapshot_id records the snapshot it uses and later calls reuse it, so a snapshot finishing processing mid-analysis does not switch data between calls. A warning is added when a newer snapshot exists. Pass snapshot_id to pin a specific snapshot now; calling it again re-pins.",
+		s.startAnalysisSession); err != nil {
+		return fmt.Errorf("failed to register start_analysis_session tool: %w", err)
+	}
+
+	if err := server.RegisterTool("end_analysis_session",
+		"End the analysis session started with start_analysis_session. Calls that omit snapshot_id use the default or latest snapshot again.",
+		s.endAnalysisSession); err != nil {
+		return fmt.Errorf("failed to register end_analysis_session tool: %w", err)
+	}
+
 	if err := server.RegisterTool("delete_snapshot",
-		"Delete a network snapshot. Requires snapshot_id. WARNING: This permanently removes the snapshot and associated historical data. Use with caution for cleanup of old snapshots.",
+		"Delete a network snapshot. Requires snapshot_id. WARNING: This permanently removes the snapshot and associated historical data. The first call only describes the impact and returns a confirmation_token; call again with the token to delete.",
 		s.deleteSnapshot); err != nil {
 		return fmt.Errorf("failed to register delete_snapshot tool: %w", err)
 	}
@@ -517,7 +894,7 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 	}
 
 	if err := server.RegisterTool("delete_location",
-		"Delete a location from a network. Requires network_id and location_id. Use to remove locations that are no longer needed.",
+		"Delete a location from a network. Requires network_id and location_id. The first call reports how many devices are assigned to the location and returns a confirmation_token; call again with the token to delete.",
 		s.deleteLocation); err != nil {
 		return fmt.Errorf("failed to register delete_location tool: %w", err)
 	}
@@ -534,6 +911,24 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register update_device_locations tool: %w", err)
 	}
 
+	if err := server.RegisterTool("plan_device_onboarding",
+		"Record devices being added to a network (name, management IP and planned location) against the newest snapshot as a baseline, and explain how to add their collection sources and credentials in Forward. Follow with check_device_onboarding once a snapshot is collected.",
+		s.planDeviceOnboarding); err != nil {
+		return fmt.Errorf("failed to register plan_device_onboarding tool: %w", err)
+	}
+
+	if err := server.RegisterTool("check_device_onboarding",
+		"Validate an onboarding batch against the newest snapshot: which devices are collected (matched by name or management IP), still missing, awaiting a snapshot newer than the baseline, or not yet at their planned location.",
+		s.checkDeviceOnboarding); err != nil {
+		return fmt.Errorf("failed to register check_device_onboarding tool: %w", err)
+	}
+
+	if err := server.RegisterTool("assign_onboarded_locations",
+		"Assign the collected devices of an onboarding batch to their planned locations (or given overrides) through update_device_locations. Use dry_run to preview.",
+		s.assignOnboardedLocations); err != nil {
+		return fmt.Errorf("failed to register assign_onboarded_locations tool: %w", err)
+	}
+
 	// Default Settings Management Tools
 	if err := server.RegisterTool("get_default_settings",
 		"View current default settings for network operations. Shows the default network ID, snapshot ID, and query limits configured for this session.",
@@ -566,13 +961,55 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register clear_cache tool: %w", err)
 	}
 
+	if err := server.RegisterTool("list_cache_entries",
+		"List semantic cache entries with their query, network, age and access count. Filter by network, age and minimum access count to see what is cached.",
+		s.listCacheEntries); err != nil {
+		return fmt.Errorf("failed to register list_cache_entries tool: %w", err)
+	}
+
+	if err := server.RegisterTool("inspect_cache_entry",
+		"Inspect a single semantic cache entry by hash: the cached query, its most similar cached neighbors (and whether they would hit), and where its result is stored.",
+		s.inspectCacheEntry); err != nil {
+		return fmt.Errorf("failed to register inspect_cache_entry tool: %w", err)
+	}
+
+	if err := server.RegisterTool("evict_cache_entry",
+		"Evict a single semantic cache entry by hash, for example a stale or wrong result, without clearing the rest of the cache.",
+		s.evictCacheEntry); err != nil {
+		return fmt.Errorf("failed to register evict_cache_entry tool: %w", err)
+	}
+
 	// AI-Powered Query Discovery Tools
+	if err := server.RegisterTool("classify_intent",
+		"Route a natural-language request to the best tool. Returns the tool (search_paths, search_nqe_queries, search_configs, search_entities, which_devices_in_prefix, get_config_diff or list_devices), a confidence and an argument skeleton with the addresses, ports and quoted text found in the request. Call it first when unsure which tool answers a question.",
+		s.classifyIntent); err != nil {
+		return fmt.Errorf("failed to register classify_intent tool: %w", err)
+	}
+
 	if err := server.RegisterTool("search_nqe_queries",
-		"🧠 **AI-POWERED SEARCH**: Find relevant NQE queries using natural language.\n\nAI-powered search through 6000+ predefined NQE queries using natural language. Describe what you want to analyze and get relevant query suggestions.\n\n**Best Practices:**\n- Be specific and descriptive in your query\n- Use examples like 'AWS security issues', 'BGP routing problems'\n- Avoid vague terms like 'network' or 'config'\n- Use category filters to narrow results\n\n**Example Queries:**\n- 'show me AWS security vulnerabilities'\n- 'find BGP routing issues'\n- 'check interface utilization'\n- 'devices with high CPU usage'\n\n**Note:** For executable queries, use find_executable_query instead.",
+		"🧠 **AI-POWERED SEARCH**: Find relevant NQE queries using natural language.\n\nAI-powered search through 6000+ predefined NQE queries using natural language. Describe what you want to analyze and get relevant query suggestions.\n\n**Best Practices:**\n- Be specific and descriptive in your query\n- Use examples like 'AWS security issues', 'BGP routing problems'\n- Avoid vague terms like 'network' or 'config'\n- Use category filters to narrow results; custom categories from set_query_category match too\n\n**Example Queries:**\n- 'show me AWS security vulnerabilities'\n- 'find BGP routing issues'\n- 'check interface utilization'\n- 'devices with high CPU usage'\n\n**Note:** For executable queries, use find_executable_query instead.",
 		s.searchNQEQueries); err != nil {
 		return fmt.Errorf("failed to register search_nqe_queries tool: %w", err)
 	}
 
+	if err := server.RegisterTool("get_nqe_query_source",
+		"📜 **View NQE query source**: Show what a library query actually does before running it. Returns the NQE source code, description, parameters and last commit for a query ID.",
+		s.getNQEQuerySource); err != nil {
+		return fmt.Errorf("failed to register get_nqe_query_source tool: %w", err)
+	}
+
+	if err := server.RegisterTool("suggest_parameter_values",
+		"Propose valid values for NQE query parameters such as device names, interfaces, VLANs, prefixes and IPs from the cached inventory and prefix index, filtered by the text typed so far. Pass query_id to cover every parameter the query declares, so run_nqe_query_by_id gets correct values on the first attempt.",
+		s.suggestParameterValues); err != nil {
+		return fmt.Errorf("failed to register suggest_parameter_values tool: %w", err)
+	}
+
+	if err := server.RegisterTool("check_query_compatibility",
+		"🧪 **Check query compatibility** before running it. Lints NQE source and compares its data sources and vendor/OS/device type filters against the network's device platforms, flagging queries likely to return no rows or fail.",
+		s.checkQueryCompatibility); err != nil {
+		return fmt.Errorf("failed to register check_query_compatibility tool: %w", err)
+	}
+
 	// Bulk location setup workflow (guides bulk upsert using PATCH)
 	if err := server.RegisterPrompt("bulk_location_setup", "Guide to bulk create or update network locations", func(args struct {
 		SessionID string `json:"session_id,omitempty"`
@@ -603,7 +1040,7 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 
 	// Database Hydration Tools
 	if err := server.RegisterTool("hydrate_database",
-		"Hydrate the NQE database by loading queries from the Forward Networks API. Use this to refresh the database with latest query metadata and ensure optimal performance for search operations. Automatically refreshes the query index and optionally regenerates AI embeddings.",
+		"Hydrate the NQE database by loading queries from the Forward Networks API. Use this to refresh the database with latest query metadata and ensure optimal performance for search operations. Enhanced mode fetches query details in parallel per repository directory and checkpoints each directory, so an interrupted run resumes where it stopped; get_database_status shows per-shard progress. Automatically refreshes the query index and optionally regenerates AI embeddings.",
 		s.hydrateDatabase); err != nil {
 		return fmt.Errorf("failed to register hydrate_database tool: %w", err)
 	}
@@ -614,6 +1051,36 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register refresh_query_index tool: %w", err)
 	}
 
+	if err := server.RegisterTool("set_query_category",
+		"Map a query ID or path prefix to a custom category (e.g. cloud, datacenter, campus) for this instance. Custom categories apply to the category filters of search_nqe_queries and list_nqe_queries and to list groupings; query ID rules win over path rules and the longest path prefix wins.",
+		s.setQueryCategory); err != nil {
+		return fmt.Errorf("failed to register set_query_category tool: %w", err)
+	}
+
+	if err := server.RegisterTool("remove_query_category",
+		"Remove a custom category rule created with set_query_category.",
+		s.removeQueryCategory); err != nil {
+		return fmt.Errorf("failed to register remove_query_category tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_query_taxonomy",
+		"List the custom query category rules of this instance and how many indexed queries each covers.",
+		s.listQueryTaxonomy); err != nil {
+		return fmt.Errorf("failed to register list_query_taxonomy tool: %w", err)
+	}
+
+	if err := server.RegisterTool("purge_deprecated_queries",
+		"Delete NQE queries that hydration marked as deprecated because they were removed or renamed in the Forward library. Deprecated queries are already excluded from search; use dry_run to preview and older_than_days to keep recent ones.",
+		s.purgeDeprecatedQueries); err != nil {
+		return fmt.Errorf("failed to register purge_deprecated_queries tool: %w", err)
+	}
+
+	if err := server.RegisterTool("query_catalog_sql",
+		"Run a read-only SQL query over the local NQE query catalog. Tables: queries (instance_id, query_id, path, directory, intent, description, repository, source_code, parameter_count, parameters, last_commit_id, last_commit_author, last_commit_date, last_commit_title, created_at, updated_at, deprecated_at), metadata (instance_id, key, value, updated_at) and instances (instance_id, current, query_count, deprecated_count, first_sync, last_sync). Timestamps are Unix seconds. Same sandbox as analyze_nqe_result_sql: one SELECT/WITH statement, no writes, timeout and memory limit. Example: SELECT directory, COUNT(*) FROM queries WHERE parameter_count > 0 GROUP BY directory;",
+		s.queryCatalogSQL); err != nil {
+		return fmt.Errorf("failed to register query_catalog_sql tool: %w", err)
+	}
+
 	if err := server.RegisterTool("get_database_status",
 		"Get the current status of the database and query index including query counts, last update times, and performance metrics.",
 		s.getDatabaseStatus); err != nil {
@@ -633,6 +1100,18 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register create_relation tool: %w", err)
 	}
 
+	if err := server.RegisterTool("bulk_create_entities",
+		"Create or update many entities in the knowledge graph in one transaction. Entities are matched by name and type: existing ones keep their ID and get new metadata keys merged in. Reports the outcome of each item; by default nothing is stored when any item fails.",
+		s.bulkCreateEntities); err != nil {
+		return fmt.Errorf("failed to register bulk_create_entities tool: %w", err)
+	}
+
+	if err := server.RegisterTool("bulk_create_relations",
+		"Create many relations in the knowledge graph in one transaction. Endpoints are entity IDs or names, optionally with a type. Relations that already exist are kept and get new properties merged in. Reports the outcome of each item; by default nothing is stored when any item fails.",
+		s.bulkCreateRelations); err != nil {
+		return fmt.Errorf("failed to register bulk_create_relations tool: %w", err)
+	}
+
 	if err := server.RegisterTool("add_observation",
 		"Add an observation to an entity. Observations are additional facts, notes, preferences, or behaviors associated with an entity.",
 		s.addObservation); err != nil {
@@ -651,6 +1130,12 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register get_entity tool: %w", err)
 	}
 
+	if err := server.RegisterTool("get_entity_versions",
+		"List the versions of a stored result or analysis, newest first. Re-running a query with unchanged output reuses the latest version; changed output becomes a new version that supersedes the previous one. Use this to fetch the latest version deterministically from any older entity_id.",
+		s.getEntityVersions); err != nil {
+		return fmt.Errorf("failed to register get_entity_versions tool: %w", err)
+	}
+
 	if err := server.RegisterTool("get_relations",
 		"Get all relations for a specific entity. Use this to understand how an entity is connected to others in the knowledge graph.",
 		s.getRelations); err != nil {
@@ -664,11 +1149,47 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 	}
 
 	if err := server.RegisterTool("delete_entity",
-		"Delete an entity and all its relations and observations. Use with caution as this permanently removes all stored information about the entity.",
+		"Delete an entity and all its relations and observations. The first call reports what would be removed and returns a confirmation_token; call again with the token to delete. Deleted entities stay in the trash, where restore_entity can bring them back until the retention period ends.",
 		s.deleteEntity); err != nil {
 		return fmt.Errorf("failed to register delete_entity tool: %w", err)
 	}
 
+	if err := server.RegisterTool("restore_entity",
+		"Restore an entity from the trash, together with its observations and the relations whose other end still exists. Use list_trash to find deleted entities.",
+		s.restoreEntity); err != nil {
+		return fmt.Errorf("failed to register restore_entity tool: %w", err)
+	}
+
+	if err := server.RegisterTool("pin_entity",
+		"Pin an entity such as a baseline inventory or golden config so it is never garbage collected: delete_entity and cleanup_workspace refuse it, cleanup_storage keeps its analysis database and bloom index, and a pinned nqe_result keeps its cached result past the cache TTL and eviction. get_memory_stats lists pinned entities.",
+		s.pinEntity); err != nil {
+		return fmt.Errorf("failed to register pin_entity tool: %w", err)
+	}
+
+	if err := server.RegisterTool("unpin_entity",
+		"Release a pinned entity so normal deletion, cleanup and cache eviction apply to it again.",
+		s.unpinEntity); err != nil {
+		return fmt.Errorf("failed to register unpin_entity tool: %w", err)
+	}
+
+	if err := server.RegisterTool("find_duplicate_entities",
+		"Find memory entities of the same type that name the same thing, such as core-rtr-01 and CORE-RTR-01: names equal ignoring case and separators, or similar names with the same numbers. Each group suggests the entity to keep and the merge_entities call.",
+		s.findDuplicateEntities); err != nil {
+		return fmt.Errorf("failed to register find_duplicate_entities tool: %w", err)
+	}
+
+	if err := server.RegisterTool("merge_entities",
+		"Merge duplicate entities of one network into one. Their observations and relations move to the target, metadata the target lacks is copied, and the duplicates go to the trash; their IDs and names keep resolving to the target and a merge observation records the provenance. The first call reports what would be merged and returns a confirmation_token; call again with the token to merge. restore_entity on a duplicate undoes its merge until the retention period ends.",
+		s.mergeEntities); err != nil {
+		return fmt.Errorf("failed to register merge_entities tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_trash",
+		"List deleted memory entities that can still be restored, with their size and when they are permanently deleted.",
+		s.listTrash); err != nil {
+		return fmt.Errorf("failed to register list_trash tool: %w", err)
+	}
+
 	if err := server.RegisterTool("delete_relation",
 		"Delete a specific relation between entities. Use this to remove connections that are no longer relevant.",
 		s.deleteRelation); err != nil {
@@ -701,13 +1222,224 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register list_instance_ids tool: %w", err)
 	}
 
+	if err := server.RegisterTool("start_session_transcript",
+		"Start recording an investigation transcript: every following tool call is stored in memory with its arguments and a trimmed result, as a chain of steps linked to a session entity. Pass session_id to resume a stopped session. Use get_session_transcript to review it or attach it to a ticket.",
+		s.startSessionTranscript); err != nil {
+		return fmt.Errorf("failed to register start_session_transcript tool: %w", err)
+	}
+
+	if err := server.RegisterTool("stop_session_transcript",
+		"Stop recording the investigation transcript started with start_session_transcript. The recorded steps stay in memory and the session can be resumed later.",
+		s.stopSessionTranscript); err != nil {
+		return fmt.Errorf("failed to register stop_session_transcript tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_session_transcript",
+		"Show an investigation transcript: the tool calls of a session in order with their arguments, duration and trimmed results. Defaults to the session being recorded, or the most recent one. Use format=json to attach it to a ticket.",
+		s.getSessionTranscript); err != nil {
+		return fmt.Errorf("failed to register get_session_transcript tool: %w", err)
+	}
+
+	if err := server.RegisterTool("create_workspace",
+		"Create a workspace for an investigation or project, such as a data center migration, or reopen an existing one by name. While it is open, every NQE result, report, note and other artifact stored in memory is linked to it, so long-running work does not get lost in the flat memory store. Devices, networks and other shared reference entities are never linked.",
+		s.createWorkspace); err != nil {
+		return fmt.Errorf("failed to register create_workspace tool: %w", err)
+	}
+
+	if err := server.RegisterTool("close_workspace",
+		"Close the open workspace so new artifacts are no longer linked to it. Its artifacts stay in memory; create_workspace with the same name reopens it.",
+		s.closeWorkspace); err != nil {
+		return fmt.Errorf("failed to register close_workspace tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_workspace_contents",
+		"List the artifacts of a workspace grouped by type, with the report files saved while it was open. Defaults to the open workspace; lists every workspace when none is open.",
+		s.listWorkspaceContents); err != nil {
+		return fmt.Errorf("failed to register list_workspace_contents tool: %w", err)
+	}
+
+	if err := server.RegisterTool("export_workspace",
+		"Export a workspace as one JSON document: its artifacts, the relations between them and its report files. Set include_observations to add stored observations such as result chunks.",
+		s.exportWorkspace); err != nil {
+		return fmt.Errorf("failed to register export_workspace tool: %w", err)
+	}
+
+	if err := server.RegisterTool("cleanup_workspace",
+		"Delete the artifacts of a workspace when the project is done. Artifacts another workspace also links are kept. Optionally delete its report files and the workspace itself. The first call reports what would be removed and returns a confirmation_token; call again with the token to delete. Artifacts go to the trash, where restore_entity can bring them back.",
+		s.cleanupWorkspace); err != nil {
+		return fmt.Errorf("failed to register cleanup_workspace tool: %w", err)
+	}
+
 	// Tool handler for get_nqe_result_chunks
 	if err := server.RegisterTool("get_nqe_result_chunks",
-		"Retrieve chunked NQE query results from the memory system. Provide either entity_id or (query_id, network_id, snapshot_id). Optionally, specify chunk_index to fetch a single chunk.",
+		"Retrieve chunked NQE query results from the memory system. Provide either entity_id or (query_id, network_id, snapshot_id). Optionally, specify chunk_index to fetch a single chunk, or start_row/end_row to fetch a row range (e.g. rows 1000-1500) across chunks. Use filters (column=value or contains) and columns to pull just the relevant rows and fields of a large result, and group_by to return counts with a few examples per group instead of every row.",
 		s.getNQEResultChunks); err != nil {
 		return fmt.Errorf("failed to register get_nqe_result_chunks tool: %w", err)
 	}
 
+	if err := server.RegisterTool("refresh_device_cache",
+		"Refresh the cached device inventory used by device name resolution, prefix discovery and cloud device detection. Use after devices are added or a new snapshot is processed.",
+		s.refreshDeviceCache); err != nil {
+		return fmt.Errorf("failed to register refresh_device_cache tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_device_aliases",
+		"List recorded device renames (former name → current name). Renames are detected automatically by comparing device inventories between snapshots; former names keep working in device resolution.",
+		s.listDeviceAliases); err != nil {
+		return fmt.Errorf("failed to register list_device_aliases tool: %w", err)
+	}
+
+	if err := server.RegisterTool("add_device_alias",
+		"Record that a device was renamed (old_name → current_name) so references to the former name keep resolving. Leave current_name empty to remove an alias.",
+		s.addDeviceAlias); err != nil {
+		return fmt.Errorf("failed to register add_device_alias tool: %w", err)
+	}
+
+	if err := server.RegisterTool("detect_device_renames",
+		"Compare the device inventories of two snapshots (the previous and latest by default) and record devices that were renamed, matched by serial number, management IP or interface addresses.",
+		s.detectDeviceRenamesTool); err != nil {
+		return fmt.Errorf("failed to register detect_device_renames tool: %w", err)
+	}
+
+	if err := server.RegisterTool("suggest_site_pairs",
+		"Suggest site pairs for multi-site planning: computes geographic distance between locations (lat/lng) and measures path hop counts between representative devices to recommend redundancy partners and long-haul paths to validate.",
+		s.suggestSitePairs); err != nil {
+		return fmt.Errorf("failed to register suggest_site_pairs tool: %w", err)
+	}
+
+	if err := server.RegisterTool("classify_devices",
+		"Classify network devices as physical, cloud or virtual from their inventory type, vendor and platform. Cloud and virtual devices cannot be assigned to physical locations.",
+		s.classifyDevices); err != nil {
+		return fmt.Errorf("failed to register classify_devices tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_support_matrix",
+		"Report which vendor-dependent tools (config section parsing, VLAN inventory, EOL forecasts, path analysis) are fully supported, partially supported or unsupported on each platform present in the network, with the device count per platform and notes on the limitations.",
+		s.getSupportMatrix); err != nil {
+		return fmt.Errorf("failed to register get_support_matrix tool: %w", err)
+	}
+
+	if err := server.RegisterTool("set_device_tag_rule",
+		"Define the rule of a device tag over inventory attributes, e.g. tag=datacenter-core condition=\"platform contains 'nxos' AND name matches 'core'\". Replaces the tag's previous rule. Run apply_device_tags to tag devices.",
+		s.setDeviceTagRule); err != nil {
+		return fmt.Errorf("failed to register set_device_tag_rule tool: %w", err)
+	}
+
+	if err := server.RegisterTool("remove_device_tag_rule",
+		"Remove a device tag together with its rule and the devices tagged with it.",
+		s.removeDeviceTagRule); err != nil {
+		return fmt.Errorf("failed to register remove_device_tag_rule tool: %w", err)
+	}
+
+	if err := server.RegisterTool("apply_device_tags",
+		"Evaluate the device tag rules over a network's inventory and store the tags in memory as device → tag relations, untagging devices that no longer match. Tags then filter list_devices (tag), analyze_network_prefixes (from_tags/to_tags) and search_paths_bulk sources (from_tag). Use dry_run to preview.",
+		s.applyDeviceTags); err != nil {
+		return fmt.Errorf("failed to register apply_device_tags tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_device_tags",
+		"List the device tag rules and the devices of a network carrying each tag.",
+		s.listDeviceTags); err != nil {
+		return fmt.Errorf("failed to register list_device_tags tool: %w", err)
+	}
+
+	if err := server.RegisterTool("annotate_prefix",
+		"Document the ownership of a prefix of a network (team, purpose, environment, notes) in the knowledge graph. More specific prefixes and addresses inherit the annotation; it appears in which_devices_in_prefix and analyze_network_prefixes. Fields left empty keep their previous value.",
+		s.annotatePrefixTool); err != nil {
+		return fmt.Errorf("failed to register annotate_prefix tool: %w", err)
+	}
+
+	if err := server.RegisterTool("import_prefix_annotations",
+		"Import the prefix ownership of a network in bulk from CSV with a header row: prefix and any of team, purpose, environment and notes. Invalid rows are reported with their line number and skipped.",
+		s.importPrefixAnnotations); err != nil {
+		return fmt.Errorf("failed to register import_prefix_annotations tool: %w", err)
+	}
+
+	if err := server.RegisterTool("prefix_documentation_coverage",
+		"Report which subnets configured on a network's interfaces have no ownership annotation, with per-team counts. format=csv returns the undocumented subnets as an import_prefix_annotations template.",
+		s.prefixDocumentationCoverage); err != nil {
+		return fmt.Errorf("failed to register prefix_documentation_coverage tool: %w", err)
+	}
+
+	if err := server.RegisterTool("which_devices_in_prefix",
+		"Find the devices and interfaces addressed inside an IP prefix (e.g. 10.20.0.0/16) using a persisted interface/prefix index. Any prefix length is supported; the index is rebuilt automatically when stale.",
+		s.whichDevicesInPrefix); err != nil {
+		return fmt.Errorf("failed to register which_devices_in_prefix tool: %w", err)
+	}
+
+	if err := server.RegisterTool("analyze_summarization",
+		"Find route summarization opportunities: contiguous subnets of a site that are advertised separately and could be replaced by one summary at the site boundary. No summary covers another site's subnets. Estimates the routing table reduction on every device from its current routes.",
+		s.analyzeSummarization); err != nil {
+		return fmt.Errorf("failed to register analyze_summarization tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_redaction_stats",
+		"Report output redaction activity: how many SNMP communities, credentials, hashes, public IPs and custom patterns were masked or allowlisted since startup. Redaction is enabled with FORWARD_REDACTION=on.",
+		s.getRedactionStats); err != nil {
+		return fmt.Errorf("failed to register get_redaction_stats tool: %w", err)
+	}
+
+	if err := server.RegisterTool("client_diagnostics",
+		"Report Forward API client connection behavior: HTTP/2 and keep-alive settings, connection pool reuse, TLS handshakes and transfer sizes. Optionally issue probe requests to measure latency.",
+		s.clientDiagnostics); err != nil {
+		return fmt.Errorf("failed to register client_diagnostics tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_last_api_errors",
+		"Summarize recent failed Forward API requests by endpoint and status, with sanitized request and response snippets. Secrets are redacted. Set FORWARD_API_DEBUG=errors or full to also log exchanges to a rotating file.",
+		s.getLastAPIErrors); err != nil {
+		return fmt.Errorf("failed to register get_last_api_errors tool: %w", err)
+	}
+
+	if err := server.RegisterTool("lookup_error",
+		"Explain an error code from a tool error (e.g. FWD-NQE-001): what it means and the steps to fix it. Tool errors with a code end with a one-line hint; call without a code to list every code.",
+		s.lookupError); err != nil {
+		return fmt.Errorf("failed to register lookup_error tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_feature_flags",
+		"List feature flags: which experimental tools are enabled and which tools are disabled by configuration (FORWARD_ENABLED_FEATURES and FORWARD_DISABLED_FEATURES).",
+		s.listFeatureFlags); err != nil {
+		return fmt.Errorf("failed to register list_feature_flags tool: %w", err)
+	}
+
+	if err := server.RegisterTool("run_diagnostics",
+		"Self-test the server setup: configuration, Forward credentials, API version compatibility, NQE spec file and databases, write permissions and the embedding provider. Each failure or warning comes with remediation steps.",
+		s.runDiagnostics); err != nil {
+		return fmt.Errorf("failed to register run_diagnostics tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_storage_stats",
+		"Report disk usage of the data directory per component: memory database, NQE database, SQL analysis databases, bloom indexes, saved reports and the disk cache, with the cleanup each supports.",
+		s.getStorageStats); err != nil {
+		return fmt.Errorf("failed to register get_storage_stats tool: %w", err)
+	}
+
+	if err := server.RegisterTool("cleanup_storage",
+		"Reclaim disk space from one storage component: VACUUM the memory or NQE database, or delete analysis databases, bloom indexes, saved reports or backup archives, optionally only those older than a number of days. Use dry_run to preview.",
+		s.cleanupStorage); err != nil {
+		return fmt.Errorf("failed to register cleanup_storage tool: %w", err)
+	}
+
+	if err := server.RegisterTool("backup_state",
+		"Archive all server state (memory and NQE databases copied with the SQLite backup API, bloom indexes, query embeddings and saved reports) into a timestamped .tar.gz, for example to migrate the server to another host.",
+		s.backupState); err != nil {
+		return fmt.Errorf("failed to register backup_state tool: %w", err)
+	}
+
+	if err := server.RegisterTool("restore_state",
+		"Restore server state from a backup_state archive, replacing the memory and NQE databases, bloom indexes, embeddings and reports (or a selected subset). Requires confirm=true.",
+		s.restoreState); err != nil {
+		return fmt.Errorf("failed to register restore_state tool: %w", err)
+	}
+
+	// Continuation of large streamed responses
+	if err := server.RegisterTool("continue_response",
+		"Fetch the next part of a large response (reports, exports, analysis results). Tools that produce more output than fits in one response return a continuation token; pass it here until no token is returned.",
+		s.continueResponse); err != nil {
+		return fmt.Errorf("failed to register continue_response tool: %w", err)
+	}
+
 	// Add get_nqe_result_summary tool handler
 	if err := server.RegisterTool("get_nqe_result_summary",
 		"Get a summary of a stored NQE result (row count, columns, preview rows) by entity_id or (query_id, network_id, snapshot_id).",
@@ -715,13 +1447,74 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 		return fmt.Errorf("failed to register get_nqe_result_summary tool: %w", err)
 	}
 
+	if err := server.RegisterTool("summarize_result",
+		"Summarize a stored NQE result in plain language with the configured chat model, e.g. to answer 'what did that 80k-row query show?' later without reading the rows again. Only the row count, the columns and a bounded sample of rows spread across the result are sent, after redaction, and requests are rate limited. The summary is stored on the result; later calls with the same focus return it without a new request unless refresh is set. Needs FORWARD_SUMMARY_PROVIDER and OPENAI_API_KEY.",
+		s.summarizeResult); err != nil {
+		return fmt.Errorf("failed to register summarize_result tool: %w", err)
+	}
+
 	// Add analyze_nqe_result_sql tool handler
 	if err := server.RegisterTool("analyze_nqe_result_sql",
-		"Run a SQL query on a stored NQE result (by entity_id). Example: SELECT COUNT(*) FROM nqe_result;",
+		"Run a read-only SQL query on a stored NQE result (by entity_id), loaded as the nqe_result table. Repeated queries reuse a cached analysis database until the result changes. One SELECT/WITH statement per call; PRAGMA, ATTACH, writes and file/extension functions are blocked, and queries are subject to a timeout and memory limit. Example: SELECT COUNT(*) FROM nqe_result;",
 		s.analyzeNQEResultSQL); err != nil {
 		return fmt.Errorf("failed to register analyze_nqe_result_sql tool: %w", err)
 	}
 
+	if err := server.RegisterTool("export_to_duckdb",
+		"Export one or more stored NQE results into a DuckDB database file for offline analytics in notebooks. Each result becomes a table with typed columns (BIGINT, DOUBLE, BOOLEAN, VARCHAR or JSON) and indexes on lookup columns; the _export_entities table records the source query, network and snapshot of every table. Requires the duckdb CLI on PATH or FORWARD_DUCKDB_PATH.",
+		s.exportToDuckDB); err != nil {
+		return fmt.Errorf("failed to register export_to_duckdb tool: %w", err)
+	}
+
+	if err := server.RegisterTool("detect_result_anomalies",
+		"Detect anomalies in the newest stored result of a query compared with its earlier results (typically other snapshots). Numeric columns and row counts are checked with z-score/EWMA, categorical columns for new or missing values; anomalies are attached to the result entity. run_nqe_query_by_id with all_results runs this automatically.",
+		s.detectResultAnomaliesTool); err != nil {
+		return fmt.Errorf("failed to register detect_result_anomalies tool: %w", err)
+	}
+
+	if err := server.RegisterTool("list_schema_changes",
+		"List schema drift of NQE queries: columns added, removed or changing type between runs of the same query ID, e.g. after a library update. run_nqe_query_by_id records each query's column schema and flags drift in its response; use this to find saved SQL views and reports to fix.",
+		s.listSchemaChanges); err != nil {
+		return fmt.Errorf("failed to register list_schema_changes tool: %w", err)
+	}
+
+	if err := server.RegisterTool("collect_timeseries",
+		"Run a query (query_id or NQE source) across every processed snapshot in a time range and store its metrics per snapshot as a named series: row_count, the sum of sum_columns and row counts per group_by value. Snapshots already collected are skipped, so repeated calls extend the series.",
+		s.collectTimeSeries); err != nil {
+		return fmt.Errorf("failed to register collect_timeseries tool: %w", err)
+	}
+
+	if err := server.RegisterTool("query_timeseries",
+		"Read a stored time series for plots and trends (e.g. route, device or ACL rule counts over time): one row per snapshot with first/last/change/min/max per metric, as markdown or JSON. Without series_name, lists the series of the network.",
+		s.queryTimeSeries); err != nil {
+		return fmt.Errorf("failed to register query_timeseries tool: %w", err)
+	}
+
+	if err := server.RegisterTool("generate_chart_spec",
+		"Generate a Vega-Lite (default) or Chart.js spec for MCP clients that can render charts: a bar/pie breakdown of a stored result or its SQL output by x_column (row counts or summed y_columns), or a line chart of a stored time series.",
+		s.generateChartSpec); err != nil {
+		return fmt.Errorf("failed to register generate_chart_spec tool: %w", err)
+	}
+
+	if err := server.RegisterTool("diff_stored_results",
+		"Compare two stored NQE results (typically the same query on different snapshots) row by row. Rows are matched on key_columns and classified as added, removed or changed; the full diff is stored as a result_diff entity.",
+		s.diffStoredResults); err != nil {
+		return fmt.Errorf("failed to register diff_stored_results tool: %w", err)
+	}
+
+	// Add result sharing tool handlers
+	if err := server.RegisterTool("share_result",
+		"Share a stored entity (including the rows of a stored query result) or a saved report with teammates using this server. The content is exported to a file named by its SHA-256 and a stable reference is returned; sharing identical content again returns the same reference.",
+		s.shareResult); err != nil {
+		return fmt.Errorf("failed to register share_result tool: %w", err)
+	}
+
+	if err := server.RegisterTool("get_shared_result",
+		"Fetch a result shared with share_result by its reference (sha256:<digest>, or the first 12 digits). The content is verified against the digest, so everyone sees exactly the same result.",
+		s.getSharedResult); err != nil {
+		return fmt.Errorf("failed to register get_shared_result tool: %w", err)
+	}
+
 	// Add bloom search tool handlers
 	if err := server.RegisterTool("build_bloom_filter",
 		"Build a bloom filter from NQE query results for efficient large dataset searching",
@@ -745,7 +1538,9 @@ func (s *ForwardMCPService) RegisterTools(server *mcp.Server) error {
 }
 
 // RegisterPrompts registers workflow prompts with the MCP server
-func (s *ForwardMCPService) RegisterPrompts(server *mcp.Server) error {
+func (s *ForwardMCPService) RegisterPrompts(mcpServer *mcp.Server) error {
+	server := &toolServer{Server: mcpServer, service: s}
+
 	// Register NQE Query Discovery workflow as a prompt
 	if err := server.RegisterPrompt("nqe_discovery", "Interactive NQE query discovery workflow to help find and run network queries", func(args NQEDiscoveryArgs) (*mcp.PromptResponse, error) {
 		response, err := s.nqeQueryDiscoveryWorkflow(args)
@@ -821,6 +1616,48 @@ func (s *ForwardMCPService) RegisterPrompts(server *mcp.Server) error {
 		return fmt.Errorf("failed to register network_prefix_discovery_workflow prompt: %w", err)
 	}
 
+	// Register Security Posture Workflow as a prompt
+	if err := server.RegisterPrompt("security_posture_workflow", "Interactive security posture assessment: runs /L3/Security/ queries, verifies ACLs on critical flows, checks EOL exposure and compiles a scored posture summary", func(args SecurityPostureWorkflowArgs) (*mcp.PromptResponse, error) {
+		response, err := s.securityPostureWorkflow(args)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Content) > 0 {
+			return mcp.NewPromptResponse("Security Posture Workflow", mcp.NewPromptMessage(response.Content[0], mcp.RoleAssistant)), nil
+		}
+		return mcp.NewPromptResponse("Security Posture Workflow", mcp.NewPromptMessage(mcp.NewTextContent("Welcome to the Security Posture Workflow!"), mcp.RoleAssistant)), nil
+	}); err != nil {
+		return fmt.Errorf("failed to register security_posture_workflow prompt: %w", err)
+	}
+
+	// Register Site Turn-up Workflow as a prompt
+	if err := server.RegisterPrompt("site_turnup_workflow", "Interactive validation of a newly added site: devices collected, locations assigned, prefixes advertised and core reachability, ending in a go/no-go checklist", func(args SiteTurnupWorkflowArgs) (*mcp.PromptResponse, error) {
+		response, err := s.siteTurnupWorkflow(args)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Content) > 0 {
+			return mcp.NewPromptResponse("Site Turn-up Workflow", mcp.NewPromptMessage(response.Content[0], mcp.RoleAssistant)), nil
+		}
+		return mcp.NewPromptResponse("Site Turn-up Workflow", mcp.NewPromptMessage(mcp.NewTextContent("Welcome to the Site Turn-up Workflow!"), mcp.RoleAssistant)), nil
+	}); err != nil {
+		return fmt.Errorf("failed to register site_turnup_workflow prompt: %w", err)
+	}
+
+	// Register Device Onboarding Workflow as a prompt
+	if err := server.RegisterPrompt("onboard_devices_workflow", "Interactive onboarding of new devices: record them with planned locations, get collection source guidance, validate their collection in the next snapshot and assign them to locations", func(args OnboardDevicesWorkflowArgs) (*mcp.PromptResponse, error) {
+		response, err := s.onboardDevicesWorkflow(args)
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Content) > 0 {
+			return mcp.NewPromptResponse("Device Onboarding Workflow", mcp.NewPromptMessage(response.Content[0], mcp.RoleAssistant)), nil
+		}
+		return mcp.NewPromptResponse("Device Onboarding Workflow", mcp.NewPromptMessage(mcp.NewTextContent("Welcome to the Device Onboarding Workflow!"), mcp.RoleAssistant)), nil
+	}); err != nil {
+		return fmt.Errorf("failed to register onboard_devices_workflow prompt: %w", err)
+	}
+
 	s.logger.Info("MCP ready - Forward Networks tools registered")
 	return nil
 }
@@ -828,7 +1665,7 @@ func (s *ForwardMCPService) RegisterPrompts(server *mcp.Server) error {
 // RegisterResources registers contextual resources with the MCP server
 func (s *ForwardMCPService) RegisterResources(server *mcp.Server) error {
 	// Register network context as a resource
-	if err := server.RegisterResource("forward://network/context", "network_context", "Current network context including available networks and queries", "application/json", func() (*mcp.ResourceResponse, error) {
+	if err := server.RegisterResource("forward://network/context", "network_context", "Bounded summary of the network context: counts, defaults and the most relevant networks by recent use, with a pointer to the full network list", "application/json", func() (*mcp.ResourceResponse, error) {
 		context, err := s.getNetworkContext(NetworkContextArgs{})
 		if err != nil {
 			return nil, fmt.Errorf("failed to get network context: %w", err)
@@ -844,6 +1681,13 @@ func (s *ForwardMCPService) RegisterResources(server *mcp.Server) error {
 		return fmt.Errorf("failed to register network_context resource: %w", err)
 	}
 
+	// Register saved reports; reports saved later are registered as they are written
+	if s.reports != nil {
+		if err := s.reports.Attach(server); err != nil {
+			return fmt.Errorf("failed to register report resources: %w", err)
+		}
+	}
+
 	s.logger.Debug("Successfully registered MCP resources")
 	return nil
 }
@@ -851,25 +1695,59 @@ func (s *ForwardMCPService) RegisterResources(server *mcp.Server) error {
 // nqeQueryDiscoveryWorkflow implements the NQE query discovery workflow
 func (s *ForwardMCPService) nqeQueryDiscoveryWorkflow(args NQEDiscoveryArgs) (*mcp.ToolResponse, error) {
 	sessionID := fmt.Sprintf("session_%v", args.SessionID) // In practice, extract from context
+	step, err := s.resolveWorkflowStep(workflowNQEDiscovery, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
 	state := s.workflowManager.GetState(sessionID)
 
-	switch state.CurrentStep {
-	case "start":
-		return s.startQueryDiscovery(sessionID)
+	// Apply explicit answers to the workflow state
+	if answer, ok := state.Parameters["answer_"+step].(string); ok && args.Answer != "" {
+		switch step {
+		case "category_selected":
+			state.Parameters["directory"] = queryDirectoryFromAnswer(answer)
+		case "query_selected":
+			state.SelectedQuery = answer
+		case "parameters_collected":
+			values := parseWorkflowKeyValues(answer)
+			state.NetworkID = s.getNetworkID(values["network_id"])
+			state.SnapshotID = s.getSnapshotID(values["snapshot_id"])
+			state.Parameters["network_id"] = state.NetworkID
+			state.Parameters["snapshot_id"] = state.SnapshotID
+		}
+		s.workflowManager.SetState(sessionID, state)
+	}
+
+	var response *mcp.ToolResponse
+	switch step {
 	case "category_selected":
-		return s.listQueriesInCategory(sessionID, state.Parameters["directory"].(string))
+		directory, _ := state.Parameters["directory"].(string)
+		if directory == "" {
+			step = "start"
+			response, err = s.startQueryDiscovery(sessionID)
+		} else {
+			response, err = s.listQueriesInCategory(sessionID, directory)
+		}
 	case "query_selected":
-		return s.collectQueryParameters(sessionID)
+		response, err = s.collectQueryParameters(sessionID)
 	case "parameters_collected":
-		return s.executeSelectedQuery(sessionID)
+		if state.NetworkID == "" || state.SelectedQuery == "" {
+			return nil, fmt.Errorf("select a query (step query_selected) and provide network_id before executing")
+		}
+		response, err = s.executeSelectedQuery(sessionID)
 	default:
-		return s.startQueryDiscovery(sessionID)
+		step = "start"
+		response, err = s.startQueryDiscovery(sessionID)
 	}
+	if err != nil {
+		return nil, err
+	}
+	return s.finishWorkflowStep(workflowNQEDiscovery, sessionID, step, response), nil
 }
 
 // networkDiscoveryWorkflow implements the network discovery workflow
 func (s *ForwardMCPService) networkDiscoveryWorkflow(args NetworkDiscoveryArgs) (*mcp.ToolResponse, error) {
-	networks, err := s.forwardClient.GetNetworks()
+	networks, err := s.getNetworks()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get networks: %w", err)
 	}
@@ -886,20 +1764,27 @@ func (s *ForwardMCPService) networkDiscoveryWorkflow(args NetworkDiscoveryArgs)
 // largeNQEResultsWorkflow implements the large NQE results workflow
 func (s *ForwardMCPService) largeNQEResultsWorkflow(args LargeNQEResultsWorkflowArgs) (*mcp.ToolResponse, error) {
 	sessionID := fmt.Sprintf("session_%v", args.SessionID)
-	state := s.workflowManager.GetState(sessionID)
+	step, err := s.resolveWorkflowStep(workflowLargeNQEResults, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
 
-	switch state.CurrentStep {
-	case "start":
-		return s.startLargeResultsWorkflow(sessionID)
+	var response *mcp.ToolResponse
+	switch step {
 	case "explain_process":
-		return s.explainLargeResultsProcess(sessionID)
+		response, err = s.explainLargeResultsProcess(sessionID)
 	case "show_example":
-		return s.showLargeResultsExample(sessionID)
+		response, err = s.showLargeResultsExample(sessionID)
 	case "demonstrate_sql":
-		return s.demonstrateSQLAnalysis(sessionID)
+		response, err = s.demonstrateSQLAnalysis(sessionID)
 	default:
-		return s.startLargeResultsWorkflow(sessionID)
+		step = "start"
+		response, err = s.startLargeResultsWorkflow(sessionID)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return s.finishWorkflowStep(workflowLargeNQEResults, sessionID, step, response), nil
 }
 
 // startLargeResultsWorkflow begins the large NQE results workflow
@@ -960,7 +1845,7 @@ When you run an NQE query with "all_results: true" or when results exceed size l
 
 **Step 3: Analysis Tools Available**
 - **get_nqe_result_summary**: View metadata and structure of stored results
-- **get_nqe_result_chunks**: Retrieve raw data chunks (all or specific chunk)
+- **get_nqe_result_chunks**: Retrieve raw data chunks (all, a specific chunk, or a filtered/projected row range)
 - **analyze_nqe_result_sql**: Run SQL queries on the complete dataset
 
 **Step 4: SQL Analysis Workflow**
@@ -1085,27 +1970,10 @@ Ready to try this workflow with your own data? Start by running a query with "al
 	return mcp.NewToolResponse(mcp.NewTextContent(promptText)), nil
 }
 
-// getNetworkContext provides contextual network information as a resource
-func (s *ForwardMCPService) getNetworkContext(args NetworkContextArgs) (interface{}, error) {
-	networks, err := s.forwardClient.GetNetworks()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get network context: %w", err)
-	}
-
-	context := map[string]interface{}{
-		"networks":          networks,
-		"timestamp":         "current",
-		"available_queries": []string{"/L3/Basic/", "/L3/Advanced/", "/L3/Security/"},
-	}
-
-	contextJSON, _ := json.MarshalIndent(context, "", "  ")
-	return string(contextJSON), nil
-}
-
 // startQueryDiscovery begins the NQE query discovery workflow
 func (s *ForwardMCPService) startQueryDiscovery(sessionID string) (*mcp.ToolResponse, error) {
 	state := &WorkflowState{
-		CurrentStep: "category_selection",
+		CurrentStep: "category_selected",
 		Parameters:  make(map[string]interface{}),
 	}
 	s.workflowManager.SetState(sessionID, state)
@@ -1122,7 +1990,7 @@ func (s *ForwardMCPService) listQueriesInCategory(sessionID, directory string) (
 	}
 
 	state := s.workflowManager.GetState(sessionID)
-	state.CurrentStep = "query_selection"
+	state.CurrentStep = "query_selected"
 	state.Parameters["directory"] = directory
 	s.workflowManager.SetState(sessionID, state)
 
@@ -1150,7 +2018,7 @@ func (s *ForwardMCPService) collectQueryParameters(sessionID string) (*mcp.ToolR
 	}
 
 	// All parameters collected, ready to execute
-	state.CurrentStep = "ready_to_execute"
+	state.CurrentStep = "parameters_collected"
 	s.workflowManager.SetState(sessionID, state)
 
 	return mcp.NewToolResponse(mcp.NewTextContent("All parameters collected! Ready to execute query. Proceed?")), nil
@@ -1183,7 +2051,7 @@ func (s *ForwardMCPService) listNetworks(args ListNetworksArgs) (*mcp.ToolRespon
 	s.logToolCall("list_networks", args, nil)
 
 	// Get all networks from API
-	allNetworks, err := s.forwardClient.GetNetworks()
+	allNetworks, err := s.getNetworks()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list networks: %w", err)
 	}
@@ -1204,6 +2072,7 @@ func (s *ForwardMCPService) listNetworks(args ListNetworksArgs) (*mcp.ToolRespon
 	var networks []forward.Network
 	var totalCount int
 	var hasMore bool
+	var entityID string
 
 	if args.AllResults {
 		// Store all networks in memory system for large datasets
@@ -1219,6 +2088,7 @@ func (s *ForwardMCPService) listNetworks(args ListNetworksArgs) (*mcp.ToolRespon
 				"timestamp":   time.Now().Unix(),
 			})
 			if err == nil {
+				entityID = entity.ID
 				// Store the networks data
 				networksJSON, _ := json.Marshal(networks)
 				s.memorySystem.AddObservation(entity.ID, string(networksJSON), "data", map[string]interface{}{
@@ -1258,7 +2128,7 @@ func (s *ForwardMCPService) listNetworks(args ListNetworksArgs) (*mcp.ToolRespon
 	responseText.WriteString(":\n")
 
 	if len(networks) > 0 {
-		result := MarshalCompactJSONString(networks)
+		result := MarshalCompactJSONString(s.displayNetworks(networks))
 		responseText.WriteString(result)
 	} else {
 		responseText.WriteString("No networks found.")
@@ -1268,7 +2138,7 @@ func (s *ForwardMCPService) listNetworks(args ListNetworksArgs) (*mcp.ToolRespon
 		responseText.WriteString(fmt.Sprintf("\n\n💾 Stored %d networks in memory system for future reference.", totalCount))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(responseText.String())), nil
+	return paginatedResponse(responseText.String(), listPagination(totalCount, offset, limit, len(networks), args.AllResults, entityID)), nil
 }
 
 func (s *ForwardMCPService) createNetwork(args CreateNetworkArgs) (*mcp.ToolResponse, error) {
@@ -1318,7 +2188,9 @@ func (s *ForwardMCPService) updateNetwork(args UpdateNetworkArgs) (*mcp.ToolResp
 type SearchPathsBulkArgs struct {
 	NetworkID               string                `json:"network_id" jsonschema:"required,description=Network ID to search in"`
 	SnapshotID              string                `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
-	Queries                 []PathSearchQueryArgs `json:"queries" jsonschema:"required,description=Array of path search queries to execute"`
+	Queries                 []PathSearchQueryArgs `json:"queries,omitempty" jsonschema:"description=Array of path search queries to execute (or use csv / csv_file)"`
+	CSV                     string                `json:"csv,omitempty" jsonschema:"description=Flows as CSV with a header row: src (IP or device name) and dst plus optionally proto (tcp/udp/icmp or number) and port. Results are reported per row"`
+	CSVFile                 string                `json:"csv_file,omitempty" jsonschema:"description=Name of a CSV file in the server import directory (<data dir>/imports) with the same columns as csv"`
 	Intent                  string                `json:"intent,omitempty" jsonschema:"description=Search intent (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)"`
 	MaxCandidates           int                   `json:"max_candidates,omitempty" jsonschema:"description=Maximum number of candidates to consider"`
 	MaxResults              int                   `json:"max_results,omitempty" jsonschema:"description=Maximum number of results to return"`
@@ -1326,21 +2198,35 @@ type SearchPathsBulkArgs struct {
 	MaxSeconds              int                   `json:"max_seconds,omitempty" jsonschema:"description=Maximum seconds per query"`
 	MaxOverallSeconds       int                   `json:"max_overall_seconds,omitempty" jsonschema:"description=Maximum overall seconds for all queries"`
 	IncludeNetworkFunctions bool                  `json:"include_network_functions,omitempty" jsonschema:"description=Include network functions in results"`
+	AnalyzeECMP             bool                  `json:"analyze_ecmp,omitempty" jsonschema:"description=Request more candidates and report ECMP fan-out per hop, flagging flows that collapse to a single path"`
+	ExpandUnderlay          bool                  `json:"expand_underlay,omitempty" jsonschema:"description=Trace the underlay path of tunnels (SD-WAN/GRE/IPsec/VXLAN) that appear as opaque hops with a follow-up search between the tunnel endpoints"`
+	VerifyWithProbes        bool                  `json:"verify_with_probes,omitempty" jsonschema:"description=Send real ICMP/TCP probes to each destination from the configured probe host or probe API and flag queries where the observed reachability contradicts the model"`
 }
 
 // PathSearchQueryArgs represents a single path search query in bulk request
 type PathSearchQueryArgs struct {
 	From    string `json:"from,omitempty" jsonschema:"description=Source device name"`
+	FromTag string `json:"from_tag,omitempty" jsonschema:"description=Device tag (see apply_device_tags); the query runs once from each tagged device"`
 	SrcIP   string `json:"src_ip,omitempty" jsonschema:"description=Source IP address or subnet"`
-	DstIP   string `json:"dst_ip" jsonschema:"required,description=Destination IP address or subnet"`
-	IPProto *int   `json:"ip_proto,omitempty" jsonschema:"description=IP protocol number"`
+	DstIP   string `json:"dst_ip" jsonschema:"required,description=Destination IP address or subnet (a device name resolves to its address)"`
+	IPProto *int   `json:"ip_proto,omitempty" jsonschema:"description=IP protocol number or name such as tcp"`
 	SrcPort string `json:"src_port,omitempty" jsonschema:"description=Source port"`
-	DstPort string `json:"dst_port,omitempty" jsonschema:"description=Destination port"`
+	DstPort string `json:"dst_port,omitempty" jsonschema:"description=Destination port or range; service names such as https and forms such as 443/tcp are accepted"`
 }
 
-func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.ToolResponse, error) {
-	s.logToolCall("search_paths_bulk", args, nil)
+// pathSearchExecution is the outcome of a bulk path search before formatting
+type pathSearchExecution struct {
+	networkID     string
+	snapshotID    string                // Snapshot sent to the API; empty means the latest processed snapshot
+	queries       []PathSearchQueryArgs // Queries as searched, after canonicalization
+	responses     []forward.PathSearchBulkResponse
+	cacheStatus   string
+	canonicalized []string // Inputs canonicalization changed, by query
+}
 
+// executePathSearchBulk validates the queries, runs them through the path cache and the API and
+// records them in the memory system. It is shared by the MCP tool and the programmatic API.
+func (s *ForwardMCPService) executePathSearchBulk(args SearchPathsBulkArgs) (*pathSearchExecution, error) {
 	// Use defaults if not specified
 	networkID := s.getNetworkID(args.NetworkID)
 	snapshotID := s.getSnapshotID(args.SnapshotID)
@@ -1349,7 +2235,7 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 	// We only fetch it if explicitly requested
 	if snapshotID == "latest" {
 		s.logger.Info("searchPathsBulk - Latest snapshot requested, fetching for network %s", networkID)
-		snapshot, err := s.forwardClient.GetLatestSnapshot(networkID)
+		snapshot, err := s.latestSnapshot(networkID)
 		if err != nil {
 			s.logger.Error("Failed to fetch latest snapshot for network %s: %v", networkID, err)
 			return nil, fmt.Errorf("failed to get latest snapshot for network %s: %w", networkID, err)
@@ -1368,6 +2254,14 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 		return nil, fmt.Errorf("at least one query must be provided in bulk path search")
 	}
 
+	// Bring inputs agents phrase differently (device name casing, masks, ports with protocols)
+	// into canonical form so validation judges what was meant
+	var canonicalized []string
+	args.Queries, canonicalized = s.canonicalizePathQueries(networkID, snapshotID, args.Queries)
+	for _, change := range canonicalized {
+		s.logger.Debug("Canonicalized path search input: %s", change)
+	}
+
 	// Convert queries to forward API format
 	var bulkQueries []forward.PathSearchParams
 	for i, query := range args.Queries {
@@ -1388,22 +2282,23 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 			return nil, fmt.Errorf("query %d: 'src_ip' is required when 'from' is not specified", i+1)
 		}
 
-		// Validate dst_ip - must be a valid IP address or CIDR (no device name resolution)
-		dstIP := query.DstIP
-		s.logger.Debug("Processing dst_ip: %s for query %d", dstIP, i+1)
+		// Validate dst_ip - device names were resolved above, so it must now be an IPv4/IPv6 address or CIDR
+		s.logger.Debug("Processing dst_ip: %s for query %d", query.DstIP, i+1)
+		dstIP, dstFamily, err := normalizeIPOrCIDR(query.DstIP)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: dst_ip '%s' must be a valid IP address, CIDR or device name in the inventory: %w", i+1, query.DstIP, err)
+		}
 
-		// Check if it's a valid IP address
-		if net.ParseIP(dstIP) != nil {
-			s.logger.Debug("dst_ip '%s' is a valid IP address", dstIP)
-		} else if strings.Contains(dstIP, "/") {
-			// Check if it's a valid CIDR
-			if _, _, err := net.ParseCIDR(dstIP); err != nil {
-				return nil, fmt.Errorf("query %d: dst_ip '%s' is not a valid IP address or CIDR: %w", i+1, dstIP, err)
+		// Validate src_ip when provided and make sure both ends use the same address family
+		if srcIP != "" {
+			normalizedSrc, srcFamily, err := normalizeIPOrCIDR(srcIP)
+			if err != nil {
+				return nil, fmt.Errorf("query %d: src_ip '%s' is not a valid IP address or CIDR: %w", i+1, srcIP, err)
 			}
-			s.logger.Debug("dst_ip '%s' is a valid CIDR", dstIP)
-		} else {
-			// Not an IP or CIDR - reject device names
-			return nil, fmt.Errorf("query %d: dst_ip '%s' must be a valid IP address or CIDR (device names are not supported)", i+1, dstIP)
+			if srcFamily != dstFamily {
+				return nil, fmt.Errorf("query %d: src_ip '%s' (IPv%d) and dst_ip '%s' (IPv%d) must use the same address family", i+1, srcIP, srcFamily, query.DstIP, dstFamily)
+			}
+			srcIP = normalizedSrc
 		}
 
 		params := forward.PathSearchParams{
@@ -1435,6 +2330,9 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 		MaxOverallSeconds:       args.MaxOverallSeconds,
 		IncludeNetworkFunctions: args.IncludeNetworkFunctions,
 	}
+	if args.AnalyzeECMP {
+		applyECMPRequestDefaults(bulkRequest)
+	}
 
 	// Execute bulk path search
 	// Pass empty string if no snapshotId is needed (API will use latest processed snapshot)
@@ -1442,19 +2340,43 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 	if snapshotID != "" && snapshotID != "latest" {
 		apiSnapshotID = snapshotID
 	}
-	responses, err := s.forwardClient.SearchPathsBulk(networkID, bulkRequest, apiSnapshotID)
-	if err != nil {
-		s.logger.Error("Bulk path search failed: %v", err)
-		return nil, fmt.Errorf("failed to execute bulk path search: %w", err)
+	// Identical path queries are common within a session, so check the exact-match cache first
+	var responses []forward.PathSearchBulkResponse
+	cacheStatus := "disabled"
+	cacheKey := ""
+	cacheHit := false
+	if s.pathCache != nil {
+		cacheKey = PathSearchCacheKey(networkID, apiSnapshotID, bulkRequest)
+		if entry, found := s.pathCache.Get(cacheKey); found {
+			responses = entry.Responses
+			cacheHit = true
+			cacheStatus = fmt.Sprintf("HIT (cached %s)", s.timeFormatter.Since(entry.CreatedAt))
+			s.logger.Debug("Bulk path search cache hit for network %s, snapshot %s", networkID, apiSnapshotID)
+		} else {
+			cacheStatus = "MISS"
+		}
 	}
 
-	s.logger.Debug("Bulk path search API returned %d responses", len(responses))
-	if len(responses) > 0 {
-		s.logger.Debug("First response structure: %+v", responses[0])
+	if !cacheHit {
+		var err error
+		responses, err = s.forwardClient.SearchPathsBulk(networkID, bulkRequest, apiSnapshotID)
+		if err != nil {
+			s.logger.Error("Bulk path search failed: %v", err)
+			return nil, fmt.Errorf("failed to execute bulk path search: %w", err)
+		}
+
+		s.logger.Debug("Bulk path search API returned %d responses", len(responses))
+		if len(responses) > 0 {
+			s.logger.Debug("First response structure: %+v", responses[0])
+		}
+
+		if s.pathCache != nil {
+			s.pathCache.Put(cacheKey, networkID, apiSnapshotID, responses)
+		}
 	}
 
-	// Track bulk path search in memory system
-	if s.apiTracker != nil {
+	// Track bulk path search in memory system (cached results were tracked when first fetched)
+	if s.apiTracker != nil && !cacheHit {
 		for i, response := range responses {
 			if i < len(args.Queries) {
 				query := args.Queries[i]
@@ -1481,6 +2403,51 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 		}
 	}
 
+	return &pathSearchExecution{networkID: networkID, snapshotID: apiSnapshotID, queries: args.Queries, responses: responses,
+		cacheStatus: cacheStatus, canonicalized: canonicalized}, nil
+}
+
+func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("search_paths_bulk", args, nil)
+
+	if args.VerifyWithProbes && s.prober == nil {
+		return nil, fmt.Errorf("verify_with_probes needs a probe host or probe API: set FORWARD_PROBE_HOST or FORWARD_PROBE_URL")
+	}
+
+	// Flows from a spreadsheet replace the queries; each keeps its row number for the summary
+	var csvRows []pathCSVQuery
+	var csvProblems []string
+	if args.CSV != "" || args.CSVFile != "" {
+		if len(args.Queries) > 0 {
+			return nil, fmt.Errorf("use either queries or csv/csv_file, not both")
+		}
+		data, err := readPathSearchCSV(args.CSV, args.CSVFile)
+		if err != nil {
+			return nil, err
+		}
+		if csvRows, csvProblems, err = parsePathSearchCSV(data); err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if len(csvRows) == 0 {
+			return nil, fmt.Errorf("csv has no valid flows:\n- %s", strings.Join(csvProblems, "\n- "))
+		}
+		for _, row := range csvRows {
+			args.Queries = append(args.Queries, row.Query)
+		}
+	}
+
+	queries, err := s.expandPathSourceTags(s.getNetworkID(args.NetworkID), args.Queries)
+	if err != nil {
+		return nil, err
+	}
+	args.Queries = queries
+	execution, err := s.executePathSearchBulk(args)
+	if err != nil {
+		return nil, err
+	}
+	networkID, apiSnapshotID, responses, cacheStatus := execution.networkID, execution.snapshotID, execution.responses, execution.cacheStatus
+	args.Queries = execution.queries
+
 	// Build summary
 	totalPaths := 0
 	successfulQueries := 0
@@ -1497,6 +2464,8 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 			totalPaths += pathCount
 			successfulQueries++
 			s.logger.Debug("Response %d: Found %d paths", i+1, pathCount)
+		} else if i < len(csvRows) {
+			errors = append(errors, fmt.Sprintf("Row %d: No paths found", csvRows[i].Row))
 		} else {
 			errors = append(errors, fmt.Sprintf("Query %d: No paths found", i+1))
 			s.logger.Debug("Response %d: No paths found", i+1)
@@ -1512,6 +2481,17 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 		}
 	}
 
+	if csvRows != nil {
+		debugInfo += formatPathCSVResults(csvRows, csvProblems, responses)
+	}
+
+	if len(execution.canonicalized) > 0 {
+		debugInfo += "\nℹ️  Normalized inputs:\n"
+		for _, change := range execution.canonicalized {
+			debugInfo += fmt.Sprintf("  - %s\n", change)
+		}
+	}
+
 	// Check for missing "from" property usage
 	missingFromCount := 0
 	for _, query := range args.Queries {
@@ -1523,10 +2503,31 @@ func (s *ForwardMCPService) searchPathsBulk(args SearchPathsBulkArgs) (*mcp.Tool
 		debugInfo += fmt.Sprintf("\n💡 Tip: %d queries don't use the 'from' property. Consider adding it for more accurate results.\n", missingFromCount)
 	}
 
+	if args.AnalyzeECMP {
+		var reports []ECMPQueryReport
+		for i, response := range responses {
+			reports = append(reports, analyzeECMP(i+1, response))
+		}
+		debugInfo += formatECMPReports(reports)
+	}
+
+	// Tunnels show up as opaque hops, so call out which segments ride an overlay
+	if overlays := analyzeOverlay(responses); len(overlays) > 0 {
+		if args.ExpandUnderlay {
+			s.expandOverlayUnderlay(networkID, apiSnapshotID, overlays)
+		}
+		debugInfo += formatOverlayReports(overlays)
+	}
+
+	// Compare the modeled outcome with what the destinations actually answer
+	if args.VerifyWithProbes {
+		debugInfo += formatProbeVerifications(s.prober.Name(), s.verifyWithProbes(args.Queries, responses))
+	}
+
 	result := MarshalCompactJSONString(responses)
 
-	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Bulk path search completed. %d/%d queries successful, found %d total paths:%s\n%s",
-		successfulQueries, len(args.Queries), totalPaths, debugInfo, result))), nil
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Bulk path search completed. %d/%d queries successful, found %d total paths:%s\nPath cache: %s\n%s",
+		successfulQueries, len(args.Queries), totalPaths, debugInfo, cacheStatus, result))), nil
 }
 
 // Helper function to convert service NQEQueryOptions to forward NQEQueryOptions
@@ -1570,6 +2571,67 @@ func (s *ForwardMCPService) convertNQEQueryOptions(options *NQEQueryOptions) *fo
 	return forwardOptions
 }
 
+// fetchAllNQEItems runs a library query by ID, paging through results until exhausted
+func (s *ForwardMCPService) fetchAllNQEItems(networkID, snapshotID, queryID string, parameters map[string]interface{}) ([]map[string]interface{}, error) {
+	limit := s.getQueryLimit(0)
+	if limit <= 0 {
+		limit = 1000
+	}
+	if err := s.checkNegativeCache(networkID, queryID); err != nil {
+		return nil, err
+	}
+	var items []map[string]interface{}
+	for offset := 0; ; offset += limit {
+		result, err := s.forwardClient.RunNQEQueryByID(&forward.NQEQueryParams{
+			NetworkID:  networkID,
+			QueryID:    queryID,
+			SnapshotID: snapshotID,
+			Parameters: parameters,
+			Options:    &forward.NQEQueryOptions{Limit: limit, Offset: offset},
+		})
+		s.recordQueryOutcome(networkID, queryID, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run NQE query %s (batch at offset %d): %w", queryID, offset, err)
+		}
+		if result == nil {
+			break
+		}
+		items = append(items, result.Items...)
+		if len(result.Items) < limit {
+			break
+		}
+	}
+	return items, nil
+}
+
+// fetchAllNQEQueryItems runs NQE source, paging through results until exhausted
+func (s *ForwardMCPService) fetchAllNQEQueryItems(networkID, snapshotID, query string) ([]map[string]interface{}, error) {
+	limit := s.getQueryLimit(0)
+	if limit <= 0 {
+		limit = 1000
+	}
+	var items []map[string]interface{}
+	for offset := 0; ; offset += limit {
+		result, err := s.forwardClient.RunNQEQueryByString(&forward.NQEQueryParams{
+			NetworkID:  networkID,
+			SnapshotID: snapshotID,
+			Query:      query,
+			Options:    &forward.NQEQueryOptions{Limit: limit, Offset: offset},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to run NQE query (batch at offset %d): %w", offset, err)
+		}
+		if result == nil {
+			break
+		}
+		items = append(items, result.Items...)
+		if len(result.Items) < limit {
+			break
+		}
+	}
+	return items, nil
+}
+
 // NQE Tool Implementations
 func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.ToolResponse, error) {
 	s.logToolCall("run_nqe_query_by_id", args, nil)
@@ -1577,6 +2639,22 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 	// Use defaults if not specified
 	networkID := s.getNetworkID(args.NetworkID)
 	snapshotID := s.getSnapshotID(args.SnapshotID)
+	// A resume token continues a batch fetch
+	if args.ResumeToken != "" {
+		args.AllResults = true
+	}
+	if args.Sample || args.SampleSize > 0 {
+		return s.sampleNQEQuery(args, networkID, snapshotID)
+	}
+
+	// Optional rollup of the rendered rows; the stored result keeps every row
+	var rollup *ResultRollup
+	if len(args.GroupBy) > 0 {
+		var err error
+		if rollup, err = newResultRollup(args.GroupBy, args.GroupExamples); err != nil {
+			return nil, err
+		}
+	}
 
 	// Proactive warning for potentially large queries
 	if (args.Options == nil || args.Options.Limit == 0 || args.Options.Limit > 1000) && !args.AllResults {
@@ -1597,48 +2675,78 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 			offset = args.Options.Offset
 		}
 
+		if err := s.checkNegativeCache(networkID, args.QueryID); err != nil {
+			return nil, err
+		}
+
+		// Continue an interrupted fetch of the same call from its last completed batch
+		progress, fetched, err := s.beginFetchProgress(args, networkID, snapshotID, limit, offset)
+		if err != nil {
+			return nil, err
+		}
 		allItems := []map[string]interface{}{}
+		fetchSnapshotID := snapshotID
+		if progress != nil {
+			allItems = append(allItems, fetched...)
+			offset = progress.nextOffset
+			fetchSnapshotID = progress.snapshotID
+		}
 		var lastResult *forward.NQERunResult
-		for {
-			params := &forward.NQEQueryParams{
+		concurrency, batches, fetchStart := s.nqeFetchConcurrency(args.Concurrency), 0, time.Now()
+		failedAt, err := fetchNQEBatches(offset, limit, concurrency, func(batchOffset int) (*forward.NQERunResult, error) {
+			result, err := s.forwardClient.RunNQEQueryByID(&forward.NQEQueryParams{
 				NetworkID:  networkID,
 				QueryID:    args.QueryID,
-				SnapshotID: snapshotID,
+				SnapshotID: fetchSnapshotID,
 				Parameters: args.Parameters,
 				Options: &forward.NQEQueryOptions{
 					Limit:  limit,
-					Offset: offset,
+					Offset: batchOffset,
 					// Format: "json", // REMOVED: API does not support this field
 				},
+			})
+			// Failures are recorded once the fetch ends, since batches past the end may fail harmlessly
+			if err == nil {
+				s.recordQueryOutcome(networkID, args.QueryID, nil)
 			}
-			result, err := s.forwardClient.RunNQEQueryByID(params)
-			if err != nil {
-				return nil, fmt.Errorf("failed to run NQE query (batch at offset %d): %w", offset, err)
-			}
+			return result, err
+		}, func(batchOffset int, result *forward.NQERunResult) {
 			if lastResult == nil {
 				lastResult = result
 			}
 			allItems = append(allItems, result.Items...)
-			if len(result.Items) < limit {
-				break // No more data
-			}
-			offset += limit
+			batches++
+			s.recordBatch(progress, batchOffset, result.Items)
+		})
+		if err != nil {
+			s.recordQueryOutcome(networkID, args.QueryID, err)
+			return nil, fmt.Errorf("failed to run NQE query (batch at offset %d): %w%s", failedAt, err, s.interruptFetch(progress, err))
 		}
 		// Use lastResult as template for metadata, but replace Items
 		if lastResult == nil {
+			s.completeFetch(progress)
 			return mcp.NewToolResponse(mcp.NewTextContent("No results found.")), nil
 		}
 		lastResult.Items = allItems
+		s.normalizeDeviceFactItems(args.QueryID, allItems)
+
+		// A fetch from the first row is the cost history estimate_query_cost predicts from
+		if s.apiTracker != nil && offset == 0 && (progress == nil || !progress.resumed) {
+			if trackErr := s.apiTracker.TrackBatchFetch(args.QueryID, networkID, snapshotID, len(allItems), batches, concurrency, time.Since(fetchStart)); trackErr != nil {
+				s.logger.Debug("Failed to track batch fetch in memory system: %v", trackErr)
+			}
+		}
 
 		// Store in memory system/database with chunking
 		var entityID string
 		if s.memorySystem != nil {
-			id, chunkErr := s.memorySystem.StoreNQEResultWithChunking(args.QueryID, networkID, snapshotID, lastResult, 200)
+			id, chunkErr := s.memorySystem.StoreNQEResultWithChunking(args.QueryID, networkID, snapshotID, lastResult, s.chunkTargetBytes())
 			if chunkErr != nil {
 				s.logger.Warn("Failed to store NQE result with chunking: %v", chunkErr)
 			} else {
 				s.logger.Debug("Stored NQE result in memory system with chunking (entity: %s)", id)
 				entityID = id
+				s.completeFetch(progress)
 
 				// Automatically build bloom filter for large results
 				if s.bloomManager != nil && len(allItems) > 100 {
@@ -1667,12 +2775,28 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 		}
 		preview := allItems[:previewRows]
 		response := "Fetched all results in batches.\n"
+		if progress != nil && progress.resumed {
+			response = fmt.Sprintf("Resumed an interrupted fetch: %d rows were already fetched, the rest was fetched in batches.\n", len(fetched))
+		}
 		response += fmt.Sprintf("Total items: %d\nColumns: %v\n", rowCount, columns)
-		previewJSON, _ := json.MarshalIndent(preview, "", "  ")
-		response += fmt.Sprintf("Preview (first %d rows):\n%s\n", previewRows, string(previewJSON))
+		if rollup != nil {
+			rollup.EntityID = entityID
+			response += rollup.AddAll(allItems).String()
+		} else {
+			previewJSON, _ := json.MarshalIndent(preview, "", "  ")
+			response += fmt.Sprintf("Preview (first %d rows):\n%s\n", previewRows, string(previewJSON))
+		}
+		response += schemaDriftWarning(s.recordQuerySchema(args.QueryID, networkID, snapshotID, allItems))
 		if entityID != "" {
 			response += fmt.Sprintf("Stored in memory system as entity: %s\n", entityID)
 			response += "You can use get_nqe_result_summary to analyze this result locally.\n"
+
+			// Flag unusual jumps against earlier runs of the same query on this network
+			if anomalies, baseline, err := s.annotateResultAnomalies(entityID, AnomalyOptions{}); err != nil {
+				s.logger.Debug("Anomaly detection skipped for %s: %v", entityID, err)
+			} else if len(anomalies) > 0 {
+				response += "\n" + formatResultAnomalies(entityID, anomalies, len(baseline))
+			}
 		}
 		return mcp.NewToolResponse(mcp.NewTextContent(response)), nil
 	}
@@ -1681,12 +2805,14 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 	// Validate query ID against database index if available
 	stats := s.queryIndex.GetStatistics()
 	totalQueries := stats["total_queries"].(int)
+	queryCategory := ""
 	if totalQueries > 0 {
 		if entry, err := s.queryIndex.GetQueryByID(args.QueryID); err != nil {
 			s.logger.Warn("Query ID %s not found in database index - may be deprecated or invalid", args.QueryID)
 			// Continue execution anyway in case it's a newer query not yet in the database
 		} else {
 			s.logger.Debug("Executing validated query: %s (Path: %s)", entry.QueryID, entry.Path)
+			queryCategory = entry.Category
 		}
 	}
 
@@ -1695,9 +2821,19 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 
 	// Try to get result from cache first
 	if s.config.Forward.SemanticCache.Enabled && s.semanticCache != nil {
-		if cachedResult, found := s.semanticCache.Get(cacheKey, networkID, snapshotID); found {
-			s.logger.Debug("Cache hit for NQE query %s", args.QueryID)
-			return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(cachedResult))), nil
+		lookup := CacheLookupOptions{Category: queryCategory, Threshold: args.SimilarityThreshold}
+		if cachedResult, hit, found := s.semanticCache.Lookup(cacheKey, networkID, snapshotID, lookup); found {
+			s.logger.Debug("Cache hit for NQE query %s (%s)", args.QueryID, hit)
+			if rollup != nil {
+				return mcp.NewToolResponse(
+					mcp.NewTextContent(rollup.AddAll(cachedResult.Items).String()),
+					mcp.NewTextContent(hit.String()),
+				), nil
+			}
+			return mcp.NewToolResponse(
+				mcp.NewTextContent(MarshalCompactJSONString(cachedResult)),
+				mcp.NewTextContent(hit.String()),
+			), nil
 		}
 	}
 
@@ -1716,10 +2852,16 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 		}
 	}
 
+	// Fail fast when this query or network failed moments ago
+	if err := s.checkNegativeCache(networkID, args.QueryID); err != nil {
+		return nil, err
+	}
+
 	// Track execution time for API memory tracking
 	start := time.Now()
 	result, err := s.forwardClient.RunNQEQueryByID(params)
 	executionTime := time.Since(start)
+	s.recordQueryOutcome(networkID, args.QueryID, err)
 
 	if err != nil {
 		s.logToolCall("run_nqe_query_by_id", args, err)
@@ -1735,6 +2877,11 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 		if strings.Contains(errorStr, "result exceeds maximum length") {
 			// Automatic fallback to batch mode for large results
 			s.logger.Warn("Result too large, retrying with all_results: true for query %s", args.QueryID)
+			if s.apiTracker != nil {
+				if trackErr := s.apiTracker.TrackOversizedResult(args.QueryID, networkID, snapshotID); trackErr != nil {
+					s.logger.Debug("Failed to track oversized result in memory system: %v", trackErr)
+				}
+			}
 			args.AllResults = true
 			// Inform the user that we're retrying in batch mode
 			msg := "The result was too large to return directly. Fetching all results in batches for local analysis. A summary will be provided.\n"
@@ -1778,18 +2925,24 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 
 	// Track the query execution in memory system
 	if s.apiTracker != nil {
-		if trackErr := s.apiTracker.TrackNetworkQuery(args.QueryID, networkID, snapshotID, result, executionTime); trackErr != nil {
+		if trackErr := s.apiTracker.TrackNetworkQueryPage(args.QueryID, networkID, snapshotID, result, executionTime, params.Options.Limit); trackErr != nil {
 			s.logger.Debug("Failed to track query execution in memory system: %v", trackErr)
 		}
 	}
 
+	// Add canonical vendor facts to inventory and support results
+	s.normalizeDeviceFactItems(args.QueryID, result.Items)
+
 	// Store result in memory system with chunking for LLM/large result use
 	if s.memorySystem != nil {
-		_, chunkErr := s.memorySystem.StoreNQEResultWithChunking(args.QueryID, networkID, snapshotID, result, 200) // 200 rows per chunk
+		storedID, chunkErr := s.memorySystem.StoreNQEResultWithChunking(args.QueryID, networkID, snapshotID, result, s.chunkTargetBytes())
 		if chunkErr != nil {
 			s.logger.Warn("Failed to store NQE result with chunking: %v", chunkErr)
 		} else {
 			s.logger.Debug("Stored NQE result in memory system with chunking (entity: %s)", args.QueryID)
+			if rollup != nil {
+				rollup.EntityID = storedID
+			}
 		}
 	}
 
@@ -1802,24 +2955,34 @@ func (s *ForwardMCPService) runNQEQueryByID(args RunNQEQueryByIDArgs) (*mcp.Tool
 		}
 	}
 
-	resultJSON := MarshalCompactJSONString(result)
 	s.logger.Debug("NQE query completed with %d items", len(result.Items))
+	drift := schemaDriftWarning(s.recordQuerySchema(args.QueryID, networkID, snapshotID, result.Items))
 
-	response := fmt.Sprintf("NQE query completed. Found %d items:\n%s\n\n", len(result.Items), resultJSON)
+	var response string
+	if rollup != nil {
+		response = fmt.Sprintf("NQE query completed. Found %d items.\n%s\n", len(result.Items), rollup.AddAll(result.Items).String())
+	} else {
+		resultJSON := MarshalCompactJSONString(result)
+		response = fmt.Sprintf("NQE query completed. Found %d items:\n%s\n\n", len(result.Items), resultJSON)
+	}
 
 	// Pagination warning if results may be truncated
 	if params.Options != nil && len(result.Items) == params.Options.Limit {
-		response += "\n⚠️ Results may be truncated. Use the 'offset' parameter to fetch the next page.\n"
-		response += fmt.Sprintf("Example: set 'offset' to %d to get the next page.\n", params.Options.Offset+params.Options.Limit)
-		response += "Or set 'all_results: true' in your request to fetch all results in batches.\n"
+		response += "\n⚠️ Results may be truncated. Pass next_offset from the pagination block as 'offset' to fetch the next page,\n"
+		response += "or set 'all_results: true' in your request to fetch all results in batches.\n"
 	}
 
+	response += drift
+
 	// Add helpful suggestions for predefined queries
 	response += "Would you like to:\n" +
 		"1. Run a different predefined query?\n" +
 		"2. Create a custom query?\n" +
 		"3. Export these results?"
 
+	if params.Options != nil {
+		return paginatedResponse(response, openPagination(params.Options.Offset, params.Options.Limit, len(result.Items))), nil
+	}
 	return mcp.NewToolResponse(mcp.NewTextContent(response)), nil
 }
 
@@ -1861,6 +3024,21 @@ func (s *ForwardMCPService) listNQEQueries(args ListNQEQueriesArgs) (*mcp.ToolRe
 	// Use database-backed query index instead of direct API calls
 	filteredEntries := s.queryIndex.FilterQueriesByDirectory(args.Directory)
 
+	// Apply the category filter, which also matches custom taxonomy categories
+	taxonomy := s.queryTaxonomy()
+	if args.Category != "" {
+		var categoryEntries []*NQEQueryIndexEntry
+		for _, entry := range filteredEntries {
+			if taxonomy.MatchesCategory(entry, args.Category, "") {
+				categoryEntries = append(categoryEntries, entry)
+			}
+		}
+		filteredEntries = categoryEntries
+	}
+	if args.GroupByCategory && len(filteredEntries) > 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(formatQueriesByCategory(filteredEntries, taxonomy))), nil
+	}
+
 	// Convert NQEQueryIndexEntry to forward.NQEQuery for compatibility
 	var queries []forward.NQEQuery
 	for _, entry := range filteredEntries {
@@ -1909,15 +3087,41 @@ func (s *ForwardMCPService) listDevices(args ListDevicesArgs) (*mcp.ToolResponse
 		limit = s.getQueryLimit(0)
 	}
 
-	params := &forward.DeviceQueryParams{
-		SnapshotID: args.SnapshotID,
-		Limit:      limit,
-		Offset:     args.Offset,
-	}
+	var response *forward.DeviceResponse
+	if args.Tag != "" {
+		// Tagged devices are filtered from the full inventory and paged locally
+		names, err := s.taggedDevices(args.NetworkID, args.Tag)
+		if err != nil {
+			return nil, err
+		}
+		inventory, err := s.getNetworkDevices(args.NetworkID, args.SnapshotID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list devices: %w", err)
+		}
+		tagged := make(map[string]bool, len(names))
+		for _, name := range names {
+			tagged[name] = true
+		}
+		var matching []forward.Device
+		for _, device := range inventory {
+			if tagged[device.Name] {
+				matching = append(matching, device)
+			}
+		}
+		start := min(max(args.Offset, 0), len(matching))
+		response = &forward.DeviceResponse{Devices: matching[start:min(start+limit, len(matching))], TotalCount: len(matching)}
+	} else {
+		params := &forward.DeviceQueryParams{
+			SnapshotID: args.SnapshotID,
+			Limit:      limit,
+			Offset:     args.Offset,
+		}
 
-	response, err := s.forwardClient.GetDevices(args.NetworkID, params)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list devices: %w", err)
+		var err error
+		response, err = s.forwardClient.GetDevices(args.NetworkID, params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list devices: %w", err)
+		}
 	}
 
 	// Track device discovery in memory system
@@ -1927,15 +3131,29 @@ func (s *ForwardMCPService) listDevices(args ListDevicesArgs) (*mcp.ToolResponse
 		}
 	}
 
-	result := MarshalCompactJSONString(response)
-	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Found %d devices (total: %d):\n%s", len(response.Devices), response.TotalCount, result))), nil
+	// Attach canonical vendor facts alongside the raw device fields
+	type normalizedDevice struct {
+		forward.Device
+		Normalized DeviceFacts `json:"normalized"`
+	}
+	devices := make([]normalizedDevice, 0, len(response.Devices))
+	for _, device := range response.Devices {
+		devices = append(devices, normalizedDevice{Device: device, Normalized: s.vendorNormalizer.NormalizeDevice(device)})
+	}
+
+	result := MarshalCompactJSONString(map[string]interface{}{
+		"devices":    devices,
+		"totalCount": response.TotalCount,
+	})
+	text := fmt.Sprintf("Found %d devices (total: %d):\n%s", len(response.Devices), response.TotalCount, result)
+	return paginatedResponse(text, newPagination(response.TotalCount, max(args.Offset, 0), limit, len(response.Devices))), nil
 }
 
 func (s *ForwardMCPService) getDeviceLocations(args GetDeviceLocationsArgs) (*mcp.ToolResponse, error) {
 	s.logToolCall("get_device_locations", args, nil)
 
 	// Get all device locations from API
-	allLocations, err := s.forwardClient.GetDeviceLocations(args.NetworkID)
+	allLocations, err := s.deviceLocationMap(args.NetworkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get device locations: %w", err)
 	}
@@ -1956,6 +3174,7 @@ func (s *ForwardMCPService) getDeviceLocations(args GetDeviceLocationsArgs) (*mc
 	var locations map[string]string
 	var totalCount int
 	var hasMore bool
+	var entityID string
 
 	if args.AllResults {
 		// Store all device locations in memory system for large datasets
@@ -1972,6 +3191,7 @@ func (s *ForwardMCPService) getDeviceLocations(args GetDeviceLocationsArgs) (*mc
 				"timestamp":   time.Now().Unix(),
 			})
 			if err == nil {
+				entityID = entity.ID
 				// Store the device locations data
 				locationsJSON, _ := json.Marshal(locations)
 				s.memorySystem.AddObservation(entity.ID, string(locationsJSON), "data", map[string]interface{}{
@@ -1993,6 +3213,8 @@ func (s *ForwardMCPService) getDeviceLocations(args GetDeviceLocationsArgs) (*mc
 			for k := range allLocations {
 				keys = append(keys, k)
 			}
+			// Sort so consecutive pages do not repeat or skip devices
+			sort.Strings(keys)
 			if end > totalCount {
 				end = totalCount
 			}
@@ -2026,7 +3248,7 @@ func (s *ForwardMCPService) getDeviceLocations(args GetDeviceLocationsArgs) (*mc
 		responseText.WriteString(fmt.Sprintf("\n\n💾 Stored %d device locations in memory system for future reference.", totalCount))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(responseText.String())), nil
+	return paginatedResponse(responseText.String(), listPagination(totalCount, offset, limit, len(locations), args.AllResults, entityID)), nil
 }
 
 // Snapshot Management Tool Implementations
@@ -2055,6 +3277,7 @@ func (s *ForwardMCPService) listSnapshots(args ListSnapshotsArgs) (*mcp.ToolResp
 	var snapshots []forward.Snapshot
 	var totalCount int
 	var hasMore bool
+	var entityID string
 
 	if args.AllResults {
 		// Store all snapshots in memory system for large datasets
@@ -2071,6 +3294,7 @@ func (s *ForwardMCPService) listSnapshots(args ListSnapshotsArgs) (*mcp.ToolResp
 				"timestamp":   time.Now().Unix(),
 			})
 			if err == nil {
+				entityID = entity.ID
 				// Store the snapshots data
 				snapshotsJSON, _ := json.Marshal(snapshots)
 				s.memorySystem.AddObservation(entity.ID, string(snapshotsJSON), "data", map[string]interface{}{
@@ -2110,7 +3334,7 @@ func (s *ForwardMCPService) listSnapshots(args ListSnapshotsArgs) (*mcp.ToolResp
 	responseText.WriteString(":\n")
 
 	if len(snapshots) > 0 {
-		result, _ := json.MarshalIndent(snapshots, "", "  ")
+		result, _ := json.MarshalIndent(s.displaySnapshots(snapshots), "", "  ")
 		responseText.WriteString(string(result))
 	} else {
 		responseText.WriteString("No snapshots found.")
@@ -2120,17 +3344,17 @@ func (s *ForwardMCPService) listSnapshots(args ListSnapshotsArgs) (*mcp.ToolResp
 		responseText.WriteString(fmt.Sprintf("\n\n💾 Stored %d snapshots in memory system for future reference.", totalCount))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(responseText.String())), nil
+	return paginatedResponse(responseText.String(), listPagination(totalCount, offset, limit, len(snapshots), args.AllResults, entityID)), nil
 }
 
 func (s *ForwardMCPService) getLatestSnapshot(args GetLatestSnapshotArgs) (*mcp.ToolResponse, error) {
 	s.logToolCall("get_latest_snapshot", args, nil)
-	snapshot, err := s.forwardClient.GetLatestSnapshot(args.NetworkID)
+	snapshot, err := s.latestSnapshot(args.NetworkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get latest snapshot: %w", err)
 	}
 
-	result, _ := json.MarshalIndent(snapshot, "", "  ")
+	result, _ := json.MarshalIndent(s.displaySnapshots([]forward.Snapshot{*snapshot})[0], "", "  ")
 	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Latest snapshot:\n%s", string(result)))), nil
 }
 
@@ -2139,7 +3363,7 @@ func (s *ForwardMCPService) listLocations(args ListLocationsArgs) (*mcp.ToolResp
 	s.logToolCall("list_locations", args, nil)
 
 	// Get all locations from API
-	allLocations, err := s.forwardClient.GetLocations(args.NetworkID)
+	allLocations, err := s.networkLocations(args.NetworkID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list locations: %w", err)
 	}
@@ -2160,6 +3384,7 @@ func (s *ForwardMCPService) listLocations(args ListLocationsArgs) (*mcp.ToolResp
 	var locations []forward.Location
 	var totalCount int
 	var hasMore bool
+	var entityID string
 
 	if args.AllResults {
 		// Store all locations in memory system for large datasets
@@ -2176,6 +3401,7 @@ func (s *ForwardMCPService) listLocations(args ListLocationsArgs) (*mcp.ToolResp
 				"timestamp":   time.Now().Unix(),
 			})
 			if err == nil {
+				entityID = entity.ID
 				// Store the locations data
 				locationsJSON, _ := json.Marshal(locations)
 				s.memorySystem.AddObservation(entity.ID, string(locationsJSON), "data", map[string]interface{}{
@@ -2225,7 +3451,7 @@ func (s *ForwardMCPService) listLocations(args ListLocationsArgs) (*mcp.ToolResp
 		responseText.WriteString(fmt.Sprintf("\n\n💾 Stored %d locations in memory system for future reference.", totalCount))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(responseText.String())), nil
+	return paginatedResponse(responseText.String(), listPagination(totalCount, offset, limit, len(locations), args.AllResults, entityID)), nil
 }
 
 func (s *ForwardMCPService) createLocation(args CreateLocationArgs) (*mcp.ToolResponse, error) {
@@ -2341,6 +3567,13 @@ func (s *ForwardMCPService) updateLocation(args UpdateLocationArgs) (*mcp.ToolRe
 
 func (s *ForwardMCPService) deleteLocation(args DeleteLocationArgs) (*mcp.ToolResponse, error) {
 	s.logToolCall("delete_location", args, nil)
+	impact, err := s.locationDeleteImpact(args.NetworkID, args.LocationID)
+	if err != nil {
+		return nil, err
+	}
+	if response, err := s.confirmDestructive("delete_location", args.NetworkID+"/"+args.LocationID, args.ConfirmationToken, impact); response != nil || err != nil {
+		return response, err
+	}
 	deletedLocation, err := s.forwardClient.DeleteLocation(args.NetworkID, args.LocationID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete location: %w", err)
@@ -2352,6 +3585,23 @@ func (s *ForwardMCPService) deleteLocation(args DeleteLocationArgs) (*mcp.ToolRe
 
 func (s *ForwardMCPService) deleteSnapshot(args DeleteSnapshotArgs) (*mcp.ToolResponse, error) {
 	s.logToolCall("delete_snapshot", args, nil)
+	// Snapshots carry no network_id, so the network access policy is checked here
+	if s.networkPolicy != nil {
+		if _, err := s.snapshotNetworkID(args.SnapshotID); err != nil {
+			return nil, err
+		}
+	}
+	impact := DeleteImpact{
+		Summary: fmt.Sprintf("Snapshot %s will be permanently deleted from Forward Enterprise.", args.SnapshotID),
+		Details: []string{"Path searches, NQE queries and diffs against this snapshot will no longer work"},
+		Rows:    1,
+	}
+	if s.defaults != nil && s.defaults.SnapshotID == args.SnapshotID {
+		impact.Details = append(impact.Details, "It is the default snapshot of this server; tools fall back to the latest snapshot")
+	}
+	if response, err := s.confirmDestructive("delete_snapshot", args.SnapshotID, args.ConfirmationToken, impact); response != nil || err != nil {
+		return response, err
+	}
 	err := s.forwardClient.DeleteSnapshot(args.SnapshotID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete snapshot: %w", err)
@@ -2471,12 +3721,18 @@ func (s *ForwardMCPService) updateDeviceLocations(args UpdateDeviceLocationsArgs
 	var cloudDevices []string
 	var physicalDevices = make(map[string]string)
 
-	for deviceName, locationID := range args.Locations {
-		if s.isCloudDevice(deviceName) {
-			cloudDevices = append(cloudDevices, deviceName)
-			s.logger.Warn("Detected cloud device in location update: %s", deviceName)
+	// Classify from inventory metadata; name heuristics only apply to devices missing from it
+	deviceNames := make([]string, 0, len(args.Locations))
+	for deviceName := range args.Locations {
+		deviceNames = append(deviceNames, deviceName)
+	}
+	sort.Strings(deviceNames)
+	for _, classification := range s.classifyDeviceNames(args.NetworkID, deviceNames) {
+		if !classification.Movable() {
+			cloudDevices = append(cloudDevices, classification.Device)
+			s.logger.Warn("Detected %s device in location update: %s (%s)", classification.Class, classification.Device, classification.Reason)
 		} else {
-			physicalDevices[deviceName] = locationID
+			physicalDevices[classification.Device] = args.Locations[classification.Device]
 		}
 	}
 
@@ -2521,7 +3777,7 @@ func (s *ForwardMCPService) updateDeviceLocations(args UpdateDeviceLocationsArgs
 
 // resolveNetworkIDByName resolves a network name to its networkId using a case-insensitive match.
 func (s *ForwardMCPService) resolveNetworkIDByName(name string) (string, error) {
-	networks, err := s.forwardClient.GetNetworks()
+	networks, err := s.getNetworks()
 	if err != nil {
 		return "", err
 	}
@@ -2610,25 +3866,6 @@ func (s *ForwardMCPService) searchConfigs(args SearchConfigsArgs) (*mcp.ToolResp
 	return s.runNQEQueryByID(queryArgs)
 }
 
-func (s *ForwardMCPService) getConfigDiff(args GetConfigDiffArgs) (*mcp.ToolResponse, error) {
-	s.logToolCall("get_config_diff", args, nil)
-
-	params := map[string]interface{}{}
-	if args.AfterSnapshot != "" {
-		params["compareSnapshotId"] = args.AfterSnapshot
-	}
-
-	queryArgs := RunNQEQueryByIDArgs{
-		NetworkID:  args.NetworkID,
-		SnapshotID: args.BeforeSnapshot,
-		QueryID:    "FQ_51f090cbea069b4049eb283716ab3bbb3f578aea", // Config Diff
-		Parameters: params,
-		Options:    args.Options,
-	}
-
-	return s.runNQEQueryByID(queryArgs)
-}
-
 // Default Settings Management Tool Implementations
 
 func (s *ForwardMCPService) getDefaultSettings(args GetDefaultSettingsArgs) (*mcp.ToolResponse, error) {
@@ -2637,7 +3874,7 @@ func (s *ForwardMCPService) getDefaultSettings(args GetDefaultSettingsArgs) (*mc
 	// Get network name if possible
 	networkName := "Not set"
 	if s.defaults.NetworkID != "" {
-		networks, err := s.forwardClient.GetNetworks()
+		networks, err := s.getNetworks()
 		if err == nil {
 			for _, network := range networks {
 				if network.ID == s.defaults.NetworkID {
@@ -2683,7 +3920,7 @@ func (s *ForwardMCPService) setDefaultNetwork(args SetDefaultNetworkArgs) (*mcp.
 	}
 
 	// First, try as network ID by listing networks and checking if it exists
-	networks, err := s.forwardClient.GetNetworks()
+	networks, err := s.getNetworks()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get networks: %w", err)
 	}
@@ -2749,6 +3986,53 @@ func (s *ForwardMCPService) getCacheStats(args GetCacheStatsArgs) (*mcp.ToolResp
 	summary += fmt.Sprintf("• Active Entries: %v/%v\n", stats["total_entries"], stats["max_entries"])
 	summary += fmt.Sprintf("• Similarity Threshold: %v\n", stats["threshold"])
 
+	if s.pathCache != nil {
+		pathStats := s.pathCache.GetStats()
+		summary += "\nPath Search Cache:\n"
+		summary += fmt.Sprintf("• Hits/Misses: %v/%v (hit rate %v%%)\n", pathStats["cache_hits"], pathStats["cache_misses"], pathStats["hit_rate_percent"])
+		summary += fmt.Sprintf("• Active Entries: %v/%v\n", pathStats["total_entries"], pathStats["max_entries"])
+	}
+
+	if s.deviceCache != nil {
+		deviceStats := s.deviceCache.GetStats()
+		summary += "\nDevice Cache:\n"
+		summary += fmt.Sprintf("• Hits/Misses: %v/%v (hit rate %v%%)\n", deviceStats["cache_hits"], deviceStats["cache_misses"], deviceStats["hit_rate_percent"])
+		summary += fmt.Sprintf("• Cached Inventories: %v (%v devices)\n", deviceStats["cached_inventories"], deviceStats["cached_devices"])
+		summary += fmt.Sprintf("• Refreshes: %v\n", deviceStats["refreshes"])
+	}
+
+	if reporter, ok := s.forwardClient.(forward.TransferStatsReporter); ok {
+		transfer := reporter.TransferStats()
+		summary += "\nForward API Transfer:\n"
+		summary += fmt.Sprintf("• Requests: %d (%d with compressed bodies)\n", transfer.Requests, transfer.CompressedRequests)
+		summary += fmt.Sprintf("• Responses: %s received as %s on the wire (%.1f%% saved, %d gzip responses)\n",
+			formatBytes(transfer.ResponseBytes), formatBytes(transfer.ResponseWireBytes), transfer.ResponseSavingsPercent(), transfer.CompressedResponses)
+		summary += fmt.Sprintf("• Request Bodies: %s sent as %s\n", formatBytes(transfer.RequestBytes), formatBytes(transfer.RequestWireBytes))
+	}
+
+	if s.negativeCache != nil {
+		negativeStats := s.negativeCache.GetStats()
+		summary += "\nNegative Cache (recent failures):\n"
+		summary += fmt.Sprintf("• Active Failures: %v (retry window %vs)\n", negativeStats["active_failures"], negativeStats["ttl_seconds"])
+		summary += fmt.Sprintf("• Fast Failures Returned: %v\n", negativeStats["fast_failures"])
+	}
+
+	if s.prefetcher != nil {
+		prefetchStats := s.prefetcher.GetStats()
+		summary += "\nPrefetch (follow-up data warmed in the background):\n"
+		summary += fmt.Sprintf("• Scheduled/Fetched/Served: %v/%v/%v\n", prefetchStats["scheduled"], prefetchStats["fetched"], prefetchStats["served"])
+		summary += fmt.Sprintf("• Rate Limited: %v (at most %v API calls per minute), Failed: %v\n", prefetchStats["rate_limited"], prefetchStats["per_minute"], prefetchStats["failed"])
+	}
+
+	if s.callCoalescer != nil {
+		coalescerStats := s.callCoalescer.GetStats()
+		summary += "\nCall Coalescing (identical concurrent tool calls):\n"
+		summary += fmt.Sprintf("• Executed/Coalesced: %v/%v (%.1f%% shared)\n", coalescerStats["executed_calls"], coalescerStats["coalesced_calls"], coalescerStats["coalesce_percent"])
+		for _, tool := range coalescerStats["by_tool"].([]map[string]interface{}) {
+			summary += fmt.Sprintf("• %v: %v coalesced\n", tool["tool"], tool["coalesced"])
+		}
+	}
+
 	return mcp.NewToolResponse(mcp.NewTextContent(summary)), nil
 }
 
@@ -2784,7 +4068,7 @@ func (s *ForwardMCPService) suggestSimilarQueries(args SuggestSimilarQueriesArgs
 			}
 			response += "\n"
 		}
-		response += fmt.Sprintf("   Used %d times, last accessed: %s\n\n", entry.AccessCount, entry.LastAccessed.Format("2006-01-02 15:04:05"))
+		response += fmt.Sprintf("   Used %d times, last accessed: %s\n\n", entry.AccessCount, s.timeFormatter.FormatWithAge(entry.LastAccessed))
 	}
 
 	response += "You can use these suggestions to refine your query or explore related network analysis patterns."
@@ -2819,12 +4103,25 @@ func (s *ForwardMCPService) clearCache(args ClearCacheArgs) (*mcp.ToolResponse,
 			embeddingService = NewMockEmbeddingService()
 		}
 		s.semanticCache = NewSemanticCache(embeddingService, s.logger, s.instanceID, &s.config.Forward.SemanticCache)
+		s.applyPinsToCache()
 
 		removed = totalEntries
 		operation = "Cleared all cache entries"
+		if s.pathCache != nil {
+			removed += s.pathCache.Clear()
+		}
+		if s.deviceCache != nil {
+			removed += s.deviceCache.Invalidate("")
+		}
 	} else {
 		removed = s.semanticCache.ClearExpired()
 		operation = "Cleared expired cache entries"
+		if s.pathCache != nil {
+			removed += s.pathCache.ClearExpired()
+		}
+		if s.deviceCache != nil {
+			removed += s.deviceCache.ClearExpired()
+		}
 	}
 
 	response := fmt.Sprintf("%s: %d entries removed\n\n", operation, removed)
@@ -2838,6 +4135,81 @@ func (s *ForwardMCPService) clearCache(args ClearCacheArgs) (*mcp.ToolResponse,
 	return mcp.NewToolResponse(mcp.NewTextContent(response)), nil
 }
 
+// listCacheEntries lists semantic cache entries matching the given filters
+func (s *ForwardMCPService) listCacheEntries(args ListCacheEntriesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_cache_entries", args, nil)
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 25
+	}
+
+	entries, total := s.semanticCache.ListEntries(CacheEntryFilter{
+		NetworkID:      args.NetworkID,
+		Networks:       s.networkPolicy,
+		MinAge:         time.Duration(args.MinAgeMinutes) * time.Minute,
+		MaxAge:         time.Duration(args.MaxAgeMinutes) * time.Minute,
+		MinAccessCount: args.MinAccessCount,
+		Limit:          limit,
+	})
+
+	if total == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No cache entries match the given filters.")), nil
+	}
+
+	response := fmt.Sprintf("Cache entries (showing %d of %d, most recently accessed first):\n\n", len(entries), total)
+	for _, entry := range entries {
+		status := ""
+		if entry.Expired {
+			status = " [expired]"
+		}
+		response += fmt.Sprintf("• %s%s\n  Query: %s\n  Network: %s | Age: %s | Accesses: %d | Size: %d bytes\n",
+			entry.Hash[:12], status, truncateString(entry.Query, 100), entry.NetworkID,
+			(time.Duration(entry.AgeSeconds) * time.Second).String(), entry.AccessCount, entry.SizeBytes)
+	}
+	response += "\nUse inspect_cache_entry or evict_cache_entry with a hash (or its first 12 characters) for details or removal."
+
+	return mcp.NewToolResponse(mcp.NewTextContent(response)), nil
+}
+
+// inspectCacheEntry shows the details of a single semantic cache entry
+func (s *ForwardMCPService) inspectCacheEntry(args InspectCacheEntryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("inspect_cache_entry", args, nil)
+
+	if args.Hash == "" {
+		return nil, fmt.Errorf("hash parameter is required")
+	}
+
+	neighbors := args.Neighbors
+	if neighbors <= 0 {
+		neighbors = 5
+	}
+
+	inspection, err := s.semanticCache.InspectEntry(args.Hash, neighbors, s.networkPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect cache entry: %w", err)
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Cache entry %s:\n%s", inspection.Hash, MarshalCompactJSONString(inspection)))), nil
+}
+
+// evictCacheEntry removes a single semantic cache entry
+func (s *ForwardMCPService) evictCacheEntry(args EvictCacheEntryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("evict_cache_entry", args, nil)
+
+	if args.Hash == "" {
+		return nil, fmt.Errorf("hash parameter is required")
+	}
+
+	evicted, err := s.semanticCache.EvictEntry(args.Hash, s.networkPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evict cache entry: %w", err)
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Evicted cache entry %s (query: %s, freed %d bytes)",
+		evicted.Hash, truncateString(evicted.Query, 100), evicted.SizeBytes))), nil
+}
+
 // AI-Powered Query Discovery Tool Implementations
 
 // searchNQEQueries performs AI-powered search through the NQE query library
@@ -2876,34 +4248,50 @@ func (s *ForwardMCPService) searchNQEQueries(args SearchNQEQueriesArgs) (*mcp.To
 		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Search failed: %v", err))), nil
 	}
 
-	// Apply category/subcategory filters if specified
+	// Apply category/subcategory filters if specified, matching custom taxonomy categories too
 	var filteredResults []*QuerySearchResult
-	categoryFilterApplied := args.Category != ""
-	subcategoryFilterApplied := args.Subcategory != ""
-
+	taxonomy := s.queryTaxonomy()
 	for _, result := range results {
-		if categoryFilterApplied && !strings.EqualFold(result.Category, args.Category) {
-			continue
-		}
-		if subcategoryFilterApplied && !strings.EqualFold(result.Subcategory, args.Subcategory) {
+		if !taxonomy.MatchesCategory(result.NQEQueryIndexEntry, args.Category, args.Subcategory) {
 			continue
 		}
 		filteredResults = append(filteredResults, result)
 	}
 
-	if len(filteredResults) == 0 {
+	// Deprecated queries are not in the index, so they are only matched on request
+	var deprecatedMatches []DeprecatedQuery
+	if args.IncludeDeprecated && s.database != nil {
+		deprecated, err := s.database.LoadDeprecatedQueries()
+		if err != nil {
+			s.logger.Warn("Failed to load deprecated queries: %v", err)
+		}
+		deprecatedMatches = matchDeprecatedQueries(deprecated, args.Query, limit)
+	}
+
+	if len(filteredResults) == 0 && len(deprecatedMatches) == 0 {
 		return mcp.NewToolResponse(mcp.NewTextContent("No relevant NQE queries found for your search. Try different keywords or check your query index.")), nil
 	}
 
 	// Format the response
-	response := fmt.Sprintf("%s search found %d relevant NQE queries for: '%s'\n\n",
-		filteredResults[0].MatchType, len(filteredResults), args.Query)
+	response := ""
+	if len(filteredResults) > 0 {
+		response = fmt.Sprintf("%s search found %d relevant NQE queries for: '%s'\n\n",
+			filteredResults[0].MatchType, len(filteredResults), args.Query)
+	}
 	for i, result := range filteredResults {
 		if i >= limit {
 			break
 		}
+		category, _, _ := taxonomy.Categorize(result.QueryID, result.Path, result.Category, result.Subcategory)
 		response += fmt.Sprintf("**%d. %s** (%.1f%% match)\n   **Intent:** %s\n   **Description:** %s\n   **Category:** %s\n   **Query ID:** `%s`\n\n",
-			i+1, result.Path, result.SimilarityScore*100, result.Intent, result.Description, result.Category, result.QueryID)
+			i+1, result.Path, result.SimilarityScore*100, result.Intent, result.Description, category, result.QueryID)
+	}
+	if len(deprecatedMatches) > 0 {
+		response += fmt.Sprintf("**Deprecated queries** (no longer in the Forward library) matching '%s':\n\n", args.Query)
+		for _, query := range deprecatedMatches {
+			response += fmt.Sprintf("- %s (deprecated %s)\n   **Intent:** %s\n   **Query ID:** `%s`\n",
+				query.Path, s.timeFormatter.Since(query.DeprecatedAt), query.Intent, query.QueryID)
+		}
 	}
 
 	return mcp.NewToolResponse(mcp.NewTextContent(response)), nil
@@ -3052,13 +4440,27 @@ func (s *ForwardMCPService) initializeQueryIndex(args InitializeQueryIndexArgs)
 // hydrateDatabase hydrates the database by loading queries from the Forward Networks API
 func (s *ForwardMCPService) hydrateDatabase(args HydrateDatabaseArgs) (*mcp.ToolResponse, error) {
 	if s.database == nil {
-		return nil, fmt.Errorf("database is not available")
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
 	}
 
 	// Set defaults
 	if args.MaxRetries == 0 {
 		args.MaxRetries = 3
 	}
+	if args.Workers <= 0 {
+		args.Workers = defaultHydrationWorkers
+	}
+
+	if args.Delta {
+		s.logger.Info("🔄 Starting delta database hydration...")
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+		delta, err := s.database.hydrateDelta(ctx, s.forwardClient, s.logger, args.Workers)
+		if err != nil {
+			return nil, fmt.Errorf("delta hydration failed: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(formatHydrationDelta(delta))), nil
+	}
 
 	s.logger.Info("🔄 Starting database hydration (async mode)...")
 
@@ -3086,10 +4488,13 @@ func (s *ForwardMCPService) hydrateDatabase(args HydrateDatabaseArgs) (*mcp.Tool
 					existingCommitIDs[query.Path] = query.LastCommit.ID
 				}
 			}
-			queries, err = s.forwardClient.GetNQEAllQueriesEnhancedWithCacheContext(ctx, existingCommitIDs)
+			queries, err = s.database.loadEnhancedSharded(ctx, s.forwardClient, s.logger, existingCommitIDs, args.Workers, args.RestartHydration)
 			if err != nil {
 				s.logger.Warn("🔄 Enhanced API failed, falling back to basic API: %v", err)
 				queries, err = s.database.loadFromBasicAPI(s.forwardClient, s.logger)
+			} else {
+				// Unchanged queries are not fetched again, so keep them from the database
+				queries = s.database.mergeQueries(existingQueries, queries)
 			}
 		} else {
 			queries, err = s.database.loadFromBasicAPI(s.forwardClient, s.logger)
@@ -3108,6 +4513,10 @@ func (s *ForwardMCPService) hydrateDatabase(args HydrateDatabaseArgs) (*mcp.Tool
 		if err := s.database.SetMetadata("last_sync", time.Now().Format(time.RFC3339)); err != nil {
 			s.logger.Warn("🔄 Failed to update sync time: %v", err)
 		}
+		// Index only the active queries; deprecated ones stay in the database until purged
+		if active, err := s.database.LoadQueries(); err == nil {
+			queries = active
+		}
 		s.logger.Info("🔄 Database hydration completed with %d queries", len(queries))
 		s.logger.Info("🔄 Refreshing query index after hydration...")
 		if s.queryIndex != nil {
@@ -3147,7 +4556,7 @@ func (s *ForwardMCPService) hydrateDatabase(args HydrateDatabaseArgs) (*mcp.Tool
 // refreshQueryIndex refreshes the query index from the current database content
 func (s *ForwardMCPService) refreshQueryIndex(args RefreshQueryIndexArgs) (*mcp.ToolResponse, error) {
 	if s.database == nil {
-		return nil, fmt.Errorf("database is not available")
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
 	}
 
 	if s.queryIndex == nil {
@@ -3197,6 +4606,20 @@ func (s *ForwardMCPService) getDatabaseStatus(args GetDatabaseStatusArgs) (*mcp.
 		// Get last sync time
 		if lastSync, err := s.database.GetMetadata("last_sync"); err == nil {
 			status["last_sync"] = lastSync
+			if synced, err := time.Parse(time.RFC3339, lastSync); err == nil {
+				status["last_sync"] = s.timeFormatter.Format(synced)
+				status["last_sync_ago"] = s.timeFormatter.Since(synced)
+			}
+		}
+
+		// Get deprecated query count
+		if deprecated, err := s.database.LoadDeprecatedQueries(); err == nil {
+			status["deprecated_query_count"] = len(deprecated)
+		}
+
+		// Get sharded hydration progress
+		if checkpoints, err := s.database.LoadHydrationCheckpoints(); err == nil && len(checkpoints) > 0 {
+			status["hydration"] = hydrationProgress(checkpoints)
 		}
 
 		// Get database path
@@ -3229,7 +4652,7 @@ func (s *ForwardMCPService) getDatabaseStatus(args GetDatabaseStatusArgs) (*mcp.
 // createEntity creates a new entity in the knowledge graph
 func (s *ForwardMCPService) createEntity(args CreateEntityArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	entity, err := s.memorySystem.CreateEntity(args.Name, args.Type, args.Metadata)
@@ -3248,7 +4671,7 @@ func (s *ForwardMCPService) createEntity(args CreateEntityArgs) (*mcp.ToolRespon
 // createRelation creates a relation between two entities
 func (s *ForwardMCPService) createRelation(args CreateRelationArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	relation, err := s.memorySystem.CreateRelation(args.FromID, args.ToID, args.Type, args.Properties)
@@ -3267,7 +4690,7 @@ func (s *ForwardMCPService) createRelation(args CreateRelationArgs) (*mcp.ToolRe
 // addObservation adds an observation to an entity
 func (s *ForwardMCPService) addObservation(args AddObservationArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	observation, err := s.memorySystem.AddObservation(args.EntityID, args.Content, args.Type, args.Metadata)
@@ -3286,7 +4709,7 @@ func (s *ForwardMCPService) addObservation(args AddObservationArgs) (*mcp.ToolRe
 // searchEntities searches for entities in the knowledge graph with automatic bloom filter optimization
 func (s *ForwardMCPService) searchEntities(args SearchEntitiesArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	// Check if we have bloom filters available for NQE result entities
@@ -3313,6 +4736,7 @@ func (s *ForwardMCPService) searchEntities(args SearchEntitiesArgs) (*mcp.ToolRe
 							if err != nil {
 								return nil, fmt.Errorf("failed to search entities after bloom filter: %w", err)
 							}
+							entities = callerEntities(args.Caller, entities)
 
 							response := fmt.Sprintf("🔍 Bloom filter search completed in %v!\n", searchResult.SearchTime)
 							response += fmt.Sprintf("📊 Found %d potential matches (bloom filter)\n", searchResult.MatchedCount)
@@ -3337,6 +4761,7 @@ func (s *ForwardMCPService) searchEntities(args SearchEntitiesArgs) (*mcp.ToolRe
 	if err != nil {
 		return nil, fmt.Errorf("failed to search entities: %w", err)
 	}
+	entities = callerEntities(args.Caller, entities)
 
 	if len(entities) == 0 {
 		return mcp.NewToolResponse(mcp.NewTextContent("No entities found matching the search criteria.")), nil
@@ -3353,7 +4778,7 @@ func (s *ForwardMCPService) searchEntities(args SearchEntitiesArgs) (*mcp.ToolRe
 // getEntity retrieves a specific entity by ID or name
 func (s *ForwardMCPService) getEntity(args GetEntityArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	entity, err := s.memorySystem.GetEntity(args.Identifier)
@@ -3372,7 +4797,7 @@ func (s *ForwardMCPService) getEntity(args GetEntityArgs) (*mcp.ToolResponse, er
 // getRelations retrieves relations for an entity
 func (s *ForwardMCPService) getRelations(args GetRelationsArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	// Get all relations from memory system
@@ -3397,6 +4822,7 @@ func (s *ForwardMCPService) getRelations(args GetRelationsArgs) (*mcp.ToolRespon
 	var relations []*Relation
 	var totalCount int
 	var hasMore bool
+	var entityID string
 
 	if args.AllResults {
 		// Store all relations in memory system for large datasets
@@ -3413,6 +4839,7 @@ func (s *ForwardMCPService) getRelations(args GetRelationsArgs) (*mcp.ToolRespon
 			"timestamp":     time.Now().Unix(),
 		})
 		if err == nil {
+			entityID = entity.ID
 			// Store the relations data
 			relationsJSON, _ := json.Marshal(relations)
 			s.memorySystem.AddObservation(entity.ID, string(relationsJSON), "data", map[string]interface{}{
@@ -3463,13 +4890,13 @@ func (s *ForwardMCPService) getRelations(args GetRelationsArgs) (*mcp.ToolRespon
 		responseText.WriteString(fmt.Sprintf("\n\n💾 Stored %d relations in memory system for future reference.", totalCount))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(responseText.String())), nil
+	return paginatedResponse(responseText.String(), listPagination(totalCount, offset, limit, len(relations), args.AllResults, entityID)), nil
 }
 
 // getObservations retrieves observations for an entity
 func (s *ForwardMCPService) getObservations(args GetObservationsArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	// Get all observations from memory system
@@ -3494,6 +4921,7 @@ func (s *ForwardMCPService) getObservations(args GetObservationsArgs) (*mcp.Tool
 	var observations []*Observation
 	var totalCount int
 	var hasMore bool
+	var entityID string
 
 	if args.AllResults {
 		// Store all observations in memory system for large datasets
@@ -3510,6 +4938,7 @@ func (s *ForwardMCPService) getObservations(args GetObservationsArgs) (*mcp.Tool
 			"timestamp":        time.Now().Unix(),
 		})
 		if err == nil {
+			entityID = entity.ID
 			// Store the observations data
 			observationsJSON, _ := json.Marshal(observations)
 			s.memorySystem.AddObservation(entity.ID, string(observationsJSON), "data", map[string]interface{}{
@@ -3560,13 +4989,13 @@ func (s *ForwardMCPService) getObservations(args GetObservationsArgs) (*mcp.Tool
 		responseText.WriteString(fmt.Sprintf("\n\n💾 Stored %d observations in memory system for future reference.", totalCount))
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(responseText.String())), nil
+	return paginatedResponse(responseText.String(), listPagination(totalCount, offset, limit, len(observations), args.AllResults, entityID)), nil
 }
 
 // deleteEntity deletes an entity and all its relations and observations
 func (s *ForwardMCPService) deleteEntity(args DeleteEntityArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	// Get entity details before deletion for confirmation
@@ -3574,19 +5003,89 @@ func (s *ForwardMCPService) deleteEntity(args DeleteEntityArgs) (*mcp.ToolRespon
 	if err != nil {
 		return nil, fmt.Errorf("entity not found: %w", err)
 	}
+	if s.memorySystem.IsPinned(entity.ID) {
+		return nil, fmt.Errorf("entity '%s' (%s) is pinned; release it with unpin_entity before deleting it", entity.Name, entity.ID)
+	}
+	relations, err := s.memorySystem.GetRelations(entity.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	observations, err := s.memorySystem.GetObservations(entity.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	impact := DeleteImpact{
+		Summary: fmt.Sprintf("Entity '%s' (%s) will be deleted.", entity.Name, entity.Type),
+		Details: []string{
+			fmt.Sprintf("%d relations to other entities", len(relations)),
+			fmt.Sprintf("%d observations, including any stored query result chunks", len(observations)),
+		},
+		Rows:       1 + len(relations) + len(observations),
+		Reversible: true,
+	}
+	if response, err := s.confirmDestructive("delete_entity", entity.ID, args.ConfirmationToken, impact); response != nil || err != nil {
+		return response, err
+	}
 
-	err = s.memorySystem.DeleteEntity(args.EntityID)
+	// Deleted entities stay in the trash for the retention period
+	s.purgeExpiredTrash()
+	trashed, err := s.memorySystem.TrashEntity(entity.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to delete entity: %w", err)
 	}
+	if s.analysisCache != nil {
+		s.analysisCache.remove(entity.ID)
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Entity '%s' (%s) deleted, including %d relations and %d observations. Undo with restore_entity entity_id=%s within %s.",
+		trashed.Name, trashed.Type, trashed.Relations, trashed.Observations, trashed.ID, formatRetention(s.trashRetention())))), nil
+}
+
+// restoreEntity undoes a recent delete_entity
+func (s *ForwardMCPService) restoreEntity(args RestoreEntityArgs) (*mcp.ToolResponse, error) {
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+
+	entity, relations, err := s.memorySystem.RestoreEntity(args.EntityID, s.trashRetention())
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Entity '%s' (%s) restored with %d relations and its observations.", entity.Name, entity.Type, relations))), nil
+}
+
+// listTrash shows deleted entities that can still be restored
+func (s *ForwardMCPService) listTrash(args ListTrashArgs) (*mcp.ToolResponse, error) {
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+
+	s.purgeExpiredTrash()
+	trashed, err := s.memorySystem.ListTrash(args.Limit)
+	if err != nil {
+		return nil, err
+	}
+	retention := s.trashRetention()
+	if len(trashed) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("The trash is empty. Deleted entities are kept for %s.", formatRetention(retention)))), nil
+	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Entity '%s' (%s) deleted successfully, including all its relations and observations.", entity.Name, entity.Type))), nil
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Trash (%d entities, kept for %s)\n\n", len(trashed), formatRetention(retention)))
+	text.WriteString("| Entity | Type | ID | Deleted | Relations | Observations | Size | Permanently deleted |\n|---|---|---|---|---|---|---|---|\n")
+	for _, entry := range trashed {
+		text.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %d | %d | %s | %s |\n", entry.Name, entry.Type, entry.ID,
+			s.timeFormatter.FormatWithAge(entry.DeletedAt), entry.Relations, entry.Observations, formatBytes(int64(entry.Bytes)),
+			s.timeFormatter.Since(entry.DeletedAt.Add(retention))))
+	}
+	text.WriteString("\nRestore an entity with restore_entity entity_id=<ID>.")
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
 }
 
 // deleteRelation deletes a specific relation
 func (s *ForwardMCPService) deleteRelation(args DeleteRelationArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	err := s.memorySystem.DeleteRelation(args.RelationID)
@@ -3600,7 +5099,7 @@ func (s *ForwardMCPService) deleteRelation(args DeleteRelationArgs) (*mcp.ToolRe
 // deleteObservation deletes a specific observation
 func (s *ForwardMCPService) deleteObservation(args DeleteObservationArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	err := s.memorySystem.DeleteObservation(args.ObservationID)
@@ -3614,13 +5113,15 @@ func (s *ForwardMCPService) deleteObservation(args DeleteObservationArgs) (*mcp.
 // getMemoryStats returns statistics about the memory system
 func (s *ForwardMCPService) getMemoryStats(args GetMemoryStatsArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
+	s.purgeExpiredTrash()
 	stats, err := s.memorySystem.GetMemoryStats()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get memory stats: %w", err)
 	}
+	stats["trash_retention_hours"] = int(s.trashRetention() / time.Hour)
 
 	statsJSON, err := json.MarshalIndent(stats, "", "  ")
 	if err != nil {
@@ -3649,10 +5150,18 @@ func (s *ForwardMCPService) getQueryAnalytics(args GetQueryAnalyticsArgs) (*mcp.
 	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Query analytics for network %s:\n%s", args.NetworkID, string(analyticsJSON)))), nil
 }
 
+// chunkTargetBytes returns the configured serialized size of stored result chunks
+func (s *ForwardMCPService) chunkTargetBytes() int {
+	if s.config != nil && s.config.Forward.ChunkTargetBytes > 0 {
+		return s.config.Forward.ChunkTargetBytes
+	}
+	return defaultChunkTargetBytes
+}
+
 // getNQEResultChunks retrieves chunked NQE query results from the memory system
 func (s *ForwardMCPService) getNQEResultChunks(args GetNQEResultChunksArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	entityID := args.EntityID
@@ -3670,6 +5179,90 @@ func (s *ForwardMCPService) getNQEResultChunks(args GetNQEResultChunksArgs) (*mc
 		return nil, fmt.Errorf("must provide either entity_id or (query_id, network_id, snapshot_id)")
 	}
 
+	// If a row range, filters or a projection are provided, return just those rows from the
+	// overlapping chunks
+	if args.StartRow != nil || args.EndRow != nil || len(args.Filters) > 0 || len(args.Columns) > 0 || len(args.GroupBy) > 0 {
+		if args.ChunkIndex != nil {
+			return nil, fmt.Errorf("chunk_index cannot be combined with start_row/end_row, filters, columns or group_by")
+		}
+		first, last := 0, math.MaxInt
+		if args.StartRow != nil {
+			first = *args.StartRow
+		}
+		if args.EndRow != nil {
+			last = *args.EndRow
+		}
+		if first < 0 || last < first {
+			return nil, fmt.Errorf("invalid row range %d-%d", first, last)
+		}
+		filters, err := validateResultRowFilters(args.Filters)
+		if err != nil {
+			return nil, err
+		}
+		limit := args.Limit
+		if limit <= 0 {
+			limit = defaultFilteredRowLimit
+		}
+
+		var rollup *ResultRollup
+		if len(args.GroupBy) > 0 {
+			if rollup, err = newResultRollup(args.GroupBy, args.GroupExamples); err != nil {
+				return nil, err
+			}
+			rollup.EntityID = entityID
+		}
+
+		// Filtered and projected retrievals are capped; a plain row range returns what it asks for
+		capped := len(filters) > 0 || len(args.Columns) > 0
+		rows := []map[string]interface{}{}
+		matched := 0
+		totalRows, err := s.memorySystem.ScanNQEResultRows(entityID, first, last, func(index int, row map[string]interface{}) bool {
+			if !rowMatchesFilters(row, filters) {
+				return true
+			}
+			matched++
+			if rollup != nil {
+				// Grouping sees every matching row; the projection applies to the examples
+				projected := projectRow(row, args.Columns)
+				for _, column := range rollup.GroupBy {
+					projected[column] = row[column]
+				}
+				rollup.Add(projected)
+				return true
+			}
+			if capped && len(rows) >= limit {
+				return true
+			}
+			rows = append(rows, projectRow(row, args.Columns))
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve result rows: %w", err)
+		}
+		if first >= totalRows {
+			return nil, fmt.Errorf("start_row %d out of range (total rows: %d)", first, totalRows)
+		}
+		if last >= totalRows {
+			last = totalRows - 1
+		}
+		if rollup != nil {
+			return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Rows %d-%d of %d (%d matched).\n%s", first, last, totalRows, matched, rollup.String()))), nil
+		}
+		response := map[string]interface{}{
+			"entity_id":  entityID,
+			"row_range":  []int{first, last},
+			"total_rows": totalRows,
+			"rows":       rows,
+		}
+		if len(filters) > 0 {
+			response["matched_rows"] = matched
+		}
+		if capped {
+			response["truncated"] = matched > len(rows)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(response))), nil
+	}
+
 	chunks, err := s.memorySystem.GetNQEResultChunks(entityID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve result chunks: %w", err)
@@ -3693,7 +5286,7 @@ func (s *ForwardMCPService) getNQEResultChunks(args GetNQEResultChunksArgs) (*mc
 // Arguments: entity_id OR (query_id, network_id, snapshot_id)
 func (s *ForwardMCPService) getNQEResultSummary(args GetNQEResultChunksArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 	entityID := args.EntityID
 	if entityID == "" && args.QueryID != "" && args.NetworkID != "" && args.SnapshotID != "" {
@@ -3714,6 +5307,9 @@ func (s *ForwardMCPService) getNQEResultSummary(args GetNQEResultChunksArgs) (*m
 	}
 
 	response := fmt.Sprintf("NQE result summary for entity %s:\n%s", entityID, obs[0].Content)
+	if stored := s.storedResultSummary(entityID, ""); stored != nil {
+		response += fmt.Sprintf("\n\nSummary (%s, %s):\n%s", resultValueString(stored.Metadata["model"]), s.timeFormatter.Format(stored.CreatedAt), stored.Content)
+	}
 
 	// Check if bloom filter is available for this data
 	if s.bloomManager != nil {
@@ -3745,104 +5341,73 @@ type AnalyzeNQEResultSQLArgs struct {
 
 func (s *ForwardMCPService) analyzeNQEResultSQL(args AnalyzeNQEResultSQLArgs) (*mcp.ToolResponse, error) {
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 	if args.EntityID == "" || args.SQLQuery == "" {
 		return nil, fmt.Errorf("entity_id and sql_query are required")
 	}
-	// Get all chunks for the entity
-	chunks, err := s.memorySystem.GetNQEResultChunks(args.EntityID)
+	if _, err := validateSandboxSQL(args.SQLQuery); err != nil {
+		return nil, err
+	}
+	// Run the query in the sandbox (limit to 100 rows)
+	resultRows, truncated, dbStatus, err := s.queryStoredResult(args.EntityID, args.SQLQuery, 100)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve result chunks: %w", err)
+		return nil, err
 	}
-	if len(chunks) == 0 {
-		return nil, fmt.Errorf("no data found for entity %s", args.EntityID)
+	resultJSON, _ := json.MarshalIndent(resultRows, "", "  ")
+	response := fmt.Sprintf("SQL query result (%d rows, max 100 shown; analysis database %s):\n%s", len(resultRows), dbStatus, string(resultJSON))
+	if truncated {
+		response += "\n\nMore rows are available; add filters, aggregation or LIMIT/OFFSET to see them."
 	}
-	// Parse all rows from all chunks
-	var allRows []map[string]interface{}
-	for _, chunk := range chunks {
-		var rows []map[string]interface{}
-		if err := json.Unmarshal([]byte(chunk), &rows); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal chunk: %w", err)
+	return s.streamResponse("analyze_nqe_result_sql", response), nil
+}
+
+// queryStoredResult runs a sandboxed SQL query against a stored NQE result entity and
+// returns at most limit rows along with how the analysis database was obtained
+func (s *ForwardMCPService) queryStoredResult(entityID, sqlQuery string, limit int) ([]map[string]interface{}, bool, string, error) {
+	sandbox := s.sqlSandbox()
+	loadChunks := func() ([]string, error) {
+		chunks, err := s.memorySystem.GetNQEResultChunks(entityID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve result chunks: %w", err)
 		}
-		allRows = append(allRows, rows...)
-	}
-	if len(allRows) == 0 {
-		return nil, fmt.Errorf("no rows found for entity %s", args.EntityID)
+		if len(chunks) == 0 {
+			return nil, fmt.Errorf("no data found for entity %s", entityID)
+		}
+		return chunks, nil
 	}
-	// Create in-memory SQLite DB
-	db, err := sql.Open("sqlite3", ":memory:")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create in-memory sqlite db: %w", err)
-	}
-	defer db.Close()
-	// Infer columns from first row
-	firstRow := allRows[0]
-	var columns []string
-	for k := range firstRow {
-		columns = append(columns, k)
-	}
-	// Create table
-	tableCols := ""
-	for i, col := range columns {
-		if i > 0 {
-			tableCols += ", "
-		}
-		tableCols += fmt.Sprintf("%s TEXT", col)
-	}
-	tableName := "nqe_result"
-	createStmt := fmt.Sprintf("CREATE TABLE %s (%s);", tableName, tableCols)
-	_, err = db.Exec(createStmt)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create table: %w", err)
-	}
-	// Insert rows
-	insertStmt := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", tableName, strings.Join(columns, ", "), strings.TrimRight(strings.Repeat("?,", len(columns)), ","))
-	for _, row := range allRows {
-		vals := make([]interface{}, len(columns))
-		for i, col := range columns {
-			if v, ok := row[col]; ok {
-				vals[i] = fmt.Sprintf("%v", v)
-			} else {
-				vals[i] = nil
-			}
+
+	// Reuse the entity's cached analysis database when the result has not changed
+	if s.analysisCache != nil {
+		version, err := s.memorySystem.NQEResultVersion(entityID)
+		if err != nil {
+			return nil, false, "", err
 		}
-		_, err := db.Exec(insertStmt, vals...)
+		buildStart := time.Now()
+		path, built, err := s.analysisCache.ensure(entityID, version, func(path string) error {
+			chunks, err := loadChunks()
+			if err != nil {
+				return err
+			}
+			return sandbox.BuildFile(path, chunks)
+		})
 		if err != nil {
-			return nil, fmt.Errorf("failed to insert row: %w", err)
+			return nil, false, "", err
 		}
+		dbStatus := "cached"
+		if built {
+			dbStatus = fmt.Sprintf("built in %s", time.Since(buildStart).Round(time.Millisecond))
+		}
+		rows, truncated, err := sandbox.QueryFile(path, sqlQuery, limit)
+		return rows, truncated, dbStatus, err
 	}
-	// Run the query (limit to 100 rows)
-	query := args.SQLQuery
-	if !strings.Contains(strings.ToLower(query), "limit") {
-		query += " LIMIT 100"
-	}
-	rows, err := db.Query(query)
+
+	chunks, err := loadChunks()
 	if err != nil {
-		return nil, fmt.Errorf("SQL query error: %w", err)
-	}
-	defer rows.Close()
-	// Read results
-	resultRows := []map[string]interface{}{}
-	cols, _ := rows.Columns()
-	for rows.Next() {
-		vals := make([]interface{}, len(cols))
-		valPtrs := make([]interface{}, len(cols))
-		for i := range vals {
-			valPtrs[i] = &vals[i]
-		}
-		if err := rows.Scan(valPtrs...); err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
-		rowMap := map[string]interface{}{}
-		for i, col := range cols {
-			rowMap[col] = vals[i]
-		}
-		resultRows = append(resultRows, rowMap)
+		return nil, false, "", err
 	}
-	resultJSON, _ := json.MarshalIndent(resultRows, "", "  ")
-	response := fmt.Sprintf("SQL query result (%d rows, max 100 shown):\n%s", len(resultRows), string(resultJSON))
-	return mcp.NewToolResponse(mcp.NewTextContent(response)), nil
+	rows, truncated, err := sandbox.Query(chunks, sqlQuery, limit)
+	return rows, truncated, "in-memory", err
 }
 
 // buildBloomFilter builds a bloom filter from NQE query results
@@ -3915,7 +5480,7 @@ func (s *ForwardMCPService) searchBloomFilter(args SearchBloomFilterArgs) (*mcp.
 	}
 
 	if s.memorySystem == nil {
-		return nil, fmt.Errorf("memory system is not available")
+		return nil, newCodedError(CodeMemoryUnavailable)
 	}
 
 	// Use defaults if not specified
@@ -4022,7 +5587,7 @@ func (s *ForwardMCPService) getBloomFilterStats(args GetBloomFilterStatsArgs) (*
 			"- Chunks: %d\n\n",
 			key, metadata.NetworkID, metadata.FilterType, metadata.ItemCount,
 			metadata.MemoryUsage, metadata.FalsePositiveRate*100,
-			metadata.LastUpdated.Format("2006-01-02 15:04:05"), metadata.ChunkCount)
+			s.timeFormatter.FormatWithAge(metadata.LastUpdated), metadata.ChunkCount)
 	}
 
 	response += "**Performance Benefits:**\n" +
@@ -4126,22 +5691,29 @@ func formatBytes(bytes int64) string {
 
 func (s *ForwardMCPService) pathSearchWorkflow(args PathSearchWorkflowArgs) (*mcp.ToolResponse, error) {
 	sessionID := fmt.Sprintf("path_session_%v", args.SessionID)
-	state := s.workflowManager.GetState(sessionID)
+	step, err := s.resolveWorkflowStep(workflowPathSearch, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
 
-	switch state.CurrentStep {
-	case "start":
-		return s.startPathSearchWorkflow(sessionID)
+	var response *mcp.ToolResponse
+	switch step {
 	case "explain_best_practices":
-		return s.explainPathSearchBestPractices(sessionID)
+		response, err = s.explainPathSearchBestPractices(sessionID)
 	case "show_bulk_example":
-		return s.showBulkPathSearchExample(sessionID)
+		response, err = s.showBulkPathSearchExample(sessionID)
 	case "guide_request_building":
-		return s.guidePathSearchRequestBuilding(sessionID)
+		response, err = s.guidePathSearchRequestBuilding(sessionID)
 	case "network_scope_discovery":
-		return s.guideNetworkScopeDiscovery(sessionID)
+		response, err = s.guideNetworkScopeDiscovery(sessionID)
 	default:
-		return s.startPathSearchWorkflow(sessionID)
+		step = "start"
+		response, err = s.startPathSearchWorkflow(sessionID)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return s.finishWorkflowStep(workflowPathSearch, sessionID, step, response), nil
 }
 
 func (s *ForwardMCPService) startPathSearchWorkflow(sessionID string) (*mcp.ToolResponse, error) {
@@ -4398,6 +5970,15 @@ func NormalizePathSearchRequest(input map[string]interface{}) (isBulk bool, bulk
 	if v, ok := input["include_network_functions"].(bool); ok {
 		bulkArgs.IncludeNetworkFunctions = v
 	}
+	if v, ok := input["analyze_ecmp"].(bool); ok {
+		bulkArgs.AnalyzeECMP = v
+	}
+	if v, ok := input["expand_underlay"].(bool); ok {
+		bulkArgs.ExpandUnderlay = v
+	}
+	if v, ok := input["verify_with_probes"].(bool); ok {
+		bulkArgs.VerifyWithProbes = v
+	}
 
 	// Check if this is a bulk request with queries array
 	if queries, ok := input["queries"]; ok {
@@ -4467,6 +6048,9 @@ func (s *ForwardMCPService) searchPathsEntry(args SearchPathsArgs) (*mcp.ToolRes
 		MaxReturnPathResults:    args.MaxReturnPathResults,
 		MaxSeconds:              args.MaxSeconds,
 		IncludeNetworkFunctions: args.IncludeNetworkFunctions,
+		AnalyzeECMP:             args.AnalyzeECMP,
+		ExpandUnderlay:          args.ExpandUnderlay,
+		VerifyWithProbes:        args.VerifyWithProbes,
 		Queries: []PathSearchQueryArgs{
 			{
 				From:    args.From,
@@ -4490,20 +6074,27 @@ func (s *ForwardMCPService) searchPathsBulkEntry(args SearchPathsBulkArgs) (*mcp
 
 func (s *ForwardMCPService) networkPrefixDiscoveryWorkflow(args NetworkPrefixDiscoveryArgs) (*mcp.ToolResponse, error) {
 	sessionID := fmt.Sprintf("session_%v", args.SessionID)
-	state := s.workflowManager.GetState(sessionID)
+	step, err := s.resolveWorkflowStep(workflowNetworkPrefixDiscover, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
 
-	switch state.CurrentStep {
-	case "start":
-		return s.startNetworkPrefixDiscovery(sessionID)
+	var response *mcp.ToolResponse
+	switch step {
 	case "explain_process":
-		return s.explainNetworkPrefixProcess(sessionID)
+		response, err = s.explainNetworkPrefixProcess(sessionID)
 	case "show_example":
-		return s.showNetworkPrefixExample(sessionID)
+		response, err = s.showNetworkPrefixExample(sessionID)
 	case "guide_analysis":
-		return s.guideNetworkPrefixAnalysis(sessionID)
+		response, err = s.guideNetworkPrefixAnalysis(sessionID)
 	default:
-		return s.startNetworkPrefixDiscovery(sessionID)
+		step = "start"
+		response, err = s.startNetworkPrefixDiscovery(sessionID)
+	}
+	if err != nil {
+		return nil, err
 	}
+	return s.finishWorkflowStep(workflowNetworkPrefixDiscover, sessionID, step, response), nil
 }
 
 func (s *ForwardMCPService) startNetworkPrefixDiscovery(sessionID string) (*mcp.ToolResponse, error) {
@@ -4698,6 +6289,13 @@ func (s *ForwardMCPService) guideNetworkPrefixAnalysis(sessionID string) (*mcp.T
 
 func (s *ForwardMCPService) analyzeNetworkPrefixes(args NetworkPrefixAnalysisArgs) (*mcp.ToolResponse, error) {
 	s.logToolCall("analyze_network_prefixes", args, nil)
+	if err := validateReportFormat(args.ReportFormat); err != nil {
+		return nil, err
+	}
+	// Saving a baseline writes to the memory system, which read-only keys may not do
+	if args.SaveBaseline && args.Caller != nil && args.Caller.ReadOnly {
+		return nil, fmt.Errorf("API key %s is read-only and cannot save a connectivity baseline; call analyze_network_prefixes without save_baseline", args.Caller.ID)
+	}
 
 	// Use defaults if not specified
 	networkID := s.getNetworkID(args.NetworkID)
@@ -4707,7 +6305,7 @@ func (s *ForwardMCPService) analyzeNetworkPrefixes(args NetworkPrefixAnalysisArg
 	// Default prefix levels if not specified
 	prefixLevels := args.PrefixLevels
 	if len(prefixLevels) == 0 {
-		prefixLevels = []string{"/8", "/16", "/24"}
+		prefixLevels = []string{"/8", "/16", "/24", "/48", "/64"}
 	}
 
 	// Default intent if not specified
@@ -4725,9 +6323,20 @@ func (s *ForwardMCPService) analyzeNetworkPrefixes(args NetworkPrefixAnalysisArg
 		s.logger.Error("Failed to discover network prefixes: %v", err)
 		return nil, fmt.Errorf("failed to discover network prefixes: %w", err)
 	}
+	s.annotatePrefixInfo(networkID, prefixInfo)
+
+	// Tags name groups of devices on either side
+	fromDevices, err := s.expandDeviceTags(networkID, args.FromDevices, args.FromTags)
+	if err != nil {
+		return nil, err
+	}
+	toDevices, err := s.expandDeviceTags(networkID, args.ToDevices, args.ToTags)
+	if err != nil {
+		return nil, err
+	}
 
 	// Step 2: Analyze connectivity between prefixes
-	connectivityResults, err := s.analyzePrefixConnectivity(networkID, prefixInfo, prefixLevels, args.FromDevices, args.ToDevices, intent, maxResults)
+	connectivityResults, err := s.analyzePrefixConnectivity(networkID, prefixInfo, prefixLevels, fromDevices, toDevices, intent, maxResults)
 	if err != nil {
 		s.logger.Error("Failed to analyze prefix connectivity: %v", err)
 		return nil, fmt.Errorf("failed to analyze prefix connectivity: %w", err)
@@ -4735,93 +6344,56 @@ func (s *ForwardMCPService) analyzeNetworkPrefixes(args NetworkPrefixAnalysisArg
 
 	// Step 3: Generate comprehensive report
 	report := s.generateConnectivityReport(prefixInfo, connectivityResults, prefixLevels)
+	report += s.saveReport(prefixAnalysisReport(networkID, prefixInfo, connectivityResults, prefixLevels), "prefix_analysis_"+networkID, args.ReportFormat)
+	if args.SaveBaseline {
+		summary, err := s.saveConnectivityBaseline(networkID, snapshotID, firstNonEmpty(args.BaselineName, defaultBaselineName), prefixLevels)
+		if err != nil {
+			summary = fmt.Sprintf("\n⚠️ Connectivity baseline not saved: %v\n", err)
+		}
+		report += summary
+	}
 
 	// Track analysis in memory system (placeholder for future implementation)
 	if s.apiTracker != nil {
 		s.logger.Debug("Network analysis completed - would track in memory system")
 	}
 
-	return mcp.NewToolResponse(mcp.NewTextContent(report)), nil
+	return s.streamResponse("analyze_network_prefixes", report), nil
 }
 
 func (s *ForwardMCPService) discoverNetworkPrefixes(networkID, snapshotID string) ([]NetworkPrefixInfo, error) {
-	// Use device inventory to discover all interface IPs and aggregate to prefixes
-	params := &forward.DeviceQueryParams{}
-	if snapshotID != "" {
-		params.SnapshotID = snapshotID
-	}
-	devicesResp, err := s.forwardClient.GetDevices(networkID, params)
+	// Use the persisted interface/prefix index, built from the device inventory when stale
+	entries, err := s.prefixIndexEntries(networkID, snapshotID, false)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get device inventory: %w", err)
+		return nil, err
 	}
 
 	// Group devices by location to identify location-based network scopes
-	locationDevices := make(map[string][]string)
-	deviceIPs := make(map[string][]string)                   // device -> IPs
+	locationDevices := make(map[string]map[string]bool)      // location -> devices
 	locationPrefixes := make(map[string]map[string][]string) // location -> prefix -> devices
 
-	for _, device := range devicesResp.Devices {
-		location := device.LocationID
-		if location == "" {
-			location = "unknown"
+	for _, entry := range entries {
+		location := entry.Location
+		if locationDevices[location] == nil {
+			locationDevices[location] = make(map[string]bool)
 		}
+		locationDevices[location][entry.Device] = true
 
-		locationDevices[location] = append(locationDevices[location], device.Name)
-
-		var deviceIPList []string
-		for _, iface := range device.Interfaces {
-			if iface.IPAddress == "" {
-				continue
-			}
-
-			// Parse the IP address
-			ip, _, err := net.ParseCIDR(iface.IPAddress)
-			if err != nil {
-				// Try to parse as plain IP and synthesize /32
-				ip = net.ParseIP(iface.IPAddress)
-				if ip == nil {
-					s.logger.Warn("Could not parse interface IP: %s on device %s", iface.IPAddress, device.Name)
-					continue
-				}
-			}
-
-			deviceIPList = append(deviceIPList, iface.IPAddress)
-
-			// Create different aggregation levels for this IP
-			aggregationLevels := []int{8, 16, 24} // /8, /16, /24 for IPv4
-			if ip.To4() == nil {
-				aggregationLevels = []int{32, 48, 64} // /32, /48, /64 for IPv6
-			}
-
-			for _, level := range aggregationLevels {
-				var mask net.IPMask
-				if ip.To4() != nil {
-					mask = net.CIDRMask(level, 32)
-				} else {
-					mask = net.CIDRMask(level, 128)
-				}
-
-				aggNet := &net.IPNet{IP: ip.Mask(mask), Mask: mask}
-				prefix := aggNet.String()
-
-				if locationPrefixes[location] == nil {
-					locationPrefixes[location] = make(map[string][]string)
-				}
+		if locationPrefixes[location] == nil {
+			locationPrefixes[location] = make(map[string][]string)
+		}
 
-				// Add device to this prefix in this location
-				found := false
-				for _, dev := range locationPrefixes[location][prefix] {
-					if dev == device.Name {
-						found = true
-						break
-					}
-				}
-				if !found {
-					locationPrefixes[location][prefix] = append(locationPrefixes[location][prefix], device.Name)
-				}
+		// Add device to this prefix in this location
+		found := false
+		for _, dev := range locationPrefixes[location][entry.Prefix] {
+			if dev == entry.Device {
+				found = true
+				break
 			}
 		}
-		deviceIPs[device.Name] = deviceIPList
+		if !found {
+			locationPrefixes[location][entry.Prefix] = append(locationPrefixes[location][entry.Prefix], entry.Device)
+		}
 	}
 
 	// Create NetworkPrefixInfo for each location-prefix combination
@@ -4829,8 +6401,13 @@ func (s *ForwardMCPService) discoverNetworkPrefixes(networkID, snapshotID string
 
 	for location, prefixMap := range locationPrefixes {
 		for prefix, devices := range prefixMap {
-			// Only include prefixes that have multiple devices or are significant
-			if len(devices) > 1 || strings.HasSuffix(prefix, "/8") || strings.HasSuffix(prefix, "/16") {
+			// Only include prefixes that have multiple devices or are significant summary levels
+			significant := false
+			if _, ipNet, err := net.ParseCIDR(prefix); err == nil {
+				length, _ := ipNet.Mask.Size()
+				significant = isSignificantAggregate(ipFamily(ipNet.IP), length)
+			}
+			if len(devices) > 1 || significant {
 				info := NetworkPrefixInfo{
 					Prefix:     prefix,
 					Device:     devices[0], // Representative device
@@ -4890,7 +6467,7 @@ func (s *ForwardMCPService) analyzePrefixConnectivity(networkID string, prefixIn
 				ToDevice:         s.findRepresentativeDevice(query.DstIP, toDevices),
 				Connectivity:     "ANALYSIS_FAILED",
 				PathCount:        0,
-				AggregationLevel: s.determineAggregationLevel(query.SrcIP),
+				AggregationLevel: s.determineAggregationLevel(query.DstIP),
 			}
 			results = append(results, result)
 		}
@@ -4932,20 +6509,22 @@ func (s *ForwardMCPService) createConnectivityQueries(prefixInfo []NetworkPrefix
 		// Get aggregated prefixes for this level
 		aggregatedPrefixes := s.aggregatePrefixes(prefixInfo, level)
 
-		// Create connectivity tests between prefixes
+		// Create connectivity tests between prefixes of the same address family
 		for i, fromPrefix := range aggregatedPrefixes {
 			for j, toPrefix := range aggregatedPrefixes {
-				if i != j { // Don't test connectivity to self
-					// Find representative devices for each prefix
-					fromDevice := s.findRepresentativeDevice(fromPrefix, fromDevices)
-					// toDevice := s.findRepresentativeDevice(toPrefix, toDevices) // Not used in current implementation
-
-					if fromDevice != "" {
-						queries = append(queries, PathSearchQueryArgs{
-							From:  fromDevice,
-							DstIP: toPrefix,
-						})
-					}
+				if i == j || prefixFamily(fromPrefix) != prefixFamily(toPrefix) {
+					continue // Don't test connectivity to self or across IPv4/IPv6
+				}
+
+				// Find representative devices for each prefix
+				fromDevice := s.findRepresentativeDevice(fromPrefix, fromDevices)
+				// toDevice := s.findRepresentativeDevice(toPrefix, toDevices) // Not used in current implementation
+
+				if fromDevice != "" {
+					queries = append(queries, PathSearchQueryArgs{
+						From:  fromDevice,
+						DstIP: toPrefix,
+					})
 				}
 			}
 		}
@@ -4972,18 +6551,9 @@ func (s *ForwardMCPService) aggregatePrefixes(prefixInfo []NetworkPrefixInfo, le
 }
 
 func (s *ForwardMCPService) extractNetworkPortion(prefix, level string) string {
-	// Simple implementation - extract network portion based on CIDR level
-	// In a real implementation, this would use proper IP network calculations
-	if strings.Contains(prefix, "/") {
-		parts := strings.Split(prefix, "/")
-		if len(parts) == 2 {
-			ip := parts[0]
-			// For now, return the IP with the requested level
-			// This is a simplified version - real implementation would calculate proper network addresses
-			return fmt.Sprintf("%s%s", ip, level)
-		}
-	}
-	return prefix
+	// Re-aggregate the prefix to the requested CIDR level; levels that don't apply
+	// to the prefix's address family (e.g. /48 for IPv4) yield no network
+	return maskPrefixToLevel(prefix, level)
 }
 
 func (s *ForwardMCPService) findRepresentativeDevice(prefix string, preferredDevices []string) string {
@@ -5002,14 +6572,7 @@ func (s *ForwardMCPService) findRepresentativeDevice(prefix string, preferredDev
 }
 
 func (s *ForwardMCPService) determineAggregationLevel(prefix string) string {
-	if strings.Contains(prefix, "/8") {
-		return "/8"
-	} else if strings.Contains(prefix, "/16") {
-		return "/16"
-	} else if strings.Contains(prefix, "/24") {
-		return "/24"
-	}
-	return "/32"
+	return prefixLevelOf(prefix)
 }
 
 func (s *ForwardMCPService) generateConnectivityReport(prefixInfo []NetworkPrefixInfo, connectivityResults []ConnectivityAnalysisResult, prefixLevels []string) string {
@@ -5023,6 +6586,10 @@ func (s *ForwardMCPService) generateConnectivityReport(prefixInfo []NetworkPrefi
 
 	report.WriteString("### Device-to-Prefix Mappings:\n")
 	for _, info := range prefixInfo {
+		if info.Ownership != "" {
+			report.WriteString(fmt.Sprintf("- **%s** → %s (Location: %s, Owner: %s)\n", info.Device, info.Prefix, info.Location, info.Ownership))
+			continue
+		}
 		report.WriteString(fmt.Sprintf("- **%s** → %s (Location: %s)\n", info.Device, info.Prefix, info.Location))
 	}
 	report.WriteString("\n")
@@ -5128,19 +6695,22 @@ func (s *ForwardMCPService) resolveDeviceToIP(networkID, deviceOrIP string) (str
 	s.logger.Debug("[%s] Resolving device name to IP: %s", resolutionID, deviceOrIP)
 
 	// Get devices and find the one with matching name
-	devices, err := s.forwardClient.GetDevices(networkID, &forward.DeviceQueryParams{})
+	devices, err := s.getNetworkDevices(networkID, "")
 	if err != nil {
 		return "", fmt.Errorf("failed to get devices for network %s: %w", networkID, err)
 	}
 
-	if devices == nil || len(devices.Devices) == 0 {
+	if len(devices) == 0 {
 		return "", fmt.Errorf("no devices found in network %s", networkID)
 	}
 
+	// Follow recorded renames so former device names keep resolving
+	deviceOrIP = s.canonicalDeviceName(networkID, deviceOrIP, devices)
+
 	// Find device by name
-	s.logger.Debug("Searching through %d devices for device name: %s", len(devices.Devices), deviceOrIP)
+	s.logger.Debug("Searching through %d devices for device name: %s", len(devices), deviceOrIP)
 	foundDevice := false
-	for _, device := range devices.Devices {
+	for _, device := range devices {
 		s.logger.Debug("Checking device: %s (management IPs: %v, interface count: %d)",
 			device.Name, device.ManagementIPs, len(device.Interfaces))
 
@@ -5152,7 +6722,7 @@ func (s *ForwardMCPService) resolveDeviceToIP(networkID, deviceOrIP string) (str
 			if len(device.ManagementIPs) > 0 {
 				// Check if this management IP is already used by another device
 				ipUsedBy := []string{}
-				for _, otherDevice := range devices.Devices {
+				for _, otherDevice := range devices {
 					if otherDevice.Name != deviceOrIP {
 						for _, mgmtIP := range otherDevice.ManagementIPs {
 							if mgmtIP == device.ManagementIPs[0] {
@@ -5178,7 +6748,7 @@ func (s *ForwardMCPService) resolveDeviceToIP(networkID, deviceOrIP string) (str
 				if iface.IPAddress != "" {
 					// Check if this interface IP is already used by another device
 					ipUsedBy := []string{}
-					for _, otherDevice := range devices.Devices {
+					for _, otherDevice := range devices {
 						if otherDevice.Name != deviceOrIP {
 							for _, otherIface := range otherDevice.Interfaces {
 								if otherIface.IPAddress == iface.IPAddress {
@@ -5207,15 +6777,15 @@ func (s *ForwardMCPService) resolveDeviceToIP(networkID, deviceOrIP string) (str
 	if !foundDevice {
 		s.logger.Warn("Device %s not found in network %s", deviceOrIP, networkID)
 		// Log some available device names for debugging
-		deviceNames := make([]string, 0, len(devices.Devices))
-		for _, device := range devices.Devices {
+		deviceNames := make([]string, 0, len(devices))
+		for _, device := range devices {
 			deviceNames = append(deviceNames, device.Name)
 		}
 		s.logger.Debug("Available devices in network: %v", deviceNames)
 	} else {
 		// Log IP conflict summary for all devices
 		ipConflictMap := make(map[string][]string)
-		for _, device := range devices.Devices {
+		for _, device := range devices {
 			for _, mgmtIP := range device.ManagementIPs {
 				ipConflictMap[mgmtIP] = append(ipConflictMap[mgmtIP], device.Name)
 			}
@@ -5242,7 +6812,7 @@ func (s *ForwardMCPService) listInstanceIDs(args ListInstanceIDsArgs) (*mcp.Tool
 	s.logToolCall("list_instance_ids", args, nil)
 
 	if s.database == nil {
-		return nil, fmt.Errorf("database is not available")
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
 	}
 
 	instances, err := s.database.GetAllInstanceIDs()
@@ -5261,8 +6831,8 @@ func (s *ForwardMCPService) listInstanceIDs(args ListInstanceIDsArgs) (*mcp.Tool
 	for i, instance := range instances {
 		responseText.WriteString(fmt.Sprintf("%d. **Instance ID: %s**\n", i+1, instance.ID))
 		responseText.WriteString(fmt.Sprintf("   - Query Count: %d\n", instance.QueryCount))
-		responseText.WriteString(fmt.Sprintf("   - First Sync: %s\n", instance.FirstSync.Format("2006-01-02 15:04:05")))
-		responseText.WriteString(fmt.Sprintf("   - Last Sync: %s\n", instance.LastSync.Format("2006-01-02 15:04:05")))
+		responseText.WriteString(fmt.Sprintf("   - First Sync: %s\n", s.timeFormatter.Format(instance.FirstSync)))
+		responseText.WriteString(fmt.Sprintf("   - Last Sync: %s\n", s.timeFormatter.FormatWithAge(instance.LastSync)))
 		responseText.WriteString("\n")
 	}
 
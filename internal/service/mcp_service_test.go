@@ -30,6 +30,7 @@ type MockForwardClient struct {
 	deviceLocations map[string]string
 	pathResponse    *forward.PathSearchResponse
 	nqeResult       *forward.NQERunResult
+	checks          map[string][]forward.NetworkCheck // Checks by snapshot ID
 	shouldError     bool
 	errorMessage    string
 }
@@ -340,6 +341,32 @@ func (m *MockForwardClient) DeleteSnapshot(snapshotID string) error {
 	return nil
 }
 
+func (m *MockForwardClient) GetChecks(snapshotID string, params *forward.CheckQueryParams) ([]forward.NetworkCheck, error) {
+	if m.shouldError {
+		return nil, &MockError{m.errorMessage}
+	}
+	var checks []forward.NetworkCheck
+	for _, check := range m.checks[snapshotID] {
+		if params != nil && params.Status != "" && check.Status != params.Status {
+			continue
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+func (m *MockForwardClient) GetCheck(snapshotID, checkID string) (*forward.NetworkCheck, error) {
+	if m.shouldError {
+		return nil, &MockError{m.errorMessage}
+	}
+	for _, check := range m.checks[snapshotID] {
+		if check.ID == checkID {
+			return &check, nil
+		}
+	}
+	return nil, &MockError{"unexpected status code: 404"}
+}
+
 func (m *MockForwardClient) GetLocations(networkID string) ([]forward.Location, error) {
 	if m.shouldError {
 		return nil, &MockError{m.errorMessage}
@@ -534,6 +561,13 @@ func (e *MockError) Error() string {
 	return e.Message
 }
 
+// uniqueTestName appends a per-run suffix to prefix. The memory system of createTestService
+// persists between test runs, so entity names, types and query IDs that must not meet data
+// from earlier runs are made unique with it.
+func uniqueTestName(prefix string) string {
+	return fmt.Sprintf("%s%d", prefix, time.Now().UnixNano())
+}
+
 // Helper function for tests
 func createTestService() *ForwardMCPService {
 	cfg := &config.Config{
@@ -585,6 +619,7 @@ func createTestService() *ForwardMCPService {
 		}(),
 		bloomManager:      NewBloomSearchManager(logger, "test"),
 		bloomIndexManager: NewBloomIndexManager(logger, "/tmp"),
+		pathCache:         NewPathSearchCache(100, time.Hour, time.Minute),
 		ctx:               ctx,
 		cancelFunc:        cancel,
 	}
@@ -605,8 +640,9 @@ func TestListNetworks(t *testing.T) {
 		t.Fatal("Expected response, got nil")
 	}
 
-	if len(response.Content) != 1 {
-		t.Fatalf("Expected 1 content item, got: %d", len(response.Content))
+	// The listing is followed by its pagination metadata
+	if len(response.Content) != 2 {
+		t.Fatalf("Expected 2 content items, got: %d", len(response.Content))
 	}
 
 	content := response.Content[0].TextContent.Text
@@ -1146,6 +1182,19 @@ func (m *MockForwardClient) GetNQEAllQueriesEnhancedWithCache(existingCommitIDs
 	return m.GetNQEAllQueriesEnhanced()
 }
 
+func (m *MockForwardClient) GetNQEQuerySummariesContext(ctx context.Context, repository string) ([]forward.NQEOrgQuerySummary, error) {
+	if m.shouldError {
+		return nil, &MockError{m.errorMessage}
+	}
+	var summaries []forward.NQEOrgQuerySummary
+	for _, query := range m.nqeQueries {
+		if firstNonEmpty(query.Repository, "org") == repository {
+			summaries = append(summaries, forward.NQEOrgQuerySummary{Path: query.Path, QueryID: query.QueryID, LastCommitId: "commit-" + query.QueryID})
+		}
+	}
+	return summaries, nil
+}
+
 func (m *MockForwardClient) GetNQEQueryByCommit(commitID string, path string, repository string) (*forward.NQEQueryDetail, error) {
 	return m.GetNQEQueryByCommitWithContext(context.Background(), commitID, path, repository)
 }
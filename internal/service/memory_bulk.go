@@ -0,0 +1,387 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	maxBulkItems = 1000 // Entities or relations accepted by one bulk call
+
+	bulkStatusCreated = "created" // A new entity or relation was stored
+	bulkStatusUpdated = "updated" // An existing entity or relation was updated in place
+	bulkStatusExists  = "exists"  // The relation already existed and had nothing to update
+	bulkStatusError   = "error"   // The item was not stored
+)
+
+// BulkEntity is one entity of a bulk import
+type BulkEntity struct {
+	Name     string                 `json:"name" jsonschema:"required,description=Name of the entity; an entity with the same name and type is updated"`
+	Type     string                 `json:"type" jsonschema:"required,description=Type of the entity e.g. device or network"`
+	Metadata map[string]interface{} `json:"metadata,omitempty" jsonschema:"description=Metadata of the entity; keys are merged into an existing entity's metadata"`
+}
+
+// BulkRelation is one relation of a bulk import. Endpoints are entity IDs or names; a type
+// picks the entity when several share a name.
+type BulkRelation struct {
+	From       string                 `json:"from" jsonschema:"required,description=ID or name of the source entity"`
+	FromType   string                 `json:"from_type,omitempty" jsonschema:"description=Type of the source entity when it is given by name"`
+	To         string                 `json:"to" jsonschema:"required,description=ID or name of the target entity"`
+	ToType     string                 `json:"to_type,omitempty" jsonschema:"description=Type of the target entity when it is given by name"`
+	Type       string                 `json:"type" jsonschema:"required,description=Type of the relation e.g. depends_on"`
+	Properties map[string]interface{} `json:"properties,omitempty" jsonschema:"description=Properties of the relation; keys are merged into an existing relation's properties"`
+}
+
+// BulkItemResult reports what happened to one item of a bulk import
+type BulkItemResult struct {
+	Index  int    `json:"index"`
+	Status string `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BulkResult reports a bulk import. With atomic imports, Committed is false when any item
+// failed and nothing was stored.
+type BulkResult struct {
+	Items     []BulkItemResult `json:"items"`
+	Committed bool             `json:"committed"`
+}
+
+// Count returns how many items ended with the status
+func (r *BulkResult) Count(status string) int {
+	count := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// mergeMetadata returns the existing metadata with the updates applied on top
+func mergeMetadata(existing, updates map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing)+len(updates))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range updates {
+		merged[key] = value
+	}
+	return merged
+}
+
+// BulkCreateEntities stores entities in one transaction, upserting by name and type: an
+// entity that already exists keeps its ID, relations and observations, and gets the new
+// metadata keys merged into its own. Atomic imports store nothing when any item fails.
+func (m *MemorySystem) BulkCreateEntities(items []BulkEntity, atomic bool) (*BulkResult, error) {
+	if len(items) > maxBulkItems {
+		return nil, fmt.Errorf("at most %d entities can be created at once, got %d", maxBulkItems, len(items))
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start bulk import: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	base := now.UnixNano()
+	result := &BulkResult{Items: make([]BulkItemResult, 0, len(items))}
+	var created []*Entity
+	for i, item := range items {
+		entity, status, err := m.upsertEntity(tx, item, fmt.Sprintf("entity_%d", base+int64(i)), now)
+		if err != nil {
+			result.Items = append(result.Items, BulkItemResult{Index: i, Status: bulkStatusError, Error: err.Error()})
+			continue
+		}
+		result.Items = append(result.Items, BulkItemResult{Index: i, Status: status, ID: entity.ID})
+		if status == bulkStatusCreated {
+			created = append(created, entity)
+		}
+	}
+
+	if atomic && result.Count(bulkStatusError) > 0 {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk import: %w", err)
+	}
+	result.Committed = true
+
+	m.logger.Debug("Bulk imported %d entities (%d created)", len(items)-result.Count(bulkStatusError), len(created))
+	if m.onStore != nil {
+		for _, entity := range created {
+			m.onStore(entity)
+		}
+	}
+	return result, nil
+}
+
+// upsertEntity inserts an entity, or merges the metadata into the entity of the same name and type
+func (m *MemorySystem) upsertEntity(tx *sql.Tx, item BulkEntity, entityID string, now time.Time) (*Entity, string, error) {
+	item.Name, item.Type = strings.TrimSpace(item.Name), strings.TrimSpace(item.Type)
+	if item.Name == "" || item.Type == "" {
+		return nil, "", fmt.Errorf("name and type are required")
+	}
+
+	var existingID string
+	var createdAt int64
+	var metadataJSON sql.NullString
+	err := tx.QueryRow(`
+		SELECT id, created_at, metadata FROM entities
+		WHERE instance_id = ? AND name = ? AND type = ?
+	`, m.instanceID, item.Name, item.Type).Scan(&existingID, &createdAt, &metadataJSON)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, "", fmt.Errorf("failed to look up entity: %w", err)
+	}
+
+	if err == nil {
+		var existing map[string]interface{}
+		if metadataJSON.Valid && metadataJSON.String != "" {
+			json.Unmarshal([]byte(metadataJSON.String), &existing)
+		}
+		if _, versioned := existing["content_hash"]; versioned {
+			return nil, "", fmt.Errorf("entity '%s' is a versioned stored result and cannot be updated", item.Name)
+		}
+		metadata := mergeMetadata(existing, item.Metadata)
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE entities SET metadata = ?, updated_at = ? WHERE instance_id = ? AND id = ?`,
+			string(data), now.Unix(), m.instanceID, existingID); err != nil {
+			return nil, "", fmt.Errorf("failed to update entity: %w", err)
+		}
+		return &Entity{ID: existingID, Name: item.Name, Type: item.Type, CreatedAt: time.Unix(createdAt, 0), UpdatedAt: now, Metadata: metadata}, bulkStatusUpdated, nil
+	}
+
+	var data string
+	if item.Metadata != nil {
+		encoded, err := json.Marshal(item.Metadata)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		data = string(encoded)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO entities (id, instance_id, name, type, created_at, updated_at, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, entityID, m.instanceID, item.Name, item.Type, now.Unix(), now.Unix(), data); err != nil {
+		return nil, "", fmt.Errorf("failed to create entity: %w", err)
+	}
+	return &Entity{ID: entityID, Name: item.Name, Type: item.Type, CreatedAt: now, UpdatedAt: now, Metadata: item.Metadata}, bulkStatusCreated, nil
+}
+
+// resolveBulkEndpoint finds a relation endpoint by ID, or by name and optionally type
+func (m *MemorySystem) resolveBulkEndpoint(identifier, entityType string) (*Entity, error) {
+	identifier, entityType = strings.TrimSpace(identifier), strings.TrimSpace(entityType)
+	if identifier == "" {
+		return nil, fmt.Errorf("from and to are required")
+	}
+	if entityType == "" {
+		return m.GetEntity(identifier)
+	}
+	if entity, err := m.getEntityByID(identifier); err == nil {
+		if entity.Type != entityType {
+			return nil, fmt.Errorf("entity %s is a %s, not a %s", identifier, entity.Type, entityType)
+		}
+		return entity, nil
+	}
+	if entity, err := m.getEntityByNameAndType(identifier, entityType); err == nil {
+		return entity, nil
+	}
+	return nil, fmt.Errorf("entity not found: %s (%s)", identifier, entityType)
+}
+
+// BulkCreateRelations stores relations in one transaction. A relation that already links the
+// two entities is kept, with the new properties merged into its own. Atomic imports store
+// nothing when any item fails.
+func (m *MemorySystem) BulkCreateRelations(items []BulkRelation, atomic bool) (*BulkResult, error) {
+	if len(items) > maxBulkItems {
+		return nil, fmt.Errorf("at most %d relations can be created at once, got %d", maxBulkItems, len(items))
+	}
+
+	// Endpoints are resolved before the transaction starts, so lookups never wait on it
+	result := &BulkResult{Items: make([]BulkItemResult, len(items))}
+	endpoints := make([][2]string, len(items))
+	for i, item := range items {
+		result.Items[i] = BulkItemResult{Index: i}
+		if strings.TrimSpace(item.Type) == "" {
+			result.Items[i].Status, result.Items[i].Error = bulkStatusError, "type is required"
+			continue
+		}
+		from, err := m.resolveBulkEndpoint(item.From, item.FromType)
+		if err != nil {
+			result.Items[i].Status, result.Items[i].Error = bulkStatusError, err.Error()
+			continue
+		}
+		to, err := m.resolveBulkEndpoint(item.To, item.ToType)
+		if err != nil {
+			result.Items[i].Status, result.Items[i].Error = bulkStatusError, err.Error()
+			continue
+		}
+		endpoints[i] = [2]string{from.ID, to.ID}
+	}
+	if atomic && result.Count(bulkStatusError) > 0 {
+		return result, nil
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start bulk import: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	base := now.UnixNano()
+	for i, item := range items {
+		if result.Items[i].Status == bulkStatusError {
+			continue
+		}
+		id, status, err := m.upsertRelation(tx, endpoints[i][0], endpoints[i][1], strings.TrimSpace(item.Type), item.Properties,
+			fmt.Sprintf("relation_%d", base+int64(i)), now)
+		if err != nil {
+			result.Items[i].Status, result.Items[i].Error = bulkStatusError, err.Error()
+			continue
+		}
+		result.Items[i].Status, result.Items[i].ID = status, id
+	}
+
+	if atomic && result.Count(bulkStatusError) > 0 {
+		return result, nil
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit bulk import: %w", err)
+	}
+	result.Committed = true
+
+	m.logger.Debug("Bulk imported %d relations (%d created)", len(items)-result.Count(bulkStatusError), result.Count(bulkStatusCreated))
+	return result, nil
+}
+
+// upsertRelation inserts a relation, or merges the properties into the one already linking the entities
+func (m *MemorySystem) upsertRelation(tx *sql.Tx, fromID, toID, relationType string, properties map[string]interface{}, relationID string, now time.Time) (string, string, error) {
+	var existingID string
+	var propertiesJSON sql.NullString
+	err := tx.QueryRow(`
+		SELECT id, properties FROM relations
+		WHERE instance_id = ? AND from_id = ? AND to_id = ? AND type = ?
+	`, m.instanceID, fromID, toID, relationType).Scan(&existingID, &propertiesJSON)
+	if err != nil && err != sql.ErrNoRows {
+		return "", "", fmt.Errorf("failed to look up relation: %w", err)
+	}
+
+	if err == nil {
+		if len(properties) == 0 {
+			return existingID, bulkStatusExists, nil
+		}
+		var existing map[string]interface{}
+		if propertiesJSON.Valid && propertiesJSON.String != "" {
+			json.Unmarshal([]byte(propertiesJSON.String), &existing)
+		}
+		data, err := json.Marshal(mergeMetadata(existing, properties))
+		if err != nil {
+			return "", "", fmt.Errorf("failed to marshal properties: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE relations SET properties = ? WHERE instance_id = ? AND id = ?`,
+			string(data), m.instanceID, existingID); err != nil {
+			return "", "", fmt.Errorf("failed to update relation: %w", err)
+		}
+		return existingID, bulkStatusUpdated, nil
+	}
+
+	var data string
+	if properties != nil {
+		encoded, err := json.Marshal(properties)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to marshal properties: %w", err)
+		}
+		data = string(encoded)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO relations (id, instance_id, from_id, to_id, type, created_at, properties)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, relationID, m.instanceID, fromID, toID, relationType, now.Unix(), data); err != nil {
+		return "", "", fmt.Errorf("failed to create relation: %w", err)
+	}
+	return relationID, bulkStatusCreated, nil
+}
+
+// bulkImportResponse renders a bulk import as counts followed by the items that need attention
+func bulkImportResponse(kind string, result *BulkResult, atomic bool) *mcp.ToolResponse {
+	var text strings.Builder
+	failed := result.Count(bulkStatusError)
+	if !result.Committed {
+		text.WriteString(fmt.Sprintf("Imported no %s: %d of %d items failed and the import is atomic, so nothing was stored. "+
+			"Fix the items below and retry, or pass atomic=false to store the valid items.\n\n", kind, failed, len(result.Items)))
+	} else {
+		text.WriteString(fmt.Sprintf("Imported %d of %d %s: %d created, %d updated", len(result.Items)-failed, len(result.Items), kind,
+			result.Count(bulkStatusCreated), result.Count(bulkStatusUpdated)))
+		if exists := result.Count(bulkStatusExists); exists > 0 {
+			text.WriteString(fmt.Sprintf(", %d already existed", exists))
+		}
+		if failed > 0 {
+			text.WriteString(fmt.Sprintf(", %d failed", failed))
+		}
+		text.WriteString(".\n\n")
+	}
+
+	text.WriteString("| # | Status | ID | Error |\n|---|---|---|---|\n")
+	for _, item := range result.Items {
+		// Atomic failures only list the failed items; the rest were not stored either
+		if !result.Committed && item.Status != bulkStatusError {
+			continue
+		}
+		text.WriteString(fmt.Sprintf("| %d | %s | %s | %s |\n", item.Index, item.Status, item.ID, item.Error))
+	}
+	if result.Committed && atomic {
+		text.WriteString("\nAll items were stored in one transaction.")
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String()))
+}
+
+// bulkAtomic reads the atomic flag of a bulk import, which defaults to true
+func bulkAtomic(atomic *bool) bool {
+	return atomic == nil || *atomic
+}
+
+// bulkCreateEntities creates or updates many entities in one call
+func (s *ForwardMCPService) bulkCreateEntities(args BulkCreateEntitiesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("bulk_create_entities", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if len(args.Entities) == 0 {
+		return nil, fmt.Errorf("at least one entity must be provided")
+	}
+	atomic := bulkAtomic(args.Atomic)
+	result, err := s.memorySystem.BulkCreateEntities(args.Entities, atomic)
+	if err != nil {
+		return nil, err
+	}
+	return bulkImportResponse("entities", result, atomic), nil
+}
+
+// bulkCreateRelations creates or updates many relations in one call
+func (s *ForwardMCPService) bulkCreateRelations(args BulkCreateRelationsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("bulk_create_relations", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if len(args.Relations) == 0 {
+		return nil, fmt.Errorf("at least one relation must be provided")
+	}
+	atomic := bulkAtomic(args.Atomic)
+	result, err := s.memorySystem.BulkCreateRelations(args.Relations, atomic)
+	if err != nil {
+		return nil, err
+	}
+	return bulkImportResponse("relations", result, atomic), nil
+}
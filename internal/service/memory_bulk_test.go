@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBulkCreateEntities(t *testing.T) {
+	service := createTestService()
+	memory := service.memorySystem
+	entityType := uniqueTestName("bulk_device_")
+	existing, _ := memory.CreateEntity("core-rtr-01", entityType, map[string]interface{}{"platform": "ios", "site": "dc-east"})
+	memory.AddObservation(existing.ID, "Runs BGP", "fact", nil)
+
+	response, err := service.bulkCreateEntities(BulkCreateEntitiesArgs{Entities: []BulkEntity{
+		{Name: "core-rtr-01", Type: entityType, Metadata: map[string]interface{}{"platform": "iosxe"}},
+		{Name: "core-rtr-02", Type: entityType},
+		{Name: "core-rtr-02", Type: entityType, Metadata: map[string]interface{}{"site": "dc-west"}},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Imported 3 of 3 entities: 1 created, 2 updated") {
+		t.Errorf("Unexpected import summary: %s", text)
+	}
+
+	updated, err := memory.GetEntity(existing.ID)
+	if err != nil || updated.Metadata["platform"] != "iosxe" || updated.Metadata["site"] != "dc-east" {
+		t.Errorf("Expected the existing entity kept with merged metadata, got %+v, %v", updated, err)
+	}
+	if observations, _ := memory.GetObservations(existing.ID, ""); len(observations) != 1 {
+		t.Errorf("Expected the existing entity's observations kept, got %d", len(observations))
+	}
+	if second, err := memory.getEntityByNameAndType("core-rtr-02", entityType); err != nil || second.Metadata["site"] != "dc-west" {
+		t.Errorf("Expected a repeated item to update the entity created earlier in the batch, got %+v, %v", second, err)
+	}
+}
+
+func TestBulkCreateEntitiesAtomic(t *testing.T) {
+	service := createTestService()
+	memory := service.memorySystem
+	entityType := uniqueTestName("bulk_atomic_")
+	items := []BulkEntity{{Name: "edge-fw-01", Type: entityType}, {Name: " ", Type: entityType}}
+
+	response, err := service.bulkCreateEntities(BulkCreateEntitiesArgs{Entities: items})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Imported no entities") || !strings.Contains(text, "| 1 | error |  | name and type are required |") {
+		t.Errorf("Expected the failed item reported and nothing stored: %s", text)
+	}
+	if _, err := memory.getEntityByNameAndType("edge-fw-01", entityType); err == nil {
+		t.Error("Expected an atomic import with a failed item to store nothing")
+	}
+
+	result, err := memory.BulkCreateEntities(items, false)
+	if err != nil || !result.Committed || result.Count(bulkStatusCreated) != 1 || result.Count(bulkStatusError) != 1 {
+		t.Fatalf("Expected the valid item stored, got %+v, %v", result, err)
+	}
+	if _, err := memory.getEntityByNameAndType("edge-fw-01", entityType); err != nil {
+		t.Errorf("Expected the valid item stored, got %v", err)
+	}
+
+	if _, err := memory.BulkCreateEntities(make([]BulkEntity, maxBulkItems+1), true); err == nil {
+		t.Error("Expected oversized imports to be refused")
+	}
+}
+
+func TestBulkCreateRelations(t *testing.T) {
+	service := createTestService()
+	memory := service.memorySystem
+	entityType := uniqueTestName("bulk_relation_")
+	router, _ := memory.CreateEntity("core-rtr-01", entityType, nil)
+	site, _ := memory.CreateEntity("dc-east", entityType+"_site", nil)
+	memory.CreateRelation(router.ID, site.ID, "located_at", map[string]interface{}{"rack": "A1"})
+
+	relations := []BulkRelation{
+		{From: router.ID, To: "dc-east", ToType: entityType + "_site", Type: "located_at", Properties: map[string]interface{}{"row": "3"}},
+		{From: "dc-east", FromType: entityType + "_site", To: router.ID, Type: "hosts"},
+		{From: router.ID, To: "missing-site", ToType: entityType + "_site", Type: "located_at"},
+	}
+	response, err := service.bulkCreateRelations(BulkCreateRelationsArgs{Relations: relations})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Imported no relations") || !strings.Contains(text, "entity not found: missing-site") {
+		t.Errorf("Expected the unknown endpoint reported and nothing stored: %s", text)
+	}
+	if memory.hasRelation(site.ID, router.ID, "hosts") {
+		t.Error("Expected an atomic import with a failed item to store nothing")
+	}
+
+	atomic := false
+	response, err = service.bulkCreateRelations(BulkCreateRelationsArgs{Relations: relations, Atomic: &atomic})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Imported 2 of 3 relations: 1 created, 1 updated, 1 failed") {
+		t.Errorf("Unexpected import summary: %s", text)
+	}
+	located, _ := memory.GetRelations(router.ID, "located_at")
+	if len(located) != 1 || located[0].Properties["rack"] != "A1" || located[0].Properties["row"] != "3" {
+		t.Errorf("Expected the existing relation kept with merged properties, got %+v", located)
+	}
+	if !memory.hasRelation(site.ID, router.ID, "hosts") {
+		t.Error("Expected the relation between entities given by name created")
+	}
+
+	result, err := memory.BulkCreateRelations(relations[1:2], true)
+	if err != nil || result.Count(bulkStatusExists) != 1 {
+		t.Errorf("Expected a repeated relation reported as existing, got %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,547 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	defaultDuplicateThreshold = 0.85 // Name similarity above which differently spelled entities are duplicates
+	defaultDuplicateGroups    = 20   // Duplicate groups listed by default
+	maxDuplicateScan          = 2000 // Entities scanned for duplicates
+	maxSimilarityScan         = 200  // Entities of one type compared pairwise by similarity
+
+	observationTypeMerge = "merge" // Observation recording an entity merged into its owner
+)
+
+// DuplicateGroup is a set of entities of one type that look like the same thing
+type DuplicateGroup struct {
+	Type       string
+	Entities   []*Entity // Oldest first
+	Similarity float64   // Lowest similarity that joined the group; 1 for equal normalized names
+}
+
+// EntityMerge records one entity merged into another
+type EntityMerge struct {
+	SourceID            string    `json:"source_id"`
+	SourceName          string    `json:"source_name"`
+	TargetID            string    `json:"target_id"`
+	MergedAt            time.Time `json:"merged_at"`
+	Observations        int       `json:"observations"`         // Moved to the target
+	DroppedObservations int       `json:"dropped_observations"` // Already on the target
+	Relations           int       `json:"relations"`            // Moved to the target
+	DroppedRelations    int       `json:"dropped_relations"`    // Between source and target, or already on the target
+}
+
+// normalizeEntityName makes names comparable across case and separators, so core-rtr-01,
+// CORE_RTR_01 and "Core Rtr 01" are equal. Device names also drop their domain.
+func normalizeEntityName(name, entityType string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if entityType == "device" && net.ParseIP(name) == nil {
+		if host, _, found := strings.Cut(name, "."); found && host != "" {
+			name = host
+		}
+	}
+	var normalized strings.Builder
+	for _, r := range name {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			normalized.WriteRune(r)
+		}
+	}
+	return normalized.String()
+}
+
+// nameNumbers returns the numbers in a name without leading zeros. Names with different
+// numbers, such as core-rtr-01 and core-rtr-02, name different things however similar.
+func nameNumbers(name string) string {
+	var numbers []string
+	for _, field := range strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		if trimmed := strings.TrimLeft(field, "0"); trimmed != "" {
+			numbers = append(numbers, trimmed)
+		} else {
+			numbers = append(numbers, "0")
+		}
+	}
+	return strings.Join(numbers, ",")
+}
+
+// editSimilarity is one minus the edit distance of two strings relative to the longer one
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 || len(rb) == 0 {
+		return 0
+	}
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return 1 - float64(previous[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+// newNameSimilarity returns the similarity of two entity names: the higher of their edit
+// similarity and, when the embedding service captures meaning, the similarity of their
+// embeddings, which also matches abbreviations such as rtr and router
+func newNameSimilarity(embeddingService EmbeddingService) func(a, b string) float64 {
+	switch embeddingService.(type) {
+	case nil, *KeywordEmbeddingService, *MockEmbeddingService:
+		// Hash-based embeddings carry no notion of name similarity
+		embeddingService = nil
+	}
+	embeddings := make(map[string][]float64)
+	embed := func(name string) []float64 {
+		if embedding, ok := embeddings[name]; ok {
+			return embedding
+		}
+		words := strings.Join(strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }), " ")
+		embedding, err := embeddingService.GenerateEmbedding(words)
+		if err != nil {
+			embedding = nil
+		}
+		embeddings[name] = embedding
+		return embedding
+	}
+	return func(a, b string) float64 {
+		similarity := editSimilarity(strings.ToLower(a), strings.ToLower(b))
+		if embeddingService != nil {
+			if ea, eb := embed(a), embed(b); ea != nil && eb != nil {
+				similarity = max(similarity, embeddingSimilarity(ea, eb))
+			}
+		}
+		return similarity
+	}
+}
+
+// groupDuplicateEntities groups entities of the same type whose names are equal once
+// normalized, or whose names are at least threshold similar and contain the same numbers.
+// Versioned stored results are never duplicates of each other, and a group never spans
+// networks: entities with a network_id only join entities of that network or of none.
+func groupDuplicateEntities(entities []*Entity, similarity func(a, b string) float64, threshold float64) []DuplicateGroup {
+	byType := make(map[string][]*Entity)
+	for _, entity := range entities {
+		if _, versioned := entity.Metadata["content_hash"]; versioned {
+			continue
+		}
+		byType[entity.Type] = append(byType[entity.Type], entity)
+	}
+
+	var groups []DuplicateGroup
+	for entityType, candidates := range byType {
+		parent := make([]int, len(candidates))
+		lowest := make([]float64, len(candidates))
+		network := make([]string, len(candidates)) // Network of each group, kept at its root
+		for i := range parent {
+			parent[i], lowest[i], network[i] = i, 1, resultValueString(candidates[i].Metadata["network_id"])
+		}
+		var find func(int) int
+		find = func(i int) int {
+			if parent[i] != i {
+				parent[i] = find(parent[i])
+			}
+			return parent[i]
+		}
+		union := func(i, j int, score float64) {
+			ri, rj := find(i), find(j)
+			if ri == rj || (network[ri] != "" && network[rj] != "" && network[ri] != network[rj]) {
+				return
+			}
+			parent[rj] = ri
+			lowest[ri] = min(lowest[ri], lowest[rj], score)
+			network[ri] = firstNonEmpty(network[ri], network[rj])
+		}
+
+		// Entities with equal normalized names, then one representative per name and network by
+		// similarity
+		first := make(map[string]int)
+		var representatives []int
+		for i, entity := range candidates {
+			key := normalizeEntityName(entity.Name, entityType) + "\x00" + network[i]
+			if j, seen := first[key]; seen {
+				union(j, i, 1)
+				continue
+			}
+			first[key] = i
+			representatives = append(representatives, i)
+		}
+		// Equal names without a network join a network's group; union keeps networks apart
+		for i, entity := range candidates {
+			if network[i] != "" {
+				if j, seen := first[normalizeEntityName(entity.Name, entityType)+"\x00"]; seen {
+					union(j, i, 1)
+				}
+			}
+		}
+		if similarity != nil && len(representatives) <= maxSimilarityScan {
+			for x, i := range representatives {
+				for _, j := range representatives[x+1:] {
+					a, b := candidates[i].Name, candidates[j].Name
+					if nameNumbers(a) != nameNumbers(b) {
+						continue
+					}
+					if score := similarity(a, b); score >= threshold {
+						union(i, j, score)
+					}
+				}
+			}
+		}
+
+		members := make(map[int][]*Entity)
+		for i, entity := range candidates {
+			root := find(i)
+			members[root] = append(members[root], entity)
+		}
+		for root, group := range members {
+			if len(group) < 2 {
+				continue
+			}
+			sort.Slice(group, func(i, j int) bool {
+				if !group[i].CreatedAt.Equal(group[j].CreatedAt) {
+					return group[i].CreatedAt.Before(group[j].CreatedAt)
+				}
+				return group[i].ID < group[j].ID
+			})
+			groups = append(groups, DuplicateGroup{Type: entityType, Entities: group, Similarity: lowest[root]})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Type != groups[j].Type {
+			return groups[i].Type < groups[j].Type
+		}
+		return groups[i].Entities[0].Name < groups[j].Entities[0].Name
+	})
+	return groups
+}
+
+// countEntityLinks counts the observations and relations of an entity
+func (m *MemorySystem) countEntityLinks(entityID string) (int, int) {
+	var observations, relations int
+	m.db.QueryRow(`SELECT COUNT(*) FROM observations WHERE instance_id = ? AND entity_id = ?`, m.instanceID, entityID).Scan(&observations)
+	m.db.QueryRow(`SELECT COUNT(*) FROM relations WHERE instance_id = ? AND (from_id = ? OR to_id = ?)`, m.instanceID, entityID, entityID).Scan(&relations)
+	return observations, relations
+}
+
+// getMergedEntity returns the entity that an entity with the ID or name was merged into
+func (m *MemorySystem) getMergedEntity(identifier string) (*Entity, error) {
+	var targetID string
+	err := m.db.QueryRow(`
+		SELECT target_id FROM entity_merges
+		WHERE instance_id = ? AND (source_id = ? OR source_name = ?)
+		ORDER BY merged_at DESC LIMIT 1
+	`, m.instanceID, identifier, identifier).Scan(&targetID)
+	if err != nil {
+		return nil, err
+	}
+	return m.getEntityByID(targetID)
+}
+
+// MergeEntities merges entities of the target's type and network into the target and moves
+// them to the trash. Their observations and relations move to the target, except observations
+// the target already has and relations between them and the target; metadata keys the target
+// lacks are copied and pins move to the target. Each merge is recorded, so the merged IDs and
+// names resolve to the target, and an observation on the target notes it. Restoring a merged
+// entity from the trash undoes its merge.
+func (m *MemorySystem) MergeEntities(targetID string, sourceIDs []string) ([]EntityMerge, error) {
+	target, err := m.getEntityByID(targetID)
+	if err != nil {
+		return nil, fmt.Errorf("entity not found: %s", targetID)
+	}
+	if _, versioned := target.Metadata["content_hash"]; versioned {
+		return nil, fmt.Errorf("entity '%s' is a versioned stored result and cannot be merged", target.Name)
+	}
+	var sources []*Entity
+	seen := map[string]bool{target.ID: true}
+	for _, sourceID := range sourceIDs {
+		source, err := m.getEntityByID(sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("entity not found: %s", sourceID)
+		}
+		if seen[source.ID] {
+			continue
+		}
+		seen[source.ID] = true
+		if source.Type != target.Type {
+			return nil, fmt.Errorf("cannot merge %s '%s' into %s '%s': entities must have the same type", source.Type, source.Name, target.Type, target.Name)
+		}
+		if _, versioned := source.Metadata["content_hash"]; versioned {
+			return nil, fmt.Errorf("entity '%s' is a versioned stored result and cannot be merged", source.Name)
+		}
+		sourceNetwork, targetNetwork := resultValueString(source.Metadata["network_id"]), resultValueString(target.Metadata["network_id"])
+		if sourceNetwork != "" && targetNetwork != "" && sourceNetwork != targetNetwork {
+			return nil, fmt.Errorf("cannot merge '%s' of network %s into '%s' of network %s", source.Name, sourceNetwork, target.Name, targetNetwork)
+		}
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no entities to merge into '%s'", target.Name)
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start merge: %w", err)
+	}
+	defer tx.Rollback()
+
+	metadata := make(map[string]interface{}, len(target.Metadata))
+	for key, value := range target.Metadata {
+		metadata[key] = value
+	}
+	now := time.Now()
+	merges := make([]EntityMerge, 0, len(sources))
+	for _, source := range sources {
+		merge := EntityMerge{SourceID: source.ID, SourceName: source.Name, TargetID: target.ID, MergedAt: now}
+		// The source as it was goes to the trash, so restore_entity can undo the merge
+		payload, err := m.readTrashPayload(tx, source.ID)
+		if err != nil {
+			return nil, err
+		}
+		affected := func(result sql.Result, err error) (int, error) {
+			if err != nil {
+				return 0, err
+			}
+			count, _ := result.RowsAffected()
+			return int(count), nil
+		}
+
+		if merge.DroppedObservations, err = affected(tx.Exec(`
+			DELETE FROM observations WHERE instance_id = ? AND entity_id = ? AND EXISTS (
+				SELECT 1 FROM observations t WHERE t.instance_id = observations.instance_id
+				AND t.entity_id = ? AND t.content = observations.content AND t.type = observations.type)
+		`, m.instanceID, source.ID, target.ID)); err != nil {
+			return nil, fmt.Errorf("failed to merge observations: %w", err)
+		}
+		if merge.Observations, err = affected(tx.Exec(`UPDATE observations SET entity_id = ? WHERE instance_id = ? AND entity_id = ?`,
+			target.ID, m.instanceID, source.ID)); err != nil {
+			return nil, fmt.Errorf("failed to merge observations: %w", err)
+		}
+
+		var relations int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM relations WHERE instance_id = ? AND (from_id = ? OR to_id = ?)`,
+			m.instanceID, source.ID, source.ID).Scan(&relations); err != nil {
+			return nil, fmt.Errorf("failed to count relations: %w", err)
+		}
+		// Links between the duplicates would become self-links; relations the target already
+		// has are left behind and removed with the source
+		if _, err := tx.Exec(`
+			DELETE FROM relations WHERE instance_id = ? AND
+				((from_id = ? AND to_id IN (?, ?)) OR (from_id = ? AND to_id = ?))
+		`, m.instanceID, source.ID, source.ID, target.ID, target.ID, source.ID); err != nil {
+			return nil, fmt.Errorf("failed to merge relations: %w", err)
+		}
+		for _, column := range []string{"from_id", "to_id"} {
+			moved, err := affected(tx.Exec(fmt.Sprintf(`UPDATE OR IGNORE relations SET %[1]s = ? WHERE instance_id = ? AND %[1]s = ?`, column),
+				target.ID, m.instanceID, source.ID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge relations: %w", err)
+			}
+			merge.Relations += moved
+		}
+		merge.DroppedRelations = relations - merge.Relations
+
+		for key, value := range source.Metadata {
+			if _, exists := metadata[key]; !exists {
+				metadata[key] = value
+			}
+		}
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO entity_pins (entity_id, instance_id, reason, pinned_at)
+			SELECT ?, instance_id, reason, pinned_at FROM entity_pins WHERE instance_id = ? AND entity_id = ?
+		`, target.ID, m.instanceID, source.ID); err != nil {
+			return nil, fmt.Errorf("failed to move pin: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM entity_pins WHERE instance_id = ? AND entity_id = ?`, m.instanceID, source.ID); err != nil {
+			return nil, fmt.Errorf("failed to move pin: %w", err)
+		}
+
+		// Entities merged into the source earlier now resolve to the target too
+		if _, err := tx.Exec(`UPDATE entity_merges SET target_id = ? WHERE instance_id = ? AND target_id = ?`,
+			target.ID, m.instanceID, source.ID); err != nil {
+			return nil, fmt.Errorf("failed to record merge: %w", err)
+		}
+		if _, err := tx.Exec(`
+			INSERT OR REPLACE INTO entity_merges (source_id, instance_id, source_name, source_type, target_id, merged_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, source.ID, m.instanceID, source.Name, source.Type, target.ID, now.Unix()); err != nil {
+			return nil, fmt.Errorf("failed to record merge: %w", err)
+		}
+		if _, err := m.moveToTrash(tx, payload); err != nil {
+			return nil, fmt.Errorf("failed to remove merged entity: %w", err)
+		}
+		merges = append(merges, merge)
+	}
+
+	if len(metadata) > len(target.Metadata) {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if _, err := tx.Exec(`UPDATE entities SET metadata = ? WHERE instance_id = ? AND id = ?`, string(data), m.instanceID, target.ID); err != nil {
+			return nil, fmt.Errorf("failed to merge metadata: %w", err)
+		}
+	}
+	if _, err := tx.Exec(`UPDATE entities SET updated_at = ? WHERE instance_id = ? AND id = ?`, now.Unix(), m.instanceID, target.ID); err != nil {
+		return nil, fmt.Errorf("failed to update entity: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to merge entities: %w", err)
+	}
+
+	for _, merge := range merges {
+		content := fmt.Sprintf("Merged duplicate '%s' (%s): %d observations and %d relations moved here", merge.SourceName, merge.SourceID, merge.Observations, merge.Relations)
+		if _, err := m.AddObservation(target.ID, content, observationTypeMerge, map[string]interface{}{
+			"source_id": merge.SourceID, "source_name": merge.SourceName, "merged_at": merge.MergedAt.Unix(),
+		}); err != nil {
+			m.logger.Warn("Failed to record merge of %s on %s: %v", merge.SourceID, target.ID, err)
+		}
+	}
+	m.logger.Debug("Merged %d entities into %s", len(merges), target.ID)
+	return merges, nil
+}
+
+// findDuplicateEntities lists groups of memory entities that look like duplicates
+func (s *ForwardMCPService) findDuplicateEntities(args FindDuplicateEntitiesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("find_duplicate_entities", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	threshold := args.Threshold
+	if threshold <= 0 || threshold > 1 {
+		threshold = defaultDuplicateThreshold
+	}
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultDuplicateGroups
+	}
+	entities, err := s.memorySystem.SearchEntities("", args.EntityType, maxDuplicateScan)
+	if err != nil {
+		return nil, err
+	}
+	var embeddingService EmbeddingService
+	if s.semanticCache != nil {
+		embeddingService = s.semanticCache.embeddingService
+	}
+	groups := groupDuplicateEntities(entities, newNameSimilarity(embeddingService), threshold)
+
+	scanned := fmt.Sprintf("%d entities", len(entities))
+	if len(entities) == maxDuplicateScan {
+		scanned = fmt.Sprintf("the %d most recently updated entities", maxDuplicateScan)
+	}
+	if len(groups) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("No duplicate entities found among %s.", scanned))), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Duplicate Entities\n\nFound %d groups among %s.\n", len(groups), scanned))
+	for i, group := range groups {
+		if i == limit {
+			text.WriteString(fmt.Sprintf("\n%d more groups not shown; raise limit or filter by entity_type.\n", len(groups)-limit))
+			break
+		}
+		// Keep the entity with the most observations and relations, the oldest on a tie
+		keep, best := 0, -1
+		links := make([][2]int, len(group.Entities))
+		for j, entity := range group.Entities {
+			observations, relations := s.memorySystem.countEntityLinks(entity.ID)
+			links[j] = [2]int{observations, relations}
+			if observations+relations > best {
+				keep, best = j, observations+relations
+			}
+		}
+
+		names := make([]string, len(group.Entities))
+		for j, entity := range group.Entities {
+			names[j] = entity.Name
+		}
+		reason := "same name ignoring case and separators"
+		if group.Similarity < 1 {
+			reason = fmt.Sprintf("similar names (similarity %.2f)", group.Similarity)
+		}
+		text.WriteString(fmt.Sprintf("\n### %d. %s: %s\n%s\n\n", i+1, group.Type, strings.Join(names, ", "), reason))
+		text.WriteString("| Entity | ID | Observations | Relations | Created |\n|---|---|---|---|---|\n")
+		var sources []string
+		for j, entity := range group.Entities {
+			name := entity.Name
+			if j == keep {
+				name += " (keep)"
+			} else {
+				sources = append(sources, fmt.Sprintf("%q", entity.ID))
+			}
+			text.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %s |\n", name, entity.ID, links[j][0], links[j][1], s.timeFormatter.FormatWithAge(entity.CreatedAt)))
+		}
+		text.WriteString(fmt.Sprintf("\nMerge: merge_entities target_id=%q source_ids=[%s]\n", group.Entities[keep].ID, strings.Join(sources, ", ")))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// mergeEntities merges duplicate entities into the one to keep
+func (s *ForwardMCPService) mergeEntities(args MergeEntitiesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("merge_entities", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if len(args.SourceIDs) == 0 {
+		return nil, fmt.Errorf("source_ids is required")
+	}
+	target, err := s.memorySystem.GetEntity(args.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	sourceIDs := make([]string, 0, len(args.SourceIDs))
+	impact := DeleteImpact{
+		Summary:    fmt.Sprintf("%d entities will be merged into '%s' (%s) and moved to the trash.", len(args.SourceIDs), target.Name, target.ID),
+		Reversible: true,
+	}
+	for _, identifier := range args.SourceIDs {
+		source, err := s.memorySystem.GetEntity(identifier)
+		if err != nil {
+			return nil, err
+		}
+		sourceIDs = append(sourceIDs, source.ID)
+		observations, relations := s.memorySystem.countEntityLinks(source.ID)
+		impact.Details = append(impact.Details, fmt.Sprintf("'%s' (%s): %d observations and %d relations move to the target", source.Name, source.ID, observations, relations))
+		impact.Rows++
+	}
+	if response, err := s.confirmDestructive("merge_entities", target.ID+"<"+strings.Join(sourceIDs, ","), args.ConfirmationToken, impact); response != nil || err != nil {
+		return response, err
+	}
+	s.purgeExpiredTrash()
+	merges, err := s.memorySystem.MergeEntities(target.ID, sourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Merged %d entities into '%s' (%s, %s).\n\n", len(merges), target.Name, target.Type, target.ID))
+	text.WriteString("| Merged | ID | Observations moved | Relations moved | Dropped |\n|---|---|---|---|---|\n")
+	for _, merge := range merges {
+		if s.analysisCache != nil {
+			s.analysisCache.remove(merge.SourceID)
+		}
+		text.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d observations, %d relations |\n", merge.SourceName, merge.SourceID,
+			merge.Observations, merge.Relations, merge.DroppedObservations, merge.DroppedRelations))
+	}
+	text.WriteString("\nDropped observations were already on the target; dropped relations linked the duplicates or already existed. " +
+		"The merged IDs and names now resolve to the target, and its merge observations record where its data came from. " +
+		fmt.Sprintf("Undo a merge with restore_entity entity_id=<merged ID> within %s.", formatRetention(s.trashRetention())))
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGroupDuplicateEntities(t *testing.T) {
+	created := time.Now()
+	entity := func(id, name, entityType string) *Entity {
+		created = created.Add(time.Second)
+		return &Entity{ID: id, Name: name, Type: entityType, CreatedAt: created}
+	}
+	entities := []*Entity{
+		entity("e1", "core-rtr-01", "device"),
+		entity("e2", "CORE_RTR_01.example.com", "device"),
+		entity("e3", "core-rtr-02", "device"),
+		entity("e4", "edge-firewall-primary", "device"),
+		entity("e5", "edge-firewal-primary", "device"),
+		entity("e6", "core-rtr-01", "site"),
+		entity("e7", "Lab Network", "network"),
+		{ID: "e8", Name: "FQ_1-162112", Type: "nqe_result", Metadata: map[string]interface{}{"content_hash": "a"}},
+		{ID: "e9", Name: "fq_1_162112", Type: "nqe_result", Metadata: map[string]interface{}{"content_hash": "b"}},
+	}
+
+	groups := groupDuplicateEntities(entities, newNameSimilarity(nil), defaultDuplicateThreshold)
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 duplicate groups, got %+v", groups)
+	}
+	if ids := []string{groups[0].Entities[0].ID, groups[0].Entities[1].ID}; len(groups[0].Entities) != 2 || ids[0] != "e1" || ids[1] != "e2" || groups[0].Similarity != 1 {
+		t.Errorf("Expected core-rtr-01 and its FQDN grouped, got %+v", groups[0])
+	}
+	if len(groups[1].Entities) != 2 || groups[1].Entities[0].ID != "e4" || groups[1].Similarity >= 1 || groups[1].Similarity < defaultDuplicateThreshold {
+		t.Errorf("Expected the misspelled firewall grouped by similarity, got %+v", groups[1])
+	}
+
+	// Equal names of different networks are kept apart; one without a network joins either
+	scoped := []*Entity{
+		{ID: "n1", Name: "core-rtr-01", Type: "device", Metadata: map[string]interface{}{"network_id": "net-1"}},
+		{ID: "n2", Name: "core-rtr-01", Type: "device", Metadata: map[string]interface{}{"network_id": "net-2"}},
+		{ID: "n3", Name: "CORE-RTR-01", Type: "device"},
+	}
+	if groups := groupDuplicateEntities(scoped, newNameSimilarity(nil), defaultDuplicateThreshold); len(groups) != 1 || len(groups[0].Entities) != 2 {
+		t.Errorf("Expected one group pairing the unscoped entity with a single network, got %+v", groups)
+	}
+	if groups := groupDuplicateEntities(scoped[:2], nil, defaultDuplicateThreshold); len(groups) != 0 {
+		t.Errorf("Expected entities of different networks never grouped, got %+v", groups)
+	}
+
+	// A similarity that matches everything still never joins names with different numbers
+	groups = groupDuplicateEntities(entities, func(a, b string) float64 { return 1 }, defaultDuplicateThreshold)
+	for _, group := range groups {
+		for _, member := range group.Entities {
+			if member.ID == "e3" {
+				t.Errorf("Expected core-rtr-02 kept apart from core-rtr-01, got %+v", group)
+			}
+		}
+	}
+}
+
+func TestNameSimilarityEmbeddings(t *testing.T) {
+	embeddings := &fakeNameEmbeddings{vectors: map[string][]float64{
+		"core rtr 01":    {1, 0},
+		"core router 01": {0.99, 0.1},
+	}}
+	similarity := newNameSimilarity(embeddings)
+	if score := similarity("core-rtr-01", "core-router-01"); score < 0.95 {
+		t.Errorf("Expected abbreviations matched through embeddings, got %.2f", score)
+	}
+	if score := newNameSimilarity(NewKeywordEmbeddingService())("core-rtr-01", "core-router-01"); score >= defaultDuplicateThreshold {
+		t.Errorf("Expected hash-based embeddings to be ignored, got %.2f", score)
+	}
+}
+
+type fakeNameEmbeddings struct {
+	vectors map[string][]float64
+}
+
+func (f *fakeNameEmbeddings) GenerateEmbedding(text string) ([]float64, error) {
+	if vector, ok := f.vectors[text]; ok {
+		return vector, nil
+	}
+	return nil, fmt.Errorf("no embedding for %q", text)
+}
+
+func TestMergeEntities(t *testing.T) {
+	service := createTestService()
+	service.confirmations = NewConfirmationStore(0)
+	memory := service.memorySystem
+	entityType := uniqueTestName("dedup_device_")
+	target, _ := memory.CreateEntity("core-rtr-01", entityType, map[string]interface{}{"platform": "ios"})
+	duplicate, _ := memory.CreateEntity("CORE-RTR-01", entityType, map[string]interface{}{"platform": "eos", "serial": "ABC123"})
+	site, _ := memory.CreateEntity("dc-east", entityType+"_site", nil)
+
+	memory.AddObservation(target.ID, "Runs BGP", "fact", nil)
+	memory.AddObservation(duplicate.ID, "Runs BGP", "fact", nil)
+	memory.AddObservation(duplicate.ID, "Owned by the core team", "fact", nil)
+	memory.CreateRelation(target.ID, site.ID, "located_at", nil)
+	memory.CreateRelation(duplicate.ID, site.ID, "located_at", nil)
+	memory.CreateRelation(site.ID, duplicate.ID, "hosts", nil)
+	memory.CreateRelation(duplicate.ID, target.ID, "peers_with", nil)
+	memory.PinEntity(duplicate.ID, "baseline")
+
+	response, err := service.findDuplicateEntities(FindDuplicateEntitiesArgs{EntityType: entityType})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "core-rtr-01, CORE-RTR-01") || !strings.Contains(text, "same name ignoring case") ||
+		!strings.Contains(text, fmt.Sprintf("merge_entities target_id=%q source_ids=[%q]", duplicate.ID, target.ID)) {
+		t.Errorf("Expected the pair with the busier duplicate kept: %s", text)
+	}
+
+	args := MergeEntitiesArgs{TargetID: target.ID, SourceIDs: []string{"CORE-RTR-01"}}
+	response, err = service.mergeEntities(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Confirmation required: merge_entities") || !strings.Contains(text, "2 observations and 3 relations move to the target") {
+		t.Fatalf("Expected the merge to ask for confirmation first: %s", text)
+	}
+	args.ConfirmationToken = confirmationToken(t, response.Content[0].TextContent.Text)
+	response, err = service.mergeEntities(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "| CORE-RTR-01 | "+duplicate.ID+" | 1 | 1 | 1 observations, 2 relations |") {
+		t.Errorf("Unexpected merge summary: %s", text)
+	}
+
+	observations, _ := memory.GetObservations(target.ID, "")
+	var contents []string
+	for _, observation := range observations {
+		contents = append(contents, observation.Type+": "+observation.Content)
+	}
+	if joined := strings.Join(contents, "\n"); len(observations) != 3 || !strings.Contains(joined, "fact: Owned by the core team") ||
+		!strings.Contains(joined, "merge: Merged duplicate 'CORE-RTR-01' ("+duplicate.ID+")") {
+		t.Errorf("Expected the moved observation and the merge provenance, got:\n%s", joined)
+	}
+	relations, _ := memory.GetRelations(target.ID, "")
+	if len(relations) != 2 || !memory.hasRelation(site.ID, target.ID, "hosts") || !memory.hasRelation(target.ID, site.ID, "located_at") {
+		t.Errorf("Expected the relations moved without duplicates or self-links, got %+v", relations)
+	}
+
+	merged, err := memory.GetEntity(duplicate.ID)
+	if err != nil || merged.ID != target.ID {
+		t.Fatalf("Expected the merged ID to resolve to the target, got %+v, %v", merged, err)
+	}
+	if merged.Metadata["platform"] != "ios" || merged.Metadata["serial"] != "ABC123" {
+		t.Errorf("Expected only missing metadata copied, got %v", merged.Metadata)
+	}
+	if !memory.IsPinned(target.ID) || memory.IsPinned(duplicate.ID) {
+		t.Error("Expected the pin moved to the target")
+	}
+	memory.UnpinEntity(target.ID)
+
+	if _, err := memory.MergeEntities(target.ID, []string{site.ID}); err == nil || !strings.Contains(err.Error(), "same type") {
+		t.Errorf("Expected entities of another type to be refused, got %v", err)
+	}
+
+	// Restoring the duplicate from the trash undoes the merge
+	if _, _, err := memory.RestoreEntity(duplicate.ID, 0); err != nil {
+		t.Fatalf("Expected the merged entity restorable, got %v", err)
+	}
+	restored, err := memory.GetEntity(duplicate.ID)
+	if err != nil || restored.ID != duplicate.ID {
+		t.Fatalf("Expected the duplicate back under its own ID, got %+v, %v", restored, err)
+	}
+	if observations, _ := memory.GetObservations(duplicate.ID, "fact"); len(observations) != 2 {
+		t.Errorf("Expected both observations back on the duplicate, got %d", len(observations))
+	}
+	if !memory.hasRelation(site.ID, duplicate.ID, "hosts") || memory.hasRelation(site.ID, target.ID, "hosts") {
+		t.Error("Expected the moved relation back on the duplicate")
+	}
+
+	// Entities of different networks are not merged
+	other, _ := memory.CreateEntity("core-rtr-01.lab", entityType, map[string]interface{}{"network_id": "net-2"})
+	memory.updateEntityMetadata(target.ID, map[string]interface{}{"network_id": "net-1"})
+	if _, err := memory.MergeEntities(target.ID, []string{other.ID}); err == nil || !strings.Contains(err.Error(), "network net-2") {
+		t.Errorf("Expected entities of another network to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// EntityPin marks an entity that deletion, workspace cleanup, storage cleanup and cache
+// eviction must keep until it is unpinned
+type EntityPin struct {
+	EntityID string                 `json:"entity_id"`
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Reason   string                 `json:"reason,omitempty"`
+	PinnedAt time.Time              `json:"pinned_at"`
+	Metadata map[string]interface{} `json:"-"`
+}
+
+// PinEntity pins an existing entity, replacing the reason of an earlier pin
+func (m *MemorySystem) PinEntity(entityID, reason string) (*EntityPin, error) {
+	entity, err := m.getEntityByID(entityID)
+	if err != nil {
+		return nil, fmt.Errorf("entity not found: %s", entityID)
+	}
+	pin := &EntityPin{EntityID: entity.ID, Name: entity.Name, Type: entity.Type, Reason: reason, PinnedAt: time.Now(), Metadata: entity.Metadata}
+	if _, err := m.db.Exec(`
+		INSERT OR REPLACE INTO entity_pins (entity_id, instance_id, reason, pinned_at)
+		VALUES (?, ?, ?, ?)
+	`, entity.ID, m.instanceID, reason, pin.PinnedAt.Unix()); err != nil {
+		return nil, fmt.Errorf("failed to pin entity: %w", err)
+	}
+	return pin, nil
+}
+
+// UnpinEntity removes an entity's pin and reports whether it was pinned
+func (m *MemorySystem) UnpinEntity(entityID string) (bool, error) {
+	result, err := m.db.Exec(`DELETE FROM entity_pins WHERE instance_id = ? AND entity_id = ?`, m.instanceID, entityID)
+	if err != nil {
+		return false, fmt.Errorf("failed to unpin entity: %w", err)
+	}
+	removed, _ := result.RowsAffected()
+	return removed > 0, nil
+}
+
+// movePin moves a pin from an entity to the entity replacing it
+func (m *MemorySystem) movePin(fromID, toID string) {
+	if _, err := m.db.Exec(`UPDATE entity_pins SET entity_id = ? WHERE instance_id = ? AND entity_id = ?`, toID, m.instanceID, fromID); err != nil {
+		m.logger.Warn("Failed to move the pin of %s to %s: %v", fromID, toID, err)
+	}
+}
+
+// IsPinned reports whether an entity is pinned
+func (m *MemorySystem) IsPinned(entityID string) bool {
+	var count int
+	if err := m.db.QueryRow(`SELECT COUNT(*) FROM entity_pins WHERE instance_id = ? AND entity_id = ?`, m.instanceID, entityID).Scan(&count); err != nil {
+		return false
+	}
+	return count > 0
+}
+
+// PinnedEntities lists pinned entities, most recently pinned first. Pins of entities that no
+// longer exist are skipped.
+func (m *MemorySystem) PinnedEntities() ([]EntityPin, error) {
+	rows, err := m.db.Query(`
+		SELECT p.entity_id, e.name, e.type, COALESCE(p.reason, ''), p.pinned_at, e.metadata
+		FROM entity_pins p JOIN entities e ON e.instance_id = p.instance_id AND e.id = p.entity_id
+		WHERE p.instance_id = ? ORDER BY p.pinned_at DESC, e.name
+	`, m.instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pinned entities: %w", err)
+	}
+	defer rows.Close()
+
+	var pins []EntityPin
+	for rows.Next() {
+		var pin EntityPin
+		var pinnedAt int64
+		var metadata *string
+		if err := rows.Scan(&pin.EntityID, &pin.Name, &pin.Type, &pin.Reason, &pinnedAt, &metadata); err != nil {
+			return nil, fmt.Errorf("failed to read pinned entity: %w", err)
+		}
+		pin.PinnedAt = time.Unix(pinnedAt, 0)
+		if metadata != nil && *metadata != "" {
+			json.Unmarshal([]byte(*metadata), &pin.Metadata)
+		}
+		pins = append(pins, pin)
+	}
+	return pins, rows.Err()
+}
+
+// pinnedEntityIDs returns the IDs of pinned entities; errors leave the set empty
+func (m *MemorySystem) pinnedEntityIDs() map[string]bool {
+	ids := make(map[string]bool)
+	pins, err := m.PinnedEntities()
+	if err != nil {
+		m.logger.Warn("Failed to load pinned entities: %v", err)
+		return ids
+	}
+	for _, pin := range pins {
+		ids[pin.EntityID] = true
+	}
+	return ids
+}
+
+// pinnedEntityIDs returns the pinned entity IDs, or none without the memory system
+func (s *ForwardMCPService) pinnedEntityIDs() map[string]bool {
+	if s.memorySystem == nil {
+		return map[string]bool{}
+	}
+	return s.memorySystem.pinnedEntityIDs()
+}
+
+// cachedResultQueryID extracts the query ID from a run_nqe_query_by_id semantic cache key
+func cachedResultQueryID(cacheKey string) (string, bool) {
+	rest, found := strings.CutPrefix(cacheKey, "query_id:")
+	if !found {
+		return "", false
+	}
+	queryID, _, _ := strings.Cut(rest, "|params:")
+	return queryID, queryID != ""
+}
+
+// applyPinsToCache keeps semantic cache entries holding the result of a pinned nqe_result
+// entity from expiring or being evicted
+func (s *ForwardMCPService) applyPinsToCache() {
+	if s.semanticCache == nil || s.memorySystem == nil {
+		return
+	}
+	pins, err := s.memorySystem.PinnedEntities()
+	if err != nil {
+		s.logger.Warn("Failed to load pinned entities for the cache: %v", err)
+		return
+	}
+	results := make(map[string]bool)
+	for _, pin := range pins {
+		if pin.Type == "nqe_result" {
+			results[fmt.Sprintf("%s|%s|%s", resultValueString(pin.Metadata["query_id"]),
+				resultValueString(pin.Metadata["network_id"]), resultValueString(pin.Metadata["snapshot_id"]))] = true
+		}
+	}
+	if len(results) == 0 {
+		s.semanticCache.SetPinned(nil)
+		return
+	}
+	s.semanticCache.SetPinned(func(entry *CacheEntry) bool {
+		queryID, ok := cachedResultQueryID(entry.Query)
+		return ok && results[fmt.Sprintf("%s|%s|%s", queryID, entry.NetworkID, entry.SnapshotID)]
+	})
+}
+
+// pinEntity pins an entity so it survives deletion, cleanup and cache eviction
+func (s *ForwardMCPService) pinEntity(args PinEntityArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("pin_entity", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	entity, err := s.memorySystem.GetEntity(args.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	pin, err := s.memorySystem.PinEntity(entity.ID, strings.TrimSpace(args.Reason))
+	if err != nil {
+		return nil, err
+	}
+	s.applyPinsToCache()
+
+	text := fmt.Sprintf("Entity '%s' (%s, %s) pinned. delete_entity and cleanup_workspace refuse it, cleanup_storage keeps its analysis database and bloom index", pin.Name, pin.Type, pin.EntityID)
+	if pin.Type == "nqe_result" {
+		text += ", and its cached query result no longer expires or gets evicted"
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text + ". Release it with unpin_entity.")), nil
+}
+
+// unpinEntity releases a pinned entity back to normal retention
+func (s *ForwardMCPService) unpinEntity(args UnpinEntityArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("unpin_entity", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	entityID := args.EntityID
+	if entity, err := s.memorySystem.GetEntity(args.EntityID); err == nil {
+		entityID = entity.ID
+	}
+	removed, err := s.memorySystem.UnpinEntity(entityID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		return nil, fmt.Errorf("entity %s is not pinned", args.EntityID)
+	}
+	s.applyPinsToCache()
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Entity %s unpinned; normal retention and eviction apply again.", entityID))), nil
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+func TestPinEntity(t *testing.T) {
+	service := createTestService()
+	entity, err := service.memorySystem.CreateEntity(uniqueTestName("golden-config-"), "golden_config", nil)
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+
+	response, err := service.pinEntity(PinEntityArgs{EntityID: entity.ID, Reason: "core router baseline"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "pinned") || !strings.Contains(text, entity.ID) {
+		t.Errorf("Unexpected response: %s", text)
+	}
+
+	if _, err := service.deleteEntity(DeleteEntityArgs{EntityID: entity.ID}); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("Expected delete_entity to refuse a pinned entity, got %v", err)
+	}
+	if _, err := service.memorySystem.TrashEntity(entity.ID); err == nil {
+		t.Error("Expected the trash to refuse a pinned entity")
+	}
+
+	response, err = service.getMemoryStats(GetMemoryStatsArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `"pinned_entities"`) || !strings.Contains(text, "core router baseline") {
+		t.Errorf("Expected the pin in the memory stats: %s", text)
+	}
+
+	if _, err := service.unpinEntity(UnpinEntityArgs{EntityID: entity.ID}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.unpinEntity(UnpinEntityArgs{EntityID: entity.ID}); err == nil {
+		t.Error("Expected unpinning twice to fail")
+	}
+	if _, err := service.memorySystem.TrashEntity(entity.ID); err != nil {
+		t.Errorf("Expected an unpinned entity to be deletable, got %v", err)
+	}
+}
+
+func TestPinsSurviveDeletionAndReplacement(t *testing.T) {
+	service := createTestService()
+	memory := service.memorySystem
+	name := uniqueTestName("pinned-baseline-")
+	entity, err := memory.CreateEntity(name, "connectivity_baseline", map[string]interface{}{"version": 1})
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	if _, err := memory.PinEntity(entity.ID, "approved baseline"); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.DeleteEntity(entity.ID); err == nil || !strings.Contains(err.Error(), "pinned") {
+		t.Errorf("Expected a pinned entity not to be deleted, got %v", err)
+	}
+
+	// Storing an entity of the same name and type carries the pin over
+	recreated, err := memory.CreateEntity(name, "connectivity_baseline", map[string]interface{}{"version": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	replaced, err := memory.ReplaceEntity(name, "connectivity_baseline", map[string]interface{}{"version": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !memory.IsPinned(replaced.ID) || memory.IsPinned(recreated.ID) || memory.IsPinned(entity.ID) {
+		t.Errorf("Expected the pin to follow the replacements to %s", replaced.ID)
+	}
+	memory.UnpinEntity(replaced.ID)
+	if err := memory.DeleteEntity(replaced.ID); err != nil {
+		t.Errorf("Expected the unpinned entity to be deleted, got %v", err)
+	}
+}
+
+func TestPinnedResultStaysCached(t *testing.T) {
+	service := createTestService()
+	queryID := uniqueTestName("FQ_pin_")
+	entity, err := service.memorySystem.CreateEntity(queryID+"-162112-snap-1", "nqe_result",
+		map[string]interface{}{"query_id": queryID, "network_id": "162112", "snapshot_id": "snap-1"})
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	if _, err := service.pinEntity(PinEntityArgs{EntityID: entity.ID}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer service.unpinEntity(UnpinEntityArgs{EntityID: entity.ID})
+
+	pinned := &CacheEntry{Query: "query_id:" + queryID + "|params:map[]", NetworkID: "162112", SnapshotID: "snap-1"}
+	other := &CacheEntry{Query: "query_id:" + queryID + "|params:map[]", NetworkID: "162112", SnapshotID: "snap-2"}
+	if !service.semanticCache.isPinned(pinned) || service.semanticCache.isPinned(other) {
+		t.Error("Expected only the cached result of the pinned snapshot to be pinned")
+	}
+}
+
+func TestSemanticCacheKeepsPinnedEntries(t *testing.T) {
+	cache := NewSemanticCache(nil, logger.New(), "test", &config.SemanticCacheConfig{
+		Enabled: true, MaxEntries: 2, TTLHours: 1, MaxMemoryMB: 10, EvictionPolicy: config.EvictionPolicyLRU,
+	})
+	defer cache.stopCleanupRoutine()
+	cache.SetPinned(func(entry *CacheEntry) bool { return entry.Query == "golden" })
+
+	result := &forward.NQERunResult{Items: []map[string]interface{}{{"device": "core1"}}}
+	for _, query := range []string{"golden", "a", "b", "c"} {
+		if err := cache.Put(query, "n1", "s1", result); err != nil {
+			t.Fatalf("Put %s failed: %v", query, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, found := cache.Get("golden", "n1", "s1"); !found {
+		t.Error("Expected the pinned entry to survive eviction")
+	}
+	if _, found := cache.Get("a", "n1", "s1"); found {
+		t.Error("Expected the oldest unpinned entry to be evicted")
+	}
+
+	for _, entry := range cache.entries {
+		entry.Timestamp = time.Now().Add(-2 * time.Hour)
+	}
+	cache.ClearExpired()
+	if _, found := cache.Get("golden", "n1", "s1"); !found || len(cache.entries) != 1 {
+		t.Errorf("Expected only the pinned entry to outlive its TTL, %d entries left", len(cache.entries))
+	}
+}
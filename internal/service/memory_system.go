@@ -1,7 +1,9 @@
 package service
 
 import (
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -68,6 +70,10 @@ type MemorySystem struct {
 	logger     *logger.Logger
 	dbPath     string
 	instanceID string
+	onStore    func(*Entity)      // Called with each entity created or reused as the latest version
+	visible    func(*Entity) bool // Hides entities from reads when it returns false (nil shows every entity)
+
+	versionMutex sync.Mutex // Serializes version chain updates
 }
 
 // NewMemorySystem creates a new memory system instance
@@ -107,6 +113,35 @@ func NewMemorySystem(logger *logger.Logger, instanceID string) (*MemorySystem, e
 	return memory, nil
 }
 
+// SetStoreHook registers a function called with every entity created, and with the latest
+// version of a versioned entity when storing unchanged content reuses it
+func (m *MemorySystem) SetStoreHook(hook func(*Entity)) {
+	m.onStore = hook
+}
+
+// SetVisibility hides the entities visible rejects from lookups and searches, along with
+// their observations and relations, e.g. the stored results of restricted networks
+func (m *MemorySystem) SetVisibility(visible func(*Entity) bool) {
+	m.visible = visible
+}
+
+// isVisible reports whether reads may return the entity
+func (m *MemorySystem) isVisible(entity *Entity) bool {
+	return m.visible == nil || m.visible(entity)
+}
+
+// checkVisible returns an error for an entity hidden from reads. Unknown IDs pass, so callers
+// report them as they always have.
+func (m *MemorySystem) checkVisible(entityID string) error {
+	if m.visible == nil {
+		return nil
+	}
+	if entity, err := m.getEntityByID(entityID); err == nil && !m.visible(entity) {
+		return fmt.Errorf("entity not found: %s", entityID)
+	}
+	return nil
+}
+
 // initSchema creates the database tables for the memory system
 func (m *MemorySystem) initSchema() error {
 	schema := `
@@ -148,6 +183,36 @@ func (m *MemorySystem) initSchema() error {
 		FOREIGN KEY(entity_id) REFERENCES entities(id) ON DELETE CASCADE
 	);
 
+	-- Deleted entities with their relations and observations, restorable for a while
+	CREATE TABLE IF NOT EXISTS entity_trash (
+		id TEXT NOT NULL,
+		instance_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		type TEXT NOT NULL,
+		deleted_at INTEGER NOT NULL,
+		payload TEXT NOT NULL,
+		PRIMARY KEY(instance_id, id)
+	);
+
+	CREATE TABLE IF NOT EXISTS entity_pins (
+		entity_id TEXT NOT NULL,
+		instance_id TEXT NOT NULL,
+		reason TEXT,
+		pinned_at INTEGER NOT NULL,
+		PRIMARY KEY(instance_id, entity_id)
+	);
+
+	-- Entities merged into another, so their IDs and names keep resolving
+	CREATE TABLE IF NOT EXISTS entity_merges (
+		source_id TEXT NOT NULL,
+		instance_id TEXT NOT NULL,
+		source_name TEXT NOT NULL,
+		source_type TEXT NOT NULL,
+		target_id TEXT NOT NULL,
+		merged_at INTEGER NOT NULL,
+		PRIMARY KEY(instance_id, source_id)
+	);
+
 	-- Indexes for better performance
 	CREATE INDEX IF NOT EXISTS idx_entities_instance_type ON entities(instance_id, type);
 	CREATE INDEX IF NOT EXISTS idx_entities_instance_name ON entities(instance_id, name);
@@ -165,11 +230,16 @@ func (m *MemorySystem) initSchema() error {
 	return nil
 }
 
-// CreateEntity creates a new entity in the knowledge graph
+// CreateEntity creates a new entity in the knowledge graph, replacing one of the same name and
+// type
 func (m *MemorySystem) CreateEntity(name, entityType string, metadata map[string]interface{}) (*Entity, error) {
 	entityID := fmt.Sprintf("entity_%d", time.Now().UnixNano())
 	now := time.Now()
 
+	// An entity of the same name and type is replaced below; its pin moves to the replacement
+	var replacedID string
+	m.db.QueryRow(`SELECT id FROM entities WHERE instance_id = ? AND name = ? AND type = ?`, m.instanceID, name, entityType).Scan(&replacedID)
+
 	var metadataJSON string
 	if metadata != nil {
 		data, err := json.Marshal(metadata)
@@ -187,6 +257,9 @@ func (m *MemorySystem) CreateEntity(name, entityType string, metadata map[string
 	if err != nil {
 		return nil, fmt.Errorf("failed to create entity: %w", err)
 	}
+	if replacedID != "" {
+		m.movePin(replacedID, entityID)
+	}
 
 	entity := &Entity{
 		ID:        entityID,
@@ -198,9 +271,28 @@ func (m *MemorySystem) CreateEntity(name, entityType string, metadata map[string
 	}
 
 	m.logger.Debug("Created entity: %s (%s)", name, entityType)
+	if m.onStore != nil {
+		m.onStore(entity)
+	}
 	return entity, nil
 }
 
+// updateEntityMetadata replaces an entity's metadata in place, keeping its ID and relations
+func (m *MemorySystem) updateEntityMetadata(entityID string, metadata map[string]interface{}) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	_, err = m.db.Exec(`
+		UPDATE entities SET metadata = ?, updated_at = ?
+		WHERE instance_id = ? AND id = ?
+	`, string(data), time.Now().Unix(), m.instanceID, entityID)
+	if err != nil {
+		return fmt.Errorf("failed to update entity: %w", err)
+	}
+	return nil
+}
+
 // CreateRelation creates a new relation between two entities
 func (m *MemorySystem) CreateRelation(fromID, toID, relationType string, properties map[string]interface{}) (*Relation, error) {
 	relationID := fmt.Sprintf("relation_%d", time.Now().UnixNano())
@@ -237,7 +329,8 @@ func (m *MemorySystem) CreateRelation(fromID, toID, relationType string, propert
 	return relation, nil
 }
 
-// AddObservation adds an observation to an entity
+// AddObservation adds an observation to an entity. Adding an observation identical to one
+// the entity already has returns the stored one instead of a duplicate.
 func (m *MemorySystem) AddObservation(entityID, content, observationType string, metadata map[string]interface{}) (*Observation, error) {
 	observationID := fmt.Sprintf("observation_%d", time.Now().UnixNano())
 	now := time.Now()
@@ -251,7 +344,27 @@ func (m *MemorySystem) AddObservation(entityID, content, observationType string,
 		metadataJSON = string(data)
 	}
 
-	_, err := m.db.Exec(`
+	// Identical observations (same content, type and metadata) are stored once
+	var existingID string
+	var existingCreatedAt int64
+	err := m.db.QueryRow(`
+		SELECT id, created_at FROM observations
+		WHERE instance_id = ? AND entity_id = ? AND type = ? AND content = ? AND COALESCE(metadata, '') = ?
+		LIMIT 1
+	`, m.instanceID, entityID, observationType, content, metadataJSON).Scan(&existingID, &existingCreatedAt)
+	if err == nil {
+		m.logger.Debug("Observation on entity %s already stored as %s", entityID, existingID)
+		return &Observation{
+			ID:        existingID,
+			EntityID:  entityID,
+			Content:   content,
+			Type:      observationType,
+			CreatedAt: time.Unix(existingCreatedAt, 0),
+			Metadata:  metadata,
+		}, nil
+	}
+
+	_, err = m.db.Exec(`
 		INSERT INTO observations (id, instance_id, entity_id, content, type, created_at, metadata)
 		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`, observationID, m.instanceID, entityID, content, observationType, now.Unix(), metadataJSON)
@@ -315,6 +428,9 @@ func (m *MemorySystem) SearchEntities(query string, entityType string, limit int
 		if err != nil {
 			return nil, err
 		}
+		if !m.isVisible(entity) {
+			continue
+		}
 		entities = append(entities, entity)
 	}
 
@@ -328,17 +444,18 @@ func (m *MemorySystem) GetEntity(identifier string) (*Entity, error) {
 
 	// Try by ID first
 	entity, err = m.getEntityByID(identifier)
-	if err == nil {
-		return entity, nil
+	if err != nil {
+		// Try by name
+		entity, err = m.getEntityByName(identifier)
 	}
-
-	// Try by name
-	entity, err = m.getEntityByName(identifier)
-	if err == nil {
-		return entity, nil
+	if err != nil {
+		// Try the ID or name of an entity merged into another
+		entity, err = m.getMergedEntity(identifier)
 	}
-
-	return nil, fmt.Errorf("entity not found: %s", identifier)
+	if err != nil || !m.isVisible(entity) {
+		return nil, fmt.Errorf("entity not found: %s", identifier)
+	}
+	return entity, nil
 }
 
 // getEntityByID retrieves an entity by ID
@@ -365,8 +482,60 @@ func (m *MemorySystem) getEntityByName(name string) (*Entity, error) {
 	return m.scanEntityRow(row)
 }
 
+// getEntityByNameAndType retrieves an entity by name and type
+func (m *MemorySystem) getEntityByNameAndType(name, entityType string) (*Entity, error) {
+	row := m.db.QueryRow(`
+		SELECT id, name, type, created_at, updated_at, metadata
+		FROM entities
+		WHERE instance_id = ? AND name = ? AND type = ?
+	`, m.instanceID, name, entityType)
+
+	return m.scanEntityRow(row)
+}
+
+// entitiesByNamePrefix retrieves up to limit entities of the type whose names start with
+// namePrefix, through the name index
+func (m *MemorySystem) entitiesByNamePrefix(entityType, namePrefix string, limit int) ([]*Entity, error) {
+	rows, err := m.db.Query(`
+		SELECT id, name, type, created_at, updated_at, metadata
+		FROM entities
+		WHERE instance_id = ? AND name >= ? AND name < ? AND type = ?
+		ORDER BY name
+		LIMIT ?
+	`, m.instanceID, namePrefix, namePrefix+"\xff", entityType, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search entities: %w", err)
+	}
+	defer rows.Close()
+
+	var entities []*Entity
+	for rows.Next() {
+		entity, err := m.scanEntity(rows)
+		if err != nil {
+			return nil, err
+		}
+		if m.isVisible(entity) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, rows.Err()
+}
+
+// hasRelation reports whether a relation of the type already links the two entities
+func (m *MemorySystem) hasRelation(fromID, toID, relationType string) bool {
+	var count int
+	err := m.db.QueryRow(`
+		SELECT COUNT(*) FROM relations
+		WHERE instance_id = ? AND from_id = ? AND to_id = ? AND type = ?
+	`, m.instanceID, fromID, toID, relationType).Scan(&count)
+	return err == nil && count > 0
+}
+
 // GetRelations retrieves relations for an entity
 func (m *MemorySystem) GetRelations(entityID string, relationType string) ([]*Relation, error) {
+	if err := m.checkVisible(entityID); err != nil {
+		return nil, err
+	}
 	var whereClause string
 	var args []interface{}
 
@@ -398,12 +567,33 @@ func (m *MemorySystem) GetRelations(entityID string, relationType string) ([]*Re
 		}
 		relations = append(relations, relation)
 	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// Relations to hidden entities are left out with them
+	if m.visible != nil {
+		linked := relations[:0]
+		for _, relation := range relations {
+			other := relation.ToID
+			if other == entityID {
+				other = relation.FromID
+			}
+			if m.checkVisible(other) == nil {
+				linked = append(linked, relation)
+			}
+		}
+		relations = linked
+	}
 
 	return relations, nil
 }
 
 // GetObservations retrieves observations for an entity
 func (m *MemorySystem) GetObservations(entityID string, observationType string) ([]*Observation, error) {
+	if err := m.checkVisible(entityID); err != nil {
+		return nil, err
+	}
 	var whereClause string
 	var args []interface{}
 
@@ -439,8 +629,34 @@ func (m *MemorySystem) GetObservations(entityID string, observationType string)
 	return observations, nil
 }
 
-// DeleteEntity removes an entity and all its relations and observations
+// DeleteEntity removes an entity and all its relations and observations; pinned entities are
+// refused
 func (m *MemorySystem) DeleteEntity(entityID string) error {
+	if m.IsPinned(entityID) {
+		return fmt.Errorf("entity %s is pinned; release it with unpin_entity before deleting it", entityID)
+	}
+	return m.deleteEntity(entityID)
+}
+
+// ReplaceEntity deletes the entity of a name and type, with its relations and observations,
+// and creates a new one in its place. A pin on the old entity moves to the new one.
+func (m *MemorySystem) ReplaceEntity(name, entityType string, metadata map[string]interface{}) (*Entity, error) {
+	if existing, err := m.getEntityByNameAndType(name, entityType); err == nil {
+		if err := m.deleteEntity(existing.ID); err != nil {
+			return nil, err
+		}
+		entity, err := m.CreateEntity(name, entityType, metadata)
+		if err != nil {
+			return nil, err
+		}
+		m.movePin(existing.ID, entity.ID)
+		return entity, nil
+	}
+	return m.CreateEntity(name, entityType, metadata)
+}
+
+// deleteEntity removes an entity whether or not it is pinned
+func (m *MemorySystem) deleteEntity(entityID string) error {
 	_, err := m.db.Exec(`
 		DELETE FROM entities WHERE instance_id = ? AND id = ?
 	`, m.instanceID, entityID)
@@ -532,6 +748,34 @@ func (m *MemorySystem) GetMemoryStats() (map[string]interface{}, error) {
 	}
 	stats["entity_types"] = entityTypes
 
+	// Deleted entities waiting in the trash
+	trash, err := m.GetTrashStats()
+	if err != nil {
+		return nil, err
+	}
+	stats["trash_count"] = trash.Count
+	stats["trash_bytes"] = trash.Bytes
+	if !trash.Oldest.IsZero() {
+		stats["trash_oldest_deleted_at"] = trash.Oldest.UTC().Format(time.RFC3339)
+	}
+
+	// Pinned entities are kept by deletion, cleanup and cache eviction
+	pins, err := m.PinnedEntities()
+	if err != nil {
+		return nil, err
+	}
+	visiblePins := pins[:0]
+	for _, pin := range pins {
+		if m.isVisible(&Entity{ID: pin.EntityID, Name: pin.Name, Type: pin.Type, Metadata: pin.Metadata}) {
+			visiblePins = append(visiblePins, pin)
+		}
+	}
+	pins = visiblePins
+	stats["pinned_count"] = len(pins)
+	if len(pins) > 0 {
+		stats["pinned_entities"] = pins
+	}
+
 	stats["database_path"] = m.dbPath
 	stats["instance_id"] = m.instanceID
 
@@ -662,42 +906,97 @@ func (m *MemorySystem) scanObservation(rows *sql.Rows) (*Observation, error) {
 	return &observation, nil
 }
 
-// StoreNQEResultWithChunking stores a large NQE result in chunked observations for LLM-friendly retrieval
-func (m *MemorySystem) StoreNQEResultWithChunking(queryID, networkID, snapshotID string, result *forward.NQERunResult, chunkSize int) (string, error) {
-	if chunkSize <= 0 {
-		chunkSize = 200 // Default chunk size if not specified
+// defaultChunkTargetBytes is the serialized size a stored result chunk aims for, roughly
+// 8k tokens, so wide rows no longer produce enormous chunks
+const defaultChunkTargetBytes = 32 * 1024
+
+// resultChunk is a contiguous run of rows serialized as one JSON array
+type resultChunk struct {
+	Start int // First row, inclusive
+	End   int // Last row, exclusive
+	JSON  []byte
+}
+
+// chunkRowsBySize splits rows into chunks whose serialized size stays near targetBytes.
+// A row larger than the target gets a chunk of its own.
+func chunkRowsBySize(items []map[string]interface{}, targetBytes int) ([]resultChunk, error) {
+	if targetBytes <= 0 {
+		targetBytes = defaultChunkTargetBytes
 	}
-	// 1. Create result entity
-	entity, err := m.CreateEntity(
+	var chunks []resultChunk
+	current := resultChunk{JSON: []byte{'['}}
+	for i, item := range items {
+		row, err := json.Marshal(item)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal row %d: %w", i, err)
+		}
+		if current.End > current.Start && len(current.JSON)+len(row)+1 > targetBytes {
+			chunks = append(chunks, closeResultChunk(current))
+			current = resultChunk{Start: i, End: i, JSON: []byte{'['}}
+		}
+		if current.End > current.Start {
+			current.JSON = append(current.JSON, ',')
+		}
+		current.JSON = append(current.JSON, row...)
+		current.End = i + 1
+	}
+	if current.End > current.Start {
+		chunks = append(chunks, closeResultChunk(current))
+	}
+	return chunks, nil
+}
+
+func closeResultChunk(chunk resultChunk) resultChunk {
+	chunk.JSON = append(chunk.JSON, ']')
+	return chunk
+}
+
+// StoreNQEResultWithChunking stores a large NQE result in chunked observations for LLM-friendly
+// retrieval. Chunk boundaries follow serialized size (targetBytes, default 32KB) rather than
+// a fixed row count; each chunk records its row range in metadata.
+func (m *MemorySystem) StoreNQEResultWithChunking(queryID, networkID, snapshotID string, result *forward.NQERunResult, targetBytes int) (string, error) {
+	if targetBytes <= 0 {
+		targetBytes = defaultChunkTargetBytes
+	}
+	// 1. Create result entity, or reuse the latest version when its content is unchanged
+	hash, err := contentHash(result.Items)
+	if err != nil {
+		return "", err
+	}
+	entity, created, err := m.storeEntityVersion(
 		fmt.Sprintf("%s-%s-%s", queryID, networkID, snapshotID),
 		"nqe_result",
 		map[string]interface{}{
 			"query_id": queryID, "network_id": networkID, "snapshot_id": snapshotID,
 			"row_count": len(result.Items),
 		},
+		hash,
 	)
 	if err != nil {
 		return "", err
 	}
+	if !created {
+		m.logger.Debug("Result of %s on %s/%s is unchanged, reusing %s", queryID, networkID, snapshotID, entity.ID)
+		return entity.ID, nil
+	}
 
 	totalRows := len(result.Items)
-	totalChunks := (totalRows + chunkSize - 1) / chunkSize
-	for i := 0; i < totalChunks; i++ {
-		start := i * chunkSize
-		end := start + chunkSize
-		if end > totalRows {
-			end = totalRows
-		}
-		chunk := result.Items[start:end]
-		chunkJSON, _ := json.Marshal(chunk)
+	chunks, err := chunkRowsBySize(result.Items, targetBytes)
+	if err != nil {
+		return "", err
+	}
+	totalChunks := len(chunks)
+	for i, chunk := range chunks {
 		_, err := m.AddObservation(
 			entity.ID,
-			string(chunkJSON),
+			string(chunk.JSON),
 			"nqe_result_chunk",
 			map[string]interface{}{
 				"chunk_index":  i,
 				"total_chunks": totalChunks,
-				"row_range":    []int{start, end - 1},
+				"row_range":    []int{chunk.Start, chunk.End - 1},
+				"row_count":    chunk.End - chunk.Start,
+				"bytes":        len(chunk.JSON),
 			},
 		)
 		if err != nil {
@@ -716,6 +1015,7 @@ func (m *MemorySystem) StoreNQEResultWithChunking(queryID, networkID, snapshotID
 		"columns":      columns,
 		"row_count":    totalRows,
 		"total_chunks": totalChunks,
+		"chunk_bytes":  targetBytes,
 		"query_id":     queryID,
 		"network_id":   networkID,
 		"snapshot_id":  snapshotID,
@@ -728,6 +1028,19 @@ func (m *MemorySystem) StoreNQEResultWithChunking(queryID, networkID, snapshotID
 
 // GetNQEResultChunks retrieves all chunk observations for a result entity, ordered by chunk_index
 func (m *MemorySystem) GetNQEResultChunks(resultEntityID string) ([]string, error) {
+	obs, err := m.getNQEResultChunkObservations(resultEntityID)
+	if err != nil {
+		return nil, err
+	}
+	chunks := make([]string, len(obs))
+	for i, o := range obs {
+		chunks[i] = o.Content
+	}
+	return chunks, nil
+}
+
+// getNQEResultChunkObservations returns the chunk observations of a result ordered by chunk_index
+func (m *MemorySystem) getNQEResultChunkObservations(resultEntityID string) ([]*Observation, error) {
 	obs, err := m.GetObservations(resultEntityID, "nqe_result_chunk")
 	if err != nil {
 		return nil, err
@@ -738,9 +1051,111 @@ func (m *MemorySystem) GetNQEResultChunks(resultEntityID string) ([]string, erro
 		cj, _ := obs[j].Metadata["chunk_index"].(float64)
 		return ci < cj
 	})
-	chunks := make([]string, len(obs))
-	for i, o := range obs {
-		chunks[i] = o.Content
+	return obs, nil
+}
+
+// NQEResultVersion fingerprints a stored result's entity and chunk observations without
+// reading chunk content, so derived data can be invalidated when the result changes
+func (m *MemorySystem) NQEResultVersion(resultEntityID string) (string, error) {
+	entity, err := m.getEntityByID(resultEntityID)
+	if err != nil {
+		return "", fmt.Errorf("entity not found: %s", resultEntityID)
 	}
-	return chunks, nil
+
+	rows, err := m.db.Query(`
+		SELECT id, created_at, LENGTH(content)
+		FROM observations
+		WHERE instance_id = ? AND entity_id = ? AND type = 'nqe_result_chunk'
+		ORDER BY id
+	`, m.instanceID, resultEntityID)
+	if err != nil {
+		return "", fmt.Errorf("failed to query chunk observations: %w", err)
+	}
+	defer rows.Close()
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s|%d", entity.ID, entity.UpdatedAt.UnixNano())
+	for rows.Next() {
+		var id string
+		var createdAt, length int64
+		if err := rows.Scan(&id, &createdAt, &length); err != nil {
+			return "", fmt.Errorf("failed to scan chunk observation: %w", err)
+		}
+		fmt.Fprintf(hash, "|%s:%d:%d", id, createdAt, length)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// GetNQEResultRows returns rows firstRow through lastRow (zero-based, inclusive) of a stored
+// result, decoding only the chunks whose row range overlaps. It also returns the total row count.
+func (m *MemorySystem) GetNQEResultRows(resultEntityID string, firstRow, lastRow int) ([]map[string]interface{}, int, error) {
+	var rows []map[string]interface{}
+	total, err := m.ScanNQEResultRows(resultEntityID, firstRow, lastRow, func(index int, row map[string]interface{}) bool {
+		rows = append(rows, row)
+		return true
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+// ScanNQEResultRows calls visit for each row from firstRow through lastRow (zero-based,
+// inclusive), decoding one overlapping chunk at a time. Returning false from visit stops
+// decoding further chunks. It returns the total row count of the result.
+func (m *MemorySystem) ScanNQEResultRows(resultEntityID string, firstRow, lastRow int, visit func(index int, row map[string]interface{}) bool) (int, error) {
+	obs, err := m.getNQEResultChunkObservations(resultEntityID)
+	if err != nil {
+		return 0, err
+	}
+
+	offset := 0
+	scanning := true
+	for _, o := range obs {
+		var chunkRows []map[string]interface{}
+		start, end, ok := chunkRowRange(o.Metadata)
+		if !ok {
+			// Chunks without a recorded range must be decoded to count their rows
+			if err := json.Unmarshal([]byte(o.Content), &chunkRows); err != nil {
+				return 0, fmt.Errorf("failed to unmarshal chunk: %w", err)
+			}
+			start, end = offset, offset+len(chunkRows)-1
+		}
+		offset = end + 1
+		if !scanning || end < firstRow || start > lastRow {
+			continue
+		}
+
+		if chunkRows == nil {
+			if err := json.Unmarshal([]byte(o.Content), &chunkRows); err != nil {
+				return 0, fmt.Errorf("failed to unmarshal chunk: %w", err)
+			}
+		}
+		for i, row := range chunkRows {
+			if index := start + i; index >= firstRow && index <= lastRow {
+				if !visit(index, row) {
+					scanning = false
+					break
+				}
+			}
+		}
+	}
+	return offset, nil
+}
+
+// chunkRowRange reads the inclusive row_range recorded in chunk metadata
+func chunkRowRange(metadata map[string]interface{}) (int, int, bool) {
+	bounds, ok := metadata["row_range"].([]interface{})
+	if !ok || len(bounds) != 2 {
+		return 0, 0, false
+	}
+	start, okStart := bounds[0].(float64)
+	end, okEnd := bounds[1].(float64)
+	if !okStart || !okEnd {
+		return 0, 0, false
+	}
+	return int(start), int(end), true
 }
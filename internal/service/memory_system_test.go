@@ -1,11 +1,13 @@
 package service
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/forward-mcp/internal/forward"
 	"github.com/forward-mcp/internal/logger"
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -464,3 +466,97 @@ func TestMemorySystemClose(t *testing.T) {
 		t.Errorf("Expected no error on double close, got: %v", err)
 	}
 }
+
+func TestChunkRowsBySize(t *testing.T) {
+	items := []map[string]interface{}{
+		{"name": "a"},
+		{"name": "b"},
+		{"name": "a-very-wide-row-that-exceeds-the-target-on-its-own"},
+		{"name": "c"},
+	}
+
+	chunks, err := chunkRowsBySize(items, 30)
+	if err != nil {
+		t.Fatalf("Failed to chunk rows: %v", err)
+	}
+	if len(chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(chunks))
+	}
+	if chunks[0].Start != 0 || chunks[0].End != 2 || string(chunks[0].JSON) != `[{"name":"a"},{"name":"b"}]` {
+		t.Errorf("Unexpected first chunk: %d-%d %s", chunks[0].Start, chunks[0].End, chunks[0].JSON)
+	}
+	if chunks[1].Start != 2 || chunks[1].End != 3 {
+		t.Errorf("Expected the wide row in its own chunk, got %d-%d", chunks[1].Start, chunks[1].End)
+	}
+	if chunks[2].Start != 3 || chunks[2].End != 4 {
+		t.Errorf("Unexpected last chunk: %d-%d", chunks[2].Start, chunks[2].End)
+	}
+}
+
+func TestGetNQEResultRows(t *testing.T) {
+	memorySystem := createTestMemorySystem(t)
+	defer memorySystem.Close()
+
+	items := make([]map[string]interface{}, 50)
+	for i := range items {
+		items[i] = map[string]interface{}{"row": i, "device": fmt.Sprintf("device-%02d", i)}
+	}
+	entityID, err := memorySystem.StoreNQEResultWithChunking("FQ_rows", "162112", "snap-rows", &forward.NQERunResult{Items: items}, 256)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+
+	chunks, err := memorySystem.getNQEResultChunkObservations(entityID)
+	if err != nil {
+		t.Fatalf("Failed to get chunks: %v", err)
+	}
+	if len(chunks) < 2 {
+		t.Fatalf("Expected the result to span several chunks, got %d", len(chunks))
+	}
+	if start, _, ok := chunkRowRange(chunks[1].Metadata); !ok || start == 0 {
+		t.Errorf("Expected a recorded row range, got %v", chunks[1].Metadata)
+	}
+
+	rows, total, err := memorySystem.GetNQEResultRows(entityID, 10, 24)
+	if err != nil {
+		t.Fatalf("Failed to get rows: %v", err)
+	}
+	if total != 50 || len(rows) != 15 {
+		t.Fatalf("Expected 15 of 50 rows, got %d of %d", len(rows), total)
+	}
+	if rows[0]["row"] != float64(10) || rows[14]["row"] != float64(24) {
+		t.Errorf("Unexpected row bounds: %v .. %v", rows[0], rows[14])
+	}
+}
+
+func TestGetNQEResultChunksRowRange(t *testing.T) {
+	service := createTestService()
+	if service.memorySystem == nil {
+		t.Skip("memory system unavailable")
+	}
+
+	items := make([]map[string]interface{}, 20)
+	for i := range items {
+		items[i] = map[string]interface{}{"row": i}
+	}
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_range_tool", "162112", uniqueTestName("snap-range-"), &forward.NQERunResult{Items: items}, 64)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	defer service.memorySystem.DeleteEntity(entityID)
+
+	start, end := 15, 100
+	response, err := service.getNQEResultChunks(GetNQEResultChunksArgs{EntityID: entityID, StartRow: &start, EndRow: &end})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, `"row_range":[15,19]`) || !contains(text, `"total_rows":20`) {
+		t.Errorf("Unexpected range response: %s", text)
+	}
+
+	start = 25
+	if _, err := service.getNQEResultChunks(GetNQEResultChunksArgs{EntityID: entityID, StartRow: &start}); err == nil {
+		t.Error("Expected an out-of-range start_row to be rejected")
+	}
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// defaultTrashRetention is how long a deleted entity stays restorable when not configured
+const defaultTrashRetention = 7 * 24 * time.Hour
+
+// TrashedEntity is an entity moved to the trash together with its relations and observations
+type TrashedEntity struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Type         string    `json:"type"`
+	DeletedAt    time.Time `json:"deleted_at"`
+	Relations    int       `json:"relations"`
+	Observations int       `json:"observations"`
+	Bytes        int       `json:"bytes"`
+}
+
+// trashPayload holds the rows of a trashed entity exactly as stored, so a restore brings
+// back the same IDs, timestamps and (already redacted) observation content
+type trashPayload struct {
+	Entity       trashRow   `json:"entity"`
+	Relations    []trashRow `json:"relations"`
+	Observations []trashRow `json:"observations"`
+}
+
+// TrashStats summarizes the trash of an instance
+type TrashStats struct {
+	Count  int       `json:"count"`
+	Bytes  int64     `json:"bytes"`
+	Oldest time.Time `json:"oldest,omitempty"`
+}
+
+// entity decodes the trashed entity's name, type and metadata
+func (p trashPayload) entity() *Entity {
+	entity := &Entity{}
+	if len(p.Entity) < 6 {
+		return entity
+	}
+	entity.ID, _ = p.Entity[0].(string)
+	entity.Name, _ = p.Entity[1].(string)
+	entity.Type, _ = p.Entity[2].(string)
+	if metadata, ok := p.Entity[5].(string); ok && metadata != "" {
+		json.Unmarshal([]byte(metadata), &entity.Metadata)
+	}
+	return entity
+}
+
+// trashRow is one database row; columns are stored in table order
+type trashRow []interface{}
+
+// queryTrashRows reads every row of a query as trashRows
+func queryTrashRows(tx *sql.Tx, query string, args ...interface{}) ([]trashRow, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []trashRow
+	for rows.Next() {
+		values := make(trashRow, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, err
+		}
+		for i, value := range values {
+			if data, ok := value.([]byte); ok {
+				values[i] = string(data)
+			}
+		}
+		result = append(result, values)
+	}
+	return result, rows.Err()
+}
+
+// TrashEntity deletes an entity and its relations and observations but keeps a copy in the
+// trash, from which RestoreEntity can bring it back within the undo window
+func (m *MemorySystem) TrashEntity(entityID string) (*TrashedEntity, error) {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start delete: %w", err)
+	}
+	defer tx.Rollback()
+
+	var pinned int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM entity_pins WHERE instance_id = ? AND entity_id = ?`, m.instanceID, entityID).Scan(&pinned); err != nil {
+		return nil, fmt.Errorf("failed to check pin: %w", err)
+	}
+	if pinned > 0 {
+		return nil, fmt.Errorf("entity %s is pinned; release it with unpin_entity before deleting it", entityID)
+	}
+
+	payload, err := m.readTrashPayload(tx, entityID)
+	if err != nil {
+		return nil, err
+	}
+	trashed, err := m.moveToTrash(tx, payload)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to delete entity: %w", err)
+	}
+
+	m.logger.Debug("Moved entity %s to trash", entityID)
+	return trashed, nil
+}
+
+// readTrashPayload reads the rows of an entity with its relations and observations
+func (m *MemorySystem) readTrashPayload(tx *sql.Tx, entityID string) (trashPayload, error) {
+	entities, err := queryTrashRows(tx, `
+		SELECT id, name, type, created_at, updated_at, metadata
+		FROM entities WHERE instance_id = ? AND id = ?
+	`, m.instanceID, entityID)
+	if err != nil {
+		return trashPayload{}, fmt.Errorf("failed to read entity: %w", err)
+	}
+	if len(entities) == 0 {
+		return trashPayload{}, fmt.Errorf("entity not found: %s", entityID)
+	}
+	payload := trashPayload{Entity: entities[0]}
+	if payload.Relations, err = queryTrashRows(tx, `
+		SELECT id, from_id, to_id, type, created_at, properties
+		FROM relations WHERE instance_id = ? AND (from_id = ? OR to_id = ?)
+	`, m.instanceID, entityID, entityID); err != nil {
+		return trashPayload{}, fmt.Errorf("failed to read relations: %w", err)
+	}
+	if payload.Observations, err = queryTrashRows(tx, `
+		SELECT id, entity_id, content, type, created_at, metadata
+		FROM observations WHERE instance_id = ? AND entity_id = ?
+	`, m.instanceID, entityID); err != nil {
+		return trashPayload{}, fmt.Errorf("failed to read observations: %w", err)
+	}
+	return payload, nil
+}
+
+// moveToTrash stores a payload read by readTrashPayload in the trash and deletes its entity
+func (m *MemorySystem) moveToTrash(tx *sql.Tx, payload trashPayload) (*TrashedEntity, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entity: %w", err)
+	}
+	trashed := &TrashedEntity{
+		ID:           fmt.Sprint(payload.Entity[0]),
+		Name:         fmt.Sprint(payload.Entity[1]),
+		Type:         fmt.Sprint(payload.Entity[2]),
+		DeletedAt:    time.Now(),
+		Relations:    len(payload.Relations),
+		Observations: len(payload.Observations),
+		Bytes:        len(data),
+	}
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO entity_trash (id, instance_id, name, type, deleted_at, payload)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, trashed.ID, m.instanceID, trashed.Name, trashed.Type, trashed.DeletedAt.Unix(), string(data)); err != nil {
+		return nil, fmt.Errorf("failed to move entity to trash: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM entities WHERE instance_id = ? AND id = ?`, m.instanceID, trashed.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete entity: %w", err)
+	}
+	return trashed, nil
+}
+
+// RestoreEntity brings an entity back from the trash if it was deleted within the retention
+// period. Relations to entities that no longer exist are dropped; the number of restored
+// relations is returned. Restoring a merged entity takes its observations and relations
+// back from the entity it was merged into.
+func (m *MemorySystem) RestoreEntity(entityID string, retention time.Duration) (*Entity, int, error) {
+	var data string
+	var deletedAt int64
+	err := m.db.QueryRow(`
+		SELECT payload, deleted_at FROM entity_trash WHERE instance_id = ? AND id = ?
+	`, m.instanceID, entityID).Scan(&data, &deletedAt)
+	if err == sql.ErrNoRows {
+		return nil, 0, fmt.Errorf("entity %s is not in the trash", entityID)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read trash: %w", err)
+	}
+	if retention > 0 && time.Since(time.Unix(deletedAt, 0)) > retention {
+		return nil, 0, fmt.Errorf("entity %s was deleted more than %s ago and can no longer be restored", entityID, formatRetention(retention))
+	}
+	var payload trashPayload
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return nil, 0, fmt.Errorf("trash entry for %s is corrupt: %w", entityID, err)
+	}
+	if !m.isVisible(payload.entity()) {
+		return nil, 0, fmt.Errorf("entity %s is not in the trash", entityID)
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to start restore: %w", err)
+	}
+	defer tx.Rollback()
+
+	row := payload.Entity
+	if _, err := tx.Exec(`
+		INSERT INTO entities (id, instance_id, name, type, created_at, updated_at, metadata)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, row[0], m.instanceID, row[1], row[2], row[3], row[4], row[5]); err != nil {
+		return nil, 0, fmt.Errorf("cannot restore %s: an entity named %v (%v) exists again: %w", entityID, row[1], row[2], err)
+	}
+	for _, row := range payload.Observations {
+		if _, err := tx.Exec(`
+			INSERT OR REPLACE INTO observations (id, instance_id, entity_id, content, type, created_at, metadata)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, row[0], m.instanceID, row[1], row[2], row[3], row[4], row[5]); err != nil {
+			return nil, 0, fmt.Errorf("failed to restore observation: %w", err)
+		}
+	}
+	restored := 0
+	for _, row := range payload.Relations {
+		result, err := tx.Exec(`
+			INSERT OR REPLACE INTO relations (id, instance_id, from_id, to_id, type, created_at, properties)
+			SELECT ?, ?, ?, ?, ?, ?, ?
+			WHERE (SELECT COUNT(*) FROM entities WHERE instance_id = ? AND id IN (?, ?)) = 2
+		`, row[0], m.instanceID, row[1], row[2], row[3], row[4], row[5], m.instanceID, row[1], row[2])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to restore relation: %w", err)
+		}
+		if affected, _ := result.RowsAffected(); affected > 0 {
+			restored++
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM entity_trash WHERE instance_id = ? AND id = ?`, m.instanceID, entityID); err != nil {
+		return nil, 0, fmt.Errorf("failed to clear trash entry: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM entity_merges WHERE instance_id = ? AND source_id = ?`, m.instanceID, entityID); err != nil {
+		return nil, 0, fmt.Errorf("failed to clear merge record: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, 0, fmt.Errorf("failed to restore entity: %w", err)
+	}
+
+	entity, err := m.getEntityByID(entityID)
+	if err != nil {
+		return nil, 0, err
+	}
+	m.logger.Debug("Restored entity %s from trash", entityID)
+	return entity, restored, nil
+}
+
+// PurgeTrash permanently removes entities deleted before the cutoff
+func (m *MemorySystem) PurgeTrash(before time.Time) (int, error) {
+	result, err := m.db.Exec(`DELETE FROM entity_trash WHERE instance_id = ? AND deleted_at < ?`, m.instanceID, before.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge trash: %w", err)
+	}
+	purged, _ := result.RowsAffected()
+	return int(purged), nil
+}
+
+// ListTrash returns trashed entities, most recently deleted first
+func (m *MemorySystem) ListTrash(limit int) ([]TrashedEntity, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+	rows, err := m.db.Query(`
+		SELECT id, name, type, deleted_at, payload FROM entity_trash
+		WHERE instance_id = ? ORDER BY deleted_at DESC, name LIMIT ?
+	`, m.instanceID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	var trashed []TrashedEntity
+	for rows.Next() {
+		var entry TrashedEntity
+		var deletedAt int64
+		var data string
+		if err := rows.Scan(&entry.ID, &entry.Name, &entry.Type, &deletedAt, &data); err != nil {
+			return nil, fmt.Errorf("failed to read trash: %w", err)
+		}
+		var payload trashPayload
+		if err := json.Unmarshal([]byte(data), &payload); err == nil {
+			if !m.isVisible(payload.entity()) {
+				continue
+			}
+			entry.Relations, entry.Observations = len(payload.Relations), len(payload.Observations)
+		}
+		entry.DeletedAt = time.Unix(deletedAt, 0)
+		entry.Bytes = len(data)
+		trashed = append(trashed, entry)
+	}
+	return trashed, rows.Err()
+}
+
+// GetTrashStats counts the trashed entities and the size of their stored rows
+func (m *MemorySystem) GetTrashStats() (TrashStats, error) {
+	var stats TrashStats
+	var oldest sql.NullInt64
+	err := m.db.QueryRow(`
+		SELECT COUNT(*), COALESCE(SUM(LENGTH(payload)), 0), MIN(deleted_at) FROM entity_trash WHERE instance_id = ?
+	`, m.instanceID).Scan(&stats.Count, &stats.Bytes, &oldest)
+	if err != nil {
+		return stats, fmt.Errorf("failed to count trash: %w", err)
+	}
+	if oldest.Valid {
+		stats.Oldest = time.Unix(oldest.Int64, 0)
+	}
+	return stats, nil
+}
+
+// formatRetention renders a retention period in whole hours or days
+func formatRetention(retention time.Duration) string {
+	if retention >= 24*time.Hour && retention%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d days", int(retention/(24*time.Hour)))
+	}
+	if retention >= time.Hour {
+		return fmt.Sprintf("%d hours", int(retention/time.Hour))
+	}
+	return retention.String()
+}
+
+// trashRetention returns how long deleted entities stay restorable
+func (s *ForwardMCPService) trashRetention() time.Duration {
+	if s.config != nil && s.config.Forward.TrashRetentionHours > 0 {
+		return time.Duration(s.config.Forward.TrashRetentionHours) * time.Hour
+	}
+	return defaultTrashRetention
+}
+
+// purgeExpiredTrash permanently removes entities deleted longer ago than the retention period
+func (s *ForwardMCPService) purgeExpiredTrash() {
+	if s.memorySystem == nil {
+		return
+	}
+	purged, err := s.memorySystem.PurgeTrash(time.Now().Add(-s.trashRetention()))
+	if err != nil {
+		s.logger.Warn("Failed to purge expired trash: %v", err)
+		return
+	}
+	if purged > 0 {
+		s.logger.Info("Permanently deleted %d entities from the trash after %s", purged, formatRetention(s.trashRetention()))
+	}
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+)
+
+func TestFormatRetention(t *testing.T) {
+	tests := map[time.Duration]string{
+		7 * 24 * time.Hour: "7 days",
+		36 * time.Hour:     "36 hours",
+		30 * time.Minute:   "30m0s",
+	}
+	for retention, expected := range tests {
+		if text := formatRetention(retention); text != expected {
+			t.Errorf("Expected %s, got %s", expected, text)
+		}
+	}
+}
+
+func TestTrashRetentionAndPurge(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	memory := service.memorySystem
+	if retention := service.trashRetention(); retention != defaultTrashRetention {
+		t.Errorf("Expected the default retention, got %s", retention)
+	}
+	service.config = &config.Config{Forward: config.ForwardConfig{TrashRetentionHours: 2}}
+
+	old, _ := memory.CreateEntity("old-result", "nqe_result", nil)
+	memory.AddObservation(old.ID, "expensive rows", "nqe_chunk", nil)
+	recent, _ := memory.CreateEntity("recent-note", "note", nil)
+	for _, entity := range []*Entity{old, recent} {
+		if _, err := memory.TrashEntity(entity.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := memory.db.Exec(`UPDATE entity_trash SET deleted_at = ? WHERE id = ?`, time.Now().Add(-3*time.Hour).Unix(), old.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := memory.RestoreEntity(old.ID, service.trashRetention()); err == nil || !strings.Contains(err.Error(), "more than 2 hours ago") {
+		t.Errorf("Expected the retention period to have passed, got %v", err)
+	}
+
+	stats, err := memory.GetTrashStats()
+	if err != nil || stats.Count != 2 || stats.Bytes == 0 {
+		t.Fatalf("Expected two trashed entities, got %+v %v", stats, err)
+	}
+
+	// Listing the trash hard-deletes what is past the retention period
+	response, err := service.listTrash(ListTrashArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Trash (1 entities, kept for 2 hours)") || !strings.Contains(text, "recent-note") || strings.Contains(text, "old-result") {
+		t.Errorf("Expected only the recent entity, got %s", text)
+	}
+
+	response, err = service.getMemoryStats(GetMemoryStatsArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `"trash_count": 1`) || !strings.Contains(text, `"trash_retention_hours": 2`) {
+		t.Errorf("Expected trash figures in the stats, got %s", text)
+	}
+
+	if _, _, err := memory.RestoreEntity(recent.ID, service.trashRetention()); err != nil {
+		t.Fatalf("Expected the recent entity to be restorable, got %v", err)
+	}
+	response, _ = service.listTrash(ListTrashArgs{})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "The trash is empty") {
+		t.Errorf("Expected an empty trash, got %s", text)
+	}
+}
+
+func TestRestoreEntityNameConflict(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	memory := createTestService().memorySystem
+
+	entity, _ := memory.CreateEntity("router-9", "device", nil)
+	if _, err := memory.TrashEntity(entity.ID); err != nil {
+		t.Fatal(err)
+	}
+	memory.CreateEntity("router-9", "device", nil)
+	if _, _, err := memory.RestoreEntity(entity.ID, defaultTrashRetention); err == nil || !strings.Contains(err.Error(), "exists again") {
+		t.Errorf("Expected a name conflict, got %v", err)
+	}
+}
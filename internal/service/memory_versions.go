@@ -0,0 +1,191 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// relationSupersedes links a newer version of an entity to the version it replaces
+const relationSupersedes = "supersedes"
+
+// maxVersionChain bounds version chain walks in case relations form a cycle
+const maxVersionChain = 1000
+
+// contentHash returns the hex SHA-256 of a value's JSON encoding. Map keys are encoded in
+// sorted order, so equal results hash equally.
+func contentHash(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash content: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// storeEntityVersion stores content identified by hash as the latest version of the entity
+// with name and type. When the latest version already holds the same content it is returned
+// with created false. Otherwise the latest version is renamed name@v<N>, the new version
+// takes the plain name, and a "supersedes" relation links it to the version it replaces.
+func (m *MemorySystem) storeEntityVersion(name, entityType string, metadata map[string]interface{}, hash string) (*Entity, bool, error) {
+	m.versionMutex.Lock()
+	defer m.versionMutex.Unlock()
+
+	if metadata == nil {
+		metadata = make(map[string]interface{})
+	}
+	version := 1
+	previous, err := m.getEntityByNameAndType(name, entityType)
+	if err == nil {
+		if previous.Metadata["content_hash"] == hash {
+			// Unchanged content counts as the latest run
+			if _, err := m.db.Exec(`UPDATE entities SET updated_at = ? WHERE instance_id = ? AND id = ?`,
+				time.Now().Unix(), m.instanceID, previous.ID); err != nil {
+				return nil, false, fmt.Errorf("failed to refresh entity: %w", err)
+			}
+			if m.onStore != nil {
+				m.onStore(previous)
+			}
+			return previous, false, nil
+		}
+		previousVersion := entityVersion(previous)
+		version = previousVersion + 1
+		archived := make(map[string]interface{}, len(previous.Metadata)+1)
+		for key, value := range previous.Metadata {
+			archived[key] = value
+		}
+		archived["superseded"] = true
+		archivedJSON, _ := json.Marshal(archived)
+		if _, err := m.db.Exec(`UPDATE entities SET name = ?, metadata = ? WHERE instance_id = ? AND id = ?`,
+			fmt.Sprintf("%s@v%d", name, previousVersion), string(archivedJSON), m.instanceID, previous.ID); err != nil {
+			return nil, false, fmt.Errorf("failed to archive previous version: %w", err)
+		}
+	} else {
+		previous = nil
+	}
+
+	metadata["content_hash"] = hash
+	metadata["version"] = version
+	entity, err := m.CreateEntity(name, entityType, metadata)
+	if err != nil {
+		return nil, false, err
+	}
+	if previous != nil {
+		if _, err := m.CreateRelation(entity.ID, previous.ID, relationSupersedes, map[string]interface{}{
+			"version":          version,
+			"previous_version": version - 1,
+		}); err != nil {
+			m.logger.Warn("Failed to link version %d of %s: %v", version, name, err)
+		}
+	}
+	return entity, true, nil
+}
+
+// isSupersededVersion reports whether a newer version of the entity has been stored
+func isSupersededVersion(entity *Entity) bool {
+	superseded, _ := entity.Metadata["superseded"].(bool)
+	return superseded
+}
+
+// entityVersion returns the version recorded in an entity's metadata, 1 when unversioned
+func entityVersion(entity *Entity) int {
+	switch version := entity.Metadata["version"].(type) {
+	case float64:
+		return int(version)
+	case int:
+		return version
+	}
+	return 1
+}
+
+// GetLatestVersion follows "supersedes" relations from any version of an entity to the
+// newest one. Unversioned entities are their own latest version.
+func (m *MemorySystem) GetLatestVersion(entityID string) (*Entity, error) {
+	entity, err := m.getEntityByID(entityID)
+	if err != nil || !m.isVisible(entity) {
+		return nil, fmt.Errorf("entity not found: %s", entityID)
+	}
+	for i := 0; i < maxVersionChain; i++ {
+		var newerID string
+		err := m.db.QueryRow(`
+			SELECT from_id FROM relations
+			WHERE instance_id = ? AND to_id = ? AND type = ?
+			ORDER BY created_at DESC
+			LIMIT 1
+		`, m.instanceID, entity.ID, relationSupersedes).Scan(&newerID)
+		if err != nil {
+			return entity, nil
+		}
+		newer, err := m.getEntityByID(newerID)
+		if err != nil {
+			return entity, nil
+		}
+		entity = newer
+	}
+	return entity, nil
+}
+
+// GetVersionHistory returns every version of an entity, newest first
+func (m *MemorySystem) GetVersionHistory(entityID string) ([]*Entity, error) {
+	entity, err := m.GetLatestVersion(entityID)
+	if err != nil {
+		return nil, err
+	}
+	history := []*Entity{entity}
+	for len(history) < maxVersionChain {
+		relations, err := m.GetRelations(entity.ID, relationSupersedes)
+		if err != nil {
+			return nil, err
+		}
+		var older *Entity
+		for _, relation := range relations {
+			if relation.FromID == entity.ID {
+				if older, err = m.getEntityByID(relation.ToID); err == nil {
+					break
+				}
+			}
+		}
+		if older == nil {
+			break
+		}
+		history = append(history, older)
+		entity = older
+	}
+	return history, nil
+}
+
+// getEntityVersions reports the latest version and version chain of an entity
+func (s *ForwardMCPService) getEntityVersions(args GetEntityVersionsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_entity_versions", args, nil)
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+
+	entity, err := s.memorySystem.GetEntity(args.Identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get entity: %w", err)
+	}
+	history, err := s.memorySystem.GetVersionHistory(entity.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get version history: %w", err)
+	}
+
+	latest := history[0]
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Versions of %s\n\n", strings.SplitN(latest.Name, "@v", 2)[0]))
+	text.WriteString(fmt.Sprintf("Latest version: v%d (entity_id: %s)\n\n", entityVersion(latest), latest.ID))
+	text.WriteString("| Version | Entity ID | Stored | Content Hash |\n|---------|-----------|--------|--------------|\n")
+	for _, version := range history {
+		hash, _ := version.Metadata["content_hash"].(string)
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+		text.WriteString(fmt.Sprintf("| v%d | %s | %s | %s |\n", entityVersion(version), version.ID, s.timeFormatter.Format(version.CreatedAt), hash))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestStoreNQEResultVersions(t *testing.T) {
+	memorySystem := createTestMemorySystem(t)
+	defer memorySystem.Close()
+
+	v1 := &forward.NQERunResult{Items: []map[string]interface{}{{"device": "edge-1"}}}
+	firstID, err := memorySystem.StoreNQEResultWithChunking("FQ_versions", "net-1", "snap-1", v1, 0)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+
+	// Identical content reuses the entity and stores no new chunks
+	sameID, err := memorySystem.StoreNQEResultWithChunking("FQ_versions", "net-1", "snap-1", v1, 0)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	if sameID != firstID {
+		t.Errorf("Expected unchanged content to reuse %s, got %s", firstID, sameID)
+	}
+	if chunks, _ := memorySystem.GetNQEResultChunks(firstID); len(chunks) != 1 {
+		t.Errorf("Expected 1 chunk after deduplication, got %d", len(chunks))
+	}
+
+	v2 := &forward.NQERunResult{Items: []map[string]interface{}{{"device": "edge-1"}, {"device": "edge-2"}}}
+	secondID, err := memorySystem.StoreNQEResultWithChunking("FQ_versions", "net-1", "snap-1", v2, 0)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	if secondID == firstID {
+		t.Fatal("Expected changed content to create a new version")
+	}
+
+	// The plain name resolves to the latest version; the old one is archived but kept
+	latest, err := memorySystem.GetEntity("FQ_versions-net-1-snap-1")
+	if err != nil || latest.ID != secondID || entityVersion(latest) != 2 {
+		t.Fatalf("Expected the name to resolve to v2 %s, got %+v (%v)", secondID, latest, err)
+	}
+	archived, err := memorySystem.getEntityByID(firstID)
+	if err != nil || archived.Name != "FQ_versions-net-1-snap-1@v1" || !isSupersededVersion(archived) {
+		t.Errorf("Expected v1 to be archived, got %+v (%v)", archived, err)
+	}
+
+	if found, err := memorySystem.GetLatestVersion(firstID); err != nil || found.ID != secondID {
+		t.Errorf("Expected the latest version of v1 to be %s, got %+v (%v)", secondID, found, err)
+	}
+	history, err := memorySystem.GetVersionHistory(firstID)
+	if err != nil || len(history) != 2 || history[0].ID != secondID || history[1].ID != firstID {
+		t.Errorf("Unexpected version history: %+v (%v)", history, err)
+	}
+}
+
+func TestAddObservationDeduplicates(t *testing.T) {
+	memorySystem := createTestMemorySystem(t)
+	defer memorySystem.Close()
+
+	entity, err := memorySystem.CreateEntity("dedup", "test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	first, _ := memorySystem.AddObservation(entity.ID, "same content", "note", map[string]interface{}{"k": "v"})
+	second, _ := memorySystem.AddObservation(entity.ID, "same content", "note", map[string]interface{}{"k": "v"})
+	if first.ID != second.ID {
+		t.Errorf("Expected the identical observation to be reused, got %s and %s", first.ID, second.ID)
+	}
+	if _, err := memorySystem.AddObservation(entity.ID, "same content", "note", map[string]interface{}{"k": "other"}); err != nil {
+		t.Fatalf("Failed to add observation: %v", err)
+	}
+	if observations, _ := memorySystem.GetObservations(entity.ID, "note"); len(observations) != 2 {
+		t.Errorf("Expected 2 distinct observations, got %d", len(observations))
+	}
+}
+
+func TestGetEntityVersionsTool(t *testing.T) {
+	service := createTestService()
+	if service.memorySystem == nil {
+		t.Skip("memory system unavailable")
+	}
+	service.memorySystem = createTestMemorySystem(t)
+	defer service.memorySystem.Close()
+
+	for _, rows := range []int{1, 2, 3} {
+		items := make([]map[string]interface{}, rows)
+		for i := range items {
+			items[i] = map[string]interface{}{"row": i}
+		}
+		if _, err := service.memorySystem.StoreNQEResultWithChunking("FQ_versions_tool", "net-1", "snap-1", &forward.NQERunResult{Items: items}, 0); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+	}
+
+	response, err := service.getEntityVersions(GetEntityVersionsArgs{Identifier: "FQ_versions_tool-net-1-snap-1@v1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, "Latest version: v3") || !contains(text, "| v1 |") || !contains(text, "## Versions of FQ_versions_tool-net-1-snap-1") {
+		t.Errorf("Unexpected versions response: %s", text)
+	}
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultNegativeCacheTTL is how long a recorded failure short-circuits retries
+const defaultNegativeCacheTTL = 60 * time.Second
+
+// transientNegativeCacheTTL caps how long a transient failure short-circuits retries of the
+// same query, so an outage that clears is noticed quickly
+const transientNegativeCacheTTL = 10 * time.Second
+
+// Error classes recorded by the negative cache
+const (
+	failureTransient      = "transient"
+	failureUnknownNetwork = "unknown_network"
+	failureUnauthorized   = "unauthorized"
+	failureInvalidQuery   = "invalid_query"
+)
+
+// NegativeCacheEntry records a recent failure for a network or query
+type NegativeCacheEntry struct {
+	Scope      string    `json:"scope"` // "network" or "query"
+	NetworkID  string    `json:"network_id"`
+	QueryID    string    `json:"query_id,omitempty"`
+	ErrorClass string    `json:"error_class"`
+	Message    string    `json:"message"`
+	FailedAt   time.Time `json:"failed_at"`
+	RetryAfter time.Time `json:"retry_after"`
+	Failures   int       `json:"failures"`
+}
+
+// NegativeCache remembers recent failures so immediate retries of a broken query or an
+// unusable network fail fast. Only deterministic failures, an unknown network or rejected
+// credentials, block every query on that network. Query failures only block the same query,
+// and transient ones (timeouts, refused connections, 502/503/504) only briefly. Unclassified
+// errors are not cached.
+type NegativeCache struct {
+	entries map[string]*NegativeCacheEntry
+	mutex   sync.Mutex
+	ttl     time.Duration
+	hits    int64
+	now     func() time.Time
+}
+
+// NewNegativeCache creates a negative cache whose entries expire after ttl
+func NewNegativeCache(ttl time.Duration) *NegativeCache {
+	if ttl <= 0 {
+		ttl = defaultNegativeCacheTTL
+	}
+	return &NegativeCache{
+		entries: make(map[string]*NegativeCacheEntry),
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+func negativeNetworkKey(networkID string) string {
+	return "network:" + networkID
+}
+
+func negativeQueryKey(networkID, queryID string) string {
+	return "query:" + networkID + ":" + queryID
+}
+
+// Check returns the active failure blocking a query on a network, or nil.
+// Network-wide failures are checked first.
+func (nc *NegativeCache) Check(networkID, queryID string) *NegativeCacheEntry {
+	if nc == nil {
+		return nil
+	}
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+
+	now := nc.now()
+	for _, key := range []string{negativeNetworkKey(networkID), negativeQueryKey(networkID, queryID)} {
+		entry, exists := nc.entries[key]
+		if !exists {
+			continue
+		}
+		if !now.Before(entry.RetryAfter) {
+			delete(nc.entries, key)
+			continue
+		}
+		nc.hits++
+		copied := *entry
+		return &copied
+	}
+	return nil
+}
+
+// RecordFailure classifies err and records it. It returns nil when the error is not cacheable.
+func (nc *NegativeCache) RecordFailure(networkID, queryID string, err error) *NegativeCacheEntry {
+	if nc == nil || err == nil {
+		return nil
+	}
+	class, networkScoped := classifyFailure(err)
+	if class == "" {
+		return nil
+	}
+
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+
+	scope, key := "query", negativeQueryKey(networkID, queryID)
+	if networkScoped {
+		scope, key = "network", negativeNetworkKey(networkID)
+		queryID = ""
+	}
+
+	now := nc.now()
+	entry, exists := nc.entries[key]
+	if !exists || !now.Before(entry.RetryAfter) {
+		entry = &NegativeCacheEntry{Scope: scope, NetworkID: networkID, QueryID: queryID}
+		nc.entries[key] = entry
+	}
+	entry.ErrorClass = class
+	entry.Message = truncateString(err.Error(), 200)
+	entry.FailedAt = now
+	ttl := nc.ttl
+	if class == failureTransient {
+		ttl = min(ttl, transientNegativeCacheTTL)
+	}
+	entry.RetryAfter = now.Add(ttl)
+	entry.Failures++
+
+	copied := *entry
+	return &copied
+}
+
+// RecordSuccess clears failures for the query and its network
+func (nc *NegativeCache) RecordSuccess(networkID, queryID string) {
+	if nc == nil {
+		return
+	}
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+	delete(nc.entries, negativeNetworkKey(networkID))
+	delete(nc.entries, negativeQueryKey(networkID, queryID))
+}
+
+// GetStats returns negative cache statistics
+func (nc *NegativeCache) GetStats() map[string]interface{} {
+	nc.mutex.Lock()
+	defer nc.mutex.Unlock()
+
+	now := nc.now()
+	active := 0
+	for _, entry := range nc.entries {
+		if now.Before(entry.RetryAfter) {
+			active++
+		}
+	}
+	return map[string]interface{}{
+		"active_failures": active,
+		"fast_failures":   nc.hits,
+		"ttl_seconds":     nc.ttl.Seconds(),
+	}
+}
+
+// Describe renders the failure with retry guidance relative to now
+func (e *NegativeCacheEntry) Describe(now time.Time) string {
+	subject := fmt.Sprintf("network %s", e.NetworkID)
+	if e.Scope == "query" {
+		subject = fmt.Sprintf("query %s on network %s", e.QueryID, e.NetworkID)
+	}
+	ago := int(now.Sub(e.FailedAt).Seconds())
+	retry := int(e.RetryAfter.Sub(now).Seconds() + 0.5)
+	return fmt.Sprintf("%s last failed %d seconds ago (%s, %d recent failures: %s); retry after %d seconds",
+		subject, ago, e.ErrorClass, e.Failures, e.Message, retry)
+}
+
+// classifyFailure maps an error to a cacheable class and reports whether it affects the
+// whole network. Transient errors only concern the query that hit them, since the next
+// query may well succeed. Unrecognized errors return an empty class.
+func classifyFailure(err error) (string, bool) {
+	message := strings.ToLower(err.Error())
+
+	// Oversized results are retried in batch mode, so they are never cached
+	if strings.Contains(message, "result exceeds maximum length") {
+		return "", false
+	}
+
+	matches := func(markers ...string) bool {
+		for _, marker := range markers {
+			if strings.Contains(message, marker) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case matches("connection refused", "no such host", "i/o timeout", "deadline exceeded", "timeout",
+		"network is unreachable", "connection reset", "status code: 502", "status code: 503", "status code: 504"):
+		return failureTransient, false
+	case matches("status code: 401", "status code: 403", "unauthorized", "forbidden"):
+		return failureUnauthorized, true
+	case matches("network not found", "unknown network", "no such network"):
+		return failureUnknownNetwork, true
+	case matches("invalid module path", "nqe_runtime_error", "nqe_compile_error", "query not found",
+		"status code: 400", "status code: 404", "status code: 422"):
+		return failureInvalidQuery, false
+	}
+	return "", false
+}
+
+// checkNegativeCache returns an informative error when the network or query failed recently
+func (s *ForwardMCPService) checkNegativeCache(networkID, queryID string) error {
+	entry := s.negativeCache.Check(networkID, queryID)
+	if entry == nil {
+		return nil
+	}
+	s.logger.Debug("Negative cache hit for %s on network %s", queryID, networkID)
+	return newCodedError(CodeRecentFailure, entry.Describe(time.Now()))
+}
+
+// recordQueryOutcome clears failures on success and records cacheable errors
+func (s *ForwardMCPService) recordQueryOutcome(networkID, queryID string, err error) {
+	if err == nil {
+		s.negativeCache.RecordSuccess(networkID, queryID)
+		return
+	}
+	if entry := s.negativeCache.RecordFailure(networkID, queryID, err); entry != nil {
+		s.logger.Debug("Recorded %s failure for %s %s", entry.ErrorClass, entry.Scope, firstNonEmpty(entry.QueryID, networkID))
+	}
+}
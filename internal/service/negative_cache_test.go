@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		message       string
+		class         string
+		networkScoped bool
+	}{
+		{"failed to send request: dial tcp: connection refused", failureTransient, false},
+		{"operation failed after 3 retries: retryable error: unexpected status code: 503", failureTransient, false},
+		{"failed to send request: context deadline exceeded (Client.Timeout exceeded while awaiting headers)", failureTransient, false},
+		{"non-retryable error: unexpected status code: 403, response: forbidden", failureUnauthorized, true},
+		{"unexpected status code: 404, response: Network not found", failureUnknownNetwork, true},
+		{"unexpected status code: 400, response: Invalid module path /L3/Legacy", failureInvalidQuery, false},
+		{"unexpected status code: 400, response: missing parameter deviceName", failureInvalidQuery, false},
+		{"NQE_RUNTIME_ERROR: division by zero", failureInvalidQuery, false},
+		{"result exceeds maximum length", "", false},
+		{"something unexpected", "", false},
+	}
+	for _, tt := range tests {
+		class, networkScoped := classifyFailure(errors.New(tt.message))
+		if class != tt.class || networkScoped != tt.networkScoped {
+			t.Errorf("classifyFailure(%q) = %q, %v; want %q, %v", tt.message, class, networkScoped, tt.class, tt.networkScoped)
+		}
+	}
+}
+
+func TestNegativeCacheLifecycle(t *testing.T) {
+	now := time.Date(2026, 1, 15, 12, 0, 0, 0, time.UTC)
+	cache := NewNegativeCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	if entry := cache.RecordFailure("net-1", "FQ_1", errors.New("temporary glitch")); entry != nil {
+		t.Errorf("Expected unclassified errors not to be cached, got %+v", entry)
+	}
+
+	cache.RecordFailure("net-1", "FQ_1", errors.New("NQE_RUNTIME_ERROR: bad query"))
+	if entry := cache.Check("net-1", "FQ_1"); entry == nil || entry.Scope != "query" {
+		t.Fatalf("Expected query failure, got %+v", entry)
+	}
+	if entry := cache.Check("net-1", "FQ_2"); entry != nil {
+		t.Errorf("Expected other queries on the network to run, got %+v", entry)
+	}
+
+	// Network failures block every query on the network
+	cache.RecordFailure("net-2", "FQ_1", errors.New("network not found"))
+	entry := cache.Check("net-2", "FQ_9")
+	if entry == nil || entry.ErrorClass != failureUnknownNetwork {
+		t.Fatalf("Expected network failure, got %+v", entry)
+	}
+
+	now = now.Add(20 * time.Second)
+	description := entry.Describe(now)
+	if !strings.Contains(description, "last failed 20 seconds ago") || !strings.Contains(description, "retry after 40 seconds") {
+		t.Errorf("Unexpected description: %s", description)
+	}
+
+	// Repeated failures extend the window and are counted
+	if entry := cache.RecordFailure("net-2", "FQ_1", errors.New("network not found")); entry.Failures != 2 {
+		t.Errorf("Expected 2 failures, got %d", entry.Failures)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if entry := cache.Check("net-1", "FQ_1"); entry != nil {
+		t.Errorf("Expected failure to expire, got %+v", entry)
+	}
+
+	// Transient failures only hold back the same query, and only briefly
+	cache.RecordFailure("net-4", "FQ_1", errors.New("i/o timeout"))
+	if entry := cache.Check("net-4", "FQ_2"); entry != nil {
+		t.Errorf("Expected a timeout not to block other queries, got %+v", entry)
+	}
+	if entry := cache.Check("net-4", "FQ_1"); entry == nil || entry.ErrorClass != failureTransient || entry.RetryAfter.Sub(now) != transientNegativeCacheTTL {
+		t.Errorf("Expected a short-lived transient failure, got %+v", entry)
+	}
+
+	cache.RecordFailure("net-3", "FQ_1", errors.New("connection refused"))
+	cache.RecordSuccess("net-3", "FQ_1")
+	if entry := cache.Check("net-3", "FQ_1"); entry != nil {
+		t.Errorf("Expected success to clear the failure, got %+v", entry)
+	}
+}
+
+func TestRunNQEQueryFailsFastAfterFailure(t *testing.T) {
+	service := createTestService()
+	service.negativeCache = NewNegativeCache(time.Minute)
+	mockClient := service.forwardClient.(*MockForwardClient)
+	mockClient.shouldError = true
+	mockClient.errorMessage = "failed to send request: connection refused"
+
+	args := RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_test", Options: &NQEQueryOptions{Limit: 10}}
+	if _, err := service.runNQEQueryByID(args); err == nil || strings.Contains(err.Error(), "not retrying") {
+		t.Fatalf("Expected the first call to reach the API, got: %v", err)
+	}
+
+	mockClient.shouldError = false
+	_, err := service.runNQEQueryByID(args)
+	if err == nil || !strings.Contains(err.Error(), "not retrying: query FQ_test on network 162112 last failed") {
+		t.Fatalf("Expected a fast failure, got: %v", err)
+	}
+	if _, err := service.fetchAllNQEItems("162112", "", "FQ_other", nil); err != nil {
+		t.Errorf("Expected a transient failure not to block other queries, got: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// NetworkAccessPolicy restricts the networks the server exposes, whatever the Forward API
+// key can reach. Denied networks win over allowed ones; an empty allowlist allows every
+// network that is not denied. A nil policy allows everything.
+type NetworkAccessPolicy struct {
+	allowed map[string]bool
+	denied  map[string]bool
+}
+
+// NewNetworkAccessPolicy builds a policy from network ID lists, returning nil when both
+// lists are empty
+func NewNetworkAccessPolicy(allowed, denied []string) *NetworkAccessPolicy {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+	policy := &NetworkAccessPolicy{allowed: make(map[string]bool), denied: make(map[string]bool)}
+	for _, networkID := range allowed {
+		policy.allowed[networkID] = true
+	}
+	for _, networkID := range denied {
+		policy.denied[networkID] = true
+	}
+	return policy
+}
+
+// Permits reports whether a network may be accessed. The empty ID is permitted so tools
+// report their own missing-argument errors.
+func (p *NetworkAccessPolicy) Permits(networkID string) bool {
+	if p == nil || networkID == "" {
+		return true
+	}
+	if p.denied[networkID] {
+		return false
+	}
+	return len(p.allowed) == 0 || p.allowed[networkID]
+}
+
+// Filter returns the permitted networks
+func (p *NetworkAccessPolicy) Filter(networks []forward.Network) []forward.Network {
+	if p == nil {
+		return networks
+	}
+	permitted := make([]forward.Network, 0, len(networks))
+	for _, network := range networks {
+		if p.Permits(network.ID) {
+			permitted = append(permitted, network)
+		}
+	}
+	return permitted
+}
+
+// PermitsEntity reports whether a memory entity may be read: entities that record the
+// network_id of a restricted network, such as stored query results, are hidden
+func (p *NetworkAccessPolicy) PermitsEntity(entity *Entity) bool {
+	return p.Permits(resultValueString(entity.Metadata["network_id"]))
+}
+
+// checkNetworkAccess returns an error when the network access policy hides a network.
+// Restricted networks are reported like missing ones so their existence is not leaked.
+func (s *ForwardMCPService) checkNetworkAccess(networkID string) error {
+	if s.networkPolicy.Permits(networkID) {
+		return nil
+	}
+	s.logger.Warn("Blocked access to restricted network %s", networkID)
+	return newCodedError(CodeNetworkNotAvailable, networkID)
+}
+
+// getNetworks lists the networks visible under the network access policy
+func (s *ForwardMCPService) getNetworks() ([]forward.Network, error) {
+	networks, err := s.forwardClient.GetNetworks()
+	if err != nil {
+		return nil, err
+	}
+	return s.networkPolicy.Filter(networks), nil
+}
+
+// snapshotNetworkID finds the permitted network a snapshot belongs to. Snapshots of
+// restricted networks are reported like missing ones.
+func (s *ForwardMCPService) snapshotNetworkID(snapshotID string) (string, error) {
+	networks, err := s.getNetworks()
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the network of snapshot %s: %w", snapshotID, err)
+	}
+	for _, network := range networks {
+		snapshots, err := s.forwardClient.GetSnapshots(network.ID)
+		if err != nil {
+			continue
+		}
+		for _, snapshot := range snapshots {
+			if snapshot.ID == snapshotID {
+				return network.ID, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("snapshot %s not found in the networks available on this server", snapshotID)
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func TestNetworkAccessPolicy(t *testing.T) {
+	if NewNetworkAccessPolicy(nil, nil) != nil {
+		t.Error("Expected no policy without allowed or denied networks")
+	}
+	var unrestricted *NetworkAccessPolicy
+	if !unrestricted.Permits("any") {
+		t.Error("Expected a nil policy to permit every network")
+	}
+
+	policy := NewNetworkAccessPolicy([]string{"net-1", "net-2"}, []string{"net-2"})
+	for networkID, permitted := range map[string]bool{"net-1": true, "net-2": false, "net-3": false, "": true} {
+		if policy.Permits(networkID) != permitted {
+			t.Errorf("Permits(%q): expected %t", networkID, permitted)
+		}
+	}
+	if denyOnly := NewNetworkAccessPolicy(nil, []string{"net-2"}); !denyOnly.Permits("net-3") || denyOnly.Permits("net-2") {
+		t.Error("Expected a denylist alone to hide only the denied networks")
+	}
+}
+
+func TestNetworkAccessPolicyEnforcement(t *testing.T) {
+	service := createTestService()
+	service.networkPolicy = NewNetworkAccessPolicy(nil, []string{"162112"})
+
+	// list_networks hides the restricted network
+	response, err := service.listNetworks(ListNetworksArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if contains(text, "162112") || !contains(text, "network-456") {
+		t.Errorf("Expected only the permitted network to be listed, got: %s", text)
+	}
+
+	// The restricted default network is never resolved
+	if networkID := service.getNetworkID(""); networkID != "" {
+		t.Errorf("Expected the restricted default network to resolve to \"\", got %q", networkID)
+	}
+	if networkID := service.getNetworkID("network-456"); networkID != "network-456" {
+		t.Errorf("Expected the permitted network to resolve, got %q", networkID)
+	}
+
+	// Registered tools reject restricted networks before the handler runs
+	called := false
+	handler := service.authorizeToolHandler("list_devices", func(args ListDevicesArgs) (*mcp.ToolResponse, error) {
+		called = true
+		return mcp.NewToolResponse(mcp.NewTextContent("ok")), nil
+	}).(func(context.Context, ListDevicesArgs) (*mcp.ToolResponse, error))
+	if _, err := handler(context.Background(), ListDevicesArgs{NetworkID: "162112"}); err == nil || called {
+		t.Errorf("Expected the restricted network to be rejected, got %v (called: %t)", err, called)
+	}
+	if _, err := handler(context.Background(), ListDevicesArgs{NetworkID: "network-456"}); err != nil || !called {
+		t.Errorf("Expected the permitted network to reach the handler, got %v", err)
+	}
+
+	// set_default_network cannot select a restricted network
+	response, _ = service.setDefaultNetwork(SetDefaultNetworkArgs{NetworkIdentifier: "162112"})
+	if !contains(response.Content[0].TextContent.Text, "not found") {
+		t.Errorf("Expected the restricted network to be unknown, got: %s", response.Content[0].TextContent.Text)
+	}
+}
+
+func TestNetworkAccessPolicyHidesStoredData(t *testing.T) {
+	service := createTestService()
+	policy := NewNetworkAccessPolicy(nil, []string{"162112"})
+	service.networkPolicy = policy
+	memory := service.memorySystem
+	memory.SetVisibility(policy.PermitsEntity)
+
+	entityType := uniqueTestName("restricted_result_")
+	hidden, _ := memory.CreateEntity("hidden-result", entityType, map[string]interface{}{"network_id": "162112"})
+	shown, _ := memory.CreateEntity("shown-result", entityType, map[string]interface{}{"network_id": "network-456"})
+	memory.AddObservation(hidden.ID, "restricted rows", "data", nil)
+	memory.CreateRelation(shown.ID, hidden.ID, "derived_from", nil)
+
+	if _, err := memory.GetEntity(hidden.ID); err == nil {
+		t.Error("Expected an entity of a restricted network to be unknown")
+	}
+	if entities, _ := memory.SearchEntities("", entityType, 10); len(entities) != 1 || entities[0].ID != shown.ID {
+		t.Errorf("Expected only the permitted network's entity found, got %+v", entities)
+	}
+	if _, err := memory.GetObservations(hidden.ID, ""); err == nil {
+		t.Error("Expected the observations of a hidden entity to be unreadable")
+	}
+	if relations, _ := memory.GetRelations(shown.ID, ""); len(relations) != 0 {
+		t.Errorf("Expected relations to hidden entities left out, got %+v", relations)
+	}
+
+	// Snapshots carry no network_id; delete_snapshot looks up the network first
+	service.networkPolicy = NewNetworkAccessPolicy([]string{"network-999"}, nil)
+	if _, err := service.deleteSnapshot(DeleteSnapshotArgs{SnapshotID: "snapshot-123"}); err == nil || !contains(err.Error(), "not found") {
+		t.Errorf("Expected a snapshot outside the permitted networks to be refused, got %v", err)
+	}
+}
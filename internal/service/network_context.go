@@ -0,0 +1,190 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const (
+	networkContextNetworks    = 10       // Networks listed in the network_context resource
+	networkContextBudget      = 8 * 1024 // Bytes the listed networks may take
+	networkContextDescription = 160      // Characters of a description kept in the resource
+	networkUseScan            = 1000     // Recently updated entities scanned for network use
+
+	networkListEntityName = "network_context_networks" // Stored full network list the resource points to
+)
+
+// networkContextEntry is a network as listed in the network_context resource
+type networkContextEntry struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Default     bool   `json:"default,omitempty"`
+	LastUsed    string `json:"last_used,omitempty"`
+}
+
+// NetworkLastUsed returns when memory last stored or refreshed something about each network,
+// taken from the most recently updated entities that record a network_id
+func (m *MemorySystem) NetworkLastUsed(scan int) (map[string]time.Time, error) {
+	rows, err := m.db.Query(`
+		SELECT metadata, updated_at FROM entities
+		WHERE instance_id = ? AND metadata LIKE '%"network_id"%'
+		ORDER BY updated_at DESC LIMIT ?
+	`, m.instanceID, scan)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network use: %w", err)
+	}
+	defer rows.Close()
+
+	lastUsed := make(map[string]time.Time)
+	for rows.Next() {
+		var metadata string
+		var updatedAt int64
+		if err := rows.Scan(&metadata, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read network use: %w", err)
+		}
+		var fields map[string]interface{}
+		if json.Unmarshal([]byte(metadata), &fields) != nil {
+			continue
+		}
+		networkID := resultValueString(fields["network_id"])
+		if networkID == "" {
+			continue
+		}
+		if used := time.Unix(updatedAt, 0); used.After(lastUsed[networkID]) {
+			lastUsed[networkID] = used
+		}
+	}
+	return lastUsed, rows.Err()
+}
+
+// rankContextNetworks orders networks by relevance: the default network, then networks by
+// most recent use, then the newest networks
+func rankContextNetworks(networks []forward.Network, defaultNetworkID string, lastUsed map[string]time.Time) []forward.Network {
+	ranked := append([]forward.Network(nil), networks...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if (a.ID == defaultNetworkID) != (b.ID == defaultNetworkID) {
+			return a.ID == defaultNetworkID
+		}
+		if usedA, usedB := lastUsed[a.ID], lastUsed[b.ID]; !usedA.Equal(usedB) {
+			return usedA.After(usedB)
+		}
+		if a.CreatedAt != b.CreatedAt {
+			return a.CreatedAt > b.CreatedAt
+		}
+		return a.Name < b.Name
+	})
+	return ranked
+}
+
+// getNetworkContext provides a bounded summary of the network context as a resource: counts,
+// defaults and the most relevant networks within a size budget. When networks are left out,
+// the full list is stored in memory and the summary points to it.
+func (s *ForwardMCPService) getNetworkContext(args NetworkContextArgs) (interface{}, error) {
+	networks, err := s.getNetworks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network context: %w", err)
+	}
+
+	defaultNetworkID := s.networkIDOrDefault("")
+	lastUsed := map[string]time.Time{}
+	if s.memorySystem != nil {
+		if used, err := s.memorySystem.NetworkLastUsed(networkUseScan); err == nil {
+			lastUsed = used
+		} else {
+			s.logger.Warn("Failed to rank networks by use: %v", err)
+		}
+	}
+
+	var listed []networkContextEntry
+	defaults := map[string]interface{}{
+		"network_id":  defaultNetworkID,
+		"snapshot_id": s.getSnapshotID(""),
+		"query_limit": s.getQueryLimit(0),
+	}
+	size := 0
+	for _, network := range rankContextNetworks(networks, defaultNetworkID, lastUsed) {
+		if network.ID == defaultNetworkID {
+			defaults["network_name"] = network.Name
+		}
+		if len(listed) == networkContextNetworks {
+			continue
+		}
+		entry := networkContextEntry{ID: network.ID, Name: network.Name, Default: network.ID == defaultNetworkID,
+			Description: trimTranscriptText(strings.TrimSpace(network.Description), networkContextDescription)}
+		if used, ok := lastUsed[network.ID]; ok {
+			entry.LastUsed = s.timeFormatter.FormatWithAge(used)
+		}
+		data, _ := json.Marshal(entry)
+		if len(listed) > 0 && size+len(data) > networkContextBudget {
+			continue
+		}
+		size += len(data)
+		listed = append(listed, entry)
+	}
+
+	queries := 0
+	if s.queryIndex != nil {
+		queries = len(s.queryIndex.Queries())
+	}
+	context := map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"counts": map[string]int{
+			"networks":        len(networks),
+			"listed_networks": len(listed),
+			"nqe_queries":     queries,
+		},
+		"defaults":          defaults,
+		"networks":          listed,
+		"available_queries": []string{"/L3/Basic/", "/L3/Advanced/", "/L3/Security/"},
+	}
+	if omitted := len(networks) - len(listed); omitted > 0 {
+		context["omitted_networks"] = omitted
+		fullData := map[string]interface{}{"list_networks": "pages through every network with limit and offset"}
+		if entityID, err := s.storeNetworkList(networks); err == nil {
+			fullData["entity_id"] = entityID
+			fullData["get_observations"] = fmt.Sprintf("get_observations entity_id=%s returns every network", entityID)
+		} else {
+			s.logger.Debug("Network list not stored for the network context: %v", err)
+		}
+		context["full_data"] = fullData
+	}
+
+	contextJSON, _ := json.MarshalIndent(context, "", "  ")
+	return string(contextJSON), nil
+}
+
+// storeNetworkList keeps the full network list in memory, as a new version only when it changed
+func (s *ForwardMCPService) storeNetworkList(networks []forward.Network) (string, error) {
+	if s.memorySystem == nil {
+		return "", fmt.Errorf("memory system unavailable")
+	}
+	hash, err := contentHash(networks)
+	if err != nil {
+		return "", err
+	}
+	entity, created, err := s.memorySystem.storeEntityVersion(networkListEntityName, "query_result", map[string]interface{}{
+		"query_type":  "list_networks",
+		"total_count": len(networks),
+		"timestamp":   time.Now().Unix(),
+	}, hash)
+	if err != nil {
+		return "", err
+	}
+	if created {
+		networksJSON, _ := json.Marshal(networks)
+		if _, err := s.memorySystem.AddObservation(entity.ID, string(networksJSON), "data", map[string]interface{}{
+			"data_type": "networks_list",
+			"count":     len(networks),
+		}); err != nil {
+			return "", err
+		}
+	}
+	return entity.ID, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+type networkContextDocument struct {
+	Counts struct {
+		Networks       int `json:"networks"`
+		ListedNetworks int `json:"listed_networks"`
+	} `json:"counts"`
+	Defaults struct {
+		NetworkID   string `json:"network_id"`
+		NetworkName string `json:"network_name"`
+	} `json:"defaults"`
+	Networks        []networkContextEntry `json:"networks"`
+	OmittedNetworks int                   `json:"omitted_networks"`
+	FullData        struct {
+		EntityID string `json:"entity_id"`
+	} `json:"full_data"`
+}
+
+func loadNetworkContext(t *testing.T, service *ForwardMCPService) networkContextDocument {
+	t.Helper()
+	context, err := service.getNetworkContext(NetworkContextArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var document networkContextDocument
+	if err := json.Unmarshal([]byte(context.(string)), &document); err != nil {
+		t.Fatalf("Invalid network context: %v", err)
+	}
+	return document
+}
+
+func TestNetworkContextSummary(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	run := uniqueTestName("net-")
+	client.networks = nil
+	for i := 0; i < 30; i++ {
+		client.networks = append(client.networks, forward.Network{ID: fmt.Sprintf("%s-%d", run, i), Name: fmt.Sprintf("Network %d", i),
+			Description: strings.Repeat("long description ", 20), CreatedAt: int64(i)})
+	}
+	service.defaults.NetworkID = client.networks[5].ID
+	if _, err := service.memorySystem.CreateEntity(uniqueTestName("result-"), "query_result",
+		map[string]interface{}{"network_id": client.networks[2].ID}); err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+
+	document := loadNetworkContext(t, service)
+	if document.Counts.Networks != 30 || document.Counts.ListedNetworks != networkContextNetworks || document.OmittedNetworks != 30-networkContextNetworks {
+		t.Errorf("Expected %d of 30 networks listed, got %+v", networkContextNetworks, document)
+	}
+	if document.Defaults.NetworkName != "Network 5" || !document.Networks[0].Default || document.Networks[0].Name != "Network 5" {
+		t.Errorf("Expected the default network first, got %+v", document.Networks[0])
+	}
+	if document.Networks[1].Name != "Network 2" || document.Networks[1].LastUsed == "" || document.Networks[2].Name != "Network 29" {
+		t.Errorf("Expected the recently used network next, then the newest, got %+v", document.Networks[1:3])
+	}
+	if length := len([]rune(document.Networks[0].Description)); length > networkContextDescription+1 {
+		t.Errorf("Expected descriptions trimmed, got %d characters", length)
+	}
+
+	observations, err := service.memorySystem.GetObservations(document.FullData.EntityID, "data")
+	if err != nil || len(observations) != 1 {
+		t.Fatalf("Expected the full network list stored, got %v, %v", observations, err)
+	}
+	var stored []forward.Network
+	if err := json.Unmarshal([]byte(observations[0].Content), &stored); err != nil || len(stored) != 30 {
+		t.Errorf("Expected all 30 networks in the stored list, got %d, %v", len(stored), err)
+	}
+	if again := loadNetworkContext(t, service); again.FullData.EntityID != document.FullData.EntityID {
+		t.Errorf("Expected an unchanged list to reuse %s, got %s", document.FullData.EntityID, again.FullData.EntityID)
+	}
+
+	client.networks = client.networks[:3]
+	if document := loadNetworkContext(t, service); document.Counts.ListedNetworks != 3 || document.OmittedNetworks != 0 || document.FullData.EntityID != "" {
+		t.Errorf("Expected a small organization listed in full, got %+v", document)
+	}
+}
+
+func TestNetworkContextBudget(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	client.networks = nil
+	for i := 0; i < 10; i++ {
+		client.networks = append(client.networks, forward.Network{ID: fmt.Sprintf("budget-%d", i), Name: strings.Repeat("n", 3000)})
+	}
+
+	document := loadNetworkContext(t, service)
+	if document.Counts.ListedNetworks == 0 || document.Counts.ListedNetworks*3000 > networkContextBudget {
+		t.Errorf("Expected the listed networks to fit the budget, got %d", document.Counts.ListedNetworks)
+	}
+	if document.OmittedNetworks != 10-document.Counts.ListedNetworks {
+		t.Errorf("Expected the omitted networks counted, got %+v", document)
+	}
+}
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/report"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Network documentation sections, in document order
+const (
+	networkDocsSites    = "sites"
+	networkDocsPrefixes = "prefixes"
+	networkDocsPaths    = "paths"
+	networkDocsEOL      = "eol"
+	networkDocsDiagram  = "diagram"
+)
+
+var networkDocsSections = []string{networkDocsSites, networkDocsPrefixes, networkDocsPaths, networkDocsEOL, networkDocsDiagram}
+
+// maxDiagramDevices is the largest topology drawn device by device; larger networks are
+// drawn as a graph of sites
+const maxDiagramDevices = 150
+
+// networkDocs is the data behind a network architecture document. Errors of optional
+// sources are documented in place of the section.
+type networkDocs struct {
+	NetworkID  string
+	SnapshotID string
+	Sections   map[string]bool
+	Topology   *TopologyExport
+	Devices    []forward.Device
+	SiteNames  map[string]string
+	Flows      []CriticalFlowCheck
+	FlowsError error
+	EOL        *EOLForecast
+	EOLError   error
+}
+
+// generateNetworkDocs renders and saves an architecture document for a network
+func (s *ForwardMCPService) generateNetworkDocs(args GenerateNetworkDocsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("generate_network_docs", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	format := firstNonEmpty(args.ReportFormat, report.FormatMarkdown)
+	if err := validateReportFormat(format); err != nil {
+		return nil, err
+	}
+	sections, err := parseNetworkDocsSections(args.Sections)
+	if err != nil {
+		return nil, err
+	}
+	var flows []CriticalFlowCheck
+	if sections[networkDocsPaths] && strings.TrimSpace(args.KeyPaths) != "" {
+		if flows, err = parseCriticalFlows(args.KeyPaths); err != nil {
+			return nil, err
+		}
+	}
+
+	topology, err := s.ExportTopology(context.Background(), networkID, args.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	docs := &networkDocs{NetworkID: networkID, SnapshotID: topology.SnapshotID, Sections: sections, Topology: topology, Flows: flows, SiteNames: make(map[string]string)}
+	if docs.Devices, err = s.getNetworkDevices(networkID, topology.SnapshotID); err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	if locations, err := s.networkLocations(networkID); err == nil {
+		for _, location := range locations {
+			docs.SiteNames[location.ID] = location.Name
+		}
+	} else {
+		s.logger.Debug("Failed to get locations for network docs: %v", err)
+	}
+
+	if len(flows) > 0 {
+		docs.FlowsError = s.verifyCriticalFlows(networkID, topology.SnapshotID, flows)
+	}
+	if sections[networkDocsEOL] {
+		docs.EOL, docs.EOLError = s.networkDocsEOLForecast(networkID, topology.SnapshotID)
+	}
+
+	doc := networkDocsReport(docs)
+	doc.GeneratedAt = time.Now()
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Network Documentation: network %s\n\n", networkID))
+	text.WriteString(fmt.Sprintf("Documented %d devices at %d sites with %d links", len(topology.Devices), len(docs.siteDevices()), len(topology.Links)))
+	if len(flows) > 0 {
+		text.WriteString(fmt.Sprintf(" and %d key paths", len(flows)))
+	}
+	text.WriteString(".\n\nSections: ")
+	titles := make([]string, 0, len(doc.Sections))
+	for _, section := range doc.Sections {
+		titles = append(titles, section.Title)
+	}
+	text.WriteString(strings.Join(titles, ", ") + "\n")
+	if docs.FlowsError != nil {
+		text.WriteString(fmt.Sprintf("\n⚠️ Key paths were not verified: %v\n", docs.FlowsError))
+	}
+	if docs.EOLError != nil {
+		text.WriteString(fmt.Sprintf("\n⚠️ EOL summary unavailable: %v\n", docs.EOLError))
+	}
+	text.WriteString(s.saveReport(doc, "network_docs_"+networkID, format))
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// parseNetworkDocsSections returns the requested sections, defaulting to all of them
+func parseNetworkDocsSections(requested []string) (map[string]bool, error) {
+	sections := make(map[string]bool, len(networkDocsSections))
+	for _, name := range requested {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(networkDocsSections, name) {
+			return nil, fmt.Errorf("invalid section '%s' (expected %s)", name, strings.Join(networkDocsSections, ", "))
+		}
+		sections[name] = true
+	}
+	if len(sections) == 0 {
+		for _, name := range networkDocsSections {
+			sections[name] = true
+		}
+	}
+	return sections, nil
+}
+
+// networkDocsEOLForecast builds the hardware and OS support forecast for the EOL summary
+func (s *ForwardMCPService) networkDocsEOLForecast(networkID, snapshotID string) (*EOLForecast, error) {
+	locations, err := s.deviceLocationMap(networkID)
+	if err != nil {
+		s.logger.Debug("Failed to get device locations for network docs: %v", err)
+	}
+	now := time.Now()
+	var records []EOLExposureRecord
+	for _, source := range []struct{ component, queryID string }{
+		{eolComponentHardware, hardwareSupportQueryID},
+		{eolComponentOS, osSupportQueryID},
+	} {
+		items, err := s.fetchAllNQEItems(networkID, snapshotID, source.queryID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s support data: %w", source.component, err)
+		}
+		records = append(records, s.eolRecordsFromItems(items, source.component, locations, now)...)
+	}
+	return buildEOLForecast(records, now), nil
+}
+
+// siteDevices groups the exported devices by location ID
+func (docs *networkDocs) siteDevices() map[string][]TopologyDevice {
+	sites := make(map[string][]TopologyDevice)
+	for _, device := range docs.Topology.Devices {
+		location := firstNonEmpty(device.LocationID, "unknown")
+		sites[location] = append(sites[location], device)
+	}
+	return sites
+}
+
+// sortedSiteIDs returns the keys of a site map in site name order
+func (docs *networkDocs) sortedSiteIDs(sites map[string][]TopologyDevice) []string {
+	ids := make([]string, 0, len(sites))
+	for id := range sites {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return docs.siteName(ids[i]) < docs.siteName(ids[j]) })
+	return ids
+}
+
+// siteName returns a location's name, or its ID when the location is not known
+func (docs *networkDocs) siteName(id string) string {
+	return firstNonEmpty(docs.SiteNames[id], id)
+}
+
+// networkDocsReport builds the architecture document from the collected data
+func networkDocsReport(docs *networkDocs) *report.Report {
+	sites := docs.siteDevices()
+	siteIDs := docs.sortedSiteIDs(sites)
+	doc := &report.Report{
+		Title:    "Network Architecture",
+		Subtitle: fmt.Sprintf("Network %s, snapshot %s", docs.NetworkID, firstNonEmpty(docs.SnapshotID, "latest")),
+		Facts: []report.Fact{
+			{Label: "Devices", Value: fmt.Sprint(len(docs.Topology.Devices))},
+			{Label: "Sites", Value: fmt.Sprint(len(sites))},
+			{Label: "Links", Value: fmt.Sprint(len(docs.Topology.Links))},
+		},
+	}
+
+	if docs.Sections[networkDocsSites] {
+		summary := make([][]string, 0, len(siteIDs))
+		for _, id := range siteIDs {
+			summary = append(summary, []string{docs.siteName(id), fmt.Sprint(len(sites[id])), networkDocsCounts(sites[id], func(d TopologyDevice) string { return d.Type }),
+				networkDocsCounts(sites[id], func(d TopologyDevice) string { return d.Vendor })})
+		}
+		doc.Sections = append(doc.Sections, report.Section{Title: "Sites", Table: reportTable([]string{"Site", "Devices", "Types", "Vendors"}, summary)})
+		for _, id := range siteIDs {
+			rows := make([][]string, 0, len(sites[id]))
+			for _, device := range sites[id] {
+				rows = append(rows, []string{device.Name, firstNonEmpty(device.Type, "-"), firstNonEmpty(device.Vendor, "-"),
+					firstNonEmpty(device.Model, "-"), firstNonEmpty(device.Platform, "-"), firstNonEmpty(strings.Join(device.ManagementIPs, ", "), "-")})
+			}
+			doc.Sections = append(doc.Sections, report.Section{Title: "Devices: " + docs.siteName(id), Table: reportTable([]string{"Device", "Type", "Vendor", "Model", "Platform", "Management IPs"}, rows)})
+		}
+	}
+
+	if docs.Sections[networkDocsPrefixes] {
+		subnets := siteSubnets(docs.Devices)
+		var rows [][]string
+		for _, id := range siteIDs {
+			for _, subnet := range subnets[id] {
+				rows = append(rows, []string{docs.siteName(id), subnet.prefix.String(), strings.Join(subnet.devices, ", ")})
+			}
+		}
+		section := report.Section{Title: "Prefixes", Text: "Subnets of device interfaces per site, with point-to-point links and covered subnets left out."}
+		if len(rows) == 0 {
+			section.Text = "No interface subnets found."
+		} else {
+			section.Table = reportTable([]string{"Site", "Prefix", "Devices"}, rows)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if docs.Sections[networkDocsPaths] {
+		section := report.Section{Title: "Key Paths"}
+		switch {
+		case len(docs.Flows) == 0:
+			section.Text = "No key paths given; pass key_paths as src>dst[:port][=allow|deny] to document them."
+		case docs.FlowsError != nil:
+			section.Text = fmt.Sprintf("Key paths were not verified: %v", docs.FlowsError)
+			section.Status = "warn"
+		default:
+			section.Status = "ok"
+			rows := make([][]string, 0, len(docs.Flows))
+			for _, flow := range docs.Flows {
+				result := "pass"
+				if !flow.Passed {
+					result = "fail"
+					section.Status = "fail"
+				}
+				rows = append(rows, []string{flow.Flow, flow.Expect, result, flow.Finding})
+			}
+			section.Table = reportTable([]string{"Flow", "Expected", "Result", "Finding"}, rows)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+
+	if docs.Sections[networkDocsEOL] {
+		doc.Sections = append(doc.Sections, networkDocsEOLSection(docs))
+	}
+
+	if docs.Sections[networkDocsDiagram] {
+		section := report.Section{Title: "Topology Diagram", Language: "mermaid", Code: topologyMermaid(docs, sites, siteIDs)}
+		if len(docs.Topology.Devices) > maxDiagramDevices {
+			section.Text = fmt.Sprintf("The network has more than %d devices, so sites are drawn with the number of links between them.", maxDiagramDevices)
+		}
+		doc.Sections = append(doc.Sections, section)
+	}
+	return doc
+}
+
+// networkDocsEOLSection summarizes support exposure per bucket and upcoming quarter
+func networkDocsEOLSection(docs *networkDocs) report.Section {
+	section := report.Section{Title: "End-of-Life Summary"}
+	if docs.EOLError != nil {
+		section.Text = fmt.Sprintf("EOL summary unavailable: %v", docs.EOLError)
+		section.Status = "warn"
+		return section
+	}
+	forecast := docs.EOL
+	if forecast == nil || forecast.Devices == 0 {
+		section.Text = "No hardware or OS support data found."
+		return section
+	}
+	section.Status = "ok"
+	if forecast.Buckets[eolBucketOverdue] > 0 {
+		section.Status = "fail"
+	} else if forecast.Buckets[eolBucket6Months] > 0 {
+		section.Status = "warn"
+	}
+	section.Text = fmt.Sprintf("%d devices with support data; counts are device components (hardware and OS) at their earliest deadline.", forecast.Devices)
+	rows := make([][]string, 0, len(eolBuckets))
+	for _, bucket := range eolBuckets {
+		rows = append(rows, []string{bucket, fmt.Sprint(forecast.Buckets[bucket])})
+	}
+	section.Table = reportTable([]string{"Support Ends", "Components"}, rows)
+	for _, quarter := range forecast.Quarters {
+		section.Items = append(section.Items, fmt.Sprintf("%s: %d replacements, %d upgrades", quarter.Quarter, quarter.Replacements, quarter.Upgrades))
+	}
+	return section
+}
+
+// networkDocsCounts summarizes a device attribute as "value ×count" in descending order
+func networkDocsCounts(devices []TopologyDevice, value func(TopologyDevice) string) string {
+	counts := make(map[string]int)
+	for _, device := range devices {
+		counts[firstNonEmpty(value(device), "unknown")]++
+	}
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		values = append(values, v)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if counts[values[i]] != counts[values[j]] {
+			return counts[values[i]] > counts[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprintf("%s ×%d", v, counts[v])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// topologyMermaid draws the topology as a Mermaid graph with one subgraph per site, or as
+// a graph of sites when the network is too large to draw device by device
+func topologyMermaid(docs *networkDocs, sites map[string][]TopologyDevice, siteIDs []string) string {
+	var out strings.Builder
+	out.WriteString("graph LR\n")
+	deviceSite := make(map[string]string)
+	for id, devices := range sites {
+		for _, device := range devices {
+			deviceSite[device.Name] = id
+		}
+	}
+
+	if len(docs.Topology.Devices) > maxDiagramDevices {
+		index := make(map[string]int, len(siteIDs))
+		for i, id := range siteIDs {
+			index[id] = i
+			out.WriteString(fmt.Sprintf("  s%d[%s]\n", i, mermaidLabel(fmt.Sprintf("%s (%d devices)", docs.siteName(id), len(sites[id])))))
+		}
+		type sitePair struct{ a, b int }
+		links := make(map[sitePair]int)
+		for _, link := range docs.Topology.Links {
+			a, okA := index[deviceSite[link.DeviceA]]
+			b, okB := index[deviceSite[link.DeviceB]]
+			if !okA || !okB || a == b {
+				continue
+			}
+			if b < a {
+				a, b = b, a
+			}
+			links[sitePair{a, b}]++
+		}
+		pairs := make([]sitePair, 0, len(links))
+		for pair := range links {
+			pairs = append(pairs, pair)
+		}
+		sort.Slice(pairs, func(i, j int) bool {
+			if pairs[i].a != pairs[j].a {
+				return pairs[i].a < pairs[j].a
+			}
+			return pairs[i].b < pairs[j].b
+		})
+		for _, pair := range pairs {
+			out.WriteString(fmt.Sprintf("  s%d ---|%d links| s%d\n", pair.a, links[pair], pair.b))
+		}
+		return strings.TrimRight(out.String(), "\n")
+	}
+
+	node := make(map[string]string, len(docs.Topology.Devices))
+	for i, id := range siteIDs {
+		out.WriteString(fmt.Sprintf("  subgraph s%d[%s]\n", i, mermaidLabel(docs.siteName(id))))
+		for _, device := range sites[id] {
+			node[device.Name] = fmt.Sprintf("d%d", len(node))
+			out.WriteString(fmt.Sprintf("    %s[%s]\n", node[device.Name], mermaidLabel(device.Name)))
+		}
+		out.WriteString("  end\n")
+	}
+	for _, link := range docs.Topology.Links {
+		a, okA := node[link.DeviceA]
+		b, okB := node[link.DeviceB]
+		if !okA || !okB {
+			continue
+		}
+		out.WriteString(fmt.Sprintf("  %s ---|%s| %s\n", a, mermaidLabel(link.InterfaceA+" - "+link.InterfaceB), b))
+	}
+	return strings.TrimRight(out.String(), "\n")
+}
+
+// mermaidLabel quotes a node or edge label, escaping characters Mermaid would parse
+func mermaidLabel(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
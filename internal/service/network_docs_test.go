@@ -0,0 +1,124 @@
+package service
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func networkDocsTestService(t *testing.T) *ForwardMCPService {
+	service := createTestService()
+	service.reports = newTestReportStore(t)
+	client := service.forwardClient.(*MockForwardClient)
+	device := func(name, location, vendor string, addresses ...string) forward.Device {
+		device := forward.Device{Name: name, LocationID: location, Type: "ROUTER", Vendor: vendor}
+		for i, address := range addresses {
+			device.Interfaces = append(device.Interfaces, forward.DeviceInterface{Name: "eth" + string(rune('0'+i)), IPAddress: address})
+		}
+		return device
+	}
+	client.devices = []forward.Device{
+		device("core1", "loc-hq", "CISCO", "10.0.10.1/24", "192.0.2.1/30"),
+		device("core2", "loc-hq", "CISCO", "10.0.10.2/24", "10.0.20.1/24"),
+		device("br1", "loc-br", "JUNIPER", "10.1.0.1/24", "192.0.2.2/30"),
+	}
+	client.locations = []forward.Location{{ID: "loc-hq", Name: "Headquarters"}, {ID: "loc-br", Name: "Branch \"East\""}}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"device": "core1", "interface": "eth1", "peerDevice": "br1", "peerInterface": "eth1"},
+		{"device": "br1", "interface": "eth1", "peerDevice": "core1", "peerInterface": "eth1"},
+		{"device": "core1", "interface": "eth2", "peerDevice": "core2", "peerInterface": "eth2"},
+	}}
+	service.deviceCache = nil
+	return service
+}
+
+func TestParseNetworkDocsSections(t *testing.T) {
+	all, err := parseNetworkDocsSections(nil)
+	if err != nil || len(all) != len(networkDocsSections) {
+		t.Errorf("Expected all sections by default, got %v (%v)", all, err)
+	}
+	some, err := parseNetworkDocsSections([]string{" Sites", "diagram"})
+	if err != nil || len(some) != 2 || !some[networkDocsSites] || !some[networkDocsDiagram] {
+		t.Errorf("Expected sites and diagram, got %v (%v)", some, err)
+	}
+	if _, err := parseNetworkDocsSections([]string{"vlans"}); err == nil {
+		t.Error("Expected an unknown section to be rejected")
+	}
+}
+
+func TestTopologyMermaid(t *testing.T) {
+	docs := &networkDocs{
+		SiteNames: map[string]string{"hq": "HQ"},
+		Topology: &TopologyExport{
+			Devices: []TopologyDevice{{Name: "a", LocationID: "hq"}, {Name: "b", LocationID: "hq"}, {Name: "c", LocationID: "dc"}},
+			Links:   []TopologyLink{{"a", "eth0", "b", "eth0"}, {"a", "eth1", "c", "eth0"}, {"b", "eth1", "c", "eth1"}},
+		},
+	}
+	sites := docs.siteDevices()
+	diagram := topologyMermaid(docs, sites, docs.sortedSiteIDs(sites))
+	expected := "graph LR\n  subgraph s0[\"HQ\"]\n    d0[\"a\"]\n    d1[\"b\"]\n  end\n  subgraph s1[\"dc\"]\n    d2[\"c\"]\n  end\n" +
+		"  d0 ---|\"eth0 - eth0\"| d1\n  d0 ---|\"eth1 - eth0\"| d2\n  d1 ---|\"eth1 - eth1\"| d2"
+	if diagram != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, diagram)
+	}
+
+	// Large networks are drawn as sites with link counts
+	for i := 0; i < maxDiagramDevices; i++ {
+		docs.Topology.Devices = append(docs.Topology.Devices, TopologyDevice{Name: "x" + string(rune('a'+i%26)), LocationID: "edge"})
+	}
+	sites = docs.siteDevices()
+	diagram = topologyMermaid(docs, sites, docs.sortedSiteIDs(sites))
+	if !strings.Contains(diagram, "s0[\"HQ (2 devices)\"]") || !strings.Contains(diagram, "s0 ---|2 links| s1") || strings.Contains(diagram, "subgraph") {
+		t.Errorf("Expected a site graph, got:\n%s", diagram)
+	}
+}
+
+func TestGenerateNetworkDocs(t *testing.T) {
+	service := networkDocsTestService(t)
+
+	response, err := service.generateNetworkDocs(GenerateNetworkDocsArgs{NetworkID: "162112", KeyPaths: "10.0.10.5>10.1.0.5:443"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"Documented 3 devices at 2 sites with 2 links and 1 key paths",
+		"Sections: Sites, Devices: Branch \"East\", Devices: Headquarters, Prefixes, Key Paths, End-of-Life Summary, Topology Diagram",
+		"markdown report saved to"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	reports, _ := service.reports.List()
+	if len(reports) != 1 || !strings.HasPrefix(reports[0].Name, "network_docs_162112") {
+		t.Fatalf("Expected one saved document, got %+v", reports)
+	}
+	content, _ := os.ReadFile(reports[0].Path)
+	for _, expected := range []string{"# Network Architecture", "- **Sites:** 2",
+		"| Headquarters | 2 | ROUTER ×2 | CISCO ×2 |", "| Headquarters | 10.0.10.0/24 | core1 |", "| Branch \"East\" | 10.1.0.0/24 | br1 |",
+		"| 10.0.10.5>10.1.0.5:443 | allow |", "## End-of-Life Summary", "```mermaid\ngraph LR\n", "subgraph s0[\"Branch #quot;East#quot;\"]"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected %q in document, got:\n%s", expected, content)
+		}
+	}
+	if strings.Contains(string(content), "192.0.2.0/30") {
+		t.Errorf("Expected point-to-point links left out of the prefixes:\n%s", content)
+	}
+
+	response, err = service.generateNetworkDocs(GenerateNetworkDocsArgs{NetworkID: "162112", Sections: []string{"diagram"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Sections: Topology Diagram\n") {
+		t.Errorf("Expected only the diagram, got: %s", text)
+	}
+
+	if _, err := service.generateNetworkDocs(GenerateNetworkDocsArgs{NetworkID: "162112", ReportFormat: "docx"}); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+	if _, err := service.generateNetworkDocs(GenerateNetworkDocsArgs{NetworkID: "162112", KeyPaths: "no-arrow"}); err == nil {
+		t.Error("Expected an invalid key path to be rejected")
+	}
+}
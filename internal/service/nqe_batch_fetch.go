@@ -0,0 +1,103 @@
+package service
+
+import (
+	"sync"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const (
+	defaultNQEFetchConcurrency = 4
+	maxNQEFetchConcurrency     = 16
+)
+
+// nqeFetchConcurrency returns the batches an all_results fetch runs in parallel: the call's
+// own setting, else the configured one, capped at maxNQEFetchConcurrency
+func (s *ForwardMCPService) nqeFetchConcurrency(requested int) int {
+	concurrency := requested
+	if concurrency <= 0 && s.config != nil {
+		concurrency = s.config.Forward.NQEFetchConcurrency
+	}
+	if concurrency <= 0 {
+		concurrency = defaultNQEFetchConcurrency
+	}
+	return min(concurrency, maxNQEFetchConcurrency)
+}
+
+// fetchNQEBatches pages through a query from offset start with up to workers batches in
+// flight. The result count is unknown up front, so workers claim offsets in order and stop
+// claiming once a short batch marks the end; a few batches past the end may be fetched and are
+// dropped. Batches are delivered in offset order, one at a time, as soon as every batch before
+// them arrived, so deliver needs no locking. On failure the batches before the failed offset
+// are still delivered and the failed offset is returned with the error.
+func fetchNQEBatches(start, limit, workers int, fetch func(offset int) (*forward.NQERunResult, error), deliver func(offset int, result *forward.NQERunResult)) (int, error) {
+	if workers < 1 {
+		workers = 1
+	}
+	var (
+		mutex      sync.Mutex
+		wg         sync.WaitGroup
+		next       = start // Next offset to claim
+		flushed    = start // Next offset to deliver
+		pending    = make(map[int]*forward.NQERunResult)
+		stopped    bool // No more offsets are claimed
+		done       bool // The last batch was delivered
+		failOffset = -1
+		failErr    error
+	)
+
+	worker := func() {
+		defer wg.Done()
+		for {
+			mutex.Lock()
+			if stopped {
+				mutex.Unlock()
+				return
+			}
+			offset := next
+			next += limit
+			mutex.Unlock()
+
+			result, err := fetch(offset)
+
+			mutex.Lock()
+			if err != nil {
+				if failOffset < 0 || offset < failOffset {
+					failOffset, failErr = offset, err
+				}
+				stopped = true
+				mutex.Unlock()
+				return
+			}
+			pending[offset] = result
+			for !done {
+				batch, ok := pending[flushed]
+				if !ok {
+					break
+				}
+				delete(pending, flushed)
+				if batch != nil {
+					deliver(flushed, batch)
+				}
+				if batch == nil || len(batch.Items) < limit {
+					done, stopped = true, true
+					break
+				}
+				flushed += limit
+			}
+			mutex.Unlock()
+		}
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+	wg.Wait()
+
+	// A failure past the end of the result, e.g. on a speculative batch, does not matter
+	if failErr != nil && !done {
+		return failOffset, failErr
+	}
+	return 0, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// pagedRows serves rows in batches, later batches answering faster so they arrive out of order
+type pagedRows struct {
+	rows     int
+	failAt   int
+	inFlight int
+	peak     int
+	mutex    sync.Mutex
+}
+
+func (p *pagedRows) fetch(limit int) func(offset int) (*forward.NQERunResult, error) {
+	return func(offset int) (*forward.NQERunResult, error) {
+		p.mutex.Lock()
+		p.inFlight++
+		p.peak = max(p.peak, p.inFlight)
+		p.mutex.Unlock()
+		defer func() {
+			p.mutex.Lock()
+			p.inFlight--
+			p.mutex.Unlock()
+		}()
+
+		time.Sleep(time.Duration(100-offset%100) * 50 * time.Microsecond)
+		if offset == p.failAt {
+			return nil, fmt.Errorf("gateway timeout")
+		}
+		result := &forward.NQERunResult{SnapshotID: "snapshot-1"}
+		for row := offset; row < min(offset+limit, p.rows); row++ {
+			result.Items = append(result.Items, map[string]interface{}{"row": row})
+		}
+		return result, nil
+	}
+}
+
+func TestFetchNQEBatchesOrdered(t *testing.T) {
+	source := &pagedRows{rows: 95, failAt: -1}
+	var offsets []int
+	var rows []map[string]interface{}
+	if _, err := fetchNQEBatches(0, 10, 4, source.fetch(10), func(offset int, result *forward.NQERunResult) {
+		offsets = append(offsets, offset)
+		rows = append(rows, result.Items...)
+	}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(rows) != 95 || len(offsets) != 10 {
+		t.Fatalf("Expected 95 rows in 10 batches, got %d in %d", len(rows), len(offsets))
+	}
+	for i, row := range rows {
+		if row["row"] != i {
+			t.Fatalf("Expected rows in order, row %d is %v", i, row["row"])
+		}
+	}
+	if source.peak < 2 || source.peak > 4 {
+		t.Errorf("Expected between 2 and 4 batches in flight, got %d", source.peak)
+	}
+}
+
+func TestFetchNQEBatchesFailure(t *testing.T) {
+	source := &pagedRows{rows: 95, failAt: 30}
+	var offsets []int
+	failedAt, err := fetchNQEBatches(0, 10, 3, source.fetch(10), func(offset int, result *forward.NQERunResult) {
+		offsets = append(offsets, offset)
+	})
+	if err == nil || failedAt != 30 {
+		t.Fatalf("Expected the failure at offset 30, got %d %v", failedAt, err)
+	}
+	if len(offsets) != 3 || offsets[2] != 20 {
+		t.Errorf("Expected the batches before the failure delivered, got %v", offsets)
+	}
+
+	// A failing batch past the end of the result is ignored
+	source = &pagedRows{rows: 25, failAt: 40}
+	if _, err := fetchNQEBatches(0, 10, 4, source.fetch(10), func(int, *forward.NQERunResult) {}); err != nil {
+		t.Errorf("Expected a failure past the end to be ignored, got %v", err)
+	}
+}
+
+func TestNQEFetchConcurrency(t *testing.T) {
+	service := createTestService()
+	service.config.Forward.NQEFetchConcurrency = 0
+	if got := service.nqeFetchConcurrency(0); got != defaultNQEFetchConcurrency {
+		t.Errorf("Expected the default concurrency, got %d", got)
+	}
+	service.config.Forward.NQEFetchConcurrency = 2
+	if got := service.nqeFetchConcurrency(0); got != 2 {
+		t.Errorf("Expected the configured 2, got %d", got)
+	}
+	if got := service.nqeFetchConcurrency(1); got != 1 {
+		t.Errorf("Expected the call's own setting, got %d", got)
+	}
+	if got := service.nqeFetchConcurrency(100); got != maxNQEFetchConcurrency {
+		t.Errorf("Expected the cap, got %d", got)
+	}
+}
@@ -13,8 +13,16 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// getWritableDataDirectory returns a directory where we can write the database
+// getWritableDataDirectory returns a directory where we can write the database. FORWARD_DATA_DIR
+// overrides the search and must be writable.
 func getWritableDataDirectory() (string, error) {
+	if dir := os.Getenv("FORWARD_DATA_DIR"); dir != "" {
+		if err := probeWritableDir(dir); err != nil {
+			return "", fmt.Errorf("FORWARD_DATA_DIR %s is not writable: %w", dir, err)
+		}
+		return dir, nil
+	}
+
 	// Try different locations in order of preference for Claude Desktop compatibility
 	candidates := []string{
 		// 1. User's home directory (most consistent across runs)
@@ -132,6 +140,7 @@ func (db *NQEDatabase) initSchema() error {
 		last_commit_title TEXT,
 		created_at INTEGER NOT NULL,
 		updated_at INTEGER NOT NULL,
+		deprecated_at INTEGER,
 		PRIMARY KEY (instance_id, query_id)
 	);
 
@@ -150,12 +159,50 @@ func (db *NQEDatabase) initSchema() error {
 	);
 
 	CREATE INDEX IF NOT EXISTS idx_metadata_instance ON db_metadata(instance_id);
+
+	-- Per-directory progress of sharded enhanced hydration, used to resume interrupted runs
+	CREATE TABLE IF NOT EXISTS hydration_checkpoints (
+		instance_id TEXT NOT NULL,
+		shard TEXT NOT NULL,
+		repository TEXT NOT NULL,
+		directory TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		total INTEGER NOT NULL,
+		fetched INTEGER NOT NULL,
+		unchanged INTEGER NOT NULL,
+		failed INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		error TEXT,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (instance_id, shard)
+	);
+
+	-- User-defined categories for query IDs or path prefixes, overriding the path-derived ones
+	CREATE TABLE IF NOT EXISTS query_taxonomy (
+		instance_id TEXT NOT NULL,
+		match TEXT NOT NULL,
+		category TEXT NOT NULL,
+		subcategory TEXT,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (instance_id, match)
+	);
 	`
 
 	if _, err := db.db.Exec(schema); err != nil {
 		return fmt.Errorf("failed to create schema: %w", err)
 	}
 
+	// Databases created before query deprecation lack the deprecated_at column
+	var hasDeprecatedColumn int
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM pragma_table_info('nqe_queries') WHERE name = 'deprecated_at'").Scan(&hasDeprecatedColumn); err != nil {
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+	if hasDeprecatedColumn == 0 {
+		if _, err := db.db.Exec("ALTER TABLE nqe_queries ADD COLUMN deprecated_at INTEGER"); err != nil {
+			return fmt.Errorf("failed to add deprecated_at column: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -349,19 +396,43 @@ func (db *NQEDatabase) SaveQueries(queries []forward.NQEQueryDetail) error {
 	if len(queries) == 0 {
 		return nil
 	}
+	if err := db.storeQueries(queries); err != nil {
+		return err
+	}
 
+	// Notify callbacks that data has been updated
+	db.notifyUpdateCallbacks()
+
+	return nil
+}
+
+// storeQueries writes queries without notifying update callbacks, for partial saves such as
+// hydration shards
+func (db *NQEDatabase) storeQueries(queries []forward.NQEQueryDetail) error {
 	tx, err := db.db.Begin()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	defer tx.Rollback()
 
+	// Upsert rather than replace so the deprecation state set by syncDeprecations survives
 	stmt, err := tx.Prepare(`
-		INSERT OR REPLACE INTO nqe_queries (
+		INSERT INTO nqe_queries (
 			instance_id, query_id, path, intent, source_code, description, repository,
 			last_commit_id, last_commit_author, last_commit_date, last_commit_title,
 			created_at, updated_at
 		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(instance_id, query_id) DO UPDATE SET
+			path = excluded.path,
+			intent = excluded.intent,
+			source_code = excluded.source_code,
+			description = excluded.description,
+			repository = excluded.repository,
+			last_commit_id = excluded.last_commit_id,
+			last_commit_author = excluded.last_commit_author,
+			last_commit_date = excluded.last_commit_date,
+			last_commit_title = excluded.last_commit_title,
+			updated_at = excluded.updated_at
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %w", err)
@@ -395,20 +466,41 @@ func (db *NQEDatabase) SaveQueries(queries []forward.NQEQueryDetail) error {
 	}
 
 	db.logger.Debug("Saved %d queries to database", len(queries))
+	return nil
+}
 
-	// Notify callbacks that data has been updated
-	db.notifyUpdateCallbacks()
+// DeleteQueries removes queries by ID, for example when they were deleted upstream
+func (db *NQEDatabase) DeleteQueries(queryIDs []string) error {
+	if len(queryIDs) == 0 {
+		return nil
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, queryID := range queryIDs {
+		if _, err := tx.Exec("DELETE FROM nqe_queries WHERE instance_id = ? AND query_id = ?", db.instanceID, queryID); err != nil {
+			return fmt.Errorf("failed to delete query %s: %w", queryID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
 
+	db.logger.Debug("Deleted %d queries from database", len(queryIDs))
 	return nil
 }
 
-// LoadQueries loads all queries from the database for this instance
+// LoadQueries loads all active (non-deprecated) queries from the database for this instance
 func (db *NQEDatabase) LoadQueries() ([]forward.NQEQueryDetail, error) {
 	rows, err := db.db.Query(`
 		SELECT query_id, path, intent, source_code, description, repository,
 			   last_commit_id, last_commit_author, last_commit_date, last_commit_title
 		FROM nqe_queries
-		WHERE instance_id = ?
+		WHERE instance_id = ? AND deprecated_at IS NULL
 		ORDER BY path
 	`, db.instanceID)
 	if err != nil {
@@ -456,10 +548,50 @@ func (db *NQEDatabase) LoadQueries() ([]forward.NQEQueryDetail, error) {
 	return queries, nil
 }
 
-// GetQueryCount returns the number of queries in the database for this instance
+// GetQuery loads a single query by ID for this instance
+func (db *NQEDatabase) GetQuery(queryID string) (*forward.NQEQueryDetail, error) {
+	var query forward.NQEQueryDetail
+	var intent, sourceCode, description, repository, commitID, authorEmail, title sql.NullString
+	var committedAt sql.NullInt64
+
+	err := db.db.QueryRow(`
+		SELECT query_id, path, intent, source_code, description, repository,
+			   last_commit_id, last_commit_author, last_commit_date, last_commit_title
+		FROM nqe_queries
+		WHERE instance_id = ? AND query_id = ?
+	`, db.instanceID, queryID).Scan(
+		&query.QueryID,
+		&query.Path,
+		&intent,
+		&sourceCode,
+		&description,
+		&repository,
+		&commitID,
+		&authorEmail,
+		&committedAt,
+		&title,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load query %s: %w", queryID, err)
+	}
+
+	query.Intent = intent.String
+	query.SourceCode = sourceCode.String
+	query.Description = description.String
+	query.Repository = repository.String
+	query.LastCommit = forward.NQECommitInfo{
+		ID:          commitID.String,
+		AuthorEmail: authorEmail.String,
+		CommittedAt: committedAt.Int64,
+		Title:       title.String,
+	}
+	return &query, nil
+}
+
+// GetQueryCount returns the number of active queries in the database for this instance
 func (db *NQEDatabase) GetQueryCount() (int, error) {
 	var count int
-	err := db.db.QueryRow("SELECT COUNT(*) FROM nqe_queries WHERE instance_id = ?", db.instanceID).Scan(&count)
+	err := db.db.QueryRow("SELECT COUNT(*) FROM nqe_queries WHERE instance_id = ? AND deprecated_at IS NULL", db.instanceID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count queries: %w", err)
 	}
@@ -477,11 +609,18 @@ func (db *NQEDatabase) GetStatistics() (map[string]interface{}, error) {
 	}
 	stats["total_queries"] = totalQueries
 
+	// Deprecated queries
+	var deprecatedQueries int
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM nqe_queries WHERE instance_id = ? AND deprecated_at IS NOT NULL", db.instanceID).Scan(&deprecatedQueries); err != nil {
+		return nil, fmt.Errorf("failed to count deprecated queries: %w", err)
+	}
+	stats["deprecated_queries"] = deprecatedQueries
+
 	// Queries by repository
 	rows, err := db.db.Query(`
 		SELECT repository, COUNT(*) 
 		FROM nqe_queries 
-		WHERE instance_id = ? AND repository IS NOT NULL AND repository != ''
+		WHERE instance_id = ? AND deprecated_at IS NULL AND repository IS NOT NULL AND repository != ''
 		GROUP BY repository
 	`, db.instanceID)
 	if err != nil {
@@ -638,7 +777,7 @@ func (db *NQEDatabase) backgroundEnhancedLoad(client forward.ClientInterface, lo
 	defer cancel()
 
 	// Try Enhanced API loading
-	enhancedQueries, err := client.GetNQEAllQueriesEnhancedWithCacheContext(ctx, existingCommitIDs)
+	enhancedQueries, err := db.loadEnhancedSharded(ctx, client, logger, existingCommitIDs, defaultHydrationWorkers, false)
 	if err != nil {
 		logger.Warn("🔄 Background Enhanced API failed: %v", err)
 		logger.Info("🔄 Background fallback to Basic API...")
@@ -694,7 +833,7 @@ func (db *NQEDatabase) backgroundEnhancedLoadWithContext(ctx context.Context, cl
 
 	// Use the passed context directly - don't create a new timeout
 	// The service will handle cancellation and timeout as needed
-	enhancedQueries, err := client.GetNQEAllQueriesEnhancedWithCacheContext(ctx, existingCommitIDs)
+	enhancedQueries, err := db.loadEnhancedSharded(ctx, client, logger, existingCommitIDs, defaultHydrationWorkers, false)
 	if err != nil {
 		// Check if we were cancelled
 		select {
@@ -812,39 +951,12 @@ func (db *NQEDatabase) synchronousLoad(ctx context.Context, client forward.Clien
 
 // loadFromEnhancedAPIWithCommitCheck loads queries using Enhanced API with commit-based incremental updates
 func (db *NQEDatabase) loadFromEnhancedAPIWithCommitCheck(ctx context.Context, client forward.ClientInterface, logger *logger.Logger, existingCommitIDs map[string]string) ([]forward.NQEQueryDetail, error) {
-	// Channel to receive results
-	resultChan := make(chan []forward.NQEQueryDetail, 1)
-	errorChan := make(chan error, 1)
-
-	// Start Enhanced API loading in background
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				errorChan <- fmt.Errorf("enhanced API panic: %v", r)
-			}
-		}()
-
-		// Load from both repositories with commit checking
-		allQueries, err := client.GetNQEAllQueriesEnhancedWithCache(existingCommitIDs)
-		if err != nil {
-			errorChan <- fmt.Errorf("failed to get queries with commit checking: %w", err)
-			return
-		}
-
-		logger.Info("Enhanced API with commit checking loaded %d queries", len(allQueries))
-		resultChan <- allQueries
-	}()
-
-	// Wait for result or timeout
-	select {
-	case <-ctx.Done():
-		return nil, fmt.Errorf("enhanced API timed out after 60 seconds")
-	case err := <-errorChan:
-		return nil, err
-	case queries := <-resultChan:
-		logger.Info("Enhanced API loaded %d queries successfully (with commit checking)", len(queries))
-		return queries, nil
+	queries, err := db.loadEnhancedSharded(ctx, client, logger, existingCommitIDs, defaultHydrationWorkers, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queries with commit checking: %w", err)
 	}
+	logger.Info("Enhanced API loaded %d queries successfully (with commit checking)", len(queries))
+	return queries, nil
 }
 
 // loadFromBasicAPI loads queries from Basic API (both repositories)
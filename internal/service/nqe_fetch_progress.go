@@ -0,0 +1,195 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	fetchProgressEntityType = "nqe_fetch_progress"
+	fetchBatchObservation   = "nqe_fetch_batch"
+	// fetchProgressTTL bounds how long an interrupted fetch can be resumed; older progress is
+	// discarded because its snapshot has likely been superseded
+	fetchProgressTTL = 24 * time.Hour
+)
+
+// fetchProgress is the persisted state of an all_results fetch: the memory entity it is stored
+// under, with one observation holding the rows of each completed batch
+type fetchProgress struct {
+	entity     *Entity
+	snapshotID string // Concrete snapshot every batch is fetched from, even when the call asked for the latest
+	nextOffset int    // Offset of the first batch not fetched yet
+	rows       int
+	resumed    bool
+}
+
+// fetchProgressName identifies a fetch by its query, network, concrete snapshot, parameters and
+// paging, so re-running the same call finds the progress of the interrupted one as long as the
+// latest snapshot has not moved
+func fetchProgressName(queryID, networkID, snapshotID string, parameters map[string]interface{}, limit, offset int) string {
+	// Map keys marshal sorted, so equal parameters hash equally
+	params, _ := json.Marshal(parameters)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d|%d", queryID, networkID, snapshotID, params, limit, offset)))
+	return fmt.Sprintf("fetch %s %s", queryID, hex.EncodeToString(sum[:8]))
+}
+
+// beginFetchProgress returns the progress of an all_results fetch and the rows already
+// fetched: the progress named by resumeToken, the unfinished progress of an identical fetch,
+// or new progress. An empty snapshotID is resolved to the latest processed snapshot, and a
+// resume token keeps the snapshot its fetch started on, so batches never mix snapshots. It
+// returns nil progress without the memory system.
+func (s *ForwardMCPService) beginFetchProgress(args RunNQEQueryByIDArgs, networkID, snapshotID string, limit, offset int) (*fetchProgress, []map[string]interface{}, error) {
+	if s.memorySystem == nil {
+		if args.ResumeToken != "" {
+			return nil, nil, newCodedError(CodeMemoryUnavailable)
+		}
+		return nil, nil, nil
+	}
+
+	var entity, token *Entity
+	if args.ResumeToken != "" {
+		found, err := s.memorySystem.GetEntity(args.ResumeToken)
+		if err != nil || found.Type != fetchProgressEntityType {
+			return nil, nil, fmt.Errorf("resume token %s not found; the fetch may have completed or expired", args.ResumeToken)
+		}
+		token = found
+		if snapshotID == "" {
+			snapshotID = resultValueString(found.Metadata["snapshot_id"])
+		}
+	}
+	if snapshotID == "" {
+		latest, err := s.latestProcessedSnapshot(networkID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve the latest snapshot to fetch from: %w", err)
+		}
+		snapshotID = latest
+	}
+	name := fetchProgressName(args.QueryID, networkID, snapshotID, args.Parameters, limit, offset)
+
+	if token != nil {
+		if token.Name != name {
+			return nil, nil, fmt.Errorf("resume token %s belongs to a different fetch (%s); resume it with its original query_id, parameters, snapshot_id and options",
+				args.ResumeToken, resultValueString(token.Metadata["query_id"]))
+		}
+		entity = token
+	} else if found, err := s.memorySystem.getEntityByNameAndType(name, fetchProgressEntityType); err == nil {
+		if time.Since(found.UpdatedAt) < fetchProgressTTL {
+			entity = found
+		} else if err := s.memorySystem.DeleteEntity(found.ID); err != nil {
+			s.logger.Warn("Failed to discard expired fetch progress %s: %v", found.ID, err)
+		}
+	}
+
+	if entity != nil {
+		rows, err := s.loadFetchedRows(entity.ID, offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		s.logger.Info("Resuming fetch of %s from snapshot %s at offset %d with %d rows already fetched", args.QueryID, snapshotID, offset+len(rows), len(rows))
+		return &fetchProgress{entity: entity, snapshotID: snapshotID, nextOffset: offset + len(rows), rows: len(rows), resumed: true}, rows, nil
+	}
+
+	params, _ := json.Marshal(args.Parameters)
+	entity, err := s.memorySystem.CreateEntity(name, fetchProgressEntityType, map[string]interface{}{
+		"query_id": args.QueryID, "network_id": networkID, "snapshot_id": snapshotID,
+		"parameters": string(params), "batch_size": limit, "start_offset": offset,
+		"next_offset": offset, "rows": 0, "status": "fetching",
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record fetch progress: %w", err)
+	}
+	return &fetchProgress{entity: entity, snapshotID: snapshotID, nextOffset: offset}, nil, nil
+}
+
+// loadFetchedRows returns the rows of the completed batches of a fetch in offset order. Only
+// the contiguous run of batches from the start offset counts, so a gap is fetched again.
+func (s *ForwardMCPService) loadFetchedRows(entityID string, offset int) ([]map[string]interface{}, error) {
+	observations, err := s.memorySystem.GetObservations(entityID, fetchBatchObservation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fetch progress: %w", err)
+	}
+	batches := make(map[int]string, len(observations))
+	for _, observation := range observations {
+		if batchOffset, ok := observation.Metadata["offset"].(float64); ok {
+			batches[int(batchOffset)] = observation.Content
+		}
+	}
+	offsets := make([]int, 0, len(batches))
+	for batchOffset := range batches {
+		offsets = append(offsets, batchOffset)
+	}
+	sort.Ints(offsets)
+
+	var rows []map[string]interface{}
+	for _, batchOffset := range offsets {
+		if batchOffset != offset+len(rows) {
+			break
+		}
+		var batch []map[string]interface{}
+		if err := json.Unmarshal([]byte(batches[batchOffset]), &batch); err != nil {
+			return nil, fmt.Errorf("failed to decode fetched batch at offset %d: %w", batchOffset, err)
+		}
+		rows = append(rows, batch...)
+	}
+	return rows, nil
+}
+
+// recordBatch stores the rows of a completed batch; failing to persist only costs the ability
+// to resume, so it is logged rather than failing the fetch
+func (s *ForwardMCPService) recordBatch(progress *fetchProgress, offset int, items []map[string]interface{}) {
+	if progress == nil {
+		return
+	}
+	data, err := json.Marshal(items)
+	if err == nil {
+		_, err = s.memorySystem.AddObservation(progress.entity.ID, string(data), fetchBatchObservation, map[string]interface{}{
+			"offset": offset, "row_count": len(items),
+		})
+	}
+	if err != nil {
+		s.logger.Warn("Failed to record fetched batch at offset %d: %v", offset, err)
+		return
+	}
+	progress.nextOffset, progress.rows = offset+len(items), progress.rows+len(items)
+	s.updateFetchProgress(progress, "fetching", "")
+}
+
+// interruptFetch marks a fetch as interrupted and returns how to resume it
+func (s *ForwardMCPService) interruptFetch(progress *fetchProgress, cause error) string {
+	if progress == nil {
+		return ""
+	}
+	s.updateFetchProgress(progress, "interrupted", cause.Error())
+	return fmt.Sprintf(". %d rows were fetched before the failure; run the same call again, or pass resume_token=%s, to continue from offset %d",
+		progress.rows, progress.entity.ID, progress.nextOffset)
+}
+
+// completeFetch removes the progress of a fetch once its result is stored
+func (s *ForwardMCPService) completeFetch(progress *fetchProgress) {
+	if progress == nil {
+		return
+	}
+	if err := s.memorySystem.DeleteEntity(progress.entity.ID); err != nil {
+		s.logger.Warn("Failed to remove fetch progress %s: %v", progress.entity.ID, err)
+	}
+}
+
+func (s *ForwardMCPService) updateFetchProgress(progress *fetchProgress, status, lastError string) {
+	metadata := make(map[string]interface{}, len(progress.entity.Metadata)+1)
+	for key, value := range progress.entity.Metadata {
+		metadata[key] = value
+	}
+	metadata["next_offset"], metadata["rows"], metadata["status"] = progress.nextOffset, progress.rows, status
+	if lastError != "" {
+		metadata["last_error"] = lastError
+	}
+	if err := s.memorySystem.updateEntityMetadata(progress.entity.ID, metadata); err != nil {
+		s.logger.Warn("Failed to update fetch progress %s: %v", progress.entity.ID, err)
+		return
+	}
+	progress.entity.Metadata = metadata
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// interruptedNQEClient fails the batch at failAt once, like a fetch dying mid-way
+type interruptedNQEClient struct {
+	*MockForwardClient
+	failAt  int
+	offsets []int
+	mutex   sync.Mutex
+}
+
+func (c *interruptedNQEClient) RunNQEQueryByID(params *forward.NQEQueryParams) (*forward.NQERunResult, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.offsets = append(c.offsets, params.Options.Offset)
+	if params.Options.Offset == c.failAt {
+		c.failAt = -1
+		return nil, fmt.Errorf("connection reset by peer")
+	}
+	return c.MockForwardClient.RunNQEQueryByID(params)
+}
+
+func TestRunNQEQueryResumesInterruptedFetch(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = &forward.NQERunResult{}
+	for i := 0; i < 25; i++ {
+		mock.nqeResult.Items = append(mock.nqeResult.Items, map[string]interface{}{"device": fmt.Sprintf("device-%02d", i)})
+	}
+	client := &interruptedNQEClient{MockForwardClient: mock, failAt: 20}
+	service.forwardClient = client
+
+	args := RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_resume", AllResults: true, Options: &NQEQueryOptions{Limit: 10}}
+	_, err := service.runNQEQueryByID(args)
+	if err == nil || !strings.Contains(err.Error(), "20 rows were fetched before the failure") || !strings.Contains(err.Error(), "continue from offset 20") {
+		t.Fatalf("Expected an interrupted fetch with resume instructions, got %v", err)
+	}
+	progress, err := service.memorySystem.SearchEntities("", fetchProgressEntityType, 10)
+	if err != nil || len(progress) != 1 || progress[0].Metadata["status"] != "interrupted" {
+		t.Fatalf("Expected one interrupted fetch, got %+v %v", progress, err)
+	}
+	if resultValueString(progress[0].Metadata["next_offset"]) != "20" {
+		t.Errorf("Expected the progress at offset 20, got %v", progress[0].Metadata)
+	}
+
+	// Re-running the same call only fetches what is missing
+	client.offsets = nil
+	response, err := service.runNQEQueryByID(args)
+	if err != nil {
+		t.Fatalf("Expected the resumed fetch to succeed, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Resumed an interrupted fetch: 20 rows were already fetched") || !strings.Contains(text, "Total items: 25") {
+		t.Errorf("Unexpected response: %s", text)
+	}
+	// Batches past the end may be fetched concurrently, but none before the resume offset
+	if !slices.Contains(client.offsets, 20) || slices.Min(client.offsets) != 20 {
+		t.Errorf("Expected the fetch to continue at offset 20, got %v", client.offsets)
+	}
+	if progress, _ := service.memorySystem.SearchEntities("", fetchProgressEntityType, 10); len(progress) != 0 {
+		t.Errorf("Expected the progress removed once the result is stored, got %d", len(progress))
+	}
+}
+
+func TestRunNQEQueryResumeToken(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = &forward.NQERunResult{}
+	for i := 0; i < 15; i++ {
+		mock.nqeResult.Items = append(mock.nqeResult.Items, map[string]interface{}{"device": fmt.Sprintf("device-%02d", i)})
+	}
+	service.forwardClient = &interruptedNQEClient{MockForwardClient: mock, failAt: 10}
+
+	args := RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_token", AllResults: true, Options: &NQEQueryOptions{Limit: 10}}
+	if _, err := service.runNQEQueryByID(args); err == nil {
+		t.Fatal("Expected the interrupted fetch to fail")
+	}
+	progress, _ := service.memorySystem.SearchEntities("", fetchProgressEntityType, 10)
+	if len(progress) != 1 {
+		t.Fatalf("Expected one fetch in progress, got %d", len(progress))
+	}
+
+	// The token only resumes the fetch it was issued for
+	other := args
+	other.QueryID, other.ResumeToken = "FQ_other", progress[0].ID
+	if _, err := service.runNQEQueryByID(other); err == nil || !strings.Contains(err.Error(), "belongs to a different fetch") {
+		t.Errorf("Expected a mismatched token to be rejected, got %v", err)
+	}
+
+	// A token implies all_results
+	resumed := args
+	resumed.AllResults, resumed.ResumeToken = false, progress[0].ID
+	response, err := service.runNQEQueryByID(resumed)
+	if err != nil {
+		t.Fatalf("Expected the fetch to resume, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Total items: 15") {
+		t.Errorf("Expected every row, got %s", text)
+	}
+	if _, err := service.runNQEQueryByID(resumed); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected a used token to be rejected, got %v", err)
+	}
+}
+
+func TestRunNQEQueryFetchPinsSnapshot(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = &forward.NQERunResult{}
+	for i := 0; i < 15; i++ {
+		mock.nqeResult.Items = append(mock.nqeResult.Items, map[string]interface{}{"device": fmt.Sprintf("device-%02d", i)})
+	}
+	client := &snapshotRecordingNQEClient{interruptedNQEClient: &interruptedNQEClient{MockForwardClient: mock, failAt: 10}}
+	service.forwardClient = client
+
+	args := RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_pinned", AllResults: true, Options: &NQEQueryOptions{Limit: 10}}
+	if _, err := service.runNQEQueryByID(args); err == nil {
+		t.Fatal("Expected the interrupted fetch to fail")
+	}
+	progress, _ := service.memorySystem.SearchEntities("", fetchProgressEntityType, 10)
+	if len(progress) != 1 || progress[0].Metadata["snapshot_id"] != "snapshot-123" {
+		t.Fatalf("Expected the progress to record the resolved snapshot, got %+v", progress)
+	}
+
+	// The latest snapshot moves on; the token still resumes against the snapshot the fetch began on
+	mock.snapshots = append([]forward.Snapshot{{ID: "snapshot-200", State: "PROCESSED", CreationDateMillis: 1840478621913}}, mock.snapshots...)
+	client.snapshotIDs = nil
+	resumed := args
+	resumed.ResumeToken = progress[0].ID
+	if _, err := service.runNQEQueryByID(resumed); err != nil {
+		t.Fatalf("Expected the fetch to resume, got %v", err)
+	}
+	for _, snapshotID := range client.snapshotIDs {
+		if snapshotID != "snapshot-123" {
+			t.Errorf("Expected every batch from snapshot-123, got %v", client.snapshotIDs)
+			break
+		}
+	}
+
+	// Without a token, an interrupted fetch is not resumed across snapshots
+	client.failAt = 10
+	if _, err := service.runNQEQueryByID(args); err == nil {
+		t.Fatal("Expected the interrupted fetch to fail")
+	}
+	mock.snapshots = append([]forward.Snapshot{{ID: "snapshot-300", State: "PROCESSED", CreationDateMillis: 1940478621913}}, mock.snapshots...)
+	client.offsets = nil
+	response, err := service.runNQEQueryByID(args)
+	if err != nil {
+		t.Fatalf("Expected a fresh fetch, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; strings.Contains(text, "Resumed an interrupted fetch") || !slices.Contains(client.offsets, 0) {
+		t.Errorf("Expected a fetch from the new snapshot to start over, got offsets %v: %s", client.offsets, text)
+	}
+}
+
+// snapshotRecordingNQEClient records the snapshot each batch is fetched from
+type snapshotRecordingNQEClient struct {
+	*interruptedNQEClient
+	snapshotIDs []string
+}
+
+func (c *snapshotRecordingNQEClient) RunNQEQueryByID(params *forward.NQEQueryParams) (*forward.NQERunResult, error) {
+	c.mutex.Lock()
+	c.snapshotIDs = append(c.snapshotIDs, params.SnapshotID)
+	c.mutex.Unlock()
+	return c.interruptedNQEClient.RunNQEQueryByID(params)
+}
@@ -0,0 +1,275 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Sampling methods of run_nqe_query_by_id
+const (
+	sampleFirst  = "first"
+	sampleRandom = "random"
+)
+
+const (
+	defaultSampleSize  = 20
+	maxSampleSize      = 500
+	sampleWindows      = 5  // Contiguous windows a random sample is drawn from
+	countRefineSteps   = 4  // Bisections narrowing the total once it is bracketed
+	maxCountProbes     = 24 // Single-row probes spent counting at most
+	sampleExampleChars = 40
+)
+
+// nqeTotal is what is known about the row count of a query result
+type nqeTotal struct {
+	Low      int // At least this many rows
+	High     int // At most this many rows (0 when unbounded)
+	Basis    string
+	Probes   int
+	Previous bool // Counted by earlier runs, which may have read another snapshot
+}
+
+// exact reports whether the count is known precisely for the snapshot being sampled
+func (t nqeTotal) exact() bool {
+	return !t.Previous && t.High == t.Low
+}
+
+func (t nqeTotal) String() string {
+	switch {
+	case t.Previous && t.High == t.Low:
+		return fmt.Sprintf("about %d rows (from a previous run)", t.Low)
+	case t.Previous:
+		return fmt.Sprintf("about %d rows (between %d and %d, from previous runs)", (t.Low+t.High)/2, t.Low, t.High)
+	case t.exact():
+		return fmt.Sprintf("exactly %d rows", t.Low)
+	case t.High == 0:
+		return fmt.Sprintf("at least %d rows", t.Low)
+	default:
+		return fmt.Sprintf("~%d rows (between %d and %d)", (t.Low+t.High)/2, t.Low, t.High)
+	}
+}
+
+// countNQERows brackets the row count of a result known to hold at least known rows: it
+// probes single rows at doubling offsets until one is missing, then bisects the bracket a few
+// times. exists reports whether a row exists at an offset.
+func countNQERows(known int, exists func(offset int) (bool, error)) (nqeTotal, error) {
+	total := nqeTotal{Low: known, Basis: "probe queries"}
+	offset := max(known, 1) * 2
+	for total.Probes < maxCountProbes {
+		total.Probes++
+		found, err := exists(offset - 1)
+		if err != nil {
+			return total, err
+		}
+		if !found {
+			total.High = offset - 1
+			break
+		}
+		total.Low = offset
+		offset *= 2
+	}
+	for step := 0; step < countRefineSteps && total.High > total.Low && total.Probes < maxCountProbes; step++ {
+		middle := (total.Low + total.High + 1) / 2
+		total.Probes++
+		found, err := exists(middle - 1)
+		if err != nil {
+			return total, err
+		}
+		if found {
+			total.Low = middle
+		} else {
+			total.High = middle - 1
+		}
+	}
+	return total, nil
+}
+
+// randomSampleWindows spreads a sample of size rows over windows contiguous runs at random
+// offsets of [0, population), one run per equal stratum so the runs never overlap
+func randomSampleWindows(population, size, windows int, intN func(int) int) [][2]int {
+	if population <= size {
+		return [][2]int{{0, population}}
+	}
+	windows = max(1, min(windows, size))
+	length := (size + windows - 1) / windows
+	stratum := population / windows
+	var runs [][2]int
+	for i, remaining := 0, size; i < windows && remaining > 0; i++ {
+		run := min(length, remaining, stratum)
+		start := i * stratum
+		if room := stratum - run; room > 0 {
+			start += intN(room + 1)
+		}
+		runs = append(runs, [2]int{start, run})
+		remaining -= run
+	}
+	return runs
+}
+
+// sampleColumn describes the shape of one column of a sample
+type sampleColumn struct {
+	Name    string
+	Types   []string
+	NonNull int
+	Example string
+}
+
+// describeSampleColumns lists the columns of sampled rows with the JSON types seen, how many
+// rows have a value and an example value
+func describeSampleColumns(rows []map[string]interface{}) []sampleColumn {
+	columns := make(map[string]*sampleColumn)
+	types := make(map[string]map[string]bool)
+	for _, row := range rows {
+		for name, value := range row {
+			column, ok := columns[name]
+			if !ok {
+				column = &sampleColumn{Name: name}
+				columns[name], types[name] = column, make(map[string]bool)
+			}
+			kind := "null"
+			switch value.(type) {
+			case string:
+				kind = "string"
+			case float64, int, int64:
+				kind = "number"
+			case bool:
+				kind = "bool"
+			case []interface{}:
+				kind = "array"
+			case map[string]interface{}:
+				kind = "object"
+			}
+			types[name][kind] = true
+			if value != nil {
+				column.NonNull++
+				if column.Example == "" {
+					column.Example = truncateString(resultValueString(value), sampleExampleChars)
+				}
+			}
+		}
+	}
+	described := make([]sampleColumn, 0, len(columns))
+	for name, column := range columns {
+		for kind := range types[name] {
+			column.Types = append(column.Types, kind)
+		}
+		sort.Strings(column.Types)
+		described = append(described, *column)
+	}
+	sort.Slice(described, func(i, j int) bool { return described[i].Name < described[j].Name })
+	return described
+}
+
+// sampleNQEQuery returns the first rows or a random sample of a library query result with
+// what is known about its total row count, without fetching the whole result
+func (s *ForwardMCPService) sampleNQEQuery(args RunNQEQueryByIDArgs, networkID, snapshotID string) (*mcp.ToolResponse, error) {
+	if args.AllResults {
+		return nil, fmt.Errorf("sample and all_results cannot be combined; sample first, then fetch everything with all_results")
+	}
+	size := args.SampleSize
+	if size <= 0 {
+		size = defaultSampleSize
+	}
+	size = min(size, maxSampleSize)
+	method := strings.ToLower(firstNonEmpty(args.SampleMethod, sampleFirst))
+	if method != sampleFirst && method != sampleRandom {
+		return nil, fmt.Errorf("unknown sample_method %q: use first or random", args.SampleMethod)
+	}
+	if err := s.checkNegativeCache(networkID, args.QueryID); err != nil {
+		return nil, err
+	}
+
+	fetch := func(offset, limit int) ([]map[string]interface{}, error) {
+		result, err := s.forwardClient.RunNQEQueryByID(&forward.NQEQueryParams{
+			NetworkID:  networkID,
+			QueryID:    args.QueryID,
+			SnapshotID: snapshotID,
+			Parameters: args.Parameters,
+			Options:    &forward.NQEQueryOptions{Limit: limit, Offset: offset},
+		})
+		s.recordQueryOutcome(networkID, args.QueryID, err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sample NQE query (offset %d): %w", offset, err)
+		}
+		if result == nil {
+			return nil, nil
+		}
+		return result.Items, nil
+	}
+
+	// One row past the sample tells whether the first page holds the whole result
+	rows, err := fetch(0, size+1)
+	if err != nil {
+		return nil, err
+	}
+	total := nqeTotal{Low: len(rows), High: len(rows), Basis: "the first page"}
+	if len(rows) > size {
+		rows = rows[:size]
+		total = s.historicalNQETotal(networkID, args.QueryID, size+1)
+		if total.Low == 0 {
+			total, err = countNQERows(size+1, func(offset int) (bool, error) {
+				items, err := fetch(offset, 1)
+				return len(items) > 0, err
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	description := fmt.Sprintf("first %d", len(rows))
+	if method == sampleRandom && total.Low > size {
+		// Only offsets below the known minimum are certain to hold rows
+		runs := randomSampleWindows(total.Low, size, sampleWindows, rand.IntN)
+		rows = rows[:0]
+		for _, run := range runs {
+			items, err := fetch(run[0], run[1])
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, items...)
+		}
+		description = fmt.Sprintf("%d random rows from %d windows", len(rows), len(runs))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sample of %s from %s on network %s\n", description, args.QueryID, networkID)
+	fmt.Fprintf(&b, "Estimated total: %s, from %s", total, total.Basis)
+	if total.Probes > 0 {
+		fmt.Fprintf(&b, " (%d single-row probes)", total.Probes)
+	}
+	b.WriteString("\n\n")
+	if columns := describeSampleColumns(rows); len(columns) > 0 {
+		b.WriteString("| Column | Types | Non-null | Example |\n|---|---|---|---|\n")
+		for _, column := range columns {
+			fmt.Fprintf(&b, "| %s | %s | %d/%d | %s |\n", column.Name, strings.Join(column.Types, ", "), column.NonNull, len(rows), column.Example)
+		}
+		b.WriteString("\n")
+	}
+	rowsJSON, _ := json.MarshalIndent(rows, "", "  ")
+	fmt.Fprintf(&b, "Rows:\n%s\n", rowsJSON)
+	if !total.exact() {
+		b.WriteString("\nUse all_results: true to fetch every row, or estimate_query_cost to see what that costs first.\n")
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(b.String())), nil
+}
+
+// historicalNQETotal returns the row count earlier complete runs on this network recorded,
+// when there are any and they are consistent with the rows seen now. Runs do not record their
+// snapshot, so the count is only an estimate for the snapshot being sampled.
+func (s *ForwardMCPService) historicalNQETotal(networkID, queryID string, seen int) nqeTotal {
+	if s.memorySystem == nil {
+		return nqeTotal{}
+	}
+	estimate, err := s.estimateQueryCostFor(networkID, queryID, 0)
+	if err != nil || estimate.Confidence == "low" || estimate.RowsLowerBound || estimate.Rows < seen {
+		return nqeTotal{}
+	}
+	return nqeTotal{Low: max(estimate.RowsMin, seen), High: max(estimate.RowsMax, seen), Basis: estimate.Basis, Previous: true}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountNQERows(t *testing.T) {
+	for _, rows := range []int{21, 40, 41, 1000, 12345} {
+		probes := 0
+		total, err := countNQERows(21, func(offset int) (bool, error) {
+			probes++
+			return offset < rows, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if total.Low > rows || (total.High != 0 && total.High < rows) {
+			t.Errorf("%d rows: bracket %d-%d misses the count", rows, total.Low, total.High)
+		}
+		if total.Probes != probes || probes > maxCountProbes {
+			t.Errorf("%d rows: expected at most %d counted probes, got %d (%d made)", rows, maxCountProbes, total.Probes, probes)
+		}
+	}
+
+	// Results just past the known rows are counted exactly
+	total, _ := countNQERows(21, func(offset int) (bool, error) { return offset < 23, nil })
+	if !total.exact() || total.Low != 23 || total.String() != "exactly 23 rows" {
+		t.Errorf("Expected exactly 23 rows, got %+v", total)
+	}
+}
+
+func TestRandomSampleWindows(t *testing.T) {
+	runs := randomSampleWindows(1000, 20, 5, func(n int) int { return n - 1 })
+	if len(runs) != 5 {
+		t.Fatalf("Expected 5 windows, got %v", runs)
+	}
+	rows := 0
+	for i, run := range runs {
+		if run[0] < i*200 || run[0]+run[1] > (i+1)*200 {
+			t.Errorf("Window %d %v leaves its stratum", i, run)
+		}
+		rows += run[1]
+	}
+	if rows != 20 {
+		t.Errorf("Expected 20 rows in all, got %d", rows)
+	}
+
+	if runs := randomSampleWindows(15, 20, 5, nil); len(runs) != 1 || runs[0] != [2]int{0, 15} {
+		t.Errorf("Expected the whole population, got %v", runs)
+	}
+}
+
+func TestSampleNQEQuery(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = pageResult(500)
+	mock.nqeResult.Items[3]["name"] = "spine-1"
+
+	response, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_big", Sample: true, SampleSize: 10})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{
+		"Sample of first 10 from FQ_big on network 162112",
+		"from probe queries",
+		"| name | string | 1/10 | spine-1 |",
+		"| row | number | 10/10 | 0 |",
+		"Use all_results: true",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	// A random sample draws from the whole known range
+	response, err = service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_big", SampleSize: 10, SampleMethod: "random"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "10 random rows from 5 windows") {
+		t.Errorf("Expected a random sample, got: %s", text)
+	}
+
+	// A result that fits in the sample is counted exactly
+	mock.nqeResult = pageResult(7)
+	response, _ = service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_small", Sample: true})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Estimated total: exactly 7 rows, from the first page") {
+		t.Errorf("Expected an exact count, got: %s", text)
+	}
+
+	if _, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_big", Sample: true, AllResults: true}); err == nil {
+		t.Error("Expected sample and all_results to be rejected together")
+	}
+	if _, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_big", Sample: true, SampleMethod: "middle"}); err == nil {
+		t.Error("Expected an unknown sample method to be rejected")
+	}
+}
+
+func TestSampleNQEQueryUsesHistory(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.apiTracker = NewAPIMemoryTracker(service.memorySystem, service.logger, "test")
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = pageResult(250)
+
+	for _, rows := range []int{240, 250, 260} {
+		if err := service.apiTracker.TrackBatchFetch("FQ_known", "162112", "snap-1", rows, 3, 4, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	response, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_known", Sample: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Estimated total: about 250 rows (between 240 and 260, from previous runs)") || strings.Contains(text, "probes") {
+		t.Errorf("Expected the count from earlier runs without probing, got: %s", text)
+	}
+	if !strings.Contains(text, "Use all_results: true") {
+		t.Errorf("Expected a count from earlier runs not to be treated as exact, got: %s", text)
+	}
+
+	if err := service.apiTracker.TrackBatchFetch("FQ_known_once", "162112", "snap-1", 250, 3, 4, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	response, err = service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_known_once", Sample: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Estimated total: about 250 rows (from a previous run)") {
+		t.Errorf("Expected a single earlier run not to be reported as exact, got: %s", text)
+	}
+}
@@ -0,0 +1,553 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const deviceOnboardingType = "device_onboarding"
+
+// Onboarding device statuses, from least to most complete
+const (
+	onboardingAwaiting  = "awaiting_snapshot" // No processed snapshot newer than the baseline yet
+	onboardingMissing   = "not_collected"     // A newer snapshot exists but the device is not in it
+	onboardingCollected = "collected"         // Collected but not yet at its planned location
+	onboardingLocated   = "located"           // Collected and at its planned location (or none was planned)
+)
+
+// Workflow state key holding the onboarding batch name
+const onboardingStateBatch = "onboarding_batch"
+
+// OnboardingDevice is a device expected to be collected once onboarding is done
+type OnboardingDevice struct {
+	Name         string `json:"name" jsonschema:"required,description=Device name as it will appear in Forward"`
+	ManagementIP string `json:"management_ip,omitempty" jsonschema:"description=Management IP; matches the device when it is collected under a different name"`
+	Location     string `json:"location,omitempty" jsonschema:"description=Name or ID of the location to assign the device to once collected"`
+}
+
+// DeviceOnboarding is a batch of devices being added to a network, recorded against the
+// snapshot that was newest when the batch was planned
+type DeviceOnboarding struct {
+	Batch              string             `json:"batch"`
+	NetworkID          string             `json:"network_id"`
+	BaselineSnapshotID string             `json:"baseline_snapshot_id,omitempty"`
+	Devices            []OnboardingDevice `json:"devices"`
+	CreatedAt          time.Time          `json:"created_at"`
+}
+
+// OnboardingDeviceStatus is the collection and location state of one onboarding device
+type OnboardingDeviceStatus struct {
+	OnboardingDevice
+	Status          string `json:"status"`
+	CollectedAs     string `json:"collected_as,omitempty"`
+	PlannedLocation string `json:"planned_location_id,omitempty"`
+	CurrentLocation string `json:"current_location_id,omitempty"`
+	Detail          string `json:"detail,omitempty"`
+}
+
+// OnboardingReport is the state of an onboarding batch in one snapshot
+type OnboardingReport struct {
+	Batch              string                   `json:"batch"`
+	NetworkID          string                   `json:"network_id"`
+	SnapshotID         string                   `json:"snapshot_id"`
+	BaselineSnapshotID string                   `json:"baseline_snapshot_id,omitempty"`
+	AwaitingSnapshot   bool                     `json:"awaiting_snapshot"`
+	Devices            []OnboardingDeviceStatus `json:"devices"`
+}
+
+// Counts returns how many devices are in each status
+func (r *OnboardingReport) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, device := range r.Devices {
+		counts[device.Status]++
+	}
+	return counts
+}
+
+// onboardingCollectionGuidance explains how to add collection sources, which the Forward API
+// used by this server cannot create
+func onboardingCollectionGuidance(networkID, baselineSnapshotID string) string {
+	return fmt.Sprintf(`## Next: add collection sources
+The Forward API used by this server does not manage device credentials or collection sources, so add them in the Forward UI for network %s:
+1. Add each device's management IP or hostname and device type to the network's collection sources
+2. Attach credentials that can log in to the devices (and a jump server where they need one)
+3. Run a collection, or wait for the next scheduled one
+
+Then run check_device_onboarding. Devices missing from a snapshot newer than the baseline (%s) usually point at unreachable management addresses or rejected credentials.`,
+		networkID, firstNonEmpty(baselineSnapshotID, "none"))
+}
+
+// parseOnboardingDevices parses "name[@management_ip][=location]" entries separated by commas,
+// semicolons or newlines
+func parseOnboardingDevices(answer string) []OnboardingDevice {
+	var devices []OnboardingDevice
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		spec, location, _ := strings.Cut(field, "=")
+		name, ip, _ := strings.Cut(spec, "@")
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		devices = append(devices, OnboardingDevice{Name: name, ManagementIP: strings.TrimSpace(ip), Location: strings.TrimSpace(location)})
+	}
+	return devices
+}
+
+// onboardingEntityName names the memory entity holding a batch
+func onboardingEntityName(networkID, batch string) string {
+	return fmt.Sprintf("%s:%s:%s", deviceOnboardingType, networkID, batch)
+}
+
+// planDeviceOnboarding records the devices being onboarded and the baseline snapshot that later
+// collection checks compare against. Planning a batch again replaces it.
+func (s *ForwardMCPService) planDeviceOnboarding(args PlanDeviceOnboardingArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("plan_device_onboarding", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	if len(args.Devices) == 0 {
+		return nil, fmt.Errorf("at least one device is required")
+	}
+	seen := make(map[string]bool)
+	for _, device := range args.Devices {
+		if strings.TrimSpace(device.Name) == "" {
+			return nil, fmt.Errorf("every device needs a name")
+		}
+		if seen[device.Name] {
+			return nil, fmt.Errorf("device %s is listed more than once", device.Name)
+		}
+		seen[device.Name] = true
+	}
+
+	batch := &DeviceOnboarding{
+		Batch:     firstNonEmpty(strings.TrimSpace(args.Batch), "onboarding-"+time.Now().UTC().Format("20060102-150405")),
+		NetworkID: networkID,
+		Devices:   args.Devices,
+		CreatedAt: time.Now().UTC(),
+	}
+	baseline, err := s.latestProcessedSnapshot(networkID)
+	if err != nil {
+		s.logger.Debug("No baseline snapshot for onboarding batch %s: %v", batch.Batch, err)
+	}
+	batch.BaselineSnapshotID = baseline
+
+	metadata := map[string]interface{}{
+		"batch":                batch.Batch,
+		"network_id":           networkID,
+		"baseline_snapshot_id": baseline,
+		"devices":              MarshalCompactJSONString(batch.Devices),
+		"created_at":           batch.CreatedAt.Format(time.RFC3339),
+	}
+	name := onboardingEntityName(networkID, batch.Batch)
+	if existing, err := s.memorySystem.getEntityByNameAndType(name, deviceOnboardingType); err == nil {
+		if err := s.memorySystem.updateEntityMetadata(existing.ID, metadata); err != nil {
+			return nil, fmt.Errorf("failed to update onboarding batch: %w", err)
+		}
+	} else if _, err := s.memorySystem.CreateEntity(name, deviceOnboardingType, metadata); err != nil {
+		return nil, fmt.Errorf("failed to store onboarding batch: %w", err)
+	}
+
+	locations, err := s.forwardClient.GetLocations(networkID)
+	if err != nil {
+		s.logger.Debug("Could not load locations for onboarding batch %s: %v", batch.Batch, err)
+	}
+	var text strings.Builder
+	fmt.Fprintf(&text, "# Onboarding batch %s: %d devices in network %s\n\n", batch.Batch, len(batch.Devices), networkID)
+	text.WriteString("| Device | Management IP | Planned Location |\n|---|---|---|\n")
+	var unknown []string
+	for _, device := range batch.Devices {
+		location := device.Location
+		if location != "" && err == nil && findLocation(locations, location) == nil {
+			unknown = append(unknown, location)
+			location += " ⚠️ not found"
+		}
+		fmt.Fprintf(&text, "| %s | %s | %s |\n", device.Name, device.ManagementIP, location)
+	}
+	if len(unknown) > 0 {
+		fmt.Fprintf(&text, "\n⚠️ Unknown locations: %s. Create them with create_location before assigning devices.\n", strings.Join(unknown, ", "))
+	}
+	fmt.Fprintf(&text, "\nBaseline snapshot: %s\n\n", firstNonEmpty(baseline, "none"))
+	text.WriteString(onboardingCollectionGuidance(networkID, baseline))
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// findLocation finds a location by ID or case-insensitive name
+func findLocation(locations []forward.Location, nameOrID string) *forward.Location {
+	for i := range locations {
+		if locations[i].ID == nameOrID || strings.EqualFold(locations[i].Name, nameOrID) {
+			return &locations[i]
+		}
+	}
+	return nil
+}
+
+// loadDeviceOnboarding reads an onboarding batch from memory
+func (s *ForwardMCPService) loadDeviceOnboarding(networkID, batch string) (*DeviceOnboarding, error) {
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	entity, err := s.memorySystem.getEntityByNameAndType(onboardingEntityName(networkID, batch), deviceOnboardingType)
+	if err != nil {
+		return nil, fmt.Errorf("onboarding batch '%s' not found in network %s; register it with plan_device_onboarding", batch, networkID)
+	}
+	onboarding := &DeviceOnboarding{
+		Batch:              batch,
+		NetworkID:          networkID,
+		BaselineSnapshotID: resultValueString(entity.Metadata["baseline_snapshot_id"]),
+	}
+	if err := json.Unmarshal([]byte(resultValueString(entity.Metadata["devices"])), &onboarding.Devices); err != nil {
+		return nil, fmt.Errorf("onboarding batch '%s' has unreadable devices: %w", batch, err)
+	}
+	if created, err := time.Parse(time.RFC3339, resultValueString(entity.Metadata["created_at"])); err == nil {
+		onboarding.CreatedAt = created
+	}
+	return onboarding, nil
+}
+
+// onboardingReport checks which devices of a batch are collected in a snapshot (default: the
+// newest processed one) and whether they sit at their planned locations
+func (s *ForwardMCPService) onboardingReport(batch *DeviceOnboarding, snapshotID string) (*OnboardingReport, error) {
+	report := &OnboardingReport{Batch: batch.Batch, NetworkID: batch.NetworkID, SnapshotID: snapshotID, BaselineSnapshotID: batch.BaselineSnapshotID}
+	if report.SnapshotID == "" {
+		latest, err := s.latestProcessedSnapshot(batch.NetworkID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the newest snapshot: %w", err)
+		}
+		report.SnapshotID = latest
+	}
+	report.AwaitingSnapshot = report.SnapshotID == batch.BaselineSnapshotID
+
+	devices, err := s.getNetworkDevices(batch.NetworkID, report.SnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device inventory: %w", err)
+	}
+	atlas, err := s.forwardClient.GetDeviceLocations(batch.NetworkID)
+	if err != nil {
+		s.logger.Debug("Could not load device locations for onboarding: %v", err)
+	}
+	locations, err := s.forwardClient.GetLocations(batch.NetworkID)
+	if err != nil {
+		s.logger.Debug("Could not load locations for onboarding: %v", err)
+	}
+
+	byName := make(map[string]forward.Device, len(devices))
+	byIP := make(map[string]forward.Device)
+	for _, device := range devices {
+		byName[device.Name] = device
+		for _, ip := range device.ManagementIPs {
+			byIP[ip] = device
+		}
+	}
+
+	for _, planned := range batch.Devices {
+		status := OnboardingDeviceStatus{OnboardingDevice: planned}
+		if planned.Location != "" {
+			if location := findLocation(locations, planned.Location); location != nil {
+				status.PlannedLocation = location.ID
+			}
+		}
+
+		device, collected := byName[s.canonicalDeviceName(batch.NetworkID, planned.Name, devices)]
+		if !collected && planned.ManagementIP != "" {
+			device, collected = byIP[planned.ManagementIP]
+		}
+		switch {
+		case !collected && report.AwaitingSnapshot:
+			status.Status = onboardingAwaiting
+			status.Detail = "no snapshot newer than the baseline yet"
+		case !collected:
+			status.Status = onboardingMissing
+			status.Detail = "not in the snapshot; check the collection source, reachability and credentials"
+		default:
+			status.CollectedAs = device.Name
+			status.CurrentLocation = deviceLocation(device, atlas)
+			switch {
+			case planned.Location == "" || status.PlannedLocation == status.CurrentLocation:
+				status.Status = onboardingLocated
+			case status.PlannedLocation == "":
+				status.Status = onboardingCollected
+				status.Detail = fmt.Sprintf("planned location %s not found", planned.Location)
+			default:
+				status.Status = onboardingCollected
+				status.Detail = "not yet at its planned location"
+			}
+		}
+		report.Devices = append(report.Devices, status)
+	}
+	return report, nil
+}
+
+// checkDeviceOnboarding reports the collection and location state of an onboarding batch
+func (s *ForwardMCPService) checkDeviceOnboarding(args CheckDeviceOnboardingArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("check_device_onboarding", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	batch, err := s.loadDeviceOnboarding(networkID, args.Batch)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.onboardingReport(batch, args.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(args.Format) {
+	case "json":
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(report))), nil
+	case "", "markdown":
+	default:
+		return nil, fmt.Errorf("unsupported format %q: use markdown or json", args.Format)
+	}
+
+	text := formatOnboardingReport(report)
+	counts := report.Counts()
+	switch {
+	case report.AwaitingSnapshot && counts[onboardingAwaiting] > 0:
+		text += fmt.Sprintf("\nNo snapshot is newer than the baseline %s yet. Collect a snapshot once the collection sources are added, then check again.", report.BaselineSnapshotID)
+	case counts[onboardingMissing] > 0:
+		text += "\nDevices not collected: fix their collection sources or credentials in Forward and collect a new snapshot."
+	case counts[onboardingCollected] > 0:
+		text += "\nNext, assign the collected devices to their planned locations with assign_onboarded_locations."
+	default:
+		text += "\n✅ Every device is collected and at its planned location."
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
+}
+
+// formatOnboardingReport renders a report as a markdown table
+func formatOnboardingReport(report *OnboardingReport) string {
+	var text strings.Builder
+	counts := report.Counts()
+	fmt.Fprintf(&text, "# Onboarding batch %s: snapshot %s\n\n", report.Batch, report.SnapshotID)
+	fmt.Fprintf(&text, "%d devices: %d located, %d collected, %d not collected, %d awaiting snapshot\n\n", len(report.Devices),
+		counts[onboardingLocated], counts[onboardingCollected], counts[onboardingMissing], counts[onboardingAwaiting])
+	text.WriteString("| Device | Management IP | Status | Collected As | Planned Location | Current Location | Detail |\n|---|---|---|---|---|---|---|\n")
+	for _, device := range report.Devices {
+		fmt.Fprintf(&text, "| %s | %s | %s | %s | %s | %s | %s |\n", device.Name, device.ManagementIP, onboardingStatusLabel(device.Status),
+			device.CollectedAs, firstNonEmpty(device.PlannedLocation, device.Location), device.CurrentLocation, device.Detail)
+	}
+	return text.String()
+}
+
+func onboardingStatusLabel(status string) string {
+	switch status {
+	case onboardingLocated:
+		return "✅ located"
+	case onboardingCollected:
+		return "🟡 collected"
+	case onboardingAwaiting:
+		return "⏳ awaiting snapshot"
+	}
+	return "❌ not collected"
+}
+
+// assignOnboardedLocations moves collected devices of a batch to their planned locations, or to
+// the locations given in args.Locations, through update_device_locations
+func (s *ForwardMCPService) assignOnboardedLocations(args AssignOnboardedLocationsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("assign_onboarded_locations", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	batch, err := s.loadDeviceOnboarding(networkID, args.Batch)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.onboardingReport(batch, "")
+	if err != nil {
+		return nil, err
+	}
+	locations, err := s.forwardClient.GetLocations(networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locations: %w", err)
+	}
+
+	assignments := make(map[string]string)
+	var skipped []string
+	for _, device := range report.Devices {
+		target := device.PlannedLocation
+		if override, ok := args.Locations[device.Name]; ok {
+			location := findLocation(locations, override)
+			if location == nil {
+				skipped = append(skipped, fmt.Sprintf("%s: location %s not found", device.Name, override))
+				continue
+			}
+			target = location.ID
+		}
+		switch {
+		case target == "":
+			if device.Location != "" {
+				skipped = append(skipped, fmt.Sprintf("%s: location %s not found", device.Name, device.Location))
+			}
+		case device.CollectedAs == "":
+			skipped = append(skipped, fmt.Sprintf("%s: not collected yet", device.Name))
+		case target != device.CurrentLocation:
+			assignments[device.CollectedAs] = target
+		}
+	}
+
+	var text strings.Builder
+	if len(assignments) == 0 {
+		text.WriteString("No devices need a location change.\n")
+	} else {
+		names := make([]string, 0, len(assignments))
+		for name := range assignments {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		verb := "Assigning"
+		if args.DryRun {
+			verb = "Would assign"
+		}
+		fmt.Fprintf(&text, "%s %d devices:\n", verb, len(assignments))
+		for _, name := range names {
+			label := assignments[name]
+			for _, location := range locations {
+				if location.ID == label {
+					label = fmt.Sprintf("%s (%s)", location.Name, location.ID)
+				}
+			}
+			fmt.Fprintf(&text, "- %s → %s\n", name, label)
+		}
+		if !args.DryRun {
+			response, err := s.updateDeviceLocations(UpdateDeviceLocationsArgs{NetworkID: networkID, Locations: assignments})
+			if err != nil {
+				return nil, err
+			}
+			text.WriteString("\n" + response.Content[0].TextContent.Text + "\n")
+		}
+	}
+	if len(skipped) > 0 {
+		text.WriteString("\nSkipped:\n")
+		for _, reason := range skipped {
+			fmt.Fprintf(&text, "- %s\n", reason)
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// onboardDevicesWorkflow guides planning new devices, validating their collection and
+// assigning them to locations
+func (s *ForwardMCPService) onboardDevicesWorkflow(args OnboardDevicesWorkflowArgs) (*mcp.ToolResponse, error) {
+	sessionID := fmt.Sprintf("onboard_session_%v", args.SessionID)
+	step, err := s.resolveWorkflowStep(workflowOnboardDevices, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
+	state := s.workflowManager.GetState(sessionID)
+	if step != "start" && step != "network_selected" && state.NetworkID == "" {
+		return nil, fmt.Errorf("select a network first (step network_selected, answer network_id=<id>)")
+	}
+	batch, _ := state.Parameters[onboardingStateBatch].(string)
+
+	var response *mcp.ToolResponse
+	switch step {
+	case "network_selected":
+		values := parseWorkflowKeyValues(args.Answer)
+		networkID := s.getNetworkID(values["network_id"])
+		if networkID == "" {
+			return nil, newCodedError(CodeNetworkIDRequired)
+		}
+		if err := s.checkNetworkAccess(networkID); err != nil {
+			return nil, err
+		}
+		state.NetworkID = networkID
+		state.Parameters[onboardingStateBatch] = firstNonEmpty(values["batch"], "onboarding-"+time.Now().UTC().Format("20060102-150405"))
+		s.workflowManager.SetState(sessionID, state)
+		response = mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+			"📋 Onboarding batch %s in network %s.\n\nNext, list the devices being added as name[@management_ip][=location], separated by commas or semicolons.",
+			state.Parameters[onboardingStateBatch], networkID)))
+	case "devices_planned":
+		response, err = s.planDeviceOnboarding(PlanDeviceOnboardingArgs{NetworkID: state.NetworkID, Batch: batch, Devices: parseOnboardingDevices(args.Answer)})
+	case "check_collection":
+		response, err = s.checkDeviceOnboarding(CheckDeviceOnboardingArgs{NetworkID: state.NetworkID, Batch: batch})
+	case "assign_locations":
+		// Prompts cannot be gated as write tools, so this step only plans the moves and
+		// leaves applying them to assign_onboarded_locations
+		response, err = s.assignOnboardedLocations(AssignOnboardedLocationsArgs{NetworkID: state.NetworkID, Batch: batch, DryRun: true})
+		if err == nil {
+			response = mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+				"%s\nTo apply these assignments, call assign_onboarded_locations with network_id=%s and batch=%s.",
+				response.Content[0].TextContent.Text, state.NetworkID, batch)))
+		}
+	case "onboarding_summary":
+		response, err = s.onboardingSummary(state.NetworkID, batch)
+	default:
+		step = "start"
+		response = mcp.NewToolResponse(mcp.NewTextContent(`🧩 **Device Onboarding Workflow**
+
+This workflow takes new devices from planning to collected and placed:
+
+1. **network_selected** - Choose the network and name the onboarding batch
+2. **devices_planned** - List the devices, their management IPs and planned locations, and get collection source guidance
+3. **check_collection** - Confirm the devices appear in a snapshot newer than the baseline
+4. **assign_locations** - Preview moving collected devices to their planned locations, then apply it with assign_onboarded_locations
+5. **onboarding_summary** - Summarize what is done and what still blocks the batch
+
+Device credentials and collection sources are added in the Forward UI; the Forward API used by this server does not manage them.`))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.finishWorkflowStep(workflowOnboardDevices, sessionID, step, response), nil
+}
+
+// onboardingSummary renders the batch as a checklist
+func (s *ForwardMCPService) onboardingSummary(networkID, batchName string) (*mcp.ToolResponse, error) {
+	batch, err := s.loadDeviceOnboarding(networkID, batchName)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.onboardingReport(batch, "")
+	if err != nil {
+		return nil, err
+	}
+	counts := report.Counts()
+	total := len(report.Devices)
+	collected := counts[onboardingLocated] + counts[onboardingCollected]
+
+	var text strings.Builder
+	status := "✅ COMPLETE"
+	if counts[onboardingLocated] < total {
+		status = "⏳ INCOMPLETE"
+	}
+	fmt.Fprintf(&text, "# Device Onboarding: %s — %s\n\n", batch.Batch, status)
+	fmt.Fprintf(&text, "- [x] devices planned: %d (baseline snapshot %s)\n", total, firstNonEmpty(batch.BaselineSnapshotID, "none"))
+	fmt.Fprintf(&text, "- [%s] devices collected: %d/%d in snapshot %s\n", checkboxMark(collected == total), collected, total, report.SnapshotID)
+	fmt.Fprintf(&text, "- [%s] locations assigned: %d/%d\n", checkboxMark(counts[onboardingLocated] == total), counts[onboardingLocated], total)
+
+	var open []string
+	for _, device := range report.Devices {
+		if device.Status != onboardingLocated {
+			open = append(open, fmt.Sprintf("%s: %s", device.Name, strings.TrimSpace(onboardingStatusLabel(device.Status)+" "+device.Detail)))
+		}
+	}
+	if len(open) > 0 {
+		text.WriteString("\n## Open items\n")
+		for _, item := range open {
+			fmt.Fprintf(&text, "- %s\n", item)
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+func checkboxMark(done bool) string {
+	if done {
+		return "x"
+	}
+	return " "
+}
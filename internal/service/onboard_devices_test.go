@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestParseOnboardingDevices(t *testing.T) {
+	devices := parseOnboardingDevices("edge-1@10.9.9.1=Data Center 2; edge-2 ,\nedge-3=location-1,")
+	if len(devices) != 3 {
+		t.Fatalf("Expected 3 devices, got %+v", devices)
+	}
+	if devices[0] != (OnboardingDevice{Name: "edge-1", ManagementIP: "10.9.9.1", Location: "Data Center 2"}) ||
+		devices[1] != (OnboardingDevice{Name: "edge-2"}) || devices[2] != (OnboardingDevice{Name: "edge-3", Location: "location-1"}) {
+		t.Errorf("Unexpected devices: %+v", devices)
+	}
+}
+
+func TestDeviceOnboarding(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	batch := uniqueTestName("batch-")
+
+	response, err := service.planDeviceOnboarding(PlanDeviceOnboardingArgs{NetworkID: "162112", Batch: batch, Devices: []OnboardingDevice{
+		{Name: "router-1", Location: "Data Center 1"},
+		{Name: "edge-9", ManagementIP: "10.9.9.9", Location: "Data Center 2"},
+		{Name: "edge-10", Location: "Nowhere"},
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"3 devices in network 162112", "Nowhere ⚠️ not found", "Baseline snapshot: snapshot-123", "collection sources"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	response, err = service.checkDeviceOnboarding(CheckDeviceOnboardingArgs{NetworkID: "162112", Batch: batch})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !strings.Contains(text, "1 located, 0 collected, 0 not collected, 2 awaiting snapshot") || !strings.Contains(text, "No snapshot is newer than the baseline") {
+		t.Errorf("Expected the new devices to await a snapshot: %s", text)
+	}
+
+	// A new snapshot collects edge-9 under its FQDN
+	client.snapshots = append(client.snapshots, forward.Snapshot{ID: "snapshot-124", State: "PROCESSED", CreationDateMillis: 1740478621914})
+	client.devices = append(client.devices, forward.Device{Name: "edge-9.lab", ManagementIPs: []string{"10.9.9.9"}})
+	response, err = service.checkDeviceOnboarding(CheckDeviceOnboardingArgs{NetworkID: "162112", Batch: batch})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Onboarding batch " + batch + ": snapshot snapshot-124", "1 located, 1 collected, 1 not collected, 0 awaiting snapshot",
+		"| edge-9 | 10.9.9.9 | 🟡 collected | edge-9.lab | location-2 |", "| edge-10 |  | ❌ not collected |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	response, err = service.assignOnboardedLocations(AssignOnboardedLocationsArgs{NetworkID: "162112", Batch: batch, DryRun: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Would assign 1 devices") || !strings.Contains(text, "edge-9.lab → Data Center 2 (location-2)") ||
+		!strings.Contains(text, "edge-10: location Nowhere not found") || client.deviceLocations["edge-9.lab"] != "" {
+		t.Errorf("Expected a dry run preview only: %s", text)
+	}
+	if _, err := service.assignOnboardedLocations(AssignOnboardedLocationsArgs{NetworkID: "162112", Batch: batch}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.deviceLocations["edge-9.lab"] != "location-2" {
+		t.Errorf("Expected edge-9.lab assigned to location-2, got %v", client.deviceLocations)
+	}
+
+	if _, err := service.checkDeviceOnboarding(CheckDeviceOnboardingArgs{NetworkID: "162112", Batch: "no-such-batch"}); err == nil {
+		t.Error("Expected an unknown batch to fail")
+	}
+}
+
+func TestOnboardDevicesWorkflow(t *testing.T) {
+	service := createTestService()
+	batch := uniqueTestName("wf-")
+
+	if _, err := service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1", Step: "check_collection"}); err == nil {
+		t.Error("Expected check_collection to require a network first")
+	}
+	if _, err := service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1", Step: "network_selected", Answer: "network_id=162112, batch=" + batch}); err != nil {
+		t.Fatalf("Expected network selection to succeed, got: %v", err)
+	}
+	if _, err := service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1", Step: "devices_planned", Answer: "switch-1=Data Center 1; edge-20@10.20.0.1"}); err != nil {
+		t.Fatalf("Expected devices to be planned, got: %v", err)
+	}
+	response, err := service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1", Step: "check_collection"})
+	if err != nil {
+		t.Fatalf("Expected collection check to run, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "| switch-1 |  | 🟡 collected | switch-1 | location-1 | location-2 |") ||
+		!strings.Contains(text, `"current_step":"check_collection"`) {
+		t.Errorf("Expected switch-1 collected at the wrong location: %s", text)
+	}
+
+	response, err = service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1", Step: "assign_locations"})
+	if err != nil {
+		t.Fatalf("Expected location preview, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Would assign 1 devices") || !strings.Contains(text, "call assign_onboarded_locations with network_id=162112 and batch="+batch) {
+		t.Errorf("Expected the prompt to preview assignments only: %s", text)
+	}
+
+	response, err = service.onboardDevicesWorkflow(OnboardDevicesWorkflowArgs{SessionID: "ob-1", Step: "onboarding_summary"})
+	if err != nil {
+		t.Fatalf("Expected summary, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Device Onboarding: " + batch + " — ⏳ INCOMPLETE", "- [ ] devices collected: 1/2", "- [ ] locations assigned: 0/2", "edge-20: ⏳ awaiting snapshot"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Pagination describes the page a list tool returned, so clients can iterate without
+// parsing the prose summary
+type Pagination struct {
+	Total      int    `json:"total"`
+	Offset     int    `json:"offset"`
+	Limit      int    `json:"limit"`
+	Returned   int    `json:"returned"`
+	NextOffset *int   `json:"next_offset"`         // Offset of the next page; null on the last page
+	HasMore    bool   `json:"has_more"`            // Whether another page is available
+	EntityID   string `json:"entity_id,omitempty"` // Memory entity holding the full result set
+	// Whether the source does not report its size, so total only counts the items up to this page
+	TotalUnknown bool `json:"total_unknown,omitempty"`
+}
+
+// newPagination describes returned items starting at offset out of total
+func newPagination(total, offset, limit, returned int) Pagination {
+	page := Pagination{Total: total, Offset: offset, Limit: limit, Returned: returned}
+	if next := offset + returned; returned > 0 && next < total {
+		page.NextOffset = &next
+		page.HasMore = true
+	}
+	return page
+}
+
+// openPagination describes returned items starting at offset from a source that does not
+// report its size; a full page may be followed by another
+func openPagination(offset, limit, returned int) Pagination {
+	page := Pagination{Total: offset + returned, Offset: offset, Limit: limit, Returned: returned, TotalUnknown: true}
+	if next := offset + returned; returned > 0 && returned >= limit {
+		page.NextOffset = &next
+		page.HasMore = true
+	}
+	return page
+}
+
+// listPagination describes the page of a list tool. With all set, the whole result set was
+// returned and, when it was stored, entityID names the memory entity holding it.
+func listPagination(total, offset, limit, returned int, all bool, entityID string) Pagination {
+	if all {
+		page := newPagination(total, 0, total, returned)
+		page.EntityID = entityID
+		return page
+	}
+	return newPagination(total, offset, limit, returned)
+}
+
+// paginatedResponse builds a tool response from text, appending the pagination metadata as
+// a trailing JSON block
+func paginatedResponse(text string, page Pagination) *mcp.ToolResponse {
+	metadata, _ := json.Marshal(map[string]Pagination{"pagination": page})
+	return mcp.NewToolResponse(mcp.NewTextContent(text), mcp.NewTextContent(string(metadata)))
+}
+
+// withNote returns a copy of response with note added after its content but before a
+// trailing pagination block, which stays last. The response itself is not changed, since
+// coalesced calls share it.
+func withNote(response *mcp.ToolResponse, note string) *mcp.ToolResponse {
+	contents := make([]*mcp.Content, 0, len(response.Content)+1)
+	contents = append(contents, response.Content...)
+	position := len(contents)
+	if last := position - 1; last >= 0 && contents[last] != nil && contents[last].TextContent != nil &&
+		strings.HasPrefix(contents[last].TextContent.Text, `{"pagination":`) {
+		position = last
+	}
+	contents = append(contents[:position], append([]*mcp.Content{mcp.NewTextContent(note)}, contents[position:]...)...)
+	return mcp.NewToolResponse(contents...)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// responsePagination parses the trailing pagination block of a list response
+func responsePagination(t *testing.T, response *mcp.ToolResponse) Pagination {
+	t.Helper()
+	if len(response.Content) < 2 {
+		t.Fatalf("Expected a trailing pagination block, got %d content items", len(response.Content))
+	}
+	var metadata struct {
+		Pagination Pagination `json:"pagination"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[len(response.Content)-1].TextContent.Text), &metadata); err != nil {
+		t.Fatalf("Expected JSON pagination metadata, got %v", err)
+	}
+	return metadata.Pagination
+}
+
+func TestNewPagination(t *testing.T) {
+	page := newPagination(5, 0, 2, 2)
+	if !page.HasMore || page.NextOffset == nil || *page.NextOffset != 2 {
+		t.Errorf("Expected a next page at offset 2, got %+v", page)
+	}
+	if page := newPagination(5, 4, 2, 1); page.HasMore || page.NextOffset != nil {
+		t.Errorf("Expected the last page, got %+v", page)
+	}
+	if page := newPagination(5, 10, 2, 0); page.HasMore || page.NextOffset != nil {
+		t.Errorf("Expected no next page past the end, got %+v", page)
+	}
+
+	if page := openPagination(20, 10, 10); !page.TotalUnknown || page.Total != 30 || !page.HasMore || *page.NextOffset != 30 {
+		t.Errorf("Expected a possible next page after a full page, got %+v", page)
+	}
+	if page := openPagination(20, 10, 4); page.HasMore || page.NextOffset != nil {
+		t.Errorf("Expected a short page to be the last, got %+v", page)
+	}
+
+	data, _ := json.Marshal(newPagination(1, 0, 25, 1))
+	if string(data) != `{"total":1,"offset":0,"limit":25,"returned":1,"next_offset":null,"has_more":false}` {
+		t.Errorf("Unexpected encoding: %s", data)
+	}
+}
+
+func TestDeviceLocationsPagination(t *testing.T) {
+	service := createTestService()
+
+	// Walking next_offset visits every device once
+	seen := make(map[string]bool)
+	offset := 0
+	for pages := 0; ; pages++ {
+		if pages > 2 {
+			t.Fatal("Expected pagination to terminate")
+		}
+		response, err := service.getDeviceLocations(GetDeviceLocationsArgs{NetworkID: "162112", Limit: 1, Offset: offset})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		page := responsePagination(t, response)
+		if page.Total != 2 || page.Returned != 1 || page.Offset != offset || page.Limit != 1 {
+			t.Fatalf("Unexpected page: %+v", page)
+		}
+		seen[response.Content[0].TextContent.Text] = true
+		if !page.HasMore {
+			break
+		}
+		offset = *page.NextOffset
+	}
+	if len(seen) != 2 {
+		t.Errorf("Expected two distinct pages, got %d", len(seen))
+	}
+}
+
+func TestRelationsPaginationWithAllResults(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	memory := service.memorySystem
+
+	router, _ := memory.CreateEntity("router-1", "device", nil)
+	for _, name := range []string{"site-a", "site-b", "site-c"} {
+		site, _ := memory.CreateEntity(name, "site", nil)
+		if _, err := memory.CreateRelation(router.ID, site.ID, "located_at", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	response, err := service.getRelations(GetRelationsArgs{EntityID: router.ID, Limit: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if page := responsePagination(t, response); page.Total != 3 || !page.HasMore || *page.NextOffset != 2 || page.EntityID != "" {
+		t.Errorf("Unexpected first page: %+v", page)
+	}
+
+	response, err = service.getRelations(GetRelationsArgs{EntityID: router.ID, AllResults: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	page := responsePagination(t, response)
+	if page.Total != 3 || page.Returned != 3 || page.HasMore || page.EntityID == "" {
+		t.Fatalf("Expected the full result set with its entity, got %+v", page)
+	}
+	if _, err := memory.GetEntity(page.EntityID); err != nil {
+		t.Errorf("Expected the stored result entity, got %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Kinds of values suggest_parameter_values can propose
+const (
+	parameterKindDevice    = "device"
+	parameterKindInterface = "interface"
+	parameterKindVLAN      = "vlan"
+	parameterKindPrefix    = "prefix"
+	parameterKindIP        = "ip"
+)
+
+const (
+	defaultParameterSuggestions = 20
+	maxParameterSuggestions     = 100
+)
+
+// parameterKindHints map words in a parameter's name, type or description to the kind of
+// value it takes, most specific first, so deviceInterface is an interface and vlanId a VLAN
+var parameterKindHints = []struct {
+	kind  string
+	words []string
+}{
+	{parameterKindVLAN, []string{"vlan"}},
+	{parameterKindInterface, []string{"interface", "iface", "port"}},
+	{parameterKindPrefix, []string{"prefix", "subnet", "cidr"}},
+	{parameterKindIP, []string{"ip", "address", "addr"}},
+	{parameterKindDevice, []string{"device", "hostname", "host", "node", "router", "switch", "firewall"}},
+}
+
+// ParameterSuggestion is one value proposed for a parameter
+type ParameterSuggestion struct {
+	Value  string `json:"value"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// inferParameterKind guesses the kind of value a query parameter takes from the words of its
+// name, then its type, then its description
+func inferParameterKind(parameter NQEQueryParameter) (string, bool) {
+	for _, text := range []string{parameter.Name, parameter.Type, parameter.Description} {
+		words := parameterWords(text)
+		for _, hint := range parameterKindHints {
+			for _, word := range hint.words {
+				if words[word] || words[word+"s"] || words[word+"es"] {
+					return hint.kind, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// parameterWords splits camelCase, snake_case and prose into lowercase words
+func parameterWords(text string) map[string]bool {
+	words := make(map[string]bool)
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words[strings.ToLower(string(word))] = true
+			word = word[:0]
+		}
+	}
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))):
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// parameterValueCandidates collects the distinct values of a kind from the inventory and
+// prefix index, optionally limited to one device. Values are sorted.
+func parameterValueCandidates(kind, device string, devices []forward.Device, prefixes []PrefixIndexEntry) []ParameterSuggestion {
+	onDevice := func(name string) bool { return device == "" || strings.EqualFold(name, device) }
+	var suggestions []ParameterSuggestion
+	switch kind {
+	case parameterKindDevice:
+		for _, d := range devices {
+			suggestions = append(suggestions, ParameterSuggestion{Value: d.Name, Detail: strings.Join(nonEmptyStrings(d.Vendor, d.Platform, d.Model), " ")})
+		}
+	case parameterKindInterface:
+		if device != "" {
+			for _, d := range devices {
+				if !onDevice(d.Name) {
+					continue
+				}
+				for _, iface := range d.Interfaces {
+					suggestions = append(suggestions, ParameterSuggestion{Value: iface.Name, Detail: strings.Join(nonEmptyStrings(iface.IPAddress, iface.Description), " ")})
+				}
+			}
+			break
+		}
+		counts := make(map[string]int)
+		for _, d := range devices {
+			for _, iface := range d.Interfaces {
+				counts[iface.Name]++
+			}
+		}
+		for name, count := range counts {
+			suggestions = append(suggestions, ParameterSuggestion{Value: name, Detail: fmt.Sprintf("on %d devices", count)})
+		}
+	case parameterKindVLAN:
+		// VLANs with an SVI; switched-only VLANs need get_vlan_inventory
+		vlans := make(map[int][]string)
+		for _, d := range devices {
+			if !onDevice(d.Name) {
+				continue
+			}
+			for _, iface := range d.Interfaces {
+				if match := sviNamePattern.FindStringSubmatch(iface.Name); match != nil {
+					if id, err := strconv.Atoi(match[1]); err == nil && id > 0 && id <= maxVLANID {
+						vlans[id] = append(vlans[id], d.Name)
+					}
+				}
+			}
+		}
+		ids := make([]int, 0, len(vlans))
+		for id := range vlans {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			suggestions = append(suggestions, ParameterSuggestion{Value: strconv.Itoa(id), Detail: fmt.Sprintf("SVI on %s", summarizeNames(vlans[id], 3))})
+		}
+		return suggestions
+	case parameterKindPrefix:
+		owners := make(map[string][]string)
+		for _, entry := range prefixes {
+			if onDevice(entry.Device) && !slices.Contains(owners[entry.Prefix], entry.Device) {
+				owners[entry.Prefix] = append(owners[entry.Prefix], entry.Device)
+			}
+		}
+		for prefix, names := range owners {
+			suggestions = append(suggestions, ParameterSuggestion{Value: prefix, Detail: fmt.Sprintf("on %s", summarizeNames(names, 3))})
+		}
+	case parameterKindIP:
+		for _, entry := range prefixes {
+			if onDevice(entry.Device) {
+				suggestions = append(suggestions, ParameterSuggestion{Value: entry.IP, Detail: entry.Device + " " + entry.Interface})
+			}
+		}
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool { return suggestions[i].Value < suggestions[j].Value })
+	return suggestions
+}
+
+// filterParameterSuggestions keeps the values starting with the typed text, case-insensitively.
+// When none do, values containing it are returned instead and contains is true.
+func filterParameterSuggestions(suggestions []ParameterSuggestion, typed string) (matches []ParameterSuggestion, contains bool) {
+	typed = strings.ToLower(strings.TrimSpace(typed))
+	if typed == "" {
+		return suggestions, false
+	}
+	var containing []ParameterSuggestion
+	for _, suggestion := range suggestions {
+		value := strings.ToLower(suggestion.Value)
+		if strings.HasPrefix(value, typed) {
+			matches = append(matches, suggestion)
+		} else if strings.Contains(value, typed) {
+			containing = append(containing, suggestion)
+		}
+	}
+	if len(matches) == 0 && len(containing) > 0 {
+		return containing, true
+	}
+	return matches, false
+}
+
+// summarizeNames lists up to limit names and counts the rest
+func summarizeNames(names []string, limit int) string {
+	if len(names) <= limit {
+		return strings.Join(names, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(names[:limit], ", "), len(names)-limit)
+}
+
+func nonEmptyStrings(values ...string) []string {
+	var result []string
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// suggestParameterValues proposes valid values for query parameters from the cached device
+// inventory and prefix index
+func (s *ForwardMCPService) suggestParameterValues(args SuggestParameterValuesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("suggest_parameter_values", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	limit := args.Limit
+	if limit <= 0 {
+		limit = defaultParameterSuggestions
+	}
+	if limit > maxParameterSuggestions {
+		limit = maxParameterSuggestions
+	}
+
+	// The parameters to suggest values for, with their kinds
+	var parameters []NQEQueryParameter
+	kinds := make(map[string]string)
+	kind := strings.ToLower(strings.TrimSpace(args.Kind))
+	switch {
+	case args.QueryID != "":
+		detail, err := s.resolveNQEQueryDetail(args.QueryID)
+		if err != nil {
+			return nil, err
+		}
+		declared := extractNQEParameters(detail.SourceCode)
+		if len(declared) == 0 {
+			return nil, fmt.Errorf("query %s declares no parameters, or its source is not available; pass kind instead", args.QueryID)
+		}
+		for _, parameter := range declared {
+			if args.Parameter != "" && !strings.EqualFold(parameter.Name, args.Parameter) {
+				continue
+			}
+			parameterKind, ok := kind, kind != ""
+			if !ok {
+				parameterKind, ok = inferParameterKind(parameter)
+			}
+			if ok {
+				parameters = append(parameters, parameter)
+				kinds[parameter.Name] = parameterKind
+			}
+		}
+		if len(parameters) == 0 {
+			names := make([]string, len(declared))
+			for i, parameter := range declared {
+				names[i] = parameter.Name
+			}
+			if args.Parameter != "" && !slices.ContainsFunc(names, func(name string) bool { return strings.EqualFold(name, args.Parameter) }) {
+				return nil, fmt.Errorf("query %s has no parameter %s; its parameters are %s", args.QueryID, args.Parameter, strings.Join(names, ", "))
+			}
+			return nil, fmt.Errorf("cannot tell which values parameters %s of query %s take; pass kind (device, interface, vlan, prefix or ip)", strings.Join(names, ", "), args.QueryID)
+		}
+	case kind != "":
+		name := args.Parameter
+		if name == "" {
+			name = kind
+		}
+		parameters = []NQEQueryParameter{{Name: name}}
+		kinds[name] = kind
+	case args.Parameter != "":
+		parameter := NQEQueryParameter{Name: args.Parameter}
+		parameterKind, ok := inferParameterKind(parameter)
+		if !ok {
+			return nil, fmt.Errorf("cannot tell which values %s takes; pass kind (device, interface, vlan, prefix or ip)", args.Parameter)
+		}
+		parameters = []NQEQueryParameter{parameter}
+		kinds[parameter.Name] = parameterKind
+	default:
+		return nil, fmt.Errorf("pass query_id, parameter or kind (device, interface, vlan, prefix or ip)")
+	}
+	for _, parameterKind := range kinds {
+		switch parameterKind {
+		case parameterKindDevice, parameterKindInterface, parameterKindVLAN, parameterKindPrefix, parameterKindIP:
+		default:
+			return nil, fmt.Errorf("unknown kind %q; use device, interface, vlan, prefix or ip", parameterKind)
+		}
+	}
+
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device inventory: %w", err)
+	}
+	var prefixes []PrefixIndexEntry
+	for _, parameterKind := range kinds {
+		if parameterKind == parameterKindPrefix || parameterKind == parameterKindIP {
+			if prefixes, err = s.prefixIndexEntries(networkID, snapshotID, false); err != nil {
+				return nil, fmt.Errorf("failed to load prefix index: %w", err)
+			}
+			break
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Parameter Suggestions (network %s)\n", networkID))
+	example := make(map[string]interface{})
+	for _, parameter := range parameters {
+		parameterKind := kinds[parameter.Name]
+		candidates := parameterValueCandidates(parameterKind, args.Device, devices, prefixes)
+		matches, contains := filterParameterSuggestions(candidates, args.Prefix)
+
+		heading := parameter.Name
+		if parameter.Type != "" {
+			heading += ": " + parameter.Type
+		}
+		text.WriteString(fmt.Sprintf("\n### %s (%s)\n", heading, parameterKind))
+		switch {
+		case len(matches) == 0 && args.Prefix != "":
+			text.WriteString(fmt.Sprintf("No %s values match %q among %d known values.\n", parameterKind, args.Prefix, len(candidates)))
+			continue
+		case len(matches) == 0:
+			text.WriteString(fmt.Sprintf("No %s values found in the inventory.\n", parameterKind))
+			continue
+		case contains:
+			text.WriteString(fmt.Sprintf("No values start with %q; showing %d of %d that contain it.\n", args.Prefix, min(len(matches), limit), len(matches)))
+		default:
+			text.WriteString(fmt.Sprintf("Showing %d of %d values.\n", min(len(matches), limit), len(matches)))
+		}
+		text.WriteString("\n| Value | Detail |\n|---|---|\n")
+		for _, match := range matches[:min(len(matches), limit)] {
+			text.WriteString(fmt.Sprintf("| %s | %s |\n", match.Value, match.Detail))
+		}
+
+		var value interface{} = matches[0].Value
+		if number, err := strconv.Atoi(matches[0].Value); err == nil && (parameterKind == parameterKindVLAN || strings.EqualFold(parameter.Type, "Number")) {
+			value = number
+		}
+		example[parameter.Name] = value
+		if parameterKind == parameterKindVLAN {
+			text.WriteString("\nVLANs come from SVIs in the inventory; get_vlan_inventory also lists VLANs that are only switched.\n")
+		}
+	}
+	if args.QueryID != "" && len(example) > 0 {
+		exampleJSON, _ := json.Marshal(example)
+		text.WriteString(fmt.Sprintf("\nExample: run_nqe_query_by_id query_id=%s parameters=%s\n", args.QueryID, exampleJSON))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestInferParameterKind(t *testing.T) {
+	cases := map[NQEQueryParameter]string{
+		{Name: "deviceName", Type: "String"}:               parameterKindDevice,
+		{Name: "vlanId", Type: "Number"}:                   parameterKindVLAN,
+		{Name: "ifaceName"}:                                parameterKindInterface,
+		{Name: "deviceInterface"}:                          parameterKindInterface,
+		{Name: "target", Type: "IpSubnet"}:                 parameterKindPrefix,
+		{Name: "src", Type: "IpAddress"}:                   parameterKindIP,
+		{Name: "hostname"}:                                 parameterKindDevice,
+		{Name: "x", Description: "Switch to inspect"}:      parameterKindDevice,
+		{Name: "y", Description: "Description of the row"}: "",
+	}
+	for parameter, expected := range cases {
+		if kind, _ := inferParameterKind(parameter); kind != expected {
+			t.Errorf("inferParameterKind(%+v) = %q; want %q", parameter, kind, expected)
+		}
+	}
+}
+
+func TestParameterValueCandidates(t *testing.T) {
+	devices := []forward.Device{
+		{Name: "core-rtr-01", Vendor: "Cisco", Interfaces: []forward.DeviceInterface{{Name: "Vlan100"}, {Name: "Gi0/1", IPAddress: "10.1.1.1"}}},
+		{Name: "core-rtr-02", Vendor: "Cisco", Interfaces: []forward.DeviceInterface{{Name: "Vlan100"}, {Name: "vlan.200"}, {Name: "Gi0/1"}}},
+		{Name: "edge-fw-01", Vendor: "Palo Alto"},
+	}
+	prefixes := []PrefixIndexEntry{
+		{Device: "core-rtr-01", Interface: "Gi0/1", IP: "10.1.1.1", Prefix: "10.1.1.0/24"},
+		{Device: "core-rtr-02", Interface: "Gi0/1", IP: "10.1.1.2", Prefix: "10.1.1.0/24"},
+	}
+
+	matches, contains := filterParameterSuggestions(parameterValueCandidates(parameterKindDevice, "", devices, nil), "CORE-")
+	if len(matches) != 2 || contains || matches[0].Value != "core-rtr-01" || matches[0].Detail != "Cisco" {
+		t.Errorf("Expected the core routers by prefix, got %+v", matches)
+	}
+	if matches, contains := filterParameterSuggestions(parameterValueCandidates(parameterKindDevice, "", devices, nil), "fw"); len(matches) != 1 || !contains {
+		t.Errorf("Expected a contains match when no value starts with the text, got %+v", matches)
+	}
+
+	vlans := parameterValueCandidates(parameterKindVLAN, "", devices, nil)
+	if len(vlans) != 2 || vlans[0].Value != "100" || vlans[0].Detail != "SVI on core-rtr-01, core-rtr-02" || vlans[1].Value != "200" {
+		t.Errorf("Expected VLANs from SVIs, got %+v", vlans)
+	}
+	if interfaces := parameterValueCandidates(parameterKindInterface, "", devices, nil); len(interfaces) != 3 || interfaces[0].Value != "Gi0/1" || interfaces[0].Detail != "on 2 devices" {
+		t.Errorf("Expected distinct interface names, got %+v", interfaces)
+	}
+	if interfaces := parameterValueCandidates(parameterKindInterface, "CORE-RTR-01", devices, nil); len(interfaces) != 2 || interfaces[0].Detail != "10.1.1.1" {
+		t.Errorf("Expected the interfaces of one device, got %+v", interfaces)
+	}
+	if prefixes := parameterValueCandidates(parameterKindPrefix, "", devices, prefixes); len(prefixes) != 1 || prefixes[0].Detail != "on core-rtr-01, core-rtr-02" {
+		t.Errorf("Expected one prefix on both routers, got %+v", prefixes)
+	}
+	if ips := parameterValueCandidates(parameterKindIP, "core-rtr-02", devices, prefixes); len(ips) != 1 || ips[0].Value != "10.1.1.2" {
+		t.Errorf("Expected the IPs of one device, got %+v", ips)
+	}
+}
+
+func TestSuggestParameterValues(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	service := createTestService()
+	database, err := NewNQEDatabase(service.logger, "test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+	service.database = database
+	if err := database.SaveQueries([]forward.NQEQueryDetail{{QueryID: "FQ_vlan_devices", Path: "/L2/VLAN/Devices in VLAN", SourceCode: testNQESource}}); err != nil {
+		t.Fatalf("Failed to save query: %v", err)
+	}
+	client := service.forwardClient.(*MockForwardClient)
+	client.devices = []forward.Device{
+		{Name: "access-sw-01", Interfaces: []forward.DeviceInterface{{Name: "Vlan10"}, {Name: "Vlan20"}}},
+		{Name: "core-rtr-01", Interfaces: []forward.DeviceInterface{{Name: "Vlan10"}}},
+	}
+
+	response, err := service.suggestParameterValues(SuggestParameterValuesArgs{NetworkID: "162112", QueryID: "FQ_vlan_devices"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"### vlanId: Number (vlan)", "| 10 | SVI on access-sw-01, core-rtr-01 |", "### deviceName: String (device)",
+		"| core-rtr-01 |", `parameters={"deviceName":"access-sw-01","vlanId":10}`} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "### site") {
+		t.Errorf("Expected parameters of unknown kind left out: %s", text)
+	}
+
+	response, err = service.suggestParameterValues(SuggestParameterValuesArgs{NetworkID: "162112", Parameter: "deviceName", Prefix: "core"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Showing 1 of 1 values") || strings.Contains(text, "access-sw-01") {
+		t.Errorf("Expected only the typed prefix: %s", text)
+	}
+
+	if _, err := service.suggestParameterValues(SuggestParameterValuesArgs{NetworkID: "162112", QueryID: "FQ_vlan_devices", Parameter: "color"}); err == nil || !strings.Contains(err.Error(), "vlanId, deviceName, site") {
+		t.Errorf("Expected an unknown parameter to list the query's parameters, got %v", err)
+	}
+	if _, err := service.suggestParameterValues(SuggestParameterValuesArgs{NetworkID: "162112", Parameter: "color"}); err == nil || !strings.Contains(err.Error(), "pass kind") {
+		t.Errorf("Expected an uninferable parameter to ask for the kind, got %v", err)
+	}
+}
@@ -0,0 +1,274 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// wellKnownPorts maps the service names agents give as ports to their numbers
+var wellKnownPorts = map[string]string{
+	"ftp": "21", "ssh": "22", "telnet": "23", "smtp": "25", "dns": "53", "domain": "53", "http": "80",
+	"ntp": "123", "snmp": "161", "bgp": "179", "ldap": "389", "https": "443", "syslog": "514",
+	"ldaps": "636", "mysql": "3306", "rdp": "3389", "postgres": "5432", "postgresql": "5432",
+}
+
+// UnmarshalJSON accepts numeric ports and named protocols (see coercePathQueryJSON)
+func (a *SearchPathsArgs) UnmarshalJSON(data []byte) error {
+	type plain SearchPathsArgs
+	coerced, err := coercePathQueryJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(coerced, (*plain)(a))
+}
+
+// UnmarshalJSON accepts numeric ports and named protocols (see coercePathQueryJSON)
+func (a *PathSearchQueryArgs) UnmarshalJSON(data []byte) error {
+	type plain PathSearchQueryArgs
+	coerced, err := coercePathQueryJSON(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(coerced, (*plain)(a))
+}
+
+// coercePathQueryJSON rewrites the port and protocol fields of a path query object so they
+// decode however an agent typed them: numeric ports become strings, and ip_proto given as a
+// name ("tcp"), a quoted number or a whole float becomes a protocol number
+func coercePathQueryJSON(data []byte) ([]byte, error) {
+	var fields map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&fields); err != nil || fields == nil {
+		// Not an object; regular decoding reports the problem
+		return data, nil
+	}
+
+	changed := false
+	for _, key := range []string{"src_port", "dst_port"} {
+		if number, ok := fields[key].(json.Number); ok {
+			fields[key] = number.String()
+			changed = true
+		}
+	}
+	switch proto := fields["ip_proto"].(type) {
+	case string:
+		if strings.TrimSpace(proto) == "" {
+			delete(fields, "ip_proto")
+		} else {
+			number, err := parseIPProtocol(proto)
+			if err != nil {
+				return nil, fmt.Errorf("ip_proto: %w", err)
+			}
+			fields["ip_proto"] = number
+		}
+		changed = true
+	case json.Number:
+		if _, err := proto.Int64(); err != nil {
+			if value, err := proto.Float64(); err == nil && value == math.Trunc(value) {
+				fields["ip_proto"] = int(value)
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+	return json.Marshal(fields)
+}
+
+// canonicalAddress moves a port off an address ("10.0.0.1:443", "[2001:db8::1]:443"), turns a
+// dotted netmask into a prefix length ("10.0.0.0 255.255.255.0") and a host prefix (/32 or
+// /128) into a plain address. The port is "" when the address had none.
+func canonicalAddress(value string) (string, string) {
+	value = strings.TrimSpace(value)
+	port := ""
+	if host, hostPort, err := net.SplitHostPort(value); err == nil && net.ParseIP(host) != nil && validatePortSpec(hostPort) == nil {
+		value, port = host, hostPort
+	}
+
+	base, mask, found := strings.Cut(value, "/")
+	if fields := strings.Fields(value); !found && len(fields) == 2 {
+		base, mask, found = fields[0], fields[1], true
+	}
+	if found && net.ParseIP(strings.TrimSpace(base)).To4() != nil {
+		if maskIP := net.ParseIP(strings.TrimSpace(mask)).To4(); maskIP != nil {
+			if ones, bits := net.IPMask(maskIP).Size(); bits != 0 {
+				value = fmt.Sprintf("%s/%d", strings.TrimSpace(base), ones)
+			}
+		}
+	}
+
+	if ip, ipNet, err := net.ParseCIDR(value); err == nil {
+		if ones, bits := ipNet.Mask.Size(); ones == bits {
+			value = ip.String()
+		}
+	}
+	return value, port
+}
+
+// canonicalPort turns a service name ("https"), a port with its protocol ("443/tcp" or
+// "tcp/443") or a padded range ("8000 - 8080") into a port spec. The protocol number is -1
+// when the value named none.
+func canonicalPort(value string) (string, int) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	proto := -1
+	if left, right, found := strings.Cut(value, "/"); found {
+		if number, ok := ipProtocolNumbers[strings.TrimSpace(right)]; ok {
+			value, proto = strings.TrimSpace(left), number
+		} else if number, ok := ipProtocolNumbers[strings.TrimSpace(left)]; ok {
+			value, proto = strings.TrimSpace(right), number
+		}
+	}
+	if number, ok := wellKnownPorts[value]; ok {
+		value = number
+	}
+	if low, high, found := strings.Cut(value, "-"); found {
+		value = strings.TrimSpace(low) + "-" + strings.TrimSpace(high)
+	}
+	return value, proto
+}
+
+// isIPOrCIDR reports whether a value is an address or prefix as path search accepts it
+func isIPOrCIDR(value string) bool {
+	_, _, err := normalizeIPOrCIDR(value)
+	return err == nil
+}
+
+// pathQueryCanonicalizer rewrites path search inputs into the forms validation expects,
+// loading the network's device inventory (from the device cache) only when a query names a
+// device
+type pathQueryCanonicalizer struct {
+	service    *ForwardMCPService
+	networkID  string
+	snapshotID string
+	devices    []forward.Device
+	loaded     bool
+}
+
+// inventory returns the network's devices, or none when they cannot be loaded
+func (c *pathQueryCanonicalizer) inventory() []forward.Device {
+	if !c.loaded {
+		c.loaded = true
+		devices, err := c.service.getNetworkDevices(c.networkID, c.snapshotID)
+		if err != nil {
+			c.service.logger.Debug("Path inputs keep their device names; inventory of network %s unavailable: %v", c.networkID, err)
+		}
+		c.devices = devices
+	}
+	return c.devices
+}
+
+// deviceName returns the inventory name of a device given with other casing, as a fully
+// qualified hostname or by a former name, or "" when no device matches
+func (c *pathQueryCanonicalizer) deviceName(name string) string {
+	devices := c.inventory()
+	if len(devices) == 0 || name == "" {
+		return ""
+	}
+	name = c.service.canonicalDeviceName(c.networkID, name, devices)
+	candidates := []string{name}
+	if short, _, found := strings.Cut(name, "."); found && short != "" && !isIPOrCIDR(name) {
+		candidates = append(candidates, short)
+	}
+	for _, candidate := range candidates {
+		for _, device := range devices {
+			if device.Name == candidate {
+				return device.Name
+			}
+		}
+		for _, device := range devices {
+			if strings.EqualFold(device.Name, candidate) || (device.Hostname != "" && strings.EqualFold(device.Hostname, candidate)) {
+				return device.Name
+			}
+		}
+	}
+	return ""
+}
+
+// canonicalize returns a query with its inputs in canonical form and a note for each value
+// that changed beyond surrounding whitespace
+func (c *pathQueryCanonicalizer) canonicalize(query PathSearchQueryArgs) (PathSearchQueryArgs, []string) {
+	original := query
+	query.From, query.FromTag = strings.TrimSpace(query.From), strings.TrimSpace(query.FromTag)
+
+	// Ports first, so a port moved off an address is not overwritten
+	for _, port := range []*string{&query.SrcPort, &query.DstPort} {
+		spec, proto := canonicalPort(*port)
+		*port = spec
+		if proto >= 0 && query.IPProto == nil {
+			query.IPProto = &proto
+		}
+	}
+	for _, address := range []struct{ ip, port *string }{{&query.SrcIP, &query.SrcPort}, {&query.DstIP, &query.DstPort}} {
+		value, port := canonicalAddress(*address.ip)
+		if port != "" && *address.port != "" && *address.port != port {
+			// Two different ports; leave the address for validation to report
+			*address.ip = strings.TrimSpace(*address.ip)
+			continue
+		}
+		*address.ip = value
+		if port != "" {
+			*address.port = port
+		}
+	}
+
+	// Device names: inventory casing, short names for FQDNs and current names for renamed devices
+	if query.From != "" {
+		if name := c.deviceName(query.From); name != "" {
+			query.From = name
+		}
+	}
+	if query.SrcIP != "" && !isIPOrCIDR(query.SrcIP) {
+		if name := c.deviceName(query.SrcIP); name != "" && (query.From == "" || query.From == name) {
+			query.From, query.SrcIP = name, ""
+		}
+	}
+	if query.DstIP != "" && !isIPOrCIDR(query.DstIP) {
+		if name := c.deviceName(query.DstIP); name != "" {
+			if ip, err := c.service.resolveDeviceToIP(c.networkID, name); err == nil {
+				query.DstIP = ip
+			}
+		}
+	}
+
+	var notes []string
+	for _, field := range []struct{ name, before, after string }{
+		{"from", original.From, query.From},
+		{"src_ip", original.SrcIP, query.SrcIP},
+		{"dst_ip", original.DstIP, query.DstIP},
+		{"src_port", original.SrcPort, query.SrcPort},
+		{"dst_port", original.DstPort, query.DstPort},
+	} {
+		if strings.TrimSpace(field.before) != field.after {
+			notes = append(notes, fmt.Sprintf("%s %q → %q", field.name, field.before, field.after))
+		}
+	}
+	if original.IPProto == nil && query.IPProto != nil {
+		notes = append(notes, "ip_proto set to "+strconv.Itoa(*query.IPProto))
+	}
+	return query, notes
+}
+
+// canonicalizePathQueries canonicalizes every query before validation and describes the
+// changes by query number
+func (s *ForwardMCPService) canonicalizePathQueries(networkID, snapshotID string, queries []PathSearchQueryArgs) ([]PathSearchQueryArgs, []string) {
+	canonicalizer := &pathQueryCanonicalizer{service: s, networkID: networkID, snapshotID: snapshotID}
+	canonical := make([]PathSearchQueryArgs, len(queries))
+	var changes []string
+	for i, query := range queries {
+		var notes []string
+		canonical[i], notes = canonicalizer.canonicalize(query)
+		if len(notes) > 0 {
+			changes = append(changes, fmt.Sprintf("query %d: %s", i+1, strings.Join(notes, ", ")))
+		}
+	}
+	return canonical, changes
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPathArgsCoerceTypes(t *testing.T) {
+	var args SearchPathsBulkArgs
+	if err := json.Unmarshal([]byte(`{"network_id":"162112","queries":[{"src_ip":"10.0.0.1","dst_ip":"10.0.1.1","ip_proto":"tcp","dst_port":443,"src_port":"1024"},{"src_ip":"10.0.0.1","dst_ip":"10.0.1.1","ip_proto":17.0}]}`), &args); err != nil {
+		t.Fatalf("Expected the bulk arguments to decode, got %v", err)
+	}
+	first, second := args.Queries[0], args.Queries[1]
+	if first.DstPort != "443" || first.SrcPort != "1024" || first.IPProto == nil || *first.IPProto != 6 {
+		t.Errorf("Expected port 443 over tcp, got %+v", first)
+	}
+	if second.IPProto == nil || *second.IPProto != 17 {
+		t.Errorf("Expected udp from a whole float, got %+v", second)
+	}
+
+	var single SearchPathsArgs
+	if err := json.Unmarshal([]byte(`{"network_id":"162112","dst_ip":"10.0.1.1","ip_proto":"6","dst_port":22,"analyze_ecmp":true}`), &single); err != nil {
+		t.Fatal(err)
+	}
+	if single.DstPort != "22" || *single.IPProto != 6 || !single.AnalyzeECMP {
+		t.Errorf("Unexpected single arguments: %+v", single)
+	}
+	if err := json.Unmarshal([]byte(`{"dst_ip":"10.0.1.1","ip_proto":"carrier-pigeon"}`), &single); err == nil || !strings.Contains(err.Error(), "ip_proto: unknown protocol") {
+		t.Errorf("Expected an unknown protocol error, got %v", err)
+	}
+}
+
+func TestCanonicalAddressAndPort(t *testing.T) {
+	for input, expected := range map[string][2]string{
+		" 10.0.0.1 ":              {"10.0.0.1", ""},
+		"10.0.0.1/32":             {"10.0.0.1", ""},
+		"10.0.0.0/24":             {"10.0.0.0/24", ""},
+		"10.0.0.0 255.255.255.0":  {"10.0.0.0/24", ""},
+		"10.0.0.0/255.255.0.0":    {"10.0.0.0/16", ""},
+		"10.0.0.1:8443":           {"10.0.0.1", "8443"},
+		"[2001:db8::1]:443":       {"2001:db8::1", "443"},
+		"2001:db8::1/128":         {"2001:db8::1", ""},
+		"web.example.com":         {"web.example.com", ""},
+		"10.0.0.1:notaport":       {"10.0.0.1:notaport", ""},
+		"2001:db8::/32":           {"2001:db8::/32", ""},
+		"192.168.1.0 255.0.255.0": {"192.168.1.0 255.0.255.0", ""},
+	} {
+		if address, port := canonicalAddress(input); address != expected[0] || port != expected[1] {
+			t.Errorf("canonicalAddress(%q) = %q, %q; expected %q, %q", input, address, port, expected[0], expected[1])
+		}
+	}
+
+	for input, expected := range map[string]struct {
+		spec  string
+		proto int
+	}{
+		"443": {"443", -1}, " HTTPS ": {"443", -1}, "443/tcp": {"443", 6}, "udp/53": {"53", 17},
+		"ssh/tcp": {"22", 6}, "8000 - 8080": {"8000-8080", -1}, "": {"", -1},
+	} {
+		if spec, proto := canonicalPort(input); spec != expected.spec || proto != expected.proto {
+			t.Errorf("canonicalPort(%q) = %q, %d; expected %q, %d", input, spec, proto, expected.spec, expected.proto)
+		}
+	}
+}
+
+func TestCanonicalizePathQueries(t *testing.T) {
+	service := createTestService()
+
+	queries, changes := service.canonicalizePathQueries("162112", "", []PathSearchQueryArgs{
+		{From: "ROUTER-1", DstIP: "10.0.1.1:443"},
+		{SrcIP: "sw1.example.com", DstIP: "Router-1", DstPort: "https/tcp"},
+		{From: "rtr1.example.com", DstIP: "10.0.1.0 255.255.255.0"},
+		{From: "unknown-device", SrcIP: " 10.0.0.1 ", DstIP: "10.0.1.1"},
+	})
+	if queries[0].From != "router-1" || queries[0].DstIP != "10.0.1.1" || queries[0].DstPort != "443" {
+		t.Errorf("Expected device casing and the port split off, got %+v", queries[0])
+	}
+	if queries[1].From != "switch-1" || queries[1].SrcIP != "" || queries[1].DstIP != "192.168.1.1" || queries[1].DstPort != "443" || *queries[1].IPProto != 6 {
+		t.Errorf("Expected hostnames expanded to the device and its address, got %+v", queries[1])
+	}
+	if queries[2].From != "router-1" || queries[2].DstIP != "10.0.1.0/24" {
+		t.Errorf("Expected the FQDN resolved and the mask converted, got %+v", queries[2])
+	}
+	if queries[3].From != "unknown-device" || queries[3].SrcIP != "10.0.0.1" {
+		t.Errorf("Expected unknown devices kept and whitespace trimmed, got %+v", queries[3])
+	}
+	if len(changes) != 3 || !strings.Contains(changes[0], `query 1: from "ROUTER-1" → "router-1", dst_ip "10.0.1.1:443" → "10.0.1.1", dst_port "" → "443"`) ||
+		!strings.Contains(changes[1], "ip_proto set to 6") {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+
+	// Searches report the normalized inputs and validate what was meant
+	response, err := service.searchPathsBulk(SearchPathsBulkArgs{NetworkID: "162112", Queries: []PathSearchQueryArgs{{From: "SWITCH-1", DstIP: "10.0.1.1/32"}}})
+	if err != nil {
+		t.Fatalf("Expected the canonicalized query to validate, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `Normalized inputs:`) || !strings.Contains(text, `from "SWITCH-1" → "switch-1"`) {
+		t.Errorf("Expected the normalized inputs reported: %s", text)
+	}
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const (
+	maxPathCSVRows         = 500
+	maxPathCSVBytes        = 1 << 20
+	csvImportDirectoryName = "imports"
+)
+
+// Column names accepted in path search CSV, after normalizeFactKey
+var (
+	pathCSVSourceKeys   = []string{"src", "source", "srcip", "sourceip"}
+	pathCSVFromKeys     = []string{"from", "fromdevice", "device", "srcdevice"}
+	pathCSVDestKeys     = []string{"dst", "destination", "dstip", "destinationip"}
+	pathCSVProtoKeys    = []string{"proto", "protocol", "ipproto"}
+	pathCSVPortKeys     = []string{"port", "dstport", "destinationport"}
+	pathCSVSrcPortKeys  = []string{"srcport", "sourceport"}
+	ipProtocolNumbers   = map[string]int{"icmp": 1, "tcp": 6, "udp": 17, "gre": 47, "esp": 50, "ah": 51, "icmpv6": 58, "sctp": 132}
+	pathCSVHeaderSample = "src,dst,proto,port"
+)
+
+// pathCSVQuery is a path search read from one CSV row
+type pathCSVQuery struct {
+	Row   int
+	Query PathSearchQueryArgs
+}
+
+// parseIPProtocol accepts a protocol number or a common name such as tcp
+func parseIPProtocol(value string) (int, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if number, ok := ipProtocolNumbers[value]; ok {
+		return number, nil
+	}
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 0 || number > 255 {
+		return 0, fmt.Errorf("unknown protocol (use tcp, udp, icmp or a number 0-255)")
+	}
+	return number, nil
+}
+
+// validatePortSpec accepts a port or a range such as 8000-8080
+func validatePortSpec(value string) error {
+	for _, part := range strings.SplitN(value, "-", 2) {
+		port, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || port < 0 || port > 65535 {
+			return fmt.Errorf("invalid port (use a number 0-65535 or a range such as 8000-8080)")
+		}
+	}
+	return nil
+}
+
+// parsePathSearchCSV reads flows with a header row naming src (address or device), dst and
+// optionally proto, port, from and src_port. Invalid rows are reported by line and skipped.
+func parsePathSearchCSV(data string) ([]pathCSVQuery, []string, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil, fmt.Errorf("csv is empty")
+	}
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeFactKey(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	column := func(keys []string) int {
+		for _, key := range keys {
+			if index, ok := columns[key]; ok {
+				return index
+			}
+		}
+		return -1
+	}
+	srcCol, fromCol, dstCol := column(pathCSVSourceKeys), column(pathCSVFromKeys), column(pathCSVDestKeys)
+	protoCol, portCol, srcPortCol := column(pathCSVProtoKeys), column(pathCSVPortKeys), column(pathCSVSrcPortKeys)
+	if dstCol < 0 || (srcCol < 0 && fromCol < 0) {
+		return nil, nil, fmt.Errorf("header must include src (or from) and dst columns, e.g. %s", pathCSVHeaderSample)
+	}
+	value := func(record []string, index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var queries []pathCSVQuery
+	var problems []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// Blank lines are skipped by the reader, so take the line from the record itself
+		line, _ := reader.FieldPos(0)
+		query, err := pathCSVRowQuery(value(record, srcCol), value(record, fromCol), value(record, dstCol),
+			value(record, protoCol), value(record, portCol), value(record, srcPortCol))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("row %d: %v", line, err))
+			continue
+		}
+		if len(queries) == maxPathCSVRows {
+			return nil, nil, fmt.Errorf("csv has more than %d flows; split it into several searches", maxPathCSVRows)
+		}
+		queries = append(queries, pathCSVQuery{Row: line, Query: query})
+	}
+	return queries, problems, nil
+}
+
+// pathCSVRowQuery validates one flow. A src that is not an address or prefix is taken as the
+// source device name.
+func pathCSVRowQuery(src, from, dst, proto, port, srcPort string) (PathSearchQueryArgs, error) {
+	query := PathSearchQueryArgs{From: from, SrcPort: srcPort, DstPort: port}
+	if dst == "" {
+		return query, fmt.Errorf("dst is empty")
+	}
+	normalizedDst, dstFamily, err := normalizeIPOrCIDR(dst)
+	if err != nil {
+		return query, fmt.Errorf("dst must be an IP address or CIDR")
+	}
+	query.DstIP = normalizedDst
+	if src != "" {
+		if normalizedSrc, srcFamily, err := normalizeIPOrCIDR(src); err == nil {
+			if srcFamily != dstFamily {
+				return query, fmt.Errorf("src (IPv%d) and dst (IPv%d) must use the same address family", srcFamily, dstFamily)
+			}
+			query.SrcIP = normalizedSrc
+		} else if query.From == "" {
+			query.From = src
+		} else {
+			return query, fmt.Errorf("src is not an IP address or CIDR")
+		}
+	}
+	if query.From == "" && query.SrcIP == "" {
+		return query, fmt.Errorf("src (or from) is empty")
+	}
+	if proto != "" {
+		number, err := parseIPProtocol(proto)
+		if err != nil {
+			return query, err
+		}
+		query.IPProto = &number
+	}
+	for _, spec := range []string{port, srcPort} {
+		if spec == "" {
+			continue
+		}
+		if err := validatePortSpec(spec); err != nil {
+			return query, err
+		}
+		if query.IPProto == nil {
+			return query, fmt.Errorf("a port needs a proto such as tcp or udp")
+		}
+	}
+	return query, nil
+}
+
+// csvImportDirectory is the only place csv_file may read from
+func csvImportDirectory() (string, error) {
+	dataDir, err := getWritableDataDirectory()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(dataDir, csvImportDirectoryName)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create import directory %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// readPathSearchCSV returns the CSV of a bulk search from its content or a file in the import
+// directory. Errors never include the file contents.
+func readPathSearchCSV(content, file string) (string, error) {
+	if content != "" && file != "" {
+		return "", fmt.Errorf("use either csv or csv_file, not both")
+	}
+	if file == "" {
+		return content, nil
+	}
+	dir, err := csvImportDirectory()
+	if err != nil {
+		return "", err
+	}
+	// Security: csv_file names a file inside the import directory; absolute paths, .. and
+	// symlinks are refused so callers cannot read other files on the server
+	name := filepath.Clean(file)
+	if !filepath.IsLocal(name) {
+		return "", fmt.Errorf("csv_file must be the name of a file in the import directory %s", dir)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open import directory %s: %w", dir, err)
+	}
+	defer root.Close()
+	info, err := root.Lstat(name)
+	if err != nil {
+		return "", fmt.Errorf("csv_file %s not found in the import directory %s", name, dir)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("csv_file %s must be a regular file, not a symlink or directory", name)
+	}
+	if info.Size() > maxPathCSVBytes {
+		return "", fmt.Errorf("csv_file %s is %s; at most %s is accepted", name, formatBytes(info.Size()), formatBytes(maxPathCSVBytes))
+	}
+	in, err := root.Open(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to open csv_file %s", name)
+	}
+	defer in.Close()
+	data, err := io.ReadAll(io.LimitReader(in, maxPathCSVBytes+1))
+	if err != nil {
+		return "", fmt.Errorf("failed to read csv_file %s", name)
+	}
+	if len(data) > maxPathCSVBytes {
+		return "", fmt.Errorf("csv_file %s is larger than %s", name, formatBytes(maxPathCSVBytes))
+	}
+	return string(data), nil
+}
+
+// formatPathCSVResults correlates the bulk search responses with the CSV rows they came from
+func formatPathCSVResults(rows []pathCSVQuery, problems []string, responses []forward.PathSearchBulkResponse) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("\n## Flows from CSV (%d searched", len(rows)))
+	if len(problems) > 0 {
+		text.WriteString(fmt.Sprintf(", %d rows skipped", len(problems)))
+	}
+	text.WriteString(")\n\n| Row | Source | Destination | Proto/Port | Paths | Status |\n|---|---|---|---|---|---|\n")
+	for i, row := range rows {
+		paths, status := 0, connectivityUnmeasured
+		if i < len(responses) {
+			paths, status = len(responses[i].Info.Paths), connectivityStatus(responses[i])
+		}
+		source := strings.TrimSpace(row.Query.From + " " + row.Query.SrcIP)
+		protoPort := "any"
+		if row.Query.IPProto != nil {
+			protoPort = strconv.Itoa(*row.Query.IPProto)
+			if row.Query.DstPort != "" {
+				protoPort += "/" + row.Query.DstPort
+			}
+		}
+		text.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %d | %s |\n", row.Row, source, row.Query.DstIP, protoPort, paths, status))
+	}
+	if len(problems) > 0 {
+		text.WriteString("\nSkipped rows:\n")
+		for _, problem := range problems {
+			text.WriteString("- " + problem + "\n")
+		}
+	}
+	return text.String()
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestParsePathSearchCSV(t *testing.T) {
+	data := "Source,Destination,Protocol,Port\n" +
+		"10.1.1.10,10.2.2.20,tcp,443\n" +
+		"\n" +
+		"core-1,10.2.2.0/24,udp,53\n" +
+		"10.1.1.10,db-1,tcp,5432\n" +
+		"10.1.1.10,2001:db8::1,,\n" +
+		"10.1.1.10,10.2.2.20,http,80\n" +
+		"10.1.1.10,10.2.2.20,,22\n" +
+		"10.1.1.10,10.2.2.20,tcp,8000-8080\n" +
+		"10.1.1.10,10.2.2.20,tcp,70000\n"
+	rows, problems, err := parsePathSearchCSV(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(rows) != 3 || rows[0].Row != 2 || rows[1].Row != 4 || rows[2].Row != 9 {
+		t.Fatalf("Expected rows 2, 4 and 9, got %+v", rows)
+	}
+	if query := rows[0].Query; query.SrcIP != "10.1.1.10" || query.From != "" || *query.IPProto != 6 || query.DstPort != "443" {
+		t.Errorf("Unexpected first query: %+v", query)
+	}
+	if query := rows[1].Query; query.From != "core-1" || query.SrcIP != "" || *query.IPProto != 17 {
+		t.Errorf("Expected a device source, got %+v", query)
+	}
+	expected := []string{"row 5: dst", "row 6: src", "row 7: unknown protocol", "row 8: a port needs a proto", "row 10: invalid port"}
+	if len(problems) != len(expected) {
+		t.Fatalf("Expected %d problems, got %v", len(expected), problems)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(problems[i], prefix) {
+			t.Errorf("Expected %q, got %q", prefix, problems[i])
+		}
+	}
+
+	if _, _, err := parsePathSearchCSV("src,proto\n10.1.1.1,tcp\n"); err == nil {
+		t.Error("Expected an error without a dst column")
+	}
+}
+
+func TestSearchPathsBulkCSV(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+
+	importDir, err := csvImportDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(importDir, "flows.csv")
+	if err := os.WriteFile(path, []byte("src,dst,proto,port\n10.1.1.10,10.2.2.20,tcp,443\n10.1.1.10,bad,tcp,443\ncore-1,10.3.3.3,icmp,\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	response, err := service.searchPathsBulk(SearchPathsBulkArgs{NetworkID: "162112", CSVFile: "flows.csv"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "2/2 queries successful") || !strings.Contains(text, "Flows from CSV (2 searched, 1 rows skipped)") ||
+		!strings.Contains(text, "| 2 | 10.1.1.10 | 10.2.2.20 | 6/443 |") || !strings.Contains(text, "| 4 | core-1 | 10.3.3.3 | 1 |") ||
+		!strings.Contains(text, "row 3: dst") {
+		t.Errorf("Expected results correlated with rows, got %s", text)
+	}
+
+	// Rows without paths are named by row in the warnings
+	service.forwardClient.(*MockForwardClient).pathResponse = &forward.PathSearchResponse{}
+	response, err = service.searchPathsBulk(SearchPathsBulkArgs{NetworkID: "162112", CSV: "src,dst\n\n10.1.1.10,10.2.2.20\n"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Row 3: No paths found") || !strings.Contains(text, "| NO_PATH |") {
+		t.Errorf("Expected the empty row reported by row number, got %s", text)
+	}
+
+	if _, err := service.searchPathsBulk(SearchPathsBulkArgs{NetworkID: "162112", CSV: "src,dst\n10.1.1.10,bad\n"}); err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("Expected an error listing the invalid rows, got %v", err)
+	}
+	if _, err := service.searchPathsBulk(SearchPathsBulkArgs{NetworkID: "162112", CSV: "src,dst\n10.1.1.10,10.2.2.20\n",
+		Queries: []PathSearchQueryArgs{{SrcIP: "10.1.1.10", DstIP: "10.2.2.20"}}}); err == nil {
+		t.Error("Expected an error combining queries and csv")
+	}
+}
+
+func TestReadPathSearchCSVConfined(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	importDir, err := csvImportDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := filepath.Join(t.TempDir(), "secret.txt")
+	os.WriteFile(secret, []byte("root:x:0:0:hunter2\n"), 0o600)
+	if err := os.Symlink(secret, filepath.Join(importDir, "link.csv")); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(importDir, "secret.csv"), []byte("root:x:0:0:hunter2\n"), 0o600)
+
+	for _, file := range []string{secret, "../secret.txt", "link.csv", "missing.csv"} {
+		if _, err := readPathSearchCSV("", file); err == nil || strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("Expected %s to be refused without its contents, got %v", file, err)
+		}
+	}
+	data, err := readPathSearchCSV("", "secret.csv")
+	if err != nil {
+		t.Fatalf("Expected a file in the import directory to be read, got %v", err)
+	}
+	if _, _, err := parsePathSearchCSV(data); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected a header error without the file contents, got %v", err)
+	}
+	if _, _, err := parseServiceEndpointCSV(data); err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Expected a header error without the file contents, got %v", err)
+	}
+	if _, problems, _ := parsePathSearchCSV("src,dst\nhunter2,hunter3\n"); len(problems) != 1 || strings.Contains(problems[0], "hunter") {
+		t.Errorf("Expected row problems without the row contents, got %v", problems)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// ecmpMinResults is the minimum number of candidates/results requested when ECMP
+// analysis is enabled, so equal-cost alternatives are not truncated by the API
+const ecmpMinResults = 16
+
+// ECMPHopFanout describes the equal-cost next hops observed from a single device
+type ECMPHopFanout struct {
+	Device   string   `json:"device"`
+	NextHops []string `json:"next_hops"`
+	FanOut   int      `json:"fan_out"`
+}
+
+// ECMPQueryReport summarizes ECMP coverage for a single path search query
+type ECMPQueryReport struct {
+	Query         int             `json:"query"`
+	PathsReturned int             `json:"paths_returned"`
+	DistinctPaths int             `json:"distinct_paths"`
+	MaxFanOut     int             `json:"max_fan_out"`
+	Hops          []ECMPHopFanout `json:"hops,omitempty"`
+	ChokePoints   []string        `json:"choke_points,omitempty"`
+	SinglePath    bool            `json:"single_path"`
+}
+
+// applyECMPRequestDefaults raises candidate and result limits so the API returns
+// enough equal-cost alternatives to analyze
+func applyECMPRequestDefaults(request *forward.PathSearchBulkRequest) {
+	if request.MaxCandidates < ecmpMinResults {
+		request.MaxCandidates = ecmpMinResults
+	}
+	if request.MaxResults < ecmpMinResults {
+		request.MaxResults = ecmpMinResults
+	}
+}
+
+// analyzeECMP groups the returned paths by distinct device sequence and reports the
+// next-hop fan-out at each device along the way
+func analyzeECMP(queryIndex int, response forward.PathSearchBulkResponse) ECMPQueryReport {
+	report := ECMPQueryReport{
+		Query:         queryIndex,
+		PathsReturned: len(response.Info.Paths),
+	}
+
+	seen := make(map[string]bool)
+	var distinct [][]string
+	for _, path := range response.Info.Paths {
+		devices := make([]string, 0, len(path.Hops))
+		for _, hop := range path.Hops {
+			devices = append(devices, hop.DeviceName)
+		}
+		key := strings.Join(devices, "\x00")
+		if len(devices) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		distinct = append(distinct, devices)
+	}
+	report.DistinctPaths = len(distinct)
+	report.SinglePath = report.DistinctPaths <= 1
+
+	// Collect next hops per device, preserving first-seen order for stable output
+	var order []string
+	nextHops := make(map[string]map[string]bool)
+	pathsThrough := make(map[string]int)
+	for _, devices := range distinct {
+		onPath := make(map[string]bool)
+		for i, device := range devices {
+			if _, exists := nextHops[device]; !exists {
+				nextHops[device] = make(map[string]bool)
+				order = append(order, device)
+			}
+			if i+1 < len(devices) {
+				nextHops[device][devices[i+1]] = true
+			}
+			onPath[device] = true
+		}
+		for device := range onPath {
+			pathsThrough[device]++
+		}
+	}
+
+	for _, device := range order {
+		hops := make([]string, 0, len(nextHops[device]))
+		for next := range nextHops[device] {
+			hops = append(hops, next)
+		}
+		sort.Strings(hops)
+		if len(hops) == 0 {
+			continue
+		}
+		report.Hops = append(report.Hops, ECMPHopFanout{Device: device, NextHops: hops, FanOut: len(hops)})
+		if len(hops) > report.MaxFanOut {
+			report.MaxFanOut = len(hops)
+		}
+	}
+
+	// Devices every distinct path traverses are choke points even when ECMP exists elsewhere
+	if report.DistinctPaths > 1 {
+		for _, device := range order {
+			if pathsThrough[device] == report.DistinctPaths {
+				report.ChokePoints = append(report.ChokePoints, device)
+			}
+		}
+	}
+
+	return report
+}
+
+// formatECMPReports renders ECMP reports as a short human-readable section
+func formatECMPReports(reports []ECMPQueryReport) string {
+	var sb strings.Builder
+	sb.WriteString("\nECMP Analysis:\n")
+	for _, report := range reports {
+		sb.WriteString(fmt.Sprintf("  - Query %d: %d distinct paths (from %d returned), max fan-out %d",
+			report.Query, report.DistinctPaths, report.PathsReturned, report.MaxFanOut))
+		if report.SinglePath {
+			sb.WriteString(" ⚠️  single path - potential SPOF")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(MarshalCompactJSONString(reports))
+	sb.WriteString("\n")
+	return sb.String()
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func bulkPath(devices ...string) forward.BulkPath {
+	path := forward.BulkPath{}
+	for _, device := range devices {
+		path.Hops = append(path.Hops, forward.BulkHop{DeviceName: device})
+	}
+	return path
+}
+
+func TestAnalyzeECMP(t *testing.T) {
+	t.Run("fan_out_and_choke_points", func(t *testing.T) {
+		response := forward.PathSearchBulkResponse{
+			Info: forward.PathSearchInfo{Paths: []forward.BulkPath{
+				bulkPath("edge-1", "spine-1", "leaf-9"),
+				bulkPath("edge-1", "spine-2", "leaf-9"),
+				bulkPath("edge-1", "spine-2", "leaf-9"), // duplicate path
+			}},
+		}
+
+		report := analyzeECMP(1, response)
+		if report.PathsReturned != 3 || report.DistinctPaths != 2 {
+			t.Fatalf("Expected 3 returned / 2 distinct paths, got %d / %d", report.PathsReturned, report.DistinctPaths)
+		}
+		if report.SinglePath {
+			t.Error("Expected multi-path flow not to be flagged as single path")
+		}
+		if report.MaxFanOut != 2 || report.Hops[0].Device != "edge-1" || report.Hops[0].FanOut != 2 {
+			t.Errorf("Expected edge-1 fan-out of 2, got %+v", report.Hops)
+		}
+		if len(report.ChokePoints) != 2 || report.ChokePoints[0] != "edge-1" || report.ChokePoints[1] != "leaf-9" {
+			t.Errorf("Expected edge-1 and leaf-9 as choke points, got %v", report.ChokePoints)
+		}
+	})
+
+	t.Run("single_path_flagged", func(t *testing.T) {
+		response := forward.PathSearchBulkResponse{
+			Info: forward.PathSearchInfo{Paths: []forward.BulkPath{bulkPath("a", "b")}},
+		}
+		report := analyzeECMP(1, response)
+		if !report.SinglePath || report.MaxFanOut != 1 {
+			t.Errorf("Expected single path with fan-out 1, got %+v", report)
+		}
+	})
+}
+
+func TestApplyECMPRequestDefaults(t *testing.T) {
+	request := &forward.PathSearchBulkRequest{MaxCandidates: 2, MaxResults: 50}
+	applyECMPRequestDefaults(request)
+	if request.MaxCandidates != ecmpMinResults || request.MaxResults != 50 {
+		t.Errorf("Unexpected limits after ECMP defaults: %+v", request)
+	}
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const (
+	segmentUnderlay = "underlay"
+	segmentOverlay  = "overlay"
+)
+
+// tunnelInterfacePattern matches interface names used for GRE, IPsec, VXLAN, SD-WAN and
+// wireless (CAPWAP) tunnels across common vendors, e.g. Tunnel100, st0.1, vti2, nve1
+var tunnelInterfacePattern = regexp.MustCompile(`(?i)^(tunnel|tun|tu|gre|ipip|ipsec|vti|st|vxlan|nve|vtep|lisp|wg|dmvpn)[-_./]?\d+|sdwan|sd-wan|capwap|vxlan|tunnel`)
+
+// PathSegment is a run of hops that is either native underlay forwarding or a tunnel
+type PathSegment struct {
+	Kind           string   `json:"kind"`
+	Devices        []string `json:"devices"`
+	Tunnel         string   `json:"tunnel,omitempty"`
+	Underlay       []string `json:"underlay,omitempty"`
+	UnderlaySource string   `json:"underlay_source,omitempty"`
+	UnderlayNote   string   `json:"underlay_note,omitempty"`
+}
+
+// OverlayPathReport describes the overlay/underlay segments of a single returned path
+type OverlayPathReport struct {
+	Query    int           `json:"query"`
+	Path     int           `json:"path"`
+	Tunnels  int           `json:"tunnels"`
+	Segments []PathSegment `json:"segments"`
+}
+
+// isTunnelInterface reports whether an interface name looks like a tunnel endpoint
+func isTunnelInterface(name string) bool {
+	return name != "" && tunnelInterfacePattern.MatchString(name)
+}
+
+// hopHasBehavior reports whether any hop behavior mentions one of the given markers
+func hopHasBehavior(hop forward.BulkHop, markers ...string) bool {
+	for _, behavior := range hop.Behaviors {
+		upper := strings.ToUpper(behavior)
+		for _, marker := range markers {
+			if strings.Contains(upper, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTunnelHead reports whether traffic enters a tunnel when leaving this hop
+func isTunnelHead(hop forward.BulkHop) bool {
+	return isTunnelInterface(hop.EgressInterface) || hopHasBehavior(hop, "ENCAP")
+}
+
+// isTunnelTail reports whether traffic leaves a tunnel when arriving at this hop
+func isTunnelTail(hop forward.BulkHop) bool {
+	return isTunnelInterface(hop.IngressInterface) || hopHasBehavior(hop, "DECAP")
+}
+
+// annotateOverlay splits a path into underlay and overlay segments. Hops between a
+// tunnel head and tail are the tunnel's visible underlay; when the head is directly
+// followed by the tail the tunnel is opaque and its underlay is unknown.
+func annotateOverlay(path forward.BulkPath) ([]PathSegment, int) {
+	var segments []PathSegment
+	current := PathSegment{Kind: segmentUnderlay}
+	tunnels := 0
+
+	for i := 0; i < len(path.Hops); i++ {
+		hop := path.Hops[i]
+		current.Devices = append(current.Devices, hop.DeviceName)
+		if !isTunnelHead(hop) || i+1 >= len(path.Hops) {
+			continue
+		}
+
+		// The tunnel ends at the next hop that decapsulates; without one, assume the next hop
+		tail := i + 1
+		for j := i + 1; j < len(path.Hops); j++ {
+			if isTunnelTail(path.Hops[j]) {
+				tail = j
+				break
+			}
+		}
+
+		segments = append(segments, current)
+		overlay := PathSegment{
+			Kind:    segmentOverlay,
+			Devices: []string{hop.DeviceName, path.Hops[tail].DeviceName},
+			Tunnel:  firstNonEmpty(hop.EgressInterface, path.Hops[tail].IngressInterface, "encapsulated"),
+		}
+		for _, transit := range path.Hops[i+1 : tail] {
+			overlay.Underlay = append(overlay.Underlay, transit.DeviceName)
+		}
+		if len(overlay.Underlay) > 0 {
+			overlay.UnderlaySource = "path"
+		}
+		segments = append(segments, overlay)
+		tunnels++
+
+		current = PathSegment{Kind: segmentUnderlay}
+		i = tail - 1
+	}
+	if len(current.Devices) > 0 {
+		segments = append(segments, current)
+	}
+	return segments, tunnels
+}
+
+// analyzeOverlay annotates every returned path that crosses at least one tunnel
+func analyzeOverlay(responses []forward.PathSearchBulkResponse) []OverlayPathReport {
+	var reports []OverlayPathReport
+	for i, response := range responses {
+		for j, path := range response.Info.Paths {
+			segments, tunnels := annotateOverlay(path)
+			if tunnels == 0 {
+				continue
+			}
+			reports = append(reports, OverlayPathReport{Query: i + 1, Path: j + 1, Tunnels: tunnels, Segments: segments})
+		}
+	}
+	return reports
+}
+
+// expandOverlayUnderlay traces the underlay of opaque tunnels with a follow-up path
+// search from each tunnel head towards the tail device's address
+func (s *ForwardMCPService) expandOverlayUnderlay(networkID, snapshotID string, reports []OverlayPathReport) {
+	type tunnelKey struct{ head, tail string }
+	var keys []tunnelKey
+	var queries []forward.PathSearchParams
+	index := make(map[tunnelKey]int)
+	notes := make(map[tunnelKey]string)
+
+	for _, report := range reports {
+		for _, segment := range report.Segments {
+			if segment.Kind != segmentOverlay || segment.UnderlaySource != "" {
+				continue
+			}
+			key := tunnelKey{segment.Devices[0], segment.Devices[1]}
+			if _, seen := index[key]; seen {
+				continue
+			}
+			if _, seen := notes[key]; seen {
+				continue
+			}
+			dstIP, err := s.resolveDeviceToIP(networkID, key.tail)
+			if err != nil {
+				notes[key] = fmt.Sprintf("tunnel tail address unavailable: %v", err)
+				continue
+			}
+			index[key] = len(queries)
+			keys = append(keys, key)
+			queries = append(queries, forward.PathSearchParams{From: key.head, DstIP: dstIP})
+		}
+	}
+
+	underlays := make(map[tunnelKey][]string)
+	if len(queries) > 0 {
+		responses, err := s.forwardClient.SearchPathsBulk(networkID, &forward.PathSearchBulkRequest{
+			Queries: queries,
+			Intent:  "PREFER_DELIVERED",
+		}, snapshotID)
+		for _, key := range keys {
+			i := index[key]
+			switch {
+			case err != nil:
+				notes[key] = fmt.Sprintf("underlay search failed: %v", err)
+			case i >= len(responses) || len(responses[i].Info.Paths) == 0:
+				notes[key] = "no underlay path found between tunnel endpoints"
+			default:
+				hops := responses[i].Info.Paths[0].Hops
+				// Only the transit devices between the endpoints belong to the underlay
+				for _, hop := range hops {
+					if hop.DeviceName != key.head && hop.DeviceName != key.tail {
+						underlays[key] = append(underlays[key], hop.DeviceName)
+					}
+				}
+				if len(underlays[key]) == 0 {
+					notes[key] = "tunnel endpoints are directly connected"
+				}
+			}
+		}
+	}
+
+	for r := range reports {
+		for g := range reports[r].Segments {
+			segment := &reports[r].Segments[g]
+			if segment.Kind != segmentOverlay || segment.UnderlaySource != "" {
+				continue
+			}
+			key := tunnelKey{segment.Devices[0], segment.Devices[1]}
+			if underlay, ok := underlays[key]; ok {
+				segment.Underlay = underlay
+				segment.UnderlaySource = "path_search"
+			}
+			segment.UnderlayNote = notes[key]
+		}
+	}
+}
+
+// formatOverlayReports renders overlay annotations as a short human-readable section
+func formatOverlayReports(reports []OverlayPathReport) string {
+	var sb strings.Builder
+	sb.WriteString("\nOverlay Analysis:\n")
+	for _, report := range reports {
+		parts := make([]string, 0, len(report.Segments))
+		for _, segment := range report.Segments {
+			if segment.Kind == segmentUnderlay {
+				parts = append(parts, strings.Join(segment.Devices, " → "))
+				continue
+			}
+			underlay := "underlay not visible"
+			if len(segment.Underlay) > 0 {
+				underlay = "via " + strings.Join(segment.Underlay, " → ")
+			}
+			parts = append(parts, fmt.Sprintf("⇒ [%s %s, %s] ⇒", segmentOverlay, segment.Tunnel, underlay))
+		}
+		sb.WriteString(fmt.Sprintf("  - Query %d path %d (%d tunnel(s)): %s\n",
+			report.Query, report.Path, report.Tunnels, strings.Join(parts, " ")))
+	}
+	sb.WriteString(MarshalCompactJSONString(reports))
+	sb.WriteString("\n")
+	return sb.String()
+}
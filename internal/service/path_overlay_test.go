@@ -0,0 +1,105 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// overlayClient returns an SD-WAN path with an opaque tunnel for user queries and an
+// underlay path for follow-up searches sourced from the tunnel head
+type overlayClient struct {
+	*MockForwardClient
+	requests []*forward.PathSearchBulkRequest
+}
+
+func (c *overlayClient) SearchPathsBulk(networkID string, request *forward.PathSearchBulkRequest, snapshotID string) ([]forward.PathSearchBulkResponse, error) {
+	c.requests = append(c.requests, request)
+	responses := make([]forward.PathSearchBulkResponse, len(request.Queries))
+	for i, query := range request.Queries {
+		hops := []forward.BulkHop{
+			{DeviceName: "branch-sw", EgressInterface: "Gi0/1"},
+			{DeviceName: "branch-edge", IngressInterface: "Gi0/0", EgressInterface: "Tunnel100"},
+			{DeviceName: "hub-edge", IngressInterface: "Tunnel100", EgressInterface: "Gi0/2"},
+			{DeviceName: "dc-core", IngressInterface: "eth1"},
+		}
+		if query.From == "branch-edge" {
+			hops = []forward.BulkHop{{DeviceName: "branch-edge"}, {DeviceName: "isp-pe1"}, {DeviceName: "isp-pe2"}, {DeviceName: "hub-edge"}}
+		}
+		responses[i] = forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{{ForwardingOutcome: "DELIVERED", Hops: hops}}}}
+	}
+	return responses, nil
+}
+
+func TestIsTunnelInterface(t *testing.T) {
+	for _, name := range []string{"Tunnel100", "tun0", "Tu1", "gre-1", "st0.1", "vti2", "nve1", "ipsec3", "Sdwan-system-intf", "capwap-ap1", "vxlan.42"} {
+		if !isTunnelInterface(name) {
+			t.Errorf("Expected %s to be detected as a tunnel", name)
+		}
+	}
+	for _, name := range []string{"", "GigabitEthernet0/1", "eth0", "Vlan10", "Stack1", "xe-0/0/0"} {
+		if isTunnelInterface(name) {
+			t.Errorf("Expected %s not to be detected as a tunnel", name)
+		}
+	}
+}
+
+func TestAnnotateOverlay(t *testing.T) {
+	// Visible underlay: the tunnel head is followed by transit hops before decapsulation
+	path := forward.BulkPath{Hops: []forward.BulkHop{
+		{DeviceName: "a"},
+		{DeviceName: "b", EgressInterface: "ge-0/0/0", Behaviors: []string{"GRE_ENCAP"}},
+		{DeviceName: "p1"},
+		{DeviceName: "c", Behaviors: []string{"GRE_DECAP"}},
+		{DeviceName: "d"},
+	}}
+	segments, tunnels := annotateOverlay(path)
+	if tunnels != 1 || len(segments) != 3 {
+		t.Fatalf("Expected one tunnel across three segments, got %d: %+v", tunnels, segments)
+	}
+	overlay := segments[1]
+	if overlay.Kind != segmentOverlay || strings.Join(overlay.Devices, ",") != "b,c" || strings.Join(overlay.Underlay, ",") != "p1" || overlay.UnderlaySource != "path" {
+		t.Errorf("Unexpected overlay segment: %+v", overlay)
+	}
+	if strings.Join(segments[0].Devices, ",") != "a,b" || strings.Join(segments[2].Devices, ",") != "c,d" {
+		t.Errorf("Unexpected underlay segments: %+v", segments)
+	}
+
+	if _, tunnels := annotateOverlay(forward.BulkPath{Hops: []forward.BulkHop{{DeviceName: "a", EgressInterface: "eth0"}, {DeviceName: "b"}}}); tunnels != 0 {
+		t.Errorf("Expected no tunnels on a native path, got %d", tunnels)
+	}
+}
+
+func TestSearchPathsOverlay(t *testing.T) {
+	service := createTestService()
+	client := &overlayClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	client.devices = []forward.Device{{Name: "hub-edge", ManagementIPs: []string{"10.255.0.2"}}}
+	service.forwardClient = client
+	service.deviceCache = nil
+	service.pathCache = nil
+
+	response, err := service.searchPathsEntry(SearchPathsArgs{NetworkID: "162112", From: "branch-sw", DstIP: "10.10.10.10"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "branch-sw → branch-edge ⇒ [overlay Tunnel100, underlay not visible] ⇒ hub-edge → dc-core") {
+		t.Errorf("Expected an annotated overlay segment, got: %s", text)
+	}
+	if len(client.requests) != 1 {
+		t.Errorf("Expected no follow-up search without expand_underlay, got %d requests", len(client.requests))
+	}
+
+	response, err = service.searchPathsEntry(SearchPathsArgs{NetworkID: "162112", From: "branch-sw", DstIP: "10.10.10.10", ExpandUnderlay: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !strings.Contains(text, "[overlay Tunnel100, via isp-pe1 → isp-pe2]") || !strings.Contains(text, `"underlay_source":"path_search"`) {
+		t.Errorf("Expected the expanded underlay, got: %s", text)
+	}
+	if last := client.requests[len(client.requests)-1]; len(last.Queries) != 1 || last.Queries[0].DstIP != "10.255.0.2" {
+		t.Errorf("Expected a follow-up search towards the tunnel tail, got %+v", last.Queries)
+	}
+}
@@ -0,0 +1,424 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+)
+
+const (
+	// maxProbeQueries bounds how many queries of one search are probed
+	maxProbeQueries  = 10
+	probeConcurrency = 4
+
+	defaultProbeCount   = 3
+	defaultProbeTimeout = 2 * time.Second
+)
+
+// Outcomes of comparing a modeled path with probes
+const (
+	probeMatch        = "match"
+	probeMismatch     = "mismatch"
+	probeDegraded     = "degraded"
+	probeInconclusive = "inconclusive"
+	probeSkipped      = "skipped"
+	probeError        = "error"
+)
+
+// ProbeRequest is one destination to probe
+type ProbeRequest struct {
+	Source         string `json:"source,omitempty"` // Modeled source; probe APIs may use it to pick an agent
+	Target         string `json:"target"`
+	Protocol       string `json:"protocol"` // icmp or tcp
+	Port           int    `json:"port,omitempty"`
+	Count          int    `json:"count"`
+	TimeoutSeconds int    `json:"timeout_seconds"`
+}
+
+// ProbeResult is what the probes of one destination observed
+type ProbeResult struct {
+	Agent    string   `json:"agent,omitempty"` // Where the probes ran
+	Sent     int      `json:"sent"`
+	Received int      `json:"received"`
+	RTTMinMs float64  `json:"rtt_min_ms,omitempty"`
+	RTTAvgMs float64  `json:"rtt_avg_ms,omitempty"`
+	RTTMaxMs float64  `json:"rtt_max_ms,omitempty"`
+	Hops     []string `json:"hops,omitempty"` // Traceroute hops, when the prober reports them
+}
+
+// LossPercent returns the share of probes that went unanswered
+func (r *ProbeResult) LossPercent() float64 {
+	if r.Sent == 0 {
+		return 100
+	}
+	return float64(r.Sent-r.Received) * 100 / float64(r.Sent)
+}
+
+// Prober sends real probes towards a destination
+type Prober interface {
+	Probe(ctx context.Context, request ProbeRequest) (*ProbeResult, error)
+	Name() string
+}
+
+// NewProber creates the prober configured for verify_with_probes, or nil when none is
+func NewProber(cfg config.ProbeConfig) Prober {
+	switch {
+	case cfg.URL != "":
+		return &apiProber{url: cfg.URL, client: &http.Client{Timeout: 60 * time.Second}}
+	case cfg.Host != "":
+		return &hostProber{host: cfg.Host, run: runProbeCommand}
+	}
+	return nil
+}
+
+// apiProber asks an external probe API to run the probes. The API receives a ProbeRequest
+// as JSON and answers with a ProbeResult.
+type apiProber struct {
+	url    string
+	client *http.Client
+}
+
+func (p *apiProber) Name() string {
+	return "probe API " + p.url
+}
+
+func (p *apiProber) Probe(ctx context.Context, request ProbeRequest) (*ProbeResult, error) {
+	body, _ := json.Marshal(request)
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create probe request: %w", err)
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+
+	response, err := p.client.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("probe API request failed: %w", err)
+	}
+	defer response.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("probe API returned %d: %s", response.StatusCode, strings.TrimSpace(string(data)))
+	}
+	var result ProbeResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid probe API response: %w", err)
+	}
+	return &result, nil
+}
+
+// hostProber probes from a host reached over SSH, or from the server itself when the host is
+// "local". ICMP probes use ping; TCP probes connect to the port.
+type hostProber struct {
+	host string
+	run  func(ctx context.Context, name string, args ...string) ([]byte, error)
+}
+
+func runProbeCommand(ctx context.Context, name string, args ...string) ([]byte, error) {
+	return exec.CommandContext(ctx, name, args...).CombinedOutput()
+}
+
+func (p *hostProber) local() bool {
+	return p.host == "local" || p.host == "localhost"
+}
+
+func (p *hostProber) Name() string {
+	if p.local() {
+		return "the server host"
+	}
+	return "probe host " + p.host
+}
+
+// command runs a command on the probe host
+func (p *hostProber) command(ctx context.Context, timeout time.Duration, args ...string) ([]byte, error) {
+	if p.local() {
+		return p.run(ctx, args[0], args[1:]...)
+	}
+	ssh := []string{"-o", "BatchMode=yes", "-o", fmt.Sprintf("ConnectTimeout=%d", int(timeout.Seconds())), p.host}
+	return p.run(ctx, "ssh", append(ssh, args...)...)
+}
+
+func (p *hostProber) Probe(ctx context.Context, request ProbeRequest) (*ProbeResult, error) {
+	timeout := time.Duration(request.TimeoutSeconds) * time.Second
+	if request.Protocol == "tcp" {
+		return p.probeTCP(ctx, request, timeout)
+	}
+
+	output, err := p.command(ctx, timeout, "ping", "-c", strconv.Itoa(request.Count), "-W", strconv.Itoa(request.TimeoutSeconds), request.Target)
+	// ping exits non-zero when replies are missing, so trust its summary over the exit status
+	result, parseErr := parsePingOutput(string(output))
+	if parseErr != nil {
+		if err != nil {
+			return nil, fmt.Errorf("ping from %s failed: %w: %s", p.Name(), err, strings.TrimSpace(string(output)))
+		}
+		return nil, parseErr
+	}
+	result.Agent = p.host
+	return result, nil
+}
+
+// probeTCP opens connections to the port. Connection times are measured on the server only;
+// over SSH each attempt is reported as answered or not.
+func (p *hostProber) probeTCP(ctx context.Context, request ProbeRequest, timeout time.Duration) (*ProbeResult, error) {
+	result := &ProbeResult{Agent: p.host}
+	address := net.JoinHostPort(request.Target, strconv.Itoa(request.Port))
+	var rtts []float64
+	for i := 0; i < request.Count; i++ {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		result.Sent++
+		if p.local() {
+			started := time.Now()
+			dialer := net.Dialer{Timeout: timeout}
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err != nil {
+				continue
+			}
+			conn.Close()
+			rtts = append(rtts, float64(time.Since(started).Microseconds())/1000)
+			result.Received++
+			continue
+		}
+		if _, err := p.command(ctx, timeout, "nc", "-z", "-w", strconv.Itoa(request.TimeoutSeconds), request.Target, strconv.Itoa(request.Port)); err == nil {
+			result.Received++
+		}
+	}
+	if len(rtts) > 0 {
+		result.RTTMinMs, result.RTTMaxMs = rtts[0], rtts[0]
+		total := 0.0
+		for _, rtt := range rtts {
+			result.RTTMinMs = min(result.RTTMinMs, rtt)
+			result.RTTMaxMs = max(result.RTTMaxMs, rtt)
+			total += rtt
+		}
+		result.RTTAvgMs = total / float64(len(rtts))
+	}
+	return result, nil
+}
+
+var (
+	pingSummaryPattern = regexp.MustCompile(`(\d+) packets transmitted, (\d+) (?:packets )?received`)
+	pingRTTPattern     = regexp.MustCompile(`= ([\d.]+)/([\d.]+)/([\d.]+)`)
+)
+
+// parsePingOutput reads the summary of Linux and BSD ping
+func parsePingOutput(output string) (*ProbeResult, error) {
+	summary := pingSummaryPattern.FindStringSubmatch(output)
+	if summary == nil {
+		return nil, fmt.Errorf("unrecognized ping output: %s", strings.TrimSpace(output))
+	}
+	result := &ProbeResult{}
+	result.Sent, _ = strconv.Atoi(summary[1])
+	result.Received, _ = strconv.Atoi(summary[2])
+	if rtt := pingRTTPattern.FindStringSubmatch(output); rtt != nil {
+		result.RTTMinMs, _ = strconv.ParseFloat(rtt[1], 64)
+		result.RTTAvgMs, _ = strconv.ParseFloat(rtt[2], 64)
+		result.RTTMaxMs, _ = strconv.ParseFloat(rtt[3], 64)
+	}
+	return result, nil
+}
+
+// ProbeVerification compares the modeled outcome of one query with what probes observed
+type ProbeVerification struct {
+	Query       int          `json:"query"`
+	Target      string       `json:"target"`
+	Protocol    string       `json:"protocol,omitempty"`
+	Port        int          `json:"port,omitempty"`
+	Modeled     string       `json:"modeled"` // delivered, not_delivered or unknown
+	ModeledHops int          `json:"modeled_hops,omitempty"`
+	Observed    *ProbeResult `json:"observed,omitempty"`
+	Status      string       `json:"status"`
+	Note        string       `json:"note,omitempty"`
+}
+
+// modeledOutcome summarizes the paths of one query: delivered when any path reaches the
+// destination without being denied
+func modeledOutcome(response forward.PathSearchBulkResponse) (string, int) {
+	for _, path := range response.Info.Paths {
+		if isDeliveredOutcome(path.ForwardingOutcome) && !isDeniedOutcome(path.SecurityOutcome) {
+			return "delivered", len(path.Hops)
+		}
+	}
+	if len(response.Info.Paths) == 0 && response.TimedOut {
+		return "unknown", 0
+	}
+	return "not_delivered", 0
+}
+
+// probeRequestFor derives the probe of a query. Probes need a single destination address and
+// either ICMP or a TCP port; anything else is skipped with the reason.
+func probeRequestFor(query PathSearchQueryArgs) (ProbeRequest, string) {
+	target, err := netip.ParseAddr(query.DstIP)
+	if err != nil {
+		prefix, prefixErr := netip.ParsePrefix(query.DstIP)
+		if prefixErr != nil || !prefix.IsSingleIP() {
+			return ProbeRequest{}, "destination is a subnet; probes need a host address"
+		}
+		target = prefix.Addr()
+	}
+	request := ProbeRequest{Source: firstNonEmpty(query.From, query.SrcIP), Target: target.String(), Protocol: "icmp"}
+	if query.IPProto == nil {
+		return request, ""
+	}
+	switch *query.IPProto {
+	case 1, 58:
+		return request, ""
+	case 6:
+		port, err := strconv.Atoi(query.DstPort)
+		if err != nil || port <= 0 || port > 65535 {
+			return ProbeRequest{}, "TCP probes need a single destination port"
+		}
+		request.Protocol, request.Port = "tcp", port
+		return request, ""
+	}
+	return ProbeRequest{}, fmt.Sprintf("IP protocol %d cannot be probed; only ICMP and TCP are supported", *query.IPProto)
+}
+
+// reconcileProbe compares a modeled outcome with the probe result
+func reconcileProbe(verification *ProbeVerification) {
+	observed := verification.Observed
+	reached := observed.Received > 0
+	switch {
+	case verification.Modeled == "unknown":
+		verification.Status = probeInconclusive
+		verification.Note = "the path search timed out"
+	case verification.Modeled == "delivered" && !reached:
+		verification.Status = probeMismatch
+		verification.Note = fmt.Sprintf("model predicts delivery but all %d probes were lost", observed.Sent)
+	case verification.Modeled != "delivered" && reached:
+		verification.Status = probeMismatch
+		verification.Note = fmt.Sprintf("%d of %d probes were answered but the model predicts the traffic is not delivered", observed.Received, observed.Sent)
+	case reached && observed.Received < observed.Sent:
+		verification.Status = probeDegraded
+		verification.Note = fmt.Sprintf("%.0f%% loss", observed.LossPercent())
+	default:
+		verification.Status = probeMatch
+	}
+	// Layer-2 hops and tunnels hide from traceroute, so a different count is only noted
+	if len(observed.Hops) > 0 && verification.ModeledHops > 0 && len(observed.Hops) != verification.ModeledHops {
+		note := fmt.Sprintf("traceroute saw %d hops, the model %d", len(observed.Hops), verification.ModeledHops)
+		verification.Note = strings.TrimPrefix(verification.Note+"; "+note, "; ")
+	}
+}
+
+// verifyWithProbes probes the destination of each query and compares the result with the
+// modeled outcome. Probes run concurrently and only for the first maxProbeQueries queries.
+func (s *ForwardMCPService) verifyWithProbes(queries []PathSearchQueryArgs, responses []forward.PathSearchBulkResponse) []ProbeVerification {
+	count, timeout := defaultProbeCount, defaultProbeTimeout
+	if s.config != nil {
+		if s.config.Forward.Probes.Count > 0 {
+			count = s.config.Forward.Probes.Count
+		}
+		if s.config.Forward.Probes.TimeoutSeconds > 0 {
+			timeout = time.Duration(s.config.Forward.Probes.TimeoutSeconds) * time.Second
+		}
+	}
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	verifications := make([]ProbeVerification, len(responses))
+	slots := make(chan struct{}, probeConcurrency)
+	var wg sync.WaitGroup
+	for i, response := range responses {
+		verification := &verifications[i]
+		verification.Query = i + 1
+		verification.Modeled, verification.ModeledHops = modeledOutcome(response)
+		if i >= len(queries) {
+			verification.Status, verification.Note = probeSkipped, "no matching query"
+			continue
+		}
+		verification.Target = queries[i].DstIP
+		if i >= maxProbeQueries {
+			verification.Status, verification.Note = probeSkipped, fmt.Sprintf("only the first %d queries are probed", maxProbeQueries)
+			continue
+		}
+		request, reason := probeRequestFor(queries[i])
+		if reason != "" {
+			verification.Status, verification.Note = probeSkipped, reason
+			continue
+		}
+		request.Count, request.TimeoutSeconds = count, int(timeout.Seconds())
+		verification.Target, verification.Protocol, verification.Port = request.Target, request.Protocol, request.Port
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			// Each probe waits at most timeout per packet, plus slack for SSH or the API
+			probeCtx, cancel := context.WithTimeout(ctx, time.Duration(count)*timeout+10*time.Second)
+			defer cancel()
+			observed, err := s.prober.Probe(probeCtx, request)
+			if err != nil {
+				verification.Status, verification.Note = probeError, err.Error()
+				return
+			}
+			verification.Observed = observed
+			reconcileProbe(verification)
+		}()
+	}
+	wg.Wait()
+	return verifications
+}
+
+// formatProbeVerifications renders the comparison of modeled paths and probes
+func formatProbeVerifications(prober string, verifications []ProbeVerification) string {
+	var sb strings.Builder
+	mismatches := 0
+	for _, verification := range verifications {
+		if verification.Status == probeMismatch {
+			mismatches++
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\nProbe Verification (probes from %s): %d mismatch(es) between model and probes\n", prober, mismatches))
+	for _, verification := range verifications {
+		target := verification.Target
+		if verification.Protocol != "" {
+			target += " " + verification.Protocol
+		}
+		if verification.Port > 0 {
+			target += fmt.Sprintf("/%d", verification.Port)
+		}
+		line := fmt.Sprintf("  - Query %d %s: modeled %s", verification.Query, target, verification.Modeled)
+		if observed := verification.Observed; observed != nil {
+			line += fmt.Sprintf(", observed %d/%d answered", observed.Received, observed.Sent)
+			if observed.RTTAvgMs > 0 {
+				line += fmt.Sprintf(", avg RTT %.1f ms", observed.RTTAvgMs)
+			}
+		}
+		status := verification.Status
+		switch status {
+		case probeMatch:
+			status = "✅ match"
+		case probeMismatch:
+			status = "❌ MISMATCH"
+		case probeDegraded:
+			status = "⚠️ degraded"
+		}
+		line += " — " + status
+		if verification.Note != "" {
+			line += ": " + verification.Note
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("  Probes run from the probe host, not from each query's source, so a mismatch can also mean the probe host's own path differs.\n")
+	sb.WriteString(MarshalCompactJSONString(verifications))
+	sb.WriteString("\n")
+	return sb.String()
+}
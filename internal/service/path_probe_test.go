@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeProber answers probes from a table of results per target
+type fakeProber struct {
+	results  map[string]*ProbeResult
+	requests []ProbeRequest
+	mutex    sync.Mutex
+}
+
+func (p *fakeProber) Name() string {
+	return "fake"
+}
+
+func (p *fakeProber) Probe(ctx context.Context, request ProbeRequest) (*ProbeResult, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.requests = append(p.requests, request)
+	if result, ok := p.results[request.Target]; ok {
+		return result, nil
+	}
+	return nil, fmt.Errorf("no route to probe %s", request.Target)
+}
+
+func TestParsePingOutput(t *testing.T) {
+	linux := "3 packets transmitted, 2 received, 33.3333% packet loss, time 2003ms\nrtt min/avg/max/mdev = 0.412/0.530/0.648/0.118 ms"
+	result, err := parsePingOutput(linux)
+	if err != nil || result.Sent != 3 || result.Received != 2 || result.RTTAvgMs != 0.53 || result.RTTMaxMs != 0.648 {
+		t.Errorf("Unexpected Linux result: %+v %v", result, err)
+	}
+
+	bsd := "3 packets transmitted, 3 packets received, 0.0% packet loss\nround-trip min/avg/max/stddev = 10.1/12.2/14.3/1.7 ms"
+	if result, err := parsePingOutput(bsd); err != nil || result.Received != 3 || result.RTTMinMs != 10.1 {
+		t.Errorf("Unexpected BSD result: %+v %v", result, err)
+	}
+
+	lost := "3 packets transmitted, 0 received, 100% packet loss, time 2050ms"
+	if result, err := parsePingOutput(lost); err != nil || result.Received != 0 || result.LossPercent() != 100 {
+		t.Errorf("Unexpected result for total loss: %+v %v", result, err)
+	}
+
+	if _, err := parsePingOutput("ping: unknown host"); err == nil {
+		t.Error("Expected unrecognized output to be rejected")
+	}
+}
+
+func TestProbeRequestFor(t *testing.T) {
+	tcp, udp := 6, 17
+	tests := []struct {
+		query    PathSearchQueryArgs
+		protocol string
+		reason   string
+	}{
+		{PathSearchQueryArgs{SrcIP: "10.0.0.1", DstIP: "10.1.1.1"}, "icmp", ""},
+		{PathSearchQueryArgs{SrcIP: "10.0.0.1", DstIP: "10.1.1.1/32"}, "icmp", ""},
+		{PathSearchQueryArgs{SrcIP: "10.0.0.1", DstIP: "10.1.1.1", IPProto: &tcp, DstPort: "443"}, "tcp", ""},
+		{PathSearchQueryArgs{SrcIP: "10.0.0.1", DstIP: "10.1.1.0/24"}, "", "subnet"},
+		{PathSearchQueryArgs{SrcIP: "10.0.0.1", DstIP: "10.1.1.1", IPProto: &tcp, DstPort: "1000-2000"}, "", "single destination port"},
+		{PathSearchQueryArgs{SrcIP: "10.0.0.1", DstIP: "10.1.1.1", IPProto: &udp, DstPort: "53"}, "", "only ICMP and TCP"},
+	}
+	for _, test := range tests {
+		request, reason := probeRequestFor(test.query)
+		if test.reason != "" {
+			if !strings.Contains(reason, test.reason) {
+				t.Errorf("%+v: expected the reason %q, got %q", test.query, test.reason, reason)
+			}
+			continue
+		}
+		if reason != "" || request.Protocol != test.protocol || request.Target != "10.1.1.1" || request.Source != "10.0.0.1" {
+			t.Errorf("%+v: unexpected request %+v (%s)", test.query, request, reason)
+		}
+	}
+}
+
+func TestReconcileProbe(t *testing.T) {
+	tests := []struct {
+		modeled  string
+		observed ProbeResult
+		status   string
+	}{
+		{"delivered", ProbeResult{Sent: 3, Received: 3}, probeMatch},
+		{"delivered", ProbeResult{Sent: 3, Received: 0}, probeMismatch},
+		{"delivered", ProbeResult{Sent: 4, Received: 3}, probeDegraded},
+		{"not_delivered", ProbeResult{Sent: 3, Received: 0}, probeMatch},
+		{"not_delivered", ProbeResult{Sent: 3, Received: 1}, probeMismatch},
+		{"unknown", ProbeResult{Sent: 3, Received: 3}, probeInconclusive},
+	}
+	for _, test := range tests {
+		verification := ProbeVerification{Modeled: test.modeled, Observed: &test.observed}
+		reconcileProbe(&verification)
+		if verification.Status != test.status {
+			t.Errorf("%s with %d/%d answered: expected %s, got %s (%s)", test.modeled, test.observed.Received, test.observed.Sent, test.status, verification.Status, verification.Note)
+		}
+	}
+
+	verification := ProbeVerification{Modeled: "delivered", ModeledHops: 2, Observed: &ProbeResult{Sent: 1, Received: 1, Hops: []string{"a", "b", "c"}}}
+	reconcileProbe(&verification)
+	if verification.Status != probeMatch || verification.Note != "traceroute saw 3 hops, the model 2" {
+		t.Errorf("Expected a hop count note, got %+v", verification)
+	}
+}
+
+func TestHostProberLocal(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("Cannot listen locally: %v", err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	var commands []string
+	prober := &hostProber{host: "local", run: func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		commands = append(commands, name+" "+strings.Join(args, " "))
+		return []byte("2 packets transmitted, 2 received, 0% packet loss\nrtt min/avg/max/mdev = 1.0/1.5/2.0/0.5 ms"), nil
+	}}
+
+	result, err := prober.Probe(context.Background(), ProbeRequest{Target: "127.0.0.1", Protocol: "tcp", Port: port, Count: 2, TimeoutSeconds: 1})
+	if err != nil || result.Sent != 2 || result.Received != 2 || result.RTTAvgMs <= 0 {
+		t.Errorf("Expected answered TCP probes, got %+v %v", result, err)
+	}
+	result, err = prober.Probe(context.Background(), ProbeRequest{Target: "10.1.1.1", Protocol: "icmp", Count: 2, TimeoutSeconds: 1})
+	if err != nil || result.Received != 2 || result.RTTAvgMs != 1.5 {
+		t.Errorf("Expected the parsed ping result, got %+v %v", result, err)
+	}
+	if len(commands) != 1 || commands[0] != "ping -c 2 -W 1 10.1.1.1" {
+		t.Errorf("Expected one local ping, got %v", commands)
+	}
+
+	// A remote host runs the same command over SSH
+	commands = nil
+	prober.host = "probe.example.com"
+	if _, err := prober.Probe(context.Background(), ProbeRequest{Target: "10.1.1.1", Protocol: "icmp", Count: 2, TimeoutSeconds: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(commands) != 1 || !strings.HasPrefix(commands[0], "ssh -o BatchMode=yes") || !strings.HasSuffix(commands[0], "probe.example.com ping -c 2 -W 1 10.1.1.1") {
+		t.Errorf("Expected ping over SSH, got %v", commands)
+	}
+}
+
+func TestSearchPathsVerifyWithProbes(t *testing.T) {
+	service := createTestService()
+	service.pathCache = nil
+
+	if _, err := service.searchPathsEntry(SearchPathsArgs{NetworkID: "162112", From: "router-1", DstIP: "10.1.1.1", VerifyWithProbes: true}); err == nil || !strings.Contains(err.Error(), "FORWARD_PROBE_HOST") {
+		t.Errorf("Expected an error without a prober, got %v", err)
+	}
+
+	// The mock models every query as delivered; the second destination does not answer
+	prober := &fakeProber{results: map[string]*ProbeResult{
+		"10.1.1.1": {Sent: 3, Received: 3, RTTAvgMs: 4.2},
+		"10.2.2.2": {Sent: 3, Received: 0},
+	}}
+	service.prober = prober
+	response, err := service.searchPathsBulk(SearchPathsBulkArgs{
+		NetworkID:        "162112",
+		VerifyWithProbes: true,
+		Queries: []PathSearchQueryArgs{
+			{From: "router-1", DstIP: "10.1.1.1"},
+			{From: "router-1", DstIP: "10.2.2.2"},
+			{From: "router-1", DstIP: "10.3.0.0/16"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{
+		"1 mismatch(es) between model and probes",
+		"Query 1 10.1.1.1 icmp: modeled delivered, observed 3/3 answered, avg RTT 4.2 ms — ✅ match",
+		"Query 2 10.2.2.2 icmp: modeled delivered, observed 0/3 answered — ❌ MISMATCH: model predicts delivery but all 3 probes were lost",
+		"Query 3 10.3.0.0/16: modeled delivered — skipped: destination is a subnet",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if len(prober.requests) != 2 || prober.requests[0].Count != defaultProbeCount {
+		t.Errorf("Expected two probes with the default count, got %+v", prober.requests)
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const (
+	// defaultPathCacheMaxEntries bounds the number of cached bulk path search results
+	defaultPathCacheMaxEntries = 500
+	// defaultPathCacheLatestTTL applies when no snapshot is pinned, since the
+	// "latest" snapshot moves as soon as the network is re-processed
+	defaultPathCacheLatestTTL = 5 * time.Minute
+)
+
+// PathSearchCacheEntry holds a cached bulk path search result
+type PathSearchCacheEntry struct {
+	Key        string
+	NetworkID  string
+	SnapshotID string
+	Responses  []forward.PathSearchBulkResponse
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	HitCount   int64
+}
+
+// PathSearchCache is an exact-match cache for bulk path search results keyed by
+// the normalized request and snapshot. Results for a pinned snapshot never change,
+// so they live for pinnedTTL; results against the latest snapshot expire quickly.
+type PathSearchCache struct {
+	entries    map[string]*PathSearchCacheEntry
+	mutex      sync.Mutex
+	maxEntries int
+	pinnedTTL  time.Duration
+	latestTTL  time.Duration
+	hits       int64
+	misses     int64
+	now        func() time.Time
+}
+
+// NewPathSearchCache creates a new exact-match path search cache
+func NewPathSearchCache(maxEntries int, pinnedTTL, latestTTL time.Duration) *PathSearchCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultPathCacheMaxEntries
+	}
+	if pinnedTTL <= 0 {
+		pinnedTTL = 24 * time.Hour
+	}
+	if latestTTL <= 0 {
+		latestTTL = defaultPathCacheLatestTTL
+	}
+	return &PathSearchCache{
+		entries:    make(map[string]*PathSearchCacheEntry),
+		maxEntries: maxEntries,
+		pinnedTTL:  pinnedTTL,
+		latestTTL:  latestTTL,
+		now:        time.Now,
+	}
+}
+
+// PathSearchCacheKey builds a stable key from the network, snapshot and bulk request.
+// Addresses are canonicalized so that equivalent spellings share an entry.
+func PathSearchCacheKey(networkID, snapshotID string, request *forward.PathSearchBulkRequest) string {
+	normalized := *request
+	normalized.Queries = make([]forward.PathSearchParams, len(request.Queries))
+	for i, query := range request.Queries {
+		query.From = strings.TrimSpace(query.From)
+		query.SrcIP = canonicalPathAddress(query.SrcIP)
+		query.DstIP = canonicalPathAddress(query.DstIP)
+		query.SrcPort = strings.TrimSpace(query.SrcPort)
+		query.DstPort = strings.TrimSpace(query.DstPort)
+		normalized.Queries[i] = query
+	}
+	normalized.Intent = strings.ToUpper(strings.TrimSpace(normalized.Intent))
+
+	if snapshotID == "" {
+		snapshotID = "latest"
+	}
+
+	payload, _ := json.Marshal(normalized)
+	hasher := sha256.New()
+	hasher.Write([]byte(fmt.Sprintf("%s|%s|%s", networkID, snapshotID, payload)))
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// canonicalPathAddress normalizes an IP address or CIDR, leaving other values trimmed
+func canonicalPathAddress(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return value
+	}
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String()
+	}
+	if _, ipNet, err := net.ParseCIDR(value); err == nil {
+		return ipNet.String()
+	}
+	return value
+}
+
+// Get returns the cached entry for key, recording a hit or miss
+func (pc *PathSearchCache) Get(key string) (*PathSearchCacheEntry, bool) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	entry, exists := pc.entries[key]
+	if exists && pc.now().After(entry.ExpiresAt) {
+		delete(pc.entries, key)
+		exists = false
+	}
+	if !exists {
+		pc.misses++
+		return nil, false
+	}
+
+	pc.hits++
+	entry.HitCount++
+	return entry, true
+}
+
+// Put stores bulk path search responses under key
+func (pc *PathSearchCache) Put(key, networkID, snapshotID string, responses []forward.PathSearchBulkResponse) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	ttl := pc.pinnedTTL
+	if snapshotID == "" || snapshotID == "latest" {
+		ttl = pc.latestTTL
+	}
+
+	if _, exists := pc.entries[key]; !exists && len(pc.entries) >= pc.maxEntries {
+		pc.evictOldest()
+	}
+
+	now := pc.now()
+	pc.entries[key] = &PathSearchCacheEntry{
+		Key:        key,
+		NetworkID:  networkID,
+		SnapshotID: snapshotID,
+		Responses:  responses,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+}
+
+// evictOldest removes the oldest entry (assumes mutex is already locked)
+func (pc *PathSearchCache) evictOldest() {
+	var oldestKey string
+	var oldestTime time.Time
+	for key, entry := range pc.entries {
+		if oldestKey == "" || entry.CreatedAt.Before(oldestTime) {
+			oldestKey = key
+			oldestTime = entry.CreatedAt
+		}
+	}
+	if oldestKey != "" {
+		delete(pc.entries, oldestKey)
+	}
+}
+
+// Clear removes all entries and returns how many were removed
+func (pc *PathSearchCache) Clear() int {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	removed := len(pc.entries)
+	pc.entries = make(map[string]*PathSearchCacheEntry)
+	return removed
+}
+
+// ClearExpired removes expired entries and returns how many were removed
+func (pc *PathSearchCache) ClearExpired() int {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	removed := 0
+	now := pc.now()
+	for key, entry := range pc.entries {
+		if now.After(entry.ExpiresAt) {
+			delete(pc.entries, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// GetStats returns path search cache statistics
+func (pc *PathSearchCache) GetStats() map[string]interface{} {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	total := pc.hits + pc.misses
+	hitRate := float64(0)
+	if total > 0 {
+		hitRate = float64(pc.hits) / float64(total) * 100
+	}
+
+	return map[string]interface{}{
+		"total_entries":      len(pc.entries),
+		"max_entries":        pc.maxEntries,
+		"cache_hits":         pc.hits,
+		"cache_misses":       pc.misses,
+		"hit_rate_percent":   fmt.Sprintf("%.2f", hitRate),
+		"pinned_ttl_hours":   pc.pinnedTTL.Hours(),
+		"latest_ttl_minutes": pc.latestTTL.Minutes(),
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestPathSearchCacheKeyNormalization(t *testing.T) {
+	a := &forward.PathSearchBulkRequest{
+		Queries: []forward.PathSearchParams{{From: " router-1 ", SrcIP: "10.0.0.1", DstIP: "10.1.2.3/16"}},
+		Intent:  "prefer_delivered",
+	}
+	b := &forward.PathSearchBulkRequest{
+		Queries: []forward.PathSearchParams{{From: "router-1", SrcIP: " 10.0.0.1", DstIP: "10.1.0.0/16"}},
+		Intent:  "PREFER_DELIVERED",
+	}
+
+	if PathSearchCacheKey("162112", "", a) != PathSearchCacheKey("162112", "latest", b) {
+		t.Error("Expected equivalent requests to share a cache key")
+	}
+	if PathSearchCacheKey("162112", "snap-1", a) == PathSearchCacheKey("162112", "snap-2", a) {
+		t.Error("Expected different snapshots to produce different cache keys")
+	}
+}
+
+func TestPathSearchCacheTTL(t *testing.T) {
+	cache := NewPathSearchCache(10, time.Hour, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	responses := []forward.PathSearchBulkResponse{{DstIpLocationType: "INTERNET"}}
+	cache.Put("pinned", "162112", "snap-1", responses)
+	cache.Put("latest", "162112", "", responses)
+
+	now = now.Add(2 * time.Minute)
+
+	if _, found := cache.Get("latest"); found {
+		t.Error("Expected latest-snapshot entry to expire after the short TTL")
+	}
+	entry, found := cache.Get("pinned")
+	if !found {
+		t.Fatal("Expected pinned-snapshot entry to still be cached")
+	}
+	if len(entry.Responses) != 1 {
+		t.Errorf("Expected 1 cached response, got %d", len(entry.Responses))
+	}
+
+	stats := cache.GetStats()
+	if stats["cache_hits"].(int64) != 1 || stats["cache_misses"].(int64) != 1 {
+		t.Errorf("Unexpected hit/miss counts: %v/%v", stats["cache_hits"], stats["cache_misses"])
+	}
+}
+
+func TestSearchPathsBulkUsesCache(t *testing.T) {
+	service := createTestService()
+
+	args := SearchPathsBulkArgs{
+		NetworkID: "162112",
+		Queries:   []PathSearchQueryArgs{{SrcIP: "10.0.0.1", DstIP: "10.0.0.100"}},
+	}
+
+	first, err := service.searchPathsBulk(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !contains(first.Content[0].TextContent.Text, "Path cache: MISS") {
+		t.Error("Expected first search to report a cache miss")
+	}
+
+	second, err := service.searchPathsBulk(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !contains(second.Content[0].TextContent.Text, "Path cache: HIT") {
+		t.Error("Expected repeated search to report a cache hit")
+	}
+}
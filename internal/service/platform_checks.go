@@ -0,0 +1,373 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	defaultCheckHistory = 5  // Snapshots get_check_results shows for one check
+	maxCheckHistory     = 20 // Snapshots get_check_results reads at most
+)
+
+// checkStatusOrder lists failing statuses first so problems lead every listing
+var checkStatusOrder = map[string]int{"FAIL": 0, "ERROR": 1, "TIMEOUT": 2, "PROCESSING": 3, "PASS": 5}
+
+// checkPriorityOrder lists the most important checks first within a status
+var checkPriorityOrder = map[string]int{"HIGH": 0, "MEDIUM": 1, "LOW": 2, "NOT_SET": 3}
+
+func checkStatusRank(status string) int {
+	if rank, ok := checkStatusOrder[strings.ToUpper(status)]; ok {
+		return rank
+	}
+	return 4
+}
+
+func checkPriorityRank(priority string) int {
+	if rank, ok := checkPriorityOrder[strings.ToUpper(priority)]; ok {
+		return rank
+	}
+	return 3
+}
+
+// sortChecks orders checks by status, then priority, then name
+func sortChecks(checks []forward.NetworkCheck) {
+	sort.SliceStable(checks, func(i, j int) bool {
+		a, b := checks[i], checks[j]
+		if checkStatusRank(a.Status) != checkStatusRank(b.Status) {
+			return checkStatusRank(a.Status) < checkStatusRank(b.Status)
+		}
+		if checkPriorityRank(a.Priority) != checkPriorityRank(b.Priority) {
+			return checkPriorityRank(a.Priority) < checkPriorityRank(b.Priority)
+		}
+		return checkName(a) < checkName(b)
+	})
+}
+
+// checkName returns a check's name, or its ID when it has none
+func checkName(check forward.NetworkCheck) string {
+	return firstNonEmpty(check.Name, check.ID)
+}
+
+// checkType returns what kind of check it is, e.g. Isolation or NQE
+func checkType(check forward.NetworkCheck) string {
+	if check.Definition == nil {
+		return ""
+	}
+	return firstNonEmpty(check.Definition.PredefinedCheckType, check.Definition.CheckType)
+}
+
+// checkStatusIcon marks a check status in tables
+func checkStatusIcon(status string) string {
+	switch strings.ToUpper(status) {
+	case "PASS":
+		return "✅ PASS"
+	case "FAIL":
+		return "❌ FAIL"
+	case "ERROR", "TIMEOUT":
+		return "⚠️ " + strings.ToUpper(status)
+	case "":
+		return "not run"
+	default:
+		return strings.ToUpper(status)
+	}
+}
+
+// checkStatusCounts tallies checks by status
+func checkStatusCounts(checks []forward.NetworkCheck) map[string]int {
+	counts := make(map[string]int)
+	for _, check := range checks {
+		counts[strings.ToUpper(firstNonEmpty(check.Status, "NONE"))]++
+	}
+	return counts
+}
+
+// formatCheckCounts renders status counts, failing statuses first
+func formatCheckCounts(counts map[string]int) string {
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if checkStatusRank(statuses[i]) != checkStatusRank(statuses[j]) {
+			return checkStatusRank(statuses[i]) < checkStatusRank(statuses[j])
+		}
+		return statuses[i] < statuses[j]
+	})
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = fmt.Sprintf("%d %s", counts[status], status)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// checkSnapshot resolves the snapshot whose checks to read, the latest processed one by default
+func (s *ForwardMCPService) checkSnapshot(networkID, snapshotID string) (string, error) {
+	if snapshotID = s.getSnapshotID(snapshotID); snapshotID != "" {
+		return snapshotID, nil
+	}
+	if networkID == "" {
+		return "", newCodedError(CodeNetworkIDRequired)
+	}
+	return s.latestProcessedSnapshot(networkID)
+}
+
+// checkHistorySnapshots returns up to count processed snapshots of a network, starting with
+// snapshotID and going back in time
+func (s *ForwardMCPService) checkHistorySnapshots(networkID, snapshotID string, count int) []forward.Snapshot {
+	current := []forward.Snapshot{{ID: snapshotID}}
+	if count <= 1 || networkID == "" {
+		return current
+	}
+	snapshots, err := s.forwardClient.GetSnapshots(networkID)
+	if err != nil {
+		s.logger.Warn("Failed to list snapshots of network %s for check history: %v", networkID, err)
+		return current
+	}
+	processed := processedSnapshotsNewestFirst(snapshots)
+	for i, snapshot := range processed {
+		if snapshot.ID == snapshotID {
+			end := i + count
+			if end > len(processed) {
+				end = len(processed)
+			}
+			return processed[i:end]
+		}
+	}
+	return current
+}
+
+// listPlatformChecks lists the verification checks configured in Forward with their result on a snapshot
+func (s *ForwardMCPService) listPlatformChecks(args ListPlatformChecksArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_platform_checks", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	snapshotID, err := s.checkSnapshot(networkID, args.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	checks, err := s.forwardClient.GetChecks(snapshotID, &forward.CheckQueryParams{
+		Type:     args.Type,
+		Priority: strings.ToUpper(args.Priority),
+		Status:   strings.ToUpper(args.Status),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list checks of snapshot %s: %w", snapshotID, err)
+	}
+	sortChecks(checks)
+
+	switch strings.ToLower(args.Format) {
+	case "json":
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(map[string]interface{}{
+			"network_id": networkID, "snapshot_id": snapshotID, "checks": checks,
+		}))), nil
+	case "", "markdown":
+	default:
+		return nil, fmt.Errorf("unsupported format %q: use markdown or json", args.Format)
+	}
+
+	if len(checks) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+			"No verification checks match on snapshot %s. Checks are defined in Forward Enterprise under Verify.", snapshotID))), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Platform Checks: snapshot %s\n\n", snapshotID)
+	fmt.Fprintf(&b, "%d checks: %s\n\n", len(checks), formatCheckCounts(checkStatusCounts(checks)))
+	b.WriteString("| Check | ID | Type | Priority | Status | Violations | Enabled |\n|---|---|---|---|---|---|---|\n")
+	for _, check := range checks {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %d | %t |\n", checkName(check), check.ID, checkType(check),
+			check.Priority, checkStatusIcon(check.Status), check.NumViolations, check.Enabled)
+	}
+	b.WriteString("\nUse get_check_results with check_id for the diagnosis of a check and its result on earlier snapshots.")
+	return mcp.NewToolResponse(mcp.NewTextContent(b.String())), nil
+}
+
+// getCheckResults shows one check's diagnosis and history, or the check results of recent
+// snapshots with the checks that started or stopped failing
+func (s *ForwardMCPService) getCheckResults(args GetCheckResultsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_check_results", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	snapshotID, err := s.checkSnapshot(networkID, args.SnapshotID)
+	if err != nil {
+		return nil, err
+	}
+	format := strings.ToLower(args.Format)
+	if format != "" && format != "markdown" && format != "json" {
+		return nil, fmt.Errorf("unsupported format %q: use markdown or json", args.Format)
+	}
+	count := args.Snapshots
+	if count <= 0 {
+		count = 1
+		if args.CheckID != "" {
+			count = defaultCheckHistory
+		}
+	}
+	if count > maxCheckHistory {
+		count = maxCheckHistory
+	}
+	history := s.checkHistorySnapshots(networkID, snapshotID, count)
+
+	if args.CheckID != "" {
+		return s.checkResultHistory(args.CheckID, history, format)
+	}
+
+	results := make([][]forward.NetworkCheck, len(history))
+	for i, snapshot := range history {
+		checks, err := s.forwardClient.GetChecks(snapshot.ID, nil)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("failed to read checks of snapshot %s: %w", snapshot.ID, err)
+			}
+			s.logger.Warn("Failed to read checks of snapshot %s: %v", snapshot.ID, err)
+			history, results = history[:i], results[:i]
+			break
+		}
+		sortChecks(checks)
+		results[i] = checks
+	}
+	var newlyFailing, newlyPassing []forward.NetworkCheck
+	if len(results) > 1 {
+		newlyFailing, newlyPassing = checkStatusChanges(results[1], results[0])
+	}
+
+	if format == "json" {
+		snapshots := make([]map[string]interface{}, len(history))
+		for i, snapshot := range history {
+			snapshots[i] = map[string]interface{}{"snapshot_id": snapshot.ID, "counts": checkStatusCounts(results[i])}
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(map[string]interface{}{
+			"network_id": networkID, "snapshot_id": snapshotID, "snapshots": snapshots,
+			"failing": failingChecks(results[0]), "newly_failing": newlyFailing, "newly_passing": newlyPassing,
+		}))), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Check Results: snapshot %s\n\n", snapshotID)
+	if len(history) > 1 {
+		b.WriteString("| Snapshot | Created | Checks | Results |\n|---|---|---|---|\n")
+		for i, snapshot := range history {
+			fmt.Fprintf(&b, "| %s | %s | %d | %s |\n", snapshot.ID, s.timeFormatter.FormatEpoch(snapshot.CreationDateMillis),
+				len(results[i]), formatCheckCounts(checkStatusCounts(results[i])))
+		}
+		b.WriteString("\n")
+		writeCheckList(&b, fmt.Sprintf("Newly failing since snapshot %s", history[1].ID), newlyFailing)
+		writeCheckList(&b, fmt.Sprintf("Newly passing since snapshot %s", history[1].ID), newlyPassing)
+	} else {
+		fmt.Fprintf(&b, "%d checks: %s\n\n", len(results[0]), formatCheckCounts(checkStatusCounts(results[0])))
+	}
+	failing := failingChecks(results[0])
+	if len(failing) == 0 {
+		b.WriteString("All checks that ran on this snapshot passed.\n")
+	} else {
+		writeCheckList(&b, "Failing checks", failing)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(strings.TrimRight(b.String(), "\n"))), nil
+}
+
+// checkResultHistory renders one check's result on each snapshot with the newest diagnosis
+func (s *ForwardMCPService) checkResultHistory(checkID string, history []forward.Snapshot, format string) (*mcp.ToolResponse, error) {
+	latest, err := s.forwardClient.GetCheck(history[0].ID, checkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read check %s on snapshot %s: %w", checkID, history[0].ID, err)
+	}
+	type snapshotResult struct {
+		SnapshotID    string `json:"snapshot_id"`
+		Status        string `json:"status"`
+		NumViolations int    `json:"num_violations"`
+		Created       int64  `json:"creation_date_millis,omitempty"`
+	}
+	results := []snapshotResult{{history[0].ID, latest.Status, latest.NumViolations, history[0].CreationDateMillis}}
+	for _, snapshot := range history[1:] {
+		result := snapshotResult{SnapshotID: snapshot.ID, Status: "ABSENT", Created: snapshot.CreationDateMillis}
+		if check, err := s.forwardClient.GetCheck(snapshot.ID, checkID); err == nil {
+			result.Status, result.NumViolations = check.Status, check.NumViolations
+		}
+		results = append(results, result)
+	}
+
+	if format == "json" {
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(map[string]interface{}{
+			"check": latest, "history": results,
+		}))), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Check %s: %s\n\n", checkName(*latest), checkStatusIcon(latest.Status))
+	fmt.Fprintf(&b, "- **ID:** %s\n- **Type:** %s\n- **Priority:** %s\n- **Violations:** %d\n", latest.ID,
+		firstNonEmpty(checkType(*latest), "unknown"), firstNonEmpty(latest.Priority, "NOT_SET"), latest.NumViolations)
+	if latest.Description != "" {
+		fmt.Fprintf(&b, "- **Description:** %s\n", latest.Description)
+	}
+	if latest.Definition != nil && latest.Definition.QueryID != "" {
+		fmt.Fprintf(&b, "- **NQE query:** %s\n", latest.Definition.QueryID)
+	}
+	if latest.ExecutionDateMillis > 0 {
+		fmt.Fprintf(&b, "- **Executed:** %s\n", s.timeFormatter.FormatEpoch(latest.ExecutionDateMillis))
+	}
+	if latest.Diagnosis != nil {
+		if latest.Diagnosis.Summary != "" {
+			fmt.Fprintf(&b, "\n## Diagnosis\n\n%s\n", latest.Diagnosis.Summary)
+		}
+		if latest.Diagnosis.Details != nil {
+			fmt.Fprintf(&b, "\n```json\n%s\n```\n", MarshalCompactJSONString(latest.Diagnosis.Details))
+		}
+	}
+	if len(results) > 1 {
+		b.WriteString("\n## History\n\n| Snapshot | Created | Status | Violations |\n|---|---|---|---|\n")
+		for _, result := range results {
+			fmt.Fprintf(&b, "| %s | %s | %s | %d |\n", result.SnapshotID, s.timeFormatter.FormatEpoch(result.Created),
+				checkStatusIcon(result.Status), result.NumViolations)
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(strings.TrimRight(b.String(), "\n"))), nil
+}
+
+// failingChecks returns the checks that failed, errored or timed out
+func failingChecks(checks []forward.NetworkCheck) []forward.NetworkCheck {
+	var failing []forward.NetworkCheck
+	for _, check := range checks {
+		if checkStatusRank(check.Status) < checkStatusRank("PROCESSING") {
+			failing = append(failing, check)
+		}
+	}
+	return failing
+}
+
+// checkStatusChanges compares two snapshots' results by check ID
+func checkStatusChanges(previous, current []forward.NetworkCheck) (newlyFailing, newlyPassing []forward.NetworkCheck) {
+	before := make(map[string]string, len(previous))
+	for _, check := range previous {
+		before[check.ID] = strings.ToUpper(check.Status)
+	}
+	for _, check := range current {
+		status, existed := before[check.ID]
+		switch strings.ToUpper(check.Status) {
+		case "FAIL", "ERROR", "TIMEOUT":
+			if !existed || status == "PASS" {
+				newlyFailing = append(newlyFailing, check)
+			}
+		case "PASS":
+			if status == "FAIL" || status == "ERROR" || status == "TIMEOUT" {
+				newlyPassing = append(newlyPassing, check)
+			}
+		}
+	}
+	return newlyFailing, newlyPassing
+}
+
+// writeCheckList renders a titled table of checks, nothing when there are none
+func writeCheckList(b *strings.Builder, title string, checks []forward.NetworkCheck) {
+	if len(checks) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "## %s (%d)\n\n| Check | ID | Type | Priority | Status | Violations |\n|---|---|---|---|---|---|\n", title, len(checks))
+	for _, check := range checks {
+		fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %d |\n", checkName(check), check.ID, checkType(check),
+			check.Priority, checkStatusIcon(check.Status), check.NumViolations)
+	}
+	b.WriteString("\n")
+}
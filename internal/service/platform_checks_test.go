@@ -0,0 +1,96 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func platformChecksTestService() *ForwardMCPService {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	client.snapshots = []forward.Snapshot{
+		{ID: "snap-3", State: "PROCESSED", CreationDateMillis: 3000},
+		{ID: "snap-2", State: "PROCESSED", CreationDateMillis: 2000},
+		{ID: "snap-1", State: "PROCESSED", CreationDateMillis: 1000},
+	}
+	isolation := &forward.CheckDefinition{CheckType: "Isolation"}
+	client.checks = map[string][]forward.NetworkCheck{
+		"snap-3": {
+			{ID: "C-1", Name: "PCI isolation", Definition: isolation, Enabled: true, Priority: "HIGH", Status: "FAIL", NumViolations: 3,
+				Diagnosis: &forward.CheckDiagnosis{Summary: "3 paths reach the PCI zone"}},
+			{ID: "C-2", Name: "DNS reachable", Definition: &forward.CheckDefinition{CheckType: "Reachability"}, Enabled: true, Priority: "LOW", Status: "PASS"},
+			{ID: "C-3", Name: "No default VLAN", Definition: &forward.CheckDefinition{CheckType: "NQE", QueryID: "FQ_vlan"}, Enabled: true, Priority: "MEDIUM", Status: "PASS"},
+		},
+		"snap-2": {
+			{ID: "C-1", Name: "PCI isolation", Definition: isolation, Priority: "HIGH", Status: "PASS"},
+			{ID: "C-2", Name: "DNS reachable", Priority: "LOW", Status: "PASS"},
+			{ID: "C-3", Name: "No default VLAN", Priority: "MEDIUM", Status: "FAIL", NumViolations: 12},
+		},
+		"snap-1": {
+			{ID: "C-2", Name: "DNS reachable", Priority: "LOW", Status: "PASS"},
+		},
+	}
+	return service
+}
+
+func TestListPlatformChecks(t *testing.T) {
+	service := platformChecksTestService()
+
+	response, err := service.listPlatformChecks(ListPlatformChecksArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Platform Checks: snapshot snap-3", "3 checks: 1 FAIL, 2 PASS",
+		"| PCI isolation | C-1 | Isolation | HIGH | ❌ FAIL | 3 | true |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	// Passing checks are ordered by priority
+	if strings.Index(text, "No default VLAN") > strings.Index(text, "DNS reachable") {
+		t.Errorf("Expected the medium priority check before the low one: %s", text)
+	}
+
+	response, _ = service.listPlatformChecks(ListPlatformChecksArgs{NetworkID: "162112", SnapshotID: "snap-2", Status: "fail", Format: "json"})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `"snapshot_id":"snap-2"`) || !strings.Contains(text, `"id":"C-3"`) || strings.Contains(text, `"id":"C-1"`) {
+		t.Errorf("Expected only the failing check of snap-2, got: %s", text)
+	}
+	if _, err := service.listPlatformChecks(ListPlatformChecksArgs{NetworkID: "162112", Format: "csv"}); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
+
+func TestGetCheckResults(t *testing.T) {
+	service := platformChecksTestService()
+
+	response, err := service.getCheckResults(GetCheckResultsArgs{NetworkID: "162112", Snapshots: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Check Results: snapshot snap-3", "| snap-2 |", "## Newly failing since snapshot snap-2 (1)",
+		"## Newly passing since snapshot snap-2 (1)", "| No default VLAN | C-3 | NQE | MEDIUM | ✅ PASS | 0 |", "## Failing checks (1)"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	response, err = service.getCheckResults(GetCheckResultsArgs{NetworkID: "162112", CheckID: "C-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Check PCI isolation: ❌ FAIL", "- **Type:** Isolation", "3 paths reach the PCI zone",
+		"| snap-3 |", "| snap-2 |", "✅ PASS | 0 |", "| snap-1 |", "ABSENT"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	if _, err := service.getCheckResults(GetCheckResultsArgs{NetworkID: "162112", CheckID: "C-404"}); err == nil {
+		t.Error("Expected an unknown check to fail")
+	}
+}
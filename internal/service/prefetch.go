@@ -0,0 +1,293 @@
+package service
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// Data the prefetcher warms for a network
+const (
+	prefetchDevices         = "devices"
+	prefetchLatestSnapshot  = "latest_snapshot"
+	prefetchDeviceLocations = "device_locations"
+	prefetchLocations       = "locations"
+)
+
+const (
+	// prefetchTTL bounds how long a prefetched snapshot or location map is served; it only
+	// exists to answer the next call, so it is short and used once
+	prefetchTTL              = 30 * time.Second
+	prefetchConcurrency      = 2
+	defaultPrefetchPerMinute = 20
+)
+
+// prefetchFollowUps lists the data warmed after each tool, from the calls agents usually
+// make next. Device inventories go to the device cache.
+var prefetchFollowUps = map[string][]string{
+	"list_devices":          {prefetchDeviceLocations, prefetchLatestSnapshot, prefetchDevices},
+	"set_default_network":   {prefetchLatestSnapshot, prefetchDevices, prefetchDeviceLocations},
+	"list_snapshots":        {prefetchLatestSnapshot},
+	"get_device_locations":  {prefetchLocations, prefetchDevices},
+	"list_locations":        {prefetchDeviceLocations},
+	"get_device_basic_info": {prefetchDeviceLocations},
+}
+
+// prefetchEntry is a value fetched ahead of the call that needs it
+type prefetchEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// Prefetcher fetches likely follow-up data in the background after certain tool calls. It
+// never runs more than prefetchConcurrency batches at once or makes more than perMinute API
+// calls in a minute; work over either limit is dropped rather than queued.
+type Prefetcher struct {
+	entries   map[string]*prefetchEntry
+	inFlight  map[string]bool
+	calls     []time.Time // Background API calls in the last minute
+	perMinute int
+	slots     chan struct{}
+	mutex     sync.Mutex
+	wg        sync.WaitGroup
+	now       func() time.Time
+
+	scheduled int64
+	fetched   int64
+	hits      int64
+	limited   int64
+	failed    int64
+}
+
+// NewPrefetcher creates a prefetcher allowed perMinute background API calls
+func NewPrefetcher(perMinute int) *Prefetcher {
+	if perMinute <= 0 {
+		perMinute = defaultPrefetchPerMinute
+	}
+	return &Prefetcher{
+		entries:   make(map[string]*prefetchEntry),
+		inFlight:  make(map[string]bool),
+		perMinute: perMinute,
+		slots:     make(chan struct{}, prefetchConcurrency),
+		now:       time.Now,
+	}
+}
+
+func prefetchKey(kind, networkID string) string {
+	return kind + "|" + networkID
+}
+
+// reserve claims one API call from the budget for key. It fails when the value is already
+// prefetched or being fetched, or when the budget of the last minute is spent.
+func (p *Prefetcher) reserve(key string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	if entry, ok := p.entries[key]; ok && now.Before(entry.expiresAt) {
+		return false
+	}
+	if p.inFlight[key] {
+		return false
+	}
+	recent := p.calls[:0]
+	for _, call := range p.calls {
+		if now.Sub(call) < time.Minute {
+			recent = append(recent, call)
+		}
+	}
+	p.calls = recent
+	if len(p.calls) >= p.perMinute {
+		p.limited++
+		return false
+	}
+	p.calls = append(p.calls, now)
+	p.inFlight[key] = true
+	return true
+}
+
+// complete stores a fetched value; a nil value only releases the key
+func (p *Prefetcher) complete(key string, value interface{}, err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.inFlight, key)
+	if err != nil {
+		p.failed++
+		return
+	}
+	p.fetched++
+	if value != nil {
+		p.entries[key] = &prefetchEntry{value: value, expiresAt: p.now().Add(prefetchTTL)}
+	}
+}
+
+// Take returns a prefetched value and removes it, so it is served at most once
+func (p *Prefetcher) Take(kind, networkID string) (interface{}, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := prefetchKey(kind, networkID)
+	entry, ok := p.entries[key]
+	if !ok {
+		return nil, false
+	}
+	delete(p.entries, key)
+	if p.now().After(entry.expiresAt) {
+		return nil, false
+	}
+	p.hits++
+	return entry.value, true
+}
+
+// Invalidate drops the prefetched values of a network, or of all networks when networkID
+// is empty
+func (p *Prefetcher) Invalidate(networkID string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for key := range p.entries {
+		if networkID == "" || strings.HasSuffix(key, "|"+networkID) {
+			delete(p.entries, key)
+		}
+	}
+}
+
+// Wait blocks until scheduled prefetches have finished
+func (p *Prefetcher) Wait() {
+	p.wg.Wait()
+}
+
+// GetStats returns prefetch statistics
+func (p *Prefetcher) GetStats() map[string]interface{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return map[string]interface{}{
+		"scheduled":    p.scheduled,
+		"fetched":      p.fetched,
+		"served":       p.hits,
+		"rate_limited": p.limited,
+		"failed":       p.failed,
+		"pending":      len(p.entries),
+		"per_minute":   p.perMinute,
+	}
+}
+
+// schedulePrefetch warms the follow-up data of a tool call in the background
+func (s *ForwardMCPService) schedulePrefetch(toolName, networkID string) {
+	kinds := prefetchFollowUps[toolName]
+	p := s.prefetcher
+	if p == nil || len(kinds) == 0 || networkID == "" {
+		return
+	}
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.mutex.Lock()
+		p.limited++
+		p.mutex.Unlock()
+		return
+	}
+
+	p.mutex.Lock()
+	p.scheduled++
+	p.mutex.Unlock()
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() { <-p.slots }()
+		for _, kind := range kinds {
+			if s.ctx != nil && s.ctx.Err() != nil {
+				return
+			}
+			s.prefetch(kind, networkID)
+		}
+	}()
+}
+
+// prefetch fetches one kind of data for a network unless it is already cached
+func (s *ForwardMCPService) prefetch(kind, networkID string) {
+	if kind == prefetchDevices && (s.deviceCache == nil || s.deviceCache.Contains(networkID, "")) {
+		return
+	}
+	key := prefetchKey(kind, networkID)
+	if !s.prefetcher.reserve(key) {
+		return
+	}
+
+	var value interface{}
+	var err error
+	switch kind {
+	case prefetchDevices:
+		_, err = s.getNetworkDevices(networkID, "")
+	case prefetchLatestSnapshot:
+		var snapshot *forward.Snapshot
+		if snapshot, err = s.forwardClient.GetLatestSnapshot(networkID); snapshot != nil {
+			value = snapshot
+		}
+	case prefetchDeviceLocations:
+		value, err = s.forwardClient.GetDeviceLocations(networkID)
+	case prefetchLocations:
+		value, err = s.forwardClient.GetLocations(networkID)
+	}
+	if err != nil {
+		s.logger.Debug("Prefetch of %s for network %s failed: %v", kind, networkID, err)
+	}
+	s.prefetcher.complete(key, value, err)
+}
+
+// prefetchToolHandler returns a handler with the same signature that schedules the follow-up
+// prefetches of successful calls and drops prefetched data of networks a write tool changed
+func (s *ForwardMCPService) prefetchToolHandler(toolName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if s.prefetcher == nil || (prefetchFollowUps[toolName] == nil && !writeTools[toolName]) ||
+		handlerType.Kind() != reflect.Func || handlerType.NumIn() != 1 || handlerType.NumOut() != 2 {
+		return handler
+	}
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		results := value.Call(args)
+		networkID, _ := s.toolNetworkID(args[0].Interface())
+		if writeTools[toolName] {
+			s.prefetcher.Invalidate(networkID)
+		} else if err, _ := results[1].Interface().(error); err == nil {
+			s.schedulePrefetch(toolName, networkID)
+		}
+		return results
+	}).Interface()
+}
+
+// latestSnapshot returns the latest snapshot of a network, prefetched when available
+func (s *ForwardMCPService) latestSnapshot(networkID string) (*forward.Snapshot, error) {
+	if s.prefetcher != nil {
+		if value, ok := s.prefetcher.Take(prefetchLatestSnapshot, networkID); ok {
+			return value.(*forward.Snapshot), nil
+		}
+	}
+	return s.forwardClient.GetLatestSnapshot(networkID)
+}
+
+// deviceLocationMap returns the device to location assignments of a network, prefetched when
+// available
+func (s *ForwardMCPService) deviceLocationMap(networkID string) (map[string]string, error) {
+	if s.prefetcher != nil {
+		if value, ok := s.prefetcher.Take(prefetchDeviceLocations, networkID); ok {
+			return value.(map[string]string), nil
+		}
+	}
+	return s.forwardClient.GetDeviceLocations(networkID)
+}
+
+// networkLocations returns the locations of a network, prefetched when available
+func (s *ForwardMCPService) networkLocations(networkID string) ([]forward.Location, error) {
+	if s.prefetcher != nil {
+		if value, ok := s.prefetcher.Take(prefetchLocations, networkID); ok {
+			return value.([]forward.Location), nil
+		}
+	}
+	return s.forwardClient.GetLocations(networkID)
+}
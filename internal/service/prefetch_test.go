@@ -0,0 +1,129 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// prefetchClient counts the API calls the prefetcher is expected to save
+type prefetchClient struct {
+	*MockForwardClient
+	snapshots       int
+	deviceLocations int
+	devices         int
+}
+
+func (c *prefetchClient) GetLatestSnapshot(networkID string) (*forward.Snapshot, error) {
+	c.snapshots++
+	return c.MockForwardClient.GetLatestSnapshot(networkID)
+}
+
+func (c *prefetchClient) GetDeviceLocations(networkID string) (map[string]string, error) {
+	c.deviceLocations++
+	return c.MockForwardClient.GetDeviceLocations(networkID)
+}
+
+func (c *prefetchClient) GetDevices(networkID string, params *forward.DeviceQueryParams) (*forward.DeviceResponse, error) {
+	c.devices++
+	return c.MockForwardClient.GetDevices(networkID, params)
+}
+
+func TestPrefetcherBudgetAndTake(t *testing.T) {
+	prefetcher := NewPrefetcher(2)
+	now := time.Now()
+	prefetcher.now = func() time.Time { return now }
+
+	if !prefetcher.reserve("a|1") || !prefetcher.reserve("b|1") {
+		t.Fatal("Expected the first two calls within the budget")
+	}
+	if prefetcher.reserve("c|1") {
+		t.Error("Expected the third call in a minute to be rate limited")
+	}
+	prefetcher.complete("a|1", "value", nil)
+	if prefetcher.reserve("a|1") {
+		t.Error("Expected a prefetched value not to be fetched again")
+	}
+
+	now = now.Add(61 * time.Second)
+	if !prefetcher.reserve("c|1") {
+		t.Error("Expected the budget to recover after a minute")
+	}
+
+	prefetcher.complete("b|1", "value", nil)
+	if value, ok := prefetcher.Take("b", "1"); !ok || value != "value" {
+		t.Fatalf("Expected the prefetched value, got %v %v", value, ok)
+	}
+	if _, ok := prefetcher.Take("b", "1"); ok {
+		t.Error("Expected a prefetched value to be served once")
+	}
+	prefetcher.complete("c|1", "value", nil)
+	now = now.Add(prefetchTTL + time.Second)
+	if _, ok := prefetcher.Take("c", "1"); ok {
+		t.Error("Expected an expired value not to be served")
+	}
+
+	stats := prefetcher.GetStats()
+	if stats["rate_limited"] != int64(1) || stats["served"] != int64(1) {
+		t.Errorf("Unexpected stats: %v", stats)
+	}
+}
+
+func TestPrefetchAfterListDevices(t *testing.T) {
+	service := createTestService()
+	client := &prefetchClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	service.forwardClient = client
+	service.deviceCache = NewDeviceCache(0, 0)
+	service.prefetcher = NewPrefetcher(10)
+
+	listDevices := service.prefetchToolHandler("list_devices", service.listDevices).(func(ListDevicesArgs) (*mcp.ToolResponse, error))
+	if _, err := listDevices(ListDevicesArgs{NetworkID: "162112"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	service.prefetcher.Wait()
+	if client.snapshots != 1 || client.deviceLocations != 1 || client.devices != 2 {
+		t.Fatalf("Expected one background call of each kind, got %+v", client)
+	}
+	if !service.deviceCache.Contains("162112", "") {
+		t.Error("Expected the device cache to be warmed")
+	}
+
+	// The follow-up calls are served without another API call
+	response, err := service.getDeviceLocations(GetDeviceLocationsArgs{NetworkID: "162112"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "router-1") {
+		t.Fatalf("Expected the prefetched locations, got %v", err)
+	}
+	if _, err := service.getLatestSnapshot(GetLatestSnapshotArgs{NetworkID: "162112"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.snapshots != 1 || client.deviceLocations != 1 {
+		t.Errorf("Expected the follow-ups to use prefetched data, got %+v", client)
+	}
+	if _, err := service.getLatestSnapshot(GetLatestSnapshotArgs{NetworkID: "162112"}); err != nil || client.snapshots != 2 {
+		t.Errorf("Expected prefetched data to be used once, got %d calls", client.snapshots)
+	}
+
+	// A write to the network drops what was prefetched for it
+	if _, err := listDevices(ListDevicesArgs{NetworkID: "162112"}); err != nil {
+		t.Fatal(err)
+	}
+	service.prefetcher.Wait()
+	update := service.prefetchToolHandler("update_device_locations", service.updateDeviceLocations).(func(UpdateDeviceLocationsArgs) (*mcp.ToolResponse, error))
+	if _, err := update(UpdateDeviceLocationsArgs{NetworkID: "162112", Locations: map[string]string{"router-1": "location-2"}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := service.prefetcher.Take(prefetchDeviceLocations, "162112"); ok {
+		t.Error("Expected the write to invalidate prefetched locations")
+	}
+}
+
+func TestPrefetchToolHandlerDisabled(t *testing.T) {
+	service := createTestService()
+	handler := service.prefetchToolHandler("list_devices", service.listDevices)
+	if _, ok := handler.(func(ListDevicesArgs) (*mcp.ToolResponse, error)); !ok {
+		t.Fatal("Expected the original handler without a prefetcher")
+	}
+}
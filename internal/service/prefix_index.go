@@ -0,0 +1,480 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// PrefixIndexEntry maps one interface address to one aggregate prefix
+type PrefixIndexEntry struct {
+	Device       string `json:"device"`
+	Interface    string `json:"interface"`
+	IP           string `json:"ip"`
+	Prefix       string `json:"prefix"`
+	PrefixLength int    `json:"prefix_length"`
+	Location     string `json:"location"`
+}
+
+// PrefixIndexStatus describes the stored index of one network snapshot
+type PrefixIndexStatus struct {
+	NetworkID   string
+	SnapshotID  string
+	DeviceCount int
+	EntryCount  int
+	IndexedAt   time.Time
+}
+
+// PrefixIndex persists interface address to prefix mappings per network snapshot in the
+// memory database, so prefix analysis and lookups do not walk the device inventory each run
+type PrefixIndex struct {
+	db         *sql.DB
+	instanceID string
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewPrefixIndex creates the prefix index tables in db
+func NewPrefixIndex(db *sql.DB, instanceID string, logger *logger.Logger) (*PrefixIndex, error) {
+	index := &PrefixIndex{db: db, instanceID: instanceID, logger: logger, now: time.Now}
+	if err := index.initSchema(); err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func (p *PrefixIndex) initSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS prefix_index (
+		instance_id TEXT NOT NULL,
+		network_id TEXT NOT NULL,
+		snapshot_id TEXT NOT NULL,
+		device TEXT NOT NULL,
+		interface TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		prefix TEXT NOT NULL,
+		prefix_length INTEGER NOT NULL,
+		location TEXT NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS prefix_index_meta (
+		instance_id TEXT NOT NULL,
+		network_id TEXT NOT NULL,
+		snapshot_id TEXT NOT NULL,
+		device_count INTEGER NOT NULL,
+		entry_count INTEGER NOT NULL,
+		indexed_at INTEGER NOT NULL,
+		PRIMARY KEY(instance_id, network_id, snapshot_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_prefix_index_lookup ON prefix_index(instance_id, network_id, snapshot_id, prefix);
+	CREATE INDEX IF NOT EXISTS idx_prefix_index_device ON prefix_index(instance_id, network_id, snapshot_id, device);
+	`
+	if _, err := p.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create prefix index schema: %w", err)
+	}
+	return nil
+}
+
+// prefixIndexSnapshotKey stores the latest snapshot under "latest"
+func prefixIndexSnapshotKey(snapshotID string) string {
+	if snapshotID == "" {
+		return "latest"
+	}
+	return snapshotID
+}
+
+// buildPrefixIndexEntries expands every routable interface address of the inventory into
+// one entry per aggregation level (/8, /16, /24 for IPv4; /32, /48, /64 for IPv6)
+func buildPrefixIndexEntries(devices []forward.Device, log *logger.Logger) []PrefixIndexEntry {
+	var entries []PrefixIndexEntry
+	for _, device := range devices {
+		location := device.LocationID
+		if location == "" {
+			location = "unknown"
+		}
+
+		for _, iface := range device.Interfaces {
+			if iface.IPAddress == "" {
+				continue
+			}
+
+			// Parse the IP address (plain IPs are treated as host addresses)
+			ip := parseInterfaceAddress(iface.IPAddress)
+			if ip == nil {
+				if log != nil {
+					log.Warn("Could not parse interface IP: %s on device %s", iface.IPAddress, device.Name)
+				}
+				continue
+			}
+
+			// Link-local addresses are reused on every segment and say nothing about topology
+			if ip.IsLinkLocalUnicast() {
+				continue
+			}
+
+			bits := 128
+			if ipFamily(ip) == ipFamilyV4 {
+				ip = ip.To4()
+				bits = 32
+			}
+			for _, level := range aggregationLevelsFor(ip) {
+				mask := net.CIDRMask(level, bits)
+				entries = append(entries, PrefixIndexEntry{
+					Device:       device.Name,
+					Interface:    iface.Name,
+					IP:           iface.IPAddress,
+					Prefix:       (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String(),
+					PrefixLength: level,
+					Location:     location,
+				})
+			}
+		}
+	}
+	return entries
+}
+
+// Build replaces the stored index of a network snapshot with entries derived from devices
+func (p *PrefixIndex) Build(networkID, snapshotID string, devices []forward.Device) ([]PrefixIndexEntry, error) {
+	snapshotKey := prefixIndexSnapshotKey(snapshotID)
+	entries := buildPrefixIndexEntries(devices, p.logger)
+
+	tx, err := p.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin prefix index transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM prefix_index WHERE instance_id = ? AND network_id = ? AND snapshot_id = ?`,
+		p.instanceID, networkID, snapshotKey); err != nil {
+		return nil, fmt.Errorf("failed to clear prefix index: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO prefix_index (instance_id, network_id, snapshot_id, device, interface, ip, prefix, prefix_length, location)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare prefix index insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		if _, err := stmt.Exec(p.instanceID, networkID, snapshotKey, entry.Device, entry.Interface, entry.IP,
+			entry.Prefix, entry.PrefixLength, entry.Location); err != nil {
+			return nil, fmt.Errorf("failed to insert prefix index entry: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(`
+		INSERT OR REPLACE INTO prefix_index_meta (instance_id, network_id, snapshot_id, device_count, entry_count, indexed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, p.instanceID, networkID, snapshotKey, len(devices), len(entries), p.now().Unix()); err != nil {
+		return nil, fmt.Errorf("failed to record prefix index metadata: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit prefix index: %w", err)
+	}
+
+	p.logger.Debug("Indexed %d prefix entries for %d devices in network %s (snapshot %s)", len(entries), len(devices), networkID, snapshotKey)
+	return entries, nil
+}
+
+// Status returns the stored index metadata of a network snapshot, or nil if none exists
+func (p *PrefixIndex) Status(networkID, snapshotID string) (*PrefixIndexStatus, error) {
+	snapshotKey := prefixIndexSnapshotKey(snapshotID)
+	status := &PrefixIndexStatus{NetworkID: networkID, SnapshotID: snapshotKey}
+	var indexedAt int64
+	err := p.db.QueryRow(`
+		SELECT device_count, entry_count, indexed_at FROM prefix_index_meta
+		WHERE instance_id = ? AND network_id = ? AND snapshot_id = ?
+	`, p.instanceID, networkID, snapshotKey).Scan(&status.DeviceCount, &status.EntryCount, &indexedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prefix index status: %w", err)
+	}
+	status.IndexedAt = time.Unix(indexedAt, 0)
+	return status, nil
+}
+
+// Entries returns every stored entry of a network snapshot
+func (p *PrefixIndex) Entries(networkID, snapshotID string) ([]PrefixIndexEntry, error) {
+	return p.query(`
+		SELECT device, interface, ip, prefix, prefix_length, location FROM prefix_index
+		WHERE instance_id = ? AND network_id = ? AND snapshot_id = ?
+	`, p.instanceID, networkID, prefixIndexSnapshotKey(snapshotID))
+}
+
+// Lookup returns the interfaces whose address falls inside prefix. The query is narrowed to
+// the closest indexed aggregation level and then filtered by exact containment, so any
+// prefix length is supported.
+func (p *PrefixIndex) Lookup(networkID, snapshotID, prefix string) ([]PrefixIndexEntry, error) {
+	target, err := parseLookupPrefix(prefix)
+	if err != nil {
+		return nil, err
+	}
+	length, bits := target.Mask.Size()
+
+	levels := ipv6AggregationLevels
+	if bits == 32 {
+		levels = ipv4AggregationLevels
+	}
+	level := levels[0]
+	for _, candidate := range levels {
+		if candidate <= length {
+			level = candidate
+		}
+	}
+
+	var candidates []PrefixIndexEntry
+	if level <= length {
+		mask := net.CIDRMask(level, bits)
+		candidates, err = p.query(`
+			SELECT device, interface, ip, prefix, prefix_length, location FROM prefix_index
+			WHERE instance_id = ? AND network_id = ? AND snapshot_id = ? AND prefix = ?
+		`, p.instanceID, networkID, prefixIndexSnapshotKey(snapshotID), (&net.IPNet{IP: target.IP.Mask(mask), Mask: mask}).String())
+	} else {
+		// Shorter than every indexed level: scan the coarsest level of the family
+		candidates, err = p.query(`
+			SELECT device, interface, ip, prefix, prefix_length, location FROM prefix_index
+			WHERE instance_id = ? AND network_id = ? AND snapshot_id = ? AND prefix_length = ?
+		`, p.instanceID, networkID, prefixIndexSnapshotKey(snapshotID), level)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []PrefixIndexEntry
+	for _, entry := range candidates {
+		ip := parseInterfaceAddress(entry.IP)
+		if ip == nil || ipFamily(ip) != ipFamily(target.IP) || !target.Contains(ip) {
+			continue
+		}
+		entry.Prefix = target.String()
+		entry.PrefixLength = length
+		matches = append(matches, entry)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Device != matches[j].Device {
+			return matches[i].Device < matches[j].Device
+		}
+		return matches[i].Interface < matches[j].Interface
+	})
+	return matches, nil
+}
+
+// Invalidate removes every stored snapshot index of a network, or all networks when
+// networkID is empty
+func (p *PrefixIndex) Invalidate(networkID string) error {
+	for _, table := range []string{"prefix_index", "prefix_index_meta"} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE instance_id = ?", table)
+		args := []interface{}{p.instanceID}
+		if networkID != "" {
+			query += " AND network_id = ?"
+			args = append(args, networkID)
+		}
+		if _, err := p.db.Exec(query, args...); err != nil {
+			return fmt.Errorf("failed to invalidate prefix index: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *PrefixIndex) query(query string, args ...interface{}) ([]PrefixIndexEntry, error) {
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query prefix index: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []PrefixIndexEntry
+	for rows.Next() {
+		var entry PrefixIndexEntry
+		if err := rows.Scan(&entry.Device, &entry.Interface, &entry.IP, &entry.Prefix, &entry.PrefixLength, &entry.Location); err != nil {
+			return nil, fmt.Errorf("failed to scan prefix index entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// parseLookupPrefix accepts a CIDR or a plain address, which is treated as a host prefix
+func parseLookupPrefix(prefix string) (*net.IPNet, error) {
+	normalized, family, err := normalizeIPOrCIDR(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefix: %w", err)
+	}
+	if !strings.Contains(normalized, "/") {
+		if family == ipFamilyV4 {
+			normalized += "/32"
+		} else {
+			normalized += "/128"
+		}
+	}
+	_, ipNet, err := net.ParseCIDR(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefix: %w", err)
+	}
+	if ipv4 := ipNet.IP.To4(); ipv4 != nil && len(ipNet.Mask) == net.IPv4len {
+		ipNet.IP = ipv4
+	}
+	return ipNet, nil
+}
+
+// prefixIndexEntries returns the prefix index of a network snapshot, rebuilding it from the
+// device inventory when missing or, for the latest snapshot, older than the latest TTL.
+// Without a persistent index the entries are computed from the inventory directly.
+func (s *ForwardMCPService) prefixIndexEntries(networkID, snapshotID string, rebuild bool) ([]PrefixIndexEntry, error) {
+	if s.prefixIndex != nil && !rebuild {
+		status, err := s.prefixIndex.Status(networkID, snapshotID)
+		if err != nil {
+			s.logger.Debug("Prefix index status unavailable: %v", err)
+		} else if status != nil && s.prefixIndexFresh(status) {
+			return s.prefixIndex.Entries(networkID, snapshotID)
+		}
+	}
+
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device inventory: %w", err)
+	}
+	if s.prefixIndex == nil {
+		return buildPrefixIndexEntries(devices, s.logger), nil
+	}
+	entries, err := s.prefixIndex.Build(networkID, snapshotID, devices)
+	if err != nil {
+		s.logger.Warn("Failed to persist prefix index for network %s: %v", networkID, err)
+		return buildPrefixIndexEntries(devices, s.logger), nil
+	}
+	return entries, nil
+}
+
+// prefixIndexFresh reports whether a stored index can be reused. Pinned snapshots never
+// change; the latest snapshot follows the device cache's latest TTL.
+func (s *ForwardMCPService) prefixIndexFresh(status *PrefixIndexStatus) bool {
+	if status.SnapshotID != "latest" {
+		return true
+	}
+	ttl := defaultDeviceCacheLatestTTL
+	if s.deviceCache != nil {
+		ttl = s.deviceCache.latestTTL
+	}
+	return s.prefixIndex.now().Sub(status.IndexedAt) < ttl
+}
+
+// whichDevicesInPrefix lists the devices and interfaces addressed inside a prefix
+func (s *ForwardMCPService) whichDevicesInPrefix(args WhichDevicesInPrefixArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("which_devices_in_prefix", args, nil)
+
+	if strings.TrimSpace(args.Prefix) == "" {
+		return nil, fmt.Errorf("prefix is required")
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	target, err := parseLookupPrefix(args.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []PrefixIndexEntry
+	if s.prefixIndex != nil {
+		if _, err := s.prefixIndexEntries(networkID, snapshotID, args.Rebuild); err != nil {
+			return nil, fmt.Errorf("failed to index network %s: %w", networkID, err)
+		}
+		matches, err = s.prefixIndex.Lookup(networkID, snapshotID, target.String())
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		entries, err := s.prefixIndexEntries(networkID, snapshotID, args.Rebuild)
+		if err != nil {
+			return nil, fmt.Errorf("failed to index network %s: %w", networkID, err)
+		}
+		matches = filterPrefixEntries(entries, target)
+	}
+
+	if args.Location != "" {
+		filtered := matches[:0]
+		for _, entry := range matches {
+			if strings.EqualFold(entry.Location, args.Location) {
+				filtered = append(filtered, entry)
+			}
+		}
+		matches = filtered
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(s.formatPrefixMatches(networkID, snapshotID, target.String(), matches))), nil
+}
+
+// filterPrefixEntries returns one entry per interface address contained in target
+func filterPrefixEntries(entries []PrefixIndexEntry, target *net.IPNet) []PrefixIndexEntry {
+	length, _ := target.Mask.Size()
+	seen := make(map[string]bool)
+	var matches []PrefixIndexEntry
+	for _, entry := range entries {
+		key := entry.Device + "|" + entry.Interface + "|" + entry.IP
+		if seen[key] {
+			continue
+		}
+		ip := parseInterfaceAddress(entry.IP)
+		if ip == nil || ipFamily(ip) != ipFamily(target.IP) || !target.Contains(ip) {
+			continue
+		}
+		seen[key] = true
+		entry.Prefix = target.String()
+		entry.PrefixLength = length
+		matches = append(matches, entry)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Device != matches[j].Device {
+			return matches[i].Device < matches[j].Device
+		}
+		return matches[i].Interface < matches[j].Interface
+	})
+	return matches
+}
+
+func (s *ForwardMCPService) formatPrefixMatches(networkID, snapshotID, prefix string, matches []PrefixIndexEntry) string {
+	devices := make(map[string]bool)
+	for _, entry := range matches {
+		devices[entry.Device] = true
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Found %d interfaces on %d devices in %s (network %s, snapshot %s)\n",
+		len(matches), len(devices), prefix, networkID, prefixIndexSnapshotKey(snapshotID)))
+	if s.prefixIndex != nil {
+		if status, err := s.prefixIndex.Status(networkID, snapshotID); err == nil && status != nil {
+			out.WriteString(fmt.Sprintf("Index: %d devices, built %s\n", status.DeviceCount, s.timeFormatter.FormatWithAge(status.IndexedAt)))
+		}
+	}
+	if owner := s.prefixOwner(networkID, prefix); owner != nil {
+		out.WriteString(fmt.Sprintf("Owner: %s\n", owner.Summary(prefix)))
+		if owner.Notes != "" {
+			out.WriteString(fmt.Sprintf("Notes: %s\n", owner.Notes))
+		}
+	}
+	if len(matches) == 0 {
+		return out.String()
+	}
+
+	out.WriteString("\n| Device | Interface | Address | Location |\n|---|---|---|---|\n")
+	for _, entry := range matches {
+		out.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", entry.Device, entry.Interface, entry.IP, entry.Location))
+	}
+	return out.String()
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newTestPrefixIndex(t *testing.T) *PrefixIndex {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	// Every pooled connection to :memory: would otherwise get its own empty database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	index, err := NewPrefixIndex(db, "test", logger.New())
+	if err != nil {
+		t.Fatalf("Failed to create prefix index: %v", err)
+	}
+	return index
+}
+
+func prefixIndexTestDevices() []forward.Device {
+	return []forward.Device{
+		{Name: "core-1", LocationID: "dc1", Interfaces: []forward.DeviceInterface{
+			{Name: "eth0", IPAddress: "10.1.1.1/24"},
+			{Name: "eth1", IPAddress: "10.2.16.1/24"},
+			{Name: "eth2", IPAddress: "fe80::1/64"},
+		}},
+		{Name: "edge-1", LocationID: "dc2", Interfaces: []forward.DeviceInterface{
+			{Name: "eth0", IPAddress: "10.1.2.1/24"},
+			{Name: "eth1", IPAddress: "2001:db8:10:1::1/64"},
+		}},
+		{Name: "branch-1", Interfaces: []forward.DeviceInterface{
+			{Name: "eth0", IPAddress: "172.16.0.1"},
+		}},
+	}
+}
+
+func TestPrefixIndexBuildAndLookup(t *testing.T) {
+	index := newTestPrefixIndex(t)
+
+	entries, err := index.Build("net-1", "", prefixIndexTestDevices())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	// Five routable addresses with three aggregation levels each; link-local is skipped
+	if len(entries) != 15 {
+		t.Errorf("Expected 15 entries, got %d", len(entries))
+	}
+
+	tests := []struct {
+		prefix  string
+		devices []string
+	}{
+		{"10.1.0.0/16", []string{"core-1", "edge-1"}},
+		{"10.1.1.0/24", []string{"core-1"}},
+		{"10.2.16.0/20", []string{"core-1"}},
+		{"10.0.0.0/4", []string{"core-1", "core-1", "edge-1"}},
+		{"172.16.0.1", []string{"branch-1"}},
+		{"2001:db8::/32", []string{"edge-1"}},
+		{"192.168.0.0/16", nil},
+	}
+	for _, tt := range tests {
+		matches, err := index.Lookup("net-1", "latest", tt.prefix)
+		if err != nil {
+			t.Fatalf("Lookup(%s) failed: %v", tt.prefix, err)
+		}
+		var devices []string
+		for _, match := range matches {
+			devices = append(devices, match.Device)
+		}
+		if strings.Join(devices, ",") != strings.Join(tt.devices, ",") {
+			t.Errorf("Lookup(%s) = %v; want %v", tt.prefix, devices, tt.devices)
+		}
+	}
+
+	if _, err := index.Lookup("net-1", "", "not-a-prefix"); err == nil {
+		t.Error("Expected an error for an invalid prefix")
+	}
+}
+
+func TestPrefixIndexStatusAndInvalidate(t *testing.T) {
+	index := newTestPrefixIndex(t)
+
+	status, err := index.Status("net-1", "snap-1")
+	if err != nil || status != nil {
+		t.Fatalf("Expected no status before build, got %v, %v", status, err)
+	}
+
+	if _, err := index.Build("net-1", "snap-1", prefixIndexTestDevices()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := index.Build("net-2", "snap-1", prefixIndexTestDevices()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	status, err = index.Status("net-1", "snap-1")
+	if err != nil || status == nil {
+		t.Fatalf("Expected status after build, got %v, %v", status, err)
+	}
+	if status.DeviceCount != 3 || status.EntryCount != 15 {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	// Rebuilding replaces rather than appends
+	if _, err := index.Build("net-1", "snap-1", prefixIndexTestDevices()[:1]); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if entries, _ := index.Entries("net-1", "snap-1"); len(entries) != 6 {
+		t.Errorf("Expected 6 entries after rebuild, got %d", len(entries))
+	}
+
+	if err := index.Invalidate("net-1"); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if status, _ := index.Status("net-1", "snap-1"); status != nil {
+		t.Error("Expected net-1 index to be removed")
+	}
+	if status, _ := index.Status("net-2", "snap-1"); status == nil {
+		t.Error("Expected net-2 index to survive invalidating net-1")
+	}
+}
+
+func TestWhichDevicesInPrefix(t *testing.T) {
+	service := createTestService()
+	service.prefixIndex = newTestPrefixIndex(t)
+	client := &countingDeviceClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	client.devices = prefixIndexTestDevices()
+	service.forwardClient = client
+
+	response, err := service.whichDevicesInPrefix(WhichDevicesInPrefixArgs{Prefix: "10.1.0.0/16"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Found 2 interfaces on 2 devices in 10.1.0.0/16") {
+		t.Errorf("Unexpected summary: %s", text)
+	}
+	if !strings.Contains(text, "| edge-1 | eth0 | 10.1.2.1/24 | dc2 |") {
+		t.Errorf("Expected edge-1 row, got: %s", text)
+	}
+
+	// The second lookup is served from the persisted index
+	response, err = service.whichDevicesInPrefix(WhichDevicesInPrefixArgs{Prefix: "10.1.0.0/16", Location: "dc1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Found 1 interfaces on 1 devices") {
+		t.Errorf("Expected location filter to apply, got: %s", text)
+	}
+	if client.deviceCalls != 1 {
+		t.Errorf("Expected one inventory fetch, got %d", client.deviceCalls)
+	}
+
+	// A stale latest index is rebuilt
+	service.prefixIndex.now = func() time.Time { return time.Now().Add(time.Hour) }
+	service.deviceCache = nil
+	if _, err := service.whichDevicesInPrefix(WhichDevicesInPrefixArgs{Prefix: "10.1.0.0/16"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.deviceCalls != 2 {
+		t.Errorf("Expected stale index to trigger a second fetch, got %d", client.deviceCalls)
+	}
+
+	if _, err := service.whichDevicesInPrefix(WhichDevicesInPrefixArgs{}); err == nil {
+		t.Error("Expected an error without a prefix")
+	}
+}
+
+func TestFilterPrefixEntriesWithoutIndex(t *testing.T) {
+	entries := buildPrefixIndexEntries(prefixIndexTestDevices(), nil)
+	target, err := parseLookupPrefix("10.1.0.0/16")
+	if err != nil {
+		t.Fatalf("parseLookupPrefix failed: %v", err)
+	}
+	matches := filterPrefixEntries(entries, target)
+	if len(matches) != 2 || matches[0].Device != "core-1" || matches[1].Device != "edge-1" {
+		t.Errorf("Unexpected matches: %+v", matches)
+	}
+}
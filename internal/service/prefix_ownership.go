@@ -0,0 +1,522 @@
+package service
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	prefixEntityType        = "prefix"
+	teamEntityType          = "team"
+	relationOwnedBy         = "owned_by" // prefix → team
+	maxAnnotatedPrefixes    = 100000
+	defaultCoverageMaxShown = 50
+)
+
+// Column names accepted by import_prefix_annotations, after normalizeFactKey
+var (
+	annotationPrefixKeys      = []string{"prefix", "subnet", "cidr", "network"}
+	annotationTeamKeys        = []string{"team", "owner", "ownerteam"}
+	annotationPurposeKeys     = []string{"purpose", "description", "use"}
+	annotationEnvironmentKeys = []string{"environment", "env"}
+	annotationNotesKeys       = []string{"notes", "note", "comment", "comments"}
+)
+
+// PrefixOwnership is the documentation of a prefix. Addresses and more specific prefixes
+// inherit it from the longest annotated prefix containing them.
+type PrefixOwnership struct {
+	Prefix      string    `json:"prefix"`
+	Team        string    `json:"team,omitempty"`
+	Purpose     string    `json:"purpose,omitempty"`
+	Environment string    `json:"environment,omitempty"`
+	Notes       string    `json:"notes,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Summary renders the ownership as "team / purpose / environment", naming the annotated
+// prefix when it was inherited from a less specific one than lookup
+func (o *PrefixOwnership) Summary(lookup string) string {
+	if o == nil {
+		return ""
+	}
+	var parts []string
+	for _, part := range []string{o.Team, o.Purpose, o.Environment} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	summary := firstNonEmpty(strings.Join(parts, " / "), o.Notes)
+	if target, err := normalizePrefix(lookup); err == nil && target.String() != o.Prefix {
+		summary += " (via " + o.Prefix + ")"
+	}
+	return summary
+}
+
+// normalizePrefix returns the canonical network of a CIDR, or the host prefix of an address
+func normalizePrefix(value string) (*net.IPNet, error) {
+	canonical, family, err := normalizeIPOrCIDR(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid prefix %q: expected a CIDR such as 10.20.0.0/16", strings.TrimSpace(value))
+	}
+	if !strings.Contains(canonical, "/") {
+		if family == ipFamilyV4 {
+			canonical += "/32"
+		} else {
+			canonical += "/128"
+		}
+	}
+	_, subnet, err := net.ParseCIDR(canonical)
+	return subnet, err
+}
+
+// PrefixAnnotations holds the annotated prefixes of a network, keyed by canonical prefix
+type PrefixAnnotations map[string]*PrefixOwnership
+
+// Lookup returns the longest annotated prefix containing target, or nil
+func (a PrefixAnnotations) Lookup(target *net.IPNet) *PrefixOwnership {
+	if len(a) == 0 {
+		return nil
+	}
+	for _, prefix := range containingPrefixes(target) {
+		if annotation := a[prefix]; annotation != nil {
+			return annotation
+		}
+	}
+	return nil
+}
+
+// LookupString is Lookup for a CIDR or address; invalid values return nil
+func (a PrefixAnnotations) LookupString(value string) *PrefixOwnership {
+	target, err := normalizePrefix(value)
+	if err != nil {
+		return nil
+	}
+	return a.Lookup(target)
+}
+
+// prefixAnnotations loads the annotated prefixes of a network from the knowledge graph
+func (s *ForwardMCPService) prefixAnnotations(networkID string) (PrefixAnnotations, error) {
+	if s.memorySystem == nil {
+		return nil, nil
+	}
+	entities, err := s.memorySystem.entitiesByNamePrefix(prefixEntityType, prefixEntityName(networkID, ""), maxAnnotatedPrefixes)
+	if err != nil {
+		return nil, err
+	}
+	annotations := make(PrefixAnnotations)
+	for _, entity := range entities {
+		if annotation := prefixOwnershipFromEntity(entity); annotation != nil {
+			annotations[annotation.Prefix] = annotation
+		}
+	}
+	return annotations, nil
+}
+
+// prefixOwner returns the longest annotated prefix of the network containing value, looking
+// up each containing prefix by name rather than loading the annotations
+func (s *ForwardMCPService) prefixOwner(networkID, value string) *PrefixOwnership {
+	target, err := normalizePrefix(value)
+	if s.memorySystem == nil || err != nil {
+		return nil
+	}
+	for _, prefix := range containingPrefixes(target) {
+		entity, err := s.memorySystem.getEntityByNameAndType(prefixEntityName(networkID, prefix), prefixEntityType)
+		if err != nil {
+			continue
+		}
+		if annotation := prefixOwnershipFromEntity(entity); annotation != nil {
+			return annotation
+		}
+	}
+	return nil
+}
+
+// prefixOwnershipFromEntity reads the ownership of a prefix entity, or nil if undocumented
+func prefixOwnershipFromEntity(entity *Entity) *PrefixOwnership {
+	annotation := &PrefixOwnership{
+		Prefix:      resultValueString(entity.Metadata["prefix"]),
+		Team:        resultValueString(entity.Metadata["owner_team"]),
+		Purpose:     resultValueString(entity.Metadata["purpose"]),
+		Environment: resultValueString(entity.Metadata["environment"]),
+		Notes:       resultValueString(entity.Metadata["notes"]),
+	}
+	if annotation.Team == "" && annotation.Purpose == "" && annotation.Environment == "" && annotation.Notes == "" {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(annotation.Prefix); err != nil {
+		return nil
+	}
+	if documented, err := time.Parse(time.RFC3339, resultValueString(entity.Metadata["documented_at"])); err == nil {
+		annotation.UpdatedAt = documented
+	}
+	return annotation
+}
+
+// storePrefixAnnotation stores the non-empty fields of annotation on the prefix entity of the
+// network, creating it if needed, and links the prefix to its team
+func (s *ForwardMCPService) storePrefixAnnotation(networkID string, annotation PrefixOwnership) (*PrefixOwnership, error) {
+	subnet, err := normalizePrefix(annotation.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	prefix := subnet.String()
+	entity, err := s.memorySystem.getEntityByNameAndType(prefixEntityName(networkID, prefix), prefixEntityType)
+	if err != nil {
+		if entity, err = s.memorySystem.CreateEntity(prefixEntityName(networkID, prefix), prefixEntityType,
+			map[string]interface{}{"prefix": prefix, "network_id": networkID}); err != nil {
+			return nil, err
+		}
+	}
+	metadata := make(map[string]interface{}, len(entity.Metadata)+5)
+	for key, value := range entity.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range map[string]string{"owner_team": annotation.Team, "purpose": annotation.Purpose,
+		"environment": annotation.Environment, "notes": annotation.Notes} {
+		if value = strings.TrimSpace(value); value != "" {
+			metadata[key] = value
+		}
+	}
+	metadata["documented_at"] = time.Now().UTC().Format(time.RFC3339)
+	if err := s.memorySystem.updateEntityMetadata(entity.ID, metadata); err != nil {
+		return nil, err
+	}
+	entity.Metadata = metadata
+
+	if team := strings.TrimSpace(annotation.Team); team != "" {
+		if err := s.linkPrefixTeam(entity.ID, team); err != nil {
+			return nil, err
+		}
+	}
+	return prefixOwnershipFromEntity(entity), nil
+}
+
+// linkPrefixTeam replaces the owned_by relation of a prefix
+func (s *ForwardMCPService) linkPrefixTeam(prefixID, team string) error {
+	teamEntity, err := s.memorySystem.getEntityByNameAndType(team, teamEntityType)
+	if err != nil {
+		if teamEntity, err = s.memorySystem.CreateEntity(team, teamEntityType, nil); err != nil {
+			return err
+		}
+	}
+	relations, err := s.memorySystem.GetRelations(prefixID, relationOwnedBy)
+	if err != nil {
+		return err
+	}
+	for _, relation := range relations {
+		if relation.FromID != prefixID {
+			continue
+		}
+		if relation.ToID == teamEntity.ID {
+			return nil
+		}
+		if err := s.memorySystem.DeleteRelation(relation.ID); err != nil {
+			return err
+		}
+	}
+	_, err = s.memorySystem.CreateRelation(prefixID, teamEntity.ID, relationOwnedBy, nil)
+	return err
+}
+
+// parsePrefixAnnotationCSV reads annotations with a header row naming a prefix column and any
+// of team, purpose, environment and notes
+func parsePrefixAnnotationCSV(data string) ([]PrefixOwnership, []string, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil, fmt.Errorf("csv is empty")
+	}
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeFactKey(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	column := func(keys []string) int {
+		for _, key := range keys {
+			if index, ok := columns[key]; ok {
+				return index
+			}
+		}
+		return -1
+	}
+	prefixCol := column(annotationPrefixKeys)
+	if prefixCol < 0 {
+		return nil, nil, fmt.Errorf("header must include a prefix column (got: %s)", strings.Join(header, ", "))
+	}
+	teamCol, purposeCol, environmentCol, notesCol := column(annotationTeamKeys), column(annotationPurposeKeys), column(annotationEnvironmentKeys), column(annotationNotesKeys)
+	value := func(record []string, index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var annotations []PrefixOwnership
+	var problems []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		// Blank lines are skipped by the reader, so take the line from the record itself
+		line, _ := reader.FieldPos(0)
+		annotation := PrefixOwnership{
+			Prefix:      value(record, prefixCol),
+			Team:        value(record, teamCol),
+			Purpose:     value(record, purposeCol),
+			Environment: value(record, environmentCol),
+			Notes:       value(record, notesCol),
+		}
+		if annotation.Prefix == "" {
+			continue
+		}
+		if _, err := normalizePrefix(annotation.Prefix); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		if annotation.Team == "" && annotation.Purpose == "" && annotation.Environment == "" && annotation.Notes == "" {
+			problems = append(problems, fmt.Sprintf("line %d: %s has no team, purpose, environment or notes", line, annotation.Prefix))
+			continue
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, problems, nil
+}
+
+// PrefixCoverage is the documentation coverage of the subnets configured in a network
+type PrefixCoverage struct {
+	Total        int
+	Documented   int
+	ByTeam       map[string]int
+	Undocumented []UndocumentedPrefix
+}
+
+// UndocumentedPrefix is a configured subnet no annotation covers
+type UndocumentedPrefix struct {
+	Prefix  string
+	Devices []string
+}
+
+// interfaceSubnets returns the subnets configured on device interfaces with their devices.
+// Host routes and link-local subnets carry no ownership and are skipped.
+func interfaceSubnets(devices []forward.Device) map[string][]string {
+	subnets := make(map[string][]string)
+	for _, device := range devices {
+		for _, iface := range device.Interfaces {
+			ip, subnet, err := net.ParseCIDR(strings.TrimSpace(iface.IPAddress))
+			if err != nil || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			if length, bits := subnet.Mask.Size(); length == bits {
+				continue
+			}
+			key := subnet.String()
+			if names := subnets[key]; len(names) == 0 || names[len(names)-1] != device.Name {
+				subnets[key] = append(subnets[key], device.Name)
+			}
+		}
+	}
+	return subnets
+}
+
+// computePrefixCoverage checks every configured subnet against the annotations
+func computePrefixCoverage(subnets map[string][]string, annotations PrefixAnnotations) *PrefixCoverage {
+	coverage := &PrefixCoverage{Total: len(subnets), ByTeam: make(map[string]int)}
+	for prefix, devices := range subnets {
+		if owner := annotations.LookupString(prefix); owner != nil {
+			coverage.Documented++
+			coverage.ByTeam[firstNonEmpty(owner.Team, "(no team)")]++
+			continue
+		}
+		coverage.Undocumented = append(coverage.Undocumented, UndocumentedPrefix{Prefix: prefix, Devices: devices})
+	}
+	sort.Slice(coverage.Undocumented, func(i, j int) bool {
+		return compareIPPrefixes(coverage.Undocumented[i].Prefix, coverage.Undocumented[j].Prefix)
+	})
+	return coverage
+}
+
+// compareIPPrefixes orders prefixes by family, address and length
+func compareIPPrefixes(a, b string) bool {
+	_, subnetA, errA := net.ParseCIDR(a)
+	_, subnetB, errB := net.ParseCIDR(b)
+	if errA != nil || errB != nil {
+		return a < b
+	}
+	ipA, ipB := subnetA.IP.To16(), subnetB.IP.To16()
+	if familyA, familyB := ipFamily(subnetA.IP), ipFamily(subnetB.IP); familyA != familyB {
+		return familyA < familyB
+	}
+	if order := strings.Compare(string(ipA), string(ipB)); order != 0 {
+		return order < 0
+	}
+	lengthA, _ := subnetA.Mask.Size()
+	lengthB, _ := subnetB.Mask.Size()
+	return lengthA < lengthB
+}
+
+// annotatePrefixInfo fills the ownership of discovered prefixes for the prefix analysis report
+func (s *ForwardMCPService) annotatePrefixInfo(networkID string, prefixInfo []NetworkPrefixInfo) {
+	annotations, err := s.prefixAnnotations(networkID)
+	if err != nil {
+		s.logger.Debug("Prefix annotations unavailable: %v", err)
+		return
+	}
+	if len(annotations) == 0 {
+		return
+	}
+	for i := range prefixInfo {
+		prefixInfo[i].Ownership = annotations.LookupString(prefixInfo[i].Prefix).Summary(prefixInfo[i].Prefix)
+	}
+}
+
+// annotatePrefixTool documents the ownership of a prefix
+func (s *ForwardMCPService) annotatePrefixTool(args AnnotatePrefixArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("annotate_prefix", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if args.Team == "" && args.Purpose == "" && args.Environment == "" && args.Notes == "" {
+		return nil, fmt.Errorf("at least one of team, purpose, environment and notes is required")
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	annotation, err := s.storePrefixAnnotation(networkID, PrefixOwnership{Prefix: args.Prefix, Team: args.Team,
+		Purpose: args.Purpose, Environment: args.Environment, Notes: args.Notes})
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"Documented %s in network %s: team %s, purpose %s, environment %s.\n\nMore specific prefixes and addresses inherit this unless annotated themselves; the ownership appears in which_devices_in_prefix and analyze_network_prefixes.",
+		annotation.Prefix, networkID, firstNonEmpty(annotation.Team, "-"), firstNonEmpty(annotation.Purpose, "-"), firstNonEmpty(annotation.Environment, "-")))), nil
+}
+
+// importPrefixAnnotations documents prefixes in bulk from CSV
+func (s *ForwardMCPService) importPrefixAnnotations(args ImportPrefixAnnotationsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("import_prefix_annotations", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	annotations, problems, err := parsePrefixAnnotationCSV(args.CSV)
+	if err != nil {
+		return nil, err
+	}
+	imported := 0
+	if !args.DryRun {
+		for _, annotation := range annotations {
+			if _, err := s.storePrefixAnnotation(networkID, annotation); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", annotation.Prefix, err))
+				continue
+			}
+			imported++
+		}
+	}
+
+	var text strings.Builder
+	if args.DryRun {
+		text.WriteString(fmt.Sprintf("Dry run: %d prefixes would be documented.\n", len(annotations)))
+	} else {
+		text.WriteString(fmt.Sprintf("Documented %d prefixes in network %s.\n", imported, networkID))
+	}
+	if len(problems) > 0 {
+		text.WriteString(fmt.Sprintf("\n%d rows skipped:\n", len(problems)))
+		for _, problem := range problems {
+			text.WriteString("- " + problem + "\n")
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// prefixDocumentationCoverage reports which configured subnets have no ownership
+func (s *ForwardMCPService) prefixDocumentationCoverage(args PrefixDocumentationCoverageArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("prefix_documentation_coverage", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	devices, err := s.getNetworkDevices(networkID, s.getSnapshotID(args.SnapshotID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	annotations, err := s.prefixAnnotations(networkID)
+	if err != nil {
+		return nil, err
+	}
+	coverage := computePrefixCoverage(interfaceSubnets(devices), annotations)
+
+	// The CSV is a template for import_prefix_annotations
+	if args.Format == "csv" {
+		var out strings.Builder
+		writer := csv.NewWriter(&out)
+		writer.Write([]string{"prefix", "team", "purpose", "environment", "notes"})
+		for _, prefix := range coverage.Undocumented {
+			writer.Write([]string{prefix.Prefix, "", "", "", "devices: " + strings.Join(prefix.Devices, " ")})
+		}
+		writer.Flush()
+		return mcp.NewToolResponse(mcp.NewTextContent(out.String())), nil
+	}
+
+	maxShown := args.MaxShown
+	if maxShown <= 0 {
+		maxShown = defaultCoverageMaxShown
+	}
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Prefix Documentation Coverage: network %s\n\n", networkID))
+	percent := 100.0
+	if coverage.Total > 0 {
+		percent = float64(coverage.Documented) * 100 / float64(coverage.Total)
+	}
+	text.WriteString(fmt.Sprintf("**Configured subnets:** %d\n**Documented:** %d (%.1f%%)\n**Undocumented:** %d\n**Annotated prefixes:** %d\n\n",
+		coverage.Total, coverage.Documented, percent, len(coverage.Undocumented), len(annotations)))
+	if len(coverage.ByTeam) > 0 {
+		teams := make([]string, 0, len(coverage.ByTeam))
+		for team := range coverage.ByTeam {
+			teams = append(teams, team)
+		}
+		sort.Strings(teams)
+		text.WriteString("### Subnets by Team\n| Team | Subnets |\n|------|---------|\n")
+		for _, team := range teams {
+			text.WriteString(fmt.Sprintf("| %s | %d |\n", team, coverage.ByTeam[team]))
+		}
+		text.WriteString("\n")
+	}
+	if len(coverage.Undocumented) > 0 {
+		text.WriteString("### Undocumented Subnets\n| Prefix | Devices |\n|--------|---------|\n")
+		for _, prefix := range coverage.Undocumented[:min(maxShown, len(coverage.Undocumented))] {
+			text.WriteString(fmt.Sprintf("| %s | %s |\n", prefix.Prefix, strings.Join(prefix.Devices, ", ")))
+		}
+		if len(coverage.Undocumented) > maxShown {
+			text.WriteString(fmt.Sprintf("\n%d more not shown.", len(coverage.Undocumented)-maxShown))
+		}
+		text.WriteString("\nUse format=csv for an import_prefix_annotations template of the undocumented subnets.\n")
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestParsePrefixAnnotationCSV(t *testing.T) {
+	data := "\ufeffSubnet,Owner Team,Purpose,Env,Notes\n" +
+		"10.20.0.0/16,payments,database tier,production,\n" +
+		"\n" +
+		"10.30.0.0/33,web,frontend,staging,\n" +
+		"10.40.0.0/16,,,,\n" +
+		"2001:db8::/32,platform\n"
+	annotations, problems, err := parsePrefixAnnotationCSV(data)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(annotations) != 2 || annotations[0].Team != "payments" || annotations[0].Environment != "production" || annotations[1].Team != "platform" {
+		t.Errorf("Unexpected annotations: %+v", annotations)
+	}
+	if len(problems) != 2 || !strings.HasPrefix(problems[0], "line 4:") || !strings.HasPrefix(problems[1], "line 5:") {
+		t.Errorf("Expected the invalid prefix and the empty row reported, got %v", problems)
+	}
+
+	if _, _, err := parsePrefixAnnotationCSV("team,purpose\npayments,db\n"); err == nil {
+		t.Error("Expected an error without a prefix column")
+	}
+}
+
+func TestPrefixAnnotationsLookup(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+
+	for _, annotation := range []PrefixOwnership{
+		{Prefix: "10.0.0.0/8", Team: "network", Environment: "production"},
+		{Prefix: "10.20.1.7/16", Team: "payments", Purpose: "database tier"},
+	} {
+		if _, err := service.storePrefixAnnotation("net-a", annotation); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+	annotations, err := service.prefixAnnotations("net-a")
+	if err != nil || len(annotations) != 2 {
+		t.Fatalf("Expected two annotations, got %v %v", annotations, err)
+	}
+	if other, err := service.prefixAnnotations("net-b"); err != nil || len(other) != 0 || service.prefixOwner("net-b", "10.20.5.9") != nil {
+		t.Errorf("Expected annotations of net-a hidden from net-b, got %v %v", other, err)
+	}
+
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{"10.20.0.0/16", "payments / database tier"},
+		{"10.20.5.9", "payments / database tier (via 10.20.0.0/16)"},
+		{"10.21.0.0/24", "network / production (via 10.0.0.0/8)"},
+		{"10.0.0.0/7", ""},
+		{"192.168.1.0/24", ""},
+	}
+	for _, test := range tests {
+		if got := annotations.LookupString(test.target).Summary(test.target); got != test.want {
+			t.Errorf("Lookup of %s gave %q, expected %q", test.target, got, test.want)
+		}
+		if got := service.prefixOwner("net-a", test.target).Summary(test.target); got != test.want {
+			t.Errorf("Owner of %s gave %q, expected %q", test.target, got, test.want)
+		}
+	}
+
+	// A partial update keeps the other fields and moves the prefix to the new team
+	if _, err := service.annotatePrefixTool(AnnotatePrefixArgs{NetworkID: "net-a", Prefix: "10.20.0.0/16", Team: "billing"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	annotations, _ = service.prefixAnnotations("net-a")
+	if owner := annotations.LookupString("10.20.0.0/16"); owner.Team != "billing" || owner.Purpose != "database tier" {
+		t.Errorf("Expected the purpose kept, got %+v", owner)
+	}
+	prefix, _ := service.memorySystem.getEntityByNameAndType(prefixEntityName("net-a", "10.20.0.0/16"), prefixEntityType)
+	relations, err := service.memorySystem.GetRelations(prefix.ID, relationOwnedBy)
+	if err != nil || len(relations) != 1 {
+		t.Fatalf("Expected a single owned_by relation, got %v %v", relations, err)
+	}
+	if team, _ := service.memorySystem.getEntityByID(relations[0].ToID); team == nil || team.Name != "billing" {
+		t.Errorf("Expected the prefix owned by billing, got %+v", team)
+	}
+
+	if _, err := service.annotatePrefixTool(AnnotatePrefixArgs{NetworkID: "net-a", Prefix: "10.20.0.0/16"}); err == nil {
+		t.Error("Expected an error without any field")
+	}
+}
+
+func TestPrefixDocumentationCoverage(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.forwardClient.(*MockForwardClient).devices = []forward.Device{
+		{Name: "core-1", Interfaces: []forward.DeviceInterface{
+			{Name: "Vlan10", IPAddress: "10.20.1.1/24"},
+			{Name: "Vlan20", IPAddress: "10.30.1.1/24"},
+			{Name: "Loopback0", IPAddress: "10.255.0.1/32"},
+			{Name: "Eth1", IPAddress: "fe80::1/64"},
+		}},
+		{Name: "core-2", Interfaces: []forward.DeviceInterface{{Name: "Vlan20", IPAddress: "10.30.1.2/24"}}},
+	}
+
+	response, err := service.importPrefixAnnotations(ImportPrefixAnnotationsArgs{NetworkID: "162112", CSV: "prefix,team\n10.20.0.0/16,payments\n"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Documented 1 prefixes in network 162112") {
+		t.Errorf("Unexpected import: %s", text)
+	}
+
+	response, err = service.prefixDocumentationCoverage(PrefixDocumentationCoverageArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "**Configured subnets:** 2") || !strings.Contains(text, "| payments | 1 |") ||
+		!strings.Contains(text, "| 10.30.1.0/24 | core-1, core-2 |") {
+		t.Errorf("Unexpected coverage: %s", text)
+	}
+
+	response, err = service.prefixDocumentationCoverage(PrefixDocumentationCoverageArgs{NetworkID: "162112", Format: "csv"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	annotations, problems, err := parsePrefixAnnotationCSV(response.Content[0].TextContent.Text)
+	if err != nil || len(problems) != 0 || len(annotations) != 1 || annotations[0].Prefix != "10.30.1.0/24" {
+		t.Errorf("Expected the template to round-trip through the importer, got %+v %v %v", annotations, problems, err)
+	}
+
+	prefixInfo := []NetworkPrefixInfo{{Prefix: "10.20.1.0/24"}, {Prefix: "10.30.0.0/16"}}
+	service.annotatePrefixInfo("162112", prefixInfo)
+	if prefixInfo[0].Ownership != "payments (via 10.20.0.0/16)" || prefixInfo[1].Ownership != "" {
+		t.Errorf("Unexpected prefix ownership: %+v", prefixInfo)
+	}
+}
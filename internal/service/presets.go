@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+const (
+	argumentPresetType   = "argument_preset"
+	presetArgument       = "preset"       // Argument of any tool call naming the preset to apply
+	presetOwnerArgument  = "preset_owner" // Set by the transport to the calling API key, never by clients
+	presetScopeSession   = "session"
+	presetScopePersisted = "persistent"
+	maxArgumentPresets   = 100 // Presets per owner and scope
+	jsonRPCInvalidParams = -32602
+)
+
+var presetNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,63}$`)
+
+// ArgumentPreset is a named bundle of tool arguments, e.g. a network, snapshot and limits
+type ArgumentPreset struct {
+	Name        string                 `json:"name"`
+	Scope       string                 `json:"scope"`
+	Description string                 `json:"description,omitempty"`
+	Arguments   map[string]interface{} `json:"arguments"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// ArgumentPresets holds the session presets of each API key (the empty owner on stdio);
+// persistent presets are memory entities and survive restarts
+type ArgumentPresets struct {
+	presets map[string]map[string]ArgumentPreset // owner → name → preset
+	mutex   sync.RWMutex
+}
+
+// NewArgumentPresets creates an empty session preset store
+func NewArgumentPresets() *ArgumentPresets {
+	return &ArgumentPresets{presets: make(map[string]map[string]ArgumentPreset)}
+}
+
+// Get returns a session preset of owner
+func (p *ArgumentPresets) Get(owner, name string) (ArgumentPreset, bool) {
+	if p == nil {
+		return ArgumentPreset{}, false
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	preset, ok := p.presets[owner][name]
+	return preset, ok
+}
+
+// Set stores a session preset of owner, replacing one of the same name
+func (p *ArgumentPresets) Set(owner string, preset ArgumentPreset) error {
+	if p == nil {
+		return fmt.Errorf("session presets are not available")
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	owned := p.presets[owner]
+	if owned == nil {
+		owned = make(map[string]ArgumentPreset)
+		p.presets[owner] = owned
+	}
+	if _, exists := owned[preset.Name]; !exists && len(owned) >= maxArgumentPresets {
+		return fmt.Errorf("at most %d session presets can be saved; delete one first", maxArgumentPresets)
+	}
+	owned[preset.Name] = preset
+	return nil
+}
+
+// Delete removes a session preset of owner and reports whether it existed
+func (p *ArgumentPresets) Delete(owner, name string) bool {
+	if p == nil {
+		return false
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if _, ok := p.presets[owner][name]; !ok {
+		return false
+	}
+	delete(p.presets[owner], name)
+	return true
+}
+
+// List returns the session presets of owner
+func (p *ArgumentPresets) List(owner string) []ArgumentPreset {
+	if p == nil {
+		return nil
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	var presets []ArgumentPreset
+	for _, preset := range p.presets[owner] {
+		presets = append(presets, preset)
+	}
+	return presets
+}
+
+// presetEntityName keeps the persistent presets of API keys apart from each other
+func presetEntityName(owner, name string) string {
+	if owner == "" {
+		return name
+	}
+	return owner + "/" + name
+}
+
+// savePersistentPreset stores a preset as a memory entity, replacing one of the same name
+func (s *ForwardMCPService) savePersistentPreset(owner string, preset ArgumentPreset) error {
+	entityName := presetEntityName(owner, preset.Name)
+	metadata := map[string]interface{}{
+		"owner":       owner,
+		"preset":      preset.Name,
+		"description": preset.Description,
+		"arguments":   MarshalCompactJSONString(preset.Arguments),
+		"updated_at":  preset.UpdatedAt.Format(time.RFC3339),
+	}
+	if existing, err := s.memorySystem.getEntityByNameAndType(entityName, argumentPresetType); err == nil {
+		return s.memorySystem.updateEntityMetadata(existing.ID, metadata)
+	}
+	if len(s.persistentPresets(owner)) >= maxArgumentPresets {
+		return fmt.Errorf("at most %d persistent presets can be saved; delete one first", maxArgumentPresets)
+	}
+	_, err := s.memorySystem.CreateEntity(entityName, argumentPresetType, metadata)
+	return err
+}
+
+// persistentPresets returns the presets of owner stored in the memory system
+func (s *ForwardMCPService) persistentPresets(owner string) []ArgumentPreset {
+	if s.memorySystem == nil {
+		return nil
+	}
+	entities, err := s.memorySystem.SearchEntities("", argumentPresetType, maxArgumentPresets*10)
+	if err != nil {
+		s.logger.Warn("Failed to load argument presets: %v", err)
+		return nil
+	}
+	var presets []ArgumentPreset
+	for _, entity := range entities {
+		if resultValueString(entity.Metadata["owner"]) != owner {
+			continue
+		}
+		preset := ArgumentPreset{
+			Name:        resultValueString(entity.Metadata["preset"]),
+			Scope:       presetScopePersisted,
+			Description: resultValueString(entity.Metadata["description"]),
+		}
+		if err := json.Unmarshal([]byte(resultValueString(entity.Metadata["arguments"])), &preset.Arguments); err != nil || preset.Name == "" {
+			s.logger.Warn("Skipping invalid argument preset %s", entity.Name)
+			continue
+		}
+		preset.UpdatedAt, _ = time.Parse(time.RFC3339, resultValueString(entity.Metadata["updated_at"]))
+		presets = append(presets, preset)
+	}
+	return presets
+}
+
+// lookupPreset finds a preset of owner, preferring the session scope
+func (s *ForwardMCPService) lookupPreset(owner, name string) (ArgumentPreset, bool) {
+	if preset, ok := s.presets.Get(owner, name); ok {
+		return preset, true
+	}
+	for _, preset := range s.persistentPresets(owner) {
+		if preset.Name == name {
+			return preset, true
+		}
+	}
+	return ArgumentPreset{}, false
+}
+
+// applyPreset fills the arguments of a tools/call request from the preset it names. Arguments
+// passed with the call win over the preset; presets may hold arguments a tool does not take,
+// which that tool ignores.
+func (s *ForwardMCPService) applyPreset(ctx context.Context, params json.RawMessage) (json.RawMessage, error) {
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(params, &call); err != nil {
+		return params, nil // Left for the server to reject
+	}
+	var arguments map[string]interface{}
+	if raw := call["arguments"]; len(raw) > 0 {
+		if err := json.Unmarshal(raw, &arguments); err != nil {
+			return params, nil
+		}
+	}
+	if arguments == nil {
+		arguments = make(map[string]interface{})
+	}
+
+	owner := ""
+	if identity := apiKeyIdentityFromContext(ctx); identity != nil {
+		owner = identity.ID
+	}
+	name, _ := arguments[presetArgument].(string)
+	_, spoofed := arguments[presetOwnerArgument]
+	if name == "" && !spoofed && owner == "" {
+		return params, nil
+	}
+	delete(arguments, presetArgument)
+	delete(arguments, presetOwnerArgument)
+	if owner != "" {
+		arguments[presetOwnerArgument] = owner
+	}
+
+	if name != "" {
+		preset, ok := s.lookupPreset(owner, name)
+		if !ok {
+			return nil, fmt.Errorf("unknown preset %q; call list_presets to see the saved presets", name)
+		}
+		for key, value := range preset.Arguments {
+			if current, ok := arguments[key]; !ok || current == nil {
+				arguments[key] = value
+			}
+		}
+	}
+
+	raw, err := json.Marshal(arguments)
+	if err != nil {
+		return nil, err
+	}
+	call["arguments"] = raw
+	return json.Marshal(call)
+}
+
+// presetTransport expands the preset argument of tool calls and takes their verbosity argument
+// before the server reads them, and scopes the sessions of prompt calls to the calling key
+type presetTransport struct {
+	transport.Transport
+	service *ForwardMCPService
+}
+
+// WithArgumentPresets wraps a transport so every tool call can pass preset: <name> and
+// verbosity: verbose, normal or terse (also from a preset), and every prompt call carries its
+// API key in its session_id
+func (s *ForwardMCPService) WithArgumentPresets(inner transport.Transport) transport.Transport {
+	return &presetTransport{Transport: inner, service: s}
+}
+
+// SetMessageHandler implements transport.Transport, answering calls to unknown presets or
+// verbosity levels directly
+func (t *presetTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	t.Transport.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		request := message.JsonRpcRequest
+		if message.Type == transport.BaseMessageTypeJSONRPCRequestType && request != nil && request.Method == "prompts/get" {
+			request.Params = t.service.scopePromptSession(ctx, request.Params)
+		}
+		if message.Type != transport.BaseMessageTypeJSONRPCRequestType || request == nil || request.Method != "tools/call" {
+			handler(ctx, message)
+			return
+		}
+		params, err := t.service.applyPreset(ctx, request.Params)
+		if err == nil {
+			ctx, params, err = takeVerbosity(ctx, params)
+		}
+		if err != nil {
+			reply := transport.NewBaseMessageError(&transport.BaseJSONRPCError{
+				Id:      request.Id,
+				Jsonrpc: request.Jsonrpc,
+				Error:   transport.BaseJSONRPCErrorInner{Code: jsonRPCInvalidParams, Message: err.Error()},
+			})
+			if err := t.Send(ctx, reply); err != nil {
+				t.service.logger.Error("Failed to reject tool call: %v", err)
+			}
+			return
+		}
+		request.Params = params
+		handler(ctx, message)
+	})
+}
+
+// savePreset stores a named bundle of tool arguments
+func (s *ForwardMCPService) savePreset(args SavePresetArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("save_preset", args, nil)
+
+	name := strings.TrimSpace(args.Name)
+	if !presetNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("invalid preset name %q: use up to 64 letters, digits, '.', '_' or '-'", args.Name)
+	}
+	if len(args.Arguments) == 0 {
+		return nil, fmt.Errorf("arguments must hold at least one tool argument, e.g. {\"network_id\": \"162112\"}")
+	}
+	for key := range args.Arguments {
+		if key == presetArgument || key == presetOwnerArgument {
+			return nil, fmt.Errorf("presets cannot set the %s argument", key)
+		}
+	}
+	scope := strings.ToLower(strings.TrimSpace(args.Scope))
+	if scope == "" {
+		scope = presetScopeSession
+	}
+
+	preset := ArgumentPreset{Name: name, Scope: scope, Description: args.Description, Arguments: args.Arguments, UpdatedAt: time.Now().UTC()}
+	switch scope {
+	case presetScopeSession:
+		if err := s.presets.Set(args.PresetOwner, preset); err != nil {
+			return nil, err
+		}
+	case presetScopePersisted:
+		if s.memorySystem == nil {
+			return nil, newCodedError(CodeMemoryUnavailable)
+		}
+		if err := s.savePersistentPreset(args.PresetOwner, preset); err != nil {
+			return nil, fmt.Errorf("failed to save preset %s: %w", name, err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown scope %q: use session or persistent", args.Scope)
+	}
+
+	keys := make([]string, 0, len(preset.Arguments))
+	for key := range preset.Arguments {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"Saved %s preset %s with %s.\n\nPass preset: %q to any tool to use these arguments; arguments given with the call override the preset.",
+		scope, name, strings.Join(keys, ", "), name))), nil
+}
+
+// listPresets shows the caller's session and persistent presets
+func (s *ForwardMCPService) listPresets(args ListPresetsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_presets", args, nil)
+
+	presets := append(s.presets.List(args.PresetOwner), s.persistentPresets(args.PresetOwner)...)
+	if len(presets) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No presets saved. Use save_preset to name a bundle of tool arguments.")), nil
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		if presets[i].Name != presets[j].Name {
+			return presets[i].Name < presets[j].Name
+		}
+		return presets[i].Scope > presets[j].Scope // The session preset wins and is listed first
+	})
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Argument Presets (%d)\n\n", len(presets))
+	b.WriteString("| Preset | Scope | Arguments | Description | Updated |\n|---|---|---|---|---|\n")
+	for _, preset := range presets {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", preset.Name, preset.Scope,
+			strings.ReplaceAll(MarshalCompactJSONString(preset.Arguments), "|", "\\|"), preset.Description, s.timeFormatter.Format(preset.UpdatedAt))
+	}
+	b.WriteString("\nPass preset: <name> to any tool; a session preset hides a persistent one of the same name.")
+	return mcp.NewToolResponse(mcp.NewTextContent(b.String())), nil
+}
+
+// deletePreset removes a preset from one or both scopes
+func (s *ForwardMCPService) deletePreset(args DeletePresetArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("delete_preset", args, nil)
+
+	name := strings.TrimSpace(args.Name)
+	scope := strings.ToLower(strings.TrimSpace(args.Scope))
+	if scope != "" && scope != presetScopeSession && scope != presetScopePersisted {
+		return nil, fmt.Errorf("unknown scope %q: use session or persistent", args.Scope)
+	}
+	var deleted []string
+	if scope != presetScopePersisted && s.presets.Delete(args.PresetOwner, name) {
+		deleted = append(deleted, presetScopeSession)
+	}
+	if scope != presetScopeSession && s.memorySystem != nil {
+		if entity, err := s.memorySystem.getEntityByNameAndType(presetEntityName(args.PresetOwner, name), argumentPresetType); err == nil {
+			if err := s.memorySystem.DeleteEntity(entity.ID); err != nil {
+				return nil, fmt.Errorf("failed to delete preset %s: %w", name, err)
+			}
+			deleted = append(deleted, presetScopePersisted)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil, fmt.Errorf("unknown preset %s", name)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Deleted %s preset %s.", strings.Join(deleted, " and "), name))), nil
+}
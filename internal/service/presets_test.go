@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+// fakeTransport records the messages a wrapped transport delivers and sends
+type fakeTransport struct {
+	handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)
+	sent    []*transport.BaseJsonRpcMessage
+}
+
+func (f *fakeTransport) Start(ctx context.Context) error { return nil }
+func (f *fakeTransport) Send(ctx context.Context, message *transport.BaseJsonRpcMessage) error {
+	f.sent = append(f.sent, message)
+	return nil
+}
+func (f *fakeTransport) Close() error                        { return nil }
+func (f *fakeTransport) SetCloseHandler(handler func())      {}
+func (f *fakeTransport) SetErrorHandler(handler func(error)) {}
+func (f *fakeTransport) SetMessageHandler(handler func(ctx context.Context, message *transport.BaseJsonRpcMessage)) {
+	f.handler = handler
+}
+
+func presetTestService() *ForwardMCPService {
+	service := createTestService()
+	service.presets = NewArgumentPresets()
+	return service
+}
+
+func TestSaveAndListPresets(t *testing.T) {
+	service := presetTestService()
+
+	if _, err := service.savePreset(SavePresetArgs{Name: "bad name", Arguments: map[string]interface{}{"limit": 5}}); err == nil {
+		t.Error("Expected an invalid name to be rejected")
+	}
+	if _, err := service.savePreset(SavePresetArgs{Name: "empty"}); err == nil {
+		t.Error("Expected a preset without arguments to be rejected")
+	}
+	if _, err := service.savePreset(SavePresetArgs{Name: "nested", Arguments: map[string]interface{}{"preset": "other"}}); err == nil {
+		t.Error("Expected a preset naming another preset to be rejected")
+	}
+	if _, err := service.savePreset(SavePresetArgs{Name: "p", Arguments: map[string]interface{}{"limit": 5}, Scope: "forever"}); err == nil {
+		t.Error("Expected an unknown scope to be rejected")
+	}
+
+	response, err := service.savePreset(SavePresetArgs{Name: "prod-quick", Arguments: map[string]interface{}{"network_id": "162112", "limit": 20}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Saved session preset prod-quick with limit, network_id") {
+		t.Errorf("Unexpected response: %s", text)
+	}
+	if _, err := service.savePreset(SavePresetArgs{Name: "prod-quick", Scope: "Persistent", Description: "Production", Arguments: map[string]interface{}{"network_id": "162112"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.savePreset(SavePresetArgs{Name: "lab", Scope: "persistent", PresetOwner: "key-b", Arguments: map[string]interface{}{"network_id": "999"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	response, _ = service.listPresets(ListPresetsArgs{})
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Argument Presets (2)", "| prod-quick | session | {\"limit\":20,\"network_id\":\"162112\"} |",
+		"| prod-quick | persistent | {\"network_id\":\"162112\"} | Production |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "lab") {
+		t.Errorf("Expected the presets of another API key to be hidden: %s", text)
+	}
+
+	if _, err := service.deletePreset(DeletePresetArgs{Name: "prod-quick", Scope: "session"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if preset, ok := service.lookupPreset("", "prod-quick"); !ok || preset.Scope != presetScopePersisted {
+		t.Errorf("Expected the persistent preset to remain, got %+v", preset)
+	}
+	response, err = service.deletePreset(DeletePresetArgs{Name: "prod-quick"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "Deleted persistent preset prod-quick") {
+		t.Errorf("Expected the persistent preset deleted, got %v", err)
+	}
+	if _, err := service.deletePreset(DeletePresetArgs{Name: "prod-quick"}); err == nil {
+		t.Error("Expected deleting an unknown preset to fail")
+	}
+	// Each API key deletes its own presets
+	response, err = service.deletePreset(DeletePresetArgs{Name: "lab", PresetOwner: "key-b"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "Deleted persistent preset lab") {
+		t.Errorf("Expected the other API key's preset deleted, got %v", err)
+	}
+	if preset, ok := service.lookupPreset("key-b", "lab"); ok {
+		t.Errorf("Expected the other API key's preset to be gone, got %+v", preset)
+	}
+}
+
+func TestApplyPreset(t *testing.T) {
+	service := presetTestService()
+	service.presets.Set("", ArgumentPreset{Name: "prod", Arguments: map[string]interface{}{"network_id": "162112", "limit": 20, "intent": "audit"}})
+	service.presets.Set("key-a", ArgumentPreset{Name: "prod", Arguments: map[string]interface{}{"network_id": "555"}})
+
+	arguments := func(params json.RawMessage) map[string]interface{} {
+		var call struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &call); err != nil || call.Name != "list_devices" {
+			t.Fatalf("Expected the call kept, got %s (%v)", params, err)
+		}
+		return call.Arguments
+	}
+
+	params, err := service.applyPreset(context.Background(), json.RawMessage(`{"name": "list_devices", "arguments": {"preset": "prod", "limit": 5, "intent": null}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	args := arguments(params)
+	if args["network_id"] != "162112" || args["limit"] != float64(5) || args["intent"] != "audit" || args["preset"] != nil {
+		t.Errorf("Expected explicit arguments to win over the preset, got %v", args)
+	}
+
+	unchanged := json.RawMessage(`{"name": "list_devices", "arguments": {"limit": 5}}`)
+	if params, _ := service.applyPreset(context.Background(), unchanged); string(params) != string(unchanged) {
+		t.Errorf("Expected calls without a preset untouched, got %s", params)
+	}
+
+	ctx := WithAPIKeyIdentity(context.Background(), &APIKeyIdentity{ID: "key-a"})
+	params, err = service.applyPreset(ctx, json.RawMessage(`{"name": "list_devices", "arguments": {"preset": "prod", "preset_owner": ""}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if args := arguments(params); args["network_id"] != "555" || args["preset_owner"] != "key-a" {
+		t.Errorf("Expected the API key's own preset and owner, got %v", args)
+	}
+
+	if _, err := service.applyPreset(context.Background(), json.RawMessage(`{"name": "list_devices", "arguments": {"preset": "missing"}}`)); err == nil {
+		t.Error("Expected an unknown preset to be rejected")
+	}
+}
+
+func TestPresetTransport(t *testing.T) {
+	service := presetTestService()
+	service.presets.Set("", ArgumentPreset{Name: "prod", Arguments: map[string]interface{}{"network_id": "162112"}})
+	inner := &fakeTransport{}
+	wrapped := service.WithArgumentPresets(inner)
+
+	var delivered []*transport.BaseJsonRpcMessage
+	wrapped.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		delivered = append(delivered, message)
+	})
+	call := func(id int, params string) {
+		inner.handler(context.Background(), transport.NewBaseMessageRequest(&transport.BaseJSONRPCRequest{
+			Id: transport.RequestId(id), Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params)}))
+	}
+
+	call(1, `{"name": "list_devices", "arguments": {"preset": "prod"}}`)
+	if len(delivered) != 1 || !strings.Contains(string(delivered[0].JsonRpcRequest.Params), `"network_id":"162112"`) {
+		t.Fatalf("Expected the preset expanded, got %+v", delivered)
+	}
+
+	call(2, `{"name": "list_devices", "arguments": {"preset": "missing"}}`)
+	if len(delivered) != 1 || len(inner.sent) != 1 || inner.sent[0].JsonRpcError == nil ||
+		inner.sent[0].JsonRpcError.Id != 2 || !strings.Contains(inner.sent[0].JsonRpcError.Error.Message, "unknown preset \"missing\"") {
+		t.Errorf("Expected the call rejected with an error response, got %+v", inner.sent)
+	}
+}
@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// Programmatic API used by pkg/forwardmcp. These methods share the caches, network access policy
+// and memory system with the MCP tools but return structured data instead of tool responses.
+
+// QueryRequest describes an NQE query run through the programmatic API. Exactly one of QueryID
+// and Query must be set.
+type QueryRequest struct {
+	QueryID    string
+	Query      string // NQE source for ad-hoc queries
+	NetworkID  string // Defaults to the configured network
+	SnapshotID string // Defaults to the configured snapshot, or the latest one
+	Parameters map[string]interface{}
+	Limit      int  // Rows per page (default: the configured query limit)
+	Offset     int  // First row to fetch
+	AllResults bool // Page through every row instead of returning a single page
+	Store      bool // Store the rows in the memory system for later SQL analysis
+}
+
+// QueryResult is the outcome of RunQuery
+type QueryResult struct {
+	NetworkID  string
+	SnapshotID string
+	Items      []map[string]interface{}
+	EntityID   string // Memory system entity holding the rows when the request asked to store them
+}
+
+// RunQuery runs an NQE query by ID or source, optionally fetching every page and storing the rows
+func (s *ForwardMCPService) RunQuery(ctx context.Context, req QueryRequest) (*QueryResult, error) {
+	if (req.QueryID == "") == (req.Query == "") {
+		return nil, fmt.Errorf("exactly one of query ID and query source is required")
+	}
+	networkID := s.networkIDOrDefault(req.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	if err := s.checkNetworkAccess(networkID); err != nil {
+		return nil, err
+	}
+	if req.QueryID != "" {
+		if err := s.checkNegativeCache(networkID, req.QueryID); err != nil {
+			return nil, err
+		}
+	}
+
+	result := &QueryResult{NetworkID: networkID, SnapshotID: s.getSnapshotID(req.SnapshotID)}
+	limit := s.getQueryLimit(req.Limit)
+	offset := req.Offset
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		params := &forward.NQEQueryParams{
+			NetworkID:  networkID,
+			SnapshotID: result.SnapshotID,
+			QueryID:    req.QueryID,
+			Query:      req.Query,
+			Parameters: req.Parameters,
+			Options:    &forward.NQEQueryOptions{Limit: limit, Offset: offset},
+		}
+		var page *forward.NQERunResult
+		var err error
+		if req.QueryID != "" {
+			page, err = s.forwardClient.RunNQEQueryByID(params)
+			s.recordQueryOutcome(networkID, req.QueryID, err)
+		} else {
+			page, err = s.forwardClient.RunNQEQueryByString(params)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to run NQE query at offset %d: %w", offset, err)
+		}
+		// Later pages must come from the snapshot the first page used
+		if result.SnapshotID == "" {
+			result.SnapshotID = page.SnapshotID
+		}
+		result.Items = append(result.Items, page.Items...)
+		if !req.AllResults || len(page.Items) < limit {
+			break
+		}
+		offset += limit
+	}
+	if req.QueryID != "" {
+		s.normalizeDeviceFactItems(req.QueryID, result.Items)
+	}
+
+	if req.Store {
+		entityID, err := s.StoreResult(firstNonEmpty(req.QueryID, "adhoc"), networkID, result.SnapshotID, result.Items)
+		if err != nil {
+			return nil, err
+		}
+		result.EntityID = entityID
+	}
+	return result, nil
+}
+
+// PathSearchRequest describes a bulk path search run through the programmatic API
+type PathSearchRequest struct {
+	NetworkID               string
+	SnapshotID              string // Empty or "latest" uses the latest processed snapshot
+	Queries                 []PathSearchQueryArgs
+	Intent                  string
+	MaxCandidates           int
+	MaxResults              int
+	MaxReturnPathResults    int
+	MaxSeconds              int
+	IncludeNetworkFunctions bool
+}
+
+// PathSearchResult is the outcome of SearchPaths, with one response per query
+type PathSearchResult struct {
+	NetworkID  string
+	SnapshotID string
+	Responses  []forward.PathSearchBulkResponse
+	Cached     bool
+}
+
+// SearchPaths runs a bulk path search through the same validation and path cache as the
+// search_paths_bulk tool
+func (s *ForwardMCPService) SearchPaths(ctx context.Context, req PathSearchRequest) (*PathSearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	networkID := s.networkIDOrDefault(req.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	if err := s.checkNetworkAccess(networkID); err != nil {
+		return nil, err
+	}
+	execution, err := s.executePathSearchBulk(SearchPathsBulkArgs{
+		NetworkID:               networkID,
+		SnapshotID:              req.SnapshotID,
+		Queries:                 req.Queries,
+		Intent:                  req.Intent,
+		MaxCandidates:           req.MaxCandidates,
+		MaxResults:              req.MaxResults,
+		MaxReturnPathResults:    req.MaxReturnPathResults,
+		MaxSeconds:              req.MaxSeconds,
+		IncludeNetworkFunctions: req.IncludeNetworkFunctions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PathSearchResult{
+		NetworkID:  execution.networkID,
+		SnapshotID: execution.snapshotID,
+		Responses:  execution.responses,
+		Cached:     execution.cacheStatus != "MISS" && execution.cacheStatus != "disabled",
+	}, nil
+}
+
+// StoreResult stores rows in the memory system as a chunked result entity, reusing the latest
+// version when the rows are unchanged, and returns the entity ID
+func (s *ForwardMCPService) StoreResult(name, networkID, snapshotID string, items []map[string]interface{}) (string, error) {
+	if s.memorySystem == nil {
+		return "", newCodedError(CodeMemoryUnavailable)
+	}
+	if name == "" {
+		return "", fmt.Errorf("result name is required")
+	}
+	result := &forward.NQERunResult{SnapshotID: snapshotID, Items: items}
+	entityID, err := s.memorySystem.StoreNQEResultWithChunking(name, networkID, snapshotID, result, s.chunkTargetBytes())
+	if err != nil {
+		return "", fmt.Errorf("failed to store result: %w", err)
+	}
+	return entityID, nil
+}
+
+// LoadResult returns the rows of a stored result by entity ID or name
+func (s *ForwardMCPService) LoadResult(identifier string) ([]map[string]interface{}, error) {
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	_, rows, err := s.loadStoredResultRows(identifier)
+	return rows, err
+}
+
+// QueryStoredResult runs a sandboxed SQL query against a stored result, given by entity ID or
+// name; the rows are exposed as the table nqe_result
+func (s *ForwardMCPService) QueryStoredResult(identifier, sqlQuery string, limit int) ([]map[string]interface{}, error) {
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	entity, err := s.memorySystem.GetEntity(identifier)
+	if err != nil {
+		return nil, newCodedError(CodeStoredResultNotFound, identifier, err)
+	}
+	rows, _, _, err := s.queryStoredResult(entity.ID, sqlQuery, limit)
+	return rows, err
+}
+
+// SyncQueries brings the NQE query database up to date with the library, fetching only queries
+// whose last commit changed and deprecating queries deleted upstream
+func (s *ForwardMCPService) SyncQueries(ctx context.Context, workers int) (*HydrationDelta, error) {
+	if s.database == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	if workers <= 0 {
+		workers = defaultHydrationWorkers
+	}
+	delta, err := s.database.hydrateDelta(ctx, s.forwardClient, s.logger, workers)
+	if err != nil {
+		return nil, fmt.Errorf("delta hydration failed: %w", err)
+	}
+	return delta, nil
+}
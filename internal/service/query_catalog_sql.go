@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"sync/atomic"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// queryCatalogTables are created in the catalog sandbox. Timestamps are Unix seconds, as in
+// the query database; use datetime(column, 'unixepoch') to read them.
+var queryCatalogTables = []string{
+	`CREATE TABLE queries (
+		instance_id TEXT, query_id TEXT, path TEXT, directory TEXT, intent TEXT, description TEXT,
+		repository TEXT, source_code TEXT, parameter_count INTEGER, parameters TEXT,
+		last_commit_id TEXT, last_commit_author TEXT, last_commit_date INTEGER, last_commit_title TEXT,
+		created_at INTEGER, updated_at INTEGER, deprecated_at INTEGER
+	)`,
+	`CREATE TABLE metadata (instance_id TEXT, key TEXT, value TEXT, updated_at INTEGER)`,
+	`CREATE TABLE instances (
+		instance_id TEXT, current INTEGER, query_count INTEGER, deprecated_count INTEGER,
+		first_sync INTEGER, last_sync INTEGER
+	)`,
+}
+
+// QueryCatalog copies the query database into the queries, metadata and instances tables of
+// a private in-memory database and runs query on a read-only connection. Queries and metadata
+// are limited to the catalog's instance unless allInstances is set.
+func (sb sqlSandbox) QueryCatalog(catalog *NQEDatabase, allInstances bool, query string, maxRows int) ([]map[string]interface{}, bool, error) {
+	statement, err := validateSandboxSQL(query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sb.timeout)
+	defer cancel()
+
+	// Both connections share one private in-memory database; the writer keeps it alive
+	dsn := fmt.Sprintf("file:nqe_catalog_%d?mode=memory&cache=shared", atomic.AddUint64(&sqlSandboxCounter, 1))
+	writer, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create in-memory sqlite db: %w", err)
+	}
+	defer writer.Close()
+	writeConn, err := writer.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open in-memory sqlite db: %w", err)
+	}
+	defer writeConn.Close()
+	if err := sb.loadCatalogTables(ctx, writeConn, catalog, allInstances); err != nil {
+		return nil, false, sb.classifyError(ctx, err)
+	}
+
+	return sb.queryReadOnly(ctx, dsn+"&_query_only=true", statement, maxRows)
+}
+
+// loadCatalogTables creates the catalog tables and copies the query database into them
+func (sb sqlSandbox) loadCatalogTables(ctx context.Context, conn *sql.Conn, catalog *NQEDatabase, allInstances bool) error {
+	// Pages beyond the memory budget fail with "database or disk is full"
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA max_page_count = %d", sb.maxMemoryBytes/4096)); err != nil {
+		return err
+	}
+	for _, table := range queryCatalogTables {
+		if _, err := conn.ExecContext(ctx, table); err != nil {
+			return fmt.Errorf("failed to create catalog table: %w", err)
+		}
+	}
+
+	scope, scopeArgs := "", []interface{}{}
+	if !allInstances {
+		scope, scopeArgs = " WHERE instance_id = ?", []interface{}{catalog.instanceID}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Queries gain their directory and the parameters declared in their source
+	rows, err := catalog.db.QueryContext(ctx, `SELECT instance_id, query_id, path, intent, description, repository, source_code,
+		last_commit_id, last_commit_author, last_commit_date, last_commit_title, created_at, updated_at, deprecated_at
+		FROM nqe_queries`+scope, scopeArgs...)
+	if err != nil {
+		return fmt.Errorf("failed to read queries: %w", err)
+	}
+	defer rows.Close()
+	insert, err := tx.PrepareContext(ctx, "INSERT INTO queries VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for rows.Next() {
+		var instanceID, queryID, queryPath string
+		var intent, description, repository, source, commitID, commitAuthor, commitTitle sql.NullString
+		var commitDate, deprecatedAt sql.NullInt64
+		var createdAt, updatedAt int64
+		if err := rows.Scan(&instanceID, &queryID, &queryPath, &intent, &description, &repository, &source,
+			&commitID, &commitAuthor, &commitDate, &commitTitle, &createdAt, &updatedAt, &deprecatedAt); err != nil {
+			return fmt.Errorf("failed to scan query: %w", err)
+		}
+		parameters := extractNQEParameters(source.String)
+		names := make([]string, len(parameters))
+		for i, parameter := range parameters {
+			names[i] = parameter.Name
+		}
+		if _, err := insert.ExecContext(ctx, instanceID, queryID, queryPath, path.Dir(queryPath), intent, description,
+			repository, source, len(parameters), strings.Join(names, ","), commitID, commitAuthor, commitDate,
+			commitTitle, createdAt, updatedAt, deprecatedAt); err != nil {
+			return fmt.Errorf("failed to insert query: %w", err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read queries: %w", err)
+	}
+
+	if err := copyCatalogRows(ctx, tx, catalog.db, "SELECT instance_id, key, value, updated_at FROM db_metadata"+scope, scopeArgs,
+		"INSERT INTO metadata VALUES (?, ?, ?, ?)"); err != nil {
+		return fmt.Errorf("failed to copy metadata: %w", err)
+	}
+
+	// Every instance is listed so the current one can be compared with the others
+	if err := copyCatalogRows(ctx, tx, catalog.db, `SELECT instance_id, instance_id = ?, COUNT(*),
+		COUNT(deprecated_at), MIN(created_at), MAX(updated_at) FROM nqe_queries GROUP BY instance_id`,
+		[]interface{}{catalog.instanceID}, "INSERT INTO instances VALUES (?, ?, ?, ?, ?, ?)"); err != nil {
+		return fmt.Errorf("failed to copy instances: %w", err)
+	}
+	return tx.Commit()
+}
+
+// copyCatalogRows inserts the rows of a query on the query database unchanged
+func copyCatalogRows(ctx context.Context, tx *sql.Tx, source *sql.DB, query string, args []interface{}, insertStatement string) error {
+	rows, err := source.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+	insert, err := tx.PrepareContext(ctx, insertStatement)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// queryCatalogSQL runs a read-only SQL query over the local NQE query catalog
+func (s *ForwardMCPService) queryCatalogSQL(args QueryCatalogSQLArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("query_catalog_sql", args, nil)
+
+	if s.database == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	if args.SQLQuery == "" {
+		return nil, fmt.Errorf("sql_query is required")
+	}
+
+	resultRows, truncated, err := s.sqlSandbox().QueryCatalog(s.database, args.AllInstances, args.SQLQuery, 100)
+	if err != nil {
+		return nil, err
+	}
+	scope := "instance " + s.database.instanceID
+	if args.AllInstances {
+		scope = "all instances"
+	}
+	resultJSON, _ := json.MarshalIndent(resultRows, "", "  ")
+	response := fmt.Sprintf("SQL query result (%d rows, max 100 shown; query catalog of %s):\n%s", len(resultRows), scope, string(resultJSON))
+	if truncated {
+		response += "\n\nMore rows are available; add filters, aggregation or LIMIT/OFFSET to see them."
+	}
+	return s.streamResponse("query_catalog_sql", response), nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestQueryCatalogSQL(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	db, err := NewNQEDatabase(service.logger, "catalog-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	service.database = db
+
+	if err := db.SaveQueries([]forward.NQEQueryDetail{
+		{QueryID: "Q1", Path: "/L3/Routes", Repository: "org", SourceCode: "@query\nroutes(vrf: String) =\nforeach d in network.devices select d.name"},
+		{QueryID: "Q2", Path: "/L3/BGP Peers", Repository: "org", SourceCode: "foreach d in network.devices select d.name"},
+		{QueryID: "Q3", Path: "/Interfaces/Errors", Repository: "fwd", SourceCode: "@query\nerrors(device: String, threshold: Number) =\nforeach d in network.devices select d.name"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	db.SetMetadata("last_sync", "2026-10-01")
+	other := &NQEDatabase{db: db.db, logger: db.logger, instanceID: "other"}
+	if err := other.SaveQueries([]forward.NQEQueryDetail{{QueryID: "X1", Path: "/Other/Query"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: "SELECT directory, COUNT(*) AS n, SUM(parameter_count > 0) AS parameterized FROM queries GROUP BY directory ORDER BY directory"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"query catalog of instance catalog-test", `"directory": "/Interfaces"`, `"n": 2`, `"parameterized": 1`} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "/Other") {
+		t.Errorf("Expected other instances left out: %s", text)
+	}
+
+	response, err = service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: "SELECT parameters FROM queries WHERE query_id = 'Q3'"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, `"parameters": "device,threshold"`) {
+		t.Errorf("Expected the declared parameters, got %v", err)
+	}
+	response, err = service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: "SELECT value FROM metadata WHERE key = 'last_sync'"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "2026-10-01") {
+		t.Errorf("Expected the metadata, got %v", err)
+	}
+
+	// Every instance is listed, and all_instances brings in their queries
+	response, err = service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: "SELECT instance_id, current, query_count FROM instances ORDER BY instance_id"})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, `"current": 1`) || !strings.Contains(response.Content[0].TextContent.Text, `"instance_id": "other"`) {
+		t.Errorf("Expected both instances, got %v", err)
+	}
+	response, err = service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: "SELECT COUNT(*) AS n FROM queries", AllInstances: true})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, `"n": 4`) {
+		t.Errorf("Expected the queries of every instance, got %v", err)
+	}
+
+	// The sandbox protections of the result SQL tool apply
+	var sandboxErr *SQLSandboxError
+	for statement, code := range map[string]string{
+		"DELETE FROM queries":                      sqlErrStatementNotAllowed,
+		"SELECT 1; DROP TABLE queries":             sqlErrMultipleStatements,
+		"ATTACH DATABASE 'x.db' AS x":              sqlErrAttachBlocked,
+		"SELECT readfile('/etc/passwd')":           sqlErrFunctionBlocked,
+		"WITH q AS (SELECT 1) DELETE FROM queries": sqlErrNotAuthorized,
+	} {
+		_, err := service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: statement})
+		if !errors.As(err, &sandboxErr) || sandboxErr.Code != code {
+			t.Errorf("%s: expected %s, got %v", statement, code, err)
+		}
+	}
+	if queries, _ := db.LoadQueries(); len(queries) != 3 {
+		t.Errorf("Expected the catalog unchanged, got %d queries", len(queries))
+	}
+
+	service.database = nil
+	if _, err := service.queryCatalogSQL(QueryCatalogSQLArgs{SQLQuery: "SELECT 1"}); err == nil {
+		t.Error("Expected an error without the query database")
+	}
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Compatibility verdicts, from best to worst
+const (
+	compatibilityCompatible  = "compatible"
+	compatibilityLikelyEmpty = "likely_empty"
+	compatibilityLikelyFail  = "likely_fail"
+)
+
+var (
+	nqeDataSourcePattern = regexp.MustCompile(`\bnetwork\.(\w+)`)
+	nqeVendorPattern     = regexp.MustCompile(`\bVendor\.(\w+)`)
+	nqeOSPattern         = regexp.MustCompile(`\bOS\.(\w+)`)
+	nqeDeviceTypePattern = regexp.MustCompile(`\bDeviceType\.(\w+)`)
+	nqeImportPattern     = regexp.MustCompile(`(?m)^\s*import\s+"([^"]+)"`)
+	nqeSelectPattern     = regexp.MustCompile(`\bselect\b`)
+	nqeBlockComment      = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	nqeLineComment       = regexp.MustCompile(`(?m)//.*$`)
+	nqeStringLiteral     = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+)
+
+// cloudDataSources are network-level collections only populated for cloud accounts
+var cloudDataSources = map[string]bool{"cloudAccounts": true}
+
+// QueryCompatibilityFinding is a single lint or compatibility observation
+type QueryCompatibilityFinding struct {
+	Severity string `json:"severity"` // "error", "warning" or "info"
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// QueryReferences are the data sources and platform literals a query depends on
+type QueryReferences struct {
+	DataSources []string `json:"data_sources"`
+	Vendors     []string `json:"vendors,omitempty"`
+	OSes        []string `json:"oses,omitempty"`
+	DeviceTypes []string `json:"device_types,omitempty"`
+	Imports     []string `json:"imports,omitempty"`
+}
+
+// QueryCompatibilityReport is the result of checking a query against a network
+type QueryCompatibilityReport struct {
+	QueryID    string                      `json:"query_id,omitempty"`
+	Path       string                      `json:"path,omitempty"`
+	NetworkID  string                      `json:"network_id"`
+	Devices    int                         `json:"devices"`
+	Verdict    string                      `json:"verdict"`
+	References QueryReferences             `json:"references"`
+	Findings   []QueryCompatibilityFinding `json:"findings"`
+}
+
+// networkPlatformProfile summarizes the platforms present in a network
+type networkPlatformProfile struct {
+	devices     int
+	vendors     map[string]int
+	families    map[string]int
+	deviceTypes map[string]int
+}
+
+// checkQueryCompatibility statically inspects a query and compares its references against
+// the platforms in the target network, flagging runs that will likely be empty or fail
+func (s *ForwardMCPService) checkQueryCompatibility(args CheckQueryCompatibilityArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("check_query_compatibility", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	report := &QueryCompatibilityReport{NetworkID: networkID, QueryID: args.QueryID}
+	source := args.Query
+	if source == "" {
+		if args.QueryID == "" {
+			return nil, fmt.Errorf("either query_id or query is required")
+		}
+		detail, err := s.resolveNQEQueryDetail(args.QueryID)
+		if err != nil {
+			return nil, err
+		}
+		if detail.SourceCode == "" {
+			return nil, fmt.Errorf("source code is not available for query %s. Run hydrate_database with enhanced mode, or pass the query source directly", args.QueryID)
+		}
+		source = detail.SourceCode
+		report.Path = detail.Path
+	}
+
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get devices: %w", err)
+	}
+	profile := s.buildPlatformProfile(devices)
+	report.Devices = profile.devices
+
+	report.References = extractQueryReferences(source)
+	report.Findings = append(lintNQESource(source), s.compareQueryToNetwork(report.References, profile)...)
+	report.Verdict = compatibilityVerdict(report.Findings)
+
+	if strings.EqualFold(args.Format, "json") {
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(report))), nil
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(formatQueryCompatibility(report))), nil
+}
+
+// extractQueryReferences collects data sources and platform enum literals from query source,
+// ignoring comments and string literals
+func extractQueryReferences(source string) QueryReferences {
+	code := stripNQEComments(source)
+	unique := func(pattern *regexp.Regexp, text string) []string {
+		seen := make(map[string]bool)
+		var values []string
+		for _, match := range pattern.FindAllStringSubmatch(text, -1) {
+			if !seen[match[1]] {
+				seen[match[1]] = true
+				values = append(values, match[1])
+			}
+		}
+		sort.Strings(values)
+		return values
+	}
+
+	withoutStrings := nqeStringLiteral.ReplaceAllString(code, `""`)
+	return QueryReferences{
+		DataSources: unique(nqeDataSourcePattern, withoutStrings),
+		Vendors:     unique(nqeVendorPattern, withoutStrings),
+		OSes:        unique(nqeOSPattern, withoutStrings),
+		DeviceTypes: unique(nqeDeviceTypePattern, withoutStrings),
+		Imports:     unique(nqeImportPattern, code),
+	}
+}
+
+// stripNQEComments removes block and line comments
+func stripNQEComments(source string) string {
+	return nqeLineComment.ReplaceAllString(nqeBlockComment.ReplaceAllString(source, ""), "")
+}
+
+// lintNQESource reports structural problems that make a query fail regardless of the network
+func lintNQESource(source string) []QueryCompatibilityFinding {
+	var findings []QueryCompatibilityFinding
+	withStrings := stripNQEComments(source)
+	code := nqeStringLiteral.ReplaceAllString(withStrings, `""`)
+
+	if strings.TrimSpace(code) == "" {
+		return []QueryCompatibilityFinding{{Severity: "error", Rule: "empty_query", Message: "Query has no code outside comments"}}
+	}
+
+	if finding := checkNQEBrackets(code); finding != nil {
+		findings = append(findings, *finding)
+	}
+
+	if !nqeSelectPattern.MatchString(code) {
+		findings = append(findings, QueryCompatibilityFinding{Severity: "warning", Rule: "no_select",
+			Message: "Query has no select clause; it may only define functions for other queries"})
+	}
+	for _, match := range nqeImportPattern.FindAllStringSubmatch(withStrings, -1) {
+		findings = append(findings, QueryCompatibilityFinding{Severity: "warning", Rule: "module_import",
+			Message: fmt.Sprintf("Imports %q; queries fail with 'Invalid module path' when library modules move", match[1])})
+	}
+	return findings
+}
+
+// checkNQEBrackets reports the first bracket mismatch in code without comments or strings
+func checkNQEBrackets(code string) *QueryCompatibilityFinding {
+	pairs := map[rune]rune{')': '(', '}': '{', ']': '['}
+	var stack []rune
+	for _, char := range code {
+		switch char {
+		case '(', '{', '[':
+			stack = append(stack, char)
+		case ')', '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[char] {
+				return &QueryCompatibilityFinding{Severity: "error", Rule: "unbalanced_brackets",
+					Message: fmt.Sprintf("Unexpected '%c' without a matching opening bracket", char)}
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if len(stack) > 0 {
+		return &QueryCompatibilityFinding{Severity: "error", Rule: "unbalanced_brackets",
+			Message: fmt.Sprintf("%d unclosed bracket(s), starting with '%c'", len(stack), stack[0])}
+	}
+	return nil
+}
+
+// buildPlatformProfile counts normalized vendors, families and device types
+func (s *ForwardMCPService) buildPlatformProfile(devices []forward.Device) networkPlatformProfile {
+	profile := networkPlatformProfile{
+		devices:     len(devices),
+		vendors:     make(map[string]int),
+		families:    make(map[string]int),
+		deviceTypes: make(map[string]int),
+	}
+	for _, device := range devices {
+		facts := s.vendorNormalizer.NormalizeDevice(device)
+		if facts.Vendor != "" {
+			profile.vendors[facts.Vendor]++
+		}
+		if facts.Family != "" {
+			profile.families[facts.Family]++
+		}
+		if device.Type != "" {
+			profile.deviceTypes[strings.ToUpper(device.Type)]++
+		}
+	}
+	return profile
+}
+
+// compareQueryToNetwork flags platform references that no device in the network satisfies.
+// A query filtering on several vendors only needs one of them to be present.
+func (s *ForwardMCPService) compareQueryToNetwork(refs QueryReferences, profile networkPlatformProfile) []QueryCompatibilityFinding {
+	var findings []QueryCompatibilityFinding
+	if profile.devices == 0 {
+		return append(findings, QueryCompatibilityFinding{Severity: "warning", Rule: "no_devices",
+			Message: "The network has no devices in this snapshot; device-based queries will return no rows"})
+	}
+
+	check := func(rule, kind string, literals []string, present func(string) bool) {
+		if len(literals) == 0 {
+			return
+		}
+		var missing []string
+		for _, literal := range literals {
+			if !present(literal) {
+				missing = append(missing, literal)
+			}
+		}
+		switch {
+		case len(missing) == len(literals):
+			findings = append(findings, QueryCompatibilityFinding{Severity: "warning", Rule: rule,
+				Message: fmt.Sprintf("Query filters on %s %s, but no device in the network matches", kind, strings.Join(literals, ", "))})
+		case len(missing) > 0:
+			findings = append(findings, QueryCompatibilityFinding{Severity: "info", Rule: rule,
+				Message: fmt.Sprintf("No devices match %s %s; those branches will not contribute rows", kind, strings.Join(missing, ", "))})
+		}
+	}
+
+	check("vendor_absent", "vendor", refs.Vendors, func(literal string) bool {
+		vendor := s.vendorNormalizer.Normalize(literal, "", "", "").Vendor
+		return profile.vendors[vendor] > 0 || profile.vendors[strings.ToLower(literal)] > 0
+	})
+	check("os_absent", "OS", refs.OSes, func(literal string) bool {
+		facts := s.vendorNormalizer.Normalize("", literal, "", "")
+		return facts.Family != "" && profile.families[facts.Family] > 0
+	})
+	check("device_type_absent", "device type", refs.DeviceTypes, func(literal string) bool {
+		return profile.deviceTypes[strings.ToUpper(literal)] > 0
+	})
+
+	for _, source := range refs.DataSources {
+		if cloudDataSources[source] && profile.families["cloud"] == 0 {
+			findings = append(findings, QueryCompatibilityFinding{Severity: "warning", Rule: "cloud_absent",
+				Message: fmt.Sprintf("Query reads network.%s, but the network has no cloud accounts", source)})
+		}
+	}
+	return findings
+}
+
+// compatibilityVerdict derives the overall verdict from the findings
+func compatibilityVerdict(findings []QueryCompatibilityFinding) string {
+	verdict := compatibilityCompatible
+	for _, finding := range findings {
+		switch {
+		case finding.Severity == "error":
+			return compatibilityLikelyFail
+		case finding.Severity == "warning" && finding.Rule != "module_import" && finding.Rule != "no_select":
+			verdict = compatibilityLikelyEmpty
+		}
+	}
+	return verdict
+}
+
+// formatQueryCompatibility renders the report as Markdown
+func formatQueryCompatibility(report *QueryCompatibilityReport) string {
+	icons := map[string]string{
+		compatibilityCompatible:  "✅",
+		compatibilityLikelyEmpty: "⚠️",
+		compatibilityLikelyFail:  "❌",
+	}
+	severityIcons := map[string]string{"error": "❌", "warning": "⚠️", "info": "ℹ️"}
+
+	var out strings.Builder
+	out.WriteString("# Query Compatibility Check\n\n")
+	if report.QueryID != "" {
+		out.WriteString(fmt.Sprintf("Query: %s", report.QueryID))
+		if report.Path != "" {
+			out.WriteString(fmt.Sprintf(" (%s)", report.Path))
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString(fmt.Sprintf("Network: %s | Devices: %d\n\n", report.NetworkID, report.Devices))
+	out.WriteString(fmt.Sprintf("**Verdict:** %s %s\n", icons[report.Verdict], report.Verdict))
+
+	refs := report.References
+	out.WriteString("\n## References\n\n")
+	out.WriteString(fmt.Sprintf("- Data sources: %s\n", firstNonEmpty(strings.Join(refs.DataSources, ", "), "none")))
+	for _, line := range []struct {
+		label  string
+		values []string
+	}{{"Vendors", refs.Vendors}, {"OS", refs.OSes}, {"Device types", refs.DeviceTypes}, {"Imports", refs.Imports}} {
+		if len(line.values) > 0 {
+			out.WriteString(fmt.Sprintf("- %s: %s\n", line.label, strings.Join(line.values, ", ")))
+		}
+	}
+
+	out.WriteString("\n## Findings\n\n")
+	if len(report.Findings) == 0 {
+		out.WriteString("No issues found.\n")
+	}
+	for _, finding := range report.Findings {
+		out.WriteString(fmt.Sprintf("- %s %s\n", severityIcons[finding.Severity], finding.Message))
+	}
+	return out.String()
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestExtractQueryReferencesAndLint(t *testing.T) {
+	source := `import "L3/Legacy/Helpers";
+// Vendor.ARISTA is mentioned in a comment only
+foreach device in network.devices
+where device.platform.vendor == Vendor.CISCO || device.platform.os == OS.JUNOS
+where device.platform.deviceType == DeviceType.FIREWALL && device.name != "Vendor.F5"
+select {name: device.name}`
+
+	refs := extractQueryReferences(source)
+	if strings.Join(refs.DataSources, ",") != "devices" || strings.Join(refs.Vendors, ",") != "CISCO" ||
+		strings.Join(refs.OSes, ",") != "JUNOS" || strings.Join(refs.DeviceTypes, ",") != "FIREWALL" {
+		t.Errorf("Unexpected references: %+v", refs)
+	}
+
+	findings := lintNQESource(source)
+	if len(findings) != 1 || findings[0].Rule != "module_import" {
+		t.Errorf("Expected only a module import warning, got %+v", findings)
+	}
+	if findings := lintNQESource("foreach d in network.devices select {name: d.name"); len(findings) != 1 || findings[0].Rule != "unbalanced_brackets" {
+		t.Errorf("Expected unbalanced bracket error, got %+v", findings)
+	}
+	if verdict := compatibilityVerdict(lintNQESource("foreach d in network.devices select {name: d.name))")); verdict != compatibilityLikelyFail {
+		t.Errorf("Expected likely_fail, got %s", verdict)
+	}
+}
+
+func TestCheckQueryCompatibility(t *testing.T) {
+	service := createTestService()
+	service.forwardClient.(*MockForwardClient).devices = []forward.Device{
+		{Name: "core-1", Vendor: "CISCO", Platform: "ios_xe", Type: "ROUTER"},
+		{Name: "leaf-1", Vendor: "ARISTA", Platform: "eos", Type: "SWITCH"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		verdict  string
+		contains string
+	}{
+		{"matching_vendor", "foreach d in network.devices where d.platform.vendor == Vendor.ARISTA select {name: d.name}", compatibilityCompatible, "No issues found"},
+		{"partial_vendor", "foreach d in network.devices where d.platform.vendor in [Vendor.CISCO, Vendor.JUNIPER] select {name: d.name}", compatibilityCompatible, "No devices match vendor JUNIPER"},
+		{"absent_os", "foreach d in network.devices where d.platform.os == OS.PAN_OS select {name: d.name}", compatibilityLikelyEmpty, "no device in the network matches"},
+		{"cloud_only", "foreach a in network.cloudAccounts select {name: a.name}", compatibilityLikelyEmpty, "no cloud accounts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := service.checkQueryCompatibility(CheckQueryCompatibilityArgs{NetworkID: "162112", Query: tt.query})
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			text := response.Content[0].TextContent.Text
+			if !strings.Contains(text, "**Verdict:** ") || !strings.Contains(text, tt.verdict) || !strings.Contains(text, tt.contains) {
+				t.Errorf("Expected verdict %s with %q, got:\n%s", tt.verdict, tt.contains, text)
+			}
+		})
+	}
+
+	if _, err := service.checkQueryCompatibility(CheckQueryCompatibilityArgs{NetworkID: "162112"}); err == nil {
+		t.Error("Expected an error without query_id or query")
+	}
+	// Index-only queries have no source to inspect
+	if _, err := service.checkQueryCompatibility(CheckQueryCompatibilityArgs{NetworkID: "162112", QueryID: "FQ_test_security_query"}); err == nil {
+		t.Error("Expected an error when query source is unavailable")
+	}
+}
@@ -0,0 +1,368 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Kinds of recorded query runs, from the mode of their performance observation
+const (
+	queryRunPage       = "page"
+	queryRunAllResults = "all_results"
+	queryRunOversized  = "oversized"
+)
+
+// Recommendations of estimate_query_cost
+const (
+	costDirect     = "direct"
+	costAllResults = "all_results"
+	costSample     = "sample"
+)
+
+const (
+	maxCostHistoryRuns    = 20
+	defaultRowBytes       = 400           // Serialized row size when no result of the query was stored
+	defaultBatchMillis    = 2000          // Time of one page when the query never ran
+	directResultMaxBytes  = 256 * 1024    // Largest result worth returning in one response
+	sampleRowThreshold    = 250000        // Results larger than this are better sampled or narrowed
+	sampleMillisThreshold = 5 * 60 * 1000 // Fetches slower than this are better sampled or narrowed
+	defaultRowsPerDevice  = 10
+)
+
+// queryRowsPerDevice guesses the rows a query returns per device from words of its path and
+// intent, for queries that never ran
+var queryRowsPerDevice = map[string]int{
+	"route": 500, "routes": 500, "fib": 500, "rib": 500, "mac": 200, "arp": 100,
+	"interface": 30, "interfaces": 30, "acl": 50, "acls": 50, "vlan": 20, "vlans": 20,
+	"bgp": 20, "neighbor": 10, "neighbors": 10, "hardware": 5, "module": 5, "modules": 5,
+	"device": 1, "devices": 1, "os": 1, "config": 1, "configs": 1,
+}
+
+// queryRun is one recorded execution of a query
+type queryRun struct {
+	NetworkID   string
+	Mode        string
+	Rows        int
+	Limit       int // Page limit of a single-page run (0 when unknown)
+	Batches     int
+	Concurrency int
+	DurationMS  int64
+	At          time.Time
+}
+
+// complete reports whether the run saw every row: a full fetch, or a page that was not full
+func (r queryRun) complete() bool {
+	switch r.Mode {
+	case queryRunAllResults:
+		return true
+	case queryRunOversized:
+		return false
+	default:
+		return r.Limit > 0 && r.Rows < r.Limit
+	}
+}
+
+// batchMillis returns the time one page of the run took
+func (r queryRun) batchMillis() int64 {
+	if r.Mode == queryRunAllResults && r.Batches > 0 {
+		return r.DurationMS * int64(max(r.Concurrency, 1)) / int64(r.Batches)
+	}
+	return r.DurationMS
+}
+
+// QueryCostEstimate predicts the result size and runtime of a query on a network
+type QueryCostEstimate struct {
+	QueryID          string `json:"query_id"`
+	NetworkID        string `json:"network_id"`
+	Rows             int    `json:"expected_rows"` // 0 when unknown
+	RowsMin          int    `json:"rows_min,omitempty"`
+	RowsMax          int    `json:"rows_max,omitempty"`
+	RowsLowerBound   bool   `json:"rows_lower_bound,omitempty"` // Rows is only a minimum
+	RowBytes         int    `json:"row_bytes"`
+	Bytes            int64  `json:"expected_bytes"`
+	PageLimit        int    `json:"page_limit"`
+	PageMillis       int64  `json:"page_ms"`
+	Batches          int    `json:"batches"`
+	Concurrency      int    `json:"concurrency"`
+	AllResultsMillis int64  `json:"all_results_ms"`
+	Runs             int    `json:"runs"`
+	Oversized        bool   `json:"oversized,omitempty"` // A direct run was rejected as too large
+	Basis            string `json:"basis"`
+	Confidence       string `json:"confidence"`
+	Recommendation   string `json:"recommendation"`
+	Reason           string `json:"reason"`
+}
+
+// queryRunHistory returns the recorded runs of a query, most recent first
+func (s *ForwardMCPService) queryRunHistory(queryID string) ([]queryRun, error) {
+	query, err := s.memorySystem.getEntityByNameAndType(queryID, "query")
+	if err != nil {
+		return nil, nil // The query never ran
+	}
+	observations, err := s.memorySystem.GetObservations(query.ID, "performance")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load query history: %w", err)
+	}
+	number := func(metadata map[string]interface{}, key string) int64 {
+		value, _ := metadata[key].(float64)
+		return int64(value)
+	}
+	runs := make([]queryRun, 0, len(observations))
+	for _, observation := range observations {
+		metadata := observation.Metadata
+		run := queryRun{
+			NetworkID:   resultValueString(metadata["network_id"]),
+			Mode:        firstNonEmpty(resultValueString(metadata["mode"]), queryRunPage),
+			Rows:        int(number(metadata, "result_count")),
+			Limit:       int(number(metadata, "limit")),
+			Batches:     int(number(metadata, "batches")),
+			Concurrency: int(number(metadata, "concurrency")),
+			DurationMS:  number(metadata, "execution_time_ms"),
+			At:          time.Unix(number(metadata, "timestamp"), 0),
+		}
+		runs = append(runs, run)
+	}
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].At.After(runs[j].At) })
+	if len(runs) > maxCostHistoryRuns {
+		runs = runs[:maxCostHistoryRuns]
+	}
+	return runs, nil
+}
+
+// queryRowBytes returns the average serialized row size of stored results of a query
+func (s *ForwardMCPService) queryRowBytes(queryID string) (int, bool) {
+	results, err := s.memorySystem.SearchEntities("", "query_result", 1000)
+	if err != nil {
+		return 0, false
+	}
+	var bytes, rows int64
+	for _, result := range results {
+		if resultValueString(result.Metadata["query_id"]) != queryID {
+			continue
+		}
+		size, _ := result.Metadata["result_size"].(float64)
+		count, _ := result.Metadata["result_count"].(float64)
+		if count > 0 {
+			bytes, rows = bytes+int64(size), rows+int64(count)
+		}
+	}
+	if rows == 0 {
+		return 0, false
+	}
+	return int(bytes / rows), true
+}
+
+// medianRows returns the median, minimum and maximum row count of runs
+func medianRows(runs []queryRun) (int, int, int) {
+	rows := make([]int, len(runs))
+	for i, run := range runs {
+		rows[i] = run.Rows
+	}
+	sort.Ints(rows)
+	return rows[len(rows)/2], rows[0], rows[len(rows)-1]
+}
+
+// rowsPerDevice guesses the rows per device of a query from its path and intent
+func rowsPerDevice(entry *NQEQueryIndexEntry) (int, string) {
+	if entry == nil {
+		return defaultRowsPerDevice, ""
+	}
+	best, keyword := 0, ""
+	words := strings.FieldsFunc(strings.ToLower(entry.Path+" "+entry.Intent), func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < '0' || r > '9')
+	})
+	for _, word := range words {
+		if rows, ok := queryRowsPerDevice[word]; ok && rows > best {
+			best, keyword = rows, word
+		}
+	}
+	if best == 0 {
+		return defaultRowsPerDevice, ""
+	}
+	return best, keyword
+}
+
+// estimateQueryCostFor predicts rows, size and runtime of a query from its runs on this
+// network, then its complete runs elsewhere, then its metadata and the network's device count
+func (s *ForwardMCPService) estimateQueryCostFor(networkID, queryID string, concurrency int) (*QueryCostEstimate, error) {
+	runs, err := s.queryRunHistory(queryID)
+	if err != nil {
+		return nil, err
+	}
+	estimate := &QueryCostEstimate{
+		QueryID: queryID, NetworkID: networkID, Runs: len(runs),
+		PageLimit: s.getQueryLimit(0), Concurrency: s.nqeFetchConcurrency(concurrency),
+	}
+
+	var localComplete, localPartial, otherComplete, localTimed, otherTimed []queryRun
+	for _, run := range runs {
+		local := run.NetworkID == networkID
+		if run.DurationMS > 0 && local {
+			localTimed = append(localTimed, run)
+		} else if run.DurationMS > 0 {
+			otherTimed = append(otherTimed, run)
+		}
+		switch {
+		case local && run.Mode == queryRunOversized:
+			estimate.Oversized = true
+		case local && run.complete():
+			localComplete = append(localComplete, run)
+		case local:
+			localPartial = append(localPartial, run)
+		case run.complete():
+			otherComplete = append(otherComplete, run)
+		}
+	}
+
+	switch {
+	case len(localComplete) > 0:
+		estimate.Rows, estimate.RowsMin, estimate.RowsMax = medianRows(localComplete)
+		estimate.Basis = fmt.Sprintf("%d complete run(s) on this network", len(localComplete))
+		estimate.Confidence = "medium"
+		if len(localComplete) >= 3 {
+			estimate.Confidence = "high"
+		}
+	case len(localPartial) > 0:
+		_, _, estimate.Rows = medianRows(localPartial)
+		estimate.RowsLowerBound = true
+		estimate.Basis = fmt.Sprintf("%d full page(s) on this network; the result is larger than one page", len(localPartial))
+		estimate.Confidence = "low"
+	case len(otherComplete) > 0:
+		estimate.Rows, estimate.RowsMin, estimate.RowsMax = medianRows(otherComplete)
+		estimate.Basis = fmt.Sprintf("%d complete run(s) on other networks", len(otherComplete))
+		estimate.Confidence = "low"
+	default:
+		var entry *NQEQueryIndexEntry
+		if s.queryIndex != nil {
+			entry, _ = s.queryIndex.GetQueryByID(queryID)
+		}
+		perDevice, keyword := rowsPerDevice(entry)
+		if devices, err := s.getNetworkDevices(networkID, ""); err == nil && len(devices) > 0 {
+			estimate.Rows = perDevice * len(devices)
+			estimate.Basis = fmt.Sprintf("no runs recorded; guessed %d row(s) per device for %d devices", perDevice, len(devices))
+			if keyword != "" {
+				estimate.Basis += fmt.Sprintf(" from %q in the query path", keyword)
+			}
+		} else {
+			estimate.Basis = "no runs recorded and the device count is unknown"
+		}
+		estimate.Confidence = "low"
+	}
+
+	// Size and time of a fetch follow from the row count
+	estimate.RowBytes = defaultRowBytes
+	if rowBytes, ok := s.queryRowBytes(queryID); ok {
+		estimate.RowBytes = rowBytes
+	}
+	estimate.Bytes = int64(estimate.Rows) * int64(estimate.RowBytes)
+	// Timings on this network are preferred; the API's speed varies more between networks
+	timed := localTimed
+	if len(timed) == 0 {
+		timed = otherTimed
+	}
+	estimate.PageMillis = defaultBatchMillis
+	if len(timed) > 0 {
+		var total int64
+		for _, run := range timed {
+			total += run.batchMillis()
+		}
+		estimate.PageMillis = total / int64(len(timed))
+	}
+	estimate.Batches = max(1, (estimate.Rows+estimate.PageLimit-1)/estimate.PageLimit)
+	estimate.AllResultsMillis = int64((estimate.Batches+estimate.Concurrency-1)/estimate.Concurrency) * estimate.PageMillis
+
+	estimate.Recommendation, estimate.Reason = recommendQueryMode(estimate)
+	return estimate, nil
+}
+
+// recommendQueryMode chooses between a direct run, all_results and sampling
+func recommendQueryMode(e *QueryCostEstimate) (string, string) {
+	switch {
+	case e.Oversized:
+		return costAllResults, "a direct run on this network was rejected as too large"
+	case e.Rows == 0 && e.Runs == 0:
+		return costSample, "nothing is known about the result size; run a small page first to measure it"
+	case e.Rows > sampleRowThreshold:
+		return costSample, fmt.Sprintf("about %d rows is too many to analyze in full; sample or narrow the query with parameters", e.Rows)
+	case e.AllResultsMillis > sampleMillisThreshold:
+		return costSample, fmt.Sprintf("fetching every row would take about %s; sample or narrow the query with parameters", time.Duration(e.AllResultsMillis)*time.Millisecond)
+	case e.RowsLowerBound:
+		return costAllResults, "earlier runs filled a whole page, so one page does not hold the result"
+	case e.Rows <= e.PageLimit && e.Bytes <= directResultMaxBytes:
+		return costDirect, "the result fits in one page"
+	case e.Rows <= e.PageLimit:
+		return costAllResults, fmt.Sprintf("the result fits in one page but is about %s; store it and analyze it locally", formatBytes(e.Bytes))
+	default:
+		return costAllResults, fmt.Sprintf("the result spans about %d pages", e.Batches)
+	}
+}
+
+// formatQueryCostEstimate renders an estimate with the call it recommends
+func formatQueryCostEstimate(e *QueryCostEstimate) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Cost estimate: %s on network %s\n\n", e.QueryID, e.NetworkID)
+	switch {
+	case e.Rows == 0 && e.Runs == 0:
+		b.WriteString("- Expected rows: unknown\n")
+	case e.RowsLowerBound:
+		fmt.Fprintf(&b, "- Expected rows: at least %d\n", e.Rows)
+	case e.RowsMax > e.RowsMin:
+		fmt.Fprintf(&b, "- Expected rows: ~%d (%d–%d)\n", e.Rows, e.RowsMin, e.RowsMax)
+	default:
+		fmt.Fprintf(&b, "- Expected rows: ~%d\n", e.Rows)
+	}
+	if e.Rows > 0 {
+		fmt.Fprintf(&b, "- Expected size: ~%s (%d bytes per row)\n", formatBytes(e.Bytes), e.RowBytes)
+	}
+	fmt.Fprintf(&b, "- One page of up to %d rows: ~%s\n", e.PageLimit, time.Duration(e.PageMillis)*time.Millisecond)
+	fmt.Fprintf(&b, "- all_results: %d batch(es), ~%s at concurrency %d\n", e.Batches, time.Duration(e.AllResultsMillis)*time.Millisecond, e.Concurrency)
+	if e.Oversized {
+		b.WriteString("- A direct run on this network exceeded the maximum response length\n")
+	}
+	fmt.Fprintf(&b, "- Basis: %s (confidence: %s)\n\n", e.Basis, e.Confidence)
+
+	fmt.Fprintf(&b, "**Recommendation: %s** — %s\n", e.Recommendation, e.Reason)
+	call := map[string]interface{}{"network_id": e.NetworkID, "query_id": e.QueryID}
+	switch e.Recommendation {
+	case costDirect:
+		call["options"] = map[string]interface{}{"limit": e.PageLimit}
+	case costAllResults:
+		call["all_results"] = true
+	case costSample:
+		call["options"] = map[string]interface{}{"limit": 100}
+	}
+	callJSON, _ := json.Marshal(call)
+	fmt.Fprintf(&b, "Call: run_nqe_query_by_id %s\n", callJSON)
+	return b.String()
+}
+
+// estimateQueryCost predicts the runtime and result size of a query before running it
+func (s *ForwardMCPService) estimateQueryCost(args EstimateQueryCostArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("estimate_query_cost", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	if args.QueryID == "" {
+		return nil, fmt.Errorf("query_id is required")
+	}
+
+	estimate, err := s.estimateQueryCostFor(networkID, args.QueryID, args.Concurrency)
+	if err != nil {
+		return nil, err
+	}
+	if args.Format == "json" {
+		data, _ := json.MarshalIndent(estimate, "", "  ")
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(formatQueryCostEstimate(estimate))), nil
+}
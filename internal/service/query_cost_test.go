@@ -0,0 +1,152 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func pageResult(rows int) *forward.NQERunResult {
+	result := &forward.NQERunResult{}
+	for i := 0; i < rows; i++ {
+		result.Items = append(result.Items, map[string]interface{}{"row": i})
+	}
+	return result
+}
+
+func TestEstimateQueryCostFromHistory(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.apiTracker = NewAPIMemoryTracker(service.memorySystem, service.logger, "test")
+	tracker := service.apiTracker
+
+	// Three complete fetches of 25 pages of 100 rows at concurrency 4
+	for _, rows := range []int{2400, 2500, 2600} {
+		if err := tracker.TrackBatchFetch("FQ_big", "162112", "snap-1", rows, 25, 4, 5*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	estimate, err := service.estimateQueryCostFor("162112", "FQ_big", 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if estimate.Rows != 2500 || estimate.RowsMin != 2400 || estimate.RowsMax != 2600 || estimate.Confidence != "high" {
+		t.Errorf("Unexpected row estimate: %+v", estimate)
+	}
+	if estimate.PageMillis != 800 || estimate.Batches != 25 || estimate.AllResultsMillis != 7*800 {
+		t.Errorf("Expected 800ms pages and 7 rounds of 4 batches, got %+v", estimate)
+	}
+	if estimate.Recommendation != costAllResults {
+		t.Errorf("Expected all_results, got %s (%s)", estimate.Recommendation, estimate.Reason)
+	}
+
+	// A page that was not full saw the whole result
+	tracker.TrackNetworkQueryPage("FQ_small", "162112", "snap-1", pageResult(40), 300*time.Millisecond, 100)
+	if estimate, _ := service.estimateQueryCostFor("162112", "FQ_small", 0); estimate.Rows != 40 || estimate.Recommendation != costDirect || estimate.PageMillis != 300 {
+		t.Errorf("Expected a direct run, got %+v", estimate)
+	}
+
+	// A full page only bounds the result from below
+	tracker.TrackNetworkQueryPage("FQ_full", "162112", "snap-1", pageResult(100), time.Second, 100)
+	if estimate, _ := service.estimateQueryCostFor("162112", "FQ_full", 0); !estimate.RowsLowerBound || estimate.Recommendation != costAllResults {
+		t.Errorf("Expected at least one page and all_results, got %+v", estimate)
+	}
+
+	// Runs on another network count, with low confidence
+	if estimate, _ := service.estimateQueryCostFor("999", "FQ_small", 0); estimate.Rows != 40 || estimate.Confidence != "low" {
+		t.Errorf("Expected the other network's run with low confidence, got %+v", estimate)
+	}
+
+	// A direct run rejected as too large settles it
+	tracker.TrackOversizedResult("FQ_small", "162112", "snap-1")
+	if estimate, _ := service.estimateQueryCostFor("162112", "FQ_small", 0); !estimate.Oversized || estimate.Recommendation != costAllResults {
+		t.Errorf("Expected all_results after an oversized result, got %+v", estimate)
+	}
+}
+
+func TestEstimateQueryCostTool(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.apiTracker = NewAPIMemoryTracker(service.memorySystem, service.logger, "test")
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = pageResult(250)
+
+	// An all_results run records the history the estimate uses
+	if _, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_run", AllResults: true, Options: &NQEQueryOptions{Limit: 100}}); err != nil {
+		t.Fatal(err)
+	}
+	response, err := service.estimateQueryCost(EstimateQueryCostArgs{NetworkID: "162112", QueryID: "FQ_run"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{
+		"## Cost estimate: FQ_run on network 162112",
+		"- Expected rows: ~250",
+		"- all_results: 3 batch(es)",
+		"1 complete run(s) on this network (confidence: medium)",
+		"**Recommendation: all_results** — the result spans about 3 pages",
+		`Call: run_nqe_query_by_id {"all_results":true,"network_id":"162112","query_id":"FQ_run"}`,
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	response, err = service.estimateQueryCost(EstimateQueryCostArgs{NetworkID: "162112", QueryID: "FQ_run", Concurrency: 1, Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var estimate QueryCostEstimate
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &estimate); err != nil || estimate.Concurrency != 1 || estimate.Runs != 1 {
+		t.Errorf("Expected the JSON estimate at concurrency 1, got %+v %v", estimate, err)
+	}
+
+	if _, err := service.estimateQueryCost(EstimateQueryCostArgs{NetworkID: "162112"}); err == nil {
+		t.Error("Expected an error without query_id")
+	}
+}
+
+func TestEstimateQueryCostWithoutHistory(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+
+	devices, _ := service.getNetworkDevices("162112", "")
+	estimate, err := service.estimateQueryCostFor("162112", "FQ_never", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if estimate.Rows != defaultRowsPerDevice*len(devices) || estimate.Confidence != "low" || !strings.Contains(estimate.Basis, "no runs recorded") {
+		t.Errorf("Expected a guess from the device count, got %+v", estimate)
+	}
+
+	perDevice, keyword := rowsPerDevice(&NQEQueryIndexEntry{Path: "/L3/Interfaces/Interface Errors"})
+	if perDevice != 30 || keyword != "interfaces" {
+		t.Errorf("Expected interfaces to guess 30 rows per device, got %d %q", perDevice, keyword)
+	}
+	if perDevice, _ := rowsPerDevice(&NQEQueryIndexEntry{Path: "/L3/Routes/Hosts", Intent: "Find host routes"}); perDevice != 500 {
+		t.Errorf("Expected routes to win over other words, got %d", perDevice)
+	}
+}
+
+func TestRecommendQueryMode(t *testing.T) {
+	tests := []struct {
+		estimate QueryCostEstimate
+		mode     string
+	}{
+		{QueryCostEstimate{}, costSample},
+		{QueryCostEstimate{Runs: 1, Rows: 50, PageLimit: 100, Bytes: 1000}, costDirect},
+		{QueryCostEstimate{Runs: 1, Rows: 50, PageLimit: 100, Bytes: 10 << 20}, costAllResults},
+		{QueryCostEstimate{Runs: 1, Rows: sampleRowThreshold + 1, PageLimit: 1000}, costSample},
+		{QueryCostEstimate{Runs: 1, Rows: 5000, PageLimit: 1000, AllResultsMillis: sampleMillisThreshold + 1}, costSample},
+		{QueryCostEstimate{Runs: 1, Rows: 5000, PageLimit: 1000, Batches: 5}, costAllResults},
+	}
+	for i, test := range tests {
+		if mode, reason := recommendQueryMode(&test.estimate); mode != test.mode {
+			t.Errorf("Case %d: expected %s, got %s (%s)", i, test.mode, mode, reason)
+		}
+	}
+}
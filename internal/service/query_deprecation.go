@@ -0,0 +1,199 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// DeprecatedQuery is a stored query that no longer appears in the latest API listing
+type DeprecatedQuery struct {
+	forward.NQEQueryDetail
+	DeprecatedAt time.Time `json:"deprecated_at"`
+}
+
+// syncDeprecations restores deprecated queries that are listed again and, when the listing is
+// complete, marks stored queries missing from it as deprecated. It returns the paths of both.
+func (db *NQEDatabase) syncDeprecations(listedIDs map[string]bool, complete bool) (deprecated, restored []string, err error) {
+	// An empty listing is far more likely an API problem than an empty library
+	if len(listedIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	rows, err := db.db.Query("SELECT query_id, path, deprecated_at FROM nqe_queries WHERE instance_id = ?", db.instanceID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	var deprecateIDs, restoreIDs []string
+	for rows.Next() {
+		var queryID, path string
+		var deprecatedAt sql.NullInt64
+		if err := rows.Scan(&queryID, &path, &deprecatedAt); err != nil {
+			rows.Close()
+			return nil, nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		switch {
+		case complete && !listedIDs[queryID] && !deprecatedAt.Valid:
+			deprecateIDs = append(deprecateIDs, queryID)
+			deprecated = append(deprecated, path)
+		case listedIDs[queryID] && deprecatedAt.Valid:
+			restoreIDs = append(restoreIDs, queryID)
+			restored = append(restored, path)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	if len(deprecateIDs)+len(restoreIDs) == 0 {
+		return nil, nil, nil
+	}
+
+	tx, err := db.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().Unix()
+	for _, queryID := range deprecateIDs {
+		if _, err := tx.Exec("UPDATE nqe_queries SET deprecated_at = ? WHERE instance_id = ? AND query_id = ?", now, db.instanceID, queryID); err != nil {
+			return nil, nil, fmt.Errorf("failed to deprecate query %s: %w", queryID, err)
+		}
+	}
+	for _, queryID := range restoreIDs {
+		if _, err := tx.Exec("UPDATE nqe_queries SET deprecated_at = NULL WHERE instance_id = ? AND query_id = ?", db.instanceID, queryID); err != nil {
+			return nil, nil, fmt.Errorf("failed to restore query %s: %w", queryID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	sort.Strings(deprecated)
+	sort.Strings(restored)
+	db.logger.Debug("Deprecated %d and restored %d queries", len(deprecated), len(restored))
+	return deprecated, restored, nil
+}
+
+// LoadDeprecatedQueries loads the deprecated queries for this instance, oldest deprecation first
+func (db *NQEDatabase) LoadDeprecatedQueries() ([]DeprecatedQuery, error) {
+	rows, err := db.db.Query(`
+		SELECT query_id, path, intent, description, repository, last_commit_id, deprecated_at
+		FROM nqe_queries
+		WHERE instance_id = ? AND deprecated_at IS NOT NULL
+		ORDER BY deprecated_at, path
+	`, db.instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query database: %w", err)
+	}
+	defer rows.Close()
+
+	var queries []DeprecatedQuery
+	for rows.Next() {
+		var query DeprecatedQuery
+		var intent, description, repository, commitID sql.NullString
+		var deprecatedAt int64
+		if err := rows.Scan(&query.QueryID, &query.Path, &intent, &description, &repository, &commitID, &deprecatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+		query.Intent = intent.String
+		query.Description = description.String
+		query.Repository = repository.String
+		query.LastCommit.ID = commitID.String
+		query.DeprecatedAt = time.Unix(deprecatedAt, 0)
+		queries = append(queries, query)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return queries, nil
+}
+
+// PurgeDeprecatedQueries deletes queries deprecated before cutoff and returns them. With dryRun
+// nothing is deleted.
+func (db *NQEDatabase) PurgeDeprecatedQueries(cutoff time.Time, dryRun bool) ([]DeprecatedQuery, error) {
+	deprecated, err := db.LoadDeprecatedQueries()
+	if err != nil {
+		return nil, err
+	}
+	var purged []DeprecatedQuery
+	var queryIDs []string
+	for _, query := range deprecated {
+		if query.DeprecatedAt.After(cutoff) {
+			continue
+		}
+		purged = append(purged, query)
+		queryIDs = append(queryIDs, query.QueryID)
+	}
+	if !dryRun {
+		if err := db.DeleteQueries(queryIDs); err != nil {
+			return nil, err
+		}
+	}
+	return purged, nil
+}
+
+// matchDeprecatedQueries returns deprecated queries whose path, intent or description contains
+// every search term, for searches that opt in to deprecated results
+func matchDeprecatedQueries(queries []DeprecatedQuery, searchText string, limit int) []DeprecatedQuery {
+	terms := strings.Fields(strings.ToLower(searchText))
+	var matches []DeprecatedQuery
+	for _, query := range queries {
+		text := strings.ToLower(query.Path + " " + query.Intent + " " + query.Description)
+		matched := len(terms) > 0
+		for _, term := range terms {
+			if !strings.Contains(text, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, query)
+			if len(matches) >= limit {
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// purgeDeprecatedQueries deletes queries that hydration marked as deprecated
+func (s *ForwardMCPService) purgeDeprecatedQueries(args PurgeDeprecatedQueriesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("purge_deprecated_queries", args, nil)
+
+	if s.database == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	if args.OlderThanDays < 0 {
+		return nil, fmt.Errorf("older_than_days must not be negative")
+	}
+	cutoff := time.Now().AddDate(0, 0, -args.OlderThanDays)
+	purged, err := s.database.PurgeDeprecatedQueries(cutoff, args.DryRun)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge deprecated queries: %w", err)
+	}
+	if len(purged) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No deprecated queries to purge.")), nil
+	}
+
+	var result strings.Builder
+	if args.DryRun {
+		result.WriteString(fmt.Sprintf("[dry run] Would purge %d deprecated queries:\n\n", len(purged)))
+	} else {
+		result.WriteString(fmt.Sprintf("Purged %d deprecated queries:\n\n", len(purged)))
+	}
+	result.WriteString("| Path | Query ID | Deprecated |\n|------|----------|------------|\n")
+	for _, query := range purged[:min(len(purged), 50)] {
+		result.WriteString(fmt.Sprintf("| %s | `%s` | %s |\n", query.Path, query.QueryID, s.timeFormatter.Format(query.DeprecatedAt)))
+	}
+	if len(purged) > 50 {
+		result.WriteString(fmt.Sprintf("\n... and %d more\n", len(purged)-50))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(result.String())), nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestQueryDeprecationLifecycle(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	db, err := NewNQEDatabase(service.logger, "deprecation-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	service.database = db
+
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeQueries = []forward.NQEQuery{
+		{QueryID: "Q1", Path: "/L3/Routes", Intent: "List routes", Repository: "org"},
+		{QueryID: "Q2", Path: "/L3/Legacy BGP Peers", Intent: "Legacy BGP peers", Repository: "org"},
+		{QueryID: "Q3", Path: "/Forward/Interfaces", Repository: "fwd"},
+	}
+	result, err := db.hydrateEnhancedSharded(context.Background(), mock, service.logger, nil, 2, false)
+	if err != nil || result.Fetched != 3 || len(result.Deprecated) != 0 {
+		t.Fatalf("Expected a clean first hydration, got %+v (%v)", result, err)
+	}
+
+	// Q2 is removed from the library
+	mock.nqeQueries = append(mock.nqeQueries[:1], mock.nqeQueries[2])
+	result, err = db.hydrateEnhancedSharded(context.Background(), mock, service.logger, nil, 2, false)
+	if err != nil || strings.Join(result.Deprecated, ",") != "/L3/Legacy BGP Peers" {
+		t.Fatalf("Expected Q2 to be deprecated, got %+v (%v)", result, err)
+	}
+	if queries, _ := db.LoadQueries(); len(queries) != 2 {
+		t.Errorf("Expected deprecated queries to be excluded from LoadQueries, got %d", len(queries))
+	}
+	stats, _ := db.GetStatistics()
+	if stats["total_queries"] != 2 || stats["deprecated_queries"] != 1 {
+		t.Errorf("Unexpected statistics: %+v", stats)
+	}
+
+	// Searches only show deprecated queries on request
+	search := func(include bool) string {
+		response, err := service.searchNQEQueries(SearchNQEQueriesArgs{Query: "legacy bgp", IncludeDeprecated: include})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		return response.Content[0].TextContent.Text
+	}
+	if text := search(false); strings.Contains(text, "Legacy BGP Peers") {
+		t.Errorf("Expected deprecated queries to be excluded by default: %s", text)
+	}
+	if text := search(true); !strings.Contains(text, "Deprecated queries") || !strings.Contains(text, "`Q2`") {
+		t.Errorf("Expected the deprecated query when requested: %s", text)
+	}
+
+	// Purging respects dry_run and the age filter
+	response, err := service.purgeDeprecatedQueries(PurgeDeprecatedQueriesArgs{DryRun: true})
+	if err != nil || !strings.HasPrefix(response.Content[0].TextContent.Text, "[dry run] Would purge 1") {
+		t.Errorf("Unexpected dry run: %v", err)
+	}
+	response, _ = service.purgeDeprecatedQueries(PurgeDeprecatedQueriesArgs{OlderThanDays: 7})
+	if text := response.Content[0].TextContent.Text; text != "No deprecated queries to purge." {
+		t.Errorf("Expected recently deprecated queries to be kept, got %s", text)
+	}
+	if _, err := service.purgeDeprecatedQueries(PurgeDeprecatedQueriesArgs{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if deprecated, _ := db.LoadDeprecatedQueries(); len(deprecated) != 0 {
+		t.Errorf("Expected the deprecated query to be purged, got %+v", deprecated)
+	}
+}
+
+func TestSyncDeprecationsRestoresListedQueries(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	db, err := NewNQEDatabase(service.logger, "restore-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	db.SaveQueries([]forward.NQEQueryDetail{{QueryID: "Q1", Path: "/A"}, {QueryID: "Q2", Path: "/B"}})
+
+	// A partial listing never deprecates
+	if deprecated, _, _ := db.syncDeprecations(map[string]bool{"Q1": true}, false); len(deprecated) != 0 {
+		t.Errorf("Expected nothing deprecated from a partial listing, got %v", deprecated)
+	}
+	if deprecated, _, _ := db.syncDeprecations(map[string]bool{"Q1": true}, true); strings.Join(deprecated, ",") != "/B" {
+		t.Errorf("Expected /B deprecated, got %v", deprecated)
+	}
+
+	// Saving a deprecated query again does not restore it; being listed does
+	db.SaveQueries([]forward.NQEQueryDetail{{QueryID: "Q2", Path: "/B", Intent: "updated"}})
+	if count, _ := db.GetQueryCount(); count != 1 {
+		t.Errorf("Expected the deprecation to survive a save, got %d active queries", count)
+	}
+	_, restored, err := db.syncDeprecations(map[string]bool{"Q1": true, "Q2": true}, false)
+	if err != nil || strings.Join(restored, ",") != "/B" {
+		t.Errorf("Expected /B restored, got %v (%v)", restored, err)
+	}
+	if count, _ := db.GetQueryCount(); count != 2 {
+		t.Errorf("Expected both queries active, got %d", count)
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// NQEQueryParameter is a parameter declared by an NQE query
+type NQEQueryParameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+var (
+	// nqeQuerySignaturePattern matches an @query definition with parameters, e.g.
+	// "@query\nfindDevice(name: String, vlan: Number) ="
+	nqeQuerySignaturePattern = regexp.MustCompile(`@query\s+\w+\s*\(([^)]*)\)\s*=`)
+	// nqeParamDocPattern matches "@param name description" lines in doc comments
+	nqeParamDocPattern = regexp.MustCompile(`(?m)^\s*(?:\*|//)?\s*@param\s+(\w+)\s*(.*)$`)
+	// nqeDescriptionPattern matches the @description annotation
+	nqeDescriptionPattern = regexp.MustCompile(`(?m)^\s*(?:\*|//)?\s*@description\s+(.+)$`)
+)
+
+// getNQEQuerySource shows the source, description, parameters and last commit of a library query
+func (s *ForwardMCPService) getNQEQuerySource(args GetNQEQuerySourceArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_nqe_query_source", args, nil)
+
+	queryID := strings.TrimSpace(args.QueryID)
+	if queryID == "" {
+		return nil, fmt.Errorf("query_id is required")
+	}
+
+	detail, err := s.resolveNQEQueryDetail(queryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(formatNQEQuerySource(detail))), nil
+}
+
+// resolveNQEQueryDetail looks up a library query and fetches its source on demand when
+// only basic metadata has been hydrated
+func (s *ForwardMCPService) resolveNQEQueryDetail(queryID string) (*forward.NQEQueryDetail, error) {
+	if s.database == nil && s.queryIndex == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	detail := s.lookupNQEQueryDetail(queryID)
+	if detail == nil {
+		return nil, fmt.Errorf("query %s not found in the query database or index. Use search_nqe_queries to find valid query IDs", queryID)
+	}
+
+	if detail.SourceCode == "" && detail.LastCommit.ID != "" {
+		fetched, err := s.forwardClient.GetNQEQueryByCommit(detail.LastCommit.ID, detail.Path, detail.Repository)
+		if err != nil {
+			s.logger.Debug("Failed to fetch source for query %s: %v", queryID, err)
+		} else if fetched != nil {
+			detail.SourceCode = fetched.SourceCode
+			detail.Description = firstNonEmpty(detail.Description, fetched.Description)
+			detail.Intent = firstNonEmpty(detail.Intent, fetched.Intent)
+		}
+	}
+	return detail, nil
+}
+
+// lookupNQEQueryDetail prefers the query database, which stores source and commit info,
+// and falls back to the in-memory query index
+func (s *ForwardMCPService) lookupNQEQueryDetail(queryID string) *forward.NQEQueryDetail {
+	if s.database != nil {
+		if detail, err := s.database.GetQuery(queryID); err == nil {
+			if s.queryIndex != nil && (detail.Description == "" || detail.Intent == "") {
+				if entry, err := s.queryIndex.GetQueryByID(queryID); err == nil {
+					detail.Description = firstNonEmpty(detail.Description, entry.Description)
+					detail.Intent = firstNonEmpty(detail.Intent, entry.Intent)
+				}
+			}
+			return detail
+		}
+	}
+
+	if s.queryIndex == nil {
+		return nil
+	}
+	entry, err := s.queryIndex.GetQueryByID(queryID)
+	if err != nil {
+		return nil
+	}
+	return &forward.NQEQueryDetail{
+		QueryID:     entry.QueryID,
+		Path:        entry.Path,
+		Intent:      entry.Intent,
+		Description: entry.Description,
+		Repository:  entry.Repository,
+	}
+}
+
+// extractNQEParameters returns the parameters declared in an @query signature, annotated with
+// any @param descriptions from the doc comment
+func extractNQEParameters(source string) []NQEQueryParameter {
+	descriptions := make(map[string]string)
+	for _, match := range nqeParamDocPattern.FindAllStringSubmatch(source, -1) {
+		descriptions[match[1]] = strings.TrimSpace(match[2])
+	}
+
+	var parameters []NQEQueryParameter
+	seen := make(map[string]bool)
+	if match := nqeQuerySignaturePattern.FindStringSubmatch(source); match != nil {
+		for _, declaration := range strings.Split(match[1], ",") {
+			name, paramType, _ := strings.Cut(declaration, ":")
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			seen[name] = true
+			parameters = append(parameters, NQEQueryParameter{
+				Name:        name,
+				Type:        strings.TrimSpace(paramType),
+				Description: descriptions[name],
+			})
+		}
+	}
+
+	// Documented parameters missing from the signature are still worth showing
+	for _, match := range nqeParamDocPattern.FindAllStringSubmatch(source, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			parameters = append(parameters, NQEQueryParameter{Name: match[1], Description: descriptions[match[1]]})
+		}
+	}
+	return parameters
+}
+
+// formatNQEQuerySource renders query metadata and source as Markdown
+func formatNQEQuerySource(detail *forward.NQEQueryDetail) string {
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# %s\n\n", firstNonEmpty(detail.Path, detail.QueryID)))
+	report.WriteString(fmt.Sprintf("- **Query ID:** %s\n", detail.QueryID))
+	if detail.Repository != "" {
+		report.WriteString(fmt.Sprintf("- **Repository:** %s\n", detail.Repository))
+	}
+	if detail.Intent != "" {
+		report.WriteString(fmt.Sprintf("- **Intent:** %s\n", detail.Intent))
+	}
+
+	description := detail.Description
+	if description == "" {
+		if match := nqeDescriptionPattern.FindStringSubmatch(detail.SourceCode); match != nil {
+			description = strings.TrimSpace(match[1])
+		}
+	}
+	if description != "" {
+		report.WriteString(fmt.Sprintf("- **Description:** %s\n", description))
+	}
+
+	if commit := detail.LastCommit; commit.ID != "" {
+		report.WriteString(fmt.Sprintf("- **Last commit:** %s", truncateString(commit.ID, 12)))
+		if commit.CommittedAt > 0 {
+			report.WriteString(" on " + epochTime(commit.CommittedAt).UTC().Format("2006-01-02"))
+		}
+		if commit.AuthorEmail != "" {
+			report.WriteString(" by " + commit.AuthorEmail)
+		}
+		if commit.Title != "" {
+			report.WriteString(fmt.Sprintf(" (%s)", commit.Title))
+		}
+		report.WriteString("\n")
+	}
+
+	if parameters := extractNQEParameters(detail.SourceCode); len(parameters) > 0 {
+		report.WriteString("\n## Parameters\n\n| Name | Type | Description |\n|---|---|---|\n")
+		for _, parameter := range parameters {
+			report.WriteString(fmt.Sprintf("| %s | %s | %s |\n", parameter.Name, parameter.Type, parameter.Description))
+		}
+	}
+
+	report.WriteString("\n## Source\n\n")
+	if detail.SourceCode == "" {
+		report.WriteString("Source code is not available for this query. Run hydrate_database with enhanced mode to load query source from the API.\n")
+	} else {
+		report.WriteString("```nqe\n")
+		report.WriteString(strings.TrimRight(detail.SourceCode, "\n"))
+		report.WriteString("\n```\n")
+	}
+
+	report.WriteString(fmt.Sprintf("\nRun it with run_nqe_query_by_id using query_id %s.\n", detail.QueryID))
+	return report.String()
+}
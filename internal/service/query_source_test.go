@@ -0,0 +1,130 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+const testNQESource = `/**
+ * @intent Find devices in a VLAN
+ * @description Lists devices that carry the given VLAN on any interface.
+ * @param vlanId VLAN number to look for
+ * @param site Optional site name
+ */
+@query
+devicesInVlan(vlanId: Number, deviceName: String) =
+foreach device in network.devices
+foreach vlan in device.vlans
+where vlan.vlanId == vlanId
+select {name: device.name}
+`
+
+func TestExtractNQEParameters(t *testing.T) {
+	parameters := extractNQEParameters(testNQESource)
+	expected := []NQEQueryParameter{
+		{Name: "vlanId", Type: "Number", Description: "VLAN number to look for"},
+		{Name: "deviceName", Type: "String"},
+		{Name: "site", Description: "Optional site name"},
+	}
+	if len(parameters) != len(expected) {
+		t.Fatalf("Expected %d parameters, got %+v", len(expected), parameters)
+	}
+	for i := range expected {
+		if parameters[i] != expected[i] {
+			t.Errorf("Parameter %d = %+v; want %+v", i, parameters[i], expected[i])
+		}
+	}
+
+	if parameters := extractNQEParameters("foreach device in network.devices select {name: device.name}"); len(parameters) != 0 {
+		t.Errorf("Expected no parameters, got %+v", parameters)
+	}
+}
+
+func TestGetNQEQuerySource(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	service := createTestService()
+
+	database, err := NewNQEDatabase(service.logger, "test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer database.Close()
+	service.database = database
+
+	if err := database.SaveQueries([]forward.NQEQueryDetail{
+		{
+			QueryID:    "FQ_vlan_devices",
+			Path:       "/L2/VLAN/Devices in VLAN",
+			SourceCode: testNQESource,
+			Repository: "ORG",
+			LastCommit: forward.NQECommitInfo{ID: "0123456789abcdef", AuthorEmail: "neteng@example.com", CommittedAt: 1767225600000, Title: "Add VLAN query"},
+		},
+		{QueryID: "FQ_no_source", Path: "/L3/Basic/Routes", LastCommit: forward.NQECommitInfo{ID: "fedcba9876543210"}},
+	}); err != nil {
+		t.Fatalf("Failed to save queries: %v", err)
+	}
+
+	t.Run("from_database", func(t *testing.T) {
+		response, err := service.getNQEQuerySource(GetNQEQuerySourceArgs{QueryID: "FQ_vlan_devices"})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		text := response.Content[0].TextContent.Text
+		for _, want := range []string{
+			"# /L2/VLAN/Devices in VLAN",
+			"**Description:** Lists devices that carry the given VLAN on any interface.",
+			"**Last commit:** 0123456789ab... on 2026-01-01 by neteng@example.com (Add VLAN query)",
+			"| vlanId | Number | VLAN number to look for |",
+			"```nqe\n/**",
+		} {
+			if !strings.Contains(text, want) {
+				t.Errorf("Expected %q in response, got:\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("fetches_missing_source_by_commit", func(t *testing.T) {
+		response, err := service.getNQEQuerySource(GetNQEQuerySourceArgs{QueryID: "FQ_no_source"})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !strings.Contains(response.Content[0].TextContent.Text, "```nqe\ntest source code\n```") {
+			t.Errorf("Expected source fetched from the API, got:\n%s", response.Content[0].TextContent.Text)
+		}
+	})
+
+	t.Run("falls_back_to_index", func(t *testing.T) {
+		response, err := service.getNQEQuerySource(GetNQEQuerySourceArgs{QueryID: "FQ_test_security_query"})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !strings.Contains(response.Content[0].TextContent.Text, "Source code is not available") {
+			t.Errorf("Expected missing-source note, got:\n%s", response.Content[0].TextContent.Text)
+		}
+	})
+
+	if _, err := service.getNQEQuerySource(GetNQEQuerySourceArgs{QueryID: "FQ_unknown"}); err == nil {
+		t.Error("Expected unknown query to return an error")
+	}
+
+	t.Run("without_query_index", func(t *testing.T) {
+		index := service.queryIndex
+		defer func() { service.queryIndex = index }()
+		service.queryIndex = nil
+
+		if _, err := service.getNQEQuerySource(GetNQEQuerySourceArgs{QueryID: "FQ_vlan_devices"}); err != nil {
+			t.Fatalf("Expected database lookup without an index, got: %v", err)
+		}
+
+		service.database = nil
+		defer func() { service.database = database }()
+		_, err := service.getNQEQuerySource(GetNQEQuerySourceArgs{QueryID: "FQ_vlan_devices"})
+		var coded *CodedError
+		if !errors.As(err, &coded) || coded.Code != CodeNQEDatabaseUnavailable {
+			t.Errorf("Expected %s without a database or index, got: %v", CodeNQEDatabaseUnavailable, err)
+		}
+	})
+}
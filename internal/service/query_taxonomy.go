@@ -0,0 +1,310 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// TaxonomyRule assigns a custom category to a query ID or to every query under a path prefix.
+// Path rules start with "/"; anything else is a query ID.
+type TaxonomyRule struct {
+	Match       string    `json:"match"`
+	Category    string    `json:"category"`
+	Subcategory string    `json:"subcategory,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// IsPath reports whether the rule matches a path prefix rather than a query ID
+func (r TaxonomyRule) IsPath() bool {
+	return strings.HasPrefix(r.Match, "/")
+}
+
+// normalizeTaxonomyMatch trims whitespace and trailing slashes from path prefixes
+func normalizeTaxonomyMatch(match string) string {
+	match = strings.TrimSpace(match)
+	if strings.HasPrefix(match, "/") {
+		if trimmed := strings.TrimRight(match, "/"); trimmed != "" {
+			return trimmed
+		}
+	}
+	return match
+}
+
+// QueryTaxonomy resolves custom categories from taxonomy rules. A nil taxonomy has no rules.
+type QueryTaxonomy struct {
+	byID  map[string]TaxonomyRule
+	paths []TaxonomyRule // Longest prefix first
+}
+
+func newQueryTaxonomy(rules []TaxonomyRule) *QueryTaxonomy {
+	taxonomy := &QueryTaxonomy{byID: make(map[string]TaxonomyRule)}
+	for _, rule := range rules {
+		if rule.IsPath() {
+			taxonomy.paths = append(taxonomy.paths, rule)
+		} else {
+			taxonomy.byID[rule.Match] = rule
+		}
+	}
+	sort.Slice(taxonomy.paths, func(i, j int) bool { return len(taxonomy.paths[i].Match) > len(taxonomy.paths[j].Match) })
+	return taxonomy
+}
+
+// Resolve returns the rule for a query. A query ID rule wins over the longest matching path
+// prefix, and prefixes only match whole path segments.
+func (t *QueryTaxonomy) Resolve(queryID, path string) (TaxonomyRule, bool) {
+	if t == nil {
+		return TaxonomyRule{}, false
+	}
+	if rule, ok := t.byID[queryID]; ok {
+		return rule, true
+	}
+	for _, rule := range t.paths {
+		if rule.Match == "/" || path == rule.Match || strings.HasPrefix(path, rule.Match+"/") {
+			return rule, true
+		}
+	}
+	return TaxonomyRule{}, false
+}
+
+// Categorize returns the custom category and subcategory of a query, or the path-derived ones
+// when no rule applies
+func (t *QueryTaxonomy) Categorize(queryID, path, category, subcategory string) (string, string, bool) {
+	rule, ok := t.Resolve(queryID, path)
+	if !ok {
+		return category, subcategory, false
+	}
+	return rule.Category, rule.Subcategory, true
+}
+
+// MatchesCategory reports whether a query belongs to category (and subcategory when given),
+// either through a custom rule or through its path-derived category
+func (t *QueryTaxonomy) MatchesCategory(entry *NQEQueryIndexEntry, category, subcategory string) bool {
+	matches := func(gotCategory, gotSubcategory string) bool {
+		return (category == "" || strings.EqualFold(gotCategory, category)) &&
+			(subcategory == "" || strings.EqualFold(gotSubcategory, subcategory))
+	}
+	if rule, ok := t.Resolve(entry.QueryID, entry.Path); ok && matches(rule.Category, rule.Subcategory) {
+		return true
+	}
+	return matches(entry.Category, entry.Subcategory)
+}
+
+// maxQueriesPerCategory caps the queries listed under each category when grouping
+const maxQueriesPerCategory = 20
+
+// formatQueriesByCategory groups index entries by their custom or path-derived category
+func formatQueriesByCategory(entries []*NQEQueryIndexEntry, taxonomy *QueryTaxonomy) string {
+	groups := make(map[string][]*NQEQueryIndexEntry)
+	custom := make(map[string]bool)
+	for _, entry := range entries {
+		category, _, isCustom := taxonomy.Categorize(entry.QueryID, entry.Path, entry.Category, entry.Subcategory)
+		if category == "" {
+			category = "Uncategorized"
+		}
+		groups[category] = append(groups[category], entry)
+		custom[category] = custom[category] || isCustom
+	}
+	categories := make([]string, 0, len(groups))
+	for category := range groups {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Found %d NQE queries in %d categories:\n", len(entries), len(categories)))
+	for _, category := range categories {
+		group := groups[category]
+		marker := ""
+		if custom[category] {
+			marker = " (custom)"
+		}
+		result.WriteString(fmt.Sprintf("\n### %s%s (%d)\n", category, marker, len(group)))
+		for _, entry := range group[:min(len(group), maxQueriesPerCategory)] {
+			result.WriteString(fmt.Sprintf("- %s (`%s`)\n", entry.Path, entry.QueryID))
+		}
+		if len(group) > maxQueriesPerCategory {
+			result.WriteString(fmt.Sprintf("- ... and %d more (filter with category=%q)\n", len(group)-maxQueriesPerCategory, category))
+		}
+	}
+	return result.String()
+}
+
+// SaveTaxonomyRule creates or replaces a taxonomy rule for this instance
+func (db *NQEDatabase) SaveTaxonomyRule(rule TaxonomyRule) error {
+	_, err := db.db.Exec(`
+		INSERT OR REPLACE INTO query_taxonomy (instance_id, match, category, subcategory, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, db.instanceID, rule.Match, rule.Category, rule.Subcategory, rule.UpdatedAt.Unix())
+	if err != nil {
+		return fmt.Errorf("failed to save taxonomy rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteTaxonomyRule removes a taxonomy rule and reports whether it existed
+func (db *NQEDatabase) DeleteTaxonomyRule(match string) (bool, error) {
+	result, err := db.db.Exec("DELETE FROM query_taxonomy WHERE instance_id = ? AND match = ?", db.instanceID, match)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete taxonomy rule: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// LoadTaxonomyRules returns the taxonomy rules of this instance ordered by match
+func (db *NQEDatabase) LoadTaxonomyRules() ([]TaxonomyRule, error) {
+	rows, err := db.db.Query(`
+		SELECT match, category, COALESCE(subcategory, ''), updated_at
+		FROM query_taxonomy
+		WHERE instance_id = ?
+		ORDER BY match
+	`, db.instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load taxonomy rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []TaxonomyRule
+	for rows.Next() {
+		var rule TaxonomyRule
+		var updatedAt int64
+		if err := rows.Scan(&rule.Match, &rule.Category, &rule.Subcategory, &updatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan taxonomy rule: %w", err)
+		}
+		rule.UpdatedAt = time.Unix(updatedAt, 0)
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// queryTaxonomy loads the instance's taxonomy; without a database or rules it returns nil, which
+// leaves the path-derived categories in place
+func (s *ForwardMCPService) queryTaxonomy() *QueryTaxonomy {
+	if s.database == nil {
+		return nil
+	}
+	rules, err := s.database.LoadTaxonomyRules()
+	if err != nil {
+		s.logger.Warn("Failed to load query taxonomy: %v", err)
+		return nil
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+	return newQueryTaxonomy(rules)
+}
+
+// countTaxonomyMatches counts the indexed queries a rule currently applies to
+func (s *ForwardMCPService) countTaxonomyMatches(taxonomy *QueryTaxonomy, match string) int {
+	if s.queryIndex == nil {
+		return 0
+	}
+	count := 0
+	for _, entry := range s.queryIndex.Queries() {
+		if rule, ok := taxonomy.Resolve(entry.QueryID, entry.Path); ok && rule.Match == match {
+			count++
+		}
+	}
+	return count
+}
+
+// setQueryCategory maps a query ID or path prefix to a custom category
+func (s *ForwardMCPService) setQueryCategory(args SetQueryCategoryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("set_query_category", args, nil)
+
+	if s.database == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	rule := TaxonomyRule{
+		Match:       normalizeTaxonomyMatch(args.Match),
+		Category:    strings.TrimSpace(args.Category),
+		Subcategory: strings.TrimSpace(args.Subcategory),
+		UpdatedAt:   time.Now(),
+	}
+	if rule.Match == "" {
+		return nil, fmt.Errorf("match is required: a query ID or a path prefix starting with /")
+	}
+	if rule.Category == "" {
+		return nil, fmt.Errorf("category is required")
+	}
+	if err := s.database.SaveTaxonomyRule(rule); err != nil {
+		return nil, err
+	}
+
+	target := fmt.Sprintf("Query %s now belongs", rule.Match)
+	if rule.IsPath() {
+		target = fmt.Sprintf("Queries under %s now belong", rule.Match)
+	}
+	label := rule.Category
+	if rule.Subcategory != "" {
+		label += " / " + rule.Subcategory
+	}
+	matched := s.countTaxonomyMatches(s.queryTaxonomy(), rule.Match)
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("%s to category %s (%d indexed queries). Use category=%q in search_nqe_queries or list_nqe_queries to filter by it.",
+		target, label, matched, rule.Category))), nil
+}
+
+// removeQueryCategory deletes a taxonomy rule
+func (s *ForwardMCPService) removeQueryCategory(args RemoveQueryCategoryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("remove_query_category", args, nil)
+
+	if s.database == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	match := normalizeTaxonomyMatch(args.Match)
+	deleted, err := s.database.DeleteTaxonomyRule(match)
+	if err != nil {
+		return nil, err
+	}
+	if !deleted {
+		return nil, fmt.Errorf("no taxonomy rule for %s", match)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Removed the taxonomy rule for %s; its queries fall back to other rules or their path-derived category.", match))), nil
+}
+
+// listQueryTaxonomy shows the taxonomy rules and how many queries each covers
+func (s *ForwardMCPService) listQueryTaxonomy(args ListQueryTaxonomyArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_query_taxonomy", args, nil)
+
+	if s.database == nil {
+		return nil, newCodedError(CodeNQEDatabaseUnavailable)
+	}
+	rules, err := s.database.LoadTaxonomyRules()
+	if err != nil {
+		return nil, err
+	}
+	taxonomy := newQueryTaxonomy(rules)
+	type ruleSummary struct {
+		TaxonomyRule
+		Queries int `json:"queries"`
+	}
+	summaries := make([]ruleSummary, len(rules))
+	for i, rule := range rules {
+		summaries[i] = ruleSummary{TaxonomyRule: rule, Queries: s.countTaxonomyMatches(taxonomy, rule.Match)}
+	}
+
+	if args.Format == "json" {
+		data, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal taxonomy: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+	if len(rules) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No custom query categories defined. Use set_query_category to map a query ID or path prefix to a category.")), nil
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Query taxonomy (%d rules):\n\n", len(rules)))
+	result.WriteString("| Match | Category | Subcategory | Queries |\n|-------|----------|-------------|---------|\n")
+	for _, summary := range summaries {
+		result.WriteString(fmt.Sprintf("| %s | %s | %s | %d |\n", summary.Match, summary.Category, summary.Subcategory, summary.Queries))
+	}
+	result.WriteString("\nQuery ID rules take precedence over path rules, and the longest matching path prefix wins.\n")
+	return mcp.NewToolResponse(mcp.NewTextContent(result.String())), nil
+}
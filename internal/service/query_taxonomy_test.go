@@ -0,0 +1,89 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQueryTaxonomyResolve(t *testing.T) {
+	taxonomy := newQueryTaxonomy([]TaxonomyRule{
+		{Match: "/L3", Category: "network"},
+		{Match: "/L3/Cloud", Category: "cloud", Subcategory: "aws"},
+		{Match: "Q9", Category: "campus"},
+	})
+	cases := []struct {
+		queryID, path, expected string
+	}{
+		{"Q1", "/L3/Cloud/VPCs", "cloud"},
+		{"Q2", "/L3/Routes", "network"},
+		{"Q3", "/L3Extra/Routes", ""},
+		{"Q9", "/L3/Cloud/VPCs", "campus"},
+	}
+	for _, tc := range cases {
+		rule, ok := taxonomy.Resolve(tc.queryID, tc.path)
+		if rule.Category != tc.expected || ok != (tc.expected != "") {
+			t.Errorf("Resolve(%s, %s) = %q, expected %q", tc.queryID, tc.path, rule.Category, tc.expected)
+		}
+	}
+
+	var none *QueryTaxonomy
+	if category, _, custom := none.Categorize("Q1", "/L3/Routes", "L3", "Routes"); category != "L3" || custom {
+		t.Errorf("Expected a nil taxonomy to keep the path category, got %q", category)
+	}
+	if normalizeTaxonomyMatch(" /L3/Cloud/ ") != "/L3/Cloud" || normalizeTaxonomyMatch("/") != "/" {
+		t.Error("Expected path prefixes to be normalized")
+	}
+}
+
+func TestQueryCategoryTools(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	db, err := NewNQEDatabase(service.logger, "taxonomy-test")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	defer db.Close()
+	service.database = db
+
+	response, err := service.setQueryCategory(SetQueryCategoryArgs{Match: "/Security/", Category: "campus"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Queries under /Security now belong to category campus (1 indexed queries)") {
+		t.Errorf("Unexpected response: %s", text)
+	}
+	if _, err := service.setQueryCategory(SetQueryCategoryArgs{Match: "/L3"}); err == nil {
+		t.Error("Expected an error without a category")
+	}
+
+	// Search and list filters match the custom category
+	search, err := service.searchNQEQueries(SearchNQEQueriesArgs{Query: "access control lists", Category: "campus"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if text := search.Content[0].TextContent.Text; !strings.Contains(text, "ACL Analysis") || !strings.Contains(text, "**Category:** campus") {
+		t.Errorf("Expected the custom category in search results: %s", text)
+	}
+	list, err := service.listNQEQueries(ListNQEQueriesArgs{Category: "campus"})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if text := list.Content[0].TextContent.Text; !strings.Contains(text, "Found 1 NQE queries") {
+		t.Errorf("Expected one query in the custom category: %s", text)
+	}
+	grouped, _ := service.listNQEQueries(ListNQEQueriesArgs{GroupByCategory: true})
+	if text := grouped.Content[0].TextContent.Text; !strings.Contains(text, "### campus (custom) (1)") || !strings.Contains(text, "### L3 (1)") {
+		t.Errorf("Unexpected grouping: %s", text)
+	}
+
+	taxonomy, _ := service.listQueryTaxonomy(ListQueryTaxonomyArgs{})
+	if text := taxonomy.Content[0].TextContent.Text; !strings.Contains(text, "| /Security | campus |  | 1 |") {
+		t.Errorf("Unexpected taxonomy listing: %s", text)
+	}
+	if _, err := service.removeQueryCategory(RemoveQueryCategoryArgs{Match: "/Security"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := service.removeQueryCategory(RemoveQueryCategoryArgs{Match: "/Security"}); err == nil {
+		t.Error("Expected an error removing a missing rule")
+	}
+}
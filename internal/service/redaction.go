@@ -0,0 +1,239 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// redactionRule masks matches of a pattern. When the pattern has a capture group only the
+// first group is masked; keep, when set, leaves matches untouched when it accepts the masked
+// value and the rest of the match after it.
+type redactionRule struct {
+	name    string
+	pattern *regexp.Regexp
+	keep    func(value, after string) bool
+}
+
+// builtinRedactionRules cover secrets commonly found in device configs and NQE results.
+// Keywords may follow JSON-escaped line breaks, and values stop at quotes, backslashes and
+// commas so masking never breaks JSON.
+var builtinRedactionRules = []redactionRule{
+	{name: "snmp_community", pattern: regexp.MustCompile(`(?i)(?:\b|\\[nrt])snmp-server\s+community\s+([^\s"'\\,]+)`)},
+	{name: "password", pattern: regexp.MustCompile(`(?i)(?:\b|\\[nrt])(?:password|passwd|secret|pre-shared-key|key-string|authentication-key)\s+(?:[0-9]\s+)?([^\s"'\\,]+)`)},
+	{name: "aaa_key", pattern: regexp.MustCompile(`(?i)(?:\b|\\[nrt])(?:tacacs-server|radius-server|tacacs|radius)\b[^\n\\"]*?\bkey\s+(?:[0-9]\s+)?([^\s"'\\,]+)`)},
+	{name: "secret_field", pattern: regexp.MustCompile(`(?i)"(?:password|passwd|secret|community|psk|pre_shared_key|api_key)"\s*:\s*"([^"]+)"`)},
+	{name: "password_hash", pattern: regexp.MustCompile(`\$(?:1|5|6|8|9|y|2[aby])\$[./A-Za-z0-9$]{8,}`)},
+	// A whole run of dotted numbers is matched, so versions and OIDs are never read as addresses
+	{name: "public_ipv4", pattern: regexp.MustCompile(`(?:^|\\[nrt]|[^\w.])(\d+(?:\.\d+)+)[\w.]*`), keep: keepNonPublicIPv4},
+}
+
+// keepNonPublicIPv4 leaves dotted numbers alone unless they are exactly a public address:
+// runs of other lengths, runs followed by letters (16.9.3a) and private addresses are kept.
+// A single trailing dot ends a sentence.
+func keepNonPublicIPv4(value, after string) bool {
+	return (after != "" && after != ".") || strings.Count(value, ".") != 3 || isNonPublicIPv4(value)
+}
+
+// isNonPublicIPv4 reports whether a dotted quad is private, reserved or a netmask, none of
+// which identify an organization on the internet
+func isNonPublicIPv4(value string) bool {
+	ip := net.ParseIP(value).To4()
+	if ip == nil {
+		return true
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	// Carrier-grade NAT and documentation ranges
+	for _, cidr := range []string{"100.64.0.0/10", "192.0.2.0/24", "198.51.100.0/24", "203.0.113.0/24", "240.0.0.0/4"} {
+		if _, network, _ := net.ParseCIDR(cidr); network.Contains(ip) {
+			return true
+		}
+	}
+	// Netmasks and wildcard masks are contiguous runs of ones or zeros
+	bits := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+	return isContiguousMask(bits) || isContiguousMask(^bits)
+}
+
+func isContiguousMask(bits uint32) bool {
+	inverted := ^bits
+	return inverted&(inverted+1) == 0
+}
+
+// RedactionStats reports how many values each rule has masked
+type RedactionStats struct {
+	Enabled       bool             `json:"enabled"`
+	Rules         []string         `json:"rules"`
+	TextsScanned  int64            `json:"texts_scanned"`
+	TextsRedacted int64            `json:"texts_redacted"`
+	Redactions    map[string]int64 `json:"redactions"`
+	Allowed       map[string]int64 `json:"allowed"` // Matches left in place by the allowlist
+}
+
+// Redactor masks sensitive values in tool and prompt output
+type Redactor struct {
+	rules     []redactionRule
+	allowlist map[string]map[string]bool
+
+	mutex         sync.Mutex
+	textsScanned  int64
+	textsRedacted int64
+	redactions    map[string]int64
+	allowed       map[string]int64
+}
+
+// NewRedactor creates a redactor with the built-in rules followed by configured patterns.
+// Invalid custom patterns are logged and skipped.
+func NewRedactor(cfg config.RedactionConfig, logger *logger.Logger) *Redactor {
+	r := &Redactor{
+		rules:      append([]redactionRule(nil), builtinRedactionRules...),
+		allowlist:  make(map[string]map[string]bool),
+		redactions: make(map[string]int64),
+		allowed:    make(map[string]int64),
+	}
+	for _, custom := range cfg.Patterns {
+		pattern, err := regexp.Compile(custom.Pattern)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("Skipping invalid redaction pattern %s: %v", custom.Name, err)
+			}
+			continue
+		}
+		r.rules = append(r.rules, redactionRule{name: custom.Name, pattern: pattern})
+	}
+	for rule, values := range cfg.Allowlist {
+		if r.allowlist[rule] == nil {
+			r.allowlist[rule] = make(map[string]bool)
+		}
+		for _, value := range values {
+			r.allowlist[rule][value] = true
+		}
+	}
+	return r
+}
+
+// Redact returns text with every sensitive value replaced by [REDACTED:<rule>]
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+
+	counts := make(map[string]int64)
+	allowed := make(map[string]int64)
+	for _, rule := range r.rules {
+		text = rule.pattern.ReplaceAllStringFunc(text, func(match string) string {
+			start, end := 0, len(match)
+			if rule.pattern.NumSubexp() > 0 {
+				// Re-match to locate the first group within the match
+				if loc := rule.pattern.FindStringSubmatchIndex(match); len(loc) >= 4 && loc[2] >= 0 {
+					start, end = loc[2], loc[3]
+				}
+			}
+			value := match[start:end]
+			if strings.HasPrefix(value, "[REDACTED:") || (rule.keep != nil && rule.keep(value, match[end:])) {
+				return match
+			}
+			if r.allowlist[rule.name][value] || r.allowlist["*"][value] {
+				allowed[rule.name]++
+				return match
+			}
+			counts[rule.name]++
+			return match[:start] + fmt.Sprintf("[REDACTED:%s]", rule.name) + match[end:]
+		})
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.textsScanned++
+	if len(counts) > 0 {
+		r.textsRedacted++
+	}
+	for rule, count := range counts {
+		r.redactions[rule] += count
+	}
+	for rule, count := range allowed {
+		r.allowed[rule] += count
+	}
+	return text
+}
+
+// Stats returns a snapshot of the redaction counters
+func (r *Redactor) Stats() RedactionStats {
+	if r == nil {
+		return RedactionStats{}
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stats := RedactionStats{
+		Enabled:       true,
+		TextsScanned:  r.textsScanned,
+		TextsRedacted: r.textsRedacted,
+		Redactions:    make(map[string]int64, len(r.redactions)),
+		Allowed:       make(map[string]int64, len(r.allowed)),
+	}
+	for _, rule := range r.rules {
+		stats.Rules = append(stats.Rules, rule.name)
+	}
+	for rule, count := range r.redactions {
+		stats.Redactions[rule] = count
+	}
+	for rule, count := range r.allowed {
+		stats.Allowed[rule] = count
+	}
+	return stats
+}
+
+// builtinRedactor applies the built-in rules to outbound data when output redaction is off
+var builtinRedactor = NewRedactor(config.RedactionConfig{}, nil)
+
+// outboundRedactor masks data sent to third-party services such as a chat model: the
+// configured redactor, or the built-in rules when output redaction is off
+func (s *ForwardMCPService) outboundRedactor() *Redactor {
+	if s.redactor != nil {
+		return s.redactor
+	}
+	return builtinRedactor
+}
+
+// redactToolResponse masks sensitive values in every text block of a tool response
+func (s *ForwardMCPService) redactToolResponse(response *mcp.ToolResponse) *mcp.ToolResponse {
+	if s.redactor == nil || response == nil {
+		return response
+	}
+	for _, content := range response.Content {
+		if content != nil && content.TextContent != nil {
+			content.TextContent.Text = s.redactor.Redact(content.TextContent.Text)
+		}
+	}
+	return response
+}
+
+// getRedactionStats reports redaction activity since startup
+func (s *ForwardMCPService) getRedactionStats(args GetRedactionStatsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_redaction_stats", args, nil)
+
+	if s.redactor == nil {
+		return mcp.NewToolResponse(mcp.NewTextContent("Redaction is disabled. Set FORWARD_REDACTION=on to mask SNMP communities, credentials, hashed secrets and public IPs in tool and prompt output.")), nil
+	}
+
+	stats := s.redactor.Stats()
+	var text strings.Builder
+	text.WriteString("## Redaction Statistics\n\n")
+	text.WriteString(fmt.Sprintf("- Texts scanned: %d\n- Texts redacted: %d\n\n", stats.TextsScanned, stats.TextsRedacted))
+	text.WriteString("| Rule | Redacted | Allowlisted |\n|------|----------|-------------|\n")
+	rules := append([]string(nil), stats.Rules...)
+	sort.Strings(rules)
+	for _, rule := range rules {
+		text.WriteString(fmt.Sprintf("| %s | %d | %d |\n", rule, stats.Redactions[rule], stats.Allowed[rule]))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func TestRedactorBuiltinRules(t *testing.T) {
+	redactor := NewRedactor(config.RedactionConfig{}, nil)
+
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"snmp-server community s3cr3t RO", "snmp-server community [REDACTED:snmp_community] RO"},
+		{"username admin password 7 0822455D0A16", "username admin password 7 [REDACTED:password]"},
+		{"enable secret 5 $1$mERr$hx5rVt7rPNoS4wqbXKX7m0", "enable secret 5 [REDACTED:password]"},
+		{"tacacs-server host 10.1.1.1 key 7 094F471A1A0A", "tacacs-server host 10.1.1.1 key 7 [REDACTED:aaa_key]"},
+		{"hash $6$rounds$abcdefghijklmnop", "hash [REDACTED:password_hash]"},
+		{"peer 8.8.4.4 via 10.0.0.1 mask 255.255.255.0 wildcard 0.0.0.255", "peer [REDACTED:public_ipv4] via 10.0.0.1 mask 255.255.255.0 wildcard 0.0.0.255"},
+		{"doc range 192.0.2.10 and 100.64.1.1", "doc range 192.0.2.10 and 100.64.1.1"},
+		{"dns 8.8.8.8,1.1.1.1 or 9.9.9.9.", "dns [REDACTED:public_ipv4],[REDACTED:public_ipv4] or [REDACTED:public_ipv4]."},
+		{"sysObjectID 1.3.6.1.4.1.9.1.1208 version 17.3.4a build 16.9.4.1b", "sysObjectID 1.3.6.1.4.1.9.1.1208 version 17.3.4a build 16.9.4.1b"},
+		{`{"config":"ntp server\n8.8.8.8"}`, `{"config":"ntp server\n[REDACTED:public_ipv4]"}`},
+	}
+	for _, tc := range cases {
+		if got := redactor.Redact(tc.input); got != tc.expected {
+			t.Errorf("Redact(%q) = %q, expected %q", tc.input, got, tc.expected)
+		}
+	}
+}
+
+func TestRedactorKeepsJSONValid(t *testing.T) {
+	redactor := NewRedactor(config.RedactionConfig{}, nil)
+	input := `[{"device":"edge-1","config":"snmp-server community public RO\npassword cisco","password":"hunter2","peer":"1.1.1.1"}]`
+
+	output := redactor.Redact(input)
+	var rows []map[string]string
+	if err := json.Unmarshal([]byte(output), &rows); err != nil {
+		t.Fatalf("Expected redacted JSON to stay valid, got %v: %s", err, output)
+	}
+	if rows[0]["password"] != "[REDACTED:secret_field]" || rows[0]["peer"] != "[REDACTED:public_ipv4]" {
+		t.Errorf("Unexpected redacted row: %v", rows[0])
+	}
+	if rows[0]["config"] != "snmp-server community [REDACTED:snmp_community] RO\npassword [REDACTED:password]" {
+		t.Errorf("Unexpected redacted config: %q", rows[0]["config"])
+	}
+}
+
+func TestRedactorAllowlistAndCustomPatterns(t *testing.T) {
+	redactor := NewRedactor(config.RedactionConfig{
+		Patterns: []config.RedactionPattern{
+			{Name: "asset_tag", Pattern: `ASSET-(\d+)`},
+			{Name: "broken", Pattern: `(`},
+		},
+		Allowlist: map[string][]string{
+			"public_ipv4":    {"8.8.8.8"},
+			"snmp_community": {"public"},
+		},
+	}, nil)
+
+	output := redactor.Redact("dns 8.8.8.8 and 9.9.9.9; snmp-server community public RO; tag ASSET-1234")
+	expected := "dns 8.8.8.8 and [REDACTED:public_ipv4]; snmp-server community public RO; tag ASSET-[REDACTED:asset_tag]"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	stats := redactor.Stats()
+	if stats.TextsScanned != 1 || stats.TextsRedacted != 1 {
+		t.Errorf("Unexpected text counters: %+v", stats)
+	}
+	if stats.Redactions["public_ipv4"] != 1 || stats.Redactions["asset_tag"] != 1 {
+		t.Errorf("Unexpected redaction counts: %v", stats.Redactions)
+	}
+	if stats.Allowed["public_ipv4"] != 1 || stats.Allowed["snmp_community"] != 1 {
+		t.Errorf("Unexpected allowlist counts: %v", stats.Allowed)
+	}
+	for _, rule := range stats.Rules {
+		if rule == "broken" {
+			t.Error("Expected the invalid custom pattern to be skipped")
+		}
+	}
+}
+
+func TestWrapToolHandlerRedactsResponses(t *testing.T) {
+	service := createTestService()
+	handler := func(args GetRedactionStatsArgs) (*mcp.ToolResponse, error) {
+		return mcp.NewToolResponse(mcp.NewTextContent("snmp-server community s3cr3t RO")), nil
+	}
+
+	// Without a redactor output passes through untouched
+	wrapped := service.wrapToolHandler(handler).(func(GetRedactionStatsArgs) (*mcp.ToolResponse, error))
+	response, _ := wrapped(GetRedactionStatsArgs{})
+	if response.Content[0].TextContent.Text != "snmp-server community s3cr3t RO" {
+		t.Errorf("Expected unredacted output, got %q", response.Content[0].TextContent.Text)
+	}
+
+	service.redactor = NewRedactor(config.RedactionConfig{}, nil)
+	wrapped = service.wrapToolHandler(handler).(func(GetRedactionStatsArgs) (*mcp.ToolResponse, error))
+	response, err := wrapped(GetRedactionStatsArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.Content[0].TextContent.Text != "snmp-server community [REDACTED:snmp_community] RO" {
+		t.Errorf("Expected redacted output, got %q", response.Content[0].TextContent.Text)
+	}
+
+	stats, _ := service.getRedactionStats(GetRedactionStatsArgs{})
+	if !contains(stats.Content[0].TextContent.Text, "| snmp_community | 1 | 0 |") {
+		t.Errorf("Unexpected stats: %s", stats.Content[0].TextContent.Text)
+	}
+}
+
+func TestPromptAndStoredRedaction(t *testing.T) {
+	service := createTestService()
+	service.redactor = NewRedactor(config.RedactionConfig{}, nil)
+	defer func() { service.redactor = nil }()
+
+	handler := func(args SecurityPostureWorkflowArgs) (*mcp.PromptResponse, error) {
+		return mcp.NewPromptResponse("test", mcp.NewPromptMessage(mcp.NewTextContent("snmp-server community s3cr3t RO"), mcp.RoleAssistant)), nil
+	}
+	wrapped := service.wrapPromptHandler(handler).(func(SecurityPostureWorkflowArgs) (*mcp.PromptResponse, error))
+	response, err := wrapped(SecurityPostureWorkflowArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text := response.Messages[0].Content.TextContent.Text; text != "snmp-server community [REDACTED:snmp_community] RO" {
+		t.Errorf("Expected redacted prompt output, got %q", text)
+	}
+
+	// Redaction applies to output only; stored data is kept as fetched
+	entity, err := service.memorySystem.CreateEntity("redaction_test_entity", "test", nil)
+	if err != nil {
+		t.Fatalf("Failed to create entity: %v", err)
+	}
+	defer service.memorySystem.DeleteEntity(entity.ID)
+	observation, err := service.memorySystem.AddObservation(entity.ID, `{"password":"hunter2"}`, "nqe_result_chunk", nil)
+	if err != nil {
+		t.Fatalf("Failed to add observation: %v", err)
+	}
+	if observation.Content != `{"password":"hunter2"}` {
+		t.Errorf("Expected stored content unchanged, got %q", observation.Content)
+	}
+}
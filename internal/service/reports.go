@@ -0,0 +1,470 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/logger"
+	"github.com/forward-mcp/internal/report"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	reportResourcePrefix = "forward://reports/"
+	maxReportTableRows   = 500 // Rows per report table; the rest are summarized in a final row
+	maxReportDiffLines   = 200 // Diff lines per device in config diff reports
+)
+
+var reportNameSanitizer = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// SavedReport is a rendered report file
+type SavedReport struct {
+	Name    string    `json:"name"`
+	Path    string    `json:"path"`
+	URI     string    `json:"uri"`
+	Format  string    `json:"format"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modified"`
+}
+
+// ReportStore renders reports through the templates and saves them as files, which are
+// also served as MCP resources once a server is attached
+type ReportStore struct {
+	renderer *report.Renderer
+	dir      string
+	logger   *logger.Logger
+	now      func() time.Time
+
+	mu     sync.Mutex
+	server *mcp.Server
+}
+
+// NewReportStore creates a report store. Without a configured directory reports are saved
+// under the writable data directory.
+func NewReportStore(cfg config.ReportConfig, logger *logger.Logger) (*ReportStore, error) {
+	dir := cfg.Directory
+	if dir == "" {
+		dataDir, err := getWritableDataDirectory()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(dataDir, "reports")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create report directory %s: %w", dir, err)
+	}
+	return &ReportStore{
+		renderer: report.NewRenderer(cfg.TemplateDir, cfg.PDFConverter),
+		dir:      dir,
+		logger:   logger,
+		now:      time.Now,
+	}, nil
+}
+
+// Save renders the report and writes it to <name>-<timestamp>.<ext>
+func (r *ReportStore) Save(doc *report.Report, name, format string) (*SavedReport, error) {
+	format, err := report.NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	content, err := r.renderer.Render(doc, format)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.Trim(reportNameSanitizer.ReplaceAllString(name, "_"), "_.")
+	fileName := fmt.Sprintf("%s-%s%s", firstNonEmpty(name, "report"), r.now().UTC().Format("20060102-150405"), report.Extension(format))
+	path := filepath.Join(r.dir, fileName)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+	saved := &SavedReport{Name: fileName, Path: path, URI: reportResourcePrefix + fileName, Format: format, Size: int64(len(content)), ModTime: r.now()}
+
+	r.mu.Lock()
+	server := r.server
+	r.mu.Unlock()
+	if server != nil {
+		if err := r.registerResource(server, *saved); err != nil {
+			r.logger.Warn("Failed to register report resource %s: %v", saved.URI, err)
+		}
+	}
+	return saved, nil
+}
+
+// List returns the saved reports, newest first
+func (r *ReportStore) List() ([]SavedReport, error) {
+	entries, err := os.ReadDir(r.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reports: %w", err)
+	}
+	var reports []SavedReport
+	for _, entry := range entries {
+		format := reportFormatForFile(entry.Name())
+		if entry.IsDir() || format == "" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		reports = append(reports, SavedReport{
+			Name:    entry.Name(),
+			Path:    filepath.Join(r.dir, entry.Name()),
+			URI:     reportResourcePrefix + entry.Name(),
+			Format:  format,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].ModTime.After(reports[j].ModTime) })
+	return reports, nil
+}
+
+// Prune deletes reports last modified before cutoff (zero deletes every report) and stops
+// serving them. With dryRun the reports are only returned.
+func (r *ReportStore) Prune(cutoff time.Time, dryRun bool) ([]SavedReport, error) {
+	reports, err := r.List()
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	server := r.server
+	r.mu.Unlock()
+
+	var pruned []SavedReport
+	for _, saved := range reports {
+		if !cutoff.IsZero() && !saved.ModTime.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.Remove(saved.Path); err != nil {
+				return pruned, fmt.Errorf("failed to delete report %s: %w", saved.Name, err)
+			}
+			if server != nil {
+				server.DeregisterResource(saved.URI)
+			}
+		}
+		pruned = append(pruned, saved)
+	}
+	return pruned, nil
+}
+
+// Attach registers the saved reports as resources and registers new ones as they are saved
+func (r *ReportStore) Attach(server *mcp.Server) error {
+	r.mu.Lock()
+	r.server = server
+	r.mu.Unlock()
+
+	reports, err := r.List()
+	if err != nil {
+		return err
+	}
+	for _, saved := range reports {
+		if err := r.registerResource(server, saved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reload registers reports copied into the directory outside Save
+func (r *ReportStore) reload() {
+	r.mu.Lock()
+	server := r.server
+	r.mu.Unlock()
+	if server == nil {
+		return
+	}
+	if err := r.Attach(server); err != nil {
+		r.logger.Warn("Failed to register restored reports: %v", err)
+	}
+}
+
+// registerResource serves a report file; the file is read on each request
+func (r *ReportStore) registerResource(server *mcp.Server, saved SavedReport) error {
+	mimeType := reportMIMEType(saved.Format)
+	return server.RegisterResource(saved.URI, saved.Name, fmt.Sprintf("Rendered %s report", saved.Format), mimeType, func() (*mcp.ResourceResponse, error) {
+		content, err := os.ReadFile(saved.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read report %s: %w", saved.Name, err)
+		}
+		if saved.Format == report.FormatPDF {
+			return mcp.NewResourceResponse(mcp.NewBlobEmbeddedResource(saved.URI, base64.StdEncoding.EncodeToString(content), mimeType)), nil
+		}
+		return mcp.NewResourceResponse(mcp.NewTextEmbeddedResource(saved.URI, string(content), mimeType)), nil
+	})
+}
+
+// reportFormatForFile maps a saved report's extension back to its format
+func reportFormatForFile(name string) string {
+	for _, format := range []string{report.FormatMarkdown, report.FormatHTML, report.FormatPDF} {
+		if strings.HasSuffix(name, report.Extension(format)) {
+			return format
+		}
+	}
+	return ""
+}
+
+func reportMIMEType(format string) string {
+	switch format {
+	case report.FormatHTML:
+		return "text/html"
+	case report.FormatPDF:
+		return "application/pdf"
+	}
+	return "text/markdown"
+}
+
+// validateReportFormat checks an optional report_format argument before any work is done
+func validateReportFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	_, err := report.NormalizeFormat(format)
+	return err
+}
+
+// saveReport renders and saves a report when a format was requested and returns a line to
+// append to the tool response. Rendering failures are reported without failing the tool.
+func (s *ForwardMCPService) saveReport(doc *report.Report, name, format string) string {
+	if format == "" {
+		return ""
+	}
+	if s.reports == nil {
+		return "\n⚠️ Report not saved: report storage is not available.\n"
+	}
+	if doc.GeneratedAt.IsZero() {
+		doc.GeneratedAt = time.Now()
+	}
+	saved, err := s.reports.Save(doc, name, format)
+	if err != nil {
+		s.logger.Warn("Failed to save %s report: %v", format, err)
+		return fmt.Sprintf("\n⚠️ Report not saved: %v\n", err)
+	}
+	s.workspaces.recordReport(saved)
+	return fmt.Sprintf("\n📄 %s report saved to %s (resource %s, %s).\n", saved.Format, saved.Path, saved.URI, formatBytes(saved.Size))
+}
+
+// reportTable builds a table, folding rows beyond maxReportTableRows into a final row
+func reportTable(columns []string, rows [][]string) *report.Table {
+	if len(rows) > maxReportTableRows {
+		more := len(rows) - maxReportTableRows
+		rows = append(rows[:maxReportTableRows:maxReportTableRows], []string{fmt.Sprintf("… %d more rows", more)})
+	}
+	return &report.Table{Columns: columns, Rows: rows}
+}
+
+// configDiffReport documents the configuration changes between two snapshots
+func configDiffReport(networkID string, args GetConfigDiffArgs, diffs []DeviceConfigDiff) *report.Report {
+	added, removed := 0, 0
+	rows := make([][]string, 0, len(diffs))
+	for _, diff := range diffs {
+		added += diff.Added
+		removed += diff.Removed
+		rows = append(rows, []string{diff.Device, fmt.Sprintf("+%d", diff.Added), fmt.Sprintf("-%d", diff.Removed)})
+	}
+	doc := &report.Report{
+		Title:    "Configuration Change Report",
+		Subtitle: fmt.Sprintf("Network %s, snapshot %s → %s", networkID, args.BeforeSnapshot, args.AfterSnapshot),
+		Facts: []report.Fact{
+			{Label: "Devices changed", Value: fmt.Sprint(len(diffs))},
+			{Label: "Lines added", Value: fmt.Sprint(added)},
+			{Label: "Lines removed", Value: fmt.Sprint(removed)},
+		},
+	}
+	if len(diffs) == 0 {
+		doc.Sections = append(doc.Sections, report.Section{Title: "Summary", Text: "No configuration changes found.", Status: "ok"})
+		return doc
+	}
+	doc.Sections = append(doc.Sections, report.Section{Title: "Devices", Table: reportTable([]string{"Device", "Lines Added", "Lines Removed"}, rows)})
+	for i, diff := range diffs {
+		if i >= maxReportTableRows {
+			break
+		}
+		lines := diff.Lines
+		section := report.Section{Title: diff.Device}
+		if len(lines) > maxReportDiffLines {
+			section.Text = fmt.Sprintf("Showing the first %d of %d diff lines; use get_config_diff with device=%s for the rest.", maxReportDiffLines, len(lines), diff.Device)
+			lines = lines[:maxReportDiffLines]
+		}
+		section.Code = strings.Join(lines, "\n")
+		doc.Sections = append(doc.Sections, section)
+	}
+	return doc
+}
+
+// resultDiffReport documents the row-level differences between two stored results
+func resultDiffReport(diff *ResultDiff) *report.Report {
+	doc := &report.Report{
+		Title:    "Stored Result Change Report",
+		Subtitle: fmt.Sprintf("%s → %s (key: %s)", diff.EntityA, diff.EntityB, strings.Join(diff.KeyColumns, ", ")),
+		Facts: []report.Fact{
+			{Label: "Added", Value: fmt.Sprint(len(diff.Added))},
+			{Label: "Removed", Value: fmt.Sprint(len(diff.Removed))},
+			{Label: "Changed", Value: fmt.Sprint(len(diff.Changed))},
+			{Label: "Unchanged", Value: fmt.Sprint(diff.Unchanged)},
+		},
+	}
+	if diff.SnapshotA != "" || diff.SnapshotB != "" {
+		doc.Facts = append(doc.Facts, report.Fact{Label: "Snapshots", Value: fmt.Sprintf("%s → %s", firstNonEmpty(diff.SnapshotA, "-"), firstNonEmpty(diff.SnapshotB, "-"))})
+	}
+	for _, group := range []struct {
+		title  string
+		status string
+		rows   []ResultRowDiff
+	}{
+		{"Added", "ok", diff.Added},
+		{"Removed", "fail", diff.Removed},
+		{"Changed", "warn", diff.Changed},
+	} {
+		if len(group.rows) == 0 {
+			continue
+		}
+		rows := make([][]string, 0, len(group.rows))
+		for _, row := range group.rows {
+			detail := MarshalCompactJSONString(row.Row)
+			if row.Type == resultRowChanged {
+				changes := make([]string, len(row.Changes))
+				for j, change := range row.Changes {
+					changes[j] = fmt.Sprintf("%s: %s → %s", change.Column, resultValueString(change.Before), resultValueString(change.After))
+				}
+				detail = strings.Join(changes, "; ")
+			}
+			rows = append(rows, []string{row.Key, detail})
+		}
+		doc.Sections = append(doc.Sections, report.Section{
+			Title:  fmt.Sprintf("%s (%d)", group.title, len(group.rows)),
+			Status: group.status,
+			Table:  reportTable([]string{"Key", "Detail"}, rows),
+		})
+	}
+	if len(doc.Sections) == 0 {
+		doc.Sections = append(doc.Sections, report.Section{Title: "Summary", Text: "No differences found.", Status: "ok"})
+	}
+	return doc
+}
+
+// prefixAnalysisReport documents discovered prefixes and their connectivity per aggregation level
+func prefixAnalysisReport(networkID string, prefixInfo []NetworkPrefixInfo, results []ConnectivityAnalysisResult, prefixLevels []string) *report.Report {
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Connectivity]++
+	}
+	doc := &report.Report{
+		Title:    "Network Prefix Connectivity Report",
+		Subtitle: fmt.Sprintf("Network %s, levels %s", networkID, strings.Join(prefixLevels, ", ")),
+		Facts: []report.Fact{
+			{Label: "Prefixes", Value: fmt.Sprint(len(prefixInfo))},
+			{Label: "Tests", Value: fmt.Sprint(len(results))},
+			{Label: "Connected", Value: fmt.Sprint(counts["CONNECTED"])},
+			{Label: "Partial", Value: fmt.Sprint(counts["PARTIAL"])},
+			{Label: "Disconnected", Value: fmt.Sprint(counts["DISCONNECTED"])},
+		},
+	}
+
+	prefixRows := make([][]string, 0, len(prefixInfo))
+	for _, info := range prefixInfo {
+		prefixRows = append(prefixRows, []string{info.Prefix, info.Location, info.Ownership, strings.Join(info.Subnets, ", ")})
+	}
+	sort.Slice(prefixRows, func(i, j int) bool { return prefixRows[i][0] < prefixRows[j][0] })
+	doc.Sections = append(doc.Sections, report.Section{Title: "Discovered Prefixes", Table: reportTable([]string{"Prefix", "Location", "Owner", "Devices"}, prefixRows)})
+
+	for _, level := range prefixLevels {
+		var rows [][]string
+		status := "ok"
+		for _, result := range results {
+			if result.AggregationLevel != level {
+				continue
+			}
+			if result.Connectivity != "CONNECTED" {
+				status = "warn"
+			}
+			rows = append(rows, []string{result.FromPrefix, result.ToPrefix, result.Connectivity, fmt.Sprint(result.PathCount)})
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		doc.Sections = append(doc.Sections, report.Section{
+			Title:  level + " Aggregation Level",
+			Status: status,
+			Table:  reportTable([]string{"From Prefix", "To Prefix", "Connectivity", "Paths"}, rows),
+		})
+	}
+	return doc
+}
+
+// securityPostureDocument documents a compliance run of the security posture workflow
+func securityPostureDocument(posture *SecurityPostureReport) *report.Report {
+	doc := &report.Report{
+		Title:       "Security Posture Report",
+		Subtitle:    fmt.Sprintf("Network %s, snapshot %s", posture.NetworkID, firstNonEmpty(posture.SnapshotID, "latest")),
+		GeneratedAt: posture.GeneratedAt,
+		Facts: []report.Fact{
+			{Label: "Score", Value: fmt.Sprintf("%.1f/100", posture.Score)},
+			{Label: "Grade", Value: posture.Grade},
+		},
+	}
+
+	sectionRows := make([][]string, 0, len(posture.Sections))
+	for _, section := range posture.Sections {
+		if !section.Assessed {
+			sectionRows = append(sectionRows, []string{section.Name, fmt.Sprintf("%.0f", section.Weight), "-", "not assessed"})
+			continue
+		}
+		sectionRows = append(sectionRows, []string{section.Name, fmt.Sprintf("%.0f", section.Weight), fmt.Sprintf("%.1f", section.Score), section.Detail})
+	}
+	status := "ok"
+	if posture.Score < 60 {
+		status = "fail"
+	} else if posture.Score < 80 {
+		status = "warn"
+	}
+	doc.Sections = append(doc.Sections, report.Section{Title: "Scores", Status: status, Table: reportTable([]string{"Section", "Weight", "Score", "Detail"}, sectionRows)})
+
+	if len(posture.Queries) > 0 {
+		rows := make([][]string, 0, len(posture.Queries))
+		for _, query := range posture.Queries {
+			result := fmt.Sprint(query.Violations)
+			if query.Error != "" {
+				result = "error: " + query.Error
+			}
+			rows = append(rows, []string{query.Path, result})
+		}
+		doc.Sections = append(doc.Sections, report.Section{Title: "Security Queries", Table: reportTable([]string{"Query", "Violations"}, rows)})
+	}
+	if len(posture.Flows) > 0 {
+		rows := make([][]string, 0, len(posture.Flows))
+		for _, flow := range posture.Flows {
+			verdict := "pass"
+			if !flow.Passed {
+				verdict = "fail"
+			}
+			rows = append(rows, []string{flow.Flow, flow.Expect, verdict, flow.Finding})
+		}
+		doc.Sections = append(doc.Sections, report.Section{Title: "Critical Flows", Table: reportTable([]string{"Flow", "Expect", "Result", "Finding"}, rows)})
+	}
+	if len(posture.EOLBuckets) > 0 {
+		buckets := make([]string, 0, len(posture.EOLBuckets))
+		for bucket := range posture.EOLBuckets {
+			buckets = append(buckets, bucket)
+		}
+		sort.Strings(buckets)
+		rows := make([][]string, len(buckets))
+		for i, bucket := range buckets {
+			rows[i] = []string{bucket, fmt.Sprint(posture.EOLBuckets[bucket])}
+		}
+		doc.Sections = append(doc.Sections, report.Section{Title: "End-of-Life Exposure", Table: reportTable([]string{"Window", "Components"}, rows)})
+	}
+	if len(posture.Actions) > 0 {
+		doc.Sections = append(doc.Sections, report.Section{Title: "Recommended Actions", Status: "warn", Items: posture.Actions})
+	}
+	return doc
+}
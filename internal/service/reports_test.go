@@ -0,0 +1,128 @@
+package service
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	"github.com/forward-mcp/internal/report"
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport/stdio"
+)
+
+func newTestReportStore(t *testing.T) *ReportStore {
+	t.Helper()
+	store, err := NewReportStore(config.ReportConfig{Directory: t.TempDir()}, logger.New())
+	if err != nil {
+		t.Fatalf("Failed to create report store: %v", err)
+	}
+	store.now = func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) }
+	return store
+}
+
+func TestReportStore(t *testing.T) {
+	store := newTestReportStore(t)
+	doc := &report.Report{Title: "Weekly", Sections: []report.Section{{Title: "Notes", Text: "all good"}}}
+
+	saved, err := store.Save(doc, "weekly/report 1", "html")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if saved.Name != "weekly_report_1-20260301-120000.html" || saved.URI != reportResourcePrefix+saved.Name {
+		t.Errorf("Unexpected saved report: %+v", saved)
+	}
+	if content, _ := os.ReadFile(saved.Path); !strings.Contains(string(content), "<h1>Weekly</h1>") {
+		t.Errorf("Expected rendered HTML on disk, got %s", content)
+	}
+	if _, err := store.Save(doc, "weekly", "docx"); err == nil {
+		t.Error("Expected an error for an unsupported format")
+	}
+
+	// Existing reports are registered on attach and new ones as they are saved
+	server := mcp.NewServer(stdio.NewStdioServerTransport())
+	if err := store.Attach(server); err != nil {
+		t.Fatalf("Failed to attach: %v", err)
+	}
+	if !server.CheckResourceRegistered(saved.URI) {
+		t.Errorf("Expected %s to be registered", saved.URI)
+	}
+	markdown, err := store.Save(doc, "weekly", "md")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !server.CheckResourceRegistered(markdown.URI) {
+		t.Errorf("Expected %s to be registered after saving", markdown.URI)
+	}
+	if reports, _ := store.List(); len(reports) != 2 {
+		t.Errorf("Expected two saved reports, got %+v", reports)
+	}
+}
+
+func TestDiffStoredResultsReport(t *testing.T) {
+	service := createTestService()
+	service.reports = newTestReportStore(t)
+	store := func(snapshot string, items []map[string]interface{}) string {
+		entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_report_test", "162112", snapshot, &forward.NQERunResult{Items: items}, 1)
+		if err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+		return entityID
+	}
+	entityA := store("snap-report-a", []map[string]interface{}{{"name": "core-1", "os": "15.2"}})
+	entityB := store("snap-report-b", []map[string]interface{}{{"name": "core-1", "os": "15.9"}, {"name": "leaf-1", "os": "4.30"}})
+
+	response, err := service.diffStoredResults(DiffStoredResultsArgs{EntityA: entityA, EntityB: entityB, KeyColumns: []string{"name"}, ReportFormat: "markdown"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "markdown report saved to") {
+		t.Fatalf("Expected a saved report note, got: %s", text)
+	}
+	reports, _ := service.reports.List()
+	if len(reports) != 1 {
+		t.Fatalf("Expected one saved report, got %+v", reports)
+	}
+	content, _ := os.ReadFile(reports[0].Path)
+	for _, expected := range []string{"# Stored Result Change Report", "## Added (1)", "| leaf-1 |", "os: 15.2 → 15.9"} {
+		if !strings.Contains(string(content), expected) {
+			t.Errorf("Expected %q in report, got:\n%s", expected, content)
+		}
+	}
+
+	if _, err := service.diffStoredResults(DiffStoredResultsArgs{EntityA: entityA, EntityB: entityB, KeyColumns: []string{"name"}, ReportFormat: "xlsx"}); err == nil {
+		t.Error("Expected an error for an unsupported report format")
+	}
+}
+
+func TestReportDocuments(t *testing.T) {
+	diffs := []DeviceConfigDiff{{Device: "core-1", Added: 1, Removed: 1, Lines: []string{"- ntp server 10.0.0.1", "+ ntp server 10.0.0.2"}}}
+	doc := configDiffReport("162112", GetConfigDiffArgs{BeforeSnapshot: "s1", AfterSnapshot: "s2"}, diffs)
+	if len(doc.Sections) != 2 || doc.Sections[1].Code != "- ntp server 10.0.0.1\n+ ntp server 10.0.0.2" {
+		t.Errorf("Unexpected config diff report: %+v", doc.Sections)
+	}
+
+	results := []ConnectivityAnalysisResult{
+		{FromPrefix: "10.1.0.0/16", ToPrefix: "10.2.0.0/16", Connectivity: "CONNECTED", AggregationLevel: "/16", PathCount: 2},
+		{FromPrefix: "10.2.0.0/16", ToPrefix: "10.1.0.0/16", Connectivity: "DISCONNECTED", AggregationLevel: "/16"},
+	}
+	doc = prefixAnalysisReport("162112", []NetworkPrefixInfo{{Prefix: "10.1.0.0/16", Location: "hq", Subnets: []string{"core-1"}}}, results, []string{"/8", "/16"})
+	if len(doc.Sections) != 2 || doc.Sections[1].Status != "warn" || len(doc.Sections[1].Table.Rows) != 2 {
+		t.Errorf("Unexpected prefix report: %+v", doc.Sections)
+	}
+
+	posture := buildSecurityPostureReport([]SecurityQueryFinding{{Path: "/L3/Security/Telnet", Violations: 3}}, nil, nil, time.Now())
+	doc = securityPostureDocument(posture)
+	if doc.Sections[0].Status != "fail" || doc.Sections[len(doc.Sections)-1].Title != "Recommended Actions" {
+		t.Errorf("Unexpected posture report: %+v", doc.Sections)
+	}
+
+	rows := make([][]string, maxReportTableRows+5)
+	if table := reportTable([]string{"a"}, rows); len(table.Rows) != maxReportTableRows+1 || table.Rows[maxReportTableRows][0] != "… 5 more rows" {
+		t.Errorf("Expected the table to be capped, got %d rows", len(table.Rows))
+	}
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	// defaultStreamBlockSize is the target size in bytes of one content block
+	defaultStreamBlockSize = 64 * 1024
+	// defaultStreamMaxBlocks is how many content blocks one tool response carries before
+	// the rest is held behind a continuation token
+	defaultStreamMaxBlocks = 8
+	// defaultContinuationTTL is how long undelivered blocks are kept
+	defaultContinuationTTL = 30 * time.Minute
+)
+
+// ResponseStreamer accumulates tool output and splits it into content blocks of roughly
+// blockSize bytes. Blocks break at line boundaries where possible so tables and CSV rows
+// are never cut in half; a single line longer than a block is split on a rune boundary.
+type ResponseStreamer struct {
+	blockSize int
+	blocks    []string
+	current   strings.Builder
+}
+
+// NewResponseStreamer creates a streamer; non-positive sizes use the default block size
+func NewResponseStreamer(blockSize int) *ResponseStreamer {
+	if blockSize <= 0 {
+		blockSize = defaultStreamBlockSize
+	}
+	return &ResponseStreamer{blockSize: blockSize}
+}
+
+// Write implements io.Writer so encoders can write straight into the streamer
+func (w *ResponseStreamer) Write(p []byte) (int, error) {
+	return w.WriteString(string(p))
+}
+
+// WriteString appends text, closing blocks as they fill up
+func (w *ResponseStreamer) WriteString(text string) (int, error) {
+	written := len(text)
+	for text != "" {
+		line, rest, found := strings.Cut(text, "\n")
+		if found {
+			line += "\n"
+		}
+		text = rest
+
+		if w.current.Len() > 0 && w.current.Len()+len(line) > w.blockSize {
+			w.flush()
+		}
+		for len(line) > w.blockSize {
+			cut := w.blockSize
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = w.blockSize
+			}
+			w.current.WriteString(line[:cut])
+			w.flush()
+			line = line[cut:]
+		}
+		w.current.WriteString(line)
+	}
+	return written, nil
+}
+
+func (w *ResponseStreamer) flush() {
+	if w.current.Len() > 0 {
+		w.blocks = append(w.blocks, w.current.String())
+		w.current.Reset()
+	}
+}
+
+// Blocks closes the current block and returns all blocks written so far
+func (w *ResponseStreamer) Blocks() []string {
+	w.flush()
+	return w.blocks
+}
+
+// continuation holds the undelivered blocks of a streamed response
+type continuation struct {
+	Tool      string
+	Blocks    []string
+	Delivered int
+	ExpiresAt time.Time
+}
+
+// ContinuationStore keeps the remaining blocks of large responses so clients can fetch
+// them with continue_response instead of receiving one giant response
+type ContinuationStore struct {
+	entries   map[string]*continuation
+	mutex     sync.Mutex
+	maxBlocks int
+	ttl       time.Duration
+	now       func() time.Time
+}
+
+// NewContinuationStore creates a store that delivers maxBlocks blocks per response
+func NewContinuationStore(maxBlocks int, ttl time.Duration) *ContinuationStore {
+	if maxBlocks <= 0 {
+		maxBlocks = defaultStreamMaxBlocks
+	}
+	if ttl <= 0 {
+		ttl = defaultContinuationTTL
+	}
+	return &ContinuationStore{
+		entries:   make(map[string]*continuation),
+		maxBlocks: maxBlocks,
+		ttl:       ttl,
+		now:       time.Now,
+	}
+}
+
+// Start returns the first batch of blocks and, when more remain, a continuation token
+func (c *ContinuationStore) Start(tool string, blocks []string) ([]string, string) {
+	if len(blocks) <= c.maxBlocks {
+		return blocks, ""
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.removeExpired()
+
+	token := fmt.Sprintf("cont_%d", c.now().UnixNano())
+	c.entries[token] = &continuation{
+		Tool:      tool,
+		Blocks:    blocks,
+		Delivered: c.maxBlocks,
+		ExpiresAt: c.now().Add(c.ttl),
+	}
+	return blocks[:c.maxBlocks], token
+}
+
+// Next returns the next batch for a token, the remaining block count and the originating tool.
+// The token is released once the last block has been delivered.
+func (c *ContinuationStore) Next(token string) ([]string, int, string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.removeExpired()
+
+	entry, ok := c.entries[token]
+	if !ok {
+		return nil, 0, "", fmt.Errorf("continuation token %s not found or expired; rerun the original tool", token)
+	}
+
+	end := entry.Delivered + c.maxBlocks
+	if end > len(entry.Blocks) {
+		end = len(entry.Blocks)
+	}
+	batch := entry.Blocks[entry.Delivered:end]
+	entry.Delivered = end
+	entry.ExpiresAt = c.now().Add(c.ttl)
+
+	remaining := len(entry.Blocks) - end
+	if remaining == 0 {
+		delete(c.entries, token)
+	}
+	return batch, remaining, entry.Tool, nil
+}
+
+// removeExpired drops abandoned continuations; callers must hold the mutex
+func (c *ContinuationStore) removeExpired() {
+	now := c.now()
+	for token, entry := range c.entries {
+		if now.After(entry.ExpiresAt) {
+			delete(c.entries, token)
+		}
+	}
+}
+
+// streamResponse splits large tool output into multiple content blocks. Output beyond the
+// per-response block limit is held behind a continuation token for continue_response.
+func (s *ForwardMCPService) streamResponse(tool, text string) *mcp.ToolResponse {
+	streamer := NewResponseStreamer(defaultStreamBlockSize)
+	streamer.WriteString(text)
+	blocks := streamer.Blocks()
+	if len(blocks) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(text))
+	}
+
+	token := ""
+	total := len(blocks)
+	if s.continuations != nil {
+		blocks, token = s.continuations.Start(tool, blocks)
+	}
+	return continuationResponse(blocks, token, total-len(blocks))
+}
+
+// continueResponse returns the next batch of blocks for a continuation token
+func (s *ForwardMCPService) continueResponse(args ContinueResponseArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("continue_response", args, nil)
+
+	token := strings.TrimSpace(args.Token)
+	if token == "" {
+		return nil, fmt.Errorf("token is required")
+	}
+	if s.continuations == nil {
+		return nil, fmt.Errorf("response continuation is not enabled")
+	}
+
+	blocks, remaining, tool, err := s.continuations.Next(token)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Debug("Continuing %s response: %d blocks delivered, %d remaining", tool, len(blocks), remaining)
+	if remaining == 0 {
+		token = ""
+	}
+	return continuationResponse(blocks, token, remaining), nil
+}
+
+// continuationResponse builds a tool response from blocks, appending a continuation note
+func continuationResponse(blocks []string, token string, remaining int) *mcp.ToolResponse {
+	contents := make([]*mcp.Content, 0, len(blocks)+1)
+	for _, block := range blocks {
+		contents = append(contents, mcp.NewTextContent(block))
+	}
+	if token != "" {
+		contents = append(contents, mcp.NewTextContent(fmt.Sprintf(
+			"Output continues: %d more blocks available. Call continue_response with token %s to fetch the next part.",
+			remaining, token)))
+	}
+	return mcp.NewToolResponse(contents...)
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestResponseStreamerSplitsAtLineBoundaries(t *testing.T) {
+	streamer := NewResponseStreamer(20)
+	streamer.WriteString("device,site\ncore-1,nyc\nedge-22,lon\n")
+	streamer.WriteString(strings.Repeat("é", 15) + "\n")
+
+	blocks := streamer.Blocks()
+	if strings.Join(blocks, "") != "device,site\ncore-1,nyc\nedge-22,lon\n"+strings.Repeat("é", 15)+"\n" {
+		t.Fatalf("Blocks do not reassemble to the original text: %q", blocks)
+	}
+	if blocks[0] != "device,site\n" || blocks[1] != "core-1,nyc\n" || blocks[2] != "edge-22,lon\n" {
+		t.Errorf("Expected rows to stay whole, got %q", blocks)
+	}
+	for _, block := range blocks {
+		if len(block) > 20 {
+			t.Errorf("Block exceeds size limit: %q", block)
+		}
+		if !utf8.ValidString(block) {
+			t.Errorf("Block splits a rune: %q", block)
+		}
+	}
+}
+
+func TestContinuationStore(t *testing.T) {
+	store := NewContinuationStore(2, time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	if first, token := store.Start("tool", []string{"a", "b"}); token != "" || len(first) != 2 {
+		t.Errorf("Expected small responses to need no token, got %v %q", first, token)
+	}
+
+	first, token := store.Start("forecast_eol_exposure", []string{"a", "b", "c", "d", "e"})
+	if token == "" || strings.Join(first, "") != "ab" {
+		t.Fatalf("Expected first batch ab with a token, got %v %q", first, token)
+	}
+	batch, remaining, tool, err := store.Next(token)
+	if err != nil || strings.Join(batch, "") != "cd" || remaining != 1 || tool != "forecast_eol_exposure" {
+		t.Errorf("Unexpected second batch: %v %d %s %v", batch, remaining, tool, err)
+	}
+	if batch, remaining, _, err = store.Next(token); err != nil || strings.Join(batch, "") != "e" || remaining != 0 {
+		t.Errorf("Unexpected final batch: %v %d %v", batch, remaining, err)
+	}
+	if _, _, _, err := store.Next(token); err == nil {
+		t.Error("Expected token to be released after the last batch")
+	}
+
+	_, expiring := store.Start("tool", []string{"a", "b", "c"})
+	now = now.Add(2 * time.Minute)
+	if _, _, _, err := store.Next(expiring); err == nil {
+		t.Error("Expected expired token to be rejected")
+	}
+}
+
+func TestStreamResponseWithContinuation(t *testing.T) {
+	service := createTestService()
+	service.continuations = NewContinuationStore(2, time.Minute)
+
+	line := strings.Repeat("x", 1023) + "\n"
+	text := strings.Repeat(line, 64*5) // five full blocks
+	response := service.streamResponse("analyze_network_prefixes", text)
+	if len(response.Content) != 3 {
+		t.Fatalf("Expected two blocks and a continuation note, got %d contents", len(response.Content))
+	}
+	note := response.Content[2].TextContent.Text
+	if !strings.Contains(note, "3 more blocks available") {
+		t.Fatalf("Unexpected continuation note: %s", note)
+	}
+	token := strings.Fields(note[strings.Index(note, "token ")+len("token "):])[0]
+
+	received := response.Content[0].TextContent.Text + response.Content[1].TextContent.Text
+	for token != "" {
+		next, err := service.continueResponse(ContinueResponseArgs{Token: token})
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		token = ""
+		for _, content := range next.Content {
+			if strings.HasPrefix(content.TextContent.Text, "Output continues") {
+				token = strings.Fields(content.TextContent.Text[strings.Index(content.TextContent.Text, "token ")+len("token "):])[0]
+				continue
+			}
+			received += content.TextContent.Text
+		}
+	}
+	if received != text {
+		t.Errorf("Reassembled output differs: got %d bytes, want %d", len(received), len(text))
+	}
+
+	if small := service.streamResponse("tool", "short report"); len(small.Content) != 1 || small.Content[0].TextContent.Text != "short report" {
+		t.Errorf("Expected a single block for short output, got %+v", small.Content)
+	}
+	if _, err := service.continueResponse(ContinueResponseArgs{Token: "cont_unknown"}); err == nil {
+		t.Error("Expected unknown token to return an error")
+	}
+}
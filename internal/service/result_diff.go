@@ -0,0 +1,349 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// resultDiffChunkRows is the number of diff rows stored per memory observation
+const resultDiffChunkRows = 200
+
+// Row classifications produced by diff_stored_results
+const (
+	resultRowAdded   = "added"
+	resultRowRemoved = "removed"
+	resultRowChanged = "changed"
+)
+
+// ResultColumnChange is one column whose value differs between two versions of a row
+type ResultColumnChange struct {
+	Column string      `json:"column"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ResultRowDiff describes a row that was added, removed or changed between two results
+type ResultRowDiff struct {
+	Key     string                 `json:"key"`
+	Type    string                 `json:"type"`
+	Row     map[string]interface{} `json:"row,omitempty"`
+	Changes []ResultColumnChange   `json:"changes,omitempty"`
+}
+
+// ResultDiff is the row-level comparison of two stored NQE results
+type ResultDiff struct {
+	EntityA       string          `json:"entity_a"`
+	EntityB       string          `json:"entity_b"`
+	SnapshotA     string          `json:"snapshot_a,omitempty"`
+	SnapshotB     string          `json:"snapshot_b,omitempty"`
+	KeyColumns    []string        `json:"key_columns"`
+	Added         []ResultRowDiff `json:"added"`
+	Removed       []ResultRowDiff `json:"removed"`
+	Changed       []ResultRowDiff `json:"changed"`
+	Unchanged     int             `json:"unchanged"`
+	DuplicateKeys int             `json:"duplicate_keys,omitempty"`
+}
+
+// diffStoredResults compares two stored NQE result entities row by row
+func (s *ForwardMCPService) diffStoredResults(args DiffStoredResultsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("diff_stored_results", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if args.EntityA == "" || args.EntityB == "" {
+		return nil, fmt.Errorf("entity_a and entity_b are required")
+	}
+	if err := validateReportFormat(args.ReportFormat); err != nil {
+		return nil, err
+	}
+	keyColumns := make([]string, 0, len(args.KeyColumns))
+	for _, column := range args.KeyColumns {
+		if column = strings.TrimSpace(column); column != "" {
+			keyColumns = append(keyColumns, column)
+		}
+	}
+	if len(keyColumns) == 0 {
+		return nil, fmt.Errorf("key_columns is required (columns that identify a row, e.g. [\"device\", \"interface\"])")
+	}
+
+	entityA, rowsA, err := s.loadStoredResultRows(args.EntityA)
+	if err != nil {
+		return nil, err
+	}
+	entityB, rowsB, err := s.loadStoredResultRows(args.EntityB)
+	if err != nil {
+		return nil, err
+	}
+	for _, column := range keyColumns {
+		if !resultRowsHaveColumn(rowsA, column) && !resultRowsHaveColumn(rowsB, column) {
+			return nil, fmt.Errorf("key column %q not found in either result", column)
+		}
+	}
+
+	diff := diffResultRows(rowsA, rowsB, keyColumns)
+	diff.EntityA = entityA.ID
+	diff.EntityB = entityB.ID
+	diff.SnapshotA, _ = entityA.Metadata["snapshot_id"].(string)
+	diff.SnapshotB, _ = entityB.Metadata["snapshot_id"].(string)
+
+	entityID := s.storeResultDiff(diff)
+
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	reportNote := s.saveReport(resultDiffReport(diff), fmt.Sprintf("result_diff_%s_%s", diff.EntityA, diff.EntityB), args.ReportFormat)
+	return s.streamResponse("diff_stored_results", formatResultDiff(diff, limit, entityID)+reportNote), nil
+}
+
+// loadStoredResultRows resolves a result entity by ID or name and decodes all of its chunks
+func (s *ForwardMCPService) loadStoredResultRows(identifier string) (*Entity, []map[string]interface{}, error) {
+	entity, err := s.memorySystem.GetEntity(identifier)
+	if err != nil {
+		return nil, nil, newCodedError(CodeStoredResultNotFound, identifier, err)
+	}
+	chunks, err := s.memorySystem.GetNQEResultChunks(entity.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve result chunks for %s: %w", identifier, err)
+	}
+	if len(chunks) == 0 {
+		return nil, nil, fmt.Errorf("entity %s has no stored NQE result rows", identifier)
+	}
+
+	var rows []map[string]interface{}
+	for _, chunk := range chunks {
+		var chunkRows []map[string]interface{}
+		if err := json.Unmarshal([]byte(chunk), &chunkRows); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal chunk for %s: %w", identifier, err)
+		}
+		rows = append(rows, chunkRows...)
+	}
+	return entity, rows, nil
+}
+
+func resultRowsHaveColumn(rows []map[string]interface{}, column string) bool {
+	for _, row := range rows {
+		if _, ok := row[column]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// diffResultRows classifies rows as added, removed or changed by their key columns. When a key
+// repeats within one result only its first row is compared and the repeat is counted.
+func diffResultRows(rowsA, rowsB []map[string]interface{}, keyColumns []string) *ResultDiff {
+	diff := &ResultDiff{
+		KeyColumns: keyColumns,
+		Added:      []ResultRowDiff{},
+		Removed:    []ResultRowDiff{},
+		Changed:    []ResultRowDiff{},
+	}
+
+	indexA := make(map[string]map[string]interface{}, len(rowsA))
+	for _, row := range rowsA {
+		key := resultRowKey(row, keyColumns)
+		if _, exists := indexA[key]; exists {
+			diff.DuplicateKeys++
+			continue
+		}
+		indexA[key] = row
+	}
+
+	seenB := make(map[string]bool, len(rowsB))
+	for _, row := range rowsB {
+		key := resultRowKey(row, keyColumns)
+		if seenB[key] {
+			diff.DuplicateKeys++
+			continue
+		}
+		seenB[key] = true
+
+		before, ok := indexA[key]
+		if !ok {
+			diff.Added = append(diff.Added, ResultRowDiff{Key: key, Type: resultRowAdded, Row: row})
+			continue
+		}
+		if changes := resultRowChanges(before, row); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, ResultRowDiff{Key: key, Type: resultRowChanged, Changes: changes})
+		} else {
+			diff.Unchanged++
+		}
+	}
+	for key, row := range indexA {
+		if !seenB[key] {
+			diff.Removed = append(diff.Removed, ResultRowDiff{Key: key, Type: resultRowRemoved, Row: row})
+		}
+	}
+
+	for _, rows := range [][]ResultRowDiff{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(rows, func(i, j int) bool { return rows[i].Key < rows[j].Key })
+	}
+	return diff
+}
+
+// resultRowKey joins the key column values of a row, e.g. "core-1 | Ethernet1"
+func resultRowKey(row map[string]interface{}, keyColumns []string) string {
+	parts := make([]string, len(keyColumns))
+	for i, column := range keyColumns {
+		parts[i] = resultValueString(row[column])
+	}
+	return strings.Join(parts, " | ")
+}
+
+// resultRowChanges lists the columns whose values differ, sorted by column name
+func resultRowChanges(before, after map[string]interface{}) []ResultColumnChange {
+	columns := make(map[string]bool, len(before)+len(after))
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+	names := make([]string, 0, len(columns))
+	for column := range columns {
+		names = append(names, column)
+	}
+	sort.Strings(names)
+
+	var changes []ResultColumnChange
+	for _, column := range names {
+		if resultValueString(before[column]) != resultValueString(after[column]) {
+			changes = append(changes, ResultColumnChange{Column: column, Before: before[column], After: after[column]})
+		}
+	}
+	return changes
+}
+
+// resultValueString renders a cell value for comparison; nested values compare by their JSON form
+func resultValueString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		// JSON numbers decode as float64; large counts must not render in exponent form
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case map[string]interface{}, []interface{}:
+		return MarshalCompactJSONString(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// storeResultDiff saves the diff as a result_diff entity linked to both compared results
+func (s *ForwardMCPService) storeResultDiff(diff *ResultDiff) string {
+	entity, err := s.memorySystem.CreateEntity(
+		fmt.Sprintf("result_diff_%s_%s", diff.EntityA, diff.EntityB),
+		"result_diff",
+		map[string]interface{}{
+			"entity_a":    diff.EntityA,
+			"entity_b":    diff.EntityB,
+			"snapshot_a":  diff.SnapshotA,
+			"snapshot_b":  diff.SnapshotB,
+			"key_columns": diff.KeyColumns,
+			"added":       len(diff.Added),
+			"removed":     len(diff.Removed),
+			"changed":     len(diff.Changed),
+			"unchanged":   diff.Unchanged,
+		},
+	)
+	if err != nil {
+		s.logger.Warn("Failed to store result diff: %v", err)
+		return ""
+	}
+
+	rows := make([]ResultRowDiff, 0, len(diff.Added)+len(diff.Removed)+len(diff.Changed))
+	rows = append(append(append(rows, diff.Added...), diff.Removed...), diff.Changed...)
+	totalChunks := (len(rows) + resultDiffChunkRows - 1) / resultDiffChunkRows
+	for i := 0; i < totalChunks; i++ {
+		end := (i + 1) * resultDiffChunkRows
+		if end > len(rows) {
+			end = len(rows)
+		}
+		if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(rows[i*resultDiffChunkRows:end]), "result_diff_chunk",
+			map[string]interface{}{"chunk_index": i, "total_chunks": totalChunks}); err != nil {
+			s.logger.Debug("Failed to store result diff chunk %d: %v", i, err)
+		}
+	}
+	summary := map[string]interface{}{
+		"added": len(diff.Added), "removed": len(diff.Removed), "changed": len(diff.Changed),
+		"unchanged": diff.Unchanged, "duplicate_keys": diff.DuplicateKeys, "key_columns": diff.KeyColumns,
+	}
+	if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(summary), "result_diff_summary", nil); err != nil {
+		s.logger.Debug("Failed to store result diff summary: %v", err)
+	}
+
+	for _, compared := range []string{diff.EntityA, diff.EntityB} {
+		if _, err := s.memorySystem.CreateRelation(entity.ID, compared, "compares", nil); err != nil {
+			s.logger.Debug("Failed to link result diff to %s: %v", compared, err)
+		}
+	}
+	return entity.ID
+}
+
+// formatResultDiff renders summary counts and up to limit rows of each classification as Markdown
+func formatResultDiff(diff *ResultDiff, limit int, entityID string) string {
+	var report strings.Builder
+	report.WriteString("# Stored Result Diff\n\n")
+	report.WriteString(fmt.Sprintf("- **Before:** %s", diff.EntityA))
+	if diff.SnapshotA != "" {
+		report.WriteString(fmt.Sprintf(" (snapshot %s)", diff.SnapshotA))
+	}
+	report.WriteString(fmt.Sprintf("\n- **After:** %s", diff.EntityB))
+	if diff.SnapshotB != "" {
+		report.WriteString(fmt.Sprintf(" (snapshot %s)", diff.SnapshotB))
+	}
+	report.WriteString(fmt.Sprintf("\n- **Key columns:** %s\n\n", strings.Join(diff.KeyColumns, ", ")))
+
+	report.WriteString("| Added | Removed | Changed | Unchanged |\n|---|---|---|---|\n")
+	report.WriteString(fmt.Sprintf("| %d | %d | %d | %d |\n", len(diff.Added), len(diff.Removed), len(diff.Changed), diff.Unchanged))
+	if diff.DuplicateKeys > 0 {
+		report.WriteString(fmt.Sprintf("\n⚠️ %d rows share a key with an earlier row and were skipped; add key columns to make rows unique.\n", diff.DuplicateKeys))
+	}
+
+	sections := []struct {
+		title string
+		rows  []ResultRowDiff
+	}{
+		{"Added", diff.Added},
+		{"Removed", diff.Removed},
+		{"Changed", diff.Changed},
+	}
+	for _, section := range sections {
+		if len(section.rows) == 0 {
+			continue
+		}
+		report.WriteString(fmt.Sprintf("\n## %s (%d)\n\n", section.title, len(section.rows)))
+		for i, row := range section.rows {
+			if i >= limit {
+				report.WriteString(fmt.Sprintf("- ... %d more\n", len(section.rows)-limit))
+				break
+			}
+			if row.Type != resultRowChanged {
+				report.WriteString(fmt.Sprintf("- `%s`: %s\n", row.Key, MarshalCompactJSONString(row.Row)))
+				continue
+			}
+			changes := make([]string, len(row.Changes))
+			for j, change := range row.Changes {
+				changes[j] = fmt.Sprintf("%s: %s → %s", change.Column, resultValueString(change.Before), resultValueString(change.After))
+			}
+			report.WriteString(fmt.Sprintf("- `%s`: %s\n", row.Key, strings.Join(changes, "; ")))
+		}
+	}
+
+	if len(diff.Added)+len(diff.Removed)+len(diff.Changed) == 0 {
+		report.WriteString("\nNo differences found.\n")
+	}
+	if entityID != "" {
+		report.WriteString(fmt.Sprintf("\nDiff stored in memory (entity: %s). Retrieve all rows with get_observations (observation_type: result_diff_chunk).\n", entityID))
+	}
+	return report.String()
+}
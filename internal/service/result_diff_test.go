@@ -0,0 +1,85 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestDiffResultRows(t *testing.T) {
+	before := []map[string]interface{}{
+		{"device": "core-1", "interface": "Ethernet1", "status": "up", "mtu": float64(1500)},
+		{"device": "core-1", "interface": "Ethernet2", "status": "up", "mtu": float64(1500)},
+		{"device": "edge-1", "interface": "Ethernet1", "status": "up", "mtu": float64(9000)},
+		{"device": "edge-1", "interface": "Ethernet1", "status": "down", "mtu": float64(9000)},
+	}
+	after := []map[string]interface{}{
+		{"device": "core-1", "interface": "Ethernet1", "status": "down", "mtu": float64(9000)},
+		{"device": "edge-1", "interface": "Ethernet1", "status": "up", "mtu": float64(9000)},
+		{"device": "leaf-1", "interface": "Ethernet1", "status": "up", "mtu": float64(1500)},
+	}
+
+	diff := diffResultRows(before, after, []string{"device", "interface"})
+	if len(diff.Added) != 1 || diff.Added[0].Key != "leaf-1 | Ethernet1" {
+		t.Errorf("Unexpected added rows: %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Key != "core-1 | Ethernet2" {
+		t.Errorf("Unexpected removed rows: %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || len(diff.Changed[0].Changes) != 2 ||
+		diff.Changed[0].Changes[0].Column != "mtu" || diff.Changed[0].Changes[1].Column != "status" {
+		t.Errorf("Unexpected changed rows: %+v", diff.Changed)
+	}
+	if diff.Unchanged != 1 || diff.DuplicateKeys != 1 {
+		t.Errorf("Expected 1 unchanged row and 1 duplicate key, got %d and %d", diff.Unchanged, diff.DuplicateKeys)
+	}
+}
+
+func TestDiffStoredResults(t *testing.T) {
+	service := createTestService()
+	entityA, err := service.memorySystem.StoreNQEResultWithChunking("FQ_diff_test", "162112", "snap-diff-a", &forward.NQERunResult{
+		Items: []map[string]interface{}{
+			{"name": "core-1", "os": "15.2"},
+			{"name": "edge-1", "os": "17.3"},
+		},
+	}, 1)
+	if err != nil {
+		t.Fatalf("Failed to store result A: %v", err)
+	}
+	entityB, err := service.memorySystem.StoreNQEResultWithChunking("FQ_diff_test", "162112", "snap-diff-b", &forward.NQERunResult{
+		Items: []map[string]interface{}{
+			{"name": "core-1", "os": "15.9"},
+			{"name": "leaf-1", "os": "4.30"},
+		},
+	}, 1)
+	if err != nil {
+		t.Fatalf("Failed to store result B: %v", err)
+	}
+
+	response, err := service.diffStoredResults(DiffStoredResultsArgs{EntityA: entityA, EntityB: "FQ_diff_test-162112-snap-diff-b", KeyColumns: []string{"name"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, want := range []string{"(snapshot snap-diff-a)", "| 1 | 1 | 1 | 0 |", "`leaf-1`", "`edge-1`", "`core-1`: os: 15.2 → 15.9", "entity: "} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in response, got:\n%s", want, text)
+		}
+	}
+
+	diffEntity, err := service.memorySystem.GetEntity("result_diff_" + entityA + "_" + entityB)
+	if err != nil {
+		t.Fatalf("Expected diff entity to be stored: %v", err)
+	}
+	if chunks, _ := service.memorySystem.GetObservations(diffEntity.ID, "result_diff_chunk"); len(chunks) != 1 {
+		t.Errorf("Expected 1 diff chunk, got %d", len(chunks))
+	}
+
+	if _, err := service.diffStoredResults(DiffStoredResultsArgs{EntityA: entityA, EntityB: entityB}); err == nil {
+		t.Error("Expected an error without key columns")
+	}
+	if _, err := service.diffStoredResults(DiffStoredResultsArgs{EntityA: entityA, EntityB: entityB, KeyColumns: []string{"serial"}}); err == nil {
+		t.Error("Expected an error for an unknown key column")
+	}
+}
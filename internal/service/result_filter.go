@@ -0,0 +1,93 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// defaultFilteredRowLimit caps how many matching rows a filtered chunk retrieval returns
+const defaultFilteredRowLimit = 1000
+
+// ResultRowFilter matches stored result rows on a single column
+type ResultRowFilter struct {
+	ColumnName string `json:"column_name" jsonschema:"required,description=Name of the column to filter on"`
+	Value      string `json:"value" jsonschema:"required,description=Value to match"`
+	Match      string `json:"match,omitempty" jsonschema:"description=How to match: 'equals' (default) or 'contains' (case-insensitive substring)"`
+}
+
+// validateResultRowFilters normalizes match modes and rejects unknown ones
+func validateResultRowFilters(filters []ResultRowFilter) ([]ResultRowFilter, error) {
+	normalized := make([]ResultRowFilter, len(filters))
+	for i, filter := range filters {
+		if filter.ColumnName == "" {
+			return nil, fmt.Errorf("filter %d is missing column_name", i)
+		}
+		filter.Match = strings.ToLower(strings.TrimSpace(filter.Match))
+		switch filter.Match {
+		case "", "equals", "=":
+			filter.Match = "equals"
+		case "contains", "~":
+			filter.Match = "contains"
+			filter.Value = strings.ToLower(filter.Value)
+		default:
+			return nil, fmt.Errorf("unsupported match %q for column %s (use 'equals' or 'contains')", filter.Match, filter.ColumnName)
+		}
+		normalized[i] = filter
+	}
+	return normalized, nil
+}
+
+// rowMatchesFilters reports whether a row satisfies every filter. Filters must have been
+// normalized by validateResultRowFilters.
+func rowMatchesFilters(row map[string]interface{}, filters []ResultRowFilter) bool {
+	for _, filter := range filters {
+		value, ok := row[filter.ColumnName]
+		if !ok {
+			return false
+		}
+		cell := resultCellString(value)
+		if filter.Match == "contains" {
+			if !strings.Contains(strings.ToLower(cell), filter.Value) {
+				return false
+			}
+		} else if cell != filter.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// projectRow returns a row holding only the requested columns, or the row itself when no
+// columns are requested
+func projectRow(row map[string]interface{}, columns []string) map[string]interface{} {
+	if len(columns) == 0 {
+		return row
+	}
+	projected := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		if value, ok := row[column]; ok {
+			projected[column] = value
+		}
+	}
+	return projected
+}
+
+// resultCellString renders a decoded JSON cell for comparison. Numbers and booleans use
+// their plain form and nested values their compact JSON.
+func resultCellString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64, bool:
+		return resultValueString(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestRowMatchesFilters(t *testing.T) {
+	row := map[string]interface{}{"device": "edge-1", "vlan": float64(10), "up": true, "tags": []interface{}{"core"}}
+
+	cases := []struct {
+		filters  []ResultRowFilter
+		expected bool
+	}{
+		{[]ResultRowFilter{{ColumnName: "device", Value: "edge-1"}}, true},
+		{[]ResultRowFilter{{ColumnName: "vlan", Value: "10"}, {ColumnName: "up", Value: "true"}}, true},
+		{[]ResultRowFilter{{ColumnName: "device", Value: "EDGE", Match: "contains"}}, true},
+		{[]ResultRowFilter{{ColumnName: "tags", Value: "core", Match: "contains"}}, true},
+		{[]ResultRowFilter{{ColumnName: "device", Value: "edge"}}, false},
+		{[]ResultRowFilter{{ColumnName: "site", Value: "edge-1"}}, false},
+	}
+	for _, tc := range cases {
+		filters, err := validateResultRowFilters(tc.filters)
+		if err != nil {
+			t.Fatalf("Unexpected validation error: %v", err)
+		}
+		if got := rowMatchesFilters(row, filters); got != tc.expected {
+			t.Errorf("rowMatchesFilters(%+v) = %v, expected %v", tc.filters, got, tc.expected)
+		}
+	}
+
+	if _, err := validateResultRowFilters([]ResultRowFilter{{ColumnName: "device", Value: "x", Match: "regex"}}); err == nil {
+		t.Error("Expected an unsupported match mode to be rejected")
+	}
+}
+
+func TestProjectRow(t *testing.T) {
+	row := map[string]interface{}{"device": "edge-1", "vlan": float64(10)}
+	projected := projectRow(row, []string{"device", "missing"})
+	if len(projected) != 1 || projected["device"] != "edge-1" {
+		t.Errorf("Unexpected projection: %v", projected)
+	}
+	if len(projectRow(row, nil)) != 2 {
+		t.Error("Expected no projection to keep every column")
+	}
+}
+
+func TestResultCellStringNumbers(t *testing.T) {
+	for value, expected := range map[interface{}]string{float64(10): "10", float64(1234567): "1234567", 0.25: "0.25", true: "true"} {
+		if got := resultCellString(value); got != expected {
+			t.Errorf("resultCellString(%v) = %q, expected %q", value, got, expected)
+		}
+	}
+}
+
+func TestGetNQEResultChunksFilters(t *testing.T) {
+	service := createTestService()
+	if service.memorySystem == nil {
+		t.Skip("memory system unavailable")
+	}
+
+	items := make([]map[string]interface{}, 30)
+	for i := range items {
+		items[i] = map[string]interface{}{"device": fmt.Sprintf("device-%02d", i), "status": []string{"up", "down", "admin-down"}[i%3]}
+	}
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_filter_tool", "162112", uniqueTestName("snap-filter-"), &forward.NQERunResult{Items: items}, 128)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	defer service.memorySystem.DeleteEntity(entityID)
+
+	response, err := service.getNQEResultChunks(GetNQEResultChunksArgs{
+		EntityID: entityID,
+		Filters:  []ResultRowFilter{{ColumnName: "status", Value: "down", Match: "contains"}},
+		Columns:  []string{"device"},
+		Limit:    5,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, `"matched_rows":20`) || !contains(text, `"truncated":true`) || !contains(text, `{"device":"device-01"}`) {
+		t.Errorf("Unexpected filtered response: %s", text)
+	}
+	if contains(text, `"status"`) {
+		t.Errorf("Expected the status column to be projected away: %s", text)
+	}
+
+	// A projection alone is capped as well
+	response, err = service.getNQEResultChunks(GetNQEResultChunksArgs{EntityID: entityID, Columns: []string{"device"}, Limit: 4})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !contains(text, `"truncated":true`) || !contains(text, `{"device":"device-03"}`) || contains(text, `"device-04"`) {
+		t.Errorf("Expected the projection capped at 4 rows: %s", text)
+	}
+
+	index := 0
+	if _, err := service.getNQEResultChunks(GetNQEResultChunksArgs{EntityID: entityID, ChunkIndex: &index, Columns: []string{"device"}}); err == nil {
+		t.Error("Expected chunk_index with a projection to be rejected")
+	}
+}
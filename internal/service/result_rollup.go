@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultRollupExamples = 2   // Example rows shown per group
+	maxRollupExamples     = 20  // Upper bound on group_examples
+	maxRollupGroups       = 200 // Groups rendered; the smallest beyond it are only counted
+)
+
+// RowGroup is the rows of a result sharing the values of the group_by columns
+type RowGroup struct {
+	Key      map[string]interface{}   `json:"key"`
+	Count    int                      `json:"count"`
+	Examples []map[string]interface{} `json:"examples,omitempty"`
+	first    int                      // Index of the group's first row, for stable ordering
+}
+
+// ResultRollup renders a result as groups of rows with counts and a few examples each,
+// instead of every row
+type ResultRollup struct {
+	GroupBy       []string   `json:"group_by"`
+	TotalRows     int        `json:"total_rows"`
+	Groups        []RowGroup `json:"groups"`
+	OmittedGroups int        `json:"omitted_groups,omitempty"`
+	OmittedRows   int        `json:"omitted_rows,omitempty"`
+	EntityID      string     `json:"entity_id,omitempty"`
+
+	examples int
+	index    map[string]int
+}
+
+// newResultRollup validates group_by and returns an empty rollup. Examples of 0 uses the
+// default; a negative value shows counts only.
+func newResultRollup(groupBy []string, examples int) (*ResultRollup, error) {
+	columns := make([]string, 0, len(groupBy))
+	for _, column := range groupBy {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		return nil, fmt.Errorf("group_by needs at least one column")
+	}
+	switch {
+	case examples == 0:
+		examples = defaultRollupExamples
+	case examples < 0:
+		examples = 0
+	case examples > maxRollupExamples:
+		examples = maxRollupExamples
+	}
+	return &ResultRollup{GroupBy: columns, examples: examples, index: make(map[string]int)}, nil
+}
+
+// Add counts a row into its group, keeping it as an example while the group has room.
+// Examples leave out the group_by columns, which the key already shows.
+func (r *ResultRollup) Add(row map[string]interface{}) {
+	parts := make([]string, len(r.GroupBy))
+	for i, column := range r.GroupBy {
+		parts[i] = resultCellString(row[column])
+	}
+	key := strings.Join(parts, "\x00")
+
+	i, ok := r.index[key]
+	if !ok {
+		group := RowGroup{Key: make(map[string]interface{}, len(r.GroupBy)), first: r.TotalRows}
+		for _, column := range r.GroupBy {
+			group.Key[column] = row[column]
+		}
+		r.Groups = append(r.Groups, group)
+		i = len(r.Groups) - 1
+		r.index[key] = i
+	}
+	r.TotalRows++
+	group := &r.Groups[i]
+	group.Count++
+	if len(group.Examples) < r.examples {
+		example := make(map[string]interface{}, len(row))
+		for column, value := range row {
+			if _, grouped := group.Key[column]; !grouped {
+				example[column] = value
+			}
+		}
+		group.Examples = append(group.Examples, example)
+	}
+}
+
+// AddAll counts every row
+func (r *ResultRollup) AddAll(rows []map[string]interface{}) *ResultRollup {
+	for _, row := range rows {
+		r.Add(row)
+	}
+	return r
+}
+
+// finish orders groups largest first and drops those beyond maxRollupGroups
+func (r *ResultRollup) finish() {
+	sort.SliceStable(r.Groups, func(i, j int) bool {
+		if r.Groups[i].Count != r.Groups[j].Count {
+			return r.Groups[i].Count > r.Groups[j].Count
+		}
+		return r.Groups[i].first < r.Groups[j].first
+	})
+	if len(r.Groups) > maxRollupGroups {
+		for _, group := range r.Groups[maxRollupGroups:] {
+			r.OmittedGroups++
+			r.OmittedRows += group.Count
+		}
+		r.Groups = r.Groups[:maxRollupGroups]
+	}
+}
+
+// String renders the rollup as a summary line, the groups as JSON and how to see every row
+// of a group
+func (r *ResultRollup) String() string {
+	r.finish()
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("Rolled up %d rows into %d groups by %s", r.TotalRows, len(r.Groups)+r.OmittedGroups, strings.Join(r.GroupBy, ", ")))
+	if r.examples > 0 {
+		out.WriteString(fmt.Sprintf(" (up to %d examples per group)", r.examples))
+	}
+	out.WriteString(":\n")
+	out.WriteString(MarshalCompactJSONString(r.Groups))
+	out.WriteString("\n")
+	if r.OmittedGroups > 0 {
+		out.WriteString(fmt.Sprintf("\n%d smaller groups with %d rows are not shown.\n", r.OmittedGroups, r.OmittedRows))
+	}
+	if r.EntityID != "" {
+		out.WriteString(fmt.Sprintf("\nEvery row is stored as entity %s. Use get_nqe_result_chunks with filters on %s to see all rows of a group.\n",
+			r.EntityID, strings.Join(r.GroupBy, ", ")))
+	}
+	return out.String()
+}
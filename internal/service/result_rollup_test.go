@@ -0,0 +1,98 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func interfaceRows() []map[string]interface{} {
+	var rows []map[string]interface{}
+	for _, device := range []struct {
+		name       string
+		interfaces int
+	}{{"leaf-1", 48}, {"leaf-2", 48}, {"spine-1", 4}} {
+		for i := 0; i < device.interfaces; i++ {
+			rows = append(rows, map[string]interface{}{"device": device.name, "interface": fmt.Sprintf("Ethernet1/%d", i+1), "status": "up"})
+		}
+	}
+	return rows
+}
+
+func TestResultRollup(t *testing.T) {
+	rollup, err := newResultRollup([]string{"device", " status "}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := interfaceRows()
+	rows = append(rows, map[string]interface{}{"device": "spine-1", "interface": "Ethernet1/5", "status": "down"})
+	text := rollup.AddAll(rows).String()
+
+	if rollup.TotalRows != 101 || len(rollup.Groups) != 4 {
+		t.Fatalf("Expected 101 rows in 4 groups, got %d in %d", rollup.TotalRows, len(rollup.Groups))
+	}
+	first := rollup.Groups[0]
+	if first.Key["device"] != "leaf-1" || first.Count != 48 || len(first.Examples) != defaultRollupExamples {
+		t.Errorf("Expected leaf-1 first with two examples, got %+v", first)
+	}
+	if _, ok := first.Examples[0]["device"]; ok || first.Examples[0]["interface"] != "Ethernet1/1" {
+		t.Errorf("Expected examples without the grouped columns, got %v", first.Examples[0])
+	}
+	if last := rollup.Groups[3]; last.Key["status"] != "down" || last.Count != 1 {
+		t.Errorf("Expected the single down interface last, got %+v", last)
+	}
+	if !strings.Contains(text, "Rolled up 101 rows into 4 groups by device, status (up to 2 examples per group)") {
+		t.Errorf("Unexpected rendering: %s", text)
+	}
+
+	// Counts only, and groups beyond the cap are summarized
+	rollup, _ = newResultRollup([]string{"interface"}, -1)
+	for i := 0; i < maxRollupGroups+10; i++ {
+		rollup.Add(map[string]interface{}{"interface": i})
+	}
+	text = rollup.String()
+	if len(rollup.Groups) != maxRollupGroups || rollup.Groups[0].Examples != nil || !strings.Contains(text, "10 smaller groups with 10 rows are not shown") {
+		t.Errorf("Expected the groups capped without examples, got %d groups: %s", len(rollup.Groups), text[len(text)-80:])
+	}
+
+	if _, err := newResultRollup([]string{" "}, 0); err == nil {
+		t.Error("Expected an empty group_by to be rejected")
+	}
+}
+
+func TestRunNQEQueryByIDGroupBy(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.config.Forward.SemanticCache.Enabled = false
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.nqeResult = &forward.NQERunResult{Items: interfaceRows()}
+
+	response, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", QueryID: "FQ_ifaces", Options: &NQEQueryOptions{Limit: 500}, GroupBy: []string{"device"}, GroupExamples: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"Found 100 items.", "Rolled up 100 rows into 3 groups by device", `"count":48`, "Use get_nqe_result_chunks with filters on device"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "Ethernet1/2\"") {
+		t.Errorf("Expected a single example per group: %s", text)
+	}
+
+	// The stored result keeps every row and can be rolled up again
+	entity, err := service.memorySystem.getEntityByName("FQ_ifaces-162112-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err = service.getNQEResultChunks(GetNQEResultChunksArgs{EntityID: entity.ID, GroupBy: []string{"status"}, Columns: []string{"interface"}, GroupExamples: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Rows 0-99 of 100 (100 matched)") || !strings.Contains(text, `[{"key":{"status":"up"},"count":100}]`) {
+		t.Errorf("Expected one group of all rows, got: %s", text)
+	}
+}
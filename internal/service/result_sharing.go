@@ -0,0 +1,363 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	sharedResultDirectoryName = "exports"
+	sharedReferencePrefix     = "sha256:"
+	sharedMetadataSuffix      = ".meta.json"
+	minSharedReferenceDigits  = 12 // Shortest abbreviated reference accepted by get_shared_result
+	defaultSharedPreviewRows  = 20
+	maxSharedPreviewRows      = 500
+	sharedKindEntity          = "entity"
+	sharedKindReport          = "report"
+)
+
+// SharedResult describes an exported entity or report. The content is written to a file
+// named by its SHA-256, so the reference always resolves to exactly the shared bytes.
+type SharedResult struct {
+	Reference string    `json:"reference"` // sha256:<hex digest of the content file>
+	Kind      string    `json:"kind"`      // entity or report
+	Source    string    `json:"source"`    // Entity ID or report file name
+	Name      string    `json:"name"`
+	Format    string    `json:"format"` // json for entities, the report format for reports
+	Size      int64     `json:"size"`
+	Rows      int       `json:"rows,omitempty"`
+	NetworkID string    `json:"network_id,omitempty"` // Network of a shared entity, for the network access policy
+	SharedAt  time.Time `json:"shared_at"`
+	File      string    `json:"file"`
+}
+
+// sharedEntity is the exported form of a memory entity
+type sharedEntity struct {
+	Entity       *Entity                  `json:"entity"`
+	Observations []*Observation           `json:"observations,omitempty"` // Result chunks are exported as rows
+	Rows         []map[string]interface{} `json:"rows,omitempty"`
+}
+
+// sharedResultDirectory returns where shared results are written
+func sharedResultDirectory() (string, error) {
+	dataDir, err := getWritableDataDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, sharedResultDirectoryName), nil
+}
+
+// writeSharedResult writes content under its digest with a metadata sidecar. Sharing the same
+// content again returns the existing share.
+func writeSharedResult(dir string, content []byte, extension string, shared SharedResult) (*SharedResult, bool, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, false, fmt.Errorf("failed to create export directory %s: %w", dir, err)
+	}
+	sum := sha256.Sum256(content)
+	digest := hex.EncodeToString(sum[:])
+	if existing, err := readSharedMetadata(dir, digest); err == nil {
+		return existing, true, nil
+	}
+
+	shared.Reference = sharedReferencePrefix + digest
+	shared.Size = int64(len(content))
+	shared.File = filepath.Join(dir, digest+extension)
+	if err := os.WriteFile(shared.File, content, 0600); err != nil {
+		return nil, false, fmt.Errorf("failed to write shared result: %w", err)
+	}
+	metadata, err := json.MarshalIndent(shared, "", "  ")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal shared result metadata: %w", err)
+	}
+	// The sidecar is written last, so a share is only visible once its content is complete
+	if err := os.WriteFile(filepath.Join(dir, digest+sharedMetadataSuffix), metadata, 0600); err != nil {
+		return nil, false, fmt.Errorf("failed to write shared result metadata: %w", err)
+	}
+	return &shared, false, nil
+}
+
+// readSharedMetadata reads the sidecar of a full digest
+func readSharedMetadata(dir, digest string) (*SharedResult, error) {
+	data, err := os.ReadFile(filepath.Join(dir, digest+sharedMetadataSuffix))
+	if err != nil {
+		return nil, err
+	}
+	var shared SharedResult
+	if err := json.Unmarshal(data, &shared); err != nil {
+		return nil, fmt.Errorf("invalid shared result metadata for %s: %w", digest, err)
+	}
+	return &shared, nil
+}
+
+// resolveSharedResult finds a share by full or abbreviated reference and verifies that its
+// content still matches the digest
+func resolveSharedResult(dir, reference string) (*SharedResult, []byte, error) {
+	digest := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(reference), sharedReferencePrefix))
+	if len(digest) < minSharedReferenceDigits || strings.Trim(digest, "0123456789abcdef") != "" {
+		return nil, nil, fmt.Errorf("invalid reference %q: expected sha256:<hex digest> with at least %d digits", reference, minSharedReferenceDigits)
+	}
+	matches, err := filepath.Glob(filepath.Join(dir, digest+"*"+sharedMetadataSuffix))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to search shared results: %w", err)
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil, fmt.Errorf("no shared result matches %s", reference)
+	case 1:
+	default:
+		return nil, nil, fmt.Errorf("reference %s is ambiguous (%d matches); use more digits", reference, len(matches))
+	}
+
+	fullDigest := strings.TrimSuffix(filepath.Base(matches[0]), sharedMetadataSuffix)
+	shared, err := readSharedMetadata(dir, fullDigest)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, filepath.Base(shared.File)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read shared result %s: %w", shared.Reference, err)
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != fullDigest {
+		return nil, nil, fmt.Errorf("shared result %s was modified on disk and no longer matches its reference", shared.Reference)
+	}
+	return shared, content, nil
+}
+
+// removeStaleShares deletes shares whose content and sidecar were both last modified before
+// the cutoff. The pair is removed together, sidecar first, so no reference resolves to a
+// missing file; leftovers of interrupted shares are grouped and removed the same way.
+func removeStaleShares(dir string, cutoff time.Time, dryRun bool) ([]string, int64, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	type share struct {
+		files        []string
+		bytes        int64
+		lastModified time.Time
+	}
+	shares := make(map[string]*share)
+	var digests []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		digest, _, _ := strings.Cut(entry.Name(), ".")
+		group, ok := shares[digest]
+		if !ok {
+			group = &share{}
+			shares[digest] = group
+			digests = append(digests, digest)
+		}
+		// The sidecar goes first so the reference stops resolving before the content is removed
+		if strings.HasSuffix(entry.Name(), sharedMetadataSuffix) {
+			group.files = append([]string{entry.Name()}, group.files...)
+		} else {
+			group.files = append(group.files, entry.Name())
+		}
+		group.bytes += info.Size()
+		if info.ModTime().After(group.lastModified) {
+			group.lastModified = info.ModTime()
+		}
+	}
+
+	var removed []string
+	var bytes int64
+	for _, digest := range digests {
+		group := shares[digest]
+		if !cutoff.IsZero() && !group.lastModified.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			for _, name := range group.files {
+				if err := os.Remove(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+					return removed, bytes, fmt.Errorf("failed to delete shared result %s: %w", digest, err)
+				}
+			}
+		}
+		removed = append(removed, sharedReferencePrefix+digest)
+		bytes += group.bytes
+	}
+	return removed, bytes, nil
+}
+
+// exportEntity serializes an entity with its observations and, for stored results, its rows
+func (s *ForwardMCPService) exportEntity(identifier string) ([]byte, *Entity, int, error) {
+	entity, err := s.memorySystem.GetEntity(identifier)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("entity %s not found: %w", identifier, err)
+	}
+	observations, err := s.memorySystem.GetObservations(entity.ID, "")
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to get observations: %w", err)
+	}
+	export := sharedEntity{Entity: entity}
+	hasChunks := false
+	for _, observation := range observations {
+		if observation.Type == "nqe_result_chunk" {
+			hasChunks = true
+			continue
+		}
+		export.Observations = append(export.Observations, observation)
+	}
+	// Observations sharing a timestamp come back in any order; sort so the digest is stable
+	sort.Slice(export.Observations, func(i, j int) bool {
+		a, b := export.Observations[i], export.Observations[j]
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	})
+	if hasChunks {
+		if _, export.Rows, err = s.loadStoredResultRows(entity.ID); err != nil {
+			return nil, nil, 0, err
+		}
+	}
+	content, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("failed to marshal entity: %w", err)
+	}
+	return content, entity, len(export.Rows), nil
+}
+
+// shareResult exports a stored entity or saved report to a content-addressed file
+func (s *ForwardMCPService) shareResult(args ShareResultArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("share_result", args, nil)
+
+	if (args.EntityID == "") == (args.Report == "") {
+		return nil, fmt.Errorf("exactly one of entity_id and report is required")
+	}
+	dir, err := sharedResultDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	var extension string
+	shared := SharedResult{SharedAt: time.Now().UTC()}
+	if args.EntityID != "" {
+		if s.memorySystem == nil {
+			return nil, newCodedError(CodeMemoryUnavailable)
+		}
+		var entity *Entity
+		content, entity, shared.Rows, err = s.exportEntity(args.EntityID)
+		if err != nil {
+			return nil, err
+		}
+		shared.Kind, shared.Source, shared.Name, shared.Format, extension = sharedKindEntity, entity.ID, entity.Name, "json", ".json"
+		shared.NetworkID = resultValueString(entity.Metadata["network_id"])
+	} else {
+		if s.reports == nil {
+			return nil, fmt.Errorf("report storage is not available")
+		}
+		name := filepath.Base(strings.TrimPrefix(args.Report, reportResourcePrefix))
+		reports, err := s.reports.List()
+		if err != nil {
+			return nil, err
+		}
+		var saved *SavedReport
+		for i := range reports {
+			if reports[i].Name == name {
+				saved = &reports[i]
+				break
+			}
+		}
+		if saved == nil {
+			return nil, fmt.Errorf("report %s not found; saved reports are listed as forward://reports/ resources", name)
+		}
+		if content, err = os.ReadFile(saved.Path); err != nil {
+			return nil, fmt.Errorf("failed to read report: %w", err)
+		}
+		shared.Kind, shared.Source, shared.Name, shared.Format, extension = sharedKindReport, saved.Name, saved.Name, saved.Format, filepath.Ext(saved.Name)
+	}
+
+	result, existing, err := writeSharedResult(dir, content, extension, shared)
+	if err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	if existing {
+		text.WriteString(fmt.Sprintf("Identical content was already shared at %s.\n\n", s.timeFormatter.Format(result.SharedAt)))
+	} else {
+		text.WriteString(fmt.Sprintf("Shared %s %s (%s).\n\n", result.Kind, result.Name, formatBytes(result.Size)))
+	}
+	text.WriteString(fmt.Sprintf("**Reference:** %s\n", result.Reference))
+	text.WriteString(fmt.Sprintf("**File:** %s\n\n", result.File))
+	text.WriteString(fmt.Sprintf("Teammates on this server can fetch exactly this content with get_shared_result reference=%s (the first %d digits are enough).", result.Reference, minSharedReferenceDigits))
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// getSharedResult returns a shared entity or report by reference
+func (s *ForwardMCPService) getSharedResult(args GetSharedResultArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_shared_result", args, nil)
+
+	dir, err := sharedResultDirectory()
+	if err != nil {
+		return nil, err
+	}
+	shared, content, err := resolveSharedResult(dir, args.Reference)
+	if err != nil {
+		return nil, err
+	}
+	var export sharedEntity
+	if shared.Kind == sharedKindEntity {
+		if err := json.Unmarshal(content, &export); err != nil {
+			return nil, fmt.Errorf("invalid shared entity %s: %w", shared.Reference, err)
+		}
+	}
+	// Shares of restricted networks, or of networks outside the calling key's scopes, are
+	// reported as missing, like their entities
+	if !s.networkPolicy.Permits(shared.NetworkID) || (export.Entity != nil && !s.networkPolicy.PermitsEntity(export.Entity)) ||
+		!args.Caller.permitsNetwork(shared.NetworkID) || !args.Caller.permitsEntity(export.Entity) {
+		return nil, fmt.Errorf("no shared result matches %s", args.Reference)
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Shared %s: %s\n\n", shared.Kind, shared.Name))
+	text.WriteString(fmt.Sprintf("**Reference:** %s\n**Source:** %s\n**Shared:** %s\n**Size:** %s\n\n",
+		shared.Reference, shared.Source, s.timeFormatter.Format(shared.SharedAt), formatBytes(shared.Size)))
+
+	if shared.Kind == sharedKindReport {
+		if shared.Format == "pdf" {
+			text.WriteString(fmt.Sprintf("PDF report saved at %s", shared.File))
+		} else {
+			text.Write(content)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+	}
+
+	maxRows := args.MaxRows
+	if maxRows <= 0 {
+		maxRows = defaultSharedPreviewRows
+	}
+	maxRows = min(maxRows, maxSharedPreviewRows)
+	text.WriteString(fmt.Sprintf("Entity type %s with %d observations and %d rows.\n\n", export.Entity.Type, len(export.Observations), len(export.Rows)))
+	for _, observation := range export.Observations {
+		text.WriteString(fmt.Sprintf("- [%s] %s\n", observation.Type, observation.Content))
+	}
+	if len(export.Rows) > 0 {
+		preview, _ := json.MarshalIndent(export.Rows[:min(maxRows, len(export.Rows))], "", "  ")
+		text.WriteString(fmt.Sprintf("\n### Rows (%d of %d)\n```json\n%s\n```\n", min(maxRows, len(export.Rows)), len(export.Rows), preview))
+		if len(export.Rows) > maxRows {
+			text.WriteString(fmt.Sprintf("\nThe full result is in %s.\n", shared.File))
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
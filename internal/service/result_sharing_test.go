@@ -0,0 +1,176 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/report"
+)
+
+// sharedReference extracts the reference from a share_result response
+func sharedReference(t *testing.T, text string) string {
+	t.Helper()
+	start := strings.Index(text, "**Reference:** ")
+	if start < 0 {
+		t.Fatalf("Expected a reference, got %s", text)
+	}
+	line := text[start+len("**Reference:** "):]
+	return line[:strings.Index(line, "\n")]
+}
+
+func TestWriteAndResolveSharedResult(t *testing.T) {
+	dir := t.TempDir()
+	shared, existing, err := writeSharedResult(dir, []byte("hello"), ".txt", SharedResult{Kind: sharedKindReport, Name: "hello.txt"})
+	if err != nil || existing {
+		t.Fatalf("Expected a new share, got %v %v", existing, err)
+	}
+	if !strings.HasPrefix(shared.Reference, sharedReferencePrefix) || filepath.Base(shared.File) != strings.TrimPrefix(shared.Reference, sharedReferencePrefix)+".txt" {
+		t.Errorf("Expected a hash-named file, got %+v", shared)
+	}
+	again, existing, err := writeSharedResult(dir, []byte("hello"), ".txt", SharedResult{Kind: sharedKindReport, Name: "renamed.txt"})
+	if err != nil || !existing || again.Reference != shared.Reference || again.Name != "hello.txt" {
+		t.Errorf("Expected identical content to return the existing share, got %+v %v %v", again, existing, err)
+	}
+
+	resolved, content, err := resolveSharedResult(dir, shared.Reference[:len(sharedReferencePrefix)+minSharedReferenceDigits])
+	if err != nil || resolved.Reference != shared.Reference || string(content) != "hello" {
+		t.Errorf("Expected an abbreviated reference to resolve, got %+v %q %v", resolved, content, err)
+	}
+	if _, _, err := resolveSharedResult(dir, "sha256:abc"); err == nil || !strings.Contains(err.Error(), "at least") {
+		t.Errorf("Expected a short reference to be rejected, got %v", err)
+	}
+	if _, _, err := resolveSharedResult(dir, strings.Repeat("0", 64)); err == nil || !strings.Contains(err.Error(), "no shared result") {
+		t.Errorf("Expected an unknown reference to fail, got %v", err)
+	}
+
+	if err := os.WriteFile(shared.File, []byte("tampered"), 0644); err != nil {
+		t.Fatalf("Failed to modify file: %v", err)
+	}
+	if _, _, err := resolveSharedResult(dir, shared.Reference); err == nil || !strings.Contains(err.Error(), "modified on disk") {
+		t.Errorf("Expected a modified file to be detected, got %v", err)
+	}
+}
+
+func TestShareAndGetEntityResult(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_share_test", "162112", "snap-share", &forward.NQERunResult{
+		Items: []map[string]interface{}{
+			{"name": "core-1", "vendor": "cisco"},
+			{"name": "edge-1", "vendor": "cisco"},
+			{"name": "leaf-1", "vendor": "arista"},
+		},
+	}, 2)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+
+	response, err := service.shareResult(ShareResultArgs{EntityID: entityID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reference := sharedReference(t, response.Content[0].TextContent.Text)
+
+	// Sharing again yields the same reference
+	response, err = service.shareResult(ShareResultArgs{EntityID: entityID})
+	if err != nil || sharedReference(t, response.Content[0].TextContent.Text) != reference ||
+		!strings.Contains(response.Content[0].TextContent.Text, "already shared") {
+		t.Errorf("Expected the existing share, got %v", err)
+	}
+
+	response, err = service.getSharedResult(GetSharedResultArgs{Reference: reference[:len(sharedReferencePrefix)+minSharedReferenceDigits], MaxRows: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Rows (2 of 3)") || !strings.Contains(text, "core-1") || strings.Contains(text, "leaf-1") {
+		t.Errorf("Expected a two-row preview of three rows, got %s", text)
+	}
+
+	dir, _ := sharedResultDirectory()
+	for _, path := range []string{dir, filepath.Join(dir, strings.TrimPrefix(reference, sharedReferencePrefix)+sharedMetadataSuffix)} {
+		if info, err := os.Stat(path); err != nil || info.Mode().Perm()&0077 != 0 {
+			t.Errorf("Expected %s to be private to the server user, got %v %v", path, info.Mode(), err)
+		}
+	}
+
+	// Shares of restricted networks are hidden like their entities
+	service.networkPolicy = NewNetworkAccessPolicy(nil, []string{"162112"})
+	service.memorySystem.SetVisibility(service.networkPolicy.PermitsEntity)
+	if _, err := service.getSharedResult(GetSharedResultArgs{Reference: reference}); err == nil || !strings.Contains(err.Error(), "no shared result") {
+		t.Errorf("Expected the restricted share to be hidden, got %v", err)
+	}
+	if _, err := service.shareResult(ShareResultArgs{EntityID: entityID}); err == nil {
+		t.Error("Expected sharing a restricted entity to fail")
+	}
+	service.networkPolicy = nil
+	service.memorySystem.SetVisibility(nil)
+
+	if _, err := service.shareResult(ShareResultArgs{}); err == nil {
+		t.Error("Expected an error without entity_id or report")
+	}
+	if _, err := service.shareResult(ShareResultArgs{EntityID: "missing-entity"}); err == nil {
+		t.Error("Expected an error for a missing entity")
+	}
+}
+
+func TestShareReport(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.reports = newTestReportStore(t)
+	saved, err := service.reports.Save(&report.Report{Title: "Weekly", Sections: []report.Section{{Title: "Notes", Text: "all good"}}}, "weekly", "md")
+	if err != nil {
+		t.Fatalf("Failed to save report: %v", err)
+	}
+
+	response, err := service.shareResult(ShareResultArgs{Report: saved.URI})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	reference := sharedReference(t, response.Content[0].TextContent.Text)
+	response, err = service.getSharedResult(GetSharedResultArgs{Reference: reference})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "all good") {
+		t.Errorf("Expected the report content, got %v", err)
+	}
+
+	if _, err := service.shareResult(ShareResultArgs{Report: "missing.md"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Expected an error for a missing report, got %v", err)
+	}
+}
+
+func TestRemoveStaleShares(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -30)
+	share := func(content string) *SharedResult {
+		shared, _, err := writeSharedResult(dir, []byte(content), ".json", SharedResult{Kind: sharedKindEntity, Name: content})
+		if err != nil {
+			t.Fatalf("Failed to share: %v", err)
+		}
+		return shared
+	}
+	stale, fresh := share("stale"), share("fresh")
+	os.Chtimes(stale.File, old, old)
+	os.Chtimes(filepath.Join(dir, strings.TrimPrefix(stale.Reference, sharedReferencePrefix)+sharedMetadataSuffix), old, old)
+	// Only the content of the fresh share is old; its sidecar keeps the pair
+	os.Chtimes(fresh.File, old, old)
+	orphan := filepath.Join(dir, strings.Repeat("ab", 32)+".json")
+	writeStorageFile(t, orphan, 16, old)
+
+	removed, _, err := removeStaleShares(dir, time.Now().AddDate(0, 0, -7), false)
+	if err != nil || len(removed) != 2 {
+		t.Fatalf("Expected the stale share and the orphan removed, got %v %v", removed, err)
+	}
+	if _, _, err := resolveSharedResult(dir, stale.Reference); err == nil {
+		t.Error("Expected the stale share to be gone")
+	}
+	if _, _, err := resolveSharedResult(dir, fresh.Reference); err != nil {
+		t.Errorf("Expected the fresh share kept with its content, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected only the fresh pair left, got %d files", len(entries))
+	}
+}
@@ -0,0 +1,340 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	llmSummaryObservation   = "llm_summary" // Natural-language summary of a stored result
+	maxSummarySampleRows    = 200
+	maxSummaryPromptChars   = 24000 // Prompt size cap; sample rows beyond it are dropped
+	maxSummaryRowChars      = 600   // Longer rows are cut in the prompt
+	maxSummaryTokens        = 500
+	summaryRequestTimeout   = 60 * time.Second
+	summaryRateLimitWindow  = time.Minute
+	defaultSummaryPerMinute = 6
+)
+
+// summaryInstructions is the system prompt of every summary request
+const summaryInstructions = "You summarize network data query results for network engineers. " +
+	"In 3 to 6 sentences, say what the result lists, the main groups and counts, outliers, and anything that looks misconfigured or unhealthy. " +
+	"Use only the data given. The rows are a sample spread across the result, so state totals only from the row count given."
+
+// ChatModel completes a prompt with a hosted language model
+type ChatModel interface {
+	Complete(ctx context.Context, instructions, prompt string) (string, error)
+	Name() string
+}
+
+// openAIChatModel calls an OpenAI-compatible chat completions endpoint
+type openAIChatModel struct {
+	url    string
+	model  string
+	apiKey string
+	client *http.Client
+}
+
+type openAIChatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIChatRequest struct {
+	Model     string              `json:"model"`
+	Messages  []openAIChatMessage `json:"messages"`
+	MaxTokens int                 `json:"max_tokens"`
+}
+
+type openAIChatResponse struct {
+	Choices []struct {
+		Message openAIChatMessage `json:"message"`
+	} `json:"choices"`
+	Error *openAIError `json:"error,omitempty"`
+}
+
+// Name returns the provider and model
+func (m *openAIChatModel) Name() string {
+	return "openai/" + m.model
+}
+
+// Complete sends the instructions and prompt as one chat request and returns the reply
+func (m *openAIChatModel) Complete(ctx context.Context, instructions, prompt string) (string, error) {
+	body, err := json.Marshal(openAIChatRequest{
+		Model: m.model,
+		Messages: []openAIChatMessage{
+			{Role: "system", Content: instructions},
+			{Role: "user", Content: prompt},
+		},
+		MaxTokens: maxSummaryTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.apiKey)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to reach the chat model: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+	var chat openAIChatResponse
+	if err := json.Unmarshal(data, &chat); err != nil {
+		return "", fmt.Errorf("failed to parse response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if chat.Error != nil {
+		return "", fmt.Errorf("OpenAI API error: %s (%s)", chat.Error.Message, chat.Error.Type)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("chat model returned HTTP %d", resp.StatusCode)
+	}
+	if len(chat.Choices) == 0 || strings.TrimSpace(chat.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("chat model returned no summary")
+	}
+	return strings.TrimSpace(chat.Choices[0].Message.Content), nil
+}
+
+// ResultSummarizer sends result samples to a chat model, at most perMinute requests in any
+// rolling minute so a loop of calls cannot run up the provider bill
+type ResultSummarizer struct {
+	model      ChatModel
+	perMinute  int
+	sampleRows int
+
+	mu    sync.Mutex
+	calls []time.Time // Requests in the current window, oldest first
+	now   func() time.Time
+}
+
+// NewResultSummarizer creates the summarizer configured for summarize_result. It returns nil
+// when no provider is set, and an error when the provider is unknown or has no API key.
+func NewResultSummarizer(cfg config.SummarizationConfig) (*ResultSummarizer, error) {
+	var model ChatModel
+	switch strings.ToLower(cfg.Provider) {
+	case "":
+		return nil, nil
+	case "openai":
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if apiKey == "" {
+			return nil, fmt.Errorf("summarization provider openai needs OPENAI_API_KEY")
+		}
+		model = &openAIChatModel{
+			url:    firstNonEmpty(cfg.URL, "https://api.openai.com/v1/chat/completions"),
+			model:  firstNonEmpty(cfg.Model, "gpt-4o-mini"),
+			apiKey: apiKey,
+			client: &http.Client{Timeout: summaryRequestTimeout},
+		}
+	default:
+		return nil, fmt.Errorf("unknown summarization provider %q", cfg.Provider)
+	}
+	return newResultSummarizer(model, cfg.RequestsPerMinute, cfg.SampleRows), nil
+}
+
+// newResultSummarizer wraps a chat model with the rate limit and sample size
+func newResultSummarizer(model ChatModel, perMinute, sampleRows int) *ResultSummarizer {
+	if perMinute <= 0 {
+		perMinute = defaultSummaryPerMinute
+	}
+	if sampleRows <= 0 {
+		sampleRows = defaultSampleSize
+	}
+	return &ResultSummarizer{model: model, perMinute: perMinute, sampleRows: min(sampleRows, maxSummarySampleRows), now: time.Now}
+}
+
+// reserve takes a request slot, or returns how long until one frees up
+func (r *ResultSummarizer) reserve() (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	recent := r.calls[:0]
+	for _, call := range r.calls {
+		if now.Sub(call) < summaryRateLimitWindow {
+			recent = append(recent, call)
+		}
+	}
+	r.calls = recent
+	if len(r.calls) >= r.perMinute {
+		return summaryRateLimitWindow - now.Sub(r.calls[0]), false
+	}
+	r.calls = append(r.calls, now)
+	return 0, true
+}
+
+// Summarize asks the chat model for a summary of prompt, within the rate limit
+func (r *ResultSummarizer) Summarize(ctx context.Context, prompt string) (string, error) {
+	if wait, ok := r.reserve(); !ok {
+		return "", fmt.Errorf("summarization is limited to %d requests per minute; try again in %s", r.perMinute, wait.Round(time.Second))
+	}
+	return r.model.Complete(ctx, summaryInstructions, prompt)
+}
+
+// spreadSample picks up to size rows evenly spaced across rows, keeping their order
+func spreadSample(rows []map[string]interface{}, size int) []map[string]interface{} {
+	if len(rows) <= size {
+		return rows
+	}
+	sample := make([]map[string]interface{}, 0, size)
+	for i := 0; i < size; i++ {
+		sample = append(sample, rows[i*len(rows)/size])
+	}
+	return sample
+}
+
+// buildSummaryPrompt describes a stored result to the chat model: where it came from, its row
+// count and columns, and a sample of its rows, within maxSummaryPromptChars. It returns the
+// prompt and the number of sample rows it holds.
+func buildSummaryPrompt(entity *Entity, query *NQEQueryIndexEntry, rows []map[string]interface{}, sampleRows int, focus string) (string, int) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Stored result: %s\n", entity.Name)
+	if queryID := resultValueString(entity.Metadata["query_id"]); queryID != "" {
+		fmt.Fprintf(&b, "Query: %s", queryID)
+		if query != nil {
+			fmt.Fprintf(&b, " (%s)", strings.Trim(query.Path+": "+firstNonEmpty(query.Intent, query.Description), ": "))
+		}
+		b.WriteString("\n")
+	}
+	for _, key := range []string{"network_id", "snapshot_id"} {
+		if value := resultValueString(entity.Metadata[key]); value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", key, value)
+		}
+	}
+	fmt.Fprintf(&b, "Total rows: %d\n", len(rows))
+	if focus != "" {
+		fmt.Fprintf(&b, "Focus the summary on: %s\n", focus)
+	}
+
+	b.WriteString("\nColumns (types, non-null values in the sample, example):\n")
+	sample := spreadSample(rows, sampleRows)
+	for _, column := range describeSampleColumns(sample) {
+		fmt.Fprintf(&b, "- %s: %s, %d/%d, %s\n", column.Name, strings.Join(column.Types, "/"), column.NonNull, len(sample), column.Example)
+	}
+
+	fmt.Fprintf(&b, "\nSample rows spread across the result, one JSON object per line:\n")
+	included := 0
+	for _, row := range sample {
+		line, err := json.Marshal(row)
+		if err != nil {
+			continue
+		}
+		text := truncateString(string(line), maxSummaryRowChars)
+		if b.Len()+len(text)+1 > maxSummaryPromptChars {
+			break
+		}
+		b.WriteString(text + "\n")
+		included++
+	}
+	return b.String(), included
+}
+
+// summarizeResult summarizes a stored result with the configured chat model and stores the
+// summary on the result, so later sessions can recall what it showed without reading the rows
+func (s *ForwardMCPService) summarizeResult(args SummarizeResultArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("summarize_result", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if args.EntityID == "" {
+		return nil, fmt.Errorf("entity_id is required")
+	}
+	entity, rows, err := s.loadStoredResultRows(args.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !args.Refresh {
+		if stored := s.storedResultSummary(entity.ID, args.Focus); stored != nil {
+			return mcp.NewToolResponse(mcp.NewTextContent(formatResultSummary(entity, stored.Content, stored.Metadata, s.timeFormatter.Format(stored.CreatedAt)) +
+				"\nThis summary was stored earlier; pass refresh=true to summarize again.")), nil
+		}
+	}
+	if s.summarizer == nil {
+		return nil, fmt.Errorf("summarize_result needs a chat model: set FORWARD_SUMMARY_PROVIDER=openai and OPENAI_API_KEY")
+	}
+
+	sampleRows := s.summarizer.sampleRows
+	if args.SampleRows > 0 {
+		sampleRows = min(args.SampleRows, maxSummarySampleRows)
+	}
+	var query *NQEQueryIndexEntry
+	if s.queryIndex != nil {
+		query, _ = s.queryIndex.GetQueryByID(resultValueString(entity.Metadata["query_id"]))
+	}
+	prompt, included := buildSummaryPrompt(entity, query, rows, sampleRows, args.Focus)
+	// Result rows leave the server here, so they are masked even when tool output is not
+	prompt = s.outboundRedactor().Redact(prompt)
+
+	ctx, cancel := context.WithTimeout(context.Background(), summaryRequestTimeout)
+	defer cancel()
+	summary, err := s.summarizer.Summarize(ctx, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize %s: %w", entity.Name, err)
+	}
+
+	metadata := map[string]interface{}{
+		"model":       s.summarizer.model.Name(),
+		"focus":       args.Focus,
+		"sample_rows": included,
+		"row_count":   len(rows),
+	}
+	if _, err := s.memorySystem.AddObservation(entity.ID, summary, llmSummaryObservation, metadata); err != nil {
+		s.logger.Warn("Failed to store the summary of %s: %v", entity.ID, err)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(formatResultSummary(entity, summary, metadata, "") +
+		"\nThe summary is stored on the result; summarize_result and get_nqe_result_summary return it later without another request.")), nil
+}
+
+// storedResultSummary returns the most recent summary of a result written with focus, or nil
+func (s *ForwardMCPService) storedResultSummary(entityID, focus string) *Observation {
+	observations, err := s.memorySystem.GetObservations(entityID, llmSummaryObservation)
+	if err != nil {
+		return nil
+	}
+	var latest *Observation
+	for _, observation := range observations {
+		if resultValueString(observation.Metadata["focus"]) != focus {
+			continue
+		}
+		if latest == nil || observation.CreatedAt.After(latest.CreatedAt) {
+			latest = observation
+		}
+	}
+	return latest
+}
+
+// formatResultSummary renders a summary with the model and sample it came from
+func formatResultSummary(entity *Entity, summary string, metadata map[string]interface{}, storedAt string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Summary of %s\n\n%s\n\n", entity.Name, summary)
+	sampleRows, _ := timeSeriesNumber(metadata["sample_rows"])
+	rowCount, _ := timeSeriesNumber(metadata["row_count"])
+	fmt.Fprintf(&b, "_%s, from a sample of %d of %d rows", resultValueString(metadata["model"]), int(sampleRows), int(rowCount))
+	if focus := resultValueString(metadata["focus"]); focus != "" {
+		fmt.Fprintf(&b, ", focused on %s", focus)
+	}
+	if storedAt != "" {
+		fmt.Fprintf(&b, ", stored %s", storedAt)
+	}
+	b.WriteString("_\n")
+	return b.String()
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+)
+
+// fakeChatModel returns a fixed reply and keeps the prompts it was sent
+type fakeChatModel struct {
+	prompts []string
+}
+
+func (m *fakeChatModel) Name() string { return "fake/model" }
+
+func (m *fakeChatModel) Complete(ctx context.Context, instructions, prompt string) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	return fmt.Sprintf("Summary %d: 3 devices report interface errors.", len(m.prompts)), nil
+}
+
+func storeSummaryTestResult(t *testing.T, service *ForwardMCPService, rows int) string {
+	result := &forward.NQERunResult{}
+	for i := 0; i < rows; i++ {
+		result.Items = append(result.Items, map[string]interface{}{"device": fmt.Sprintf("leaf-%d", i), "errors": i % 7})
+	}
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_errors", "162112", "snap-1", result, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entityID
+}
+
+func TestSummarizeResult(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	model := &fakeChatModel{}
+	service.summarizer = newResultSummarizer(model, 10, 20)
+	entityID := storeSummaryTestResult(t, service, 1000)
+
+	response, err := service.summarizeResult(SummarizeResultArgs{EntityID: entityID})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"## Summary of FQ_errors-162112-snap-1", "Summary 1: 3 devices", "_fake/model, from a sample of 20 of 1000 rows_"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	prompt := model.prompts[0]
+	for _, expected := range []string{"Query: FQ_errors", "Total rows: 1000", "- device: string, 20/20, leaf-0", `"device":"leaf-950"`} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("Expected %q in the prompt: %s", expected, prompt)
+		}
+	}
+
+	// The stored summary is recalled without another request, here and in the result summary
+	response, _ = service.summarizeResult(SummarizeResultArgs{EntityID: entityID})
+	if text := response.Content[0].TextContent.Text; len(model.prompts) != 1 || !strings.Contains(text, "Summary 1") || !strings.Contains(text, "stored earlier") {
+		t.Errorf("Expected the stored summary, got %d requests and: %s", len(model.prompts), text)
+	}
+	service.memorySystem.AddObservation(entityID, "1000 rows, 2 columns", "nqe_result_summary", nil)
+	response, _ = service.getNQEResultSummary(GetNQEResultChunksArgs{EntityID: entityID})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Summary (fake/model") {
+		t.Errorf("Expected the stored summary in get_nqe_result_summary: %s", text)
+	}
+
+	// Another focus, or refresh, asks the model again
+	service.summarizeResult(SummarizeResultArgs{EntityID: entityID, Focus: "errors"})
+	service.summarizeResult(SummarizeResultArgs{EntityID: entityID, Refresh: true})
+	if len(model.prompts) != 3 || !strings.Contains(model.prompts[1], "Focus the summary on: errors") {
+		t.Errorf("Expected two more requests, got %d", len(model.prompts))
+	}
+
+	service.summarizer = nil
+	if _, err := service.summarizeResult(SummarizeResultArgs{EntityID: entityID, Refresh: true}); err == nil || !strings.Contains(err.Error(), "FORWARD_SUMMARY_PROVIDER") {
+		t.Errorf("Expected a configuration error, got %v", err)
+	}
+}
+
+func TestSummarizeResultRedactsPrompt(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	model := &fakeChatModel{}
+	service.summarizer = newResultSummarizer(model, 10, 20)
+	service.redactor = NewRedactor(config.RedactionConfig{Enabled: true}, service.logger)
+	result := &forward.NQERunResult{Items: []map[string]interface{}{{"device": "fw-1", "config": "snmp-server community s3cret RO"}}}
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_cfg", "162112", "snap-1", result, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.summarizeResult(SummarizeResultArgs{EntityID: entityID}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(model.prompts[0], "s3cret") {
+		t.Errorf("Expected the community string masked: %s", model.prompts[0])
+	}
+
+	// The built-in rules still apply with output redaction off
+	service.redactor = nil
+	if _, err := service.summarizeResult(SummarizeResultArgs{EntityID: entityID, Refresh: true}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(model.prompts[1], "s3cret") {
+		t.Errorf("Expected the community string masked without output redaction: %s", model.prompts[1])
+	}
+}
+
+func TestResultSummarizerRateLimit(t *testing.T) {
+	summarizer := newResultSummarizer(&fakeChatModel{}, 2, 0)
+	now := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	summarizer.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if _, err := summarizer.Summarize(context.Background(), "rows"); err != nil {
+			t.Fatalf("Request %d: expected no error, got %v", i, err)
+		}
+	}
+	now = now.Add(20 * time.Second)
+	if _, err := summarizer.Summarize(context.Background(), "rows"); err == nil || !strings.Contains(err.Error(), "try again in 40s") {
+		t.Errorf("Expected the third request rejected, got %v", err)
+	}
+	now = now.Add(41 * time.Second)
+	if _, err := summarizer.Summarize(context.Background(), "rows"); err != nil {
+		t.Errorf("Expected a slot after the window, got %v", err)
+	}
+}
+
+func TestBuildSummaryPromptBounded(t *testing.T) {
+	rows := make([]map[string]interface{}, 500)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"device": fmt.Sprintf("leaf-%d", i), "config": strings.Repeat("x", 2000)}
+	}
+	prompt, included := buildSummaryPrompt(&Entity{Name: "big"}, nil, rows, 200, "")
+	if len(prompt) > maxSummaryPromptChars || included == 0 || included == 200 {
+		t.Errorf("Expected a prompt within %d chars holding part of the sample, got %d chars and %d rows", maxSummaryPromptChars, len(prompt), included)
+	}
+	if sample := spreadSample(rows, 4); sample[1]["device"] != "leaf-125" || sample[3]["device"] != "leaf-375" {
+		t.Errorf("Expected rows spread evenly, got %v %v", sample[1]["device"], sample[3]["device"])
+	}
+}
+
+func TestOpenAIChatModel(t *testing.T) {
+	var request openAIChatRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk-test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":{"message":"bad key","type":"invalid_request_error"}}`))
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+		w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" All 12 leaves are healthy. "}}]}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("OPENAI_API_KEY", "sk-test")
+	summarizer, err := NewResultSummarizer(config.SummarizationConfig{Provider: "openai", URL: server.URL, Model: "gpt-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := summarizer.Summarize(context.Background(), "rows")
+	if err != nil || summary != "All 12 leaves are healthy." {
+		t.Fatalf("Expected the trimmed reply, got %q %v", summary, err)
+	}
+	if request.Model != "gpt-test" || len(request.Messages) != 2 || request.Messages[0].Role != "system" || request.Messages[1].Content != "rows" {
+		t.Errorf("Unexpected request: %+v", request)
+	}
+
+	t.Setenv("OPENAI_API_KEY", "sk-wrong")
+	summarizer, _ = NewResultSummarizer(config.SummarizationConfig{Provider: "openai", URL: server.URL})
+	if _, err := summarizer.Summarize(context.Background(), "rows"); err == nil || !strings.Contains(err.Error(), "bad key") {
+		t.Errorf("Expected the API error, got %v", err)
+	}
+
+	if summarizer, err := NewResultSummarizer(config.SummarizationConfig{}); summarizer != nil || err != nil {
+		t.Error("Expected summarization off without a provider")
+	}
+	t.Setenv("OPENAI_API_KEY", "")
+	if _, err := NewResultSummarizer(config.SummarizationConfig{Provider: "openai"}); err == nil {
+		t.Error("Expected an error without OPENAI_API_KEY")
+	}
+	if _, err := NewResultSummarizer(config.SummarizationConfig{Provider: "other"}); err == nil {
+		t.Error("Expected an error for an unknown provider")
+	}
+}
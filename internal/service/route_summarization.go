@@ -0,0 +1,411 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// summarizationRoutesQuery returns one row per route of every device and VRF; the address
+// family table (ipv4Unicast or ipv6Unicast) is filled in
+const summarizationRoutesQuery = `foreach device in network.devices
+foreach networkInstance in device.networkInstances
+foreach entry in networkInstance.afts.%s.ipEntries
+select {
+  device: device.name,
+  vrf: networkInstance.name,
+  prefix: toString(entry.prefix)
+}`
+
+const (
+	maxSummarizationReportRows = 50
+	maxSummaryComponentsShown  = 4
+)
+
+// RouteSummary is a supernet that can replace the separately advertised prefixes of one site
+type RouteSummary struct {
+	Location   string   `json:"location"`
+	Summary    string   `json:"summary"`
+	Components []string `json:"components"`
+	Coverage   float64  `json:"coverage"`
+	Devices    []string `json:"devices"`
+
+	prefix netip.Prefix
+}
+
+// DeviceRouteReduction estimates how much smaller a device's routing table gets when the
+// other sites advertise their summaries
+type DeviceRouteReduction struct {
+	Device    string  `json:"device"`
+	Location  string  `json:"location"`
+	Routes    int     `json:"routes"`
+	Replaced  int     `json:"replaced"`
+	After     int     `json:"after"`
+	Reduction float64 `json:"reduction_percent"`
+}
+
+// siteSubnet is a LAN subnet of a site with the devices it is configured on
+type siteSubnet struct {
+	prefix  netip.Prefix
+	devices []string
+}
+
+// siteSubnets groups the LAN subnets of the inventory by site (device location), as the site
+// turn-up checks find them, and drops subnets another subnet of the same site covers.
+// Point-to-point links are not site prefixes and are left out.
+func siteSubnets(devices []forward.Device) map[string][]siteSubnet {
+	byLocation := make(map[string][]forward.Device)
+	for _, device := range devices {
+		location := firstNonEmpty(device.LocationID, "unknown")
+		byLocation[location] = append(byLocation[location], device)
+	}
+
+	sites := make(map[string][]siteSubnet, len(byLocation))
+	for location, siteDevices := range byLocation {
+		var list []siteSubnet
+		for _, lan := range siteLANPrefixes(siteDevices) {
+			if prefix, err := netip.ParsePrefix(lan.Prefix); err == nil {
+				list = append(list, siteSubnet{prefix: prefix.Masked(), devices: []string{lan.Device}})
+			}
+		}
+		sortSiteSubnets(list)
+		kept := list[:0]
+		for _, candidate := range list {
+			if len(kept) > 0 && kept[len(kept)-1].prefix.Overlaps(candidate.prefix) {
+				last := &kept[len(kept)-1]
+				last.devices = append(last.devices, candidate.devices...)
+				continue
+			}
+			kept = append(kept, candidate)
+		}
+		sites[location] = kept
+	}
+	return sites
+}
+
+// sortSiteSubnets orders prefixes by family, address and then length, so a covering prefix
+// comes before the prefixes inside it
+func sortSiteSubnets(prefixes []siteSubnet) {
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := prefixes[i].prefix, prefixes[j].prefix
+		if a.Addr().Is4() != b.Addr().Is4() {
+			return a.Addr().Is4()
+		}
+		if order := a.Addr().Compare(b.Addr()); order != 0 {
+			return order < 0
+		}
+		return a.Bits() < b.Bits()
+	})
+}
+
+// prefixSize returns the number of addresses in a prefix as a float, exact enough to compare
+// coverage of IPv6 prefixes
+func prefixSize(prefix netip.Prefix) float64 {
+	return math.Ldexp(1, prefix.Addr().BitLen()-prefix.Bits())
+}
+
+// commonSupernet returns the longest prefix containing every prefix of a sorted,
+// non-overlapping list of one family
+func commonSupernet(prefixes []siteSubnet) netip.Prefix {
+	first, last := prefixes[0].prefix, prefixes[len(prefixes)-1].prefix
+	bits := min(first.Bits(), last.Bits())
+	for ; bits > 0; bits-- {
+		candidate, _ := first.Addr().Prefix(bits)
+		if candidate.Contains(last.Addr()) {
+			return candidate
+		}
+	}
+	candidate, _ := first.Addr().Prefix(0)
+	return candidate
+}
+
+// summarizeSite finds the largest supernets that replace two or more of a site's prefixes
+// without overlapping a prefix of another site and with at least minCoverage of their
+// addresses in use. Prefixes are split by the next address bit until a supernet qualifies.
+func summarizeSite(location string, prefixes []siteSubnet, foreign []netip.Prefix, minCoverage float64) []RouteSummary {
+	if len(prefixes) < 2 {
+		return nil
+	}
+	// Families never share a supernet
+	if prefixes[0].prefix.Addr().Is4() != prefixes[len(prefixes)-1].prefix.Addr().Is4() {
+		split := sort.Search(len(prefixes), func(i int) bool { return !prefixes[i].prefix.Addr().Is4() })
+		return append(summarizeSite(location, prefixes[:split], foreign, minCoverage),
+			summarizeSite(location, prefixes[split:], foreign, minCoverage)...)
+	}
+
+	supernet := commonSupernet(prefixes)
+	used := 0.0
+	for _, prefix := range prefixes {
+		used += prefixSize(prefix.prefix)
+	}
+	coverage := used / prefixSize(supernet)
+	overlapsForeign := false
+	for _, other := range foreign {
+		if other.Overlaps(supernet) {
+			overlapsForeign = true
+			break
+		}
+	}
+	if !overlapsForeign && coverage >= minCoverage-1e-9 {
+		summary := RouteSummary{Location: location, Summary: supernet.String(), Coverage: math.Round(coverage*1000) / 1000, prefix: supernet}
+		seen := make(map[string]bool)
+		for _, prefix := range prefixes {
+			summary.Components = append(summary.Components, prefix.prefix.String())
+			for _, device := range prefix.devices {
+				if !seen[device] {
+					seen[device] = true
+					summary.Devices = append(summary.Devices, device)
+				}
+			}
+		}
+		sort.Strings(summary.Devices)
+		return []RouteSummary{summary}
+	}
+
+	// The halves of the supernet hold at least one prefix each
+	half, _ := supernet.Addr().Prefix(supernet.Bits() + 1)
+	split := sort.Search(len(prefixes), func(i int) bool { return !half.Contains(prefixes[i].prefix.Addr()) })
+	return append(summarizeSite(location, prefixes[:split], foreign, minCoverage),
+		summarizeSite(location, prefixes[split:], foreign, minCoverage)...)
+}
+
+// findRouteSummaries proposes summaries for every site, or only the given one
+func findRouteSummaries(sites map[string][]siteSubnet, location string, minCoverage float64) []RouteSummary {
+	var summaries []RouteSummary
+	for site, prefixes := range sites {
+		if location != "" && !strings.EqualFold(site, location) {
+			continue
+		}
+		var foreign []netip.Prefix
+		for other, otherPrefixes := range sites {
+			if other == site {
+				continue
+			}
+			for _, prefix := range otherPrefixes {
+				foreign = append(foreign, prefix.prefix)
+			}
+		}
+		summaries = append(summaries, summarizeSite(site, prefixes, foreign, minCoverage)...)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if len(summaries[i].Components) != len(summaries[j].Components) {
+			return len(summaries[i].Components) > len(summaries[j].Components)
+		}
+		return compareIPPrefixes(summaries[i].Summary, summaries[j].Summary)
+	})
+	return summaries
+}
+
+// estimateRouteReductions counts, per device and VRF, the routes inside each summary of
+// another site. Those routes collapse into the summary, which is counted once unless the
+// device already has it. Devices of the summarized site keep their specific routes.
+func estimateRouteReductions(routes []map[string]interface{}, summaries []RouteSummary, deviceSites map[string]string) []DeviceRouteReduction {
+	type tableKey struct{ device, vrf string }
+	tables := make(map[tableKey][]netip.Prefix)
+	totals := make(map[string]int)
+	for _, row := range routes {
+		device := resultValueString(row["device"])
+		prefix, err := netip.ParsePrefix(resultValueString(row["prefix"]))
+		if device == "" || err != nil {
+			continue
+		}
+		key := tableKey{device, resultValueString(row["vrf"])}
+		tables[key] = append(tables[key], prefix.Masked())
+		totals[device]++
+	}
+
+	replaced := make(map[string]int)
+	added := make(map[string]int)
+	for key, table := range tables {
+		site := deviceSites[key.device]
+		for _, summary := range summaries {
+			if summary.Location == site {
+				continue
+			}
+			inside, hasSummary := 0, false
+			for _, prefix := range table {
+				if prefix == summary.prefix {
+					hasSummary = true
+				} else if prefix.Bits() > summary.prefix.Bits() && summary.prefix.Contains(prefix.Addr()) {
+					inside++
+				}
+			}
+			if inside == 0 || (inside == 1 && !hasSummary) {
+				continue
+			}
+			replaced[key.device] += inside
+			if !hasSummary {
+				added[key.device]++
+			}
+		}
+	}
+
+	reductions := make([]DeviceRouteReduction, 0, len(totals))
+	for device, total := range totals {
+		reduction := DeviceRouteReduction{Device: device, Location: firstNonEmpty(deviceSites[device], "unknown"), Routes: total, Replaced: replaced[device]}
+		reduction.After = total - replaced[device] + added[device]
+		if total > 0 {
+			reduction.Reduction = math.Round(float64(total-reduction.After)/float64(total)*1000) / 10
+		}
+		reductions = append(reductions, reduction)
+	}
+	sort.Slice(reductions, func(i, j int) bool {
+		if saved := reductions[i].Routes - reductions[i].After - reductions[j].Routes + reductions[j].After; saved != 0 {
+			return saved > 0
+		}
+		return reductions[i].Device < reductions[j].Device
+	})
+	return reductions
+}
+
+// analyzeSummarization proposes per-site route summaries and estimates the routing table
+// reduction on the devices of the other sites
+func (s *ForwardMCPService) analyzeSummarization(args AnalyzeSummarizationArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("analyze_summarization", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	minCoverage := args.MinCoverage
+	if minCoverage == 0 {
+		minCoverage = 1
+	}
+	if minCoverage < 0 || minCoverage > 1 {
+		return nil, fmt.Errorf("min_coverage must be between 0 and 1, got %g", args.MinCoverage)
+	}
+
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device inventory: %w", err)
+	}
+	sites := siteSubnets(devices)
+	summaries := findRouteSummaries(sites, args.Location, minCoverage)
+	replaced := 0
+	for _, summary := range summaries {
+		replaced += len(summary.Components)
+	}
+
+	deviceSites := make(map[string]string, len(devices))
+	for _, device := range devices {
+		deviceSites[device.Name] = firstNonEmpty(device.LocationID, "unknown")
+	}
+	var reductions []DeviceRouteReduction
+	routesNote := ""
+	if len(summaries) > 0 {
+		// Only the tables of the families that have summaries are fetched
+		families := make(map[string]bool)
+		for _, summary := range summaries {
+			if summary.prefix.Addr().Is4() {
+				families["ipv4Unicast"] = true
+			} else {
+				families["ipv6Unicast"] = true
+			}
+		}
+		var routes []map[string]interface{}
+		for _, family := range []string{"ipv4Unicast", "ipv6Unicast"} {
+			if !families[family] {
+				continue
+			}
+			items, err := s.fetchAllNQEQueryItems(networkID, snapshotID, fmt.Sprintf(summarizationRoutesQuery, family))
+			if err != nil {
+				s.logger.Debug("Routing tables unavailable for summarization on network %s: %v", networkID, err)
+				routesNote = fmt.Sprintf("Routing tables could not be fetched (%v); the reduction per device is not estimated.", err)
+				break
+			}
+			routes = append(routes, items...)
+		}
+		if routesNote == "" {
+			reductions = estimateRouteReductions(routes, summaries, deviceSites)
+		}
+	}
+
+	if strings.EqualFold(args.Format, "json") {
+		result := map[string]interface{}{
+			"network_id":        networkID,
+			"min_coverage":      minCoverage,
+			"summaries":         summaries,
+			"prefixes_replaced": replaced,
+			"device_reductions": reductions,
+		}
+		if routesNote != "" {
+			result["routes_note"] = routesNote
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode summarization report: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# Route Summarization: network %s\n\n", networkID))
+	if len(summaries) == 0 {
+		report.WriteString("No site has two or more contiguous prefixes that a summary could replace")
+		if minCoverage == 1 {
+			report.WriteString(" exactly; a min_coverage below 1 allows summaries that include unused space")
+		}
+		report.WriteString(".\n")
+		return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+	}
+	summarySites := make(map[string]bool)
+	for _, summary := range summaries {
+		summarySites[summary.Location] = true
+	}
+	report.WriteString(fmt.Sprintf("%d summaries could replace %d separately advertised prefixes at %d sites", len(summaries), replaced, len(summarySites)))
+	if minCoverage < 1 {
+		report.WriteString(fmt.Sprintf(" (summaries may include up to %.0f%% unused space; no summary covers another site's subnet)", (1-minCoverage)*100))
+	}
+	report.WriteString(".\n")
+
+	report.WriteString("\n## Summaries\n\n| Site | Summary | Replaces | Coverage | Prefixes |\n|---|---|---|---|---|\n")
+	for i, summary := range summaries {
+		if i == maxSummarizationReportRows {
+			report.WriteString(fmt.Sprintf("\n… %d more summaries (use format=json for all)\n", len(summaries)-i))
+			break
+		}
+		components := summary.Components
+		more := ""
+		if len(components) > maxSummaryComponentsShown {
+			more = fmt.Sprintf(" … +%d", len(components)-maxSummaryComponentsShown)
+			components = components[:maxSummaryComponentsShown]
+		}
+		report.WriteString(fmt.Sprintf("| %s | %s | %d | %.0f%% | %s%s |\n", summary.Location, summary.Summary, len(summary.Components),
+			summary.Coverage*100, strings.Join(components, ", "), more))
+	}
+
+	if routesNote != "" {
+		report.WriteString("\n" + routesNote + "\n")
+		return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+	}
+	totalBefore, totalAfter, affected := 0, 0, 0
+	for _, reduction := range reductions {
+		totalBefore += reduction.Routes
+		totalAfter += reduction.After
+		if reduction.After < reduction.Routes {
+			affected++
+		}
+	}
+	report.WriteString(fmt.Sprintf("\n## Routing Table Reduction\n\nEstimated %d fewer routes on %d devices (%d → %d routes in all VRFs).\n\n", totalBefore-totalAfter, affected, totalBefore, totalAfter))
+	if affected == 0 {
+		report.WriteString("No device outside the summarized sites carries two or more of the summarized prefixes.\n")
+		return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+	}
+	report.WriteString("| Device | Site | Routes | Replaced | After | Reduction |\n|---|---|---|---|---|---|\n")
+	for i, reduction := range reductions[:affected] {
+		if i == maxSummarizationReportRows {
+			report.WriteString(fmt.Sprintf("\n… %d more devices (use format=json for all)\n", affected-i))
+			break
+		}
+		report.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d | %.1f%% |\n", reduction.Device, reduction.Location,
+			reduction.Routes, reduction.Replaced, reduction.After, reduction.Reduction))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
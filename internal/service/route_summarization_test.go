@@ -0,0 +1,130 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func summarizationTestService() *ForwardMCPService {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	device := func(name, location string, addresses ...string) forward.Device {
+		device := forward.Device{Name: name, LocationID: location}
+		for i, address := range addresses {
+			device.Interfaces = append(device.Interfaces, forward.DeviceInterface{Name: "eth" + string(rune('0'+i)), IPAddress: address})
+		}
+		return device
+	}
+	client.devices = []forward.Device{
+		device("rtr-a", "site-a", "10.1.0.1/24", "10.1.1.1/24", "10.1.8.1/24", "10.3.0.1/24", "192.0.2.1/30"),
+		device("sw-a", "site-a", "10.1.2.1/24", "10.1.3.1/24", "10.3.2.1/24", "10.3.3.1/24", "10.1.2.129/25"),
+		device("rtr-b", "site-b", "10.2.0.1/24", "10.2.1.1/24", "10.2.3.1/24", "192.0.2.2/30"),
+		device("rtr-c", "site-c", "10.3.1.1/24", "10.9.0.1/24"),
+	}
+	route := func(device, prefix string) map[string]interface{} {
+		return map[string]interface{}{"device": device, "vrf": "default", "prefix": prefix}
+	}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		route("rtr-c", "10.1.0.0/24"), route("rtr-c", "10.1.1.0/24"), route("rtr-c", "10.1.2.0/24"), route("rtr-c", "10.1.3.0/24"),
+		route("rtr-c", "10.2.0.0/24"), route("rtr-c", "10.2.1.0/24"), route("rtr-c", "0.0.0.0/0"),
+		route("rtr-a", "10.1.0.0/24"), route("rtr-a", "10.1.1.0/24"), route("rtr-a", "10.1.2.0/24"), route("rtr-a", "10.1.3.0/24"),
+		route("rtr-a", "10.2.0.0/24"), route("rtr-a", "10.2.1.0/24"), route("rtr-a", "10.2.0.0/23"),
+		route("rtr-b", "0.0.0.0/0"),
+	}}
+	service.deviceCache = nil
+	return service
+}
+
+func TestFindRouteSummaries(t *testing.T) {
+	sites := siteSubnets(summarizationTestService().forwardClient.(*MockForwardClient).devices)
+	if len(sites["site-a"]) != 8 {
+		t.Errorf("Expected the covered /25 and the point-to-point link dropped, got %+v", sites["site-a"])
+	}
+
+	summaries := func(minCoverage float64) map[string]string {
+		found := make(map[string]string)
+		for _, summary := range findRouteSummaries(sites, "", minCoverage) {
+			found[summary.Summary] = summary.Location + " " + strings.Join(summary.Components, ",")
+		}
+		return found
+	}
+	exact := summaries(1)
+	expected := map[string]string{
+		"10.1.0.0/22": "site-a 10.1.0.0/24,10.1.1.0/24,10.1.2.0/24,10.1.3.0/24",
+		"10.3.2.0/23": "site-a 10.3.2.0/24,10.3.3.0/24",
+		"10.2.0.0/23": "site-b 10.2.0.0/24,10.2.1.0/24",
+	}
+	if len(exact) != len(expected) {
+		t.Errorf("Expected %v, got %v", expected, exact)
+	}
+	for summary, components := range expected {
+		if exact[summary] != components {
+			t.Errorf("Expected %s to replace %s, got %q", summary, components, exact[summary])
+		}
+	}
+
+	// Unused space is allowed down to the coverage, but never another site's subnet
+	loose := summaries(0.75)
+	if loose["10.2.0.0/22"] != "site-b 10.2.0.0/24,10.2.1.0/24,10.2.3.0/24" {
+		t.Errorf("Expected a 75%% summary for site-b, got %v", loose)
+	}
+	if _, found := loose["10.3.0.0/22"]; found || loose["10.3.2.0/23"] == "" {
+		t.Errorf("Expected site-c's 10.3.1.0/24 to keep 10.3.0.0/24 out of a summary, got %v", loose)
+	}
+	if only := findRouteSummaries(sites, "SITE-B", 1); len(only) != 1 || only[0].Summary != "10.2.0.0/23" {
+		t.Errorf("Expected only site-b's summary, got %+v", only)
+	}
+}
+
+func TestAnalyzeSummarization(t *testing.T) {
+	service := summarizationTestService()
+
+	response, err := service.analyzeSummarization(AnalyzeSummarizationArgs{NetworkID: "162112", Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report struct {
+		Replaced   int                    `json:"prefixes_replaced"`
+		Reductions []DeviceRouteReduction `json:"device_reductions"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	if report.Replaced != 8 || len(report.Reductions) != 3 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if c := report.Reductions[0]; c != (DeviceRouteReduction{Device: "rtr-c", Location: "site-c", Routes: 7, Replaced: 6, After: 3, Reduction: 57.1}) {
+		t.Errorf("Unexpected reduction for rtr-c: %+v", c)
+	}
+	// rtr-a keeps its own site's routes and already has site-b's summary
+	if a := report.Reductions[1]; a != (DeviceRouteReduction{Device: "rtr-a", Location: "site-a", Routes: 7, Replaced: 2, After: 5, Reduction: 28.6}) {
+		t.Errorf("Unexpected reduction for rtr-a: %+v", a)
+	}
+
+	response, err = service.analyzeSummarization(AnalyzeSummarizationArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"3 summaries could replace 8 separately advertised prefixes at 2 sites",
+		"| site-a | 10.1.0.0/22 | 4 | 100% | 10.1.0.0/24, 10.1.1.0/24, 10.1.2.0/24, 10.1.3.0/24 |",
+		"Estimated 6 fewer routes on 2 devices (15 → 9 routes in all VRFs)", "| rtr-c | site-c | 7 | 6 | 3 | 57.1% |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "| rtr-b |") {
+		t.Errorf("Expected devices without a reduction left out: %s", text)
+	}
+
+	if _, err := service.analyzeSummarization(AnalyzeSummarizationArgs{NetworkID: "162112", MinCoverage: 1.5}); err == nil {
+		t.Error("Expected an out of range coverage to be rejected")
+	}
+	response, _ = service.analyzeSummarization(AnalyzeSummarizationArgs{NetworkID: "162112", Location: "site-c"})
+	if !strings.Contains(response.Content[0].TextContent.Text, "a min_coverage below 1 allows summaries") {
+		t.Errorf("Expected no summaries for site-c: %s", response.Content[0].TextContent.Text)
+	}
+}
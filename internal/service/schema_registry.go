@@ -0,0 +1,269 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	querySchemaType        = "query_schema"
+	maxSchemaChangesKept   = 20   // Changes kept per query, oldest dropped first
+	maxQuerySchemasScanned = 5000 // Query schemas list_schema_changes reads
+)
+
+// SchemaChange is a difference between the columns of two runs of the same query
+type SchemaChange struct {
+	QueryID            string            `json:"query_id"`
+	NetworkID          string            `json:"network_id"`
+	SnapshotID         string            `json:"snapshot_id"`
+	PreviousSnapshotID string            `json:"previous_snapshot_id,omitempty"`
+	Added              []string          `json:"added,omitempty"`
+	Removed            []string          `json:"removed,omitempty"`
+	TypeChanged        map[string]string `json:"type_changed,omitempty"` // column → "old → new"
+	DetectedAt         time.Time         `json:"detected_at"`
+}
+
+// Summary describes the change in one line
+func (c SchemaChange) Summary() string {
+	var parts []string
+	if len(c.Added) > 0 {
+		parts = append(parts, "added "+strings.Join(c.Added, ", "))
+	}
+	if len(c.Removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(c.Removed, ", "))
+	}
+	columns := make([]string, 0, len(c.TypeChanged))
+	for column := range c.TypeChanged {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		parts = append(parts, fmt.Sprintf("%s changed %s", column, c.TypeChanged[column]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// resultSchema returns the columns of result rows with the JSON type of their values. Columns
+// seen only with null values are "null"; columns holding several types join them with "|".
+func resultSchema(rows []map[string]interface{}) map[string]string {
+	kinds := make(map[string]map[string]bool)
+	for _, row := range rows {
+		for column, value := range row {
+			if kinds[column] == nil {
+				kinds[column] = make(map[string]bool)
+			}
+			if kind := jsonKind(value); kind != "null" {
+				kinds[column][kind] = true
+			}
+		}
+	}
+	schema := make(map[string]string, len(kinds))
+	for column, seen := range kinds {
+		if len(seen) == 0 {
+			schema[column] = "null"
+			continue
+		}
+		names := make([]string, 0, len(seen))
+		for kind := range seen {
+			names = append(names, kind)
+		}
+		sort.Strings(names)
+		schema[column] = strings.Join(names, "|")
+	}
+	return schema
+}
+
+// jsonKind names the JSON type of a decoded value
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64, float32, int, int64, int32, json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return "unknown"
+	}
+}
+
+// diffResultSchema compares two schemas. A column whose type was only ever null on one side
+// is not a type change, since null says nothing about the type.
+func diffResultSchema(previous, current map[string]string) (added, removed []string, typeChanged map[string]string) {
+	for column, kind := range current {
+		old, ok := previous[column]
+		switch {
+		case !ok:
+			added = append(added, column)
+		case old != kind && old != "null" && kind != "null":
+			if typeChanged == nil {
+				typeChanged = make(map[string]string)
+			}
+			typeChanged[column] = old + " → " + kind
+		}
+	}
+	for column := range previous {
+		if _, ok := current[column]; !ok {
+			removed = append(removed, column)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed, typeChanged
+}
+
+// recordQuerySchema stores the columns a run of a query returned on a network and, when they
+// differ from the previous run on that network, records and returns the schema drift. Empty results are skipped since
+// they show no columns.
+func (s *ForwardMCPService) recordQuerySchema(queryID, networkID, snapshotID string, rows []map[string]interface{}) *SchemaChange {
+	if s.memorySystem == nil || queryID == "" || len(rows) == 0 {
+		return nil
+	}
+	schema := resultSchema(rows)
+	now := time.Now().UTC()
+	metadata := map[string]interface{}{
+		"query_id":    queryID,
+		"network_id":  networkID,
+		"snapshot_id": snapshotID,
+		"columns":     MarshalCompactJSONString(schema),
+		"updated_at":  now.Format(time.RFC3339),
+	}
+
+	// Networks run different platform versions, so each keeps its own schema per query
+	name := querySchemaType + ":" + networkID + ":" + queryID
+	existing, err := s.memorySystem.getEntityByNameAndType(name, querySchemaType)
+	if err != nil {
+		if _, err := s.memorySystem.CreateEntity(name, querySchemaType, metadata); err != nil {
+			s.logger.Warn("Failed to store schema of query %s on network %s: %v", queryID, networkID, err)
+		}
+		return nil
+	}
+
+	var previous map[string]string
+	if err := json.Unmarshal([]byte(resultValueString(existing.Metadata["columns"])), &previous); err != nil {
+		previous = nil
+	}
+	changes := storedSchemaChanges(existing)
+	var change *SchemaChange
+	if previous != nil {
+		added, removed, typeChanged := diffResultSchema(previous, schema)
+		if len(added)+len(removed)+len(typeChanged) > 0 {
+			change = &SchemaChange{
+				QueryID: queryID, NetworkID: networkID, SnapshotID: snapshotID,
+				PreviousSnapshotID: resultValueString(existing.Metadata["snapshot_id"]),
+				Added:              added, Removed: removed, TypeChanged: typeChanged, DetectedAt: now,
+			}
+			changes = append(changes, *change)
+			if len(changes) > maxSchemaChangesKept {
+				changes = changes[len(changes)-maxSchemaChangesKept:]
+			}
+			s.logger.Warn("Schema drift in query %s on network %s: %s", queryID, networkID, change.Summary())
+		}
+	}
+	// Columns only ever null keep their earlier type so a later run can still compare them
+	for column, kind := range schema {
+		if old, ok := previous[column]; ok && kind == "null" {
+			schema[column] = old
+		}
+	}
+	metadata["columns"] = MarshalCompactJSONString(schema)
+	if len(changes) > 0 {
+		metadata["changes"] = MarshalCompactJSONString(changes)
+	}
+	if err := s.memorySystem.updateEntityMetadata(existing.ID, metadata); err != nil {
+		s.logger.Warn("Failed to update schema of query %s on network %s: %v", queryID, networkID, err)
+	}
+	return change
+}
+
+// storedSchemaChanges returns the schema changes recorded on a query_schema entity, oldest first
+func storedSchemaChanges(entity *Entity) []SchemaChange {
+	var changes []SchemaChange
+	if raw := resultValueString(entity.Metadata["changes"]); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &changes); err != nil {
+			return nil
+		}
+	}
+	return changes
+}
+
+// schemaDriftWarning tells the caller a query's output columns changed since its previous run
+func schemaDriftWarning(change *SchemaChange) string {
+	if change == nil {
+		return ""
+	}
+	return fmt.Sprintf("\n⚠️ Schema drift: query %s returned different columns than its previous run on network %s (snapshot %s): %s. "+
+		"Saved SQL views, reports and key columns using this query may need updating; list_schema_changes shows the history.\n",
+		change.QueryID, change.NetworkID, firstNonEmpty(change.PreviousSnapshotID, "unknown"), change.Summary())
+}
+
+// listSchemaChanges lists recorded schema drift of NQE queries, newest first
+func (s *ForwardMCPService) listSchemaChanges(args ListSchemaChangesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_schema_changes", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	entities, err := s.memorySystem.SearchEntities("", querySchemaType, maxQuerySchemasScanned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load query schemas: %w", err)
+	}
+	var since time.Time
+	if args.SinceDays > 0 {
+		since = time.Now().Add(-time.Duration(args.SinceDays) * 24 * time.Hour)
+	}
+	var changes []SchemaChange
+	for _, entity := range entities {
+		if args.QueryID != "" && resultValueString(entity.Metadata["query_id"]) != args.QueryID {
+			continue
+		}
+		for _, change := range storedSchemaChanges(entity) {
+			if !change.DetectedAt.Before(since) {
+				changes = append(changes, change)
+			}
+		}
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].DetectedAt.After(changes[j].DetectedAt) })
+	limit := args.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	if len(changes) > limit {
+		changes = changes[:limit]
+	}
+
+	switch strings.ToLower(args.Format) {
+	case "json":
+		return mcp.NewToolResponse(mcp.NewTextContent(MarshalCompactJSONString(map[string]interface{}{
+			"tracked_queries": len(entities), "changes": changes,
+		}))), nil
+	case "", "markdown":
+	default:
+		return nil, fmt.Errorf("unsupported format %q: use markdown or json", args.Format)
+	}
+
+	if len(changes) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+			"No schema changes recorded across %d tracked queries. Column schemas are recorded each time run_nqe_query_by_id returns rows.", len(entities)))), nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# NQE Schema Changes (%d)\n\n", len(changes))
+	b.WriteString("| Detected | Query | Network | Snapshot | Previous Snapshot | Change |\n|---|---|---|---|---|---|\n")
+	for _, change := range changes {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n", s.timeFormatter.Format(change.DetectedAt), change.QueryID,
+			change.NetworkID, change.SnapshotID, change.PreviousSnapshotID, strings.ReplaceAll(change.Summary(), "|", "\\|"))
+	}
+	b.WriteString("\nFix saved SQL views, reports and pipeline key columns that use removed or retyped columns.")
+	return mcp.NewToolResponse(mcp.NewTextContent(b.String())), nil
+}
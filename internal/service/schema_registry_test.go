@@ -0,0 +1,104 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestResultSchema(t *testing.T) {
+	schema := resultSchema([]map[string]interface{}{
+		{"name": "core1", "mtu": float64(1500), "tags": nil, "mixed": "a"},
+		{"name": "core2", "mtu": float64(9000), "up": true, "mixed": float64(1)},
+	})
+	expected := map[string]string{"name": "string", "mtu": "number", "tags": "null", "up": "boolean", "mixed": "number|string"}
+	if len(schema) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, schema)
+	}
+	for column, kind := range expected {
+		if schema[column] != kind {
+			t.Errorf("Expected %s to be %s, got %s", column, kind, schema[column])
+		}
+	}
+
+	added, removed, typeChanged := diffResultSchema(
+		map[string]string{"name": "string", "mtu": "number", "tags": "null", "vlan": "number"},
+		map[string]string{"name": "string", "mtu": "string", "tags": "array", "site": "string"})
+	if len(added) != 1 || added[0] != "site" || len(removed) != 1 || removed[0] != "vlan" ||
+		len(typeChanged) != 1 || typeChanged["mtu"] != "number → string" {
+		t.Errorf("Unexpected diff: added %v removed %v changed %v", added, removed, typeChanged)
+	}
+}
+
+func TestRecordQuerySchema(t *testing.T) {
+	service := createTestService()
+	queryA, queryB := uniqueTestName("FQ_a_"), uniqueTestName("FQ_b_")
+
+	if change := service.recordQuerySchema(queryA, "162112", "snap-1", []map[string]interface{}{{"device": "core1", "mtu": float64(1500)}}); change != nil {
+		t.Errorf("Expected no drift on the first run, got %+v", change)
+	}
+	if change := service.recordQuerySchema(queryA, "162112", "snap-2", nil); change != nil {
+		t.Errorf("Expected empty results skipped, got %+v", change)
+	}
+	if change := service.recordQuerySchema(queryA, "162112", "snap-2", []map[string]interface{}{{"device": "core1", "mtu": nil}}); change != nil {
+		t.Errorf("Expected a null column not to count as drift, got %+v", change)
+	}
+	change := service.recordQuerySchema(queryA, "162112", "snap-3", []map[string]interface{}{{"deviceName": "core1", "mtu": "1500"}})
+	if change == nil || change.PreviousSnapshotID != "snap-2" || change.Summary() != "added deviceName; removed device; mtu changed number → string" {
+		t.Fatalf("Expected drift against snap-2, got %+v", change)
+	}
+	if warning := schemaDriftWarning(change); !strings.Contains(warning, "⚠️ Schema drift: query "+queryA) {
+		t.Errorf("Unexpected warning: %s", warning)
+	}
+	// Another network keeps its own schema of the same query
+	if change := service.recordQuerySchema(queryA, "555", "snap-9", []map[string]interface{}{{"device": "edge1"}}); change != nil {
+		t.Errorf("Expected no drift against another network's runs, got %+v", change)
+	}
+	if change := service.recordQuerySchema(queryA, "555", "snap-10", []map[string]interface{}{{"device": "edge1", "vrf": "default"}}); change == nil || change.NetworkID != "555" || change.PreviousSnapshotID != "snap-9" {
+		t.Errorf("Expected drift against the earlier run on network 555, got %+v", change)
+	}
+	service.recordQuerySchema(queryB, "162112", "snap-1", []map[string]interface{}{{"prefix": "10.0.0.0/8"}})
+	service.recordQuerySchema(queryB, "162112", "snap-2", []map[string]interface{}{{"prefix": "10.0.0.0/8", "vrf": "default"}})
+
+	response, err := service.listSchemaChanges(ListSchemaChangesArgs{SinceDays: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"# NQE Schema Changes", "| " + queryA + " | 162112 | snap-3 | snap-2 | added deviceName; removed device; mtu changed number → string |",
+		"| " + queryB + " | 162112 | snap-2 | snap-1 | added vrf |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	response, _ = service.listSchemaChanges(ListSchemaChangesArgs{QueryID: queryB, Format: "json"})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `"added":["vrf"]`) || strings.Count(text, `"query_id"`) != 1 {
+		t.Errorf("Expected only %s as JSON, got: %s", queryB, text)
+	}
+	if _, err := service.listSchemaChanges(ListSchemaChangesArgs{Format: "yaml"}); err == nil {
+		t.Error("Expected an unsupported format to be rejected")
+	}
+}
+
+func TestRunNQEQueryByIDFlagsSchemaDrift(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	queryID := uniqueTestName("FQ_drift_")
+	run := func(snapshotID string) string {
+		response, err := service.runNQEQueryByID(RunNQEQueryByIDArgs{NetworkID: "162112", SnapshotID: snapshotID, QueryID: queryID, Options: &NQEQueryOptions{Limit: 100}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return response.Content[0].TextContent.Text
+	}
+
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{{"device": "core1", "status": "up"}}}
+	if text := run("snap-1"); strings.Contains(text, "Schema drift") {
+		t.Errorf("Expected no drift on the first run: %s", text)
+	}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{{"device": "core1", "state": "up"}}}
+	if text := run("snap-2"); !strings.Contains(text, "Schema drift: query "+queryID) || !strings.Contains(text, "added state; removed status") {
+		t.Errorf("Expected drift flagged in the response: %s", text)
+	}
+}
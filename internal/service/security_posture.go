@@ -0,0 +1,503 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// securityQueryDirectory holds the NQE library queries run by the posture assessment
+const securityQueryDirectory = "/L3/Security/"
+
+// securityPostureMaxQueries bounds how many security queries one assessment runs
+const securityPostureMaxQueries = 15
+
+// Posture score weights; sections that were skipped are left out and the score is
+// normalized over the sections that ran
+const (
+	postureWeightQueries = 40.0
+	postureWeightFlows   = 30.0
+	postureWeightEOL     = 30.0
+)
+
+// Workflow state keys holding section results
+const (
+	postureStateQueries = "posture_queries"
+	postureStateFlows   = "posture_flows"
+	postureStateEOL     = "posture_eol"
+)
+
+// SecurityQueryFinding is the outcome of one /L3/Security/ query
+type SecurityQueryFinding struct {
+	QueryID    string `json:"query_id"`
+	Path       string `json:"path"`
+	Violations int    `json:"violations"`
+	Error      string `json:"error,omitempty"`
+}
+
+// CriticalFlowCheck compares a flow's path search verdict with the expected policy
+type CriticalFlowCheck struct {
+	Flow      string `json:"flow"`
+	Source    string `json:"source"`
+	Dest      string `json:"destination"`
+	DstPort   string `json:"dst_port,omitempty"`
+	Expect    string `json:"expect"` // allow or deny
+	Delivered bool   `json:"delivered"`
+	ACLDenied bool   `json:"acl_denied"`
+	Passed    bool   `json:"passed"`
+	Finding   string `json:"finding"`
+}
+
+// PostureSection is the score of one assessment area
+type PostureSection struct {
+	Name     string  `json:"name"`
+	Weight   float64 `json:"weight"`
+	Score    float64 `json:"score"` // 0-100
+	Detail   string  `json:"detail"`
+	Assessed bool    `json:"assessed"`
+}
+
+// SecurityPostureReport is the scored summary compiled at the end of the workflow
+type SecurityPostureReport struct {
+	NetworkID   string                 `json:"network_id"`
+	SnapshotID  string                 `json:"snapshot_id,omitempty"`
+	GeneratedAt time.Time              `json:"generated_at"`
+	Score       float64                `json:"score"`
+	Grade       string                 `json:"grade"`
+	Sections    []PostureSection       `json:"sections"`
+	Queries     []SecurityQueryFinding `json:"queries,omitempty"`
+	Flows       []CriticalFlowCheck    `json:"flows,omitempty"`
+	EOLBuckets  map[string]int         `json:"eol_buckets,omitempty"`
+	Actions     []string               `json:"actions,omitempty"`
+}
+
+// securityPostureWorkflow walks through security queries, critical flow ACL checks and
+// EOL exposure, then compiles a scored posture summary
+func (s *ForwardMCPService) securityPostureWorkflow(args SecurityPostureWorkflowArgs) (*mcp.ToolResponse, error) {
+	sessionID := fmt.Sprintf("posture_session_%v", args.SessionID)
+	step, err := s.resolveWorkflowStep(workflowSecurityPosture, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
+	state := s.workflowManager.GetState(sessionID)
+
+	if step == "scope_selected" && args.Answer != "" {
+		values := parseWorkflowKeyValues(args.Answer)
+		state.NetworkID = s.getNetworkID(values["network_id"])
+		state.SnapshotID = s.getSnapshotID(values["snapshot_id"])
+		delete(state.Parameters, postureStateQueries)
+		delete(state.Parameters, postureStateFlows)
+		delete(state.Parameters, postureStateEOL)
+		s.workflowManager.SetState(sessionID, state)
+	}
+	if step != "start" && step != "scope_selected" && state.NetworkID == "" {
+		return nil, fmt.Errorf("select a network first (step scope_selected, answer network_id=<id>)")
+	}
+
+	var response *mcp.ToolResponse
+	switch step {
+	case "scope_selected":
+		if state.NetworkID == "" {
+			return nil, newCodedError(CodeNetworkIDRequired)
+		}
+		response = mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+			"🔐 Assessing network %s (snapshot %s).\n\nNext, run the %s library queries; each returned row is counted as a violation.",
+			state.NetworkID, firstNonEmpty(state.SnapshotID, "latest"), securityQueryDirectory)))
+	case "run_security_queries":
+		response, err = s.runPostureSecurityQueries(sessionID, state)
+	case "verify_critical_flows":
+		response, err = s.verifyPostureCriticalFlows(sessionID, state, args.Answer)
+	case "check_eol":
+		response, err = s.checkPostureEOL(sessionID, state)
+	case "posture_summary":
+		response, err = s.compilePostureSummary(state, parseWorkflowKeyValues(args.Answer)["report_format"])
+	default:
+		step = "start"
+		response = mcp.NewToolResponse(mcp.NewTextContent(`🛡️ **Security Posture Assessment Workflow**
+
+This workflow runs a repeatable security review of one network snapshot:
+
+1. **run_security_queries** - Run the ` + securityQueryDirectory + ` NQE queries and count violations
+2. **verify_critical_flows** - Check that critical flows are allowed or denied by ACLs as intended
+3. **check_eol** - Measure hardware and OS end-of-support exposure
+4. **posture_summary** - Compile a scored summary (0-100, grades A-F) and store it in memory; answer report_format=html (or markdown/pdf) to also save a rendered report
+
+Sections can be skipped; the score is normalized over the sections that ran.
+Start by selecting the network with step scope_selected.`))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.finishWorkflowStep(workflowSecurityPosture, sessionID, step, response), nil
+}
+
+// runPostureSecurityQueries runs the security query library and records violation counts
+func (s *ForwardMCPService) runPostureSecurityQueries(sessionID string, state *WorkflowState) (*mcp.ToolResponse, error) {
+	queries, err := s.forwardClient.GetNQEQueries(securityQueryDirectory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get security queries: %w", err)
+	}
+	var selected []forward.NQEQuery
+	for _, query := range queries {
+		if strings.HasPrefix(query.Path, securityQueryDirectory) {
+			selected = append(selected, query)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].Path < selected[j].Path })
+	skipped := 0
+	if len(selected) > securityPostureMaxQueries {
+		skipped = len(selected) - securityPostureMaxQueries
+		selected = selected[:securityPostureMaxQueries]
+	}
+
+	findings := make([]SecurityQueryFinding, 0, len(selected))
+	for _, query := range selected {
+		finding := SecurityQueryFinding{QueryID: query.QueryID, Path: query.Path}
+		items, err := s.fetchAllNQEItems(state.NetworkID, state.SnapshotID, query.QueryID, nil)
+		if err != nil {
+			finding.Error = err.Error()
+		} else {
+			finding.Violations = len(items)
+		}
+		findings = append(findings, finding)
+	}
+	state.Parameters[postureStateQueries] = findings
+	s.workflowManager.SetState(sessionID, state)
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Security queries (%s)\n\n", securityQueryDirectory))
+	if len(findings) == 0 {
+		text.WriteString("No security queries were found in the query library; this section will not be scored.\n")
+		return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+	}
+	text.WriteString("| Query | Violations |\n|-------|------------|\n")
+	for _, finding := range findings {
+		result := strconv.Itoa(finding.Violations)
+		if finding.Error != "" {
+			result = "error: " + finding.Error
+		} else if finding.Violations == 0 {
+			result = "0 ✅"
+		}
+		text.WriteString(fmt.Sprintf("| %s | %s |\n", finding.Path, result))
+	}
+	if skipped > 0 {
+		text.WriteString(fmt.Sprintf("\n%d more security queries were not run (limit %d per assessment).\n", skipped, securityPostureMaxQueries))
+	}
+	text.WriteString("\nNext, verify critical flows (answer: src>dst[:port]=allow|deny; ...) or skip to check_eol.")
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// verifyPostureCriticalFlows path-searches each critical flow and compares the verdict with
+// the expected policy
+func (s *ForwardMCPService) verifyPostureCriticalFlows(sessionID string, state *WorkflowState, answer string) (*mcp.ToolResponse, error) {
+	checks, err := parseCriticalFlows(answer)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyCriticalFlows(state.NetworkID, state.SnapshotID, checks); err != nil {
+		return nil, err
+	}
+	state.Parameters[postureStateFlows] = checks
+	s.workflowManager.SetState(sessionID, state)
+
+	var text strings.Builder
+	text.WriteString("## Critical flow verification\n\n| Flow | Expected | Result | Finding |\n|------|----------|--------|---------|\n")
+	for _, check := range checks {
+		result := "✅ pass"
+		if !check.Passed {
+			result = "❌ fail"
+		}
+		text.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", check.Flow, check.Expect, result, check.Finding))
+	}
+	text.WriteString("\nNext, check EOL exposure with check_eol or compile the posture_summary.")
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// verifyCriticalFlows path-searches parsed flows and records each verdict and finding
+func (s *ForwardMCPService) verifyCriticalFlows(networkID, snapshotID string, checks []CriticalFlowCheck) error {
+	queries := make([]forward.PathSearchParams, 0, len(checks))
+	for i := range checks {
+		src, err := s.resolveTroubleshootEndpoint(networkID, checks[i].Source)
+		if err != nil && src.IP == "" && src.Device == "" {
+			return fmt.Errorf("failed to resolve source '%s': %w", checks[i].Source, err)
+		}
+		dst, err := s.resolveTroubleshootEndpoint(networkID, checks[i].Dest)
+		if err != nil || dst.IP == "" {
+			return fmt.Errorf("failed to resolve destination '%s' to an IP address: %w", checks[i].Dest, err)
+		}
+		query := forward.PathSearchParams{From: src.Device, SrcIP: src.IP, DstIP: dst.IP, DstPort: checks[i].DstPort}
+		if checks[i].DstPort != "" {
+			tcp := 6
+			query.IPProto = &tcp
+		}
+		queries = append(queries, query)
+	}
+
+	apiSnapshotID := ""
+	if snapshotID != "latest" {
+		apiSnapshotID = snapshotID
+	}
+	responses, err := s.forwardClient.SearchPathsBulk(networkID, &forward.PathSearchBulkRequest{
+		Queries:    queries,
+		Intent:     "PREFER_DELIVERED",
+		MaxResults: 5,
+	}, apiSnapshotID)
+	if err != nil {
+		return fmt.Errorf("failed to execute path search: %w", err)
+	}
+
+	for i := range checks {
+		var response forward.PathSearchBulkResponse
+		if i < len(responses) {
+			response = responses[i]
+		}
+		diagnosis := diagnosePathDirection("forward", queries[i], response)
+		checks[i].Delivered = diagnosis.Delivered
+		checks[i].ACLDenied = diagnosis.ACLDenied
+		checks[i].Finding = diagnosis.Finding
+		if checks[i].Expect == "allow" {
+			checks[i].Passed = diagnosis.Delivered
+		} else {
+			checks[i].Passed = !diagnosis.Delivered
+			if checks[i].Passed && !diagnosis.ACLDenied {
+				checks[i].Finding += " (not enforced by an ACL)"
+			}
+		}
+	}
+	return nil
+}
+
+// parseCriticalFlows parses "src>dst[:port][=allow|deny]" entries separated by semicolons
+// or newlines. Flows without an expectation are expected to be allowed.
+func parseCriticalFlows(answer string) ([]CriticalFlowCheck, error) {
+	var checks []CriticalFlowCheck
+	entries := strings.FieldsFunc(answer, func(r rune) bool { return r == ';' || r == '\n' })
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		check := CriticalFlowCheck{Flow: entry, Expect: "allow"}
+		flow := entry
+		if before, expect, found := strings.Cut(entry, "="); found {
+			flow = strings.TrimSpace(before)
+			check.Expect = strings.ToLower(strings.TrimSpace(expect))
+			if check.Expect != "allow" && check.Expect != "deny" {
+				return nil, fmt.Errorf("invalid expectation '%s' in flow '%s' (expected allow or deny)", expect, entry)
+			}
+		}
+		src, dst, found := strings.Cut(strings.Replace(flow, "->", ">", 1), ">")
+		if !found || strings.TrimSpace(src) == "" || strings.TrimSpace(dst) == "" {
+			return nil, fmt.Errorf("invalid flow '%s' (expected src>dst[:port][=allow|deny])", entry)
+		}
+		check.Source = strings.TrimSpace(src)
+		check.Dest = strings.TrimSpace(dst)
+		// A trailing :port is split off unless the destination is a bare IPv6 address
+		if host, port, found := strings.Cut(check.Dest, ":"); found && !strings.Contains(port, ":") {
+			if _, err := strconv.Atoi(port); err != nil {
+				return nil, fmt.Errorf("invalid port '%s' in flow '%s'", port, entry)
+			}
+			check.Dest = host
+			check.DstPort = port
+		}
+		checks = append(checks, check)
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("at least one flow is required (expected src>dst[:port][=allow|deny])")
+	}
+	return checks, nil
+}
+
+// checkPostureEOL builds the EOL forecast used for the posture score
+func (s *ForwardMCPService) checkPostureEOL(sessionID string, state *WorkflowState) (*mcp.ToolResponse, error) {
+	locations, err := s.forwardClient.GetDeviceLocations(state.NetworkID)
+	if err != nil {
+		s.logger.Debug("Failed to get device locations for posture EOL check: %v", err)
+	}
+
+	now := time.Now()
+	var records []EOLExposureRecord
+	for _, source := range []struct{ component, queryID string }{
+		{eolComponentHardware, hardwareSupportQueryID},
+		{eolComponentOS, osSupportQueryID},
+	} {
+		items, err := s.fetchAllNQEItems(state.NetworkID, state.SnapshotID, source.queryID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s support data: %w", source.component, err)
+		}
+		records = append(records, s.eolRecordsFromItems(items, source.component, locations, now)...)
+	}
+	forecast := buildEOLForecast(records, now)
+	forecast.NetworkID = state.NetworkID
+	forecast.SnapshotID = state.SnapshotID
+	state.Parameters[postureStateEOL] = forecast
+	s.workflowManager.SetState(sessionID, state)
+
+	return mcp.NewToolResponse(mcp.NewTextContent(formatEOLForecast(forecast) + "\nNext, compile the posture_summary.")), nil
+}
+
+// compilePostureSummary scores the collected sections and stores the report in memory,
+// saving a rendered copy when a report format is given
+func (s *ForwardMCPService) compilePostureSummary(state *WorkflowState, reportFormat string) (*mcp.ToolResponse, error) {
+	if err := validateReportFormat(reportFormat); err != nil {
+		return nil, err
+	}
+	queries, _ := state.Parameters[postureStateQueries].([]SecurityQueryFinding)
+	flows, _ := state.Parameters[postureStateFlows].([]CriticalFlowCheck)
+	forecast, _ := state.Parameters[postureStateEOL].(*EOLForecast)
+
+	report := buildSecurityPostureReport(queries, flows, forecast, time.Now())
+	report.NetworkID = state.NetworkID
+	report.SnapshotID = state.SnapshotID
+	if report.Grade == "" {
+		return nil, fmt.Errorf("no assessment sections have run yet; run run_security_queries, verify_critical_flows or check_eol first")
+	}
+
+	text := formatSecurityPostureReport(report)
+	if s.memorySystem != nil {
+		if entityID, err := s.storeSecurityPostureReport(report); err != nil {
+			s.logger.Warn("Failed to store security posture report: %v", err)
+		} else {
+			text += fmt.Sprintf("\nReport stored in memory (entity: %s).\n", entityID)
+		}
+	}
+	text += s.saveReport(securityPostureDocument(report), "security_posture_"+report.NetworkID, reportFormat)
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
+}
+
+// buildSecurityPostureReport scores each assessed section and combines them by weight
+func buildSecurityPostureReport(queries []SecurityQueryFinding, flows []CriticalFlowCheck, forecast *EOLForecast, now time.Time) *SecurityPostureReport {
+	report := &SecurityPostureReport{GeneratedAt: now, Queries: queries, Flows: flows}
+
+	querySection := PostureSection{Name: "Security queries", Weight: postureWeightQueries}
+	ran, clean := 0, 0
+	for _, finding := range queries {
+		if finding.Error != "" {
+			continue
+		}
+		ran++
+		if finding.Violations == 0 {
+			clean++
+		} else {
+			report.Actions = append(report.Actions, fmt.Sprintf("Remediate %d violations reported by %s", finding.Violations, finding.Path))
+		}
+	}
+	if ran > 0 {
+		querySection.Assessed = true
+		querySection.Score = float64(clean) / float64(ran) * 100
+		querySection.Detail = fmt.Sprintf("%d of %d queries clean", clean, ran)
+	}
+
+	flowSection := PostureSection{Name: "Critical flow ACLs", Weight: postureWeightFlows}
+	if len(flows) > 0 {
+		passed := 0
+		for _, flow := range flows {
+			if flow.Passed {
+				passed++
+			} else {
+				verdict := "allowed"
+				if flow.Expect == "deny" {
+					verdict = "denied"
+				}
+				report.Actions = append(report.Actions, fmt.Sprintf("Flow %s should be %s: %s", flow.Flow, verdict, flow.Finding))
+			}
+		}
+		flowSection.Assessed = true
+		flowSection.Score = float64(passed) / float64(len(flows)) * 100
+		flowSection.Detail = fmt.Sprintf("%d of %d flows match policy", passed, len(flows))
+	}
+
+	eolSection := PostureSection{Name: "EOL exposure", Weight: postureWeightEOL}
+	if forecast != nil && len(forecast.Records) > 0 {
+		report.EOLBuckets = forecast.Buckets
+		overdue := forecast.Buckets[eolBucketOverdue]
+		soon := forecast.Buckets[eolBucket6Months]
+		exposure := (float64(overdue) + 0.5*float64(soon)) / float64(len(forecast.Records))
+		eolSection.Assessed = true
+		eolSection.Score = math.Max(0, (1-exposure)*100)
+		eolSection.Detail = fmt.Sprintf("%d components past end of support, %d within 6 months", overdue, soon)
+		if overdue > 0 {
+			report.Actions = append(report.Actions, fmt.Sprintf("Replace or upgrade %d components past end of support", overdue))
+		}
+	}
+
+	report.Sections = []PostureSection{querySection, flowSection, eolSection}
+	totalWeight, weighted := 0.0, 0.0
+	for _, section := range report.Sections {
+		if section.Assessed {
+			totalWeight += section.Weight
+			weighted += section.Weight * section.Score
+		}
+	}
+	if totalWeight == 0 {
+		return report
+	}
+	report.Score = math.Round(weighted/totalWeight*10) / 10
+	report.Grade = postureGrade(report.Score)
+	return report
+}
+
+// postureGrade maps a 0-100 score to a letter grade
+func postureGrade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	}
+	return "F"
+}
+
+// formatSecurityPostureReport renders the posture report as markdown
+func formatSecurityPostureReport(report *SecurityPostureReport) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Security Posture: %.1f/100 (grade %s)\n\n", report.Score, report.Grade))
+	text.WriteString(fmt.Sprintf("Network %s, snapshot %s, generated %s\n\n", report.NetworkID, firstNonEmpty(report.SnapshotID, "latest"), report.GeneratedAt.Format(time.RFC3339)))
+	text.WriteString("| Section | Weight | Score | Detail |\n|---------|--------|-------|--------|\n")
+	for _, section := range report.Sections {
+		if !section.Assessed {
+			text.WriteString(fmt.Sprintf("| %s | %.0f | - | not assessed |\n", section.Name, section.Weight))
+			continue
+		}
+		text.WriteString(fmt.Sprintf("| %s | %.0f | %.1f | %s |\n", section.Name, section.Weight, section.Score, section.Detail))
+	}
+	if len(report.Actions) > 0 {
+		text.WriteString("\n## Recommended actions\n")
+		for _, action := range report.Actions {
+			text.WriteString(fmt.Sprintf("- %s\n", action))
+		}
+	}
+	return text.String()
+}
+
+// storeSecurityPostureReport saves the report as a memory entity with a JSON observation
+func (s *ForwardMCPService) storeSecurityPostureReport(report *SecurityPostureReport) (string, error) {
+	entity, err := s.memorySystem.CreateEntity(
+		fmt.Sprintf("security_posture_%s_%d", report.NetworkID, report.GeneratedAt.Unix()),
+		"security_posture_report",
+		map[string]interface{}{
+			"network_id":   report.NetworkID,
+			"snapshot_id":  report.SnapshotID,
+			"generated_at": report.GeneratedAt.Format(time.RFC3339),
+			"score":        report.Score,
+			"grade":        report.Grade,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create security posture entity: %w", err)
+	}
+	if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(report), "summary", nil); err != nil {
+		s.logger.Debug("Failed to add security posture summary: %v", err)
+	}
+	return entity.ID, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestParseCriticalFlows(t *testing.T) {
+	checks, err := parseCriticalFlows("10.0.0.1>10.1.0.5:443=deny; web-01 -> 10.2.0.1 ;\n10.0.0.1>2001:db8::1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(checks) != 3 {
+		t.Fatalf("Expected 3 flows, got %d", len(checks))
+	}
+	if checks[0].Dest != "10.1.0.5" || checks[0].DstPort != "443" || checks[0].Expect != "deny" {
+		t.Errorf("Unexpected first flow: %+v", checks[0])
+	}
+	if checks[1].Source != "web-01" || checks[1].Dest != "10.2.0.1" || checks[1].Expect != "allow" {
+		t.Errorf("Unexpected second flow: %+v", checks[1])
+	}
+	if checks[2].Dest != "2001:db8::1" || checks[2].DstPort != "" {
+		t.Errorf("Expected bare IPv6 destination to keep its colons, got: %+v", checks[2])
+	}
+
+	for _, invalid := range []string{"", "10.0.0.1", "a>b=maybe", "a>b:https"} {
+		if _, err := parseCriticalFlows(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestBuildSecurityPostureReport(t *testing.T) {
+	queries := []SecurityQueryFinding{
+		{Path: "/L3/Security/Telnet Enabled", Violations: 0},
+		{Path: "/L3/Security/Weak SNMP", Violations: 4},
+		{Path: "/L3/Security/Broken", Error: "timeout"},
+	}
+	flows := []CriticalFlowCheck{
+		{Flow: "a>b", Expect: "allow", Passed: true},
+		{Flow: "c>d", Expect: "deny", Passed: false, Finding: "Traffic is delivered across 2 hops"},
+	}
+
+	report := buildSecurityPostureReport(queries, flows, nil, time.Now())
+	// Queries 50 (weight 40) and flows 50 (weight 30); EOL was not assessed
+	if report.Score != 50 || report.Grade != "F" {
+		t.Errorf("Expected score 50 (F), got %.1f (%s)", report.Score, report.Grade)
+	}
+	if report.Sections[2].Assessed {
+		t.Error("Expected EOL section to be skipped")
+	}
+	if len(report.Actions) != 2 || !contains(report.Actions[1], "should be denied") {
+		t.Errorf("Unexpected actions: %v", report.Actions)
+	}
+
+	forecast := &EOLForecast{
+		Buckets: map[string]int{eolBucketOverdue: 1, eolBucket6Months: 2},
+		Records: make([]EOLExposureRecord, 10),
+	}
+	report = buildSecurityPostureReport(nil, nil, forecast, time.Now())
+	if report.Score != 80 || report.Grade != "B" {
+		t.Errorf("Expected EOL-only score 80 (B), got %.1f (%s)", report.Score, report.Grade)
+	}
+
+	if report := buildSecurityPostureReport(nil, nil, nil, time.Now()); report.Grade != "" {
+		t.Errorf("Expected no grade without assessed sections, got %s", report.Grade)
+	}
+}
+
+func TestSecurityPostureWorkflow(t *testing.T) {
+	service := createTestService()
+	mockClient := service.forwardClient.(*MockForwardClient)
+	mockClient.nqeQueries = []forward.NQEQuery{
+		{QueryID: "FQ_sec1", Path: "/L3/Security/Telnet Enabled"},
+		{QueryID: "FQ_basic", Path: "/L3/Basic/All Devices"},
+	}
+
+	if _, err := service.securityPostureWorkflow(SecurityPostureWorkflowArgs{SessionID: "sp-1"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := service.securityPostureWorkflow(SecurityPostureWorkflowArgs{SessionID: "sp-1", Step: "run_security_queries"}); err == nil {
+		t.Error("Expected queries before scope selection to be rejected")
+	}
+	if _, err := service.securityPostureWorkflow(SecurityPostureWorkflowArgs{SessionID: "sp-1", Step: "scope_selected", Answer: "network_id=162112"}); err != nil {
+		t.Fatalf("Expected scope selection to succeed, got: %v", err)
+	}
+
+	response, err := service.securityPostureWorkflow(SecurityPostureWorkflowArgs{SessionID: "sp-1", Step: "run_security_queries"})
+	if err != nil {
+		t.Fatalf("Expected security queries to run, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, "Telnet Enabled") || contains(text, "All Devices") {
+		t.Errorf("Expected only security queries to run, got: %s", text)
+	}
+
+	response, err = service.securityPostureWorkflow(SecurityPostureWorkflowArgs{SessionID: "sp-1", Step: "verify_critical_flows", Answer: "10.0.0.1>10.0.1.1:443=allow; 10.0.0.1>10.0.2.1:23=deny"})
+	if err != nil {
+		t.Fatalf("Expected flow verification to run, got: %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !contains(text, "✅ pass") || !contains(text, "❌ fail") {
+		t.Errorf("Expected one passing and one failing flow, got: %s", text)
+	}
+
+	response, err = service.securityPostureWorkflow(SecurityPostureWorkflowArgs{SessionID: "sp-1", Step: "posture_summary"})
+	if err != nil {
+		t.Fatalf("Expected posture summary, got: %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !contains(text, "# Security Posture:") || !contains(text, "Report stored in memory") || !contains(text, `"current_step":"posture_summary"`) {
+		t.Errorf("Unexpected posture summary: %s", text)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -95,6 +96,9 @@ type SemanticCache struct {
 
 	// Memory tracking
 	currentMemoryUsage int64
+
+	// Reports entries that must not expire or be evicted (nil pins nothing)
+	pinned func(entry *CacheEntry) bool
 }
 
 // truncateString safely truncates a string for logging
@@ -278,8 +282,51 @@ func (sc *SemanticCache) cosineSimilarity(a, b []float64) float64 {
 	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
 }
 
+// CacheLookupOptions tunes a single cache lookup
+type CacheLookupOptions struct {
+	Category  string  // Query category used to select a per-category threshold
+	Threshold float64 // Per-call similarity threshold override (0 = not set)
+}
+
+// CacheHit describes how a cached result was matched
+type CacheHit struct {
+	MatchType       string  `json:"match_type"` // "exact" or "semantic"
+	Similarity      float64 `json:"similarity"`
+	Threshold       float64 `json:"threshold"`
+	ThresholdSource string  `json:"threshold_source"` // "override", "category:<name>" or "global"
+}
+
+// String renders the hit for inclusion in tool responses
+func (h *CacheHit) String() string {
+	return fmt.Sprintf("Cache hit: %s match (similarity %.3f, threshold %.2f from %s)",
+		h.MatchType, h.Similarity, h.Threshold, h.ThresholdSource)
+}
+
+// EffectiveThreshold resolves the similarity threshold for a lookup: a per-call override
+// wins over a category threshold, which wins over the global threshold.
+// Category names are matched case-insensitively.
+func (sc *SemanticCache) EffectiveThreshold(opts CacheLookupOptions) (float64, string) {
+	if opts.Threshold > 0 && opts.Threshold <= 1 {
+		return opts.Threshold, "override"
+	}
+	if opts.Category != "" && sc.config != nil {
+		for category, threshold := range sc.config.CategoryThresholds {
+			if strings.EqualFold(category, opts.Category) && threshold > 0 {
+				return threshold, "category:" + category
+			}
+		}
+	}
+	return sc.similarityThreshold, "global"
+}
+
 // Get attempts to retrieve a cached result using semantic similarity
 func (sc *SemanticCache) Get(query, networkID, snapshotID string) (*forward.NQERunResult, bool) {
+	result, _, found := sc.Lookup(query, networkID, snapshotID, CacheLookupOptions{})
+	return result, found
+}
+
+// Lookup retrieves a cached result using the threshold resolved from opts and reports how it matched
+func (sc *SemanticCache) Lookup(query, networkID, snapshotID string, opts CacheLookupOptions) (*forward.NQERunResult, *CacheHit, bool) {
 	start := time.Now()
 	defer func() {
 		if sc.metricsEnabled {
@@ -293,6 +340,8 @@ func (sc *SemanticCache) Get(query, networkID, snapshotID string) (*forward.NQER
 	// Only increment TotalQueries once per Get call
 	sc.metrics.TotalQueries++
 
+	threshold, source := sc.EffectiveThreshold(opts)
+
 	// First try exact match
 	key := sc.generateCacheKey(query, networkID, snapshotID)
 	if entry, exists := sc.entries[key]; exists && !sc.isExpired(entry) {
@@ -300,7 +349,7 @@ func (sc *SemanticCache) Get(query, networkID, snapshotID string) (*forward.NQER
 		if err != nil {
 			sc.logger.Error("Failed to retrieve result from cache entry: %v", err)
 			sc.metrics.MissCount++
-			return nil, false
+			return nil, nil, false
 		}
 
 		// Update access metrics
@@ -310,7 +359,7 @@ func (sc *SemanticCache) Get(query, networkID, snapshotID string) (*forward.NQER
 
 		sc.logger.Debug("CACHE HIT: Exact match for query: %s (compression: %v, size: %d bytes)",
 			truncateString(query, 50), entry.IsCompressed, entry.CompressedSize)
-		return result, true
+		return result, &CacheHit{MatchType: "exact", Similarity: 1, Threshold: threshold, ThresholdSource: source}, true
 	}
 
 	// Generate embedding for semantic search if embedding service available
@@ -321,27 +370,27 @@ func (sc *SemanticCache) Get(query, networkID, snapshotID string) (*forward.NQER
 		} else {
 			// Search for semantically similar queries
 			bestMatch := sc.findBestMatch(embedding, networkID, snapshotID)
-			if bestMatch != nil && bestMatch.SimilarityScore >= sc.similarityThreshold {
+			if bestMatch != nil && bestMatch.SimilarityScore >= threshold {
 				result, err := sc.getResultFromEntry(bestMatch)
 				if err != nil {
 					sc.logger.Error("Failed to retrieve result from best match: %v", err)
 					sc.metrics.MissCount++
-					return nil, false
+					return nil, nil, false
 				}
 
 				bestMatch.AccessCount++
 				bestMatch.LastAccessed = time.Now()
 				sc.metrics.HitCount++
 
-				sc.logger.Debug("CACHE HIT: Semantic match (%.3f similarity) for query: %s",
-					bestMatch.SimilarityScore, truncateString(query, 50))
-				return result, true
+				sc.logger.Debug("CACHE HIT: Semantic match (%.3f similarity, threshold %.2f from %s) for query: %s",
+					bestMatch.SimilarityScore, threshold, source, truncateString(query, 50))
+				return result, &CacheHit{MatchType: "semantic", Similarity: bestMatch.SimilarityScore, Threshold: threshold, ThresholdSource: source}, true
 			}
 		}
 	}
 
 	sc.metrics.MissCount++
-	return nil, false
+	return nil, nil, false
 }
 
 // getResultFromEntry retrieves the result from a cache entry, handling compression and disk storage
@@ -442,7 +491,10 @@ func (sc *SemanticCache) Put(query, networkID, snapshotID string, result *forwar
 	// Strictly enforce maxEntries after insertion
 	for len(sc.entries) > sc.maxEntries {
 		sc.logger.Debug("Strict maxEntries enforcement: evicting to maintain limit (%d/%d)", len(sc.entries), sc.maxEntries)
-		sc.evictEntriesByPolicy(1)
+		if sc.evictEntriesByPolicy(1) == 0 {
+			sc.logger.Warn("Cache holds %d entries over its limit of %d because the rest are pinned", len(sc.entries), sc.maxEntries)
+			break
+		}
 	}
 
 	return nil
@@ -475,15 +527,27 @@ func (sc *SemanticCache) findBestMatch(embedding []float64, networkID, snapshotI
 	return bestMatch
 }
 
-// isExpired checks if a cache entry has expired
+// SetPinned sets the function reporting entries that must not expire or be evicted
+func (sc *SemanticCache) SetPinned(pinned func(entry *CacheEntry) bool) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+	sc.pinned = pinned
+}
+
+// isPinned reports whether an entry is kept regardless of TTL and eviction policy
+func (sc *SemanticCache) isPinned(entry *CacheEntry) bool {
+	return sc.pinned != nil && sc.pinned(entry)
+}
+
+// isExpired checks if a cache entry has expired; pinned entries never expire
 func (sc *SemanticCache) isExpired(entry *CacheEntry) bool {
-	return time.Since(entry.Timestamp) > sc.ttl
+	return time.Since(entry.Timestamp) > sc.ttl && !sc.isPinned(entry)
 }
 
-// evictOldest removes the oldest cache entry
-func (sc *SemanticCache) evictOldest() {
+// evictOldest removes the oldest unpinned cache entry and reports whether one was removed
+func (sc *SemanticCache) evictOldest() bool {
 	if len(sc.entries) == 0 {
-		return
+		return false
 	}
 
 	// Find oldest entry by creation time (Timestamp)
@@ -491,7 +555,7 @@ func (sc *SemanticCache) evictOldest() {
 	var oldestTime time.Time = time.Now()
 
 	for key, entry := range sc.entries {
-		if entry.Timestamp.Before(oldestTime) {
+		if entry.Timestamp.Before(oldestTime) && !sc.isPinned(entry) {
 			oldestTime = entry.Timestamp
 			oldestKey = key
 		}
@@ -514,7 +578,9 @@ func (sc *SemanticCache) evictOldest() {
 		}
 
 		sc.logger.Debug("CACHE EVICT: Removed entry for query: %s", truncateString(entry.Query, 50))
+		return true
 	}
+	return false
 }
 
 // evictEntriesByPolicy evicts entries based on the configured eviction policy
@@ -529,26 +595,22 @@ func (sc *SemanticCache) evictEntriesByPolicy(maxToEvict int) int {
 	switch policy {
 	case config.EvictionPolicyLRU:
 		// Evict based on Least Recently Used
-		for evicted < maxToEvict && len(sc.entries) > 0 {
-			sc.evictOldest()
+		for evicted < maxToEvict && sc.evictOldest() {
 			evicted++
 		}
 	case config.EvictionPolicyLFU:
 		// Evict based on Least Frequently Used
-		for evicted < maxToEvict && len(sc.entries) > 0 {
-			sc.evictLeastFrequent()
+		for evicted < maxToEvict && sc.evictLeastFrequent() {
 			evicted++
 		}
 	case config.EvictionPolicySize:
 		// Evict largest entries first
-		for evicted < maxToEvict && len(sc.entries) > 0 {
-			sc.evictLargest()
+		for evicted < maxToEvict && sc.evictLargest() {
 			evicted++
 		}
 	default:
 		// Default to oldest
-		for evicted < maxToEvict && len(sc.entries) > 0 {
-			sc.evictOldest()
+		for evicted < maxToEvict && sc.evictOldest() {
 			evicted++
 		}
 	}
@@ -559,10 +621,11 @@ func (sc *SemanticCache) evictEntriesByPolicy(maxToEvict int) int {
 	return evicted
 }
 
-// evictLeastFrequent removes the least frequently used cache entry
-func (sc *SemanticCache) evictLeastFrequent() {
+// evictLeastFrequent removes the least frequently used unpinned cache entry and reports
+// whether one was removed
+func (sc *SemanticCache) evictLeastFrequent() bool {
 	if len(sc.entries) == 0 {
-		return
+		return false
 	}
 
 	var lfuKey string
@@ -570,6 +633,9 @@ func (sc *SemanticCache) evictLeastFrequent() {
 	var oldestTime time.Time = time.Now()
 
 	for key, entry := range sc.entries {
+		if sc.isPinned(entry) {
+			continue
+		}
 		if minAccessCount == -1 || entry.AccessCount < minAccessCount || (entry.AccessCount == minAccessCount && entry.Timestamp.Before(oldestTime)) {
 			minAccessCount = entry.AccessCount
 			oldestTime = entry.Timestamp
@@ -594,13 +660,16 @@ func (sc *SemanticCache) evictLeastFrequent() {
 
 		sc.logger.Debug("CACHE EVICT (LFU): Removed entry for query: %s (access count: %d)",
 			truncateString(entry.Query, 50), entry.AccessCount)
+		return true
 	}
+	return false
 }
 
-// evictLargest removes the largest cache entry by memory usage
-func (sc *SemanticCache) evictLargest() {
+// evictLargest removes the largest unpinned cache entry by memory usage and reports whether
+// one was removed
+func (sc *SemanticCache) evictLargest() bool {
 	if len(sc.entries) == 0 {
-		return
+		return false
 	}
 
 	var largestKey string
@@ -608,7 +677,7 @@ func (sc *SemanticCache) evictLargest() {
 
 	for key, entry := range sc.entries {
 		size := sc.estimateMemoryUsage(entry)
-		if size > maxSize {
+		if size > maxSize && !sc.isPinned(entry) {
 			maxSize = size
 			largestKey = key
 		}
@@ -631,7 +700,9 @@ func (sc *SemanticCache) evictLargest() {
 
 		sc.logger.Debug("CACHE EVICT (Size): Removed entry for query: %s (size: %d bytes)",
 			truncateString(entry.Query, 50), maxSize)
+		return true
 	}
+	return false
 }
 
 // GetStats returns cache performance statistics
@@ -657,6 +728,7 @@ func (sc *SemanticCache) GetStats() map[string]interface{} {
 		"cache_misses":         sc.metrics.MissCount,
 		"hit_rate_percent":     fmt.Sprintf("%.2f", hitRate),
 		"threshold":            sc.similarityThreshold,
+		"category_thresholds":  sc.config.CategoryThresholds,
 		"max_entries":          sc.maxEntries,
 		"max_memory_mb":        float64(sc.maxMemoryBytes) / (1024 * 1024),
 		"current_memory_mb":    sc.metrics.MemoryUsageMB,
@@ -905,3 +977,218 @@ func (sc *SemanticCache) loadFromDisk(filePath string) (*forward.NQERunResult, e
 
 	return entry.Result, nil
 }
+
+// CacheEntrySummary describes a cache entry without its result payload
+type CacheEntrySummary struct {
+	Hash         string    `json:"hash"`
+	Query        string    `json:"query"`
+	NetworkID    string    `json:"network_id"`
+	SnapshotID   string    `json:"snapshot_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	LastAccessed time.Time `json:"last_accessed"`
+	AgeSeconds   int64     `json:"age_seconds"`
+	AccessCount  int64     `json:"access_count"`
+	SizeBytes    int64     `json:"size_bytes"`
+	Expired      bool      `json:"expired"`
+}
+
+// CacheEntryFilter selects entries for ListEntries; zero values match everything
+type CacheEntryFilter struct {
+	NetworkID      string
+	Networks       *NetworkAccessPolicy // Hides entries of restricted networks (nil shows every network)
+	MinAge         time.Duration
+	MaxAge         time.Duration
+	MinAccessCount int64
+	Limit          int
+}
+
+// CacheNeighbor is a cached query similar to an inspected entry
+type CacheNeighbor struct {
+	Hash       string  `json:"hash"`
+	Query      string  `json:"query"`
+	Similarity float64 `json:"similarity"`
+	WouldHit   bool    `json:"would_hit"` // Similarity meets the cache threshold
+}
+
+// CacheResultPointer describes where an entry's result is stored
+type CacheResultPointer struct {
+	Storage          string `json:"storage"` // memory, memory_compressed or disk
+	DiskPath         string `json:"disk_path,omitempty"`
+	UncompressedSize int64  `json:"uncompressed_size"`
+	CompressedSize   int64  `json:"compressed_size,omitempty"`
+	Rows             int    `json:"rows"`
+}
+
+// CacheEntryInspection is the detailed view of a single cache entry
+type CacheEntryInspection struct {
+	CacheEntrySummary
+	HasEmbedding bool               `json:"has_embedding"`
+	Result       CacheResultPointer `json:"result"`
+	Neighbors    []CacheNeighbor    `json:"neighbors"`
+}
+
+// summarizeEntry builds a summary (assumes mutex is already locked)
+func (sc *SemanticCache) summarizeEntry(entry *CacheEntry) CacheEntrySummary {
+	return CacheEntrySummary{
+		Hash:         entry.Hash,
+		Query:        entry.Query,
+		NetworkID:    entry.NetworkID,
+		SnapshotID:   entry.SnapshotID,
+		CreatedAt:    entry.Timestamp,
+		LastAccessed: entry.LastAccessed,
+		AgeSeconds:   int64(time.Since(entry.Timestamp).Seconds()),
+		AccessCount:  entry.AccessCount,
+		SizeBytes:    sc.estimateMemoryUsage(entry),
+		Expired:      sc.isExpired(entry),
+	}
+}
+
+// ListEntries returns entries matching the filter, most recently accessed first,
+// along with the total number of matches before the limit is applied
+func (sc *SemanticCache) ListEntries(filter CacheEntryFilter) ([]CacheEntrySummary, int) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	var summaries []CacheEntrySummary
+	for _, entry := range sc.entries {
+		age := time.Since(entry.Timestamp)
+		if (filter.NetworkID != "" && entry.NetworkID != filter.NetworkID) || !filter.Networks.Permits(entry.NetworkID) ||
+			(filter.MinAge > 0 && age < filter.MinAge) ||
+			(filter.MaxAge > 0 && age > filter.MaxAge) ||
+			entry.AccessCount < filter.MinAccessCount {
+			continue
+		}
+		summaries = append(summaries, sc.summarizeEntry(entry))
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].LastAccessed.After(summaries[j].LastAccessed)
+	})
+
+	total := len(summaries)
+	if filter.Limit > 0 && len(summaries) > filter.Limit {
+		summaries = summaries[:filter.Limit]
+	}
+	return summaries, total
+}
+
+// findEntry resolves a full hash or a unique hash prefix; entries of networks the policy
+// restricts are not found (assumes mutex is already locked)
+func (sc *SemanticCache) findEntry(hash string, networks *NetworkAccessPolicy) (*CacheEntry, error) {
+	if entry, exists := sc.entries[hash]; exists && networks.Permits(entry.NetworkID) {
+		return entry, nil
+	}
+	if len(hash) < 6 {
+		return nil, fmt.Errorf("cache entry %s not found (use at least 6 characters of the hash)", hash)
+	}
+
+	var match *CacheEntry
+	for key, entry := range sc.entries {
+		if len(key) >= len(hash) && key[:len(hash)] == hash && networks.Permits(entry.NetworkID) {
+			if match != nil {
+				return nil, fmt.Errorf("hash prefix %s is ambiguous", hash)
+			}
+			match = entry
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("cache entry %s not found", hash)
+	}
+	return match, nil
+}
+
+// InspectEntry returns the details of a cache entry, its most similar neighbors and
+// a pointer to where its result is stored
+func (sc *SemanticCache) InspectEntry(hash string, maxNeighbors int, networks *NetworkAccessPolicy) (*CacheEntryInspection, error) {
+	sc.mutex.RLock()
+	defer sc.mutex.RUnlock()
+
+	entry, err := sc.findEntry(hash, networks)
+	if err != nil {
+		return nil, err
+	}
+
+	inspection := &CacheEntryInspection{
+		CacheEntrySummary: sc.summarizeEntry(entry),
+		HasEmbedding:      len(entry.Embedding) > 0,
+		Result: CacheResultPointer{
+			Storage:          "memory",
+			UncompressedSize: entry.UncompressedSize,
+			Rows:             -1,
+		},
+		Neighbors: []CacheNeighbor{},
+	}
+
+	switch {
+	case entry.DiskPath != "":
+		inspection.Result.Storage = "disk"
+		inspection.Result.DiskPath = entry.DiskPath
+		inspection.Result.CompressedSize = entry.CompressedSize
+	case entry.IsCompressed:
+		inspection.Result.Storage = "memory_compressed"
+		inspection.Result.CompressedSize = entry.CompressedSize
+	}
+	if result, err := sc.getResultFromEntry(entry); err == nil && result != nil {
+		inspection.Result.Rows = len(result.Items)
+	}
+
+	if len(entry.Embedding) > 0 {
+		for _, other := range sc.embeddingIndex {
+			if other.Hash == entry.Hash {
+				continue
+			}
+			if neighbor, exists := sc.entries[other.Hash]; exists && !networks.Permits(neighbor.NetworkID) {
+				continue
+			}
+			similarity := sc.cosineSimilarity(entry.Embedding, other.Embedding)
+			inspection.Neighbors = append(inspection.Neighbors, CacheNeighbor{
+				Hash:       other.Hash,
+				Query:      other.Query,
+				Similarity: similarity,
+				WouldHit:   similarity >= sc.similarityThreshold,
+			})
+		}
+		sort.Slice(inspection.Neighbors, func(i, j int) bool {
+			return inspection.Neighbors[i].Similarity > inspection.Neighbors[j].Similarity
+		})
+		if maxNeighbors > 0 && len(inspection.Neighbors) > maxNeighbors {
+			inspection.Neighbors = inspection.Neighbors[:maxNeighbors]
+		}
+	}
+
+	return inspection, nil
+}
+
+// EvictEntry removes a single entry by hash or unique hash prefix and returns its summary
+func (sc *SemanticCache) EvictEntry(hash string, networks *NetworkAccessPolicy) (*CacheEntrySummary, error) {
+	sc.mutex.Lock()
+	defer sc.mutex.Unlock()
+
+	entry, err := sc.findEntry(hash, networks)
+	if err != nil {
+		return nil, err
+	}
+	summary := sc.summarizeEntry(entry)
+
+	sc.currentMemoryUsage -= summary.SizeBytes
+	delete(sc.entries, entry.Hash)
+	for i, indexEntry := range sc.embeddingIndex {
+		if indexEntry.Hash == entry.Hash {
+			sc.embeddingIndex = append(sc.embeddingIndex[:i], sc.embeddingIndex[i+1:]...)
+			break
+		}
+	}
+	if entry.DiskPath != "" {
+		if err := os.Remove(entry.DiskPath); err != nil && !os.IsNotExist(err) {
+			sc.logger.Warn("Failed to remove disk cache file %s: %v", entry.DiskPath, err)
+		}
+	}
+
+	if sc.metricsEnabled {
+		sc.metrics.EvictedCount++
+		sc.metrics.EvictionsByPolicy["manual"]++
+	}
+
+	sc.logger.Debug("CACHE EVICT (Manual): Removed entry for query: %s", truncateString(entry.Query, 50))
+	return &summary, nil
+}
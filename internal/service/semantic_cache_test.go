@@ -778,3 +778,135 @@ func TestMockEmbeddingService(t *testing.T) {
 func createTestLogger() *logger.Logger {
 	return logger.New()
 }
+
+// TestCacheEntryInspection tests listing, inspecting and evicting individual entries
+func TestCacheEntryInspection(t *testing.T) {
+	cache := NewSemanticCache(NewMockEmbeddingService(), createTestLogger(), "test", nil)
+	result := &forward.NQERunResult{Items: []map[string]interface{}{{"name": "router-1"}, {"name": "switch-1"}}}
+
+	queries := []struct{ query, networkID string }{
+		{"foreach device in network.devices select {name: device.name}", "net-a"},
+		{"foreach device in network.devices select {name: device.name, os: device.os}", "net-a"},
+		{"foreach interface in network.interfaces select {name: interface.name}", "net-b"},
+	}
+	for _, q := range queries {
+		if err := cache.Put(q.query, q.networkID, "latest", result); err != nil {
+			t.Fatalf("Failed to put result in cache: %v", err)
+		}
+	}
+	cache.Get(queries[0].query, "net-a", "latest")
+
+	t.Run("list_with_filters", func(t *testing.T) {
+		entries, total := cache.ListEntries(CacheEntryFilter{NetworkID: "net-a"})
+		if total != 2 || len(entries) != 2 {
+			t.Fatalf("Expected 2 entries for net-a, got %d", total)
+		}
+		if entries, _ := cache.ListEntries(CacheEntryFilter{MinAccessCount: 2}); len(entries) != 1 || entries[0].Query != queries[0].query {
+			t.Errorf("Expected only the accessed entry, got %+v", entries)
+		}
+		if entries, total := cache.ListEntries(CacheEntryFilter{Limit: 1}); len(entries) != 1 || total != 3 {
+			t.Errorf("Expected limit to apply after counting, got %d of %d", len(entries), total)
+		}
+		if _, total := cache.ListEntries(CacheEntryFilter{MinAge: time.Hour}); total != 0 {
+			t.Errorf("Expected no entries older than an hour, got %d", total)
+		}
+	})
+
+	entries, _ := cache.ListEntries(CacheEntryFilter{NetworkID: "net-b"})
+	hash := entries[0].Hash
+
+	t.Run("inspect_by_prefix", func(t *testing.T) {
+		inspection, err := cache.InspectEntry(hash[:12], 5, nil)
+		if err != nil {
+			t.Fatalf("Failed to inspect entry: %v", err)
+		}
+		if inspection.Query != queries[2].query || inspection.Result.Rows != 2 {
+			t.Errorf("Unexpected inspection: %+v", inspection)
+		}
+		if len(inspection.Neighbors) != 2 {
+			t.Errorf("Expected 2 neighbors, got %d", len(inspection.Neighbors))
+		}
+		if _, err := cache.InspectEntry("abc", 5, nil); err == nil {
+			t.Error("Expected short prefix to be rejected")
+		}
+	})
+
+	t.Run("restricted_networks", func(t *testing.T) {
+		policy := NewNetworkAccessPolicy(nil, []string{"net-b"})
+		if entries, total := cache.ListEntries(CacheEntryFilter{Networks: policy}); total != 2 || entries[0].NetworkID != "net-a" {
+			t.Errorf("Expected only the permitted network's entries, got %+v", entries)
+		}
+		if _, err := cache.InspectEntry(hash, 5, policy); err == nil {
+			t.Error("Expected an entry of a restricted network to be unknown")
+		}
+		if _, err := cache.EvictEntry(hash, policy); err == nil {
+			t.Error("Expected an entry of a restricted network not to be evicted")
+		}
+		entries, _ := cache.ListEntries(CacheEntryFilter{NetworkID: "net-a"})
+		if inspection, err := cache.InspectEntry(entries[0].Hash, 5, policy); err != nil || len(inspection.Neighbors) != 1 {
+			t.Errorf("Expected neighbors of restricted networks left out, got %+v, %v", inspection, err)
+		}
+	})
+
+	t.Run("evict_single_entry", func(t *testing.T) {
+		if _, err := cache.EvictEntry(hash, nil); err != nil {
+			t.Fatalf("Failed to evict entry: %v", err)
+		}
+		if _, total := cache.ListEntries(CacheEntryFilter{}); total != 2 {
+			t.Errorf("Expected 2 entries after eviction, got %d", total)
+		}
+		if _, found := cache.Get(queries[2].query, "net-b", "latest"); found {
+			t.Error("Expected evicted entry to miss")
+		}
+		if _, err := cache.EvictEntry(hash, nil); err == nil {
+			t.Error("Expected evicting a missing entry to fail")
+		}
+	})
+}
+
+// TestCategorySimilarityThresholds tests per-category and per-call threshold resolution
+func TestCategorySimilarityThresholds(t *testing.T) {
+	cfg := &config.SemanticCacheConfig{
+		MaxEntries:          100,
+		TTLHours:            1,
+		SimilarityThreshold: 0.5,
+		MaxMemoryMB:         10,
+		EvictionPolicy:      config.EvictionPolicyLRU,
+		CategoryThresholds:  map[string]float64{"Security": 0.999, "Hardware": 0.6},
+	}
+	cache := NewSemanticCache(NewKeywordEmbeddingService(), createTestLogger(), "test", cfg)
+
+	if threshold, source := cache.EffectiveThreshold(CacheLookupOptions{Category: "security"}); threshold != 0.999 || source != "category:Security" {
+		t.Errorf("Expected category threshold, got %.3f from %s", threshold, source)
+	}
+	if threshold, source := cache.EffectiveThreshold(CacheLookupOptions{Category: "Security", Threshold: 0.7}); threshold != 0.7 || source != "override" {
+		t.Errorf("Expected per-call override, got %.3f from %s", threshold, source)
+	}
+	if threshold, source := cache.EffectiveThreshold(CacheLookupOptions{Category: "L3"}); threshold != 0.5 || source != "global" {
+		t.Errorf("Expected global threshold, got %.3f from %s", threshold, source)
+	}
+
+	result := &forward.NQERunResult{Items: []map[string]interface{}{{"name": "router-1"}}}
+	if err := cache.Put("show all devices in the network", "162112", "latest", result); err != nil {
+		t.Fatalf("Failed to put result in cache: %v", err)
+	}
+
+	// Exact hits report the effective threshold too
+	_, hit, found := cache.Lookup("show all devices in the network", "162112", "latest", CacheLookupOptions{Category: "Hardware"})
+	if !found || hit.MatchType != "exact" || hit.Threshold != 0.6 {
+		t.Fatalf("Expected exact hit with category threshold, got %+v (found=%v)", hit, found)
+	}
+
+	// A similar query hits under the lenient global threshold but not the strict security one
+	similar := "list all devices in the network"
+	_, hit, found = cache.Lookup(similar, "162112", "latest", CacheLookupOptions{})
+	if !found || hit.MatchType != "semantic" || hit.ThresholdSource != "global" {
+		t.Fatalf("Expected semantic hit with global threshold, got %+v (found=%v)", hit, found)
+	}
+	if _, _, found := cache.Lookup(similar, "162112", "latest", CacheLookupOptions{Category: "Security"}); found {
+		t.Error("Expected strict security threshold to reject the semantic match")
+	}
+	if !contains(hit.String(), "threshold 0.50 from global") {
+		t.Errorf("Unexpected hit description: %s", hit.String())
+	}
+}
@@ -0,0 +1,729 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/netip"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	serviceMapEntityType   = "service_map"
+	appTierEntityType      = "app_tier"
+	middleboxEntityType    = "middlebox"
+	relationContainsTier   = "contains"   // service map → tier
+	relationDependsOn      = "depends_on" // tier → tier; tiers traverse (relationTraverses) middleboxes
+	defaultServiceMapName  = "default"
+	maxServiceMapEndpoints = 200
+	maxServiceMapFlows     = 500
+)
+
+// Middlebox kinds recognized on path hops
+const (
+	middleboxFirewall     = "firewall"
+	middleboxLoadBalancer = "load_balancer"
+)
+
+// Column names accepted in service endpoint CSV, after normalizeFactKey
+var (
+	serviceEndpointNameKeys = []string{"name", "endpoint", "host", "hostname"}
+	serviceEndpointTierKeys = []string{"tier", "apptier", "role", "service"}
+	serviceEndpointIPKeys   = []string{"ip", "address", "ipaddress", "addr"}
+)
+
+// loadBalancerMarkers identify load balancers by device type
+var loadBalancerMarkers = []string{"LOAD_BALANCER", "LOADBALANCER", "BIGIP", "F5", "NETSCALER", "AVI"}
+
+// ServiceEndpoint is an application endpoint of a service map
+type ServiceEndpoint struct {
+	Name  string `json:"name"`
+	Tier  string `json:"tier"`
+	IP    string `json:"ip"`
+	Proto *int   `json:"proto,omitempty"` // Protocol of the service the endpoint listens on
+	Port  string `json:"port,omitempty"`
+}
+
+// ServiceTier is a node of a service map grouping the endpoints of one tier
+type ServiceTier struct {
+	Name      string   `json:"name"`
+	Endpoints []string `json:"endpoints"`
+}
+
+// ServiceMiddlebox is a firewall or load balancer that flows between tiers traverse
+type ServiceMiddlebox struct {
+	Device string `json:"device"`
+	Kind   string `json:"kind"`
+}
+
+// ServiceDependency is a tier calling another tier, aggregated over the endpoint pairs
+type ServiceDependency struct {
+	From          string         `json:"from"`
+	To            string         `json:"to"`
+	Flows         int            `json:"flows"`
+	Connected     int            `json:"connected"`
+	Statuses      map[string]int `json:"statuses"`
+	Firewalls     []string       `json:"firewalls,omitempty"`
+	LoadBalancers []string       `json:"load_balancers,omitempty"`
+}
+
+// ServiceMap is the dependency graph of an application's tiers
+type ServiceMap struct {
+	Name         string              `json:"name"`
+	NetworkID    string              `json:"network_id"`
+	SnapshotID   string              `json:"snapshot_id"`
+	CreatedAt    time.Time           `json:"created_at"`
+	Tiers        []ServiceTier       `json:"tiers"`
+	Middleboxes  []ServiceMiddlebox  `json:"middleboxes"`
+	Dependencies []ServiceDependency `json:"dependencies"`
+	EntityID     string              `json:"entity_id,omitempty"`
+	Failed       []string            `json:"failed_batches,omitempty"`
+}
+
+// serviceFlow is one path search between two endpoints of different tiers
+type serviceFlow struct {
+	src, dst ServiceEndpoint
+	status   string
+	hops     []forward.BulkHop
+}
+
+// parseServiceEndpointCSV reads endpoints with a header row naming name, tier and ip, plus
+// optionally proto and port. Invalid rows are reported by line and skipped.
+func parseServiceEndpointCSV(data string) ([]ServiceEndpoint, []string, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, nil, fmt.Errorf("csv is empty")
+	}
+	reader := csv.NewReader(strings.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[normalizeFactKey(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	column := func(keys []string) int {
+		for _, key := range keys {
+			if index, ok := columns[key]; ok {
+				return index
+			}
+		}
+		return -1
+	}
+	ipCol, tierCol := column(serviceEndpointIPKeys), column(serviceEndpointTierKeys)
+	if ipCol < 0 || tierCol < 0 {
+		return nil, nil, fmt.Errorf("header must include tier and ip columns, e.g. tier,ip,proto,port")
+	}
+	nameCol, protoCol, portCol := column(serviceEndpointNameKeys), column(pathCSVProtoKeys), column(pathCSVPortKeys)
+	value := func(record []string, index int) string {
+		if index < 0 || index >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[index])
+	}
+
+	var endpoints []ServiceEndpoint
+	var problems []string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		fields := map[string]string{
+			"name": value(record, nameCol), "tier": value(record, tierCol), "ip": value(record, ipCol),
+			"proto": value(record, protoCol), "port": value(record, portCol),
+		}
+		endpoint, err := newServiceEndpoint(fields)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, problems, nil
+}
+
+// newServiceEndpoint validates the fields of one endpoint; the name defaults to the address
+func newServiceEndpoint(fields map[string]string) (ServiceEndpoint, error) {
+	endpoint := ServiceEndpoint{Tier: fields["tier"], Port: fields["port"]}
+	if endpoint.Tier == "" {
+		return endpoint, fmt.Errorf("tier is required")
+	}
+	address, err := netip.ParseAddr(fields["ip"])
+	if err != nil {
+		return endpoint, fmt.Errorf("ip is not a host address")
+	}
+	endpoint.IP = address.String()
+	endpoint.Name = firstNonEmpty(fields["name"], endpoint.IP)
+	if proto := fields["proto"]; proto != "" {
+		number, err := parseIPProtocol(proto)
+		if err != nil {
+			return endpoint, err
+		}
+		endpoint.Proto = &number
+	}
+	if endpoint.Port != "" {
+		if err := validatePortSpec(endpoint.Port); err != nil {
+			return endpoint, err
+		}
+		if endpoint.Proto == nil {
+			tcp := ipProtocolNumbers["tcp"]
+			endpoint.Proto = &tcp
+		}
+	}
+	return endpoint, nil
+}
+
+// serviceEndpointsFromEntities reads endpoints from memory entities of a type. Metadata names
+// the address (ip or address) and tier (tier or role) and may hold proto and port.
+func (s *ForwardMCPService) serviceEndpointsFromEntities(entityType string) ([]ServiceEndpoint, []string, error) {
+	entities, err := s.memorySystem.SearchEntities("", entityType, maxServiceMapEndpoints+1)
+	if err != nil {
+		return nil, nil, err
+	}
+	var endpoints []ServiceEndpoint
+	var problems []string
+	for _, entity := range entities {
+		field := func(keys ...string) string {
+			for _, key := range keys {
+				if value := strings.TrimSpace(resultValueString(entity.Metadata[key])); value != "" {
+					return value
+				}
+			}
+			return ""
+		}
+		endpoint, err := newServiceEndpoint(map[string]string{
+			"name": entity.Name, "tier": field("tier", "role"), "ip": field("ip", "address"),
+			"proto": field("proto", "protocol"), "port": field("port"),
+		})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("entity %s: %v", entity.Name, err))
+			continue
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, problems, nil
+}
+
+// serviceMapFlows pairs endpoints of different tiers in the same address family. With a tier
+// order, only flows from each tier to the next are included.
+func serviceMapFlows(endpoints []ServiceEndpoint, tierOrder []string) []serviceFlow {
+	next := make(map[string]string)
+	for i := 0; i+1 < len(tierOrder); i++ {
+		next[tierOrder[i]] = tierOrder[i+1]
+	}
+	var flows []serviceFlow
+	for _, src := range endpoints {
+		for _, dst := range endpoints {
+			if src.Tier == dst.Tier || strings.Contains(src.IP, ":") != strings.Contains(dst.IP, ":") {
+				continue
+			}
+			if len(tierOrder) > 0 && next[src.Tier] != dst.Tier {
+				continue
+			}
+			flows = append(flows, serviceFlow{src: src, dst: dst})
+		}
+	}
+	return flows
+}
+
+// middleboxKind reports whether a hop is a firewall or a load balancer
+func middleboxKind(hop forward.BulkHop) string {
+	deviceType := strings.ToUpper(hop.DeviceType)
+	if strings.Contains(deviceType, "FIREWALL") {
+		return middleboxFirewall
+	}
+	for _, marker := range loadBalancerMarkers {
+		if strings.Contains(deviceType, marker) {
+			return middleboxLoadBalancer
+		}
+	}
+	return ""
+}
+
+// measureServiceFlows runs the path search of every flow in batches, recording its status and
+// the hops of the delivered path (or of the first path when none is delivered)
+func (s *ForwardMCPService) measureServiceFlows(networkID, snapshotID string, flows []serviceFlow) []string {
+	apiSnapshotID := ""
+	if snapshotID != "latest" {
+		apiSnapshotID = snapshotID
+	}
+	var failed []string
+	for start := 0; start < len(flows); start += sweepBatchSize {
+		batch := flows[start:min(start+sweepBatchSize, len(flows))]
+		queries := make([]forward.PathSearchParams, len(batch))
+		for i, flow := range batch {
+			batch[i].status = connectivityUnmeasured
+			queries[i] = forward.PathSearchParams{SrcIP: flow.src.IP, DstIP: flow.dst.IP, IPProto: flow.dst.Proto, DstPort: flow.dst.Port}
+		}
+		responses, err := s.forwardClient.SearchPathsBulk(networkID, &forward.PathSearchBulkRequest{
+			Queries:    queries,
+			Intent:     "PREFER_DELIVERED",
+			MaxResults: 3,
+		}, apiSnapshotID)
+		if err != nil {
+			s.logger.Warn("Service map batch at %d failed: %v", start, err)
+			failed = append(failed, fmt.Sprintf("searches %d-%d: %s", start+1, start+len(batch), truncateString(err.Error(), 160)))
+			continue
+		}
+		for i, response := range responses {
+			if i >= len(batch) {
+				break
+			}
+			batch[i].status = connectivityStatus(response)
+			for j, path := range response.Info.Paths {
+				if j == 0 || classifyViolation(path) == "" {
+					batch[i].hops = path.Hops
+				}
+				if classifyViolation(path) == "" {
+					break
+				}
+			}
+		}
+	}
+	return failed
+}
+
+// assembleServiceMap aggregates measured flows into tiers, middleboxes and dependencies
+func assembleServiceMap(endpoints []ServiceEndpoint, flows []serviceFlow) ([]ServiceTier, []ServiceMiddlebox, []ServiceDependency) {
+	tierEndpoints := make(map[string][]string)
+	for _, endpoint := range endpoints {
+		tierEndpoints[endpoint.Tier] = append(tierEndpoints[endpoint.Tier], endpoint.Name)
+	}
+	tiers := make([]ServiceTier, 0, len(tierEndpoints))
+	for name, members := range tierEndpoints {
+		sort.Strings(members)
+		tiers = append(tiers, ServiceTier{Name: name, Endpoints: members})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Name < tiers[j].Name })
+
+	type tierPair struct{ from, to string }
+	dependencies := make(map[tierPair]*ServiceDependency)
+	middleboxes := make(map[string]string)
+	for _, flow := range flows {
+		key := tierPair{flow.src.Tier, flow.dst.Tier}
+		dependency := dependencies[key]
+		if dependency == nil {
+			dependency = &ServiceDependency{From: key.from, To: key.to, Statuses: make(map[string]int)}
+			dependencies[key] = dependency
+		}
+		dependency.Flows++
+		dependency.Statuses[flow.status]++
+		if flow.status == connectivityConnected {
+			dependency.Connected++
+		}
+		for _, hop := range flow.hops {
+			switch kind := middleboxKind(hop); kind {
+			case middleboxFirewall:
+				if !slices.Contains(dependency.Firewalls, hop.DeviceName) {
+					dependency.Firewalls = append(dependency.Firewalls, hop.DeviceName)
+				}
+				middleboxes[hop.DeviceName] = kind
+			case middleboxLoadBalancer:
+				if !slices.Contains(dependency.LoadBalancers, hop.DeviceName) {
+					dependency.LoadBalancers = append(dependency.LoadBalancers, hop.DeviceName)
+				}
+				middleboxes[hop.DeviceName] = kind
+			}
+		}
+	}
+
+	edges := make([]ServiceDependency, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		sort.Strings(dependency.Firewalls)
+		sort.Strings(dependency.LoadBalancers)
+		edges = append(edges, *dependency)
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+	boxes := make([]ServiceMiddlebox, 0, len(middleboxes))
+	for device, kind := range middleboxes {
+		boxes = append(boxes, ServiceMiddlebox{Device: device, Kind: kind})
+	}
+	sort.Slice(boxes, func(i, j int) bool { return boxes[i].Device < boxes[j].Device })
+	return tiers, boxes, edges
+}
+
+// serviceMapEntityName is the memory entity name of a network's named service map
+func serviceMapEntityName(networkID, name string) string {
+	return fmt.Sprintf("service_map_%s_%s", networkID, name)
+}
+
+// appTierEntityName names a tier entity; like the map, tiers are scoped to their network
+func appTierEntityName(networkID, mapName, tier string) string {
+	return fmt.Sprintf("%s:%s:%s", networkID, mapName, tier)
+}
+
+// middleboxEntityName names a middlebox entity, shared by every map of its network
+func middleboxEntityName(networkID, device string) string {
+	return fmt.Sprintf("%s:%s", networkID, device)
+}
+
+// storeServiceMap saves a service map in the knowledge graph, replacing one of the same name:
+// the map entity contains a tier entity per tier, tiers depend_on the tiers they call and
+// traverse the middleboxes on the way
+func (s *ForwardMCPService) storeServiceMap(serviceMap *ServiceMap) error {
+	entityName := serviceMapEntityName(serviceMap.NetworkID, serviceMap.Name)
+	if existing, err := s.memorySystem.getEntityByNameAndType(entityName, serviceMapEntityType); err == nil {
+		// Pinned tiers are kept; recreating them below moves their pins to the new tiers
+		relations, _ := s.memorySystem.GetRelations(existing.ID, relationContainsTier)
+		for _, relation := range relations {
+			if relation.FromID == existing.ID {
+				s.memorySystem.DeleteEntity(relation.ToID)
+			}
+		}
+	}
+	// The graph lives in metadata so export_service_map can rebuild it without walking relations
+	mapEntity, err := s.memorySystem.ReplaceEntity(entityName, serviceMapEntityType, map[string]interface{}{
+		"name":         serviceMap.Name,
+		"network_id":   serviceMap.NetworkID,
+		"snapshot_id":  serviceMap.SnapshotID,
+		"created_at":   serviceMap.CreatedAt.Format(time.RFC3339),
+		"tiers":        len(serviceMap.Tiers),
+		"dependencies": len(serviceMap.Dependencies),
+		"graph":        MarshalCompactJSONString(serviceMap),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store service map %s: %w", serviceMap.Name, err)
+	}
+	serviceMap.EntityID = mapEntity.ID
+
+	tierIDs := make(map[string]string, len(serviceMap.Tiers))
+	for _, tier := range serviceMap.Tiers {
+		entity, err := s.memorySystem.CreateEntity(appTierEntityName(serviceMap.NetworkID, serviceMap.Name, tier.Name), appTierEntityType, map[string]interface{}{
+			"service_map": serviceMap.Name,
+			"network_id":  serviceMap.NetworkID,
+			"tier":        tier.Name,
+			"endpoints":   strings.Join(tier.Endpoints, ", "),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to store tier %s: %w", tier.Name, err)
+		}
+		tierIDs[tier.Name] = entity.ID
+		if _, err := s.memorySystem.CreateRelation(mapEntity.ID, entity.ID, relationContainsTier, nil); err != nil {
+			return err
+		}
+	}
+
+	middleboxIDs := make(map[string]string, len(serviceMap.Middleboxes))
+	for _, middlebox := range serviceMap.Middleboxes {
+		name := middleboxEntityName(serviceMap.NetworkID, middlebox.Device)
+		entity, err := s.memorySystem.getEntityByNameAndType(name, middleboxEntityType)
+		if err != nil {
+			if entity, err = s.memorySystem.CreateEntity(name, middleboxEntityType, map[string]interface{}{
+				"kind": middlebox.Kind, "network_id": serviceMap.NetworkID, "device": middlebox.Device,
+			}); err != nil {
+				return fmt.Errorf("failed to store middlebox %s: %w", middlebox.Device, err)
+			}
+		}
+		middleboxIDs[middlebox.Device] = entity.ID
+	}
+
+	for _, dependency := range serviceMap.Dependencies {
+		from := tierIDs[dependency.From]
+		if _, err := s.memorySystem.CreateRelation(from, tierIDs[dependency.To], relationDependsOn, map[string]interface{}{
+			"flows":          dependency.Flows,
+			"connected":      dependency.Connected,
+			"firewalls":      strings.Join(dependency.Firewalls, ", "),
+			"load_balancers": strings.Join(dependency.LoadBalancers, ", "),
+		}); err != nil {
+			return err
+		}
+		for _, device := range append(append([]string{}, dependency.Firewalls...), dependency.LoadBalancers...) {
+			if s.memorySystem.hasRelation(from, middleboxIDs[device], relationTraverses) {
+				continue
+			}
+			if _, err := s.memorySystem.CreateRelation(from, middleboxIDs[device], relationTraverses, map[string]interface{}{"to_tier": dependency.To}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// loadServiceMap reads a network's named service map
+func (s *ForwardMCPService) loadServiceMap(networkID, name string) (*ServiceMap, error) {
+	entity, err := s.memorySystem.getEntityByNameAndType(serviceMapEntityName(networkID, name), serviceMapEntityType)
+	if err != nil {
+		return nil, fmt.Errorf("no service map %q for network %s; run build_service_map first", name, networkID)
+	}
+	var serviceMap ServiceMap
+	if err := json.Unmarshal([]byte(resultValueString(entity.Metadata["graph"])), &serviceMap); err != nil {
+		return nil, fmt.Errorf("service map %q is corrupt: %w", name, err)
+	}
+	serviceMap.EntityID = entity.ID
+	return &serviceMap, nil
+}
+
+// buildServiceMap searches the paths between the tiers of an application and stores the
+// resulting dependency graph
+func (s *ForwardMCPService) buildServiceMap(args BuildServiceMapArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("build_service_map", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	var endpoints []ServiceEndpoint
+	var problems []string
+	switch {
+	case args.EntityType != "" && (args.CSV != "" || args.CSVFile != ""):
+		return nil, fmt.Errorf("use either csv/csv_file or entity_type, not both")
+	case args.EntityType != "":
+		var err error
+		if endpoints, problems, err = s.serviceEndpointsFromEntities(args.EntityType); err != nil {
+			return nil, fmt.Errorf("failed to read endpoints: %w", err)
+		}
+	case args.CSV != "" || args.CSVFile != "":
+		data, err := readPathSearchCSV(args.CSV, args.CSVFile)
+		if err != nil {
+			return nil, err
+		}
+		if endpoints, problems, err = parseServiceEndpointCSV(data); err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("provide endpoints as csv, csv_file or entity_type")
+	}
+	if len(endpoints) > maxServiceMapEndpoints {
+		return nil, fmt.Errorf("%d endpoints given; at most %d are mapped at once", len(endpoints), maxServiceMapEndpoints)
+	}
+	flows := serviceMapFlows(endpoints, args.TierOrder)
+	if len(flows) == 0 {
+		detail := ""
+		if len(problems) > 0 {
+			detail = ":\n- " + strings.Join(problems, "\n- ")
+		}
+		return nil, fmt.Errorf("no flows to search: endpoints must span at least two tiers (and follow tier_order when given)%s", detail)
+	}
+	if len(flows) > maxServiceMapFlows {
+		return nil, fmt.Errorf("%d flows between %d endpoints exceed the limit of %d; pass tier_order or fewer endpoints per tier", len(flows), len(endpoints), maxServiceMapFlows)
+	}
+
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	failed := s.measureServiceFlows(networkID, snapshotID, flows)
+	if len(failed) > 0 && !slices.ContainsFunc(flows, func(flow serviceFlow) bool { return flow.status != connectivityUnmeasured }) {
+		return nil, fmt.Errorf("service map not built, path searches failed: %s", failed[0])
+	}
+
+	serviceMap := &ServiceMap{
+		Name:       firstNonEmpty(args.Name, defaultServiceMapName),
+		NetworkID:  networkID,
+		SnapshotID: prefixIndexSnapshotKey(snapshotID),
+		CreatedAt:  time.Now().UTC(),
+		Failed:     failed,
+	}
+	serviceMap.Tiers, serviceMap.Middleboxes, serviceMap.Dependencies = assembleServiceMap(endpoints, flows)
+	if err := s.storeServiceMap(serviceMap); err != nil {
+		return nil, err
+	}
+	return s.serviceMapResponse(serviceMap, args.Format, problems)
+}
+
+// exportServiceMap returns a stored service map as GraphML or JSON
+func (s *ForwardMCPService) exportServiceMap(args ExportServiceMapArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("export_service_map", args, nil)
+
+	if s.memorySystem == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	serviceMap, err := s.loadServiceMap(networkID, firstNonEmpty(args.Name, defaultServiceMapName))
+	if err != nil {
+		return nil, err
+	}
+	return s.serviceMapResponse(serviceMap, firstNonEmpty(args.Format, "graphml"), nil)
+}
+
+// serviceMapResponse renders a service map as markdown, JSON or GraphML
+func (s *ForwardMCPService) serviceMapResponse(serviceMap *ServiceMap, format string, problems []string) (*mcp.ToolResponse, error) {
+	switch strings.ToLower(format) {
+	case "json":
+		data, err := json.MarshalIndent(serviceMap, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode service map: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	case "graphml":
+		data, err := serviceMapGraphML(serviceMap)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(data)), nil
+	case "", "markdown":
+		return mcp.NewToolResponse(mcp.NewTextContent(s.formatServiceMap(serviceMap, problems))), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q (use markdown, json or graphml)", format)
+}
+
+// formatServiceMap renders a service map as markdown
+func (s *ForwardMCPService) formatServiceMap(serviceMap *ServiceMap, problems []string) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Service Map %q: network %s (snapshot %s)\n\n", serviceMap.Name, serviceMap.NetworkID, serviceMap.SnapshotID))
+	endpoints := 0
+	for _, tier := range serviceMap.Tiers {
+		endpoints += len(tier.Endpoints)
+	}
+	flows := 0
+	for _, dependency := range serviceMap.Dependencies {
+		flows += dependency.Flows
+	}
+	text.WriteString(fmt.Sprintf("Searched %d flows between %d endpoints in %d tiers, built %s. Stored in memory as entity %s.\n\n",
+		flows, endpoints, len(serviceMap.Tiers), s.timeFormatter.FormatWithAge(serviceMap.CreatedAt), serviceMap.EntityID))
+	for _, failure := range serviceMap.Failed {
+		text.WriteString(fmt.Sprintf("⚠️ Failed batch %s\n", failure))
+	}
+	if len(problems) > 0 {
+		text.WriteString(fmt.Sprintf("⚠️ %d endpoints skipped:\n", len(problems)))
+		for _, problem := range problems {
+			text.WriteString("- " + problem + "\n")
+		}
+		text.WriteString("\n")
+	}
+
+	text.WriteString("| From tier | To tier | Connected | Firewalls | Load balancers |\n|---|---|---|---|---|\n")
+	for _, dependency := range serviceMap.Dependencies {
+		text.WriteString(fmt.Sprintf("| %s | %s | %d/%d | %s | %s |\n", dependency.From, dependency.To, dependency.Connected, dependency.Flows,
+			firstNonEmpty(strings.Join(dependency.Firewalls, ", "), "-"), firstNonEmpty(strings.Join(dependency.LoadBalancers, ", "), "-")))
+	}
+	if len(serviceMap.Middleboxes) > 0 {
+		text.WriteString("\n**Middleboxes:**\n")
+		for _, middlebox := range serviceMap.Middleboxes {
+			var pairs []string
+			for _, dependency := range serviceMap.Dependencies {
+				if slices.Contains(dependency.Firewalls, middlebox.Device) || slices.Contains(dependency.LoadBalancers, middlebox.Device) {
+					pairs = append(pairs, dependency.From+" → "+dependency.To)
+				}
+			}
+			text.WriteString(fmt.Sprintf("- %s (%s): %s\n", middlebox.Device, strings.ReplaceAll(middlebox.Kind, "_", " "), strings.Join(pairs, ", ")))
+		}
+	}
+	text.WriteString("\nUse export_service_map for GraphML, or troubleshoot_connectivity on tiers that are not fully connected.\n")
+	return text.String()
+}
+
+// GraphML document structure
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// serviceMapGraphML renders a service map as GraphML: tiers and middleboxes are nodes, calls
+// between tiers are depends_on edges and each tier has a traverses edge to the middleboxes
+// its calls cross
+func serviceMapGraphML(serviceMap *ServiceMap) (string, error) {
+	document := graphMLDocument{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "endpoints", For: "node", Name: "endpoints", Type: "string"},
+			{ID: "relation", For: "edge", Name: "relation", Type: "string"},
+			{ID: "flows", For: "edge", Name: "flows", Type: "int"},
+			{ID: "connected", For: "edge", Name: "connected", Type: "int"},
+			{ID: "via", For: "edge", Name: "via", Type: "string"},
+		},
+		Graph: graphMLGraph{ID: serviceMap.Name, EdgeDefault: "directed"},
+	}
+	for _, tier := range serviceMap.Tiers {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{ID: "tier:" + tier.Name, Data: []graphMLData{
+			{Key: "label", Value: tier.Name}, {Key: "kind", Value: "tier"}, {Key: "endpoints", Value: strings.Join(tier.Endpoints, ", ")},
+		}})
+	}
+	for _, middlebox := range serviceMap.Middleboxes {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{ID: "device:" + middlebox.Device, Data: []graphMLData{
+			{Key: "label", Value: middlebox.Device}, {Key: "kind", Value: middlebox.Kind},
+		}})
+	}
+	traversed := make(map[string]bool)
+	for _, dependency := range serviceMap.Dependencies {
+		via := append(append([]string{}, dependency.Firewalls...), dependency.LoadBalancers...)
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			ID:     fmt.Sprintf("e%d", len(document.Graph.Edges)),
+			Source: "tier:" + dependency.From,
+			Target: "tier:" + dependency.To,
+			Data: []graphMLData{
+				{Key: "relation", Value: relationDependsOn},
+				{Key: "flows", Value: fmt.Sprint(dependency.Flows)},
+				{Key: "connected", Value: fmt.Sprint(dependency.Connected)},
+				{Key: "via", Value: strings.Join(via, ", ")},
+			},
+		})
+		for _, device := range via {
+			if traversed[dependency.From+"|"+device] {
+				continue
+			}
+			traversed[dependency.From+"|"+device] = true
+			document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+				ID:     fmt.Sprintf("e%d", len(document.Graph.Edges)),
+				Source: "tier:" + dependency.From,
+				Target: "device:" + device,
+				Data:   []graphMLData{{Key: "relation", Value: relationTraverses}},
+			})
+		}
+	}
+	data, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode GraphML: %w", err)
+	}
+	return xml.Header + string(data) + "\n", nil
+}
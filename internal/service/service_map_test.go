@@ -0,0 +1,181 @@
+package service
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// serviceMapClient answers path searches through a load balancer towards the app tier and a
+// firewall towards the database tier; everything towards the web tier is denied
+type serviceMapClient struct {
+	*MockForwardClient
+	requests []*forward.PathSearchBulkRequest
+}
+
+func (c *serviceMapClient) SearchPathsBulk(networkID string, request *forward.PathSearchBulkRequest, snapshotID string) ([]forward.PathSearchBulkResponse, error) {
+	c.requests = append(c.requests, request)
+	var responses []forward.PathSearchBulkResponse
+	for _, query := range request.Queries {
+		path := forward.BulkPath{ForwardingOutcome: "DELIVERED", SecurityOutcome: "PERMITTED"}
+		switch {
+		case strings.HasPrefix(query.DstIP, "10.2."):
+			path.Hops = []forward.BulkHop{{DeviceName: "sw-1", DeviceType: "SWITCH"}, {DeviceName: "lb-1", DeviceType: "LOAD_BALANCER"}}
+		case strings.HasPrefix(query.DstIP, "10.3."):
+			path.Hops = []forward.BulkHop{{DeviceName: "sw-1", DeviceType: "SWITCH"}, {DeviceName: "fw-1", DeviceType: "FIREWALL"}}
+		default:
+			path.SecurityOutcome = "DENIED"
+			path.Hops = []forward.BulkHop{{DeviceName: "fw-1", DeviceType: "FIREWALL"}}
+		}
+		responses = append(responses, forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{path}}})
+	}
+	return responses, nil
+}
+
+const serviceMapCSV = `name,tier,ip,proto,port
+web-1,web,10.1.0.1,tcp,443
+web-2,web,10.1.0.2,tcp,443
+app-1,app,10.2.0.1,tcp,8080
+db-1,db,10.3.0.1,tcp,5432
+broken,db,10.3.0.0/24,,
+`
+
+func TestParseServiceEndpointCSV(t *testing.T) {
+	endpoints, problems, err := parseServiceEndpointCSV(serviceMapCSV)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(endpoints) != 4 || len(problems) != 1 || !strings.Contains(problems[0], "line 6") {
+		t.Fatalf("Expected 4 endpoints and the subnet row rejected, got %d %v", len(endpoints), problems)
+	}
+	if db := endpoints[3]; db.Name != "db-1" || db.Port != "5432" || db.Proto == nil || *db.Proto != 6 {
+		t.Errorf("Unexpected endpoint: %+v", db)
+	}
+	if _, _, err := parseServiceEndpointCSV("name,ip\nweb-1,10.1.0.1\n"); err == nil {
+		t.Error("Expected a header without tier to be rejected")
+	}
+}
+
+func TestServiceMapFlows(t *testing.T) {
+	endpoints, _, _ := parseServiceEndpointCSV(serviceMapCSV)
+	if flows := serviceMapFlows(endpoints, nil); len(flows) != 10 {
+		t.Errorf("Expected every cross-tier pair in both directions, got %d", len(flows))
+	}
+	flows := serviceMapFlows(endpoints, []string{"web", "app", "db"})
+	if len(flows) != 3 {
+		t.Fatalf("Expected web→app twice and app→db once, got %d", len(flows))
+	}
+	for _, flow := range flows {
+		if !(flow.src.Tier == "web" && flow.dst.Tier == "app") && !(flow.src.Tier == "app" && flow.dst.Tier == "db") {
+			t.Errorf("Unexpected flow %s → %s", flow.src.Tier, flow.dst.Tier)
+		}
+	}
+}
+
+func TestBuildServiceMap(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	client := &serviceMapClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	service.forwardClient = client
+	memory := service.memorySystem
+
+	response, err := service.buildServiceMap(BuildServiceMapArgs{NetworkID: "162112", Name: "shop", CSV: serviceMapCSV})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{
+		"Searched 10 flows between 4 endpoints in 3 tiers",
+		"| app | db | 1/1 | fw-1 | - |",
+		"| web | app | 2/2 | - | lb-1 |",
+		"| app | web | 0/2 | fw-1 | - |",
+		"- lb-1 (load balancer): db → app, web → app",
+		"1 endpoints skipped",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if query := client.requests[0].Queries[0]; query.DstPort == "" || query.IPProto == nil {
+		t.Errorf("Expected searches towards the service port, got %+v", query)
+	}
+
+	// The knowledge graph holds the tiers, their dependencies and the middleboxes
+	web, err := memory.getEntityByNameAndType(appTierEntityName("162112", "shop", "web"), appTierEntityType)
+	if err != nil {
+		t.Fatalf("Expected the web tier entity, got %v", err)
+	}
+	if relations, _ := memory.GetRelations(web.ID, relationDependsOn); len(relations) != 4 {
+		t.Errorf("Expected web → app, web → db and the reverse calls, got %d", len(relations))
+	}
+	lb, err := memory.getEntityByNameAndType(middleboxEntityName("162112", "lb-1"), middleboxEntityType)
+	if err != nil || lb.Metadata["kind"] != middleboxLoadBalancer {
+		t.Fatalf("Expected the load balancer entity, got %+v %v", lb, err)
+	}
+	if !memory.hasRelation(web.ID, lb.ID, relationTraverses) {
+		t.Error("Expected the web tier to traverse the load balancer")
+	}
+
+	// A map of the same name in another network keeps its own tiers and middleboxes
+	if _, err := service.buildServiceMap(BuildServiceMapArgs{NetworkID: "162113", Name: "shop", CSV: serviceMapCSV, TierOrder: []string{"web", "app", "db"}}); err != nil {
+		t.Fatal(err)
+	}
+	if other, err := memory.getEntityByNameAndType(appTierEntityName("162113", "shop", "web"), appTierEntityType); err != nil || other.ID == web.ID {
+		t.Errorf("Expected a separate web tier in network 162113, got %+v %v", other, err)
+	}
+	if other, err := memory.getEntityByNameAndType(middleboxEntityName("162113", "lb-1"), middleboxEntityType); err != nil || other.ID == lb.ID {
+		t.Errorf("Expected a separate load balancer in network 162113, got %+v %v", other, err)
+	}
+
+	// Rebuilding replaces the tiers of the map rather than adding to them
+	if _, err := service.buildServiceMap(BuildServiceMapArgs{NetworkID: "162112", Name: "shop", CSV: serviceMapCSV, TierOrder: []string{"web", "app", "db"}}); err != nil {
+		t.Fatal(err)
+	}
+	web, _ = memory.getEntityByNameAndType(appTierEntityName("162112", "shop", "web"), appTierEntityType)
+	if relations, _ := memory.GetRelations(web.ID, relationDependsOn); len(relations) != 1 {
+		t.Errorf("Expected only web → app after the rebuild, got %d", len(relations))
+	}
+
+	response, err = service.exportServiceMap(ExportServiceMapArgs{NetworkID: "162112", Name: "shop"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var document graphMLDocument
+	if err := xml.Unmarshal([]byte(response.Content[0].TextContent.Text), &document); err != nil {
+		t.Fatalf("Expected valid GraphML, got %v", err)
+	}
+	if len(document.Graph.Nodes) != 5 || len(document.Graph.Edges) != 4 {
+		t.Errorf("Expected 3 tiers, 2 middleboxes, 2 dependencies and 2 traversals, got %d nodes and %d edges",
+			len(document.Graph.Nodes), len(document.Graph.Edges))
+	}
+	if edge := document.Graph.Edges[0]; edge.Source != "tier:app" || edge.Target != "tier:db" {
+		t.Errorf("Unexpected first edge: %+v", edge)
+	}
+
+	if _, err := service.exportServiceMap(ExportServiceMapArgs{NetworkID: "162112", Name: "missing"}); err == nil {
+		t.Error("Expected an unknown map to be rejected")
+	}
+}
+
+func TestBuildServiceMapFromEntities(t *testing.T) {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.forwardClient = &serviceMapClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	memory := service.memorySystem
+
+	memory.CreateEntity("web-1", "app_endpoint", map[string]interface{}{"tier": "web", "ip": "10.1.0.1"})
+	memory.CreateEntity("app-1", "app_endpoint", map[string]interface{}{"role": "app", "address": "10.2.0.1", "port": float64(8080)})
+
+	response, err := service.buildServiceMap(BuildServiceMapArgs{NetworkID: "162112", EntityType: "app_endpoint", TierOrder: []string{"web", "app"}, Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `"load_balancers": [`) || !strings.Contains(text, `"name": "default"`) {
+		t.Errorf("Expected the JSON map, got %s", text)
+	}
+	if _, err := service.buildServiceMap(BuildServiceMapArgs{NetworkID: "162112", EntityType: "nothing"}); err == nil {
+		t.Error("Expected an error without endpoints")
+	}
+}
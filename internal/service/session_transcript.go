@@ -0,0 +1,412 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	transcriptSessionType      = "investigation_session"
+	transcriptStepType         = "transcript_step"
+	transcriptStepRelation     = "has_step"  // Session → each of its steps
+	transcriptNextRelation     = "next_step" // Step → the step recorded after it
+	transcriptArgumentsType    = "tool_arguments"
+	transcriptResultType       = "tool_result"
+	maxTranscriptArgumentChars = 500
+	maxTranscriptResultChars   = 600
+	defaultTranscriptMaxSteps  = 100
+)
+
+// transcriptControlTools are not recorded, so reading a transcript does not extend it
+var transcriptControlTools = map[string]bool{
+	"start_session_transcript": true, "stop_session_transcript": true, "get_session_transcript": true,
+}
+
+// SessionTranscript records tool calls as a chain of memory entities: an investigation_session
+// entity linked to one transcript_step entity per call, each step linked to the next. Recording
+// is opt-in: it starts with start_session_transcript, or with the first call when
+// FORWARD_SESSION_TRANSCRIPT is set. A nil SessionTranscript records nothing.
+type SessionTranscript struct {
+	memory    *MemorySystem
+	formatter *TimeFormatter // Display timezone for default session titles
+	logger    *logger.Logger
+	autoStart bool
+
+	mu         sync.Mutex
+	session    *Entity // Session being recorded (nil when not recording)
+	steps      int
+	lastStepID string
+}
+
+// TranscriptStep is one recorded tool call
+type TranscriptStep struct {
+	Index      int       `json:"index"`
+	Tool       string    `json:"tool"`
+	RecordedAt time.Time `json:"recorded_at"`
+	DurationMS int64     `json:"duration_ms"`
+	Failed     bool      `json:"failed,omitempty"`
+	Arguments  string    `json:"arguments,omitempty"`
+	Result     string    `json:"result,omitempty"`
+}
+
+// NewSessionTranscript creates a recorder; autoStart begins a session with the first tool call
+func NewSessionTranscript(memory *MemorySystem, formatter *TimeFormatter, logger *logger.Logger, autoStart bool) *SessionTranscript {
+	if memory == nil {
+		return nil
+	}
+	return &SessionTranscript{memory: memory, formatter: formatter, logger: logger, autoStart: autoStart}
+}
+
+// Start begins recording a new session, or resumes the session with resumeID
+func (t *SessionTranscript) Start(title, resumeID string) (*Entity, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if resumeID != "" {
+		session, err := t.memory.GetEntity(resumeID)
+		if err != nil || session.Type != transcriptSessionType {
+			return nil, 0, fmt.Errorf("session transcript %s not found", resumeID)
+		}
+		steps, err := t.loadSteps(session.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		t.session, t.steps, t.lastStepID = session, len(steps), ""
+		if len(steps) > 0 {
+			t.lastStepID = steps[len(steps)-1].entityID
+		}
+		t.updateSession("recording")
+		return session, t.steps, nil
+	}
+	if err := t.begin(title); err != nil {
+		return nil, 0, err
+	}
+	return t.session, 0, nil
+}
+
+// begin creates a session entity; callers hold t.mu
+func (t *SessionTranscript) begin(title string) error {
+	now := time.Now().UTC()
+	if title == "" {
+		title = "Investigation " + t.formatter.Format(now)
+	}
+	session, err := t.memory.CreateEntity(fmt.Sprintf("session %s", now.Format("20060102T150405.000Z")), transcriptSessionType, map[string]interface{}{
+		"title":      title,
+		"started_at": now.Format(time.RFC3339),
+		"status":     "recording",
+		"steps":      0,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to start session transcript: %w", err)
+	}
+	t.session, t.steps, t.lastStepID = session, 0, ""
+	return nil
+}
+
+// Stop ends recording and returns the session and its step count
+func (t *SessionTranscript) Stop() (*Entity, int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.session == nil {
+		return nil, 0, fmt.Errorf("no session transcript is being recorded")
+	}
+	t.updateSession("stopped")
+	session, steps := t.session, t.steps
+	t.session, t.steps, t.lastStepID = nil, 0, ""
+	// Calls after an explicit stop are not recorded, even with automatic recording configured
+	t.autoStart = false
+	return session, steps, nil
+}
+
+// Active returns the session being recorded, or nil
+func (t *SessionTranscript) Active() *Entity {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.session
+}
+
+// updateSession stores the status and step count on the session entity; callers hold t.mu
+func (t *SessionTranscript) updateSession(status string) {
+	metadata := make(map[string]interface{}, len(t.session.Metadata)+2)
+	for key, value := range t.session.Metadata {
+		metadata[key] = value
+	}
+	metadata["status"] = status
+	metadata["steps"] = t.steps
+	if err := t.memory.updateEntityMetadata(t.session.ID, metadata); err != nil {
+		t.logger.Warn("Failed to update session transcript %s: %v", t.session.ID, err)
+		return
+	}
+	t.session.Metadata = metadata
+}
+
+// Record appends a tool call to the session being recorded. Failures are logged, never
+// returned, so recording cannot break a tool call.
+func (t *SessionTranscript) Record(tool string, args interface{}, response *mcp.ToolResponse, callErr error, duration time.Duration) {
+	if t == nil || transcriptControlTools[tool] {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.session == nil {
+		if !t.autoStart {
+			return
+		}
+		if err := t.begin(""); err != nil {
+			t.logger.Warn("%v", err)
+			return
+		}
+	}
+
+	index := t.steps + 1
+	step, err := t.memory.CreateEntity(fmt.Sprintf("%s step %d: %s", t.session.Name, index, tool), transcriptStepType, map[string]interface{}{
+		"session_id":  t.session.ID,
+		"index":       index,
+		"tool":        tool,
+		"duration_ms": duration.Milliseconds(),
+		"failed":      callErr != nil,
+		"recorded_at": time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		t.logger.Warn("Failed to record %s in session transcript: %v", tool, err)
+		return
+	}
+	if arguments, err := json.Marshal(args); err == nil && string(arguments) != "{}" {
+		if _, err := t.memory.AddObservation(step.ID, trimTranscriptText(string(arguments), maxTranscriptArgumentChars), transcriptArgumentsType, nil); err != nil {
+			t.logger.Warn("Failed to record %s arguments: %v", tool, err)
+		}
+	}
+	if _, err := t.memory.AddObservation(step.ID, summarizeTranscriptResult(response, callErr), transcriptResultType, nil); err != nil {
+		t.logger.Warn("Failed to record %s result: %v", tool, err)
+	}
+	if _, err := t.memory.CreateRelation(t.session.ID, step.ID, transcriptStepRelation, map[string]interface{}{"index": index}); err != nil {
+		t.logger.Warn("Failed to link transcript step %d: %v", index, err)
+	}
+	if t.lastStepID != "" {
+		if _, err := t.memory.CreateRelation(t.lastStepID, step.ID, transcriptNextRelation, nil); err != nil {
+			t.logger.Warn("Failed to chain transcript step %d: %v", index, err)
+		}
+	}
+	t.steps, t.lastStepID = index, step.ID
+	t.updateSession("recording")
+}
+
+// summarizeTranscriptResult trims a tool response or error to a one-paragraph summary
+func summarizeTranscriptResult(response *mcp.ToolResponse, callErr error) string {
+	if callErr != nil {
+		return "Error: " + trimTranscriptText(callErr.Error(), maxTranscriptResultChars)
+	}
+	if response != nil {
+		for _, content := range response.Content {
+			if content != nil && content.TextContent != nil {
+				return trimTranscriptText(content.TextContent.Text, maxTranscriptResultChars)
+			}
+		}
+	}
+	return "(no text result)"
+}
+
+// trimTranscriptText collapses whitespace and truncates text to limit characters
+func trimTranscriptText(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+	return text
+}
+
+// transcriptStep is a loaded step with the ID of its entity
+type transcriptStep struct {
+	TranscriptStep
+	entityID string
+}
+
+// loadSteps returns the steps of a session in recording order
+func (t *SessionTranscript) loadSteps(sessionID string) ([]transcriptStep, error) {
+	relations, err := t.memory.GetRelations(sessionID, transcriptStepRelation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load session transcript: %w", err)
+	}
+	var steps []transcriptStep
+	for _, relation := range relations {
+		if relation.FromID != sessionID {
+			continue
+		}
+		entity, err := t.memory.getEntityByID(relation.ToID)
+		if err != nil {
+			continue // Deleted step
+		}
+		index, _ := timeSeriesNumber(entity.Metadata["index"])
+		duration, _ := timeSeriesNumber(entity.Metadata["duration_ms"])
+		step := transcriptStep{entityID: entity.ID, TranscriptStep: TranscriptStep{
+			Index:      int(index),
+			Tool:       resultValueString(entity.Metadata["tool"]),
+			DurationMS: int64(duration),
+			RecordedAt: entity.CreatedAt,
+		}}
+		step.Failed, _ = entity.Metadata["failed"].(bool)
+		observations, err := t.memory.GetObservations(entity.ID, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transcript step %d: %w", step.Index, err)
+		}
+		for _, observation := range observations {
+			switch observation.Type {
+			case transcriptArgumentsType:
+				step.Arguments = observation.Content
+			case transcriptResultType:
+				step.Result = observation.Content
+			}
+		}
+		steps = append(steps, step)
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].Index < steps[j].Index })
+	return steps, nil
+}
+
+// recordToolHandler returns a handler with the same signature that appends each call to the
+// session transcript
+func (s *ForwardMCPService) recordToolHandler(toolName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if s.transcript == nil || transcriptControlTools[toolName] || handlerType.Kind() != reflect.Func ||
+		handlerType.NumIn() != 1 || handlerType.NumOut() != 2 || handlerType.Out(0) != toolResponseType {
+		return handler
+	}
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		start := time.Now()
+		results := value.Call(args)
+		response, _ := results[0].Interface().(*mcp.ToolResponse)
+		err, _ := results[1].Interface().(error)
+		s.transcript.Record(toolName, args[0].Interface(), response, err, time.Since(start))
+		return results
+	}).Interface()
+}
+
+// startSessionTranscript begins or resumes recording tool calls
+func (s *ForwardMCPService) startSessionTranscript(args StartSessionTranscriptArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("start_session_transcript", args, nil)
+
+	if s.transcript == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if active := s.transcript.Active(); active != nil && active.ID != args.SessionID {
+		return nil, fmt.Errorf("session transcript %s is already being recorded; stop it first with stop_session_transcript", active.ID)
+	}
+	session, steps, err := s.transcript.Start(args.Title, args.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	verb := "Started"
+	if args.SessionID != "" {
+		verb = fmt.Sprintf("Resumed (%d steps recorded so far)", steps)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"%s session transcript %s: %s.\n\nEvery tool call is now recorded with its arguments and a trimmed result. Review it with get_session_transcript session_id=%s; stop_session_transcript ends recording.",
+		verb, session.ID, resultValueString(session.Metadata["title"]), session.ID))), nil
+}
+
+// stopSessionTranscript ends recording
+func (s *ForwardMCPService) stopSessionTranscript(args StopSessionTranscriptArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("stop_session_transcript", args, nil)
+
+	if s.transcript == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	session, steps, err := s.transcript.Stop()
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"Stopped session transcript %s after %d steps. Resume it with start_session_transcript session_id=%s.",
+		session.ID, steps, session.ID))), nil
+}
+
+// getSessionTranscript returns the recorded steps of a session
+func (s *ForwardMCPService) getSessionTranscript(args GetSessionTranscriptArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_session_transcript", args, nil)
+
+	if s.transcript == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	var session *Entity
+	switch {
+	case args.SessionID != "":
+		entity, err := s.memorySystem.GetEntity(args.SessionID)
+		if err != nil || entity.Type != transcriptSessionType {
+			return nil, fmt.Errorf("session transcript %s not found", args.SessionID)
+		}
+		session = entity
+	case s.transcript.Active() != nil:
+		session = s.transcript.Active()
+	default:
+		// Sessions are touched on every step, so the first is the most recently recorded
+		sessions, err := s.memorySystem.SearchEntities("", transcriptSessionType, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(sessions) == 0 {
+			return nil, fmt.Errorf("no session transcripts recorded; call start_session_transcript to begin one")
+		}
+		session = sessions[0]
+	}
+
+	steps, err := s.transcript.loadSteps(session.ID)
+	if err != nil {
+		return nil, err
+	}
+	maxSteps := args.MaxSteps
+	if maxSteps <= 0 {
+		maxSteps = defaultTranscriptMaxSteps
+	}
+	shown := steps
+	if len(shown) > maxSteps {
+		shown = shown[len(shown)-maxSteps:]
+	}
+
+	if args.Format == "json" {
+		export := struct {
+			SessionID string                 `json:"session_id"`
+			Name      string                 `json:"name"`
+			Metadata  map[string]interface{} `json:"metadata"`
+			Steps     []TranscriptStep       `json:"steps"`
+		}{SessionID: session.ID, Name: session.Name, Metadata: session.Metadata, Steps: []TranscriptStep{}}
+		for _, step := range shown {
+			export.Steps = append(export.Steps, step.TranscriptStep)
+		}
+		data, err := json.MarshalIndent(export, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal transcript: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Session Transcript: %s\n\n", resultValueString(session.Metadata["title"])))
+	text.WriteString(fmt.Sprintf("**Session:** %s\n**Status:** %s\n**Started:** %s\n**Steps:** %d\n\n",
+		session.ID, resultValueString(session.Metadata["status"]), s.timeFormatter.Format(session.CreatedAt), len(steps)))
+	if len(shown) < len(steps) {
+		text.WriteString(fmt.Sprintf("Showing the last %d steps.\n\n", len(shown)))
+	}
+	for _, step := range shown {
+		status := ""
+		if step.Failed {
+			status = " (failed)"
+		}
+		text.WriteString(fmt.Sprintf("### %d. %s%s\n_%s, %d ms_\n\n", step.Index, step.Tool, status, s.timeFormatter.Format(step.RecordedAt), step.DurationMS))
+		if step.Arguments != "" {
+			text.WriteString(fmt.Sprintf("**Arguments:** `%s`\n\n", step.Arguments))
+		}
+		text.WriteString(fmt.Sprintf("%s\n\n", step.Result))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// transcriptTestTool is a handler recorded by the transcript tests
+func transcriptTestTool(args GetEntityArgs) (*mcp.ToolResponse, error) {
+	if args.Identifier == "missing" {
+		return nil, fmt.Errorf("entity missing not found")
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent("Found   entity\n" + args.Identifier)), nil
+}
+
+func TestSessionTranscriptRecording(t *testing.T) {
+	service := createTestService()
+	service.transcript = NewSessionTranscript(service.memorySystem, service.timeFormatter, service.logger, false)
+	handler := service.recordToolHandler("get_entity", transcriptTestTool).(func(GetEntityArgs) (*mcp.ToolResponse, error))
+
+	// Nothing is recorded before a session starts
+	if _, err := handler(GetEntityArgs{Identifier: "core-1"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if service.transcript.Active() != nil {
+		t.Fatal("Expected no session without start_session_transcript")
+	}
+
+	if _, err := service.startSessionTranscript(StartSessionTranscriptArgs{Title: "INC-42 packet loss"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	session := service.transcript.Active()
+	if session == nil {
+		t.Fatal("Expected an active session")
+	}
+	handler(GetEntityArgs{Identifier: "core-1"})
+	if _, err := handler(GetEntityArgs{Identifier: "missing"}); err == nil {
+		t.Error("Expected the handler error to be returned unchanged")
+	}
+	if _, err := service.startSessionTranscript(StartSessionTranscriptArgs{}); err == nil {
+		t.Error("Expected an error starting a second session while recording")
+	}
+
+	response, err := service.getSessionTranscript(GetSessionTranscriptArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "INC-42 packet loss") || !strings.Contains(text, "### 1. get_entity") ||
+		!strings.Contains(text, "Found entity core-1") || !strings.Contains(text, "### 2. get_entity (failed)") {
+		t.Errorf("Unexpected transcript: %s", text)
+	}
+
+	// Steps form a chain after the session entity
+	steps, err := service.transcript.loadSteps(session.ID)
+	if err != nil || len(steps) != 2 {
+		t.Fatalf("Expected two steps, got %d (%v)", len(steps), err)
+	}
+	next, err := service.memorySystem.GetRelations(steps[0].entityID, transcriptNextRelation)
+	if err != nil || len(next) != 1 || next[0].ToID != steps[1].entityID {
+		t.Errorf("Expected step 1 linked to step 2, got %v %v", next, err)
+	}
+
+	if _, err := service.stopSessionTranscript(StopSessionTranscriptArgs{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	handler(GetEntityArgs{Identifier: "edge-1"})
+
+	// Resuming continues the numbering and the chain
+	if _, err := service.startSessionTranscript(StartSessionTranscriptArgs{SessionID: session.ID}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	handler(GetEntityArgs{Identifier: "leaf-1"})
+	response, err = service.getSessionTranscript(GetSessionTranscriptArgs{SessionID: session.ID, Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var export struct {
+		Steps []TranscriptStep `json:"steps"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &export); err != nil {
+		t.Fatalf("Expected JSON, got %v", err)
+	}
+	if len(export.Steps) != 3 || export.Steps[2].Index != 3 || !strings.Contains(export.Steps[2].Arguments, "leaf-1") || !export.Steps[1].Failed {
+		t.Errorf("Expected the resumed step appended as step 3, got %+v", export.Steps)
+	}
+	if next, _ := service.memorySystem.GetRelations(steps[1].entityID, transcriptNextRelation); len(next) != 2 {
+		t.Errorf("Expected step 2 linked to the resumed step, got %v", next)
+	}
+}
+
+func TestSessionTranscriptAutoStart(t *testing.T) {
+	service := createTestService()
+	service.transcript = NewSessionTranscript(service.memorySystem, service.timeFormatter, service.logger, true)
+	handler := service.recordToolHandler("get_entity", transcriptTestTool).(func(GetEntityArgs) (*mcp.ToolResponse, error))
+
+	handler(GetEntityArgs{Identifier: "core-1"})
+	session := service.transcript.Active()
+	if session == nil || session.Metadata["steps"] != 1 {
+		t.Fatalf("Expected the first call to start a session, got %+v", session)
+	}
+	service.transcript.Stop()
+	handler(GetEntityArgs{Identifier: "core-1"})
+	if service.transcript.Active() != nil {
+		t.Error("Expected no new session after an explicit stop")
+	}
+}
+
+func TestTrimTranscriptText(t *testing.T) {
+	if got := trimTranscriptText("a  b\n\tc", 10); got != "a b c" {
+		t.Errorf("Expected collapsed whitespace, got %q", got)
+	}
+	if got := trimTranscriptText(strings.Repeat("x", 20), 5); got != "xxxxx…" {
+		t.Errorf("Expected truncation, got %q", got)
+	}
+	if got := summarizeTranscriptResult(nil, fmt.Errorf("boom")); got != "Error: boom" {
+		t.Errorf("Expected the error, got %q", got)
+	}
+}
@@ -0,0 +1,360 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	// earthRadiusKm is the mean Earth radius used for great-circle distances
+	earthRadiusKm = 6371.0
+	// defaultSitePairs is how many site pairs suggest_site_pairs measures by default
+	defaultSitePairs = 10
+	// maxSitePairs bounds the path searches one suggest_site_pairs call issues
+	maxSitePairs = 50
+	// defaultMinSeparationKm keeps redundancy partners out of the same metro area
+	defaultMinSeparationKm = 50.0
+)
+
+// Site pair roles
+const (
+	sitePairRedundancy = "redundancy"
+	sitePairValidation = "validation"
+)
+
+// sitePairSite is a location with coordinates and a representative device
+type sitePairSite struct {
+	Location forward.Location
+	Device   string
+	IP       string
+}
+
+// SitePair is a suggested pair of sites with their distance and measured path
+type SitePair struct {
+	SiteA      string  `json:"site_a"`
+	SiteB      string  `json:"site_b"`
+	DistanceKm float64 `json:"distance_km"`
+	DeviceA    string  `json:"device_a,omitempty"`
+	DeviceB    string  `json:"device_b,omitempty"`
+	Role       string  `json:"role"`
+	Measured   bool    `json:"measured"`
+	Hops       int     `json:"hops"`
+	Delivered  bool    `json:"delivered"`
+	Outcome    string  `json:"outcome,omitempty"`
+	Suggestion string  `json:"suggestion"`
+
+	a, b int
+}
+
+// haversineKm returns the great-circle distance between two coordinates in kilometres
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// hasCoordinates reports whether a location has been placed on the map
+func hasCoordinates(location forward.Location) bool {
+	return location.Lat != 0 || location.Lng != 0
+}
+
+// suggestSitePairs pairs sites by geographic distance and measured path hop counts to
+// suggest redundancy partners and long-haul paths worth validating
+func (s *ForwardMCPService) suggestSitePairs(args SuggestSitePairsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("suggest_site_pairs", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	maxPairs := args.MaxPairs
+	if maxPairs <= 0 {
+		maxPairs = defaultSitePairs
+	}
+	if maxPairs > maxSitePairs {
+		maxPairs = maxSitePairs
+	}
+	minSeparation := args.MinSeparationKm
+	if minSeparation <= 0 {
+		minSeparation = defaultMinSeparationKm
+	}
+
+	locations, err := s.forwardClient.GetLocations(networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locations: %w", err)
+	}
+
+	wanted := make(map[string]bool)
+	for _, location := range args.Locations {
+		wanted[strings.ToLower(strings.TrimSpace(location))] = true
+	}
+
+	var sites []sitePairSite
+	var unplaced []string
+	for _, location := range locations {
+		if len(wanted) > 0 && !wanted[strings.ToLower(location.ID)] && !wanted[strings.ToLower(location.Name)] {
+			continue
+		}
+		if !hasCoordinates(location) {
+			unplaced = append(unplaced, location.Name)
+			continue
+		}
+		sites = append(sites, sitePairSite{Location: location})
+	}
+	if len(sites) < 2 {
+		return nil, fmt.Errorf("at least two locations with coordinates are required, found %d (set lat/lng with update_location)", len(sites))
+	}
+	sort.Slice(sites, func(i, j int) bool { return sites[i].Location.Name < sites[j].Location.Name })
+
+	var withoutDevice []string
+	if !args.SkipPathSearch {
+		withoutDevice = s.assignSiteDevices(networkID, sites)
+	}
+
+	pairs := selectSitePairs(sites, maxPairs, minSeparation)
+	var measureErr error
+	if !args.SkipPathSearch {
+		if measureErr = s.measureSitePairs(networkID, snapshotID, sites, pairs); measureErr != nil {
+			s.logger.Warn("Site pair path search failed: %v", measureErr)
+		}
+	}
+	annotateSitePairs(pairs)
+
+	return mcp.NewToolResponse(mcp.NewTextContent(formatSitePairs(networkID, len(sites), pairs, unplaced, withoutDevice, measureErr))), nil
+}
+
+// assignSiteDevices picks a physical device with a resolvable address at each site to act as
+// the path search endpoint, returning the names of sites without one
+func (s *ForwardMCPService) assignSiteDevices(networkID string, sites []sitePairSite) []string {
+	devices, err := s.getNetworkDevices(networkID, "")
+	if err != nil {
+		s.logger.Debug("Could not load inventory for site pairing: %v", err)
+		devices = nil
+	}
+
+	// The atlas assignment wins over the inventory field when both exist
+	atlas, err := s.forwardClient.GetDeviceLocations(networkID)
+	if err != nil {
+		s.logger.Debug("Could not load device locations for site pairing: %v", err)
+	}
+	byLocation := make(map[string][]forward.Device)
+	for _, device := range devices {
+		location := device.LocationID
+		if assigned, ok := atlas[device.Name]; ok {
+			location = assigned
+		}
+		if location != "" {
+			byLocation[location] = append(byLocation[location], device)
+		}
+	}
+
+	var missing []string
+	for i := range sites {
+		candidates := byLocation[sites[i].Location.ID]
+		sort.Slice(candidates, func(a, b int) bool { return candidates[a].Name < candidates[b].Name })
+		for _, device := range candidates {
+			if !s.classifyDevice(device).Movable() {
+				continue
+			}
+			if ip := deviceAddress(device); ip != "" {
+				sites[i].Device = device.Name
+				sites[i].IP = ip
+				break
+			}
+		}
+		if sites[i].Device == "" {
+			missing = append(missing, sites[i].Location.Name)
+		}
+	}
+	return missing
+}
+
+// deviceAddress returns a management address, or the first routable interface address
+func deviceAddress(device forward.Device) string {
+	if len(device.ManagementIPs) > 0 {
+		return device.ManagementIPs[0]
+	}
+	for _, iface := range device.Interfaces {
+		if ip := parseInterfaceAddress(iface.IPAddress); ip != nil && !ip.IsLinkLocalUnicast() {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// selectSitePairs chooses each site's nearest partner beyond the minimum separation as a
+// redundancy candidate, then fills the remaining budget with the longest pairs to validate
+func selectSitePairs(sites []sitePairSite, maxPairs int, minSeparation float64) []*SitePair {
+	var all []*SitePair
+	for i := 0; i < len(sites); i++ {
+		for j := i + 1; j < len(sites); j++ {
+			a, b := sites[i].Location, sites[j].Location
+			all = append(all, &SitePair{
+				SiteA:      a.Name,
+				SiteB:      b.Name,
+				DistanceKm: haversineKm(a.Lat, a.Lng, b.Lat, b.Lng),
+				Hops:       -1,
+				a:          i,
+				b:          j,
+			})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].DistanceKm < all[j].DistanceKm })
+
+	selected := make(map[*SitePair]bool)
+	var pairs []*SitePair
+	for site := range sites {
+		if len(pairs) >= maxPairs {
+			break
+		}
+		for _, pair := range all {
+			if (pair.a == site || pair.b == site) && pair.DistanceKm >= minSeparation {
+				if !selected[pair] {
+					selected[pair] = true
+					pair.Role = sitePairRedundancy
+					pairs = append(pairs, pair)
+				}
+				break
+			}
+		}
+	}
+	for i := len(all) - 1; i >= 0 && len(pairs) < maxPairs; i-- {
+		if !selected[all[i]] {
+			selected[all[i]] = true
+			all[i].Role = sitePairValidation
+			pairs = append(pairs, all[i])
+		}
+	}
+	return pairs
+}
+
+// measureSitePairs runs one bulk path search between the representative devices of each pair
+func (s *ForwardMCPService) measureSitePairs(networkID, snapshotID string, sites []sitePairSite, pairs []*SitePair) error {
+	var queries []forward.PathSearchParams
+	var measured []*SitePair
+	for _, pair := range pairs {
+		a, b := sites[pair.a], sites[pair.b]
+		pair.DeviceA, pair.DeviceB = a.Device, b.Device
+		if a.IP == "" || b.IP == "" {
+			continue
+		}
+		queries = append(queries, forward.PathSearchParams{From: a.Device, SrcIP: a.IP, DstIP: b.IP})
+		measured = append(measured, pair)
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	apiSnapshotID := ""
+	if snapshotID != "latest" {
+		apiSnapshotID = snapshotID
+	}
+	responses, err := s.forwardClient.SearchPathsBulk(networkID, &forward.PathSearchBulkRequest{
+		Queries:    queries,
+		Intent:     "PREFER_DELIVERED",
+		MaxResults: 1,
+	}, apiSnapshotID)
+	if err != nil {
+		return err
+	}
+
+	for i, pair := range measured {
+		if i >= len(responses) {
+			break
+		}
+		diagnosis := diagnosePathDirection("forward", queries[i], responses[i])
+		pair.Measured = true
+		pair.Delivered = diagnosis.Delivered
+		pair.Hops = len(diagnosis.RouteTrace)
+		pair.Outcome = firstNonEmpty(diagnosis.ForwardingOutcome, "NO_PATH")
+	}
+	return nil
+}
+
+// annotateSitePairs writes a suggestion for every pair. Delivered paths with at least twice
+// the median hop count of the measured pairs are flagged as likely detours.
+func annotateSitePairs(pairs []*SitePair) {
+	var hops []int
+	for _, pair := range pairs {
+		if pair.Measured && pair.Delivered {
+			hops = append(hops, pair.Hops)
+		}
+	}
+	median := 0
+	if len(hops) > 0 {
+		sort.Ints(hops)
+		median = hops[len(hops)/2]
+	}
+
+	for _, pair := range pairs {
+		switch {
+		case !pair.Measured && pair.Role == sitePairRedundancy:
+			pair.Suggestion = "Nearest site beyond the separation threshold; measure connectivity before pairing for redundancy"
+		case !pair.Measured:
+			pair.Suggestion = "Long-haul pair; validate the WAN path between these sites"
+		case !pair.Delivered:
+			pair.Suggestion = fmt.Sprintf("Path is not delivered (%s); validate connectivity before relying on this pair", pair.Outcome)
+		case median > 0 && pair.Hops >= 2*median:
+			pair.Suggestion = fmt.Sprintf("%d hops is twice the median of %d; check for a detour or hairpin", pair.Hops, median)
+		case pair.Role == sitePairRedundancy:
+			pair.Suggestion = "Good redundancy partner: nearby and reachable"
+		default:
+			pair.Suggestion = "Long-haul path delivered; keep in routine validation"
+		}
+	}
+}
+
+func formatSitePairs(networkID string, siteCount int, pairs []*SitePair, unplaced, withoutDevice []string, measureErr error) string {
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("# Site Pair Suggestions (network %s)\n\n", networkID))
+	out.WriteString(fmt.Sprintf("Evaluated %d sites with coordinates and suggested %d pairs.\n\n", siteCount, len(pairs)))
+
+	for _, role := range []string{sitePairRedundancy, sitePairValidation} {
+		var rows []*SitePair
+		for _, pair := range pairs {
+			if pair.Role == role {
+				rows = append(rows, pair)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		if role == sitePairRedundancy {
+			out.WriteString("## Redundancy Candidates\n\n")
+		} else {
+			out.WriteString("## Paths to Validate\n\n")
+		}
+		out.WriteString("| Site A | Site B | Distance (km) | Hops | Outcome | Suggestion |\n|---|---|---|---|---|---|\n")
+		for _, pair := range rows {
+			hops, outcome := "-", "not measured"
+			if pair.Measured {
+				hops = fmt.Sprintf("%d", pair.Hops)
+				outcome = pair.Outcome
+			}
+			out.WriteString(fmt.Sprintf("| %s | %s | %.0f | %s | %s | %s |\n",
+				pair.SiteA, pair.SiteB, pair.DistanceKm, hops, outcome, pair.Suggestion))
+		}
+		out.WriteString("\n")
+	}
+
+	if len(unplaced) > 0 {
+		out.WriteString(fmt.Sprintf("Locations without coordinates (skipped): %s\n", strings.Join(unplaced, ", ")))
+	}
+	if len(withoutDevice) > 0 {
+		out.WriteString(fmt.Sprintf("Sites without a physical device to measure from: %s\n", strings.Join(withoutDevice, ", ")))
+	}
+	if measureErr != nil {
+		out.WriteString(fmt.Sprintf("Hop counts unavailable, path search failed: %v\n", measureErr))
+	}
+	return out.String()
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// hopCountingPathClient answers bulk path searches with a fixed hop count per destination
+type hopCountingPathClient struct {
+	*MockForwardClient
+	hops    map[string]int
+	queries []forward.PathSearchParams
+}
+
+func (c *hopCountingPathClient) SearchPathsBulk(networkID string, request *forward.PathSearchBulkRequest, snapshotID string) ([]forward.PathSearchBulkResponse, error) {
+	c.queries = append(c.queries, request.Queries...)
+	var responses []forward.PathSearchBulkResponse
+	for _, query := range request.Queries {
+		count, ok := c.hops[query.DstIP]
+		if !ok {
+			responses = append(responses, forward.PathSearchBulkResponse{})
+			continue
+		}
+		path := forward.BulkPath{ForwardingOutcome: "DELIVERED"}
+		for i := 0; i < count; i++ {
+			path.Hops = append(path.Hops, forward.BulkHop{DeviceName: "hop"})
+		}
+		responses = append(responses, forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{path}}})
+	}
+	return responses, nil
+}
+
+func TestHaversineKm(t *testing.T) {
+	// New York to London is roughly 5570 km
+	distance := haversineKm(40.7128, -74.0060, 51.5074, -0.1278)
+	if math.Abs(distance-5570) > 20 {
+		t.Errorf("Expected ~5570 km, got %.1f", distance)
+	}
+	if distance := haversineKm(10, 10, 10, 10); distance != 0 {
+		t.Errorf("Expected 0 km for identical points, got %.1f", distance)
+	}
+}
+
+func TestSelectSitePairs(t *testing.T) {
+	sites := []sitePairSite{
+		{Location: forward.Location{Name: "chicago", Lat: 41.88, Lng: -87.63}},
+		{Location: forward.Location{Name: "london", Lat: 51.51, Lng: -0.13}},
+		{Location: forward.Location{Name: "newark", Lat: 40.73, Lng: -74.17}},
+		{Location: forward.Location{Name: "nyc", Lat: 40.71, Lng: -74.01}},
+	}
+
+	pairs := selectSitePairs(sites, 10, 50)
+	if len(pairs) != 6 {
+		t.Fatalf("Expected all 6 pairs within budget, got %d", len(pairs))
+	}
+	for _, pair := range pairs {
+		if pair.Role == sitePairRedundancy && pair.DistanceKm < 50 {
+			t.Errorf("Redundancy pair %s-%s is closer than the separation threshold", pair.SiteA, pair.SiteB)
+		}
+	}
+	// newark and nyc share a metro area, so they are only suggested for validation
+	for _, pair := range pairs {
+		if pair.SiteA == "newark" && pair.SiteB == "nyc" && pair.Role != sitePairValidation {
+			t.Errorf("Expected newark-nyc to be a validation pair, got %s", pair.Role)
+		}
+	}
+
+	limited := selectSitePairs(sites, 2, 50)
+	if len(limited) != 2 {
+		t.Errorf("Expected the pair budget to be honored, got %d", len(limited))
+	}
+}
+
+func TestSuggestSitePairs(t *testing.T) {
+	service := createTestService()
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.locations = []forward.Location{
+		{ID: "loc-nyc", Name: "nyc", Lat: 40.71, Lng: -74.01},
+		{ID: "loc-chi", Name: "chicago", Lat: 41.88, Lng: -87.63},
+		{ID: "loc-lon", Name: "london", Lat: 51.51, Lng: -0.13},
+		{ID: "loc-new", Name: "unplaced"},
+	}
+	mock.devices = []forward.Device{
+		{Name: "nyc-core", Type: "ROUTER", Vendor: "CISCO", LocationID: "loc-nyc", ManagementIPs: []string{"10.0.1.1"}},
+		{Name: "chi-core", Type: "ROUTER", Vendor: "CISCO", LocationID: "loc-chi", ManagementIPs: []string{"10.0.2.1"}},
+		{Name: "lon-core", Type: "ROUTER", Vendor: "CISCO", LocationID: "loc-lon", ManagementIPs: []string{"10.0.3.1"}},
+	}
+	mock.deviceLocations = nil
+	client := &hopCountingPathClient{MockForwardClient: mock, hops: map[string]int{"10.0.1.1": 3, "10.0.2.1": 3, "10.0.3.1": 9}}
+	service.forwardClient = client
+
+	response, err := service.suggestSitePairs(SuggestSitePairsArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+
+	expected := []string{
+		"Evaluated 3 sites with coordinates and suggested 3 pairs",
+		"## Redundancy Candidates",
+		"| chicago | nyc |",
+		"Good redundancy partner",
+		"twice the median",
+		"Locations without coordinates (skipped): unplaced",
+	}
+	for _, want := range expected {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected report to contain %q, got:\n%s", want, text)
+		}
+	}
+	if len(client.queries) != 3 {
+		t.Errorf("Expected one path search per pair, got %d", len(client.queries))
+	}
+
+	// Distances only
+	client.queries = nil
+	response, err = service.suggestSitePairs(SuggestSitePairsArgs{NetworkID: "162112", SkipPathSearch: true, Locations: []string{"nyc", "loc-lon"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "not measured") || len(client.queries) != 0 {
+		t.Errorf("Expected unmeasured pairs without path searches, got:\n%s", text)
+	}
+
+	if _, err := service.suggestSitePairs(SuggestSitePairsArgs{NetworkID: "162112", Locations: []string{"nyc"}}); err == nil {
+		t.Error("Expected an error with fewer than two placed locations")
+	}
+}
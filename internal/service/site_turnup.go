@@ -0,0 +1,508 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// siteTurnupMaxPrefixes bounds how many site prefixes are path-searched from each core service
+const siteTurnupMaxPrefixes = 5
+
+// Turn-up check stages, in checklist order
+const (
+	turnupStageDevices      = "devices collected"
+	turnupStageLocations    = "locations assigned"
+	turnupStagePrefixes     = "prefixes advertised"
+	turnupStageReachability = "core reachability"
+)
+
+var turnupStages = []string{turnupStageDevices, turnupStageLocations, turnupStagePrefixes, turnupStageReachability}
+
+// Turn-up check statuses
+const (
+	turnupPass = "pass"
+	turnupWarn = "warn"
+	turnupFail = "fail"
+)
+
+// Workflow state keys holding turn-up results
+const (
+	turnupStateSite     = "turnup_site"
+	turnupStateDevices  = "turnup_devices"
+	turnupStatePrefixes = "turnup_prefixes"
+	turnupStateChecks   = "turnup_checks"
+)
+
+// TurnupCheck is one line of the turn-up checklist
+type TurnupCheck struct {
+	Stage  string `json:"stage"`
+	Item   string `json:"item"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// sitePrefix is a LAN prefix configured on a site device
+type sitePrefix struct {
+	Prefix string
+	Device string
+	IP     string // Interface address, used as the reverse path destination
+}
+
+// SiteTurnupReport is the go/no-go checklist compiled at the end of the workflow
+type SiteTurnupReport struct {
+	NetworkID   string        `json:"network_id"`
+	SnapshotID  string        `json:"snapshot_id,omitempty"`
+	Site        string        `json:"site"`
+	SiteID      string        `json:"site_id"`
+	GeneratedAt time.Time     `json:"generated_at"`
+	Decision    string        `json:"decision"` // GO or NO-GO
+	Blockers    []string      `json:"blockers,omitempty"`
+	Checks      []TurnupCheck `json:"checks"`
+}
+
+// siteTurnupWorkflow guides validating a newly added site and produces a go/no-go checklist
+func (s *ForwardMCPService) siteTurnupWorkflow(args SiteTurnupWorkflowArgs) (*mcp.ToolResponse, error) {
+	sessionID := fmt.Sprintf("turnup_session_%v", args.SessionID)
+	step, err := s.resolveWorkflowStep(workflowSiteTurnup, sessionID, args.Step, args.Answer)
+	if err != nil {
+		return nil, err
+	}
+	state := s.workflowManager.GetState(sessionID)
+	if step != "start" && step != "site_selected" {
+		if _, ok := state.Parameters[turnupStateSite].(forward.Location); !ok {
+			return nil, fmt.Errorf("select a site first (step site_selected, answer network_id=<id>, site=<location>)")
+		}
+	}
+
+	var response *mcp.ToolResponse
+	switch step {
+	case "site_selected":
+		response, err = s.selectTurnupSite(sessionID, state, args.Answer)
+	case "check_devices":
+		response, err = s.checkTurnupDevices(sessionID, state, args.Answer)
+	case "check_prefixes":
+		response, err = s.checkTurnupPrefixes(sessionID, state)
+	case "check_reachability":
+		response, err = s.checkTurnupReachability(sessionID, state, args.Answer)
+	case "turnup_checklist":
+		response, err = s.compileTurnupChecklist(state)
+	default:
+		step = "start"
+		response = mcp.NewToolResponse(mcp.NewTextContent(`🏗️ **New Site Turn-up Validation Workflow**
+
+This workflow validates a newly added site before it is handed over:
+
+1. **site_selected** - Choose the network and the site (location) being turned up
+2. **check_devices** - Confirm the expected devices are collected and assigned to the site
+3. **check_prefixes** - Find the LAN prefixes configured at the site
+4. **check_reachability** - Path-search from the site to core services and back to each site prefix
+5. **turnup_checklist** - Produce the go/no-go checklist and store it as a report
+
+Every stage must run without failures for a GO decision.`))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.finishWorkflowStep(workflowSiteTurnup, sessionID, step, response), nil
+}
+
+// selectTurnupSite resolves the network and site and resets earlier results
+func (s *ForwardMCPService) selectTurnupSite(sessionID string, state *WorkflowState, answer string) (*mcp.ToolResponse, error) {
+	values := parseWorkflowKeyValues(answer)
+	networkID := s.getNetworkID(values["network_id"])
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	siteName := values["site"]
+	if siteName == "" {
+		return nil, fmt.Errorf("site is required (answer network_id=<id>, site=<location name or ID>)")
+	}
+
+	locations, err := s.forwardClient.GetLocations(networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get locations: %w", err)
+	}
+	var site *forward.Location
+	for i := range locations {
+		if locations[i].ID == siteName || strings.EqualFold(locations[i].Name, siteName) {
+			site = &locations[i]
+			break
+		}
+	}
+	if site == nil {
+		return nil, fmt.Errorf("site '%s' not found in network %s; create the location first", siteName, networkID)
+	}
+
+	state.NetworkID = networkID
+	state.SnapshotID = s.getSnapshotID(values["snapshot_id"])
+	state.Parameters[turnupStateSite] = *site
+	state.Parameters[turnupStateChecks] = map[string][]TurnupCheck{}
+	delete(state.Parameters, turnupStateDevices)
+	delete(state.Parameters, turnupStatePrefixes)
+	s.workflowManager.SetState(sessionID, state)
+
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"📍 Validating site %s (ID: %s) in network %s, snapshot %s.\n\nNext, list the devices expected at the site (comma-separated), or answer 'all' to check every device assigned to it.",
+		site.Name, site.ID, networkID, firstNonEmpty(state.SnapshotID, "latest")))), nil
+}
+
+// checkTurnupDevices confirms expected devices are collected and assigned to the site
+func (s *ForwardMCPService) checkTurnupDevices(sessionID string, state *WorkflowState, answer string) (*mcp.ToolResponse, error) {
+	site := state.Parameters[turnupStateSite].(forward.Location)
+	devices, err := s.getNetworkDevices(state.NetworkID, state.SnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device inventory: %w", err)
+	}
+	atlas, err := s.forwardClient.GetDeviceLocations(state.NetworkID)
+	if err != nil {
+		s.logger.Debug("Could not load device locations for site turn-up: %v", err)
+	}
+
+	byName := make(map[string]forward.Device, len(devices))
+	var assigned []string
+	for _, device := range devices {
+		byName[device.Name] = device
+		if deviceLocation(device, atlas) == site.ID {
+			assigned = append(assigned, device.Name)
+		}
+	}
+	sort.Strings(assigned)
+
+	expected := assigned
+	if answer = strings.TrimSpace(answer); !strings.EqualFold(answer, "all") {
+		expected = nil
+		for _, name := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+			if name = strings.TrimSpace(name); name != "" {
+				expected = append(expected, name)
+			}
+		}
+	}
+
+	var checks []TurnupCheck
+	var siteDevices []forward.Device
+	if len(expected) == 0 {
+		checks = append(checks, TurnupCheck{Stage: turnupStageDevices, Item: site.Name, Status: turnupFail, Detail: "no devices are assigned to the site"})
+	}
+	for _, name := range expected {
+		canonical := s.canonicalDeviceName(state.NetworkID, name, devices)
+		device, collected := byName[canonical]
+		if !collected {
+			checks = append(checks, TurnupCheck{Stage: turnupStageDevices, Item: name, Status: turnupFail, Detail: "not found in the snapshot; check collection and credentials"})
+			checks = append(checks, TurnupCheck{Stage: turnupStageLocations, Item: name, Status: turnupFail, Detail: "cannot be assigned until collected"})
+			continue
+		}
+		detail := ""
+		if canonical != name {
+			detail = fmt.Sprintf("collected as %s", canonical)
+		}
+		checks = append(checks, TurnupCheck{Stage: turnupStageDevices, Item: name, Status: turnupPass, Detail: detail})
+
+		switch location := deviceLocation(device, atlas); location {
+		case site.ID:
+			checks = append(checks, TurnupCheck{Stage: turnupStageLocations, Item: canonical, Status: turnupPass})
+			siteDevices = append(siteDevices, device)
+		case "":
+			checks = append(checks, TurnupCheck{Stage: turnupStageLocations, Item: canonical, Status: turnupFail, Detail: "no location assigned"})
+		default:
+			checks = append(checks, TurnupCheck{Stage: turnupStageLocations, Item: canonical, Status: turnupFail, Detail: fmt.Sprintf("assigned to location %s", location)})
+		}
+	}
+
+	state.Parameters[turnupStateDevices] = siteDevices
+	recordTurnupChecks(state, checks, turnupStageDevices, turnupStageLocations)
+	s.workflowManager.SetState(sessionID, state)
+
+	text := formatTurnupChecks(fmt.Sprintf("Devices at %s", site.Name), checks)
+	text += "\nNext, check the prefixes configured at the site with check_prefixes."
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
+}
+
+// deviceLocation returns a device's location, preferring the atlas assignment
+func deviceLocation(device forward.Device, atlas map[string]string) string {
+	if location, ok := atlas[device.Name]; ok {
+		return location
+	}
+	return device.LocationID
+}
+
+// checkTurnupPrefixes collects the LAN prefixes configured on the site's devices
+func (s *ForwardMCPService) checkTurnupPrefixes(sessionID string, state *WorkflowState) (*mcp.ToolResponse, error) {
+	site := state.Parameters[turnupStateSite].(forward.Location)
+	devices, _ := state.Parameters[turnupStateDevices].([]forward.Device)
+	if len(devices) == 0 {
+		return nil, fmt.Errorf("no devices are confirmed at site %s; run check_devices first", site.Name)
+	}
+
+	prefixes := siteLANPrefixes(devices)
+	var checks []TurnupCheck
+	withPrefix := make(map[string]bool)
+	for _, prefix := range prefixes {
+		withPrefix[prefix.Device] = true
+		checks = append(checks, TurnupCheck{Stage: turnupStagePrefixes, Item: prefix.Prefix, Status: turnupPass, Detail: fmt.Sprintf("configured on %s (%s)", prefix.Device, prefix.IP)})
+	}
+	for _, device := range devices {
+		if !withPrefix[device.Name] {
+			checks = append(checks, TurnupCheck{Stage: turnupStagePrefixes, Item: device.Name, Status: turnupWarn, Detail: "no LAN prefix configured on this device"})
+		}
+	}
+	if len(prefixes) == 0 {
+		checks = append(checks, TurnupCheck{Stage: turnupStagePrefixes, Item: site.Name, Status: turnupFail, Detail: "no LAN prefixes found on site devices"})
+	}
+
+	state.Parameters[turnupStatePrefixes] = prefixes
+	recordTurnupChecks(state, checks, turnupStagePrefixes)
+	s.workflowManager.SetState(sessionID, state)
+
+	text := formatTurnupChecks(fmt.Sprintf("Prefixes at %s", site.Name), checks)
+	text += "\nPrefixes are confirmed as advertised when core services can reach them. Next, run check_reachability with the core services to test (comma-separated IPs or device names)."
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
+}
+
+// siteLANPrefixes returns the distinct subnets configured on device interfaces, skipping
+// host routes and point-to-point links, which are not advertised as site prefixes
+func siteLANPrefixes(devices []forward.Device) []sitePrefix {
+	seen := make(map[string]bool)
+	var prefixes []sitePrefix
+	for _, device := range devices {
+		for _, iface := range device.Interfaces {
+			ip, subnet, err := net.ParseCIDR(strings.TrimSpace(iface.IPAddress))
+			if err != nil || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			ones, bits := subnet.Mask.Size()
+			if bits-ones <= 2 {
+				continue
+			}
+			if seen[subnet.String()] {
+				continue
+			}
+			seen[subnet.String()] = true
+			prefixes = append(prefixes, sitePrefix{Prefix: subnet.String(), Device: device.Name, IP: ip.String()})
+		}
+	}
+	sort.Slice(prefixes, func(i, j int) bool { return prefixes[i].Prefix < prefixes[j].Prefix })
+	return prefixes
+}
+
+// checkTurnupReachability path-searches from the site to each core service and from each
+// service back to the site's prefixes
+func (s *ForwardMCPService) checkTurnupReachability(sessionID string, state *WorkflowState, answer string) (*mcp.ToolResponse, error) {
+	site := state.Parameters[turnupStateSite].(forward.Location)
+	devices, _ := state.Parameters[turnupStateDevices].([]forward.Device)
+	prefixes, _ := state.Parameters[turnupStatePrefixes].([]sitePrefix)
+
+	var source forward.Device
+	for _, device := range devices {
+		if deviceAddress(device) != "" {
+			source = device
+			break
+		}
+	}
+	if source.Name == "" {
+		return nil, fmt.Errorf("no addressed device is confirmed at site %s; run check_devices first", site.Name)
+	}
+	sourceIP := deviceAddress(source)
+	if parsed := parseInterfaceAddress(sourceIP); parsed != nil {
+		sourceIP = parsed.String()
+	}
+	if len(prefixes) > siteTurnupMaxPrefixes {
+		prefixes = prefixes[:siteTurnupMaxPrefixes]
+	}
+
+	var queries []forward.PathSearchParams
+	var items []string
+	for _, service := range strings.Split(answer, ",") {
+		service = strings.TrimSpace(service)
+		if service == "" {
+			continue
+		}
+		endpoint, err := s.resolveTroubleshootEndpoint(state.NetworkID, service)
+		if err != nil || endpoint.IP == "" {
+			return nil, fmt.Errorf("failed to resolve core service '%s' to an IP address: %w", service, err)
+		}
+		queries = append(queries, forward.PathSearchParams{From: source.Name, SrcIP: sourceIP, DstIP: endpoint.IP})
+		items = append(items, fmt.Sprintf("%s → %s", source.Name, service))
+		for _, prefix := range prefixes {
+			queries = append(queries, forward.PathSearchParams{From: endpoint.Device, SrcIP: endpoint.IP, DstIP: prefix.IP})
+			items = append(items, fmt.Sprintf("%s → %s", service, prefix.Prefix))
+		}
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("at least one core service is required")
+	}
+
+	apiSnapshotID := ""
+	if state.SnapshotID != "latest" {
+		apiSnapshotID = state.SnapshotID
+	}
+	responses, err := s.forwardClient.SearchPathsBulk(state.NetworkID, &forward.PathSearchBulkRequest{
+		Queries:    queries,
+		Intent:     "PREFER_DELIVERED",
+		MaxResults: 1,
+	}, apiSnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute path search: %w", err)
+	}
+
+	checks := make([]TurnupCheck, 0, len(queries))
+	for i, query := range queries {
+		var response forward.PathSearchBulkResponse
+		if i < len(responses) {
+			response = responses[i]
+		}
+		diagnosis := diagnosePathDirection("forward", query, response)
+		status := turnupPass
+		if !diagnosis.Delivered {
+			status = turnupFail
+		}
+		checks = append(checks, TurnupCheck{Stage: turnupStageReachability, Item: items[i], Status: status, Detail: diagnosis.Finding})
+	}
+	recordTurnupChecks(state, checks, turnupStageReachability)
+	s.workflowManager.SetState(sessionID, state)
+
+	text := formatTurnupChecks(fmt.Sprintf("Reachability between %s and core services", site.Name), checks)
+	text += "\nNext, produce the go/no-go turnup_checklist."
+	return mcp.NewToolResponse(mcp.NewTextContent(text)), nil
+}
+
+// recordTurnupChecks replaces the results of the given stages in the workflow state
+func recordTurnupChecks(state *WorkflowState, checks []TurnupCheck, stages ...string) {
+	results, _ := state.Parameters[turnupStateChecks].(map[string][]TurnupCheck)
+	if results == nil {
+		results = make(map[string][]TurnupCheck)
+	}
+	for _, stage := range stages {
+		results[stage] = nil
+	}
+	for _, check := range checks {
+		results[check.Stage] = append(results[check.Stage], check)
+	}
+	state.Parameters[turnupStateChecks] = results
+}
+
+// formatTurnupChecks renders checks as a markdown table
+func formatTurnupChecks(title string, checks []TurnupCheck) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## %s\n\n| Check | Item | Status | Detail |\n|-------|------|--------|--------|\n", title))
+	for _, check := range checks {
+		text.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", check.Stage, check.Item, turnupStatusLabel(check.Status), check.Detail))
+	}
+	return text.String()
+}
+
+func turnupStatusLabel(status string) string {
+	switch status {
+	case turnupPass:
+		return "✅ pass"
+	case turnupWarn:
+		return "⚠️ warn"
+	}
+	return "❌ fail"
+}
+
+// compileTurnupChecklist decides go/no-go and stores the checklist as a report entity
+func (s *ForwardMCPService) compileTurnupChecklist(state *WorkflowState) (*mcp.ToolResponse, error) {
+	site := state.Parameters[turnupStateSite].(forward.Location)
+	results, _ := state.Parameters[turnupStateChecks].(map[string][]TurnupCheck)
+
+	report := buildSiteTurnupReport(results, time.Now())
+	report.NetworkID = state.NetworkID
+	report.SnapshotID = state.SnapshotID
+	report.Site = site.Name
+	report.SiteID = site.ID
+
+	var text strings.Builder
+	icon := "✅"
+	if report.Decision != "GO" {
+		icon = "⛔"
+	}
+	text.WriteString(fmt.Sprintf("# Site Turn-up Checklist: %s — %s %s\n\n", site.Name, icon, report.Decision))
+	for _, stage := range turnupStages {
+		status := turnupPass
+		count := 0
+		for _, check := range report.Checks {
+			if check.Stage != stage {
+				continue
+			}
+			count++
+			if check.Status == turnupFail || (check.Status == turnupWarn && status == turnupPass) {
+				status = check.Status
+			}
+		}
+		if count == 0 {
+			text.WriteString(fmt.Sprintf("- [ ] %s: not checked\n", stage))
+			continue
+		}
+		mark := "x"
+		if status == turnupFail {
+			mark = " "
+		}
+		text.WriteString(fmt.Sprintf("- [%s] %s: %s (%d checks)\n", mark, stage, turnupStatusLabel(status), count))
+	}
+	if len(report.Blockers) > 0 {
+		text.WriteString("\n## Blockers\n")
+		for _, blocker := range report.Blockers {
+			text.WriteString(fmt.Sprintf("- %s\n", blocker))
+		}
+	}
+
+	if s.memorySystem != nil {
+		if entityID, err := s.storeSiteTurnupReport(report); err != nil {
+			s.logger.Warn("Failed to store site turn-up report: %v", err)
+		} else {
+			text.WriteString(fmt.Sprintf("\nReport stored in memory (entity: %s).\n", entityID))
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// buildSiteTurnupReport flattens stage results in checklist order and decides go/no-go.
+// Every stage must have run and none may have failed for a GO.
+func buildSiteTurnupReport(results map[string][]TurnupCheck, now time.Time) *SiteTurnupReport {
+	report := &SiteTurnupReport{GeneratedAt: now, Decision: "GO"}
+	for _, stage := range turnupStages {
+		checks := results[stage]
+		if len(checks) == 0 {
+			report.Blockers = append(report.Blockers, fmt.Sprintf("%s: not checked", stage))
+		}
+		for _, check := range checks {
+			report.Checks = append(report.Checks, check)
+			if check.Status == turnupFail {
+				report.Blockers = append(report.Blockers, strings.TrimSpace(fmt.Sprintf("%s: %s %s", stage, check.Item, check.Detail)))
+			}
+		}
+	}
+	if len(report.Blockers) > 0 {
+		report.Decision = "NO-GO"
+	}
+	return report
+}
+
+// storeSiteTurnupReport saves the checklist as a memory entity with a JSON observation
+func (s *ForwardMCPService) storeSiteTurnupReport(report *SiteTurnupReport) (string, error) {
+	entity, err := s.memorySystem.CreateEntity(
+		fmt.Sprintf("site_turnup_%s_%s_%d", report.NetworkID, report.SiteID, report.GeneratedAt.Unix()),
+		"site_turnup_report",
+		map[string]interface{}{
+			"network_id":   report.NetworkID,
+			"snapshot_id":  report.SnapshotID,
+			"site":         report.Site,
+			"site_id":      report.SiteID,
+			"generated_at": report.GeneratedAt.Format(time.RFC3339),
+			"decision":     report.Decision,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create site turn-up entity: %w", err)
+	}
+	if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(report), "summary", nil); err != nil {
+		s.logger.Debug("Failed to add site turn-up summary: %v", err)
+	}
+	return entity.ID, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestParseWorkflowKeyValuesKeepsSpacesInValues(t *testing.T) {
+	values := parseWorkflowKeyValues("network_id=162112, site=Data Center 1; Snapshot_ID=s-1")
+	if values["network_id"] != "162112" || values["site"] != "Data Center 1" || values["snapshot_id"] != "s-1" {
+		t.Errorf("Unexpected parsed values: %v", values)
+	}
+}
+
+func TestSiteLANPrefixes(t *testing.T) {
+	devices := []forward.Device{
+		{Name: "edge-1", Interfaces: []forward.DeviceInterface{
+			{Name: "vlan10", IPAddress: "10.10.1.1/24"},
+			{Name: "wan0", IPAddress: "172.16.0.1/30"},
+			{Name: "lo0", IPAddress: "10.255.0.1/32"},
+			{Name: "mgmt", IPAddress: "192.168.1.1"},
+		}},
+		{Name: "edge-2", Interfaces: []forward.DeviceInterface{
+			{Name: "vlan10", IPAddress: "10.10.1.2/24"},
+			{Name: "vlan20", IPAddress: "2001:db8:10::1/64"},
+		}},
+	}
+
+	prefixes := siteLANPrefixes(devices)
+	if len(prefixes) != 2 {
+		t.Fatalf("Expected 2 LAN prefixes, got %+v", prefixes)
+	}
+	if prefixes[0].Prefix != "10.10.1.0/24" || prefixes[0].Device != "edge-1" || prefixes[0].IP != "10.10.1.1" {
+		t.Errorf("Unexpected first prefix: %+v", prefixes[0])
+	}
+	if prefixes[1].Prefix != "2001:db8:10::/64" {
+		t.Errorf("Unexpected second prefix: %+v", prefixes[1])
+	}
+}
+
+func TestBuildSiteTurnupReport(t *testing.T) {
+	results := map[string][]TurnupCheck{
+		turnupStageDevices:   {{Stage: turnupStageDevices, Item: "edge-1", Status: turnupPass}},
+		turnupStageLocations: {{Stage: turnupStageLocations, Item: "edge-1", Status: turnupPass}},
+		turnupStagePrefixes:  {{Stage: turnupStagePrefixes, Item: "edge-2", Status: turnupWarn}},
+	}
+	report := buildSiteTurnupReport(results, time.Now())
+	if report.Decision != "NO-GO" || len(report.Blockers) != 1 || report.Blockers[0] != "core reachability: not checked" {
+		t.Errorf("Expected a NO-GO for the unchecked stage, got %s %v", report.Decision, report.Blockers)
+	}
+
+	results[turnupStageReachability] = []TurnupCheck{{Stage: turnupStageReachability, Item: "edge-1 → dns", Status: turnupPass}}
+	if report := buildSiteTurnupReport(results, time.Now()); report.Decision != "GO" {
+		t.Errorf("Expected warnings alone to allow a GO, got %s %v", report.Decision, report.Blockers)
+	}
+}
+
+func TestSiteTurnupWorkflow(t *testing.T) {
+	service := createTestService()
+	mockClient := service.forwardClient.(*MockForwardClient)
+	mockClient.devices[0].Interfaces = []forward.DeviceInterface{{Name: "vlan10", IPAddress: "10.10.1.1/24"}}
+
+	if _, err := service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if _, err := service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1", Step: "site_selected", Answer: "network_id=162112, site=Nowhere"}); err == nil {
+		t.Error("Expected an unknown site to be rejected")
+	}
+	if _, err := service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1", Step: "site_selected", Answer: "network_id=162112, site=data center 1"}); err != nil {
+		t.Fatalf("Expected site selection to succeed, got: %v", err)
+	}
+
+	response, err := service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1", Step: "check_devices", Answer: "router-1, switch-1, edge-9"})
+	if err != nil {
+		t.Fatalf("Expected device checks to run, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, "assigned to location location-2") || !contains(text, "not found in the snapshot") {
+		t.Errorf("Expected misplaced and missing devices to fail, got: %s", text)
+	}
+
+	response, err = service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1", Step: "check_prefixes"})
+	if err != nil {
+		t.Fatalf("Expected prefix checks to run, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "10.10.1.0/24") {
+		t.Errorf("Expected the site prefix, got: %s", response.Content[0].TextContent.Text)
+	}
+
+	response, err = service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1", Step: "check_reachability", Answer: "10.0.0.53"})
+	if err != nil {
+		t.Fatalf("Expected reachability checks to run, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "10.0.0.53 → 10.10.1.0/24") {
+		t.Errorf("Expected a reverse path to the site prefix, got: %s", response.Content[0].TextContent.Text)
+	}
+
+	response, err = service.siteTurnupWorkflow(SiteTurnupWorkflowArgs{SessionID: "tu-1", Step: "turnup_checklist"})
+	if err != nil {
+		t.Fatalf("Expected checklist, got: %v", err)
+	}
+	text = response.Content[0].TextContent.Text
+	if !contains(text, "NO-GO") || !contains(text, "edge-9") || !contains(text, "Report stored in memory") {
+		t.Errorf("Unexpected checklist: %s", text)
+	}
+}
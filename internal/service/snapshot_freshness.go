@@ -0,0 +1,231 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// snapshotListTTL bounds how long a network's snapshot list is reused by freshness checks
+const snapshotListTTL = time.Minute
+
+// snapshotFreshnessExempt tools take a snapshot but do not analyze it
+var snapshotFreshnessExempt = map[string]bool{
+	"start_analysis_session": true, "end_analysis_session": true, "get_nqe_result_chunks": true,
+}
+
+// SnapshotFreshnessWarning describes why a tool response may be based on outdated data
+type SnapshotFreshnessWarning struct {
+	NetworkID       string  `json:"network_id"`
+	SnapshotID      string  `json:"snapshot_id"`
+	CreatedAt       string  `json:"created_at"`
+	AgeHours        float64 `json:"age_hours"`
+	ThresholdHours  float64 `json:"threshold_hours"`
+	Stale           bool    `json:"stale"`
+	NewerSnapshotID string  `json:"newer_snapshot_id,omitempty"`
+	NewerCreatedAt  string  `json:"newer_created_at,omitempty"`
+	NewerSnapshots  int     `json:"newer_snapshots,omitempty"`
+
+	created      time.Time
+	newerCreated time.Time
+}
+
+// snapshotList is a network's processed snapshots, newest first
+type snapshotList struct {
+	snapshots []forward.Snapshot
+	fetchedAt time.Time
+}
+
+// SnapshotFreshness checks whether the snapshot a tool call analyzes is older than the
+// network's threshold or has been superseded. Snapshot lists are cached per network for
+// snapshotListTTL so consecutive calls cost one API request. A nil SnapshotFreshness checks
+// nothing.
+type SnapshotFreshness struct {
+	maxAge   time.Duration
+	networks map[string]time.Duration
+	lists    map[string]snapshotList
+	mutex    sync.Mutex
+	now      func() time.Time
+}
+
+// NewSnapshotFreshness creates the checker, or returns nil when every threshold is off
+func NewSnapshotFreshness(cfg config.SnapshotFreshnessConfig) *SnapshotFreshness {
+	f := &SnapshotFreshness{
+		maxAge:   hoursDuration(cfg.MaxAgeHours),
+		networks: make(map[string]time.Duration, len(cfg.Networks)),
+		lists:    make(map[string]snapshotList),
+		now:      time.Now,
+	}
+	enabled := f.maxAge > 0
+	for networkID, hours := range cfg.Networks {
+		f.networks[networkID] = hoursDuration(hours)
+		enabled = enabled || hours > 0
+	}
+	if !enabled {
+		return nil
+	}
+	return f
+}
+
+// hoursDuration converts fractional hours; negative values are 0
+func hoursDuration(hours float64) time.Duration {
+	if hours <= 0 {
+		return 0
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// Threshold returns the age after which a network's snapshots are stale (0 when off)
+func (f *SnapshotFreshness) Threshold(networkID string) time.Duration {
+	if f == nil {
+		return 0
+	}
+	if threshold, ok := f.networks[networkID]; ok {
+		return threshold
+	}
+	return f.maxAge
+}
+
+// processedSnapshots returns a network's processed snapshots, newest first, from the cache
+// while it is fresh
+func (f *SnapshotFreshness) processedSnapshots(client forward.ClientInterface, networkID string) ([]forward.Snapshot, error) {
+	f.mutex.Lock()
+	list, ok := f.lists[networkID]
+	f.mutex.Unlock()
+	if ok && f.now().Sub(list.fetchedAt) < snapshotListTTL {
+		return list.snapshots, nil
+	}
+
+	snapshots, err := client.GetSnapshots(networkID)
+	if err != nil {
+		return nil, err
+	}
+	var processed []forward.Snapshot
+	for _, snapshot := range snapshots {
+		if !snapshot.IsDraft && (snapshot.State == "" || strings.EqualFold(snapshot.State, "PROCESSED")) {
+			processed = append(processed, snapshot)
+		}
+	}
+	sort.Slice(processed, func(i, j int) bool { return processed[i].CreationDateMillis > processed[j].CreationDateMillis })
+
+	f.mutex.Lock()
+	f.lists[networkID] = snapshotList{snapshots: processed, fetchedAt: f.now()}
+	f.mutex.Unlock()
+	return processed, nil
+}
+
+// Check returns a warning when the snapshot (the latest processed one when empty) is older than
+// the network's threshold or a newer processed snapshot exists, and nil when it is current
+func (f *SnapshotFreshness) Check(client forward.ClientInterface, networkID, snapshotID string) (*SnapshotFreshnessWarning, error) {
+	threshold := f.Threshold(networkID)
+	if threshold <= 0 || networkID == "" {
+		return nil, nil
+	}
+	snapshots, err := f.processedSnapshots(client, networkID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := -1
+	for i, snapshot := range snapshots {
+		if snapshotID == "" || snapshot.ID == snapshotID {
+			target = i
+			break
+		}
+	}
+	// Unknown or undated snapshots cannot be judged
+	if target < 0 || snapshots[target].CreationDateMillis <= 0 {
+		return nil, nil
+	}
+
+	created := epochTime(snapshots[target].CreationDateMillis)
+	warning := &SnapshotFreshnessWarning{
+		NetworkID:      networkID,
+		SnapshotID:     snapshots[target].ID,
+		AgeHours:       math.Round(f.now().Sub(created).Hours()*10) / 10,
+		ThresholdHours: math.Round(threshold.Hours()*10) / 10,
+		Stale:          f.now().Sub(created) > threshold,
+		NewerSnapshots: target,
+		created:        created,
+	}
+	if target > 0 {
+		warning.NewerSnapshotID = snapshots[0].ID
+		warning.newerCreated = epochTime(snapshots[0].CreationDateMillis)
+	}
+	if !warning.Stale && warning.NewerSnapshotID == "" {
+		return nil, nil
+	}
+	return warning, nil
+}
+
+// formatSnapshotFreshnessWarning renders a warning as a sentence followed by its fields as JSON
+func (s *ForwardMCPService) formatSnapshotFreshnessWarning(warning *SnapshotFreshnessWarning) string {
+	warning.CreatedAt = s.timeFormatter.Format(warning.created)
+	warning.NewerCreatedAt = s.timeFormatter.Format(warning.newerCreated)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "⚠️ Snapshot freshness: snapshot %s of network %s was created %s", warning.SnapshotID, warning.NetworkID, s.timeFormatter.Since(warning.created))
+	if warning.Stale {
+		fmt.Fprintf(&b, ", beyond the %gh threshold", warning.ThresholdHours)
+	}
+	b.WriteString(".")
+	if warning.NewerSnapshotID != "" {
+		fmt.Fprintf(&b, " %d newer snapshot(s) exist; the latest is %s (created %s). Pass snapshot_id=%s to analyze it.",
+			warning.NewerSnapshots, warning.NewerSnapshotID, s.timeFormatter.Since(warning.newerCreated), warning.NewerSnapshotID)
+	} else {
+		b.WriteString(" It is the latest processed snapshot; collect a new one to analyze current state.")
+	}
+	fmt.Fprintf(&b, "\n%s", MarshalCompactJSONString(map[string]interface{}{"snapshot_freshness": warning}))
+	return b.String()
+}
+
+// snapshotFreshnessWarning checks a tool call's snapshot and renders the warning ("" when the
+// snapshot is current or cannot be checked)
+func (s *ForwardMCPService) snapshotFreshnessWarning(networkID, snapshotID string) string {
+	warning, err := s.snapshotFreshness.Check(s.forwardClient, networkID, snapshotID)
+	if err != nil {
+		s.logger.Debug("Skipping snapshot freshness check on network %s: %v", networkID, err)
+		return ""
+	}
+	if warning == nil {
+		return ""
+	}
+	return s.formatSnapshotFreshnessWarning(warning)
+}
+
+// freshnessToolHandler returns a handler with the same signature that puts a snapshot
+// freshness warning at the top of a successful response when the snapshot it analyzed is
+// stale or superseded
+func (s *ForwardMCPService) freshnessToolHandler(toolName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if s.snapshotFreshness == nil || snapshotFreshnessExempt[toolName] || handlerType.Kind() != reflect.Func ||
+		handlerType.NumIn() != 1 || handlerType.NumOut() != 2 || handlerType.Out(0) != toolResponseType ||
+		!snapshotPinnable(handlerType.In(0)) {
+		return handler
+	}
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		results := value.Call(args)
+		response, ok := results[0].Interface().(*mcp.ToolResponse)
+		if !ok || response == nil {
+			return results
+		}
+		networkID, _ := s.toolNetworkID(args[0].Interface())
+		snapshotID := s.getSnapshotID(args[0].FieldByName("SnapshotID").String())
+		if warning := s.snapshotFreshnessWarning(networkID, snapshotID); warning != "" {
+			// Coalesced calls share the response, so the warning goes on a copy
+			contents := make([]*mcp.Content, 0, len(response.Content)+1)
+			contents = append(append(contents, mcp.NewTextContent(warning)), response.Content...)
+			results[0] = reflect.ValueOf(mcp.NewToolResponse(contents...))
+		}
+		return results
+	}).Interface()
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func TestSnapshotFreshnessCheck(t *testing.T) {
+	if NewSnapshotFreshness(config.SnapshotFreshnessConfig{}) != nil {
+		t.Error("Expected no checker without a threshold")
+	}
+	freshness := NewSnapshotFreshness(config.SnapshotFreshnessConfig{MaxAgeHours: 24, Networks: map[string]float64{"lab": 0, "prod": 2}})
+	now := time.Now()
+	freshness.now = func() time.Time { return now }
+	client := NewMockForwardClient()
+	client.snapshots = []forward.Snapshot{
+		{ID: "old", CreationDateMillis: now.Add(-72 * time.Hour).UnixMilli()},
+		{ID: "draft", IsDraft: true, CreationDateMillis: now.UnixMilli()},
+		{ID: "latest", CreationDateMillis: now.Add(-3 * time.Hour).UnixMilli()},
+	}
+
+	// The latest snapshot is within the default threshold but beyond the one for prod
+	if warning, err := freshness.Check(client, "162112", ""); err != nil || warning != nil {
+		t.Errorf("Expected the latest snapshot to be current, got %+v %v", warning, err)
+	}
+	warning, err := freshness.Check(client, "prod", "")
+	if err != nil || warning == nil || !warning.Stale || warning.SnapshotID != "latest" || warning.ThresholdHours != 2 || warning.NewerSnapshotID != "" {
+		t.Errorf("Expected the latest snapshot stale on prod, got %+v %v", warning, err)
+	}
+
+	// An older snapshot is stale and superseded; drafts do not count as newer
+	warning, _ = freshness.Check(client, "162112", "old")
+	if warning == nil || !warning.Stale || warning.AgeHours != 72 || warning.NewerSnapshotID != "latest" || warning.NewerSnapshots != 1 {
+		t.Errorf("Expected old to be stale with latest newer, got %+v", warning)
+	}
+
+	// Disabled networks and unknown snapshots are not judged
+	if warning, _ := freshness.Check(client, "lab", "old"); warning != nil {
+		t.Errorf("Expected no check on lab, got %+v", warning)
+	}
+	if warning, _ := freshness.Check(client, "162112", "missing"); warning != nil {
+		t.Errorf("Expected no warning for an unknown snapshot, got %+v", warning)
+	}
+
+	// Snapshot lists are reused until they expire
+	client.snapshots = nil
+	if warning, _ := freshness.Check(client, "162112", "old"); warning == nil {
+		t.Error("Expected the cached snapshot list to be used")
+	}
+	now = now.Add(2 * snapshotListTTL)
+	if warning, _ := freshness.Check(client, "162112", "old"); warning != nil {
+		t.Errorf("Expected the list refetched after expiry, got %+v", warning)
+	}
+}
+
+func TestFreshnessToolHandler(t *testing.T) {
+	service := createTestService()
+	service.snapshotFreshness = NewSnapshotFreshness(config.SnapshotFreshnessConfig{MaxAgeHours: 24})
+	client := service.forwardClient.(*MockForwardClient)
+	now := time.Now()
+	client.snapshots = []forward.Snapshot{
+		{ID: "s2", CreationDateMillis: now.Add(-30 * time.Hour).UnixMilli()},
+		{ID: "s1", CreationDateMillis: now.Add(-50 * time.Hour).UnixMilli()},
+	}
+
+	handler := service.freshnessToolHandler("list_devices", func(args pinnedToolArgs) (*mcp.ToolResponse, error) {
+		return mcp.NewToolResponse(mcp.NewTextContent("devices")), nil
+	}).(func(pinnedToolArgs) (*mcp.ToolResponse, error))
+
+	response, _ := handler(pinnedToolArgs{NetworkID: "162112", SnapshotID: "s1"})
+	if len(response.Content) != 2 || response.Content[1].TextContent.Text != "devices" {
+		t.Fatalf("Expected the warning before the response, got %d blocks", len(response.Content))
+	}
+	warning := response.Content[0].TextContent.Text
+	for _, expected := range []string{"⚠️ Snapshot freshness: snapshot s1 of network 162112 was created 2 days ago, beyond the 24h threshold",
+		"the latest is s2", "pass snapshot_id=s2", `"snapshot_freshness":{"network_id":"162112","snapshot_id":"s1"`, `"age_hours":50`} {
+		if !strings.Contains(strings.ToLower(warning), strings.ToLower(expected)) {
+			t.Errorf("Expected %q in: %s", expected, warning)
+		}
+	}
+
+	// The latest snapshot is stale but has nothing newer
+	response, _ = handler(pinnedToolArgs{NetworkID: "162112"})
+	if !strings.Contains(response.Content[0].TextContent.Text, "It is the latest processed snapshot") {
+		t.Errorf("Unexpected warning: %s", response.Content[0].TextContent.Text)
+	}
+
+	// Exempt tools and handlers without a snapshot are returned unchanged
+	exempt := service.freshnessToolHandler("get_nqe_result_chunks", handler).(func(pinnedToolArgs) (*mcp.ToolResponse, error))
+	if response, _ := exempt(pinnedToolArgs{NetworkID: "162112", SnapshotID: "s1"}); len(response.Content) != 2 {
+		t.Errorf("Expected the exempt wrapper to add nothing, got %d blocks", len(response.Content))
+	}
+	client.snapshots[0].CreationDateMillis = now.UnixMilli()
+	service.snapshotFreshness = NewSnapshotFreshness(config.SnapshotFreshnessConfig{MaxAgeHours: 24})
+	response, _ = handler(pinnedToolArgs{NetworkID: "162112", SnapshotID: "s2"})
+	if len(response.Content) != 1 {
+		t.Errorf("Expected no warning for a current snapshot, got %d blocks", len(response.Content))
+	}
+}
+
+func TestFreshnessToolHandlerCoalesced(t *testing.T) {
+	service := createTestService()
+	service.callCoalescer = NewCallCoalescer()
+	service.snapshotFreshness = NewSnapshotFreshness(config.SnapshotFreshnessConfig{MaxAgeHours: 24})
+	client := service.forwardClient.(*MockForwardClient)
+	client.snapshots = []forward.Snapshot{{ID: "s1", CreationDateMillis: time.Now().Add(-50 * time.Hour).UnixMilli()}}
+
+	release := make(chan struct{})
+	shared := mcp.NewToolResponse(mcp.NewTextContent("devices"))
+	handler := service.freshnessToolHandler("list_devices", service.coalesceToolHandler("list_devices", func(args ListDevicesArgs) (*mcp.ToolResponse, error) {
+		<-release
+		return shared, nil
+	})).(func(ListDevicesArgs) (*mcp.ToolResponse, error))
+
+	var wg sync.WaitGroup
+	responses := make([]*mcp.ToolResponse, 2)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], _ = handler(ListDevicesArgs{NetworkID: "162112", SnapshotID: "s1"})
+		}(i)
+	}
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		if service.callCoalescer.GetStats()["coalesced_calls"].(int64) == 1 {
+			break
+		}
+	}
+	close(release)
+	wg.Wait()
+
+	for _, response := range responses {
+		if response == nil || len(response.Content) != 2 || response.Content[1].TextContent.Text != "devices" {
+			t.Errorf("Expected one warning before the shared response, got %+v", response)
+		}
+	}
+	if len(shared.Content) != 1 {
+		t.Errorf("Expected the shared response left unchanged, got %d blocks", len(shared.Content))
+	}
+}
@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// snapshotPinCheckInterval bounds how often a pinned network is checked for a newer snapshot
+const snapshotPinCheckInterval = time.Minute
+
+// snapshotPinControlTools manage the analysis session themselves and are never pinned
+var snapshotPinControlTools = map[string]bool{
+	"start_analysis_session": true, "end_analysis_session": true,
+}
+
+// snapshotPin is the snapshot a network's calls use during an analysis session
+type snapshotPin struct {
+	SnapshotID string
+	Tool       string // Tool whose call recorded the pin
+	PinnedAt   time.Time
+	Newer      string // Newer snapshot seen at the last check ("" when the pin is current)
+	checkedAt  time.Time
+	warned     string // Newer snapshot the caller was already warned about
+}
+
+// pinSession is the analysis session of one caller
+type pinSession struct {
+	active bool
+	ended  bool // Ended explicitly, which turns automatic pinning off for the caller
+	pins   map[string]*snapshotPin
+}
+
+// SnapshotPins keeps the tool calls of an analysis session on one snapshot per network, so a
+// snapshot finishing processing mid-analysis does not silently switch the data sequential
+// calls see. The first call for a network that omits snapshot_id records the snapshot it
+// uses; later calls reuse it unless they pass snapshot_id. Sessions begin with
+// start_analysis_session, or with the first call when FORWARD_SNAPSHOT_PINNING is set.
+// Each caller (API key, or the unauthenticated transport) has its own session, so one
+// client's pins never apply to another's calls. A nil SnapshotPins pins nothing.
+type SnapshotPins struct {
+	autoStart bool
+	sessions  map[string]*pinSession // Keyed by callerID
+	mutex     sync.Mutex
+	now       func() time.Time
+}
+
+// NewSnapshotPins creates the pin store; autoStart begins a session with a caller's first tool call
+func NewSnapshotPins(autoStart bool) *SnapshotPins {
+	return &SnapshotPins{autoStart: autoStart, sessions: make(map[string]*pinSession), now: time.Now}
+}
+
+// session returns the session of a caller, creating an inactive one; the mutex must be held
+func (p *SnapshotPins) session(caller string) *pinSession {
+	session, ok := p.sessions[caller]
+	if !ok {
+		session = &pinSession{pins: make(map[string]*snapshotPin)}
+		p.sessions[caller] = session
+	}
+	return session
+}
+
+// Start begins a new analysis session for a caller, dropping the pins of its previous one
+func (p *SnapshotPins) Start(caller string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.sessions[caller] = &pinSession{active: true, pins: make(map[string]*snapshotPin)}
+}
+
+// Stop ends the analysis session of a caller and returns how many networks were pinned
+func (p *SnapshotPins) Stop(caller string) (int, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	session := p.session(caller)
+	if !session.active {
+		return 0, fmt.Errorf("no analysis session is active")
+	}
+	pinned := len(session.pins)
+	// Calls after an explicit end are not pinned, even with automatic pinning configured
+	p.sessions[caller] = &pinSession{ended: true, pins: make(map[string]*snapshotPin)}
+	return pinned, nil
+}
+
+// activate reports whether a caller's calls are pinned, starting its session when automatic
+func (p *SnapshotPins) activate(caller string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	session := p.session(caller)
+	if !session.active && p.autoStart && !session.ended {
+		session.active = true
+	}
+	return session.active
+}
+
+// Pinned returns the snapshot a network is pinned to in a caller's session
+func (p *SnapshotPins) Pinned(caller, networkID string) (string, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if pin, ok := p.session(caller).pins[networkID]; ok {
+		return pin.SnapshotID, true
+	}
+	return "", false
+}
+
+// Pin records the snapshot of a network in a caller's session unless one is pinned already,
+// and returns the pin that is in effect
+func (p *SnapshotPins) Pin(caller, networkID, snapshotID, tool string) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pins := p.session(caller).pins
+	if pin, ok := pins[networkID]; ok {
+		return pin.SnapshotID
+	}
+	now := p.now()
+	pins[networkID] = &snapshotPin{SnapshotID: snapshotID, Tool: tool, PinnedAt: now, checkedAt: now}
+	return snapshotID
+}
+
+// dueForCheck claims the next newer-snapshot check of a network in a caller's session
+func (p *SnapshotPins) dueForCheck(caller, networkID string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pin, ok := p.session(caller).pins[networkID]
+	if !ok || p.now().Sub(pin.checkedAt) < snapshotPinCheckInterval {
+		return false
+	}
+	pin.checkedAt = p.now()
+	return true
+}
+
+// observeLatest records the latest snapshot of a network and returns the newer snapshot the
+// caller has not been warned about yet ("" when there is none)
+func (p *SnapshotPins) observeLatest(caller, networkID, latestID string) string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	pin, ok := p.session(caller).pins[networkID]
+	if !ok {
+		return ""
+	}
+	pin.Newer = ""
+	if latestID == "" || latestID == pin.SnapshotID {
+		return ""
+	}
+	pin.Newer = latestID
+	if pin.warned == latestID {
+		return ""
+	}
+	pin.warned = latestID
+	return latestID
+}
+
+// List returns a copy of a caller's pins by network
+func (p *SnapshotPins) List(caller string) map[string]snapshotPin {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	session := p.session(caller)
+	pins := make(map[string]snapshotPin, len(session.pins))
+	for networkID, pin := range session.pins {
+		pins[networkID] = *pin
+	}
+	return pins
+}
+
+// snapshotPinnable reports whether tool arguments name both a network and a snapshot
+func snapshotPinnable(argsType reflect.Type) bool {
+	if argsType.Kind() != reflect.Struct {
+		return false
+	}
+	for _, name := range []string{"NetworkID", "SnapshotID"} {
+		field, ok := argsType.FieldByName(name)
+		if !ok || field.Type.Kind() != reflect.String {
+			return false
+		}
+	}
+	return true
+}
+
+// pinSnapshotHandler returns a context-aware handler that fills in the pinned snapshot of the
+// network when a call omits snapshot_id during the caller's analysis session, and warns once
+// per newer snapshot that the analysis is not using it
+func (s *ForwardMCPService) pinSnapshotHandler(toolName string, handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if s.snapshotPins == nil || snapshotPinControlTools[toolName] || handlerType.Kind() != reflect.Func ||
+		handlerType.NumIn() != 1 || handlerType.NumOut() != 2 || handlerType.Out(0) != toolResponseType ||
+		!snapshotPinnable(handlerType.In(0)) {
+		return handler
+	}
+	wrappedType := reflect.FuncOf([]reflect.Type{contextType, handlerType.In(0)},
+		[]reflect.Type{handlerType.Out(0), handlerType.Out(1)}, false)
+	return reflect.MakeFunc(wrappedType, func(args []reflect.Value) []reflect.Value {
+		caller := ""
+		if ctx, _ := args[0].Interface().(context.Context); ctx != nil {
+			caller = apiKeyIdentityFromContext(ctx).callerID()
+		}
+		if !s.snapshotPins.activate(caller) || args[1].FieldByName("SnapshotID").String() != "" {
+			return value.Call(args[1:])
+		}
+		networkID, _ := s.toolNetworkID(args[1].Interface())
+		if networkID == "" {
+			return value.Call(args[1:])
+		}
+		snapshotID, err := s.pinnedSnapshot(caller, networkID, toolName)
+		if err != nil {
+			s.logger.Debug("Not pinning a snapshot for %s on network %s: %v", toolName, networkID, err)
+			return value.Call(args[1:])
+		}
+
+		pinned := reflect.New(handlerType.In(0)).Elem()
+		pinned.Set(args[1])
+		pinned.FieldByName("SnapshotID").SetString(snapshotID)
+		results := value.Call([]reflect.Value{pinned})
+		if response, ok := results[0].Interface().(*mcp.ToolResponse); ok && response != nil {
+			if warning := s.newerSnapshotWarning(caller, networkID, snapshotID); warning != "" {
+				results[0] = reflect.ValueOf(withNote(response, warning))
+			}
+		}
+		return results
+	}).Interface()
+}
+
+// pinnedSnapshot returns the snapshot a network is pinned to, pinning the default snapshot or
+// the latest one on the network's first call of the caller's session
+func (s *ForwardMCPService) pinnedSnapshot(caller, networkID, toolName string) (string, error) {
+	if snapshotID, ok := s.snapshotPins.Pinned(caller, networkID); ok {
+		return snapshotID, nil
+	}
+	snapshotID := s.getSnapshotID("")
+	if snapshotID == "" {
+		snapshot, err := s.latestSnapshot(networkID)
+		if err != nil {
+			return "", err
+		}
+		if snapshot == nil || snapshot.ID == "" {
+			return "", fmt.Errorf("network has no processed snapshot")
+		}
+		snapshotID = snapshot.ID
+	}
+	return s.snapshotPins.Pin(caller, networkID, snapshotID, toolName), nil
+}
+
+// newerSnapshotWarning checks, at most once per snapshotPinCheckInterval, whether a network
+// has a snapshot newer than its pin and describes it the first time it is seen
+func (s *ForwardMCPService) newerSnapshotWarning(caller, networkID, snapshotID string) string {
+	if !s.snapshotPins.dueForCheck(caller, networkID) {
+		return ""
+	}
+	latest, err := s.forwardClient.GetLatestSnapshot(networkID)
+	if err != nil || latest == nil {
+		return ""
+	}
+	newer := s.snapshotPins.observeLatest(caller, networkID, latest.ID)
+	if newer == "" {
+		return ""
+	}
+	return fmt.Sprintf("⚠️ Snapshot %s is newer than snapshot %s this analysis session is pinned to on network %s. "+
+		"Results stay on %s for consistency; pass snapshot_id=%s to use the new snapshot for one call, or call start_analysis_session to re-pin.",
+		newer, snapshotID, networkID, snapshotID, newer)
+}
+
+// formatSnapshotPins lists the pins of the analysis session
+func (s *ForwardMCPService) formatSnapshotPins(pins map[string]snapshotPin) string {
+	if len(pins) == 0 {
+		return "No network is pinned yet; the next call that omits snapshot_id pins its network."
+	}
+	networkIDs := make([]string, 0, len(pins))
+	for networkID := range pins {
+		networkIDs = append(networkIDs, networkID)
+	}
+	sort.Strings(networkIDs)
+
+	var b strings.Builder
+	b.WriteString("Pinned snapshots:\n")
+	for _, networkID := range networkIDs {
+		pin := pins[networkID]
+		fmt.Fprintf(&b, "- Network %s: snapshot %s, pinned by %s %s", networkID, pin.SnapshotID, pin.Tool, s.timeFormatter.Since(pin.PinnedAt))
+		if pin.Newer != "" {
+			fmt.Fprintf(&b, " (newer snapshot %s available)", pin.Newer)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// startAnalysisSession begins the caller's analysis session, optionally pinning a snapshot right away
+func (s *ForwardMCPService) startAnalysisSession(args StartAnalysisSessionArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("start_analysis_session", args, nil)
+
+	if args.SnapshotID != "" && s.getNetworkID(args.NetworkID) == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	caller := args.Caller.callerID()
+	previous := s.snapshotPins.List(caller)
+	s.snapshotPins.Start(caller)
+	if args.SnapshotID != "" {
+		s.snapshotPins.Pin(caller, s.getNetworkID(args.NetworkID), args.SnapshotID, "start_analysis_session")
+	}
+
+	var b strings.Builder
+	b.WriteString("Started analysis session. Calls that omit snapshot_id now stay on the snapshot their network's first call used, ")
+	b.WriteString("with a warning when a newer snapshot finishes processing. Pass snapshot_id to override a single call; end_analysis_session stops pinning.\n\n")
+	if len(previous) > 0 {
+		fmt.Fprintf(&b, "Dropped %d pin(s) of the previous session.\n", len(previous))
+	}
+	b.WriteString(s.formatSnapshotPins(s.snapshotPins.List(caller)))
+	return mcp.NewToolResponse(mcp.NewTextContent(b.String())), nil
+}
+
+// endAnalysisSession stops pinning snapshots for the caller
+func (s *ForwardMCPService) endAnalysisSession(args EndAnalysisSessionArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("end_analysis_session", args, nil)
+
+	caller := args.Caller.callerID()
+	pins := s.snapshotPins.List(caller)
+	if _, err := s.snapshotPins.Stop(caller); err != nil {
+		return nil, err
+	}
+	text := "Ended analysis session; no network was pinned."
+	if len(pins) > 0 {
+		text = "Ended analysis session.\n\n" + s.formatSnapshotPins(pins)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text + "\nCalls that omit snapshot_id use the default or latest snapshot again.")), nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// pinnedToolArgs names a network and a snapshot like the arguments of snapshot-aware tools
+type pinnedToolArgs struct {
+	NetworkID  string
+	SnapshotID string
+}
+
+func TestSnapshotPinsAutoStart(t *testing.T) {
+	pins := NewSnapshotPins(false)
+	if pins.activate("") {
+		t.Error("Expected no session without start or automatic pinning")
+	}
+
+	pins = NewSnapshotPins(true)
+	if !pins.activate("") {
+		t.Fatal("Expected the first call to start the session")
+	}
+	if pinned := pins.Pin("", "n1", "s1", "list_devices"); pinned != "s1" {
+		t.Errorf("Expected s1 pinned, got %s", pinned)
+	}
+	if pinned := pins.Pin("", "n1", "s2", "search_paths"); pinned != "s1" {
+		t.Errorf("Expected the first pin to stay, got %s", pinned)
+	}
+	if count, err := pins.Stop(""); err != nil || count != 1 {
+		t.Errorf("Expected one pinned network, got %d %v", count, err)
+	}
+	if pins.activate("") {
+		t.Error("Expected an explicit end to turn automatic pinning off")
+	}
+	if _, err := pins.Stop(""); err == nil {
+		t.Error("Expected an error ending an inactive session")
+	}
+}
+
+func TestPinSnapshotHandler(t *testing.T) {
+	service := createTestService()
+	service.snapshotPins = NewSnapshotPins(false)
+	client := service.forwardClient.(*MockForwardClient)
+	now := time.Now()
+	service.snapshotPins.now = func() time.Time { return now }
+
+	var used []string
+	shared := paginatedResponse("ok", newPagination(1, 0, 10, 1))
+	pinned := service.pinSnapshotHandler("test_tool", func(args pinnedToolArgs) (*mcp.ToolResponse, error) {
+		used = append(used, args.SnapshotID)
+		return shared, nil
+	}).(func(context.Context, pinnedToolArgs) (*mcp.ToolResponse, error))
+	handler := func(args pinnedToolArgs) (*mcp.ToolResponse, error) { return pinned(context.Background(), args) }
+
+	// Without a session calls keep resolving the snapshot themselves
+	handler(pinnedToolArgs{NetworkID: "162112"})
+	if used[0] != "" {
+		t.Errorf("Expected no pin outside a session, got %q", used[0])
+	}
+
+	if _, err := service.startAnalysisSession(StartAnalysisSessionArgs{}); err != nil {
+		t.Fatal(err)
+	}
+	first := client.snapshots[0].ID
+	handler(pinnedToolArgs{NetworkID: "162112"})
+	if used[1] != first {
+		t.Fatalf("Expected the latest snapshot %s pinned, got %q", first, used[1])
+	}
+
+	// Another API key's calls are outside this session
+	other := WithAPIKeyIdentity(context.Background(), &APIKeyIdentity{ID: "other"})
+	pinned(other, pinnedToolArgs{NetworkID: "162112"})
+	if used[2] != "" {
+		t.Fatalf("Expected another caller's call left unpinned, got %q", used[2])
+	}
+	used = used[:2]
+
+	// A newer snapshot does not move the pin; the caller is warned once
+	client.snapshots = append([]forward.Snapshot{{ID: "snapshot-new"}}, client.snapshots...)
+	now = now.Add(2 * snapshotPinCheckInterval)
+	response, _ := handler(pinnedToolArgs{NetworkID: "162112"})
+	if used[2] != first {
+		t.Errorf("Expected the pin to hold, got %q", used[2])
+	}
+	if len(response.Content) != 3 || !strings.Contains(response.Content[1].TextContent.Text, "Snapshot snapshot-new is newer than snapshot "+first) ||
+		!strings.HasPrefix(response.Content[2].TextContent.Text, `{"pagination":`) {
+		t.Errorf("Expected a newer snapshot warning before the pagination block, got %+v", response.Content)
+	}
+	if len(shared.Content) != 2 {
+		t.Errorf("Expected the handler's response left unchanged, got %d blocks", len(shared.Content))
+	}
+	now = now.Add(2 * snapshotPinCheckInterval)
+	if response, _ := handler(pinnedToolArgs{NetworkID: "162112"}); len(response.Content) != 2 {
+		t.Error("Expected the warning only once per newer snapshot")
+	}
+
+	// An explicit snapshot overrides the pin for one call
+	handler(pinnedToolArgs{NetworkID: "162112", SnapshotID: "snapshot-new"})
+	handler(pinnedToolArgs{NetworkID: "162112"})
+	if used[4] != "snapshot-new" || used[5] != first {
+		t.Errorf("Expected an explicit override then the pin again, got %v", used[4:])
+	}
+
+	response, err := service.endAnalysisSession(EndAnalysisSessionArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Network 162112: snapshot "+first+", pinned by test_tool") ||
+		!strings.Contains(text, "newer snapshot snapshot-new available") {
+		t.Errorf("Unexpected summary: %s", text)
+	}
+	handler(pinnedToolArgs{NetworkID: "162112"})
+	if used[6] != "" {
+		t.Errorf("Expected no pin after the session ended, got %q", used[6])
+	}
+}
+
+func TestStartAnalysisSessionWithSnapshot(t *testing.T) {
+	service := createTestService()
+	service.snapshotPins = NewSnapshotPins(false)
+
+	response, err := service.startAnalysisSession(StartAnalysisSessionArgs{SnapshotID: "snapshot-old"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Network 162112: snapshot snapshot-old, pinned by start_analysis_session") {
+		t.Errorf("Expected the explicit pin, got %s", text)
+	}
+
+	// Starting again re-pins from the next call
+	response, _ = service.startAnalysisSession(StartAnalysisSessionArgs{})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Dropped 1 pin(s)") || !strings.Contains(text, "No network is pinned yet") {
+		t.Errorf("Expected the previous pins dropped, got %s", text)
+	}
+}
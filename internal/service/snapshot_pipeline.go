@@ -0,0 +1,769 @@
+package service
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/report"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Snapshot pipeline triggers
+const (
+	pipelineTriggerPoll    = "poll"
+	pipelineTriggerWebhook = "webhook"
+	pipelineTriggerManual  = "manual"
+)
+
+const (
+	pipelineRunEntityType   = "pipeline_run"
+	pipelineWebhookPath     = "/webhooks/snapshot"
+	maxPipelineRuns         = 50 // Runs kept for get_pipeline_runs
+	pipelineSampleRows      = 5  // Violating rows kept per failed assertion
+	pipelineNotifyTimeout   = 10 * time.Second
+	maxPipelineWebhookBytes = 1 << 20
+
+	pipelineWebhookHeaderTimeout = 10 * time.Second // Time a webhook client has to send its headers
+	pipelineWebhookTimeout       = 30 * time.Second // Time a webhook request may take to read or answer
+	pipelineWebhookIdleTimeout   = 60 * time.Second // Time an idle keep-alive connection stays open
+)
+
+// PipelineAssertionResult is the outcome of one playbook assertion
+type PipelineAssertionResult struct {
+	Name    string                   `json:"name"`
+	Rows    int                      `json:"rows"`
+	MaxRows int                      `json:"max_rows"`
+	Passed  bool                     `json:"passed"`
+	Sample  []map[string]interface{} `json:"sample,omitempty"`
+	Error   string                   `json:"error,omitempty"`
+}
+
+// PipelineQueryDiff is a key query's result compared with the previous snapshot
+type PipelineQueryDiff struct {
+	Name     string `json:"name"`
+	EntityID string `json:"entity_id,omitempty"` // Stored result of the new snapshot
+	Rows     int    `json:"rows"`
+	Added    int    `json:"added"`
+	Removed  int    `json:"removed"`
+	Changed  int    `json:"changed"`
+	Error    string `json:"error,omitempty"`
+}
+
+// PipelineRun is the result of running the playbook against one snapshot
+type PipelineRun struct {
+	NetworkID          string                    `json:"network_id"`
+	SnapshotID         string                    `json:"snapshot_id"`
+	PreviousSnapshotID string                    `json:"previous_snapshot_id,omitempty"`
+	Trigger            string                    `json:"trigger"`
+	StartedAt          time.Time                 `json:"started_at"`
+	FinishedAt         time.Time                 `json:"finished_at"`
+	Passed             bool                      `json:"passed"`
+	Assertions         []PipelineAssertionResult `json:"assertions,omitempty"`
+	Flows              []CriticalFlowCheck       `json:"key_paths,omitempty"`
+	FlowsError         string                    `json:"key_paths_error,omitempty"`
+	Diffs              []PipelineQueryDiff       `json:"changes,omitempty"`
+	EntityID           string                    `json:"entity_id,omitempty"`
+	Report             string                    `json:"report,omitempty"`
+	NotifyError        string                    `json:"notify_error,omitempty"`
+}
+
+// SnapshotPipeline runs the configured playbook once per new processed snapshot of the
+// watched networks and keeps the most recent runs. Runs never overlap, and a network has at
+// most one automatic run in progress with one newer snapshot queued behind it.
+type SnapshotPipeline struct {
+	config   config.SnapshotPipelineConfig
+	seen     map[string]forward.Snapshot // Network → newest snapshot run or current at startup
+	pending  map[string]forward.Snapshot // Network → newest snapshot announced during a run
+	active   map[string]bool             // Networks with an automatic run in progress
+	runs     []*PipelineRun              // Newest first
+	mutex    sync.Mutex
+	runMutex sync.Mutex
+	client   *http.Client
+}
+
+// NewSnapshotPipeline creates the pipeline, or returns nil when the playbook is empty
+func NewSnapshotPipeline(cfg config.SnapshotPipelineConfig) *SnapshotPipeline {
+	playbook := cfg.Playbook
+	if len(playbook.Assertions) == 0 && len(playbook.KeyQueries) == 0 && strings.TrimSpace(playbook.KeyPaths) == "" {
+		return nil
+	}
+	return &SnapshotPipeline{
+		config:  cfg,
+		seen:    make(map[string]forward.Snapshot),
+		pending: make(map[string]forward.Snapshot),
+		active:  make(map[string]bool),
+		client:  &http.Client{Timeout: pipelineNotifyTimeout},
+	}
+}
+
+// validatePipelinePlaybook checks that every query can run and the key paths parse
+func validatePipelinePlaybook(playbook config.PipelinePlaybook) error {
+	for _, query := range append(slices.Clone(playbook.Assertions), playbook.KeyQueries...) {
+		if query.Name == "" {
+			return fmt.Errorf("every playbook query needs a name")
+		}
+		if (query.QueryID == "") == (query.Query == "") {
+			return fmt.Errorf("playbook query %q needs exactly one of queryId and query", query.Name)
+		}
+	}
+	for _, query := range playbook.KeyQueries {
+		if len(query.KeyColumns) == 0 {
+			return fmt.Errorf("key query %q needs keyColumns to be diffed across snapshots", query.Name)
+		}
+	}
+	if strings.TrimSpace(playbook.KeyPaths) != "" {
+		if _, err := parseCriticalFlows(playbook.KeyPaths); err != nil {
+			return fmt.Errorf("invalid playbook keyPaths: %w", err)
+		}
+	}
+	return nil
+}
+
+// isNewer reports whether a snapshot is newer than the last one handled for the network, so
+// repeated or older snapshot IDs never run again (assumes mutex is already locked)
+func (p *SnapshotPipeline) isNewer(networkID string, snapshot forward.Snapshot) bool {
+	last, ok := p.seen[networkID]
+	return !ok || (snapshot.ID != last.ID && snapshot.CreationDateMillis > last.CreationDateMillis)
+}
+
+// markSeen records a snapshot as handled for a network unless a newer one already is
+func (p *SnapshotPipeline) markSeen(networkID string, snapshot forward.Snapshot) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.isNewer(networkID, snapshot) {
+		p.seen[networkID] = snapshot
+	}
+}
+
+// claim reserves an automatic run of a snapshot newer than the last one handled. While the
+// network has a run in progress the snapshot is queued behind it instead, replacing an older
+// queued one. It reports whether the caller runs the snapshot now and whether it was accepted.
+func (p *SnapshotPipeline) claim(networkID string, snapshot forward.Snapshot) (bool, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if !p.isNewer(networkID, snapshot) {
+		return false, false
+	}
+	if p.active[networkID] {
+		if queued, ok := p.pending[networkID]; !ok || snapshot.CreationDateMillis > queued.CreationDateMillis {
+			p.pending[networkID] = snapshot
+		}
+		return false, true
+	}
+	p.active[networkID] = true
+	p.seen[networkID] = snapshot
+	return true, true
+}
+
+// next ends a claimed run and returns the snapshot queued behind it, if it is still newer
+// than the last one handled; the claim then carries over to it
+func (p *SnapshotPipeline) next(networkID string) (forward.Snapshot, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	queued, ok := p.pending[networkID]
+	delete(p.pending, networkID)
+	if ok && p.isNewer(networkID, queued) {
+		p.seen[networkID] = queued
+		return queued, true
+	}
+	delete(p.active, networkID)
+	return forward.Snapshot{}, false
+}
+
+// addRun keeps a run, dropping the oldest beyond maxPipelineRuns
+func (p *SnapshotPipeline) addRun(run *PipelineRun) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.runs = append([]*PipelineRun{run}, p.runs...)
+	if len(p.runs) > maxPipelineRuns {
+		p.runs = p.runs[:maxPipelineRuns]
+	}
+}
+
+// Runs returns the kept runs of a network (all networks when empty), newest first
+func (p *SnapshotPipeline) Runs(networkID string) []*PipelineRun {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var runs []*PipelineRun
+	for _, run := range p.runs {
+		if networkID == "" || run.NetworkID == networkID {
+			runs = append(runs, run)
+		}
+	}
+	return runs
+}
+
+// StartSnapshotPipeline starts polling and the webhook listener of the snapshot pipeline.
+// It returns at once; both stop when the service shuts down. Snapshots that are current when
+// polling starts are not run again.
+func (s *ForwardMCPService) StartSnapshotPipeline() error {
+	p := s.pipeline
+	if p == nil || (p.config.PollIntervalMinutes <= 0 && p.config.WebhookAddr == "") {
+		return nil
+	}
+	if err := validatePipelinePlaybook(p.config.Playbook); err != nil {
+		return err
+	}
+	networks := s.pipelineNetworks()
+	if len(networks) == 0 {
+		return fmt.Errorf("the snapshot pipeline has no network to watch: set FORWARD_PIPELINE_NETWORKS or a default network")
+	}
+
+	if p.config.PollIntervalMinutes > 0 {
+		for _, networkID := range networks {
+			if latest, err := s.processedSnapshot(networkID, ""); err == nil {
+				p.markSeen(networkID, latest)
+			} else {
+				s.logger.Warn("Snapshot pipeline could not read the latest snapshot of network %s: %v", networkID, err)
+			}
+		}
+		go s.pollSnapshots(time.Duration(p.config.PollIntervalMinutes) * time.Minute)
+		s.logger.Info("Snapshot pipeline polling networks %s every %d minutes", strings.Join(networks, ", "), p.config.PollIntervalMinutes)
+	}
+
+	if p.config.WebhookAddr != "" {
+		if p.config.WebhookSecret == "" {
+			return fmt.Errorf("the snapshot pipeline webhook needs a secret: set FORWARD_PIPELINE_WEBHOOK_SECRET")
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc(pipelineWebhookPath, s.handleSnapshotWebhook)
+		server := &http.Server{
+			Addr:              p.config.WebhookAddr,
+			Handler:           mux,
+			ReadHeaderTimeout: pipelineWebhookHeaderTimeout,
+			ReadTimeout:       pipelineWebhookTimeout,
+			WriteTimeout:      pipelineWebhookTimeout,
+			IdleTimeout:       pipelineWebhookIdleTimeout,
+		}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.logger.Error("Snapshot pipeline webhook failed: %v", err)
+			}
+		}()
+		go func() {
+			<-s.ctx.Done()
+			server.Close()
+		}()
+		s.logger.Info("Snapshot pipeline webhook listening at %s%s", p.config.WebhookAddr, pipelineWebhookPath)
+	}
+	return nil
+}
+
+// pipelineNetworks returns the watched networks the access policy allows
+func (s *ForwardMCPService) pipelineNetworks() []string {
+	networks := s.pipeline.config.Networks
+	if len(networks) == 0 {
+		if networkID := s.networkIDOrDefault(""); networkID != "" {
+			networks = []string{networkID}
+		}
+	}
+	var allowed []string
+	for _, networkID := range networks {
+		if s.checkNetworkAccess(networkID) == nil {
+			allowed = append(allowed, networkID)
+		}
+	}
+	return allowed
+}
+
+// pollSnapshots checks the watched networks for new snapshots until the service shuts down
+func (s *ForwardMCPService) pollSnapshots(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+			for _, networkID := range s.pipelineNetworks() {
+				s.triggerSnapshotPipeline(networkID, "", pipelineTriggerPoll)
+			}
+		}
+	}
+}
+
+// triggerSnapshotPipeline runs the playbook for a snapshot (the latest when empty) if it is
+// newer than the last one handled; failures are logged since nobody waits for the result
+func (s *ForwardMCPService) triggerSnapshotPipeline(networkID, snapshotID, trigger string) {
+	snapshot, err := s.processedSnapshot(networkID, snapshotID)
+	if err != nil {
+		s.logger.Warn("Snapshot pipeline could not read snapshot %q of network %s: %v", snapshotID, networkID, err)
+		return
+	}
+	if run, _ := s.pipeline.claim(networkID, snapshot); run {
+		s.runClaimedSnapshots(networkID, snapshot, trigger)
+	}
+}
+
+// runClaimedSnapshots runs a claimed snapshot, then each newer snapshot queued meanwhile
+func (s *ForwardMCPService) runClaimedSnapshots(networkID string, snapshot forward.Snapshot, trigger string) {
+	for ok := true; ok; snapshot, ok = s.pipeline.next(networkID) {
+		run, err := s.runSnapshotPipeline(networkID, snapshot.ID, trigger)
+		if err != nil {
+			s.logger.Error("Snapshot pipeline failed for network %s snapshot %s: %v", networkID, snapshot.ID, err)
+			continue
+		}
+		s.logger.Info("Snapshot pipeline %s for network %s snapshot %s (%s)", pipelineResult(run.Passed), networkID, snapshot.ID, trigger)
+	}
+}
+
+// processedSnapshot returns a processed snapshot of a network, the newest when snapshotID
+// is empty
+func (s *ForwardMCPService) processedSnapshot(networkID, snapshotID string) (forward.Snapshot, error) {
+	snapshots, err := s.forwardClient.GetSnapshots(networkID)
+	if err != nil {
+		return forward.Snapshot{}, err
+	}
+	processed := processedSnapshotsNewestFirst(snapshots)
+	if len(processed) == 0 {
+		return forward.Snapshot{}, fmt.Errorf("network %s has no processed snapshot", networkID)
+	}
+	if snapshotID == "" {
+		return processed[0], nil
+	}
+	index := slices.IndexFunc(processed, func(snapshot forward.Snapshot) bool { return snapshot.ID == snapshotID })
+	if index < 0 {
+		return forward.Snapshot{}, fmt.Errorf("snapshot %s is not a processed snapshot of network %s", snapshotID, networkID)
+	}
+	return processed[index], nil
+}
+
+// latestProcessedSnapshot returns the ID of a network's newest processed snapshot
+func (s *ForwardMCPService) latestProcessedSnapshot(networkID string) (string, error) {
+	snapshot, err := s.processedSnapshot(networkID, "")
+	return snapshot.ID, err
+}
+
+// handleSnapshotWebhook accepts a POST announcing a processed snapshot, as JSON with
+// networkId and optionally snapshotId, and runs the playbook in the background when the
+// snapshot is newer than the last one handled
+func (s *ForwardMCPService) handleSnapshotWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	secret := s.pipeline.config.WebhookSecret
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if secret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	var payload map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxPipelineWebhookBytes)).Decode(&payload); err != nil {
+		http.Error(w, "invalid JSON body", http.StatusBadRequest)
+		return
+	}
+	networkID := firstNonEmpty(resultValueString(payload["networkId"]), resultValueString(payload["network_id"]))
+	snapshotID := firstNonEmpty(resultValueString(payload["snapshotId"]), resultValueString(payload["snapshot_id"]))
+	if networkID == "" {
+		http.Error(w, "networkId is required", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(s.pipelineNetworks(), networkID) {
+		http.Error(w, "network is not watched by the snapshot pipeline", http.StatusNotFound)
+		return
+	}
+	snapshot, err := s.processedSnapshot(networkID, snapshotID)
+	if err != nil {
+		http.Error(w, "snapshot is not a processed snapshot of the network", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	run, accepted := s.pipeline.claim(networkID, snapshot)
+	switch {
+	case !accepted:
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"status":"skipped","reason":"the snapshot is not newer than the last one run"}`))
+	case !run:
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"queued"}`))
+	default:
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"status":"accepted"}`))
+		go s.runClaimedSnapshots(networkID, snapshot, pipelineTriggerWebhook)
+	}
+}
+
+// runSnapshotPipeline runs the playbook against a snapshot (the latest processed one when
+// empty), then stores, reports and announces the run
+func (s *ForwardMCPService) runSnapshotPipeline(networkID, snapshotID, trigger string) (*PipelineRun, error) {
+	p := s.pipeline
+	playbook := p.config.Playbook
+	if err := validatePipelinePlaybook(playbook); err != nil {
+		return nil, err
+	}
+	p.runMutex.Lock()
+	defer p.runMutex.Unlock()
+
+	snapshots, err := s.forwardClient.GetSnapshots(networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	processed := processedSnapshotsNewestFirst(snapshots)
+	if len(processed) == 0 {
+		return nil, fmt.Errorf("network %s has no processed snapshot", networkID)
+	}
+	index := 0
+	if snapshotID != "" {
+		index = slices.IndexFunc(processed, func(snapshot forward.Snapshot) bool { return snapshot.ID == snapshotID })
+		if index < 0 {
+			return nil, fmt.Errorf("snapshot %s is not a processed snapshot of network %s", snapshotID, networkID)
+		}
+	}
+	snapshotID = processed[index].ID
+
+	run := &PipelineRun{NetworkID: networkID, SnapshotID: snapshotID, Trigger: trigger, StartedAt: time.Now().UTC(), Passed: true}
+	if index+1 < len(processed) {
+		run.PreviousSnapshotID = processed[index+1].ID
+	}
+	p.markSeen(networkID, processed[index])
+
+	for _, assertion := range playbook.Assertions {
+		result := PipelineAssertionResult{Name: assertion.Name, MaxRows: assertion.MaxRows}
+		rows, err := s.pipelineQueryRows(networkID, snapshotID, assertion)
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Rows = len(rows)
+			result.Passed = len(rows) <= assertion.MaxRows
+			if !result.Passed {
+				result.Sample = rows[:min(len(rows), pipelineSampleRows)]
+			}
+		}
+		run.Passed = run.Passed && result.Passed
+		run.Assertions = append(run.Assertions, result)
+	}
+
+	if strings.TrimSpace(playbook.KeyPaths) != "" {
+		run.Flows, _ = parseCriticalFlows(playbook.KeyPaths)
+		if err := s.verifyCriticalFlows(networkID, snapshotID, run.Flows); err != nil {
+			run.FlowsError = err.Error()
+			run.Passed = false
+		}
+		for _, flow := range run.Flows {
+			run.Passed = run.Passed && flow.Passed
+		}
+	}
+
+	for _, query := range playbook.KeyQueries {
+		run.Diffs = append(run.Diffs, s.pipelineQueryDiff(run, query))
+	}
+	run.FinishedAt = time.Now().UTC()
+
+	s.storePipelineRun(run)
+	if p.config.ReportFormat != "" {
+		run.Report = strings.TrimSpace(s.saveReport(pipelineRunReport(run), fmt.Sprintf("pipeline_%s_%s", networkID, snapshotID), p.config.ReportFormat))
+	}
+	if p.config.NotifyURL != "" {
+		if err := p.notify(run); err != nil {
+			s.logger.Warn("Snapshot pipeline notification failed: %v", err)
+			run.NotifyError = err.Error()
+		}
+	}
+	p.addRun(run)
+	return run, nil
+}
+
+// pipelineQueryDiff stores a key query's rows for the new snapshot and diffs them with the
+// previous snapshot's
+func (s *ForwardMCPService) pipelineQueryDiff(run *PipelineRun, query config.PipelineQuery) PipelineQueryDiff {
+	result := PipelineQueryDiff{Name: query.Name}
+	rows, err := s.pipelineQueryRows(run.NetworkID, run.SnapshotID, query)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Rows = len(rows)
+	if s.memorySystem != nil {
+		entityID, err := s.memorySystem.StoreNQEResultWithChunking(firstNonEmpty(query.QueryID, query.Name), run.NetworkID, run.SnapshotID, &forward.NQERunResult{Items: rows}, s.chunkTargetBytes())
+		if err != nil {
+			s.logger.Warn("Failed to store key query %s: %v", query.Name, err)
+		}
+		result.EntityID = entityID
+	}
+	if run.PreviousSnapshotID == "" {
+		return result
+	}
+	previous, err := s.pipelineQueryRows(run.NetworkID, run.PreviousSnapshotID, query)
+	if err != nil {
+		result.Error = fmt.Sprintf("previous snapshot: %v", err)
+		return result
+	}
+	diff := diffResultRows(previous, rows, query.KeyColumns)
+	result.Added, result.Removed, result.Changed = len(diff.Added), len(diff.Removed), len(diff.Changed)
+	return result
+}
+
+// pipelineQueryRows fetches all rows of a playbook query
+func (s *ForwardMCPService) pipelineQueryRows(networkID, snapshotID string, query config.PipelineQuery) ([]map[string]interface{}, error) {
+	if query.QueryID != "" {
+		return s.fetchAllNQEItems(networkID, snapshotID, query.QueryID, nil)
+	}
+	return s.fetchAllNQEQueryItems(networkID, snapshotID, query.Query)
+}
+
+// storePipelineRun keeps a run in the memory system, replacing an earlier run of the same
+// snapshot
+func (s *ForwardMCPService) storePipelineRun(run *PipelineRun) {
+	if s.memorySystem == nil {
+		return
+	}
+	name := fmt.Sprintf("pipeline_run:%s:%s", run.NetworkID, run.SnapshotID)
+	entity, err := s.memorySystem.ReplaceEntity(name, pipelineRunEntityType, map[string]interface{}{
+		"network_id":           run.NetworkID,
+		"snapshot_id":          run.SnapshotID,
+		"previous_snapshot_id": run.PreviousSnapshotID,
+		"trigger":              run.Trigger,
+		"passed":               run.Passed,
+		"finished_at":          run.FinishedAt.Format(time.RFC3339),
+		"run":                  MarshalCompactJSONString(run),
+	})
+	if err != nil {
+		s.logger.Warn("Failed to store pipeline run %s: %v", name, err)
+		return
+	}
+	run.EntityID = entity.ID
+}
+
+// notify POSTs a run as JSON to the configured URL
+func (p *SnapshotPipeline) notify(run *PipelineRun) error {
+	body, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	response, err := p.client.Post(p.config.NotifyURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("%s returned %s", p.config.NotifyURL, response.Status)
+	}
+	return nil
+}
+
+// processedSnapshotsNewestFirst drops draft and unprocessed snapshots and sorts the rest
+// newest first
+func processedSnapshotsNewestFirst(snapshots []forward.Snapshot) []forward.Snapshot {
+	var processed []forward.Snapshot
+	for _, snapshot := range snapshots {
+		if !snapshot.IsDraft && (snapshot.State == "" || strings.EqualFold(snapshot.State, "PROCESSED")) {
+			processed = append(processed, snapshot)
+		}
+	}
+	sort.SliceStable(processed, func(i, j int) bool { return processed[i].CreationDateMillis > processed[j].CreationDateMillis })
+	return processed
+}
+
+func pipelineResult(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
+
+// runSnapshotPipelineTool runs the playbook on demand against a snapshot
+func (s *ForwardMCPService) runSnapshotPipelineTool(args RunSnapshotPipelineArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("run_snapshot_pipeline", args, nil)
+
+	if s.pipeline == nil {
+		return nil, fmt.Errorf("no snapshot pipeline playbook is configured (set forward.snapshotPipeline.playbook in the config file)")
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	run, err := s.runSnapshotPipeline(networkID, s.getSnapshotID(args.SnapshotID), pipelineTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(args.Format) == "json" {
+		output, _ := json.MarshalIndent(run, "", "  ")
+		return mcp.NewToolResponse(mcp.NewTextContent(string(output))), nil
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(s.formatPipelineRun(run))), nil
+}
+
+// getPipelineRuns lists the recent runs of the snapshot pipeline
+func (s *ForwardMCPService) getPipelineRuns(args GetPipelineRunsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_pipeline_runs", args, nil)
+
+	if s.pipeline == nil {
+		return mcp.NewToolResponse(mcp.NewTextContent("The snapshot pipeline is not configured: add a playbook under forward.snapshotPipeline in the config file.")), nil
+	}
+	networkID := ""
+	if args.NetworkID != "" {
+		if networkID = s.getNetworkID(args.NetworkID); networkID == "" {
+			return nil, newCodedError(CodeNetworkIDRequired)
+		}
+	}
+	var runs []*PipelineRun
+	for _, run := range s.pipeline.Runs(networkID) {
+		if s.networkPolicy.Permits(run.NetworkID) {
+			runs = append(runs, run)
+		}
+	}
+	if args.Limit > 0 && len(runs) > args.Limit {
+		runs = runs[:args.Limit]
+	}
+	if strings.ToLower(args.Format) == "json" {
+		output, _ := json.MarshalIndent(map[string]interface{}{"runs": runs}, "", "  ")
+		return mcp.NewToolResponse(mcp.NewTextContent(string(output))), nil
+	}
+	if len(runs) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No snapshot pipeline runs yet. Runs start when a new snapshot is processed, or on demand with run_snapshot_pipeline.")), nil
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Snapshot Pipeline Runs (%d)\n\n", len(runs)))
+	text.WriteString("| Finished | Network | Snapshot | Trigger | Result | Failed Checks | Changed Rows | Entity |\n|---|---|---|---|---|---|---|---|\n")
+	for _, run := range runs {
+		failed, changed := pipelineRunCounts(run)
+		text.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %d | %d | %s |\n", s.timeFormatter.Format(run.FinishedAt), run.NetworkID, run.SnapshotID,
+			run.Trigger, pipelineResultIcon(run.Passed), failed, changed, firstNonEmpty(run.EntityID, "-")))
+	}
+	text.WriteString("\nUse get_entity with an entity ID for the full run.\n")
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// pipelineRunCounts returns the failed assertions and key paths of a run and its changed
+// key query rows
+func pipelineRunCounts(run *PipelineRun) (int, int) {
+	failed, changed := 0, 0
+	for _, assertion := range run.Assertions {
+		if !assertion.Passed {
+			failed++
+		}
+	}
+	for _, flow := range run.Flows {
+		if !flow.Passed {
+			failed++
+		}
+	}
+	for _, diff := range run.Diffs {
+		changed += diff.Added + diff.Removed + diff.Changed
+	}
+	return failed, changed
+}
+
+func pipelineResultIcon(passed bool) string {
+	if passed {
+		return "✅ passed"
+	}
+	return "❌ failed"
+}
+
+// formatPipelineRun renders a run as markdown
+func (s *ForwardMCPService) formatPipelineRun(run *PipelineRun) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Snapshot Pipeline: network %s snapshot %s\n\n", run.NetworkID, run.SnapshotID))
+	text.WriteString(fmt.Sprintf("%s (%s trigger, %s)", pipelineResultIcon(run.Passed), run.Trigger, run.FinishedAt.Sub(run.StartedAt).Round(time.Millisecond)))
+	if run.PreviousSnapshotID != "" {
+		text.WriteString(fmt.Sprintf(", compared with snapshot %s", run.PreviousSnapshotID))
+	}
+	text.WriteString("\n")
+
+	if len(run.Assertions) > 0 {
+		text.WriteString(fmt.Sprintf("\n## Assertions (%d)\n\n| Assertion | Rows | Allowed | Result |\n|---|---|---|---|\n", len(run.Assertions)))
+		for _, assertion := range run.Assertions {
+			result := pipelineResultIcon(assertion.Passed)
+			if assertion.Error != "" {
+				result = "⚠️ " + assertion.Error
+			}
+			text.WriteString(fmt.Sprintf("| %s | %d | %d | %s |\n", assertion.Name, assertion.Rows, assertion.MaxRows, result))
+		}
+	}
+	if run.FlowsError != "" {
+		text.WriteString(fmt.Sprintf("\n## Key Paths\n\n⚠️ Not verified: %s\n", run.FlowsError))
+	} else if len(run.Flows) > 0 {
+		text.WriteString("\n## Key Paths\n\n| Flow | Expected | Result | Finding |\n|---|---|---|---|\n")
+		for _, flow := range run.Flows {
+			text.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", flow.Flow, flow.Expect, pipelineResultIcon(flow.Passed), flow.Finding))
+		}
+	}
+	if len(run.Diffs) > 0 {
+		text.WriteString("\n## Changes Since the Previous Snapshot\n\n")
+		if run.PreviousSnapshotID == "" {
+			text.WriteString("No earlier processed snapshot to compare with; the results are stored as the first reference.\n\n")
+		}
+		text.WriteString("| Query | Rows | Added | Removed | Changed | Stored Result |\n|---|---|---|---|---|---|\n")
+		for _, diff := range run.Diffs {
+			if diff.Error != "" {
+				text.WriteString(fmt.Sprintf("| %s | ⚠️ %s | | | | |\n", diff.Name, diff.Error))
+				continue
+			}
+			text.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %s |\n", diff.Name, diff.Rows, diff.Added, diff.Removed, diff.Changed, firstNonEmpty(diff.EntityID, "-")))
+		}
+		text.WriteString("\nUse diff_stored_results on the stored results of two runs for the changed rows.\n")
+	}
+	if run.Report != "" {
+		text.WriteString("\n" + run.Report + "\n")
+	}
+	if run.NotifyError != "" {
+		text.WriteString(fmt.Sprintf("\n⚠️ Notification failed: %s\n", run.NotifyError))
+	}
+	return text.String()
+}
+
+// pipelineRunReport documents a run for the report renderer
+func pipelineRunReport(run *PipelineRun) *report.Report {
+	failed, changed := pipelineRunCounts(run)
+	doc := &report.Report{
+		Title:       "Snapshot Validation Report",
+		Subtitle:    fmt.Sprintf("Network %s, snapshot %s", run.NetworkID, run.SnapshotID),
+		GeneratedAt: run.FinishedAt,
+		Facts: []report.Fact{
+			{Label: "Result", Value: pipelineResult(run.Passed)},
+			{Label: "Failed checks", Value: fmt.Sprint(failed)},
+			{Label: "Changed rows", Value: fmt.Sprint(changed)},
+			{Label: "Previous snapshot", Value: firstNonEmpty(run.PreviousSnapshotID, "-")},
+		},
+	}
+	if len(run.Assertions) > 0 {
+		section := report.Section{Title: "Assertions", Status: "ok"}
+		rows := make([][]string, 0, len(run.Assertions))
+		for _, assertion := range run.Assertions {
+			if !assertion.Passed {
+				section.Status = "fail"
+			}
+			rows = append(rows, []string{assertion.Name, fmt.Sprint(assertion.Rows), fmt.Sprint(assertion.MaxRows), firstNonEmpty(assertion.Error, pipelineResult(assertion.Passed))})
+		}
+		section.Table = reportTable([]string{"Assertion", "Rows", "Allowed", "Result"}, rows)
+		doc.Sections = append(doc.Sections, section)
+	}
+	if run.FlowsError != "" {
+		doc.Sections = append(doc.Sections, report.Section{Title: "Key Paths", Status: "fail", Text: "Not verified: " + run.FlowsError})
+	} else if len(run.Flows) > 0 {
+		section := report.Section{Title: "Key Paths", Status: "ok"}
+		rows := make([][]string, 0, len(run.Flows))
+		for _, flow := range run.Flows {
+			if !flow.Passed {
+				section.Status = "fail"
+			}
+			rows = append(rows, []string{flow.Flow, flow.Expect, pipelineResult(flow.Passed), flow.Finding})
+		}
+		section.Table = reportTable([]string{"Flow", "Expected", "Result", "Finding"}, rows)
+		doc.Sections = append(doc.Sections, section)
+	}
+	if len(run.Diffs) > 0 {
+		rows := make([][]string, 0, len(run.Diffs))
+		for _, diff := range run.Diffs {
+			rows = append(rows, []string{diff.Name, fmt.Sprint(diff.Rows), fmt.Sprint(diff.Added), fmt.Sprint(diff.Removed), fmt.Sprint(diff.Changed), diff.Error})
+		}
+		doc.Sections = append(doc.Sections, report.Section{Title: "Changes Since the Previous Snapshot", Table: reportTable([]string{"Query", "Rows", "Added", "Removed", "Changed", "Error"}, rows)})
+	}
+	return doc
+}
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+)
+
+func pipelineTestService(t *testing.T, cfg config.SnapshotPipelineConfig) *ForwardMCPService {
+	service := createTestService()
+	service.reports = newTestReportStore(t)
+	client := service.forwardClient.(*MockForwardClient)
+	client.snapshots = []forward.Snapshot{
+		{ID: "snap-draft", IsDraft: true, CreationDateMillis: 4000},
+		{ID: "snap-3", State: "PROCESSED", CreationDateMillis: 3000},
+		{ID: "snap-1", State: "PROCESSED", CreationDateMillis: 1000},
+		{ID: "snap-2", State: "PROCESSING", CreationDateMillis: 2000},
+	}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		{"device": "core1", "status": "down"},
+		{"device": "core2", "status": "down"},
+		{"device": "core3", "status": "down"},
+	}}
+	if cfg.Playbook.Assertions == nil && cfg.Playbook.KeyQueries == nil && cfg.Playbook.KeyPaths == "" {
+		cfg.Playbook = config.PipelinePlaybook{
+			Assertions: []config.PipelineQuery{
+				{Name: "no down links", Query: "foreach d in network.devices select {device: d.name}"},
+				{Name: "few down links", QueryID: "FQ_down", MaxRows: 5},
+			},
+			KeyQueries: []config.PipelineQuery{{Name: "links", QueryID: "FQ_links", KeyColumns: []string{"device"}}},
+			KeyPaths:   "10.0.0.1>10.0.1.1:443",
+		}
+	}
+	service.pipeline = NewSnapshotPipeline(cfg)
+	return service
+}
+
+func TestValidatePipelinePlaybook(t *testing.T) {
+	if NewSnapshotPipeline(config.SnapshotPipelineConfig{PollIntervalMinutes: 5}) != nil {
+		t.Error("Expected no pipeline without a playbook")
+	}
+	for _, playbook := range []config.PipelinePlaybook{
+		{Assertions: []config.PipelineQuery{{QueryID: "FQ_a"}}},
+		{Assertions: []config.PipelineQuery{{Name: "both", QueryID: "FQ_a", Query: "foreach d in network.devices select d"}}},
+		{KeyQueries: []config.PipelineQuery{{Name: "unkeyed", QueryID: "FQ_a"}}},
+		{KeyPaths: "no-arrow"},
+	} {
+		if err := validatePipelinePlaybook(playbook); err == nil {
+			t.Errorf("Expected %+v to be rejected", playbook)
+		}
+	}
+	if err := validatePipelinePlaybook(config.PipelinePlaybook{KeyQueries: []config.PipelineQuery{{Name: "ok", QueryID: "FQ_a", KeyColumns: []string{"device"}}}}); err != nil {
+		t.Errorf("Expected a valid playbook, got %v", err)
+	}
+}
+
+func TestRunSnapshotPipeline(t *testing.T) {
+	var notified PipelineRun
+	notifier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &notified)
+	}))
+	defer notifier.Close()
+	service := pipelineTestService(t, config.SnapshotPipelineConfig{NotifyURL: notifier.URL, ReportFormat: "markdown"})
+
+	run, err := service.runSnapshotPipeline("162112", "", pipelineTriggerManual)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if run.SnapshotID != "snap-3" || run.PreviousSnapshotID != "snap-1" {
+		t.Errorf("Expected snap-3 compared with snap-1, got %s and %s", run.SnapshotID, run.PreviousSnapshotID)
+	}
+	if run.Passed || len(run.Assertions) != 2 || run.Assertions[0].Passed || len(run.Assertions[0].Sample) != 3 || !run.Assertions[1].Passed {
+		t.Errorf("Expected only the strict assertion to fail, got %+v", run.Assertions)
+	}
+	if len(run.Flows) != 1 || run.FlowsError != "" {
+		t.Errorf("Expected the key path to be verified, got %+v (%s)", run.Flows, run.FlowsError)
+	}
+	if len(run.Diffs) != 1 || run.Diffs[0].Rows != 3 || run.Diffs[0].Added+run.Diffs[0].Removed+run.Diffs[0].Changed != 0 || run.Diffs[0].EntityID == "" {
+		t.Errorf("Expected an unchanged stored key query, got %+v", run.Diffs)
+	}
+	if run.EntityID == "" || !strings.Contains(run.Report, "markdown report saved to") {
+		t.Errorf("Expected the run stored and reported, got entity %q report %q", run.EntityID, run.Report)
+	}
+	if notified.SnapshotID != "snap-3" || notified.Passed {
+		t.Errorf("Expected the failed run to be posted, got %+v", notified)
+	}
+	entity, err := service.memorySystem.GetEntity(run.EntityID)
+	if err != nil || entity.Type != pipelineRunEntityType || entity.Metadata["passed"] != false {
+		t.Errorf("Expected a stored pipeline_run entity, got %+v (%v)", entity, err)
+	}
+
+	// The oldest snapshot has nothing to compare with
+	run, err = service.runSnapshotPipeline("162112", "snap-1", pipelineTriggerManual)
+	if err != nil || run.PreviousSnapshotID != "" || run.Diffs[0].Rows != 3 {
+		t.Errorf("Expected a first reference run, got %+v (%v)", run, err)
+	}
+
+	if _, err := service.runSnapshotPipeline("162112", "snap-missing", pipelineTriggerManual); err == nil || !strings.Contains(err.Error(), "not a processed snapshot") {
+		t.Errorf("Expected an unknown snapshot to be rejected, got: %v", err)
+	}
+}
+
+func TestSnapshotPipelineTools(t *testing.T) {
+	service := createTestService()
+	response, err := service.getPipelineRuns(GetPipelineRunsArgs{})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "not configured") {
+		t.Errorf("Expected a not configured note, got %v", err)
+	}
+	if _, err := service.runSnapshotPipelineTool(RunSnapshotPipelineArgs{}); err == nil {
+		t.Error("Expected an error without a playbook")
+	}
+
+	service = pipelineTestService(t, config.SnapshotPipelineConfig{})
+	response, err = service.runSnapshotPipelineTool(RunSnapshotPipelineArgs{SnapshotID: "snap-3"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"# Snapshot Pipeline: network 162112 snapshot snap-3", "❌ failed (manual trigger", "compared with snapshot snap-1",
+		"| no down links | 3 | 0 | ❌ failed |", "| few down links | 3 | 5 | ✅ passed |", "| 10.0.0.1>10.0.1.1:443 | allow |", "| links | 3 | 0 | 0 | 0 |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	service.triggerSnapshotPipeline("162112", "", pipelineTriggerPoll)
+	service.triggerSnapshotPipeline("162112", "", pipelineTriggerPoll)
+	response, _ = service.getPipelineRuns(GetPipelineRunsArgs{NetworkID: "162112"})
+	text = response.Content[0].TextContent.Text
+	if !strings.Contains(text, "# Snapshot Pipeline Runs (1)") || !strings.Contains(text, "| 162112 | snap-3 | manual | ❌ failed | 1 | 0 |") {
+		t.Errorf("Expected the polled snapshot to be skipped after the manual run, got: %s", text)
+	}
+}
+
+func TestSnapshotWebhook(t *testing.T) {
+	service := pipelineTestService(t, config.SnapshotPipelineConfig{WebhookSecret: "s3cret"})
+	post := func(body, token string) int {
+		request := httptest.NewRequest(http.MethodPost, pipelineWebhookPath, strings.NewReader(body))
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		service.handleSnapshotWebhook(recorder, request)
+		return recorder.Code
+	}
+	if code := post(`{"networkId": "162112"}`, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong secret, got %d", code)
+	}
+	if code := post(`{"snapshotId": "snap-3"}`, "s3cret"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 without a network, got %d", code)
+	}
+	if code := post(`{"networkId": "999"}`, "s3cret"); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for an unwatched network, got %d", code)
+	}
+	if code := post(`{"networkId": "162112", "snapshotId": "snap-2"}`, "s3cret"); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a snapshot still processing, got %d", code)
+	}
+	if code := post(`{"networkId": "162112", "snapshotId": "snap-3"}`, "s3cret"); code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d", code)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(service.pipeline.Runs("162112")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if runs := service.pipeline.Runs(""); len(runs) != 1 || runs[0].Trigger != pipelineTriggerWebhook || runs[0].SnapshotID != "snap-3" {
+		t.Errorf("Expected one webhook run, got %+v", runs)
+	}
+
+	// Repeated and older snapshots are not run again
+	if code := post(`{"networkId": "162112", "snapshotId": "snap-3"}`, "s3cret"); code != http.StatusOK {
+		t.Errorf("Expected 200 for a snapshot already run, got %d", code)
+	}
+	if code := post(`{"networkId": "162112", "snapshotId": "snap-1"}`, "s3cret"); code != http.StatusOK {
+		t.Errorf("Expected 200 for an older snapshot, got %d", code)
+	}
+	if runs := service.pipeline.Runs(""); len(runs) != 1 {
+		t.Errorf("Expected still one run, got %d", len(runs))
+	}
+
+	// Without a secret the webhook refuses to start and rejects every call
+	service = pipelineTestService(t, config.SnapshotPipelineConfig{WebhookAddr: "127.0.0.1:0"})
+	if err := service.StartSnapshotPipeline(); err == nil || !strings.Contains(err.Error(), "needs a secret") {
+		t.Errorf("Expected the webhook to need a secret, got %v", err)
+	}
+	if code := post(`{"networkId": "162112"}`, ""); code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a configured secret, got %d", code)
+	}
+}
+
+func TestSnapshotPipelineClaims(t *testing.T) {
+	pipeline := NewSnapshotPipeline(config.SnapshotPipelineConfig{Playbook: config.PipelinePlaybook{KeyPaths: "10.0.0.1>10.0.1.1"}})
+	a := forward.Snapshot{ID: "a", CreationDateMillis: 1000}
+	b := forward.Snapshot{ID: "b", CreationDateMillis: 2000}
+	c := forward.Snapshot{ID: "c", CreationDateMillis: 3000}
+
+	if run, accepted := pipeline.claim("n1", a); !run || !accepted {
+		t.Fatal("Expected the first snapshot to run")
+	}
+	// During the run newer snapshots queue, the newest winning; alternating IDs are not rerun
+	if run, accepted := pipeline.claim("n1", c); run || !accepted {
+		t.Error("Expected a newer snapshot queued during the run")
+	}
+	if run, accepted := pipeline.claim("n1", b); run || !accepted {
+		t.Error("Expected an older queued snapshot to be accepted but not replace c")
+	}
+	if run, accepted := pipeline.claim("n1", a); run || accepted {
+		t.Error("Expected the running snapshot to be refused")
+	}
+	if next, ok := pipeline.next("n1"); !ok || next.ID != "c" {
+		t.Fatalf("Expected c to run next, got %+v", next)
+	}
+	if _, ok := pipeline.next("n1"); ok {
+		t.Error("Expected nothing left to run")
+	}
+	for _, snapshot := range []forward.Snapshot{a, b, c} {
+		if run, accepted := pipeline.claim("n1", snapshot); run || accepted {
+			t.Errorf("Expected %s refused after c ran", snapshot.ID)
+		}
+	}
+	if run, _ := pipeline.claim("n2", a); !run {
+		t.Error("Expected other networks to run independently")
+	}
+}
@@ -0,0 +1,519 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQL sandbox error codes reported by analyze_nqe_result_sql and query_catalog_sql
+const (
+	sqlErrEmptyStatement      = "empty_statement"
+	sqlErrStatementTooLong    = "statement_too_long"
+	sqlErrMultipleStatements  = "multiple_statements"
+	sqlErrStatementNotAllowed = "statement_not_allowed"
+	sqlErrPragmaBlocked       = "pragma_blocked"
+	sqlErrAttachBlocked       = "attach_blocked"
+	sqlErrFunctionBlocked     = "function_blocked"
+	sqlErrNotAuthorized       = "not_authorized"
+	sqlErrTimeout             = "timeout"
+	sqlErrMemoryLimit         = "memory_limit"
+)
+
+// sqlSandboxErrorCatalog explains each blocked construct and how to work within the sandbox
+var sqlSandboxErrorCatalog = map[string]string{
+	sqlErrEmptyStatement:      "Provide a SELECT query against the nqe_result table (queries, metadata and instances for query_catalog_sql)",
+	sqlErrStatementTooLong:    "Queries are limited to 10000 characters; simplify the query",
+	sqlErrMultipleStatements:  "Only one statement may be run per call; split the work into separate calls",
+	sqlErrStatementNotAllowed: "Only SELECT, WITH and VALUES queries are allowed; the tables are read-only",
+	sqlErrPragmaBlocked:       "PRAGMA statements cannot change or inspect the sandbox",
+	sqlErrAttachBlocked:       "ATTACH/DETACH are blocked; only the loaded tables are available",
+	sqlErrFunctionBlocked:     "Extension loading and file access functions are disabled",
+	sqlErrNotAuthorized:       "The query touched a construct the read-only sandbox does not permit (writes, schema changes or blocked functions)",
+	sqlErrTimeout:             "The query exceeded the execution timeout; add filters or a LIMIT",
+	sqlErrMemoryLimit:         "The result or query exceeded the sandbox memory limit; filter the result before analyzing it",
+}
+
+// SQLSandboxError reports a query rejected or stopped by the SQL sandbox
+type SQLSandboxError struct {
+	Code   string
+	Detail string
+}
+
+func (e *SQLSandboxError) Error() string {
+	return fmt.Sprintf("SQL blocked [%s]: %s. %s", e.Code, e.Detail, sqlSandboxErrorCatalog[e.Code])
+}
+
+const (
+	maxSandboxSQLLength = 10000
+	sqliteRecursive     = 33 // SQLITE_RECURSIVE, not exported by the driver
+)
+
+// sqlBlockedFunctions can reach outside the sandbox
+var sqlBlockedFunctions = map[string]bool{
+	"load_extension": true,
+	"readfile":       true,
+	"writefile":      true,
+	"edit":           true,
+	"fts3_tokenizer": true,
+}
+
+// sqlSandboxCounter gives every sandbox its own shared in-memory database
+var sqlSandboxCounter uint64
+
+// sqlSandbox runs a single read-only query against a stored result loaded into an
+// in-memory or cached on-disk SQLite database
+type sqlSandbox struct {
+	timeout        time.Duration
+	maxMemoryBytes int64
+}
+
+// sqlSandbox returns the sandbox configured for this service
+func (s *ForwardMCPService) sqlSandbox() sqlSandbox {
+	sandbox := sqlSandbox{timeout: 5 * time.Second, maxMemoryBytes: 64 << 20}
+	if s.config != nil {
+		if s.config.Forward.SQLTimeoutSeconds > 0 {
+			sandbox.timeout = time.Duration(s.config.Forward.SQLTimeoutSeconds) * time.Second
+		}
+		if s.config.Forward.SQLMaxMemoryMB > 0 {
+			sandbox.maxMemoryBytes = int64(s.config.Forward.SQLMaxMemoryMB) << 20
+		}
+	}
+	return sandbox
+}
+
+// validateSandboxSQL enforces a single SELECT-style statement free of blocked constructs
+// and returns it without its trailing semicolon
+func validateSandboxSQL(query string) (string, error) {
+	if strings.TrimSpace(query) == "" {
+		return "", &SQLSandboxError{Code: sqlErrEmptyStatement, Detail: "the query is empty"}
+	}
+	if len(query) > maxSandboxSQLLength {
+		return "", &SQLSandboxError{Code: sqlErrStatementTooLong, Detail: fmt.Sprintf("the query is %d characters", len(query))}
+	}
+
+	words, end, multiple := scanSQLWords(query)
+	if multiple {
+		return "", &SQLSandboxError{Code: sqlErrMultipleStatements, Detail: "found more than one statement"}
+	}
+	for _, word := range words {
+		switch {
+		case word == "PRAGMA":
+			return "", &SQLSandboxError{Code: sqlErrPragmaBlocked, Detail: "PRAGMA is not allowed"}
+		case word == "ATTACH" || word == "DETACH":
+			return "", &SQLSandboxError{Code: sqlErrAttachBlocked, Detail: word + " is not allowed"}
+		case sqlBlockedFunctions[strings.ToLower(word)]:
+			return "", &SQLSandboxError{Code: sqlErrFunctionBlocked, Detail: strings.ToLower(word) + "() is not allowed"}
+		}
+	}
+	if len(words) == 0 {
+		return "", &SQLSandboxError{Code: sqlErrEmptyStatement, Detail: "the query has no statement"}
+	}
+	if first := words[0]; first != "SELECT" && first != "WITH" && first != "VALUES" {
+		return "", &SQLSandboxError{Code: sqlErrStatementNotAllowed, Detail: first + " statements are not allowed"}
+	}
+	return strings.TrimSpace(query[:end]), nil
+}
+
+// scanSQLWords returns the upper-cased keywords and identifiers outside string literals,
+// quoted identifiers and comments, the offset where the first statement ends, and whether
+// anything but whitespace or comments follows that statement.
+func scanSQLWords(query string) ([]string, int, bool) {
+	var words []string
+	end := len(query)
+	terminated := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closer := c
+			if c == '[' {
+				closer = ']'
+			}
+			j := i + 1
+			for j < len(query) {
+				if query[j] == closer {
+					// Doubled quotes escape themselves
+					if closer != ']' && j+1 < len(query) && query[j+1] == closer {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if terminated {
+				return words, end, true
+			}
+			i = j + 1
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			if j := strings.Index(query[i+2:], "*/"); j >= 0 {
+				i += j + 4
+			} else {
+				i = len(query)
+			}
+		case c == ';':
+			if !terminated {
+				terminated = true
+				end = i
+			}
+			i++
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		default:
+			if terminated {
+				return words, end, true
+			}
+			j := i
+			for j < len(query) && isSQLWordByte(query[j]) {
+				j++
+			}
+			if j == i {
+				i++
+				continue
+			}
+			words = append(words, strings.ToUpper(query[i:j]))
+			i = j
+		}
+	}
+	return words, end, false
+}
+
+func isSQLWordByte(c byte) bool {
+	return c == '_' || c == '$' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// sqlSandboxAuthorizer allows reads and ordinary functions only
+func sqlSandboxAuthorizer(op int, arg1, arg2, arg3 string) int {
+	switch op {
+	case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqliteRecursive:
+		return sqlite3.SQLITE_OK
+	case sqlite3.SQLITE_FUNCTION:
+		if sqlBlockedFunctions[strings.ToLower(arg2)] {
+			return sqlite3.SQLITE_DENY
+		}
+		return sqlite3.SQLITE_OK
+	}
+	return sqlite3.SQLITE_DENY
+}
+
+// Query loads the chunked result into a table named nqe_result in a private in-memory
+// database and runs query on a read-only connection, returning at most maxRows rows and
+// whether more were available
+func (sb sqlSandbox) Query(chunks []string, query string, maxRows int) ([]map[string]interface{}, bool, error) {
+	statement, err := validateSandboxSQL(query)
+	if err != nil {
+		return nil, false, err
+	}
+	allRows, err := sb.decodeChunks(chunks)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sb.timeout)
+	defer cancel()
+
+	// Both connections share one private in-memory database; the writer keeps it alive
+	dsn := fmt.Sprintf("file:nqe_sql_%d?mode=memory&cache=shared", atomic.AddUint64(&sqlSandboxCounter, 1))
+	writer, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create in-memory sqlite db: %w", err)
+	}
+	defer writer.Close()
+	writeConn, err := writer.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open in-memory sqlite db: %w", err)
+	}
+	defer writeConn.Close()
+	if err := sb.loadResultTable(ctx, writeConn, allRows); err != nil {
+		return nil, false, sb.classifyError(ctx, err)
+	}
+
+	return sb.queryReadOnly(ctx, dsn+"&_query_only=true", statement, maxRows)
+}
+
+// BuildFile materializes the chunked result as the nqe_result table of a new on-disk
+// database at path
+func (sb sqlSandbox) BuildFile(path string, chunks []string) error {
+	allRows, err := sb.decodeChunks(chunks)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sb.timeout)
+	defer cancel()
+
+	db, err := sql.Open("sqlite3", sqliteFileURI(path)+"?mode=rwc")
+	if err != nil {
+		return fmt.Errorf("failed to create analysis database: %w", err)
+	}
+	defer db.Close()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open analysis database: %w", err)
+	}
+	defer conn.Close()
+	if err := sb.loadResultTable(ctx, conn, allRows); err != nil {
+		return sb.classifyError(ctx, err)
+	}
+	return nil
+}
+
+// QueryFile runs query on a read-only connection to a database built by BuildFile
+func (sb sqlSandbox) QueryFile(path, query string, maxRows int) ([]map[string]interface{}, bool, error) {
+	statement, err := validateSandboxSQL(query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sb.timeout)
+	defer cancel()
+	return sb.queryReadOnly(ctx, sqliteFileURI(path)+"?mode=ro&_query_only=true", statement, maxRows)
+}
+
+// sqliteFileURI returns a SQLite URI filename for path
+func sqliteFileURI(path string) string {
+	return "file:" + (&url.URL{Path: filepath.ToSlash(path)}).EscapedPath()
+}
+
+// decodeChunks parses chunk JSON into rows, enforcing the memory limit on the raw result
+func (sb sqlSandbox) decodeChunks(chunks []string) ([]map[string]interface{}, error) {
+	var datasetBytes int64
+	var allRows []map[string]interface{}
+	for _, chunk := range chunks {
+		datasetBytes += int64(len(chunk))
+		if datasetBytes > sb.maxMemoryBytes {
+			return nil, &SQLSandboxError{Code: sqlErrMemoryLimit, Detail: fmt.Sprintf("the stored result exceeds %d MB", sb.maxMemoryBytes>>20)}
+		}
+		var rows []map[string]interface{}
+		if err := json.Unmarshal([]byte(chunk), &rows); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chunk: %w", err)
+		}
+		allRows = append(allRows, rows...)
+	}
+	if len(allRows) == 0 {
+		return nil, fmt.Errorf("no rows found in the stored result")
+	}
+	return allRows, nil
+}
+
+// queryReadOnly opens a query-only connection to dsn, restricts it with SQLite limits and
+// the sandbox authorizer, and runs statement
+func (sb sqlSandbox) queryReadOnly(ctx context.Context, dsn, statement string, maxRows int) ([]map[string]interface{}, bool, error) {
+	reader, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open read-only connection: %w", err)
+	}
+	defer reader.Close()
+	readConn, err := reader.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to open read-only connection: %w", err)
+	}
+	defer readConn.Close()
+	if _, err := readConn.ExecContext(ctx, fmt.Sprintf("PRAGMA cache_size = -%d", sb.maxMemoryBytes>>10)); err != nil {
+		return nil, false, fmt.Errorf("failed to configure read-only connection: %w", err)
+	}
+	err = readConn.Raw(func(driverConn interface{}) error {
+		conn, ok := driverConn.(*sqlite3.SQLiteConn)
+		if !ok {
+			return fmt.Errorf("unexpected sqlite driver connection %T", driverConn)
+		}
+		conn.SetLimit(sqlite3.SQLITE_LIMIT_LENGTH, int(sb.maxMemoryBytes))
+		conn.SetLimit(sqlite3.SQLITE_LIMIT_SQL_LENGTH, maxSandboxSQLLength)
+		conn.SetLimit(sqlite3.SQLITE_LIMIT_ATTACHED, 0)
+		conn.RegisterAuthorizer(sqlSandboxAuthorizer)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	rows, err := readConn.QueryContext(ctx, statement)
+	if err != nil {
+		return nil, false, sb.classifyError(ctx, err)
+	}
+	defer rows.Close()
+
+	resultRows := []map[string]interface{}{}
+	truncated := false
+	cols, _ := rows.Columns()
+	for rows.Next() {
+		if len(resultRows) >= maxRows {
+			truncated = true
+			break
+		}
+		vals := make([]interface{}, len(cols))
+		valPtrs := make([]interface{}, len(cols))
+		for i := range vals {
+			valPtrs[i] = &vals[i]
+		}
+		if err := rows.Scan(valPtrs...); err != nil {
+			return nil, false, fmt.Errorf("failed to scan row: %w", err)
+		}
+		rowMap := map[string]interface{}{}
+		for i, col := range cols {
+			if b, ok := vals[i].([]byte); ok {
+				vals[i] = string(b)
+			}
+			rowMap[col] = vals[i]
+		}
+		resultRows = append(resultRows, rowMap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, sb.classifyError(ctx, err)
+	}
+	return resultRows, truncated, nil
+}
+
+// loadResultTable creates nqe_result with a TEXT column per result column and inserts the
+// rows. Column names come from result data, so they are always quoted.
+func (sb sqlSandbox) loadResultTable(ctx context.Context, conn *sql.Conn, allRows []map[string]interface{}) error {
+	// Pages beyond the memory budget fail with "database or disk is full"
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("PRAGMA max_page_count = %d", sb.maxMemoryBytes/4096)); err != nil {
+		return err
+	}
+
+	columnSet := make(map[string]bool)
+	for _, row := range allRows {
+		for column := range row {
+			columnSet[column] = true
+		}
+	}
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	quoted := make([]string, len(columns))
+	definitions := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = `"` + strings.ReplaceAll(column, `"`, `""`) + `"`
+		definitions[i] = quoted[i] + " TEXT"
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE nqe_result (%s)", strings.Join(definitions, ", "))); err != nil {
+		return fmt.Errorf("failed to create table: %w", err)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	insertStmt := fmt.Sprintf("INSERT INTO nqe_result (%s) VALUES (%s)", strings.Join(quoted, ", "), strings.TrimRight(strings.Repeat("?,", len(columns)), ","))
+	stmt, err := tx.PrepareContext(ctx, insertStmt)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, row := range allRows {
+		vals := make([]interface{}, len(columns))
+		for i, column := range columns {
+			if v, ok := row[column]; ok && v != nil {
+				vals[i] = resultCellString(v)
+			}
+		}
+		if _, err := stmt.ExecContext(ctx, vals...); err != nil {
+			return fmt.Errorf("failed to insert row: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// classifyError maps driver errors to sandbox error codes where one applies
+func (sb sqlSandbox) classifyError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &SQLSandboxError{Code: sqlErrTimeout, Detail: fmt.Sprintf("stopped after %s", sb.timeout)}
+	}
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not authorized"), strings.Contains(message, "readonly database"):
+		return &SQLSandboxError{Code: sqlErrNotAuthorized, Detail: message}
+	case strings.Contains(message, "database or disk is full"), strings.Contains(message, "out of memory"), strings.Contains(message, "too big"):
+		return &SQLSandboxError{Code: sqlErrMemoryLimit, Detail: message}
+	}
+	return fmt.Errorf("SQL query error: %w", err)
+}
+
+// analysisDBCache keeps an on-disk analysis database per stored result so repeated SQL over
+// the same result skips rebuilding the nqe_result table. Files are named by entity and
+// result version, so a changed result gets a fresh database and the stale one is removed.
+type analysisDBCache struct {
+	dir   string
+	mutex sync.Mutex // Serializes builds
+}
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// newAnalysisDBCache creates a cache storing databases under dir
+func newAnalysisDBCache(dir string) *analysisDBCache {
+	return &analysisDBCache{dir: dir}
+}
+
+// remove deletes every cached database of an entity
+func (c *analysisDBCache) remove(entityID string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	files, _ := filepath.Glob(filepath.Join(c.dir, c.filePrefix(entityID)+"*.db"))
+	for _, file := range files {
+		os.Remove(file)
+	}
+}
+
+func (c *analysisDBCache) filePrefix(entityID string) string {
+	return unsafeFileNameChars.ReplaceAllString(entityID, "_") + "-"
+}
+
+// ensure returns the path of the entity's database for version, calling build to create it
+// when it is missing. built reports whether this call built it.
+func (c *analysisDBCache) ensure(entityID, version string, build func(path string) error) (string, bool, error) {
+	prefix := c.filePrefix(entityID)
+	path := filepath.Join(c.dir, prefix+version+".db")
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	// Another call may have built it while we waited
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+	if err := os.MkdirAll(c.dir, 0700); err != nil {
+		return "", false, fmt.Errorf("failed to create analysis cache directory: %w", err)
+	}
+
+	stale, _ := filepath.Glob(filepath.Join(c.dir, prefix+"*.db"))
+	for _, file := range stale {
+		os.Remove(file)
+	}
+
+	tmpPath := path + ".tmp"
+	os.Remove(tmpPath)
+	if err := build(tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return "", false, err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to store analysis database: %w", err)
+	}
+	return path, true, nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestValidateSandboxSQL(t *testing.T) {
+	cases := []struct {
+		query string
+		code  string
+	}{
+		{"SELECT COUNT(*) FROM nqe_result;", ""},
+		{"WITH x AS (SELECT * FROM nqe_result) SELECT * FROM x -- done;", ""},
+		{"SELECT ';' AS semi, \"pragma\" FROM nqe_result", ""},
+		{"   ", sqlErrEmptyStatement},
+		{"SELECT 1; DROP TABLE nqe_result", sqlErrMultipleStatements},
+		{"SELECT 1;'x'", sqlErrMultipleStatements},
+		{"PRAGMA query_only = off", sqlErrPragmaBlocked},
+		{"ATTACH DATABASE '/tmp/x.db' AS x", sqlErrAttachBlocked},
+		{"SELECT load_extension('/tmp/evil.so')", sqlErrFunctionBlocked},
+		{"DELETE FROM nqe_result", sqlErrStatementNotAllowed},
+	}
+	for _, tc := range cases {
+		_, err := validateSandboxSQL(tc.query)
+		var sandboxErr *SQLSandboxError
+		switch {
+		case tc.code == "" && err != nil:
+			t.Errorf("Expected %q to be allowed, got %v", tc.query, err)
+		case tc.code != "" && (!errors.As(err, &sandboxErr) || sandboxErr.Code != tc.code):
+			t.Errorf("Expected %q to be blocked with %s, got %v", tc.query, tc.code, err)
+		}
+	}
+
+	statement, _ := validateSandboxSQL("SELECT 1 ; -- trailing")
+	if statement != "SELECT 1" {
+		t.Errorf("Expected the trailing semicolon to be stripped, got %q", statement)
+	}
+}
+
+func TestSQLSandboxQuery(t *testing.T) {
+	sandbox := sqlSandbox{timeout: 5 * time.Second, maxMemoryBytes: 16 << 20}
+	chunks := []string{
+		`[{"device":"edge-1","vlan":10},{"device":"edge-2","vlan":20}]`,
+		`[{"device":"core-1","vlan":10,"x\"); DROP TABLE nqe_result; --":"y"}]`,
+	}
+
+	rows, truncated, err := sandbox.Query(chunks, "SELECT vlan, COUNT(*) AS n FROM nqe_result GROUP BY vlan ORDER BY vlan;", 100)
+	if err != nil {
+		t.Fatalf("Expected query to succeed, got: %v", err)
+	}
+	if truncated || len(rows) != 2 || rows[0]["vlan"] != "10" || rows[0]["n"] != int64(2) {
+		t.Errorf("Unexpected rows: %v", rows)
+	}
+
+	_, truncated, _ = sandbox.Query(chunks, "SELECT device FROM nqe_result", 2)
+	if !truncated {
+		t.Error("Expected the result to be truncated at maxRows")
+	}
+
+	// Writes hidden inside a CTE are stopped by the read-only connection
+	_, _, err = sandbox.Query(chunks, "WITH x AS (SELECT 1) INSERT INTO nqe_result (device) SELECT * FROM x", 100)
+	var sandboxErr *SQLSandboxError
+	if !errors.As(err, &sandboxErr) || sandboxErr.Code != sqlErrNotAuthorized {
+		t.Errorf("Expected a not_authorized error, got %v", err)
+	}
+}
+
+func TestSQLSandboxLimits(t *testing.T) {
+	chunks := []string{`[{"n":1}]`}
+	var sandboxErr *SQLSandboxError
+
+	sandbox := sqlSandbox{timeout: 50 * time.Millisecond, maxMemoryBytes: 16 << 20}
+	_, _, err := sandbox.Query(chunks, "WITH RECURSIVE c(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM c) SELECT MAX(i) FROM c", 100)
+	if !errors.As(err, &sandboxErr) || sandboxErr.Code != sqlErrTimeout {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+
+	sandbox = sqlSandbox{timeout: 5 * time.Second, maxMemoryBytes: 4}
+	_, _, err = sandbox.Query(chunks, "SELECT * FROM nqe_result", 100)
+	if !errors.As(err, &sandboxErr) || sandboxErr.Code != sqlErrMemoryLimit {
+		t.Errorf("Expected a memory_limit error, got %v", err)
+	}
+}
+
+func TestAnalyzeNQEResultSQLSandbox(t *testing.T) {
+	service := createTestService()
+	if service.memorySystem == nil {
+		t.Skip("memory system unavailable")
+	}
+	result := &forward.NQERunResult{Items: []map[string]interface{}{{"device": "edge-1"}, {"device": "edge-2"}}}
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_sql_sandbox", "162112", uniqueTestName("snap-sql-"), result, 0)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	defer service.memorySystem.DeleteEntity(entityID)
+
+	response, err := service.analyzeNQEResultSQL(AnalyzeNQEResultSQLArgs{EntityID: entityID, SQLQuery: "SELECT COUNT(*) AS n FROM nqe_result;"})
+	if err != nil {
+		t.Fatalf("Expected query to succeed, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, `"n": 2`) {
+		t.Errorf("Unexpected response: %s", response.Content[0].TextContent.Text)
+	}
+
+	_, err = service.analyzeNQEResultSQL(AnalyzeNQEResultSQLArgs{EntityID: entityID, SQLQuery: "SELECT 1; SELECT 2"})
+	if err == nil || !contains(err.Error(), "[multiple_statements]") {
+		t.Errorf("Expected a multiple_statements error, got %v", err)
+	}
+}
+
+func TestAnalyzeNQEResultSQLCachesDatabase(t *testing.T) {
+	service := createTestService()
+	if service.memorySystem == nil {
+		t.Skip("memory system unavailable")
+	}
+	service.analysisCache = newAnalysisDBCache(t.TempDir())
+
+	result := &forward.NQERunResult{Items: []map[string]interface{}{{"device": "edge-1"}, {"device": "edge-2"}}}
+	entityID, err := service.memorySystem.StoreNQEResultWithChunking("FQ_sql_cache", "162112", uniqueTestName("snap-sql-cache-"), result, 0)
+	if err != nil {
+		t.Fatalf("Failed to store result: %v", err)
+	}
+	defer service.memorySystem.DeleteEntity(entityID)
+
+	query := AnalyzeNQEResultSQLArgs{EntityID: entityID, SQLQuery: "SELECT COUNT(*) AS n FROM nqe_result"}
+	response, err := service.analyzeNQEResultSQL(query)
+	if err != nil {
+		t.Fatalf("Expected query to succeed, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !contains(text, "analysis database built") || !contains(text, `"n": 2`) {
+		t.Errorf("Expected the first call to build the database, got: %s", text)
+	}
+
+	response, err = service.analyzeNQEResultSQL(query)
+	if err != nil {
+		t.Fatalf("Expected query to succeed, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "analysis database cached") {
+		t.Errorf("Expected the second call to reuse the database, got: %s", response.Content[0].TextContent.Text)
+	}
+
+	// Adding a chunk changes the result version and rebuilds the database
+	if _, err := service.memorySystem.AddObservation(entityID, `[{"device":"edge-3"}]`, "nqe_result_chunk", map[string]interface{}{"chunk_index": 1}); err != nil {
+		t.Fatalf("Failed to add chunk: %v", err)
+	}
+	response, err = service.analyzeNQEResultSQL(query)
+	if err != nil {
+		t.Fatalf("Expected query to succeed, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !contains(text, "analysis database built") || !contains(text, `"n": 3`) {
+		t.Errorf("Expected a rebuild after the result changed, got: %s", text)
+	}
+	if files, _ := filepath.Glob(filepath.Join(service.analysisCache.dir, "*.db")); len(files) != 1 {
+		t.Errorf("Expected the stale database to be removed, got %v", files)
+	}
+
+	// The cached database is opened read-only
+	_, err = service.analyzeNQEResultSQL(AnalyzeNQEResultSQLArgs{EntityID: entityID, SQLQuery: "WITH x AS (SELECT 1) INSERT INTO nqe_result (device) SELECT * FROM x"})
+	if err == nil || !contains(err.Error(), "[not_authorized]") {
+		t.Errorf("Expected writes to be blocked, got %v", err)
+	}
+}
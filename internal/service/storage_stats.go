@@ -0,0 +1,359 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Storage components reported by get_storage_stats and cleaned up by cleanup_storage
+const (
+	storageMemoryDB     = "memory_db"
+	storageNQEDB        = "nqe_db"
+	storageAnalysis     = "analysis"
+	storageBloomIndexes = "bloom_indexes"
+	storageReports      = "reports"
+	storageDiskCache    = "disk_cache"
+	storageBackups      = "backups"
+	storageExports      = "exports"
+)
+
+// StorageComponent is the on-disk footprint of one kind of server data
+type StorageComponent struct {
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Path         string     `json:"path"`
+	Files        int        `json:"files"`
+	Bytes        int64      `json:"bytes"`
+	LastModified *time.Time `json:"last_modified,omitempty"`
+	Cleanup      string     `json:"cleanup"`
+}
+
+// StorageStats is the disk usage of the data directory and related locations
+type StorageStats struct {
+	DataDir    string             `json:"data_dir"`
+	TotalBytes int64              `json:"total_bytes"`
+	Components []StorageComponent `json:"components"`
+}
+
+// storageLocation is a component and the files or directories that hold it
+type storageLocation struct {
+	name        string
+	description string
+	cleanup     string
+	paths       []string
+}
+
+// sqliteFiles returns a database path with its write-ahead log and shared memory files
+func sqliteFiles(dbPath string) []string {
+	return []string{dbPath, dbPath + "-wal", dbPath + "-shm"}
+}
+
+// storageLocations lists the components enabled on this server
+func (s *ForwardMCPService) storageLocations() []storageLocation {
+	var locations []storageLocation
+	if s.memorySystem != nil {
+		locations = append(locations, storageLocation{storageMemoryDB, "Knowledge graph, stored NQE results and time series",
+			"VACUUM to reclaim space from deleted entities", sqliteFiles(s.memorySystem.dbPath)})
+	}
+	if s.database != nil {
+		locations = append(locations, storageLocation{storageNQEDB, "NQE query index",
+			"VACUUM to reclaim space", sqliteFiles(s.database.dbPath)})
+	}
+	if s.analysisCache != nil {
+		locations = append(locations, storageLocation{storageAnalysis, "SQL databases of stored results",
+			"delete; rebuilt on the next SQL analysis", []string{s.analysisCache.dir}})
+	}
+	if s.bloomIndexManager != nil {
+		locations = append(locations, storageLocation{storageBloomIndexes, "Persistent bloom indexes",
+			"delete; rebuilt by build_bloom_filter", []string{s.bloomIndexManager.baseDir}})
+	}
+	if s.reports != nil {
+		locations = append(locations, storageLocation{storageReports, "Rendered reports and exports",
+			"delete saved reports", []string{s.reports.dir}})
+	}
+	if dir, err := backupDirectory(); err == nil {
+		locations = append(locations, storageLocation{storageBackups, "Archives written by backup_state",
+			"delete archives", []string{dir}})
+	}
+	if dir, err := sharedResultDirectory(); err == nil {
+		locations = append(locations, storageLocation{storageExports, "Results shared with share_result",
+			"delete shared results; their references stop resolving", []string{dir}})
+	}
+	if s.config.Forward.SemanticCache.PersistToDisk && s.config.Forward.SemanticCache.DiskCachePath != "" {
+		locations = append(locations, storageLocation{storageDiskCache, "Semantic cache entries persisted to disk",
+			"use clear_cache", []string{s.config.Forward.SemanticCache.DiskCachePath}})
+	}
+	return locations
+}
+
+// measureStorage sums the sizes of the regular files under the paths; missing paths count as empty
+func measureStorage(paths []string) (files int, bytes int64, lastModified time.Time) {
+	for _, root := range paths {
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || !entry.Type().IsRegular() {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return nil
+			}
+			files++
+			bytes += info.Size()
+			if info.ModTime().After(lastModified) {
+				lastModified = info.ModTime()
+			}
+			return nil
+		})
+	}
+	return files, bytes, lastModified
+}
+
+// collectStorageStats measures every storage component
+func (s *ForwardMCPService) collectStorageStats() StorageStats {
+	stats := StorageStats{}
+	if dir, err := getWritableDataDirectory(); err == nil {
+		stats.DataDir = dir
+	}
+	for _, location := range s.storageLocations() {
+		component := StorageComponent{
+			Name:        location.name,
+			Description: location.description,
+			Path:        location.paths[0],
+			Cleanup:     location.cleanup,
+		}
+		var lastModified time.Time
+		component.Files, component.Bytes, lastModified = measureStorage(location.paths)
+		if !lastModified.IsZero() {
+			component.LastModified = &lastModified
+		}
+		stats.TotalBytes += component.Bytes
+		stats.Components = append(stats.Components, component)
+	}
+	return stats
+}
+
+// getStorageStats reports disk usage per storage component
+func (s *ForwardMCPService) getStorageStats(args GetStorageStatsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_storage_stats", args, nil)
+
+	stats := s.collectStorageStats()
+	if args.Format == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode storage stats: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var result strings.Builder
+	result.WriteString("Storage Usage\n\n")
+	result.WriteString(fmt.Sprintf("Data directory: %s\n", firstNonEmpty(stats.DataDir, "(not writable)")))
+	result.WriteString(fmt.Sprintf("Total: %s\n\n", formatBytes(stats.TotalBytes)))
+	result.WriteString("| Component | Size | Files | Last Modified | Path | Cleanup |\n")
+	result.WriteString("|---|---|---|---|---|---|\n")
+	for _, component := range stats.Components {
+		modified := "-"
+		if component.LastModified != nil {
+			modified = s.timeFormatter.Format(*component.LastModified)
+		}
+		result.WriteString(fmt.Sprintf("| %s | %s | %d | %s | %s | %s |\n",
+			component.Name, formatBytes(component.Bytes), component.Files, modified, component.Path, component.Cleanup))
+	}
+	result.WriteString("\nUse cleanup_storage with a component name to reclaim space.\n")
+	return mcp.NewToolResponse(mcp.NewTextContent(result.String())), nil
+}
+
+// removeStaleEntries deletes the entries directly under dir last modified before cutoff (zero
+// removes every entry) for which match returns true, returning the removed names and bytes
+func removeStaleEntries(dir string, cutoff time.Time, dryRun bool, match func(entry fs.DirEntry) bool) ([]string, int64, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	var removed []string
+	var bytes int64
+	for _, entry := range entries {
+		if match != nil && !match(entry) {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		_, size, lastModified := measureStorage([]string{path})
+		if info, err := entry.Info(); err == nil && info.ModTime().After(lastModified) {
+			lastModified = info.ModTime()
+		}
+		if !cutoff.IsZero() && !lastModified.Before(cutoff) {
+			continue
+		}
+		if !dryRun {
+			if err := os.RemoveAll(path); err != nil {
+				return removed, bytes, fmt.Errorf("failed to delete %s: %w", path, err)
+			}
+		}
+		removed = append(removed, entry.Name())
+		bytes += size
+	}
+	return removed, bytes, nil
+}
+
+// vacuumDatabase rebuilds a SQLite database file to release free pages
+func vacuumDatabase(run func(query string) error, dbPath string, dryRun bool) (string, error) {
+	_, before, _ := measureStorage(sqliteFiles(dbPath))
+	if dryRun {
+		return fmt.Sprintf("Would VACUUM %s (currently %s)", dbPath, formatBytes(before)), nil
+	}
+	if err := run("VACUUM"); err != nil {
+		return "", fmt.Errorf("failed to vacuum %s: %w", dbPath, err)
+	}
+	// Fold the write-ahead log back into the database so the freed space shows up
+	run("PRAGMA wal_checkpoint(TRUNCATE)")
+	_, after, _ := measureStorage(sqliteFiles(dbPath))
+	return fmt.Sprintf("Vacuumed %s: %s → %s", dbPath, formatBytes(before), formatBytes(after)), nil
+}
+
+// cleanupStorage reclaims disk space from one storage component
+func (s *ForwardMCPService) cleanupStorage(args CleanupStorageArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("cleanup_storage", args, nil)
+
+	if args.OlderThanDays < 0 {
+		return nil, fmt.Errorf("older_than_days must not be negative")
+	}
+	var cutoff time.Time
+	if args.OlderThanDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -args.OlderThanDays)
+	}
+	prefix := ""
+	if args.DryRun {
+		prefix = "[dry run] "
+	}
+	removedSummary := func(what string, removed []string, bytes int64) *mcp.ToolResponse {
+		verb := "Removed"
+		if args.DryRun {
+			verb = "Would remove"
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("%s%s %d %s (%s)", prefix, verb, len(removed), what, formatBytes(bytes))))
+	}
+
+	switch args.Component {
+	case storageMemoryDB:
+		if s.memorySystem == nil {
+			return nil, newCodedError(CodeMemoryUnavailable)
+		}
+		message, err := vacuumDatabase(func(query string) error {
+			_, err := s.memorySystem.db.Exec(query)
+			return err
+		}, s.memorySystem.dbPath, args.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(prefix + message)), nil
+
+	case storageNQEDB:
+		if s.database == nil {
+			return nil, newCodedError(CodeNQEDatabaseUnavailable)
+		}
+		message, err := vacuumDatabase(func(query string) error {
+			_, err := s.database.db.Exec(query)
+			return err
+		}, s.database.dbPath, args.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(prefix + message)), nil
+
+	case storageAnalysis:
+		if s.analysisCache == nil {
+			return nil, fmt.Errorf("analysis database cache is not available")
+		}
+		pins := s.pinnedEntityIDs()
+		s.analysisCache.mutex.Lock()
+		defer s.analysisCache.mutex.Unlock()
+		removed, bytes, err := removeStaleEntries(s.analysisCache.dir, cutoff, args.DryRun, func(entry fs.DirEntry) bool {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".db") {
+				return false
+			}
+			for entityID := range pins {
+				if strings.HasPrefix(entry.Name(), s.analysisCache.filePrefix(entityID)) {
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+		return removedSummary("analysis databases", removed, bytes), nil
+
+	case storageBloomIndexes:
+		if s.bloomIndexManager == nil {
+			return nil, fmt.Errorf("bloom index manager is not available")
+		}
+		pins := s.pinnedEntityIDs()
+		removed, bytes, err := removeStaleEntries(s.bloomIndexManager.baseDir, cutoff, args.DryRun, func(entry fs.DirEntry) bool {
+			return entry.IsDir() && !pins[entry.Name()]
+		})
+		if !args.DryRun {
+			for _, entityID := range removed {
+				s.bloomIndexManager.RemoveEngine(entityID)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		return removedSummary("bloom indexes", removed, bytes), nil
+
+	case storageReports:
+		if s.reports == nil {
+			return nil, fmt.Errorf("report storage is not available")
+		}
+		pruned, err := s.reports.Prune(cutoff, args.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		var bytes int64
+		for _, saved := range pruned {
+			names = append(names, saved.Name)
+			bytes += saved.Size
+		}
+		return removedSummary("reports", names, bytes), nil
+
+	case storageBackups:
+		dir, err := backupDirectory()
+		if err != nil {
+			return nil, err
+		}
+		removed, bytes, err := removeStaleEntries(dir, cutoff, args.DryRun, func(entry fs.DirEntry) bool {
+			return !entry.IsDir() && strings.HasSuffix(entry.Name(), backupArchiveSuffix)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return removedSummary("backup archives", removed, bytes), nil
+
+	case storageExports:
+		dir, err := sharedResultDirectory()
+		if err != nil {
+			return nil, err
+		}
+		removed, bytes, err := removeStaleShares(dir, cutoff, args.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		return removedSummary("shared results", removed, bytes), nil
+
+	case storageDiskCache:
+		return nil, fmt.Errorf("the disk cache is managed by the semantic cache; use clear_cache with clear_all=true")
+	}
+	return nil, fmt.Errorf("unknown storage component %q (use %s, %s, %s, %s, %s, %s or %s)", args.Component,
+		storageMemoryDB, storageNQEDB, storageAnalysis, storageBloomIndexes, storageReports, storageBackups, storageExports)
+}
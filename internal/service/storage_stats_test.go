@@ -0,0 +1,150 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/report"
+)
+
+func writeStorageFile(t *testing.T, path string, size int, modified time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modified, modified); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDataDirectoryOverride(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "custom")
+	t.Setenv("FORWARD_DATA_DIR", dir)
+	if got, err := getWritableDataDirectory(); err != nil || got != dir {
+		t.Errorf("Expected %s, got %s (%v)", dir, got, err)
+	}
+
+	file := filepath.Join(t.TempDir(), "not-a-dir")
+	writeStorageFile(t, file, 1, time.Now())
+	t.Setenv("FORWARD_DATA_DIR", file)
+	if _, err := getWritableDataDirectory(); err == nil || !strings.Contains(err.Error(), "FORWARD_DATA_DIR") {
+		t.Errorf("Expected an error for an unusable FORWARD_DATA_DIR, got %v", err)
+	}
+}
+
+func TestRemoveStaleEntries(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().AddDate(0, 0, -10)
+	writeStorageFile(t, filepath.Join(dir, "old.db"), 100, old)
+	writeStorageFile(t, filepath.Join(dir, "new.db"), 50, time.Now())
+
+	removed, bytes, err := removeStaleEntries(dir, time.Now().AddDate(0, 0, -5), true, nil)
+	if err != nil || len(removed) != 1 || removed[0] != "old.db" || bytes != 100 {
+		t.Errorf("Unexpected dry run result: %v %d %v", removed, bytes, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.db")); err != nil {
+		t.Error("Expected a dry run to keep the file")
+	}
+
+	removed, bytes, err = removeStaleEntries(dir, time.Time{}, false, nil)
+	if err != nil || len(removed) != 2 || bytes != 150 {
+		t.Errorf("Expected every entry removed, got %v %d %v", removed, bytes, err)
+	}
+	if removed, _, err := removeStaleEntries(filepath.Join(dir, "missing"), time.Time{}, false, nil); err != nil || len(removed) != 0 {
+		t.Errorf("Expected a missing directory to be empty, got %v %v", removed, err)
+	}
+}
+
+func TestStorageStatsAndCleanup(t *testing.T) {
+	service := createTestService()
+	analysisDir := t.TempDir()
+	bloomDir := t.TempDir()
+	service.analysisCache = newAnalysisDBCache(analysisDir)
+	service.bloomIndexManager = NewBloomIndexManager(service.logger, bloomDir)
+	service.reports = newTestReportStore(t)
+
+	old := time.Now().AddDate(0, 0, -30)
+	writeStorageFile(t, filepath.Join(analysisDir, "entity_a-v1.db"), 4096, old)
+	writeStorageFile(t, filepath.Join(analysisDir, "entity_b-v1.db"), 1024, time.Now())
+	writeStorageFile(t, filepath.Join(bloomDir, "entity_a", "block-1"), 512, old)
+	if _, err := service.bloomIndexManager.GetOrCreateEngine("entity_b"); err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+	if _, err := service.reports.Save(&report.Report{Title: "Weekly"}, "weekly", "md"); err != nil {
+		t.Fatalf("Failed to save report: %v", err)
+	}
+
+	response, err := service.getStorageStats(GetStorageStatsArgs{Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var stats StorageStats
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &stats); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	byName := make(map[string]StorageComponent)
+	for _, component := range stats.Components {
+		byName[component.Name] = component
+	}
+	if analysis := byName[storageAnalysis]; analysis.Files != 2 || analysis.Bytes != 5120 || analysis.Path != analysisDir {
+		t.Errorf("Unexpected analysis component: %+v", analysis)
+	}
+	if bloom := byName[storageBloomIndexes]; bloom.Files != 1 || bloom.Bytes != 512 {
+		t.Errorf("Unexpected bloom component: %+v", bloom)
+	}
+	if _, ok := byName[storageMemoryDB]; !ok {
+		t.Error("Expected the memory database to be reported")
+	}
+	if stats.TotalBytes < 5632 {
+		t.Errorf("Expected the total to include every component, got %d", stats.TotalBytes)
+	}
+
+	response, err = service.getStorageStats(GetStorageStatsArgs{})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "| analysis | 5.0 KB | 2 |") {
+		t.Errorf("Expected a markdown table, got %v", response.Content[0].TextContent.Text)
+	}
+
+	// Only the stale analysis database is removed
+	response, err = service.cleanupStorage(CleanupStorageArgs{Component: storageAnalysis, OlderThanDays: 7})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "Removed 1 analysis databases (4.0 KB)") {
+		t.Errorf("Unexpected analysis cleanup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(analysisDir, "entity_b-v1.db")); err != nil {
+		t.Error("Expected the recent analysis database to be kept")
+	}
+
+	response, err = service.cleanupStorage(CleanupStorageArgs{Component: storageBloomIndexes})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "Removed 2 bloom indexes") {
+		t.Errorf("Unexpected bloom cleanup: %v %v", response, err)
+	}
+	if len(service.bloomIndexManager.engines) != 0 {
+		t.Error("Expected the engines of removed indexes to be dropped")
+	}
+
+	response, err = service.cleanupStorage(CleanupStorageArgs{Component: storageReports, DryRun: true})
+	if err != nil || !strings.HasPrefix(response.Content[0].TextContent.Text, "[dry run] Would remove 1 reports") {
+		t.Errorf("Unexpected report dry run: %v", err)
+	}
+	if reports, _ := service.reports.List(); len(reports) != 1 {
+		t.Error("Expected the dry run to keep the report")
+	}
+
+	response, err = service.cleanupStorage(CleanupStorageArgs{Component: storageMemoryDB, DryRun: true})
+	if err != nil || !strings.Contains(response.Content[0].TextContent.Text, "Would VACUUM") {
+		t.Errorf("Unexpected memory database dry run: %v", err)
+	}
+
+	if _, err := service.cleanupStorage(CleanupStorageArgs{Component: "everything"}); err == nil {
+		t.Error("Expected an error for an unknown component")
+	}
+	if _, err := service.cleanupStorage(CleanupStorageArgs{Component: storageDiskCache}); err == nil {
+		t.Error("Expected the disk cache to be left to clear_cache")
+	}
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Support levels of a tool on a platform family
+const (
+	supportFull        = "full"
+	supportPartial     = "partial"
+	supportUnsupported = "unsupported"
+)
+
+// unrecognizedFamily is the matrix column for devices the vendor normalizer cannot place
+const unrecognizedFamily = "unrecognized"
+
+// PlatformSupport is the support level of a tool on one platform family
+type PlatformSupport struct {
+	Level string `json:"level"`
+	Note  string `json:"note,omitempty"`
+}
+
+// toolCapability is one row of the maintained capability table. Families without an
+// entry, including custom families from vendor mappings, get the default.
+type toolCapability struct {
+	Tools    []string
+	Analysis string
+	Default  PlatformSupport
+	Families map[string]PlatformSupport
+}
+
+// toolCapabilities records how the vendor-dependent tools behave per normalized platform
+// family (see builtinVendorMappings). Update it alongside changes to those tools.
+var toolCapabilities = []toolCapability{
+	{
+		Tools:    []string{"get_config_section"},
+		Analysis: "Configuration section parsing",
+		Default:  PlatformSupport{supportUnsupported, "no grammar for this platform; the IOS grammar misplaces most statements"},
+		Families: map[string]PlatformSupport{
+			"ios":     {supportFull, "IOS grammar"},
+			"ios_xe":  {supportFull, "IOS grammar"},
+			"nx_os":   {supportFull, "IOS grammar"},
+			"eos":     {supportFull, "IOS grammar"},
+			"junos":   {supportFull, "Junos grammar, brace and set styles"},
+			"ios_xr":  {supportPartial, "IOS grammar; route-policy and prefix-set blocks are not typed"},
+			"asa":     {supportPartial, "IOS grammar; access lists and interfaces only, object groups and NAT are not typed"},
+			"cloud":   {supportUnsupported, "cloud resources have no device configuration"},
+			"linux":   {supportUnsupported, "host configuration is not collected as a device config"},
+			"fortios": {supportUnsupported, "FortiOS config blocks are not parsed"},
+		},
+	},
+	{
+		Tools:    []string{"get_config_diff", "search_configs"},
+		Analysis: "Line-based configuration diff and search",
+		Default:  PlatformSupport{supportFull, ""},
+		Families: map[string]PlatformSupport{
+			"cloud": {supportUnsupported, "cloud resources have no device configuration"},
+			"linux": {supportPartial, "only the files Forward collects from the host"},
+		},
+	},
+	{
+		Tools:    []string{"get_vlan_inventory", "check_vlan_consistency"},
+		Analysis: "VLAN inventory from switched interfaces",
+		Default:  PlatformSupport{supportUnsupported, "no switched VLAN model for this platform"},
+		Families: map[string]PlatformSupport{
+			"ios":           {supportFull, ""},
+			"ios_xe":        {supportFull, ""},
+			"nx_os":         {supportFull, ""},
+			"eos":           {supportFull, ""},
+			"junos":         {supportFull, ""},
+			"cumulus_linux": {supportFull, ""},
+			"ios_xr":        {supportPartial, "bridge domains are not reported as switched VLANs"},
+			"asa":           {supportPartial, "only interfaces in switched mode"},
+			"pan_os":        {supportPartial, "only layer 2 interfaces"},
+			"fortios":       {supportPartial, "only switch interfaces"},
+		},
+	},
+	{
+		Tools:    []string{"check_interface_hygiene"},
+		Analysis: "Interface description conventions",
+		Default:  PlatformSupport{supportFull, ""},
+		Families: map[string]PlatformSupport{
+			"cloud": {supportPartial, "cloud interfaces rarely carry descriptions; roles other than routed are not detected"},
+		},
+	},
+	{
+		Tools:    []string{"forecast_eol_exposure", "get_hardware_support", "get_os_support"},
+		Analysis: "End-of-life and support dates",
+		Default:  PlatformSupport{supportUnsupported, "no vendor support data"},
+		Families: map[string]PlatformSupport{
+			"ios":           {supportFull, ""},
+			"ios_xe":        {supportFull, ""},
+			"nx_os":         {supportFull, ""},
+			"ios_xr":        {supportFull, ""},
+			"asa":           {supportFull, ""},
+			"junos":         {supportFull, ""},
+			"eos":           {supportFull, ""},
+			"pan_os":        {supportFull, ""},
+			"fortios":       {supportFull, ""},
+			"tmos":          {supportFull, ""},
+			"gaia":          {supportPartial, "hardware dates only"},
+			"cumulus_linux": {supportPartial, "OS dates only; the hardware is third party"},
+			"cloud":         {supportUnsupported, "managed by the cloud provider"},
+		},
+	},
+	{
+		Tools:    []string{"search_paths", "search_paths_bulk", "troubleshoot_connectivity", "sweep_violations"},
+		Analysis: "Path analysis on the forwarding model",
+		Default:  PlatformSupport{supportFull, ""},
+		Families: map[string]PlatformSupport{
+			"cloud": {supportPartial, "managed services without a modelled data plane are opaque hops"},
+			"linux": {supportPartial, "hosts are path endpoints; their own routing and firewall rules are not modelled"},
+		},
+	},
+	{
+		Tools:    []string{"classify_devices", "reconcile_inventory"},
+		Analysis: "Inventory classification and reconciliation",
+		Default:  PlatformSupport{supportFull, ""},
+	},
+}
+
+// unrecognizedSupport is reported for devices the vendor normalizer cannot place
+var unrecognizedSupport = PlatformSupport{supportUnsupported, "platform not recognized; add a vendor mapping to classify it"}
+
+// supportFor returns the support level of a capability on a platform family
+func (c toolCapability) supportFor(family string) PlatformSupport {
+	if family == unrecognizedFamily {
+		return unrecognizedSupport
+	}
+	if support, ok := c.Families[family]; ok {
+		return support
+	}
+	return c.Default
+}
+
+// matches reports whether the capability covers the named tool
+func (c toolCapability) matches(tool string) bool {
+	for _, name := range c.Tools {
+		if name == tool {
+			return true
+		}
+	}
+	return false
+}
+
+// SupportMatrixRow is the support of one analysis on every platform in the network
+type SupportMatrixRow struct {
+	Tools     []string                   `json:"tools"`
+	Analysis  string                     `json:"analysis"`
+	Platforms map[string]PlatformSupport `json:"platforms"`
+	// Devices on platforms with less than full support
+	Limited int `json:"limited_devices"`
+}
+
+// SupportMatrix is the support of the vendor-dependent tools on the platforms of a network
+type SupportMatrix struct {
+	NetworkID string             `json:"network_id"`
+	Devices   int                `json:"devices"`
+	Families  map[string]int     `json:"families"`
+	Rows      []SupportMatrixRow `json:"rows"`
+}
+
+// buildSupportMatrix evaluates the capability table for the families present, optionally
+// restricted to one tool
+func buildSupportMatrix(networkID string, families map[string]int, tool string) *SupportMatrix {
+	matrix := &SupportMatrix{NetworkID: networkID, Families: families}
+	for _, count := range families {
+		matrix.Devices += count
+	}
+	for _, capability := range toolCapabilities {
+		if tool != "" && !capability.matches(tool) {
+			continue
+		}
+		row := SupportMatrixRow{Tools: capability.Tools, Analysis: capability.Analysis, Platforms: make(map[string]PlatformSupport)}
+		for family, count := range families {
+			support := capability.supportFor(family)
+			row.Platforms[family] = support
+			if support.Level != supportFull {
+				row.Limited += count
+			}
+		}
+		matrix.Rows = append(matrix.Rows, row)
+	}
+	return matrix
+}
+
+// sortedFamilies orders families by device count, largest first
+func (m *SupportMatrix) sortedFamilies() []string {
+	families := make([]string, 0, len(m.Families))
+	for family := range m.Families {
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		if m.Families[families[i]] != m.Families[families[j]] {
+			return m.Families[families[i]] > m.Families[families[j]]
+		}
+		return families[i] < families[j]
+	})
+	return families
+}
+
+// formatSupportMatrix renders the matrix as a markdown table followed by the notes on
+// partial and unsupported platforms
+func formatSupportMatrix(matrix *SupportMatrix) string {
+	families := matrix.sortedFamilies()
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("## Support Matrix for network %s\n\n", matrix.NetworkID))
+	out.WriteString(fmt.Sprintf("%d devices on %d platforms.\n\n", matrix.Devices, len(families)))
+
+	out.WriteString("| Analysis | Tools |")
+	for _, family := range families {
+		out.WriteString(fmt.Sprintf(" %s (%d) |", family, matrix.Families[family]))
+	}
+	out.WriteString(" Limited devices |\n|---|---|" + strings.Repeat("---|", len(families)+1) + "\n")
+	for _, row := range matrix.Rows {
+		out.WriteString(fmt.Sprintf("| %s | %s |", row.Analysis, strings.Join(row.Tools, ", ")))
+		for _, family := range families {
+			out.WriteString(" " + row.Platforms[family].Level + " |")
+		}
+		out.WriteString(fmt.Sprintf(" %d |\n", row.Limited))
+	}
+
+	var notes []string
+	for _, row := range matrix.Rows {
+		for _, family := range families {
+			support := row.Platforms[family]
+			if support.Level == supportFull {
+				continue
+			}
+			note := fmt.Sprintf("- **%s** on %s: %s", row.Analysis, family, support.Level)
+			if support.Note != "" {
+				note += " — " + support.Note
+			}
+			notes = append(notes, note)
+		}
+	}
+	if len(notes) > 0 {
+		out.WriteString("\n### Limitations\n\n" + strings.Join(notes, "\n") + "\n")
+	}
+	return out.String()
+}
+
+// getSupportMatrix reports which vendor-dependent tools are fully supported, partially
+// supported or unsupported on each platform present in the network
+func (s *ForwardMCPService) getSupportMatrix(args GetSupportMatrixArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_support_matrix", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	tool := strings.TrimSpace(args.Tool)
+	if tool != "" {
+		known := false
+		for _, capability := range toolCapabilities {
+			known = known || capability.matches(tool)
+		}
+		if !known {
+			return nil, fmt.Errorf("tool '%s' is not in the support matrix; it does not depend on the device platform", tool)
+		}
+	}
+
+	format := strings.ToLower(args.Format)
+	if format != "" && format != "markdown" && format != "json" {
+		return nil, fmt.Errorf("invalid format '%s' (expected markdown or json)", args.Format)
+	}
+
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load devices for network %s: %w", networkID, err)
+	}
+	families := make(map[string]int)
+	for _, device := range devices {
+		family := s.vendorNormalizer.NormalizeDevice(device).Family
+		if family == "" {
+			family = unrecognizedFamily
+		}
+		families[family]++
+	}
+
+	matrix := buildSupportMatrix(networkID, families, tool)
+	if format == "json" {
+		return s.streamResponse("get_support_matrix", MarshalCompactJSONString(matrix)), nil
+	}
+	return s.streamResponse("get_support_matrix", formatSupportMatrix(matrix)), nil
+}
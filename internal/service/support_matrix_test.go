@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestGetSupportMatrix(t *testing.T) {
+	service := createTestService()
+	service.deviceCache = nil
+	mock := service.forwardClient.(*MockForwardClient)
+	mock.devices = []forward.Device{
+		{Name: "core-1", Vendor: "CISCO", Platform: "NX_OS"},
+		{Name: "core-2", Vendor: "CISCO", Platform: "NX_OS"},
+		{Name: "edge-1", Vendor: "JUNIPER", Platform: "JUNOS"},
+		{Name: "fw-1", Vendor: "FORTINET", Platform: "FORTIOS"},
+		{Name: "box-1", Vendor: "ACME"},
+	}
+
+	response, err := service.getSupportMatrix(GetSupportMatrixArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{
+		"5 devices on 4 platforms",
+		"| Analysis | Tools | nx_os (2) | fortios (1) | junos (1) | unrecognized (1) | Limited devices |",
+		"| Configuration section parsing | get_config_section | full | unsupported | full | unsupported | 2 |",
+		"- **VLAN inventory from switched interfaces** on fortios: partial — only switch interfaces",
+		"on unrecognized: unsupported — platform not recognized",
+	} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+
+	// One tool, as JSON
+	response, err = service.getSupportMatrix(GetSupportMatrixArgs{NetworkID: "162112", Tool: "check_vlan_consistency", Format: "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var matrix SupportMatrix
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &matrix); err != nil {
+		t.Fatal(err)
+	}
+	if len(matrix.Rows) != 1 || matrix.Rows[0].Platforms["junos"].Level != supportFull || matrix.Rows[0].Limited != 2 {
+		t.Errorf("Expected the VLAN row only, got %+v", matrix.Rows)
+	}
+
+	if _, err := service.getSupportMatrix(GetSupportMatrixArgs{NetworkID: "162112", Tool: "list_networks"}); err == nil {
+		t.Error("Expected a tool outside the matrix to be rejected")
+	}
+}
+
+func TestToolCapabilitiesReferenceRegisteredTools(t *testing.T) {
+	for _, capability := range toolCapabilities {
+		for _, tool := range capability.Tools {
+			if _, ok := toolGroups[tool]; !ok {
+				t.Errorf("Capability %q names unknown tool %s", capability.Analysis, tool)
+			}
+		}
+		for family := range capability.Families {
+			known := false
+			for _, rule := range builtinVendorMappings {
+				known = known || rule.Family == family
+			}
+			if !known {
+				t.Errorf("Capability %q names unknown family %s", capability.Analysis, family)
+			}
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	defaultSweepPrefixes   = 12
+	maxSweepPrefixes       = 50
+	defaultSweepMaxQueries = 200
+	maxSweepQueries        = 1000
+	sweepBatchSize         = 50 // Path searches per bulk request
+	sweepExamplesPerType   = 5
+)
+
+// sweepEndpoint is the representative host of one prefix in the sweep matrix
+type sweepEndpoint struct {
+	Prefix string
+	Device string
+	IP     string
+	Family int
+}
+
+// SweepViolation is one violating path found by the sweep
+type SweepViolation struct {
+	Type              string `json:"type"`
+	SrcPrefix         string `json:"src_prefix"`
+	DstPrefix         string `json:"dst_prefix"`
+	From              string `json:"from"`
+	SrcIP             string `json:"src_ip"`
+	DstIP             string `json:"dst_ip"`
+	Device            string `json:"device"`
+	Interface         string `json:"interface,omitempty"`
+	ForwardingOutcome string `json:"forwarding_outcome,omitempty"`
+	SecurityOutcome   string `json:"security_outcome,omitempty"`
+}
+
+// SweepReport summarizes a violation sweep
+type SweepReport struct {
+	NetworkID   string              `json:"network_id"`
+	SnapshotID  string              `json:"snapshot_id"`
+	PrefixLevel int                 `json:"prefix_level"`
+	Prefixes    int                 `json:"prefixes"`
+	Searches    int                 `json:"searches"`
+	Skipped     int                 `json:"skipped_pairs"`
+	TimedOut    int                 `json:"timed_out"`
+	ByType      map[string]int      `json:"by_type"`
+	ByDevice    []SweepDeviceCount  `json:"by_device"`
+	Violations  []SweepViolation    `json:"violations"`
+	Failed      []string            `json:"failed_batches,omitempty"`
+	examples    map[string][]string // Sample flows per violation type for the markdown report
+}
+
+// SweepDeviceCount is the number of violations attributed to one device
+type SweepDeviceCount struct {
+	Device string         `json:"device"`
+	Total  int            `json:"total"`
+	ByType map[string]int `json:"by_type"`
+}
+
+// sweepEndpoints picks the prefixes at a level with the most devices and one host address
+// in each to act as source and destination
+func sweepEndpoints(entries []PrefixIndexEntry, level, limit int) []sweepEndpoint {
+	type prefixGroup struct {
+		endpoint sweepEndpoint
+		devices  map[string]bool
+	}
+	groups := make(map[string]*prefixGroup)
+	for _, entry := range entries {
+		if entry.PrefixLength != level {
+			continue
+		}
+		ip := parseInterfaceAddress(entry.IP)
+		if ip == nil {
+			continue
+		}
+		group := groups[entry.Prefix]
+		if group == nil {
+			group = &prefixGroup{devices: make(map[string]bool)}
+			groups[entry.Prefix] = group
+		}
+		group.devices[entry.Device] = true
+		// The lowest device/interface is the representative, so sweeps are repeatable
+		candidate := sweepEndpoint{Prefix: entry.Prefix, Device: entry.Device, IP: ip.String(), Family: ipFamily(ip)}
+		if group.endpoint.Device == "" || entry.Device < group.endpoint.Device ||
+			(entry.Device == group.endpoint.Device && candidate.IP < group.endpoint.IP) {
+			group.endpoint = candidate
+		}
+	}
+
+	prefixes := make([]string, 0, len(groups))
+	for prefix := range groups {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Slice(prefixes, func(i, j int) bool {
+		a, b := groups[prefixes[i]], groups[prefixes[j]]
+		if len(a.devices) != len(b.devices) {
+			return len(a.devices) > len(b.devices)
+		}
+		return prefixes[i] < prefixes[j]
+	})
+	if len(prefixes) > limit {
+		prefixes = prefixes[:limit]
+	}
+	endpoints := make([]sweepEndpoint, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		endpoints = append(endpoints, groups[prefix].endpoint)
+	}
+	return endpoints
+}
+
+// classifyViolation names the violation of a path, or "" for a delivered, permitted path
+func classifyViolation(path forward.BulkPath) string {
+	if isDeniedOutcome(path.SecurityOutcome) {
+		return "acl_drop"
+	}
+	outcome := strings.ToUpper(path.ForwardingOutcome)
+	switch {
+	case strings.Contains(outcome, "LOOP"):
+		return "loop"
+	case strings.Contains(outcome, "BLACKHOLE"):
+		return "blackhole"
+	case strings.Contains(outcome, "INCORRECT"):
+		return "misdelivered"
+	case isDeliveredOutcome(outcome):
+		return ""
+	case strings.Contains(outcome, "DROP"):
+		return "dropped"
+	case strings.Contains(outcome, "UNREACHABLE"):
+		return "unreachable"
+	case outcome == "":
+		return "unknown"
+	}
+	return strings.ToLower(outcome)
+}
+
+// violationLocation attributes a violation to a device: the first device seen twice for
+// loops, the hop whose behaviors mention a policy for ACL drops, otherwise the last hop
+func violationLocation(violationType string, path forward.BulkPath) (string, string) {
+	if len(path.Hops) == 0 {
+		return "", ""
+	}
+	switch violationType {
+	case "loop":
+		seen := make(map[string]bool)
+		for _, hop := range path.Hops {
+			if seen[hop.DeviceName] {
+				return hop.DeviceName, hop.IngressInterface
+			}
+			seen[hop.DeviceName] = true
+		}
+	case "acl_drop":
+		for i := len(path.Hops) - 1; i >= 0; i-- {
+			hop := path.Hops[i]
+			for _, behavior := range hop.Behaviors {
+				upper := strings.ToUpper(behavior)
+				if strings.Contains(upper, "ACL") || strings.Contains(upper, "POLICY") || strings.Contains(upper, "DENY") {
+					return hop.DeviceName, firstNonEmpty(hop.IngressInterface, hop.EgressInterface)
+				}
+			}
+		}
+	}
+	last := path.Hops[len(path.Hops)-1]
+	return last.DeviceName, firstNonEmpty(last.EgressInterface, last.IngressInterface)
+}
+
+// sweepViolations runs VIOLATIONS_ONLY path searches between representative hosts of the
+// network's prefixes and summarizes the blackholes, loops and ACL drops found
+func (s *ForwardMCPService) sweepViolations(args SweepViolationsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("sweep_violations", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	level := 24
+	if args.PrefixLevel != "" {
+		if _, err := fmt.Sscanf(strings.TrimPrefix(args.PrefixLevel, "/"), "%d", &level); err != nil {
+			return nil, fmt.Errorf("invalid prefix_level %q (use one of /8, /16, /24, /32, /48, /64)", args.PrefixLevel)
+		}
+	}
+	if !slices.Contains(ipv4AggregationLevels, level) && !slices.Contains(ipv6AggregationLevels, level) {
+		return nil, fmt.Errorf("invalid prefix_level /%d (use one of /8, /16, /24, /32, /48, /64)", level)
+	}
+	maxPrefixes := args.MaxPrefixes
+	if maxPrefixes <= 0 {
+		maxPrefixes = defaultSweepPrefixes
+	}
+	if maxPrefixes > maxSweepPrefixes {
+		maxPrefixes = maxSweepPrefixes
+	}
+	maxQueries := args.MaxQueries
+	if maxQueries <= 0 {
+		maxQueries = defaultSweepMaxQueries
+	}
+	if maxQueries > maxSweepQueries {
+		maxQueries = maxSweepQueries
+	}
+
+	entries, err := s.prefixIndexEntries(networkID, snapshotID, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index network %s: %w", networkID, err)
+	}
+	endpoints := sweepEndpoints(entries, level, maxPrefixes)
+	if len(endpoints) < 2 {
+		return nil, fmt.Errorf("network %s has %d /%d prefixes with addressed interfaces; a sweep needs at least 2", networkID, len(endpoints), level)
+	}
+
+	// Every ordered pair of prefixes in the same address family, up to max_queries
+	type sweepPair struct{ src, dst sweepEndpoint }
+	var pairs []sweepPair
+	skipped := 0
+	for _, src := range endpoints {
+		for _, dst := range endpoints {
+			if src.Prefix == dst.Prefix || src.Family != dst.Family {
+				continue
+			}
+			if len(pairs) >= maxQueries {
+				skipped++
+				continue
+			}
+			pairs = append(pairs, sweepPair{src, dst})
+		}
+	}
+
+	apiSnapshotID := ""
+	if snapshotID != "latest" {
+		apiSnapshotID = snapshotID
+	}
+	report := &SweepReport{
+		NetworkID:   networkID,
+		SnapshotID:  prefixIndexSnapshotKey(snapshotID),
+		PrefixLevel: level,
+		Prefixes:    len(endpoints),
+		Skipped:     skipped,
+		ByType:      make(map[string]int),
+		examples:    make(map[string][]string),
+	}
+	devices := make(map[string]*SweepDeviceCount)
+	for start := 0; start < len(pairs); start += sweepBatchSize {
+		batch := pairs[start:min(start+sweepBatchSize, len(pairs))]
+		queries := make([]forward.PathSearchParams, len(batch))
+		for i, pair := range batch {
+			queries[i] = forward.PathSearchParams{From: pair.src.Device, SrcIP: pair.src.IP, DstIP: pair.dst.IP, IPProto: args.IPProto, DstPort: args.DstPort}
+		}
+		responses, err := s.forwardClient.SearchPathsBulk(networkID, &forward.PathSearchBulkRequest{
+			Queries:    queries,
+			Intent:     "VIOLATIONS_ONLY",
+			MaxResults: 3,
+		}, apiSnapshotID)
+		if err != nil {
+			s.logger.Warn("Violation sweep batch at %d failed: %v", start, err)
+			report.Failed = append(report.Failed, fmt.Sprintf("searches %d-%d: %s", start+1, start+len(batch), truncateString(err.Error(), 160)))
+			continue
+		}
+		report.Searches += len(batch)
+
+		for i, response := range responses {
+			if i >= len(batch) {
+				break
+			}
+			if response.TimedOut {
+				report.TimedOut++
+			}
+			pair := batch[i]
+			// One violation per type and flow; alternative paths usually repeat the same failure
+			reported := make(map[string]bool)
+			for _, path := range response.Info.Paths {
+				violationType := classifyViolation(path)
+				if violationType == "" || reported[violationType] {
+					continue
+				}
+				reported[violationType] = true
+				device, iface := violationLocation(violationType, path)
+				violation := SweepViolation{
+					Type:              violationType,
+					SrcPrefix:         pair.src.Prefix,
+					DstPrefix:         pair.dst.Prefix,
+					From:              pair.src.Device,
+					SrcIP:             pair.src.IP,
+					DstIP:             pair.dst.IP,
+					Device:            firstNonEmpty(device, "unknown"),
+					Interface:         iface,
+					ForwardingOutcome: path.ForwardingOutcome,
+					SecurityOutcome:   path.SecurityOutcome,
+				}
+				report.Violations = append(report.Violations, violation)
+				report.ByType[violationType]++
+				count := devices[violation.Device]
+				if count == nil {
+					count = &SweepDeviceCount{Device: violation.Device, ByType: make(map[string]int)}
+					devices[violation.Device] = count
+				}
+				count.Total++
+				count.ByType[violationType]++
+				if len(report.examples[violationType]) < sweepExamplesPerType {
+					report.examples[violationType] = append(report.examples[violationType],
+						fmt.Sprintf("%s (%s) → %s at %s", pair.src.Device, pair.src.Prefix, pair.dst.IP, violation.Device))
+				}
+			}
+		}
+	}
+	if report.Searches == 0 && len(report.Failed) > 0 {
+		return nil, fmt.Errorf("violation sweep failed: %s", report.Failed[0])
+	}
+	for _, count := range devices {
+		report.ByDevice = append(report.ByDevice, *count)
+	}
+	sort.Slice(report.ByDevice, func(i, j int) bool {
+		if report.ByDevice[i].Total != report.ByDevice[j].Total {
+			return report.ByDevice[i].Total > report.ByDevice[j].Total
+		}
+		return report.ByDevice[i].Device < report.ByDevice[j].Device
+	})
+
+	if strings.EqualFold(args.Format, "json") {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode sweep report: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(formatSweepReport(report))), nil
+}
+
+// formatSweepReport renders a sweep as markdown
+func formatSweepReport(report *SweepReport) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("# Violation Sweep: network %s (snapshot %s)\n\n", report.NetworkID, report.SnapshotID))
+	text.WriteString(fmt.Sprintf("Ran %d path searches between %d /%d prefixes", report.Searches, report.Prefixes, report.PrefixLevel))
+	if report.Skipped > 0 {
+		text.WriteString(fmt.Sprintf(" (%d pairs skipped by max_queries)", report.Skipped))
+	}
+	if report.TimedOut > 0 {
+		text.WriteString(fmt.Sprintf("; %d timed out", report.TimedOut))
+	}
+	text.WriteString(".\n\n")
+	for _, failure := range report.Failed {
+		text.WriteString(fmt.Sprintf("⚠️ Failed batch %s\n", failure))
+	}
+
+	if len(report.Violations) == 0 {
+		text.WriteString("✅ No blackholes, loops or ACL drops found between the sampled prefixes.\n")
+		return text.String()
+	}
+
+	types := make([]string, 0, len(report.ByType))
+	for violationType := range report.ByType {
+		types = append(types, violationType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if report.ByType[types[i]] != report.ByType[types[j]] {
+			return report.ByType[types[i]] > report.ByType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+
+	text.WriteString(fmt.Sprintf("## ❌ %d violations by type\n\n| Type | Flows |\n|---|---|\n", len(report.Violations)))
+	for _, violationType := range types {
+		text.WriteString(fmt.Sprintf("| %s | %d |\n", violationType, report.ByType[violationType]))
+	}
+
+	text.WriteString("\n## By device\n\n| Device | Violations | Types |\n|---|---|---|\n")
+	for i, device := range report.ByDevice {
+		if i == 15 {
+			text.WriteString(fmt.Sprintf("\n… %d more devices (use format=json for all)\n", len(report.ByDevice)-i))
+			break
+		}
+		var kinds []string
+		for _, violationType := range types {
+			if count := device.ByType[violationType]; count > 0 {
+				kinds = append(kinds, fmt.Sprintf("%s ×%d", violationType, count))
+			}
+		}
+		text.WriteString(fmt.Sprintf("| %s | %d | %s |\n", device.Device, device.Total, strings.Join(kinds, ", ")))
+	}
+
+	text.WriteString("\n## Examples\n")
+	for _, violationType := range types {
+		text.WriteString(fmt.Sprintf("\n**%s**\n", violationType))
+		for _, example := range report.examples[violationType] {
+			text.WriteString("- " + example + "\n")
+		}
+	}
+	text.WriteString("\nUse troubleshoot_connectivity on an example flow for the full route trace.\n")
+	return text.String()
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+// sweepClient answers VIOLATIONS_ONLY searches: traffic to branch-1 is blackholed and
+// edge-1 loops towards 10.1.1.1 while also hitting an ACL
+type sweepClient struct {
+	*MockForwardClient
+	requests []*forward.PathSearchBulkRequest
+}
+
+func (c *sweepClient) SearchPathsBulk(networkID string, request *forward.PathSearchBulkRequest, snapshotID string) ([]forward.PathSearchBulkResponse, error) {
+	c.requests = append(c.requests, request)
+	responses := make([]forward.PathSearchBulkResponse, len(request.Queries))
+	for i, query := range request.Queries {
+		var paths []forward.BulkPath
+		switch {
+		case query.DstIP == "172.16.0.1":
+			paths = []forward.BulkPath{{ForwardingOutcome: "BLACKHOLE", SecurityOutcome: "PERMITTED",
+				Hops: []forward.BulkHop{{DeviceName: query.From}, {DeviceName: "core-1", IngressInterface: "eth9"}}}}
+		case query.From == "edge-1" && query.DstIP == "10.1.1.1":
+			paths = []forward.BulkPath{
+				{ForwardingOutcome: "LOOP", Hops: []forward.BulkHop{{DeviceName: "edge-1"}, {DeviceName: "core-1"}, {DeviceName: "edge-1", IngressInterface: "eth0"}}},
+				{ForwardingOutcome: "DELIVERED", SecurityOutcome: "DENIED", Hops: []forward.BulkHop{
+					{DeviceName: "edge-1"}, {DeviceName: "fw-1", IngressInterface: "inside", Behaviors: []string{"ACL_DENY"}}, {DeviceName: "core-1"}}},
+			}
+		}
+		responses[i] = forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: paths}}
+	}
+	return responses, nil
+}
+
+func TestClassifyViolation(t *testing.T) {
+	tests := map[string]forward.BulkPath{
+		"":             {ForwardingOutcome: "DELIVERED", SecurityOutcome: "PERMITTED"},
+		"acl_drop":     {ForwardingOutcome: "DELIVERED", SecurityOutcome: "DENIED"},
+		"loop":         {ForwardingOutcome: "LOOP"},
+		"blackhole":    {ForwardingOutcome: "BLACKHOLE"},
+		"misdelivered": {ForwardingOutcome: "DELIVERED_TO_INCORRECT_LOCATION"},
+		"dropped":      {ForwardingOutcome: "DROPPED"},
+		"inadmissible": {ForwardingOutcome: "INADMISSIBLE"},
+	}
+	for expected, path := range tests {
+		if violationType := classifyViolation(path); violationType != expected {
+			t.Errorf("%+v: expected %q, got %q", path, expected, violationType)
+		}
+	}
+}
+
+func TestSweepViolations(t *testing.T) {
+	service := createTestService()
+	client := &sweepClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	client.devices = prefixIndexTestDevices()
+	service.forwardClient = client
+	service.deviceCache = nil
+
+	response, err := service.sweepViolations(SweepViolationsArgs{Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report SweepReport
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+
+	// Four /24 prefixes form twelve ordered pairs in one batch
+	if report.Prefixes != 4 || report.Searches != 12 || len(client.requests) != 1 {
+		t.Errorf("Expected 12 searches across 4 prefixes in one request, got %d across %d in %d", report.Searches, report.Prefixes, len(client.requests))
+	}
+	if client.requests[0].Intent != "VIOLATIONS_ONLY" {
+		t.Errorf("Expected VIOLATIONS_ONLY intent, got %s", client.requests[0].Intent)
+	}
+	expected := map[string]int{"blackhole": 3, "loop": 1, "acl_drop": 1}
+	for violationType, count := range expected {
+		if report.ByType[violationType] != count {
+			t.Errorf("%s: expected %d, got %d (%v)", violationType, count, report.ByType[violationType], report.ByType)
+		}
+	}
+	if len(report.ByDevice) == 0 || report.ByDevice[0].Device != "core-1" || report.ByDevice[0].Total != 3 {
+		t.Errorf("Expected core-1 to lead the device summary, got %+v", report.ByDevice)
+	}
+	for _, violation := range report.Violations {
+		if violation.Type == "acl_drop" && violation.Device != "fw-1" {
+			t.Errorf("Expected the ACL drop to be attributed to fw-1, got %s", violation.Device)
+		}
+		if violation.Type == "loop" && violation.Device != "edge-1" {
+			t.Errorf("Expected the loop to be attributed to edge-1, got %s", violation.Device)
+		}
+	}
+
+	// max_queries bounds the matrix and the markdown report summarizes by type
+	response, err = service.sweepViolations(SweepViolationsArgs{MaxQueries: 5})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Ran 5 path searches") || !strings.Contains(text, "7 pairs skipped") || !strings.Contains(text, "violations by type") {
+		t.Errorf("Unexpected report: %s", text)
+	}
+
+	if _, err := service.sweepViolations(SweepViolationsArgs{PrefixLevel: "/20"}); err == nil {
+		t.Error("Expected an error for an unindexed prefix level")
+	}
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// epochMillisThreshold separates epoch seconds from epoch milliseconds; 1e12 ms is September 2001
+const epochMillisThreshold = 1e12
+
+// TimeFormatter renders timestamps in tool responses as ISO-8601 in the configured display
+// timezone. A nil formatter renders UTC.
+type TimeFormatter struct {
+	location *time.Location
+	now      func() time.Time
+}
+
+// NewTimeFormatter creates a formatter for an IANA timezone name such as "America/New_York".
+// Empty or unknown names fall back to UTC.
+func NewTimeFormatter(timezone string, logger *logger.Logger) *TimeFormatter {
+	location := time.UTC
+	if timezone = strings.TrimSpace(timezone); timezone != "" {
+		loaded, err := time.LoadLocation(timezone)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("Unknown display timezone %q, using UTC: %v", timezone, err)
+			}
+		} else {
+			location = loaded
+		}
+	}
+	return &TimeFormatter{location: location, now: time.Now}
+}
+
+// Location returns the display timezone
+func (f *TimeFormatter) Location() *time.Location {
+	if f == nil || f.location == nil {
+		return time.UTC
+	}
+	return f.location
+}
+
+func (f *TimeFormatter) currentTime() time.Time {
+	if f == nil || f.now == nil {
+		return time.Now()
+	}
+	return f.now()
+}
+
+// Format renders t as ISO-8601 in the display timezone; the zero time renders as ""
+func (f *TimeFormatter) Format(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(f.Location()).Format(time.RFC3339)
+}
+
+// FormatEpoch renders an API timestamp given in epoch milliseconds or seconds
+func (f *TimeFormatter) FormatEpoch(epoch int64) string {
+	return f.Format(epochTime(epoch))
+}
+
+// FormatWithAge renders t followed by its humanized age, e.g. "2026-01-01T09:00:00Z (3 hours ago)"
+func (f *TimeFormatter) FormatWithAge(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", f.Format(t), f.Since(t))
+}
+
+// Since humanizes the time between t and now, e.g. "3 hours ago" or "in 2 days"
+func (f *TimeFormatter) Since(t time.Time) string {
+	return humanizeDuration(f.currentTime().Sub(t))
+}
+
+// epochTime converts epoch milliseconds or seconds to a time; zero and negative values are unset
+func epochTime(epoch int64) time.Time {
+	switch {
+	case epoch <= 0:
+		return time.Time{}
+	case epoch < epochMillisThreshold:
+		return time.Unix(epoch, 0)
+	default:
+		return time.UnixMilli(epoch)
+	}
+}
+
+// humanizeDuration renders an elapsed duration relative to now; negative durations are in the future
+func humanizeDuration(elapsed time.Duration) string {
+	future := elapsed < 0
+	if future {
+		elapsed = -elapsed
+	}
+	if elapsed < time.Minute {
+		return "just now"
+	}
+
+	units := []struct {
+		size time.Duration
+		name string
+	}{
+		{365 * 24 * time.Hour, "year"},
+		{30 * 24 * time.Hour, "month"},
+		{7 * 24 * time.Hour, "week"},
+		{24 * time.Hour, "day"},
+		{time.Hour, "hour"},
+		{time.Minute, "minute"},
+	}
+	for _, unit := range units {
+		if elapsed < unit.size {
+			continue
+		}
+		count := int(elapsed / unit.size)
+		phrase := fmt.Sprintf("%d %s", count, unit.name)
+		if count != 1 {
+			phrase += "s"
+		}
+		if future {
+			return "in " + phrase
+		}
+		return phrase + " ago"
+	}
+	return "just now"
+}
+
+// displaySnapshot adds ISO-8601 timestamps and the snapshot age alongside the raw API millis
+type displaySnapshot struct {
+	forward.Snapshot
+	CreatedAt   string `json:"createdAt,omitempty"`
+	ProcessedAt string `json:"processedAt,omitempty"`
+	Age         string `json:"age,omitempty"`
+}
+
+// displaySnapshots prepares snapshots for tool responses
+func (s *ForwardMCPService) displaySnapshots(snapshots []forward.Snapshot) []displaySnapshot {
+	display := make([]displaySnapshot, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		view := displaySnapshot{
+			Snapshot:    snapshot,
+			CreatedAt:   s.timeFormatter.FormatEpoch(snapshot.CreationDateMillis),
+			ProcessedAt: s.timeFormatter.FormatEpoch(snapshot.ProcessedAtMillis),
+		}
+		if created := epochTime(snapshot.CreationDateMillis); !created.IsZero() {
+			view.Age = s.timeFormatter.Since(created)
+		}
+		display = append(display, view)
+	}
+	return display
+}
+
+// displayNetwork replaces the network creation millis with an ISO-8601 timestamp
+type displayNetwork struct {
+	forward.Network
+	CreatedAt string `json:"createdAt,omitempty"`
+}
+
+// displayNetworks prepares networks for tool responses
+func (s *ForwardMCPService) displayNetworks(networks []forward.Network) []displayNetwork {
+	display := make([]displayNetwork, 0, len(networks))
+	for _, network := range networks {
+		display = append(display, displayNetwork{
+			Network:   network,
+			CreatedAt: s.timeFormatter.FormatEpoch(network.CreatedAt),
+		})
+	}
+	return display
+}
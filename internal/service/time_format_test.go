@@ -0,0 +1,85 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestTimeFormatter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	formatter := NewTimeFormatter("America/New_York", nil)
+	formatter.now = func() time.Time { return now }
+
+	if got := formatter.Format(now); got != "2026-01-01T07:00:00-05:00" {
+		t.Errorf("Format = %q; want New York offset", got)
+	}
+	if got := formatter.FormatEpoch(now.UnixMilli()); got != "2026-01-01T07:00:00-05:00" {
+		t.Errorf("FormatEpoch(millis) = %q", got)
+	}
+	if got := formatter.FormatEpoch(now.Unix()); got != "2026-01-01T07:00:00-05:00" {
+		t.Errorf("FormatEpoch(seconds) = %q", got)
+	}
+	if got := formatter.FormatEpoch(0); got != "" {
+		t.Errorf("FormatEpoch(0) = %q; want empty", got)
+	}
+	if got := formatter.FormatWithAge(now.Add(-3 * time.Hour)); got != "2026-01-01T04:00:00-05:00 (3 hours ago)" {
+		t.Errorf("FormatWithAge = %q", got)
+	}
+
+	if got := NewTimeFormatter("Not/AZone", nil).Format(now); got != "2026-01-01T12:00:00Z" {
+		t.Errorf("Unknown timezone should fall back to UTC, got %q", got)
+	}
+	var unset *TimeFormatter
+	if got := unset.Format(now); got != "2026-01-01T12:00:00Z" {
+		t.Errorf("Nil formatter should render UTC, got %q", got)
+	}
+}
+
+func TestHumanizeDuration(t *testing.T) {
+	tests := []struct {
+		elapsed time.Duration
+		want    string
+	}{
+		{10 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{3*time.Hour + 20*time.Minute, "3 hours ago"},
+		{50 * time.Hour, "2 days ago"},
+		{400 * 24 * time.Hour, "1 year ago"},
+		{-48 * time.Hour, "in 2 days"},
+	}
+	for _, tt := range tests {
+		if got := humanizeDuration(tt.elapsed); got != tt.want {
+			t.Errorf("humanizeDuration(%v) = %q; want %q", tt.elapsed, got, tt.want)
+		}
+	}
+}
+
+func TestSnapshotTimestampsInResponses(t *testing.T) {
+	service := createTestService()
+	service.timeFormatter = NewTimeFormatter("UTC", nil)
+	service.forwardClient.(*MockForwardClient).snapshots = []forward.Snapshot{
+		{ID: "snap-1", CreationDateMillis: 1767225600000, ProcessedAtMillis: 1767225900000},
+	}
+
+	response, err := service.getLatestSnapshot(GetLatestSnapshotArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, want := range []string{`"createdAt": "2026-01-01T00:00:00Z"`, `"processedAt": "2026-01-01T00:05:00Z"`, `"age": "`, `"creationDateMillis": 1767225600000`} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %s in response, got:\n%s", want, text)
+		}
+	}
+
+	response, err = service.listNetworks(ListNetworksArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, `"createdAt":"2025-04-25T`) {
+		t.Errorf("Expected ISO-8601 network creation time, got:\n%s", text)
+	}
+}
@@ -0,0 +1,606 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	defaultTimeSeriesSnapshots = 30
+	maxTimeSeriesSnapshots     = 200
+	maxTimeSeriesGroups        = 50 // Distinct group_by values kept per snapshot; the rest count as (other)
+)
+
+// TimeSeriesDefinition is a named query whose metrics are collected per snapshot
+type TimeSeriesDefinition struct {
+	Name       string    `json:"name"`
+	NetworkID  string    `json:"network_id"`
+	QueryID    string    `json:"query_id,omitempty"`
+	Query      string    `json:"query,omitempty"`
+	SumColumns []string  `json:"sum_columns,omitempty"`
+	GroupBy    string    `json:"group_by,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	Points     int       `json:"points"`
+}
+
+// TimeSeriesPoint holds the metrics of one snapshot
+type TimeSeriesPoint struct {
+	SnapshotID   string             `json:"snapshot_id"`
+	SnapshotTime time.Time          `json:"snapshot_time"`
+	Metrics      map[string]float64 `json:"metrics"`
+}
+
+// TimeSeriesStore persists query metrics per network snapshot in the memory database, so
+// trends over historical snapshots are computed once and read back without API calls
+type TimeSeriesStore struct {
+	db         *sql.DB
+	instanceID string
+	logger     *logger.Logger
+	now        func() time.Time
+}
+
+// NewTimeSeriesStore creates the time-series tables in db
+func NewTimeSeriesStore(db *sql.DB, instanceID string, logger *logger.Logger) (*TimeSeriesStore, error) {
+	store := &TimeSeriesStore{db: db, instanceID: instanceID, logger: logger, now: time.Now}
+	if err := store.initSchema(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (t *TimeSeriesStore) initSchema() error {
+	schema := `
+	CREATE TABLE IF NOT EXISTS timeseries_definitions (
+		instance_id TEXT NOT NULL,
+		network_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		query_id TEXT NOT NULL,
+		query TEXT NOT NULL,
+		sum_columns TEXT NOT NULL,
+		group_by TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY(instance_id, network_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS timeseries_points (
+		instance_id TEXT NOT NULL,
+		network_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		snapshot_id TEXT NOT NULL,
+		snapshot_time INTEGER NOT NULL,
+		metrics TEXT NOT NULL,
+		collected_at INTEGER NOT NULL,
+		PRIMARY KEY(instance_id, network_id, name, snapshot_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_timeseries_points_time ON timeseries_points(instance_id, network_id, name, snapshot_time);
+	`
+	if _, err := t.db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create time-series schema: %w", err)
+	}
+	return nil
+}
+
+// Define records a series definition, keeping the original creation time when it exists
+func (t *TimeSeriesStore) Define(definition *TimeSeriesDefinition) error {
+	sumColumns, err := json.Marshal(definition.SumColumns)
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.Exec(`
+		INSERT INTO timeseries_definitions (instance_id, network_id, name, query_id, query, sum_columns, group_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(instance_id, network_id, name) DO UPDATE SET
+			query_id = excluded.query_id, query = excluded.query, sum_columns = excluded.sum_columns, group_by = excluded.group_by
+	`, t.instanceID, definition.NetworkID, definition.Name, definition.QueryID, definition.Query, string(sumColumns),
+		definition.GroupBy, t.now().Unix()); err != nil {
+		return fmt.Errorf("failed to store time series %s: %w", definition.Name, err)
+	}
+	return nil
+}
+
+// Definition returns a series definition, or nil if none exists
+func (t *TimeSeriesStore) Definition(networkID, name string) (*TimeSeriesDefinition, error) {
+	definitions, err := t.definitions(`AND d.name = ?`, networkID, name)
+	if err != nil || len(definitions) == 0 {
+		return nil, err
+	}
+	return &definitions[0], nil
+}
+
+// List returns the series defined on a network
+func (t *TimeSeriesStore) List(networkID string) ([]TimeSeriesDefinition, error) {
+	return t.definitions("", networkID)
+}
+
+func (t *TimeSeriesStore) definitions(filter string, networkID string, args ...interface{}) ([]TimeSeriesDefinition, error) {
+	rows, err := t.db.Query(`
+		SELECT d.name, d.query_id, d.query, d.sum_columns, d.group_by, d.created_at,
+			(SELECT COUNT(*) FROM timeseries_points p
+			 WHERE p.instance_id = d.instance_id AND p.network_id = d.network_id AND p.name = d.name)
+		FROM timeseries_definitions d
+		WHERE d.instance_id = ? AND d.network_id = ? `+filter+`
+		ORDER BY d.name
+	`, append([]interface{}{t.instanceID, networkID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time series: %w", err)
+	}
+	defer rows.Close()
+
+	var definitions []TimeSeriesDefinition
+	for rows.Next() {
+		definition := TimeSeriesDefinition{NetworkID: networkID}
+		var sumColumns string
+		var createdAt int64
+		if err := rows.Scan(&definition.Name, &definition.QueryID, &definition.Query, &sumColumns, &definition.GroupBy,
+			&createdAt, &definition.Points); err != nil {
+			return nil, fmt.Errorf("failed to scan time series: %w", err)
+		}
+		if err := json.Unmarshal([]byte(sumColumns), &definition.SumColumns); err != nil {
+			t.logger.Debug("Invalid sum columns of time series %s: %v", definition.Name, err)
+		}
+		definition.CreatedAt = time.Unix(createdAt, 0)
+		definitions = append(definitions, definition)
+	}
+	return definitions, rows.Err()
+}
+
+// AddPoint stores the metrics of one snapshot, replacing earlier metrics of that snapshot
+func (t *TimeSeriesStore) AddPoint(networkID, name string, point TimeSeriesPoint) error {
+	metrics, err := json.Marshal(point.Metrics)
+	if err != nil {
+		return err
+	}
+	if _, err := t.db.Exec(`
+		INSERT OR REPLACE INTO timeseries_points (instance_id, network_id, name, snapshot_id, snapshot_time, metrics, collected_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, t.instanceID, networkID, name, point.SnapshotID, point.SnapshotTime.UnixMilli(), string(metrics), t.now().Unix()); err != nil {
+		return fmt.Errorf("failed to store time series point: %w", err)
+	}
+	return nil
+}
+
+// Points returns the points of a series between from and to (zero bounds are open), oldest first
+func (t *TimeSeriesStore) Points(networkID, name string, from, to time.Time) ([]TimeSeriesPoint, error) {
+	query := `SELECT snapshot_id, snapshot_time, metrics FROM timeseries_points
+		WHERE instance_id = ? AND network_id = ? AND name = ?`
+	args := []interface{}{t.instanceID, networkID, name}
+	if !from.IsZero() {
+		query += " AND snapshot_time >= ?"
+		args = append(args, from.UnixMilli())
+	}
+	if !to.IsZero() {
+		query += " AND snapshot_time <= ?"
+		args = append(args, to.UnixMilli())
+	}
+	rows, err := t.db.Query(query+" ORDER BY snapshot_time", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query time series points: %w", err)
+	}
+	defer rows.Close()
+
+	var points []TimeSeriesPoint
+	for rows.Next() {
+		var point TimeSeriesPoint
+		var snapshotTime int64
+		var metrics string
+		if err := rows.Scan(&point.SnapshotID, &snapshotTime, &metrics); err != nil {
+			return nil, fmt.Errorf("failed to scan time series point: %w", err)
+		}
+		if err := json.Unmarshal([]byte(metrics), &point.Metrics); err != nil {
+			return nil, fmt.Errorf("invalid metrics for snapshot %s: %w", point.SnapshotID, err)
+		}
+		point.SnapshotTime = time.UnixMilli(snapshotTime)
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+// timeSeriesMetrics computes the row count, the sum of each requested column and, with
+// groupBy, the row count per distinct value as count(column=value)
+func timeSeriesMetrics(rows []map[string]interface{}, sumColumns []string, groupBy string) map[string]float64 {
+	metrics := map[string]float64{"row_count": float64(len(rows))}
+	for _, column := range sumColumns {
+		metrics["sum("+column+")"] = 0
+	}
+	groups := make(map[string]float64)
+	for _, row := range rows {
+		for _, column := range sumColumns {
+			if value, ok := timeSeriesNumber(row[column]); ok {
+				metrics["sum("+column+")"] += value
+			}
+		}
+		if groupBy != "" {
+			groups[resultValueString(row[groupBy])]++
+		}
+	}
+
+	values := make([]string, 0, len(groups))
+	for value := range groups {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if groups[values[i]] != groups[values[j]] {
+			return groups[values[i]] > groups[values[j]]
+		}
+		return values[i] < values[j]
+	})
+	for i, value := range values {
+		key := fmt.Sprintf("count(%s=%s)", groupBy, value)
+		if i >= maxTimeSeriesGroups {
+			key = fmt.Sprintf("count(%s=(other))", groupBy)
+		}
+		metrics[key] += groups[value]
+	}
+	return metrics
+}
+
+// timeSeriesNumber reads a numeric result value; numeric strings count as numbers
+func timeSeriesNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// parseTimeSeriesBound accepts RFC 3339 timestamps, dates and ages such as "90d" or "12h".
+// Dates used as an upper bound include the whole day.
+func parseTimeSeriesBound(value string, now time.Time, upper bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if age, err := time.ParseDuration(value); err == nil && age >= 0 {
+		return now.Add(-age), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		if upper {
+			return t.AddDate(0, 0, 1).Add(-time.Millisecond), nil
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339, YYYY-MM-DD or an age such as 30d", value)
+}
+
+// timeSeriesSnapshots selects the processed snapshots between from and to, keeping the
+// newest limit snapshots, oldest first
+func timeSeriesSnapshots(snapshots []forward.Snapshot, from, to time.Time, limit int) []forward.Snapshot {
+	var selected []forward.Snapshot
+	for _, snapshot := range snapshots {
+		if snapshot.IsDraft || (snapshot.State != "" && !strings.EqualFold(snapshot.State, "PROCESSED")) {
+			continue
+		}
+		created := epochTime(snapshot.CreationDateMillis)
+		if created.IsZero() || (!from.IsZero() && created.Before(from)) || (!to.IsZero() && created.After(to)) {
+			continue
+		}
+		selected = append(selected, snapshot)
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].CreationDateMillis < selected[j].CreationDateMillis })
+	if len(selected) > limit {
+		selected = selected[len(selected)-limit:]
+	}
+	return selected
+}
+
+// fetchTimeSeriesRows runs the series query against one snapshot, paging through every row
+func (s *ForwardMCPService) fetchTimeSeriesRows(definition *TimeSeriesDefinition, snapshotID string) ([]map[string]interface{}, error) {
+	if definition.QueryID != "" {
+		return s.fetchAllNQEItems(definition.NetworkID, snapshotID, definition.QueryID, nil)
+	}
+	return s.fetchAllNQEQueryItems(definition.NetworkID, snapshotID, definition.Query)
+}
+
+// collectTimeSeries runs a series query across the historical snapshots in a range and
+// stores one point of metrics per snapshot
+func (s *ForwardMCPService) collectTimeSeries(args CollectTimeSeriesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("collect_timeseries", args, nil)
+
+	if s.timeSeries == nil {
+		return nil, newCodedError(CodeTimeSeriesUnavailable)
+	}
+	name := strings.TrimSpace(args.SeriesName)
+	if name == "" {
+		return nil, fmt.Errorf("series_name is required")
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	now := s.timeSeries.now()
+	from, err := parseTimeSeriesBound(args.From, now, false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeSeriesBound(args.To, now, true)
+	if err != nil {
+		return nil, err
+	}
+	limit := args.MaxSnapshots
+	if limit <= 0 {
+		limit = defaultTimeSeriesSnapshots
+	}
+	if limit > maxTimeSeriesSnapshots {
+		limit = maxTimeSeriesSnapshots
+	}
+
+	definition, err := s.timeSeries.Definition(networkID, name)
+	if err != nil {
+		return nil, err
+	}
+	requested := &TimeSeriesDefinition{Name: name, NetworkID: networkID, QueryID: args.QueryID, Query: args.Query,
+		SumColumns: args.SumColumns, GroupBy: args.GroupBy}
+	switch {
+	case definition == nil:
+		if (args.QueryID == "") == (args.Query == "") {
+			return nil, fmt.Errorf("new series %s needs exactly one of query_id or query", name)
+		}
+		if err := s.timeSeries.Define(requested); err != nil {
+			return nil, err
+		}
+		definition = requested
+	case args.QueryID != "" || args.Query != "" || len(args.SumColumns) > 0 || args.GroupBy != "":
+		if args.QueryID != definition.QueryID || args.Query != definition.Query ||
+			strings.Join(args.SumColumns, ",") != strings.Join(definition.SumColumns, ",") || args.GroupBy != definition.GroupBy {
+			return nil, fmt.Errorf("series %s already exists with a different query or metrics; omit them to extend it or choose another name", name)
+		}
+	}
+
+	snapshots, err := s.forwardClient.GetSnapshots(networkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	selected := timeSeriesSnapshots(snapshots, from, to, limit)
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("network %s has no processed snapshots in the requested range", networkID)
+	}
+
+	collected := make(map[string]bool)
+	if !args.Refresh {
+		existing, err := s.timeSeries.Points(networkID, name, time.Time{}, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		for _, point := range existing {
+			collected[point.SnapshotID] = true
+		}
+	}
+
+	added, skipped := 0, 0
+	var failures []string
+	for _, snapshot := range selected {
+		if collected[snapshot.ID] {
+			skipped++
+			continue
+		}
+		rows, err := s.fetchTimeSeriesRows(definition, snapshot.ID)
+		if err != nil {
+			s.logger.Warn("Time series %s: snapshot %s failed: %v", name, snapshot.ID, err)
+			failures = append(failures, fmt.Sprintf("%s: %s", snapshot.ID, truncateString(err.Error(), 160)))
+			continue
+		}
+		point := TimeSeriesPoint{
+			SnapshotID:   snapshot.ID,
+			SnapshotTime: epochTime(snapshot.CreationDateMillis),
+			Metrics:      timeSeriesMetrics(rows, definition.SumColumns, definition.GroupBy),
+		}
+		if err := s.timeSeries.AddPoint(networkID, name, point); err != nil {
+			return nil, err
+		}
+		added++
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("Time series %s on network %s: collected %d snapshots, %d already stored, %d failed (%d in range).\n",
+		name, networkID, added, skipped, len(failures), len(selected)))
+	report.WriteString(fmt.Sprintf("Range: %s to %s\n", s.timeFormatter.FormatEpoch(selected[0].CreationDateMillis),
+		s.timeFormatter.FormatEpoch(selected[len(selected)-1].CreationDateMillis)))
+	for _, failure := range failures {
+		report.WriteString("  ❌ " + failure + "\n")
+	}
+	report.WriteString(fmt.Sprintf("\nUse query_timeseries with series_name=%q to read the trend.", name))
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
+
+// TimeSeriesTrend summarizes one metric over the selected points
+type TimeSeriesTrend struct {
+	Metric string  `json:"metric"`
+	First  float64 `json:"first"`
+	Last   float64 `json:"last"`
+	Change float64 `json:"change"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// timeSeriesTrends summarizes each metric, ordered by name with row_count first. Points
+// missing a metric (e.g. a group absent in that snapshot) count as zero.
+func timeSeriesTrends(points []TimeSeriesPoint, metrics []string) []TimeSeriesTrend {
+	trends := make([]TimeSeriesTrend, 0, len(metrics))
+	for _, metric := range metrics {
+		trend := TimeSeriesTrend{Metric: metric}
+		for i, point := range points {
+			value := point.Metrics[metric]
+			if i == 0 {
+				trend.First, trend.Min, trend.Max = value, value, value
+			}
+			if value < trend.Min {
+				trend.Min = value
+			}
+			if value > trend.Max {
+				trend.Max = value
+			}
+			trend.Last = value
+		}
+		trend.Change = trend.Last - trend.First
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// timeSeriesMetricNames lists the metrics present in points that match filter (a substring),
+// row_count first
+func timeSeriesMetricNames(points []TimeSeriesPoint, filter string) []string {
+	seen := make(map[string]bool)
+	for _, point := range points {
+		for metric := range point.Metrics {
+			if filter == "" || strings.Contains(metric, filter) {
+				seen[metric] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for metric := range seen {
+		names = append(names, metric)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if (names[i] == "row_count") != (names[j] == "row_count") {
+			return names[i] == "row_count"
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// queryTimeSeries returns the stored points of a series with per-metric trends, or lists
+// the series of a network when no name is given
+func (s *ForwardMCPService) queryTimeSeries(args QueryTimeSeriesArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("query_timeseries", args, nil)
+
+	if s.timeSeries == nil {
+		return nil, newCodedError(CodeTimeSeriesUnavailable)
+	}
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+
+	name := strings.TrimSpace(args.SeriesName)
+	if name == "" {
+		definitions, err := s.timeSeries.List(networkID)
+		if err != nil {
+			return nil, err
+		}
+		if len(definitions) == 0 {
+			return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("No time series on network %s. Create one with collect_timeseries.", networkID))), nil
+		}
+		var report strings.Builder
+		report.WriteString(fmt.Sprintf("Time series on network %s:\n\n| Series | Query | Metrics | Points |\n|---|---|---|---|\n", networkID))
+		for _, definition := range definitions {
+			query := definition.QueryID
+			if query == "" {
+				query = truncateString(strings.Join(strings.Fields(definition.Query), " "), 60)
+			}
+			metrics := []string{"row_count"}
+			for _, column := range definition.SumColumns {
+				metrics = append(metrics, "sum("+column+")")
+			}
+			if definition.GroupBy != "" {
+				metrics = append(metrics, "count("+definition.GroupBy+"=…)")
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s | %d |\n", definition.Name, query, strings.Join(metrics, ", "), definition.Points))
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+	}
+
+	definition, err := s.timeSeries.Definition(networkID, name)
+	if err != nil {
+		return nil, err
+	}
+	if definition == nil {
+		return nil, fmt.Errorf("time series %s not found on network %s", name, networkID)
+	}
+	now := s.timeSeries.now()
+	from, err := parseTimeSeriesBound(args.From, now, false)
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTimeSeriesBound(args.To, now, true)
+	if err != nil {
+		return nil, err
+	}
+	points, err := s.timeSeries.Points(networkID, name, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("Time series %s has no points in the requested range. Run collect_timeseries to add snapshots.", name))), nil
+	}
+	metrics := timeSeriesMetricNames(points, args.Metric)
+	if len(metrics) == 0 {
+		return nil, fmt.Errorf("no metric of series %s matches %q", name, args.Metric)
+	}
+	trends := timeSeriesTrends(points, metrics)
+
+	if strings.EqualFold(args.Format, "json") {
+		type jsonPoint struct {
+			SnapshotID   string             `json:"snapshot_id"`
+			SnapshotTime string             `json:"snapshot_time"`
+			Metrics      map[string]float64 `json:"metrics"`
+		}
+		output := struct {
+			Series  *TimeSeriesDefinition `json:"series"`
+			Metrics []string              `json:"metrics"`
+			Points  []jsonPoint           `json:"points"`
+			Trends  []TimeSeriesTrend     `json:"trends"`
+		}{Series: definition, Metrics: metrics, Trends: trends}
+		for _, point := range points {
+			values := make(map[string]float64, len(metrics))
+			for _, metric := range metrics {
+				values[metric] = point.Metrics[metric]
+			}
+			output.Points = append(output.Points, jsonPoint{point.SnapshotID, s.timeFormatter.Format(point.SnapshotTime), values})
+		}
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode time series: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("Time series %s on network %s: %d points from %s to %s\n\n", name, networkID, len(points),
+		s.timeFormatter.Format(points[0].SnapshotTime), s.timeFormatter.Format(points[len(points)-1].SnapshotTime)))
+	report.WriteString("| Snapshot time | Snapshot | " + strings.Join(metrics, " | ") + " |\n")
+	report.WriteString("|---|---|" + strings.Repeat("---|", len(metrics)) + "\n")
+	for _, point := range points {
+		values := make([]string, len(metrics))
+		for i, metric := range metrics {
+			values[i] = strconv.FormatFloat(point.Metrics[metric], 'f', -1, 64)
+		}
+		report.WriteString(fmt.Sprintf("| %s | %s | %s |\n", s.timeFormatter.Format(point.SnapshotTime), point.SnapshotID, strings.Join(values, " | ")))
+	}
+	report.WriteString("\nTrends:\n")
+	for _, trend := range trends {
+		report.WriteString(fmt.Sprintf("- %s: %g → %g (%+g; min %g, max %g)\n", trend.Metric, trend.First, trend.Last, trend.Change, trend.Min, trend.Max))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
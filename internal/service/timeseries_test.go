@@ -0,0 +1,142 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// snapshotQueryClient returns per-snapshot query rows and counts query runs
+type snapshotQueryClient struct {
+	*MockForwardClient
+	rows    map[string][]map[string]interface{}
+	queries int
+}
+
+func (c *snapshotQueryClient) RunNQEQueryByID(params *forward.NQEQueryParams) (*forward.NQERunResult, error) {
+	c.queries++
+	return &forward.NQERunResult{SnapshotID: params.SnapshotID, Items: c.rows[params.SnapshotID]}, nil
+}
+
+func newTestTimeSeriesStore(t *testing.T) *TimeSeriesStore {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewTimeSeriesStore(db, "test", logger.New())
+	if err != nil {
+		t.Fatalf("Failed to create time-series store: %v", err)
+	}
+	return store
+}
+
+func TestTimeSeriesMetrics(t *testing.T) {
+	rows := []map[string]interface{}{
+		{"vendor": "cisco", "routes": float64(10)},
+		{"vendor": "cisco", "routes": "5"},
+		{"vendor": "arista", "routes": nil},
+	}
+	metrics := timeSeriesMetrics(rows, []string{"routes"}, "vendor")
+	expected := map[string]float64{"row_count": 3, "sum(routes)": 15, "count(vendor=cisco)": 2, "count(vendor=arista)": 1}
+	if len(metrics) != len(expected) {
+		t.Errorf("Expected %d metrics, got %v", len(expected), metrics)
+	}
+	for metric, value := range expected {
+		if metrics[metric] != value {
+			t.Errorf("%s: expected %g, got %g", metric, value, metrics[metric])
+		}
+	}
+
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	if bound, _ := parseTimeSeriesBound("30d", now, false); !bound.Equal(now.AddDate(0, 0, -30)) {
+		t.Errorf("Expected 30d to be 30 days before now, got %v", bound)
+	}
+	if bound, _ := parseTimeSeriesBound("2026-03-01", now, true); bound.Day() != 1 || bound.Hour() != 23 {
+		t.Errorf("Expected an upper date bound to include the whole day, got %v", bound)
+	}
+	if _, err := parseTimeSeriesBound("last week", now, false); err == nil {
+		t.Error("Expected an error for an unparseable bound")
+	}
+}
+
+func TestCollectAndQueryTimeSeries(t *testing.T) {
+	service := createTestService()
+	service.timeSeries = newTestTimeSeriesStore(t)
+	day := int64(24 * time.Hour / time.Millisecond)
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	client := &snapshotQueryClient{MockForwardClient: service.forwardClient.(*MockForwardClient)}
+	client.snapshots = []forward.Snapshot{
+		{ID: "s3", State: "PROCESSED", CreationDateMillis: base + 2*day},
+		{ID: "s1", State: "PROCESSED", CreationDateMillis: base},
+		{ID: "s2", State: "PROCESSED", CreationDateMillis: base + day},
+		{ID: "draft", IsDraft: true, CreationDateMillis: base + 3*day},
+	}
+	client.rows = map[string][]map[string]interface{}{
+		"s1": {{"device": "a", "vendor": "cisco"}},
+		"s2": {{"device": "a", "vendor": "cisco"}, {"device": "b", "vendor": "arista"}},
+		"s3": {{"device": "a", "vendor": "cisco"}, {"device": "b", "vendor": "arista"}, {"device": "c", "vendor": "cisco"}},
+	}
+	service.forwardClient = client
+
+	args := CollectTimeSeriesArgs{SeriesName: "devices", QueryID: "FQ_devices", GroupBy: "vendor", To: "2026-01-02"}
+	response, err := service.collectTimeSeries(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "collected 2 snapshots, 0 already stored") {
+		t.Errorf("Expected two snapshots in range, got: %s", text)
+	}
+
+	// Extending the series only runs the new snapshot
+	args.To = ""
+	if _, err := service.collectTimeSeries(args); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if client.queries != 3 {
+		t.Errorf("Expected 3 query runs, got %d", client.queries)
+	}
+
+	// A different query under the same name is rejected
+	if _, err := service.collectTimeSeries(CollectTimeSeriesArgs{SeriesName: "devices", QueryID: "FQ_other"}); err == nil {
+		t.Error("Expected an error for a conflicting series definition")
+	}
+
+	response, err = service.queryTimeSeries(QueryTimeSeriesArgs{SeriesName: "devices", Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var output struct {
+		Points []struct {
+			SnapshotID string             `json:"snapshot_id"`
+			Metrics    map[string]float64 `json:"metrics"`
+		} `json:"points"`
+		Trends []TimeSeriesTrend `json:"trends"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &output); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+	if len(output.Points) != 3 || output.Points[0].SnapshotID != "s1" || output.Points[2].Metrics["count(vendor=cisco)"] != 2 {
+		t.Errorf("Unexpected points: %+v", output.Points)
+	}
+	if len(output.Trends) == 0 || output.Trends[0].Metric != "row_count" || output.Trends[0].Change != 2 || output.Trends[0].Max != 3 {
+		t.Errorf("Unexpected trends: %+v", output.Trends)
+	}
+
+	response, err = service.queryTimeSeries(QueryTimeSeriesArgs{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "| devices | FQ_devices |") || !strings.Contains(text, "| 3 |") {
+		t.Errorf("Expected the series listing, got: %s", text)
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"reflect"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+var (
+	toolResponseType   = reflect.TypeOf(&mcp.ToolResponse{})
+	promptResponseType = reflect.TypeOf(&mcp.PromptResponse{})
+	contextType        = reflect.TypeOf((*context.Context)(nil)).Elem()
+)
+
+// toolServer registers tools and prompts on the MCP server, passing every response through
+// the service's output filters before it reaches the client, and enforcing the network access
+// policy and API key scopes. Other registrations are forwarded to the embedded server unchanged.
+type toolServer struct {
+	*mcp.Server
+	service *ForwardMCPService
+}
+
+// RegisterTool registers a tool whose handler output is filtered, whose calls are checked
+// against the network access policy and API key scopes, whose identical concurrent calls
+// share one execution, whose stale or superseded snapshot is flagged at the top of the
+// response, whose snapshot is pinned during an analysis session, whose likely
+// follow-up data is prefetched, whose calls are added to the session transcript while one
+// is recorded, whose stored artifacts are linked to the caller's workspace and whose
+// response is trimmed to the requested verbosity. Tools turned off by feature flags are
+// skipped.
+func (t *toolServer) RegisterTool(name, description string, handler interface{}) error {
+	enabled := t.service.features.ToolEnabled(name)
+	t.service.features.record(name, enabled)
+	if !enabled {
+		t.service.logger.Debug("Skipping tool %s: disabled by feature flag %s", name, flagName(name))
+		return nil
+	}
+	if experimentalTools[name] {
+		description = "[Experimental] " + description
+	}
+	handler = t.service.coalesceToolHandler(name, t.service.wrapToolHandler(handler))
+	handler = t.service.recordToolHandler(name, t.service.prefetchToolHandler(name, t.service.freshnessToolHandler(name, handler)))
+	// Pinning needs the caller, so it takes the context the authorization layer passes on
+	handler = t.service.workspaceToolHandler(t.service.pinSnapshotHandler(name, handler))
+	return t.Server.RegisterTool(name, description, t.service.verbosityToolHandler(t.service.authorizeToolHandler(name, handler)))
+}
+
+// RegisterPrompt registers a prompt whose output is filtered like a tool's and whose calls
+// are checked against the network access policy and API key scopes
+func (t *toolServer) RegisterPrompt(name, description string, handler interface{}) error {
+	return t.Server.RegisterPrompt(name, description, t.service.authorizePromptHandler(name, t.service.wrapPromptHandler(handler)))
+}
+
+// wrapToolHandler returns a handler with the same signature whose *mcp.ToolResponse result
+// is filtered and whose recognized errors carry their catalog code and hint. The signature
+// is preserved so the input schema is derived as before.
+func (s *ForwardMCPService) wrapToolHandler(handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if handlerType.Kind() != reflect.Func || handlerType.NumOut() == 0 || handlerType.Out(0) != toolResponseType {
+		return handler
+	}
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		results := value.Call(args)
+		if response, ok := results[0].Interface().(*mcp.ToolResponse); ok && response != nil {
+			results[0] = reflect.ValueOf(s.filterToolResponse(response))
+		}
+		if len(results) == 2 {
+			if err, ok := results[1].Interface().(error); ok && err != nil {
+				err = describeToolError(err)
+				results[1] = reflect.ValueOf(&err).Elem()
+			}
+		}
+		return results
+	}).Interface()
+}
+
+// filterToolResponse applies output filters to a tool response
+func (s *ForwardMCPService) filterToolResponse(response *mcp.ToolResponse) *mcp.ToolResponse {
+	return s.redactToolResponse(response)
+}
+
+// wrapPromptHandler returns a handler with the same signature whose *mcp.PromptResponse
+// messages are filtered like tool output
+func (s *ForwardMCPService) wrapPromptHandler(handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if handlerType.Kind() != reflect.Func || handlerType.NumOut() == 0 || handlerType.Out(0) != promptResponseType {
+		return handler
+	}
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		results := value.Call(args)
+		if response, ok := results[0].Interface().(*mcp.PromptResponse); ok && response != nil {
+			for _, message := range response.Messages {
+				if message != nil && message.Content != nil && message.Content.TextContent != nil && s.redactor != nil {
+					message.Content.TextContent.Text = s.redactor.Redact(message.Content.TextContent.Text)
+				}
+			}
+		}
+		if len(results) == 2 {
+			if err, ok := results[1].Interface().(error); ok && err != nil {
+				err = describeToolError(err)
+				results[1] = reflect.ValueOf(&err).Elem()
+			}
+		}
+		return results
+	}).Interface()
+}
@@ -37,6 +37,18 @@ type RunNQEQueryByIDArgs struct {
 	Parameters map[string]interface{} `json:"parameters,omitempty" jsonschema:"description=Optional parameters for the query"`
 	Options    *NQEQueryOptions       `json:"options,omitempty" jsonschema:"description=Optional query options for sorting and filtering"`
 	AllResults bool                   `json:"all_results,omitempty" jsonschema:"description=If true, fetch all results using pagination (limit/offset) and aggregate them into a single response"`
+	// Batch fetching in all_results mode
+	Concurrency int    `json:"concurrency,omitempty" jsonschema:"description=Batches fetched in parallel with all_results (default: 4, max: 16; 1 fetches one batch at a time)"`
+	ResumeToken string `json:"resume_token,omitempty" jsonschema:"description=Token from an interrupted all_results fetch; continues from its last completed batch (re-running the same call resumes too)"`
+	// Sampling instead of a full run
+	Sample       bool   `json:"sample,omitempty" jsonschema:"description=If true, return a small sample with an estimated total row count instead of the full result, to inspect the data shape cheaply"`
+	SampleSize   int    `json:"sample_size,omitempty" jsonschema:"description=Rows in the sample (default: 20, max: 500); setting it implies sample"`
+	SampleMethod string `json:"sample_method,omitempty" jsonschema:"description=How rows are sampled: first (default, the first N rows) or random (windows at random offsets)"`
+	// Rollup of repeated rows at render time
+	GroupBy       []string `json:"group_by,omitempty" jsonschema:"description=Render rows grouped by these columns with a count and a few examples per group instead of every row; the stored result keeps every row"`
+	GroupExamples int      `json:"group_examples,omitempty" jsonschema:"description=Example rows shown per group with group_by (default: 2, max: 20; -1 shows counts only)"`
+	// Cache tuning
+	SimilarityThreshold float64 `json:"similarity_threshold,omitempty" jsonschema:"description=Optional semantic cache similarity threshold for this call (0-1). Overrides the per-category and global thresholds"`
 }
 
 type NQEQueryOptions struct {
@@ -58,7 +70,9 @@ type NQEColumnFilter struct {
 }
 
 type ListNQEQueriesArgs struct {
-	Directory string `json:"directory,omitempty" jsonschema:"description=Filter queries by directory (e.g. '/L3/Advanced/')"`
+	Directory       string `json:"directory,omitempty" jsonschema:"description=Filter queries by directory (e.g. '/L3/Advanced/')"`
+	Category        string `json:"category,omitempty" jsonschema:"description=Filter by category, either a custom taxonomy category or the path-derived one (e.g. 'cloud' or 'L3')"`
+	GroupByCategory bool   `json:"group_by_category,omitempty" jsonschema:"description=Group the listed queries by category, applying the custom taxonomy (default: false)"`
 }
 
 // Device Management Tool Arguments
@@ -67,6 +81,7 @@ type ListDevicesArgs struct {
 	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
 	Limit      int    `json:"limit,omitempty" jsonschema:"description=Maximum number of devices to return"`
 	Offset     int    `json:"offset,omitempty" jsonschema:"description=Number of devices to skip"`
+	Tag        string `json:"tag,omitempty" jsonschema:"description=Only list devices with this tag (see apply_device_tags)"`
 }
 
 type GetDeviceLocationsArgs struct {
@@ -89,7 +104,8 @@ type GetLatestSnapshotArgs struct {
 }
 
 type DeleteSnapshotArgs struct {
-	SnapshotID string `json:"snapshot_id" jsonschema:"required,description=ID of the snapshot to delete"`
+	SnapshotID        string `json:"snapshot_id" jsonschema:"required,description=ID of the snapshot to delete"`
+	ConfirmationToken string `json:"confirmation_token,omitempty" jsonschema:"description=Token returned by the first call; resend the same arguments with it to carry out the deletion"`
 }
 
 // Location Management Tool Arguments
@@ -123,8 +139,9 @@ type UpdateLocationArgs struct {
 }
 
 type DeleteLocationArgs struct {
-	NetworkID  string `json:"network_id" jsonschema:"required,description=ID of the network"`
-	LocationID string `json:"location_id" jsonschema:"required,description=ID of the location to delete"`
+	NetworkID         string `json:"network_id" jsonschema:"required,description=ID of the network"`
+	LocationID        string `json:"location_id" jsonschema:"required,description=ID of the location to delete"`
+	ConfirmationToken string `json:"confirmation_token,omitempty" jsonschema:"description=Token returned by the first call; resend the same arguments with it to carry out the deletion"`
 }
 
 type UpdateDeviceLocationsArgs struct {
@@ -173,6 +190,128 @@ type GetOSSupportArgs struct {
 	Options    *NQEQueryOptions `json:"options,omitempty" jsonschema:"description=Query options like limit, offset, sorting, etc."`
 }
 
+// ForecastEOLExposureArgs represents arguments for the EOL budget forecast
+type ForecastEOLExposureArgs struct {
+	NetworkID  string `json:"network_id" jsonschema:"required,description=ID of the network"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	Scope      string `json:"scope,omitempty" jsonschema:"description=Support data to include: all (default), hardware or os"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default), json or csv"`
+}
+
+// ReconcileInventoryArgs represents arguments for reconciling an asset list against live hardware
+type ReconcileInventoryArgs struct {
+	NetworkID  string `json:"network_id" jsonschema:"required,description=ID of the network"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	AssetsCSV  string `json:"assets_csv" jsonschema:"required,description=CSV of expected assets with a header row including serial and/or hostname columns and optionally model"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// GetVLANInventoryArgs represents arguments for listing VLANs per device and trunk
+type GetVLANInventoryArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	Location   string `json:"location,omitempty" jsonschema:"description=Only include devices at this site (location name)"`
+	Device     string `json:"device,omitempty" jsonschema:"description=Only include this device"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// CheckVLANConsistencyArgs represents arguments for checking VLANs across trunk links
+type CheckVLANConsistencyArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	Location   string `json:"location,omitempty" jsonschema:"description=Only report issues at this site (location name)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// CheckFHRPArgs represents arguments for checking HSRP and VRRP groups
+type CheckFHRPArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	Location   string `json:"location,omitempty" jsonschema:"description=Only report groups at this site (location name)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// RunSnapshotPipelineArgs represents arguments for running the snapshot pipeline playbook
+type RunSnapshotPipelineArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to validate (latest processed snapshot if omitted)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// GetPipelineRunsArgs represents arguments for listing snapshot pipeline runs
+type GetPipelineRunsArgs struct {
+	NetworkID string `json:"network_id,omitempty" jsonschema:"description=Only list runs of this network (all networks if omitted)"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"description=Maximum runs to list (newest first)"`
+	Format    string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// ListPlatformChecksArgs represents arguments for listing the verification checks configured in Forward
+type ListPlatformChecksArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot whose check results to list (latest processed if omitted)"`
+	Type       string `json:"type,omitempty" jsonschema:"description=Only list checks of this type e.g. Isolation or Reachability or NQE"`
+	Priority   string `json:"priority,omitempty" jsonschema:"description=Only list checks of this priority: HIGH or MEDIUM or LOW or NOT_SET"`
+	Status     string `json:"status,omitempty" jsonschema:"description=Only list checks with this status: PASS or FAIL or ERROR or TIMEOUT"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// GetCheckResultsArgs represents arguments for reading verification check results per snapshot
+type GetCheckResultsArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Newest snapshot to read (latest processed if omitted)"`
+	CheckID    string `json:"check_id,omitempty" jsonschema:"description=Check to show with its diagnosis and history (all checks if omitted)"`
+	Snapshots  int    `json:"snapshots,omitempty" jsonschema:"description=Snapshots to read going back in time (default: 5 for one check and 1 for all checks; max: 20)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// SavePresetArgs represents arguments for saving a named bundle of tool arguments
+type SavePresetArgs struct {
+	Name        string                 `json:"name" jsonschema:"required,description=Preset name e.g. prod-quick (letters digits '.' '_' and '-')"`
+	Arguments   map[string]interface{} `json:"arguments" jsonschema:"required,description=Tool arguments the preset supplies as a JSON object e.g. {\"network_id\": \"162112\"}"`
+	Scope       string                 `json:"scope,omitempty" jsonschema:"description=session (default; kept until the server restarts) or persistent (stored in the memory system)"`
+	Description string                 `json:"description,omitempty" jsonschema:"description=What the preset is for"`
+	PresetOwner string                 `json:"preset_owner,omitempty" jsonschema:"-"`
+}
+
+// ListPresetsArgs represents arguments for listing argument presets
+type ListPresetsArgs struct {
+	PresetOwner string `json:"preset_owner,omitempty" jsonschema:"-"`
+}
+
+// DeletePresetArgs represents arguments for deleting an argument preset
+type DeletePresetArgs struct {
+	Name        string `json:"name" jsonschema:"required,description=Preset to delete"`
+	Scope       string `json:"scope,omitempty" jsonschema:"description=Only delete from session or persistent (both if omitted)"`
+	PresetOwner string `json:"preset_owner,omitempty" jsonschema:"-"`
+}
+
+// GenerateNetworkDocsArgs represents arguments for generating a network architecture document
+type GenerateNetworkDocsArgs struct {
+	NetworkID    string   `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID   string   `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	KeyPaths     string   `json:"key_paths,omitempty" jsonschema:"description=Key paths to document as src>dst[:port][=allow|deny] separated by semicolons"`
+	Sections     []string `json:"sections,omitempty" jsonschema:"description=Sections to include: sites prefixes paths eol and diagram (default all)"`
+	ReportFormat string   `json:"report_format,omitempty" jsonschema:"description=Document format: markdown (default) html or pdf (served as a forward://reports/ resource)"`
+}
+
+// InterfaceConventionArgs represents one interface description template
+type InterfaceConventionArgs struct {
+	Site    string `json:"site,omitempty" jsonschema:"description=Site (location name) the template applies to; * or omitted for every site"`
+	Role    string `json:"role" jsonschema:"required,description=Interface role: uplink; trunk; access; loopback; management; svi; port_channel or routed"`
+	Pattern string `json:"pattern" jsonschema:"required,description=Regular expression descriptions must match; {device} {site} {interface} {peer_device} {peer_interface} and {vlan} expand to the interface's values"`
+}
+
+// CheckInterfaceHygieneArgs represents arguments for checking interface descriptions
+type CheckInterfaceHygieneArgs struct {
+	NetworkID   string                    `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID  string                    `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	Location    string                    `json:"location,omitempty" jsonschema:"description=Only check devices at this site (location name)"`
+	Device      string                    `json:"device,omitempty" jsonschema:"description=Only check this device"`
+	Conventions []InterfaceConventionArgs `json:"conventions,omitempty" jsonschema:"description=Templates for this call; they override the configured interfaceConventions for the same site and role"`
+	IncludeDown bool                      `json:"include_down,omitempty" jsonschema:"description=Also check administratively down interfaces (skipped by default)"`
+	Format      string                    `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
 // SearchConfigsArgs represents arguments for configuration search
 type SearchConfigsArgs struct {
 	NetworkID    string                 `json:"network_id" jsonschema:"description=Network ID (use list_networks to find, or set default with set_default_network)"`
@@ -184,15 +323,28 @@ type SearchConfigsArgs struct {
 	AllResults   bool                   `json:"all_results,omitempty" jsonschema:"description=If true, fetch all config matches using pagination and store in memory system"`
 }
 
+// GetConfigSectionArgs represents arguments for extracting a single configuration section
+type GetConfigSectionArgs struct {
+	NetworkID  string `json:"network_id" jsonschema:"description=Network ID (use list_networks to find, or set default with set_default_network)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID (optional, uses latest if not specified)"`
+	Device     string `json:"device" jsonschema:"required,description=Device name whose configuration to extract from"`
+	Section    string `json:"section" jsonschema:"required,description=Section to return: interfaces, bgp, ospf, acl, static_routes or vlans"`
+	Name       string `json:"name,omitempty" jsonschema:"description=Optional section instance to return, such as an interface, ACL or BGP group name"`
+	Vendor     string `json:"vendor,omitempty" jsonschema:"description=Configuration grammar hint such as cisco, arista or juniper (auto-detected if not specified)"`
+	ConfigText string `json:"config_text,omitempty" jsonschema:"description=Raw configuration to parse instead of fetching it from Forward"`
+}
+
 // GetConfigDiffArgs represents arguments for configuration comparison
 type GetConfigDiffArgs struct {
 	NetworkID      string                 `json:"network_id" jsonschema:"description=Network ID (use list_networks to find, or set default with set_default_network)"`
 	BeforeSnapshot string                 `json:"before_snapshot" jsonschema:"required,description=Earlier snapshot ID for comparison"`
 	AfterSnapshot  string                 `json:"after_snapshot" jsonschema:"required,description=Later snapshot ID for comparison"`
 	DeviceFilter   string                 `json:"device_filter,omitempty" jsonschema:"description=Optional device name pattern to filter results"`
+	Device         string                 `json:"device,omitempty" jsonschema:"description=Device name to drill into. Returns that device's diff lines instead of the per-device summary"`
 	Parameters     map[string]interface{} `json:"parameters,omitempty" jsonschema:"description=Additional query parameters"`
-	Options        *NQEQueryOptions       `json:"options,omitempty" jsonschema:"description=Query options (limit, offset, etc.)"`
-	AllResults     bool                   `json:"all_results,omitempty" jsonschema:"description=If true, fetch all config diff results using pagination and store in memory system"`
+	Options        *NQEQueryOptions       `json:"options,omitempty" jsonschema:"description=Paging options: limit/offset apply to devices in the summary and to diff lines for a device"`
+	AllResults     bool                   `json:"all_results,omitempty" jsonschema:"description=Deprecated: diffs are always fetched in full and summarized per device"`
+	ReportFormat   string                 `json:"report_format,omitempty" jsonschema:"description=Also save a rendered change report of the summary: markdown, html or pdf (served as a forward://reports/ resource)"`
 }
 
 type GetDeviceUtilitiesArgs struct {
@@ -204,22 +356,28 @@ type GetDeviceUtilitiesArgs struct {
 // Prompt Workflow Arguments
 type NQEDiscoveryArgs struct {
 	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
 }
 
 type NetworkDiscoveryArgs struct {
 	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
 }
 
+// noArguments is embedded by the arguments of tools that take no parameters, since the MCP
+// framework needs at least one property to build a tool's input schema
+type noArguments struct {
+	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+}
+
 // Resource Arguments
 type NetworkContextArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 // Default Settings Management argument structures
 type GetDefaultSettingsArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 type SetDefaultNetworkArgs struct {
@@ -228,8 +386,7 @@ type SetDefaultNetworkArgs struct {
 
 // Semantic Cache and AI Enhancement Args
 type GetCacheStatsArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 type SuggestSimilarQueriesArgs struct {
@@ -241,15 +398,301 @@ type ClearCacheArgs struct {
 	ClearAll bool `json:"clear_all,omitempty" jsonschema:"description=Clear all cache entries instead of just expired ones"`
 }
 
+type ListCacheEntriesArgs struct {
+	NetworkID      string `json:"network_id,omitempty" jsonschema:"description=Only list entries cached for this network"`
+	MinAgeMinutes  int    `json:"min_age_minutes,omitempty" jsonschema:"description=Only list entries at least this many minutes old"`
+	MaxAgeMinutes  int    `json:"max_age_minutes,omitempty" jsonschema:"description=Only list entries at most this many minutes old"`
+	MinAccessCount int64  `json:"min_access_count,omitempty" jsonschema:"description=Only list entries accessed at least this many times"`
+	Limit          int    `json:"limit,omitempty" jsonschema:"description=Maximum number of entries to return (default: 25)"`
+}
+
+type InspectCacheEntryArgs struct {
+	Hash      string `json:"hash" jsonschema:"required,description=Cache entry hash or a unique prefix (from list_cache_entries)"`
+	Neighbors int    `json:"neighbors,omitempty" jsonschema:"description=Number of similar cached queries to show (default: 5)"`
+}
+
+type EvictCacheEntryArgs struct {
+	Hash string `json:"hash" jsonschema:"required,description=Cache entry hash or a unique prefix (from list_cache_entries)"`
+}
+
 // AI-Powered Query Discovery Tools
 
 // SearchNQEQueriesArgs represents arguments for intelligent query search
 type SearchNQEQueriesArgs struct {
-	Query       string `json:"query" jsonschema:"required,description=Natural language description of what you want to analyze. Be specific and descriptive. Good examples: 'show me AWS security vulnerabilities', 'find BGP routing issues', 'check interface utilization', 'devices with high CPU usage'. Avoid vague terms like 'network' or 'config'."`
-	Limit       int    `json:"limit" jsonschema:"description=Maximum number of query suggestions to return (default: 10, max: 50)"`
-	Category    string `json:"category" jsonschema:"description=Filter by category to narrow results (e.g., 'Cloud', 'L3', 'Security', 'Device')."`
-	Subcategory string `json:"subcategory" jsonschema:"description=Filter by subcategory (e.g., 'AWS', 'BGP', 'ACL', 'OSPF')."`
-	IncludeCode bool   `json:"include_code" jsonschema:"description=Include NQE source code in results for advanced users (default: false). Warning: makes response much longer."`
+	Query             string `json:"query" jsonschema:"required,description=Natural language description of what you want to analyze. Be specific and descriptive. Good examples: 'show me AWS security vulnerabilities', 'find BGP routing issues', 'check interface utilization', 'devices with high CPU usage'. Avoid vague terms like 'network' or 'config'."`
+	Limit             int    `json:"limit" jsonschema:"description=Maximum number of query suggestions to return (default: 10, max: 50)"`
+	Category          string `json:"category" jsonschema:"description=Filter by category to narrow results (e.g., 'Cloud', 'L3', 'Security', 'Device')."`
+	Subcategory       string `json:"subcategory" jsonschema:"description=Filter by subcategory (e.g., 'AWS', 'BGP', 'ACL', 'OSPF')."`
+	IncludeCode       bool   `json:"include_code" jsonschema:"description=Include NQE source code in results for advanced users (default: false). Warning: makes response much longer."`
+	IncludeDeprecated bool   `json:"include_deprecated,omitempty" jsonschema:"description=Also list deprecated queries (removed or renamed in the Forward library) whose path, intent or description contains every search word (default: false)"`
+}
+
+// RefreshDeviceCacheArgs represents the arguments for refreshing the device inventory cache
+type RefreshDeviceCacheArgs struct {
+	NetworkID   string `json:"network_id,omitempty" jsonschema:"description=Network whose device inventory should be reloaded (uses default network if omitted)"`
+	SnapshotID  string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to reload (latest if omitted)"`
+	AllNetworks bool   `json:"all_networks,omitempty" jsonschema:"description=Clear cached inventories for every network without reloading"`
+}
+
+// ListDeviceAliasesArgs represents the arguments for listing device renames
+type ListDeviceAliasesArgs struct {
+	NetworkID string `json:"network_id,omitempty" jsonschema:"description=Network to list aliases for (uses default network if omitted)"`
+	Device    string `json:"device,omitempty" jsonschema:"description=Only list aliases involving this former or current device name"`
+}
+
+// AddDeviceAliasArgs represents the arguments for recording a device rename by hand
+type AddDeviceAliasArgs struct {
+	NetworkID   string `json:"network_id,omitempty" jsonschema:"description=Network the device belongs to (uses default network if omitted)"`
+	OldName     string `json:"old_name" jsonschema:"required,description=Former device name"`
+	CurrentName string `json:"current_name,omitempty" jsonschema:"description=Current device name (leave empty to remove the alias for old_name)"`
+}
+
+// DetectDeviceRenamesArgs represents the arguments for detecting renames between snapshots
+type DetectDeviceRenamesArgs struct {
+	NetworkID      string `json:"network_id,omitempty" jsonschema:"description=Network to compare (uses default network if omitted)"`
+	FromSnapshotID string `json:"from_snapshot_id,omitempty" jsonschema:"description=Older snapshot (defaults to the snapshot before to_snapshot_id)"`
+	ToSnapshotID   string `json:"to_snapshot_id,omitempty" jsonschema:"description=Newer snapshot (defaults to the latest processed snapshot)"`
+	DryRun         bool   `json:"dry_run,omitempty" jsonschema:"description=Report renames without recording aliases"`
+}
+
+// SuggestSitePairsArgs represents the arguments for geo-distance and hop-count site pairing
+type SuggestSitePairsArgs struct {
+	NetworkID       string   `json:"network_id,omitempty" jsonschema:"description=Network to plan (uses default network if omitted)"`
+	SnapshotID      string   `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot for path searches (latest if omitted)"`
+	Locations       []string `json:"locations,omitempty" jsonschema:"description=Only consider these location IDs or names (all locations with coordinates if omitted)"`
+	MaxPairs        int      `json:"max_pairs,omitempty" jsonschema:"description=Maximum number of site pairs to suggest and measure (default: 10, max: 50)"`
+	MinSeparationKm float64  `json:"min_separation_km,omitempty" jsonschema:"description=Minimum distance in km between redundancy partners so they do not share a metro area (default: 50)"`
+	SkipPathSearch  bool     `json:"skip_path_search,omitempty" jsonschema:"description=Only compute distances without measuring path hop counts"`
+}
+
+// SetDeviceTagRuleArgs represents the arguments for defining the rule of a device tag
+type SetDeviceTagRuleArgs struct {
+	Tag       string `json:"tag" jsonschema:"required,description=Tag to assign, e.g. datacenter-core"`
+	Condition string `json:"condition" jsonschema:"required,description=Rule over inventory attributes, e.g. platform contains 'nxos' AND name matches 'core'. Fields: name, hostname, platform, vendor, model, type, os_version, location, management_ip. Operators: contains, equals, starts_with, matches (regex), in (CIDR). Combine with AND, OR and NOT"`
+}
+
+// RemoveDeviceTagRuleArgs represents the arguments for removing a device tag
+type RemoveDeviceTagRuleArgs struct {
+	Tag string `json:"tag" jsonschema:"required,description=Tag to remove with its rule and device links"`
+}
+
+// ApplyDeviceTagsArgs represents the arguments for tagging a network's devices
+type ApplyDeviceTagsArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network whose devices to tag (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot whose inventory to use (latest if omitted)"`
+	Tag        string `json:"tag,omitempty" jsonschema:"description=Only apply the rule of this tag (all rules if omitted)"`
+	DryRun     bool   `json:"dry_run,omitempty" jsonschema:"description=Report which devices match without storing tags"`
+}
+
+// ListDeviceTagsArgs represents the arguments for listing device tags
+type ListDeviceTagsArgs struct {
+	NetworkID string `json:"network_id,omitempty" jsonschema:"description=Also list the tagged devices of this network (default network if omitted)"`
+}
+
+// AnnotatePrefixArgs represents the arguments for documenting the ownership of a prefix
+type AnnotatePrefixArgs struct {
+	NetworkID   string `json:"network_id,omitempty" jsonschema:"description=Network the prefix belongs to (uses default network if omitted)"`
+	Prefix      string `json:"prefix" jsonschema:"required,description=Prefix to document, e.g. 10.20.0.0/16; more specific prefixes and addresses inherit the annotation"`
+	Team        string `json:"team,omitempty" jsonschema:"description=Owning team"`
+	Purpose     string `json:"purpose,omitempty" jsonschema:"description=What the prefix is used for, e.g. payments database tier"`
+	Environment string `json:"environment,omitempty" jsonschema:"description=Environment, e.g. production or staging"`
+	Notes       string `json:"notes,omitempty" jsonschema:"description=Free-form notes"`
+}
+
+// ImportPrefixAnnotationsArgs represents the arguments for importing prefix ownership from CSV
+type ImportPrefixAnnotationsArgs struct {
+	NetworkID string `json:"network_id,omitempty" jsonschema:"description=Network the prefixes belong to (uses default network if omitted)"`
+	CSV       string `json:"csv" jsonschema:"required,description=CSV with a header row: prefix and any of team, purpose, environment and notes"`
+	DryRun    bool   `json:"dry_run,omitempty" jsonschema:"description=Validate the rows without storing them"`
+}
+
+// PrefixDocumentationCoverageArgs represents the arguments for reporting undocumented prefixes
+type PrefixDocumentationCoverageArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network whose interface subnets are checked (default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=markdown (default) or csv for an import_prefix_annotations template of the undocumented prefixes"`
+	MaxShown   int    `json:"max_shown,omitempty" jsonschema:"description=Maximum undocumented prefixes listed (default: 50)"`
+}
+
+// GetSupportMatrixArgs represents the arguments for reporting tool support per platform
+type GetSupportMatrixArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network whose platforms to report (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot whose inventory to use (latest if omitted)"`
+	Tool       string `json:"tool,omitempty" jsonschema:"description=Only report the analysis behind this tool (e.g. get_config_section)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// ClassifyDevicesArgs represents the arguments for classifying devices as physical, cloud or virtual
+type ClassifyDevicesArgs struct {
+	NetworkID  string   `json:"network_id,omitempty" jsonschema:"description=Network to classify (uses default network if omitted)"`
+	SnapshotID string   `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot whose inventory to use (latest if omitted)"`
+	Devices    []string `json:"devices,omitempty" jsonschema:"description=Only classify these device names (all devices if omitted)"`
+	Class      string   `json:"class,omitempty" jsonschema:"description=Only list devices of this class: physical, cloud, virtual or unknown"`
+}
+
+// WhichDevicesInPrefixArgs represents the arguments for looking up devices inside a prefix
+type WhichDevicesInPrefixArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network to search (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to search (latest if omitted)"`
+	Prefix     string `json:"prefix" jsonschema:"required,description=IPv4 or IPv6 prefix or address to look up (e.g. 10.20.0.0/16)"`
+	Location   string `json:"location,omitempty" jsonschema:"description=Only return devices in this location"`
+	Rebuild    bool   `json:"rebuild,omitempty" jsonschema:"description=Rebuild the prefix index from the device inventory before the lookup"`
+}
+
+// AnalyzeSummarizationArgs represents arguments for finding route summarization opportunities
+type AnalyzeSummarizationArgs struct {
+	NetworkID   string  `json:"network_id,omitempty" jsonschema:"description=Network to analyze (uses default network if omitted)"`
+	SnapshotID  string  `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to analyze (latest if omitted)"`
+	Location    string  `json:"location,omitempty" jsonschema:"description=Only propose summaries for this location"`
+	MinCoverage float64 `json:"min_coverage,omitempty" jsonschema:"description=Share of a summary's addresses that must be in use at the site (default 1: exact summaries only; 0.5 allows half unused)"`
+	Format      string  `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// ClientDiagnosticsArgs represents the arguments for inspecting the Forward API client
+type ClientDiagnosticsArgs struct {
+	ProbeRequests int `json:"probe_requests,omitempty" jsonschema:"description=Number of lightweight list-networks requests to issue to measure latency and connection reuse (default: 0, max: 10)"`
+}
+
+// GetLastAPIErrorsArgs represents the arguments for summarizing recent failed Forward API requests
+type GetLastAPIErrorsArgs struct {
+	Limit  int             `json:"limit,omitempty" jsonschema:"description=Most recent failures to list in detail (default: 10, max: 50)"`
+	Format string          `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+	Caller *APIKeyIdentity `json:"-"` // Calling API key; only failures of its networks are listed
+}
+
+// RunDiagnosticsArgs represents the arguments for the configuration self-test
+type RunDiagnosticsArgs struct {
+	ProbeEmbeddings bool `json:"probe_embeddings,omitempty" jsonschema:"description=Generate one embedding to check the OpenAI provider is reachable (default: false; uses one API call)"`
+}
+
+// GetStorageStatsArgs represents the arguments for reporting disk usage per storage component
+type GetStorageStatsArgs struct {
+	Format string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// CleanupStorageArgs represents the arguments for reclaiming disk space from a storage component
+type CleanupStorageArgs struct {
+	Component     string `json:"component" jsonschema:"required,description=Component to clean up: memory_db or nqe_db (VACUUM), analysis or bloom_indexes (deleted and rebuilt on demand), reports (saved reports), backups (backup_state archives), exports (share_result files)"`
+	OlderThanDays int    `json:"older_than_days,omitempty" jsonschema:"description=Only delete analysis databases, bloom indexes, reports, backups or shared results not modified for this many days (default: all)"`
+	DryRun        bool   `json:"dry_run,omitempty" jsonschema:"description=Report what would be removed without deleting anything"`
+}
+
+// BackupStateArgs represents the arguments for archiving server state
+type BackupStateArgs struct {
+	Components []string `json:"components,omitempty" jsonschema:"description=Components to include: memory_db, nqe_db, bloom_indexes, embeddings, reports (default: all)"`
+	OutputDir  string   `json:"output_dir,omitempty" jsonschema:"description=Subdirectory of <data dir>/backups for the archive (default: the backup directory itself)"`
+}
+
+// RestoreStateArgs represents the arguments for restoring server state from an archive
+type RestoreStateArgs struct {
+	Archive    string   `json:"archive" jsonschema:"required,description=File name of a backup_state archive in <data dir>/backups, or its path there"`
+	Components []string `json:"components,omitempty" jsonschema:"description=Components to restore: memory_db, nqe_db, bloom_indexes, embeddings, reports (default: all in the archive)"`
+	Confirm    bool     `json:"confirm" jsonschema:"required,description=Must be true; restoring replaces the current state of the selected components"`
+}
+
+// ContinueResponseArgs represents the arguments for fetching the next part of a streamed response
+type ContinueResponseArgs struct {
+	Token string `json:"token" jsonschema:"required,description=Continuation token returned by the previous response"`
+}
+
+// GetNQEQuerySourceArgs represents the arguments for viewing a library query's source
+type GetNQEQuerySourceArgs struct {
+	QueryID string `json:"query_id" jsonschema:"required,description=Query ID from the NQE Library (e.g. FQ_ac651cb2901b067fe7dbfb511613ab44776d8029)"`
+}
+
+// SuggestParameterValuesArgs represents the arguments for proposing query parameter values
+type SuggestParameterValuesArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network whose inventory supplies the values (uses the default network when omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to take values from (latest when omitted)"`
+	QueryID    string `json:"query_id,omitempty" jsonschema:"description=Parameterized NQE query to suggest values for; its declared parameters determine the kinds of values"`
+	Parameter  string `json:"parameter,omitempty" jsonschema:"description=Parameter name e.g. deviceName; limits a query to one parameter or without query_id infers the kind from the name"`
+	Kind       string `json:"kind,omitempty" jsonschema:"description=Kind of value (device interface vlan prefix or ip) when it cannot be inferred from the parameter"`
+	Prefix     string `json:"prefix,omitempty" jsonschema:"description=Text typed so far; values starting with it are suggested (case-insensitive) e.g. core- or 10.1."`
+	Device     string `json:"device,omitempty" jsonschema:"description=Only suggest interfaces VLANs prefixes or IPs of this device"`
+	Limit      int    `json:"limit,omitempty" jsonschema:"description=Maximum values per parameter (default: 20; max: 100)"`
+}
+
+// CheckQueryCompatibilityArgs represents the arguments for checking a query against a network
+type CheckQueryCompatibilityArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID to check against (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Specific snapshot ID (optional)"`
+	QueryID    string `json:"query_id,omitempty" jsonschema:"description=Library query ID to check. Its source is loaded from the query database"`
+	Query      string `json:"query,omitempty" jsonschema:"description=NQE source to check instead of a library query"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// DiffStoredResultsArgs represents the arguments for diffing two stored NQE results
+type DiffStoredResultsArgs struct {
+	EntityA      string   `json:"entity_a" jsonschema:"required,description=Entity ID or name of the earlier stored NQE result (e.g. FQ_xxx-162112-snapshotA)"`
+	EntityB      string   `json:"entity_b" jsonschema:"required,description=Entity ID or name of the later stored NQE result"`
+	KeyColumns   []string `json:"key_columns" jsonschema:"required,description=Columns that identify a row across both results (e.g. [\"device\", \"interface\"])"`
+	Limit        int      `json:"limit,omitempty" jsonschema:"description=Rows to show per added/removed/changed section (default: 20). All rows are stored in the diff entity"`
+	ReportFormat string   `json:"report_format,omitempty" jsonschema:"description=Also save a rendered change report with all rows: markdown, html or pdf (served as a forward://reports/ resource)"`
+}
+
+// ListSchemaChangesArgs represents the arguments for listing schema drift of NQE queries
+type ListSchemaChangesArgs struct {
+	QueryID   string `json:"query_id,omitempty" jsonschema:"description=Only list changes of this query ID"`
+	SinceDays int    `json:"since_days,omitempty" jsonschema:"description=Only list changes detected in the last N days (default: all)"`
+	Limit     int    `json:"limit,omitempty" jsonschema:"description=Maximum changes to list (default: 50)"`
+	Format    string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// ExportToDuckDBArgs represents the arguments for exporting stored results to a DuckDB file
+type ExportToDuckDBArgs struct {
+	Entities     []string `json:"entities" jsonschema:"required,description=Entity IDs or names of the stored NQE results to export (one table each)"`
+	Name         string   `json:"name,omitempty" jsonschema:"description=File name of the database in the exports directory (default: export-<timestamp>)"`
+	IndexColumns []string `json:"index_columns,omitempty" jsonschema:"description=Columns to index in every table that has them (default: device/name/hostname and ID columns)"`
+	Overwrite    bool     `json:"overwrite,omitempty" jsonschema:"description=Replace an existing database with the same name"`
+}
+
+// DetectResultAnomaliesArgs represents the arguments for checking a stored result against its baseline
+type DetectResultAnomaliesArgs struct {
+	EntityID        string  `json:"entity_id,omitempty" jsonschema:"description=Stored NQE result entity to check. Defaults to the newest result of query_id on the network"`
+	QueryID         string  `json:"query_id,omitempty" jsonschema:"description=Query ID whose newest stored result should be checked"`
+	NetworkID       string  `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	BaselineSize    int     `json:"baseline_size,omitempty" jsonschema:"description=Number of earlier results used as the baseline (default: 5)"`
+	ZThreshold      float64 `json:"z_threshold,omitempty" jsonschema:"description=Z-score a numeric metric must reach when the baseline varies (default: 3.0)"`
+	ChangeThreshold float64 `json:"change_threshold,omitempty" jsonschema:"description=Relative change from the baseline that counts as a jump, e.g. 0.3 for 30% (default: 0.3)"`
+}
+
+// CollectTimeSeriesArgs represents the arguments for collecting query metrics across snapshots
+type CollectTimeSeriesArgs struct {
+	SeriesName   string   `json:"series_name" jsonschema:"required,description=Name of the series (e.g. device_count). Existing series are extended with the snapshots not yet collected"`
+	NetworkID    string   `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	QueryID      string   `json:"query_id,omitempty" jsonschema:"description=Library query ID to run on each snapshot (new series need query_id or query)"`
+	Query        string   `json:"query,omitempty" jsonschema:"description=NQE source to run on each snapshot instead of a library query"`
+	SumColumns   []string `json:"sum_columns,omitempty" jsonschema:"description=Numeric columns to sum per snapshot (e.g. [\"routeCount\"])"`
+	GroupBy      string   `json:"group_by,omitempty" jsonschema:"description=Column whose values are counted per snapshot (e.g. vendor)"`
+	From         string   `json:"from,omitempty" jsonschema:"description=Earliest snapshot time: RFC 3339, YYYY-MM-DD or an age such as 90d (default: no limit)"`
+	To           string   `json:"to,omitempty" jsonschema:"description=Latest snapshot time: RFC 3339, YYYY-MM-DD or an age (default: now)"`
+	MaxSnapshots int      `json:"max_snapshots,omitempty" jsonschema:"description=Newest snapshots in the range to collect (default: 30, max: 200)"`
+	Refresh      bool     `json:"refresh,omitempty" jsonschema:"description=Recollect snapshots that are already stored (default: false)"`
+}
+
+// QueryTimeSeriesArgs represents the arguments for reading a stored time series
+type QueryTimeSeriesArgs struct {
+	SeriesName string `json:"series_name,omitempty" jsonschema:"description=Series to read; omit to list the series of the network"`
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	Metric     string `json:"metric,omitempty" jsonschema:"description=Only include metrics containing this text (e.g. row_count or vendor=)"`
+	From       string `json:"from,omitempty" jsonschema:"description=Earliest snapshot time: RFC 3339, YYYY-MM-DD or an age such as 90d"`
+	To         string `json:"to,omitempty" jsonschema:"description=Latest snapshot time: RFC 3339, YYYY-MM-DD or an age"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// GenerateChartSpecArgs represents the arguments for building a chart spec from stored data
+type GenerateChartSpecArgs struct {
+	EntityID      string   `json:"entity_id,omitempty" jsonschema:"description=Stored NQE result entity to chart (use with x_column)"`
+	SQLQuery      string   `json:"sql_query,omitempty" jsonschema:"description=Optional SQL over the stored result (table nqe_result) whose output is charted instead of the raw rows"`
+	SeriesName    string   `json:"series_name,omitempty" jsonschema:"description=Stored time series to chart over snapshot time instead of a result"`
+	NetworkID     string   `json:"network_id,omitempty" jsonschema:"description=Network ID of the time series (uses default network if omitted)"`
+	Metric        string   `json:"metric,omitempty" jsonschema:"description=Only chart time-series metrics containing this text"`
+	XColumn       string   `json:"x_column,omitempty" jsonschema:"description=Category or time column for the x axis (pie slices)"`
+	YColumns      []string `json:"y_columns,omitempty" jsonschema:"description=Numeric columns summed per x value; omit to chart the row count per x value"`
+	ChartType     string   `json:"chart_type,omitempty" jsonschema:"description=bar, pie or line (time series). Defaults to line for time values and bar otherwise"`
+	Library       string   `json:"library,omitempty" jsonschema:"description=Spec format: vega-lite (default) or chartjs"`
+	Title         string   `json:"title,omitempty" jsonschema:"description=Chart title"`
+	MaxCategories int      `json:"max_categories,omitempty" jsonschema:"description=Largest categories shown in bar and pie charts; the rest are grouped as (other) (default: 20)"`
 }
 
 // InitializeQueryIndexArgs represents arguments for building the AI query index
@@ -267,8 +710,7 @@ type FindExecutableQueryArgs struct {
 
 // Smart Query Workflow Arguments
 type SmartQueryWorkflowArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 // Database Hydration Tools Arguments
@@ -277,16 +719,40 @@ type HydrateDatabaseArgs struct {
 	EnhancedMode         bool `json:"enhanced_mode" jsonschema:"description=Use enhanced API mode for metadata enrichment (default: true)"`
 	MaxRetries           int  `json:"max_retries" jsonschema:"description=Maximum number of retry attempts for API calls (default: 3)"`
 	RegenerateEmbeddings bool `json:"regenerate_embeddings" jsonschema:"description=Automatically regenerate AI embeddings after hydration for improved semantic search (default: false)"`
+	Workers              int  `json:"workers,omitempty" jsonschema:"description=Parallel workers fetching query details in enhanced mode (default: 4, max: 16)"`
+	Delta                bool `json:"delta,omitempty" jsonschema:"description=Fetch only queries whose last commit changed since the stored version and deprecate queries deleted upstream; runs synchronously and returns a summary of added, updated and deprecated queries (default: false)"`
+	RestartHydration     bool `json:"restart_hydration,omitempty" jsonschema:"description=Ignore checkpoints from an interrupted enhanced hydration and fetch every directory again (default: false)"`
+}
+
+// SetQueryCategoryArgs represents the arguments for mapping queries to a custom category
+type SetQueryCategoryArgs struct {
+	Match       string `json:"match" jsonschema:"required,description=Query ID or path prefix starting with / (e.g. '/L3/Cloud' covers every query under it)"`
+	Category    string `json:"category" jsonschema:"required,description=Custom category such as cloud, datacenter or campus"`
+	Subcategory string `json:"subcategory,omitempty" jsonschema:"description=Optional custom subcategory"`
+}
+
+// RemoveQueryCategoryArgs represents the arguments for deleting a taxonomy rule
+type RemoveQueryCategoryArgs struct {
+	Match string `json:"match" jsonschema:"required,description=Query ID or path prefix of the rule to remove"`
+}
+
+// ListQueryTaxonomyArgs represents the arguments for listing the custom query taxonomy
+type ListQueryTaxonomyArgs struct {
+	Format string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// PurgeDeprecatedQueriesArgs represents the arguments for deleting deprecated NQE queries
+type PurgeDeprecatedQueriesArgs struct {
+	OlderThanDays int  `json:"older_than_days,omitempty" jsonschema:"description=Only purge queries deprecated at least this many days ago (default: all deprecated queries)"`
+	DryRun        bool `json:"dry_run,omitempty" jsonschema:"description=List the queries that would be purged without deleting them"`
 }
 
 type RefreshQueryIndexArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 type GetDatabaseStatusArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 type GetQueryIndexStatsArgs struct {
@@ -307,6 +773,18 @@ type CreateRelationArgs struct {
 	Properties map[string]interface{} `json:"properties" jsonschema:"description=Properties of the relation"`
 }
 
+// BulkCreateEntitiesArgs represents the arguments for creating many memory entities at once
+type BulkCreateEntitiesArgs struct {
+	Entities []BulkEntity `json:"entities" jsonschema:"required,description=Entities to create or update (at most 1000)"`
+	Atomic   *bool        `json:"atomic,omitempty" jsonschema:"description=Store nothing when any item fails (default: true); false stores the valid items"`
+}
+
+// BulkCreateRelationsArgs represents the arguments for creating many memory relations at once
+type BulkCreateRelationsArgs struct {
+	Relations []BulkRelation `json:"relations" jsonschema:"required,description=Relations to create or update (at most 1000)"`
+	Atomic    *bool          `json:"atomic,omitempty" jsonschema:"description=Store nothing when any item fails (default: true); false stores the valid items"`
+}
+
 type AddObservationArgs struct {
 	EntityID string                 `json:"entity_id" jsonschema:"required,description=ID of the entity to add observation to"`
 	Content  string                 `json:"content" jsonschema:"required,description=Content of the observation"`
@@ -315,15 +793,21 @@ type AddObservationArgs struct {
 }
 
 type SearchEntitiesArgs struct {
-	Query      string `json:"query" jsonschema:"description=Search query to find entities by name or observation content"`
-	EntityType string `json:"entity_type" jsonschema:"description=Filter by entity type"`
-	Limit      int    `json:"limit" jsonschema:"description=Maximum number of results to return (default: 50)"`
+	Query      string          `json:"query" jsonschema:"description=Search query to find entities by name or observation content"`
+	EntityType string          `json:"entity_type" jsonschema:"description=Filter by entity type"`
+	Limit      int             `json:"limit" jsonschema:"description=Maximum number of results to return (default: 50)"`
+	Caller     *APIKeyIdentity `json:"-"` // Calling API key; entities of other networks are left out
 }
 
 type GetEntityArgs struct {
 	Identifier string `json:"identifier" jsonschema:"required,description=Entity ID or name to retrieve"`
 }
 
+// GetEntityVersionsArgs represents the arguments for listing the versions of an entity
+type GetEntityVersionsArgs struct {
+	Identifier string `json:"identifier" jsonschema:"required,description=Entity ID or name of any version of the entity"`
+}
+
 type GetRelationsArgs struct {
 	EntityID     string `json:"entity_id" jsonschema:"required,description=ID of the entity to get relations for"`
 	RelationType string `json:"relation_type" jsonschema:"description=Filter by relation type"`
@@ -341,7 +825,50 @@ type GetObservationsArgs struct {
 }
 
 type DeleteEntityArgs struct {
-	EntityID string `json:"entity_id" jsonschema:"required,description=ID of the entity to delete"`
+	EntityID          string `json:"entity_id" jsonschema:"required,description=ID of the entity to delete"`
+	ConfirmationToken string `json:"confirmation_token,omitempty" jsonschema:"description=Token returned by the first call; resend the same arguments with it to carry out the deletion"`
+}
+
+// RestoreEntityArgs represents the arguments for undoing an entity deletion
+type RestoreEntityArgs struct {
+	EntityID string `json:"entity_id" jsonschema:"required,description=ID of the deleted entity, as reported by delete_entity or list_trash"`
+}
+
+// PinEntityArgs represents the arguments for pinning an entity against garbage collection
+type PinEntityArgs struct {
+	EntityID string `json:"entity_id" jsonschema:"required,description=ID or name of the entity to pin"`
+	Reason   string `json:"reason,omitempty" jsonschema:"description=Why the entity must be kept e.g. golden config for the core routers"`
+}
+
+// UnpinEntityArgs represents the arguments for releasing a pinned entity
+type UnpinEntityArgs struct {
+	EntityID string `json:"entity_id" jsonschema:"required,description=ID or name of the pinned entity"`
+}
+
+// ClassifyIntentArgs represents the arguments for routing a natural-language request to a tool
+type ClassifyIntentArgs struct {
+	Request      string `json:"request" jsonschema:"required,description=The user's request in their own words, e.g. 'can 10.1.1.10 reach 10.2.2.20 on https'"`
+	Alternatives int    `json:"alternatives,omitempty" jsonschema:"description=Number of runner-up intents to list (default: 2)"`
+	Format       string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// ListTrashArgs represents the arguments for listing deleted entities
+type ListTrashArgs struct {
+	Limit int `json:"limit,omitempty" jsonschema:"description=Maximum number of entities to list, most recently deleted first (default: 50)"`
+}
+
+// FindDuplicateEntitiesArgs represents the arguments for finding duplicate memory entities
+type FindDuplicateEntitiesArgs struct {
+	EntityType string  `json:"entity_type,omitempty" jsonschema:"description=Only look for duplicates among entities of this type e.g. device"`
+	Threshold  float64 `json:"threshold,omitempty" jsonschema:"description=Name similarity from 0 to 1 above which differently spelled names count as duplicates (default: 0.85)"`
+	Limit      int     `json:"limit,omitempty" jsonschema:"description=Maximum number of duplicate groups to list (default: 20)"`
+}
+
+// MergeEntitiesArgs represents the arguments for merging duplicate entities
+type MergeEntitiesArgs struct {
+	TargetID          string   `json:"target_id" jsonschema:"required,description=ID or name of the entity to keep"`
+	SourceIDs         []string `json:"source_ids" jsonschema:"required,description=IDs or names of the duplicates to merge into the target; they are removed afterwards"`
+	ConfirmationToken string   `json:"confirmation_token,omitempty" jsonschema:"description=Token returned by the first call; resend the same arguments with it to carry out the merge"`
 }
 
 type DeleteRelationArgs struct {
@@ -352,9 +879,44 @@ type DeleteObservationArgs struct {
 	ObservationID string `json:"observation_id" jsonschema:"required,description=ID of the observation to delete"`
 }
 
+// ShareResultArgs represents the arguments for sharing a stored entity or report
+type ShareResultArgs struct {
+	EntityID string `json:"entity_id,omitempty" jsonschema:"description=ID or name of the memory entity to share; stored query results include their rows"`
+	Report   string `json:"report,omitempty" jsonschema:"description=File name or forward://reports/ URI of a saved report to share"`
+}
+
+// GetSharedResultArgs represents the arguments for fetching a shared result
+type GetSharedResultArgs struct {
+	Reference string          `json:"reference" jsonschema:"required,description=Reference returned by share_result (sha256:<digest>); the first 12 digits are enough"`
+	MaxRows   int             `json:"max_rows,omitempty" jsonschema:"description=Rows of a shared query result to show (default: 20, max: 500)"`
+	Caller    *APIKeyIdentity `json:"-"` // Calling API key; shares of other networks are reported as missing
+}
+
+// SummarizeResultArgs represents the arguments for summarizing a stored result
+type SummarizeResultArgs struct {
+	EntityID   string `json:"entity_id" jsonschema:"required,description=ID or name of the stored NQE result to summarize"`
+	Focus      string `json:"focus,omitempty" jsonschema:"description=What the summary should concentrate on, e.g. interfaces with errors"`
+	SampleRows int    `json:"sample_rows,omitempty" jsonschema:"description=Rows sent to the model, spread across the result (default: 50, max: 200)"`
+	Refresh    bool   `json:"refresh,omitempty" jsonschema:"description=Summarize again even if a summary with the same focus is stored"`
+}
+
+// LookupErrorArgs represents the arguments for explaining an error code
+type LookupErrorArgs struct {
+	Code string `json:"code,omitempty" jsonschema:"description=Error code from a tool error such as FWD-NET-001 (omit to list every code)"`
+}
+
+// ListFeatureFlagsArgs represents the arguments for listing feature flags
+type ListFeatureFlagsArgs struct {
+	noArguments
+}
+
+// GetRedactionStatsArgs represents the arguments for reporting redaction statistics
+type GetRedactionStatsArgs struct {
+	noArguments
+}
+
 type GetMemoryStatsArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 // API Analytics Tools Arguments
@@ -362,10 +924,81 @@ type GetQueryAnalyticsArgs struct {
 	NetworkID string `json:"network_id" jsonschema:"required,description=Network ID to get analytics for"`
 }
 
+// StartSessionTranscriptArgs represents the arguments for starting or resuming a session transcript
+type StartSessionTranscriptArgs struct {
+	Title     string `json:"title,omitempty" jsonschema:"description=Title of the investigation, e.g. a ticket number and symptom"`
+	SessionID string `json:"session_id,omitempty" jsonschema:"description=ID of a stopped session to resume; its new steps continue the same chain"`
+}
+
+// StopSessionTranscriptArgs represents the arguments for stopping the session transcript
+type StopSessionTranscriptArgs struct {
+	noArguments
+}
+
+// GetSessionTranscriptArgs represents the arguments for reading a session transcript
+type GetSessionTranscriptArgs struct {
+	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID (default: the session being recorded, or the most recent one)"`
+	Format    string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+	MaxSteps  int    `json:"max_steps,omitempty" jsonschema:"description=Most recent steps to show (default: 100)"`
+}
+
+// CreateWorkspaceArgs represents the arguments for creating or reopening a workspace
+type CreateWorkspaceArgs struct {
+	Name        string `json:"name" jsonschema:"required,description=Workspace name, e.g. dc-migration; an existing workspace with this name is reopened"`
+	Description string `json:"description,omitempty" jsonschema:"description=What the investigation or project is about"`
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key; each key has its own open workspace
+}
+
+// CloseWorkspaceArgs represents the arguments for closing the open workspace
+type CloseWorkspaceArgs struct {
+	noArguments
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key; only its workspace closes
+}
+
+// ListWorkspaceContentsArgs represents the arguments for listing a workspace's artifacts
+type ListWorkspaceContentsArgs struct {
+	Name string `json:"name,omitempty" jsonschema:"description=Workspace name (default: the open workspace; lists every workspace when none is open)"`
+	Type string `json:"type,omitempty" jsonschema:"description=Only list artifacts of this entity type, e.g. nqe_result, note or report"`
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key, whose open workspace is the default
+}
+
+// ExportWorkspaceArgs represents the arguments for exporting a workspace
+type ExportWorkspaceArgs struct {
+	Name                string `json:"name,omitempty" jsonschema:"description=Workspace name (default: the open workspace)"`
+	IncludeObservations bool   `json:"include_observations,omitempty" jsonschema:"description=Include the observations of each artifact, such as stored result chunks (default: false)"`
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key, whose open workspace is the default
+}
+
+// CleanupWorkspaceArgs represents the arguments for deleting a workspace's artifacts
+type CleanupWorkspaceArgs struct {
+	Name              string `json:"name" jsonschema:"required,description=Workspace name"`
+	DeleteReports     bool   `json:"delete_reports,omitempty" jsonschema:"description=Also delete the report files saved in the workspace; they cannot be restored (default: false)"`
+	DeleteWorkspace   bool   `json:"delete_workspace,omitempty" jsonschema:"description=Also delete the workspace itself (default: false)"`
+	ConfirmationToken string `json:"confirmation_token,omitempty" jsonschema:"description=Token returned by the first call; resend the same arguments with it to carry out the cleanup"`
+}
+
+// StartAnalysisSessionArgs represents the arguments for starting an analysis session
+type StartAnalysisSessionArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network to pin snapshot_id on (default: the default network)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to pin right away (default: the snapshot the network's first call uses)"`
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key; each key has its own session
+}
+
+// EndAnalysisSessionArgs represents the arguments for ending the analysis session
+type EndAnalysisSessionArgs struct {
+	noArguments
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key; only its session ends
+}
+
 // Instance Management Tool Arguments
 type ListInstanceIDsArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 // For the config search tool schema/registration:
@@ -384,6 +1017,8 @@ type ListInstanceIDsArgs struct {
 // Large NQE Results Workflow Arguments
 type LargeNQEResultsWorkflowArgs struct {
 	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
 }
 
 // Path Search Arguments
@@ -392,21 +1027,109 @@ type SearchPathsArgs struct {
 	SnapshotID              string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
 	From                    string `json:"from,omitempty" jsonschema:"description=Source device name"`
 	SrcIP                   string `json:"src_ip,omitempty" jsonschema:"description=Source IP address or subnet"`
-	DstIP                   string `json:"dst_ip" jsonschema:"required,description=Destination IP address or subnet"`
-	IPProto                 *int   `json:"ip_proto,omitempty" jsonschema:"description=IP protocol number"`
+	DstIP                   string `json:"dst_ip" jsonschema:"required,description=Destination IP address or subnet (a device name resolves to its address)"`
+	IPProto                 *int   `json:"ip_proto,omitempty" jsonschema:"description=IP protocol number or name such as tcp"`
 	SrcPort                 string `json:"src_port,omitempty" jsonschema:"description=Source port"`
-	DstPort                 string `json:"dst_port,omitempty" jsonschema:"description=Destination port"`
+	DstPort                 string `json:"dst_port,omitempty" jsonschema:"description=Destination port or range; service names such as https and forms such as 443/tcp are accepted"`
 	Intent                  string `json:"intent,omitempty" jsonschema:"description=Search intent (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)"`
 	MaxCandidates           int    `json:"max_candidates,omitempty" jsonschema:"description=Maximum number of candidates to consider"`
 	MaxResults              int    `json:"max_results,omitempty" jsonschema:"description=Maximum number of results to return"`
 	MaxReturnPathResults    int    `json:"max_return_path_results,omitempty" jsonschema:"description=Maximum number of return path results"`
 	MaxSeconds              int    `json:"max_seconds,omitempty" jsonschema:"description=Maximum seconds per query"`
 	IncludeNetworkFunctions bool   `json:"include_network_functions,omitempty" jsonschema:"description=Include network functions in results"`
+	AnalyzeECMP             bool   `json:"analyze_ecmp,omitempty" jsonschema:"description=Request more candidates and report ECMP fan-out per hop, flagging flows that collapse to a single path"`
+	ExpandUnderlay          bool   `json:"expand_underlay,omitempty" jsonschema:"description=Trace the underlay path of tunnels (SD-WAN/GRE/IPsec/VXLAN) that appear as opaque hops with a follow-up search between the tunnel endpoints"`
+	VerifyWithProbes        bool   `json:"verify_with_probes,omitempty" jsonschema:"description=Send real ICMP/TCP probes to the destination from the configured probe host or probe API and flag a mismatch when the observed reachability contradicts the model"`
+}
+
+// TroubleshootConnectivityArgs represents arguments for the composite connectivity troubleshooting tool
+type TroubleshootConnectivityArgs struct {
+	NetworkID   string `json:"network_id" jsonschema:"required,description=Network ID to troubleshoot in"`
+	SnapshotID  string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
+	Source      string `json:"source" jsonschema:"required,description=Source device name or IP address"`
+	Destination string `json:"destination" jsonschema:"required,description=Destination device name or IP address"`
+	IPProto     *int   `json:"ip_proto,omitempty" jsonschema:"description=IP protocol number (e.g. 6 for TCP)"`
+	DstPort     string `json:"dst_port,omitempty" jsonschema:"description=Destination port or range; service names such as https and forms such as 443/tcp are accepted"`
+}
+
+// SweepViolationsArgs represents the arguments for a network-wide violation sweep
+type SweepViolationsArgs struct {
+	NetworkID   string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID  string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
+	PrefixLevel string `json:"prefix_level,omitempty" jsonschema:"description=Prefix aggregation level whose representative hosts form the matrix: /8, /16 or /24 for IPv4, /32, /48 or /64 for IPv6 (default: /24)"`
+	MaxPrefixes int    `json:"max_prefixes,omitempty" jsonschema:"description=Prefixes to sample, those with the most devices first (default: 12, max: 50)"`
+	MaxQueries  int    `json:"max_queries,omitempty" jsonschema:"description=Maximum path searches (prefix pairs) to run (default: 200, max: 1000)"`
+	IPProto     *int   `json:"ip_proto,omitempty" jsonschema:"description=IP protocol number to test (e.g. 6 for TCP; default: any)"`
+	DstPort     string `json:"dst_port,omitempty" jsonschema:"description=Destination port to test (optional)"`
+	Format      string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// DetectConnectivityDriftArgs represents the arguments for comparing connectivity with a baseline
+type DetectConnectivityDriftArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to compare against the baseline (optional, uses latest if omitted)"`
+	Baseline   string `json:"baseline,omitempty" jsonschema:"description=Baseline name given to analyze_network_prefixes (default: default)"`
+	Sample     int    `json:"sample,omitempty" jsonschema:"description=Re-run only this many randomly chosen pairs instead of the whole matrix"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// BuildServiceMapArgs represents the arguments for building a service dependency map
+type BuildServiceMapArgs struct {
+	NetworkID  string   `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	SnapshotID string   `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
+	Name       string   `json:"name,omitempty" jsonschema:"description=Name the map is stored under (default: default); building the same name again replaces it"`
+	CSV        string   `json:"csv,omitempty" jsonschema:"description=Endpoints as CSV with a header row: tier and ip, plus optionally name, proto and port of the service the endpoint listens on"`
+	CSVFile    string   `json:"csv_file,omitempty" jsonschema:"description=Name of a CSV file in the server import directory (<data dir>/imports) with the same columns as csv"`
+	EntityType string   `json:"entity_type,omitempty" jsonschema:"description=Read endpoints from memory entities of this type instead; their metadata needs ip (or address) and tier (or role), and may hold proto and port"`
+	TierOrder  []string `json:"tier_order,omitempty" jsonschema:"description=Calling order of the tiers, e.g. [web, app, db]; only flows from each tier to the next are searched. Without it every pair of tiers is searched in both directions"`
+	Format     string   `json:"format,omitempty" jsonschema:"description=Output format: markdown (default), json or graphml"`
+}
+
+// ExportServiceMapArgs represents the arguments for exporting a stored service map
+type ExportServiceMapArgs struct {
+	NetworkID string `json:"network_id,omitempty" jsonschema:"description=Network ID (uses default network if omitted)"`
+	Name      string `json:"name,omitempty" jsonschema:"description=Name given to build_service_map (default: default)"`
+	Format    string `json:"format,omitempty" jsonschema:"description=Output format: graphml (default), json or markdown"`
+}
+
+// EstimateQueryCostArgs represents the arguments for estimating the cost of an NQE query
+type EstimateQueryCostArgs struct {
+	NetworkID   string `json:"network_id,omitempty" jsonschema:"description=Network the query would run on (default: the default network)"`
+	QueryID     string `json:"query_id" jsonschema:"required,description=Query ID from the NQE library"`
+	Concurrency int    `json:"concurrency,omitempty" jsonschema:"description=Batches fetched in parallel to assume for all_results (default: the configured concurrency)"`
+	Format      string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// QueryCatalogSQLArgs represents the arguments for querying the NQE query catalog with SQL
+type QueryCatalogSQLArgs struct {
+	SQLQuery     string `json:"sql_query" jsonschema:"required,description=SQL query over the queries, metadata and instances tables"`
+	AllInstances bool   `json:"all_instances,omitempty" jsonschema:"description=Include the queries and metadata of every instance in the database, not just this one"`
+}
+
+// RunFederatedQueryArgs represents the arguments for running an NQE query across federated instances
+type RunFederatedQueryArgs struct {
+	NetworkID  string                 `json:"network_id,omitempty" jsonschema:"description=Network queried on this instance (default: the default network); other instances use their configured network"`
+	QueryID    string                 `json:"query_id,omitempty" jsonschema:"description=Query ID from the NQE library; it must exist on every instance"`
+	Query      string                 `json:"query,omitempty" jsonschema:"description=NQE source to run instead of a query ID"`
+	Parameters map[string]interface{} `json:"parameters,omitempty" jsonschema:"description=Query parameters"`
+	Instances  []string               `json:"instances,omitempty" jsonschema:"description=Only query these instances (local is this server's instance; default: all)"`
+	Limit      int                    `json:"limit,omitempty" jsonschema:"description=Rows per instance (default: the default query limit)"`
+	AllResults bool                   `json:"all_results,omitempty" jsonschema:"description=Fetch every row from each instance in pages of limit rows"`
+	Format     string                 `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// GetFederatedInventoryArgs represents the arguments for listing devices across federated instances
+type GetFederatedInventoryArgs struct {
+	NetworkID string   `json:"network_id,omitempty" jsonschema:"description=Network listed on this instance (default: the default network); other instances use their configured network"`
+	Instances []string `json:"instances,omitempty" jsonschema:"description=Only list these instances (local is this server's instance; default: all)"`
+	Format    string   `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
 }
 
 // Path Search Workflow Arguments
 type PathSearchWorkflowArgs struct {
 	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
 }
 
 // Bloom Search Arguments
@@ -425,24 +1148,75 @@ type SearchBloomFilterArgs struct {
 }
 
 type GetBloomFilterStatsArgs struct {
-	// Dummy parameter for MCP framework compatibility
-	Dummy string `json:"dummy,omitempty" jsonschema:"description=Dummy parameter for no-parameter tools"`
+	noArguments
 }
 
 // Network Prefix Discovery and Connectivity Analysis
 type NetworkPrefixDiscoveryArgs struct {
 	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
-	Step      string `json:"step,omitempty" jsonschema:"description=Current step in the workflow"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
+}
+
+// SecurityPostureWorkflowArgs represents the arguments for the security posture workflow prompt
+type SecurityPostureWorkflowArgs struct {
+	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
+}
+
+// SiteTurnupWorkflowArgs represents the arguments for the site turn-up workflow prompt
+type SiteTurnupWorkflowArgs struct {
+	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
+}
+
+// OnboardDevicesWorkflowArgs represents the arguments for the device onboarding workflow prompt
+type OnboardDevicesWorkflowArgs struct {
+	SessionID string `json:"session_id,omitempty" jsonschema:"description=Session ID for tracking workflow state"`
+	Step      string `json:"step,omitempty" jsonschema:"description=Workflow step to run next (see next_steps in the previous response; omit to continue)"`
+	Answer    string `json:"answer,omitempty" jsonschema:"description=Answer for steps that require input (see answer_hint in next_steps)"`
+}
+
+// PlanDeviceOnboardingArgs represents the arguments for recording devices being onboarded
+type PlanDeviceOnboardingArgs struct {
+	NetworkID string             `json:"network_id,omitempty" jsonschema:"description=ID of the network the devices are added to (uses the default network if omitted)"`
+	Batch     string             `json:"batch,omitempty" jsonschema:"description=Name of the onboarding batch (default: onboarding-<timestamp>); planning an existing batch replaces it"`
+	Devices   []OnboardingDevice `json:"devices" jsonschema:"required,description=Devices being onboarded"`
+}
+
+// CheckDeviceOnboardingArgs represents the arguments for validating collection of an onboarding batch
+type CheckDeviceOnboardingArgs struct {
+	NetworkID  string `json:"network_id,omitempty" jsonschema:"description=ID of the network (uses the default network if omitted)"`
+	Batch      string `json:"batch" jsonschema:"required,description=Name of the onboarding batch"`
+	SnapshotID string `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot to check (default: the newest processed snapshot)"`
+	Format     string `json:"format,omitempty" jsonschema:"description=Output format: markdown (default) or json"`
+}
+
+// AssignOnboardedLocationsArgs represents the arguments for assigning onboarded devices to locations
+type AssignOnboardedLocationsArgs struct {
+	NetworkID string            `json:"network_id,omitempty" jsonschema:"description=ID of the network (uses the default network if omitted)"`
+	Batch     string            `json:"batch" jsonschema:"required,description=Name of the onboarding batch"`
+	Locations map[string]string `json:"locations,omitempty" jsonschema:"description=Device name to location name or ID overriding the planned locations"`
+	DryRun    bool              `json:"dry_run,omitempty" jsonschema:"description=List the assignments without applying them"`
 }
 
 type NetworkPrefixAnalysisArgs struct {
 	NetworkID    string   `json:"network_id" jsonschema:"required,description=Network ID to analyze"`
 	SnapshotID   string   `json:"snapshot_id,omitempty" jsonschema:"description=Snapshot ID to use (optional, uses latest if omitted)"`
-	PrefixLevels []string `json:"prefix_levels,omitempty" jsonschema:"description=Aggregation levels to analyze (e.g., ['/8', '/16', '/24'])"`
+	PrefixLevels []string `json:"prefix_levels,omitempty" jsonschema:"description=Aggregation levels to analyze; levels up to /32 apply to IPv4 and from /32 to IPv6 (default: ['/8', '/16', '/24', '/48', '/64'])"`
 	FromDevices  []string `json:"from_devices,omitempty" jsonschema:"description=Source devices to analyze"`
 	ToDevices    []string `json:"to_devices,omitempty" jsonschema:"description=Destination devices to analyze"`
+	FromTags     []string `json:"from_tags,omitempty" jsonschema:"description=Device tags whose devices are added to from_devices (see apply_device_tags)"`
+	ToTags       []string `json:"to_tags,omitempty" jsonschema:"description=Device tags whose devices are added to to_devices"`
 	Intent       string   `json:"intent,omitempty" jsonschema:"description=Search intent (PREFER_DELIVERED, PREFER_VIOLATIONS, VIOLATIONS_ONLY)"`
 	MaxResults   int      `json:"max_results,omitempty" jsonschema:"description=Maximum number of results to return"`
+	ReportFormat string   `json:"report_format,omitempty" jsonschema:"description=Also save a rendered report: markdown, html or pdf (served as a forward://reports/ resource)"`
+	SaveBaseline bool     `json:"save_baseline,omitempty" jsonschema:"description=Measure the connectivity matrix between representative hosts of the prefixes and store it as a baseline for detect_connectivity_drift"`
+	BaselineName string   `json:"baseline_name,omitempty" jsonschema:"description=Name of the saved baseline, replacing one of the same name (default: default)"`
+
+	Caller *APIKeyIdentity `json:"-"` // Calling API key; read-only keys cannot save a baseline
 }
 
 type NetworkPrefixInfo struct {
@@ -452,6 +1226,7 @@ type NetworkPrefixInfo struct {
 	Location   string   `json:"location,omitempty"`
 	Aggregated bool     `json:"aggregated"`
 	Subnets    []string `json:"subnets,omitempty"`
+	Ownership  string   `json:"ownership,omitempty"`
 }
 
 type ConnectivityAnalysisResult struct {
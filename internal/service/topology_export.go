@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// topologyLinksQuery returns one row per interface link; each physical link appears once from
+// each end and is deduplicated locally
+const topologyLinksQuery = `foreach device in network.devices
+foreach iface in device.interfaces
+foreach link in iface.links
+select {
+  device: device.name,
+  interface: iface.name,
+  peerDevice: link.deviceName,
+  peerInterface: link.ifaceName
+}`
+
+// TopologyDevice is a node of an exported topology
+type TopologyDevice struct {
+	Name          string   `json:"name"`
+	Type          string   `json:"type,omitempty"`
+	Vendor        string   `json:"vendor,omitempty"`
+	Platform      string   `json:"platform,omitempty"`
+	Model         string   `json:"model,omitempty"`
+	LocationID    string   `json:"location_id,omitempty"`
+	ManagementIPs []string `json:"management_ips,omitempty"`
+}
+
+// TopologyLink is an edge of an exported topology, with the endpoints in name order
+type TopologyLink struct {
+	DeviceA    string `json:"device_a"`
+	InterfaceA string `json:"interface_a"`
+	DeviceB    string `json:"device_b"`
+	InterfaceB string `json:"interface_b"`
+}
+
+// TopologyExport is the device and link graph of a network snapshot
+type TopologyExport struct {
+	NetworkID  string           `json:"network_id"`
+	SnapshotID string           `json:"snapshot_id,omitempty"`
+	Devices    []TopologyDevice `json:"devices"`
+	Links      []TopologyLink   `json:"links"`
+}
+
+// ExportTopology returns the devices of a network and the links between them
+func (s *ForwardMCPService) ExportTopology(ctx context.Context, networkID, snapshotID string) (*TopologyExport, error) {
+	networkID = s.networkIDOrDefault(networkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	if err := s.checkNetworkAccess(networkID); err != nil {
+		return nil, err
+	}
+	snapshotID = s.getSnapshotID(snapshotID)
+
+	devices, err := s.getNetworkDevices(networkID, snapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch devices: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	items, err := s.fetchAllNQEQueryItems(networkID, snapshotID, topologyLinksQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch links: %w", err)
+	}
+
+	export := &TopologyExport{NetworkID: networkID, SnapshotID: snapshotID, Devices: make([]TopologyDevice, 0, len(devices))}
+	for _, device := range devices {
+		export.Devices = append(export.Devices, TopologyDevice{
+			Name:          device.Name,
+			Type:          device.Type,
+			Vendor:        device.Vendor,
+			Platform:      device.Platform,
+			Model:         device.Model,
+			LocationID:    device.LocationID,
+			ManagementIPs: device.ManagementIPs,
+		})
+	}
+	sort.Slice(export.Devices, func(i, j int) bool { return export.Devices[i].Name < export.Devices[j].Name })
+	export.Links = parseTopologyLinks(items)
+	return export, nil
+}
+
+// parseTopologyLinks converts link rows into edges, keeping one edge per pair of endpoints
+func parseTopologyLinks(items []map[string]interface{}) []TopologyLink {
+	seen := make(map[TopologyLink]bool)
+	links := []TopologyLink{}
+	for _, item := range items {
+		link := TopologyLink{
+			DeviceA:    resultValueString(item["device"]),
+			InterfaceA: resultValueString(item["interface"]),
+			DeviceB:    resultValueString(item["peerDevice"]),
+			InterfaceB: resultValueString(item["peerInterface"]),
+		}
+		if link.DeviceA == "" || link.DeviceB == "" {
+			continue
+		}
+		if link.DeviceB < link.DeviceA || (link.DeviceB == link.DeviceA && link.InterfaceB < link.InterfaceA) {
+			link = TopologyLink{DeviceA: link.DeviceB, InterfaceA: link.InterfaceB, DeviceB: link.DeviceA, InterfaceB: link.InterfaceA}
+		}
+		if seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].DeviceA != links[j].DeviceA {
+			return links[i].DeviceA < links[j].DeviceA
+		}
+		if links[i].InterfaceA != links[j].InterfaceA {
+			return links[i].InterfaceA < links[j].InterfaceA
+		}
+		return links[i].DeviceB < links[j].DeviceB
+	})
+	return links
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestExportTopology(t *testing.T) {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	link := func(device, iface, peerDevice, peerIface string) map[string]interface{} {
+		return map[string]interface{}{"device": device, "interface": iface, "peerDevice": peerDevice, "peerInterface": peerIface}
+	}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		link("sw2", "Gi1", "sw1", "Gi1"),
+		link("sw1", "Gi1", "sw2", "Gi1"),
+		link("sw1", "Gi2", "sw3", "Gi1"),
+		link("sw3", "Gi2", "", ""),
+	}}
+	client.devices = []forward.Device{{Name: "sw3"}, {Name: "sw1", Vendor: "CISCO", LocationID: "hq"}, {Name: "sw2"}}
+	service.deviceCache = nil
+
+	topology, err := service.ExportTopology(context.Background(), "network-1", "")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(topology.Devices) != 3 || topology.Devices[0].Name != "sw1" || topology.Devices[0].LocationID != "hq" {
+		t.Errorf("Expected devices sorted by name, got %+v", topology.Devices)
+	}
+	expected := []TopologyLink{
+		{DeviceA: "sw1", InterfaceA: "Gi1", DeviceB: "sw2", InterfaceB: "Gi1"},
+		{DeviceA: "sw1", InterfaceA: "Gi2", DeviceB: "sw3", InterfaceB: "Gi1"},
+	}
+	if !reflect.DeepEqual(topology.Links, expected) {
+		t.Errorf("Expected each link once, got %+v", topology.Links)
+	}
+}
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/forward-mcp/internal/forward"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// ConnectivityDiagnosis summarizes the outcome of a path search in one direction
+type ConnectivityDiagnosis struct {
+	Direction         string       `json:"direction"`
+	SrcIP             string       `json:"src_ip,omitempty"`
+	DstIP             string       `json:"dst_ip"`
+	From              string       `json:"from,omitempty"`
+	PathCount         int          `json:"path_count"`
+	Delivered         bool         `json:"delivered"`
+	ForwardingOutcome string       `json:"forwarding_outcome,omitempty"`
+	SecurityOutcome   string       `json:"security_outcome,omitempty"`
+	ACLDenied         bool         `json:"acl_denied"`
+	FailureDevice     string       `json:"failure_device,omitempty"`
+	FailureInterface  string       `json:"failure_interface,omitempty"`
+	RouteTrace        []string     `json:"route_trace,omitempty"`
+	Route             *RouteLookup `json:"route,omitempty"` // Route at the failure point of a forwarding failure
+	Finding           string       `json:"finding"`
+	TimedOut          bool         `json:"timed_out,omitempty"`
+}
+
+// troubleshootRouteQuery returns the routes of one device that cover an address, in every
+// VRF; the address family table (ipv4Unicast or ipv6Unicast) is filled in
+const troubleshootRouteQuery = `foreach device in network.devices
+where device.name == %s
+foreach networkInstance in device.networkInstances
+foreach entry in networkInstance.afts.%s.ipEntries
+where ipAddress(%s) in entry.prefix
+select {
+  vrf: networkInstance.name,
+  prefix: toString(entry.prefix),
+  nextHops: (foreach nextHop in entry.nextHops select {
+    ip: toString(nextHop.ipAddress),
+    interface: nextHop.interfaceName
+  })
+}`
+
+// RouteLookup is the route a device matches for a destination: the longest prefix of its
+// forwarding tables that covers the address
+type RouteLookup struct {
+	Device      string   `json:"device"`
+	Destination string   `json:"destination"`
+	VRF         string   `json:"vrf,omitempty"`
+	Prefix      string   `json:"prefix,omitempty"` // Empty when no route covers the destination
+	NextHops    []string `json:"next_hops,omitempty"`
+	Interfaces  []string `json:"interfaces,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// describe renders the lookup for findings
+func (r *RouteLookup) describe() string {
+	switch {
+	case r.Error != "":
+		return fmt.Sprintf("the route for %s on %s could not be looked up (%s)", r.Destination, r.Device, r.Error)
+	case r.Prefix == "":
+		return fmt.Sprintf("%s has no route to %s", r.Device, r.Destination)
+	}
+	text := fmt.Sprintf("%s matches route %s", r.Device, r.Prefix)
+	if r.VRF != "" {
+		text += " in VRF " + r.VRF
+	}
+	var via []string
+	via = append(via, r.NextHops...)
+	via = append(via, r.Interfaces...)
+	if len(via) == 0 {
+		return text + " with no next hop (the traffic is dropped there)"
+	}
+	return text + " via " + strings.Join(via, ", ")
+}
+
+// troubleshootEndpoint is a resolved source or destination
+type troubleshootEndpoint struct {
+	Input  string
+	Device string // Set when the input was a device name
+	IP     string
+}
+
+// troubleshootConnectivity runs the common troubleshooting golden path: resolve both
+// endpoints, search paths in both directions, check ACL verdicts and forwarding, and
+// summarize the likely failure point. Intermediate artifacts are stored as memory entities.
+func (s *ForwardMCPService) troubleshootConnectivity(args TroubleshootConnectivityArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("troubleshoot_connectivity", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	if args.Source == "" || args.Destination == "" {
+		return nil, fmt.Errorf("both source and destination are required")
+	}
+
+	// Step 1: Resolve source and destination
+	src, srcErr := s.resolveTroubleshootEndpoint(networkID, args.Source)
+	if srcErr != nil && src.Device == "" {
+		return nil, fmt.Errorf("failed to resolve source '%s': %w", args.Source, srcErr)
+	}
+	dst, err := s.resolveTroubleshootEndpoint(networkID, args.Destination)
+	if err != nil || dst.IP == "" {
+		return nil, fmt.Errorf("failed to resolve destination '%s' to an IP address: %w", args.Destination, err)
+	}
+
+	// Step 2: Path search in both directions (reverse needs a source address)
+	queries := []forward.PathSearchParams{{
+		From:    src.Device,
+		SrcIP:   src.IP,
+		DstIP:   dst.IP,
+		IPProto: args.IPProto,
+		DstPort: args.DstPort,
+	}}
+	directions := []string{"forward"}
+	if src.IP != "" {
+		queries = append(queries, forward.PathSearchParams{
+			From:    dst.Device,
+			SrcIP:   dst.IP,
+			DstIP:   src.IP,
+			IPProto: args.IPProto,
+			SrcPort: args.DstPort,
+		})
+		directions = append(directions, "reverse")
+	}
+
+	request := &forward.PathSearchBulkRequest{
+		Queries:    queries,
+		Intent:     "PREFER_DELIVERED",
+		MaxResults: 5,
+	}
+	apiSnapshotID := ""
+	if snapshotID != "latest" {
+		apiSnapshotID = snapshotID
+	}
+	responses, err := s.forwardClient.SearchPathsBulk(networkID, request, apiSnapshotID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute path search: %w", err)
+	}
+
+	// Steps 3-4: ACL verdicts and route trace per direction
+	var diagnoses []ConnectivityDiagnosis
+	for i, direction := range directions {
+		var response forward.PathSearchBulkResponse
+		if i < len(responses) {
+			response = responses[i]
+		}
+		diagnosis := diagnosePathDirection(direction, queries[i], response)
+		diagnoses = append(diagnoses, diagnosis)
+	}
+
+	// Step 5: Look up the route at the failure point of forwarding failures
+	for i := range diagnoses {
+		s.lookupFailureRoute(networkID, apiSnapshotID, &diagnoses[i])
+	}
+
+	// Step 6: Summarize the likely failure point
+	summary := summarizeConnectivityDiagnoses(diagnoses)
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# Connectivity Troubleshooting: %s → %s\n\n", args.Source, args.Destination))
+	report.WriteString(fmt.Sprintf("**Source:** %s", describeEndpoint(src)))
+	if srcErr != nil {
+		report.WriteString(fmt.Sprintf(" (IP not resolved: %v; reverse path skipped)", srcErr))
+	}
+	report.WriteString(fmt.Sprintf("\n**Destination:** %s\n\n", describeEndpoint(dst)))
+	report.WriteString(fmt.Sprintf("## Summary\n%s\n\n", summary))
+
+	for _, diagnosis := range diagnoses {
+		status := "✅"
+		if !diagnosis.Delivered {
+			status = "❌"
+		}
+		report.WriteString(fmt.Sprintf("## %s %s path (%s → %s)\n", status, strings.ToUpper(diagnosis.Direction[:1])+diagnosis.Direction[1:], firstNonEmpty(diagnosis.From, diagnosis.SrcIP), diagnosis.DstIP))
+		report.WriteString(fmt.Sprintf("- Paths found: %d\n", diagnosis.PathCount))
+		if diagnosis.ForwardingOutcome != "" {
+			report.WriteString(fmt.Sprintf("- Forwarding outcome: %s\n", diagnosis.ForwardingOutcome))
+		}
+		if diagnosis.SecurityOutcome != "" {
+			report.WriteString(fmt.Sprintf("- Security (ACL) verdict: %s\n", diagnosis.SecurityOutcome))
+		}
+		if len(diagnosis.RouteTrace) > 0 {
+			report.WriteString("- Route trace:\n")
+			for _, hop := range diagnosis.RouteTrace {
+				report.WriteString(fmt.Sprintf("  - %s\n", hop))
+			}
+		}
+		if diagnosis.Route != nil {
+			report.WriteString(fmt.Sprintf("- Route lookup: %s\n", diagnosis.Route.describe()))
+		}
+		report.WriteString(fmt.Sprintf("- Finding: %s\n\n", diagnosis.Finding))
+	}
+
+	// Store intermediate artifacts for drill-down
+	if s.memorySystem != nil {
+		sessionEntityID, err := s.storeTroubleshootingArtifacts(networkID, snapshotID, args, diagnoses, responses, summary)
+		if err != nil {
+			s.logger.Warn("Failed to store troubleshooting artifacts: %v", err)
+		} else {
+			report.WriteString(fmt.Sprintf("📦 Artifacts stored in memory as entity %s (use get_relations / get_observations to drill down)\n", sessionEntityID))
+		}
+	}
+
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
+
+// resolveTroubleshootEndpoint resolves a device name or IP to an endpoint with a host IP
+func (s *ForwardMCPService) resolveTroubleshootEndpoint(networkID, input string) (troubleshootEndpoint, error) {
+	endpoint := troubleshootEndpoint{Input: strings.TrimSpace(input)}
+	if normalized, _, err := normalizeIPOrCIDR(endpoint.Input); err == nil {
+		endpoint.IP = normalized
+		return endpoint, nil
+	}
+
+	endpoint.Device = endpoint.Input
+	if devices, err := s.getNetworkDevices(networkID, ""); err == nil {
+		endpoint.Device = s.canonicalDeviceName(networkID, endpoint.Input, devices)
+	}
+	ip, err := s.resolveDeviceToIP(networkID, endpoint.Device)
+	if err != nil {
+		return endpoint, err
+	}
+	// Interface addresses may carry a prefix length; path search needs the host address
+	if parsed := parseInterfaceAddress(ip); parsed != nil {
+		ip = parsed.String()
+	}
+	endpoint.IP = ip
+	return endpoint, nil
+}
+
+// diagnosePathDirection inspects the best path returned for a direction
+func diagnosePathDirection(direction string, query forward.PathSearchParams, response forward.PathSearchBulkResponse) ConnectivityDiagnosis {
+	diagnosis := ConnectivityDiagnosis{
+		Direction: direction,
+		SrcIP:     query.SrcIP,
+		DstIP:     query.DstIP,
+		From:      query.From,
+		PathCount: len(response.Info.Paths),
+		TimedOut:  response.TimedOut,
+	}
+
+	if len(response.Info.Paths) == 0 {
+		diagnosis.Finding = "No path found - the source may not be modeled in the network or the destination is unknown"
+		if response.TimedOut {
+			diagnosis.Finding = "Path search timed out before any path was found"
+		}
+		return diagnosis
+	}
+
+	// Prefer a delivered path if any exists; otherwise diagnose the first (best-ranked) path
+	best := response.Info.Paths[0]
+	for _, path := range response.Info.Paths {
+		if isDeliveredOutcome(path.ForwardingOutcome) && !isDeniedOutcome(path.SecurityOutcome) {
+			best = path
+			diagnosis.Delivered = true
+			break
+		}
+	}
+	diagnosis.ForwardingOutcome = best.ForwardingOutcome
+	diagnosis.SecurityOutcome = best.SecurityOutcome
+
+	for _, hop := range best.Hops {
+		trace := hop.DeviceName
+		if hop.IngressInterface != "" || hop.EgressInterface != "" {
+			trace += fmt.Sprintf(" (%s → %s)", firstNonEmpty(hop.IngressInterface, "-"), firstNonEmpty(hop.EgressInterface, "-"))
+		}
+		if isTunnelHead(hop) {
+			trace += " [overlay]"
+		}
+		diagnosis.RouteTrace = append(diagnosis.RouteTrace, trace)
+	}
+
+	if diagnosis.Delivered {
+		diagnosis.Finding = fmt.Sprintf("Traffic is delivered across %d hops", len(best.Hops))
+		return diagnosis
+	}
+
+	var lastHop forward.BulkHop
+	if len(best.Hops) > 0 {
+		lastHop = best.Hops[len(best.Hops)-1]
+	}
+
+	if isDeniedOutcome(best.SecurityOutcome) {
+		diagnosis.ACLDenied = true
+		diagnosis.FailureDevice = lastHop.DeviceName
+		diagnosis.FailureInterface = firstNonEmpty(lastHop.EgressInterface, lastHop.IngressInterface)
+		// Prefer the hop whose behaviors mention an ACL or policy
+		for _, hop := range best.Hops {
+			for _, behavior := range hop.Behaviors {
+				upper := strings.ToUpper(behavior)
+				if strings.Contains(upper, "ACL") || strings.Contains(upper, "POLICY") || strings.Contains(upper, "DENY") {
+					diagnosis.FailureDevice = hop.DeviceName
+					diagnosis.FailureInterface = firstNonEmpty(hop.IngressInterface, hop.EgressInterface)
+				}
+			}
+		}
+		diagnosis.Finding = fmt.Sprintf("Traffic is blocked by security policy at %s", diagnosis.FailureDevice)
+		return diagnosis
+	}
+
+	diagnosis.FailureDevice = lastHop.DeviceName
+	diagnosis.FailureInterface = firstNonEmpty(lastHop.EgressInterface, lastHop.IngressInterface)
+	diagnosis.Finding = fmt.Sprintf("Forwarding stops at %s (%s) - inspect routes for %s on this device",
+		diagnosis.FailureDevice, firstNonEmpty(best.ForwardingOutcome, "not delivered"), query.DstIP)
+	return diagnosis
+}
+
+// lookupFailureRoute finds the route used where a direction's forwarding stops, or at the
+// source device when no path was found, and adds it to the finding. ACL denials and
+// delivered directions are left alone.
+func (s *ForwardMCPService) lookupFailureRoute(networkID, snapshotID string, diagnosis *ConnectivityDiagnosis) {
+	if diagnosis.Delivered || diagnosis.ACLDenied {
+		return
+	}
+	device := firstNonEmpty(diagnosis.FailureDevice, diagnosis.From)
+	if device == "" {
+		return
+	}
+	diagnosis.Route = s.lookupRoute(networkID, snapshotID, device, diagnosis.DstIP)
+	diagnosis.Finding += "; " + diagnosis.Route.describe()
+}
+
+// lookupRoute returns the longest prefix route of a device covering the destination
+func (s *ForwardMCPService) lookupRoute(networkID, snapshotID, device, destination string) *RouteLookup {
+	lookup := &RouteLookup{Device: device, Destination: destination}
+	address, err := netip.ParseAddr(destination)
+	if err != nil {
+		prefix, prefixErr := netip.ParsePrefix(destination)
+		if prefixErr != nil {
+			lookup.Error = "destination is not an IP address"
+			return lookup
+		}
+		address = prefix.Addr()
+	}
+	family := "ipv4Unicast"
+	if address.Is6() {
+		family = "ipv6Unicast"
+	}
+	query := fmt.Sprintf(troubleshootRouteQuery, strconv.Quote(device), family, strconv.Quote(address.String()))
+	items, err := s.fetchAllNQEQueryItems(networkID, snapshotID, query)
+	if err != nil {
+		s.logger.Debug("Route lookup for %s on %s failed: %v", destination, device, err)
+		lookup.Error = err.Error()
+		return lookup
+	}
+
+	best := -1
+	for _, item := range items {
+		prefix, err := netip.ParsePrefix(resultValueString(item["prefix"]))
+		if err != nil || !prefix.Contains(address) || prefix.Bits() <= best {
+			continue
+		}
+		best = prefix.Bits()
+		lookup.VRF, lookup.Prefix = resultValueString(item["vrf"]), prefix.Masked().String()
+		lookup.NextHops, lookup.Interfaces = nil, nil
+		hops, _ := item["nextHops"].([]interface{})
+		for _, hop := range hops {
+			record, ok := hop.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if ip := resultValueString(record["ip"]); ip != "" {
+				lookup.NextHops = append(lookup.NextHops, ip)
+			}
+			if name := resultValueString(record["interface"]); name != "" {
+				lookup.Interfaces = append(lookup.Interfaces, name)
+			}
+		}
+	}
+	return lookup
+}
+
+// summarizeConnectivityDiagnoses produces a one-paragraph conclusion across directions
+func summarizeConnectivityDiagnoses(diagnoses []ConnectivityDiagnosis) string {
+	var failures []string
+	for _, diagnosis := range diagnoses {
+		if !diagnosis.Delivered {
+			failures = append(failures, fmt.Sprintf("%s: %s", diagnosis.Direction, diagnosis.Finding))
+		}
+	}
+
+	if len(failures) == 0 {
+		if len(diagnoses) == 1 {
+			return "Forward path is delivered. Reverse path could not be checked because the source address is unknown."
+		}
+		return "Traffic is delivered in both directions. If users still report issues, check application-level or endpoint problems."
+	}
+	if len(diagnoses) == 2 && len(failures) == 1 && !diagnoses[1].Delivered {
+		return "Forward path is delivered but the return path fails (possible asymmetric routing or stateful firewall issue) - " + failures[0]
+	}
+	return "Likely failure point - " + strings.Join(failures, "; ")
+}
+
+// storeTroubleshootingArtifacts stores the session, per-direction results and findings as memory entities
+func (s *ForwardMCPService) storeTroubleshootingArtifacts(networkID, snapshotID string, args TroubleshootConnectivityArgs, diagnoses []ConnectivityDiagnosis, responses []forward.PathSearchBulkResponse, summary string) (string, error) {
+	timestamp := time.Now().Unix()
+	session, err := s.memorySystem.CreateEntity(
+		fmt.Sprintf("troubleshoot_%s_to_%s_%d", args.Source, args.Destination, timestamp),
+		"connectivity_troubleshooting",
+		map[string]interface{}{
+			"network_id":  networkID,
+			"snapshot_id": snapshotID,
+			"source":      args.Source,
+			"destination": args.Destination,
+			"timestamp":   timestamp,
+		},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create troubleshooting entity: %w", err)
+	}
+
+	if _, err := s.memorySystem.AddObservation(session.ID, summary, "summary", nil); err != nil {
+		s.logger.Debug("Failed to add troubleshooting summary: %v", err)
+	}
+
+	for i, diagnosis := range diagnoses {
+		entity, err := s.memorySystem.CreateEntity(
+			fmt.Sprintf("troubleshoot_%s_path_%s_to_%s_%d", diagnosis.Direction, firstNonEmpty(diagnosis.From, diagnosis.SrcIP), diagnosis.DstIP, timestamp),
+			"path_search",
+			map[string]interface{}{
+				"network_id": networkID,
+				"direction":  diagnosis.Direction,
+				"delivered":  diagnosis.Delivered,
+				"path_count": diagnosis.PathCount,
+			},
+		)
+		if err != nil {
+			s.logger.Debug("Failed to create %s path entity: %v", diagnosis.Direction, err)
+			continue
+		}
+		if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(diagnosis), "diagnosis", nil); err != nil {
+			s.logger.Debug("Failed to add %s diagnosis: %v", diagnosis.Direction, err)
+		}
+		if i < len(responses) {
+			if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(responses[i]), "data", nil); err != nil {
+				s.logger.Debug("Failed to add %s path data: %v", diagnosis.Direction, err)
+			}
+		}
+		if _, err := s.memorySystem.CreateRelation(session.ID, entity.ID, "has_"+diagnosis.Direction+"_path", nil); err != nil {
+			s.logger.Debug("Failed to relate %s path entity: %v", diagnosis.Direction, err)
+		}
+		if diagnosis.Route != nil {
+			s.storeRouteLookup(networkID, entity.ID, diagnosis.Direction, diagnosis.Route, timestamp)
+		}
+	}
+
+	return session.ID, nil
+}
+
+// storeRouteLookup stores the route at a failure point as its own entity, related to the path
+func (s *ForwardMCPService) storeRouteLookup(networkID, pathEntityID, direction string, route *RouteLookup, timestamp int64) {
+	entity, err := s.memorySystem.CreateEntity(
+		fmt.Sprintf("troubleshoot_%s_route_%s_to_%s_%d", direction, route.Device, route.Destination, timestamp),
+		"route_lookup",
+		map[string]interface{}{
+			"network_id":  networkID,
+			"device":      route.Device,
+			"destination": route.Destination,
+			"prefix":      route.Prefix,
+			"vrf":         route.VRF,
+		},
+	)
+	if err != nil {
+		s.logger.Debug("Failed to create %s route entity: %v", direction, err)
+		return
+	}
+	if _, err := s.memorySystem.AddObservation(entity.ID, MarshalCompactJSONString(route), "data", nil); err != nil {
+		s.logger.Debug("Failed to add %s route data: %v", direction, err)
+	}
+	if _, err := s.memorySystem.CreateRelation(pathEntityID, entity.ID, "matched_route", nil); err != nil {
+		s.logger.Debug("Failed to relate %s route entity: %v", direction, err)
+	}
+}
+
+// isDeliveredOutcome reports whether a forwarding outcome means the packet reached its destination
+func isDeliveredOutcome(outcome string) bool {
+	return strings.HasPrefix(strings.ToUpper(outcome), "DELIVERED")
+}
+
+// isDeniedOutcome reports whether a security outcome means the packet was blocked
+func isDeniedOutcome(outcome string) bool {
+	upper := strings.ToUpper(outcome)
+	return strings.Contains(upper, "DENIED") || strings.Contains(upper, "DROP")
+}
+
+// describeEndpoint renders a resolved endpoint for reports
+func describeEndpoint(endpoint troubleshootEndpoint) string {
+	if endpoint.Device != "" && endpoint.IP != "" {
+		return fmt.Sprintf("%s (%s)", endpoint.Device, endpoint.IP)
+	}
+	if endpoint.Device != "" {
+		return endpoint.Device
+	}
+	if ip := net.ParseIP(endpoint.IP); ip != nil {
+		return ip.String()
+	}
+	return endpoint.IP
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestDiagnosePathDirection(t *testing.T) {
+	query := forward.PathSearchParams{From: "edge-1", DstIP: "10.0.0.5"}
+
+	t.Run("acl_denied", func(t *testing.T) {
+		response := forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{{
+			ForwardingOutcome: "DELIVERED",
+			SecurityOutcome:   "DENIED",
+			Hops: []forward.BulkHop{
+				{DeviceName: "edge-1", EgressInterface: "ge-0/0/1"},
+				{DeviceName: "fw-1", IngressInterface: "eth1", Behaviors: []string{"ACL_DENY"}},
+				{DeviceName: "core-1"},
+			},
+		}}}}
+
+		diagnosis := diagnosePathDirection("forward", query, response)
+		if diagnosis.Delivered || !diagnosis.ACLDenied {
+			t.Fatalf("Expected ACL-denied, undelivered diagnosis, got %+v", diagnosis)
+		}
+		if diagnosis.FailureDevice != "fw-1" || diagnosis.FailureInterface != "eth1" {
+			t.Errorf("Expected failure at fw-1/eth1, got %s/%s", diagnosis.FailureDevice, diagnosis.FailureInterface)
+		}
+	})
+
+	t.Run("forwarding_drop", func(t *testing.T) {
+		response := forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{{
+			ForwardingOutcome: "BLACKHOLE",
+			SecurityOutcome:   "PERMITTED",
+			Hops:              []forward.BulkHop{{DeviceName: "edge-1"}, {DeviceName: "core-2", EgressInterface: "null0"}},
+		}}}}
+
+		diagnosis := diagnosePathDirection("forward", query, response)
+		if diagnosis.FailureDevice != "core-2" || diagnosis.ACLDenied {
+			t.Errorf("Expected forwarding failure at core-2, got %+v", diagnosis)
+		}
+		if len(diagnosis.RouteTrace) != 2 {
+			t.Errorf("Expected 2-hop route trace, got %v", diagnosis.RouteTrace)
+		}
+	})
+
+	t.Run("no_paths", func(t *testing.T) {
+		diagnosis := diagnosePathDirection("reverse", query, forward.PathSearchBulkResponse{})
+		if diagnosis.Delivered || diagnosis.PathCount != 0 || diagnosis.Finding == "" {
+			t.Errorf("Expected undelivered diagnosis with a finding, got %+v", diagnosis)
+		}
+	})
+}
+
+func TestSummarizeConnectivityDiagnoses(t *testing.T) {
+	summary := summarizeConnectivityDiagnoses([]ConnectivityDiagnosis{
+		{Direction: "forward", Delivered: true},
+		{Direction: "reverse", Finding: "Forwarding stops at core-2"},
+	})
+	if !contains(summary, "return path fails") {
+		t.Errorf("Expected asymmetric failure summary, got: %s", summary)
+	}
+}
+
+func TestTroubleshootConnectivity(t *testing.T) {
+	service := createTestService()
+
+	response, err := service.troubleshootConnectivity(TroubleshootConnectivityArgs{
+		NetworkID:   "162112",
+		Source:      "router-1",
+		Destination: "10.0.0.100",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	text := response.Content[0].TextContent.Text
+	if !contains(text, "router-1 (192.168.1.1)") {
+		t.Errorf("Expected source device to resolve to its management IP, got: %s", text)
+	}
+	if !contains(text, "Forward path") || !contains(text, "Reverse path") {
+		t.Errorf("Expected both directions in report, got: %s", text)
+	}
+	if !contains(text, "Artifacts stored in memory") {
+		t.Error("Expected artifacts to be stored in memory")
+	}
+}
+
+// routeTableClient drops every path at core-2 and serves core-2's covering routes
+type routeTableClient struct {
+	*MockForwardClient
+	routes []map[string]interface{}
+	query  string
+}
+
+func (c *routeTableClient) SearchPathsBulk(networkID string, request *forward.PathSearchBulkRequest, snapshotID string) ([]forward.PathSearchBulkResponse, error) {
+	var responses []forward.PathSearchBulkResponse
+	for range request.Queries {
+		responses = append(responses, forward.PathSearchBulkResponse{Info: forward.PathSearchInfo{Paths: []forward.BulkPath{{
+			ForwardingOutcome: "BLACKHOLE",
+			SecurityOutcome:   "PERMITTED",
+			Hops:              []forward.BulkHop{{DeviceName: "router-1"}, {DeviceName: "core-2", EgressInterface: "null0"}},
+		}}}})
+	}
+	return responses, nil
+}
+
+func (c *routeTableClient) RunNQEQueryByString(params *forward.NQEQueryParams) (*forward.NQERunResult, error) {
+	c.query = params.Query
+	result := &forward.NQERunResult{}
+	for _, route := range c.routes {
+		result.Items = append(result.Items, route)
+	}
+	return result, nil
+}
+
+func TestTroubleshootConnectivityRouteLookup(t *testing.T) {
+	service := createTestService()
+	client := &routeTableClient{MockForwardClient: NewMockForwardClient(), routes: []map[string]interface{}{
+		{"vrf": "default", "prefix": "0.0.0.0/0", "nextHops": []interface{}{map[string]interface{}{"ip": "192.0.2.1", "interface": "ge-0/0/0"}}},
+		{"vrf": "default", "prefix": "10.0.0.0/24", "nextHops": []interface{}{map[string]interface{}{"interface": "null0"}}},
+		{"vrf": "default", "prefix": "10.0.0.0/8", "nextHops": []interface{}{map[string]interface{}{"ip": "192.0.2.2"}}},
+	}}
+	service.forwardClient = client
+	// Lookups stored by earlier runs are still in memory, so count them first
+	storedBefore := countRouteLookups(t, service)
+
+	response, err := service.troubleshootConnectivity(TroubleshootConnectivityArgs{
+		NetworkID:   "162112",
+		Source:      "router-1",
+		Destination: "10.0.0.100",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !strings.Contains(client.query, `device.name == "core-2"`) || !strings.Contains(client.query, "ipv4Unicast") {
+		t.Errorf("Expected core-2's IPv4 routes queried, got:\n%s", client.query)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "core-2 matches route 10.0.0.0/24 in VRF default via null0") {
+		t.Errorf("Expected the longest matching route in the summary, got: %s", text)
+	}
+
+	if stored := countRouteLookups(t, service) - storedBefore; stored != 1 {
+		t.Errorf("Expected the forward route lookup stored as a route_lookup entity, got %d", stored)
+	}
+
+	client.routes = nil
+	route := service.lookupRoute("162112", "", "core-2", "10.0.0.100")
+	if route.Prefix != "" || route.describe() != "core-2 has no route to 10.0.0.100" {
+		t.Errorf("Expected no route reported, got %+v", route)
+	}
+}
+
+// countRouteLookups counts the stored route lookups of core-2 for 10.0.0.0/24
+func countRouteLookups(t *testing.T, service *ForwardMCPService) int {
+	routes, err := service.memorySystem.SearchEntities("", "route_lookup", 100000)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	count := 0
+	for _, entity := range routes {
+		if entity.Metadata["device"] == "core-2" && entity.Metadata["prefix"] == "10.0.0.0/24" {
+			count++
+		}
+	}
+	return count
+}
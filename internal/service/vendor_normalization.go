@@ -0,0 +1,216 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/logger"
+)
+
+// DeviceFacts is the canonical, vendor-neutral view of a device's platform
+type DeviceFacts struct {
+	Vendor  string `json:"vendor"`
+	Family  string `json:"family,omitempty"`
+	OSTrain string `json:"os_train,omitempty"`
+	EOLKey  string `json:"eol_key,omitempty"`
+}
+
+// vendorMapping is a compiled vendor mapping rule
+type vendorMapping struct {
+	pattern *regexp.Regexp
+	vendor  string
+	family  string
+	osTrain string
+	eolKey  string
+}
+
+// builtinVendorMappings are evaluated in order, so more specific families come first
+var builtinVendorMappings = []config.VendorMappingRule{
+	{Match: `nx[- ]?os|nexus`, Vendor: "cisco", Family: "nx_os"},
+	{Match: `ios[- ]?xr|xrv`, Vendor: "cisco", Family: "ios_xr"},
+	{Match: `ios[- ]?xe|catalyst 9|cat9k|csr1000v|c8000`, Vendor: "cisco", Family: "ios_xe"},
+	{Match: `\basa\b|adaptive security|firepower`, Vendor: "cisco", Family: "asa"},
+	{Match: `cisco|\bios\b`, Vendor: "cisco", Family: "ios"},
+	{Match: `junos|juniper|\bsrx\d*|\bmx\d+|\bqfx\d*|\bex\d+`, Vendor: "juniper", Family: "junos"},
+	{Match: `arista|\beos\b`, Vendor: "arista", Family: "eos"},
+	{Match: `pan[- ]?os|palo ?alto`, Vendor: "palo_alto", Family: "pan_os"},
+	{Match: `forti`, Vendor: "fortinet", Family: "fortios"},
+	{Match: `\bf5\b|big[- ]?ip|tmos`, Vendor: "f5", Family: "tmos"},
+	{Match: `check ?point|gaia`, Vendor: "checkpoint", Family: "gaia"},
+	{Match: `cumulus`, Vendor: "nvidia", Family: "cumulus_linux"},
+	{Match: `\baws\b|amazon`, Vendor: "aws", Family: "cloud"},
+	{Match: `azure`, Vendor: "azure", Family: "cloud"},
+	{Match: `\bgcp\b|google`, Vendor: "gcp", Family: "cloud"},
+	{Match: `linux|ubuntu|centos|debian|rhel`, Vendor: "linux", Family: "linux"},
+}
+
+// osTrainPattern extracts the major.minor release from version strings such as
+// 15.2(4)M, 9.3(8), 21.4R3-S2, 4.28.3M or 10.1.6-h6
+var osTrainPattern = regexp.MustCompile(`(\d+)(?:\.(\d+))?`)
+
+// Field names (lowercased, without separators) that carry each raw fact in NQE results
+var (
+	vendorFactKeys   = []string{"vendor", "platformvendor", "devicevendor", "manufacturer"}
+	platformFactKeys = []string{"platform", "os", "ostype", "devicetype", "type", "osname"}
+	versionFactKeys  = []string{"osversion", "version", "softwareversion", "osver"}
+	modelFactKeys    = []string{"model", "hardwaremodel", "platformmodel", "devicemodel"}
+)
+
+// deviceFactQueryIDs are the inventory and support queries whose rows are annotated
+var deviceFactQueryIDs = map[string]bool{
+	"FQ_ac651cb2901b067fe7dbfb511613ab44776d8029": true, // Device Basic Info
+	"FQ_7ec4a8148b48a91271f342c512b2af1cdb276744": true, // Device Hardware
+	"FQ_f0984b777b940b4376ed3ec4317ad47437426e7c": true, // Hardware Support
+	"FQ_fc33d9fd70ba19a18455b0e4d26ca8420003d9cc": true, // OS Support
+}
+
+// VendorNormalizer maps vendor-specific platform, OS and hardware values into DeviceFacts.
+// A nil normalizer uses the built-in mapping table only.
+type VendorNormalizer struct {
+	mappings []vendorMapping
+}
+
+// NewVendorNormalizer creates a normalizer with custom rules evaluated before the built-in table.
+// Rules with invalid patterns are skipped and logged.
+func NewVendorNormalizer(custom []config.VendorMappingRule, logger *logger.Logger) *VendorNormalizer {
+	normalizer := &VendorNormalizer{}
+	for _, rule := range append(append([]config.VendorMappingRule{}, custom...), builtinVendorMappings...) {
+		mapping, err := compileVendorMapping(rule)
+		if err != nil {
+			if logger != nil {
+				logger.Warn("Skipping vendor mapping: %v", err)
+			}
+			continue
+		}
+		normalizer.mappings = append(normalizer.mappings, mapping)
+	}
+	return normalizer
+}
+
+// compileVendorMapping validates and compiles a vendor mapping rule
+func compileVendorMapping(rule config.VendorMappingRule) (vendorMapping, error) {
+	if rule.Match == "" || rule.Vendor == "" {
+		return vendorMapping{}, fmt.Errorf("rule requires match and vendor (match=%q)", rule.Match)
+	}
+	pattern, err := regexp.Compile("(?i)" + rule.Match)
+	if err != nil {
+		return vendorMapping{}, fmt.Errorf("invalid match pattern %q: %w", rule.Match, err)
+	}
+	return vendorMapping{
+		pattern: pattern,
+		vendor:  strings.ToLower(rule.Vendor),
+		family:  strings.ToLower(rule.Family),
+		osTrain: rule.OSTrain,
+		eolKey:  rule.EOLKey,
+	}, nil
+}
+
+var defaultVendorNormalizer = NewVendorNormalizer(nil, nil)
+
+// Normalize maps raw vendor, platform, version and model values into DeviceFacts.
+// Unrecognized devices keep their lowercased vendor with no family.
+func (n *VendorNormalizer) Normalize(vendor, platform, version, model string) DeviceFacts {
+	if n == nil {
+		n = defaultVendorNormalizer
+	}
+
+	// Underscores are treated as separators so enum values like CISCO_NX_OS match word boundaries
+	subject := strings.ReplaceAll(strings.Join([]string{vendor, platform, model}, " "), "_", " ")
+
+	facts := DeviceFacts{OSTrain: osTrainFromVersion(version)}
+	for _, mapping := range n.mappings {
+		if !mapping.pattern.MatchString(subject) {
+			continue
+		}
+		facts.Vendor = mapping.vendor
+		facts.Family = mapping.family
+		if mapping.osTrain != "" {
+			facts.OSTrain = mapping.osTrain
+		}
+		facts.EOLKey = mapping.eolKey
+		break
+	}
+
+	if facts.Vendor == "" {
+		facts.Vendor = strings.ToLower(strings.TrimSpace(vendor))
+	}
+	if facts.EOLKey == "" && facts.Vendor != "" && facts.Family != "" {
+		facts.EOLKey = facts.Vendor + ":" + facts.Family
+		if facts.OSTrain != "" {
+			facts.EOLKey += ":" + facts.OSTrain
+		}
+	}
+	return facts
+}
+
+// NormalizeDevice normalizes the facts of a device returned by the devices API
+func (n *VendorNormalizer) NormalizeDevice(device forward.Device) DeviceFacts {
+	version := device.OSVersion
+	if version == "" {
+		version = device.Version
+	}
+	return n.Normalize(device.Vendor, device.Platform+" "+device.Type, version, device.Model)
+}
+
+// NormalizeItem normalizes the facts in an NQE result row. It reports false when the
+// row carries no vendor, platform or model fields.
+func (n *VendorNormalizer) NormalizeItem(item map[string]interface{}) (DeviceFacts, bool) {
+	fields := make(map[string]string, len(item))
+	for key, value := range item {
+		if s, ok := value.(string); ok {
+			fields[normalizeFactKey(key)] = s
+		}
+	}
+
+	vendor := firstFactValue(fields, vendorFactKeys)
+	platform := firstFactValue(fields, platformFactKeys)
+	model := firstFactValue(fields, modelFactKeys)
+	if vendor == "" && platform == "" && model == "" {
+		return DeviceFacts{}, false
+	}
+	return n.Normalize(vendor, platform, firstFactValue(fields, versionFactKeys), model), true
+}
+
+// normalizeDeviceFactItems adds a "normalized" field to rows of inventory and support queries
+func (s *ForwardMCPService) normalizeDeviceFactItems(queryID string, items []map[string]interface{}) {
+	if !deviceFactQueryIDs[queryID] {
+		return
+	}
+	for _, item := range items {
+		if facts, ok := s.vendorNormalizer.NormalizeItem(item); ok {
+			item["normalized"] = facts
+		}
+	}
+}
+
+// osTrainFromVersion returns the major.minor release train of a version string
+func osTrainFromVersion(version string) string {
+	match := osTrainPattern.FindStringSubmatch(version)
+	if match == nil {
+		return ""
+	}
+	if match[2] == "" {
+		return match[1]
+	}
+	return match[1] + "." + match[2]
+}
+
+// normalizeFactKey lowercases a field name and strips separators so "OS Version",
+// "os_version" and "osVersion" compare equal
+func normalizeFactKey(key string) string {
+	replacer := strings.NewReplacer("_", "", "-", "", " ", "", ".", "")
+	return strings.ToLower(replacer.Replace(key))
+}
+
+// firstFactValue returns the first non-empty value among the candidate keys
+func firstFactValue(fields map[string]string, keys []string) string {
+	for _, key := range keys {
+		if value := strings.TrimSpace(fields[key]); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/forward"
+)
+
+func TestVendorNormalizerBuiltins(t *testing.T) {
+	normalizer := NewVendorNormalizer(nil, nil)
+
+	tests := []struct {
+		vendor, platform, version, model string
+		expected                         DeviceFacts
+	}{
+		{"CISCO", "CISCO_NX_OS", "9.3(8)", "N9K-C93180YC-EX", DeviceFacts{"cisco", "nx_os", "9.3", "cisco:nx_os:9.3"}},
+		{"Cisco Systems", "IOS-XE", "17.03.04a", "C9300-48P", DeviceFacts{"cisco", "ios_xe", "17.03", "cisco:ios_xe:17.03"}},
+		{"", "cisco_ios", "15.2(4)M", "", DeviceFacts{"cisco", "ios", "15.2", "cisco:ios:15.2"}},
+		{"JUNIPER", "JUNIPER_JUNOS", "21.4R3-S2", "MX204", DeviceFacts{"juniper", "junos", "21.4", "juniper:junos:21.4"}},
+		{"Arista Networks", "", "4.28.3M", "DCS-7050", DeviceFacts{"arista", "eos", "4.28", "arista:eos:4.28"}},
+		{"PALO_ALTO_NETWORKS", "PAN_OS", "10.1.6-h6", "PA-5220", DeviceFacts{"palo_alto", "pan_os", "10.1", "palo_alto:pan_os:10.1"}},
+		{"Acme", "AcmeOS", "", "", DeviceFacts{Vendor: "acme"}},
+	}
+
+	for _, tt := range tests {
+		got := normalizer.Normalize(tt.vendor, tt.platform, tt.version, tt.model)
+		if got != tt.expected {
+			t.Errorf("Normalize(%q, %q, %q, %q) = %+v; want %+v", tt.vendor, tt.platform, tt.version, tt.model, got, tt.expected)
+		}
+	}
+}
+
+func TestVendorNormalizerCustomMappings(t *testing.T) {
+	normalizer := NewVendorNormalizer([]config.VendorMappingRule{
+		{Match: `acmeos`, Vendor: "Acme", Family: "acme_os", EOLKey: "acme-lifecycle"},
+		{Match: `nexus`, Vendor: "cisco", Family: "nexus_legacy"},
+		{Match: `(`, Vendor: "broken"}, // Invalid patterns are skipped
+	}, nil)
+
+	if got := normalizer.Normalize("Acme", "AcmeOS", "3.1", ""); got != (DeviceFacts{"acme", "acme_os", "3.1", "acme-lifecycle"}) {
+		t.Errorf("Unexpected custom mapping result: %+v", got)
+	}
+	// Custom rules take precedence over built-ins
+	if got := normalizer.Normalize("Cisco", "Nexus", "7.0", ""); got.Family != "nexus_legacy" {
+		t.Errorf("Expected custom rule to override built-in, got %+v", got)
+	}
+}
+
+func TestVendorNormalizerItems(t *testing.T) {
+	var normalizer *VendorNormalizer // nil normalizer falls back to built-ins
+
+	facts, ok := normalizer.NormalizeItem(map[string]interface{}{"Vendor": "ARISTA", "OS Version": "4.30.1F"})
+	if !ok || facts.EOLKey != "arista:eos:4.30" {
+		t.Errorf("Expected arista facts, got %+v (ok=%v)", facts, ok)
+	}
+	if _, ok := normalizer.NormalizeItem(map[string]interface{}{"interface": "eth0"}); ok {
+		t.Error("Expected rows without vendor fields to be skipped")
+	}
+
+	device := forward.Device{Name: "core-1", Vendor: "JUNIPER", Platform: "junos", Version: "22.2R1"}
+	if got := normalizer.NormalizeDevice(device); got.EOLKey != "juniper:junos:22.2" {
+		t.Errorf("Unexpected device facts: %+v", got)
+	}
+}
+
+func TestListDevicesIncludesNormalizedFacts(t *testing.T) {
+	service := createTestService()
+	service.forwardClient.(*MockForwardClient).devices = []forward.Device{
+		{Name: "edge-1", Vendor: "CISCO", Platform: "ios_xr", OSVersion: "7.5.2"},
+	}
+
+	response, err := service.listDevices(ListDevicesArgs{NetworkID: "162112"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, `"eol_key":"cisco:ios_xr:7.5"`) {
+		t.Errorf("Expected normalized facts in device list, got: %s", response.Content[0].TextContent.Text)
+	}
+}
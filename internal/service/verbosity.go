@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Verbosity controls how much presentation text tool responses carry
+type Verbosity string
+
+const (
+	VerbosityVerbose Verbosity = "verbose" // Responses as the tools write them
+	VerbosityNormal  Verbosity = "normal"  // Without tips and coaching sections
+	VerbosityTerse   Verbosity = "terse"   // Also without follow-up suggestions and emoji; data and status only
+
+	verbosityArgument = "verbosity" // Argument of any tool call overriding the configured verbosity
+)
+
+// ParseVerbosity parses verbose, normal or terse
+func ParseVerbosity(value string) (Verbosity, error) {
+	switch verbosity := Verbosity(strings.ToLower(strings.TrimSpace(value))); verbosity {
+	case VerbosityVerbose, VerbosityNormal, VerbosityTerse:
+		return verbosity, nil
+	}
+	return "", fmt.Errorf("unknown verbosity %q; use verbose, normal or terse", value)
+}
+
+// NewVerbosity returns the configured default verbosity. Empty or unknown values fall back to
+// verbose.
+func NewVerbosity(value string, logger *logger.Logger) Verbosity {
+	if strings.TrimSpace(value) == "" {
+		return VerbosityVerbose
+	}
+	verbosity, err := ParseVerbosity(value)
+	if err != nil {
+		if logger != nil {
+			logger.Warn("%v, using verbose", err)
+		}
+		return VerbosityVerbose
+	}
+	return verbosity
+}
+
+type verbosityContextKey struct{}
+
+// verbosityFromContext returns the verbosity a tool call asked for, if any
+func verbosityFromContext(ctx context.Context) (Verbosity, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	verbosity, ok := ctx.Value(verbosityContextKey{}).(Verbosity)
+	return verbosity, ok
+}
+
+// takeVerbosity removes the verbosity argument from a tools/call request and carries it in
+// the context instead, so tools never see it and their input schemas stay unchanged
+func takeVerbosity(ctx context.Context, params json.RawMessage) (context.Context, json.RawMessage, error) {
+	var call map[string]json.RawMessage
+	if err := json.Unmarshal(params, &call); err != nil || len(call["arguments"]) == 0 {
+		return ctx, params, nil
+	}
+	var arguments map[string]interface{}
+	if err := json.Unmarshal(call["arguments"], &arguments); err != nil {
+		return ctx, params, nil
+	}
+	value, ok := arguments[verbosityArgument]
+	if !ok {
+		return ctx, params, nil
+	}
+	delete(arguments, verbosityArgument)
+	if value != nil {
+		verbosity, err := ParseVerbosity(fmt.Sprint(value))
+		if err != nil {
+			return ctx, nil, err
+		}
+		ctx = context.WithValue(ctx, verbosityContextKey{}, verbosity)
+	}
+
+	raw, err := json.Marshal(arguments)
+	if err != nil {
+		return ctx, nil, err
+	}
+	call["arguments"] = raw
+	params, err = json.Marshal(call)
+	return ctx, params, err
+}
+
+// verbosityToolHandler returns a context-aware handler with the same signature whose response
+// is trimmed to the verbosity of the call, or the configured verbosity when the call names
+// none. The response is copied, since identical calls may share it.
+func (s *ForwardMCPService) verbosityToolHandler(handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if handlerType.Kind() != reflect.Func || handlerType.NumIn() != 2 || handlerType.In(0) != contextType ||
+		handlerType.NumOut() == 0 || handlerType.Out(0) != toolResponseType {
+		return handler
+	}
+	return reflect.MakeFunc(handlerType, func(args []reflect.Value) []reflect.Value {
+		results := value.Call(args)
+		verbosity := s.verbosity
+		if ctx, _ := args[0].Interface().(context.Context); ctx != nil {
+			if requested, ok := verbosityFromContext(ctx); ok {
+				verbosity = requested
+			}
+		}
+		if response, ok := results[0].Interface().(*mcp.ToolResponse); ok && response != nil {
+			results[0] = reflect.ValueOf(applyVerbosity(response, verbosity))
+		}
+		return results
+	}).Interface()
+}
+
+// applyVerbosity returns a copy of a response with its text trimmed to verbosity
+func applyVerbosity(response *mcp.ToolResponse, verbosity Verbosity) *mcp.ToolResponse {
+	if verbosity != VerbosityNormal && verbosity != VerbosityTerse {
+		return response
+	}
+	contents := make([]*mcp.Content, 0, len(response.Content))
+	for _, content := range response.Content {
+		if content != nil && content.TextContent != nil {
+			content = mcp.NewTextContent(trimToVerbosity(content.TextContent.Text, verbosity))
+		}
+		contents = append(contents, content)
+	}
+	return mcp.NewToolResponse(contents...)
+}
+
+var (
+	// Lines that coach rather than report, dropped from normal and terse responses
+	tipLinePattern = regexp.MustCompile(`^(?:[-*•]\s+)?(?:\*\*)?(?:💡|(?:Pro )?Tips?:)`)
+	// Headings of coaching sections
+	coachingHeadingPattern = regexp.MustCompile(`(?i)^(?:#+\s*|\*\*)?(?:💡\s*)?(?:pro tips|tips|usage tips|next steps|suggested next steps|best practices|common use cases|use cases|try next|what's next)\s*:?\s*(?:\*\*)?\s*:?$`)
+	// Follow-up suggestions, dropped from terse responses
+	followUpLinePattern = regexp.MustCompile(`^(?:[-*•]\s+|\d+\.\s+)?(?:Next,|Next step|You can |Try |(?:Use|Call|Run) '?[a-z]+_[a-z_]+)`)
+	headingLinePattern  = regexp.MustCompile(`^(?:#+\s|\*\*[^*]+\*\*:?$)`)
+	blankLinesPattern   = regexp.MustCompile(`\n{3,}`)
+)
+
+// statusEmoji keep their meaning in terse responses as short text markers
+var statusEmoji = strings.NewReplacer(
+	"✅", "[ok]", "✔️", "[ok]", "✔", "[ok]", "✓", "[ok]", "🟢", "[ok]",
+	"❌", "[fail]", "✖️", "[fail]", "✗", "[fail]", "🔴", "[fail]",
+	"⚠️", "[warn]", "⚠", "[warn]", "🟡", "[partial]", "⏳", "[pending]",
+)
+
+// trimToVerbosity drops tips and coaching sections from text, and for terse also follow-up
+// suggestions and emoji. JSON documents and fenced code blocks are data and left untouched.
+func trimToVerbosity(text string, verbosity Verbosity) string {
+	if trimmed := strings.TrimSpace(text); (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return text
+	}
+
+	var lines []string
+	// A coaching section runs from its heading to the first blank line after its body
+	inFence, inCoaching, coachingBody := false, false, false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inFence = !inFence
+			inCoaching = false
+			lines = append(lines, line)
+			continue
+		}
+		if inFence {
+			lines = append(lines, line)
+			continue
+		}
+		if inCoaching {
+			if trimmed == "" && !coachingBody {
+				continue
+			}
+			if trimmed != "" && !headingLinePattern.MatchString(trimmed) {
+				coachingBody = true
+				continue
+			}
+			inCoaching = false
+		}
+		switch {
+		case coachingHeadingPattern.MatchString(trimmed):
+			inCoaching, coachingBody = true, false
+			continue
+		case tipLinePattern.MatchString(trimmed):
+			continue
+		case verbosity == VerbosityTerse && followUpLinePattern.MatchString(trimmed):
+			continue
+		}
+		if verbosity == VerbosityTerse {
+			line = stripEmoji(statusEmoji.Replace(line))
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// stripEmoji removes pictographic emoji with the space that follows them
+func stripEmoji(line string) string {
+	var out strings.Builder
+	skipSpace := false
+	for _, r := range line {
+		if isEmoji(r) {
+			skipSpace = true
+			continue
+		}
+		if skipSpace && r == ' ' {
+			skipSpace = false
+			continue
+		}
+		skipSpace = false
+		out.WriteRune(r)
+	}
+	return strings.TrimRight(out.String(), " ")
+}
+
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // Pictographs, emoticons, transport, symbols
+		r >= 0x2600 && r <= 0x27BF, // Miscellaneous symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF, // Stars and arrows such as ⭐
+		r >= 0x23E9 && r <= 0x23FA, // Media and clock symbols such as ⏱
+		r == 0x231A || r == 0x231B, // Watch and hourglass
+		r == 0xFE0F || r == 0x200D: // Emoji presentation selector and joiner
+		return true
+	}
+	return false
+}
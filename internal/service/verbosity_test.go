@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	mcp "github.com/metoro-io/mcp-golang"
+	"github.com/metoro-io/mcp-golang/transport"
+)
+
+const verbosityTestText = `## 🔍 Devices
+
+✅ Found 2 devices
+| Device | Status |
+|---|---|
+| core-1 | ✅ up |
+| edge-1 | ⚠️ degraded |
+
+💡 Tip: Use set_default_network to skip network_id.
+
+**Next Steps:**
+
+1. Run get_device_details on edge-1
+2. Check its interfaces
+
+Use get_config_diff with device=edge-1 to compare configs.
+` + "```json\n{\"state\": \"✅\"}\n```"
+
+func TestTrimToVerbosity(t *testing.T) {
+	normal := trimToVerbosity(verbosityTestText, VerbosityNormal)
+	for _, expected := range []string{"## 🔍 Devices", "| edge-1 | ⚠️ degraded |", "Use get_config_diff with device=edge-1"} {
+		if !strings.Contains(normal, expected) {
+			t.Errorf("Expected %q in normal output: %s", expected, normal)
+		}
+	}
+	for _, unexpected := range []string{"Tip", "Next Steps", "get_device_details", "Check its interfaces"} {
+		if strings.Contains(normal, unexpected) {
+			t.Errorf("Expected %q dropped from normal output: %s", unexpected, normal)
+		}
+	}
+
+	terse := trimToVerbosity(verbosityTestText, VerbosityTerse)
+	for _, expected := range []string{"## Devices", "[ok] Found 2 devices", "| core-1 | [ok] up |", "| edge-1 | [warn] degraded |", "{\"state\": \"✅\"}"} {
+		if !strings.Contains(terse, expected) {
+			t.Errorf("Expected %q in terse output: %s", expected, terse)
+		}
+	}
+	if strings.Contains(terse, "get_config_diff") || strings.Contains(terse, "🔍") || strings.Contains(terse, "\n\n\n") {
+		t.Errorf("Expected follow-ups and emoji dropped from terse output: %s", terse)
+	}
+
+	if document := `{"note": "💡 Tip: kept"}`; trimToVerbosity(document, VerbosityTerse) != document {
+		t.Error("Expected JSON documents left untouched")
+	}
+}
+
+func TestTakeVerbosity(t *testing.T) {
+	ctx, params, err := takeVerbosity(context.Background(), json.RawMessage(`{"name": "list_devices", "arguments": {"verbosity": "Terse", "limit": 5}}`))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if verbosity, ok := verbosityFromContext(ctx); !ok || verbosity != VerbosityTerse {
+		t.Errorf("Expected terse in the context, got %q", verbosity)
+	}
+	if strings.Contains(string(params), "verbosity") || !strings.Contains(string(params), `"limit":5`) {
+		t.Errorf("Expected only the verbosity argument removed, got %s", params)
+	}
+
+	unchanged := json.RawMessage(`{"name": "list_devices", "arguments": {"limit": 5}}`)
+	if _, params, _ := takeVerbosity(context.Background(), unchanged); string(params) != string(unchanged) {
+		t.Errorf("Expected calls without verbosity unchanged, got %s", params)
+	}
+	if _, _, err := takeVerbosity(context.Background(), json.RawMessage(`{"name": "list_devices", "arguments": {"verbosity": "loud"}}`)); err == nil {
+		t.Error("Expected an unknown verbosity to be rejected")
+	}
+}
+
+func TestVerbosityToolHandler(t *testing.T) {
+	service := createTestService()
+	service.verbosity = VerbosityNormal
+	shared := mcp.NewToolResponse(mcp.NewTextContent(verbosityTestText))
+	handler := service.verbosityToolHandler(func(ctx context.Context, args ListDevicesArgs) (*mcp.ToolResponse, error) {
+		return shared, nil
+	}).(func(context.Context, ListDevicesArgs) (*mcp.ToolResponse, error))
+
+	response, _ := handler(context.Background(), ListDevicesArgs{})
+	if text := response.Content[0].TextContent.Text; strings.Contains(text, "Tip") || !strings.Contains(text, "🔍") {
+		t.Errorf("Expected the configured normal verbosity: %s", text)
+	}
+	response, _ = handler(context.WithValue(context.Background(), verbosityContextKey{}, VerbosityVerbose), ListDevicesArgs{})
+	if response != shared {
+		t.Error("Expected a verbose call to return the response unchanged")
+	}
+	if !strings.Contains(shared.Content[0].TextContent.Text, "Tip") {
+		t.Error("Expected the shared response not to be modified")
+	}
+}
+
+func TestPresetTransportVerbosity(t *testing.T) {
+	service := presetTestService()
+	service.presets.Set("", ArgumentPreset{Name: "pipeline", Arguments: map[string]interface{}{"verbosity": "terse"}})
+	inner := &fakeTransport{}
+	wrapped := service.WithArgumentPresets(inner)
+
+	var verbosities []Verbosity
+	wrapped.SetMessageHandler(func(ctx context.Context, message *transport.BaseJsonRpcMessage) {
+		verbosity, _ := verbosityFromContext(ctx)
+		verbosities = append(verbosities, verbosity)
+	})
+	call := func(id int, params string) {
+		inner.handler(context.Background(), transport.NewBaseMessageRequest(&transport.BaseJSONRPCRequest{
+			Id: transport.RequestId(id), Jsonrpc: "2.0", Method: "tools/call", Params: json.RawMessage(params)}))
+	}
+
+	call(1, `{"name": "list_devices", "arguments": {"preset": "pipeline"}}`)
+	call(2, `{"name": "list_devices", "arguments": {"preset": "pipeline", "verbosity": "normal"}}`)
+	if len(verbosities) != 2 || verbosities[0] != VerbosityTerse || verbosities[1] != VerbosityNormal {
+		t.Errorf("Expected the preset verbosity unless the call overrides it, got %v", verbosities)
+	}
+
+	call(3, `{"name": "list_devices", "arguments": {"verbosity": "chatty"}}`)
+	if len(verbosities) != 2 || len(inner.sent) != 1 || !strings.Contains(inner.sent[0].JsonRpcError.Error.Message, "unknown verbosity") {
+		t.Errorf("Expected the call rejected with an error response, got %+v", inner.sent)
+	}
+}
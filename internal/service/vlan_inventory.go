@@ -0,0 +1,601 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// vlanPortsQuery returns one row per switched interface with its VLAN configuration and
+// link peer; aggregation per device, trunk and site happens locally
+const vlanPortsQuery = `foreach device in network.devices
+foreach iface in device.interfaces
+where isPresent(iface.ethernet.switchedVlan)
+let vlan = iface.ethernet.switchedVlan
+let peer = (foreach link in iface.links select link)
+select {
+  device: device.name,
+  location: device.locationName,
+  interface: iface.name,
+  mode: vlan.vlanMode,
+  accessVlan: vlan.accessVlan,
+  nativeVlan: vlan.nativeVlan,
+  trunkVlans: (foreach r in vlan.trunkVlans select toString(r.start) + "-" + toString(r.end)),
+  peerDevice: (foreach l in peer select l.deviceName),
+  peerInterface: (foreach l in peer select l.ifaceName)
+}`
+
+const (
+	maxVLANID          = 4094
+	maxVLANReportRows  = 50
+	vlanModeTrunk      = "trunk"
+	vlanModeAccess     = "access"
+	vlanUnknownSite    = "unknown"
+	vlanIssueMissing   = "vlan_missing_on_peer"
+	vlanIssueAllowed   = "allowed_vlan_mismatch"
+	vlanIssueNative    = "native_vlan_mismatch"
+	vlanIssueMode      = "mode_mismatch"
+	vlanIssueNoPeerEnd = "peer_not_switched"
+)
+
+// sviNamePattern matches VLAN interfaces such as Vlan100, vlan.100 or irb.100
+var sviNamePattern = regexp.MustCompile(`(?i)^(?:vlan|irb|bvi)[.\-]?(\d+)$`)
+
+// VLANPort is the VLAN configuration of one switched interface
+type VLANPort struct {
+	Device        string `json:"device"`
+	Location      string `json:"location"`
+	Interface     string `json:"interface"`
+	Mode          string `json:"mode"`
+	AccessVLAN    int    `json:"access_vlan,omitempty"`
+	NativeVLAN    int    `json:"native_vlan,omitempty"`
+	AllowedVLANs  []int  `json:"-"`
+	Allowed       string `json:"allowed_vlans,omitempty"`
+	PeerDevice    string `json:"peer_device,omitempty"`
+	PeerInterface string `json:"peer_interface,omitempty"`
+}
+
+// VLANDevice summarizes the VLANs of one device
+type VLANDevice struct {
+	Device      string `json:"device"`
+	Location    string `json:"location"`
+	VLANs       []int  `json:"vlans"`
+	AccessPorts int    `json:"access_ports"`
+	Trunks      int    `json:"trunks"`
+}
+
+// VLANSiteSummary aggregates devices, VLANs and issues per site
+type VLANSiteSummary struct {
+	Location    string `json:"location"`
+	Devices     int    `json:"devices"`
+	VLANs       int    `json:"vlans"`
+	AccessPorts int    `json:"access_ports"`
+	Trunks      int    `json:"trunks"`
+	Issues      int    `json:"issues"`
+}
+
+// VLANIssue is one inconsistency between the two ends of a trunk
+type VLANIssue struct {
+	Type     string `json:"type"`
+	Location string `json:"location"`
+	DeviceA  string `json:"device_a"`
+	PortA    string `json:"interface_a"`
+	DeviceB  string `json:"device_b"`
+	PortB    string `json:"interface_b"`
+	OnlyA    string `json:"only_a,omitempty"`
+	OnlyB    string `json:"only_b,omitempty"`
+	Detail   string `json:"detail"`
+}
+
+// parseVLANPorts converts query rows, accepting VLAN lists as numbers, "10-20" ranges or
+// comma-separated strings. Trunks without an allowed list carry every VLAN.
+func parseVLANPorts(items []map[string]interface{}) []VLANPort {
+	ports := make([]VLANPort, 0, len(items))
+	for _, item := range items {
+		port := VLANPort{
+			Device:        resultValueString(item["device"]),
+			Location:      firstNonEmpty(resultValueString(item["location"]), vlanUnknownSite),
+			Interface:     resultValueString(item["interface"]),
+			Mode:          strings.ToLower(resultValueString(item["mode"])),
+			AccessVLAN:    firstVLAN(item["accessVlan"]),
+			NativeVLAN:    firstVLAN(item["nativeVlan"]),
+			PeerDevice:    firstListValue(item["peerDevice"]),
+			PeerInterface: firstListValue(item["peerInterface"]),
+		}
+		if port.Device == "" || port.Interface == "" {
+			continue
+		}
+		if strings.Contains(port.Mode, vlanModeTrunk) {
+			port.Mode = vlanModeTrunk
+			port.AllowedVLANs = parseVLANList(item["trunkVlans"])
+			if len(port.AllowedVLANs) == 0 {
+				port.AllowedVLANs = parseVLANList(fmt.Sprintf("1-%d", maxVLANID))
+			}
+			port.Allowed = formatVLANRanges(port.AllowedVLANs)
+		} else if port.Mode == "" || strings.Contains(port.Mode, vlanModeAccess) {
+			port.Mode = vlanModeAccess
+		}
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		if ports[i].Device != ports[j].Device {
+			return ports[i].Device < ports[j].Device
+		}
+		return ports[i].Interface < ports[j].Interface
+	})
+	return ports
+}
+
+// parseVLANList expands VLAN numbers and ranges into sorted unique IDs
+func parseVLANList(value interface{}) []int {
+	seen := make(map[int]bool)
+	var add func(interface{})
+	add = func(value interface{}) {
+		switch v := value.(type) {
+		case []interface{}:
+			for _, element := range v {
+				add(element)
+			}
+		case float64:
+			if id := int(v); id >= 1 && id <= maxVLANID {
+				seen[id] = true
+			}
+		case string:
+			for _, part := range strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' }) {
+				bounds := strings.SplitN(part, "-", 2)
+				start, err := strconv.Atoi(strings.TrimSpace(bounds[0]))
+				if err != nil {
+					continue
+				}
+				end := start
+				if len(bounds) == 2 {
+					if end, err = strconv.Atoi(strings.TrimSpace(bounds[1])); err != nil {
+						continue
+					}
+				}
+				for id := max(start, 1); id <= min(end, maxVLANID); id++ {
+					seen[id] = true
+				}
+			}
+		}
+	}
+	add(value)
+
+	ids := make([]int, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// firstVLAN returns the first VLAN ID of a value, or 0
+func firstVLAN(value interface{}) int {
+	if ids := parseVLANList(value); len(ids) > 0 {
+		return ids[0]
+	}
+	return 0
+}
+
+// firstListValue returns a string value or the first element of a list
+func firstListValue(value interface{}) string {
+	if list, ok := value.([]interface{}); ok {
+		if len(list) == 0 {
+			return ""
+		}
+		value = list[0]
+	}
+	return resultValueString(value)
+}
+
+// formatVLANRanges renders sorted VLAN IDs as compact ranges, e.g. "1-3,10,20-29"
+func formatVLANRanges(ids []int) string {
+	var ranges []string
+	for i := 0; i < len(ids); {
+		j := i
+		for j+1 < len(ids) && ids[j+1] == ids[j]+1 {
+			j++
+		}
+		if i == j {
+			ranges = append(ranges, strconv.Itoa(ids[i]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", ids[i], ids[j]))
+		}
+		i = j + 1
+	}
+	return strings.Join(ranges, ",")
+}
+
+// vlanDifference returns the IDs in a that are not in b; both are sorted
+func vlanDifference(a, b []int) []int {
+	var difference []int
+	j := 0
+	for _, id := range a {
+		for j < len(b) && b[j] < id {
+			j++
+		}
+		if j >= len(b) || b[j] != id {
+			difference = append(difference, id)
+		}
+	}
+	return difference
+}
+
+// vlanIntersection returns the IDs in both a and b; both are sorted
+func vlanIntersection(a, b []int) []int {
+	return vlanDifference(a, vlanDifference(a, b))
+}
+
+// vlanDevices summarizes the ports per device. A device's VLANs are those in local use:
+// access VLANs, native VLANs and VLAN interfaces (SVIs), not everything its trunks permit.
+func vlanDevices(ports []VLANPort, interfaces map[string][]string) []VLANDevice {
+	byDevice := make(map[string]*VLANDevice)
+	vlans := make(map[string]map[int]bool)
+	var names []string
+	for _, port := range ports {
+		device := byDevice[port.Device]
+		if device == nil {
+			device = &VLANDevice{Device: port.Device, Location: port.Location}
+			byDevice[port.Device] = device
+			vlans[port.Device] = make(map[int]bool)
+			names = append(names, port.Device)
+		}
+		if port.Mode == vlanModeTrunk {
+			device.Trunks++
+			if port.NativeVLAN > 0 {
+				vlans[port.Device][port.NativeVLAN] = true
+			}
+		} else {
+			device.AccessPorts++
+			if port.AccessVLAN > 0 {
+				vlans[port.Device][port.AccessVLAN] = true
+			}
+		}
+	}
+	for device, ifaceNames := range interfaces {
+		if vlans[device] == nil {
+			continue
+		}
+		for _, name := range ifaceNames {
+			if match := sviNamePattern.FindStringSubmatch(name); match != nil {
+				if id, err := strconv.Atoi(match[1]); err == nil && id >= 1 && id <= maxVLANID {
+					vlans[device][id] = true
+				}
+			}
+		}
+	}
+
+	sort.Strings(names)
+	devices := make([]VLANDevice, 0, len(names))
+	for _, name := range names {
+		device := byDevice[name]
+		for id := range vlans[name] {
+			device.VLANs = append(device.VLANs, id)
+		}
+		sort.Ints(device.VLANs)
+		devices = append(devices, *device)
+	}
+	return devices
+}
+
+// checkVLANConsistency compares mode, native VLAN and allowed VLANs at both ends of every
+// trunk and counts the trunks whose peer is not modeled
+func checkVLANConsistency(ports []VLANPort, devices []VLANDevice) ([]VLANIssue, int) {
+	byPort := make(map[string]VLANPort, len(ports))
+	for _, port := range ports {
+		byPort[port.Device+"\x00"+port.Interface] = port
+	}
+	inUse := make(map[string][]int, len(devices))
+	for _, device := range devices {
+		inUse[device.Device] = device.VLANs
+	}
+
+	var issues []VLANIssue
+	checked := make(map[string]bool)
+	unpaired := 0
+	for _, a := range ports {
+		if a.Mode != vlanModeTrunk {
+			continue
+		}
+		if a.PeerDevice == "" {
+			unpaired++
+			continue
+		}
+		key := a.Device + "\x00" + a.Interface
+		peerKey := a.PeerDevice + "\x00" + a.PeerInterface
+		if checked[key] {
+			continue
+		}
+		checked[key], checked[peerKey] = true, true
+
+		issue := VLANIssue{Location: a.Location, DeviceA: a.Device, PortA: a.Interface, DeviceB: a.PeerDevice, PortB: a.PeerInterface}
+		b, found := byPort[peerKey]
+		if !found {
+			if _, modeled := inUse[a.PeerDevice]; !modeled {
+				unpaired++
+				continue
+			}
+			issue.Type = vlanIssueNoPeerEnd
+			issue.Detail = "peer interface is not a switched port"
+			issues = append(issues, issue)
+			continue
+		}
+		if b.Mode != vlanModeTrunk {
+			issue.Type = vlanIssueMode
+			issue.Detail = fmt.Sprintf("trunk facing %s port (VLAN %d)", b.Mode, b.AccessVLAN)
+			issues = append(issues, issue)
+			continue
+		}
+		if a.NativeVLAN != b.NativeVLAN {
+			issue.Type = vlanIssueNative
+			issue.Detail = fmt.Sprintf("native VLAN %d vs %d", a.NativeVLAN, b.NativeVLAN)
+			issues = append(issues, issue)
+		}
+
+		// A VLAN carried by one end only matters when either device uses it: used only on the
+		// carrying side it is missing on the peer, used on the pruning side the lists disagree
+		onlyA := vlanDifference(a.AllowedVLANs, b.AllowedVLANs)
+		onlyB := vlanDifference(b.AllowedVLANs, a.AllowedVLANs)
+		missingOnB := vlanDifference(vlanIntersection(onlyA, inUse[a.Device]), inUse[b.Device])
+		missingOnA := vlanDifference(vlanIntersection(onlyB, inUse[b.Device]), inUse[a.Device])
+		if len(missingOnB) > 0 || len(missingOnA) > 0 {
+			missing := issue
+			missing.Type = vlanIssueMissing
+			missing.OnlyA = formatVLANRanges(missingOnB)
+			missing.OnlyB = formatVLANRanges(missingOnA)
+			missing.Detail = "VLANs used on one side do not exist on the other"
+			issues = append(issues, missing)
+		}
+		prunedByB := vlanIntersection(onlyA, inUse[b.Device])
+		prunedByA := vlanIntersection(onlyB, inUse[a.Device])
+		if len(prunedByB) > 0 || len(prunedByA) > 0 {
+			mismatch := issue
+			mismatch.Type = vlanIssueAllowed
+			mismatch.OnlyA = formatVLANRanges(prunedByB)
+			mismatch.OnlyB = formatVLANRanges(prunedByA)
+			mismatch.Detail = "VLANs used on both devices are allowed on one end of the trunk only"
+			issues = append(issues, mismatch)
+		}
+	}
+	return issues, unpaired
+}
+
+// vlanSiteSummaries aggregates devices and issues per site
+func vlanSiteSummaries(devices []VLANDevice, issues []VLANIssue) []VLANSiteSummary {
+	sites := make(map[string]*VLANSiteSummary)
+	vlans := make(map[string]map[int]bool)
+	site := func(location string) *VLANSiteSummary {
+		if sites[location] == nil {
+			sites[location] = &VLANSiteSummary{Location: location}
+			vlans[location] = make(map[int]bool)
+		}
+		return sites[location]
+	}
+	for _, device := range devices {
+		summary := site(device.Location)
+		summary.Devices++
+		summary.AccessPorts += device.AccessPorts
+		summary.Trunks += device.Trunks
+		for _, id := range device.VLANs {
+			vlans[device.Location][id] = true
+		}
+	}
+	for _, issue := range issues {
+		site(issue.Location).Issues++
+	}
+
+	summaries := make([]VLANSiteSummary, 0, len(sites))
+	for location, summary := range sites {
+		summary.VLANs = len(vlans[location])
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Location < summaries[j].Location })
+	return summaries
+}
+
+// loadVLANPorts fetches the switched ports of a network, optionally limited to one site or
+// device, and the interface names of every device for SVI detection
+func (s *ForwardMCPService) loadVLANPorts(networkID, snapshotID, location, device string) ([]VLANPort, map[string][]string, error) {
+	items, err := s.fetchAllNQEQueryItems(networkID, snapshotID, vlanPortsQuery)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch VLAN configuration: %w", err)
+	}
+	ports := parseVLANPorts(items)
+
+	interfaces := make(map[string][]string)
+	if inventory, err := s.getNetworkDevices(networkID, snapshotID); err == nil {
+		for _, d := range inventory {
+			for _, iface := range d.Interfaces {
+				interfaces[d.Name] = append(interfaces[d.Name], iface.Name)
+			}
+		}
+	} else {
+		s.logger.Debug("VLAN interfaces unavailable for network %s: %v", networkID, err)
+	}
+
+	if location == "" && device == "" {
+		return ports, interfaces, nil
+	}
+	filtered := ports[:0]
+	for _, port := range ports {
+		if (location == "" || strings.EqualFold(port.Location, location)) && (device == "" || strings.EqualFold(port.Device, device)) {
+			filtered = append(filtered, port)
+		}
+	}
+	return filtered, interfaces, nil
+}
+
+// getVLANInventory lists VLANs per device and trunk with a per-site summary
+func (s *ForwardMCPService) getVLANInventory(args GetVLANInventoryArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("get_vlan_inventory", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	ports, interfaces, err := s.loadVLANPorts(networkID, snapshotID, args.Location, args.Device)
+	if err != nil {
+		return nil, err
+	}
+	if len(ports) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf("No switched ports found in network %s.", networkID))), nil
+	}
+	devices := vlanDevices(ports, interfaces)
+	sites := vlanSiteSummaries(devices, nil)
+	var trunks []VLANPort
+	for _, port := range ports {
+		if port.Mode == vlanModeTrunk {
+			trunks = append(trunks, port)
+		}
+	}
+
+	if strings.EqualFold(args.Format, "json") {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"network_id": networkID,
+			"sites":      sites,
+			"devices":    devices,
+			"trunks":     trunks,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode VLAN inventory: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# VLAN Inventory: network %s\n\n", networkID))
+	writeVLANSites(&report, sites, false)
+
+	report.WriteString(fmt.Sprintf("\n## Devices (%d)\n\n| Device | Site | VLANs | Access ports | Trunks |\n|---|---|---|---|---|\n", len(devices)))
+	for i, device := range devices {
+		if i == maxVLANReportRows {
+			report.WriteString(fmt.Sprintf("\n… %d more devices (use format=json for all)\n", len(devices)-i))
+			break
+		}
+		report.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %d |\n", device.Device, device.Location,
+			firstNonEmpty(formatVLANRanges(device.VLANs), "-"), device.AccessPorts, device.Trunks))
+	}
+
+	report.WriteString(fmt.Sprintf("\n## Trunks (%d)\n\n| Device | Interface | Peer | Native | Allowed VLANs |\n|---|---|---|---|---|\n", len(trunks)))
+	for i, trunk := range trunks {
+		if i == maxVLANReportRows {
+			report.WriteString(fmt.Sprintf("\n… %d more trunks (use format=json for all)\n", len(trunks)-i))
+			break
+		}
+		peer := "-"
+		if trunk.PeerDevice != "" {
+			peer = trunk.PeerDevice + " " + trunk.PeerInterface
+		}
+		report.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %s |\n", trunk.Device, trunk.Interface, peer, trunk.NativeVLAN, trunk.Allowed))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
+
+// checkVLANConsistencyTool flags VLAN and allowed-list mismatches across trunk links
+func (s *ForwardMCPService) checkVLANConsistencyTool(args CheckVLANConsistencyArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("check_vlan_consistency", args, nil)
+
+	networkID := s.getNetworkID(args.NetworkID)
+	if networkID == "" {
+		return nil, newCodedError(CodeNetworkIDRequired)
+	}
+	snapshotID := s.getSnapshotID(args.SnapshotID)
+
+	// Both ends are needed to compare a trunk, so the site filter applies to the issues
+	ports, interfaces, err := s.loadVLANPorts(networkID, snapshotID, "", "")
+	if err != nil {
+		return nil, err
+	}
+	devices := vlanDevices(ports, interfaces)
+	issues, unpaired := checkVLANConsistency(ports, devices)
+	if args.Location != "" {
+		filteredIssues := issues[:0]
+		for _, issue := range issues {
+			if strings.EqualFold(issue.Location, args.Location) {
+				filteredIssues = append(filteredIssues, issue)
+			}
+		}
+		issues = filteredIssues
+		filteredDevices := devices[:0]
+		for _, device := range devices {
+			if strings.EqualFold(device.Location, args.Location) {
+				filteredDevices = append(filteredDevices, device)
+			}
+		}
+		devices = filteredDevices
+	}
+	sites := vlanSiteSummaries(devices, issues)
+
+	if strings.EqualFold(args.Format, "json") {
+		data, err := json.MarshalIndent(map[string]interface{}{
+			"network_id":      networkID,
+			"sites":           sites,
+			"issues":          issues,
+			"unpaired_trunks": unpaired,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode VLAN consistency report: %w", err)
+		}
+		return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+	}
+
+	var report strings.Builder
+	report.WriteString(fmt.Sprintf("# VLAN Consistency: network %s\n\n", networkID))
+	if len(issues) == 0 {
+		report.WriteString("✅ Both ends of every modeled trunk agree on mode, native VLAN and allowed VLANs.\n")
+	} else {
+		counts := make(map[string]int)
+		for _, issue := range issues {
+			counts[issue.Type]++
+		}
+		report.WriteString(fmt.Sprintf("❌ %d issues:", len(issues)))
+		for _, issueType := range []string{vlanIssueMissing, vlanIssueAllowed, vlanIssueNative, vlanIssueMode, vlanIssueNoPeerEnd} {
+			if counts[issueType] > 0 {
+				report.WriteString(fmt.Sprintf(" %s ×%d", issueType, counts[issueType]))
+			}
+		}
+		report.WriteString("\n")
+	}
+	if unpaired > 0 {
+		report.WriteString(fmt.Sprintf("ℹ️ %d trunks have no modeled peer and were not compared.\n", unpaired))
+	}
+	report.WriteString("\n")
+	writeVLANSites(&report, sites, true)
+
+	if len(issues) > 0 {
+		report.WriteString("\n## Issues\n\n| Type | Site | Side A | Side B | Only A | Only B | Detail |\n|---|---|---|---|---|---|---|\n")
+		for i, issue := range issues {
+			if i == maxVLANReportRows {
+				report.WriteString(fmt.Sprintf("\n… %d more issues (use format=json for all)\n", len(issues)-i))
+				break
+			}
+			report.WriteString(fmt.Sprintf("| %s | %s | %s %s | %s %s | %s | %s | %s |\n", issue.Type, issue.Location,
+				issue.DeviceA, issue.PortA, issue.DeviceB, issue.PortB, firstNonEmpty(issue.OnlyA, "-"), firstNonEmpty(issue.OnlyB, "-"), issue.Detail))
+		}
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(report.String())), nil
+}
+
+// writeVLANSites renders the per-site summary table
+func writeVLANSites(report *strings.Builder, sites []VLANSiteSummary, withIssues bool) {
+	report.WriteString("## Sites\n\n| Site | Devices | VLANs | Access ports | Trunks |")
+	if withIssues {
+		report.WriteString(" Issues |\n|---|---|---|---|---|---|\n")
+	} else {
+		report.WriteString("\n|---|---|---|---|---|\n")
+	}
+	for _, site := range sites {
+		report.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d |", site.Location, site.Devices, site.VLANs, site.AccessPorts, site.Trunks))
+		if withIssues {
+			report.WriteString(fmt.Sprintf(" %d |", site.Issues))
+		}
+		report.WriteString("\n")
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/forward"
+)
+
+func vlanTestService() *ForwardMCPService {
+	service := createTestService()
+	client := service.forwardClient.(*MockForwardClient)
+	port := func(device, location, iface, mode string, access, native interface{}, trunk interface{}, peerDevice, peerIface string) map[string]interface{} {
+		row := map[string]interface{}{"device": device, "location": location, "interface": iface, "mode": mode,
+			"accessVlan": access, "nativeVlan": native, "trunkVlans": trunk}
+		if peerDevice != "" {
+			row["peerDevice"] = []interface{}{peerDevice}
+			row["peerInterface"] = []interface{}{peerIface}
+		}
+		return row
+	}
+	client.nqeResult = &forward.NQERunResult{Items: []map[string]interface{}{
+		port("sw1", "hq", "Gi1", "TRUNK", nil, float64(1), []interface{}{"10-10", "20-20", "30-30"}, "sw2", "Gi1"),
+		port("sw1", "hq", "Gi2", "ACCESS", float64(10), nil, nil, "", ""),
+		port("sw1", "hq", "Gi3", "TRUNK", nil, float64(1), []interface{}{}, "sw3", "Gi1"),
+		port("sw1", "hq", "Gi4", "TRUNK", nil, float64(1), "10", "sw2", "Gi4"),
+		port("sw1", "hq", "Gi5", "ACCESS", float64(20), nil, nil, "", ""),
+		port("sw2", "hq", "Gi1", "TRUNK", nil, float64(99), "10,20", "sw1", "Gi1"),
+		port("sw2", "hq", "Gi2", "ACCESS", float64(20), nil, nil, "", ""),
+		port("sw2", "hq", "Gi4", "TRUNK", nil, float64(1), "10,20", "sw1", "Gi4"),
+		port("sw3", "branch", "Gi1", "ACCESS", float64(10), nil, nil, "sw1", "Gi3"),
+		port("sw4", "branch", "Gi1", "TRUNK", nil, float64(1), "1-4094", "router-x", "ge-0/0/0"),
+	}}
+	client.devices = []forward.Device{{Name: "sw1", Interfaces: []forward.DeviceInterface{{Name: "Vlan30"}, {Name: "Gi1"}}}}
+	service.deviceCache = nil
+	return service
+}
+
+func TestVLANRanges(t *testing.T) {
+	ids := parseVLANList([]interface{}{"1-3", float64(10), "20-22,30", "5000"})
+	if expected := []int{1, 2, 3, 10, 20, 21, 22, 30}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+	if ranges := formatVLANRanges(ids); ranges != "1-3,10,20-22,30" {
+		t.Errorf("Unexpected ranges: %s", ranges)
+	}
+	if difference := vlanDifference(ids, []int{2, 10, 21}); !reflect.DeepEqual(difference, []int{1, 3, 20, 22, 30}) {
+		t.Errorf("Unexpected difference: %v", difference)
+	}
+	if intersection := vlanIntersection(ids, []int{2, 10, 40}); !reflect.DeepEqual(intersection, []int{2, 10}) {
+		t.Errorf("Unexpected intersection: %v", intersection)
+	}
+}
+
+func TestCheckVLANConsistency(t *testing.T) {
+	service := vlanTestService()
+	response, err := service.checkVLANConsistencyTool(CheckVLANConsistencyArgs{Format: "json"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var report struct {
+		Sites    []VLANSiteSummary `json:"sites"`
+		Issues   []VLANIssue       `json:"issues"`
+		Unpaired int               `json:"unpaired_trunks"`
+	}
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &report); err != nil {
+		t.Fatalf("Expected JSON output, got %v", err)
+	}
+
+	found := make(map[string]VLANIssue)
+	for _, issue := range report.Issues {
+		found[issue.Type+" "+issue.PortA] = issue
+	}
+	if len(report.Issues) != 4 {
+		t.Errorf("Expected 4 issues, got %+v", report.Issues)
+	}
+	// sw1 uses VLAN 30 (SVI) and carries it to sw2, which has no VLAN 30
+	if issue, ok := found[vlanIssueMissing+" Gi1"]; !ok || issue.OnlyA != "30" || issue.OnlyB != "" {
+		t.Errorf("Expected VLAN 30 missing on sw2, got %+v", issue)
+	}
+	if _, ok := found[vlanIssueNative+" Gi1"]; !ok {
+		t.Error("Expected a native VLAN mismatch on sw1 Gi1")
+	}
+	if _, ok := found[vlanIssueMode+" Gi3"]; !ok {
+		t.Error("Expected sw1 Gi3 to face an access port")
+	}
+	// Both switches use VLAN 20 but sw1 Gi4 prunes it
+	if issue, ok := found[vlanIssueAllowed+" Gi4"]; !ok || issue.OnlyB != "20" {
+		t.Errorf("Expected VLAN 20 allowed only on sw2 Gi4, got %+v", issue)
+	}
+	if report.Unpaired != 1 {
+		t.Errorf("Expected one unpaired trunk, got %d", report.Unpaired)
+	}
+	if len(report.Sites) != 2 || report.Sites[1].Location != "hq" || report.Sites[1].Issues != 4 {
+		t.Errorf("Unexpected site summary: %+v", report.Sites)
+	}
+
+	response, err = service.checkVLANConsistencyTool(CheckVLANConsistencyArgs{Location: "branch"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Both ends of every modeled trunk agree") {
+		t.Errorf("Expected no issues at branch, got: %s", text)
+	}
+}
+
+func TestGetVLANInventory(t *testing.T) {
+	service := vlanTestService()
+	response, err := service.getVLANInventory(GetVLANInventoryArgs{Location: "hq"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"| hq | 2 |", "| sw1 | hq | 1,10,20,30 | 2 | 3 |", "| sw1 | Gi3 | sw3 Gi1 | 1 | 1-4094 |"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in inventory, got: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, "sw4") {
+		t.Errorf("Expected the site filter to exclude sw4, got: %s", text)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+// Workflow names used for explicit step advancement
+const (
+	workflowNQEDiscovery          = "nqe_discovery"
+	workflowLargeNQEResults       = "large_nqe_results"
+	workflowPathSearch            = "path_search"
+	workflowNetworkPrefixDiscover = "network_prefix_discovery"
+	workflowSecurityPosture       = "security_posture"
+	workflowSiteTurnup            = "site_turnup"
+	workflowOnboardDevices        = "onboard_devices"
+)
+
+// WorkflowNextStep describes a step a client may request on its next call
+type WorkflowNextStep struct {
+	Step           string `json:"step"`
+	Description    string `json:"description"`
+	RequiresAnswer bool   `json:"requires_answer,omitempty"`
+	AnswerHint     string `json:"answer_hint,omitempty"`
+}
+
+// workflowStepDef defines a single workflow step and the steps reachable from it
+type workflowStepDef struct {
+	description string
+	answerHint  string // Non-empty when the step requires an answer
+	next        []string
+}
+
+// workflowDefinitions holds the step graph for each interactive workflow prompt
+var workflowDefinitions = map[string]map[string]workflowStepDef{
+	workflowNQEDiscovery: {
+		"start":                {description: "Show the available query categories", next: []string{"category_selected"}},
+		"category_selected":    {description: "List queries in a category", answerHint: "Query directory such as /L3/Basic/, or 1-3 from the category list", next: []string{"query_selected", "start"}},
+		"query_selected":       {description: "Select a query to run", answerHint: "Query ID of the query to run", next: []string{"parameters_collected", "category_selected"}},
+		"parameters_collected": {description: "Execute the selected query", answerHint: "network_id=<id>[,snapshot_id=<id>]", next: []string{"start"}},
+	},
+	workflowLargeNQEResults: {
+		"start":           {description: "Introduce the large results workflow", next: []string{"explain_process", "show_example", "demonstrate_sql"}},
+		"explain_process": {description: "Explain chunked storage and analysis", next: []string{"show_example"}},
+		"show_example":    {description: "Walk through a practical example", next: []string{"demonstrate_sql"}},
+		"demonstrate_sql": {description: "Show SQL analysis capabilities", next: []string{"start"}},
+	},
+	workflowPathSearch: {
+		"start":                   {description: "Introduce the path search workflow", next: []string{"explain_best_practices"}},
+		"explain_best_practices":  {description: "Explain path search best practices", next: []string{"show_bulk_example"}},
+		"show_bulk_example":       {description: "Show a bulk path search example", next: []string{"guide_request_building"}},
+		"guide_request_building":  {description: "Guide building a path search request", next: []string{"network_scope_discovery"}},
+		"network_scope_discovery": {description: "Discover network scopes for planning", next: []string{"start"}},
+	},
+	workflowNetworkPrefixDiscover: {
+		"start":           {description: "Introduce network prefix discovery", next: []string{"explain_process"}},
+		"explain_process": {description: "Explain the prefix discovery process", next: []string{"show_example"}},
+		"show_example":    {description: "Show an example analysis", next: []string{"guide_analysis"}},
+		"guide_analysis":  {description: "Guide running your own analysis", next: []string{"start"}},
+	},
+	workflowSecurityPosture: {
+		"start":                 {description: "Introduce the security posture assessment", next: []string{"scope_selected"}},
+		"scope_selected":        {description: "Select the network and snapshot to assess", answerHint: "network_id=<id>[,snapshot_id=<id>]", next: []string{"run_security_queries", "verify_critical_flows", "check_eol"}},
+		"run_security_queries":  {description: "Run the /L3/Security/ queries and count violations", next: []string{"verify_critical_flows", "check_eol", "posture_summary"}},
+		"verify_critical_flows": {description: "Verify ACL verdicts on critical flows", answerHint: "src>dst[:port][=allow|deny]; ... (default expectation: allow)", next: []string{"check_eol", "posture_summary"}},
+		"check_eol":             {description: "Check hardware and OS end-of-support exposure", next: []string{"posture_summary"}},
+		"posture_summary":       {description: "Compile the scored posture summary (answer report_format=html, markdown or pdf to save a rendered report)", next: []string{"start"}},
+	},
+	workflowSiteTurnup: {
+		"start":              {description: "Introduce new-site turn-up validation", next: []string{"site_selected"}},
+		"site_selected":      {description: "Select the network and the site being turned up", answerHint: "network_id=<id>, site=<location name or ID>[, snapshot_id=<id>]", next: []string{"check_devices"}},
+		"check_devices":      {description: "Confirm devices are collected and assigned to the site", answerHint: "Comma-separated device names expected at the site, or 'all'", next: []string{"check_prefixes"}},
+		"check_prefixes":     {description: "Find the LAN prefixes configured at the site", next: []string{"check_reachability", "turnup_checklist"}},
+		"check_reachability": {description: "Path-search between the site and core services", answerHint: "Comma-separated core service IPs or device names", next: []string{"turnup_checklist"}},
+		"turnup_checklist":   {description: "Produce the go/no-go checklist", next: []string{"start"}},
+	},
+	workflowOnboardDevices: {
+		"start":              {description: "Introduce device onboarding", next: []string{"network_selected"}},
+		"network_selected":   {description: "Select the network and name the onboarding batch", answerHint: "network_id=<id>[, batch=<name>]", next: []string{"devices_planned"}},
+		"devices_planned":    {description: "Record the devices being added and get collection source guidance", answerHint: "name[@management_ip][=location], separated by commas or semicolons", next: []string{"check_collection"}},
+		"check_collection":   {description: "Confirm the devices are collected in a snapshot newer than the baseline", next: []string{"assign_locations", "onboarding_summary"}},
+		"assign_locations":   {description: "Preview the location assignments for collected devices", next: []string{"check_collection", "onboarding_summary"}},
+		"onboarding_summary": {description: "Summarize the batch and its open items", next: []string{"start"}},
+	},
+}
+
+// resolveWorkflowStep validates an explicitly requested step against the session's
+// last rendered step, records its answer and returns the step to render. When no
+// step is requested the session's current step is returned unchanged.
+func (s *ForwardMCPService) resolveWorkflowStep(workflow, sessionID, step, answer string) (string, error) {
+	state := s.workflowManager.GetState(sessionID)
+	step = strings.TrimSpace(step)
+	if step == "" {
+		if answer != "" {
+			return "", fmt.Errorf("an answer requires a step; valid next steps: %s", strings.Join(workflowStepNames(workflowNextSteps(workflow, state.LastStep)), ", "))
+		}
+		return state.CurrentStep, nil
+	}
+
+	steps := workflowDefinitions[workflow]
+	def, exists := steps[step]
+	if !exists {
+		valid := make([]string, 0, len(steps))
+		for name := range steps {
+			valid = append(valid, name)
+		}
+		sort.Strings(valid)
+		return "", fmt.Errorf("unknown step '%s' for workflow %s (valid steps: %s)", step, workflow, strings.Join(valid, ", "))
+	}
+
+	if !isWorkflowTransitionAllowed(workflow, state, step) {
+		return "", fmt.Errorf("cannot move from step '%s' to '%s'; valid next steps: %s", lastWorkflowStep(state), step, strings.Join(workflowStepNames(workflowNextSteps(workflow, state.LastStep)), ", "))
+	}
+
+	if def.answerHint != "" && strings.TrimSpace(answer) == "" {
+		return "", fmt.Errorf("step '%s' requires an answer: %s", step, def.answerHint)
+	}
+
+	if state.Parameters == nil {
+		state.Parameters = make(map[string]interface{})
+	}
+	if answer != "" {
+		state.Parameters["answer_"+step] = strings.TrimSpace(answer)
+	}
+	state.CurrentStep = step
+	s.workflowManager.SetState(sessionID, state)
+
+	return step, nil
+}
+
+// isWorkflowTransitionAllowed reports whether step may follow the session's last rendered step.
+// Restarting and repeating the current or last step are always allowed.
+func isWorkflowTransitionAllowed(workflow string, state *WorkflowState, step string) bool {
+	if step == "start" || step == state.CurrentStep || step == state.LastStep {
+		return true
+	}
+	for _, next := range workflowDefinitions[workflow][lastWorkflowStep(state)].next {
+		if next == step {
+			return true
+		}
+	}
+	return false
+}
+
+// lastWorkflowStep returns the last rendered step, treating a fresh session as "start"
+func lastWorkflowStep(state *WorkflowState) string {
+	if state.LastStep == "" {
+		return "start"
+	}
+	return state.LastStep
+}
+
+// workflowNextSteps returns the steps reachable from the given step
+func workflowNextSteps(workflow, step string) []WorkflowNextStep {
+	if step == "" {
+		step = "start"
+	}
+	steps := workflowDefinitions[workflow]
+	var result []WorkflowNextStep
+	for _, name := range steps[step].next {
+		def := steps[name]
+		result = append(result, WorkflowNextStep{
+			Step:           name,
+			Description:    def.description,
+			RequiresAnswer: def.answerHint != "",
+			AnswerHint:     def.answerHint,
+		})
+	}
+	return result
+}
+
+// workflowStepNames extracts step names from a next-step list
+func workflowStepNames(steps []WorkflowNextStep) []string {
+	names := make([]string, 0, len(steps))
+	for _, step := range steps {
+		names = append(names, step.Step)
+	}
+	return names
+}
+
+// finishWorkflowStep records the rendered step and appends the structured next_steps list
+// so clients can drive the workflow deterministically
+func (s *ForwardMCPService) finishWorkflowStep(workflow, sessionID, renderedStep string, response *mcp.ToolResponse) *mcp.ToolResponse {
+	state := s.workflowManager.GetState(sessionID)
+	state.LastStep = renderedStep
+	s.workflowManager.SetState(sessionID, state)
+
+	if response == nil || len(response.Content) == 0 || response.Content[0].TextContent == nil {
+		return response
+	}
+
+	nextSteps := map[string]interface{}{
+		"workflow":     workflow,
+		"current_step": renderedStep,
+		"next_steps":   workflowNextSteps(workflow, renderedStep),
+	}
+	response.Content[0].TextContent.Text += "\n\n---\nnext_steps: " + MarshalCompactJSONString(nextSteps)
+	return response
+}
+
+// queryDirectoryFromAnswer maps a category answer (number or directory) to a query directory
+func queryDirectoryFromAnswer(answer string) string {
+	switch strings.TrimSpace(answer) {
+	case "1", "basic", "Basic":
+		return "/L3/Basic/"
+	case "2", "advanced", "Advanced":
+		return "/L3/Advanced/"
+	case "3", "security", "Security":
+		return "/L3/Security/"
+	}
+	return strings.TrimSpace(answer)
+}
+
+// parseWorkflowKeyValues parses "key=value" pairs separated by commas, semicolons, newlines or
+// whitespace. Keys are lower-cased, and words without "=" join the previous value so site
+// names like "Data Center 1" keep their spaces.
+func parseWorkflowKeyValues(answer string) map[string]string {
+	values := make(map[string]string)
+	for _, field := range strings.FieldsFunc(answer, func(r rune) bool { return r == ',' || r == ';' || r == '\n' }) {
+		lastKey := ""
+		for _, word := range strings.Fields(field) {
+			if key, value, found := strings.Cut(word, "="); found {
+				lastKey = strings.ToLower(strings.TrimSpace(key))
+				values[lastKey] = strings.TrimSpace(value)
+			} else if lastKey != "" {
+				values[lastKey] = strings.TrimSpace(values[lastKey] + " " + word)
+			}
+		}
+	}
+	return values
+}
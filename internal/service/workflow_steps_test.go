@@ -0,0 +1,85 @@
+package service
+
+import (
+	"testing"
+)
+
+func TestWorkflowExplicitStepAdvancement(t *testing.T) {
+	service := createTestService()
+
+	// Fresh session renders the start step and lists next steps
+	response, err := service.largeNQEResultsWorkflow(LargeNQEResultsWorkflowArgs{SessionID: "wf-1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !contains(text, `"current_step":"start"`) || !contains(text, `"step":"explain_process"`) {
+		t.Errorf("Expected structured next_steps for start, got: %s", text)
+	}
+
+	// Jumping to an offered step is allowed
+	response, err = service.largeNQEResultsWorkflow(LargeNQEResultsWorkflowArgs{SessionID: "wf-1", Step: "demonstrate_sql"})
+	if err != nil {
+		t.Fatalf("Expected transition to demonstrate_sql to be allowed, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, `"current_step":"demonstrate_sql"`) {
+		t.Error("Expected demonstrate_sql to be rendered")
+	}
+
+	// demonstrate_sql only leads back to start
+	if _, err := service.largeNQEResultsWorkflow(LargeNQEResultsWorkflowArgs{SessionID: "wf-1", Step: "show_example"}); err == nil {
+		t.Error("Expected invalid transition to be rejected")
+	}
+
+	// Unknown steps are rejected
+	if _, err := service.largeNQEResultsWorkflow(LargeNQEResultsWorkflowArgs{SessionID: "wf-1", Step: "bogus"}); err == nil {
+		t.Error("Expected unknown step to be rejected")
+	}
+}
+
+func TestNQEDiscoveryWorkflowAnswers(t *testing.T) {
+	service := createTestService()
+
+	if _, err := service.nqeQueryDiscoveryWorkflow(NQEDiscoveryArgs{SessionID: "wf-2"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Steps requiring input reject empty answers
+	if _, err := service.nqeQueryDiscoveryWorkflow(NQEDiscoveryArgs{SessionID: "wf-2", Step: "category_selected"}); err == nil {
+		t.Error("Expected category_selected without an answer to be rejected")
+	}
+
+	response, err := service.nqeQueryDiscoveryWorkflow(NQEDiscoveryArgs{SessionID: "wf-2", Step: "category_selected", Answer: "1"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "/L3/Basic/") {
+		t.Error("Expected category answer to select /L3/Basic/")
+	}
+
+	if _, err := service.nqeQueryDiscoveryWorkflow(NQEDiscoveryArgs{SessionID: "wf-2", Step: "query_selected", Answer: "FQ_test"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	response, err = service.nqeQueryDiscoveryWorkflow(NQEDiscoveryArgs{SessionID: "wf-2", Step: "parameters_collected", Answer: "network_id=162112"})
+	if err != nil {
+		t.Fatalf("Expected query execution step to succeed, got: %v", err)
+	}
+	if !contains(response.Content[0].TextContent.Text, "Query executed successfully") {
+		t.Errorf("Expected executed query, got: %s", response.Content[0].TextContent.Text)
+	}
+}
+
+func TestParseWorkflowKeyValues(t *testing.T) {
+	values := parseWorkflowKeyValues("network_id=123, snapshot_id=456")
+	if values["network_id"] != "123" || values["snapshot_id"] != "456" {
+		t.Errorf("Unexpected parsed values: %v", values)
+	}
+}
+
+func TestParseWorkflowKeyValuesSpaceSeparated(t *testing.T) {
+	values := parseWorkflowKeyValues("network_id=123 snapshot_id=456")
+	if values["network_id"] != "123" || values["snapshot_id"] != "456" {
+		t.Errorf("Unexpected parsed values: %v", values)
+	}
+}
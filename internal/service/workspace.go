@@ -0,0 +1,639 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/forward-mcp/internal/logger"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+const (
+	workspaceEntityType   = "workspace"
+	relationInWorkspace   = "workspace_contains" // Workspace → each artifact linked to it
+	workspaceReportType   = "workspace_report"   // Observation on a workspace naming a saved report file
+	maxWorkspaceListItems = 50                   // Artifacts listed per type before the rest are counted
+)
+
+// workspaceSharedTypes are reference and bookkeeping entities that many investigations touch;
+// they are never linked to a workspace, so cleaning a workspace up cannot remove them
+var workspaceSharedTypes = map[string]bool{
+	workspaceEntityType: true, deviceEntityType: true, "network": true, "snapshot": true, "query": true,
+	"location": true, "site": true, prefixEntityType: true, teamEntityType: true, deviceTagType: true,
+	middleboxEntityType: true, transcriptStepType: true, fetchProgressEntityType: true,
+}
+
+// Workspaces groups the artifacts of an investigation: while a workspace is open, every
+// result, report, note or other artifact stored in memory is linked to it, so a long-running
+// project can be listed, exported and cleaned up as a unit. Each caller (API key, or the
+// unauthenticated transport) opens its own workspace. The memory system does not know which
+// call stored an entity, so it is linked to the workspace of the caller whose tool calls are
+// running; while calls of several callers run at once, or none run and several workspaces are
+// open, stored entities are not linked rather than risk linking them to the wrong one.
+// A nil Workspaces links nothing.
+type Workspaces struct {
+	memory *MemorySystem
+	logger *logger.Logger
+
+	mu     sync.Mutex
+	active map[string]*Entity // Open workspace of each caller, keyed by callerID
+	calls  map[string]int     // Tool calls in flight, keyed by callerID
+}
+
+// WorkspaceReport is a report file saved while a workspace was open
+type WorkspaceReport struct {
+	Path    string    `json:"path"`
+	URI     string    `json:"uri,omitempty"`
+	Format  string    `json:"format,omitempty"`
+	Size    int64     `json:"size,omitempty"`
+	SavedAt time.Time `json:"saved_at"`
+}
+
+// NewWorkspaces creates the workspace registry and links new memory entities to the open
+// workspace
+func NewWorkspaces(memory *MemorySystem, logger *logger.Logger) *Workspaces {
+	if memory == nil {
+		return nil
+	}
+	w := &Workspaces{memory: memory, logger: logger, active: make(map[string]*Entity), calls: make(map[string]int)}
+	memory.SetStoreHook(w.link)
+	return w
+}
+
+// Open makes the workspace with name the one the caller's new artifacts are linked to,
+// creating it when it does not exist yet
+func (w *Workspaces) Open(caller, name, description string) (*Entity, bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, false, fmt.Errorf("workspace name is required")
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	workspace, err := w.memory.getEntityByNameAndType(name, workspaceEntityType)
+	created := false
+	if err != nil {
+		workspace, err = w.memory.CreateEntity(name, workspaceEntityType, map[string]interface{}{
+			"description": description,
+			"created_at":  time.Now().UTC().Format(time.RFC3339),
+		})
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to create workspace: %w", err)
+		}
+		created = true
+	} else if description != "" && description != resultValueString(workspace.Metadata["description"]) {
+		metadata := make(map[string]interface{}, len(workspace.Metadata)+1)
+		for key, value := range workspace.Metadata {
+			metadata[key] = value
+		}
+		metadata["description"] = description
+		if err := w.memory.updateEntityMetadata(workspace.ID, metadata); err != nil {
+			return nil, false, err
+		}
+		workspace.Metadata = metadata
+	}
+	w.active[caller] = workspace
+	return workspace, created, nil
+}
+
+// Close stops linking the caller's artifacts and returns the workspace that was open
+func (w *Workspaces) Close(caller string) (*Entity, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	workspace := w.active[caller]
+	if workspace == nil {
+		return nil, fmt.Errorf("no workspace is open")
+	}
+	delete(w.active, caller)
+	return workspace, nil
+}
+
+// Active returns the caller's open workspace, or nil
+func (w *Workspaces) Active(caller string) *Entity {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.active[caller]
+}
+
+// forget closes the workspace with workspaceID for every caller that has it open
+func (w *Workspaces) forget(workspaceID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for caller, workspace := range w.active {
+		if workspace.ID == workspaceID {
+			delete(w.active, caller)
+		}
+	}
+}
+
+// begin and end count the tool calls of a caller that are running
+func (w *Workspaces) begin(caller string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.calls[caller]++
+}
+
+func (w *Workspaces) end(caller string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.calls[caller]--; w.calls[caller] <= 0 {
+		delete(w.calls, caller)
+	}
+}
+
+// storeWorkspace returns the workspace an entity stored now belongs to: that of the only
+// caller with calls running, or with none running, the only open workspace
+func (w *Workspaces) storeWorkspace() *Entity {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	candidates := make([]string, 0, 1)
+	if len(w.calls) > 0 {
+		for caller := range w.calls {
+			candidates = append(candidates, caller)
+		}
+	} else {
+		for caller := range w.active {
+			candidates = append(candidates, caller)
+		}
+	}
+	if len(candidates) != 1 {
+		return nil
+	}
+	return w.active[candidates[0]]
+}
+
+// workspaceToolHandler returns a context-aware handler that counts the call as running for
+// its caller, so the artifacts it stores are linked to the caller's workspace
+func (s *ForwardMCPService) workspaceToolHandler(handler interface{}) interface{} {
+	value := reflect.ValueOf(handler)
+	handlerType := value.Type()
+	if s.workspaces == nil || handlerType.Kind() != reflect.Func || handlerType.NumOut() != 2 {
+		return handler
+	}
+	takesContext := handlerType.NumIn() == 2 && handlerType.In(0) == contextType
+	if handlerType.NumIn() != 1 && !takesContext {
+		return handler
+	}
+	wrappedType := reflect.FuncOf([]reflect.Type{contextType, handlerType.In(handlerType.NumIn() - 1)},
+		[]reflect.Type{handlerType.Out(0), handlerType.Out(1)}, false)
+	return reflect.MakeFunc(wrappedType, func(args []reflect.Value) []reflect.Value {
+		caller := ""
+		if ctx, _ := args[0].Interface().(context.Context); ctx != nil {
+			caller = apiKeyIdentityFromContext(ctx).callerID()
+		}
+		s.workspaces.begin(caller)
+		defer s.workspaces.end(caller)
+		if takesContext {
+			return value.Call(args)
+		}
+		return value.Call(args[1:])
+	}).Interface()
+}
+
+// link adds an entity stored while a workspace is open to that workspace. Failures are
+// logged, never returned, so linking cannot break the tool that stored the entity.
+func (w *Workspaces) link(entity *Entity) {
+	// Checked first: creating a workspace entity calls this with the mutex held
+	if workspaceSharedTypes[entity.Type] {
+		return
+	}
+	workspace := w.storeWorkspace()
+	if workspace == nil {
+		return
+	}
+	relations, err := w.memory.GetRelations(entity.ID, relationInWorkspace)
+	if err != nil {
+		w.logger.Warn("Failed to link %s to workspace %s: %v", entity.Name, workspace.Name, err)
+		return
+	}
+	for _, relation := range relations {
+		if relation.FromID == workspace.ID {
+			return // A reused version that is already linked
+		}
+	}
+	if _, err := w.memory.CreateRelation(workspace.ID, entity.ID, relationInWorkspace, nil); err != nil {
+		w.logger.Warn("Failed to link %s to workspace %s: %v", entity.Name, workspace.Name, err)
+	}
+}
+
+// recordReport notes a saved report file on the open workspace it was saved for
+func (w *Workspaces) recordReport(saved *SavedReport) {
+	workspace := w.storeWorkspace()
+	if workspace == nil {
+		return
+	}
+	if _, err := w.memory.AddObservation(workspace.ID, saved.Path, workspaceReportType, map[string]interface{}{
+		"uri":    saved.URI,
+		"format": saved.Format,
+		"size":   saved.Size,
+	}); err != nil {
+		w.logger.Warn("Failed to record report %s in workspace %s: %v", saved.Path, workspace.Name, err)
+	}
+}
+
+// contents returns the artifacts linked to a workspace, oldest first, and its reports
+func (w *Workspaces) contents(workspaceID string) ([]*Entity, []WorkspaceReport, error) {
+	relations, err := w.memory.GetRelations(workspaceID, relationInWorkspace)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load workspace: %w", err)
+	}
+	var entities []*Entity
+	for _, relation := range relations {
+		if relation.FromID != workspaceID {
+			continue
+		}
+		entity, err := w.memory.getEntityByID(relation.ToID)
+		if err != nil {
+			continue // Deleted artifact
+		}
+		entities = append(entities, entity)
+	}
+	sort.Slice(entities, func(i, j int) bool { return entities[i].CreatedAt.Before(entities[j].CreatedAt) })
+
+	observations, err := w.memory.GetObservations(workspaceID, workspaceReportType)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load workspace reports: %w", err)
+	}
+	reports := make([]WorkspaceReport, 0, len(observations))
+	for _, observation := range observations {
+		size, _ := timeSeriesNumber(observation.Metadata["size"])
+		reports = append(reports, WorkspaceReport{
+			Path:    observation.Content,
+			URI:     resultValueString(observation.Metadata["uri"]),
+			Format:  resultValueString(observation.Metadata["format"]),
+			Size:    int64(size),
+			SavedAt: observation.CreatedAt,
+		})
+	}
+	return entities, reports, nil
+}
+
+// otherWorkspaces reports whether an entity is also linked to a workspace besides workspaceID
+func (w *Workspaces) otherWorkspaces(entityID, workspaceID string) bool {
+	relations, err := w.memory.GetRelations(entityID, relationInWorkspace)
+	if err != nil {
+		return true // Keep what cannot be checked
+	}
+	for _, relation := range relations {
+		if relation.ToID == entityID && relation.FromID != workspaceID {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveWorkspace returns the workspace with name, or the caller's open one when name is empty
+func (s *ForwardMCPService) resolveWorkspace(caller, name string) (*Entity, error) {
+	if name == "" {
+		if active := s.workspaces.Active(caller); active != nil {
+			return active, nil
+		}
+		return nil, fmt.Errorf("no workspace is open; pass name, or open one with create_workspace")
+	}
+	workspace, err := s.memorySystem.getEntityByNameAndType(name, workspaceEntityType)
+	if err != nil {
+		return nil, fmt.Errorf("workspace %q not found", name)
+	}
+	return workspace, nil
+}
+
+// createWorkspace creates or reopens a workspace and links subsequent artifacts to it
+func (s *ForwardMCPService) createWorkspace(args CreateWorkspaceArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("create_workspace", args, nil)
+
+	if s.workspaces == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	caller := args.Caller.callerID()
+	previous := s.workspaces.Active(caller)
+	workspace, created, err := s.workspaces.Open(caller, args.Name, args.Description)
+	if err != nil {
+		return nil, err
+	}
+	var text strings.Builder
+	if created {
+		text.WriteString(fmt.Sprintf("Created workspace '%s' (%s).", workspace.Name, workspace.ID))
+	} else {
+		entities, reports, err := s.workspaces.contents(workspace.ID)
+		if err != nil {
+			return nil, err
+		}
+		text.WriteString(fmt.Sprintf("Reopened workspace '%s' (%s) with %d artifacts and %d reports.", workspace.Name, workspace.ID, len(entities), len(reports)))
+	}
+	if previous != nil && previous.ID != workspace.ID {
+		text.WriteString(fmt.Sprintf(" Workspace '%s' was closed.", previous.Name))
+	}
+	text.WriteString("\n\nResults, reports, notes and other artifacts stored from now on are linked to it. Review them with list_workspace_contents; close_workspace stops linking.")
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// closeWorkspace stops linking artifacts to the open workspace
+func (s *ForwardMCPService) closeWorkspace(args CloseWorkspaceArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("close_workspace", args, nil)
+
+	if s.workspaces == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	workspace, err := s.workspaces.Close(args.Caller.callerID())
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(fmt.Sprintf(
+		"Closed workspace '%s'. Its artifacts stay in memory; reopen it with create_workspace name=%q.", workspace.Name, workspace.Name))), nil
+}
+
+// listWorkspaceContents lists the artifacts of a workspace, or every workspace when none is
+// named or open
+func (s *ForwardMCPService) listWorkspaceContents(args ListWorkspaceContentsArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("list_workspace_contents", args, nil)
+
+	if s.workspaces == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	caller := args.Caller.callerID()
+	if args.Name == "" && s.workspaces.Active(caller) == nil {
+		return s.listWorkspaces()
+	}
+	workspace, err := s.resolveWorkspace(caller, args.Name)
+	if err != nil {
+		return nil, err
+	}
+	entities, reports, err := s.workspaces.contents(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byType := make(map[string][]*Entity)
+	var types []string
+	for _, entity := range entities {
+		if args.Type != "" && entity.Type != args.Type {
+			continue
+		}
+		if _, ok := byType[entity.Type]; !ok {
+			types = append(types, entity.Type)
+		}
+		byType[entity.Type] = append(byType[entity.Type], entity)
+	}
+	sort.Strings(types)
+
+	var text strings.Builder
+	status := ""
+	if active := s.workspaces.Active(caller); active != nil && active.ID == workspace.ID {
+		status = " (open)"
+	}
+	text.WriteString(fmt.Sprintf("## Workspace: %s%s\n\n", workspace.Name, status))
+	if description := resultValueString(workspace.Metadata["description"]); description != "" {
+		text.WriteString(description + "\n\n")
+	}
+	text.WriteString(fmt.Sprintf("**Workspace:** %s\n**Created:** %s\n**Artifacts:** %d\n**Reports:** %d\n\n",
+		workspace.ID, s.timeFormatter.Format(workspace.CreatedAt), len(entities), len(reports)))
+	for _, entityType := range types {
+		members := byType[entityType]
+		text.WriteString(fmt.Sprintf("### %s (%d)\n", entityType, len(members)))
+		for i, entity := range members {
+			if i == maxWorkspaceListItems {
+				text.WriteString(fmt.Sprintf("- … %d more\n", len(members)-i))
+				break
+			}
+			text.WriteString(fmt.Sprintf("- %s (%s), %s\n", entity.Name, entity.ID, s.timeFormatter.Format(entity.CreatedAt)))
+		}
+		text.WriteString("\n")
+	}
+	if len(reports) > 0 && (args.Type == "" || args.Type == "report") {
+		text.WriteString(fmt.Sprintf("### Reports (%d)\n", len(reports)))
+		for _, report := range reports {
+			text.WriteString(fmt.Sprintf("- %s (%s, %s), %s\n", report.Path, report.Format, formatBytes(report.Size), s.timeFormatter.Format(report.SavedAt)))
+		}
+		text.WriteString("\n")
+	}
+	if len(entities) == 0 && len(reports) == 0 {
+		text.WriteString("Nothing has been stored in this workspace yet.\n")
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// listWorkspaces lists every workspace with its artifact count
+func (s *ForwardMCPService) listWorkspaces() (*mcp.ToolResponse, error) {
+	workspaces, err := s.memorySystem.SearchEntities("", workspaceEntityType, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(workspaces) == 0 {
+		return mcp.NewToolResponse(mcp.NewTextContent("No workspaces yet. Create one with create_workspace.")), nil
+	}
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("## Workspaces (%d)\n\nNo workspace is open.\n\n", len(workspaces)))
+	for _, workspace := range workspaces {
+		entities, reports, err := s.workspaces.contents(workspace.ID)
+		if err != nil {
+			return nil, err
+		}
+		text.WriteString(fmt.Sprintf("- **%s**: %d artifacts, %d reports, last changed %s\n",
+			workspace.Name, len(entities), len(reports), s.timeFormatter.Since(workspace.UpdatedAt)))
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// WorkspaceExport is a workspace with its artifacts and the relations between them
+type WorkspaceExport struct {
+	Workspace  *Entity                 `json:"workspace"`
+	ExportedAt time.Time               `json:"exported_at"`
+	Entities   []WorkspaceExportEntity `json:"entities"`
+	Relations  []*Relation             `json:"relations"`
+	Reports    []WorkspaceReport       `json:"reports"`
+}
+
+// WorkspaceExportEntity is an exported artifact
+type WorkspaceExportEntity struct {
+	*Entity
+	Observations []*Observation `json:"observations,omitempty"`
+}
+
+// exportWorkspace returns a workspace's artifacts as one JSON document
+func (s *ForwardMCPService) exportWorkspace(args ExportWorkspaceArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("export_workspace", args, nil)
+
+	if s.workspaces == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	workspace, err := s.resolveWorkspace(args.Caller.callerID(), args.Name)
+	if err != nil {
+		return nil, err
+	}
+	entities, reports, err := s.workspaces.contents(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := WorkspaceExport{Workspace: workspace, ExportedAt: time.Now().UTC(), Entities: []WorkspaceExportEntity{}, Relations: []*Relation{}, Reports: reports}
+	members := make(map[string]bool, len(entities))
+	for _, entity := range entities {
+		members[entity.ID] = true
+	}
+	seen := make(map[string]bool)
+	for _, entity := range entities {
+		exported := WorkspaceExportEntity{Entity: entity}
+		if args.IncludeObservations {
+			if exported.Observations, err = s.memorySystem.GetObservations(entity.ID, ""); err != nil {
+				return nil, err
+			}
+		}
+		export.Entities = append(export.Entities, exported)
+
+		// Relations between artifacts of the workspace; links to shared entities are left out
+		relations, err := s.memorySystem.GetRelations(entity.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, relation := range relations {
+			if members[relation.FromID] && members[relation.ToID] && !seen[relation.ID] {
+				seen[relation.ID] = true
+				export.Relations = append(export.Relations, relation)
+			}
+		}
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal workspace: %w", err)
+	}
+	return mcp.NewToolResponse(mcp.NewTextContent(string(data))), nil
+}
+
+// cleanupWorkspace moves a workspace's artifacts to the trash, optionally with its report
+// files and the workspace itself. Artifacts linked to another workspace are kept.
+func (s *ForwardMCPService) cleanupWorkspace(args CleanupWorkspaceArgs) (*mcp.ToolResponse, error) {
+	s.logToolCall("cleanup_workspace", args, nil)
+
+	if s.workspaces == nil {
+		return nil, newCodedError(CodeMemoryUnavailable)
+	}
+	if args.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	workspace, err := s.resolveWorkspace("", args.Name)
+	if err != nil {
+		return nil, err
+	}
+	entities, reports, err := s.workspaces.contents(workspace.ID)
+	if err != nil {
+		return nil, err
+	}
+	var trash []*Entity
+	kept, pinned := 0, 0
+	pins := s.pinnedEntityIDs()
+	for _, entity := range entities {
+		if pins[entity.ID] {
+			pinned++
+			continue
+		}
+		if s.workspaces.otherWorkspaces(entity.ID, workspace.ID) {
+			kept++
+			continue
+		}
+		trash = append(trash, entity)
+	}
+	if !args.DeleteReports {
+		reports = nil
+	}
+
+	impact := DeleteImpact{
+		Summary:    fmt.Sprintf("The artifacts of workspace '%s' will be deleted.", workspace.Name),
+		Details:    []string{fmt.Sprintf("%d artifacts, with their observations and relations", len(trash))},
+		Rows:       len(trash) + len(reports),
+		Reversible: len(reports) == 0,
+	}
+	if kept > 0 {
+		impact.Details = append(impact.Details, fmt.Sprintf("%d artifacts are kept because another workspace links them", kept))
+	}
+	if pinned > 0 {
+		impact.Details = append(impact.Details, fmt.Sprintf("%d pinned artifacts are kept", pinned))
+	}
+	if len(reports) > 0 {
+		impact.Details = append(impact.Details, fmt.Sprintf("%d report files, which cannot be restored", len(reports)))
+	}
+	if args.DeleteWorkspace {
+		impact.Details = append(impact.Details, "the workspace itself")
+		impact.Rows++
+	}
+	if response, err := s.confirmDestructive("cleanup_workspace", workspace.ID, args.ConfirmationToken, impact); response != nil || err != nil {
+		return response, err
+	}
+
+	s.purgeExpiredTrash()
+	trashed, failed := 0, 0
+	for _, entity := range trash {
+		if _, err := s.memorySystem.TrashEntity(entity.ID); err != nil {
+			s.logger.Warn("Failed to delete %s from workspace %s: %v", entity.ID, workspace.Name, err)
+			failed++
+			continue
+		}
+		if s.analysisCache != nil {
+			s.analysisCache.remove(entity.ID)
+		}
+		trashed++
+	}
+	removed := 0
+	for _, report := range reports {
+		if err := s.removeReportFile(report.Path); err != nil {
+			s.logger.Warn("Failed to delete report %s: %v", report.Path, err)
+			failed++
+			continue
+		}
+		removed++
+	}
+	if args.DeleteWorkspace && failed == 0 {
+		s.workspaces.forget(workspace.ID)
+		if _, err := s.memorySystem.TrashEntity(workspace.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete workspace: %w", err)
+		}
+	}
+
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Cleaned up workspace '%s': %d artifacts moved to the trash", workspace.Name, trashed))
+	if len(reports) > 0 {
+		text.WriteString(fmt.Sprintf(", %d report files deleted", removed))
+	}
+	text.WriteString(".")
+	if kept > 0 {
+		text.WriteString(fmt.Sprintf(" %d artifacts shared with other workspaces were kept.", kept))
+	}
+	if pinned > 0 {
+		text.WriteString(fmt.Sprintf(" %d pinned artifacts were kept.", pinned))
+	}
+	if failed > 0 {
+		text.WriteString(fmt.Sprintf(" %d deletions failed and the workspace was kept; see the server log.", failed))
+	} else if args.DeleteWorkspace {
+		text.WriteString(" The workspace was deleted.")
+	}
+	text.WriteString(fmt.Sprintf(" Undo with restore_entity within %s.", formatRetention(s.trashRetention())))
+	return mcp.NewToolResponse(mcp.NewTextContent(text.String())), nil
+}
+
+// removeReportFile deletes a saved report, refusing paths outside the report directory
+func (s *ForwardMCPService) removeReportFile(path string) error {
+	if s.reports == nil {
+		return fmt.Errorf("report storage is not available")
+	}
+	relative, err := filepath.Rel(s.reports.dir, path)
+	if err != nil || strings.HasPrefix(relative, "..") || filepath.IsAbs(relative) {
+		return fmt.Errorf("%s is not in the report directory", path)
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
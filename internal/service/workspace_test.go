@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/logger"
+	"github.com/forward-mcp/internal/report"
+	mcp "github.com/metoro-io/mcp-golang"
+)
+
+func newWorkspaceTestService(t *testing.T) *ForwardMCPService {
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	service := createTestService()
+	service.workspaces = NewWorkspaces(service.memorySystem, service.logger)
+	reports, err := NewReportStore(config.ReportConfig{Directory: t.TempDir()}, logger.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	service.reports = reports
+	return service
+}
+
+func TestWorkspaceLinksArtifacts(t *testing.T) {
+	service := newWorkspaceTestService(t)
+	memory := service.memorySystem
+
+	before, _ := memory.CreateEntity("before", "note", nil)
+	if _, err := service.createWorkspace(CreateWorkspaceArgs{Name: "dc-migration", Description: "Move DC1 to DC2"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	note, _ := memory.CreateEntity("cutover plan", "note", nil)
+	if _, err := memory.CreateEntity("leaf-1", deviceEntityType, nil); err != nil {
+		t.Fatal(err)
+	}
+	resultID, err := memory.StoreNQEResultWithChunking("FQ_1", "162112", "snap-1", pageResult(3), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Storing the same result again reuses the linked version
+	if _, err := memory.StoreNQEResultWithChunking("FQ_1", "162112", "snap-1", pageResult(3), 1<<20); err != nil {
+		t.Fatal(err)
+	}
+	service.saveReport(&report.Report{Title: "Cutover"}, "cutover", "md")
+
+	entities, reports, err := service.workspaces.contents(service.workspaces.Active("").ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 2 || entities[0].ID != note.ID || entities[1].ID != resultID {
+		t.Errorf("Expected the note and the result, got %+v", entities)
+	}
+	if len(reports) != 1 || reports[0].Format != "markdown" {
+		t.Errorf("Expected the saved report, got %+v", reports)
+	}
+
+	response, err := service.listWorkspaceContents(ListWorkspaceContentsArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := response.Content[0].TextContent.Text
+	for _, expected := range []string{"## Workspace: dc-migration (open)", "Move DC1 to DC2", "### note (1)", "- cutover plan", "### nqe_result (1)", "### Reports (1)"} {
+		if !strings.Contains(text, expected) {
+			t.Errorf("Expected %q in: %s", expected, text)
+		}
+	}
+	if strings.Contains(text, before.Name) || strings.Contains(text, "leaf-1") {
+		t.Errorf("Expected earlier and shared entities left out: %s", text)
+	}
+
+	// Closed, nothing is linked and the listing falls back to every workspace
+	if _, err := service.closeWorkspace(CloseWorkspaceArgs{}); err != nil {
+		t.Fatal(err)
+	}
+	memory.CreateEntity("after", "note", nil)
+	response, _ = service.listWorkspaceContents(ListWorkspaceContentsArgs{})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "- **dc-migration**: 2 artifacts, 1 reports") {
+		t.Errorf("Expected the workspace list, got: %s", text)
+	}
+
+	// Reopening by name continues the same workspace
+	response, _ = service.createWorkspace(CreateWorkspaceArgs{Name: "dc-migration"})
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "Reopened workspace 'dc-migration'") {
+		t.Errorf("Expected the workspace reopened, got: %s", text)
+	}
+}
+
+func TestExportWorkspace(t *testing.T) {
+	service := newWorkspaceTestService(t)
+	memory := service.memorySystem
+
+	service.createWorkspace(CreateWorkspaceArgs{Name: "audit"})
+	first, _ := memory.CreateEntity("finding", "note", nil)
+	second, _ := memory.CreateEntity("evidence", "note", nil)
+	memory.AddObservation(second.ID, "acl 101 permits any", "note", nil)
+	memory.CreateRelation(first.ID, second.ID, "supported_by", nil)
+	device, _ := memory.CreateEntity("fw-1", deviceEntityType, nil)
+	memory.CreateRelation(first.ID, device.ID, "mentions", nil)
+
+	response, err := service.exportWorkspace(ExportWorkspaceArgs{IncludeObservations: true})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var export WorkspaceExport
+	if err := json.Unmarshal([]byte(response.Content[0].TextContent.Text), &export); err != nil {
+		t.Fatal(err)
+	}
+	if export.Workspace.Name != "audit" || len(export.Entities) != 2 {
+		t.Fatalf("Expected the two notes, got %+v", export)
+	}
+	if len(export.Relations) != 1 || export.Relations[0].Type != "supported_by" {
+		t.Errorf("Expected only the relation between artifacts, got %+v", export.Relations)
+	}
+	if observations := export.Entities[1].Observations; len(observations) != 1 || observations[0].Content != "acl 101 permits any" {
+		t.Errorf("Expected the evidence observation, got %+v", observations)
+	}
+}
+
+func TestCleanupWorkspace(t *testing.T) {
+	service := newWorkspaceTestService(t)
+	service.confirmations = NewConfirmationStore(0)
+	memory := service.memorySystem
+
+	service.createWorkspace(CreateWorkspaceArgs{Name: "first"})
+	shared, _ := memory.CreateEntity("shared", "note", nil)
+	own, _ := memory.CreateEntity("own", "note", nil)
+	service.saveReport(&report.Report{Title: "Findings"}, "findings", "md")
+	service.createWorkspace(CreateWorkspaceArgs{Name: "second"})
+	memory.CreateRelation(service.workspaces.Active("").ID, shared.ID, relationInWorkspace, nil)
+	service.createWorkspace(CreateWorkspaceArgs{Name: "first"})
+	_, reports, _ := service.workspaces.contents(service.workspaces.Active("").ID)
+
+	args := CleanupWorkspaceArgs{Name: "first", DeleteReports: true, DeleteWorkspace: true}
+	response, err := service.cleanupWorkspace(args)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	text := response.Content[0].TextContent.Text
+	if !strings.Contains(text, "Confirmation required") || !strings.Contains(text, "1 artifacts are kept") {
+		t.Fatalf("Expected a confirmation request, got: %s", text)
+	}
+	if _, err := memory.GetEntity(own.ID); err != nil {
+		t.Fatal("Expected nothing deleted before confirming")
+	}
+
+	args.ConfirmationToken = confirmationTokenPattern.FindString(text)
+	response, err = service.cleanupWorkspace(args)
+	if err != nil {
+		t.Fatalf("Expected the cleanup to run, got %v", err)
+	}
+	if text := response.Content[0].TextContent.Text; !strings.Contains(text, "1 artifacts moved to the trash, 1 report files deleted") || !strings.Contains(text, "The workspace was deleted") {
+		t.Errorf("Unexpected result: %s", text)
+	}
+	if _, err := memory.GetEntity(own.ID); err == nil {
+		t.Error("Expected the workspace's own note deleted")
+	}
+	if _, err := memory.GetEntity(shared.ID); err != nil {
+		t.Error("Expected the note shared with another workspace kept")
+	}
+	if _, err := os.Stat(reports[0].Path); !os.IsNotExist(err) {
+		t.Errorf("Expected the report file deleted, got %v", err)
+	}
+	if service.workspaces.Active("") != nil {
+		t.Error("Expected the deleted workspace closed")
+	}
+	if err := service.removeReportFile("/etc/passwd"); err == nil {
+		t.Error("Expected files outside the report directory to be refused")
+	}
+}
+
+// workspaceNoteArgs stands in for the arguments of a tool that stores a note
+type workspaceNoteArgs struct {
+	Name string
+}
+
+func TestWorkspacesArePerCaller(t *testing.T) {
+	service := newWorkspaceTestService(t)
+	memory := service.memorySystem
+	alice, bob := &APIKeyIdentity{ID: "alice"}, &APIKeyIdentity{ID: "bob"}
+
+	if _, err := service.createWorkspace(CreateWorkspaceArgs{Name: "alice-audit", Caller: alice}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if service.workspaces.Active("") != nil || service.workspaces.Active("bob") != nil {
+		t.Fatal("Expected the workspace open for alice only")
+	}
+	if _, err := service.closeWorkspace(CloseWorkspaceArgs{Caller: bob}); err == nil {
+		t.Error("Expected bob to have no workspace to close")
+	}
+
+	store := service.workspaceToolHandler(func(args workspaceNoteArgs) (*mcp.ToolResponse, error) {
+		_, err := memory.CreateEntity(args.Name, "note", nil)
+		return mcp.NewToolResponse(mcp.NewTextContent("stored")), err
+	}).(func(context.Context, workspaceNoteArgs) (*mcp.ToolResponse, error))
+	store(WithAPIKeyIdentity(context.Background(), alice), workspaceNoteArgs{Name: "alice note"})
+	store(WithAPIKeyIdentity(context.Background(), bob), workspaceNoteArgs{Name: "bob note"})
+
+	// While another caller's call runs, stores cannot be attributed and are not linked
+	service.workspaces.begin("bob")
+	store(WithAPIKeyIdentity(context.Background(), alice), workspaceNoteArgs{Name: "overlapping note"})
+	service.workspaces.end("bob")
+
+	entities, _, err := service.workspaces.contents(service.workspaces.Active("alice").ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entities) != 1 || entities[0].Name != "alice note" {
+		t.Errorf("Expected only alice's note in her workspace, got %+v", entities)
+	}
+}
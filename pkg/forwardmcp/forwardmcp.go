@@ -0,0 +1,161 @@
+// Package forwardmcp exposes the forward-mcp service layer as a Go API for automation that
+// wants the server's caching, memory system and query index without speaking MCP.
+//
+// A Client owns the same state as a running server: the NQE and memory databases under
+// FORWARD_DATA_DIR, the path search cache and the negative cache. Configuration is read from the
+// FORWARD_* environment variables and the config file like the server, and Options override it.
+//
+//	client, err := forwardmcp.New(forwardmcp.Options{NetworkID: "162112"})
+//	if err != nil {
+//		return err
+//	}
+//	defer client.Close()
+//	result, err := client.RunQuery(ctx, forwardmcp.QueryOptions{QueryID: "FQ_...", AllResults: true, Store: true})
+package forwardmcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/forward-mcp/internal/config"
+	"github.com/forward-mcp/internal/logger"
+	"github.com/forward-mcp/internal/service"
+)
+
+// shutdownTimeout bounds how long Close waits for background work
+const shutdownTimeout = 30 * time.Second
+
+// Options override the environment configuration; empty fields keep the configured values
+type Options struct {
+	APIBaseURL         string
+	APIKey             string
+	APISecret          string
+	NetworkID          string // Default network for calls that do not name one
+	SnapshotID         string // Default snapshot for calls that do not name one
+	InstanceID         string // Partitions the local databases when several Forward instances are used
+	InsecureSkipVerify bool
+}
+
+// Client runs forward-mcp operations in process. It is safe for concurrent use.
+type Client struct {
+	service *service.ForwardMCPService
+	logger  *logger.Logger
+}
+
+// New loads the configuration, applies opts and starts the service layer
+func New(opts Options) (*Client, error) {
+	cfg := config.LoadConfig()
+	forward := &cfg.Forward
+	if opts.APIBaseURL != "" {
+		forward.APIBaseURL = opts.APIBaseURL
+	}
+	if opts.APIKey != "" {
+		forward.APIKey = opts.APIKey
+	}
+	if opts.APISecret != "" {
+		forward.APISecret = opts.APISecret
+	}
+	if opts.NetworkID != "" {
+		forward.DefaultNetworkID = opts.NetworkID
+	}
+	if opts.SnapshotID != "" {
+		forward.DefaultSnapshotID = opts.SnapshotID
+	}
+	if opts.InstanceID != "" {
+		forward.InstanceID = opts.InstanceID
+	}
+	if opts.InsecureSkipVerify {
+		forward.InsecureSkipVerify = true
+	}
+	if forward.APIBaseURL == "" || forward.APIKey == "" || forward.APISecret == "" {
+		return nil, fmt.Errorf("the Forward API base URL, key and secret are required (set FORWARD_API_BASE_URL, FORWARD_API_KEY and FORWARD_API_SECRET or pass them in Options)")
+	}
+
+	log := logger.New()
+	return &Client{service: service.NewForwardMCPService(cfg, log), logger: log}, nil
+}
+
+// Close stops background work and closes the local databases
+func (c *Client) Close() error {
+	return c.service.Shutdown(shutdownTimeout)
+}
+
+// RunQuery runs an NQE query by ID or source. With AllResults every page is fetched, and with
+// Store the rows are kept in the memory system for LoadResult and QueryResult.
+func (c *Client) RunQuery(ctx context.Context, opts QueryOptions) (*QueryResult, error) {
+	result, err := c.service.RunQuery(ctx, service.QueryRequest{
+		QueryID:    opts.QueryID,
+		Query:      opts.Query,
+		NetworkID:  opts.NetworkID,
+		SnapshotID: opts.SnapshotID,
+		Parameters: opts.Parameters,
+		Limit:      opts.Limit,
+		Offset:     opts.Offset,
+		AllResults: opts.AllResults,
+		Store:      opts.Store,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &QueryResult{
+		NetworkID:  result.NetworkID,
+		SnapshotID: result.SnapshotID,
+		Rows:       result.Items,
+		ResultID:   result.EntityID,
+	}, nil
+}
+
+// SearchPaths runs one or more path searches in a single bulk request. Results are cached like
+// the search_paths_bulk tool, so repeated searches are answered locally.
+func (c *Client) SearchPaths(ctx context.Context, opts PathSearchOptions) (*PathSearchResult, error) {
+	queries := make([]service.PathSearchQueryArgs, len(opts.Queries))
+	for i, query := range opts.Queries {
+		queries[i] = service.PathSearchQueryArgs{
+			From:    query.From,
+			SrcIP:   query.SrcIP,
+			DstIP:   query.DstIP,
+			IPProto: query.IPProto,
+			SrcPort: query.SrcPort,
+			DstPort: query.DstPort,
+		}
+	}
+	result, err := c.service.SearchPaths(ctx, service.PathSearchRequest{
+		NetworkID:               opts.NetworkID,
+		SnapshotID:              opts.SnapshotID,
+		Queries:                 queries,
+		Intent:                  opts.Intent,
+		MaxCandidates:           opts.MaxCandidates,
+		MaxResults:              opts.MaxResults,
+		MaxReturnPathResults:    opts.MaxReturnPathResults,
+		MaxSeconds:              opts.MaxSeconds,
+		IncludeNetworkFunctions: opts.IncludeNetworkFunctions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newPathSearchResult(result, opts.Queries), nil
+}
+
+// StoreResult keeps rows in the memory system under name and returns the result ID. Storing the
+// same rows again returns the existing result.
+func (c *Client) StoreResult(name string, rows []map[string]interface{}, opts StoreOptions) (string, error) {
+	return c.service.StoreResult(name, opts.NetworkID, opts.SnapshotID, rows)
+}
+
+// LoadResult returns the rows of a stored result by ID or name
+func (c *Client) LoadResult(id string) ([]map[string]interface{}, error) {
+	return c.service.LoadResult(id)
+}
+
+// QueryResult runs a read-only SQL query against a stored result, whose rows form the table
+// nqe_result, and returns at most limit rows
+func (c *Client) QueryResult(id, sql string, limit int) ([]map[string]interface{}, error) {
+	return c.service.QueryStoredResult(id, sql, limit)
+}
+
+// ErrorCode returns the stable code of an error returned by a Client, such as "FWD-NET-001",
+// or "" when the error has none. The lookup_error tool explains each code.
+func ErrorCode(err error) string {
+	return service.ErrorCode(err)
+}
@@ -0,0 +1,118 @@
+package forwardmcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClient starts a fake Forward API and a client with its state in a temporary directory
+func newTestClient(t *testing.T, pathSearches *int) *Client {
+	t.Helper()
+	t.Setenv("FORWARD_DATA_DIR", t.TempDir())
+	t.Setenv("FORWARD_INSTANCE_ID", "sdk-test")
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/nqe":
+			var body struct {
+				QueryOptions struct {
+					Offset int `json:"offset"`
+				} `json:"queryOptions"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			items := []map[string]interface{}{}
+			if body.QueryOptions.Offset == 0 {
+				items = []map[string]interface{}{{"name": "core-1"}, {"name": "core-2"}}
+			} else if body.QueryOptions.Offset == 2 {
+				items = []map[string]interface{}{{"name": "edge-1"}}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"snapshotId": "snap-1", "items": items})
+		case "/api/networks/net-1/paths-bulk":
+			*pathSearches++
+			json.NewEncoder(w).Encode([]map[string]interface{}{{
+				"dstIpLocationType": "HOST",
+				"info": map[string]interface{}{
+					"paths": []map[string]interface{}{{
+						"forwardingOutcome": "DELIVERED",
+						"hops":              []map[string]interface{}{{"deviceName": "core-1", "egressInterface": "eth0"}},
+					}},
+					"totalHits": map[string]interface{}{"value": 1},
+				},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	client, err := New(Options{APIBaseURL: api.URL, APIKey: "key", APISecret: "secret", NetworkID: "net-1"})
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestNewRequiresCredentials(t *testing.T) {
+	t.Setenv("FORWARD_API_BASE_URL", "")
+	t.Setenv("FORWARD_API_KEY", "")
+	t.Setenv("FORWARD_API_SECRET", "")
+	_, err := New(Options{APIBaseURL: "https://fwd.example.com"})
+	assert.Error(t, err)
+}
+
+func TestRunQueryAndStoredResults(t *testing.T) {
+	client := newTestClient(t, new(int))
+	ctx := context.Background()
+
+	page, err := client.RunQuery(ctx, QueryOptions{QueryID: "FQ_devices", Limit: 2})
+	require.NoError(t, err)
+	assert.Len(t, page.Rows, 2)
+	assert.Equal(t, "net-1", page.NetworkID)
+	assert.Empty(t, page.ResultID)
+
+	all, err := client.RunQuery(ctx, QueryOptions{QueryID: "FQ_devices", Limit: 2, AllResults: true, Store: true})
+	require.NoError(t, err)
+	assert.Len(t, all.Rows, 3)
+	assert.Equal(t, "snap-1", all.SnapshotID)
+	require.NotEmpty(t, all.ResultID)
+
+	rows, err := client.QueryResult(all.ResultID, "SELECT name FROM nqe_result WHERE name LIKE 'core%' ORDER BY name", 10)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	id, err := client.StoreResult("custom-inventory", []map[string]interface{}{{"site": "nyc"}}, StoreOptions{NetworkID: "net-1"})
+	require.NoError(t, err)
+	loaded, err := client.LoadResult(id)
+	require.NoError(t, err)
+	assert.Equal(t, "nyc", loaded[0]["site"])
+
+	_, err = client.RunQuery(ctx, QueryOptions{QueryID: "FQ_devices", Query: "foreach d in network.devices select {}"})
+	assert.Error(t, err, "query ID and source are mutually exclusive")
+}
+
+func TestSearchPathsUsesCache(t *testing.T) {
+	searches := 0
+	client := newTestClient(t, &searches)
+	opts := PathSearchOptions{Queries: []PathQuery{{SrcIP: "10.0.0.1", DstIP: "10.0.1.1"}}}
+
+	result, err := client.SearchPaths(context.Background(), opts)
+	require.NoError(t, err)
+	require.Len(t, result.Results, 1)
+	assert.False(t, result.Cached)
+	assert.Equal(t, "10.0.1.1", result.Results[0].Query.DstIP)
+	assert.Equal(t, "DELIVERED", result.Results[0].Paths[0].ForwardingOutcome)
+	assert.Equal(t, "core-1", result.Results[0].Paths[0].Hops[0].Device)
+
+	cached, err := client.SearchPaths(context.Background(), opts)
+	require.NoError(t, err)
+	assert.True(t, cached.Cached)
+	assert.Equal(t, 1, searches)
+
+	_, err = client.SearchPaths(context.Background(), PathSearchOptions{Queries: []PathQuery{{SrcIP: "10.0.0.1", DstIP: "core-1"}}})
+	assert.Error(t, err, "device names are not valid destinations")
+}
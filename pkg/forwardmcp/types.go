@@ -0,0 +1,133 @@
+package forwardmcp
+
+import (
+	"github.com/forward-mcp/internal/forward"
+	"github.com/forward-mcp/internal/service"
+)
+
+// QueryOptions selects an NQE query and how to run it. Exactly one of QueryID and Query is set.
+type QueryOptions struct {
+	QueryID    string
+	Query      string // NQE source for ad-hoc queries
+	NetworkID  string // Defaults to Options.NetworkID
+	SnapshotID string // Defaults to Options.SnapshotID, then the latest snapshot
+	Parameters map[string]interface{}
+	Limit      int  // Rows per page
+	Offset     int  // First row to fetch
+	AllResults bool // Fetch every page
+	Store      bool // Keep the rows in the memory system
+}
+
+// QueryResult holds the rows of an NQE query
+type QueryResult struct {
+	NetworkID  string
+	SnapshotID string
+	Rows       []map[string]interface{}
+	ResultID   string // Set when QueryOptions.Store was requested
+}
+
+// PathQuery is a single path search. DstIP is required, along with From or SrcIP.
+type PathQuery struct {
+	From    string
+	SrcIP   string
+	DstIP   string
+	IPProto *int
+	SrcPort string
+	DstPort string
+}
+
+// PathSearchOptions configures a bulk path search
+type PathSearchOptions struct {
+	NetworkID               string
+	SnapshotID              string // Empty or "latest" uses the latest processed snapshot
+	Queries                 []PathQuery
+	Intent                  string // PREFER_DELIVERED, PREFER_VIOLATIONS or VIOLATIONS_ONLY
+	MaxCandidates           int
+	MaxResults              int
+	MaxReturnPathResults    int
+	MaxSeconds              int
+	IncludeNetworkFunctions bool
+}
+
+// PathSearchResult holds one PathResult per query, in query order
+type PathSearchResult struct {
+	NetworkID  string
+	SnapshotID string
+	Cached     bool // Answered from the path search cache
+	Results    []PathResult
+}
+
+// PathResult is the outcome of one path search
+type PathResult struct {
+	Query           PathQuery
+	DstLocationType string
+	TimedOut        bool
+	TotalHits       int
+	Paths           []Path
+	ReturnPaths     []Path
+}
+
+// Path is a forwarding path with its outcomes
+type Path struct {
+	ForwardingOutcome string
+	SecurityOutcome   string
+	Hops              []Hop
+}
+
+// Hop is a device along a path
+type Hop struct {
+	Device           string
+	DeviceType       string
+	IngressInterface string
+	EgressInterface  string
+	Behaviors        []string
+}
+
+// StoreOptions records where stored rows came from
+type StoreOptions struct {
+	NetworkID  string
+	SnapshotID string
+}
+
+// newPathSearchResult converts the service result into the public types
+func newPathSearchResult(result *service.PathSearchResult, queries []PathQuery) *PathSearchResult {
+	converted := &PathSearchResult{
+		NetworkID:  result.NetworkID,
+		SnapshotID: result.SnapshotID,
+		Cached:     result.Cached,
+		Results:    make([]PathResult, len(result.Responses)),
+	}
+	for i, response := range result.Responses {
+		pathResult := PathResult{
+			DstLocationType: response.DstIpLocationType,
+			TimedOut:        response.TimedOut,
+			TotalHits:       response.Info.TotalHits.Value,
+		}
+		if i < len(queries) {
+			pathResult.Query = queries[i]
+		}
+		for _, path := range response.Info.Paths {
+			pathResult.Paths = append(pathResult.Paths, newPath(path))
+		}
+		for _, path := range response.ReturnPathInfo.Paths {
+			pathResult.ReturnPaths = append(pathResult.ReturnPaths, newPath(path))
+		}
+		converted.Results[i] = pathResult
+	}
+	return converted
+}
+
+// newPath converts an API path into the public type
+func newPath(bulkPath forward.BulkPath) Path {
+	path := Path{ForwardingOutcome: bulkPath.ForwardingOutcome, SecurityOutcome: bulkPath.SecurityOutcome, Hops: make([]Hop, len(bulkPath.Hops))}
+	for i, hop := range bulkPath.Hops {
+		path.Hops[i] = Hop{
+			Device:           hop.DeviceName,
+			DeviceType:       hop.DeviceType,
+			IngressInterface: hop.IngressInterface,
+			EgressInterface:  hop.EgressInterface,
+			Behaviors:        hop.Behaviors,
+		}
+	}
+	return path
+}